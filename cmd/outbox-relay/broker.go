@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/consumer"
+	"github.com/Guizzs26/fintrack/pkg/retry"
+)
+
+var (
+	_ consumer.Producer = (*stdoutProducer)(nil)
+	_ consumer.Producer = (*restProxyProducer)(nil)
+)
+
+// stdoutProducer writes each record as a JSON line, for local runs without a broker
+type stdoutProducer struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newStdoutProducer(w io.Writer) *stdoutProducer {
+	return &stdoutProducer{enc: json.NewEncoder(w)}
+}
+
+// Produce writes the record to the output
+func (p *stdoutProducer) Produce(ctx context.Context, record consumer.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.enc.Encode(struct {
+		Topic   string            `json:"topic"`
+		Key     string            `json:"key"`
+		Headers map[string]string `json:"headers"`
+		Value   json.RawMessage   `json:"value"`
+	}{record.Topic, string(record.Key), record.Headers, record.Value})
+}
+
+// restProxyProducer publishes records through the v3 API of a Kafka REST proxy, the broker partitioning them by key
+type restProxyProducer struct {
+	baseURL    string
+	clusterID  string
+	httpClient *http.Client
+}
+
+func newRestProxyProducer(baseURL, clusterID string, timeout time.Duration) *restProxyProducer {
+	return &restProxyProducer{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		clusterID:  clusterID,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// restProxyData is a key or a value of a produce request
+type restProxyData struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// restProxyHeader is a record header, its value encoded in base64
+type restProxyHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// restProxyResponse is the body of a produce response, error_code being 200 once the record was written
+type restProxyResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Produce posts the record and waits for the broker to acknowledge it
+func (p *restProxyProducer) Produce(ctx context.Context, record consumer.Record) error {
+	headers := make([]restProxyHeader, 0, len(record.Headers))
+	for name, value := range record.Headers {
+		headers = append(headers, restProxyHeader{Name: name, Value: base64.StdEncoding.EncodeToString([]byte(value))})
+	}
+
+	body, err := json.Marshal(struct {
+		Key     restProxyData     `json:"key"`
+		Value   restProxyData     `json:"value"`
+		Headers []restProxyHeader `json:"headers"`
+	}{
+		Key:     restProxyData{Type: "STRING", Data: string(record.Key)},
+		Value:   restProxyData{Type: "JSON", Data: json.RawMessage(record.Value)},
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal produce request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/clusters/%s/topics/%s/records", p.baseURL, url.PathEscape(p.clusterID), url.PathEscape(record.Topic))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create produce request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return retry.Retryable(fmt.Errorf("failed to send produce request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	var result restProxyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("failed to decode produce response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.ErrorCode != http.StatusOK {
+		err := fmt.Errorf("rest proxy rejected the record to %s: status %d, error code %d: %s",
+			record.Topic, resp.StatusCode, result.ErrorCode, result.Message)
+		// The proxy or the broker being overloaded or unavailable is worth another attempt, a malformed record is not
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || result.ErrorCode >= 500 {
+			return retry.Retryable(err)
+		}
+		return err
+	}
+	return nil
+}
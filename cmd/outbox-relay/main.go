@@ -0,0 +1,174 @@
+// Command outbox-relay publishes the events the ledger and the identity service write to their outbox
+//
+// Usage:
+//
+//	outbox-relay [flags]                          relay the outboxes until interrupted
+//	outbox-relay -replay ledger=1200 [flags]      move the cursor of a source back, then relay from it
+//
+// Flags default to the environment variables named in their usage
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/consumer"
+	"github.com/Guizzs26/fintrack/pkg/logger"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/pkg/outbox"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// options are the settings of the relay, read from the flags
+type options struct {
+	databaseURL      string
+	dynamoEndpoint   string
+	dynamoRegion     string
+	identityTable    string
+	broker           string
+	restProxyURL     string
+	restProxyCluster string
+	topicPrefix      string
+	batchSize        int
+	pollInterval     time.Duration
+	settleDelay      time.Duration
+	metricsAddr      string
+	replay           string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.databaseURL, "database-url", os.Getenv("DATABASE_URL"), "ledger Postgres `DSN` holding the ledger outbox and the relay cursors (DATABASE_URL)")
+	flag.StringVar(&opts.dynamoEndpoint, "dynamodb-endpoint", os.Getenv("DYNAMODB_ENDPOINT"), "DynamoDB endpoint, for a local instance (DYNAMODB_ENDPOINT)")
+	flag.StringVar(&opts.dynamoRegion, "dynamodb-region", envOr("AWS_REGION", "us-east-1"), "DynamoDB region (AWS_REGION)")
+	flag.StringVar(&opts.identityTable, "identity-table", envOr("IDENTITY_TABLE", "FintrackIdentity"), "identity service table holding its outbox, empty to skip it (IDENTITY_TABLE)")
+	flag.StringVar(&opts.broker, "broker", envOr("OUTBOX_BROKER", "stdout"), "where events are published: stdout or rest-proxy (OUTBOX_BROKER)")
+	flag.StringVar(&opts.restProxyURL, "rest-proxy-url", os.Getenv("KAFKA_REST_PROXY_URL"), "base URL of the Kafka REST proxy (KAFKA_REST_PROXY_URL)")
+	flag.StringVar(&opts.restProxyCluster, "rest-proxy-cluster", os.Getenv("KAFKA_CLUSTER_ID"), "Kafka cluster ID on the REST proxy (KAFKA_CLUSTER_ID)")
+	flag.StringVar(&opts.topicPrefix, "topic-prefix", envOr("OUTBOX_TOPIC_PREFIX", "fintrack."), "prefix of the topics, followed by the aggregate type (OUTBOX_TOPIC_PREFIX)")
+	flag.IntVar(&opts.batchSize, "batch-size", 500, "messages read from a source at a time")
+	flag.DurationVar(&opts.pollInterval, "poll-interval", time.Second, "wait before polling a drained source again")
+	flag.DurationVar(&opts.settleDelay, "settle-delay", 5*time.Second, "how old a message must be to be relayed, so late commits are not skipped")
+	flag.StringVar(&opts.metricsAddr, "metrics-addr", envOr("OUTBOX_METRICS_ADDR", ":9092"), "address serving /metrics, empty to disable it (OUTBOX_METRICS_ADDR)")
+	flag.StringVar(&opts.replay, "replay", "", "`source=cursor` to move the cursor of a source before relaying, an empty cursor replays the whole outbox")
+	flag.Parse()
+
+	if err := run(context.Background(), opts); err != nil {
+		fmt.Fprintf(os.Stderr, "outbox relay finished with an error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Logs go to stderr, the stdout broker writing the events to the standard output
+	baseLogger := logger.NewSlogConfig(logger.SlogConfig{
+		Level:           logger.LevelInfo,
+		Format:          logger.FormatJSON,
+		Writer:          os.Stderr,
+		SamplePerSecond: 100,
+	})
+	slog.SetDefault(baseLogger)
+
+	if opts.databaseURL == "" {
+		return errors.New("the ledger database URL is required")
+	}
+	pool, err := pgxpool.New(ctx, opts.databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the ledger database: %w", err)
+	}
+	defer pool.Close()
+
+	sources := []outbox.Source{newLedgerSource(pool)}
+	if opts.identityTable != "" {
+		identitySource, err := newIdentitySource(ctx, opts.dynamoEndpoint, opts.dynamoRegion, opts.identityTable)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, identitySource)
+	}
+
+	producer, err := newProducer(opts)
+	if err != nil {
+		return err
+	}
+
+	metricsRegistry := metrics.NewRegistry()
+	relay := outbox.New(sources, newPostgresOffsetStore(pool), producer, outbox.Config{
+		BatchSize:    opts.batchSize,
+		PollInterval: opts.pollInterval,
+		SettleDelay:  opts.settleDelay,
+		TopicPrefix:  opts.topicPrefix,
+	}, baseLogger, clock.SystemClock{}, metricsRegistry)
+
+	if opts.replay != "" {
+		source, cursor, ok := strings.Cut(opts.replay, "=")
+		if !ok {
+			return fmt.Errorf("invalid -replay %q, expected source=cursor", opts.replay)
+		}
+		if err := relay.Replay(ctx, source, cursor); err != nil {
+			return err
+		}
+	}
+
+	if opts.metricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", metricsRegistry.Handler())
+		metricsServer := &http.Server{
+			Addr:              opts.metricsAddr,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			slog.Info("metrics server listening", slog.String("addr", opts.metricsAddr))
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("metrics server failed to serve", slog.String("error", err.Error()))
+			}
+		}()
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCancel()
+			if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+				slog.Error("failed to shut down metrics server", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
+	slog.Info("outbox relay started", slog.Int("sources", len(sources)), slog.String("broker", opts.broker))
+	return relay.Run(ctx)
+}
+
+// newProducer creates the producer of the configured broker
+func newProducer(opts options) (consumer.Producer, error) {
+	switch opts.broker {
+	case "stdout":
+		return newStdoutProducer(os.Stdout), nil
+	case "rest-proxy":
+		if opts.restProxyURL == "" || opts.restProxyCluster == "" {
+			return nil, errors.New("the rest-proxy broker needs the proxy URL and the cluster ID")
+		}
+		return newRestProxyProducer(opts.restProxyURL, opts.restProxyCluster, 10*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unknown broker %q, expected stdout or rest-proxy", opts.broker)
+	}
+}
+
+// envOr returns the environment variable, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/outbox"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ outbox.Source      = (*ledgerSource)(nil)
+	_ outbox.Source      = (*identitySource)(nil)
+	_ outbox.OffsetStore = (*postgresOffsetStore)(nil)
+)
+
+// ----- Ledger ----- //
+
+// ledgerSource reads the outbox table of the ledger, the cursor being the position of the last row relayed
+type ledgerSource struct {
+	pool *pgxpool.Pool
+}
+
+func newLedgerSource(pool *pgxpool.Pool) *ledgerSource {
+	return &ledgerSource{pool: pool}
+}
+
+// Name returns "ledger"
+func (s *ledgerSource) Name() string { return "ledger" }
+
+// Fetch reads the rows after the position of the cursor recorded before the given time
+func (s *ledgerSource) Fetch(ctx context.Context, after string, before time.Time, limit int) ([]outbox.Message, error) {
+	var position int64
+	if after != "" {
+		parsed, err := strconv.ParseInt(after, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ledger cursor %q: %w", after, err)
+		}
+		position = parsed
+	}
+
+	query := `
+		SELECT position, event_id, aggregate_type, aggregate_id, event_type, event_version, envelope, recorded_at
+		FROM outbox
+		WHERE position > $1 AND recorded_at < $2
+		ORDER BY position
+		LIMIT $3
+	`
+	rows, err := s.pool.Query(ctx, query, position, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger outbox: %w", err)
+	}
+
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (outbox.Message, error) {
+		var msg outbox.Message
+		var position int64
+		var aggregateID uuid.UUID
+		if err := row.Scan(&position, &msg.EventID, &msg.AggregateType, &aggregateID, &msg.EventType, &msg.EventVersion, &msg.Envelope, &msg.RecordedAt); err != nil {
+			return outbox.Message{}, err
+		}
+		msg.Cursor = strconv.FormatInt(position, 10)
+		msg.AggregateID = aggregateID.String()
+		return msg, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan ledger outbox: %w", err)
+	}
+	return messages, nil
+}
+
+// postgresOffsetStore keeps the cursors of every source in the outbox_relay_offsets table of the ledger
+type postgresOffsetStore struct {
+	pool *pgxpool.Pool
+}
+
+func newPostgresOffsetStore(pool *pgxpool.Pool) *postgresOffsetStore {
+	return &postgresOffsetStore{pool: pool}
+}
+
+// Load returns the cursor of the source, empty when nothing was relayed yet
+func (s *postgresOffsetStore) Load(ctx context.Context, source string) (string, error) {
+	var cursor string
+	err := s.pool.QueryRow(ctx, `SELECT cursor FROM outbox_relay_offsets WHERE source = $1`, source).Scan(&cursor)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to load cursor of %s: %w", source, err)
+	}
+	return cursor, nil
+}
+
+// Store upserts the cursor of the source
+func (s *postgresOffsetStore) Store(ctx context.Context, source, cursor string) error {
+	query := `
+		INSERT INTO outbox_relay_offsets (source, cursor, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (source) DO UPDATE SET cursor = EXCLUDED.cursor, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := s.pool.Exec(ctx, query, source, cursor); err != nil {
+		return fmt.Errorf("failed to store cursor of %s: %w", source, err)
+	}
+	return nil
+}
+
+// ----- Identity ----- //
+
+const (
+	// identityOutboxPK is the partition of the outbox items in the identity table
+	identityOutboxPK = "OUTBOX"
+	// identityOutboxSKPrefix starts the sort keys, followed by a fixed width UTC timestamp and the event ID
+	identityOutboxSKPrefix   = "EVENT#"
+	identityOutboxTimeLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+// identityOutboxItem mirrors the outbox items the identity service writes next to its users
+type identityOutboxItem struct {
+	SK            string    `dynamodbav:"SK"`
+	EventID       uuid.UUID `dynamodbav:"EventID"`
+	AggregateType string    `dynamodbav:"AggregateType"`
+	AggregateID   string    `dynamodbav:"AggregateID"`
+	EventType     string    `dynamodbav:"EventType"`
+	EventVersion  int       `dynamodbav:"EventVersion"`
+	Envelope      string    `dynamodbav:"Envelope"`
+	RecordedAt    time.Time `dynamodbav:"RecordedAt"`
+}
+
+// identitySource queries the outbox partition of the identity table, the cursor being the sort key of the last item relayed
+type identitySource struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// newIdentitySource creates the DynamoDB client, pointed at a local instance when the endpoint is set
+func newIdentitySource(ctx context.Context, endpoint, region, tableName string) (*identitySource, error) {
+	cfgOptions := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if endpoint != "" {
+		// A local DynamoDB ignores the credentials, but the SDK requires them
+		cfgOptions = append(cfgOptions, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider("DUMMY", "DUMMY", ""),
+		))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, cfgOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return &identitySource{client: client, tableName: tableName}, nil
+}
+
+// Name returns "identity"
+func (s *identitySource) Name() string { return "identity" }
+
+// Fetch queries the items sorted after the cursor and recorded before the given time
+func (s *identitySource) Fetch(ctx context.Context, after string, before time.Time, limit int) ([]outbox.Message, error) {
+	if after == "" {
+		after = identityOutboxSKPrefix
+	}
+
+	// BETWEEN is inclusive, the item of the cursor is read again and skipped
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND SK BETWEEN :after AND :before"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: identityOutboxPK},
+			":after":  &types.AttributeValueMemberS{Value: after},
+			":before": &types.AttributeValueMemberS{Value: identityOutboxSKPrefix + before.UTC().Format(identityOutboxTimeLayout)},
+		},
+		Limit: aws.Int32(int32(limit + 1)),
+	}
+
+	output, err := s.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query identity outbox: %w", err)
+	}
+
+	var items []identityOutboxItem
+	if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal identity outbox items: %w", err)
+	}
+
+	messages := make([]outbox.Message, 0, len(items))
+	for _, item := range items {
+		if item.SK == after || len(messages) == limit {
+			continue
+		}
+		messages = append(messages, outbox.Message{
+			Cursor:        item.SK,
+			EventID:       item.EventID,
+			EventType:     item.EventType,
+			EventVersion:  item.EventVersion,
+			AggregateType: item.AggregateType,
+			AggregateID:   item.AggregateID,
+			Envelope:      []byte(item.Envelope),
+			RecordedAt:    item.RecordedAt,
+		})
+	}
+	return messages, nil
+}
@@ -0,0 +1,136 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: common/v1/date_range.proto
+
+package commonv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DateRange is the half-open range of time [from, to)
+type DateRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DateRange) Reset() {
+	*x = DateRange{}
+	mi := &file_common_v1_date_range_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DateRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DateRange) ProtoMessage() {}
+
+func (x *DateRange) ProtoReflect() protoreflect.Message {
+	mi := &file_common_v1_date_range_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DateRange.ProtoReflect.Descriptor instead.
+func (*DateRange) Descriptor() ([]byte, []int) {
+	return file_common_v1_date_range_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DateRange) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *DateRange) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+var File_common_v1_date_range_proto protoreflect.FileDescriptor
+
+const file_common_v1_date_range_proto_rawDesc = "" +
+	"\n" +
+	"\x1acommon/v1/date_range.proto\x12\tcommon.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"g\n" +
+	"\tDateRange\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02toB8Z6github.com/Guizzs26/fintrack/gen/go/common/v1;commonv1b\x06proto3"
+
+var (
+	file_common_v1_date_range_proto_rawDescOnce sync.Once
+	file_common_v1_date_range_proto_rawDescData []byte
+)
+
+func file_common_v1_date_range_proto_rawDescGZIP() []byte {
+	file_common_v1_date_range_proto_rawDescOnce.Do(func() {
+		file_common_v1_date_range_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_common_v1_date_range_proto_rawDesc), len(file_common_v1_date_range_proto_rawDesc)))
+	})
+	return file_common_v1_date_range_proto_rawDescData
+}
+
+var file_common_v1_date_range_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_common_v1_date_range_proto_goTypes = []any{
+	(*DateRange)(nil),             // 0: common.v1.DateRange
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_common_v1_date_range_proto_depIdxs = []int32{
+	1, // 0: common.v1.DateRange.from:type_name -> google.protobuf.Timestamp
+	1, // 1: common.v1.DateRange.to:type_name -> google.protobuf.Timestamp
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_common_v1_date_range_proto_init() }
+func file_common_v1_date_range_proto_init() {
+	if File_common_v1_date_range_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_v1_date_range_proto_rawDesc), len(file_common_v1_date_range_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_common_v1_date_range_proto_goTypes,
+		DependencyIndexes: file_common_v1_date_range_proto_depIdxs,
+		MessageInfos:      file_common_v1_date_range_proto_msgTypes,
+	}.Build()
+	File_common_v1_date_range_proto = out.File
+	file_common_v1_date_range_proto_goTypes = nil
+	file_common_v1_date_range_proto_depIdxs = nil
+}
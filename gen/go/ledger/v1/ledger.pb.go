@@ -0,0 +1,499 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ledger/v1/ledger.proto
+
+package ledgerv1
+
+import (
+	v1 "github.com/Guizzs26/fintrack/gen/go/common/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AccountKind int32
+
+const (
+	AccountKind_ACCOUNT_KIND_UNSPECIFIED AccountKind = 0
+	AccountKind_ACCOUNT_KIND_CHECKING    AccountKind = 1
+	AccountKind_ACCOUNT_KIND_CREDIT_CARD AccountKind = 2
+	AccountKind_ACCOUNT_KIND_INVESTMENT  AccountKind = 3
+)
+
+// Enum value maps for AccountKind.
+var (
+	AccountKind_name = map[int32]string{
+		0: "ACCOUNT_KIND_UNSPECIFIED",
+		1: "ACCOUNT_KIND_CHECKING",
+		2: "ACCOUNT_KIND_CREDIT_CARD",
+		3: "ACCOUNT_KIND_INVESTMENT",
+	}
+	AccountKind_value = map[string]int32{
+		"ACCOUNT_KIND_UNSPECIFIED": 0,
+		"ACCOUNT_KIND_CHECKING":    1,
+		"ACCOUNT_KIND_CREDIT_CARD": 2,
+		"ACCOUNT_KIND_INVESTMENT":  3,
+	}
+)
+
+func (x AccountKind) Enum() *AccountKind {
+	p := new(AccountKind)
+	*p = x
+	return p
+}
+
+func (x AccountKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccountKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_ledger_v1_ledger_proto_enumTypes[0].Descriptor()
+}
+
+func (AccountKind) Type() protoreflect.EnumType {
+	return &file_ledger_v1_ledger_proto_enumTypes[0]
+}
+
+func (x AccountKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccountKind.Descriptor instead.
+func (AccountKind) EnumDescriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{0}
+}
+
+type Account struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Id                      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Kind                    AccountKind            `protobuf:"varint,3,opt,name=kind,proto3,enum=ledger.v1.AccountKind" json:"kind,omitempty"`
+	RealBalance             *v1.Money              `protobuf:"bytes,4,opt,name=real_balance,json=realBalance,proto3" json:"real_balance,omitempty"`                // Balance of the paid transactions
+	ProjectedBalance        *v1.Money              `protobuf:"bytes,5,opt,name=projected_balance,json=projectedBalance,proto3" json:"projected_balance,omitempty"` // Balance once every transaction is paid
+	IncludeInOverallBalance bool                   `protobuf:"varint,6,opt,name=include_in_overall_balance,json=includeInOverallBalance,proto3" json:"include_in_overall_balance,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_ledger_v1_ledger_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_v1_ledger_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Account) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Account) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Account) GetKind() AccountKind {
+	if x != nil {
+		return x.Kind
+	}
+	return AccountKind_ACCOUNT_KIND_UNSPECIFIED
+}
+
+func (x *Account) GetRealBalance() *v1.Money {
+	if x != nil {
+		return x.RealBalance
+	}
+	return nil
+}
+
+func (x *Account) GetProjectedBalance() *v1.Money {
+	if x != nil {
+		return x.ProjectedBalance
+	}
+	return nil
+}
+
+func (x *Account) GetIncludeInOverallBalance() bool {
+	if x != nil {
+		return x.IncludeInOverallBalance
+	}
+	return false
+}
+
+type ListAccountsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountsRequest) Reset() {
+	*x = ListAccountsRequest{}
+	mi := &file_ledger_v1_ledger_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountsRequest) ProtoMessage() {}
+
+func (x *ListAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_v1_ledger_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountsRequest.ProtoReflect.Descriptor instead.
+func (*ListAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListAccountsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListAccountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*Account             `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountsResponse) Reset() {
+	*x = ListAccountsResponse{}
+	mi := &file_ledger_v1_ledger_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountsResponse) ProtoMessage() {}
+
+func (x *ListAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_v1_ledger_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountsResponse.ProtoReflect.Descriptor instead.
+func (*ListAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListAccountsResponse) GetAccounts() []*Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type GetCashFlowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Range         *v1.DateRange          `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCashFlowRequest) Reset() {
+	*x = GetCashFlowRequest{}
+	mi := &file_ledger_v1_ledger_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCashFlowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCashFlowRequest) ProtoMessage() {}
+
+func (x *GetCashFlowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_v1_ledger_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCashFlowRequest.ProtoReflect.Descriptor instead.
+func (*GetCashFlowRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetCashFlowRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetCashFlowRequest) GetRange() *v1.DateRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+// CashFlow is the flow of the accounts of one currency
+type CashFlow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Income        *v1.Money              `protobuf:"bytes,1,opt,name=income,proto3" json:"income,omitempty"` // Incomes and balance adjustments
+	Expense       *v1.Money              `protobuf:"bytes,2,opt,name=expense,proto3" json:"expense,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CashFlow) Reset() {
+	*x = CashFlow{}
+	mi := &file_ledger_v1_ledger_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CashFlow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CashFlow) ProtoMessage() {}
+
+func (x *CashFlow) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_v1_ledger_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CashFlow.ProtoReflect.Descriptor instead.
+func (*CashFlow) Descriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CashFlow) GetIncome() *v1.Money {
+	if x != nil {
+		return x.Income
+	}
+	return nil
+}
+
+func (x *CashFlow) GetExpense() *v1.Money {
+	if x != nil {
+		return x.Expense
+	}
+	return nil
+}
+
+type GetCashFlowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flows         []*CashFlow            `protobuf:"bytes,1,rep,name=flows,proto3" json:"flows,omitempty"` // One flow per currency
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCashFlowResponse) Reset() {
+	*x = GetCashFlowResponse{}
+	mi := &file_ledger_v1_ledger_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCashFlowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCashFlowResponse) ProtoMessage() {}
+
+func (x *GetCashFlowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_v1_ledger_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCashFlowResponse.ProtoReflect.Descriptor instead.
+func (*GetCashFlowResponse) Descriptor() ([]byte, []int) {
+	return file_ledger_v1_ledger_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetCashFlowResponse) GetFlows() []*CashFlow {
+	if x != nil {
+		return x.Flows
+	}
+	return nil
+}
+
+var File_ledger_v1_ledger_proto protoreflect.FileDescriptor
+
+const file_ledger_v1_ledger_proto_rawDesc = "" +
+	"\n" +
+	"\x16ledger/v1/ledger.proto\x12\tledger.v1\x1a\x1acommon/v1/date_range.proto\x1a\x15common/v1/money.proto\"\x8a\x02\n" +
+	"\aAccount\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12*\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x16.ledger.v1.AccountKindR\x04kind\x123\n" +
+	"\freal_balance\x18\x04 \x01(\v2\x10.common.v1.MoneyR\vrealBalance\x12=\n" +
+	"\x11projected_balance\x18\x05 \x01(\v2\x10.common.v1.MoneyR\x10projectedBalance\x12;\n" +
+	"\x1ainclude_in_overall_balance\x18\x06 \x01(\bR\x17includeInOverallBalance\".\n" +
+	"\x13ListAccountsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"F\n" +
+	"\x14ListAccountsResponse\x12.\n" +
+	"\baccounts\x18\x01 \x03(\v2\x12.ledger.v1.AccountR\baccounts\"Y\n" +
+	"\x12GetCashFlowRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12*\n" +
+	"\x05range\x18\x02 \x01(\v2\x14.common.v1.DateRangeR\x05range\"`\n" +
+	"\bCashFlow\x12(\n" +
+	"\x06income\x18\x01 \x01(\v2\x10.common.v1.MoneyR\x06income\x12*\n" +
+	"\aexpense\x18\x02 \x01(\v2\x10.common.v1.MoneyR\aexpense\"@\n" +
+	"\x13GetCashFlowResponse\x12)\n" +
+	"\x05flows\x18\x01 \x03(\v2\x13.ledger.v1.CashFlowR\x05flows*\x81\x01\n" +
+	"\vAccountKind\x12\x1c\n" +
+	"\x18ACCOUNT_KIND_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ACCOUNT_KIND_CHECKING\x10\x01\x12\x1c\n" +
+	"\x18ACCOUNT_KIND_CREDIT_CARD\x10\x02\x12\x1b\n" +
+	"\x17ACCOUNT_KIND_INVESTMENT\x10\x032\xae\x01\n" +
+	"\rLedgerService\x12O\n" +
+	"\fListAccounts\x12\x1e.ledger.v1.ListAccountsRequest\x1a\x1f.ledger.v1.ListAccountsResponse\x12L\n" +
+	"\vGetCashFlow\x12\x1d.ledger.v1.GetCashFlowRequest\x1a\x1e.ledger.v1.GetCashFlowResponseB8Z6github.com/Guizzs26/fintrack/gen/go/ledger/v1;ledgerv1b\x06proto3"
+
+var (
+	file_ledger_v1_ledger_proto_rawDescOnce sync.Once
+	file_ledger_v1_ledger_proto_rawDescData []byte
+)
+
+func file_ledger_v1_ledger_proto_rawDescGZIP() []byte {
+	file_ledger_v1_ledger_proto_rawDescOnce.Do(func() {
+		file_ledger_v1_ledger_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ledger_v1_ledger_proto_rawDesc), len(file_ledger_v1_ledger_proto_rawDesc)))
+	})
+	return file_ledger_v1_ledger_proto_rawDescData
+}
+
+var file_ledger_v1_ledger_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ledger_v1_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_ledger_v1_ledger_proto_goTypes = []any{
+	(AccountKind)(0),             // 0: ledger.v1.AccountKind
+	(*Account)(nil),              // 1: ledger.v1.Account
+	(*ListAccountsRequest)(nil),  // 2: ledger.v1.ListAccountsRequest
+	(*ListAccountsResponse)(nil), // 3: ledger.v1.ListAccountsResponse
+	(*GetCashFlowRequest)(nil),   // 4: ledger.v1.GetCashFlowRequest
+	(*CashFlow)(nil),             // 5: ledger.v1.CashFlow
+	(*GetCashFlowResponse)(nil),  // 6: ledger.v1.GetCashFlowResponse
+	(*v1.Money)(nil),             // 7: common.v1.Money
+	(*v1.DateRange)(nil),         // 8: common.v1.DateRange
+}
+var file_ledger_v1_ledger_proto_depIdxs = []int32{
+	0,  // 0: ledger.v1.Account.kind:type_name -> ledger.v1.AccountKind
+	7,  // 1: ledger.v1.Account.real_balance:type_name -> common.v1.Money
+	7,  // 2: ledger.v1.Account.projected_balance:type_name -> common.v1.Money
+	1,  // 3: ledger.v1.ListAccountsResponse.accounts:type_name -> ledger.v1.Account
+	8,  // 4: ledger.v1.GetCashFlowRequest.range:type_name -> common.v1.DateRange
+	7,  // 5: ledger.v1.CashFlow.income:type_name -> common.v1.Money
+	7,  // 6: ledger.v1.CashFlow.expense:type_name -> common.v1.Money
+	5,  // 7: ledger.v1.GetCashFlowResponse.flows:type_name -> ledger.v1.CashFlow
+	2,  // 8: ledger.v1.LedgerService.ListAccounts:input_type -> ledger.v1.ListAccountsRequest
+	4,  // 9: ledger.v1.LedgerService.GetCashFlow:input_type -> ledger.v1.GetCashFlowRequest
+	3,  // 10: ledger.v1.LedgerService.ListAccounts:output_type -> ledger.v1.ListAccountsResponse
+	6,  // 11: ledger.v1.LedgerService.GetCashFlow:output_type -> ledger.v1.GetCashFlowResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_ledger_v1_ledger_proto_init() }
+func file_ledger_v1_ledger_proto_init() {
+	if File_ledger_v1_ledger_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ledger_v1_ledger_proto_rawDesc), len(file_ledger_v1_ledger_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ledger_v1_ledger_proto_goTypes,
+		DependencyIndexes: file_ledger_v1_ledger_proto_depIdxs,
+		EnumInfos:         file_ledger_v1_ledger_proto_enumTypes,
+		MessageInfos:      file_ledger_v1_ledger_proto_msgTypes,
+	}.Build()
+	File_ledger_v1_ledger_proto = out.File
+	file_ledger_v1_ledger_proto_goTypes = nil
+	file_ledger_v1_ledger_proto_depIdxs = nil
+}
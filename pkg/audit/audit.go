@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/requestid"
+	"github.com/google/uuid"
+)
+
+// SystemActor is the actor of records created outside a user request (scheduled tasks, CLI...)
+const SystemActor = "system"
+
+// Record is an immutable audit entry describing who did what to which resource
+// Unlike operational logs, records are kept in a dedicated sink and are never sampled or redacted away
+type Record struct {
+	ID           uuid.UUID         `json:"id"`
+	OccurredAt   time.Time         `json:"occurred_at"`
+	Actor        string            `json:"actor"`         // The user ID acting, SystemActor or "anonymous"
+	Action       string            `json:"action"`        // What happened, as "<resource>.<verb>" (e.g., "account.archived")
+	ResourceType string            `json:"resource_type"` // The kind of resource changed (e.g., "account")
+	ResourceID   string            `json:"resource_id,omitempty"`
+	Before       json.RawMessage   `json:"before,omitempty"` // The resource state before the change, if it existed
+	After        json.RawMessage   `json:"after,omitempty"`  // The resource state after the change, if it still exists
+	RequestID    string            `json:"request_id,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// Entry is what callers provide, the Logger completes it with the ID, time, actor and request ID
+type Entry struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Actor        string // Actor overrides the authenticated user found in the context
+	Before       any    // Before is encoded as JSON, nil when the resource did not exist
+	After        any    // After is encoded as JSON, nil when the resource was deleted
+	Metadata     map[string]string
+}
+
+// Sink stores audit records, append-only
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+// Logger writes audit records to a Sink
+type Logger struct {
+	sink  Sink
+	clock clock.Clock
+}
+
+// NewLogger creates a new audit Logger
+func NewLogger(sink Sink, clock clock.Clock) *Logger {
+	return &Logger{sink: sink, clock: clock}
+}
+
+// Log writes an audit record for the entry
+// The actor is taken from the authenticated user in ctx unless the entry sets one
+func (l *Logger) Log(ctx context.Context, entry Entry) error {
+	record := Record{
+		ID:           uuid.New(),
+		OccurredAt:   l.clock.Now().UTC(),
+		Actor:        entry.Actor,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Metadata:     entry.Metadata,
+	}
+
+	if record.Actor == "" {
+		record.Actor = SystemActor
+		if user, ok := authctx.UserFromContext(ctx); ok {
+			record.Actor = user.UserID.String()
+			if user.ImpersonatedBy != "" {
+				record.Metadata = withImpersonation(entry.Metadata, user.ImpersonatedBy)
+			}
+		}
+	}
+	if id, ok := requestid.FromContext(ctx); ok {
+		record.RequestID = id
+	}
+
+	var err error
+	if record.Before, err = encode(entry.Before); err != nil {
+		return fmt.Errorf("failed to encode audit before state: %w", err)
+	}
+	if record.After, err = encode(entry.After); err != nil {
+		return fmt.Errorf("failed to encode audit after state: %w", err)
+	}
+
+	if err := l.sink.Write(ctx, record); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Record writes an audit record like Log, but only logs a failure instead of returning it
+// Used where the audited operation already succeeded and must not be reported as failed
+func (l *Logger) Record(ctx context.Context, entry Entry) {
+	if err := l.Log(ctx, entry); err != nil {
+		ctxlogger.GetLogger(ctx).Error("audit record lost",
+			slog.String("action", entry.Action),
+			slog.String("resource_id", entry.ResourceID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// withImpersonation copies the metadata adding the support member acting as the user, so the records tell the
+// changes of support apart from the changes of the user
+func withImpersonation(metadata map[string]string, impersonatedBy string) map[string]string {
+	copied := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	copied["impersonated_by"] = impersonatedBy
+	return copied
+}
+
+// encode marshals a resource state, keeping nil as an absent state
+func encode(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var _ Sink = (*FileSink)(nil)
+
+// FileSink appends audit records as JSON lines to a dedicated file, separate from the operational logs
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) the audit file in append-only mode
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends the record as a single JSON line and syncs it to disk
+func (s *FileSink) Write(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// Close closes the audit file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
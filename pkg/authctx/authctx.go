@@ -0,0 +1,84 @@
+package authctx
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// key is an unexported type used as the key for the authenticated user in the context
+// Using an unexported type prevents key collisions with other packages
+type key string
+
+// userKey is the specific key value used to store the authenticated user in the context
+const userKey key = "auth_user"
+
+// UserContext describes the authenticated principal on whose behalf the current operation runs
+// It is filled by the HTTP auth middleware, the gRPC auth interceptor or by background jobs
+// acting for a specific user
+type UserContext struct {
+	UserID uuid.UUID // UserID is the identifier of the authenticated user
+	Roles  []string  // Roles holds the roles granted to the user (e.g., "admin")
+	Locale string    // Locale is the preferred language tag of the user (e.g., "pt-BR")
+	Scope  Scope     // Scope narrows what the token of the user grants, the zero Scope granting everything
+	// EmailVerified tells whether the user confirmed they own their email
+	EmailVerified bool
+	// ImpersonatedBy names the support member acting as the user, empty when the user acts themselves
+	ImpersonatedBy string
+}
+
+// Scope narrows what an access token grants, for the tokens handed to read-only dashboards and integrations
+type Scope struct {
+	ReadOnly   bool        // ReadOnly grants the reads alone
+	AccountIDs []uuid.UUID // AccountIDs restricts the token to these accounts, every account of the user being granted when empty
+}
+
+// Restricted reports whether the scope grants less than everything
+func (s Scope) Restricted() bool {
+	return s.ReadOnly || len(s.AccountIDs) > 0
+}
+
+// AllowsAccount reports whether the scope grants the account
+func (s Scope) AllowsAccount(accountID uuid.UUID) bool {
+	return len(s.AccountIDs) == 0 || slices.Contains(s.AccountIDs, accountID)
+}
+
+// Within reports whether the scope grants nothing more than other, so a token can only hand out narrower ones
+func (s Scope) Within(other Scope) bool {
+	if other.ReadOnly && !s.ReadOnly {
+		return false
+	}
+	if len(other.AccountIDs) == 0 {
+		return true
+	}
+	if len(s.AccountIDs) == 0 {
+		return false
+	}
+	for _, accountID := range s.AccountIDs {
+		if !other.AllowsAccount(accountID) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasRole reports whether the user was granted the given role
+func (u UserContext) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+// WithUser returns a new context that carries the provided UserContext
+func WithUser(ctx context.Context, user UserContext) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext retrieves the UserContext from the provided context
+// The boolean result is false when the context carries no authenticated user
+func UserFromContext(ctx context.Context) (UserContext, bool) {
+	user, ok := ctx.Value(userKey).(UserContext)
+	if !ok || user.UserID == uuid.Nil {
+		return UserContext{}, false
+	}
+	return user, true
+}
@@ -0,0 +1,205 @@
+// Package boleto parses the digitable lines of boletos, the Brazilian payment slips, validating their check digits
+package boleto
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+var (
+	ErrInvalidLine         = errx.New(errx.CategoryValidation, "INVALID_BOLETO_LINE", "the boleto digitable line must have 47 digits, or 48 for utility and tax bills")
+	ErrInvalidCheckDigit   = errx.New(errx.CategoryValidation, "INVALID_BOLETO_CHECK_DIGIT", "the boleto digitable line has a wrong check digit, it was probably mistyped")
+	ErrUnsupportedCurrency = errx.New(errx.CategoryValidation, "UNSUPPORTED_BOLETO_CURRENCY", "only boletos in reais are supported")
+)
+
+const (
+	// Currency is the currency boleto amounts are expressed in
+	Currency = "BRL"
+
+	bankLineLength       = 47
+	collectionLineLength = 48
+
+	// realCurrencyCode identifies bank boletos in reais
+	realCurrencyCode = '9'
+	// dueFactorCycle is how many factors there are before they restart at minDueFactor (from 1000 to 9999)
+	dueFactorCycle = 9000
+	minDueFactor   = 1000
+)
+
+// dueFactorBase is the date of factor 1000, the factors restarting at 1000 on 2025-02-22 after reaching 9999
+var dueFactorBase = time.Date(2000, time.July, 3, 0, 0, 0, 0, time.UTC)
+
+// Kind is the kind of a boleto
+type Kind string
+
+const (
+	KindBank       Kind = "BANK"       // KindBank is a boleto issued by a bank, whose line has 47 digits
+	KindCollection Kind = "COLLECTION" // KindCollection is a utility or tax bill (boleto de arrecadação), whose line has 48 digits and starts with 8
+)
+
+// Boleto is what a digitable line encodes
+type Boleto struct {
+	Kind     Kind
+	Barcode  string     // Barcode is the 44 digits barcode the line represents
+	BankCode string     // BankCode is the 3 digits code of the issuing bank, empty for collection boletos
+	Amount   int64      // Amount in centavos, zero when the amount is left to the payer
+	DueDate  *time.Time // DueDate is nil for collection boletos and bank boletos without due date
+}
+
+// Parse validates a digitable line, which may be formatted with dots and spaces, and decodes its barcode
+// The due date factors restart every 9000 days, so the due date is the one closest to reference
+func Parse(line string, reference time.Time) (*Boleto, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == '.' || r == ' ' || r == '-':
+			return -1
+		}
+		return 'x'
+	}, line)
+	if strings.ContainsRune(digits, 'x') {
+		return nil, ErrInvalidLine
+	}
+
+	switch len(digits) {
+	case bankLineLength:
+		return parseBankLine(digits, reference)
+	case collectionLineLength:
+		if digits[0] != '8' {
+			return nil, ErrInvalidLine
+		}
+		return parseCollectionLine(digits)
+	}
+	return nil, ErrInvalidLine
+}
+
+// parseBankLine decodes a bank boleto line: 3 fields carrying the barcode from its 20th digit with their check digit,
+// the general check digit of the barcode, then the due date factor and the amount
+func parseBankLine(line string, reference time.Time) (*Boleto, error) {
+	fields := []struct{ data, checkDigit string }{
+		{line[0:9], line[9:10]},
+		{line[10:20], line[20:21]},
+		{line[21:31], line[31:32]},
+	}
+	for i, field := range fields {
+		if mod10(field.data) != field.checkDigit[0] {
+			return nil, ErrInvalidCheckDigit.With("field", i+1)
+		}
+	}
+
+	barcode := line[0:4] + line[32:33] + line[33:47] + line[4:9] + line[10:20] + line[21:31]
+	if bankCheckDigit(barcode[:4]+barcode[5:]) != barcode[4] {
+		return nil, ErrInvalidCheckDigit.With("field", 4)
+	}
+	if barcode[3] != realCurrencyCode {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	amount, _ := strconv.ParseInt(barcode[9:19], 10, 64)
+	boleto := &Boleto{
+		Kind:     KindBank,
+		Barcode:  barcode,
+		BankCode: barcode[0:3],
+		Amount:   amount,
+	}
+	factor, _ := strconv.Atoi(barcode[5:9])
+	if factor >= minDueFactor {
+		dueDate := dueDateOf(factor, reference)
+		boleto.DueDate = &dueDate
+	}
+	return boleto, nil
+}
+
+// parseCollectionLine decodes a collection boleto line: 4 blocks of 11 barcode digits followed by their check digit
+// The third digit tells whether the check digits are modulo 10 or 11 and whether the amount is the actual value
+func parseCollectionLine(line string) (*Boleto, error) {
+	checkDigit := mod10
+	switch line[2] {
+	case '6', '7':
+	case '8', '9':
+		checkDigit = collectionMod11
+	default:
+		return nil, ErrInvalidLine
+	}
+
+	var barcode strings.Builder
+	for i := range 4 {
+		block := line[i*12 : i*12+12]
+		if checkDigit(block[:11]) != block[11] {
+			return nil, ErrInvalidCheckDigit.With("field", i+1)
+		}
+		barcode.WriteString(block[:11])
+	}
+
+	code := barcode.String()
+	if checkDigit(code[:3]+code[4:]) != code[3] {
+		// The general check digit is the fourth digit of the first block
+		return nil, ErrInvalidCheckDigit.With("field", 1)
+	}
+
+	boleto := &Boleto{Kind: KindCollection, Barcode: code}
+	// 7 and 9 mean the amount field holds a reference quantity (e.g., a tax index) rather than reais
+	if line[2] == '6' || line[2] == '8' {
+		boleto.Amount, _ = strconv.ParseInt(code[4:15], 10, 64)
+	}
+	return boleto, nil
+}
+
+// dueDateOf returns the date of a due date factor closest to reference, among its 9000 days cycles
+func dueDateOf(factor int, reference time.Time) time.Time {
+	reference = time.Date(reference.Year(), reference.Month(), reference.Day(), 0, 0, 0, 0, time.UTC)
+	days := factor - minDueFactor
+	elapsed := reference.Sub(dueFactorBase).Hours() / 24
+	cycles := max(0, int(math.Round((elapsed-float64(days))/dueFactorCycle)))
+	return dueFactorBase.AddDate(0, 0, days+cycles*dueFactorCycle)
+}
+
+// mod10 computes the modulo 10 check digit: the digits are weighted 2 and 1 alternately from the right,
+// the digits of each product summed
+func mod10(digits string) byte {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		product := int(digits[i]-'0') * weight
+		sum += product/10 + product%10
+		weight = 3 - weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// weightedMod11 sums the digits weighted from 2 to 9 from the right, restarting at 2, returning the rest of the division by 11
+func weightedMod11(digits string) int {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	return sum % 11
+}
+
+// bankCheckDigit computes the general check digit of a bank boleto barcode, which is never 0
+func bankCheckDigit(digits string) byte {
+	digit := 11 - weightedMod11(digits)
+	if digit == 0 || digit >= 10 {
+		return '1'
+	}
+	return byte('0' + digit)
+}
+
+// collectionMod11 computes the modulo 11 check digit of collection boletos
+func collectionMod11(digits string) byte {
+	rest := weightedMod11(digits)
+	if rest <= 1 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
@@ -1,13 +1,93 @@
 package clock
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
+var (
+	_ Clock = SystemClock{}
+	_ Clock = (*SystemClock)(nil)
+	_ Clock = FixedClock{}
+	_ Clock = OffsetClock{}
+	_ Clock = (*ManualClock)(nil)
+)
+
+// Clock abstracts the current time so time-dependent code can be tested with a frozen or shifted clock
 type Clock interface {
 	Now() time.Time
 }
 
+// SystemClock reads the real wall clock
+// It has a value receiver, so both SystemClock{} and &SystemClock{} satisfy Clock
 type SystemClock struct{}
 
+// Now returns the current wall clock time
 func (sc SystemClock) Now() time.Time {
 	return time.Now()
 }
+
+// FixedClock always returns the same instant, used to freeze time in tests and reproducible runs
+type FixedClock struct {
+	Time time.Time
+}
+
+// NewFixedClock creates a FixedClock frozen at t
+func NewFixedClock(t time.Time) FixedClock {
+	return FixedClock{Time: t}
+}
+
+// Now returns the frozen instant
+func (fc FixedClock) Now() time.Time {
+	return fc.Time
+}
+
+// OffsetClock shifts another clock by a fixed duration (e.g., to simulate the next day or month)
+type OffsetClock struct {
+	Base   Clock
+	Offset time.Duration
+}
+
+// NewOffsetClock creates an OffsetClock reading base shifted by offset
+func NewOffsetClock(base Clock, offset time.Duration) OffsetClock {
+	return OffsetClock{Base: base, Offset: offset}
+}
+
+// Now returns the base clock time shifted by the offset
+func (oc OffsetClock) Now() time.Time {
+	return oc.Base.Now().Add(oc.Offset)
+}
+
+// ManualClock is a settable clock for tests, safe for concurrent use
+// It must be used through a pointer, so every holder observes Set and Advance
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a ManualClock starting at t
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns the current instant of the clock
+func (mc *ManualClock) Now() time.Time {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.now
+}
+
+// Set moves the clock to t
+func (mc *ManualClock) Set(t time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.now = t
+}
+
+// Advance moves the clock forward by d (or backwards when d is negative) and returns the new instant
+func (mc *ManualClock) Advance(d time.Duration) time.Time {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.now = mc.now.Add(d)
+	return mc.now
+}
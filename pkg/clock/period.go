@@ -0,0 +1,78 @@
+package clock
+
+import "time"
+
+// StartOfDayIn returns midnight of the day t falls on in loc
+func StartOfDayIn(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// EndOfDayIn returns the last nanosecond of the day t falls on in loc
+func EndOfDayIn(t time.Time, loc *time.Location) time.Time {
+	return StartOfDayIn(t, loc).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfMonthIn returns midnight of the first day of the month t falls on in loc
+func StartOfMonthIn(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+}
+
+// EndOfMonthIn returns the last nanosecond of the month t falls on in loc
+func EndOfMonthIn(t time.Time, loc *time.Location) time.Time {
+	return StartOfMonthIn(t, loc).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// MonthRangeIn returns the half-open range [start, end) of the month t falls on in loc,
+// the preferred form to filter dates without off-by-one-nanosecond comparisons
+func MonthRangeIn(t time.Time, loc *time.Location) (start, end time.Time) {
+	start = StartOfMonthIn(t, loc)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// AccountingPeriod tells when the financial month of a user starts (e.g., on payday), the zero value being the calendar month
+// A period is named after the month it starts in: starting on day 5, January 3 falls on the December period
+type AccountingPeriod struct {
+	StartDay         int // StartDay is the day of the month the period starts on, 1 to 28 so every month has it
+	StartBusinessDay int // StartBusinessDay is the nth weekday (Monday to Friday) the period starts on, it wins over StartDay
+}
+
+// IsCalendarMonth reports whether the period is the calendar month
+func (p AccountingPeriod) IsCalendarMonth() bool {
+	return p.StartBusinessDay == 0 && p.StartDay <= 1
+}
+
+// MonthRangeIn returns the half-open range [start, end) of the period named after year and month in loc
+func (p AccountingPeriod) MonthRangeIn(year int, month time.Month, loc *time.Location) (start, end time.Time) {
+	start = p.startIn(year, month, loc)
+	next := time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+	return start, p.startIn(next.Year(), next.Month(), loc)
+}
+
+// RangeIn returns the half-open range [start, end) of the period t falls on in loc
+func (p AccountingPeriod) RangeIn(t time.Time, loc *time.Location) (start, end time.Time) {
+	t = t.In(loc)
+	start, end = p.MonthRangeIn(t.Year(), t.Month(), loc)
+	if t.Before(start) {
+		previous := time.Date(t.Year(), t.Month()-1, 1, 0, 0, 0, 0, loc)
+		return p.MonthRangeIn(previous.Year(), previous.Month(), loc)
+	}
+	return start, end
+}
+
+// startIn returns midnight of the day the period named after year and month starts on in loc
+func (p AccountingPeriod) startIn(year int, month time.Month, loc *time.Location) time.Time {
+	if p.StartBusinessDay > 0 {
+		day := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		for weekdays := 0; ; day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				continue
+			}
+			if weekdays++; weekdays == p.StartBusinessDay {
+				return day
+			}
+		}
+	}
+	return time.Date(year, month, max(p.StartDay, 1), 0, 0, 0, 0, loc)
+}
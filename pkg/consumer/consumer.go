@@ -0,0 +1,454 @@
+// Package consumer reads the events of a consumer group, handing the records of each partition to its own worker
+// so partitions are processed in parallel while the records of a partition keep their order
+//
+// Records whose handler keeps failing are retried with backoff and then published to a dead-letter topic
+// (the source topic with DeadLetterSuffix appended) so one poisoned event does not block its partition.
+// Offsets are committed after processing, so a record may be delivered again after a crash or a rebalance:
+// handlers must be idempotent, which the services get by recording the processed events in an inbox table
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+)
+
+// Dead-letter headers, describing where the record came from and why it was dead-lettered
+const (
+	HeaderSourceTopic     = "x-dlq-source-topic"
+	HeaderSourcePartition = "x-dlq-source-partition"
+	HeaderSourceOffset    = "x-dlq-source-offset"
+	HeaderError           = "x-dlq-error"
+	HeaderAttempts        = "x-dlq-attempts"
+)
+
+// TopicPartition identifies a partition of a topic
+type TopicPartition struct {
+	Topic     string
+	Partition int32
+}
+
+// Record is a message read from a partition
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte // Key is the aggregate the record belongs to, records of the same key land on the same partition
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+// TopicPartition returns the partition the record was read from
+func (r Record) TopicPartition() TopicPartition {
+	return TopicPartition{Topic: r.Topic, Partition: r.Partition}
+}
+
+// Client is the group session of a broker client, which joins the group, heartbeats and fetches the
+// records of the partitions assigned to this member
+// Adapters for Kafka clients (e.g., franz-go) implement it and call Assigned and Revoked on the Consumer
+// from their rebalance callbacks
+type Client interface {
+	// Poll blocks until records are fetched or ctx is done
+	Poll(ctx context.Context) ([]Record, error)
+	// Commit stores, for the group, the next offset to read of each partition
+	Commit(ctx context.Context, offsets map[TopicPartition]int64) error
+}
+
+// Producer publishes records, used to dead-letter the records that could not be processed
+type Producer interface {
+	Produce(ctx context.Context, record Record) error
+}
+
+// Handler processes a record, returning an error to retry it or a Permanent error to dead-letter it right away
+type Handler func(ctx context.Context, record Record) error
+
+// Config tunes a Consumer, zero values falling back to the defaults
+type Config struct {
+	Group            string        // Group names the consumer group in logs and metrics
+	MaxAttempts      int           // MaxAttempts is how many times a record is handled before being dead-lettered, 5 by default
+	RetryBackoff     time.Duration // RetryBackoff is the wait before the first retry, doubling on every retry, 500ms by default
+	MaxRetryBackoff  time.Duration // MaxRetryBackoff caps the wait between retries, 30s by default
+	DeadLetterSuffix string        // DeadLetterSuffix is appended to the source topic to name the dead-letter topic, ".dlq" by default
+	QueueSize        int           // QueueSize is how many fetched records wait for each partition worker, 256 by default
+	CommitInterval   time.Duration // CommitInterval is how often the processed offsets are committed, 5s by default
+}
+
+// permanentError marks an error retrying can not fix (e.g., a malformed payload)
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, so the record is dead-lettered right away
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// Consumer dispatches the records polled from a Client to one worker per assigned partition
+type Consumer struct {
+	client     Client
+	deadLetter Producer
+	handler    Handler
+	cfg        Config
+	logger     *slog.Logger
+	records    metrics.Counter
+	retries    metrics.Counter
+
+	// ctx is the context of Run, the workers being derived from it
+	ctx context.Context
+
+	mu      sync.Mutex
+	workers map[TopicPartition]*worker
+	pending map[TopicPartition]int64 // pending holds the next offset to commit of each partition
+}
+
+// worker processes the records of one partition in order
+type worker struct {
+	records chan Record
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New creates a new Consumer, deadLetter receiving the records that could not be processed
+func New(client Client, deadLetter Producer, handler Handler, cfg Config, logger *slog.Logger, provider metrics.Provider) *Consumer {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 30 * time.Second
+	}
+	if cfg.DeadLetterSuffix == "" {
+		cfg.DeadLetterSuffix = ".dlq"
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 256
+	}
+	if cfg.CommitInterval <= 0 {
+		cfg.CommitInterval = 5 * time.Second
+	}
+
+	return &Consumer{
+		client:     client,
+		deadLetter: deadLetter,
+		handler:    handler,
+		cfg:        cfg,
+		logger:     logger.With(slog.String("component", "consumer"), slog.String("group", cfg.Group)),
+		records:    provider.Counter("consumer_records_total", "Records consumed, by group, topic and outcome (processed or dead_lettered)", "group", "topic", "outcome"),
+		retries:    provider.Counter("consumer_retries_total", "Records handled again after a failure, by group and topic", "group", "topic"),
+		workers:    make(map[TopicPartition]*worker),
+		pending:    make(map[TopicPartition]int64),
+	}
+}
+
+// Run polls and dispatches records until ctx is done, then stops the workers and commits what they processed
+func (c *Consumer) Run(ctx context.Context) error {
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+
+	c.logger.Info("consumer started")
+
+	commitDone := make(chan struct{})
+	go func() {
+		defer close(commitDone)
+		ticker := time.NewTicker(c.cfg.CommitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.commit(ctx, nil)
+			}
+		}
+	}()
+
+	var runErr error
+	for ctx.Err() == nil {
+		records, err := c.client.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			runErr = fmt.Errorf("failed to poll records: %w", err)
+			break
+		}
+		for _, record := range records {
+			c.dispatch(ctx, record)
+		}
+	}
+
+	<-commitDone
+	c.stopWorkers(nil)
+
+	// Use a fresh context: ctx is already cancelled when shutting down
+	commitCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c.commit(commitCtx, nil)
+
+	c.logger.Info("consumer stopped")
+	return runErr
+}
+
+// Assigned starts the workers of the partitions assigned to this member by a rebalance
+func (c *Consumer) Assigned(partitions []TopicPartition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tp := range partitions {
+		c.startWorkerLocked(tp)
+	}
+	c.logger.Info("partitions assigned", slog.Int("partitions", len(partitions)))
+}
+
+// Revoked stops the workers of the partitions taken away by a rebalance and commits what they processed,
+// so the next owner resumes right after the last processed record
+// The records still queued are left to the next owner
+func (c *Consumer) Revoked(ctx context.Context, partitions []TopicPartition) {
+	c.stopWorkers(partitions)
+	c.commit(ctx, partitions)
+
+	c.mu.Lock()
+	for _, tp := range partitions {
+		delete(c.pending, tp)
+	}
+	c.mu.Unlock()
+	c.logger.Info("partitions revoked", slog.Int("partitions", len(partitions)))
+}
+
+// dispatch queues the record on the worker of its partition, waiting while the queue is full
+func (c *Consumer) dispatch(ctx context.Context, record Record) {
+	c.mu.Lock()
+	w := c.startWorkerLocked(record.TopicPartition())
+	c.mu.Unlock()
+
+	select {
+	case w.records <- record:
+	case <-ctx.Done():
+	}
+}
+
+// startWorkerLocked returns the worker of a partition, starting it when the partition has none
+// The caller must hold mu
+func (c *Consumer) startWorkerLocked(tp TopicPartition) *worker {
+	if w, ok := c.workers[tp]; ok {
+		return w
+	}
+
+	parent := c.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	w := &worker{
+		records: make(chan Record, c.cfg.QueueSize),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	c.workers[tp] = w
+
+	log := c.logger.With(slog.String("topic", tp.Topic), slog.Int("partition", int(tp.Partition)))
+	go func() {
+		defer close(w.done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case record := <-w.records:
+				if !c.process(ctx, log, record) {
+					return
+				}
+				c.markProcessed(record)
+			}
+		}
+	}()
+	return w
+}
+
+// stopWorkers stops the workers of the given partitions, or every worker when partitions is nil,
+// and waits for them to return
+func (c *Consumer) stopWorkers(partitions []TopicPartition) {
+	c.mu.Lock()
+	stopped := make([]*worker, 0, len(c.workers))
+	if partitions == nil {
+		partitions = make([]TopicPartition, 0, len(c.workers))
+		for tp := range c.workers {
+			partitions = append(partitions, tp)
+		}
+	}
+	for _, tp := range partitions {
+		if w, ok := c.workers[tp]; ok {
+			w.cancel()
+			stopped = append(stopped, w)
+			delete(c.workers, tp)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, w := range stopped {
+		<-w.done
+	}
+}
+
+// process handles a record, retrying it with backoff and dead-lettering it once the attempts are exhausted
+// It returns false when ctx is done before the record was processed, which is then left uncommitted
+func (c *Consumer) process(ctx context.Context, log *slog.Logger, record Record) bool {
+	backoff := c.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.handle(ctx, record)
+		if err == nil {
+			c.records.Inc(c.cfg.Group, record.Topic, "processed")
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if IsPermanent(err) || attempt >= c.cfg.MaxAttempts {
+			return c.deadLetterRecord(ctx, log, record, err, attempt)
+		}
+
+		log.Warn("failed to handle record, retrying",
+			slog.Int64("offset", record.Offset),
+			slog.Int("attempt", attempt),
+			slog.String("error", err.Error()),
+		)
+		c.retries.Inc(c.cfg.Group, record.Topic)
+		if !sleep(ctx, backoff) {
+			return false
+		}
+		backoff = min(backoff*2, c.cfg.MaxRetryBackoff)
+	}
+}
+
+// handle runs the handler, turning a panic into an error so one record does not bring the consumer down
+func (c *Consumer) handle(ctx context.Context, record Record) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return c.handler(ctx, record)
+}
+
+// deadLetterRecord publishes the record to the dead-letter topic, retrying until it succeeds or ctx is done
+// since committing the record without it would lose the event
+func (c *Consumer) deadLetterRecord(ctx context.Context, log *slog.Logger, record Record, cause error, attempts int) bool {
+	headers := make(map[string]string, len(record.Headers)+5)
+	maps.Copy(headers, record.Headers)
+	headers[HeaderSourceTopic] = record.Topic
+	headers[HeaderSourcePartition] = strconv.Itoa(int(record.Partition))
+	headers[HeaderSourceOffset] = strconv.FormatInt(record.Offset, 10)
+	headers[HeaderError] = cause.Error()
+	headers[HeaderAttempts] = strconv.Itoa(attempts)
+
+	deadLettered := Record{
+		Topic:     record.Topic + c.cfg.DeadLetterSuffix,
+		Key:       record.Key,
+		Value:     record.Value,
+		Headers:   headers,
+		Timestamp: record.Timestamp,
+	}
+
+	backoff := c.cfg.RetryBackoff
+	for {
+		err := c.deadLetter.Produce(ctx, deadLettered)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		log.Error("failed to dead-letter record, retrying",
+			slog.Int64("offset", record.Offset),
+			slog.String("error", err.Error()),
+		)
+		if !sleep(ctx, backoff) {
+			return false
+		}
+		backoff = min(backoff*2, c.cfg.MaxRetryBackoff)
+	}
+
+	log.Error("record dead-lettered",
+		slog.Int64("offset", record.Offset),
+		slog.String("dead_letter_topic", deadLettered.Topic),
+		slog.Int("attempts", attempts),
+		slog.String("error", cause.Error()),
+	)
+	c.records.Inc(c.cfg.Group, record.Topic, "dead_lettered")
+	return true
+}
+
+// markProcessed moves the offset to commit of the record's partition past it
+func (c *Consumer) markProcessed(record Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[record.TopicPartition()] = record.Offset + 1
+}
+
+// commit commits the pending offsets of the given partitions, or of every partition when partitions is nil
+// Offsets that fail to commit stay pending for the next commit, unless a newer one was stored meanwhile
+func (c *Consumer) commit(ctx context.Context, partitions []TopicPartition) {
+	c.mu.Lock()
+	offsets := make(map[TopicPartition]int64)
+	if partitions == nil {
+		maps.Copy(offsets, c.pending)
+		clear(c.pending)
+	} else {
+		for _, tp := range partitions {
+			if offset, ok := c.pending[tp]; ok {
+				offsets[tp] = offset
+				delete(c.pending, tp)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if len(offsets) == 0 {
+		return
+	}
+	if err := c.client.Commit(ctx, offsets); err != nil {
+		c.logger.Error("failed to commit offsets", slog.Int("partitions", len(offsets)), slog.String("error", err.Error()))
+
+		c.mu.Lock()
+		for tp, offset := range offsets {
+			if _, ok := c.pending[tp]; !ok {
+				c.pending[tp] = offset
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// sleep waits for d, returning false when ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,110 @@
+// Package contracts defines the events the services exchange, so producers and consumers share
+// the same payloads instead of hand-rolling them
+//
+// Every event has a type (e.g., "account.archived") and a major version. Additive changes (a new optional
+// field) keep the version and consumers ignore the fields they do not know, any other change is a new version
+// with its own Go type and JSON Schema, which producers publish alongside the previous one until every
+// consumer reads it
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownEvent        = errx.New(errx.CategoryValidation, "UNKNOWN_EVENT", "the event type or version has no contract")
+	ErrInvalidEnvelope     = errx.New(errx.CategoryValidation, "INVALID_EVENT_ENVELOPE", "the event envelope is malformed")
+	ErrInvalidPayload      = errx.New(errx.CategoryValidation, "INVALID_EVENT_PAYLOAD", "the event payload does not match its schema")
+	ErrEventTypeMismatch   = errx.New(errx.CategoryValidation, "EVENT_TYPE_MISMATCH", "the event is not of the expected type")
+	ErrIncompatibleVersion = errx.New(errx.CategoryValidation, "INCOMPATIBLE_EVENT_VERSION", "the event version is not the one the consumer reads")
+)
+
+// Event is the payload of a versioned event
+type Event interface {
+	EventType() string // EventType is the "<resource>.<verb>" name of the event (e.g., "account.archived")
+	EventVersion() int // EventVersion is the major version of the payload, starting at 1
+}
+
+// Envelope wraps a payload with what consumers need to route and deduplicate it
+type Envelope struct {
+	ID         uuid.UUID       `json:"id"` // ID is unique per event, consumers use it to process each event once
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps the event in a new envelope, checking the payload matches the schema of its version
+func NewEnvelope(event Event, occurredAt time.Time) (Envelope, error) {
+	schema, err := lookupSchema(event.EventType(), event.EventVersion())
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal %s event: %w", event.EventType(), err)
+	}
+	if err := validatePayload(schema, data); err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:         uuid.New(),
+		Type:       event.EventType(),
+		Version:    event.EventVersion(),
+		OccurredAt: occurredAt.UTC(),
+		Data:       data,
+	}, nil
+}
+
+// Marshal wraps the event in a new envelope and encodes it as JSON
+func Marshal(event Event, occurredAt time.Time) ([]byte, error) {
+	envelope, err := NewEnvelope(event, occurredAt)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope)
+}
+
+// Unmarshal decodes an envelope, checking it carries a known event whose payload matches its schema
+// Consumers then switch on Type and Version and decode the payload with Decode
+func Unmarshal(data []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return Envelope{}, ErrInvalidEnvelope.With("reason", err.Error())
+	}
+	if envelope.ID == uuid.Nil || envelope.Type == "" || envelope.Version < 1 || envelope.OccurredAt.IsZero() {
+		return Envelope{}, ErrInvalidEnvelope.With("reason", "id, type, version and occurred_at are required")
+	}
+
+	schema, err := lookupSchema(envelope.Type, envelope.Version)
+	if err != nil {
+		return Envelope{}, err
+	}
+	if err := validatePayload(schema, envelope.Data); err != nil {
+		return Envelope{}, err
+	}
+	return envelope, nil
+}
+
+// Decode decodes the payload into event, which must be of the type and version of the envelope
+func (e Envelope) Decode(event Event) error {
+	if e.Type != event.EventType() {
+		return ErrEventTypeMismatch.With("type", e.Type).With("expected", event.EventType())
+	}
+	if e.Version != event.EventVersion() {
+		return ErrIncompatibleVersion.With("version", e.Version).With("expected", event.EventVersion())
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(e.Data)).Decode(event); err != nil {
+		return ErrInvalidPayload.With("type", e.Type).With("reason", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package contracts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types, the resource and the verb of what happened
+const (
+	TypeUserRegistered             = "user.registered"
+	TypeEmailVerificationRequested = "user.email_verification_requested"
+	TypeTransactionCreated         = "transaction.created"
+	TypeAccountArchived            = "account.archived"
+
+	TypeAccountDeletionScheduled = "account.deletion_scheduled"
+	TypeAccountDeletionCancelled = "account.deletion_cancelled"
+	TypeAccountDeleted           = "account.deleted"
+)
+
+var (
+	_ Event = UserRegisteredV1{}
+	_ Event = EmailVerificationRequestedV1{}
+	_ Event = TransactionCreatedV1{}
+	_ Event = AccountArchivedV1{}
+	_ Event = AccountDeletionScheduledV1{}
+	_ Event = AccountDeletionCancelledV1{}
+	_ Event = AccountDeletedV1{}
+)
+
+// UserRegisteredV1 is published by the identity service once a user signed up
+type UserRegisteredV1 struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// EventType returns TypeUserRegistered
+func (UserRegisteredV1) EventType() string { return TypeUserRegistered }
+
+// EventVersion returns 1
+func (UserRegisteredV1) EventVersion() int { return 1 }
+
+// EmailVerificationRequestedV1 is published by the identity service when a user asked for the link confirming they own
+// their email, for the mailer to send it. The token is the credential of the link until it expires
+type EmailVerificationRequestedV1 struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EventType returns TypeEmailVerificationRequested
+func (EmailVerificationRequestedV1) EventType() string { return TypeEmailVerificationRequested }
+
+// EventVersion returns 1
+func (EmailVerificationRequestedV1) EventVersion() int { return 1 }
+
+// TransactionCreatedV1 is published by the ledger once a transaction was added to an account
+type TransactionCreatedV1 struct {
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	AccountID     uuid.UUID  `json:"account_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Type          string     `json:"type"`   // Type is INCOME, EXPENSE or ADJUSTMENT
+	Amount        int64      `json:"amount"` // Amount in minor units of the currency
+	Currency      string     `json:"currency"`
+	DueDate       time.Time  `json:"due_date"`
+	PaidAt        *time.Time `json:"paid_at"`     // PaidAt is null while the transaction is unpaid
+	CategoryID    *uuid.UUID `json:"category_id"` // CategoryID is null for uncategorized transactions
+}
+
+// EventType returns TypeTransactionCreated
+func (TransactionCreatedV1) EventType() string { return TypeTransactionCreated }
+
+// EventVersion returns 1
+func (TransactionCreatedV1) EventVersion() int { return 1 }
+
+// AccountArchivedV1 is published by the ledger once an account was archived
+type AccountArchivedV1 struct {
+	AccountID  uuid.UUID `json:"account_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// EventType returns TypeAccountArchived
+func (AccountArchivedV1) EventType() string { return TypeAccountArchived }
+
+// EventVersion returns 1
+func (AccountArchivedV1) EventVersion() int { return 1 }
+
+// AccountDeletionScheduledV1 is published by the ledger once the user asked to delete an account,
+// which is deleted for good at DeleteAfter unless the deletion is cancelled before
+type AccountDeletionScheduledV1 struct {
+	AccountID   uuid.UUID `json:"account_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// EventType returns TypeAccountDeletionScheduled
+func (AccountDeletionScheduledV1) EventType() string { return TypeAccountDeletionScheduled }
+
+// EventVersion returns 1
+func (AccountDeletionScheduledV1) EventVersion() int { return 1 }
+
+// AccountDeletionCancelledV1 is published by the ledger once the user cancelled the deletion of an account
+type AccountDeletionCancelledV1 struct {
+	AccountID   uuid.UUID `json:"account_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+// EventType returns TypeAccountDeletionCancelled
+func (AccountDeletionCancelledV1) EventType() string { return TypeAccountDeletionCancelled }
+
+// EventVersion returns 1
+func (AccountDeletionCancelledV1) EventVersion() int { return 1 }
+
+// AccountDeletedV1 is published by the ledger once an account and its transactions were deleted for good
+type AccountDeletedV1 struct {
+	AccountID uuid.UUID `json:"account_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// EventType returns TypeAccountDeleted
+func (AccountDeletedV1) EventType() string { return TypeAccountDeleted }
+
+// EventVersion returns 1
+func (AccountDeletedV1) EventVersion() int { return 1 }
@@ -0,0 +1,238 @@
+package contracts
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// schemaFiles holds a JSON Schema per event version, named "<type>.v<version>.json"
+// Only the keywords the contracts use are supported: type, format (uuid, date-time), enum, properties and required
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// schemas are the parsed schemaFiles, keyed by file name without extension
+var schemas = mustLoadSchemas()
+
+// schema is the supported subset of a JSON Schema
+type schema struct {
+	Types      []string           `json:"-"` // Types lists the allowed JSON types, "type" being either a string or a list
+	Format     string             `json:"format"`
+	Enum       []string           `json:"enum"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+}
+
+// UnmarshalJSON reads "type" as a string or a list of strings
+func (s *schema) UnmarshalJSON(data []byte) error {
+	type plain schema
+	var raw struct {
+		plain
+		Type json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = schema(raw.plain)
+
+	if len(raw.Type) == 0 {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(raw.Type, &single); err == nil {
+		s.Types = []string{single}
+		return nil
+	}
+	return json.Unmarshal(raw.Type, &s.Types)
+}
+
+// Schema returns the JSON Schema of an event version, to publish the contracts to other tools
+func Schema(eventType string, version int) ([]byte, bool) {
+	data, err := schemaFiles.ReadFile(path.Join("schemas", schemaName(eventType, version)+".json"))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// CheckCompatibility checks next can replace previous as the schema of the same event version:
+// the properties of previous are kept with the same types and formats, the enums keep their values,
+// and the properties added are optional, since the events published before were written without them
+// The errors of every violation are joined, nil meaning next is compatible
+func CheckCompatibility(previous, next []byte) error {
+	var prev, nxt schema
+	if err := json.Unmarshal(previous, &prev); err != nil {
+		return fmt.Errorf("failed to parse previous schema: %w", err)
+	}
+	if err := json.Unmarshal(next, &nxt); err != nil {
+		return fmt.Errorf("failed to parse next schema: %w", err)
+	}
+	return errors.Join(compareSchemas("$", &prev, &nxt)...)
+}
+
+// compareSchemas lists the breaking changes between two versions of a schema node
+func compareSchemas(at string, prev, next *schema) []error {
+	var errs []error
+	if !sameElements(prev.Types, next.Types) {
+		errs = append(errs, fmt.Errorf("%s: type changed from %v to %v", at, prev.Types, next.Types))
+	}
+	if prev.Format != next.Format {
+		errs = append(errs, fmt.Errorf("%s: format changed from %q to %q", at, prev.Format, next.Format))
+	}
+	for _, value := range prev.Enum {
+		if !slices.Contains(next.Enum, value) {
+			errs = append(errs, fmt.Errorf("%s: enum value %q removed", at, value))
+		}
+	}
+
+	for name, prevProperty := range prev.Properties {
+		nextProperty, ok := next.Properties[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s.%s: property removed", at, name))
+			continue
+		}
+		errs = append(errs, compareSchemas(at+"."+name, prevProperty, nextProperty)...)
+	}
+	for _, name := range next.Required {
+		if !slices.Contains(prev.Required, name) {
+			errs = append(errs, fmt.Errorf("%s.%s: property became required", at, name))
+		}
+	}
+	return errs
+}
+
+// lookupSchema returns the schema of an event version
+func lookupSchema(eventType string, version int) (*schema, error) {
+	s, ok := schemas[schemaName(eventType, version)]
+	if !ok {
+		return nil, ErrUnknownEvent.With("type", eventType).With("version", version)
+	}
+	return s, nil
+}
+
+// validatePayload checks the JSON payload matches the schema, unknown properties being allowed
+func validatePayload(s *schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return ErrInvalidPayload.With("reason", err.Error())
+	}
+	if err := validateValue("$", s, value); err != nil {
+		return ErrInvalidPayload.With("reason", err.Error())
+	}
+	return nil
+}
+
+// validateValue checks a decoded JSON value against a schema node
+func validateValue(at string, s *schema, value any) error {
+	if len(s.Types) > 0 && !slices.Contains(s.Types, jsonType(value)) {
+		// Integers are numbers as well
+		if !(jsonType(value) == "integer" && slices.Contains(s.Types, "number")) {
+			return fmt.Errorf("%s: expected %s, got %s", at, strings.Join(s.Types, " or "), jsonType(value))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if err := validateFormat(s.Format, v); err != nil {
+			return fmt.Errorf("%s: %w", at, err)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+			return fmt.Errorf("%s: %q is not one of %v", at, v, s.Enum)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s.%s: required property missing", at, name)
+			}
+		}
+		for name, property := range s.Properties {
+			if propertyValue, ok := v[name]; ok {
+				if err := validateValue(at+"."+name, property, propertyValue); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateFormat checks a string against the supported formats, unknown formats being accepted
+func validateFormat(format, value string) error {
+	switch format {
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			return fmt.Errorf("%q is not a uuid", value)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return fmt.Errorf("%q is not a date-time", value)
+		}
+	}
+	return nil
+}
+
+// jsonType returns the JSON Schema type of a value decoded with UseNumber
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// mustLoadSchemas parses the embedded schemas, a malformed one being a programming error
+func mustLoadSchemas() map[string]*schema {
+	entries, err := fs.ReadDir(schemaFiles, "schemas")
+	if err != nil {
+		panic(fmt.Sprintf("contracts: failed to read schemas: %v", err))
+	}
+
+	loaded := make(map[string]*schema, len(entries))
+	for _, entry := range entries {
+		data, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("contracts: failed to read schema %s: %v", entry.Name(), err))
+		}
+		var s schema
+		if err := json.Unmarshal(data, &s); err != nil {
+			panic(fmt.Sprintf("contracts: failed to parse schema %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = &s
+	}
+	return loaded
+}
+
+// schemaName names the schema of an event version (e.g., "account.archived.v1")
+func schemaName(eventType string, version int) string {
+	return fmt.Sprintf("%s.v%d", eventType, version)
+}
+
+// sameElements reports whether both lists hold the same values, in any order
+func sameElements(a, b []string) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(a, func(v string) bool { return !slices.Contains(b, v) })
+}
@@ -0,0 +1,64 @@
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/requestid"
+	"github.com/google/uuid"
+)
+
+// ErrorReporter sends server errors (5xx responses, unhandled errors, panics) to an error tracking service
+// Report must not block the caller, implementations deliver in the background
+type ErrorReporter interface {
+	Report(ctx context.Context, err error, tags map[string]string)
+}
+
+// Tags commonly attached to reports
+const (
+	TagHTTPMethod = "http.method"
+	TagHTTPRoute  = "http.route"
+	TagHTTPStatus = "http.status"
+	TagGRPCMethod = "grpc.method"
+	TagGRPCCode   = "grpc.code"
+	TagStack      = "stack" // TagStack holds the goroutine stack of a recovered panic
+)
+
+// Event is a reported error with the request and user context found when it happened
+type Event struct {
+	ID        uuid.UUID
+	Timestamp time.Time
+	Type      string // Type is the Go type of the error (e.g., "*errors.errorString")
+	Message   string
+	RequestID string
+	UserID    string
+	Tags      map[string]string
+}
+
+// NewEvent builds the Event of err, attaching the request ID and authenticated user found in ctx
+func NewEvent(ctx context.Context, err error, tags map[string]string, now time.Time) Event {
+	event := Event{
+		ID:        uuid.New(),
+		Timestamp: now.UTC(),
+		Type:      fmt.Sprintf("%T", err),
+		Message:   err.Error(),
+		Tags:      tags,
+	}
+	if id, ok := requestid.FromContext(ctx); ok {
+		event.RequestID = id
+	}
+	if user, ok := authctx.UserFromContext(ctx); ok {
+		event.UserID = user.UserID.String()
+	}
+	return event
+}
+
+var _ ErrorReporter = Nop{}
+
+// Nop is an ErrorReporter discarding every report, used when no error tracking service is configured
+type Nop struct{}
+
+// Report does nothing
+func (Nop) Report(context.Context, error, map[string]string) {}
@@ -0,0 +1,200 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+)
+
+var _ ErrorReporter = (*SentryReporter)(nil)
+
+const (
+	sentryClient    = "fintrack-errreport/1.0"
+	sentryQueueSize = 100
+)
+
+// SentryReporter sends events to Sentry (or any Sentry-compatible service) through its envelope HTTP API
+// Events are queued and delivered by Run, a full queue drops new events instead of slowing requests down
+type SentryReporter struct {
+	endpoint    string
+	auth        string
+	dsn         string
+	environment string
+	release     string
+	client      *http.Client
+	clock       clock.Clock
+	queue       chan Event
+	logger      *slog.Logger
+}
+
+// NewSentryReporter creates a SentryReporter from a DSN (e.g., "https://<key>@o0.ingest.sentry.io/<project>")
+func NewSentryReporter(dsn, environment, release string, timeout time.Duration, logger *slog.Logger, clock clock.Clock) (*SentryReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sentry dsn: %w", err)
+	}
+
+	publicKey := parsed.User.Username()
+	projectID := strings.Trim(parsed.Path, "/")
+	if publicKey == "" || projectID == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid sentry dsn: expected <scheme>://<key>@<host>/<project>")
+	}
+
+	return &SentryReporter{
+		endpoint:    fmt.Sprintf("%s://%s/api/%s/envelope/", parsed.Scheme, parsed.Host, projectID),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", publicKey, sentryClient),
+		dsn:         dsn,
+		environment: environment,
+		release:     release,
+		client:      &http.Client{Timeout: timeout},
+		clock:       clock,
+		queue:       make(chan Event, sentryQueueSize),
+		logger:      logger,
+	}, nil
+}
+
+// Report queues the error for delivery
+func (r *SentryReporter) Report(ctx context.Context, err error, tags map[string]string) {
+	event := NewEvent(ctx, err, maps.Clone(tags), r.clock.Now())
+	select {
+	case r.queue <- event:
+	default:
+		ctxlogger.GetLogger(ctx).Warn("error report dropped, sentry queue is full", slog.String("event_id", event.ID.String()))
+	}
+}
+
+// Run delivers the queued events until ctx is canceled, then flushes the events still queued
+func (r *SentryReporter) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-r.queue:
+			r.deliver(ctx, event)
+		case <-ctx.Done():
+			r.flush()
+			return
+		}
+	}
+}
+
+// flush delivers the events left in the queue at shutdown, bounded by the client timeout
+func (r *SentryReporter) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.client.Timeout)
+	defer cancel()
+
+	for {
+		select {
+		case event := <-r.queue:
+			r.deliver(ctx, event)
+		default:
+			return
+		}
+	}
+}
+
+func (r *SentryReporter) deliver(ctx context.Context, event Event) {
+	if err := r.send(ctx, event); err != nil {
+		r.logger.Error("failed to deliver error report",
+			slog.String("event_id", event.ID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// sentryEvent is the subset of the Sentry event payload filled from an Event
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Exception   sentryExceptions  `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+	User        *sentryUser       `json:"user,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+// send posts the event as an envelope holding a single event item
+func (r *SentryReporter) send(ctx context.Context, event Event) error {
+	eventID := strings.ReplaceAll(event.ID.String(), "-", "")
+
+	payload := sentryEvent{
+		EventID:     eventID,
+		Timestamp:   event.Timestamp,
+		Level:       "error",
+		Platform:    "go",
+		Environment: r.environment,
+		Release:     r.release,
+		Exception:   sentryExceptions{Values: []sentryException{{Type: event.Type, Value: event.Message}}},
+		Tags:        make(map[string]string, len(event.Tags)+1),
+		Extra:       make(map[string]string),
+	}
+	for k, v := range event.Tags {
+		// Stacks are too long for a tag, Sentry shows them as extra data
+		if k == TagStack {
+			payload.Extra[k] = v
+			continue
+		}
+		payload.Tags[k] = v
+	}
+	if event.RequestID != "" {
+		payload.Tags["request_id"] = event.RequestID
+	}
+	if event.UserID != "" {
+		payload.User = &sentryUser{ID: event.UserID}
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	envelope := []any{
+		map[string]any{"event_id": eventID, "dsn": r.dsn, "sent_at": r.clock.Now().UTC()},
+		map[string]string{"type": "event"},
+		payload,
+	}
+	for _, line := range envelope {
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("failed to encode sentry envelope: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create sentry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sentry envelope: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sentry rejected the envelope with status %d", resp.StatusCode)
+	}
+	return nil
+}
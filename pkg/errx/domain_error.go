@@ -0,0 +1,71 @@
+package errx
+
+import (
+	"errors"
+	"maps"
+)
+
+// Category groups domain errors by the kind of failure they represent
+// Transport layers (HTTP, gRPC) map a category to their own status codes, so
+// modules never need to know about status codes themselves
+type Category string
+
+const (
+	CategoryNotFound        Category = "not_found"       // The requested resource does not exist (or is not visible to the caller)
+	CategoryConflict        Category = "conflict"        // The operation conflicts with the current state of the resource
+	CategoryValidation      Category = "validation"      // The input violates a business rule
+	CategoryForbidden       Category = "forbidden"       // The operation is not allowed on the resource
+	CategoryUnauthenticated Category = "unauthenticated" // The caller could not be authenticated
+	CategoryUnavailable     Category = "unavailable"     // A dependency is temporarily unavailable
+)
+
+// DomainError is the typed error returned by the domain and application layers
+// It carries a stable, machine-readable code, a category used for transport mapping
+// and optional metadata (IDs, limits...) that is exposed to clients as error details
+type DomainError struct {
+	Code     string         // A stable, machine-readable error code (e.g., "ACCOUNT_ARCHIVED")
+	Category Category       // The kind of failure, used to pick the HTTP/gRPC status
+	Message  string         // A human-readable message intended for the developer consuming the API
+	Metadata map[string]any // Optional context about the failure (e.g., {"account_id": "..."})
+}
+
+// New creates a new DomainError without metadata, usually declared as a package-level sentinel
+func New(category Category, code, message string) *DomainError {
+	return &DomainError{
+		Code:     code,
+		Category: category,
+		Message:  message,
+	}
+}
+
+// Error implements the error interface for DomainError
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is a DomainError with the same code
+// This keeps errors.Is working against the sentinels even after metadata has been attached
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && e.Code == t.Code
+}
+
+// With returns a copy of the error with the given metadata entry attached
+// The receiver is never modified, so it is safe to call on package-level sentinels
+func (e *DomainError) With(key string, value any) *DomainError {
+	cp := *e
+	cp.Metadata = make(map[string]any, len(e.Metadata)+1)
+	maps.Copy(cp.Metadata, e.Metadata)
+	cp.Metadata[key] = value
+	return &cp
+}
+
+// AsDomainError finds the first DomainError in err's chain
+// The boolean result is false when the chain carries no DomainError
+func AsDomainError(err error) (*DomainError, bool) {
+	var domainErr *DomainError
+	if !errors.As(err, &domainErr) {
+		return nil, false
+	}
+	return domainErr, true
+}
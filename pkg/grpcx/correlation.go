@@ -0,0 +1,90 @@
+package grpcx
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// UserIDMetadataKey is the gRPC metadata key carrying the ID of the user the call is made for
+	// It only correlates the logs of the services, the called service authenticates the user on its own
+	UserIDMetadataKey = "x-user-id"
+
+	// LocaleMetadataKey is the gRPC metadata key carrying the preferred language tag of the user (e.g., "pt-BR")
+	LocaleMetadataKey = "accept-language"
+)
+
+// CorrelationUnaryClientInterceptor forwards the request ID, user ID and locale of the context as outgoing metadata,
+// so the called service logs under the same correlation IDs and answers in the language of the user
+// Outside of an authenticated request, the user ID and locale received by the calling service are forwarded as is
+func CorrelationUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var pairs []string
+		if id, ok := requestid.FromContext(ctx); ok {
+			pairs = append(pairs, requestid.MetadataKey, id)
+		}
+
+		userID, locale := "", ""
+		if user, ok := authctx.UserFromContext(ctx); ok {
+			userID, locale = user.UserID.String(), user.Locale
+		} else if md, ok := metadata.FromIncomingContext(ctx); ok {
+			userID, locale = firstValue(md, UserIDMetadataKey), firstValue(md, LocaleMetadataKey)
+		}
+		if userID != "" {
+			pairs = append(pairs, UserIDMetadataKey, userID)
+		}
+		if locale != "" {
+			pairs = append(pairs, LocaleMetadataKey, locale)
+		}
+
+		if len(pairs) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// CorrelationUnaryServerInterceptor reads the request ID from the incoming metadata (or generates one)
+// and injects it, together with a request-scoped logger carrying it and the user ID and locale of the caller,
+// into the handler context
+// The user ID is never trusted for authorization, it is only logged
+func CorrelationUnaryServerInterceptor(baseLogger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		id := firstValue(md, requestid.MetadataKey)
+		if id == "" {
+			id = requestid.New()
+		}
+
+		attrs := []any{
+			slog.String("request_id", id),
+			slog.String("grpc_method", info.FullMethod),
+		}
+		if userID := firstValue(md, UserIDMetadataKey); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if locale := firstValue(md, LocaleMetadataKey); locale != "" {
+			attrs = append(attrs, slog.String("locale", locale))
+		}
+		requestLogger := baseLogger.With(attrs...)
+
+		ctx = requestid.WithRequestID(ctx, id)
+		ctx = ctxlogger.SetLogger(ctx, requestLogger)
+		return handler(ctx, req)
+	}
+}
+
+// firstValue returns the first value of the metadata key, empty when the key is missing
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
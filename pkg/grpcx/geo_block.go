@@ -0,0 +1,55 @@
+package grpcx
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/ipfilter"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GeoBlockUnaryServerInterceptor rejects with codes.PermissionDenied the calls to the given methods made from a blocked
+// country, X-Forwarded-For being followed through the trusted proxies only
+// The resolver failing lets the call through, an unavailable GeoIP database must not lock every user out
+func GeoBlockUnaryServerInterceptor(block *ipfilter.GeoBlock, trustedProxies *ipfilter.Networks, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		guarded[m] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := guarded[info.FullMethod]; !ok || !block.Enabled() {
+			return handler(ctx, req)
+		}
+
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return handler(ctx, req)
+		}
+		remote, err := ipfilter.ParseRemoteAddr(p.Addr.String())
+		if err != nil {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		client := ipfilter.ClientAddr(remote, md.Get(forwardedForMetadataKey), trustedProxies)
+
+		country, blocked, err := block.Blocked(ctx, client)
+		if err != nil {
+			ctxlogger.GetLogger(ctx).Warn("geoip resolver failed, letting the call through", slog.String("error", err.Error()))
+			return handler(ctx, req)
+		}
+		if blocked {
+			ctxlogger.GetLogger(ctx).Warn("call from a blocked country",
+				slog.String("method", info.FullMethod),
+				slog.String("country", country),
+			)
+			return nil, status.Error(codes.PermissionDenied, "access from this country is not allowed")
+		}
+		return handler(ctx, req)
+	}
+}
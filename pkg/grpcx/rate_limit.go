@@ -0,0 +1,68 @@
+package grpcx
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterMetadataKey is the response header telling a rejected caller how many seconds to wait
+const RetryAfterMetadataKey = "retry-after"
+
+// forwardedForMetadataKey carries the address of the client when the call went through a proxy or load balancer
+const forwardedForMetadataKey = "x-forwarded-for"
+
+// RateLimitUnaryServerInterceptor rejects with codes.ResourceExhausted the calls of a client over the limit of
+// the method, methods without a limit being let through
+// The limiter failing lets the call through, an unavailable Redis must not take the service down with it
+func RateLimitUnaryServerInterceptor(limiter ratelimit.Limiter, limits map[string]ratelimit.Limit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		limit, ok := limits[info.FullMethod]
+		if !ok || !limit.Enabled() {
+			return handler(ctx, req)
+		}
+
+		result, err := limiter.Allow(ctx, "grpc:"+info.FullMethod+":"+clientAddr(ctx), limit)
+		if err != nil {
+			ctxlogger.GetLogger(ctx).Warn("rate limiter failed, letting the call through", slog.String("error", err.Error()))
+			return handler(ctx, req)
+		}
+		if !result.Allowed {
+			retryAfter := strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadataKey, retryAfter))
+			return nil, status.Error(codes.ResourceExhausted, "too many requests, try again later")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// clientAddr returns the IP of the client, preferring the first address forwarded by a proxy over the peer
+func clientAddr(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if forwarded := firstValue(md, forwardedForMetadataKey); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
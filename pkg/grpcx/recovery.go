@@ -0,0 +1,52 @@
+package grpcx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/Guizzs26/fintrack/pkg/errreport"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnaryServerInterceptor turns a handler panic into a codes.Internal status instead of crashing the server,
+// and reports panics and server errors (Internal, Unknown, DataLoss) to the ErrorReporter
+func RecoveryUnaryServerInterceptor(reporter errreport.ErrorReporter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			stack := string(debug.Stack())
+			panicErr := fmt.Errorf("panic: %v", r)
+			ctxlogger.GetLogger(ctx).Error("gRPC handler panicked",
+				slog.String("error", panicErr.Error()),
+				slog.String("stack", stack),
+			)
+			reporter.Report(ctx, panicErr, map[string]string{
+				errreport.TagGRPCMethod: info.FullMethod,
+				errreport.TagStack:      stack,
+			})
+
+			resp, err = nil, status.Error(codes.Internal, "internal server error")
+		}()
+
+		resp, err = handler(ctx, req)
+		if err != nil {
+			switch code := status.Code(err); code {
+			case codes.Internal, codes.Unknown, codes.DataLoss:
+				reporter.Report(ctx, err, map[string]string{
+					errreport.TagGRPCMethod: info.FullMethod,
+					errreport.TagGRPCCode:   code.String(),
+				})
+			}
+		}
+		return resp, err
+	}
+}
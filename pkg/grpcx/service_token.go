@@ -0,0 +1,58 @@
+package grpcx
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceTokenMetadataKey is the gRPC metadata key carrying the shared secret a service calls the service-only
+// methods of another with
+const ServiceTokenMetadataKey = "x-service-token"
+
+// WithServiceToken returns a copy of ctx sending the service token as outgoing metadata
+func WithServiceToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, ServiceTokenMetadataKey, token)
+}
+
+// ServiceTokenUnaryServerInterceptor rejects with codes.Unauthenticated the calls to the given methods not sending the
+// service token. An empty token rejects every call to them, so a service missing its secret fails closed
+func ServiceTokenUnaryServerInterceptor(token string, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		guarded[m] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := guarded[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		sent := firstValue(md, ServiceTokenMetadataKey)
+		if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid or missing service token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// UnexposedUnaryServerInterceptor rejects the calls to the given methods with codes.Unimplemented, as if the server did
+// not have them, for the listeners that must not serve the service-only methods of a service they register
+func UnexposedUnaryServerInterceptor(methods ...string) grpc.UnaryServerInterceptor {
+	hidden := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		hidden[m] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := hidden[info.FullMethod]; ok {
+			return nil, status.Errorf(codes.Unimplemented, "method %s not implemented", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
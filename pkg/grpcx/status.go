@@ -0,0 +1,96 @@
+package grpcx
+
+import (
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorInfoDomain identifies fintrack as the source of the ErrorInfo details attached to statuses
+const errorInfoDomain = "fintrack"
+
+// CodeFromCategory maps a domain error category to its gRPC status code
+func CodeFromCategory(category errx.Category) codes.Code {
+	switch category {
+	case errx.CategoryNotFound:
+		return codes.NotFound
+	case errx.CategoryConflict:
+		return codes.AlreadyExists
+	case errx.CategoryValidation:
+		return codes.InvalidArgument
+	case errx.CategoryForbidden:
+		return codes.PermissionDenied
+	case errx.CategoryUnauthenticated:
+		return codes.Unauthenticated
+	case errx.CategoryUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// StatusFromError converts an error into a gRPC status error
+// DomainErrors are mapped by category and carry their code and metadata as an ErrorInfo detail,
+// any other error becomes codes.Internal with fallbackMsg so internal details never leak to callers
+func StatusFromError(err error, fallbackMsg string) error {
+	domainErr, ok := errx.AsDomainError(err)
+	if !ok {
+		return status.Error(codes.Internal, fallbackMsg)
+	}
+
+	st := status.New(CodeFromCategory(domainErr.Category), domainErr.Message)
+
+	metadata := make(map[string]string, len(domainErr.Metadata))
+	for k, v := range domainErr.Metadata {
+		metadata[k] = fmt.Sprint(v)
+	}
+
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   domainErr.Code,
+		Domain:   errorInfoDomain,
+		Metadata: metadata,
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// FieldViolation describes a field of a request failing its validation (e.g., {"email", "must be a valid email"})
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// BadRequest returns an InvalidArgument status carrying the violations as a BadRequest detail, so clients can point
+// each error at its field
+func BadRequest(msg string, violations ...FieldViolation) error {
+	st := status.New(codes.InvalidArgument, msg)
+	detail := &errdetails.BadRequest{FieldViolations: make([]*errdetails.BadRequest_FieldViolation, 0, len(violations))}
+	for _, v := range violations {
+		detail.FieldViolations = append(detail.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	withDetails, err := st.WithDetails(detail)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// StatusWithReason returns a status carrying reason as an ErrorInfo detail, for the failures a handler reports
+// without a DomainError (e.g., answering every failed login alike)
+func StatusWithReason(code codes.Code, msg, reason string) error {
+	st := status.New(code, msg)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorInfoDomain})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,118 @@
+package grpcx
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+)
+
+// RequestValidator validates a request, returning the violations of its fields
+type RequestValidator func(req any) []FieldViolation
+
+// StringCheck checks the value of a string field, returning the description of its violation or "" when it is valid
+type StringCheck func(value string) string
+
+// FieldRule validates a field of a request of type T, returning its violation or nil
+type FieldRule[T any] func(req T) *FieldViolation
+
+// ValidationUnaryServerInterceptor rejects with codes.InvalidArgument the calls whose request breaks the rules of
+// the method, every violation being listed in a BadRequest detail. Methods without rules are let through
+func ValidationUnaryServerInterceptor(validators map[string]RequestValidator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		validate, ok := validators[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		if violations := validate(req); len(violations) > 0 {
+			return nil, BadRequest("invalid request", violations...)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Fields builds the validator of the requests of type T from the rules of their fields
+// (e.g., Fields(String("email", (*pb.LoginRequest).GetEmail, Required, Email))), a request of another type passing
+func Fields[T any](rules ...FieldRule[T]) RequestValidator {
+	return func(req any) []FieldViolation {
+		typed, ok := req.(T)
+		if !ok {
+			return nil
+		}
+		var violations []FieldViolation
+		for _, rule := range rules {
+			if violation := rule(typed); violation != nil {
+				violations = append(violations, *violation)
+			}
+		}
+		return violations
+	}
+}
+
+// String is the rule of a string field read by get, the first failing check being its violation
+func String[T any](field string, get func(T) string, checks ...StringCheck) FieldRule[T] {
+	return func(req T) *FieldViolation {
+		value := get(req)
+		for _, check := range checks {
+			if description := check(value); description != "" {
+				return &FieldViolation{Field: field, Description: description}
+			}
+		}
+		return nil
+	}
+}
+
+// Required rejects an empty value
+func Required(value string) string {
+	if value == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// NotBlank rejects a value that is empty once trimmed
+func NotBlank(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// Email rejects a value that is not a bare email address (e.g., "Ana <ana@mail.com>"), blanks around it being allowed
+func Email(value string) string {
+	value = strings.TrimSpace(value)
+	if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// UUID rejects a value that is not a UUID
+func UUID(value string) string {
+	if _, err := uuid.Parse(value); err != nil {
+		return "must be a UUID"
+	}
+	return ""
+}
+
+// Strings is the rule of a repeated string field read by get, holding at most maxItems values (0 for no limit) that
+// pass the checks. A failing value is reported by its index (e.g., "user_ids[2]")
+func Strings[T any](field string, get func(T) []string, maxItems int, checks ...StringCheck) FieldRule[T] {
+	return func(req T) *FieldViolation {
+		values := get(req)
+		if maxItems > 0 && len(values) > maxItems {
+			return &FieldViolation{Field: field, Description: fmt.Sprintf("must hold at most %d values", maxItems)}
+		}
+		for i, value := range values {
+			for _, check := range checks {
+				if description := check(value); description != "" {
+					return &FieldViolation{Field: fmt.Sprintf("%s[%d]", field, i), Description: description}
+				}
+			}
+		}
+		return nil
+	}
+}
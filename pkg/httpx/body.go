@@ -0,0 +1,338 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/validatorx"
+)
+
+// Tags of the problems found in a request body, reported like the validation tags
+const (
+	BodyTagSyntax       = "syntax"        // BodyTagSyntax is a body that is not valid JSON
+	BodyTagUnknownField = "unknown_field" // BodyTagUnknownField is a field the request does not have
+	BodyTagType         = "type"          // BodyTagType is a value of the wrong JSON type
+	BodyTagFloatAmount  = "float_amount"  // BodyTagFloatAmount is a floating-point number sent as an amount
+	BodyTagDateTime     = "datetime"      // BodyTagDateTime is a date that is not RFC 3339 with a timezone
+	BodyTagFormat       = "format"        // BodyTagFormat is a value its type can not parse (e.g., a malformed UUID)
+)
+
+// bodyMessages translates the problems of the request bodies, keyed by tag or, for BodyTagType, by the expected type
+var bodyMessages = i18n.Catalog{
+	i18n.English: {
+		BodyTagSyntax:       "the body is not valid JSON (near byte %d)",
+		BodyTagUnknownField: "the field is not accepted by this request",
+		BodyTagFloatAmount:  "amounts must be decimal strings (e.g., \"1234.56\") or whole minor units, not floating-point numbers",
+		BodyTagDateTime:     "must be an RFC 3339 date and time with a timezone (e.g., \"2025-01-31T14:30:00-03:00\")",
+		BodyTagFormat:       "the value is malformed",
+		"type_string":       "must be a string",
+		"type_number":       "must be a number",
+		"type_integer":      "must be a whole number",
+		"type_boolean":      "must be true or false",
+		"type_object":       "must be an object",
+		"type_list":         "must be a list",
+	},
+	i18n.Portuguese: {
+		BodyTagSyntax:       "o corpo não é um JSON válido (perto do byte %d)",
+		BodyTagUnknownField: "o campo não é aceito por esta requisição",
+		BodyTagFloatAmount:  "valores devem ser strings decimais (ex.: \"1234.56\") ou unidades menores inteiras, não números de ponto flutuante",
+		BodyTagDateTime:     "deve ser uma data e hora RFC 3339 com fuso horário (ex.: \"2025-01-31T14:30:00-03:00\")",
+		BodyTagFormat:       "o valor é inválido",
+		"type_string":       "deve ser uma string",
+		"type_number":       "deve ser um número",
+		"type_integer":      "deve ser um número inteiro",
+		"type_boolean":      "deve ser true ou false",
+		"type_object":       "deve ser um objeto",
+		"type_list":         "deve ser uma lista",
+	},
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// bodyProblem is a problem found in a request body, its message being translated when the error is rendered
+type bodyProblem struct {
+	field string
+	tag   string
+	key   string // key is the message of bodyMessages, the tag when empty
+	args  []any
+}
+
+// BodyError lists every problem of a malformed request body, field by field, so the client fixes them all at once
+// Errors holds the messages in the default language, use Localize to get them in the client's language
+type BodyError struct {
+	Errors []validatorx.FieldError
+
+	problems []bodyProblem
+	err      error // err is the error of the JSON decoder, when it found the problem
+}
+
+// newBodyError creates a BodyError from its problems
+func newBodyError(problems []bodyProblem, err error) *BodyError {
+	e := &BodyError{problems: problems, err: err}
+	e.Errors = e.Localize(i18n.Default)
+	return e
+}
+
+// Error implements the error interface for BodyError
+func (e *BodyError) Error() string {
+	return fmt.Sprintf("malformed request body with %d error(s)", len(e.problems))
+}
+
+// Unwrap returns the error of the JSON decoder, nil when the problems were found before decoding
+func (e *BodyError) Unwrap() error {
+	return e.err
+}
+
+// Localize returns the field errors with messages in the language
+func (e *BodyError) Localize(lang i18n.Lang) []validatorx.FieldError {
+	errs := make([]validatorx.FieldError, 0, len(e.problems))
+	for _, p := range e.problems {
+		key := p.key
+		if key == "" {
+			key = p.tag
+		}
+		errs = append(errs, validatorx.FieldError{
+			Field:   p.field,
+			Tag:     p.tag,
+			Message: bodyMessages.Format(lang, key, p.args...),
+		})
+	}
+	return errs
+}
+
+// decodeStrictJSON decodes the JSON body into target, rejecting the fields target does not have, the floating-point
+// numbers sent for strings and integers (the amounts are decimal strings or minor units) and the dates that are not
+// RFC 3339 with a timezone. Every problem is reported with its field in a *BodyError
+func decodeStrictJSON(data []byte, target any) error {
+	if !json.Valid(data) {
+		var syntaxErr *json.SyntaxError
+		var offset int64
+		if err := json.Unmarshal(data, new(any)); errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+		return newBodyError([]bodyProblem{{tag: BodyTagSyntax, args: []any{offset}}}, nil)
+	}
+
+	var problems []bodyProblem
+	checkJSON(data, reflect.TypeOf(target), "", false, &problems)
+	if len(problems) > 0 {
+		return newBodyError(problems, nil)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return newBodyError([]bodyProblem{{field: typeErr.Field, tag: BodyTagType, key: typeKey(typeErr.Type)}}, err)
+		}
+		return newBodyError([]bodyProblem{{tag: BodyTagFormat}}, err)
+	}
+	return nil
+}
+
+// checkJSON checks the raw JSON value against the type it is decoded into, appending its problems and the problems of
+// the values it contains. Values whose type decodes itself are checked by decoding them alone
+// A floating-point number is reported as an amount only when amount is set, the value being an amount field
+func checkJSON(raw json.RawMessage, t reflect.Type, field string, amount bool, problems *[]bodyProblem) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	raw = bytes.TrimSpace(raw)
+	if string(raw) == "null" {
+		return
+	}
+
+	switch {
+	case t == timeType:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagDateTime})
+		} else if _, err := time.Parse(time.RFC3339, s); err != nil {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagDateTime})
+		}
+		return
+	case reflect.PointerTo(t).Implements(jsonUnmarshalerType), reflect.PointerTo(t).Implements(textUnmarshalerType):
+		if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagFormat})
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_object"})
+			return
+		}
+		fields := jsonFields(t)
+		for _, name := range slices.Sorted(maps.Keys(object)) {
+			f, ok := lookupField(fields, name)
+			if !ok {
+				*problems = append(*problems, bodyProblem{field: joinField(field, name), tag: BodyTagUnknownField})
+				continue
+			}
+			if f.quoted {
+				continue
+			}
+			checkJSON(object[name], f.typ, joinField(field, f.name), isAmountField(f.name), problems)
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_object"})
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			checkJSON(object[key], t.Elem(), joinField(field, key), amount, problems)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return // Bytes are sent as base64 strings, which the decoder checks
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_list"})
+			return
+		}
+		for i, item := range items {
+			checkJSON(item, t.Elem(), fmt.Sprintf("%s[%d]", field, i), amount, problems)
+		}
+	case reflect.String:
+		switch {
+		case amount && isFloat(raw):
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagFloatAmount})
+		case raw[0] != '"':
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_string"})
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch {
+		case amount && isFloat(raw):
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagFloatAmount})
+		case !isNumber(raw) || isFloat(raw):
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_integer"})
+		}
+	case reflect.Float32, reflect.Float64:
+		if !isNumber(raw) {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_number"})
+		}
+	case reflect.Bool:
+		if string(raw) != "true" && string(raw) != "false" {
+			*problems = append(*problems, bodyProblem{field: field, tag: BodyTagType, key: "type_boolean"})
+		}
+	}
+}
+
+// jsonField is a field of a struct as encoding/json sees it
+type jsonField struct {
+	name   string
+	typ    reflect.Type
+	quoted bool // quoted is a field tagged with the string option, its value being a JSON string
+}
+
+// jsonFields returns the fields encoding/json decodes into t, the fields of its embedded structs included
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(ft)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, jsonField{
+			name:   name,
+			typ:    sf.Type,
+			quoted: slices.Contains(strings.Split(opts, ","), "string"),
+		})
+	}
+	return fields
+}
+
+// isAmountField reports whether the field holds an amount, as the Amount of money.Money and the amounts of the
+// requests (e.g., "amount" or "original_amount") are named
+func isAmountField(name string) bool {
+	return strings.Contains(strings.ToLower(name), "amount")
+}
+
+// lookupField finds the field of a JSON key, preferring an exact match but, as encoding/json, ignoring the case
+func lookupField(fields []jsonField, key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// typeKey is the message of a value that is not of the JSON type of t
+func typeKey(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "type_string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "type_integer"
+	case reflect.Float32, reflect.Float64:
+		return "type_number"
+	case reflect.Bool:
+		return "type_boolean"
+	case reflect.Slice, reflect.Array:
+		return "type_list"
+	default:
+		return "type_object"
+	}
+}
+
+// isNumber reports whether the raw JSON value is a number
+func isNumber(raw json.RawMessage) bool {
+	return len(raw) > 0 && (raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9'))
+}
+
+// isFloat reports whether the raw JSON value is a number with a fraction or an exponent (e.g., 12.5 or 1e3)
+func isFloat(raw json.RawMessage) bool {
+	return isNumber(raw) && bytes.ContainsAny(raw, ".eE")
+}
+
+// joinField appends a key to the path of a field (e.g., "splits[0].amount")
+func joinField(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
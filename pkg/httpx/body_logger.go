@@ -0,0 +1,82 @@
+package httpx
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/logger"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// HeaderDebugBodies asks, on a single request, for its bodies to be logged when the BodyLogHeader mode is on
+const HeaderDebugBodies = "X-Debug-Bodies"
+
+// BodyLogMode selects which requests have their bodies logged
+type BodyLogMode string
+
+const (
+	BodyLogOff    BodyLogMode = "off"    // BodyLogOff never logs bodies
+	BodyLogHeader BodyLogMode = "header" // BodyLogHeader logs the bodies of requests sent with the HeaderDebugBodies header
+	BodyLogAlways BodyLogMode = "always" // BodyLogAlways logs the bodies of every request
+)
+
+// BodyLoggerConfig configures the BodyLoggerMiddleware
+type BodyLoggerConfig struct {
+	Mode       BodyLogMode
+	MaxBytes   int      // MaxBytes caps each logged body, longer ones are truncated
+	RedactKeys []string // RedactKeys are the JSON keys whose values are stripped. Defaults to logger.DefaultRedactKeys if nil
+}
+
+// BodyLoggerMiddleware logs the sanitized request and response bodies, to troubleshoot client integrations
+// Only JSON bodies are logged, with their secrets redacted, other content is summarized by its size
+func BodyLoggerMiddleware(cfg BodyLoggerConfig) echo.MiddlewareFunc {
+	redactKeys := cfg.RedactKeys
+	if redactKeys == nil {
+		redactKeys = logger.DefaultRedactKeys
+	}
+
+	return middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: func(c echo.Context) bool {
+			switch cfg.Mode {
+			case BodyLogAlways:
+				return false
+			case BodyLogHeader:
+				return c.Request().Header.Get(HeaderDebugBodies) == ""
+			default:
+				return true
+			}
+		},
+		Handler: func(c echo.Context, reqBody, resBody []byte) {
+			ctx := c.Request().Context()
+			ctxlogger.GetLogger(ctx).LogAttrs(ctx, slog.LevelInfo, "HTTP_BODIES",
+				slog.String("method", c.Request().Method),
+				slog.String("uri", c.Request().RequestURI),
+				slog.Int("status", c.Response().Status),
+				slog.String("request_body", sanitizeBody(reqBody, c.Request().Header.Get(echo.HeaderContentType), redactKeys, cfg.MaxBytes)),
+				slog.String("response_body", sanitizeBody(resBody, c.Response().Header().Get(echo.HeaderContentType), redactKeys, cfg.MaxBytes)),
+			)
+		},
+	})
+}
+
+// sanitizeBody returns the redacted JSON body capped to maxBytes, or a summary for other content
+func sanitizeBody(body []byte, contentType string, redactKeys []string, maxBytes int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return fmt.Sprintf("[%d bytes of %q omitted]", len(body), contentType)
+	}
+
+	redacted, ok := logger.RedactJSON(body, redactKeys)
+	if !ok {
+		return fmt.Sprintf("[%d bytes of invalid JSON omitted]", len(body))
+	}
+	if maxBytes > 0 && len(redacted) > maxBytes {
+		return fmt.Sprintf("%s...[truncated %d bytes]", redacted[:maxBytes], len(redacted)-maxBytes)
+	}
+	return string(redacted)
+}
@@ -0,0 +1,74 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/ratelimit"
+	"github.com/labstack/echo/v4"
+)
+
+// CostLimitConfig configures the CostLimitMiddleware
+type CostLimitConfig struct {
+	Cost         func(c echo.Context) int      // Cost weighs the request (e.g., the months a report spans), 0 for the requests left alone
+	Concurrency  *ratelimit.ConcurrencyLimiter // Concurrency caps the costly requests each caller runs at once, nil for no cap
+	QueueTimeout time.Duration                 // QueueTimeout is how long a request waits for a slot before being rejected
+	Limiter      ratelimit.Limiter
+	Budget       ratelimit.Limit             // Budget is the cost each caller may spend over its window, Requests counting cost units
+	KeyFunc      func(c echo.Context) string // KeyFunc identifies the caller, the client IP by default
+}
+
+// CostLimitMiddleware protects the costly routes (e.g., reports and exports), apart from the limit of every request:
+// a caller runs a few of them at once, the next ones waiting in a queue, and spends a budget weighted by their cost,
+// so a single caller exporting years of history can not starve the others. Rejected requests are answered with 429
+// Too Many Requests, and the limiter failing lets the request through as for RateLimitMiddleware
+func CostLimitMiddleware(cfg CostLimitConfig) echo.MiddlewareFunc {
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(c echo.Context) string { return c.RealIP() }
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cost := cfg.Cost(c)
+			if cost <= 0 {
+				return next(c)
+			}
+			key := keyFunc(c)
+			ctx := c.Request().Context()
+
+			if cfg.Concurrency != nil {
+				queueCtx, cancel := context.WithTimeout(ctx, cfg.QueueTimeout)
+				release, err := cfg.Concurrency.Acquire(queueCtx, key)
+				cancel()
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return err
+					}
+					c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(max(cfg.QueueTimeout, time.Second).Seconds()))))
+					return SendAPIError(c, http.StatusTooManyRequests, NewAPIError("TOO_MANY_CONCURRENT_REQUESTS", "too many costly requests running at once, wait for them to finish", nil))
+				}
+				defer release()
+			}
+
+			if cfg.Limiter != nil && cfg.Budget.Enabled() {
+				result, err := cfg.Limiter.AllowN(ctx, "cost:"+key, cfg.Budget, cost)
+				if err != nil {
+					ctxlogger.GetLogger(ctx).Warn("cost limiter failed, letting the request through", slog.String("error", err.Error()))
+					return next(c)
+				}
+				if !result.Allowed {
+					c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+					return SendAPIError(c, http.StatusTooManyRequests, NewAPIError("COST_LIMITED", "too many costly requests, try again later or ask for a shorter period", nil))
+				}
+			}
+			return next(c)
+		}
+	}
+}
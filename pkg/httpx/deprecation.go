@@ -0,0 +1,79 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderLink links a deprecated route to its successor (RFC 8288)
+const HeaderLink = "Link"
+
+// deprecationKey stores the deprecation of the matched route in the echo context
+const deprecationKey = "httpx.deprecation"
+
+// Deprecation describes a deprecated route, announced to its clients through the Deprecation (RFC 9745),
+// Sunset (RFC 8594) and Link headers
+type Deprecation struct {
+	Since     time.Time // Since is when the route was deprecated, "true" being announced when zero
+	Sunset    time.Time // Sunset is the date the route stops being served, not announced when zero
+	Successor string    // Successor is the route replacing it (e.g., "/api/v1/me/preferences"), linked as its successor version
+}
+
+// Deprecated marks a route as deprecated, given as a route middleware (e.g., g.PUT("/old", h.old, httpx.Deprecated(...)))
+// The headers are set before the handler runs, so the error responses of the route announce the deprecation too
+func Deprecated(d Deprecation) echo.MiddlewareFunc {
+	deprecation := "true"
+	if !d.Since.IsZero() {
+		deprecation = "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	}
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var link string
+	if d.Successor != "" {
+		link = fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Successor)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set(HeaderDeprecation, deprecation)
+			if sunset != "" {
+				header.Set(HeaderSunset, sunset)
+			}
+			if link != "" {
+				header.Add(HeaderLink, link)
+			}
+			c.Set(deprecationKey, d)
+			return next(c)
+		}
+	}
+}
+
+// IsDeprecated reports whether the matched route was marked as deprecated
+func IsDeprecated(c echo.Context) bool {
+	_, ok := c.Get(deprecationKey).(Deprecation)
+	return ok
+}
+
+// DeprecationUsageMiddleware counts the requests to the deprecated routes by method and route, so the clients still
+// calling them can be followed before their sunset. Set on the echo instance, it sees the mark of every route
+func DeprecationUsageMiddleware(provider metrics.Provider) echo.MiddlewareFunc {
+	usage := provider.Counter("http_deprecated_requests_total", "Requests to deprecated routes, by method and route", "method", "route")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			if IsDeprecated(c) {
+				usage.Inc(c.Request().Method, c.Path())
+			}
+			return err
+		}
+	}
+}
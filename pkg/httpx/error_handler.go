@@ -0,0 +1,106 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Guizzs26/fintrack/pkg/errreport"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/validatorx"
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorHandler is the centralized error handler for the entire API
+// It intercepts any error returned from a handler, inspects its type, and
+// formats a standardized JSON error response using our APIError structure
+// Messages are translated to the language of the request by SendAPIError
+// Server errors (5xx and unhandled errors) are also sent to the ErrorReporter
+func ErrorHandler(reporter errreport.ErrorReporter) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		log := ctxlogger.GetLogger(c.Request().Context())
+		if c.Response().Committed {
+			return
+		}
+
+		report := func(status int) {
+			reporter.Report(c.Request().Context(), err, map[string]string{
+				errreport.TagHTTPMethod: c.Request().Method,
+				errreport.TagHTTPRoute:  c.Path(),
+				errreport.TagHTTPStatus: strconv.Itoa(status),
+			})
+		}
+
+		// 1. Handle custom validation errors from our validatorx package
+		var valErr validatorx.ValidationError
+		if errors.As(err, &valErr) {
+			errResp := NewAPIError(
+				"VALIDATION_ERROR",
+				"one or more fields failed validation",
+				valErr.Localize(Language(c)), // The 'Details' field will contain the slice of FieldError
+			)
+			SendAPIError(c, http.StatusBadRequest, errResp)
+			return
+		}
+
+		// Malformed bodies, rejected by the binder before validation, list their problems the same way
+		var bodyErr *BodyError
+		if errors.As(err, &bodyErr) {
+			errResp := NewAPIError("INVALID_BODY", "the request body is malformed", bodyErr.Localize(Language(c)))
+			SendAPIError(c, http.StatusBadRequest, errResp)
+			return
+		}
+
+		// 2. Handle the requests aborted on the way: the client went away, cancelling its context and the queries
+		// in flight, or a query ran past its timeout
+		if errors.Is(err, context.Canceled) {
+			log.Info("request cancelled by the client", slog.String("error", err.Error()))
+			SendAPIError(c, StatusClientClosedRequest, NewAPIError("CLIENT_CLOSED_REQUEST", "the request was cancelled by the client", nil))
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Warn("request timed out", slog.String("error", err.Error()))
+			report(http.StatusGatewayTimeout)
+			SendAPIError(c, http.StatusGatewayTimeout, NewAPIError("TIMEOUT", "the request took too long to complete", nil))
+			return
+		}
+
+		// 3. Handle typed domain errors from any module, mapping them by category
+		if domainErr, ok := errx.AsDomainError(err); ok {
+			if domainErr.Category == errx.CategoryUnavailable {
+				log.Error("dependency unavailable", slog.String("code", domainErr.Code), slog.String("error", err.Error()))
+			}
+			status := StatusFromCategory(domainErr.Category)
+			if status >= http.StatusInternalServerError {
+				report(status)
+			}
+			SendAPIError(c, status, NewAPIErrorFromDomain(domainErr))
+			return
+		}
+
+		// 4. Handle generic Echo HTTP errors
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			if httpErr.Code >= http.StatusInternalServerError {
+				report(httpErr.Code)
+			}
+			errResp := NewAPIError("HTTP_ERROR", fmt.Sprintf("%v", httpErr.Message), nil)
+			SendAPIError(c, httpErr.Code, errResp)
+			return
+		}
+
+		// 5. Fallback for any other unexpected error
+		log.Error("unhandled internal error", slog.String("error", err.Error()))
+		report(http.StatusInternalServerError)
+		errResp := NewAPIError(
+			"INTERNAL_SERVER_ERROR",
+			"An unexpected error occurred",
+			nil,
+		)
+		SendAPIError(c, http.StatusInternalServerError, errResp) // 500
+	}
+}
@@ -1,13 +1,25 @@
 package httpx
 
-import "github.com/labstack/echo/v4"
+import (
+	"net/http"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/requestid"
+	"github.com/labstack/echo/v4"
+)
+
+// StatusClientClosedRequest is the status logged for the requests whose client went away before the response
+// (nginx's convention), the response never reaching the client
+const StatusClientClosedRequest = 499
 
 // APIError is the standard wrapper for all error API responses (4xx and 5xx status codes)
 // It provides a consistent, machine-readable format for clients to handle failures
 type APIError struct {
-	Code    string `json:"code"`              // A machine-readable error code (e.g., "VALIDATION_ERROR", "RESOURCE_NOT_FOUND")
-	Message string `json:"message"`           // A human-readable message intended for the developer consuming the API
-	Details any    `json:"details,omitempty"` // An optional field for providing more specific context, like a slice of validation errors
+	Code      string `json:"code"`                 // A machine-readable error code (e.g., "VALIDATION_ERROR", "RESOURCE_NOT_FOUND")
+	Message   string `json:"message"`              // A human-readable message intended for the developer consuming the API
+	Details   any    `json:"details,omitempty"`    // An optional field for providing more specific context, like a slice of validation errors
+	RequestID string `json:"request_id,omitempty"` // The ID of the failed request, which users can quote to support to find its logs
+	TraceID   string `json:"trace_id,omitempty"`   // The ID of the distributed trace of the request, only when tracing is enabled
 }
 
 // NewAPIError creates a new APIError response structure
@@ -19,7 +31,50 @@ func NewAPIError(code, message string, details any) APIError {
 	}
 }
 
+// NewAPIErrorFromDomain creates a new APIError from a DomainError, exposing its metadata as details
+func NewAPIErrorFromDomain(err *errx.DomainError) APIError {
+	var details any
+	if len(err.Metadata) > 0 {
+		details = err.Metadata
+	}
+	return NewAPIError(err.Code, err.Message, details)
+}
+
+// StatusFromCategory maps a domain error category to its HTTP status code
+func StatusFromCategory(category errx.Category) int {
+	switch category {
+	case errx.CategoryNotFound:
+		return http.StatusNotFound
+	case errx.CategoryConflict:
+		return http.StatusConflict
+	case errx.CategoryValidation:
+		return http.StatusUnprocessableEntity
+	case errx.CategoryForbidden:
+		return http.StatusForbidden
+	case errx.CategoryUnauthenticated:
+		return http.StatusUnauthorized
+	case errx.CategoryUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // SendAPIError is a helper function to standardize sending error JSON responses
+// It stamps the error with the request ID (and trace ID) of the request, unless it already carries them,
+// and translates its message to the language of the request (see LanguageMiddleware)
 func SendAPIError(c echo.Context, httpStatus int, err APIError) error {
+	err = err.Localize(languageCatalog(c), Language(c))
+	ctx := c.Request().Context()
+	if err.RequestID == "" {
+		if id, ok := requestid.FromContext(ctx); ok {
+			err.RequestID = id
+		} else {
+			err.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+		}
+	}
+	if err.TraceID == "" {
+		err.TraceID, _ = requestid.TraceIDFromContext(ctx)
+	}
 	return c.JSON(httpStatus, err)
 }
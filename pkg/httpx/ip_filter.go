@@ -0,0 +1,43 @@
+package httpx
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Guizzs26/fintrack/pkg/ipfilter"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/labstack/echo/v4"
+)
+
+// IPAllowlistConfig configures the IPAllowlistMiddleware
+type IPAllowlistConfig struct {
+	Allowed        *ipfilter.Networks // Allowed are the networks the clients must belong to, every client being allowed when empty
+	TrustedProxies *ipfilter.Networks // TrustedProxies are the proxies whose X-Forwarded-For is followed, the peer being the client when empty
+}
+
+// IPAllowlistMiddleware answers 403 Forbidden to the clients outside of the allowed networks
+// The client is not read with echo.Context.RealIP, which believes any X-Forwarded-For header
+func IPAllowlistMiddleware(cfg IPAllowlistConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Allowed.Empty() {
+				return next(c)
+			}
+
+			req := c.Request()
+			remote, err := ipfilter.ParseRemoteAddr(req.RemoteAddr)
+			if err == nil {
+				client := ipfilter.ClientAddr(remote, req.Header.Values(echo.HeaderXForwardedFor), cfg.TrustedProxies)
+				if cfg.Allowed.Contains(client) {
+					return next(c)
+				}
+			}
+
+			ctxlogger.GetLogger(req.Context()).Warn("request from a network not allowed",
+				slog.String("remote_addr", req.RemoteAddr),
+				slog.String("path", c.Path()),
+			)
+			return SendAPIError(c, http.StatusForbidden, NewAPIError("IP_NOT_ALLOWED", "requests from this network are not allowed", nil))
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package httpx
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JobResponse is the standard representation of a long-running operation (imports, exports, data takeout...)
+// It is returned with 202 Accepted when the operation starts and by the job status endpoint afterwards
+type JobResponse struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`                   // The kind of operation (e.g., "transactions_import")
+	Status      string     `json:"status"`                 // pending, running, succeeded or failed
+	Progress    int        `json:"progress"`               // Completion percentage, from 0 to 100
+	Result      any        `json:"result,omitempty"`       // The operation output, only present once it succeeded
+	Error       *APIError  `json:"error,omitempty"`        // The failure details, only present once it failed
+	StatusURL   string     `json:"status_url"`             // The endpoint clients poll to follow the job
+	CreatedAt   time.Time  `json:"created_at"`             // When the job was accepted
+	UpdatedAt   time.Time  `json:"updated_at"`             // When the job last changed (status or progress)
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // When the job reached a final status
+}
+
+// SendAccepted is a helper function to standardize starting a long-running operation
+// It replies 202 Accepted with the job resource and points the Location header to its status endpoint
+func SendAccepted(c echo.Context, job JobResponse) error {
+	c.Response().Header().Set(echo.HeaderLocation, job.StatusURL)
+	return SendSuccess(c, http.StatusAccepted, job)
+}
@@ -0,0 +1,110 @@
+package httpx
+
+import (
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderAcceptLanguage is the HTTP header carrying the languages the client reads
+const HeaderAcceptLanguage = "Accept-Language"
+
+// languageCatalogKey stores the catalog translating the error messages in the echo context
+const languageCatalogKey = "httpx.language_catalog"
+
+// Messages translates the error messages of the httpx middlewares, of echo and the messages shared by the handlers
+// Keys are the error codes or, for the errors whose code is shared by many messages, the English messages
+var Messages = i18n.Catalog{
+	i18n.Portuguese: {
+		"VALIDATION_ERROR":             "um ou mais campos falharam na validação",
+		"INVALID_BODY":                 "o corpo da requisição é inválido",
+		"INTERNAL_SERVER_ERROR":        "Ocorreu um erro inesperado",
+		"RATE_LIMITED":                 "muitas requisições, tente novamente mais tarde",
+		"COST_LIMITED":                 "muitas requisições custosas, tente novamente mais tarde ou peça um período menor",
+		"TOO_MANY_CONCURRENT_REQUESTS": "muitas requisições custosas em andamento, aguarde o término delas",
+		"UNREADABLE_BODY":              "não foi possível ler o corpo da requisição",
+		"BODY_TOO_LARGE":               "o corpo da requisição é grande demais para ser verificado",
+		"CLIENT_CLOSED_REQUEST":        "a requisição foi cancelada pelo cliente",
+		"TIMEOUT":                      "a requisição demorou demais para ser concluída",
+
+		// INVALID_SIGNATURE carries a message per failure
+		"the request is not signed":                               "a requisição não está assinada",
+		"the signature timestamp is malformed":                    "o timestamp da assinatura é inválido",
+		"the signature timestamp is outside of the replay window": "o timestamp da assinatura está fora da janela de repetição",
+		"the signature is malformed":                              "a assinatura é inválida",
+		"the signature does not match":                            "a assinatura não confere",
+		"the request was already received":                        "a requisição já foi recebida",
+
+		// UNSUPPORTED_API_VERSION carries a message per header
+		"the API version of the body is not supported": "a versão da API do corpo da requisição não é suportada",
+		"the accepted API version is not supported":    "a versão da API aceita não é suportada",
+
+		// Messages of the echo errors
+		"Bad Request":              "Requisição inválida",
+		"Unauthorized":             "Não autorizado",
+		"Forbidden":                "Proibido",
+		"Not Found":                "Não encontrado",
+		"Method Not Allowed":       "Método não permitido",
+		"Request Entity Too Large": "Corpo da requisição grande demais",
+		"Unsupported Media Type":   "Tipo de mídia não suportado",
+		"Too Many Requests":        "Muitas requisições",
+		"Internal Server Error":    "Erro interno do servidor",
+		"Service Unavailable":      "Serviço indisponível",
+
+		// Messages shared by the handlers
+		"authentication required":           "autenticação necessária",
+		"missing or malformed bearer token": "token bearer ausente ou malformado",
+		"offset must be a positive number":  "offset deve ser um número positivo",
+	},
+}
+
+// LanguageConfig defines the config for the language middleware
+type LanguageConfig struct {
+	Catalog i18n.Catalog // Catalog translates the error messages of the service, on top of Messages
+}
+
+// LanguageMiddleware sets the language of the request from its Accept-Language header
+// Requests without a supported language are left without one, so a later middleware may apply
+// the preference of the authenticated user with SetLanguage. Error responses sent through
+// SendAPIError are translated to the language of the request with the catalog of the config
+func LanguageMiddleware(config LanguageConfig) echo.MiddlewareFunc {
+	catalog := i18n.Merge(Messages, config.Catalog)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(languageCatalogKey, catalog)
+			if lang, ok := i18n.Resolve(c.Request().Header.Get(HeaderAcceptLanguage)); ok {
+				SetLanguage(c, lang)
+			}
+			return next(c)
+		}
+	}
+}
+
+// SetLanguage sets the language the response to the request is written in
+func SetLanguage(c echo.Context, lang i18n.Lang) {
+	c.SetRequest(c.Request().WithContext(i18n.WithLang(c.Request().Context(), lang)))
+}
+
+// Language returns the language of the request, the Default when none was set
+func Language(c echo.Context) i18n.Lang {
+	lang, _ := i18n.FromContext(c.Request().Context())
+	return lang
+}
+
+// Localize returns the error with its message in the language, looked up in the catalog by code then by message
+// The message is left in English when the catalog does not translate it
+func (e APIError) Localize(catalog i18n.Catalog, lang i18n.Lang) APIError {
+	if msg, ok := catalog.Message(lang, e.Code); ok {
+		e.Message = msg
+	} else if msg, ok := catalog.Message(lang, e.Message); ok {
+		e.Message = msg
+	}
+	return e
+}
+
+// languageCatalog returns the catalog set by the middleware, or Messages when it is not installed
+func languageCatalog(c echo.Context) i18n.Catalog {
+	if catalog, ok := c.Get(languageCatalogKey).(i18n.Catalog); ok {
+		return catalog
+	}
+	return Messages
+}
@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// paginationContextKey stores the PaginationConfig of the server in the echo context
+const paginationContextKey = "httpx.pagination"
+
+// PaginationConfig bounds the result sets clients may request, zero values falling back to the defaults
+type PaginationConfig struct {
+	DefaultPageSize int // DefaultPageSize is the page size when the client sets no limit, 50 by default
+	MaxPageSize     int // MaxPageSize is the largest limit a client may request, 100 by default
+	MaxExportRows   int // MaxExportRows bounds the rows of the exports, which are not paginated, 100000 by default
+}
+
+// withDefaults fills the zero fields of the config
+func (cfg PaginationConfig) withDefaults() PaginationConfig {
+	if cfg.MaxPageSize <= 0 {
+		cfg.MaxPageSize = 100
+	}
+	if cfg.DefaultPageSize <= 0 {
+		cfg.DefaultPageSize = 50
+	}
+	cfg.DefaultPageSize = min(cfg.DefaultPageSize, cfg.MaxPageSize)
+	if cfg.MaxExportRows <= 0 {
+		cfg.MaxExportRows = 100000
+	}
+	return cfg
+}
+
+// PaginationMiddleware makes the pagination bounds of the server available to ParsePage and MaxExportRows
+func PaginationMiddleware(cfg PaginationConfig) echo.MiddlewareFunc {
+	cfg = cfg.withDefaults()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(paginationContextKey, cfg)
+			return next(c)
+		}
+	}
+}
+
+// paginationConfig returns the config set by the middleware, or the defaults when it is not installed
+func paginationConfig(c echo.Context) PaginationConfig {
+	if cfg, ok := c.Get(paginationContextKey).(PaginationConfig); ok {
+		return cfg
+	}
+	return PaginationConfig{}.withDefaults()
+}
+
+// Page is the slice of a result set requested by the client through the limit and offset query parameters
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// ParsePage reads the limit and offset query parameters, the limit defaulting to the page size of the server
+// A limit over the max page size is rejected, so clients learn the bound instead of silently getting less
+func ParsePage(c echo.Context) (Page, error) {
+	cfg := paginationConfig(c)
+	page := Page{Limit: cfg.DefaultPageSize}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > cfg.MaxPageSize {
+			return Page{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be a number between 1 and %d", cfg.MaxPageSize))
+		}
+		page.Limit = limit
+	}
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return Page{}, echo.NewHTTPError(http.StatusBadRequest, "offset must be a positive number")
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
+
+// MaxExportRows returns how many rows an export may hold
+func MaxExportRows(c echo.Context) int {
+	return paginationConfig(c).MaxExportRows
+}
@@ -0,0 +1,64 @@
+package httpx
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/ratelimit"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Rate limit headers, telling clients how many requests they have left
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+)
+
+// RateLimitConfig configures the RateLimitMiddleware
+type RateLimitConfig struct {
+	Limiter ratelimit.Limiter
+	Limit   ratelimit.Limit
+	KeyFunc func(c echo.Context) string // KeyFunc identifies the caller, the client IP by default
+	Skipper middleware.Skipper          // Skipper exempts requests from the limit (e.g., health checks)
+}
+
+// RateLimitMiddleware answers 429 Too Many Requests once a caller made more requests than the limit allows
+// The limiter failing lets the request through, an unavailable Redis must not take the API down with it
+func RateLimitMiddleware(cfg RateLimitConfig) echo.MiddlewareFunc {
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(c echo.Context) string { return c.RealIP() }
+	}
+	skipper := cfg.Skipper
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper(c) || !cfg.Limit.Enabled() {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			result, err := cfg.Limiter.Allow(ctx, "http:"+keyFunc(c), cfg.Limit)
+			if err != nil {
+				ctxlogger.GetLogger(ctx).Warn("rate limiter failed, letting the request through", slog.String("error", err.Error()))
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				return SendAPIError(c, http.StatusTooManyRequests, NewAPIError("RATE_LIMITED", "too many requests, try again later", nil))
+			}
+			return next(c)
+		}
+	}
+}
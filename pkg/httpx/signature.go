@@ -0,0 +1,158 @@
+package httpx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Request signing headers: the Unix time the request was signed at and the hex HMAC-SHA256 of "<timestamp>.<body>"
+const (
+	HeaderSignature          = "X-Fintrack-Signature"
+	HeaderSignatureTimestamp = "X-Fintrack-Timestamp"
+)
+
+// Sign returns the signature of a body sent at the given time, as set in the HeaderSignature header
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureConfig configures the SignatureMiddleware
+type SignatureConfig struct {
+	// Secrets are the keys a signature may be made with, several being accepted while a key is rotated
+	Secrets      []string
+	ReplayWindow time.Duration // ReplayWindow is how far the timestamp may be from now, 5m by default
+	MaxBodySize  int64         // MaxBodySize bounds the bodies read before their signature is verified, 1MB by default
+	Clock        clock.Clock   // Clock is the system clock by default
+	Skipper      middleware.Skipper
+}
+
+// SignatureMiddleware rejects with 401 Unauthorized the requests without a valid signature of their timestamp and body
+// A request is accepted once: its signature is remembered until its timestamp leaves the replay window.
+// Each instance remembers the signatures it saw, so the timestamp window is what bounds a replay across instances
+func SignatureMiddleware(cfg SignatureConfig) echo.MiddlewareFunc {
+	if cfg.ReplayWindow <= 0 {
+		cfg.ReplayWindow = 5 * time.Minute
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.SystemClock{}
+	}
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	secrets := make([][]byte, 0, len(cfg.Secrets))
+	for _, secret := range cfg.Secrets {
+		if secret != "" {
+			secrets = append(secrets, []byte(secret))
+		}
+	}
+	seen := &seenSignatures{expires: make(map[string]time.Time)}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			body, err := io.ReadAll(io.LimitReader(req.Body, cfg.MaxBodySize+1))
+			if err != nil {
+				return SendAPIError(c, http.StatusBadRequest, NewAPIError("UNREADABLE_BODY", "the request body could not be read", nil))
+			}
+			if int64(len(body)) > cfg.MaxBodySize {
+				return SendAPIError(c, http.StatusRequestEntityTooLarge, NewAPIError("BODY_TOO_LARGE", "the request body is too large to be verified", nil))
+			}
+			// The handler reads the body again
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := cfg.Clock.Now()
+			signature := req.Header.Get(HeaderSignature)
+			if err := verifySignature(secrets, req.Header.Get(HeaderSignatureTimestamp), signature, body, now, cfg.ReplayWindow); err != nil {
+				return SendAPIError(c, http.StatusUnauthorized, NewAPIError("INVALID_SIGNATURE", err.Error(), nil))
+			}
+			// A signature stays within the window for at most twice its length, the timestamp being up to a window ahead
+			if !seen.add(strings.ToLower(signature), now.Add(2*cfg.ReplayWindow), now) {
+				return SendAPIError(c, http.StatusUnauthorized, NewAPIError("INVALID_SIGNATURE", "the request was already received", nil))
+			}
+			return next(c)
+		}
+	}
+}
+
+// verifySignature checks that the timestamp is within the replay window and that one of the secrets signed the body
+func verifySignature(secrets [][]byte, timestamp, signature string, body []byte, now time.Time, window time.Duration) error {
+	if timestamp == "" || signature == "" {
+		return errors.New("the request is not signed")
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("the signature timestamp is malformed")
+	}
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-window)) || signedAt.After(now.Add(window)) {
+		return errors.New("the signature timestamp is outside of the replay window")
+	}
+
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.New("the signature is malformed")
+	}
+	for _, secret := range secrets {
+		expected, _ := hex.DecodeString(Sign(secret, signedAt, body))
+		if hmac.Equal(given, expected) {
+			return nil
+		}
+	}
+	return errors.New("the signature does not match")
+}
+
+// sweepSeenEvery is how many signatures are recorded between sweeps of the expired ones
+const sweepSeenEvery = 256
+
+// seenSignatures remembers the signatures accepted within the replay window
+type seenSignatures struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	adds    int
+}
+
+// add records the signature, reporting false when it was already seen and has not expired yet
+func (s *seenSignatures) add(signature string, expiresAt, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.adds++
+	if s.adds%sweepSeenEvery == 0 {
+		for sig, exp := range s.expires {
+			if !exp.After(now) {
+				delete(s.expires, sig)
+			}
+		}
+	}
+
+	if exp, ok := s.expires[signature]; ok && exp.After(now) {
+		return false
+	}
+	s.expires[signature] = expiresAt
+	return true
+}
@@ -0,0 +1,175 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Media types of the versioned JSON bodies (e.g., "application/vnd.fintrack.v2+json"), plain application/json bodies
+// following the default version
+const (
+	MIMEFintrackV1 = "application/vnd.fintrack.v1+json"
+	MIMEFintrackV2 = "application/vnd.fintrack.v2+json"
+
+	vendorMIMEPrefix = "application/vnd.fintrack.v"
+	vendorMIMESuffix = "+json"
+)
+
+// Headers announcing that a version is deprecated, and the date it stops being served (RFC 8594)
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// apiVersionKey stores the version of the request in the echo context
+const apiVersionKey = "httpx.api_version"
+
+// VersionConfig defines the config for the version middleware
+type VersionConfig struct {
+	Latest  int               // Latest is the highest version served, 1 by default
+	Default int               // Default is the version of plain application/json requests, 1 by default
+	Sunsets map[int]time.Time // Sunsets are the dates the deprecated versions stop being served, announced to their clients
+}
+
+// VersionMiddleware sets the version of the request from the media type of its body or, for the requests without
+// one, from its Accept header. Versions out of range are answered with 415 Unsupported Media Type (body) or
+// 406 Not Acceptable (Accept), and the responses of a deprecated version carry the Deprecation and Sunset headers
+func VersionMiddleware(cfg VersionConfig) echo.MiddlewareFunc {
+	cfg.Latest = max(cfg.Latest, 1)
+	cfg.Default = max(cfg.Default, 1)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			version, fromBody := cfg.Default, false
+			if v, ok := vendorVersion(req.Header.Get(echo.HeaderContentType)); ok {
+				version, fromBody = v, true
+			} else if v, ok := acceptedVersion(req.Header.Get(echo.HeaderAccept)); ok {
+				version = v
+			}
+
+			if version < 1 || version > cfg.Latest {
+				details := map[string]any{"version": version, "latest": cfg.Latest}
+				if fromBody {
+					return SendAPIError(c, http.StatusUnsupportedMediaType, NewAPIError("UNSUPPORTED_API_VERSION", "the API version of the body is not supported", details))
+				}
+				return SendAPIError(c, http.StatusNotAcceptable, NewAPIError("UNSUPPORTED_API_VERSION", "the accepted API version is not supported", details))
+			}
+
+			c.Set(apiVersionKey, version)
+			if sunset, ok := cfg.Sunsets[version]; ok {
+				header := c.Response().Header()
+				header.Set(HeaderDeprecation, "true")
+				header.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+			}
+			return next(c)
+		}
+	}
+}
+
+// APIVersion returns the version of the request set by VersionMiddleware, 1 when the middleware did not run
+func APIVersion(c echo.Context) int {
+	if version, ok := c.Get(apiVersionKey).(int); ok {
+		return version
+	}
+	return 1
+}
+
+// VersionedRequest is implemented by the request bodies whose shape changed between versions, the type holding the
+// shape of the latest version and decoding the bodies of the older ones
+type VersionedRequest interface {
+	// BindVersion decodes a body of an older version into the request, decode unmarshalling the JSON body into its argument
+	BindVersion(version int, decode func(any) error) error
+}
+
+var _ echo.Binder = (*VersionedBinder)(nil)
+
+// VersionedBinder binds the JSON bodies of every version, which echo's binder would reject as an unsupported media type,
+// handing the bodies of the older versions to the requests implementing VersionedRequest. The bodies are decoded
+// strictly, a malformed one failing with a *BodyError listing its problems field by field
+type VersionedBinder struct {
+	echo.DefaultBinder
+	latest int
+}
+
+// NewVersionedBinder creates a VersionedBinder, latest being the version the request types are shaped after
+func NewVersionedBinder(latest int) *VersionedBinder {
+	return &VersionedBinder{latest: max(latest, 1)}
+}
+
+// Bind binds the path parameters, the query parameters of GET, DELETE and HEAD requests, then the body
+func (b *VersionedBinder) Bind(i any, c echo.Context) error {
+	req := c.Request()
+	mediaType, _, _ := strings.Cut(req.Header.Get(echo.HeaderContentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	if _, vendor := vendorVersion(mediaType); !vendor && mediaType != echo.MIMEApplicationJSON {
+		return b.DefaultBinder.Bind(i, c)
+	}
+
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodDelete || req.Method == http.MethodHead {
+		if err := b.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	if req.ContentLength == 0 {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		if httpErr, ok := err.(*echo.HTTPError); ok {
+			return httpErr
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	decode := func(target any) error {
+		return decodeStrictJSON(data, target)
+	}
+	if versioned, ok := i.(VersionedRequest); ok {
+		if version := APIVersion(c); version < b.latest {
+			return versioned.BindVersion(version, decode)
+		}
+	}
+	return decode(i)
+}
+
+// vendorVersion returns the version of a versioned media type (e.g., 2 for "application/vnd.fintrack.v2+json; charset=utf-8")
+func vendorVersion(mediaType string) (int, bool) {
+	base, _, _ := strings.Cut(mediaType, ";")
+	rest, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(base)), vendorMIMEPrefix)
+	if !ok {
+		return 0, false
+	}
+	digits, ok := strings.CutSuffix(rest, vendorMIMESuffix)
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// acceptedVersion returns the version of the first versioned media type of an Accept header
+func acceptedVersion(accept string) (int, bool) {
+	for mediaType := range strings.SplitSeq(accept, ",") {
+		if version, ok := vendorVersion(mediaType); ok {
+			return version, true
+		}
+	}
+	return 0, false
+}
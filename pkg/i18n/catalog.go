@@ -0,0 +1,47 @@
+package i18n
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Catalog holds the messages of each language by key
+// Keys are either stable identifiers (e.g., an error code) or the English message itself
+type Catalog map[Lang]map[string]string
+
+// Message returns the message of the key in the language
+// The boolean result is false when the catalog has no such message in that language
+func (c Catalog) Message(lang Lang, key string) (string, bool) {
+	msg, ok := c[lang][key]
+	return msg, ok
+}
+
+// Format returns the message of the key in the language with the args interpolated as in fmt.Sprintf
+// It falls back to the English message, then to the key itself, so a missing translation never loses the text
+func (c Catalog) Format(lang Lang, key string, args ...any) string {
+	msg, ok := c.Message(lang, key)
+	if !ok {
+		msg, ok = c.Message(English, key)
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Merge returns a catalog with the messages of every catalog, later catalogs taking precedence
+func Merge(catalogs ...Catalog) Catalog {
+	merged := Catalog{}
+	for _, catalog := range catalogs {
+		for lang, messages := range catalog {
+			if merged[lang] == nil {
+				merged[lang] = make(map[string]string, len(messages))
+			}
+			maps.Copy(merged[lang], messages)
+		}
+	}
+	return merged
+}
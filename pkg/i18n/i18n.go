@@ -0,0 +1,64 @@
+// Package i18n picks the language the API messages and the notifications are written in
+//
+// The language comes from the Accept-Language header of the request or, when the client sends none,
+// from the locale the user chose in their preferences. Each package keeps the translations of its
+// own messages in a Catalog, falling back to English when a message is not translated
+package i18n
+
+import (
+	"context"
+	"strings"
+)
+
+// Lang is a language the API messages and the notifications are written in
+type Lang string
+
+const (
+	English    Lang = "en"
+	Portuguese Lang = "pt-BR"
+
+	// Default is used when the client sends no (or no supported) language,
+	// since the product's main audience is Brazilian
+	Default = Portuguese
+)
+
+// key is an unexported type used as the key for the language in the context
+// Using an unexported type prevents key collisions with other packages
+type key string
+
+// langKey is the specific key value used to store the language in the context
+const langKey key = "lang"
+
+// Resolve picks the first supported language of an Accept-Language header value or of a locale
+// (e.g., "en-US,en;q=0.9" -> English, "pt-BR" -> Portuguese)
+// The boolean result is false, and the language is Default, when none of them is supported
+func Resolve(acceptLanguage string) (Lang, bool) {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		lang, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+		lang, _, _ = strings.Cut(lang, "_")
+
+		switch strings.ToLower(lang) {
+		case "pt":
+			return Portuguese, true
+		case "en":
+			return English, true
+		}
+	}
+	return Default, false
+}
+
+// WithLang returns a new context that carries the language the response is written in
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey, lang)
+}
+
+// FromContext retrieves the language from the provided context
+// The boolean result is false, and the language is Default, when the context carries no language
+func FromContext(ctx context.Context) (Lang, bool) {
+	lang, ok := ctx.Value(langKey).(Lang)
+	if !ok || lang == "" {
+		return Default, false
+	}
+	return lang, true
+}
@@ -0,0 +1,61 @@
+// Package idgen creates the IDs of new records, abstracted so the code creating them can be run with predictable IDs
+package idgen
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	_ Generator = V7Generator{}
+	_ Generator = RandomGenerator{}
+	_ Generator = (*SequenceGenerator)(nil)
+)
+
+// Generator creates the ID of a new record
+type Generator interface {
+	NewID() uuid.UUID
+}
+
+// V7Generator creates time-ordered UUIDv7 IDs, the default: records inserted together land next to each other in the
+// B-tree indexes of their primary keys, and ordering by ID follows the order they were created in
+// The IDs created by a process always increase, even within the same millisecond
+type V7Generator struct{}
+
+// NewID returns a new UUIDv7
+func (V7Generator) NewID() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// RandomGenerator creates random UUIDv4 IDs, for the records whose IDs must not tell when they were created
+type RandomGenerator struct{}
+
+// NewID returns a new UUIDv4
+func (RandomGenerator) NewID() uuid.UUID {
+	return uuid.New()
+}
+
+// SequenceGenerator returns the IDs it was given in order, then random ones, used for reproducible runs and tests
+// It must be used through a pointer, so every holder takes from the same sequence
+type SequenceGenerator struct {
+	mu  sync.Mutex
+	ids []uuid.UUID
+}
+
+// NewSequenceGenerator creates a SequenceGenerator returning ids
+func NewSequenceGenerator(ids ...uuid.UUID) *SequenceGenerator {
+	return &SequenceGenerator{ids: ids}
+}
+
+// NewID returns the next ID of the sequence, or a new UUIDv7 once it is exhausted
+func (sg *SequenceGenerator) NewID() uuid.UUID {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+	if len(sg.ids) == 0 {
+		return V7Generator{}.NewID()
+	}
+	id := sg.ids[0]
+	sg.ids = sg.ids[1:]
+	return id
+}
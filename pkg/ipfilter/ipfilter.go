@@ -0,0 +1,208 @@
+// Package ipfilter decides whether a client may reach an endpoint by its address: the networks it belongs to
+// (allowlists) or the country it is located in (geo-blocking)
+//
+// Countries come from a Resolver, so any GeoIP database plugs in (e.g., a MaxMind reader), the static resolver
+// mapping configured networks to countries for private deployments and local runs.
+// X-Forwarded-For is only followed through the trusted proxies, a forged header cannot bypass the filters.
+// The HTTP middleware (httpx) and the gRPC interceptor (grpcx) take the filters of this package
+package ipfilter
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Networks is a set of IP ranges, an address matching when it belongs to any of them
+type Networks struct {
+	prefixes []netip.Prefix
+}
+
+// ParseNetworks parses CIDR ranges (e.g., "10.0.0.0/8"), a bare address standing for itself
+func ParseNetworks(cidrs []string) (*Networks, error) {
+	n := &Networks{prefixes: make([]netip.Prefix, 0, len(cidrs))}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		n.prefixes = append(n.prefixes, prefix)
+	}
+	return n, nil
+}
+
+// Empty reports whether no range was configured
+func (n *Networks) Empty() bool {
+	return n == nil || len(n.prefixes) == 0
+}
+
+// Contains reports whether the address belongs to one of the ranges, IPv4 addresses mapped to IPv6 included
+func (n *Networks) Contains(addr netip.Addr) bool {
+	if n == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range n.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientAddr returns the address of the client of a request received from remote, walking X-Forwarded-For from
+// the right while the hops are trusted proxies, so the first untrusted hop is the client
+// forwardedFor holds the values of the header, each possibly a comma separated list of hops
+func ClientAddr(remote netip.Addr, forwardedFor []string, trusted *Networks) netip.Addr {
+	client := remote.Unmap()
+	if !trusted.Contains(client) {
+		return client
+	}
+
+	hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+		if !trusted.Contains(client) {
+			break
+		}
+	}
+	return client
+}
+
+// ParseRemoteAddr parses the address of a peer, written as "host:port" or as a bare address
+func ParseRemoteAddr(remote string) (netip.Addr, error) {
+	if addrPort, err := netip.ParseAddrPort(remote); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	addr, err := netip.ParseAddr(remote)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid remote address %q", remote)
+	}
+	return addr.Unmap(), nil
+}
+
+// ----- Geo-blocking ----- //
+
+// Resolver locates the country of an address, an empty country meaning it is unknown
+type Resolver interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of the address (e.g., "BR")
+	Country(ctx context.Context, addr netip.Addr) (string, error)
+}
+
+var _ Resolver = (*StaticResolver)(nil)
+
+// StaticResolver locates the addresses in configured ranges, the most specific range winning
+type StaticResolver struct {
+	ranges map[netip.Prefix]string
+}
+
+// ParseStaticResolver creates a StaticResolver from "<cidr>=<country>" entries (e.g., "203.0.113.0/24=BR")
+func ParseStaticResolver(entries []string) (*StaticResolver, error) {
+	r := &StaticResolver{ranges: make(map[netip.Prefix]string, len(entries))}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		cidr, country, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid GeoIP range %q, expected <cidr>=<country>", entry)
+		}
+		prefix, err := parsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, err
+		}
+		code, err := parseCountry(country)
+		if err != nil {
+			return nil, err
+		}
+		r.ranges[prefix] = code
+	}
+	return r, nil
+}
+
+// Country returns the country of the most specific range holding the address, empty when none does
+func (r *StaticResolver) Country(_ context.Context, addr netip.Addr) (string, error) {
+	addr = addr.Unmap()
+	country, bits := "", -1
+	for prefix, code := range r.ranges {
+		if prefix.Bits() > bits && prefix.Contains(addr) {
+			country, bits = code, prefix.Bits()
+		}
+	}
+	return country, nil
+}
+
+// GeoBlock rejects the clients located in the blocked countries, the clients of unknown location being let through
+type GeoBlock struct {
+	resolver Resolver
+	blocked  map[string]bool
+}
+
+// NewGeoBlock creates a GeoBlock of the countries, given as ISO 3166-1 alpha-2 codes
+func NewGeoBlock(resolver Resolver, countries []string) (*GeoBlock, error) {
+	g := &GeoBlock{resolver: resolver, blocked: make(map[string]bool, len(countries))}
+	for _, country := range countries {
+		if strings.TrimSpace(country) == "" {
+			continue
+		}
+		code, err := parseCountry(country)
+		if err != nil {
+			return nil, err
+		}
+		g.blocked[code] = true
+	}
+	return g, nil
+}
+
+// Enabled reports whether any country is blocked
+func (g *GeoBlock) Enabled() bool {
+	return g != nil && len(g.blocked) > 0
+}
+
+// Blocked locates the address and reports whether its country is blocked, along the country found
+func (g *GeoBlock) Blocked(ctx context.Context, addr netip.Addr) (string, bool, error) {
+	country, err := g.resolver.Country(ctx, addr)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to locate %s: %w", addr, err)
+	}
+	return country, g.blocked[strings.ToUpper(country)], nil
+}
+
+// parsePrefix parses a CIDR range or a bare address, masking the host bits
+func parsePrefix(cidr string) (netip.Prefix, error) {
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid network %q", cidr)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network %q", cidr)
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// parseCountry validates an ISO 3166-1 alpha-2 code, returned in upper case
+func parseCountry(country string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(country))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", fmt.Errorf("invalid country code %q", country)
+	}
+	return code, nil
+}
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// scopeKey is the attribute added to the records of a scoped logger
+const scopeKey = "scope"
+
+// Levels holds the minimum levels of the application logger, adjustable at runtime
+// The root level applies to every record; a scope (e.g., "http", "repository") may override it
+type Levels struct {
+	root    slog.LevelVar
+	initial slog.Level
+
+	mu     sync.RWMutex
+	scopes map[string]*scopeLevel
+}
+
+// scopeLevel is the level of a scope, following the root level until it is overridden
+type scopeLevel struct {
+	level      slog.LevelVar
+	overridden bool
+}
+
+// NewLevels creates the levels of a logger, pass them to NewSlogConfig through SlogConfig.Levels
+func NewLevels() *Levels {
+	return &Levels{scopes: make(map[string]*scopeLevel)}
+}
+
+// Root returns the root level
+func (l *Levels) Root() slog.Level {
+	return l.root.Level()
+}
+
+// SetRoot changes the root level
+func (l *Levels) SetRoot(level slog.Level) {
+	l.root.Set(level)
+}
+
+// ResetRoot restores the root level the logger was created with
+func (l *Levels) ResetRoot() {
+	l.root.Set(l.initial)
+}
+
+// Set overrides the level of a scope, failing when the scope was never registered with WithScope
+func (l *Levels) Set(scope string, level slog.Level) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.scopes[scope]
+	if !ok {
+		return fmt.Errorf("unknown log scope %q", scope)
+	}
+	s.level.Set(level)
+	s.overridden = true
+	return nil
+}
+
+// Reset removes the override of a scope, which follows the root level again
+func (l *Levels) Reset(scope string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.scopes[scope]
+	if !ok {
+		return fmt.Errorf("unknown log scope %q", scope)
+	}
+	s.overridden = false
+	return nil
+}
+
+// ScopeLevels returns the effective level of every registered scope, sorted by scope name
+func (l *Levels) ScopeLevels() []ScopeLevel {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	levels := make([]ScopeLevel, 0, len(l.scopes))
+	for name, s := range l.scopes {
+		levels = append(levels, ScopeLevel{Scope: name, Level: l.effective(s), Overridden: s.overridden})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Scope < levels[j].Scope })
+	return levels
+}
+
+// ScopeLevel is the effective level of a scope
+type ScopeLevel struct {
+	Scope      string
+	Level      slog.Level
+	Overridden bool // Overridden is false when the scope follows the root level
+}
+
+// enabled reports whether a record of the given level must be written for the scope
+func (l *Levels) enabled(scope string, level slog.Level) bool {
+	if scope == "" {
+		return level >= l.root.Level()
+	}
+
+	l.mu.RLock()
+	s, ok := l.scopes[scope]
+	l.mu.RUnlock()
+	if !ok {
+		return level >= l.root.Level()
+	}
+	return level >= l.effective(s)
+}
+
+// effective returns the level applied to a scope
+func (l *Levels) effective(s *scopeLevel) slog.Level {
+	if s.overridden {
+		return s.level.Level()
+	}
+	return l.root.Level()
+}
+
+// register makes a scope known, so its level can be changed
+func (l *Levels) register(scope string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.scopes[scope]; !ok {
+		l.scopes[scope] = &scopeLevel{}
+	}
+}
+
+// ParseLevel converts a level name (debug, info, warn or error) into a slog.Level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case string(LevelDebug):
+		return slog.LevelDebug, nil
+	case string(LevelInfo):
+		return slog.LevelInfo, nil
+	case string(LevelWarn):
+		return slog.LevelWarn, nil
+	case string(LevelError):
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// levelHandler filters records using the runtime levels of its scope
+// The handlers it wraps are created with the lowest level, so Levels is the only filter
+type levelHandler struct {
+	next   slog.Handler
+	levels *Levels
+	scope  string
+}
+
+// minLevel lets every record through the handlers wrapped by levelHandler
+const minLevel = slog.Level(math.MinInt)
+
+// Enabled reports whether the scope accepts records of the given level
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.levels.enabled(h.scope, level) && h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a levelHandler of the same scope
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), levels: h.levels, scope: h.scope}
+}
+
+// WithGroup returns a levelHandler of the same scope
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), levels: h.levels, scope: h.scope}
+}
+
+// WithScope returns a logger whose level can be changed independently through Levels.Set
+// (e.g., "http", "repository"). Records carry the scope as an attribute
+// Loggers not created by NewSlogConfig with Levels are returned with the attribute only
+func WithScope(logger *slog.Logger, scope string) *slog.Logger {
+	h, ok := logger.Handler().(*levelHandler)
+	if !ok {
+		return logger.With(slog.String(scopeKey, scope))
+	}
+
+	h.levels.register(scope)
+	scoped := &levelHandler{next: h.next, levels: h.levels, scope: scope}
+	return slog.New(scoped).With(slog.String(scopeKey, scope))
+}
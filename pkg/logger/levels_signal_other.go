@@ -0,0 +1,13 @@
+//go:build !unix
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// HandleLevelSignals is a no-op on platforms without SIGUSR1/SIGUSR2, it returns when ctx is done
+func HandleLevelSignals(ctx context.Context, levels *Levels, logger *slog.Logger) {
+	<-ctx.Done()
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleLevelSignals lets operators toggle debug logs without an HTTP call until ctx is done:
+// SIGUSR1 sets the root level to debug and SIGUSR2 restores the level the logger was created with
+func HandleLevelSignals(ctx context.Context, levels *Levels, logger *slog.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGUSR1 {
+				levels.SetRoot(slog.LevelDebug)
+			} else {
+				levels.ResetRoot()
+			}
+			logger.Warn("log level changed by signal", slog.String("signal", sig.String()), slog.String("level", levels.Root().String()))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the value of every sensitive attribute
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys are the attribute keys redacted when SlogConfig.RedactKeys is nil
+// Keys are matched ignoring case, "_" and "-", so "password_hash" also matches "PasswordHash"
+var DefaultRedactKeys = []string{
+	"email",
+	"password",
+	"password_hash",
+	"token",
+	"access_token",
+	"refresh_token",
+	"authorization",
+	"secret",
+	"cpf",
+	"cnpj",
+	"document",
+	"document_number",
+}
+
+// RedactingHandler wraps a slog.Handler, replacing the values of sensitive attributes before output
+// It inspects nested groups and maps with string keys (e.g., a DynamoDB item logged with slog.Any)
+type RedactingHandler struct {
+	next slog.Handler
+	keys map[string]struct{}
+}
+
+// NewRedactingHandler creates a RedactingHandler redacting the given attribute keys
+func NewRedactingHandler(next slog.Handler, keys []string) *RedactingHandler {
+	normalized := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		normalized[normalizeKey(k)] = struct{}{}
+	}
+	return &RedactingHandler{next: next, keys: normalized}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record attributes and passes the record to the wrapped handler
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacts the attributes once, when they are attached to the logger
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), keys: h.keys}
+}
+
+// WithGroup returns a RedactingHandler wrapping the grouped handler
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+// redactAttr returns the attribute with its value redacted when its key is sensitive,
+// descending into groups and maps
+func (h *RedactingHandler) redactAttr(a slog.Attr) slog.Attr {
+	if h.isSensitive(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		return slog.Any(a.Key, h.redactAny(a.Value.Any()))
+	default:
+		return a
+	}
+}
+
+// redactAny returns a copy of maps with string keys whose sensitive entries are redacted
+// Other values are returned untouched
+func (h *RedactingHandler) redactAny(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return v
+	}
+
+	redacted := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		if h.isSensitive(key) {
+			redacted[key] = RedactedValue
+			continue
+		}
+		redacted[key] = h.redactAny(iter.Value().Interface())
+	}
+	return redacted
+}
+
+// isSensitive reports whether key is one of the redacted keys
+func (h *RedactingHandler) isSensitive(key string) bool {
+	_, ok := h.keys[normalizeKey(key)]
+	return ok
+}
+
+// normalizeKey lowercases a key and removes separators, so naming styles do not matter
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+}
@@ -0,0 +1,43 @@
+package logger
+
+import "encoding/json"
+
+// RedactJSON returns a copy of a JSON document whose sensitive object keys have their values redacted,
+// at any depth (nested objects and arrays)
+// It reports false when data is not valid JSON, so callers never log it verbatim
+func RedactJSON(data []byte, keys []string) ([]byte, bool) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+
+	normalized := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		normalized[normalizeKey(k)] = struct{}{}
+	}
+
+	redacted, err := json.Marshal(redactJSONValue(doc, normalized))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// redactJSONValue redacts in place the sensitive entries of a decoded JSON value
+func redactJSONValue(v any, keys map[string]struct{}) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, entry := range value {
+			if _, ok := keys[normalizeKey(key)]; ok {
+				value[key] = RedactedValue
+				continue
+			}
+			value[key] = redactJSONValue(entry, keys)
+		}
+	case []any:
+		for i, entry := range value {
+			value[i] = redactJSONValue(entry, keys)
+		}
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+)
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// backupTimeFormat names the rotated files (e.g., "api-20251017T150405.000.log"), sortable by name
+const backupTimeFormat = "20060102T150405.000"
+
+// RotationConfig configures a RotatingFile
+type RotationConfig struct {
+	Path       string        // Path of the active log file, rotated files are kept next to it
+	MaxSize    int64         // MaxSize in bytes the file reaches before being rotated. Zero disables size-based rotation
+	MaxAge     time.Duration // MaxAge of the active file before being rotated. Zero disables age-based rotation
+	MaxBackups int           // MaxBackups is the number of rotated files kept, the oldest are deleted. Zero keeps them all
+}
+
+// RotatingFile is an io.Writer appending to a file that is rotated by size and age,
+// for deployments without a log shipper. The file is opened on the first write
+type RotatingFile struct {
+	mu       sync.Mutex
+	cfg      RotationConfig
+	clock    clock.Clock
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingFile creates a new RotatingFile
+func NewRotatingFile(cfg RotationConfig, clock clock.Clock) *RotatingFile {
+	return &RotatingFile{cfg: cfg, clock: clock}
+}
+
+// Write appends p to the active file, rotating it first when p would exceed MaxSize or the file is older than MaxAge
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	tooBig := f.cfg.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.cfg.MaxSize
+	tooOld := f.cfg.MaxAge > 0 && f.clock.Now().Sub(f.openedAt) >= f.cfg.MaxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the active file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens (or creates) the active file, keeping appending to an existing one
+// An existing file keeps its age from its modification time, so restarts do not postpone rotation forever
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.clock.Now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// rotate renames the active file with its rotation time, opens a new one and prunes the old backups
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file for rotation: %w", err)
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.cfg.Path)
+	base := strings.TrimSuffix(f.cfg.Path, ext)
+	backup := fmt.Sprintf("%s-%s%s", base, f.clock.Now().UTC().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.cfg.Path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.openedAt = f.clock.Now()
+
+	return f.prune(base, ext)
+}
+
+// prune deletes the oldest rotated files beyond MaxBackups
+func (f *RotatingFile) prune(base, ext string) error {
+	if f.cfg.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(base + "-*" + ext)
+	if err != nil {
+		return fmt.Errorf("failed to list rotated log files: %w", err)
+	}
+	if len(backups) <= f.cfg.MaxBackups {
+		return nil
+	}
+
+	sort.Strings(backups)
+	for _, old := range backups[:len(backups)-f.cfg.MaxBackups] {
+		if err := os.Remove(old); err != nil {
+			return fmt.Errorf("failed to delete rotated log file: %w", err)
+		}
+	}
+	return nil
+}
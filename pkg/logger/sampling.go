@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+)
+
+// droppedMessage is the message of the records reporting how many logs were dropped by sampling
+const droppedMessage = "log records dropped by sampling"
+
+// SamplingHandler wraps a slog.Handler, keeping only the first N records per second of each
+// message (and level), so a failing dependency logging in a loop can not flood the log pipeline
+// The number of dropped records is reported once per message when the next second starts
+type SamplingHandler struct {
+	next  slog.Handler
+	state *samplingState
+}
+
+// samplingState is shared by every handler derived with WithAttrs/WithGroup, so limits are global
+type samplingState struct {
+	mu        sync.Mutex
+	root      slog.Handler // root reports the drop counters without the attributes of derived loggers
+	clock     clock.Clock
+	perSecond int
+	window    time.Time
+	counts    map[samplingKey]int
+}
+
+// samplingKey identifies the records sampled together
+type samplingKey struct {
+	level   slog.Level
+	message string
+}
+
+// NewSamplingHandler creates a SamplingHandler keeping at most perSecond records per message each second
+func NewSamplingHandler(next slog.Handler, perSecond int, clock clock.Clock) *SamplingHandler {
+	return &SamplingHandler{
+		next: next,
+		state: &samplingState{
+			root:      next,
+			clock:     clock,
+			perSecond: perSecond,
+			counts:    make(map[samplingKey]int),
+		},
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler unless its message already reached the limit this second
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	keep, dropped := h.state.sample(samplingKey{level: r.Level, message: r.Message})
+
+	for key, count := range dropped {
+		h.state.reportDropped(ctx, key, count)
+	}
+
+	if !keep {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a SamplingHandler sharing the same limits
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a SamplingHandler sharing the same limits
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// sample counts a record, reporting whether it must be kept
+// When a new second starts, it also returns the drop counters of the previous one
+func (s *samplingState) sample(key samplingKey) (bool, map[samplingKey]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dropped map[samplingKey]int
+	now := s.clock.Now().Truncate(time.Second)
+	if !now.Equal(s.window) {
+		for k, count := range s.counts {
+			if count > s.perSecond {
+				if dropped == nil {
+					dropped = make(map[samplingKey]int)
+				}
+				dropped[k] = count - s.perSecond
+			}
+		}
+		s.window = now
+		clear(s.counts)
+	}
+
+	s.counts[key]++
+	return s.counts[key] <= s.perSecond, dropped
+}
+
+// reportDropped emits a warning with the number of records of a message dropped in the previous second
+func (s *samplingState) reportDropped(ctx context.Context, key samplingKey, count int) {
+	if !s.root.Enabled(ctx, slog.LevelWarn) {
+		return
+	}
+
+	r := slog.NewRecord(s.clock.Now(), slog.LevelWarn, droppedMessage, 0)
+	r.AddAttrs(
+		slog.String("sampled_message", key.message),
+		slog.String("sampled_level", key.level.String()),
+		slog.Int("dropped", count),
+	)
+	_ = s.root.Handle(ctx, r)
+}
@@ -4,7 +4,8 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
 )
 
 // Level defines the logging level for the application
@@ -27,25 +28,20 @@ const (
 
 // SlogConfig holds all the configuration for the application logger (slog)
 type SlogConfig struct {
-	Level     Level     // Level is the minimum level of logs to be written
-	Format    Format    // Format specifies the output format (e.g., "json" or "text")
-	AddSource bool      // AddSource determines whether to include the source code file and line number in the log output
-	Writer    io.Writer // Writer is the destination for the logs. Defaults to os.Stdout if nil
+	Level           Level         // Level is the minimum level of logs to be written
+	Format          Format        // Format specifies the output format (e.g., "json" or "text")
+	AddSource       bool          // AddSource determines whether to include the source code file and line number in the log output
+	Writer          io.Writer     // Writer is the destination for the logs. Defaults to os.Stdout if nil
+	File            *RotatingFile // File, when set, also receives the logs alongside Writer (set Writer to io.Discard for file-only output)
+	RedactKeys      []string      // RedactKeys are the attribute keys whose values never reach the output. Defaults to DefaultRedactKeys if nil
+	SamplePerSecond int           // SamplePerSecond caps the records written per message and level each second, counting the dropped ones. Zero disables sampling
+	Levels          *Levels       // Levels, when set, makes the level adjustable at runtime, starting at Level, and enables WithScope
 }
 
 // NewSlogConfig creates a new slog.Logger based on the provided configuration
 func NewSlogConfig(cfg SlogConfig) *slog.Logger {
-	var level slog.Level
-	switch strings.ToLower(string(cfg.Level)) {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
+	level, err := ParseLevel(string(cfg.Level))
+	if err != nil {
 		level = slog.LevelInfo
 	}
 
@@ -54,12 +50,21 @@ func NewSlogConfig(cfg SlogConfig) *slog.Logger {
 	if writer == nil {
 		writer = os.Stdout
 	}
+	if cfg.File != nil {
+		writer = io.MultiWriter(writer, cfg.File)
+	}
 
 	// Configure handler options based on the config
 	opts := slog.HandlerOptions{
 		AddSource: cfg.AddSource,
 		Level:     level,
 	}
+	if cfg.Levels != nil {
+		// The runtime levels are the only filter, checked before sampling so filtered records are not counted
+		cfg.Levels.initial = level
+		cfg.Levels.root.Set(level)
+		opts.Level = minLevel
+	}
 
 	// Create the appropriate handler based on the specified format
 	var handler slog.Handler
@@ -72,5 +77,19 @@ func NewSlogConfig(cfg SlogConfig) *slog.Logger {
 		handler = slog.NewJSONHandler(writer, &opts)
 	}
 
+	redactKeys := cfg.RedactKeys
+	if redactKeys == nil {
+		redactKeys = DefaultRedactKeys
+	}
+	handler = NewRedactingHandler(handler, redactKeys)
+
+	if cfg.SamplePerSecond > 0 {
+		handler = NewSamplingHandler(handler, cfg.SamplePerSecond, clock.SystemClock{})
+	}
+
+	if cfg.Levels != nil {
+		handler = &levelHandler{next: handler, levels: cfg.Levels}
+	}
+
 	return slog.New(handler)
 }
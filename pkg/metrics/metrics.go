@@ -0,0 +1,44 @@
+package metrics
+
+// Counter is a monotonically increasing metric, label values are given in the order of the declared label names
+type Counter interface {
+	Inc(labelValues ...string)
+	Add(delta float64, labelValues ...string)
+}
+
+// Gauge is a metric that can go up and down, label values are given in the order of the declared label names
+type Gauge interface {
+	Set(value float64, labelValues ...string)
+	Add(delta float64, labelValues ...string)
+}
+
+// Provider creates the metrics of a service
+// Registry is the built-in implementation, adapters for Prometheus or OpenTelemetry clients can be plugged instead
+type Provider interface {
+	Counter(name, help string, labelNames ...string) Counter
+	Gauge(name, help string, labelNames ...string) Gauge
+}
+
+var (
+	_ Provider = Nop{}
+	_ Counter  = Nop{}
+	_ Gauge    = Nop{}
+)
+
+// Nop is a Provider whose metrics discard every observation (e.g., for CLI tools and tests)
+type Nop struct{}
+
+// Counter returns a counter that discards its observations
+func (Nop) Counter(string, string, ...string) Counter { return Nop{} }
+
+// Gauge returns a gauge that discards its observations
+func (Nop) Gauge(string, string, ...string) Gauge { return Nop{} }
+
+// Inc does nothing
+func (Nop) Inc(...string) {}
+
+// Add does nothing
+func (Nop) Add(float64, ...string) {}
+
+// Set does nothing
+func (Nop) Set(float64, ...string) {}
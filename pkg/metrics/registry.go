@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var _ Provider = (*Registry)(nil)
+
+const (
+	kindCounter = "counter"
+	kindGauge   = "gauge"
+)
+
+// Registry is an in-memory Provider that exposes its metrics in the Prometheus text format,
+// so they can be scraped without pulling a metrics client into every service
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+// metric holds the series of a metric, keyed by their joined label values
+type metric struct {
+	mu         sync.Mutex
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	series     map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// Counter registers (or returns the already registered) counter with the given name
+func (r *Registry) Counter(name, help string, labelNames ...string) Counter {
+	return r.register(name, help, kindCounter, labelNames)
+}
+
+// Gauge registers (or returns the already registered) gauge with the given name
+func (r *Registry) Gauge(name, help string, labelNames ...string) Gauge {
+	return r.register(name, help, kindGauge, labelNames)
+}
+
+// register panics when a name is reused with another kind or label set, as it is a programming error
+func (r *Registry) register(name, help, kind string, labelNames []string) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, ok := r.metrics[name]; ok {
+		if m.kind != kind || strings.Join(m.labelNames, ",") != strings.Join(labelNames, ",") {
+			panic(fmt.Sprintf("metrics: %q already registered as a %s with labels %v", name, m.kind, m.labelNames))
+		}
+		return m
+	}
+
+	m := &metric{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		series:     make(map[string]*series),
+	}
+	r.metrics[name] = m
+	return m
+}
+
+// Inc increments the counter (or gauge) by one
+func (m *metric) Inc(labelValues ...string) {
+	m.Add(1, labelValues...)
+}
+
+// Add adds delta to the series, counters ignore negative deltas
+func (m *metric) Add(delta float64, labelValues ...string) {
+	if m.kind == kindCounter && delta < 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seriesFor(labelValues).value += delta
+}
+
+// Set replaces the value of the gauge series
+func (m *metric) Set(value float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seriesFor(labelValues).value = value
+}
+
+// seriesFor returns the series of the label values, missing values are recorded as empty
+func (m *metric) seriesFor(labelValues []string) *series {
+	values := make([]string, len(m.labelNames))
+	copy(values, labelValues)
+
+	key := strings.Join(values, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: values}
+		m.series[key] = s
+	}
+	return s
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.Lock()
+		m := r.metrics[name]
+		r.mu.Unlock()
+
+		if err := m.writeText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *metric) writeText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+	fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.kind)
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := m.series[key]
+		b.WriteString(m.name)
+		if len(m.labelNames) > 0 {
+			b.WriteByte('{')
+			for i, labelName := range m.labelNames {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, `%s="%s"`, labelName, escapeLabelValue(s.labelValues[i]))
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the metrics for scraping
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// escapeHelp escapes the characters the text format reserves in HELP lines
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// escapeLabelValue escapes the characters the text format reserves in label values
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
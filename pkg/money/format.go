@@ -0,0 +1,155 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+)
+
+// currencyInfo describes how a currency is represented to humans
+type currencyInfo struct {
+	Symbol     string // Symbol is the display symbol of the currency (e.g., "R$")
+	MinorUnits int    // MinorUnits is the number of decimal places of the currency (e.g., 2 for cents)
+}
+
+// localeInfo describes the number formatting conventions of a locale
+type localeInfo struct {
+	DecimalSep  string // DecimalSep separates the integer and fractional parts (e.g., "," in pt-BR)
+	GroupSep    string // GroupSep separates thousands groups (e.g., "." in pt-BR)
+	SymbolSpace bool   // SymbolSpace adds a space between the symbol and the number (e.g., "R$ 1,00")
+	SymbolAfter bool   // SymbolAfter places the symbol after the number (e.g., "1,00 €")
+}
+
+// currencies holds the currencies known by the platform, keyed by ISO 4217 code
+var currencies = map[string]currencyInfo{
+	"BRL": {Symbol: "R$", MinorUnits: 2},
+	"USD": {Symbol: "$", MinorUnits: 2},
+	"EUR": {Symbol: "€", MinorUnits: 2},
+	"GBP": {Symbol: "£", MinorUnits: 2},
+	"JPY": {Symbol: "¥", MinorUnits: 0},
+}
+
+// locales holds the supported formatting locales, keyed by lowercase language tag
+// Lookups fall back to the bare language (e.g., "pt-PT" -> "pt") and then to defaultLocale
+var locales = map[string]localeInfo{
+	"pt":    {DecimalSep: ",", GroupSep: ".", SymbolSpace: true},
+	"pt-br": {DecimalSep: ",", GroupSep: ".", SymbolSpace: true},
+	"en":    {DecimalSep: ".", GroupSep: ","},
+	"en-us": {DecimalSep: ".", GroupSep: ","},
+	"es":    {DecimalSep: ",", GroupSep: ".", SymbolSpace: true, SymbolAfter: true},
+	"de":    {DecimalSep: ",", GroupSep: ".", SymbolSpace: true, SymbolAfter: true},
+	"fr":    {DecimalSep: ",", GroupSep: " ", SymbolSpace: true, SymbolAfter: true},
+}
+
+// defaultLocale is used when the requested locale is empty or unknown
+const defaultLocale = "pt-br"
+
+// IsKnownCurrency reports whether the currency code is supported by the platform
+func IsKnownCurrency(currency string) bool {
+	_, ok := currencies[strings.ToUpper(currency)]
+	return ok
+}
+
+// IsKnownLocale reports whether amounts can be formatted following the conventions of the locale (or of its language)
+func IsKnownLocale(locale string) bool {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	lang, _, _ := strings.Cut(tag, "-")
+	_, exact := locales[tag]
+	_, bare := locales[lang]
+	return exact || bare
+}
+
+// MinorUnits returns the number of decimal places of the currency
+// Unknown currencies are assumed to have 2 decimal places, the most common case
+func MinorUnits(currency string) int {
+	if info, ok := currencies[strings.ToUpper(currency)]; ok {
+		return info.MinorUnits
+	}
+	return 2
+}
+
+// Format renders the value for humans following the conventions of the given locale
+// (e.g., "R$ 1.234,56" for pt-BR, "$1,234.56" for en-US)
+// Empty or unknown locales fall back to pt-BR and unknown currencies use their code as symbol
+func (m Money) Format(locale string) string {
+	loc := lookupLocale(locale)
+
+	symbol := m.Currency
+	if info, ok := currencies[m.Currency]; ok {
+		symbol = info.Symbol
+	}
+
+	negative := m.Amount < 0
+	number := formatNumber(m.Amount, MinorUnits(m.Currency), loc.DecimalSep, loc.GroupSep)
+	if negative {
+		number = strings.TrimPrefix(number, "-")
+	}
+
+	sep := ""
+	if loc.SymbolSpace {
+		sep = " "
+	}
+
+	var out string
+	if loc.SymbolAfter {
+		out = number + sep + symbol
+	} else {
+		out = symbol + sep + number
+	}
+
+	if negative {
+		return "-" + out
+	}
+	return out
+}
+
+// lookupLocale resolves a language tag (e.g., "pt-BR", "en_US") to its formatting conventions
+func lookupLocale(locale string) localeInfo {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if info, ok := locales[tag]; ok {
+		return info
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	if info, ok := locales[lang]; ok {
+		return info
+	}
+	return locales[defaultLocale]
+}
+
+// formatNumber renders an amount in minor units as a decimal number using the given separators
+func formatNumber(amount int64, minorUnits int, decimalSep, groupSep string) string {
+	negative := amount < 0
+
+	// Work on the unsigned magnitude so math.MinInt64 does not overflow
+	magnitude := uint64(amount)
+	if negative {
+		magnitude = uint64(-(amount + 1)) + 1
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if len(digits) <= minorUnits {
+		digits = strings.Repeat("0", minorUnits-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-minorUnits]
+	fracPart := digits[len(digits)-minorUnits:]
+
+	if groupSep != "" {
+		var b strings.Builder
+		for i, r := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				b.WriteString(groupSep)
+			}
+			b.WriteRune(r)
+		}
+		intPart = b.String()
+	}
+
+	out := intPart
+	if minorUnits > 0 {
+		out += decimalSep + fracPart
+	}
+	if negative {
+		out = "-" + out
+	}
+	return out
+}
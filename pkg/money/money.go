@@ -0,0 +1,154 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+var (
+	ErrCurrencyMismatch = errx.New(errx.CategoryValidation, "CURRENCY_MISMATCH", "money amounts have different currencies")
+	ErrAmountOverflow   = errx.New(errx.CategoryValidation, "AMOUNT_OVERFLOW", "money amount is out of the supported range")
+	ErrInvalidRatios    = errx.New(errx.CategoryValidation, "INVALID_ALLOCATION_RATIOS", "allocation ratios must be non-negative and sum to more than zero")
+)
+
+// Money is an immutable monetary value expressed in the minor unit of its currency
+// (e.g., cents for BRL and USD), so no floating point is ever involved in the math
+type Money struct {
+	Amount   int64  // Amount is the value in the currency's minor unit (e.g., 1050 = R$ 10,50)
+	Currency string // Currency is the ISO 4217 currency code (e.g., "BRL")
+}
+
+// New creates a new Money with the given amount in minor units and currency code
+func New(amount int64, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: strings.ToUpper(strings.TrimSpace(currency)),
+	}
+}
+
+// Zero creates a zero-valued Money in the given currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative reports whether the amount is lower than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// SameCurrency reports whether both values share the same currency
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency
+}
+
+// Add returns the sum of both values, failing on currency mismatch or overflow
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, m.mismatch(other)
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrAmountOverflow.With("currency", m.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference between both values, failing on currency mismatch or overflow
+func (m Money) Sub(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, m.mismatch(other)
+	}
+	if (other.Amount < 0 && m.Amount > math.MaxInt64+other.Amount) ||
+		(other.Amount > 0 && m.Amount < math.MinInt64+other.Amount) {
+		return Money{}, ErrAmountOverflow.With("currency", m.Currency)
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Multiply returns the value multiplied by an integer factor, failing on overflow
+func (m Money) Multiply(factor int64) (Money, error) {
+	if m.Amount == 0 || factor == 0 {
+		return Zero(m.Currency), nil
+	}
+	result := m.Amount * factor
+	if result/factor != m.Amount || (m.Amount == -1 && factor == math.MinInt64) || (factor == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrAmountOverflow.With("currency", m.Currency)
+	}
+	return Money{Amount: result, Currency: m.Currency}, nil
+}
+
+// MultiplyFraction returns the value multiplied by num/den, truncating toward zero (e.g., a unit price times a fractional quantity)
+func (m Money) MultiplyFraction(num, den int64) (Money, error) {
+	if num < 0 || den <= 0 {
+		return Money{}, ErrInvalidRatios
+	}
+	result, err := mulDiv(m.Amount, num, den)
+	if err != nil {
+		return Money{}, ErrAmountOverflow.With("currency", m.Currency)
+	}
+	return Money{Amount: result, Currency: m.Currency}, nil
+}
+
+// Negate returns the value with its sign inverted, failing on overflow
+func (m Money) Negate() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow.With("currency", m.Currency)
+	}
+	return Money{Amount: -m.Amount, Currency: m.Currency}, nil
+}
+
+// Sum adds up all values, which must share the given currency
+// An empty slice results in a zero-valued Money of that currency
+func Sum(currency string, values ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, v := range values {
+		var err error
+		if total, err = total.Add(v); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Allocate splits the value proportionally to the given ratios without losing any minor unit, following DefaultRounding
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	return DefaultRounding.Allocate(m, ratios...)
+}
+
+// Split divides the value into n equal shares, following DefaultRounding
+func (m Money) Split(n int) ([]Money, error) {
+	return DefaultRounding.Split(m, n)
+}
+
+// String returns a locale-independent representation of the value (e.g., "1234.56 BRL")
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+// Decimal returns the amount as a locale-independent decimal number without currency (e.g., "1234.56")
+func (m Money) Decimal() string {
+	return formatNumber(m.Amount, MinorUnits(m.Currency), ".", "")
+}
+
+// mismatch builds the currency mismatch error carrying both currencies as metadata
+func (m Money) mismatch(other Money) error {
+	return ErrCurrencyMismatch.With("currencies", []string{m.Currency, other.Currency})
+}
+
+// mulDiv computes amount*num/den, truncating toward zero, and fails when the result does not fit in an int64
+func mulDiv(amount, num, den int64) (int64, error) {
+	return RoundingPolicy{Mode: RoundDown}.mulDiv(amount, num, den)
+}
@@ -0,0 +1,138 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+var (
+	ErrInvalidAmount    = errx.New(errx.CategoryValidation, "INVALID_AMOUNT", "amount is not a valid decimal number")
+	ErrAmountTooPrecise = errx.New(errx.CategoryValidation, "AMOUNT_TOO_PRECISE", "amount has more decimal places than its currency allows")
+)
+
+// Parse converts a human-typed decimal amount into Money of the given currency
+// Both "1.234,56" (pt-BR) and "1,234.56" / "1234.56" (en-US) styles are accepted:
+//   - when both separators are present, the last one is the decimal separator
+//   - a separator repeated more than once is a thousands separator
+//   - a single separator followed by exactly 3 digits is a thousands separator, unless
+//     the currency itself has 3 decimal places or the digits before it are a leading zero
+//     (e.g., "0.500", rejected as too precise rather than read as 500); otherwise it is the decimal separator
+//
+// Failures are returned as DomainErrors carrying the offending input and the reason as metadata
+func Parse(input, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	minorUnits := MinorUnits(currency)
+
+	s := strings.TrimSpace(input)
+	invalid := func(reason string) (Money, error) {
+		return Money{}, ErrInvalidAmount.With("input", input).With("reason", reason)
+	}
+
+	negative := false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		negative, s = true, rest
+	} else if rest, ok := strings.CutPrefix(s, "+"); ok {
+		s = rest
+	}
+	if s == "" {
+		return invalid("amount is empty")
+	}
+
+	for _, r := range s {
+		if (r < '0' || r > '9') && r != '.' && r != ',' {
+			return invalid("only digits, '.' and ',' are allowed")
+		}
+	}
+
+	decimalSep, groupSep := detectSeparators(s, minorUnits)
+
+	intPart, fracPart := s, ""
+	if decimalSep != "" {
+		var found bool
+		intPart, fracPart, found = strings.Cut(s, decimalSep)
+		if found && strings.Contains(fracPart, decimalSep) {
+			return invalid("decimal separator appears more than once")
+		}
+		if strings.ContainsAny(fracPart, ".,") {
+			return invalid("thousands separator found after the decimal separator")
+		}
+		if fracPart == "" {
+			return invalid("missing digits after the decimal separator")
+		}
+	}
+
+	if groupSep != "" {
+		groups := strings.Split(intPart, groupSep)
+		for i, g := range groups {
+			if (i == 0 && (len(g) < 1 || len(g) > 3)) || (i > 0 && len(g) != 3) {
+				return invalid("thousands separators must split the number in groups of 3 digits")
+			}
+			if i == 0 && g[0] == '0' {
+				return invalid("thousands separators cannot follow a leading zero")
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	if intPart == "" {
+		intPart = "0"
+	}
+	if strings.ContainsAny(intPart, ".,") {
+		return invalid("mixed or misplaced separators")
+	}
+
+	if len(fracPart) > minorUnits {
+		return Money{}, ErrAmountTooPrecise.With("input", input).With("max_decimal_places", minorUnits)
+	}
+	fracPart += strings.Repeat("0", minorUnits-len(fracPart))
+
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if digits == "" {
+		return Zero(currency), nil
+	}
+	if negative {
+		digits = "-" + digits
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, ErrAmountOverflow.With("input", input)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// detectSeparators infers which of '.' and ',' is used as decimal and which as thousands separator
+// An empty result means the corresponding separator is not used in s
+func detectSeparators(s string, minorUnits int) (decimalSep, groupSep string) {
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastDot > lastComma {
+			return ".", ","
+		}
+		return ",", "."
+	case lastDot < 0 && lastComma < 0:
+		return "", ""
+	}
+
+	sep := "."
+	if lastComma >= 0 {
+		sep = ","
+	}
+
+	if strings.Count(s, sep) > 1 {
+		return "", sep
+	}
+
+	// A leading zero is never followed by thousands, so "0.500" is half a unit, too precise for most currencies
+	whole, frac, _ := strings.Cut(s, sep)
+	if len(frac) == 3 && minorUnits != 3 && whole != "" && whole[0] != '0' {
+		return "", sep
+	}
+	return sep, ""
+}
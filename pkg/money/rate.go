@@ -0,0 +1,106 @@
+package money
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+var ErrInvalidExchangeRate = errx.New(errx.CategoryValidation, "INVALID_EXCHANGE_RATE", "exchange rate must be a positive decimal number")
+
+// RateDecimals is the number of decimal places kept by an ExchangeRate
+const RateDecimals = 8
+
+// ExchangeRate is how many units of To one unit of From is worth, as a fixed-point
+// number with RateDecimals decimal places (e.g., 543200000 is 5.432)
+type ExchangeRate struct {
+	From  string
+	To    string
+	Value int64
+}
+
+// RateOf returns the rate at which original was converted into converted (e.g., 10.00 USD into 54.32 BRL is 5.432)
+// Both values must be non-zero and share the same sign, the rate is truncated to RateDecimals decimal places
+func RateOf(original, converted Money) (ExchangeRate, error) {
+	if original.IsZero() || converted.IsZero() || original.IsNegative() != converted.IsNegative() {
+		return ExchangeRate{}, ErrInvalidExchangeRate.With("original", original.String()).With("converted", converted.String())
+	}
+
+	if original.Amount == math.MinInt64 || converted.Amount == math.MinInt64 {
+		return ExchangeRate{}, ErrAmountOverflow
+	}
+	from, to := abs(original.Amount), abs(converted.Amount)
+
+	// rate = (to / 10^toUnits) / (from / 10^fromUnits), scaled by 10^RateDecimals
+	num := pow10(MinorUnits(original.Currency) + RateDecimals)
+	den := pow10(MinorUnits(converted.Currency))
+	if from > math.MaxInt64/den {
+		return ExchangeRate{}, ErrAmountOverflow.With("currency", original.Currency)
+	}
+
+	value, err := mulDiv(to, num, from*den)
+	if err != nil || value == 0 {
+		return ExchangeRate{}, ErrInvalidExchangeRate.With("original", original.String()).With("converted", converted.String())
+	}
+
+	return ExchangeRate{From: original.Currency, To: converted.Currency, Value: value}, nil
+}
+
+// ParseRate reads a rate in the locale-independent form returned by String (e.g., "5.432")
+func ParseRate(from, to, value string) (ExchangeRate, error) {
+	invalid := func() (ExchangeRate, error) {
+		return ExchangeRate{}, ErrInvalidExchangeRate.With("input", value)
+	}
+
+	whole, frac, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if whole == "" || len(frac) > RateDecimals || !isDigits(whole) || !isDigits(frac) {
+		return invalid()
+	}
+
+	parsed, err := strconv.ParseInt(whole+frac+strings.Repeat("0", RateDecimals-len(frac)), 10, 64)
+	if err != nil || parsed <= 0 {
+		return invalid()
+	}
+
+	return ExchangeRate{
+		From:  strings.ToUpper(strings.TrimSpace(from)),
+		To:    strings.ToUpper(strings.TrimSpace(to)),
+		Value: parsed,
+	}, nil
+}
+
+// String returns the rate as a plain decimal without trailing zeros (e.g., "5.432")
+func (r ExchangeRate) String() string {
+	s := formatNumber(r.Value, RateDecimals, ".", "")
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// abs returns the absolute value of an amount other than math.MinInt64
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+// pow10 returns 10^n for the small exponents used by currencies and rates
+func pow10(n int) int64 {
+	result := int64(1)
+	for range n {
+		result *= 10
+	}
+	return result
+}
+
+// isDigits reports whether s only holds ASCII digits, an empty string included
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+package money
+
+import (
+	"cmp"
+	"math"
+	"math/bits"
+	"slices"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+var ErrInvalidRoundingMode = errx.New(errx.CategoryValidation, "INVALID_ROUNDING_MODE", "rounding mode must be HALF_EVEN, HALF_UP, DOWN or UP")
+
+// RoundingMode decides which minor unit a value falling between two of them is rounded to
+type RoundingMode string
+
+const (
+	RoundHalfEven RoundingMode = "HALF_EVEN" // RoundHalfEven rounds to the nearest unit, ties to the even one (banker's rounding)
+	RoundHalfUp   RoundingMode = "HALF_UP"   // RoundHalfUp rounds to the nearest unit, ties away from zero
+	RoundDown     RoundingMode = "DOWN"      // RoundDown truncates toward zero
+	RoundUp       RoundingMode = "UP"        // RoundUp rounds away from zero
+)
+
+// RoundingPolicy rounds the results of currency conversions and allocations to the minor unit of their currency
+// Allocations hand the units left over by rounding to the shares whose exact value was closest to the next unit,
+// ties going to the earliest share, so the shares always add up to the amount allocated
+type RoundingPolicy struct {
+	Mode RoundingMode
+}
+
+// DefaultRounding is the policy used when none is configured, rounding half to even
+var DefaultRounding = RoundingPolicy{Mode: RoundHalfEven}
+
+// ParseRoundingPolicy reads a rounding mode (e.g., "HALF_EVEN"), the default policy applying when it is empty
+func ParseRoundingPolicy(mode string) (RoundingPolicy, error) {
+	switch m := RoundingMode(strings.ToUpper(strings.TrimSpace(mode))); m {
+	case "":
+		return DefaultRounding, nil
+	case RoundHalfEven, RoundHalfUp, RoundDown, RoundUp:
+		return RoundingPolicy{Mode: m}, nil
+	default:
+		return RoundingPolicy{}, ErrInvalidRoundingMode.With("mode", mode)
+	}
+}
+
+// Scale returns the value multiplied by num/den, rounded by the policy (e.g., a unit price times a fractional quantity)
+func (p RoundingPolicy) Scale(m Money, num, den int64) (Money, error) {
+	if num < 0 || den <= 0 {
+		return Money{}, ErrInvalidRatios
+	}
+	result, err := p.mulDiv(m.Amount, num, den)
+	if err != nil {
+		return Money{}, ErrAmountOverflow.With("currency", m.Currency)
+	}
+	return Money{Amount: result, Currency: m.Currency}, nil
+}
+
+// Convert returns the value converted at the rate into its target currency, rounded by the policy
+func (p RoundingPolicy) Convert(m Money, rate ExchangeRate) (Money, error) {
+	if m.Currency != rate.From {
+		return Money{}, ErrCurrencyMismatch.With("currencies", []string{m.Currency, rate.From})
+	}
+	if rate.Value <= 0 {
+		return Money{}, ErrInvalidExchangeRate.With("rate", rate.String())
+	}
+
+	// converted = amount / 10^fromUnits * rate / 10^RateDecimals * 10^toUnits
+	num, den := rate.Value, int64(1)
+	if exp := RateDecimals + MinorUnits(rate.From) - MinorUnits(rate.To); exp >= 0 {
+		den = pow10(exp)
+	} else {
+		factor := pow10(-exp)
+		if num > math.MaxInt64/factor {
+			return Money{}, ErrAmountOverflow.With("currency", rate.To)
+		}
+		num *= factor
+	}
+
+	result, err := p.mulDiv(m.Amount, num, den)
+	if err != nil {
+		return Money{}, ErrAmountOverflow.With("currency", rate.To)
+	}
+	return Money{Amount: result, Currency: rate.To}, nil
+}
+
+// Allocate splits the value proportionally to the given ratios, rounding each share by the policy
+// and then moving single units between shares until they add up exactly to the original amount
+func (p RoundingPolicy) Allocate(m Money, ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	var total int64
+	for _, r := range ratios {
+		if r < 0 || total > math.MaxInt64-r {
+			return nil, ErrInvalidRatios
+		}
+		total += r
+	}
+	if total == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	// Shares are rounded on the magnitude of the amount, so negative amounts mirror the positive ones
+	magnitude, negative := unsigned(m.Amount)
+	type share struct {
+		index   int
+		amount  uint64
+		rem     uint64 // rem is what the rounding discarded of the exact share, over total
+		roundUp bool
+	}
+	shares := make([]share, len(ratios))
+	var allocated uint64
+	for i, r := range ratios {
+		quo, rem, err := mulDivRem(magnitude, uint64(r), uint64(total))
+		if err != nil {
+			return nil, ErrAmountOverflow.With("currency", m.Currency)
+		}
+		s := share{index: i, amount: quo, rem: rem, roundUp: p.roundsUp(quo, rem, uint64(total))}
+		if s.roundUp {
+			s.amount++
+		}
+		shares[i] = s
+		allocated += s.amount
+	}
+
+	// Too many units were handed out when the rounding went up more often than the exact shares warrant,
+	// they are taken back from the rounded-up shares furthest from the next unit, and the other way round
+	if allocated != magnitude {
+		slices.SortStableFunc(shares, func(a, b share) int {
+			return cmp.Compare(b.rem, a.rem)
+		})
+		if allocated > magnitude {
+			for i := len(shares) - 1; allocated > magnitude; i-- {
+				if shares[i].roundUp {
+					shares[i].amount--
+					allocated--
+				}
+			}
+		} else {
+			for i := 0; allocated < magnitude; i++ {
+				if !shares[i].roundUp && shares[i].rem > 0 {
+					shares[i].amount++
+					allocated++
+				}
+			}
+		}
+	}
+
+	result := make([]Money, len(ratios))
+	for _, s := range shares {
+		amount := int64(s.amount)
+		if negative {
+			amount = -amount
+		}
+		result[s.index] = Money{Amount: amount, Currency: m.Currency}
+	}
+	return result, nil
+}
+
+// Split divides the value into n equal shares, rounded by the policy
+func (p RoundingPolicy) Split(m Money, n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, ErrInvalidRatios
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return p.Allocate(m, ratios...)
+}
+
+// mulDiv computes amount*num/den rounded by the policy, failing when the result does not fit in an int64
+func (p RoundingPolicy) mulDiv(amount, num, den int64) (int64, error) {
+	magnitude, negative := unsigned(amount)
+	quo, rem, err := mulDivRem(magnitude, uint64(num), uint64(den))
+	if err != nil {
+		return 0, err
+	}
+	if p.roundsUp(quo, rem, uint64(den)) && quo < math.MaxUint64 {
+		quo++
+	}
+	if quo > math.MaxInt64 {
+		return 0, ErrAmountOverflow
+	}
+	if negative {
+		return -int64(quo), nil
+	}
+	return int64(quo), nil
+}
+
+// roundsUp reports whether the magnitude quo+rem/den is rounded away from zero, rem being lower than den
+func (p RoundingPolicy) roundsUp(quo, rem, den uint64) bool {
+	if rem == 0 {
+		return false
+	}
+	// den fits in an int64, so doubling the remainder can not overflow
+	half := 2 * rem
+	switch p.Mode {
+	case RoundDown:
+		return false
+	case RoundUp:
+		return true
+	case RoundHalfUp:
+		return half >= den
+	default:
+		return half > den || (half == den && quo%2 == 1)
+	}
+}
+
+// unsigned returns the magnitude of an amount, math.MinInt64 included, and whether it is negative
+func unsigned(amount int64) (uint64, bool) {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1, true
+	}
+	return uint64(amount), false
+}
+
+// mulDivRem computes magnitude*num/den truncated with its remainder, failing when the quotient does not fit in a uint64
+func mulDivRem(magnitude, num, den uint64) (uint64, uint64, error) {
+	hi, lo := bits.Mul64(magnitude, num)
+	if hi >= den {
+		return 0, 0, ErrAmountOverflow
+	}
+	quo, rem := bits.Div64(hi, lo, den)
+	return quo, rem, nil
+}
@@ -0,0 +1,261 @@
+// Package outbox relays the events the services write to their outbox, in the transaction of the change
+// they describe, to the broker
+//
+// Each source is tailed from the cursor of the last message relayed, which is stored once a whole batch was
+// published, so a message may be published again after a crash: consumers deduplicate by the event ID.
+// Messages are published with the aggregate ID as key, landing on the same partition, and the messages of an
+// aggregate are published one after the other so consumers read them in the order they were written
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/consumer"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/pkg/retry"
+	"github.com/google/uuid"
+)
+
+// Headers of the published records, so consumers route an event without decoding it
+const (
+	HeaderEventID      = "x-event-id"
+	HeaderEventType    = "x-event-type"
+	HeaderEventVersion = "x-event-version"
+)
+
+// Message is an event written to an outbox
+type Message struct {
+	Cursor        string    // Cursor is the position of the message in its source, relaying resumes after it
+	EventID       uuid.UUID // EventID is the ID of the envelope
+	EventType     string
+	EventVersion  int
+	AggregateType string // AggregateType names the topic the message is published to (e.g., "account")
+	AggregateID   string // AggregateID is the key of the published record
+	Envelope      []byte // Envelope is the encoded contracts envelope, published as is
+	RecordedAt    time.Time
+}
+
+// Source reads the messages of an outbox in the order they were written
+type Source interface {
+	// Name identifies the source in offsets, logs and metrics (e.g., "ledger")
+	Name() string
+	// Fetch returns up to limit messages written after the cursor and before the given time, oldest first
+	// An empty cursor reads from the start of the outbox
+	Fetch(ctx context.Context, after string, before time.Time, limit int) ([]Message, error)
+}
+
+// OffsetStore keeps the cursor of the last message relayed from each source
+type OffsetStore interface {
+	// Load returns the cursor of the source, empty when nothing was relayed yet
+	Load(ctx context.Context, source string) (string, error)
+	Store(ctx context.Context, source, cursor string) error
+}
+
+// Config tunes a Relay, zero values falling back to the defaults
+type Config struct {
+	BatchSize    int           // BatchSize is how many messages are read from a source at a time, 500 by default
+	PollInterval time.Duration // PollInterval is the wait before polling a source again once it was drained, 1s by default
+	// SettleDelay is how old a message must be to be relayed, 5s by default
+	// Messages are read a little behind so a write committed after a newer one is not skipped by the cursor
+	SettleDelay time.Duration
+	TopicPrefix string // TopicPrefix is prepended to the aggregate type to name the topic, "fintrack." by default
+	// Retry spaces the attempts of a message the producer failed to publish, before the batch is given up
+	// and read again at the next poll. The producer classifies its errors (see retry.Retryable)
+	Retry retry.Policy
+}
+
+// Relay tails the outbox of each source and publishes its messages through the producer
+type Relay struct {
+	sources   []Source
+	offsets   OffsetStore
+	producer  consumer.Producer
+	cfg       Config
+	logger    *slog.Logger
+	clock     clock.Clock
+	lag       metrics.Gauge
+	published metrics.Counter
+}
+
+// New creates a new Relay
+func New(sources []Source, offsets OffsetStore, producer consumer.Producer, cfg Config, logger *slog.Logger, clock clock.Clock, provider metrics.Provider) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.SettleDelay <= 0 {
+		cfg.SettleDelay = 5 * time.Second
+	}
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = "fintrack."
+	}
+
+	return &Relay{
+		sources:  sources,
+		offsets:  offsets,
+		producer: producer,
+		cfg:      cfg,
+		logger:   logger.With(slog.String("component", "outbox_relay")),
+		clock:    clock,
+		lag: provider.Gauge("outbox_relay_lag_seconds",
+			"Age of the oldest message waiting to be relayed, zero once the source is drained", "source"),
+		published: provider.Counter("outbox_relay_published_total", "Messages published to the broker", "source"),
+	}
+}
+
+// Run relays the messages of every source until ctx is done
+func (r *Relay) Run(ctx context.Context) error {
+	if len(r.sources) == 0 {
+		return errors.New("the outbox relay needs at least one source")
+	}
+
+	var wg sync.WaitGroup
+	for _, source := range r.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.tail(ctx, source)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Replay moves the cursor of a source, so the messages written after it are published again
+// An empty cursor replays the whole outbox, the relay must be stopped while the cursor is moved
+func (r *Relay) Replay(ctx context.Context, source, cursor string) error {
+	known := false
+	for _, s := range r.sources {
+		if s.Name() == source {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown outbox source %q", source)
+	}
+
+	if err := r.offsets.Store(ctx, source, cursor); err != nil {
+		return fmt.Errorf("failed to store replay cursor of %s: %w", source, err)
+	}
+	r.logger.Info("outbox cursor moved for replay", slog.String("source", source), slog.String("cursor", cursor))
+	return nil
+}
+
+// tail relays the batches of a source, polling again right away while batches come full
+func (r *Relay) tail(ctx context.Context, source Source) {
+	for {
+		relayed, err := r.relayBatch(ctx, source)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("failed to relay outbox batch",
+				slog.String("source", source.Name()),
+				slog.String("error", err.Error()),
+			)
+		}
+		if err == nil && relayed == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// relayBatch publishes the next batch of a source and stores its cursor, returning how many messages it published
+func (r *Relay) relayBatch(ctx context.Context, source Source) (int, error) {
+	name := source.Name()
+
+	cursor, err := r.offsets.Load(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load cursor: %w", err)
+	}
+
+	now := r.clock.Now()
+	messages, err := source.Fetch(ctx, cursor, now.Add(-r.cfg.SettleDelay), r.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+	if len(messages) == 0 {
+		r.lag.Set(0, name)
+		return 0, nil
+	}
+	r.lag.Set(now.Sub(messages[0].RecordedAt).Seconds(), name)
+
+	if err := r.publish(ctx, messages); err != nil {
+		return 0, err
+	}
+	r.published.Add(float64(len(messages)), name)
+
+	last := messages[len(messages)-1].Cursor
+	if err := r.offsets.Store(ctx, name, last); err != nil {
+		return 0, fmt.Errorf("failed to store cursor: %w", err)
+	}
+
+	r.logger.Debug("outbox batch relayed",
+		slog.String("source", name),
+		slog.Int("messages", len(messages)),
+		slog.String("cursor", last),
+	)
+	return len(messages), nil
+}
+
+// publish publishes the messages of each aggregate in order, aggregates being published concurrently
+func (r *Relay) publish(ctx context.Context, messages []Message) error {
+	type aggregate struct{ typ, id string }
+
+	var order []aggregate
+	byAggregate := make(map[aggregate][]Message)
+	for _, msg := range messages {
+		key := aggregate{typ: msg.AggregateType, id: msg.AggregateID}
+		if _, ok := byAggregate[key]; !ok {
+			order = append(order, key)
+		}
+		byAggregate[key] = append(byAggregate[key], msg)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(order))
+	for i, key := range order {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, msg := range byAggregate[key] {
+				err := retry.Do(ctx, r.cfg.Retry, func(ctx context.Context) error {
+					return r.producer.Produce(ctx, r.record(msg))
+				})
+				if err != nil {
+					errs[i] = fmt.Errorf("failed to publish event %s: %w", msg.EventID, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// record builds the broker record of a message
+func (r *Relay) record(msg Message) consumer.Record {
+	return consumer.Record{
+		Topic: r.cfg.TopicPrefix + msg.AggregateType,
+		Key:   []byte(msg.AggregateID),
+		Value: msg.Envelope,
+		Headers: map[string]string{
+			HeaderEventID:      msg.EventID.String(),
+			HeaderEventType:    msg.EventType,
+			HeaderEventVersion: strconv.Itoa(msg.EventVersion),
+		},
+		Timestamp: msg.RecordedAt,
+	}
+}
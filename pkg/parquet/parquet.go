@@ -0,0 +1,325 @@
+// Package parquet writes tables as Parquet files, the columnar format data warehouses load (BigQuery, Athena, Spark...)
+//
+// Only what exports need is supported: flat schemas of required columns, written in a single row group with one
+// uncompressed PLAIN encoded page per column. The metadata is encoded with the Thrift compact protocol, following
+// https://github.com/apache/parquet-format
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// createdBy names the writer in the metadata of the files
+const createdBy = "fintrack parquet"
+
+// Type is the type of the values of a column
+type Type int
+
+const (
+	String    Type = iota // String columns hold UTF-8 text
+	JSON                  // JSON columns hold JSON documents, as text
+	Int32                 // Int32 columns hold int32 values
+	Int64                 // Int64 columns hold int64 values
+	Timestamp             // Timestamp columns hold time.Time values, stored as UTC milliseconds
+)
+
+// Column is a column of the schema of a table
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Table is a Parquet table being written one row at a time
+type Table struct {
+	columns []Column
+	pages   []bytes.Buffer // pages holds the PLAIN encoded values of each column
+	rows    int
+}
+
+// New creates an empty table with the given columns, in order
+func New(columns ...Column) *Table {
+	return &Table{columns: columns, pages: make([]bytes.Buffer, len(columns))}
+}
+
+// Len returns how many rows were appended
+func (t *Table) Len() int {
+	return t.rows
+}
+
+// Append adds a row, the values following the order of the columns and matching their type:
+// string for String and JSON, int32 for Int32, int64 for Int64 and time.Time for Timestamp
+func (t *Table) Append(values ...any) error {
+	if len(values) != len(t.columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(t.columns))
+	}
+
+	encoded := make([][]byte, len(values))
+	for i, value := range values {
+		column := t.columns[i]
+		var ok bool
+		switch column.Type {
+		case String, JSON:
+			var s string
+			if s, ok = value.(string); ok {
+				encoded[i] = binary.LittleEndian.AppendUint32(nil, uint32(len(s)))
+				encoded[i] = append(encoded[i], s...)
+			}
+		case Int32:
+			var n int32
+			if n, ok = value.(int32); ok {
+				encoded[i] = binary.LittleEndian.AppendUint32(nil, uint32(n))
+			}
+		case Int64:
+			var n int64
+			if n, ok = value.(int64); ok {
+				encoded[i] = binary.LittleEndian.AppendUint64(nil, uint64(n))
+			}
+		case Timestamp:
+			var at time.Time
+			if at, ok = value.(time.Time); ok {
+				encoded[i] = binary.LittleEndian.AppendUint64(nil, uint64(at.UnixMilli()))
+			}
+		}
+		if !ok {
+			return fmt.Errorf("invalid value %T for column %s", value, column.Name)
+		}
+	}
+
+	// Values are only written once the whole row is valid, so a rejected row leaves the columns aligned
+	for i := range encoded {
+		t.pages[i].Write(encoded[i])
+	}
+	t.rows++
+	return nil
+}
+
+// Bytes encodes the table as a Parquet file
+func (t *Table) Bytes() []byte {
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	chunks := make([]columnChunk, len(t.columns))
+	var totalSize int64
+	for i := range t.columns {
+		page := t.pages[i].Bytes()
+		header := pageHeader(len(page), t.rows)
+
+		chunks[i] = columnChunk{offset: int64(file.Len()), size: int64(len(header) + len(page))}
+		totalSize += chunks[i].size
+		file.Write(header)
+		file.Write(page)
+	}
+
+	footer := t.fileMetaData(chunks, totalSize)
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.WriteString(magic)
+	return file.Bytes()
+}
+
+// columnChunk is where the page of a column was written in the file
+type columnChunk struct {
+	offset int64
+	size   int64 // size counts the page header, pages not being compressed it is both the compressed and uncompressed size
+}
+
+// Values of the enums of the Parquet metadata
+const (
+	physicalInt32     = 1
+	physicalInt64     = 2
+	physicalByteArray = 6
+
+	repetitionRequired = 0
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+	convertedJSON            = 19
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+
+	pageTypeData = 0
+)
+
+// physical returns the physical type of the column and its converted type, -1 when it has none
+func (c Column) physical() (int32, int32) {
+	switch c.Type {
+	case String:
+		return physicalByteArray, convertedUTF8
+	case JSON:
+		return physicalByteArray, convertedJSON
+	case Int32:
+		return physicalInt32, -1
+	case Timestamp:
+		return physicalInt64, convertedTimestampMillis
+	default:
+		return physicalInt64, -1
+	}
+}
+
+// pageHeader encodes the PageHeader of a data page holding the given number of values
+// Columns being required, the page holds no repetition nor definition levels
+func pageHeader(size, values int) []byte {
+	var e compactEncoder
+	e.i32(1, pageTypeData)
+	e.i32(2, int32(size))
+	e.i32(3, int32(size))
+	e.beginStruct(5) // DataPageHeader
+	e.i32(1, int32(values))
+	e.i32(2, encodingPlain)
+	e.i32(3, encodingRLE)
+	e.i32(4, encodingRLE)
+	e.endStruct()
+	e.endStruct()
+	return e.buf.Bytes()
+}
+
+// fileMetaData encodes the FileMetaData of the footer: the schema and the single row group
+func (t *Table) fileMetaData(chunks []columnChunk, totalSize int64) []byte {
+	var e compactEncoder
+	e.i32(1, 1)
+
+	e.beginList(2, compactStruct, len(t.columns)+1)
+	e.beginElement() // the root of the schema, a group of every column
+	e.binary(4, "schema")
+	e.i32(5, int32(len(t.columns)))
+	e.endStruct()
+	for _, column := range t.columns {
+		physical, converted := column.physical()
+		e.beginElement()
+		e.i32(1, physical)
+		e.i32(3, repetitionRequired)
+		e.binary(4, column.Name)
+		if converted >= 0 {
+			e.i32(6, converted)
+		}
+		e.endStruct()
+	}
+
+	e.i64(3, int64(t.rows))
+
+	e.beginList(4, compactStruct, 1)
+	e.beginElement() // RowGroup
+	e.beginList(1, compactStruct, len(t.columns))
+	for i, column := range t.columns {
+		physical, _ := column.physical()
+		e.beginElement() // ColumnChunk
+		e.i64(2, chunks[i].offset)
+		e.beginStruct(3) // ColumnMetaData
+		e.i32(1, physical)
+		e.beginList(2, compactI32, 2)
+		e.varint(encodingPlain)
+		e.varint(encodingRLE)
+		e.beginList(3, compactBinary, 1)
+		e.rawBinary(column.Name)
+		e.i32(4, codecUncompressed)
+		e.i64(5, int64(t.rows))
+		e.i64(6, chunks[i].size)
+		e.i64(7, chunks[i].size)
+		e.i64(9, chunks[i].offset)
+		e.endStruct()
+		e.endStruct()
+	}
+	e.i64(2, totalSize)
+	e.i64(3, int64(t.rows))
+	e.endStruct()
+
+	e.binary(6, createdBy)
+	e.endStruct()
+	return e.buf.Bytes()
+}
+
+// ----- Thrift compact protocol ----- //
+
+// Types of the fields and list elements of the compact protocol
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactEncoder writes Thrift structs with the compact protocol, fields being written in increasing ID order
+type compactEncoder struct {
+	buf     bytes.Buffer
+	lastID  int16
+	parents []int16 // parents holds the last field ID of the enclosing structs
+}
+
+// field writes the header of a field, its ID as a delta of the previous field when it fits in 4 bits
+func (e *compactEncoder) field(id int16, typ byte) {
+	if delta := id - e.lastID; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.buf.WriteByte(typ)
+		e.varint(int64(id))
+	}
+	e.lastID = id
+}
+
+func (e *compactEncoder) i32(id int16, v int32) {
+	e.field(id, compactI32)
+	e.varint(int64(v))
+}
+
+func (e *compactEncoder) i64(id int16, v int64) {
+	e.field(id, compactI64)
+	e.varint(v)
+}
+
+func (e *compactEncoder) binary(id int16, s string) {
+	e.field(id, compactBinary)
+	e.rawBinary(s)
+}
+
+// rawBinary writes a string without field header, as list elements are
+func (e *compactEncoder) rawBinary(s string) {
+	e.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	e.buf.WriteString(s)
+}
+
+// varint writes a zigzag encoded integer, as i16, i32 and i64 values are
+func (e *compactEncoder) varint(v int64) {
+	e.buf.Write(binary.AppendUvarint(nil, uint64(v<<1^v>>63)))
+}
+
+// beginList writes the header of a list field of size elements
+func (e *compactEncoder) beginList(id int16, elem byte, size int) {
+	e.field(id, compactList)
+	if size < 15 {
+		e.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	e.buf.WriteByte(0xf0 | elem)
+	e.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+// beginStruct writes the header of a struct field, its fields following until endStruct
+func (e *compactEncoder) beginStruct(id int16) {
+	e.field(id, compactStruct)
+	e.beginElement()
+}
+
+// beginElement starts a struct written as a list element, without field header
+func (e *compactEncoder) beginElement() {
+	e.parents = append(e.parents, e.lastID)
+	e.lastID = 0
+}
+
+// endStruct writes the stop field of the current struct, the outermost one included
+func (e *compactEncoder) endStruct() {
+	e.buf.WriteByte(0)
+	if n := len(e.parents); n > 0 {
+		e.lastID = e.parents[n-1]
+		e.parents = e.parents[:n-1]
+	}
+}
@@ -0,0 +1,174 @@
+// Package pdf writes simple text documents as PDF files (e.g., account statements)
+//
+// Documents are written in the Courier fonts every PDF reader ships, so they need no embedded fonts
+// and columns line up by padding the text. Only the characters of the Windows-1252 encoding are
+// supported, which covers English and Portuguese, the others being written as "?"
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// A4 page in points, with the same margin on every side
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+
+	headingSize = 13
+	textSize    = 9
+
+	// courierAdvance is the width of every Courier glyph, in thousandths of the font size
+	courierAdvance = 600
+)
+
+// line is a line of text placed on a page
+type line struct {
+	text string
+	bold bool
+	size int
+	y    int
+}
+
+// Document is a PDF document being written one line at a time, pages breaking as they fill up
+type Document struct {
+	title string
+	pages [][]line
+	y     int // y is where the next line goes on the last page, from the bottom of the page
+}
+
+// New creates an empty document, the title being shown by the PDF readers
+func New(title string) *Document {
+	d := &Document{title: title}
+	d.NewPage()
+	return d
+}
+
+// Columns is how many characters of body text fit in a line
+func Columns() int {
+	return (pageWidth - 2*margin) * 1000 / (textSize * courierAdvance)
+}
+
+// Heading adds a line of bold text, larger than the body text
+func (d *Document) Heading(text string) {
+	d.add(line{text: text, bold: true, size: headingSize})
+}
+
+// Text adds a line of body text, cut at Columns characters
+func (d *Document) Text(text string) {
+	d.add(line{text: text, size: textSize})
+}
+
+// Bold adds a line of bold body text, cut at Columns characters
+func (d *Document) Bold(text string) {
+	d.add(line{text: text, bold: true, size: textSize})
+}
+
+// Blank adds an empty line
+func (d *Document) Blank() {
+	d.add(line{size: textSize})
+}
+
+// NewPage starts a new page, unless the last one is still empty
+func (d *Document) NewPage() {
+	if len(d.pages) > 0 && len(d.pages[len(d.pages)-1]) == 0 {
+		return
+	}
+	d.pages = append(d.pages, nil)
+	d.y = pageHeight - margin
+}
+
+// add places the line below the previous one, on a new page when the current one is full
+func (d *Document) add(l line) {
+	height := l.size * 3 / 2
+	if d.y-height < margin {
+		d.NewPage()
+	}
+	d.y -= height
+	l.y = d.y
+	if columns := (pageWidth - 2*margin) * 1000 / (l.size * courierAdvance); utf8.RuneCountInString(l.text) > columns {
+		l.text = string([]rune(l.text)[:columns])
+	}
+	last := len(d.pages) - 1
+	d.pages[last] = append(d.pages[last], l)
+}
+
+// Bytes writes the document as a PDF file
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1 to 5 come first, each page then taking two objects: the page and its content
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (fintrack) >>", encode(d.title)))
+
+	for i, page := range d.pages {
+		var content bytes.Buffer
+		for _, l := range page {
+			if l.text == "" {
+				continue
+			}
+			font := "F1"
+			if l.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, l.size, margin, l.y, encode(l.text))
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// winAnsi maps the characters Windows-1252 places apart from Latin-1
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// encode converts the text to Windows-1252, escaping the characters delimiting PDF strings
+func encode(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsi[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
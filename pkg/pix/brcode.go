@@ -0,0 +1,97 @@
+package pix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// pixGUI identifies the PIX merchant account information of a BR Code
+	pixGUI = "br.gov.bcb.pix"
+	// maxQRPayloadLength bounds the BR Codes accepted, dynamic ones carrying a URL rather than the charge itself
+	maxQRPayloadLength = 512
+)
+
+// BR Code fields (EMV QRCPS), identified by their 2 digits ID
+const (
+	fieldPayloadFormat      = "00"
+	fieldMerchantAccount    = "26"
+	fieldCRC                = "63"
+	fieldMerchantAccountGUI = "00"
+)
+
+// ValidateQRPayload checks a PIX "copia e cola" payload (BR Code): its fields must be well formed,
+// it must start with the payload format, carry a PIX merchant account and end with a valid CRC16
+func ValidateQRPayload(payload string) error {
+	if len(payload) > maxQRPayloadLength {
+		return ErrInvalidQRPayload.With("max_length", maxQRPayloadLength)
+	}
+
+	fields, err := parseEMV(payload)
+	if err != nil {
+		return ErrInvalidQRPayload.With("reason", err.Error())
+	}
+	if len(fields) == 0 || fields[0].id != fieldPayloadFormat || fields[0].value != "01" {
+		return ErrInvalidQRPayload.With("reason", "missing payload format indicator")
+	}
+	last := fields[len(fields)-1]
+	if last.id != fieldCRC || len(last.value) != 4 {
+		return ErrInvalidQRPayload.With("reason", "missing CRC")
+	}
+	// The CRC covers the whole payload up to its own ID and length
+	expected := fmt.Sprintf("%04X", crc16(payload[:len(payload)-4]))
+	if !strings.EqualFold(last.value, expected) {
+		return ErrInvalidQRPayload.With("reason", "CRC mismatch")
+	}
+
+	for _, field := range fields {
+		if field.id != fieldMerchantAccount {
+			continue
+		}
+		account, err := parseEMV(field.value)
+		if err == nil && len(account) > 0 && account[0].id == fieldMerchantAccountGUI && strings.EqualFold(account[0].value, pixGUI) {
+			return nil
+		}
+	}
+	return ErrInvalidQRPayload.With("reason", "missing PIX merchant account")
+}
+
+// emvField is a field of an EMV payload, encoded as its ID, the 2 digits length of its value and its value
+type emvField struct {
+	id    string
+	value string
+}
+
+// parseEMV splits an EMV payload into its fields
+func parseEMV(payload string) ([]emvField, error) {
+	fields := make([]emvField, 0)
+	for rest := payload; rest != ""; {
+		if len(rest) < 4 || !isDigits(rest[:4]) {
+			return nil, fmt.Errorf("malformed field at offset %d", len(payload)-len(rest))
+		}
+		length, _ := strconv.Atoi(rest[2:4])
+		if len(rest) < 4+length {
+			return nil, fmt.Errorf("field %s overflows the payload", rest[:2])
+		}
+		fields = append(fields, emvField{id: rest[:2], value: rest[4 : 4+length]})
+		rest = rest[4+length:]
+	}
+	return fields, nil
+}
+
+// crc16 computes the CRC-16/CCITT-FALSE checksum (polynomial 0x1021, initial value 0xFFFF) required by the BR Code
+func crc16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,113 @@
+// Package pix validates the identifiers of PIX, the instant payment system of the Banco Central do Brasil
+package pix
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/taxid"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidKey        = errx.New(errx.CategoryValidation, "INVALID_PIX_KEY", "the PIX key is not a valid CPF, CNPJ, e-mail, phone or random key")
+	ErrInvalidEndToEndID = errx.New(errx.CategoryValidation, "INVALID_PIX_END_TO_END_ID", "the PIX end-to-end ID is malformed")
+	ErrInvalidQRPayload  = errx.New(errx.CategoryValidation, "INVALID_PIX_QR_PAYLOAD", "the PIX QR code payload is malformed")
+)
+
+const (
+	// endToEndIDLength is the length of an end-to-end ID: "E", the 8 digits ISPB of the payer's bank,
+	// the yyyyMMddHHmm UTC time it was created and 11 alphanumeric characters
+	endToEndIDLength = 32
+	// maxEmailKeyLength is the longest e-mail the DICT (the PIX key directory) accepts
+	maxEmailKeyLength = 77
+)
+
+// KeyType is the kind of a PIX key
+type KeyType string
+
+const (
+	KeyCPF   KeyType = "CPF"
+	KeyCNPJ  KeyType = "CNPJ"
+	KeyEmail KeyType = "EMAIL"
+	KeyPhone KeyType = "PHONE" // KeyPhone is an E.164 Brazilian phone number (e.g., +5511987654321)
+	KeyEVP   KeyType = "EVP"   // KeyEVP is a random key, a UUID generated by the DICT
+)
+
+// NormalizeKey validates a PIX key, returning it in the form the DICT stores it with its type
+// CPF and CNPJ keys may be formatted (e.g., "123.456.789-09"), e-mails and random keys are lowercased
+func NormalizeKey(key string) (string, KeyType, error) {
+	key = strings.TrimSpace(key)
+	switch {
+	case key == "":
+		return "", "", ErrInvalidKey
+	case strings.HasPrefix(key, "+"):
+		if isPhoneKey(key) {
+			return key, KeyPhone, nil
+		}
+	case strings.Contains(key, "@"):
+		email := strings.ToLower(key)
+		if isEmailKey(email) {
+			return email, KeyEmail, nil
+		}
+	case len(key) == 36 && strings.Count(key, "-") == 4:
+		if id, err := uuid.Parse(key); err == nil {
+			return id.String(), KeyEVP, nil
+		}
+	default:
+		digits := taxid.StripFormatting(key)
+		if taxid.IsCPF(digits) {
+			return digits, KeyCPF, nil
+		}
+		if taxid.IsCNPJ(digits) {
+			return digits, KeyCNPJ, nil
+		}
+	}
+	return "", "", ErrInvalidKey
+}
+
+// ValidateEndToEndID checks the format of an end-to-end ID, which identifies a PIX transfer across banks
+// (e.g., E00000000202510171530abcdefghijk)
+func ValidateEndToEndID(id string) error {
+	if len(id) != endToEndIDLength || id[0] != 'E' && id[0] != 'e' {
+		return ErrInvalidEndToEndID
+	}
+	if !isDigits(id[1:21]) {
+		return ErrInvalidEndToEndID
+	}
+	if _, err := time.Parse("200601021504", id[9:21]); err != nil {
+		return ErrInvalidEndToEndID
+	}
+	for _, r := range id[21:] {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return ErrInvalidEndToEndID
+		}
+	}
+	return nil
+}
+
+// isPhoneKey reports whether key is a Brazilian phone in E.164: +55, the 2 digits area code and 8 or 9 digits
+func isPhoneKey(key string) bool {
+	digits := strings.TrimPrefix(key, "+55")
+	return len(digits) != len(key) && (len(digits) == 10 || len(digits) == 11) && isDigits(digits) && digits[0] != '0'
+}
+
+// isEmailKey reports whether key looks like an e-mail, the DICT checking it is reachable when the key is registered
+func isEmailKey(key string) bool {
+	local, domain, ok := strings.Cut(key, "@")
+	return ok && len(key) <= maxEmailKeyLength && local != "" && strings.Contains(domain, ".") &&
+		!strings.ContainsAny(key, " \t") && !strings.Contains(domain, "@") &&
+		!strings.HasPrefix(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// isDigits reports whether s is made of ASCII digits only
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueueFull is returned when a key already has as many requests waiting for a slot as its queue holds
+var ErrQueueFull = errors.New("too many requests waiting for a slot")
+
+// ConcurrencyLimiter caps the requests each key (e.g., a user) runs at once, the next ones waiting in a bounded queue
+// for a slot. The slots are kept in the process, since what they protect (e.g., the database pool) is the instance's
+type ConcurrencyLimiter struct {
+	slots     int // slots is how many requests of a key run at once
+	maxQueued int // maxQueued is how many requests of a key wait for a slot, the next ones being rejected
+
+	mu   sync.Mutex
+	keys map[string]*concurrencyKey
+}
+
+// concurrencyKey holds the slots of a key, forgotten once no request of the key runs or waits
+type concurrencyKey struct {
+	slots   chan struct{}
+	waiting int
+}
+
+// NewConcurrencyLimiter creates a ConcurrencyLimiter running up to slots requests of each key and queueing up to
+// maxQueued more
+func NewConcurrencyLimiter(slots, maxQueued int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		slots:     max(slots, 1),
+		maxQueued: max(maxQueued, 0),
+		keys:      make(map[string]*concurrencyKey),
+	}
+}
+
+// Acquire waits for a slot of the key until ctx is done, returning the func releasing it. It fails at once with
+// ErrQueueFull when the queue of the key is full, and with the error of ctx when no slot freed up in time
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	k, ok := l.keys[key]
+	if !ok {
+		k = &concurrencyKey{slots: make(chan struct{}, l.slots)}
+		l.keys[key] = k
+	}
+
+	select {
+	case k.slots <- struct{}{}:
+		l.mu.Unlock()
+		return l.releaser(key, k), nil
+	default:
+	}
+	if k.waiting >= l.maxQueued {
+		l.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	k.waiting++
+	l.mu.Unlock()
+
+	select {
+	case k.slots <- struct{}{}:
+		l.mu.Lock()
+		k.waiting--
+		l.mu.Unlock()
+		return l.releaser(key, k), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		k.waiting--
+		l.forget(key, k)
+		l.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// releaser returns the func releasing a slot of the key, once however many times it is called
+func (l *ConcurrencyLimiter) releaser(key string, k *concurrencyKey) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			<-k.slots
+			l.forget(key, k)
+		})
+	}
+}
+
+// forget drops the key once no request of it runs or waits, the caller holding the lock
+func (l *ConcurrencyLimiter) forget(key string, k *concurrencyKey) {
+	if len(k.slots) == 0 && k.waiting == 0 {
+		delete(l.keys, key)
+	}
+}
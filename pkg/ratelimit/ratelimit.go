@@ -0,0 +1,128 @@
+// Package ratelimit counts the requests of each caller over a sliding window
+//
+// The Redis limiter keeps the windows in Redis, so a limit holds across every instance behind the load balancer,
+// while the memory limiter keeps them in the process, for a single instance or local runs.
+// The HTTP middleware (httpx) and the gRPC interceptor (grpcx) take either through the Limiter interface.
+// The concurrency limiter caps the requests each caller runs at once instead, for the costly routes
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+)
+
+// Limit is how many requests a key may make within a sliding window
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Enabled reports whether the limit is set, a zero limit letting every request through
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+
+// Result is the decision of a limiter for a request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // Remaining is how many more requests the key may make in the current window
+	RetryAfter time.Duration // RetryAfter is when a rejected key may try again, zero when the request was allowed
+}
+
+// Limiter decides whether a key (e.g., "login:203.0.113.7") may make one more request
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	// AllowN records a request costing n requests of the limit (e.g., a report spanning many months), allowing it
+	// only when the key has n requests left in the window
+	AllowN(ctx context.Context, key string, limit Limit, n int) (Result, error)
+}
+
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// sweepEvery is how many calls the memory limiter serves between sweeps of the idle keys
+const sweepEvery = 1024
+
+// MemoryLimiter keeps the sliding windows in the process, so each instance enforces its own limits
+type MemoryLimiter struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+	calls   int
+}
+
+// memoryWindow holds the requests of a key within its window, oldest first
+type memoryWindow struct {
+	requests []time.Time
+	size     time.Duration
+}
+
+// NewMemoryLimiter creates a new MemoryLimiter
+func NewMemoryLimiter(clock clock.Clock) *MemoryLimiter {
+	return &MemoryLimiter{clock: clock, windows: make(map[string]*memoryWindow)}
+}
+
+// Allow records the request when the key is under the limit
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.AllowN(ctx, key, limit, 1)
+}
+
+// AllowN records the n requests when the key has that many left
+func (l *MemoryLimiter) AllowN(ctx context.Context, key string, limit Limit, n int) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	now := l.clock.Now()
+	start := now.Add(-limit.Window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	window, ok := l.windows[key]
+	if !ok {
+		window = &memoryWindow{}
+		l.windows[key] = window
+	}
+	window.size = limit.Window
+	window.requests = dropBefore(window.requests, start)
+
+	n = min(max(n, 1), limit.Requests)
+	if len(window.requests)+n > limit.Requests {
+		// The request fits once enough of the oldest requests left the window
+		waitFor := window.requests[len(window.requests)+n-limit.Requests-1]
+		return Result{Limit: limit.Requests, Remaining: limit.Requests - len(window.requests), RetryAfter: waitFor.Sub(start)}, nil
+	}
+
+	for range n {
+		window.requests = append(window.requests, now)
+	}
+	return Result{Allowed: true, Limit: limit.Requests, Remaining: limit.Requests - len(window.requests)}, nil
+}
+
+// sweep forgets the keys without a request within their window
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, window := range l.windows {
+		if len(window.requests) == 0 || !window.requests[len(window.requests)-1].After(now.Add(-window.size)) {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// dropBefore removes the requests made before start, the requests being sorted
+func dropBefore(requests []time.Time, start time.Time) []time.Time {
+	i := 0
+	for i < len(requests) && !requests[i].After(start) {
+		i++
+	}
+	return requests[i:]
+}
@@ -0,0 +1,299 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// slidingWindowScript keeps the requests of a key in a sorted set scored by their time in microseconds
+// The time is read from Redis, so instances with drifting clocks share the same windows
+// A request costing n requests adds n members, and waits for the n-th oldest member to leave the window when rejected
+// It returns {allowed, remaining, retry after in microseconds}
+const slidingWindowScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local n = tonumber(ARGV[4])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count + n <= limit then
+  for i = 1, n do
+    redis.call('ZADD', KEYS[1], now, ARGV[3] .. ':' .. i)
+  end
+  redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+  return {1, limit - count - n, 0}
+end
+local oldest = redis.call('ZRANGE', KEYS[1], count + n - limit - 1, count + n - limit - 1, 'WITHSCORES')
+return {0, limit - count, tonumber(oldest[2]) + window - now}
+`
+
+// RedisConfig configures the connection of a RedisLimiter
+type RedisConfig struct {
+	Addr        string // Addr is the host:port of the Redis server
+	Password    string
+	DB          int
+	KeyPrefix   string        // KeyPrefix namespaces the keys of the limiter, "ratelimit:" by default
+	Timeout     time.Duration // Timeout bounds dialing and each command, 500ms by default
+	MaxIdleConn int           // MaxIdleConn is how many connections are kept open between requests, 8 by default
+}
+
+// RedisLimiter keeps the sliding windows in Redis, so the limits hold across every instance sharing the server
+type RedisLimiter struct {
+	cfg       RedisConfig
+	scriptSHA string
+	idle      chan *redisConn
+}
+
+// NewRedisLimiter creates a new RedisLimiter, connections being opened on first use
+func NewRedisLimiter(cfg RedisConfig) *RedisLimiter {
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "ratelimit:"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 500 * time.Millisecond
+	}
+	if cfg.MaxIdleConn <= 0 {
+		cfg.MaxIdleConn = 8
+	}
+
+	sum := sha1.Sum([]byte(slidingWindowScript))
+	return &RedisLimiter{
+		cfg:       cfg,
+		scriptSHA: hex.EncodeToString(sum[:]),
+		idle:      make(chan *redisConn, cfg.MaxIdleConn),
+	}
+}
+
+// Allow records the request in the window of the key when it is under the limit
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.AllowN(ctx, key, limit, 1)
+}
+
+// AllowN records the n requests in the window of the key when it has that many left
+func (l *RedisLimiter) AllowN(ctx context.Context, key string, limit Limit, n int) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	args := []string{
+		"1", l.cfg.KeyPrefix + key,
+		strconv.FormatInt(limit.Window.Microseconds(), 10),
+		strconv.Itoa(limit.Requests),
+		uuid.NewString(),
+		strconv.Itoa(min(max(n, 1), limit.Requests)),
+	}
+
+	// The script is cached by Redis after its first run, later calls only send its digest
+	reply, err := l.do(ctx, append([]string{"EVALSHA", l.scriptSHA}, args...)...)
+	var redisErr redisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		reply, err = l.do(ctx, append([]string{"EVAL", slidingWindowScript}, args...)...)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfter, _ := values[2].(int64)
+
+	return Result{
+		Allowed:    allowed == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(retryAfter) * time.Microsecond,
+	}, nil
+}
+
+// Close closes the idle connections
+func (l *RedisLimiter) Close() error {
+	for {
+		select {
+		case conn := <-l.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Ping checks that Redis answers, dialing a connection when none is idle
+func (l *RedisLimiter) Ping(ctx context.Context) error {
+	if _, err := l.do(ctx, "PING"); err != nil {
+		return fmt.Errorf("failed to ping redis at %s: %w", l.cfg.Addr, err)
+	}
+	return nil
+}
+
+// do runs a command on an idle connection, or on a new one when none is idle
+// The connection is discarded when the command failed on the wire, leaving it in an unknown state
+func (l *RedisLimiter) do(ctx context.Context, args ...string) (any, error) {
+	conn, err := l.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(l.cfg.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set redis deadline: %w", err)
+	}
+
+	reply, err := conn.do(args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.Close()
+		return nil, err
+	}
+
+	select {
+	case l.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return reply, err
+}
+
+// conn returns an idle connection or dials a new one, authenticating and selecting the database
+func (l *RedisLimiter) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-l.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: l.cfg.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", l.cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if err := conn.SetDeadline(time.Now().Add(l.cfg.Timeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set redis deadline: %w", err)
+	}
+	if l.cfg.Password != "" {
+		if _, err := conn.do("AUTH", l.cfg.Password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate to redis: %w", err)
+		}
+	}
+	if l.cfg.DB != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(l.cfg.DB)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// ----- RESP ----- //
+
+// redisError is an error reply, the connection remaining usable
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisConn speaks the RESP2 protocol over a connection, just enough to run scripts
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// do writes a command and reads its reply: string, int64, []byte, []any, nil or a redisError
+func (c *redisConn) do(args ...string) (any, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, b.String()); err != nil {
+		return nil, fmt.Errorf("failed to write redis command: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply reads a reply, recursing into arrays
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch prefix, body := line[0], line[1:]; prefix {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis integer %q: %w", body, err)
+		}
+		return n, nil
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis bulk length %q: %w", body, err)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, fmt.Errorf("failed to read redis bulk reply: %w", err)
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis array length %q: %w", body, err)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		// An error within an array is kept as a value, so the rest of the array is still read off the wire
+		values := make([]any, count)
+		for i := range values {
+			value, err := c.readReply()
+			var redisErr redisError
+			if errors.As(err, &redisErr) {
+				values[i] = redisErr
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -0,0 +1,55 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header is the HTTP header carrying the request ID
+	Header = "X-Request-ID"
+
+	// MetadataKey is the gRPC metadata key carrying the request ID between services
+	MetadataKey = "x-request-id"
+)
+
+// key is an unexported type used as the key for the request ID in the context
+// Using an unexported type prevents key collisions with other packages
+type key string
+
+// requestIDKey and traceIDKey are the key values used to store the request and trace IDs in the context
+const (
+	requestIDKey key = "request_id"
+	traceIDKey   key = "trace_id"
+)
+
+// WithRequestID returns a new context that carries the provided request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// FromContext retrieves the request ID from the provided context
+// The boolean result is false when the context carries no request ID
+func FromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok && requestID != ""
+}
+
+// WithTraceID returns a new context that carries the ID of the distributed trace the request belongs to
+// It is set by the tracing middleware, when tracing is enabled
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// TraceIDFromContext retrieves the trace ID from the provided context
+// The boolean result is false when tracing is disabled or the context carries no trace
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	traceID, ok := ctx.Value(traceIDKey).(string)
+	return traceID, ok && traceID != ""
+}
+
+// New generates a new request ID, used when a request arrives without one
+func New() string {
+	return uuid.NewString()
+}
@@ -0,0 +1,152 @@
+// Package retry runs operations again when they fail with a transient error
+//
+// Attempts are spaced by an exponential backoff with jitter, so callers retrying at the same time spread out
+// instead of hitting the recovering dependency together. Only errors classified as retryable are retried:
+// a validation error or a failed condition fails the same way on every attempt
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+// Policy describes how many times and how far apart an operation is attempted
+type Policy struct {
+	MaxAttempts    int              // MaxAttempts counts the first attempt, 3 by default
+	InitialBackoff time.Duration    // InitialBackoff is the wait before the second attempt, 100ms by default
+	MaxBackoff     time.Duration    // MaxBackoff caps the wait between two attempts, 2s by default
+	Multiplier     float64          // Multiplier grows the backoff after each attempt, 2 by default
+	Jitter         float64          // Jitter is the fraction of the backoff randomized, within [0, 1], 0.2 by default
+	Retryable      func(error) bool // Retryable classifies the errors worth another attempt, IsRetryable by default
+}
+
+// withDefaults fills the zero fields of the policy
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 2 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter <= 0 || p.Jitter > 1 {
+		p.Jitter = 0.2
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+// Backoff returns the wait after the given failed attempt, starting at 1, before jitter
+func (p Policy) Backoff(attempt int) time.Duration {
+	p = p.withDefaults()
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if backoff > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// Do calls fn until it succeeds, fails with an error that is not retryable or runs out of attempts
+// The error of the last attempt is returned, and the context being done stops the waits between attempts
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for operations returning a value
+func DoValue[T any](ctx context.Context, policy Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	policy = policy.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return value, permanent.err
+		}
+		if attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			return value, err
+		}
+
+		timer := time.NewTimer(jitter(policy.Backoff(attempt), policy.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, err
+		case <-timer.C:
+		}
+	}
+}
+
+// jitter spreads the backoff uniformly within ±fraction of its value
+func jitter(backoff time.Duration, fraction float64) time.Duration {
+	delta := float64(backoff) * fraction
+	return time.Duration(float64(backoff) - delta + rand.Float64()*2*delta)
+}
+
+// ----- Classification ----- //
+
+// retryableError marks an error as transient, whatever its type
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// permanentError stops the retries, its wrapped error being returned as is
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Retryable marks err as transient, for failures IsRetryable cannot recognize (e.g., a status code)
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// Permanent stops the retries of Do with err, even when the policy would classify it as retryable
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable reports whether err is worth another attempt: errors marked with Retryable, domain errors of
+// the unavailable category and network timeouts
+// The context of the caller being canceled or past its deadline is never retried
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var retryable *retryableError
+	if errors.As(err, &retryable) {
+		return true
+	}
+	if domainErr, ok := errx.AsDomainError(err); ok {
+		return domainErr.Category == errx.CategoryUnavailable
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,81 @@
+// Package taxid validates the Brazilian tax IDs: the CPF of people and the CNPJ of companies
+package taxid
+
+import (
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+)
+
+var ErrInvalidCNPJ = errx.New(errx.CategoryValidation, "INVALID_CNPJ", "the CNPJ is not valid")
+
+// separators are the punctuation of the formatted documents (e.g., "12.345.678/0001-95")
+var separators = strings.NewReplacer(".", "", "-", "", "/", "")
+
+// NormalizeCNPJ validates a CNPJ, formatted or not, returning its 14 digits
+func NormalizeCNPJ(document string) (string, error) {
+	digits := StripFormatting(document)
+	if !IsCNPJ(digits) {
+		return "", ErrInvalidCNPJ
+	}
+	return digits, nil
+}
+
+// FormatCNPJ formats the 14 digits of a CNPJ as written on documents (e.g., "12.345.678/0001-95")
+func FormatCNPJ(digits string) string {
+	if len(digits) != 14 {
+		return digits
+	}
+	return digits[:2] + "." + digits[2:5] + "." + digits[5:8] + "/" + digits[8:12] + "-" + digits[12:]
+}
+
+// StripFormatting removes the punctuation of a formatted CPF or CNPJ
+func StripFormatting(document string) string {
+	return separators.Replace(strings.TrimSpace(document))
+}
+
+// IsCPF reports whether the digits are a CPF with valid check digits
+func IsCPF(digits string) bool {
+	if len(digits) != 11 || !isDigits(digits) || allSame(digits) {
+		return false
+	}
+	return checkDigit(digits[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[9] &&
+		checkDigit(digits[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[10]
+}
+
+// IsCNPJ reports whether the digits are a CNPJ with valid check digits
+func IsCNPJ(digits string) bool {
+	if len(digits) != 14 || !isDigits(digits) || allSame(digits) {
+		return false
+	}
+	return checkDigit(digits[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[12] &&
+		checkDigit(digits[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[13]
+}
+
+// checkDigit computes the modulo 11 check digit of the CPF and CNPJ with the given weights
+func checkDigit(digits string, weights []int) byte {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+// isDigits reports whether s is made of ASCII digits only
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// allSame reports whether every character of s is the same, which passes the check digits of CPF and CNPJ but is never issued
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
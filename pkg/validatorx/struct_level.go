@@ -0,0 +1,39 @@
+package validatorx
+
+import "time"
+
+// StructLevelValidator is implemented by request types whose rules span several fields
+// (e.g., "due_date is required unless paid_at is set"). ValidateStruct runs together with
+// the field-level rules, so the client receives every failure at once
+type StructLevelValidator interface {
+	ValidateStruct(sl *StructLevel)
+}
+
+// StructLevel collects the cross-field failures reported while validating a single struct
+type StructLevel struct {
+	now    time.Time
+	errors []structError
+}
+
+// structError is a failed cross-field rule, involving one or more fields
+type structError struct {
+	tag    string
+	param  string
+	fields []string
+}
+
+// Now returns the validator's current time, so time-based rules can be tested with a fixed clock
+func (sl *StructLevel) Now() time.Time {
+	return sl.now
+}
+
+// ReportError records a failed rule identified by tag, involving the given fields (JSON names)
+// The first field is the one reported as the FieldError.Field, while all of them are listed in Fields
+// The param is interpolated into the rule message (e.g., the name of the related field)
+func (sl *StructLevel) ReportError(tag, param string, fields ...string) {
+	sl.errors = append(sl.errors, structError{
+		tag:    tag,
+		param:  param,
+		fields: fields,
+	})
+}
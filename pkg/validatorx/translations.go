@@ -0,0 +1,153 @@
+package validatorx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ptbr_translations "github.com/go-playground/validator/v10/translations/pt_BR"
+)
+
+// translatorLocales maps the supported languages to the locales of the go-playground translations
+var translatorLocales = map[i18n.Lang]string{
+	i18n.English:    "en",
+	i18n.Portuguese: "pt_BR",
+}
+
+// messages holds our own user-friendly messages per language and tag
+// They take precedence over the go-playground translations, which are only a fallback
+// for tags not listed here. A "%s" verb is replaced by the tag parameter (or the enum values)
+var messages = i18n.Catalog{
+	i18n.English: {
+		"required": "this field is required",
+		"email":    "invalid email format",
+		"min":      "this field must be at least %s characters long",
+		"max":      "this field must not exceed %s characters",
+		"currency": "unsupported currency code",
+		"enum":     "this field must be one of: %s",
+		"fallback": "failed validation on rule: %s",
+
+		// cross-field rules reported through StructLevel
+		"not_in_future":    "this date cannot be in the future",
+		"required_without": "this field is required when %s is not provided",
+		"required_any":     "at least one of these fields must be provided: %s",
+		"required_if":      "this field is required when %s",
+		"required_with":    "this field is required when %s is provided",
+		"excluded_unless":  "this field is only allowed when %s",
+		"not_negative":     "this amount cannot be negative",
+	},
+	i18n.Portuguese: {
+		"required": "este campo é obrigatório",
+		"email":    "formato de e-mail inválido",
+		"min":      "este campo deve ter pelo menos %s caracteres",
+		"max":      "este campo não pode exceder %s caracteres",
+		"currency": "código de moeda não suportado",
+		"enum":     "este campo deve ser um dos valores: %s",
+		"fallback": "falha na validação da regra: %s",
+
+		// cross-field rules reported through StructLevel
+		"not_in_future":    "esta data não pode estar no futuro",
+		"required_without": "este campo é obrigatório quando %s não é informado",
+		"required_any":     "pelo menos um destes campos deve ser informado: %s",
+		"required_if":      "este campo é obrigatório quando %s",
+		"required_with":    "este campo é obrigatório quando %s é informado",
+		"excluded_unless":  "este campo só é permitido quando %s",
+		"not_negative":     "este valor não pode ser negativo",
+	},
+}
+
+// translator resolves validation messages in the client's language
+type translator struct {
+	uni *ut.UniversalTranslator
+}
+
+// newTranslator registers the go-playground default translations of every supported language
+func newTranslator(v *validator.Validate) *translator {
+	uni := ut.New(pt_BR.New(), pt_BR.New(), en.New())
+
+	if trans, ok := uni.GetTranslator(translatorLocales[i18n.English]); ok {
+		en_translations.RegisterDefaultTranslations(v, trans)
+	}
+	if trans, ok := uni.GetTranslator(translatorLocales[i18n.Portuguese]); ok {
+		ptbr_translations.RegisterDefaultTranslations(v, trans)
+	}
+
+	return &translator{uni: uni}
+}
+
+// fieldErrors converts the go-playground and struct-level errors into FieldErrors in the language
+func (t *translator) fieldErrors(validationErrors validator.ValidationErrors, structErrors []structError, lang i18n.Lang) []FieldError {
+	if _, ok := messages[lang]; !ok {
+		lang = i18n.Default
+	}
+	trans, _ := t.uni.GetTranslator(translatorLocales[lang])
+
+	out := make([]FieldError, 0, len(validationErrors)+len(structErrors))
+	for _, fe := range validationErrors {
+		out = append(out, FieldError{
+			Field:   strings.ToLower(fe.Field()),
+			Tag:     fe.Tag(),
+			Message: message(lang, fe, trans),
+		})
+	}
+	for _, se := range structErrors {
+		fe := FieldError{
+			Tag:     se.tag,
+			Message: structMessage(lang, se),
+		}
+		if len(se.fields) > 0 {
+			fe.Field = se.fields[0]
+		}
+		if len(se.fields) > 1 {
+			fe.Fields = se.fields
+		}
+		out = append(out, fe)
+	}
+	return out
+}
+
+// message builds the user-friendly message for a field error in the given language
+// Our own messages come first, then the go-playground translation and finally a generic message
+func message(lang i18n.Lang, fe validator.FieldError, trans ut.Translator) string {
+	msgs := messages[lang]
+
+	if msg, ok := msgs[fe.Tag()]; ok {
+		if fe.Tag() == "enum" {
+			if enum, ok := fe.Value().(Enum); ok {
+				return fmt.Sprintf(msg, strings.Join(enum.Values(), ", "))
+			}
+		}
+		if strings.Contains(msg, "%s") {
+			return fmt.Sprintf(msg, fe.Param())
+		}
+		return msg
+	}
+
+	// Translate returns the raw English error when no translation is registered for the tag
+	if trans != nil {
+		if translated := fe.Translate(trans); translated != fe.Error() {
+			return translated
+		}
+	}
+
+	return fmt.Sprintf(msgs["fallback"], fe.Tag())
+}
+
+// structMessage builds the user-friendly message for a failed cross-field rule in the given language
+func structMessage(lang i18n.Lang, se structError) string {
+	msgs := messages[lang]
+
+	msg, ok := msgs[se.tag]
+	if !ok {
+		return fmt.Sprintf(msgs["fallback"], se.tag)
+	}
+	if strings.Contains(msg, "%s") {
+		return fmt.Sprintf(msg, se.param)
+	}
+	return msg
+}
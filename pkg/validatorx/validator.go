@@ -3,23 +3,40 @@ package validatorx
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/money"
 	"github.com/go-playground/validator/v10"
 )
 
+// Enum is implemented by string-based types whose valid values form a closed set
+// (e.g., a transaction type). Fields of such types can be checked with the `enum` tag
+type Enum interface {
+	Values() []string
+}
+
 // FieldError contains structured information about a single validation error
 // This structure is designed to be returned to the API client
 type FieldError struct {
-	Field   string `json:"field"`
-	Tag     string `json:"tag"`
-	Message string `json:"message"`
+	Field   string   `json:"field"`
+	Fields  []string `json:"fields,omitempty"` // Every field involved in a cross-field rule (e.g., ["due_date", "paid_at"])
+	Tag     string   `json:"tag"`
+	Message string   `json:"message"`
 }
 
 // ValidationError is a custom error type that wraps one or more FieldErrors
 // This allows us to return all validation failures at once
+// Errors holds the messages in the default language, use Localize to get them in the client's language
 type ValidationError struct {
 	Errors []FieldError
+
+	fieldErrors  validator.ValidationErrors
+	structErrors []structError
+	translator   *translator
 }
 
 // Error implements the error interface for ValidationError
@@ -27,55 +44,87 @@ func (ve ValidationError) Error() string {
 	return fmt.Sprintf("validation failed with %d error(s)", len(ve.Errors))
 }
 
+// Localize returns the field errors with messages in the language,
+// falling back to the default language (pt-BR) when it is not supported
+func (ve ValidationError) Localize(lang i18n.Lang) []FieldError {
+	if ve.translator == nil {
+		return ve.Errors
+	}
+	return ve.translator.fieldErrors(ve.fieldErrors, ve.structErrors, lang)
+}
+
 // Validator is a custom validator for Echo that uses the go-playground/validator library
 type Validator struct {
-	validator *validator.Validate
+	validator  *validator.Validate
+	translator *translator
+	clock      clock.Clock
 }
 
-// NewValidator creates a new instance of Validator
-func NewValidator() *Validator {
-	return &Validator{validator: validator.New()}
+// NewValidator creates a new instance of Validator with the custom validations registered:
+//   - enum: the field implements Enum and its value is one of Values()
+//   - currency: the field is a currency code supported by the money package
+//
+// The go-playground translations for every supported language are registered as well
+// Fields are reported by their JSON names and the clock is exposed to struct-level rules
+func NewValidator(clock clock.Clock) *Validator {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
+	v.RegisterValidation("enum", validateEnum)
+	v.RegisterValidation("currency", validateCurrency)
+	return &Validator{
+		validator:  v,
+		translator: newTranslator(v),
+		clock:      clock,
+	}
 }
 
 // Validate implements the echo.Validator interface
-// It performs struct validation and, if it fails, returns a custom ValidationError
-// containing detailed information about each field error
+// It performs field and struct-level (StructLevelValidator) validation and, if any fails,
+// returns a custom ValidationError containing detailed information about each failure
 func (v *Validator) Validate(i any) error {
-	err := v.validator.Struct(i)
-	if err == nil {
+	var validationErrors validator.ValidationErrors
+	if err := v.validator.Struct(i); err != nil && !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	var structErrors []structError
+	if sv, ok := i.(StructLevelValidator); ok {
+		sl := &StructLevel{now: v.clock.Now()}
+		sv.ValidateStruct(sl)
+		structErrors = sl.errors
+	}
+
+	if len(validationErrors) == 0 && len(structErrors) == 0 {
 		return nil
 	}
 
-	var validationErrors validator.ValidationErrors
-	if errors.As(err, &validationErrors) {
-		out := ValidationError{
-			Errors: make([]FieldError, len(validationErrors)),
-		}
-
-		for i, fe := range validationErrors {
-			out.Errors[i] = FieldError{
-				Field:   strings.ToLower(fe.Field()),
-				Tag:     fe.Tag(),
-				Message: msgForTag(fe.Tag(), fe.Param()),
-			}
-		}
-		return out
+	return ValidationError{
+		Errors:       v.translator.fieldErrors(validationErrors, structErrors, i18n.Default),
+		fieldErrors:  validationErrors,
+		structErrors: structErrors,
+		translator:   v.translator,
 	}
-	return err
 }
 
-// msgForTag translates a validator tag into a user-friendly message
-func msgForTag(tag, param string) string {
-	switch tag {
-	case "required":
-		return "this field is required"
-	case "email":
-		return "invalid email format"
-	case "min":
-		return fmt.Sprintf("this field must be at least %s characters long", param)
-	case "max":
-		return fmt.Sprintf("this field must not exceed %s characters", param)
-	default:
-		return fmt.Sprintf("failed validation on rule: %s", tag)
+// jsonFieldName reports struct fields by their JSON name, so errors match the request body
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
 	}
+	return name
+}
+
+// validateEnum checks that an Enum field holds one of its allowed values
+func validateEnum(fl validator.FieldLevel) bool {
+	enum, ok := fl.Field().Interface().(Enum)
+	if !ok {
+		return false
+	}
+	return slices.Contains(enum.Values(), fl.Field().String())
+}
+
+// validateCurrency checks that a string field holds a supported currency code
+func validateCurrency(fl validator.FieldLevel) bool {
+	return money.IsKnownCurrency(fl.Field().String())
 }
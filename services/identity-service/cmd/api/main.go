@@ -9,26 +9,44 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	identityv1 "github.com/Guizzs26/fintrack/services/identity-service/gen/go"
+	identityv1 "github.com/Guizzs26/fintrack/gen/go/identity/v1"
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errreport"
+	"github.com/Guizzs26/fintrack/pkg/grpcx"
+	"github.com/Guizzs26/fintrack/pkg/ipfilter"
+	"github.com/Guizzs26/fintrack/pkg/logger"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/pkg/ratelimit"
 	identity "github.com/Guizzs26/fintrack/services/identity-service/internal"
 	"github.com/Guizzs26/fintrack/services/identity-service/internal/platform/config"
 	"google.golang.org/grpc"
 )
 
-type InMemoryPublisher struct{}
-
-// Publish simula a publicação de um evento, logando-o na saída padrão.
-func (p *InMemoryPublisher) Publish(ctx context.Context, topic string, eventData []byte) error {
-	slog.Info("EVENT PUBLISHED", slog.String("topic", topic), slog.String("payload", string(eventData)))
-	return nil
-}
-
 func main() {
 	cfg := config.Config{
-		PasswordPepper: "aksdaksdasokdad",
+		PasswordPepper:       "aksdaksdasokdad",
+		FoldEmailAliases:     os.Getenv("FOLD_EMAIL_ALIASES") == "true",
+		SlidingRefresh:       os.Getenv("REFRESH_SLIDING") == "true",
+		RefreshMaxSessionAge: 30 * 24 * time.Hour,
+		LoginOnRegister:      os.Getenv("LOGIN_ON_REGISTER") == "true",
+		InternalAddr:         ":50052",
+		ServiceToken:         os.Getenv("INTERNAL_SERVICE_TOKEN"),
+	}
+	if addr := os.Getenv("INTERNAL_GRPC_ADDR"); addr != "" {
+		cfg.InternalAddr = addr
+	}
+	if raw := os.Getenv("REFRESH_MAX_SESSION_AGE"); raw != "" {
+		maxSessionAge, err := time.ParseDuration(raw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid REFRESH_MAX_SESSION_AGE: %s\n", err)
+			os.Exit(1)
+		}
+		cfg.RefreshMaxSessionAge = maxSessionAge
 	}
 
 	if err := run(context.Background(), cfg); err != nil {
@@ -41,15 +59,29 @@ func run(ctx context.Context, cfg config.Config) error {
 	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	// The default logger redacts sensitive attributes, repositories log user items at debug level
+	logLevels := logger.NewLevels()
+	logCfg := logger.SlogConfig{
+		Level:           logger.LevelDebug,
+		Format:          logger.FormatJSON,
+		AddSource:       true,
+		SamplePerSecond: 100,
+		Levels:          logLevels,
+	}
+	baseLogger := logger.NewSlogConfig(logCfg)
+	slog.SetDefault(baseLogger)
+
+	// The gRPC service has no admin endpoint, SIGUSR1/SIGUSR2 toggle debug logs instead
+	go logger.HandleLevelSignals(ctx, logLevels, baseLogger)
+
 	slog.Info("Starting identity-service...")
 
 	dbClient, err := identity.NewDynamoDBClient(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create dynamodb client: %v", err)
 	}
-	publisher := &InMemoryPublisher{}
 
-	tableName := "FintrackUsers"
+	tableName := identity.TableName
 	userRepo := identity.NewDynamoDBUserRepository(dbClient, tableName)
 	tokenRepo := identity.NewDynamoDBTokenRepository(dbClient, tableName)
 
@@ -58,21 +90,120 @@ func run(ctx context.Context, cfg config.Config) error {
 	refreshTokenTTL := time.Hour * 24 * 7
 	pepper := "kkkkkkkkkkkkkkkkkkkkkkkkkkkk"
 
-	pwdManager := identity.NewPasswordManager(pepper)
+	pwdManager, err := identity.NewPasswordManager(pepper)
+	if err != nil {
+		return err
+	}
 	jwtManager := identity.NewJWTManager(jwtSecret, accessTokenTTL)
 
-	tokenService := identity.NewTokenService(tokenRepo, jwtManager, refreshTokenTTL)
-	userService := identity.NewService(userRepo, tokenService, pwdManager, publisher)
+	// Security events go to a dedicated append-only file, apart from the operational logs
+	auditSink, err := audit.NewFileSink("identity-audit.log")
+	if err != nil {
+		return err
+	}
+	defer auditSink.Close()
+	auditLogger := audit.NewLogger(auditSink, clock.SystemClock{})
+
+	tokenService := identity.NewTokenService(tokenRepo, userRepo, jwtManager, identity.RefreshPolicy{
+		TTL:           refreshTokenTTL,
+		Sliding:       cfg.SlidingRefresh,
+		MaxSessionAge: cfg.RefreshMaxSessionAge,
+	})
+	metricsRegistry := metrics.NewRegistry()
+	userService := identity.NewService(userRepo, tokenService, pwdManager, auditLogger, identity.NewMetrics(metricsRegistry), identity.EmailPolicy{
+		FoldPlusAliases: cfg.FoldEmailAliases,
+	})
+
+	grpcHandler := identity.NewServer(userService, cfg.LoginOnRegister)
+
+	// Panics and server errors are reported to Sentry when SENTRY_DSN is set
+	var errorReporter errreport.ErrorReporter = errreport.Nop{}
+	if dsn := os.Getenv("SENTRY_DSN"); dsn != "" {
+		sentryReporter, err := errreport.NewSentryReporter(dsn, os.Getenv("SENTRY_ENVIRONMENT"), os.Getenv("SENTRY_RELEASE"), 5*time.Second, baseLogger, clock.SystemClock{})
+		if err != nil {
+			return err
+		}
+		go sentryReporter.Run(ctx)
+		errorReporter = sentryReporter
+	}
+
+	// Credential endpoints are limited per client to slow down brute force, the windows being shared through Redis
+	// by every instance when REDIS_ADDR is set
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter(clock.SystemClock{})
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		redisLimiter := ratelimit.NewRedisLimiter(ratelimit.RedisConfig{
+			Addr:      addr,
+			Password:  os.Getenv("REDIS_PASSWORD"),
+			KeyPrefix: "identity:ratelimit:",
+		})
+		defer redisLimiter.Close()
+		limiter = redisLimiter
+	}
+	rateLimits := map[string]ratelimit.Limit{
+		identityv1.IdentityService_Login_FullMethodName:        {Requests: 10, Window: time.Minute},
+		identityv1.IdentityService_Register_FullMethodName:     {Requests: 5, Window: time.Hour},
+		identityv1.IdentityService_RefreshToken_FullMethodName: {Requests: 30, Window: time.Minute},
+		// Every request for a verification mails the user, so it is limited like a registration
+		identityv1.IdentityService_RequestEmailVerification_FullMethodName: {Requests: 5, Window: time.Hour},
+		identityv1.IdentityService_VerifyEmail_FullMethodName:              {Requests: 10, Window: time.Minute},
+	}
 
-	grpcHandler := identity.NewServer(userService)
+	// Credential endpoints reject the countries of AUTH_BLOCKED_COUNTRIES (e.g., "KP,IR"), located by the GeoIP resolver
+	// The static resolver reads GEOIP_STATIC_RANGES ("<cidr>=<country>" pairs), a GeoIP database plugging in through ipfilter.Resolver
+	geoResolver, err := ipfilter.ParseStaticResolver(strings.Split(os.Getenv("GEOIP_STATIC_RANGES"), ","))
+	if err != nil {
+		return err
+	}
+	geoBlock, err := ipfilter.NewGeoBlock(geoResolver, strings.Split(os.Getenv("AUTH_BLOCKED_COUNTRIES"), ","))
+	if err != nil {
+		return err
+	}
+	// X-Forwarded-For is only followed through the proxies of TRUSTED_PROXIES, so a client cannot forge its country
+	trustedProxies, err := ipfilter.ParseNetworks(strings.Split(os.Getenv("TRUSTED_PROXIES"), ","))
+	if err != nil {
+		return err
+	}
 
-	grpcServer := grpc.NewServer()
-	identityv1.RegisterIdentityServiceServer(grpcServer, grpcHandler)
+	// The service-only methods are served on the internal listener alone, to the services sending the service token
+	serviceOnlyMethods := []string{identityv1.IdentityService_IssueImpersonationToken_FullMethodName}
+	if cfg.ServiceToken == "" {
+		slog.Warn("INTERNAL_SERVICE_TOKEN is not set, the service-only methods are refused")
+	}
+	newGRPCServer := func(listenerInterceptor grpc.UnaryServerInterceptor) *grpc.Server {
+		server := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				grpcx.CorrelationUnaryServerInterceptor(baseLogger),
+				grpcx.RecoveryUnaryServerInterceptor(errorReporter),
+				listenerInterceptor,
+				grpcx.GeoBlockUnaryServerInterceptor(geoBlock, trustedProxies,
+					identityv1.IdentityService_Login_FullMethodName,
+					identityv1.IdentityService_Register_FullMethodName,
+					identityv1.IdentityService_RefreshToken_FullMethodName,
+				),
+				grpcx.RateLimitUnaryServerInterceptor(limiter, rateLimits),
+				identity.AuthInterceptor(
+					tokenService,
+					identityv1.IdentityService_Logout_FullMethodName,
+					identityv1.IdentityService_IssueScopedToken_FullMethodName,
+					identityv1.IdentityService_RequestEmailVerification_FullMethodName,
+				),
+				grpcx.ValidationUnaryServerInterceptor(identity.RequestValidators()),
+			),
+		)
+		identityv1.RegisterIdentityServiceServer(server, grpcHandler)
+		return server
+	}
+	grpcServer := newGRPCServer(grpcx.UnexposedUnaryServerInterceptor(serviceOnlyMethods...))
+	internalServer := newGRPCServer(grpcx.ServiceTokenUnaryServerInterceptor(cfg.ServiceToken, serviceOnlyMethods...))
 
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		return fmt.Errorf("failed to listen on port 50051: %v", err)
 	}
+	internalLis, err := net.Listen("tcp", cfg.InternalAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on internal address %s: %v", cfg.InternalAddr, err)
+	}
 
 	go func() {
 		slog.Info("gRPC server listening on :50051")
@@ -82,10 +213,41 @@ func run(ctx context.Context, cfg config.Config) error {
 		}
 	}()
 
+	go func() {
+		slog.Info("internal gRPC server listening", slog.String("addr", cfg.InternalAddr))
+		if err := internalServer.Serve(internalLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("internal gRPC server failed to serve", slog.String("error", err.Error()))
+			cancel()
+		}
+	}()
+
+	// Business metrics are served apart from the gRPC port, for Prometheus to scrape
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("GET /metrics", metricsRegistry.Handler())
+	metricsServer := &http.Server{
+		Addr:              ":9091",
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		slog.Info("metrics server listening on :9091")
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed to serve", slog.String("error", err.Error()))
+		}
+	}()
+
 	<-ctx.Done()
 
 	slog.Info("Shutting down server gracefully...")
 	grpcServer.GracefulStop()
+	internalServer.GracefulStop()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shut down metrics server", slog.String("error", err.Error()))
+	}
 
 	return nil
 }
@@ -0,0 +1,136 @@
+// Command migrate moves the users of the legacy identity table, keyed by a bare ID, to the single table keyed by PK
+// and SK, where they live next to their refresh tokens and the outbox
+//
+// Usage:
+//
+//	migrate -dry-run                      count the users that would be migrated
+//	migrate -create-table                 create the table when missing, then migrate the users
+//	migrate -fold-email-aliases           migrate the users, then re-key the emails reserved with a plus alias
+//	migrate -verify-existing-emails       migrate the users, then mark the emails of the users not verified as verified
+//
+// Running it again is safe, the users already migrated are skipped. Run it with -fold-email-aliases before turning
+// FOLD_EMAIL_ALIASES on in the service, so the users who signed up with a "+tag" are found by their folded email, and
+// with -verify-existing-emails before turning IDENTITY_REQUIRE_VERIFIED_EMAIL on in the ledger, so the users who
+// signed up before the verification links were mailed keep their writes.
+// Flags default to the environment variables named in their usage, DYNAMODB_ENDPOINT pointing the client at a local
+// instance
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/logger"
+	identity "github.com/Guizzs26/fintrack/services/identity-service/internal"
+)
+
+// options are the settings of the migration, read from the flags
+type options struct {
+	legacyTable string
+	table       string
+	createTable bool
+	foldAliases bool
+	// verifyExisting marks the users not verified as verified at the start of the run
+	verifyExisting bool
+	dryRun         bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.legacyTable, "legacy-table", envOr("LEGACY_IDENTITY_TABLE", "FintrackUsers"), "table holding the users keyed by their bare ID (LEGACY_IDENTITY_TABLE)")
+	flag.StringVar(&opts.table, "table", envOr("IDENTITY_TABLE", identity.TableName), "single table the users are migrated to (IDENTITY_TABLE)")
+	flag.BoolVar(&opts.createTable, "create-table", false, "create the table and its GSI when missing")
+	flag.BoolVar(&opts.foldAliases, "fold-email-aliases", os.Getenv("FOLD_EMAIL_ALIASES") == "true", "fold the plus aliases of the migrated emails, then of the emails already in the table (FOLD_EMAIL_ALIASES)")
+	flag.BoolVar(&opts.verifyExisting, "verify-existing-emails", false, "mark the emails of the users of the table not verified as verified")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "only count the users that would be migrated")
+	flag.Parse()
+
+	if err := run(context.Background(), opts); err != nil {
+		fmt.Fprintf(os.Stderr, "migration finished with an error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	slog.SetDefault(logger.NewSlogConfig(logger.SlogConfig{
+		Level:  logger.LevelInfo,
+		Format: logger.FormatJSON,
+	}))
+
+	startedAt := time.Now()
+	if opts.legacyTable == opts.table {
+		return fmt.Errorf("the legacy table and the table must differ, a table has a single key schema")
+	}
+
+	client, err := identity.NewDynamoDBClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamodb client: %w", err)
+	}
+
+	if opts.createTable && !opts.dryRun {
+		if err := identity.EnsureTable(ctx, client, opts.table); err != nil {
+			return err
+		}
+	}
+
+	report, err := identity.MigrateLegacyUsers(ctx, client, opts.legacyTable, opts.table, identity.EmailPolicy{FoldPlusAliases: opts.foldAliases}, opts.dryRun)
+	slog.Info("users migrated",
+		slog.String("legacy_table", opts.legacyTable),
+		slog.String("table", opts.table),
+		slog.Bool("dry_run", opts.dryRun),
+		slog.Int("scanned", report.Scanned),
+		slog.Int("migrated", report.Migrated),
+		slog.Int("skipped", report.Skipped),
+		slog.Int("conflicts", report.Conflicts),
+	)
+	if err != nil {
+		return err
+	}
+
+	if opts.foldAliases {
+		report, err = identity.FoldEmailAliases(ctx, client, opts.table, opts.dryRun)
+		slog.Info("email aliases folded",
+			slog.String("table", opts.table),
+			slog.Bool("dry_run", opts.dryRun),
+			slog.Int("scanned", report.Scanned),
+			slog.Int("folded", report.Migrated),
+			slog.Int("skipped", report.Skipped),
+			slog.Int("conflicts", report.Conflicts),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.verifyExisting {
+		report, err = identity.MarkEmailsVerified(ctx, client, opts.table, startedAt, opts.dryRun)
+		slog.Info("existing emails marked verified",
+			slog.String("table", opts.table),
+			slog.Bool("dry_run", opts.dryRun),
+			slog.Int("scanned", report.Scanned),
+			slog.Int("verified", report.Migrated),
+			slog.Int("skipped", report.Skipped),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envOr returns the environment variable, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -10,10 +10,12 @@ require (
 	github.com/google/uuid v1.6.0
 	golang.org/x/crypto v0.42.0
 	google.golang.org/grpc v1.76.0
-	google.golang.org/protobuf v1.36.10
 )
 
-require github.com/aws/aws-sdk-go-v2/service/dynamodbstreams v1.31.0 // indirect
+require (
+	github.com/aws/aws-sdk-go-v2/service/dynamodbstreams v1.31.0 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+)
 
 require (
 	github.com/aws/aws-sdk-go-v2 v1.39.2
@@ -0,0 +1,52 @@
+package identity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/retry"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoRetryPolicy attempts again the writes DynamoDB rejected for a transient reason
+// Throttling of single requests is already retried by the SDK, this covers what it gives up on right away:
+// transactions canceled by a conflicting one and items a batch left unprocessed
+var dynamoRetryPolicy = retry.Policy{
+	MaxAttempts:    4,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Retryable:      isRetryableDynamoError,
+}
+
+// errUnprocessedItems is returned by a batch write leaving items unprocessed, DynamoDB asking to send them again
+var errUnprocessedItems = errors.New("dynamodb left items of the batch unprocessed")
+
+// isRetryableDynamoError reports whether a write may succeed when attempted again
+// A transaction is only retried when none of its conditions failed, a failed condition failing every attempt
+func isRetryableDynamoError(err error) bool {
+	if errors.Is(err, errUnprocessedItems) {
+		return true
+	}
+
+	var conflictErr *types.TransactionConflictException
+	if errors.As(err, &conflictErr) {
+		return true
+	}
+
+	var canceledErr *types.TransactionCanceledException
+	if errors.As(err, &canceledErr) {
+		transient := false
+		for _, reason := range canceledErr.CancellationReasons {
+			switch aws.ToString(reason.Code) {
+			case "ConditionalCheckFailed", "ValidationError", "ItemCollectionSizeLimitExceeded":
+				return false
+			case "TransactionConflict", "ThrottlingError", "ProvisionedThroughputExceeded":
+				transient = true
+			}
+		}
+		return transient
+	}
+
+	return retry.IsRetryable(err)
+}
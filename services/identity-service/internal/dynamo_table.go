@@ -0,0 +1,95 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// Single Table Design: every item of the identity service is keyed by PK and SK, the prefix of the keys naming the
+// entity of the item. The users are found by email through the items reserving them, GSI1 finds the refresh tokens
+// by hash
+const (
+	// TableName is the table holding the users, their emails, refresh tokens and the outbox
+	TableName = "FintrackIdentity"
+
+	gsi1Name = "GSI1"
+
+	entityUser  = "USER"
+	entityEmail = "EMAIL"
+	entityToken = "TOKEN"
+
+	userProfileSK = "PROFILE"
+	emailSK       = "EMAIL"
+	tokenGSI1SK   = "TOKEN"
+)
+
+// userPK is the partition of a user, holding their profile and refresh tokens
+func userPK(id uuid.UUID) string {
+	return "USER#" + id.String()
+}
+
+// emailPK is the key of the item reserving the email of a user
+func emailPK(email string) string {
+	return "EMAIL#" + email
+}
+
+// tokenSK is the sort key of a refresh token in the partition of its user
+func tokenSK(tokenHash string) string {
+	return "TOKEN#" + tokenHash
+}
+
+// itemKey returns the primary key of an item
+func itemKey(pk, sk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: pk},
+		"SK": &types.AttributeValueMemberS{Value: sk},
+	}
+}
+
+// TableDefinition returns the input creating the table of the identity service, billed per request
+func TableDefinition(tableName string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		TableName:   aws.String(tableName),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("PK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("SK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("GSI1PK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("GSI1SK"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(gsi1Name),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("GSI1PK"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("GSI1SK"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+	}
+}
+
+// EnsureTable creates the table of the identity service when it does not exist, waiting until it is active
+func EnsureTable(ctx context.Context, client *dynamodb.Client, tableName string) error {
+	_, err := client.CreateTable(ctx, TableDefinition(tableName))
+	var inUseErr *types.ResourceInUseException
+	if err != nil && !errors.As(err, &inUseErr) {
+		return fmt.Errorf("failed to create table %s: %w", tableName, err)
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)}, 2*time.Minute); err != nil {
+		return fmt.Errorf("failed to wait for table %s: %w", tableName, err)
+	}
+	return nil
+}
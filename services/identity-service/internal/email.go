@@ -0,0 +1,29 @@
+package identity
+
+import "strings"
+
+// EmailPolicy normalizes the emails before they are stored or looked up, so a user is found whatever the case or
+// the blanks they type their email with, and two users cannot sign up with the same mailbox
+type EmailPolicy struct {
+	// FoldPlusAliases drops the "+tag" of the local part (e.g., "ana+bank@mail.com" is "ana@mail.com"), for the
+	// providers delivering the aliases to the same mailbox
+	FoldPlusAliases bool
+}
+
+// Normalize trims and lowercases the email, folding its plus alias when the policy asks for it
+// An email without a local part or a domain is only trimmed and lowercased, its validation being left to the caller
+func (p EmailPolicy) Normalize(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !p.FoldPlusAliases {
+		return email
+	}
+
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" || domain == "" {
+		return email
+	}
+	if base, _, aliased := strings.Cut(local, "+"); aliased && base != "" {
+		local = base
+	}
+	return local + "@" + domain
+}
@@ -3,8 +3,13 @@ package identity
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"time"
 
-	identityv1 "github.com/Guizzs26/fintrack/services/identity-service/gen/go"
+	identityv1 "github.com/Guizzs26/fintrack/gen/go/identity/v1"
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/grpcx"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
@@ -13,38 +18,45 @@ import (
 
 type Server struct {
 	identityv1.UnimplementedIdentityServiceServer
-	service *Service
+	service         *Service
+	loginOnRegister bool // loginOnRegister answers the registrations with a token pair, sparing the clients a login
 }
 
-func NewServer(s *Service) *Server {
-	return &Server{service: s}
+func NewServer(s *Service, loginOnRegister bool) *Server {
+	return &Server{service: s, loginOnRegister: loginOnRegister}
 }
 
 func (s *Server) Register(ctx context.Context, req *identityv1.RegisterRequest) (*identityv1.RegisterResponse, error) {
-	if req.GetName() == "" || req.GetEmail() == "" || req.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "name, email and password are rqeuired")
-	}
-
 	user, err := s.service.Register(ctx, req.GetName(), req.GetEmail(), req.GetPassword())
 	if err != nil {
-		if errors.Is(err, ErrEmailAlreadyInUse) {
-			return nil, status.Error(codes.AlreadyExists, err.Error())
-		}
-		return nil, status.Error(codes.Internal, "failed to register user")
+		return nil, grpcx.StatusFromError(err, "failed to register user")
 	}
 
-	return &identityv1.RegisterResponse{UserId: user.ID.String()}, nil
-}
+	resp := &identityv1.RegisterResponse{UserId: user.ID.String()}
+	if !s.loginOnRegister {
+		return resp, nil
+	}
 
-func (s *Server) Login(ctx context.Context, req *identityv1.LoginRequest) (*identityv1.LoginResponse, error) {
-	if req.GetEmail() == "" || req.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	// The user exists by now, so a failed session only costs the client a login
+	pair, err := s.service.StartSession(ctx, user)
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Warn("failed to log in registered user", slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
+		return resp, nil
 	}
+	resp.AccessToken = pair.AccessToken
+	resp.RefreshToken = pair.RefreshToken
+	return resp, nil
+}
 
+func (s *Server) Login(ctx context.Context, req *identityv1.LoginRequest) (*identityv1.LoginResponse, error) {
+	// Unknown emails and wrong passwords share their reason, so the answer does not tell which emails are registered
 	tokenPair, err := s.service.Login(ctx, req.GetEmail(), req.GetPassword())
 	if err != nil {
-		if errors.Is(err, ErrUserNotFound) {
-			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			return nil, grpcx.StatusWithReason(codes.Unauthenticated, "invalid credentials", ErrInvalidCredentials.Code)
+		case errors.Is(err, ErrUserDeactivated):
+			return nil, grpcx.StatusWithReason(codes.Unauthenticated, ErrUserDeactivated.Message, ErrUserDeactivated.Code)
 		}
 		return nil, status.Error(codes.Internal, "failed to login user")
 	}
@@ -56,10 +68,6 @@ func (s *Server) Login(ctx context.Context, req *identityv1.LoginRequest) (*iden
 }
 
 func (s *Server) RefreshToken(ctx context.Context, req *identityv1.RefreshTokenRequest) (*identityv1.LoginResponse, error) {
-	if req.GetRefreshToken() == "" {
-		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
-	}
-
 	tokenPair, err := s.service.RefreshToken(ctx, req.GetRefreshToken())
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
@@ -72,12 +80,202 @@ func (s *Server) RefreshToken(ctx context.Context, req *identityv1.RefreshTokenR
 }
 
 func (s *Server) Logout(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
-	userID, _ := uuid.Parse("...") // fake
+	user, ok := authctx.UserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
 
-	if err := s.service.Logout(ctx, userID); err != nil {
+	// A scoped token cannot end the sessions of the user it was handed out by
+	if user.Scope.Restricted() {
+		return nil, grpcx.StatusWithReason(codes.PermissionDenied, "a scoped token cannot logout", ErrScopeTooBroad.Code)
+	}
+	if user.ImpersonatedBy != "" {
+		return nil, grpcx.StatusFromError(ErrImpersonating, "failed to logout")
+	}
+
+	if err := s.service.Logout(ctx, user.UserID); err != nil {
 		// Logar o erro aqui
 		return nil, status.Error(codes.Internal, "failed to logout")
 	}
 
 	return &empty.Empty{}, nil
 }
+
+func (s *Server) ValidateToken(ctx context.Context, req *identityv1.ValidateTokenRequest) (*identityv1.ValidateTokenResponse, error) {
+	claims, err := s.service.ValidateAccessToken(ctx, req.GetAccessToken())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid access token")
+	}
+
+	accountIDs := make([]string, 0, len(claims.Scope.AccountIDs))
+	for _, accountID := range claims.Scope.AccountIDs {
+		accountIDs = append(accountIDs, accountID.String())
+	}
+
+	return &identityv1.ValidateTokenResponse{
+		UserId:         claims.UserID.String(),
+		ExpiresAt:      claims.ExpiresAt,
+		ReadOnly:       claims.Scope.ReadOnly,
+		AccountIds:     accountIDs,
+		EmailVerified:  claims.EmailVerified,
+		ImpersonatedBy: claims.ImpersonatedBy,
+	}, nil
+}
+
+// IssueScopedToken hands the caller a token narrower than theirs, the account IDs being validated by the interceptor
+func (s *Server) IssueScopedToken(ctx context.Context, req *identityv1.IssueScopedTokenRequest) (*identityv1.IssueScopedTokenResponse, error) {
+	user, ok := authctx.UserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	scope := authctx.Scope{ReadOnly: req.GetReadOnly()}
+	for _, raw := range req.GetAccountIds() {
+		accountID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, grpcx.BadRequest("invalid account id format", grpcx.FieldViolation{Field: "account_ids", Description: "must hold UUIDs"})
+		}
+		scope.AccountIDs = append(scope.AccountIDs, accountID)
+	}
+
+	token, expiresAt, err := s.service.IssueScopedToken(ctx, user, scope, time.Duration(req.GetTtlSeconds())*time.Second)
+	if err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to issue scoped token")
+	}
+
+	return &identityv1.IssueScopedTokenResponse{
+		AccessToken: token,
+		ExpiresAt:   expiresAt.Unix(),
+	}, nil
+}
+
+// IssueImpersonationToken serves the operator endpoints of the other services, on the internal listener alone and to
+// the callers sending the service token, the request being validated by the interceptors
+func (s *Server) IssueImpersonationToken(ctx context.Context, req *identityv1.IssueImpersonationTokenRequest) (*identityv1.IssueImpersonationTokenResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, grpcx.BadRequest("invalid user id format", grpcx.FieldViolation{Field: "user_id", Description: "must be a UUID"})
+	}
+
+	ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+	token, expiresAt, err := s.service.IssueImpersonationToken(ctx, userID, req.GetOperator(), req.GetReason(), ttl)
+	if err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to issue impersonation token")
+	}
+
+	return &identityv1.IssueImpersonationTokenResponse{
+		AccessToken: token,
+		ExpiresAt:   expiresAt.Unix(),
+	}, nil
+}
+
+// RequestEmailVerification mails the caller the link confirming they own their email
+func (s *Server) RequestEmailVerification(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	user, ok := authctx.UserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	// A scoped token is handed out for reading, not for acting on the user
+	if user.Scope.Restricted() {
+		return nil, grpcx.StatusWithReason(codes.PermissionDenied, "a scoped token cannot request an email verification", ErrScopeTooBroad.Code)
+	}
+
+	if err := s.service.RequestEmailVerification(ctx, user); err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to request email verification")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+// VerifyEmail serves the link mailed by RequestEmailVerification, unauthenticated since the token is the credential
+func (s *Server) VerifyEmail(ctx context.Context, req *identityv1.VerifyEmailRequest) (*empty.Empty, error) {
+	if err := s.service.VerifyEmail(ctx, req.GetToken()); err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to verify email")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *identityv1.GetUserRequest) (*identityv1.GetUserResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, grpcx.BadRequest("invalid user id format", grpcx.FieldViolation{Field: "user_id", Description: "must be a UUID"})
+	}
+
+	user, err := s.service.GetUser(ctx, userID)
+	if err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to get user")
+	}
+
+	return &identityv1.GetUserResponse{
+		UserId: user.ID.String(),
+		Name:   user.Name,
+		Email:  user.Email,
+	}, nil
+}
+
+// GetUsersByIDs resolves many users at once, the IDs being validated by the interceptor
+func (s *Server) GetUsersByIDs(ctx context.Context, req *identityv1.GetUsersByIDsRequest) (*identityv1.GetUsersByIDsResponse, error) {
+	userIDs := make([]uuid.UUID, 0, len(req.GetUserIds()))
+	for _, raw := range req.GetUserIds() {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, grpcx.BadRequest("invalid user id format", grpcx.FieldViolation{Field: "user_ids", Description: "must hold UUIDs"})
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	users, err := s.service.GetUsers(ctx, userIDs)
+	if err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to get users")
+	}
+
+	resp := &identityv1.GetUsersByIDsResponse{Users: make([]*identityv1.UserSummary, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, &identityv1.UserSummary{
+			UserId: user.ID.String(),
+			Name:   user.Name,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) DeactivateUser(ctx context.Context, req *identityv1.DeactivateUserRequest) (*empty.Empty, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, grpcx.BadRequest("invalid user id format", grpcx.FieldViolation{Field: "user_id", Description: "must be a UUID"})
+	}
+
+	if err := s.service.DeactivateUser(ctx, userID); err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to deactivate user")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) ReactivateUser(ctx context.Context, req *identityv1.ReactivateUserRequest) (*empty.Empty, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, grpcx.BadRequest("invalid user id format", grpcx.FieldViolation{Field: "user_id", Description: "must be a UUID"})
+	}
+
+	if err := s.service.ReactivateUser(ctx, userID); err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to reactivate user")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) DeleteUser(ctx context.Context, req *identityv1.DeleteUserRequest) (*empty.Empty, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, grpcx.BadRequest("invalid user id format", grpcx.FieldViolation{Field: "user_id", Description: "must be a UUID"})
+	}
+
+	if err := s.service.DeleteUser(ctx, userID); err != nil {
+		return nil, grpcx.StatusFromError(err, "failed to delete user")
+	}
+
+	return &empty.Empty{}, nil
+}
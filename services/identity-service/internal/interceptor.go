@@ -0,0 +1,59 @@
+package identity
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/grpcx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthInterceptor returns a unary server interceptor that validates the bearer access token
+// sent in the "authorization" metadata for the given protected methods and injects the
+// authenticated user into the context via the authctx package
+func AuthInterceptor(tm TokenManager, protectedMethods ...string) grpc.UnaryServerInterceptor {
+	protected := make(map[string]struct{}, len(protectedMethods))
+	for _, m := range protectedMethods {
+		protected[m] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := protected[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+		}
+
+		accessToken, found := strings.CutPrefix(values[0], "Bearer ")
+		if !found {
+			return nil, status.Error(codes.Unauthenticated, "malformed authorization metadata")
+		}
+
+		claims, err := tm.ValidateAccessToken(accessToken)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+
+		var locale string
+		if langs := md.Get(grpcx.LocaleMetadataKey); len(langs) > 0 {
+			locale = langs[0]
+		}
+
+		ctx = authctx.WithUser(ctx, authctx.UserContext{
+			UserID:         claims.UserID,
+			Locale:         locale,
+			Scope:          claims.Scope,
+			EmailVerified:  claims.EmailVerified,
+			ImpersonatedBy: claims.ImpersonatedBy,
+		})
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,19 @@
+package identity
+
+import "github.com/Guizzs26/fintrack/pkg/metrics"
+
+// Metrics holds the business metrics of the identity service
+type Metrics struct {
+	registrations   metrics.Counter
+	loginsSucceeded metrics.Counter
+	loginsFailed    metrics.Counter
+}
+
+// NewMetrics registers the identity metrics in the provider
+func NewMetrics(provider metrics.Provider) *Metrics {
+	return &Metrics{
+		registrations:   provider.Counter("identity_registrations_total", "Users registered"),
+		loginsSucceeded: provider.Counter("identity_logins_succeeded_total", "Successful logins"),
+		loginsFailed:    provider.Counter("identity_logins_failed_total", "Failed logins, by reason", "reason"),
+	}
+}
@@ -0,0 +1,66 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// outboxPK is the partition of the outbox items, which the outbox relay queries in sort key order
+	outboxPK = "OUTBOX"
+	// outboxTimeLayout is a fixed width UTC timestamp, so the sort keys sort in the order the events were recorded
+	outboxTimeLayout = "2006-01-02T15:04:05.000000000Z"
+	// outboxAggregateUser is the aggregate type of the user events, naming the topic they are published to
+	outboxAggregateUser = "user"
+)
+
+// Single Table Design, the outbox items live next to the users and tokens
+type outboxItem struct {
+	PK            string    `dynamodbav:"PK"` // Format: OUTBOX
+	SK            string    `dynamodbav:"SK"` // Format: EVENT#<RecordedAt>#<EventID>
+	EventID       uuid.UUID `dynamodbav:"EventID"`
+	AggregateType string    `dynamodbav:"AggregateType"`
+	AggregateID   string    `dynamodbav:"AggregateID"`
+	EventType     string    `dynamodbav:"EventType"`
+	EventVersion  int       `dynamodbav:"EventVersion"`
+	Envelope      string    `dynamodbav:"Envelope"` // Envelope is the encoded contracts envelope
+	RecordedAt    time.Time `dynamodbav:"RecordedAt"`
+}
+
+// outboxPut wraps an event of the user in an envelope and returns the transaction item writing it to the outbox
+func outboxPut(tableName string, userID uuid.UUID, event contracts.Event, occurredAt time.Time) (types.TransactWriteItem, error) {
+	envelope, err := contracts.NewEnvelope(event, occurredAt)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("failed to wrap %s event: %w", event.EventType(), err)
+	}
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("failed to marshal %s event: %w", envelope.Type, err)
+	}
+
+	recordedAt := time.Now().UTC()
+	item, err := attributevalue.MarshalMap(outboxItem{
+		PK:            outboxPK,
+		SK:            fmt.Sprintf("EVENT#%s#%s", recordedAt.Format(outboxTimeLayout), envelope.ID),
+		EventID:       envelope.ID,
+		AggregateType: outboxAggregateUser,
+		AggregateID:   userID.String(),
+		EventType:     envelope.Type,
+		EventVersion:  envelope.Version,
+		Envelope:      string(encoded),
+		RecordedAt:    recordedAt,
+	})
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("failed to marshal outbox item for dynamodb: %v", err)
+	}
+
+	return types.TransactWriteItem{
+		Put: &types.Put{TableName: &tableName, Item: item},
+	}, nil
+}
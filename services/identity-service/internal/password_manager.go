@@ -22,10 +22,14 @@ type argonParams struct {
 type PasswordManager struct {
 	params *argonParams
 	pepper []byte
+	// dummyHash is the hash of a random password, verified against when there is no user
+	dummyHash string
 }
 
-func NewPasswordManager(pepper string) *PasswordManager {
-	return &PasswordManager{
+// NewPasswordManager creates a PasswordManager, failing when the dummy hash of the unknown users cannot be created:
+// without it their logins would answer right away, telling the registered emails apart by timing
+func NewPasswordManager(pepper string) (*PasswordManager, error) {
+	pm := &PasswordManager{
 		params: &argonParams{
 			memory:      64 * 1024,
 			iterations:  3,
@@ -35,6 +39,18 @@ func NewPasswordManager(pepper string) *PasswordManager {
 		},
 		pepper: []byte(pepper),
 	}
+
+	// Hashed up front, so the first login of an unknown email is not the slowest
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate dummy password: %w", err)
+	}
+	dummyHash, err := pm.Hash(base64.RawStdEncoding.EncodeToString(random))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
+	pm.dummyHash = dummyHash
+	return pm, nil
 }
 
 func (pm *PasswordManager) Hash(password string) (string, error) {
@@ -79,6 +95,12 @@ func (pm *PasswordManager) Verify(password, encodedHash string) (bool, error) {
 	return false, nil
 }
 
+// VerifyDummy spends the time of a Verify against the hash of a random password, so a login of an unknown email
+// answers as late as a wrong password does
+func (pm *PasswordManager) VerifyDummy(password string) {
+	_, _ = pm.Verify(password, pm.dummyHash)
+}
+
 func (pm *PasswordManager) decodeHash(encodedHash string) (params *argonParams, salt, hash []byte, err error) {
 	vals := strings.Split(encodedHash, "$")
 	if len(vals) != 6 {
@@ -1,5 +1,16 @@
 package config
 
+import "time"
+
 type Config struct {
-	PasswordPepper string `env:"PASSWORD_PEPPER,required"`
+	PasswordPepper   string `env:"PASSWORD_PEPPER,required"`
+	FoldEmailAliases bool   `env:"FOLD_EMAIL_ALIASES"` // FoldEmailAliases treats "ana+tag@mail.com" as "ana@mail.com"
+	// SlidingRefresh extends the sessions on every refresh, up to RefreshMaxSessionAge after their login
+	SlidingRefresh       bool          `env:"REFRESH_SLIDING"`
+	RefreshMaxSessionAge time.Duration `env:"REFRESH_MAX_SESSION_AGE"`
+	LoginOnRegister      bool          `env:"LOGIN_ON_REGISTER"` // LoginOnRegister answers the registrations with a token pair
+	// InternalAddr is the listener of the other services, the only one serving the service-only methods
+	InternalAddr string `env:"INTERNAL_GRPC_ADDR"`
+	// ServiceToken is the secret the other services send to call the service-only methods, which are refused when empty
+	ServiceToken string `env:"INTERNAL_SERVICE_TOKEN"`
 }
@@ -2,7 +2,10 @@ package identity
 
 import (
 	"context"
+	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/errx"
 	"github.com/google/uuid"
 )
 
@@ -11,12 +14,102 @@ type TokenPair struct {
 	RefreshToken string
 }
 
+// AccessTokenClaims holds the verified information extracted from an access token
+// Lifetimes of the scoped tokens, which have no refresh token and cannot be revoked, so they are kept short lived
+const (
+	DefaultScopedTokenTTL = 24 * time.Hour      // DefaultScopedTokenTTL is used when the request names no lifetime
+	MaxScopedTokenTTL     = 30 * 24 * time.Hour // MaxScopedTokenTTL bounds the lifetime a request can ask for
+)
+
+// EmailVerificationTTL is how long the link mailed by RequestEmailVerification is valid
+const EmailVerificationTTL = 24 * time.Hour
+
+// Lifetimes of the impersonation tokens, as short as a support session
+const (
+	DefaultImpersonationTTL = 15 * time.Minute // DefaultImpersonationTTL is used when the request names no lifetime
+	MaxImpersonationTTL     = time.Hour        // MaxImpersonationTTL bounds the lifetime a request can ask for
+)
+
+var (
+	ErrScopeNotNarrowed         = errx.New(errx.CategoryValidation, "SCOPE_NOT_NARROWED", "a scoped token must be read-only or restricted to accounts")
+	ErrScopeTooBroad            = errx.New(errx.CategoryForbidden, "SCOPE_TOO_BROAD", "a token cannot issue a token granting more than itself")
+	ErrInvalidTokenTTL          = errx.New(errx.CategoryValidation, "INVALID_TOKEN_TTL", "the lifetime of a scoped token must be positive and at most 30 days")
+	ErrInvalidImpersonationTTL  = errx.New(errx.CategoryValidation, "INVALID_IMPERSONATION_TTL", "the lifetime of an impersonation token must be positive and at most 1 hour")
+	ErrImpersonating            = errx.New(errx.CategoryForbidden, "IMPERSONATION_FORBIDDEN", "an impersonation token cannot do this on behalf of the user")
+	ErrInvalidVerificationToken = errx.New(errx.CategoryValidation, "INVALID_VERIFICATION_TOKEN", "the email verification link is invalid or expired")
+)
+
+// RefreshPolicy decides when the refresh tokens expire. By default a session ends TTL after its login, whatever its
+// rotations. A sliding session is extended by TTL on every rotation instead, so it ends after TTL without use or at
+// MaxSessionAge after its login, whichever comes first
+type RefreshPolicy struct {
+	TTL           time.Duration // TTL is the lifetime of the refresh token of a login
+	Sliding       bool
+	MaxSessionAge time.Duration // MaxSessionAge bounds a sliding session, TTL being used when it is shorter
+}
+
+// expiresAt is the expiration of the token rotated at now from a session started at startedAt and expiring at current
+func (p RefreshPolicy) expiresAt(now, startedAt, current time.Time) time.Time {
+	if !p.Sliding {
+		return current
+	}
+	deadline := startedAt.Add(max(p.MaxSessionAge, p.TTL))
+	if next := now.Add(p.TTL); next.Before(deadline) {
+		return next
+	}
+	return deadline
+}
+
+type AccessTokenClaims struct {
+	UserID        uuid.UUID
+	ExpiresAt     int64
+	Scope         authctx.Scope // Scope is the zero Scope for the tokens of a login
+	EmailVerified bool          // EmailVerified is false for the tokens issued before it was claimed
+	// ImpersonatedBy names the support member acting as the user, empty for the tokens of the user
+	ImpersonatedBy string
+}
+
+// EmailVerificationClaims holds the verified information extracted from an email verification token
+type EmailVerificationClaims struct {
+	UserID uuid.UUID
+	Email  string // Email is the email the link was mailed to
+}
+
+// TokenSubject is the user an access token is issued to, as its claims describe them
+type TokenSubject struct {
+	UserID         uuid.UUID
+	EmailVerified  bool
+	ImpersonatedBy string // ImpersonatedBy names the support member the token is handed to
+}
+
+// subjectOf describes the user to the tokens issued to them
+func subjectOf(user *User) TokenSubject {
+	return TokenSubject{
+		UserID:        user.ID,
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}
+}
+
 type TokenGenerator interface {
-	Generate(userID uuid.UUID) (string, error)
+	Generate(subject TokenSubject) (string, error)
+	// GenerateScoped signs a token of the scope valid for ttl, returning it with its expiration
+	GenerateScoped(subject TokenSubject, scope authctx.Scope, ttl time.Duration) (string, time.Time, error)
+	Validate(accessToken string) (*AccessTokenClaims, error)
+	// GenerateEmailVerification signs the token of the link confirming the user owns the email, valid for ttl
+	GenerateEmailVerification(userID uuid.UUID, email string, ttl time.Duration) (string, time.Time, error)
+	ValidateEmailVerification(verificationToken string) (*EmailVerificationClaims, error)
 }
 
 type TokenManager interface {
-	NewPairForUser(ctx context.Context, userID uuid.UUID) (*TokenPair, error)
+	NewPairForUser(ctx context.Context, user *User) (*TokenPair, error)
 	RotateRefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
 	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+	ValidateAccessToken(accessToken string) (*AccessTokenClaims, error)
+	// IssueScopedToken issues an access token of the scope without a refresh token, it expires after ttl for good
+	IssueScopedToken(user *User, scope authctx.Scope, ttl time.Duration) (string, time.Time, error)
+	// IssueImpersonationToken issues an access token acting as the user on behalf of operator, it expires after ttl
+	IssueImpersonationToken(user *User, operator string, ttl time.Duration) (string, time.Time, error)
+	// IssueEmailVerificationToken issues the token of the link confirming the user owns their email, it expires after ttl
+	IssueEmailVerificationToken(user *User, ttl time.Duration) (string, time.Time, error)
+	ValidateEmailVerificationToken(verificationToken string) (*EmailVerificationClaims, error)
 }
@@ -1,12 +1,35 @@
 package identity
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/authctx"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// Claims of the scope of a token, left out of the tokens of a login
+const (
+	claimScope    = "scope"    // claimScope is "read" for the read-only tokens
+	claimAccounts = "accounts" // claimAccounts lists the accounts the token is restricted to
+	scopeRead     = "read"
+)
+
+// claimEmailVerified tells whether the user confirmed they own their email, named as OpenID Connect does
+const claimEmailVerified = "email_verified"
+
+// claimActor names who acts as the subject of an impersonation token, as the "act" claim of RFC 8693 does
+const claimActor = "act"
+
+// Claims of the email verification tokens, signed with the key of the access tokens, so the purpose keeps one from
+// being taken for the other
+const (
+	claimPurpose             = "purpose"
+	claimEmail               = "email" // claimEmail is the email the link was mailed to, a changed email voiding it
+	purposeEmailVerification = "email_verification"
+)
+
 type JWTManager struct {
 	secretKey      []byte
 	accessTokenTTL time.Duration
@@ -19,12 +42,175 @@ func NewJWTManager(sk string, attl time.Duration) *JWTManager {
 	}
 }
 
-func (m *JWTManager) Generate(userID uuid.UUID) (string, error) {
+func (m *JWTManager) Generate(subject TokenSubject) (string, error) {
+	token, _, err := m.GenerateScoped(subject, authctx.Scope{}, m.accessTokenTTL)
+	return token, err
+}
+
+// GenerateScoped signs a token of the scope valid for ttl, returning it with its expiration
+func (m *JWTManager) GenerateScoped(subject TokenSubject, scope authctx.Scope, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
 	claims := jwt.MapClaims{
-		"sub": userID.String(),
-		"exp": time.Now().Add(m.accessTokenTTL).Unix(),
-		"iat": time.Now().Unix(),
+		"sub":              subject.UserID.String(),
+		"exp":              expiresAt.Unix(),
+		"iat":              now.Unix(),
+		claimEmailVerified: subject.EmailVerified,
+	}
+	if subject.ImpersonatedBy != "" {
+		claims[claimActor] = map[string]string{"sub": subject.ImpersonatedBy}
+	}
+	if scope.ReadOnly {
+		claims[claimScope] = scopeRead
 	}
+	if len(scope.AccountIDs) > 0 {
+		accounts := make([]string, 0, len(scope.AccountIDs))
+		for _, accountID := range scope.AccountIDs {
+			accounts = append(accounts, accountID.String())
+		}
+		claims[claimAccounts] = accounts
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+	signed, err := token.SignedString(m.secretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, time.Unix(expiresAt.Unix(), 0), nil
+}
+
+// Validate parses and verifies a signed access token, returning the user it was issued to
+// and its expiration time. Any parsing, signature or expiration failure results in ErrInvalidToken
+func (m *JWTManager) Validate(accessToken string) (*AccessTokenClaims, error) {
+	token, err := jwt.Parse(accessToken, func(t *jwt.Token) (any, error) {
+		return m.secretKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// A verification token would otherwise pass for an access token, being signed with the same key
+	mapClaims, _ := token.Claims.(jwt.MapClaims)
+	if _, ok := mapClaims[claimPurpose]; ok {
+		return nil, fmt.Errorf("%w: not an access token", ErrInvalidToken)
+	}
+
+	sub, err := token.Claims.GetSubject()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+
+	exp, err := token.Claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, fmt.Errorf("%w: missing expiration", ErrInvalidToken)
+	}
+
+	scope, err := scopeFromClaims(token.Claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// The tokens issued before the claim are taken as unverified, they expire soon enough
+	emailVerified, _ := mapClaims[claimEmailVerified].(bool)
+
+	var impersonatedBy string
+	if raw, ok := mapClaims[claimActor]; ok {
+		actor, _ := raw.(map[string]any)
+		if impersonatedBy, _ = actor["sub"].(string); impersonatedBy == "" {
+			return nil, fmt.Errorf("%w: invalid actor claim", ErrInvalidToken)
+		}
+	}
+
+	return &AccessTokenClaims{
+		UserID:         userID,
+		ExpiresAt:      exp.Unix(),
+		Scope:          scope,
+		EmailVerified:  emailVerified,
+		ImpersonatedBy: impersonatedBy,
+	}, nil
+}
+
+// GenerateEmailVerification signs the token of the link confirming the user owns the email, valid for ttl
+func (m *JWTManager) GenerateEmailVerification(userID uuid.UUID, email string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":        userID.String(),
+		"exp":        expiresAt.Unix(),
+		"iat":        now.Unix(),
+		claimPurpose: purposeEmailVerification,
+		claimEmail:   email,
+	})
+	signed, err := token.SignedString(m.secretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, time.Unix(expiresAt.Unix(), 0), nil
+}
+
+// ValidateEmailVerification verifies a token signed by GenerateEmailVerification, returning the user and the email it
+// was issued for. Any other token, an access token included, results in ErrInvalidVerificationToken
+func (m *JWTManager) ValidateEmailVerification(verificationToken string) (*EmailVerificationClaims, error) {
+	token, err := jwt.Parse(verificationToken, func(t *jwt.Token) (any, error) {
+		return m.secretKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVerificationToken, err)
+	}
+
+	mapClaims, _ := token.Claims.(jwt.MapClaims)
+	if purpose, _ := mapClaims[claimPurpose].(string); purpose != purposeEmailVerification {
+		return nil, fmt.Errorf("%w: not a verification token", ErrInvalidVerificationToken)
+	}
+	email, _ := mapClaims[claimEmail].(string)
+	if email == "" {
+		return nil, fmt.Errorf("%w: missing email", ErrInvalidVerificationToken)
+	}
+
+	sub, err := token.Claims.GetSubject()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVerificationToken, err)
+	}
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", ErrInvalidVerificationToken)
+	}
+
+	return &EmailVerificationClaims{UserID: userID, Email: email}, nil
+}
+
+// scopeFromClaims reads the scope of a token, a token without scope claims granting everything
+func scopeFromClaims(claims jwt.Claims) (authctx.Scope, error) {
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return authctx.Scope{}, fmt.Errorf("unexpected claims type %T", claims)
+	}
+
+	var scope authctx.Scope
+	if raw, ok := mapClaims[claimScope]; ok {
+		if raw != scopeRead {
+			return authctx.Scope{}, fmt.Errorf("unknown scope %v", raw)
+		}
+		scope.ReadOnly = true
+	}
+	if raw, ok := mapClaims[claimAccounts]; ok {
+		accounts, ok := raw.([]any)
+		if !ok {
+			return authctx.Scope{}, fmt.Errorf("invalid accounts claim")
+		}
+		for _, account := range accounts {
+			value, _ := account.(string)
+			accountID, err := uuid.Parse(value)
+			if err != nil {
+				return authctx.Scope{}, fmt.Errorf("invalid account in accounts claim")
+			}
+			scope.AccountIDs = append(scope.AccountIDs, accountID)
+		}
+	}
+	return scope, nil
 }
@@ -2,10 +2,12 @@ package identity
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/retry"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -15,11 +17,15 @@ import (
 
 // Single Table Design :)
 type tokenItem struct {
-	PK        string    `dynamodbav:"PK"` // Format: USER#<UserID>
-	SK        string    `dynamodbav:"SK"` // Format: TOKEN#<TokenHash>
-	UserID    uuid.UUID `dynamodbav:"UserID"`
-	TokenHash string    `dynamodbav:"TokenHash"`
-	ExpiresAt int64     `dynamodbav:"ExpiresAt"`
+	PK               string    `dynamodbav:"PK"`     // Format: USER#<UserID>
+	SK               string    `dynamodbav:"SK"`     // Format: TOKEN#<TokenHash>
+	GSI1PK           string    `dynamodbav:"GSI1PK"` // Format: TOKEN#<TokenHash>
+	GSI1SK           string    `dynamodbav:"GSI1SK"` // Format: TOKEN
+	EntityType       string    `dynamodbav:"EntityType"`
+	UserID           uuid.UUID `dynamodbav:"UserID"`
+	TokenHash        string    `dynamodbav:"TokenHash"`
+	ExpiresAt        int64     `dynamodbav:"ExpiresAt"`
+	SessionStartedAt int64     `dynamodbav:"SessionStartedAt,omitempty"`
 }
 
 var _ TokenRepository = (*DynamoDBTokenRepository)(nil)
@@ -38,11 +44,15 @@ func NewDynamoDBTokenRepository(c *dynamodb.Client, tn string) *DynamoDBTokenRep
 
 func (r *DynamoDBTokenRepository) Save(ctx context.Context, token *RefreshToken) error {
 	item := tokenItem{
-		PK:        fmt.Sprintf("USER#%s", token.UserID),
-		SK:        fmt.Sprintf("TOKEN#%s", token.TokenHash),
-		UserID:    token.UserID,
-		TokenHash: token.TokenHash,
-		ExpiresAt: token.ExpiresAt,
+		PK:               userPK(token.UserID),
+		SK:               tokenSK(token.TokenHash),
+		GSI1PK:           tokenSK(token.TokenHash),
+		GSI1SK:           tokenGSI1SK,
+		EntityType:       entityToken,
+		UserID:           token.UserID,
+		TokenHash:        token.TokenHash,
+		ExpiresAt:        token.ExpiresAt,
+		SessionStartedAt: token.SessionStartedAt,
 	}
 
 	av, err := attributevalue.MarshalMap(item)
@@ -55,7 +65,11 @@ func (r *DynamoDBTokenRepository) Save(ctx context.Context, token *RefreshToken)
 		Item:      av,
 	}
 
-	if _, err := r.client.PutItem(ctx, input); err != nil {
+	err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save token to dynamodb: %v", err)
 	}
 
@@ -63,50 +77,57 @@ func (r *DynamoDBTokenRepository) Save(ctx context.Context, token *RefreshToken)
 }
 
 // revoke a refresh token - usign 'read-then-write' pattern
-func (r *DynamoDBTokenRepository) Revoke(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+func (r *DynamoDBTokenRepository) Revoke(ctx context.Context, tokenHash string) (*RefreshToken, error) {
 	// use GSI to find the full token item
 	queryInput := &dynamodb.QueryInput{
 		TableName:              &r.tableName,
-		IndexName:              aws.String("TokenHashIndex"), // GSI to be created
-		KeyConditionExpression: aws.String("TokenHash = :hash"),
+		IndexName:              aws.String(gsi1Name),
+		KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK = :sk"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":hash": &types.AttributeValueMemberS{Value: tokenHash},
+			":pk": &types.AttributeValueMemberS{Value: tokenSK(tokenHash)},
+			":sk": &types.AttributeValueMemberS{Value: tokenGSI1SK},
 		},
 	}
 
 	output, err := r.client.Query(ctx, queryInput)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to query token by hash: %v", err)
+		return nil, fmt.Errorf("failed to query token by hash: %v", err)
 	}
 	if len(output.Items) == 0 {
-		return uuid.Nil, fmt.Errorf("token not found")
+		return nil, fmt.Errorf("token not found")
 	}
 
 	var item tokenItem
 	if err := attributevalue.UnmarshalMap(output.Items[0], &item); err != nil {
-		return uuid.Nil, fmt.Errorf("failed to unmarshal token item: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal token item: %v", err)
 	}
 
 	// delet the item using its full primary key (PK and SK)
 	deleteInput := &dynamodb.DeleteItemInput{
 		TableName: &r.tableName,
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: item.PK},
-			"SK": &types.AttributeValueMemberS{Value: item.SK},
-		},
+		Key:       itemKey(item.PK, item.SK),
 	}
 
-	if _, err := r.client.DeleteItem(ctx, deleteInput); err != nil {
-		return uuid.Nil, fmt.Errorf("failed to delete token: %v", err)
+	err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+		_, err := r.client.DeleteItem(ctx, deleteInput)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete token: %v", err)
 	}
 
-	return item.UserID, nil
+	return &RefreshToken{
+		TokenHash:        item.TokenHash,
+		UserID:           item.UserID,
+		ExpiresAt:        item.ExpiresAt,
+		SessionStartedAt: item.SessionStartedAt,
+	}, nil
 }
 
 func (r *DynamoDBTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
 	log := ctxlogger.GetLogger(ctx)
 
-	pk := fmt.Sprintf("USER#%s", userID)
+	pk := userPK(userID)
 	queryInput := &dynamodb.QueryInput{
 		TableName:              &r.tableName,
 		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk_prefix)"),
@@ -144,27 +165,34 @@ func (r *DynamoDBTokenRepository) RevokeAllForUser(ctx context.Context, userID u
 	const maxBatchSize = 25
 	for i := 0; i < len(writeRequests); i += maxBatchSize {
 		end := min(i+maxBatchSize, len(writeRequests))
-		chunk := writeRequests[i:end]
+		pending := writeRequests[i:end]
 
-		batchInput := &dynamodb.BatchWriteItemInput{
-			RequestItems: map[string][]types.WriteRequest{
-				r.tableName: chunk,
-			},
-		}
-
-		output, err := r.client.BatchWriteItem(ctx, batchInput)
-		if err != nil {
-			return fmt.Errorf("failed to batch delete tokens: %v", err)
-		}
-
-		// Handle unprocessed items (simplified approach with logging).
-		// In the future, we may have retry logic here.
-		if len(output.UnprocessedItems) > 0 {
-			unprocessedCount := len(output.UnprocessedItems[r.tableName])
+		// Unprocessed items are sent again with a backoff, as DynamoDB asks
+		err := retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+			batchInput := &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{
+					r.tableName: pending,
+				},
+			}
+
+			output, err := r.client.BatchWriteItem(ctx, batchInput)
+			if err != nil {
+				return err
+			}
+			if pending = output.UnprocessedItems[r.tableName]; len(pending) > 0 {
+				return errUnprocessedItems
+			}
+			return nil
+		})
+		if errors.Is(err, errUnprocessedItems) {
 			log.Warn("some tokens were not processed in batch delete and will be orphaned",
-				slog.Int("unprocessed_count", unprocessedCount),
+				slog.Int("unprocessed_count", len(pending)),
 				slog.String("user_id", userID.String()),
 			)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to batch delete tokens: %v", err)
 		}
 	}
 
@@ -8,27 +8,37 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/authctx"
 	"github.com/google/uuid"
 )
 
 var _ TokenManager = (*TokenService)(nil)
 
 type TokenService struct {
-	tokenRepo       TokenRepository
-	jwtGenerator    TokenGenerator
-	refreshTokenTTL time.Duration
+	tokenRepo     TokenRepository
+	userRepo      UserRepository // userRepo loads the users whose tokens are rotated, their claims being current
+	jwtGenerator  TokenGenerator
+	refreshPolicy RefreshPolicy
 }
 
-func NewTokenService(repo TokenRepository, jwtGen TokenGenerator, refreshTTL time.Duration) *TokenService {
+func NewTokenService(repo TokenRepository, users UserRepository, jwtGen TokenGenerator, rp RefreshPolicy) *TokenService {
 	return &TokenService{
-		tokenRepo:       repo,
-		jwtGenerator:    jwtGen,
-		refreshTokenTTL: refreshTTL,
+		tokenRepo:     repo,
+		userRepo:      users,
+		jwtGenerator:  jwtGen,
+		refreshPolicy: rp,
 	}
 }
 
-func (s *TokenService) NewPairForUser(ctx context.Context, userID uuid.UUID) (*TokenPair, error) {
-	accessToken, err := s.jwtGenerator.Generate(userID)
+// NewPairForUser starts a session for the user, as a login does
+func (s *TokenService) NewPairForUser(ctx context.Context, user *User) (*TokenPair, error) {
+	now := time.Now()
+	return s.newPair(ctx, user, now, now.Add(s.refreshPolicy.TTL))
+}
+
+// newPair issues the tokens of a session started at startedAt, the refresh token expiring at expiresAt
+func (s *TokenService) newPair(ctx context.Context, user *User, startedAt, expiresAt time.Time) (*TokenPair, error) {
+	accessToken, err := s.jwtGenerator.Generate(subjectOf(user))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %v", err)
 	}
@@ -39,9 +49,10 @@ func (s *TokenService) NewPairForUser(ctx context.Context, userID uuid.UUID) (*T
 	}
 
 	rt := &RefreshToken{
-		TokenHash: refreshTokenHash,
-		UserID:    userID,
-		ExpiresAt: time.Now().Add(s.refreshTokenTTL).Unix(),
+		TokenHash:        refreshTokenHash,
+		UserID:           user.ID,
+		ExpiresAt:        expiresAt.Unix(),
+		SessionStartedAt: startedAt.Unix(),
 	}
 	if err := s.tokenRepo.Save(ctx, rt); err != nil {
 		return nil, fmt.Errorf("failed to save refresh token: %v", err)
@@ -57,20 +68,76 @@ func (s *TokenService) RotateRefreshToken(ctx context.Context, refreshToken stri
 	hash := sha256.Sum256([]byte(refreshToken))
 	tokenHash := hex.EncodeToString(hash[:])
 
-	// Revoke the old token. Successful revocation proves the token was valid
-	// and returns the UserID it belonged to
-	userID, err := s.tokenRepo.Revoke(ctx, tokenHash)
+	// Revoke the old token. Successful revocation proves the token was issued
+	// and returns the session it belonged to
+	old, err := s.tokenRepo.Revoke(ctx, tokenHash)
 	if err != nil {
 		return nil, fmt.Errorf("invalid or expired refresh token: %v", err)
 	}
 
-	return s.NewPairForUser(ctx, userID)
+	now := time.Now()
+	current := time.Unix(old.ExpiresAt, 0)
+	if !now.Before(current) {
+		return nil, fmt.Errorf("invalid or expired refresh token: expired at %s", current.UTC().Format(time.RFC3339))
+	}
+
+	// The tokens saved before the session start was recorded are assumed to come from a login
+	startedAt := current.Add(-s.refreshPolicy.TTL)
+	if old.SessionStartedAt != 0 {
+		startedAt = time.Unix(old.SessionStartedAt, 0)
+	}
+
+	// The user is loaded again, so the new access token claims what changed since the login
+	user, err := s.userRepo.FindByID(ctx, old.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user of refresh token: %v", err)
+	}
+	if user.DeactivatedAt != nil {
+		return nil, fmt.Errorf("invalid or expired refresh token: %v", ErrUserDeactivated)
+	}
+
+	return s.newPair(ctx, user, startedAt, s.refreshPolicy.expiresAt(now, startedAt, current))
 }
 
 func (s *TokenService) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
 	return s.tokenRepo.RevokeAllForUser(ctx, userID)
 }
 
+func (s *TokenService) ValidateAccessToken(accessToken string) (*AccessTokenClaims, error) {
+	return s.jwtGenerator.Validate(accessToken)
+}
+
+func (s *TokenService) IssueScopedToken(user *User, scope authctx.Scope, ttl time.Duration) (string, time.Time, error) {
+	token, expiresAt, err := s.jwtGenerator.GenerateScoped(subjectOf(user), scope, ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate scoped access token: %v", err)
+	}
+	return token, expiresAt, nil
+}
+
+func (s *TokenService) IssueImpersonationToken(user *User, operator string, ttl time.Duration) (string, time.Time, error) {
+	subject := subjectOf(user)
+	subject.ImpersonatedBy = operator
+
+	token, expiresAt, err := s.jwtGenerator.GenerateScoped(subject, authctx.Scope{}, ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate impersonation token: %v", err)
+	}
+	return token, expiresAt, nil
+}
+
+func (s *TokenService) IssueEmailVerificationToken(user *User, ttl time.Duration) (string, time.Time, error) {
+	token, expiresAt, err := s.jwtGenerator.GenerateEmailVerification(user.ID, user.Email, ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate email verification token: %v", err)
+	}
+	return token, expiresAt, nil
+}
+
+func (s *TokenService) ValidateEmailVerificationToken(verificationToken string) (*EmailVerificationClaims, error) {
+	return s.jwtGenerator.ValidateEmailVerification(verificationToken)
+}
+
 func (s *TokenService) generateOpaqueToken() (token, hash string, err error) {
 	randomBytes := make([]byte, 32)
 	if _, err := rand.Read(randomBytes); err != nil {
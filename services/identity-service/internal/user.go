@@ -2,26 +2,35 @@ package identity
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/Guizzs26/fintrack/pkg/errx"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrEmailAlreadyInUse = errors.New("email is already in use")
-	ErrUserNotFound      = errors.New("user not found")
+	ErrEmailAlreadyInUse  = errx.New(errx.CategoryConflict, "EMAIL_ALREADY_IN_USE", "email is already in use")
+	ErrUserNotFound       = errx.New(errx.CategoryNotFound, "USER_NOT_FOUND", "user not found")
+	ErrInvalidCredentials = errx.New(errx.CategoryUnauthenticated, "INVALID_CREDENTIALS", "invalid credentials")
+	ErrInvalidToken       = errx.New(errx.CategoryUnauthenticated, "INVALID_TOKEN", "invalid or expired access token")
+	ErrUserDeactivated    = errx.New(errx.CategoryForbidden, "USER_DEACTIVATED", "the user is deactivated while their account is being deleted")
+	ErrUserConflict       = errx.New(errx.CategoryConflict, "USER_CONFLICT", "the user was changed by another request, load it and try again")
 )
 
 type UserRepository interface {
-	Save(ctx context.Context, user *User) error
+	Create(ctx context.Context, user *User, events ...contracts.Event) error        // Create writes the events to the outbox along with the user
+	Save(ctx context.Context, user *User) error                                     // Save fails with ErrUserConflict when the user changed since it was loaded
+	Publish(ctx context.Context, userID uuid.UUID, events ...contracts.Event) error // Publish writes the events to the outbox alone
 	FindByEmail(ctx context.Context, email string) (*User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*User, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*User, error) // FindByIDs leaves the unknown users out
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type TokenRepository interface {
 	Save(ctx context.Context, token *RefreshToken) error
-	Revoke(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	Revoke(ctx context.Context, tokenHash string) (*RefreshToken, error) // Revoke returns the revoked token
 	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
 }
 
@@ -32,10 +41,18 @@ type User struct {
 	PasswordHash string    `dynamodbav:"PasswordHash"`
 	CreatedAt    time.Time `dynamodbav:"CreatedAt"`
 	UpdatedAt    time.Time `dynamodbav:"UpdatedAt"`
+	// EmailVerifiedAt is set once the user confirmed they own their email, claimed by their access tokens
+	EmailVerifiedAt *time.Time `dynamodbav:"EmailVerifiedAt,omitempty"`
+	// DeactivatedAt is set while the account of the user is being deleted, blocking their logins
+	DeactivatedAt *time.Time `dynamodbav:"DeactivatedAt,omitempty"`
+	// Version is incremented by every update, starting at 1 when the user is created
+	Version int64 `dynamodbav:"Version"`
 }
 
 type RefreshToken struct {
 	TokenHash string    `dynamodbav:"TokenHash"`
 	UserID    uuid.UUID `dynamodbav:"UserID"`
 	ExpiresAt int64     `dynamodbav:"ExpiresAt"`
+	// SessionStartedAt is the login the token was rotated from, 0 for the tokens saved before it was recorded
+	SessionStartedAt int64 `dynamodbav:"SessionStartedAt,omitempty"`
 }
@@ -0,0 +1,267 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/retry"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// MigrationReport counts the items a migration of the users went through
+type MigrationReport struct {
+	Scanned   int // Scanned are the items read from the legacy table
+	Migrated  int // Migrated are the users written to the table, or that would be on a dry run
+	Skipped   int // Skipped are the users already migrated and the items that are not users
+	Conflicts int // Conflicts are the users whose email is reserved by another user, left for a manual fix
+}
+
+// MigrateLegacyUsers copies the users of a legacy table, keyed by their bare ID, to the table of the identity service
+// keyed by PK and SK, reserving their emails along. The copies are conditional, so running it again skips the users
+// already migrated. The emails are normalized by the policy the service looks them up with. On a dry run the users are
+// only counted
+func MigrateLegacyUsers(ctx context.Context, client *dynamodb.Client, legacyTable, tableName string, emails EmailPolicy, dryRun bool) (MigrationReport, error) {
+	log := ctxlogger.GetLogger(ctx)
+
+	var report MigrationReport
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{TableName: &legacyTable})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return report, fmt.Errorf("failed to scan legacy table %s: %w", legacyTable, err)
+		}
+
+		for _, av := range output.Items {
+			report.Scanned++
+			if _, ok := av["ID"]; !ok {
+				report.Skipped++
+				continue
+			}
+
+			var user User
+			if err := attributevalue.UnmarshalMap(av, &user); err != nil {
+				return report, fmt.Errorf("failed to unmarshal legacy user: %w", err)
+			}
+			user.Email = emails.Normalize(user.Email)
+			if dryRun {
+				report.Migrated++
+				continue
+			}
+
+			items, err := userPuts(tableName, &user)
+			if err != nil {
+				return report, err
+			}
+			// The token makes a retry after a lost response a no-op instead of a failed condition
+			input := &dynamodb.TransactWriteItemsInput{TransactItems: items, ClientRequestToken: aws.String(uuid.NewString())}
+			err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+				_, err := client.TransactWriteItems(ctx, input)
+				return err
+			})
+			switch {
+			case err == nil:
+				report.Migrated++
+			case conditionFailed(err, userPutsProfile):
+				report.Skipped++
+			case conditionFailed(err, userPutsEmail):
+				log.Warn("email of a legacy user is reserved by another user, not migrating it", slog.String("user_id", user.ID.String()))
+				report.Conflicts++
+			default:
+				return report, fmt.Errorf("failed to migrate user %s: %w", user.ID, err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// FoldEmailAliases re-keys the emails reserved with a plus alias (e.g., "EMAIL#ana+bank@mail.com") under the folded
+// email, updating the profile of their user in the same transaction, for the tables written before FOLD_EMAIL_ALIASES
+// was turned on. An email whose folded form is reserved by another user is left as is and counted as a conflict, the
+// service still finding its user by the email as typed. The folded emails are counted as Migrated, and running it
+// again is safe, only the emails still aliased being scanned
+func FoldEmailAliases(ctx context.Context, client *dynamodb.Client, tableName string, dryRun bool) (MigrationReport, error) {
+	log := ctxlogger.GetLogger(ctx)
+	policy := EmailPolicy{FoldPlusAliases: true}
+
+	var report MigrationReport
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:        &tableName,
+		FilterExpression: aws.String("EntityType = :entity AND contains(PK, :plus)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":entity": &types.AttributeValueMemberS{Value: entityEmail},
+			":plus":   &types.AttributeValueMemberS{Value: "+"},
+		},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return report, fmt.Errorf("failed to scan emails of table %s: %w", tableName, err)
+		}
+
+		for _, av := range output.Items {
+			report.Scanned++
+			var item emailItem
+			if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+				return report, fmt.Errorf("failed to unmarshal email item: %w", err)
+			}
+			email := strings.TrimPrefix(item.PK, emailPK(""))
+			folded := policy.Normalize(email)
+			if folded == email {
+				report.Skipped++
+				continue
+			}
+			if dryRun {
+				report.Migrated++
+				continue
+			}
+
+			items, err := foldedEmailWrites(tableName, item.UserID, email, folded)
+			if err != nil {
+				return report, err
+			}
+			input := &dynamodb.TransactWriteItemsInput{TransactItems: items, ClientRequestToken: aws.String(uuid.NewString())}
+			err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+				_, err := client.TransactWriteItems(ctx, input)
+				return err
+			})
+			switch {
+			case err == nil:
+				report.Migrated++
+			case conditionFailed(err, foldedEmailPut):
+				log.Warn("folded email is reserved by another user, keeping the alias", slog.String("user_id", item.UserID.String()))
+				report.Conflicts++
+			case conditionFailed(err, foldedEmailRelease), conditionFailed(err, foldedEmailProfile):
+				report.Skipped++ // The user was deleted or changed their email meanwhile
+			default:
+				return report, fmt.Errorf("failed to fold email of user %s: %w", item.UserID, err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// MarkEmailsVerified marks the emails of the users who never verified theirs as verified at verifiedAt, for the tables
+// written before the verification was mailed. The users signed up then had no way to verify, so they are trusted with
+// the email they log in with rather than locked out of the writes when the ledger requires verified emails. The update
+// bumps the version of the user, failing the concurrent saves as any update does. The users marked are counted as
+// Migrated, and running it again is safe, only the unverified users being scanned
+func MarkEmailsVerified(ctx context.Context, client *dynamodb.Client, tableName string, verifiedAt time.Time, dryRun bool) (MigrationReport, error) {
+	at, err := attributevalue.Marshal(verifiedAt.UTC())
+	if err != nil {
+		return MigrationReport{}, fmt.Errorf("failed to marshal verification time for dynamodb: %v", err)
+	}
+
+	var report MigrationReport
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                &tableName,
+		FilterExpression:         aws.String("EntityType = :entity AND attribute_not_exists(#eva)"),
+		ExpressionAttributeNames: map[string]string{"#eva": "EmailVerifiedAt"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":entity": &types.AttributeValueMemberS{Value: entityUser},
+		},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return report, fmt.Errorf("failed to scan users of table %s: %w", tableName, err)
+		}
+
+		for _, av := range output.Items {
+			report.Scanned++
+			var item userItem
+			if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+				return report, fmt.Errorf("failed to unmarshal user item: %w", err)
+			}
+			if dryRun {
+				report.Migrated++
+				continue
+			}
+
+			// The users written before the versions were introduced have none, starting at 1 like a first save
+			input := &dynamodb.UpdateItemInput{
+				TableName:                &tableName,
+				Key:                      itemKey(item.PK, item.SK),
+				UpdateExpression:         aws.String("SET #eva = :at, #v = if_not_exists(#v, :zero) + :one"),
+				ConditionExpression:      aws.String("attribute_exists(PK) AND attribute_not_exists(#eva)"),
+				ExpressionAttributeNames: map[string]string{"#eva": "EmailVerifiedAt", "#v": "Version"},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":at":   at,
+					":zero": &types.AttributeValueMemberN{Value: "0"},
+					":one":  &types.AttributeValueMemberN{Value: "1"},
+				},
+			}
+			err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+				_, err := client.UpdateItem(ctx, input)
+				return err
+			})
+			var condErr *types.ConditionalCheckFailedException
+			switch {
+			case err == nil:
+				report.Migrated++
+			case errors.As(err, &condErr):
+				report.Skipped++ // The user was deleted or verified their email meanwhile
+			default:
+				return report, fmt.Errorf("failed to mark email of user %s verified: %w", item.ID, err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// Indexes of the items written by foldedEmailWrites, to tell which condition canceled the transaction
+const (
+	foldedEmailPut = iota
+	foldedEmailRelease
+	foldedEmailProfile
+)
+
+// foldedEmailWrites returns the transaction items reserving the folded email of a user, releasing the aliased one and
+// pointing their profile at the folded one
+func foldedEmailWrites(tableName string, userID uuid.UUID, email, folded string) ([]types.TransactWriteItem, error) {
+	reservation, err := attributevalue.MarshalMap(emailItem{
+		PK:         emailPK(folded),
+		SK:         emailSK,
+		EntityType: entityEmail,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user email for dynamodb: %v", err)
+	}
+	owner, err := attributevalue.Marshal(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user id for dynamodb: %v", err)
+	}
+
+	return []types.TransactWriteItem{
+		{Put: &types.Put{TableName: &tableName, Item: reservation, ConditionExpression: aws.String("attribute_not_exists(PK)")}},
+		{Delete: &types.Delete{
+			TableName:                 &tableName,
+			Key:                       itemKey(emailPK(email), emailSK),
+			ConditionExpression:       aws.String("UserID = :owner"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{":owner": owner},
+		}},
+		{Update: &types.Update{
+			TableName:                &tableName,
+			Key:                      itemKey(userPK(userID), userProfileSK),
+			UpdateExpression:         aws.String("SET #email = :folded"),
+			ConditionExpression:      aws.String("attribute_exists(PK) AND #email = :email"),
+			ExpressionAttributeNames: map[string]string{"#email": "Email"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":folded": &types.AttributeValueMemberS{Value: folded},
+				":email":  &types.AttributeValueMemberS{Value: email},
+			},
+		}},
+	}, nil
+}
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/contracts"
 	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/retry"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -16,6 +20,23 @@ import (
 
 var _ UserRepository = (*DynamoDBUserRepository)(nil)
 
+// Single Table Design, the profile of a user lives in their partition, next to their refresh tokens
+type userItem struct {
+	PK         string `dynamodbav:"PK"` // Format: USER#<ID>
+	SK         string `dynamodbav:"SK"` // Format: PROFILE
+	EntityType string `dynamodbav:"EntityType"`
+	User
+}
+
+// emailItem reserves the normalized email of a user, written in the same transaction as their profile, so two users
+// cannot sign up with the same email. A condition on the profile alone would only guard its own key
+type emailItem struct {
+	PK         string    `dynamodbav:"PK"` // Format: EMAIL#<Email>
+	SK         string    `dynamodbav:"SK"` // Format: EMAIL
+	EntityType string    `dynamodbav:"EntityType"`
+	UserID     uuid.UUID `dynamodbav:"UserID"`
+}
+
 // DynamoDBUserRepository is a DynamoDB implementation of the UserRepository interface
 type DynamoDBUserRepository struct {
 	client    *dynamodb.Client
@@ -29,107 +50,237 @@ func NewDynamoDBUserRepository(c *dynamodb.Client, tn string) *DynamoDBUserRepos
 	}
 }
 
-// Save persists a new or updated user to DynamoDb (upsert-like)
-func (r *DynamoDBUserRepository) Save(ctx context.Context, user *User) error {
+// Create persists a new user along with the events of its creation, written to the outbox in the same transaction
+func (r *DynamoDBUserRepository) Create(ctx context.Context, user *User, events ...contracts.Event) error {
 	log := ctxlogger.GetLogger(ctx)
 
-	isNewUser := user.CreatedAt.IsZero()
-	if isNewUser {
-		// marshal Go struct into a map of DynamoDB attribute values
-		item, err := attributevalue.MarshalMap(user)
+	items, err := userPuts(r.tableName, user)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		put, err := outboxPut(r.tableName, user.ID, event, user.CreatedAt)
 		if err != nil {
-			return fmt.Errorf("failed to marshal user for dynamodb: %v", err)
+			return err
 		}
+		items = append(items, put)
+	}
 
-		// create the input for the PutItem operation
-		input := &dynamodb.PutItemInput{
-			TableName:           &r.tableName,
-			Item:                item,
-			ConditionExpression: aws.String("attribute_not_exists(Email)"),
+	log.Debug("creating new user in dynamodb", slog.Any("item", items[0].Put.Item), slog.Int("events", len(events)))
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: items, ClientRequestToken: aws.String(uuid.NewString())}
+	err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		if conditionFailed(err, userPutsEmail) {
+			return ErrEmailAlreadyInUse
 		}
+		return fmt.Errorf("failed to create user to dynamodb: %v", err)
+	}
 
-		log.Debug("creating new user in dynamodb", slog.Any("item", item))
-		if _, err := r.client.PutItem(ctx, input); err != nil {
-			var condErr *types.ConditionalCheckFailedException
-			if errors.As(err, &condErr) {
-				return ErrEmailAlreadyInUse
-			}
-			return fmt.Errorf("failed to create user to dynamodb: %v", err)
-		}
+	user.Version = 1
+	return nil
+}
+
+// Indexes of the items written by userPuts, to tell which condition canceled the transaction
+const (
+	userPutsProfile = iota
+	userPutsEmail
+)
+
+// userPuts returns the transaction items writing the profile of a new user at version 1 and reserving their email
+func userPuts(tableName string, user *User) ([]types.TransactWriteItem, error) {
+	item := userItem{
+		PK:         userPK(user.ID),
+		SK:         userProfileSK,
+		EntityType: entityUser,
+		User:       *user,
+	}
+	item.Version = 1
+	profile, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user for dynamodb: %v", err)
+	}
+	email, err := attributevalue.MarshalMap(emailItem{
+		PK:         emailPK(user.Email),
+		SK:         emailSK,
+		EntityType: entityEmail,
+		UserID:     user.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user email for dynamodb: %v", err)
+	}
+
+	return []types.TransactWriteItem{
+		{Put: &types.Put{TableName: &tableName, Item: profile, ConditionExpression: aws.String("attribute_not_exists(PK)")}},
+		{Put: &types.Put{TableName: &tableName, Item: email, ConditionExpression: aws.String("attribute_not_exists(PK)")}},
+	}, nil
+}
+
+// conditionFailed reports whether the condition of the item at index canceled the transaction
+func conditionFailed(err error, index int) bool {
+	var canceledErr *types.TransactionCanceledException
+	return errors.As(err, &canceledErr) && len(canceledErr.CancellationReasons) > index &&
+		aws.ToString(canceledErr.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
+
+// Save persists a new or updated user to DynamoDb (upsert-like)
+// An update is conditioned on the version the user was loaded at, so concurrent updates cannot overwrite each other:
+// the loser fails with ErrUserConflict, or ErrUserNotFound when the user was deleted meanwhile
+func (r *DynamoDBUserRepository) Save(ctx context.Context, user *User) error {
+	log := ctxlogger.GetLogger(ctx)
+
+	isNewUser := user.CreatedAt.IsZero()
+	if isNewUser {
+		return r.Create(ctx, user)
+	}
+
+	log.Debug("updating existing user in dynamodb", slog.String("user_id", user.ID.String()))
+	// The write ID lets a retry recognize the write of an attempt whose response was lost, which fails its version
+	// condition on the next attempt without being a conflict
+	writeID := uuid.NewString()
+	updateExpr := "SET #name = :name, #pwhash = :pwhash, #ua = :ua, #v = :next, #wid = :wid"
+	exprAttrNames := map[string]string{
+		"#name":   "Name",
+		"#pwhash": "PasswordHash",
+		"#ua":     "UpdatedAt",
+		"#da":     "DeactivatedAt",
+		"#eva":    "EmailVerifiedAt",
+		"#v":      "Version",
+		"#wid":    userWriteIDAttr,
+	}
+	values := map[string]interface{}{
+		":name":     user.Name,
+		":pwhash":   user.PasswordHash,
+		":ua":       user.UpdatedAt,
+		":expected": user.Version,
+		":next":     user.Version + 1,
+		":wid":      writeID,
+	}
+	// The users written before the versions were introduced have none, loaded at version 0
+	condExpr := "attribute_exists(PK) AND #v = :expected"
+	if user.Version == 0 {
+		condExpr = "attribute_exists(PK) AND (attribute_not_exists(#v) OR #v = :expected)"
+	}
+	// The timestamps left unset are removed, so the stored profile matches the user
+	var removed []string
+	if user.DeactivatedAt != nil {
+		updateExpr += ", #da = :da"
+		values[":da"] = *user.DeactivatedAt
+	} else {
+		removed = append(removed, "#da")
+	}
+	if user.EmailVerifiedAt != nil {
+		updateExpr += ", #eva = :eva"
+		values[":eva"] = *user.EmailVerifiedAt
 	} else {
-		log.Debug("updating existing user in dynamodb", slog.String("user_id", user.ID.String()))
-		updateExpr := "SET #name = :name, #pwhash = :pwhash, #ua = :ua"
-		exprAttrNames := map[string]string{
-			"#name":   "Name",
-			"#pwhash": "PasswordHash",
-			"#ua":     "UpdatedAt",
+		removed = append(removed, "#eva")
+	}
+	if len(removed) > 0 {
+		updateExpr += " REMOVE " + strings.Join(removed, ", ")
+	}
+	exprAttrValues, err := attributevalue.MarshalMap(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update values for dynamodb: %v", err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 &r.tableName,
+		Key:                       itemKey(userPK(user.ID), userProfileSK),
+		UpdateExpression:          aws.String(updateExpr),
+		ConditionExpression:       aws.String(condExpr),
+		ExpressionAttributeNames:  exprAttrNames,
+		ExpressionAttributeValues: exprAttrValues,
+		// The item is returned on a failed condition, telling a deleted user from a changed one
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+		_, err := r.client.UpdateItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if !errors.As(err, &condErr) {
+			return fmt.Errorf("failed to update user in dynamodb: %v", err)
 		}
-		exprAttrValues, err := attributevalue.MarshalMap(map[string]interface{}{
-			":name":   user.Name,
-			":pwhash": user.PasswordHash,
-			":ua":     user.UpdatedAt,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to marshal update values for dynamodb: %v", err)
+		switch {
+		case condErr.Item == nil:
+			return ErrUserNotFound.With("user_id", user.ID)
+		case !writtenBy(condErr.Item, writeID):
+			return ErrUserConflict.With("user_id", user.ID)
 		}
+		log.Debug("user update already applied by a previous attempt", slog.String("user_id", user.ID.String()))
+	}
 
-		input := &dynamodb.UpdateItemInput{
-			TableName: &r.tableName,
-			Key: map[string]types.AttributeValue{
-				"ID": &types.AttributeValueMemberS{Value: user.ID.String()},
-			},
-			UpdateExpression:          aws.String(updateExpr),
-			ExpressionAttributeNames:  exprAttrNames,
-			ExpressionAttributeValues: exprAttrValues,
-		}
+	user.Version++
+	return nil
+}
 
-		if _, err := r.client.UpdateItem(ctx, input); err != nil {
-			return fmt.Errorf("failed to update user in dynamodb: %v", err)
+// Publish writes the events of a user to the outbox, for the events recorded without a change of the user
+func (r *DynamoDBUserRepository) Publish(ctx context.Context, userID uuid.UUID, events ...contracts.Event) error {
+	log := ctxlogger.GetLogger(ctx)
+
+	occurredAt := time.Now().UTC()
+	items := make([]types.TransactWriteItem, 0, len(events))
+	for _, event := range events {
+		put, err := outboxPut(r.tableName, userID, event, occurredAt)
+		if err != nil {
+			return err
 		}
+		items = append(items, put)
+	}
+	if len(items) == 0 {
+		return nil
 	}
 
+	log.Debug("publishing user events in dynamodb", slog.String("user_id", userID.String()), slog.Int("events", len(events)))
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: items, ClientRequestToken: aws.String(uuid.NewString())}
+	err := retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish user events to dynamodb: %v", err)
+	}
 	return nil
 }
 
-// FindByEmail finds a user by their email using a Global Secondary Index (GSI)
+// userWriteIDAttr names the attribute holding the ID of the last write of a user profile
+const userWriteIDAttr = "WriteID"
+
+// writtenBy reports whether the stored item was last written with writeID
+func writtenBy(item map[string]types.AttributeValue, writeID string) bool {
+	attr, ok := item[userWriteIDAttr].(*types.AttributeValueMemberS)
+	return ok && attr.Value == writeID
+}
+
+// FindByEmail finds a user through the item reserving their email, which names a single user
 func (r *DynamoDBUserRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
 	log := ctxlogger.GetLogger(ctx)
 
-	// define the query input
-	input := &dynamodb.QueryInput{
-		TableName:              &r.tableName,
-		IndexName:              aws.String("EmailIndex"),
-		KeyConditionExpression: aws.String("Email = :email"),
-		ExpressionAttributeNames: map[string]string{
-			"#email": "Email",
-		},
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":email": &types.AttributeValueMemberS{Value: email},
-		},
+	input := &dynamodb.GetItemInput{
+		TableName: &r.tableName,
+		Key:       itemKey(emailPK(email), emailSK),
 	}
 
 	log.Debug("finding user by email in dynamodb", slog.String("email", email))
-	output, err := r.client.Query(ctx, input)
+	output, err := r.client.GetItem(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query user by email from dynamodb: %v", err)
+		return nil, fmt.Errorf("failed to get user email from dynamodb: %v", err)
 	}
 
-	if len(output.Items) == 0 {
+	if output.Item == nil {
 		return nil, ErrUserNotFound
 	}
 
-	// !!! critical !!!
-	if len(output.Items) > 1 {
-		log.Warn("found multiple users with the same email", slog.String("email", email))
-	}
-
-	var user User
-	// unmarshal the first found item back into our Go struct
-	if err := attributevalue.UnmarshalMap(output.Items[0], &user); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal user from dynamodb: %v", err)
+	var item emailItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user email from dynamodb: %v", err)
 	}
 
-	return &user, nil
+	return r.FindByID(ctx, item.UserID)
 }
 
 func (r *DynamoDBUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*User, error) {
@@ -137,9 +288,7 @@ func (r *DynamoDBUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*U
 
 	input := &dynamodb.GetItemInput{
 		TableName: &r.tableName,
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id.String()},
-		},
+		Key:       itemKey(userPK(id), userProfileSK),
 	}
 
 	log.Debug("finding user by id in dynamodb", slog.String("user_id", id.String()))
@@ -149,13 +298,98 @@ func (r *DynamoDBUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*U
 	}
 
 	if output.Item == nil {
-		return nil, ErrUserNotFound
+		return nil, ErrUserNotFound.With("user_id", id)
 	}
 
-	var user User
-	if err := attributevalue.UnmarshalMap(output.Item, &user); err != nil {
+	var item userItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal user from dynamodb: %v", err)
 	}
 
-	return &user, nil
+	return &item.User, nil
+}
+
+// maxBatchGetKeys is the most keys DynamoDB reads in a single BatchGetItem
+const maxBatchGetKeys = 100
+
+// FindByIDs reads the users in batches, the unknown ones being left out and the order of ids not being kept
+func (r *DynamoDBUserRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*User, error) {
+	log := ctxlogger.GetLogger(ctx)
+
+	users := make([]*User, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	keys := make([]map[string]types.AttributeValue, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, itemKey(userPK(id), userProfileSK))
+		}
+	}
+
+	log.Debug("finding users by ids in dynamodb", slog.Int("user_count", len(keys)))
+	for i := 0; i < len(keys); i += maxBatchGetKeys {
+		pending := keys[i:min(i+maxBatchGetKeys, len(keys))]
+
+		// Unprocessed keys are read again with a backoff, as DynamoDB asks
+		err := retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+			output, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					r.tableName: {Keys: pending},
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, av := range output.Responses[r.tableName] {
+				var item userItem
+				if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+					return fmt.Errorf("failed to unmarshal user from dynamodb: %w", err)
+				}
+				users = append(users, &item.User)
+			}
+			if pending = output.UnprocessedKeys[r.tableName].Keys; len(pending) > 0 {
+				return errUnprocessedItems
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users by ids from dynamodb: %v", err)
+		}
+	}
+
+	return users, nil
+}
+
+// Delete removes a user and releases their email, deleting an unknown user succeeds
+// The email of a user never changes, so the item reserving it is the one written along their profile
+func (r *DynamoDBUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	log := ctxlogger.GetLogger(ctx)
+
+	user, err := r.FindByID(ctx, id)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Delete: &types.Delete{TableName: &r.tableName, Key: itemKey(userPK(id), userProfileSK)}},
+			{Delete: &types.Delete{TableName: &r.tableName, Key: itemKey(emailPK(user.Email), emailSK)}},
+		},
+		ClientRequestToken: aws.String(uuid.NewString()),
+	}
+
+	log.Debug("deleting user in dynamodb", slog.String("user_id", id.String()))
+	err = retry.Do(ctx, dynamoRetryPolicy, func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user from dynamodb: %v", err)
+	}
+
+	return nil
 }
@@ -4,38 +4,67 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/contracts"
 	"github.com/google/uuid"
 )
 
-type EventPublisher interface {
-	Publish(ctx context.Context, topic string, eventData []byte) error
-}
+// Audit vocabulary of the identity security events
+const (
+	auditUserRegistered    = "user.registered"
+	auditLoginSucceeded    = "auth.login_succeeded"
+	auditLoginFailed       = "auth.login_failed"
+	auditLoggedOut         = "auth.logged_out"
+	auditScopedTokenIssued = "auth.scoped_token_issued"
+	auditImpersonated      = "auth.impersonation_started"
+	auditSupportActor      = "support:"
+	auditVerificationSent  = "user.email_verification_requested"
+	auditEmailVerified     = "user.email_verified"
+	auditUserDeactivated   = "user.deactivated"
+	auditUserReactivated   = "user.reactivated"
+	auditUserDeleted       = "user.deleted"
+	auditResourceUser      = "user"
+	auditAnonymousActor    = "anonymous"
+	auditReasonUnknownUser = "unknown_user"
+	auditReasonBadPassword = "invalid_password"
+	auditReasonDeactivated = "deactivated_user"
+)
 
 type Service struct {
 	repo         UserRepository
 	tokenManager TokenManager
 	passManager  *PasswordManager
-	publisher    EventPublisher
+	auditor      *audit.Logger
+	metrics      *Metrics
+	emails       EmailPolicy
 }
 
 func NewService(
 	r UserRepository,
 	tm TokenManager,
 	pm *PasswordManager,
-	p EventPublisher,
+	a *audit.Logger,
+	m *Metrics,
+	ep EmailPolicy,
 ) *Service {
 	return &Service{
 		repo:         r,
 		tokenManager: tm,
 		passManager:  pm,
-		publisher:    p,
+		auditor:      a,
+		metrics:      m,
+		emails:       ep,
 	}
 }
 
+// Register creates a user with the normalized email, the repository enforcing its uniqueness when the check below
+// races with another registration
 func (s *Service) Register(ctx context.Context, name, email, password string) (*User, error) {
-	if _, err := s.repo.FindByEmail(ctx, email); !errors.Is(err, ErrUserNotFound) {
+	if _, err := s.findByEmail(ctx, email); !errors.Is(err, ErrUserNotFound) {
 		if err == nil {
 			return nil, ErrEmailAlreadyInUse
 		}
@@ -50,33 +79,90 @@ func (s *Service) Register(ctx context.Context, name, email, password string) (*
 	user := &User{
 		ID:           uuid.New(),
 		Name:         name,
-		Email:        email,
+		Email:        s.emails.Normalize(email),
 		PasswordHash: passwordHash,
 		CreatedAt:    time.Now().UTC(),
 		UpdatedAt:    time.Now().UTC(),
 	}
 
-	if err := s.repo.Save(ctx, user); err != nil {
+	// The ledger learns about the user from the event, relayed from the outbox to the broker
+	registered := contracts.UserRegisteredV1{
+		UserID:       user.ID,
+		Name:         user.Name,
+		Email:        user.Email,
+		RegisteredAt: user.CreatedAt,
+	}
+	if err := s.repo.Create(ctx, user, registered); err != nil {
 		return nil, fmt.Errorf("save user in register: %v", err)
 	}
 
-	// TODO -> Publish event in kafka
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditUserRegistered,
+		ResourceType: auditResourceUser,
+		ResourceID:   user.ID.String(),
+		Actor:        user.ID.String(),
+		After:        map[string]string{"name": user.Name, "email": user.Email},
+	})
+	s.metrics.registrations.Inc()
 
 	return user, nil
 }
 
+// Login fails alike for an unknown email and a wrong password, with the same error and after verifying a password
+// either way, so neither the answer nor its timing tells which emails are registered
 func (s *Service) Login(ctx context.Context, email, password string) (*TokenPair, error) {
-	user, err := s.repo.FindByEmail(ctx, email)
+	user, err := s.findByEmail(ctx, email)
 	if err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", ErrUserNotFound)
+		s.passManager.VerifyDummy(password)
+		s.recordLoginFailure(ctx, "", auditReasonUnknownUser)
+		return nil, fmt.Errorf("authentication failed: %w", ErrInvalidCredentials)
 	}
 
 	match, err := s.passManager.Verify(password, user.PasswordHash)
 	if err != nil || !match {
-		return nil, fmt.Errorf("authentication failed")
+		s.recordLoginFailure(ctx, user.ID.String(), auditReasonBadPassword)
+		return nil, fmt.Errorf("authentication failed: %w", ErrInvalidCredentials)
+	}
+	if user.DeactivatedAt != nil {
+		s.recordLoginFailure(ctx, user.ID.String(), auditReasonDeactivated)
+		return nil, fmt.Errorf("authentication failed: %w", ErrUserDeactivated)
+	}
+
+	return s.StartSession(ctx, user)
+}
+
+// findByEmail finds the user of the normalized email. When the aliases are folded, an email not found is looked up
+// again only trimmed and lowercased: the users who signed up with a "+tag" before the folding was turned on keep it
+// until FoldEmailAliases re-keys them
+func (s *Service) findByEmail(ctx context.Context, email string) (*User, error) {
+	normalized := s.emails.Normalize(email)
+	user, err := s.repo.FindByEmail(ctx, normalized)
+	if !errors.Is(err, ErrUserNotFound) {
+		return user, err
+	}
+	if exact := (EmailPolicy{}).Normalize(email); exact != normalized {
+		return s.repo.FindByEmail(ctx, exact)
+	}
+	return nil, err
+}
+
+// StartSession logs the user in without their password, for the callers that just authenticated them otherwise
+// (e.g., a registration)
+func (s *Service) StartSession(ctx context.Context, user *User) (*TokenPair, error) {
+	pair, err := s.tokenManager.NewPairForUser(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.tokenManager.NewPairForUser(ctx, user.ID)
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditLoginSucceeded,
+		ResourceType: auditResourceUser,
+		ResourceID:   user.ID.String(),
+		Actor:        user.ID.String(),
+	})
+	s.metrics.loginsSucceeded.Inc()
+
+	return pair, nil
 }
 
 func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
@@ -88,5 +174,287 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Token
 }
 
 func (s *Service) Logout(ctx context.Context, userID uuid.UUID) error {
-	return s.tokenManager.RevokeAllForUser(ctx, userID)
+	if err := s.tokenManager.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditLoggedOut,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Actor:        userID.String(),
+	})
+
+	return nil
+}
+
+// IssueScopedToken issues a token of the scope for the caller, the scope being narrower than everything and than the
+// scope of the token of the caller. A ttl of 0 picks DefaultScopedTokenTTL
+func (s *Service) IssueScopedToken(ctx context.Context, caller authctx.UserContext, scope authctx.Scope, ttl time.Duration) (string, time.Time, error) {
+	if caller.ImpersonatedBy != "" {
+		return "", time.Time{}, ErrImpersonating
+	}
+	if !scope.Restricted() {
+		return "", time.Time{}, ErrScopeNotNarrowed
+	}
+	if !scope.Within(caller.Scope) {
+		return "", time.Time{}, ErrScopeTooBroad
+	}
+	if ttl == 0 {
+		ttl = DefaultScopedTokenTTL
+	}
+	if ttl < 0 || ttl > MaxScopedTokenTTL {
+		return "", time.Time{}, ErrInvalidTokenTTL
+	}
+
+	user, err := s.repo.FindByID(ctx, caller.UserID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get user to issue scoped token: %w", err)
+	}
+	if user.DeactivatedAt != nil {
+		return "", time.Time{}, ErrUserDeactivated
+	}
+
+	token, expiresAt, err := s.tokenManager.IssueScopedToken(user, scope, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditScopedTokenIssued,
+		ResourceType: auditResourceUser,
+		ResourceID:   user.ID.String(),
+		Actor:        user.ID.String(),
+		Metadata: map[string]string{
+			"read_only":   strconv.FormatBool(scope.ReadOnly),
+			"account_ids": strconv.Itoa(len(scope.AccountIDs)),
+			"expires_at":  expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+
+	return token, expiresAt, nil
+}
+
+// IssueImpersonationToken hands operator, a support member, a token acting as the user for the reason, recorded in
+// the audit trail. A ttl of 0 picks DefaultImpersonationTTL
+func (s *Service) IssueImpersonationToken(ctx context.Context, userID uuid.UUID, operator, reason string, ttl time.Duration) (string, time.Time, error) {
+	if ttl == 0 {
+		ttl = DefaultImpersonationTTL
+	}
+	if ttl < 0 || ttl > MaxImpersonationTTL {
+		return "", time.Time{}, ErrInvalidImpersonationTTL
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get user to impersonate: %w", err)
+	}
+	if user.DeactivatedAt != nil {
+		return "", time.Time{}, ErrUserDeactivated
+	}
+
+	token, expiresAt, err := s.tokenManager.IssueImpersonationToken(user, operator, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditImpersonated,
+		ResourceType: auditResourceUser,
+		ResourceID:   user.ID.String(),
+		Actor:        auditSupportActor + operator,
+		Metadata: map[string]string{
+			"reason":     reason,
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+
+	return token, expiresAt, nil
+}
+
+// RequestEmailVerification publishes the link confirming the caller owns their email, for the mailer to send it. It
+// does nothing once the email is verified
+func (s *Service) RequestEmailVerification(ctx context.Context, caller authctx.UserContext) error {
+	if caller.ImpersonatedBy != "" {
+		return ErrImpersonating
+	}
+
+	user, err := s.repo.FindByID(ctx, caller.UserID)
+	if err != nil {
+		return fmt.Errorf("get user to verify email: %w", err)
+	}
+	if user.DeactivatedAt != nil {
+		return ErrUserDeactivated
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	token, expiresAt, err := s.tokenManager.IssueEmailVerificationToken(user, EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	requested := contracts.EmailVerificationRequestedV1{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.Publish(ctx, user.ID, requested); err != nil {
+		return fmt.Errorf("publish email verification: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditVerificationSent,
+		ResourceType: auditResourceUser,
+		ResourceID:   user.ID.String(),
+		Actor:        user.ID.String(),
+		Metadata:     map[string]string{"expires_at": expiresAt.UTC().Format(time.RFC3339)},
+	})
+	return nil
+}
+
+// VerifyEmail marks the email of the user the verification token was issued to as verified, the token being void
+// once the user changed their email. The access tokens issued before claim the email unverified until refreshed
+func (s *Service) VerifyEmail(ctx context.Context, verificationToken string) error {
+	claims, err := s.tokenManager.ValidateEmailVerificationToken(verificationToken)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return fmt.Errorf("get user to verify email: %w", err)
+	}
+	if user.Email != claims.Email {
+		return ErrInvalidVerificationToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	if err := s.repo.Save(ctx, user); err != nil {
+		return fmt.Errorf("save verified user: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditEmailVerified,
+		ResourceType: auditResourceUser,
+		ResourceID:   user.ID.String(),
+		Actor:        user.ID.String(),
+		After:        map[string]string{"email": user.Email},
+	})
+	return nil
+}
+
+func (s *Service) ValidateAccessToken(ctx context.Context, accessToken string) (*AccessTokenClaims, error) {
+	return s.tokenManager.ValidateAccessToken(accessToken)
+}
+
+func (s *Service) GetUser(ctx context.Context, userID uuid.UUID) (*User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+	return user, nil
+}
+
+// GetUsers returns the users of the IDs, the unknown ones being left out
+func (s *Service) GetUsers(ctx context.Context, userIDs []uuid.UUID) ([]*User, error) {
+	users, err := s.repo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get users by ids: %w", err)
+	}
+	return users, nil
+}
+
+// DeactivateUser blocks the logins of a user and revokes their sessions while their account is being deleted
+func (s *Service) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user to deactivate: %w", err)
+	}
+	if user.DeactivatedAt == nil {
+		now := time.Now().UTC()
+		user.DeactivatedAt = &now
+		user.UpdatedAt = now
+		if err := s.repo.Save(ctx, user); err != nil {
+			return fmt.Errorf("save deactivated user: %w", err)
+		}
+	}
+
+	// Revoke even when already deactivated, a previous attempt may have failed after saving
+	if err := s.tokenManager.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditUserDeactivated,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Actor:        userID.String(),
+	})
+	return nil
+}
+
+// ReactivateUser unblocks the logins of a deactivated user
+func (s *Service) ReactivateUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user to reactivate: %w", err)
+	}
+	if user.DeactivatedAt == nil {
+		return nil
+	}
+
+	user.DeactivatedAt = nil
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Save(ctx, user); err != nil {
+		return fmt.Errorf("save reactivated user: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditUserReactivated,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Actor:        userID.String(),
+	})
+	return nil
+}
+
+// DeleteUser removes a user and revokes their sessions, deleting an unknown user succeeds so retries are safe
+func (s *Service) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	if err := s.tokenManager.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditUserDeleted,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Actor:        userID.String(),
+	})
+	return nil
+}
+
+// recordLoginFailure audits and counts a failed login, the user ID is empty when the email is unknown
+func (s *Service) recordLoginFailure(ctx context.Context, userID, reason string) {
+	s.metrics.loginsFailed.Inc(reason)
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditLoginFailed,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID,
+		Actor:        auditAnonymousActor,
+		Metadata:     map[string]string{"reason": reason},
+	})
 }
@@ -0,0 +1,59 @@
+package identity
+
+import (
+	identityv1 "github.com/Guizzs26/fintrack/gen/go/identity/v1"
+	"github.com/Guizzs26/fintrack/pkg/grpcx"
+)
+
+const (
+	MaxUsersByIDs          = 500 // MaxUsersByIDs is the most users resolved by a single GetUsersByIDs call
+	MaxScopedTokenAccounts = 50  // MaxScopedTokenAccounts is the most accounts a scoped token can be restricted to
+)
+
+// RequestValidators are the rules of the identity requests, checked by the validation interceptor before the
+// handlers run, so the handlers only see well formed requests
+func RequestValidators() map[string]grpcx.RequestValidator {
+	return map[string]grpcx.RequestValidator{
+		identityv1.IdentityService_Register_FullMethodName: grpcx.Fields(
+			grpcx.String("name", (*identityv1.RegisterRequest).GetName, grpcx.NotBlank),
+			grpcx.String("email", (*identityv1.RegisterRequest).GetEmail, grpcx.NotBlank, grpcx.Email),
+			grpcx.String("password", (*identityv1.RegisterRequest).GetPassword, grpcx.Required),
+		),
+		identityv1.IdentityService_Login_FullMethodName: grpcx.Fields(
+			grpcx.String("email", (*identityv1.LoginRequest).GetEmail, grpcx.NotBlank),
+			grpcx.String("password", (*identityv1.LoginRequest).GetPassword, grpcx.Required),
+		),
+		identityv1.IdentityService_RefreshToken_FullMethodName: grpcx.Fields(
+			grpcx.String("refresh_token", (*identityv1.RefreshTokenRequest).GetRefreshToken, grpcx.Required),
+		),
+		identityv1.IdentityService_ValidateToken_FullMethodName: grpcx.Fields(
+			grpcx.String("access_token", (*identityv1.ValidateTokenRequest).GetAccessToken, grpcx.Required),
+		),
+		identityv1.IdentityService_IssueScopedToken_FullMethodName: grpcx.Fields(
+			grpcx.Strings("account_ids", (*identityv1.IssueScopedTokenRequest).GetAccountIds, MaxScopedTokenAccounts, grpcx.UUID),
+		),
+		identityv1.IdentityService_IssueImpersonationToken_FullMethodName: grpcx.Fields(
+			grpcx.String("user_id", (*identityv1.IssueImpersonationTokenRequest).GetUserId, grpcx.UUID),
+			grpcx.String("operator", (*identityv1.IssueImpersonationTokenRequest).GetOperator, grpcx.NotBlank),
+			grpcx.String("reason", (*identityv1.IssueImpersonationTokenRequest).GetReason, grpcx.NotBlank),
+		),
+		identityv1.IdentityService_VerifyEmail_FullMethodName: grpcx.Fields(
+			grpcx.String("token", (*identityv1.VerifyEmailRequest).GetToken, grpcx.Required),
+		),
+		identityv1.IdentityService_GetUser_FullMethodName: grpcx.Fields(
+			grpcx.String("user_id", (*identityv1.GetUserRequest).GetUserId, grpcx.UUID),
+		),
+		identityv1.IdentityService_GetUsersByIDs_FullMethodName: grpcx.Fields(
+			grpcx.Strings("user_ids", (*identityv1.GetUsersByIDsRequest).GetUserIds, MaxUsersByIDs, grpcx.UUID),
+		),
+		identityv1.IdentityService_DeactivateUser_FullMethodName: grpcx.Fields(
+			grpcx.String("user_id", (*identityv1.DeactivateUserRequest).GetUserId, grpcx.UUID),
+		),
+		identityv1.IdentityService_ReactivateUser_FullMethodName: grpcx.Fields(
+			grpcx.String("user_id", (*identityv1.ReactivateUserRequest).GetUserId, grpcx.UUID),
+		),
+		identityv1.IdentityService_DeleteUser_FullMethodName: grpcx.Fields(
+			grpcx.String("user_id", (*identityv1.DeleteUserRequest).GetUserId, grpcx.UUID),
+		),
+	}
+}
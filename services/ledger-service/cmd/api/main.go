@@ -2,31 +2,96 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/authctx"
 	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errreport"
 	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/ipfilter"
 	"github.com/Guizzs26/fintrack/pkg/logger"
 	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/ratelimit"
+	"github.com/Guizzs26/fintrack/pkg/requestid"
 	"github.com/Guizzs26/fintrack/pkg/validatorx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/db"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/accountdeletion"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/attachments"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/backup"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/bankconnect"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/budgets"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/business"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/categorization"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/comments"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/demo"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/forecast"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/households"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/imports"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/insights"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/interest"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/investments"
 	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/networth"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/notifications"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/offlinesync"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/payees"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/admin"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/auditlog"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/authz"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/boot"
 	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/fieldcrypt"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
 	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
-	"github.com/google/uuid"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/saga"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/scheduler"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/translations"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/preferences"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/recategorization"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/reports"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/retention"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/settings"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/statements"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/summaries"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/transfers"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/views"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/warehouse"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// apiLatestVersion is the version the request bodies are shaped after, the requests whose shape changed decoding the
+// bodies of the older versions (see httpx.VersionedRequest)
+const apiLatestVersion = 2
+
+// fullHistoryCost weighs the routes reading the whole history of a user (e.g., the backup export) as 10 years of it
+const fullHistoryCost = 120
+
 func main() {
 	ctx := context.Background()
 
-	cfg, err := config.Load()
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load config to api: %s\n", err)
 		os.Exit(1)
@@ -42,59 +107,611 @@ func run(ctx context.Context, cfg *config.Config) error {
 	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	logLevels := logger.NewLevels()
 	logCfg := logger.SlogConfig{
-		Level:     logger.LevelDebug,
-		Format:    logger.FormatJSON,
-		AddSource: true,
+		Level:           logger.Level(cfg.Observability.Log.Level),
+		Format:          logger.FormatJSON,
+		AddSource:       true,
+		SamplePerSecond: cfg.Observability.Log.SamplePerSecond,
+		Levels:          logLevels,
+	}
+	if !cfg.Observability.Log.Stdout {
+		logCfg.Writer = io.Discard
+	}
+	// Bare-metal deployments without a log shipper keep the logs in rotated files
+	if cfg.Observability.Log.File != "" {
+		logFile := logger.NewRotatingFile(logger.RotationConfig{
+			Path:       cfg.Observability.Log.File,
+			MaxSize:    cfg.Observability.Log.FileMaxSizeMB << 20,
+			MaxAge:     cfg.Observability.Log.FileMaxAge,
+			MaxBackups: cfg.Observability.Log.FileMaxBackups,
+		}, clock.SystemClock{})
+		defer logFile.Close()
+		logCfg.File = logFile
 	}
 	baseLogger := logger.NewSlogConfig(logCfg)
 	slog.SetDefault(baseLogger)
+	go logger.HandleLevelSignals(ctx, logLevels, baseLogger)
+
+	systemClock := clock.SystemClock{}
+	idGenerator := idgen.V7Generator{}
+
+	var errorReporter errreport.ErrorReporter = errreport.Nop{}
+	if cfg.Observability.ErrorReporting.SentryDSN != "" {
+		sentryReporter, err := errreport.NewSentryReporter(
+			cfg.Observability.ErrorReporting.SentryDSN,
+			cfg.Observability.ErrorReporting.Environment,
+			cfg.Observability.ErrorReporting.Release,
+			cfg.Observability.ErrorReporting.Timeout,
+			baseLogger,
+			systemClock,
+		)
+		if err != nil {
+			return err
+		}
+		go sentryReporter.Run(ctx)
+		errorReporter = sentryReporter
+	}
+
+	versionCfg, err := buildVersionConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	metricsRegistry := metrics.NewRegistry()
 
 	e := echo.New()
 	e.HideBanner = true
-	e.Validator = validatorx.NewValidator()
-	e.HTTPErrorHandler = customerErrorHandler
+	e.Binder = httpx.NewVersionedBinder(versionCfg.Latest)
+	e.Validator = validatorx.NewValidator(systemClock)
+	e.HTTPErrorHandler = httpx.ErrorHandler(errorReporter)
 
-	e.Use(middleware.Recover())
 	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
-		Generator: func() string {
-			return uuid.NewString()
-		},
+		Generator: requestid.New,
 	}))
-	e.Use(middleware.BodyLimit("2MB"))
-	e.Use(ContextualLoggerMiddleware(baseLogger))
+	// The language is set early so every error response, even of a request rejected by the body limit, is translated
+	e.Use(httpx.LanguageMiddleware(httpx.LanguageConfig{Catalog: translations.Errors}))
+	e.Use(httpx.VersionMiddleware(versionCfg))
+	// Backup archives hold the whole history of a user, and attachments are files, so their routes set their own limits
+	e.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Skipper: func(c echo.Context) bool { return c.Path() == backup.RestorePath || c.Path() == attachments.UploadPath },
+		Limit:   "2MB",
+	}))
+	e.Use(ContextualLoggerMiddleware(logger.WithScope(baseLogger, "http")))
 	e.Use(RequestLoggerMiddleware())
+	// Recovery runs inside the logging middlewares, so a recovered panic is still logged with its request ID
+	e.Use(RecoverMiddleware(errorReporter))
+
+	// With Redis the limit holds across the instances behind the load balancer, otherwise each instance counts on its own
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter(systemClock)
+	var redisLimiter *ratelimit.RedisLimiter
+	if cfg.Redis.Addr != "" {
+		redisLimiter = ratelimit.NewRedisLimiter(ratelimit.RedisConfig{
+			Addr:      cfg.Redis.Addr,
+			Password:  cfg.Redis.Password,
+			DB:        cfg.Redis.DB,
+			KeyPrefix: "ledger:ratelimit:",
+		})
+		defer redisLimiter.Close()
+		limiter = redisLimiter
+	}
+	// Metrics are scraped and webhooks are sent from a few addresses, which would exhaust a per-IP limit
+	e.Use(httpx.RateLimitMiddleware(httpx.RateLimitConfig{
+		Limiter: limiter,
+		Limit:   ratelimit.Limit{Requests: cfg.RateLimit.Requests, Window: cfg.RateLimit.Window},
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/metrics" || strings.HasPrefix(c.Path(), "/api/v1/webhooks/")
+		},
+	}))
+	e.Use(httpx.PaginationMiddleware(httpx.PaginationConfig{
+		DefaultPageSize: cfg.Pagination.DefaultPageSize,
+		MaxPageSize:     cfg.Pagination.MaxPageSize,
+		MaxExportRows:   cfg.Pagination.MaxExportRows,
+	}))
+	e.Use(httpx.BodyLoggerMiddleware(httpx.BodyLoggerConfig{
+		Mode:     httpx.BodyLogMode(cfg.Observability.Log.Bodies),
+		MaxBytes: cfg.Observability.Log.BodiesMaxBytes,
+	}))
+	e.Use(httpx.DeprecationUsageMiddleware(metricsRegistry))
+
+	identityClient, err := identityclient.NewClient(*cfg)
+	if err != nil {
+		return err
+	}
+	defer identityClient.Close()
+
+	// Every dependency is checked before the pools are opened and the port is bound, a failing instance reporting all
+	// its problems at once instead of the first one
+	if err := buildStartupChecker(cfg, identityClient, redisLimiter).Run(ctx); err != nil {
+		return err
+	}
 
-	pgConn, err := postgres.NewPostgresConnection(ctx, *cfg)
+	pgConn, err := postgres.NewPostgresConnection(ctx, *cfg, nil)
 	if err != nil {
 		return err
 	}
 	defer pgConn.Close()
+	// Listings, reports and exports go to the read replica, when configured, while it keeps up with the primary
+	go pgConn.MonitorReplica(ctx)
+	go pgConn.MonitorPools(ctx, metricsRegistry, cfg.Postgres.StatsInterval)
+
+	jobService := jobs.NewService(jobs.NewPostgresJobRepository(pgConn.Pool), metricsRegistry, idGenerator, systemClock)
+	if err := jobService.FailInterrupted(ctx); err != nil {
+		return err
+	}
+	sched := scheduler.New(pgConn.Pool, logger.WithScope(baseLogger, "scheduler"), *cfg, systemClock)
+	auditLogger := audit.NewLogger(auditlog.NewPostgresSink(pgConn.Pool), systemClock)
+	sagaCoordinator := saga.NewCoordinator(saga.NewPostgresRepository(pgConn.Pool), logger.WithScope(baseLogger, "saga"), systemClock)
+	fieldCipher, err := fieldcrypt.NewLocalCipher(cfg.Ledger.FieldEncryption.MasterKey, fieldcrypt.NewPostgresKeyStore(pgConn.Pool))
+	if err != nil {
+		return err
+	}
+	rounding, err := money.ParseRoundingPolicy(cfg.Ledger.Money.RoundingMode)
+	if err != nil {
+		return fmt.Errorf("failed to load rounding policy: %w", err)
+	}
+
+	// ----- Shared dependencies handed to every module ----- //
+
+	deps := module.Deps{
+		Config:   cfg,
+		Logger:   baseLogger,
+		Postgres: pgConn,
+		Identity: identityClient,
+		Jobs:     jobService,
+		Tasks:    sched,
+		Sagas:    sagaCoordinator,
+		Audit:    auditLogger,
+		Metrics:  metricsRegistry,
+		Periods:  module.CalendarPeriods{},
+		Display:  module.DefaultPreferences{},
+		Fields:   fieldCipher,
+		Rounding: rounding,
+		IDs:      idGenerator,
+		Clock:    systemClock,
+	}
+
+	// ----- Modules ----- //
+
+	// Notifications is built first so the modules that notify users receive its Notifier
+	notificationsModule := notifications.NewModule(deps)
+	deps.Notifier = notificationsModule.Notifier()
+	deps.Mailer = notificationsModule.Mailer()
+
+	// In demo mode the ledger data lives in memory and demo users are provisioned on demand
+	// Budgets, payee limits, bulk recategorizations, investment positions, backups, imports, categorization rules, reports,
+	// saved views, user settings and preferences, transaction comments, account transfers, households, account deletions, business profiles, offline sync, monthly statements, attachments, savings interest, bank connections and warehouse exports work straight on Postgres,
+	// so they are only available outside demo mode where every user keeps calendar months and the default preferences
+	var (
+		ledgerModule      *ledger.Module
+		bankConnectModule *bankconnect.Module
+		summariesModule   *summaries.Module
+		statementsModule  *statements.Module
+		attachmentsModule *attachments.Module
+		warehouseModule   *warehouse.Module
+		demoService       *demo.Service
+		investmentsValuer networth.InvestmentValuer
+		modules           = []module.Module{jobs.NewHandler(jobService), notificationsModule}
+	)
+	if cfg.Demo.Enabled {
+		demoAccounts := ledger.NewInMemoryAccountRepository()
+		ledgerModule = ledger.NewModuleWithRepository(deps, demoAccounts, nil)
+		demoService = demo.NewService(demoAccounts, idGenerator, systemClock, cfg.Demo.SessionTTL, cfg.Demo.SeedMonths, cfg.Demo.MaxSessions)
+		baseLogger.Warn("demo mode enabled, ledger data is kept in memory")
+	} else {
+		// Settings come first so the modules computing monthly figures follow the financial month of each user
+		settingsModule := settings.NewModule(deps)
+		deps.Periods = settingsModule.AccountingPeriods()
+		// Preferences follow so the reports and notifications show amounts and dates the way each user chose
+		preferencesModule := preferences.NewModule(deps, settingsModule.Service(), notificationsModule.Service())
+		deps.Display = preferencesModule.Display()
+
+		budgetsModule := budgets.NewModule(deps)
+		payeesModule := payees.NewModule(deps)
+		ledgerModule = ledger.NewModule(deps, households.NewPostgresRepository(deps.Postgres.Pool), budgetsModule.TransactionListener(), payeesModule.TransactionListener())
+
+		staticQuotes, err := investments.NewStaticQuoteProvider(cfg.Ledger.Investments.Quotes, systemClock)
+		if err != nil {
+			return fmt.Errorf("failed to load investment quotes: %w", err)
+		}
+		quotes := investments.NewCachedQuoteProvider(staticQuotes, cfg.Ledger.Investments.QuoteCacheTTL, systemClock)
+		investmentsModule := investments.NewModule(deps, ledgerModule.Service(), quotes)
+		investmentsValuer = investmentsModule.Service()
+		categorizationModule := categorization.NewModule(deps)
+		summariesModule = summaries.NewModule(deps, ledgerModule.Service(), budgetsModule.Service())
+		statementsModule = statements.NewModule(deps, ledgerModule.Service())
+
+		attachmentStorage, err := buildAttachmentStorage(cfg, systemClock)
+		if err != nil {
+			return err
+		}
+		attachmentsModule = attachments.NewModule(deps, attachmentStorage)
+
+		warehouseSink, err := buildWarehouseSink(cfg, systemClock)
+		if err != nil {
+			return err
+		}
+		if warehouseSink != nil {
+			warehouseModule = warehouse.NewModule(deps, warehouseSink)
+		}
+
+		cdi, err := interest.ParseRate(cfg.Ledger.Interest.CDIAnnualRate)
+		if err != nil {
+			return fmt.Errorf("failed to load CDI rate: %w", err)
+		}
+
+		accountDeletionModule := accountdeletion.NewModule(deps)
+		if err := sagaCoordinator.Register(accountDeletionModule.Saga()); err != nil {
+			return err
+		}
+
+		modules = append(modules,
+			settingsModule,
+			preferencesModule,
+			budgetsModule,
+			payeesModule,
+			recategorization.NewModule(deps),
+			investmentsModule,
+			backup.NewModule(deps),
+			imports.NewModule(deps, ledgerModule.Service()),
+			categorizationModule,
+			reports.NewModule(deps),
+			views.NewModule(deps),
+			comments.NewModule(deps),
+			transfers.NewModule(deps),
+			households.NewModule(deps, ledgerModule.Service(), budgetsModule.Service()),
+			accountDeletionModule,
+			business.NewModule(deps),
+			offlinesync.NewModule(deps, ledgerModule.Service()),
+			statementsModule,
+			attachmentsModule,
+			interest.NewModule(deps, ledgerModule.Service(), cdi),
+		)
 
-	clock := clock.SystemClock{}
+		if bankConnectors := buildBankConnectors(cfg, systemClock); len(bankConnectors) > 0 {
+			secrets, err := bankconnect.NewSecretBox(cfg.BankConnect.SecretKey)
+			if err != nil {
+				return err
+			}
+			if secrets == nil && cfg.BankConnect.PlaidClientID != "" {
+				return errors.New("BANK_CONNECT_SECRET_KEY is required to store the Plaid access tokens")
+			}
+			bankConnectModule = bankconnect.NewModule(deps, ledgerModule.Service(), categorizationModule.Service(), secrets, bankConnectors...)
+			modules = append(modules, bankConnectModule)
+		}
+	}
+	netWorthModule := networth.NewModule(deps, ledgerModule.Service(), investmentsValuer)
+	modules = append(modules,
+		ledgerModule,
+		forecast.NewModule(deps, ledgerModule.Service()),
+		insights.NewModule(deps, ledgerModule.Service(), insights.DefaultAnalyzers()...),
+		netWorthModule,
+	)
+
+	// ----- Scheduled tasks ----- //
+
+	err = sched.Register(scheduler.Task{
+		Name:     "jobs_purge",
+		Schedule: cfg.Jobs.Scheduler.JobsPurgeCron,
+		Run: func(ctx context.Context) error {
+			return jobService.PurgeCompleted(ctx, cfg.Jobs.Scheduler.JobsRetention)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	retentionService := retention.NewService(retention.NewPostgresRepository(pgConn.Pool), retention.PolicyFromConfig(cfg), auditLogger, systemClock)
+	err = sched.Register(scheduler.Task{
+		Name:     "retention",
+		Schedule: cfg.Jobs.Scheduler.RetentionCron,
+		Run: func(ctx context.Context) error {
+			_, err := retentionService.Run(ctx, cfg.Jobs.Retention.DryRun)
+			return err
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = sched.Register(scheduler.Task{
+		Name:     "sagas_resume",
+		Schedule: cfg.Jobs.Scheduler.SagasResumeCron,
+		Run:      sagaCoordinator.Resume,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = sched.Register(scheduler.Task{
+		Name:     "account_purge",
+		Schedule: cfg.Jobs.Scheduler.AccountPurgeCron,
+		Run:      ledgerModule.Service().PurgeDeletedAccounts,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Snapshots cover the users stored in Postgres, demo users living in memory are never snapshotted
+	err = sched.Register(scheduler.Task{
+		Name:     "net_worth_snapshots",
+		Schedule: cfg.Jobs.Scheduler.NetWorthSnapshotCron,
+		Run:      netWorthModule.Service().TakeSnapshots,
+	})
+	if err != nil {
+		return err
+	}
 
-	// ----- Ledger module dependencies ----- //
+	// Summaries read the budgets and opt-outs stored in Postgres, demo users never receive them
+	if summariesModule != nil {
+		err = sched.Register(scheduler.Task{
+			Name:     "weekly_summaries",
+			Schedule: cfg.Jobs.Scheduler.WeeklySummaryCron,
+			Run:      summariesModule.Service().SendWeeklySummaries,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-	accountRepo := ledger.NewPostgresAccountRepository(pgConn.Pool)
-	ledgerSvc := ledger.NewLedgerService(accountRepo, clock)
-	ledgerHandler := ledger.NewLedgerHandler(ledgerSvc, clock)
+	if statementsModule != nil {
+		err = sched.Register(scheduler.Task{
+			Name:     "monthly_statements",
+			Schedule: cfg.Jobs.Scheduler.MonthlyStatementCron,
+			Run:      statementsModule.Service().SendMonthlyStatements,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-	apiRouteGroup := e.Group("/api/v1")
-	ledgerHandler.RegisterRoutes(apiRouteGroup)
+	if attachmentsModule != nil {
+		err = sched.Register(scheduler.Task{
+			Name:     "attachments_gc",
+			Schedule: cfg.Jobs.Scheduler.AttachmentsGCCron,
+			Run:      attachmentsModule.Service().CollectGarbage,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-	e.Logger.Fatal(e.Start(":9999"))
-	return nil
+	if warehouseModule != nil {
+		err = sched.Register(scheduler.Task{
+			Name:     "warehouse_export",
+			Schedule: cfg.Jobs.Scheduler.WarehouseExportCron,
+			Run:      warehouseModule.Service().Export,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if bankConnectModule != nil {
+		err = sched.Register(scheduler.Task{
+			Name:     "bank_sync",
+			Schedule: cfg.Jobs.Scheduler.BankSyncCron,
+			Run:      bankConnectModule.Service().SyncAll,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Jobs.Scheduler.Enabled {
+		go sched.Run(ctx)
+	}
+
+	if demoService != nil {
+		go demoService.Run(ctx)
+		demo.NewDemoHandler(demoService).RegisterRoutes(e.Group("/api/v1/public"))
+	}
+
+	// The local attachment storage serves its signed download URLs itself, a bucket serving them directly
+	if attachmentsModule != nil {
+		attachmentsModule.RegisterPublicRoutes(e.Group("/api/v1/public"))
+	}
+
+	// Metrics are scraped from the internal network, so the endpoint is not authenticated
+	e.GET("/metrics", echo.WrapHandler(metricsRegistry.Handler()))
+
+	// Bank providers authenticate their webhooks with signatures, not with the user tokens of the API
+	if bankConnectModule != nil {
+		bankConnectModule.RegisterWebhookRoutes(e.Group("/api/v1/webhooks"))
+	}
+
+	// The echo endpoint lets machine-to-machine callers, and our own webhook deliveries, check their signatures
+	signingSecrets := append(slices.Clone(cfg.Signing.Secrets), cfg.Notifications.WebhookSigningSecret)
+	if slices.ContainsFunc(signingSecrets, func(s string) bool { return s != "" }) {
+		e.POST("/api/v1/webhooks/echo", signedEchoHandler, httpx.SignatureMiddleware(httpx.SignatureConfig{
+			Secrets:      signingSecrets,
+			ReplayWindow: cfg.Signing.ReplayWindow,
+			Clock:        systemClock,
+		}))
+	}
+
+	if cfg.Admin.Token != "" {
+		adminNetworks, err := ipfilter.ParseNetworks(cfg.Admin.AllowedCIDRs)
+		if err != nil {
+			return fmt.Errorf("failed to load admin networks: %w", err)
+		}
+		trustedProxies, err := ipfilter.ParseNetworks(cfg.Server.TrustedProxies)
+		if err != nil {
+			return fmt.Errorf("failed to load trusted proxies: %w", err)
+		}
+		adminRouteGroup := e.Group("/admin",
+			httpx.IPAllowlistMiddleware(httpx.IPAllowlistConfig{Allowed: adminNetworks, TrustedProxies: trustedProxies}),
+			admin.TokenMiddleware(cfg.Admin.Token),
+		)
+		admin.NewAdminHandler(logLevels).RegisterRoutes(adminRouteGroup)
+		admin.NewImpersonationHandler(identityClient, auditLogger, deps.Notifier, deps.Display).RegisterRoutes(adminRouteGroup)
+	}
+
+	apiRouteGroup := e.Group("/api/v1",
+		AuthMiddleware(identityClient, demoService),
+		PreferredLanguageMiddleware(deps.Display),
+		authz.RoutePolicies(cfg.Identity.RequireVerifiedEmail).Middleware(),
+	)
+	// The reports and exports share the limiter of the rate limit, each instance capping the ones running at once
+	var concurrency *ratelimit.ConcurrencyLimiter
+	if cfg.CostLimit.Concurrent > 0 {
+		concurrency = ratelimit.NewConcurrencyLimiter(cfg.CostLimit.Concurrent, cfg.CostLimit.MaxQueued)
+	}
+	apiRouteGroup.Use(httpx.CostLimitMiddleware(httpx.CostLimitConfig{
+		Cost:         requestCost(systemClock),
+		Concurrency:  concurrency,
+		QueueTimeout: cfg.CostLimit.QueueTimeout,
+		Limiter:      limiter,
+		Budget:       ratelimit.Limit{Requests: cfg.CostLimit.Budget, Window: cfg.CostLimit.Window},
+		KeyFunc:      userKey,
+	}))
+	for _, m := range modules {
+		m.RegisterRoutes(apiRouteGroup)
+		baseLogger.Info("module registered", slog.String("module", m.Name()))
+	}
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      e,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		baseLogger.Info("HTTP server listening", slog.String("addr", server.Addr))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	baseLogger.Info("Shutting down server gracefully...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := jobService.Wait(shutdownCtx); err != nil {
+		return err
+	}
+	return sagaCoordinator.Wait(shutdownCtx)
+}
+
+// buildBankConnectors creates the connector of every bank provider whose client ID is configured
+func buildBankConnectors(cfg *config.Config, clock clock.Clock) []bankconnect.Connector {
+	bc := cfg.BankConnect
+	var connectors []bankconnect.Connector
+	if bc.PluggyClientID != "" {
+		connectors = append(connectors, bankconnect.NewPluggyConnector(bankconnect.PluggyConfig{
+			BaseURL:       bc.PluggyBaseURL,
+			ClientID:      bc.PluggyClientID,
+			ClientSecret:  bc.PluggyClientSecret,
+			WebhookSecret: bc.PluggyWebhookSecret,
+			Timeout:       bc.Timeout,
+		}, clock))
+	}
+	if bc.PlaidClientID != "" {
+		connectors = append(connectors, bankconnect.NewPlaidConnector(bankconnect.PlaidConfig{
+			BaseURL:      bc.PlaidBaseURL,
+			ClientID:     bc.PlaidClientID,
+			Secret:       bc.PlaidSecret,
+			CountryCodes: bc.PlaidCountryCodes,
+			Language:     bc.PlaidLanguage,
+			WebhookURL:   bc.PlaidWebhookURL,
+			Timeout:      bc.Timeout,
+		}, clock))
+	}
+	return connectors
+}
+
+// buildStartupChecker checks the databases and their migrations, the identity service, Redis when configured and the
+// secrets of the enabled features
+func buildStartupChecker(cfg *config.Config, identityClient *identityclient.Client, redisLimiter *ratelimit.RedisLimiter) *boot.Checker {
+	checks := []boot.Check{
+		boot.PostgresCheck("postgres", func(ctx context.Context) (*pgxpool.Pool, error) {
+			return postgres.Probe(ctx, *cfg, cfg.Database.Host, cfg.Database.Port)
+		}, db.Migrations, "migrations"),
+		boot.PingCheck("identity", identityClient),
+		boot.SecretsCheck(cfg.RequiredSecrets()),
+	}
+	if cfg.DatabaseReplica.Host != "" {
+		checks = append(checks, boot.PostgresCheck("postgres replica", func(ctx context.Context) (*pgxpool.Pool, error) {
+			return postgres.Probe(ctx, *cfg, cfg.DatabaseReplica.Host, cfg.DatabaseReplica.Port)
+		}, nil, ""))
+	}
+	if redisLimiter != nil {
+		checks = append(checks, boot.PingCheck("redis", redisLimiter))
+	}
+	return boot.NewChecker(cfg.Server.StartupCheckTimeout, checks...)
+}
+
+// buildVersionConfig returns the API versions served, announcing the sunset of version 1 when configured
+func buildVersionConfig(cfg *config.Config) (httpx.VersionConfig, error) {
+	versionCfg := httpx.VersionConfig{
+		Latest:  apiLatestVersion,
+		Default: cfg.API.DefaultVersion,
+		Sunsets: make(map[int]time.Time),
+	}
+	if cfg.API.V1Sunset != "" {
+		sunset, err := time.Parse(time.DateOnly, cfg.API.V1Sunset)
+		if err != nil {
+			return httpx.VersionConfig{}, fmt.Errorf("invalid API_V1_SUNSET %q, expected YYYY-MM-DD: %w", cfg.API.V1Sunset, err)
+		}
+		versionCfg.Sunsets[1] = sunset
+	}
+	return versionCfg, nil
+}
+
+// buildAttachmentStorage creates the bucket storage of the attachments when configured, the local disk one otherwise
+func buildAttachmentStorage(cfg *config.Config, clock clock.Clock) (attachments.Storage, error) {
+	ac := cfg.Attachments
+	if ac.S3Bucket != "" {
+		return attachments.NewS3Storage(ac.S3Endpoint, ac.S3Region, ac.S3Bucket, ac.S3AccessKeyID, ac.S3SecretAccessKey, ac.S3Timeout, clock)
+	}
+
+	secret := []byte(ac.LocalSigningSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate attachments signing secret: %w", err)
+		}
+	}
+	return attachments.NewDiskStorage(ac.LocalDir, ac.PublicBaseURL, secret, clock)
+}
+
+// buildWarehouseSink creates the bucket sink of the warehouse exports when configured, the local directory one otherwise,
+// nil when neither is configured and the export is disabled
+func buildWarehouseSink(cfg *config.Config, clock clock.Clock) (warehouse.Sink, error) {
+	wc := cfg.Warehouse
+	switch {
+	case wc.S3Bucket != "":
+		return attachments.NewS3Storage(wc.S3Endpoint, wc.S3Region, wc.S3Bucket, wc.S3AccessKeyID, wc.S3SecretAccessKey, wc.S3Timeout, clock)
+	case wc.LocalDir != "":
+		return warehouse.NewDirSink(wc.LocalDir)
+	default:
+		return nil, nil
+	}
 }
 
 // ContextualLoggerMiddleware creates a request-scoped logger containing the request ID
 // and injects it into the standard `context.Context` for use in downstream handlers and services
+// The request ID itself is injected too, so outgoing gRPC calls forward it to other services
 func ContextualLoggerMiddleware(baseLogger *slog.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
 			requestLogger := baseLogger.With(slog.String("request_id", requestID))
 
-			ctx := c.Request().Context()
+			ctx := requestid.WithRequestID(c.Request().Context(), requestID)
 			ctxWithLogger := ctxlogger.SetLogger(ctx, requestLogger)
 			c.SetRequest(c.Request().WithContext(ctxWithLogger))
 
@@ -103,6 +720,195 @@ func ContextualLoggerMiddleware(baseLogger *slog.Logger) echo.MiddlewareFunc {
 	}
 }
 
+// RecoverMiddleware recovers from handler panics, logging the stack through the contextual logger,
+// reporting it to the ErrorReporter and answering with the standard APIError envelope
+func RecoverMiddleware(reporter errreport.ErrorReporter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (returnErr error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				// http.ErrAbortHandler aborts the response on purpose, net/http handles it silently
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				ctx := c.Request().Context()
+				stack := string(debug.Stack())
+				panicErr := fmt.Errorf("panic: %v", r)
+
+				ctxlogger.GetLogger(ctx).LogAttrs(ctx, slog.LevelError, "HTTP_PANIC_RECOVERED",
+					slog.String("method", c.Request().Method),
+					slog.String("uri", c.Request().RequestURI),
+					slog.String("error", panicErr.Error()),
+					slog.String("stack", stack),
+				)
+				reporter.Report(ctx, panicErr, map[string]string{
+					errreport.TagHTTPMethod: c.Request().Method,
+					errreport.TagHTTPRoute:  c.Path(),
+					errreport.TagHTTPStatus: strconv.Itoa(http.StatusInternalServerError),
+					errreport.TagStack:      stack,
+				})
+
+				if c.Response().Committed {
+					return
+				}
+				errResp := httpx.NewAPIError(
+					"INTERNAL_SERVER_ERROR",
+					"An unexpected error occurred",
+					nil,
+				)
+				returnErr = httpx.SendAPIError(c, http.StatusInternalServerError, errResp)
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// signedEchoHandler answers a signed request with its own body, once the signature middleware verified it
+func signedEchoHandler(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return httpx.SendAPIError(c, http.StatusBadRequest, httpx.NewAPIError("UNREADABLE_BODY", "the request body could not be read", nil))
+	}
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	return c.Blob(http.StatusOK, contentType, body)
+}
+
+// AuthMiddleware validates the bearer access token against the identity service and
+// injects the authenticated user into the request context via the authctx package
+// When demo mode is enabled (demoService not nil), demo tokens are validated locally instead
+func AuthMiddleware(identityClient *identityclient.Client, demoService *demo.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
+			accessToken, found := strings.CutPrefix(authHeader, "Bearer ")
+			if !found || strings.TrimSpace(accessToken) == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed bearer token")
+			}
+
+			ctx := c.Request().Context()
+			user := authctx.UserContext{
+				Locale: primaryLanguage(c.Request().Header.Get(httpx.HeaderAcceptLanguage)),
+			}
+
+			if demoService != nil && demo.IsDemoToken(accessToken) {
+				session, ok := demoService.Authenticate(accessToken)
+				if !ok {
+					return identityclient.ErrInvalidToken
+				}
+				user.UserID = session.UserID
+				user.Roles = []string{demo.Role}
+				user.EmailVerified = true
+			} else {
+				tokenInfo, err := identityClient.ValidateToken(ctx, accessToken)
+				if err != nil {
+					return err
+				}
+				user.UserID = tokenInfo.UserID
+				user.Scope = tokenInfo.Scope
+				user.EmailVerified = tokenInfo.EmailVerified
+				user.ImpersonatedBy = tokenInfo.ImpersonatedBy
+			}
+
+			ctxWithUser := authctx.WithUser(ctx, user)
+
+			// Enrich the request-scoped logger so every downstream log carries the user ID, and support acting as them
+			requestLogger := ctxlogger.GetLogger(ctx).With(slog.String("user_id", user.UserID.String()))
+			if user.ImpersonatedBy != "" {
+				requestLogger = requestLogger.With(slog.String("impersonated_by", user.ImpersonatedBy))
+			}
+			ctxWithUser = ctxlogger.SetLogger(ctxWithUser, requestLogger)
+			c.SetRequest(c.Request().WithContext(ctxWithUser))
+
+			return next(c)
+		}
+	}
+}
+
+// requestCost weighs the reports and exports by the months of history they read (e.g., 120 for a 10-year export), the
+// other routes costing nothing and being left to the rate limit alone
+func requestCost(clock clock.Clock) func(c echo.Context) int {
+	return func(c echo.Context) int {
+		switch c.Path() {
+		case "/api/v1/reports/tags", "/api/v1/reports/spending", "/api/v1/reports/spending/export", "/api/v1/reports/spending/weekly",
+			"/api/v1/business/reports/cost-centers":
+			return monthsRequested(c, clock.Now(), 0)
+		case "/api/v1/net-worth/history":
+			return monthsRequested(c, clock.Now(), networth.DefaultHistoryRange)
+		case "/api/v1/households/:id/report", "/api/v1/payee-limits/report":
+			return 1
+		case "/api/v1/backup":
+			return fullHistoryCost
+		default:
+			return 0
+		}
+	}
+}
+
+// monthsRequested counts the months between the from and to query parameters, to defaulting to now and from to
+// defaultRange before to. Malformed dates count as the default, the handler rejecting them anyway
+func monthsRequested(c echo.Context, now time.Time, defaultRange time.Duration) int {
+	to, err := time.Parse(time.DateOnly, c.QueryParam("to"))
+	if err != nil {
+		to = now
+	}
+	from, err := time.Parse(time.DateOnly, c.QueryParam("from"))
+	if err != nil {
+		from = to.Add(-defaultRange)
+	}
+	return max(int(to.Sub(from).Hours()/24/30)+1, 1)
+}
+
+// userKey identifies the caller by their user, or by their IP when the request is not authenticated
+func userKey(c echo.Context) string {
+	if user, ok := authctx.UserFromContext(c.Request().Context()); ok {
+		return "user:" + user.UserID.String()
+	}
+	return "ip:" + c.RealIP()
+}
+
+// PreferredLanguageMiddleware writes the responses in the locale of the user's preferences
+// when the client sent no supported Accept-Language, it must run after AuthMiddleware
+func PreferredLanguageMiddleware(display module.UserPreferences) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			if _, ok := i18n.FromContext(ctx); ok {
+				return next(c)
+			}
+			user, ok := authctx.UserFromContext(ctx)
+			if !ok {
+				return next(c)
+			}
+
+			prefs, err := display.DisplayPreferences(ctx, user.UserID)
+			if err != nil {
+				// The default language is good enough, a failed lookup must not fail the request
+				ctxlogger.GetLogger(ctx).Warn("failed to find display preferences", slog.String("error", err.Error()))
+				return next(c)
+			}
+			if lang, ok := i18n.Resolve(prefs.Locale); ok {
+				httpx.SetLanguage(c, lang)
+			}
+			return next(c)
+		}
+	}
+}
+
+// primaryLanguage returns the first language tag of an Accept-Language header value
+func primaryLanguage(acceptLanguage string) string {
+	tag, _, _ := strings.Cut(acceptLanguage, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	return strings.TrimSpace(tag)
+}
+
 // RequestLoggerMiddleware configures and returns Echo's built-in request logger middleware
 // It uses the contextual logger (injected by ContextualLoggerMiddleware) to ensure
 // that every access log automatically includes the corresponding request ID
@@ -137,126 +943,3 @@ func RequestLoggerMiddleware() echo.MiddlewareFunc {
 		},
 	})
 }
-
-// customErrorHandler is the centralized error handler for the entire API
-// It intercepts any error returned from a handler, inspects its type, and
-// formats a standardized JSON error response using our' httpx.Error structure
-func customerErrorHandler(err error, c echo.Context) {
-	log := ctxlogger.GetLogger(c.Request().Context())
-	if c.Response().Committed {
-		return
-	}
-
-	// 1. Handle custom validation errors from our validatorx package
-	var valErr validatorx.ValidationError
-	if errors.As(err, &valErr) {
-		errResp := httpx.NewAPIError(
-			"VALIDATION_ERROR",
-			"one or more fields failed validation",
-			valErr.Errors, // The 'Details' field will contain the slice of FieldError
-		)
-		httpx.SendAPIError(c, http.StatusBadRequest, errResp)
-		return
-	}
-
-	// 2. Handle known domain errors from the LEDGER MODULE
-	var httpStatus int
-	var errCode string
-	var errMsg string = err.Error()
-	switch {
-	// 404 Not Found
-	case errors.Is(err, ledger.ErrAccountNotFound):
-		httpStatus = http.StatusNotFound
-		errCode = "RESOURCE_NOT_FOUND"
-		errMsg = ledger.ErrAccountNotFound.Error()
-	case errors.Is(err, ledger.ErrTransactionNotFound):
-		httpStatus = http.StatusNotFound
-		errCode = "RESOURCE_NOT_FOUND"
-		errMsg = ledger.ErrTransactionNotFound.Error()
-
-		// 403 Forbidden
-	case errors.Is(err, ledger.ErrAccountArchived):
-		httpStatus = http.StatusForbidden
-		errCode = "FORBIDDEN"
-		errMsg = ledger.ErrAccountArchived.Error()
-	case errors.Is(err, ledger.ErrAccountAlreadyArchived):
-		httpStatus = http.StatusForbidden
-		errCode = "FORBIDDEN"
-		errMsg = ledger.ErrAccountArchived.Error()
-
-		// 409 Conflict
-	case errors.Is(err, ledger.ErrTransactionAlreadyPaid):
-		httpStatus = http.StatusConflict
-		errCode = "STATE_CONFLICT"
-		errMsg = ledger.ErrTransactionAlreadyPaid.Error()
-	case errors.Is(err, ledger.ErrTransactionAlreadyUnpaid):
-		httpStatus = http.StatusConflict
-		errCode = "STATE_CONFLICT"
-		errMsg = ledger.ErrTransactionAlreadyUnpaid.Error()
-	case errors.Is(err, ledger.ErrAccountAlreadyIncluded):
-		httpStatus = http.StatusConflict
-		errCode = "STATE_CONFLICT"
-		errMsg = ledger.ErrAccountAlreadyIncluded.Error()
-	case errors.Is(err, ledger.ErrAccountAlreadyExcluded):
-		httpStatus = http.StatusConflict
-		errCode = "STATE_CONFLICT"
-		errMsg = ledger.ErrAccountAlreadyExcluded.Error()
-	case errors.Is(err, ledger.ErrAccountNotArchived):
-		httpStatus = http.StatusConflict
-		errCode = "STATE_CONFLICT"
-		errMsg = ledger.ErrAccountNotArchived.Error()
-	case errors.Is(err, ledger.ErrAccountBalanceMustBeZeroToArchive):
-		httpStatus = http.StatusConflict
-		errCode = "STATE_CONFLICT"
-		errMsg = ledger.ErrAccountBalanceMustBeZeroToArchive.Error()
-
-		// 422 Unprocessable Entity
-	case errors.Is(err, ledger.ErrAccountNameRequired):
-		httpStatus = http.StatusUnprocessableEntity
-		errCode = "BUSINESS_RULE_VIOLATION"
-		errMsg = ledger.ErrAccountNameRequired.Error()
-	case errors.Is(err, ledger.ErrInconsistentAmountSign):
-		httpStatus = http.StatusUnprocessableEntity
-		errCode = "BUSINESS_RULE_VIOLATION"
-		errMsg = ledger.ErrInconsistentAmountSign.Error()
-	case errors.Is(err, ledger.ErrAmountCannotBeZero):
-		httpStatus = http.StatusUnprocessableEntity
-		errCode = "BUSINESS_RULE_VIOLATION"
-		errMsg = ledger.ErrAmountCannotBeZero.Error()
-	case errors.Is(err, ledger.ErrDescriptionRequired):
-		httpStatus = http.StatusUnprocessableEntity
-		errCode = "BUSINESS_RULE_VIOLATION"
-		errMsg = ledger.ErrDescriptionRequired.Error()
-	case errors.Is(err, ledger.ErrInvalidTransactionType):
-		httpStatus = http.StatusUnprocessableEntity
-		errCode = "BUSINESS_RULE_VIOLATION"
-		errMsg = ledger.ErrInvalidTransactionType.Error()
-	case errors.Is(err, ledger.ErrPaymentDateInFuture):
-		httpStatus = http.StatusUnprocessableEntity
-		errCode = "BUSINESS_RULE_VIOLATION"
-		errMsg = ledger.ErrPaymentDateInFuture.Error()
-	}
-
-	if httpStatus != 0 {
-		errResp := httpx.NewAPIError(errCode, errMsg, nil)
-		httpx.SendAPIError(c, httpStatus, errResp)
-		return
-	}
-
-	// 3. Handle generic Echo HTTP errors
-	var httpErr *echo.HTTPError
-	if errors.As(err, &httpErr) {
-		errResp := httpx.NewAPIError("HTTP_ERROR", fmt.Sprintf("%v", httpErr.Message), nil)
-		httpx.SendAPIError(c, httpErr.Code, errResp)
-		return
-	}
-
-	// 4. Fallback for any other unexpected error
-	log.Error("unhandled internal error", slog.String("error", err.Error()))
-	errResp := httpx.NewAPIError(
-		"INTERNAL_SERVER_ERROR",
-		"An unexpected error occurred",
-		nil,
-	)
-	httpx.SendAPIError(c, http.StatusInternalServerError, errResp) // 500
-}
@@ -0,0 +1,114 @@
+// Command fakeserver serves a fake of the ledger API from memory, for the contract tests of the clients that cannot
+// import the fakeserver package (e.g., the mobile apps): no database or identity service is needed
+//
+// Usage:
+//
+//	fakeserver                                                      DefaultToken on :8089, the clock at 2025-01-15
+//	fakeserver -addr :9000 -now 2025-06-01T09:00:00Z
+//	fakeserver -users alice-token=<uuid>,bob-token=<uuid>           one canned token per user
+//
+// Between their cases the clients call POST /_fake/reset to drop the data, and PUT /_fake/clock with {"now": ...} or
+// {"advance": "24h"} to move the clock. Flags default to the environment variables named in their usage
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/fakeserver"
+	"github.com/google/uuid"
+)
+
+func main() {
+	var (
+		addr  string
+		now   string
+		users string
+	)
+	flag.StringVar(&addr, "addr", envOr("FAKESERVER_ADDR", ":8089"), "`address` the server listens on (FAKESERVER_ADDR)")
+	flag.StringVar(&now, "now", envOr("FAKESERVER_NOW", fakeserver.DefaultNow.Format(time.RFC3339)), "RFC 3339 `instant` the clock starts at and goes back to on reset (FAKESERVER_NOW)")
+	flag.StringVar(&users, "users", os.Getenv("FAKESERVER_USERS"), "canned `tokens` of the users as token=uuid pairs, "+fakeserver.DefaultToken+" alone when empty (FAKESERVER_USERS)")
+	flag.Parse()
+
+	if err := run(addr, now, users); err != nil {
+		fmt.Fprintf(os.Stderr, "fakeserver: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(addr, rawNow, rawUsers string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	now, err := time.Parse(time.RFC3339, rawNow)
+	if err != nil {
+		return fmt.Errorf("invalid -now %q, expected an RFC 3339 instant: %w", rawNow, err)
+	}
+	users, err := parseUsers(rawUsers)
+	if err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           fakeserver.New(fakeserver.Options{Now: now, Users: users, Logger: logger}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	logger.Info("fake ledger API listening", slog.String("addr", addr), slog.Time("now", now), slog.Int("users", max(len(users), 1)))
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// parseUsers reads the token=uuid pairs of the users, none standing for the default user
+func parseUsers(raw string) ([]fakeserver.User, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var users []fakeserver.User
+	for _, pair := range strings.Split(raw, ",") {
+		token, rawID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || token == "" {
+			return nil, fmt.Errorf("invalid user %q, expected token=uuid", pair)
+		}
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id %q of token %s: %w", rawID, token, err)
+		}
+		users = append(users, fakeserver.User{Token: token, ID: id})
+	}
+	return users, nil
+}
+
+// envOr returns the environment variable, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/services/ledger-service/db"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/devseed"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/auditlog"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/migrate"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/retention"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ----- migrate ----- //
+
+// runMigrate applies the embedded migrations or reports their status
+func runMigrate(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("migrate", "up|status")
+	fs.Parse(args)
+
+	action := fs.Arg(0)
+	if action != "up" && action != "status" {
+		fs.Usage()
+		return errors.New("expected 'up' or 'status'")
+	}
+
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	migrator, err := migrate.New(pg.Pool, db.Migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	if action == "status" {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("no pending migrations")
+	}
+	return nil
+}
+
+// ----- create-user ----- //
+
+// runCreateUser registers a user in the identity service and mirrors it into the ledger users table,
+// which the ledger tables reference
+func runCreateUser(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("create-user", "-name <name> -email <email>")
+	name := fs.String("name", "", "full name of the user")
+	email := fs.String("email", "", "email used to sign in")
+	fs.Parse(args)
+
+	if strings.TrimSpace(*name) == "" || strings.TrimSpace(*email) == "" {
+		fs.Usage()
+		return errors.New("-name and -email are required")
+	}
+
+	// The password is read from stdin so it never ends up in the shell history
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("password can not be empty")
+	}
+
+	identity, err := env.identityClient()
+	if err != nil {
+		return err
+	}
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	userID, err := identity.Register(ctx, *name, *email, password)
+	if err != nil {
+		return fmt.Errorf("failed to register user: %w", err)
+	}
+
+	// Credentials are owned by the identity service, the ledger copy only satisfies foreign keys
+	query := `
+		INSERT INTO users (id, name, email, password_hash)
+		VALUES ($1, $2, $3, '')
+		ON CONFLICT (id) DO NOTHING
+	`
+	if _, err := pg.Pool.Exec(ctx, query, userID, *name, *email); err != nil {
+		return fmt.Errorf("user %s registered but not mirrored into the ledger: %w", userID, err)
+	}
+
+	fmt.Printf("created user %s\n", userID)
+	return nil
+}
+
+// ----- seed ----- //
+
+// runSeed generates realistic categories, accounts and months of transactions for an existing user
+func runSeed(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("seed", "-user <user id> [-months 6] [-rand-seed 1]")
+	rawUserID := fs.String("user", "", "id of the user receiving the generated data")
+	months := fs.Int("months", 6, "months of transaction history to generate, ending with the current month")
+	randSeed := fs.Int64("rand-seed", 1, "seed of the generator, the same seed generates the same data")
+	fs.Parse(args)
+
+	userID, err := parseUserID(fs.Usage, *rawUserID)
+	if err != nil {
+		return err
+	}
+
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	fields, err := env.fieldCipher(pg)
+	if err != nil {
+		return err
+	}
+
+	seeder := devseed.NewSeeder(devseed.NewPostgresCategoryStore(pg.Pool), ledger.NewPostgresAccountRepository(pg.Pool, nil, fields, nil, ledger.QueryTimeouts{}), idgen.V7Generator{}, clock.SystemClock{})
+	result, err := seeder.Seed(ctx, devseed.Options{
+		UserID:   userID,
+		Months:   *months,
+		RandSeed: *randSeed,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("seeded user %s: %d categories, %d accounts, %d transactions\n",
+		userID, result.Categories, result.Accounts, result.Transactions)
+	return nil
+}
+
+// ----- recompute-balances ----- //
+
+// runRecomputeBalances recomputes the balances of every account from its transactions
+// Balances are derived data, so this reports them and flags the accounts whose balance
+// can not be computed (e.g., overflow or transactions in another currency)
+func runRecomputeBalances(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("recompute-balances", "[-user <user id>]")
+	rawUserID := fs.String("user", "", "only recompute the accounts of this user")
+	fs.Parse(args)
+
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	var userIDs []uuid.UUID
+	if *rawUserID != "" {
+		userID, err := parseUserID(fs.Usage, *rawUserID)
+		if err != nil {
+			return err
+		}
+		userIDs = append(userIDs, userID)
+	} else {
+		rows, err := pg.Pool.Query(ctx, "SELECT DISTINCT user_id FROM accounts ORDER BY user_id")
+		if err != nil {
+			return fmt.Errorf("failed to list users with accounts: %w", err)
+		}
+		userIDs, err = pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+		if err != nil {
+			return fmt.Errorf("failed to scan users with accounts: %w", err)
+		}
+	}
+
+	fields, err := env.fieldCipher(pg)
+	if err != nil {
+		return err
+	}
+
+	systemClock := clock.SystemClock{}
+	ledgerSvc := ledger.NewLedgerService(
+		ledger.NewPostgresAccountRepository(pg.Pool, nil, fields, nil, ledger.QueryTimeouts{}),
+		audit.NewLogger(auditlog.NewPostgresSink(pg.Pool), systemClock),
+		ledger.NewMetrics(metrics.Nop{}),
+		env.cfg.Ledger.AccountDeletionGrace,
+		env.cfg.Ledger.MaxActiveTransactions,
+		nil,
+		nil,
+		idgen.V7Generator{},
+		systemClock,
+	)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tACCOUNT\tNAME\tREAL BALANCE\tPROJECTED BALANCE\tSTATUS")
+
+	inconsistent := 0
+	for _, userID := range userIDs {
+		accounts, err := ledgerSvc.FindAccountsByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to load accounts of user %s: %w", userID, err)
+		}
+
+		for _, acc := range accounts {
+			realBalance, realErr := acc.RealBalance(systemClock)
+			projected, projectedErr := acc.ProjectedBalance()
+			if err := errors.Join(realErr, projectedErr); err != nil {
+				inconsistent++
+				fmt.Fprintf(w, "%s\t%s\t%s\t-\t-\t%s\n", userID, acc.ID, acc.Name, err)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\tok\n", userID, acc.ID, acc.Name, realBalance, projected)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if inconsistent > 0 {
+		return fmt.Errorf("%d account(s) with inconsistent balances", inconsistent)
+	}
+	return nil
+}
+
+// ----- retention ----- //
+
+// runRetention reports how many rows each enabled retention rule removes, applying the rules with -apply
+func runRetention(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("retention", "[-apply]")
+	apply := fs.Bool("apply", false, "remove the data instead of only counting it")
+	fs.Parse(args)
+
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	systemClock := clock.SystemClock{}
+	retentionSvc := retention.NewService(
+		retention.NewPostgresRepository(pg.Pool),
+		retention.PolicyFromConfig(env.cfg),
+		audit.NewLogger(auditlog.NewPostgresSink(pg.Pool), systemClock),
+		systemClock,
+	)
+
+	results, err := retentionSvc.Run(ctx, !*apply)
+	if len(results) == 0 && err == nil {
+		fmt.Println("no retention rule is enabled")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RULE\tBEFORE\tAFFECTED")
+	for _, r := range results {
+		affected := strconv.FormatInt(r.Affected, 10)
+		if r.DryRun {
+			affected += " (dry run)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Rule, r.Before.Format(time.RFC3339), affected)
+	}
+	if flushErr := w.Flush(); flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// ----- anonymize-user ----- //
+
+// runAnonymizeUser replaces the personal data a user left in the ledger database, keeping the amounts
+// and dates so aggregated reports stay correct. Identity data (credentials, email) must be erased
+// in the identity service
+func runAnonymizeUser(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("anonymize-user", "-user <user id> -confirm")
+	rawUserID := fs.String("user", "", "id of the user to anonymize")
+	confirm := fs.Bool("confirm", false, "confirm the irreversible anonymization")
+	fs.Parse(args)
+
+	userID, err := parseUserID(fs.Usage, *rawUserID)
+	if err != nil {
+		return err
+	}
+	if !*confirm {
+		return errors.New("anonymization is irreversible, run again with -confirm")
+	}
+
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	statements := []statement{
+		{"user", `UPDATE users SET name = 'Anonymized user', email = 'anonymized+' || id || '@fintrack.invalid', password_hash = '', updated_at = NOW() WHERE id = $1`},
+		{"accounts", `UPDATE accounts SET name = 'Anonymized account', updated_at = NOW() WHERE user_id = $1`},
+		{"transactions", `UPDATE transactions SET description = 'Anonymized transaction', observation = NULL, metadata = NULL, updated_at = NOW() WHERE user_id = $1`},
+		{"archived transactions", `UPDATE archived_transactions SET description = 'Anonymized transaction', observation = NULL, metadata = NULL, tags = '{}' WHERE user_id = $1`},
+		{"tags", `DELETE FROM tags WHERE user_id = $1`},
+		{"payee limits", `DELETE FROM payee_limits WHERE user_id = $1`},
+		{"saved views", `DELETE FROM saved_views WHERE user_id = $1`},
+		{"transaction comments", `UPDATE transaction_comments SET body = 'Anonymized comment' WHERE author_id = $1`},
+		{"notifications", `DELETE FROM notifications WHERE user_id = $1`},
+		{"notification preferences", `DELETE FROM notification_preferences WHERE user_id = $1`},
+		{"push devices", `DELETE FROM push_devices WHERE user_id = $1`},
+		{"webhook deliveries", `DELETE FROM webhook_deliveries WHERE user_id = $1`},
+		{"jobs", `DELETE FROM jobs WHERE user_id = $1`},
+		{"data key", `DELETE FROM user_data_keys WHERE user_id = $1`},
+		{"business profile", `UPDATE business_profiles SET legal_name = 'Anonymized business', cnpj = NULL WHERE user_id = $1`},
+		{"cost centers", `UPDATE cost_centers SET name = 'Cost center ' || left(md5(id::text), 8) WHERE user_id = $1`},
+		{"transaction business details", `UPDATE transaction_business_details SET supplier_cnpj = NULL WHERE account_id IN (SELECT id FROM accounts WHERE user_id = $1)`},
+		{"attachments", `UPDATE attachments SET filename = 'Anonymized attachment' WHERE user_id = $1`},
+		{"household transaction proposals", `
+			UPDATE household_transaction_proposals SET
+				description = 'Anonymized transaction',
+				rejection_reason = CASE WHEN rejection_reason = '' THEN '' ELSE 'Anonymized reason' END
+			WHERE proposed_by = $1 OR owner_id = $1
+		`},
+	}
+
+	var affected []string
+	err = pgx.BeginTxFunc(ctx, pg.Pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		affected, err = execStatements(ctx, tx, statements, userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("anonymized user %s (%s)\n", userID, strings.Join(affected, ", "))
+	fmt.Println("remember to delete the user from the identity service as well")
+	return nil
+}
+
+// ----- anonymize ----- //
+
+// scrambleFunction replaces every word of a text with a token of the same length derived from the word and a salt,
+// so the same word gives the same token across tables (e.g., a payee limit keeps matching the descriptions)
+// scramble_digits does the same for documents such as the CNPJs, keeping them digits so a supplier still matches the
+// business profile of the same company
+const scrambleFunction = `
+	CREATE FUNCTION pg_temp.scramble(value TEXT, salt TEXT) RETURNS TEXT AS $$
+		SELECT string_agg(
+			substr(repeat(md5(salt || lower(word)), length(word) / 32 + 1), 1, length(word)),
+			' ' ORDER BY position
+		)
+		FROM regexp_split_to_table(value, ' ') WITH ORDINALITY AS words(word, position)
+	$$ LANGUAGE SQL IMMUTABLE;
+
+	CREATE FUNCTION pg_temp.scramble_digits(value TEXT, salt TEXT) RETURNS TEXT AS $$
+		SELECT translate(substr(repeat(md5(salt || value), length(value) / 32 + 1), 1, length(value)), 'abcdef', '012345')
+	$$ LANGUAGE SQL IMMUTABLE
+`
+
+// runAnonymize scrambles the personal data of every user, for copies of the production database used in other
+// environments (e.g., staging). Amounts and dates are kept, free texts are scrambled word by word with a salt
+// drawn for the run, and what can not be scrambled meaningfully (notifications, imports, bank consents, encrypted
+// observations...) is deleted
+func runAnonymize(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("anonymize", "-confirm-database <database name>")
+	confirmDatabase := fs.String("confirm-database", "", "name of the database being anonymized, as a confirmation")
+	fs.Parse(args)
+
+	if *confirmDatabase == "" {
+		fs.Usage()
+		return errors.New("anonymization is irreversible, run again with -confirm-database")
+	}
+	if *confirmDatabase != env.cfg.Database.Name {
+		return fmt.Errorf("connected to database %q, not %q", env.cfg.Database.Name, *confirmDatabase)
+	}
+	if env.cfg.Profile == config.ProfileProd || env.cfg.Observability.ErrorReporting.Environment == "production" {
+		return errors.New("refusing to anonymize the database of the production environment")
+	}
+
+	pg, err := env.postgres(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The salt is never stored, so the tokens can not be traced back to the words by hashing candidates
+	salt := rand.Text()
+
+	scrambled := []statement{
+		{"users", `UPDATE users SET name = 'User ' || left(md5($1 || id::text), 8), email = 'user+' || id || '@fintrack.invalid', password_hash = '', updated_at = NOW()`},
+		{"accounts", `UPDATE accounts SET name = pg_temp.scramble(name, $1)`},
+		{"categories", `UPDATE categories SET name = pg_temp.scramble(name, $1) WHERE user_id IS NOT NULL`},
+		{"transactions", `UPDATE transactions SET description = pg_temp.scramble(description, $1), observation = CASE WHEN observation LIKE 'enc:%' THEN NULL ELSE pg_temp.scramble(observation, $1) END, metadata = NULL`},
+		{"archived transactions", `UPDATE archived_transactions SET description = pg_temp.scramble(description, $1), observation = CASE WHEN observation LIKE 'enc:%' THEN NULL ELSE pg_temp.scramble(observation, $1) END, metadata = NULL, tags = '{}'`},
+		{"tags", `UPDATE tags SET name = pg_temp.scramble(name, $1)`},
+		{"payee limits", `UPDATE payee_limits SET payee = pg_temp.scramble(payee, $1)`},
+		{"categorization rules", `UPDATE categorization_rules SET pattern = pg_temp.scramble(pattern, $1)`},
+		{"saved views", `
+			UPDATE saved_views SET
+				name = pg_temp.scramble(name, $1),
+				criteria = CASE WHEN criteria ? 'search' THEN jsonb_set(criteria, '{search}', to_jsonb(pg_temp.scramble(criteria->>'search', $1))) ELSE criteria END
+		`},
+		{"transaction comments", `UPDATE transaction_comments SET body = pg_temp.scramble(body, $1)`},
+		{"households", `UPDATE households SET name = pg_temp.scramble(name, $1)`},
+		{"business profiles", `UPDATE business_profiles SET legal_name = pg_temp.scramble(legal_name, $1), cnpj = pg_temp.scramble_digits(cnpj, $1)`},
+		{"cost centers", `UPDATE cost_centers SET name = pg_temp.scramble(name, $1)`},
+		{"transaction business details", `UPDATE transaction_business_details SET supplier_cnpj = pg_temp.scramble_digits(supplier_cnpj, $1)`},
+		{"attachments", `UPDATE attachments SET filename = pg_temp.scramble(filename, $1)`},
+		{"household transaction proposals", `
+			UPDATE household_transaction_proposals SET
+				description = pg_temp.scramble(description, $1),
+				rejection_reason = pg_temp.scramble(rejection_reason, $1)
+		`},
+	}
+	// Another environment must never call the webhooks of the users, push to their phones or sync their banks
+	cleared := []statement{
+		{"notification preferences", `UPDATE notification_preferences SET webhook_url = NULL`},
+		{"push devices", `DELETE FROM push_devices`},
+		{"webhook deliveries", `DELETE FROM webhook_deliveries`},
+		{"bank consents", `DELETE FROM bank_consents`},
+		{"notifications", `DELETE FROM notifications`},
+		{"jobs", `DELETE FROM jobs`},
+		{"imports", `DELETE FROM imports`},
+		{"data keys", `DELETE FROM user_data_keys`},
+	}
+
+	var affected []string
+	err = pgx.BeginTxFunc(ctx, pg.Pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, scrambleFunction); err != nil {
+			return fmt.Errorf("failed to create the scramble function: %w", err)
+		}
+		scrambledRows, err := execStatements(ctx, tx, scrambled, salt)
+		if err != nil {
+			return err
+		}
+		clearedRows, err := execStatements(ctx, tx, cleared)
+		if err != nil {
+			return err
+		}
+		affected = append(scrambledRows, clearedRows...)
+
+		// audit_logs is append-only row by row, its states hold the names and descriptions as they were
+		if _, err := tx.Exec(ctx, `TRUNCATE audit_logs`); err != nil {
+			return fmt.Errorf("failed to anonymize audit logs: %w", err)
+		}
+		affected = append(affected, "audit logs: truncated")
+
+		_, err = tx.Exec(ctx, `DROP FUNCTION pg_temp.scramble(TEXT, TEXT); DROP FUNCTION pg_temp.scramble_digits(TEXT, TEXT)`)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("anonymized database %s (%s)\n", env.cfg.Database.Name, strings.Join(affected, ", "))
+	fmt.Println("remember to point this environment to its own identity service, the credentials are not copied")
+	return nil
+}
+
+// statement is a query run for its side effects, what naming the data it changes
+type statement struct {
+	what  string
+	query string
+}
+
+// execStatements runs the statements in order, reporting how many rows each one affected
+func execStatements(ctx context.Context, tx pgx.Tx, statements []statement, args ...any) ([]string, error) {
+	affected := make([]string, 0, len(statements))
+	for _, stmt := range statements {
+		tag, err := tx.Exec(ctx, stmt.query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to anonymize %s: %w", stmt.what, err)
+		}
+		affected = append(affected, fmt.Sprintf("%s: %d", stmt.what, tag.RowsAffected()))
+	}
+	return affected, nil
+}
+
+// parseUserID validates a -user flag value
+func parseUserID(usage func(), raw string) (uuid.UUID, error) {
+	if raw == "" {
+		usage()
+		return uuid.Nil, errors.New("-user is required")
+	}
+
+	userID, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id %q: %w", raw, err)
+	}
+	return userID, nil
+}
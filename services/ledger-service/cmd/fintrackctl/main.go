@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/fieldcrypt"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
+)
+
+// command is a fintrackctl subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, env *environment, args []string) error
+}
+
+// commands lists every subcommand in the order they are shown by the usage message
+var commands = []command{
+	{name: "migrate", summary: "apply pending migrations (up) or list them (status)", run: runMigrate},
+	{name: "create-user", summary: "register a user in the identity service and the ledger", run: runCreateUser},
+	{name: "seed", summary: "generate categories, accounts and months of transactions for a user", run: runSeed},
+	{name: "recompute-balances", summary: "recompute account balances from transactions and report inconsistencies", run: runRecomputeBalances},
+	{name: "anonymize-user", summary: "irreversibly replace a user's personal data in the ledger", run: runAnonymizeUser},
+	{name: "retention", summary: "report what the retention policy removes, or apply it with -apply", run: runRetention},
+	{name: "anonymize", summary: "scramble the personal data of every user, for non-production copies of the database", run: runAnonymize},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+
+	idx := slices.IndexFunc(commands, func(c command) bool { return c.name == os.Args[1] })
+	if idx < 0 {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config to fintrackctl: %s\n", err)
+		os.Exit(1)
+	}
+
+	env := &environment{cfg: cfg}
+	defer env.close()
+
+	if err := commands[idx].run(ctx, env, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", commands[idx].name, err)
+		env.close()
+		os.Exit(1)
+	}
+}
+
+// usage prints the available subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "fintrackctl is the administrative CLI of the fintrack ledger")
+	fmt.Fprintln(os.Stderr, "\nUsage:\n  fintrackctl <command> [flags]\n\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'fintrackctl <command> -h' for the flags of a command")
+}
+
+// environment lazily opens the infrastructure a command needs, so that e.g. migrations
+// do not require the identity service to be reachable
+type environment struct {
+	cfg      *config.Config
+	pg       *postgres.Postgres
+	identity *identityclient.Client
+}
+
+// postgres returns the Postgres connection, opening it on first use
+func (e *environment) postgres(ctx context.Context) (*postgres.Postgres, error) {
+	if e.pg == nil {
+		pg, err := postgres.NewPostgresConnection(ctx, *e.cfg, nil)
+		if err != nil {
+			return nil, err
+		}
+		e.pg = pg
+	}
+	return e.pg, nil
+}
+
+// fieldCipher returns the cipher of the encrypted fields of the transactions, nil when their encryption is not configured
+func (e *environment) fieldCipher(pg *postgres.Postgres) (*fieldcrypt.Cipher, error) {
+	return fieldcrypt.NewLocalCipher(e.cfg.Ledger.FieldEncryption.MasterKey, fieldcrypt.NewPostgresKeyStore(pg.Pool))
+}
+
+// identityClient returns the identity service client, creating it on first use
+func (e *environment) identityClient() (*identityclient.Client, error) {
+	if e.identity == nil {
+		client, err := identityclient.NewClient(*e.cfg)
+		if err != nil {
+			return nil, err
+		}
+		e.identity = client
+	}
+	return e.identity, nil
+}
+
+// close releases whatever the command opened
+func (e *environment) close() {
+	if e.pg != nil {
+		e.pg.Close()
+		e.pg = nil
+	}
+	if e.identity != nil {
+		e.identity.Close()
+		e.identity = nil
+	}
+}
+
+// newFlagSet creates the flag set of a subcommand, exiting on -h like the standard flag package
+func newFlagSet(name, usageLine string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  fintrackctl %s %s\n\nFlags:\n", name, usageLine)
+		fs.PrintDefaults()
+	}
+	return fs
+}
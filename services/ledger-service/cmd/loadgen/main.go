@@ -0,0 +1,139 @@
+// Command loadgen drives a synthetic workload against the ledger API of a target environment and reports the latency
+// and the errors of each operation, to validate the storage redesigns under load before they are released
+//
+// Usage:
+//
+//	loadgen -target https://staging.example.com -users 50 -duration 10m           demo sessions, default mix
+//	loadgen -token $TOKEN -mix writes=80,reads=20 -burst 50 -duration 2h          soak test as a single user
+//	loadgen -duration 5m -max-error-rate 0.01 -max-p99 800ms                      fail when the thresholds are crossed
+//
+// Without -token every virtual user opens its own demo session, so the target must run in demo mode, where the reports
+// are not served: the default mix then leaves them out. Flags default to the environment variables named in their usage
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// options are the settings of a load run, read from the flags
+type options struct {
+	target         string
+	token          string
+	users          int
+	accounts       int
+	burst          int
+	mix            string
+	think          time.Duration
+	rampUp         time.Duration
+	duration       time.Duration
+	timeout        time.Duration
+	reportInterval time.Duration
+	maxErrorRate   float64
+	maxP99         time.Duration
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.target, "target", envOr("LOADGEN_TARGET", "http://localhost:8080"), "base `URL` of the ledger API (LOADGEN_TARGET)")
+	flag.StringVar(&opts.token, "token", os.Getenv("LOADGEN_TOKEN"), "access token shared by every virtual user, empty to open a demo session per user (LOADGEN_TOKEN)")
+	flag.IntVar(&opts.users, "users", 10, "virtual users running the workload concurrently")
+	flag.IntVar(&opts.accounts, "accounts", 2, "accounts each virtual user creates before running the workload")
+	flag.StringVar(&opts.mix, "mix", "", "weights of the `operations` (e.g., writes=60,reads=25,reports=15), reports being left out in demo mode when empty")
+	flag.IntVar(&opts.burst, "burst", 20, "transactions written back to back by each write operation")
+	flag.DurationVar(&opts.think, "think", 200*time.Millisecond, "pause of a virtual user between two operations, 0 running them back to back")
+	flag.DurationVar(&opts.rampUp, "ramp-up", 10*time.Second, "time over which the virtual users are started")
+	flag.DurationVar(&opts.duration, "duration", time.Minute, "how long the workload runs once the users are started, for soak tests set it to hours")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of each request")
+	flag.DurationVar(&opts.reportInterval, "report-interval", 10*time.Second, "interval of the progress lines, 0 to print the final report alone")
+	flag.Float64Var(&opts.maxErrorRate, "max-error-rate", 0, "share of failed requests (e.g., 0.01) above which loadgen exits with an error, 0 to disable it")
+	flag.DurationVar(&opts.maxP99, "max-p99", 0, "p99 latency of any operation above which loadgen exits with an error, 0 to disable it")
+	flag.Parse()
+
+	if err := run(context.Background(), opts); err != nil {
+		fmt.Fprintf(os.Stderr, "load run finished with an error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if opts.users < 1 || opts.accounts < 1 || opts.burst < 1 {
+		return errors.New("-users, -accounts and -burst must be at least 1")
+	}
+	rawMix := opts.mix
+	if rawMix == "" {
+		rawMix = defaultMix(opts.token == "")
+	}
+	mix, err := parseMix(rawMix)
+	if err != nil {
+		return err
+	}
+
+	client := &apiClient{
+		baseURL: strings.TrimSuffix(opts.target, "/"),
+		http:    &http.Client{Timeout: opts.timeout},
+		stats:   newStats(),
+	}
+
+	fmt.Fprintf(os.Stderr, "loadgen: %d users against %s for %s (mix %s, bursts of %d)\n", opts.users, client.baseURL, opts.duration, rawMix, opts.burst)
+
+	// The users run until the deadline, which only starts once they were all started
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	time.AfterFunc(opts.rampUp+opts.duration, stop)
+
+	if opts.reportInterval > 0 {
+		go client.stats.printProgress(runCtx, os.Stderr, opts.reportInterval)
+	}
+
+	var wg sync.WaitGroup
+	for i := range opts.users {
+		if i > 0 && opts.rampUp > 0 {
+			select {
+			case <-runCtx.Done():
+			case <-time.After(opts.rampUp / time.Duration(opts.users)):
+			}
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u := &virtualUser{client: client, token: opts.token, mix: mix, burst: opts.burst, think: opts.think}
+			if err := u.setUp(runCtx, opts.accounts); err != nil {
+				fmt.Fprintf(os.Stderr, "loadgen: virtual user %d could not set up: %s\n", i, err)
+				return
+			}
+			u.run(runCtx)
+		}()
+	}
+	wg.Wait()
+
+	summary := client.stats.summary()
+	summary.print(os.Stdout)
+	if ctx.Err() != nil {
+		return errors.New("interrupted before the end of the run")
+	}
+	return summary.check(opts.maxErrorRate, opts.maxP99)
+}
+
+// envOr returns the environment variable, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// minLatency and latencyGrowth size the buckets of the histograms, each bucket being 10% wider than the previous one
+	// so the percentiles are known within 10% in constant memory, however long a soak test runs
+	minLatency     = 50 * time.Microsecond
+	latencyGrowth  = 1.1
+	latencyBuckets = 160 // latencyBuckets cover up to ~3 minutes, slower requests falling into the last one
+)
+
+// histogram counts the latencies into exponentially growing buckets
+type histogram struct {
+	buckets [latencyBuckets]int64
+	count   int64
+	max     time.Duration
+}
+
+// add counts a latency
+func (h *histogram) add(d time.Duration) {
+	i := 0
+	if d > minLatency {
+		i = min(int(math.Ceil(math.Log(float64(d)/float64(minLatency))/math.Log(latencyGrowth))), latencyBuckets-1)
+	}
+	h.buckets[i]++
+	h.count++
+	h.max = max(h.max, d)
+}
+
+// merge adds the latencies counted by other
+func (h *histogram) merge(other *histogram) {
+	for i, n := range other.buckets {
+		h.buckets[i] += n
+	}
+	h.count += other.count
+	h.max = max(h.max, other.max)
+}
+
+// percentile returns the upper bound of the bucket holding the latency below which the share p of the requests fall
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p * float64(h.count)))
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			return min(time.Duration(float64(minLatency)*math.Pow(latencyGrowth, float64(i))), h.max)
+		}
+	}
+	return h.max
+}
+
+// operationStats are the requests of an operation (e.g., add_transaction) and how they went
+type operationStats struct {
+	requests  int64
+	failed    int64 // failed counts the requests answered with an error status or not answered at all
+	throttled int64 // throttled counts the failed requests answered with 429, the rate limit of the target kicking in
+	latency   histogram
+}
+
+// add counts a request, status 0 standing for a request not answered
+func (s *operationStats) add(elapsed time.Duration, status int) {
+	s.requests++
+	s.latency.add(elapsed)
+	if status == 0 || status >= http.StatusBadRequest {
+		s.failed++
+	}
+	if status == http.StatusTooManyRequests {
+		s.throttled++
+	}
+}
+
+// merge adds the requests counted by other
+func (s *operationStats) merge(other *operationStats) {
+	s.requests += other.requests
+	s.failed += other.failed
+	s.throttled += other.throttled
+	s.latency.merge(&other.latency)
+}
+
+// stats gathers the requests of every virtual user, for the whole run and since the last progress line
+type stats struct {
+	mu         sync.Mutex
+	started    time.Time
+	operations map[string]*operationStats
+	window     operationStats
+}
+
+// newStats creates the stats of a run starting now
+func newStats() *stats {
+	return &stats{started: time.Now(), operations: make(map[string]*operationStats)}
+}
+
+// record counts a request of the operation
+func (s *stats) record(operation string, elapsed time.Duration, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op, ok := s.operations[operation]
+	if !ok {
+		op = &operationStats{}
+		s.operations[operation] = op
+	}
+	op.add(elapsed, status)
+	s.window.add(elapsed, status)
+}
+
+// printProgress writes a line on the requests of every interval until the end of the run
+func (s *stats) printProgress(ctx context.Context, w io.Writer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		window := s.window
+		s.window = operationStats{}
+		s.mu.Unlock()
+
+		fmt.Fprintf(w, "%8s  %7.1f req/s  %5d failed  %5d throttled  p50 %-9s p99 %-9s max %s\n",
+			time.Since(s.started).Round(time.Second),
+			float64(window.requests)/interval.Seconds(),
+			window.failed,
+			window.throttled,
+			window.latency.percentile(0.50).Round(time.Microsecond*100),
+			window.latency.percentile(0.99).Round(time.Microsecond*100),
+			window.latency.max.Round(time.Microsecond*100),
+		)
+	}
+}
+
+// runSummary is the outcome of a run, by operation and overall
+type runSummary struct {
+	elapsed    time.Duration
+	operations []string
+	byName     map[string]operationStats
+	total      operationStats
+}
+
+// summary takes the outcome of the run so far
+func (s *stats) summary() runSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := runSummary{elapsed: time.Since(s.started), byName: make(map[string]operationStats, len(s.operations))}
+	for name, op := range s.operations {
+		summary.operations = append(summary.operations, name)
+		summary.byName[name] = *op
+		summary.total.merge(op)
+	}
+	slices.Sort(summary.operations)
+	return summary
+}
+
+// print writes the table of the operations, followed by their total
+func (r runSummary) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "operation\trequests\treq/s\tfailed\tthrottled\tp50\tp90\tp99\tmax\t\n")
+	row := func(name string, op operationStats) {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+			name,
+			op.requests,
+			float64(op.requests)/r.elapsed.Seconds(),
+			op.failed,
+			op.throttled,
+			op.latency.percentile(0.50).Round(time.Microsecond*100),
+			op.latency.percentile(0.90).Round(time.Microsecond*100),
+			op.latency.percentile(0.99).Round(time.Microsecond*100),
+			op.latency.max.Round(time.Microsecond*100),
+		)
+	}
+	for _, name := range r.operations {
+		row(name, r.byName[name])
+	}
+	row("total", r.total)
+	tw.Flush()
+}
+
+// check fails the run when the share of failed requests or the p99 latency of an operation crossed their threshold,
+// a zero threshold being disabled
+func (r runSummary) check(maxErrorRate float64, maxP99 time.Duration) error {
+	if r.total.requests == 0 {
+		return errors.New("no request was sent")
+	}
+	if rate := float64(r.total.failed) / float64(r.total.requests); maxErrorRate > 0 && rate > maxErrorRate {
+		return fmt.Errorf("%.2f%% of the requests failed, above the %.2f%% allowed", rate*100, maxErrorRate*100)
+	}
+	if maxP99 > 0 {
+		for _, name := range r.operations {
+			op := r.byName[name]
+			if p99 := op.latency.percentile(0.99); p99 > maxP99 {
+				return fmt.Errorf("the p99 latency of %s is %s, above the %s allowed", name, p99.Round(time.Millisecond), maxP99)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/demo"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// workloads are the operations a virtual user picks from, in the order they are written in the mix
+var workloads = []string{"writes", "reads", "reports"}
+
+// weightedWorkload is a workload of the mix and its share of the operations
+type weightedWorkload struct {
+	name   string
+	weight int
+}
+
+// defaultMix returns the mix of the runs without -mix, the reports being left out of demo mode where they are not served
+func defaultMix(demoMode bool) string {
+	if demoMode {
+		return "writes=70,reads=30"
+	}
+	return "writes=60,reads=25,reports=15"
+}
+
+// parseMix reads the weights of the workloads (e.g., "writes=60,reads=25,reports=15"), the omitted ones never running
+func parseMix(raw string) ([]weightedWorkload, error) {
+	var (
+		mix   []weightedWorkload
+		total int
+	)
+	for _, part := range strings.Split(raw, ",") {
+		name, rawWeight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !slices.Contains(workloads, name) {
+			return nil, fmt.Errorf("invalid mix entry %q, expected one of %s followed by =weight", part, strings.Join(workloads, ", "))
+		}
+		weight, err := strconv.Atoi(rawWeight)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q of %s, expected a positive integer", rawWeight, name)
+		}
+		mix = append(mix, weightedWorkload{name: name, weight: weight})
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the mix %q runs nothing", raw)
+	}
+	return mix, nil
+}
+
+// pick draws a workload of the mix according to the weights
+func pick(mix []weightedWorkload) string {
+	total := 0
+	for _, w := range mix {
+		total += w.weight
+	}
+	n := rand.IntN(total)
+	for _, w := range mix {
+		if n < w.weight {
+			return w.name
+		}
+		n -= w.weight
+	}
+	return mix[len(mix)-1].name
+}
+
+// apiClient sends the requests of the virtual users, recording the latency and the outcome of each operation
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+	stats   *stats
+}
+
+// statusError is the answer of the API to a request that failed
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.body)
+}
+
+// do sends a request on behalf of the operation, decoding the data of a successful answer into out when given
+// The requests cut short by the end of the run are not recorded
+func (c *apiClient) do(ctx context.Context, operation, token, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		// The bodies take the shape of the latest version, their amounts being decimal strings
+		req.Header.Set("Content-Type", httpx.MIMEFintrackV2)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.stats.record(operation, time.Since(start), 0)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The whole answer is read, so the latency covers its transfer like it does for the clients
+	payload, err := io.ReadAll(resp.Body)
+	elapsed := time.Since(start)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.stats.record(operation, elapsed, 0)
+		}
+		return err
+	}
+	c.stats.record(operation, elapsed, resp.StatusCode)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &statusError{status: resp.StatusCode, body: strings.TrimSpace(string(payload))}
+	}
+	if out == nil {
+		return nil
+	}
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	return json.Unmarshal(payload, &envelope)
+}
+
+// virtualUser runs the workload of one user of the API, on the accounts it created
+type virtualUser struct {
+	client   *apiClient
+	token    string
+	mix      []weightedWorkload
+	burst    int
+	think    time.Duration
+	accounts []uuid.UUID
+}
+
+// setUp opens a demo session when no token was given, then creates the accounts of the user
+func (u *virtualUser) setUp(ctx context.Context, accounts int) error {
+	if u.token == "" {
+		var session demo.SessionResponse
+		if err := u.client.do(ctx, "open_session", "", http.MethodPost, "/api/v1/public/demo/sessions", nil, &session); err != nil {
+			return fmt.Errorf("failed to open demo session: %w", err)
+		}
+		u.token = session.AccessToken
+	}
+
+	for i := range accounts {
+		req := ledger.CreateAccountRequest{Name: fmt.Sprintf("Load test %d %s", i+1, uuid.NewString()[:8])}
+		var account struct {
+			ID uuid.UUID `json:"id"`
+		}
+		if err := u.client.do(ctx, "create_account", u.token, http.MethodPost, "/api/v1/accounts", req, &account); err != nil {
+			return fmt.Errorf("failed to create account: %w", err)
+		}
+		u.accounts = append(u.accounts, account.ID)
+	}
+	return nil
+}
+
+// run picks and runs operations until the end of the run, the failures being left to the report
+func (u *virtualUser) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		switch pick(u.mix) {
+		case "writes":
+			u.writeBurst(ctx)
+		case "reads":
+			u.read(ctx)
+		case "reports":
+			u.readReport(ctx)
+		}
+
+		if u.think > 0 {
+			// The pause is jittered by half of it either way, so the users do not move in lockstep
+			pause := u.think/2 + rand.N(u.think)
+			select {
+			case <-ctx.Done():
+			case <-time.After(pause):
+			}
+		}
+	}
+}
+
+// writeBurst adds transactions back to back to one of the accounts, like an import or a busy day does
+func (u *virtualUser) writeBurst(ctx context.Context) {
+	accountID := u.accounts[rand.IntN(len(u.accounts))]
+	path := "/api/v1/accounts/" + accountID.String() + "/transactions"
+	for range u.burst {
+		if ctx.Err() != nil {
+			return
+		}
+		_ = u.client.do(ctx, "add_transaction", u.token, http.MethodPost, path, syntheticTransaction(), nil)
+	}
+}
+
+// read lists the accounts of the user or opens one of them with its transactions
+func (u *virtualUser) read(ctx context.Context) {
+	if rand.IntN(2) == 0 {
+		_ = u.client.do(ctx, "list_accounts", u.token, http.MethodGet, "/api/v1/accounts", nil, nil)
+		return
+	}
+	accountID := u.accounts[rand.IntN(len(u.accounts))]
+	_ = u.client.do(ctx, "get_account", u.token, http.MethodGet, "/api/v1/accounts/"+accountID.String(), nil, nil)
+}
+
+// readReport reads one of the spending reports over the last 1 to 12 months
+func (u *virtualUser) readReport(ctx context.Context) {
+	now := time.Now().UTC()
+	query := "?from=" + now.AddDate(0, -1-rand.IntN(12), 0).Format(time.DateOnly) + "&to=" + now.Format(time.DateOnly)
+	switch rand.IntN(3) {
+	case 0:
+		_ = u.client.do(ctx, "report_spending", u.token, http.MethodGet, "/api/v1/reports/spending"+query, nil, nil)
+	case 1:
+		_ = u.client.do(ctx, "report_tags", u.token, http.MethodGet, "/api/v1/reports/tags"+query, nil, nil)
+	default:
+		_ = u.client.do(ctx, "report_weekly", u.token, http.MethodGet, "/api/v1/reports/spending/weekly"+query, nil, nil)
+	}
+}
+
+// syntheticDescriptions are the descriptions of the generated transactions
+var syntheticDescriptions = []string{"Supermercado", "Padaria", "Uber", "Farmácia", "Restaurante", "Posto de gasolina", "Streaming", "Academia"}
+
+// syntheticTransaction returns a transaction of the last year, mostly small paid expenses with a few incomes and unpaid bills
+func syntheticTransaction() ledger.AddTransactionRequest {
+	now := time.Now().UTC()
+	dueDate := now.Add(-time.Duration(rand.Int64N(int64(365 * 24 * time.Hour))))
+	req := ledger.AddTransactionRequest{
+		Type:        ledger.Expense,
+		Description: syntheticDescriptions[rand.IntN(len(syntheticDescriptions))],
+		Amount:      fmt.Sprintf("%d.%02d", 5+rand.IntN(300), rand.IntN(100)),
+		DueDate:     &dueDate,
+	}
+	if rand.IntN(10) == 0 {
+		req.Type = ledger.Income
+		req.Description = "Freelance"
+		req.Amount = fmt.Sprintf("%d.00", 500+rand.IntN(5000))
+	}
+	if rand.IntN(10) != 0 {
+		req.PaidAt = &dueDate
+	}
+	return req
+}
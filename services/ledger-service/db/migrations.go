@@ -0,0 +1,8 @@
+package db
+
+import "embed"
+
+// Migrations holds the goose SQL migrations, embedded so binaries can migrate without the source tree
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL,
+  kind VARCHAR(50) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  progress SMALLINT NOT NULL DEFAULT 0,
+  result JSONB,
+  error_code VARCHAR(100),
+  error_message TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  completed_at TIMESTAMPTZ,
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT chk_jobs_progress CHECK (progress BETWEEN 0 AND 100)
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_user_id ON jobs (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_jobs_user_id;
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
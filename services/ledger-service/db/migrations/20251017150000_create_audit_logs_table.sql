@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id UUID PRIMARY KEY,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  actor VARCHAR(64) NOT NULL,
+  action VARCHAR(100) NOT NULL,
+  resource_type VARCHAR(50) NOT NULL,
+  resource_id VARCHAR(64),
+  before_state JSONB,
+  after_state JSONB,
+  request_id VARCHAR(100),
+  metadata JSONB
+);
+
+-- Audit queries look up the history of a resource or of an actor
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs (resource_type, resource_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_occurred_at ON audit_logs (actor, occurred_at);
+
+-- Audit records are immutable: reject any change to rows already written
+CREATE OR REPLACE FUNCTION reject_audit_logs_change() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_logs_immutable
+  BEFORE UPDATE OR DELETE ON audit_logs
+  FOR EACH ROW EXECUTE FUNCTION reject_audit_logs_change();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_audit_logs_immutable ON audit_logs;
+DROP FUNCTION IF EXISTS reject_audit_logs_change();
+DROP INDEX IF EXISTS idx_audit_logs_actor_occurred_at;
+DROP INDEX IF EXISTS idx_audit_logs_resource;
+DROP TABLE IF EXISTS audit_logs;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS budgets (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL,
+  category_id UUID NOT NULL,
+  amount_in_cents BIGINT NOT NULL CHECK (amount_in_cents > 0),
+  currency VARCHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_categories FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE CASCADE,
+  -- A category has a single monthly budget per user
+  CONSTRAINT uq_budgets_user_id_category_id UNIQUE (user_id, category_id)
+);
+
+CREATE TABLE IF NOT EXISTS budget_alert_preferences (
+  user_id UUID PRIMARY KEY,
+  thresholds INTEGER[] NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Each threshold of a budget alerts at most once per month
+CREATE TABLE IF NOT EXISTS budget_alerts_sent (
+  budget_id UUID NOT NULL,
+  month DATE NOT NULL,
+  threshold INTEGER NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (budget_id, month, threshold),
+  CONSTRAINT fk_budgets FOREIGN KEY(budget_id) REFERENCES budgets(id) ON DELETE CASCADE
+);
+
+-- Budget checks sum the expenses of a category within a month
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id_category_id_due_date ON transactions (user_id, category_id, due_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_user_id_category_id_due_date;
+DROP TABLE IF EXISTS budget_alerts_sent;
+DROP TABLE IF EXISTS budget_alert_preferences;
+DROP TABLE IF EXISTS budgets;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'CHECKING'
+    CHECK (kind IN ('CHECKING', 'CREDIT_CARD')),
+  ADD COLUMN IF NOT EXISTS statement_closing_day SMALLINT
+    CHECK (statement_closing_day BETWEEN 1 AND 28),
+  ADD CONSTRAINT chk_accounts_credit_card_closing_day
+    CHECK ((kind = 'CREDIT_CARD') = (statement_closing_day IS NOT NULL));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts
+  DROP CONSTRAINT IF EXISTS chk_accounts_credit_card_closing_day,
+  DROP COLUMN IF EXISTS statement_closing_day,
+  DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A bulk recategorization that can still be undone, its ID is the undo token
+CREATE TABLE IF NOT EXISTS recategorization_batches (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  target_category_id UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_categories FOREIGN KEY(target_category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+-- transaction_id has no foreign key: saving an account rewrites its transaction rows, keeping their IDs
+CREATE TABLE IF NOT EXISTS recategorization_items (
+  batch_id UUID NOT NULL,
+  transaction_id UUID NOT NULL,
+  previous_category_id UUID,
+
+  PRIMARY KEY (batch_id, transaction_id),
+  CONSTRAINT fk_recategorization_batches FOREIGN KEY(batch_id) REFERENCES recategorization_batches(id) ON DELETE CASCADE,
+  CONSTRAINT fk_categories FOREIGN KEY(previous_category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recategorization_batches_user_id_expires_at ON recategorization_batches (user_id, expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_recategorization_batches_user_id_expires_at;
+DROP TABLE IF EXISTS recategorization_items;
+DROP TABLE IF EXISTS recategorization_batches;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transactions made in another currency than the account's keep the original amount and the conversion rate
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS original_amount_in_cents BIGINT,
+  ADD COLUMN IF NOT EXISTS original_currency CHAR(3),
+  ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20, 8) CHECK (exchange_rate > 0),
+  ADD CONSTRAINT chk_transactions_original_amount CHECK (
+    (original_amount_in_cents IS NULL AND original_currency IS NULL AND exchange_rate IS NULL)
+    OR (original_amount_in_cents IS NOT NULL AND original_currency IS NOT NULL AND exchange_rate IS NOT NULL)
+  );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS chk_transactions_original_amount,
+  DROP COLUMN IF EXISTS exchange_rate,
+  DROP COLUMN IF EXISTS original_currency,
+  DROP COLUMN IF EXISTS original_amount_in_cents;
+-- +goose StatementEnd
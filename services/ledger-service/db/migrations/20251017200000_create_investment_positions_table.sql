@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_kind_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_kind_check CHECK (kind IN ('CHECKING', 'CREDIT_CARD', 'INVESTMENT'));
+
+-- Positions are kept apart from the cash transactions of the investment accounts
+CREATE TABLE IF NOT EXISTS investment_positions (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  ticker VARCHAR(12) NOT NULL,
+  quantity BIGINT NOT NULL CHECK (quantity > 0), -- quantity has 8 implied decimal places
+  cost_basis_in_cents BIGINT NOT NULL CHECK (cost_basis_in_cents >= 0),
+  currency CHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT uq_investment_positions_account_id_ticker UNIQUE (account_id, ticker)
+);
+
+CREATE INDEX IF NOT EXISTS idx_investment_positions_user_id ON investment_positions (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_investment_positions_user_id;
+DROP TABLE IF EXISTS investment_positions;
+UPDATE accounts SET kind = 'CHECKING' WHERE kind = 'INVESTMENT';
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_kind_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_kind_check CHECK (kind IN ('CHECKING', 'CREDIT_CARD'));
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per user, day and currency, split by account group
+CREATE TABLE IF NOT EXISTS net_worth_snapshots (
+  user_id UUID NOT NULL,
+  snapshot_date DATE NOT NULL,
+  currency CHAR(3) NOT NULL,
+  cash_in_cents BIGINT NOT NULL,
+  credit_cards_in_cents BIGINT NOT NULL,
+  investments_in_cents BIGINT NOT NULL,
+  total_in_cents BIGINT NOT NULL,
+  taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (user_id, snapshot_date, currency),
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS net_worth_snapshots;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An uploaded export waiting for the user to confirm how its categories map to fintrack categories
+CREATE TABLE IF NOT EXISTS imports (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  format VARCHAR(20) NOT NULL,
+  status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'IMPORTING', 'IMPORTED')),
+  data JSONB NOT NULL, -- data holds the parsed rows of the file
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_imports_user_id_expires_at ON imports (user_id, expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_imports_user_id_expires_at;
+DROP TABLE IF EXISTS imports;
+-- +goose StatementEnd
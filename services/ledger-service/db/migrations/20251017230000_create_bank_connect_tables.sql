@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An Open Finance consent given by the user on an aggregator (e.g., a Pluggy item)
+CREATE TABLE IF NOT EXISTS bank_consents (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  provider VARCHAR(20) NOT NULL,
+  external_id VARCHAR(255) NOT NULL, -- external_id identifies the consent on the provider
+  status VARCHAR(20) NOT NULL CHECK (status IN ('ACTIVE', 'REVOKED', 'EXPIRED')),
+  expires_at TIMESTAMPTZ,
+  last_synced_at TIMESTAMPTZ,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT uq_bank_consents_user_id_provider_external_id UNIQUE (user_id, provider, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_consents_status ON bank_consents (status);
+
+-- A bank account of a consent synced into a ledger account, each ledger account mirrors at most one bank account
+CREATE TABLE IF NOT EXISTS bank_account_links (
+  id UUID PRIMARY KEY,
+  consent_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  remote_account_id VARCHAR(255) NOT NULL,
+  account_id UUID NOT NULL,
+  last_synced_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_bank_consents FOREIGN KEY(consent_id) REFERENCES bank_consents(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT uq_bank_account_links_consent_id_remote_account_id UNIQUE (consent_id, remote_account_id),
+  CONSTRAINT uq_bank_account_links_account_id UNIQUE (account_id)
+);
+
+-- The bank transactions already added to the ledger account of a link, so syncs never import them twice
+CREATE TABLE IF NOT EXISTS bank_synced_transactions (
+  link_id UUID NOT NULL,
+  remote_transaction_id VARCHAR(255) NOT NULL,
+  synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (link_id, remote_transaction_id),
+  CONSTRAINT fk_bank_account_links FOREIGN KEY(link_id) REFERENCES bank_account_links(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bank_synced_transactions;
+DROP TABLE IF EXISTS bank_account_links;
+DROP INDEX IF EXISTS idx_bank_consents_status;
+DROP TABLE IF EXISTS bank_consents;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- secret holds the AES-GCM encrypted credential of the consent on its provider (e.g., the Plaid access token)
+ALTER TABLE bank_consents ADD COLUMN IF NOT EXISTS secret BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bank_consents DROP COLUMN IF EXISTS secret;
+-- +goose StatementEnd
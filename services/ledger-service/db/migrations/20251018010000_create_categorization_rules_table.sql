@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A rule categorizing the synced bank transactions whose description contains its pattern
+CREATE TABLE IF NOT EXISTS categorization_rules (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  pattern VARCHAR(100) NOT NULL,
+  category_id UUID NOT NULL,
+  account_id UUID, -- account_id restricts the rule to an account, NULL applying it to every account
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_categories FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- The nil UUID stands for the rules of every account, which NULL would let repeat
+CREATE UNIQUE INDEX IF NOT EXISTS uq_categorization_rules_user_id_pattern_account_id
+  ON categorization_rules (user_id, LOWER(pattern), COALESCE(account_id, '00000000-0000-0000-0000-000000000000'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_categorization_rules_user_id_pattern_account_id;
+DROP TABLE IF EXISTS categorization_rules;
+-- +goose StatementEnd
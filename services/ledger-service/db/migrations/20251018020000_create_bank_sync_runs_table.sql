@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The log of every sync of a consent, with what it did with the bank transactions
+CREATE TABLE IF NOT EXISTS bank_sync_runs (
+  id UUID PRIMARY KEY,
+  consent_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('MANUAL', 'SCHEDULED')),
+  status VARCHAR(20) NOT NULL CHECK (status IN ('RUNNING', 'SUCCEEDED', 'FAILED')),
+  fetched INTEGER NOT NULL DEFAULT 0,
+  imported INTEGER NOT NULL DEFAULT 0,
+  matched INTEGER NOT NULL DEFAULT 0,
+  duplicates INTEGER NOT NULL DEFAULT 0,
+  categorized INTEGER NOT NULL DEFAULT 0,
+  adjusted INTEGER NOT NULL DEFAULT 0,
+  error TEXT,
+  started_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ,
+
+  CONSTRAINT fk_bank_consents FOREIGN KEY(consent_id) REFERENCES bank_consents(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_sync_runs_consent_id_started_at ON bank_sync_runs (consent_id, started_at DESC);
+
+-- A consent syncs one run at a time
+CREATE UNIQUE INDEX IF NOT EXISTS uq_bank_sync_runs_consent_id_running ON bank_sync_runs (consent_id) WHERE status = 'RUNNING';
+
+-- ledger_transaction_id is the ledger transaction the synced transaction was imported as or matched to
+-- It has no foreign key: saving an account rewrites its transaction rows, keeping their IDs
+ALTER TABLE bank_synced_transactions ADD COLUMN IF NOT EXISTS ledger_transaction_id UUID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bank_synced_transactions DROP COLUMN IF EXISTS ledger_transaction_id;
+DROP INDEX IF EXISTS uq_bank_sync_runs_consent_id_running;
+DROP INDEX IF EXISTS idx_bank_sync_runs_consent_id_started_at;
+DROP TABLE IF EXISTS bank_sync_runs;
+-- +goose StatementEnd
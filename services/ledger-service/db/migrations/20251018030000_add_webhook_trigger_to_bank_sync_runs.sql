@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE bank_sync_runs DROP CONSTRAINT IF EXISTS bank_sync_runs_trigger_check;
+ALTER TABLE bank_sync_runs ADD CONSTRAINT bank_sync_runs_trigger_check CHECK (trigger IN ('MANUAL', 'SCHEDULED', 'WEBHOOK'));
+
+-- The webhooks identify the consents by their ID on the provider
+CREATE INDEX IF NOT EXISTS idx_bank_consents_provider_external_id ON bank_consents (provider, external_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_bank_consents_provider_external_id;
+DELETE FROM bank_sync_runs WHERE trigger = 'WEBHOOK';
+ALTER TABLE bank_sync_runs DROP CONSTRAINT IF EXISTS bank_sync_runs_trigger_check;
+ALTER TABLE bank_sync_runs ADD CONSTRAINT bank_sync_runs_trigger_check CHECK (trigger IN ('MANUAL', 'SCHEDULED'));
+-- +goose StatementEnd
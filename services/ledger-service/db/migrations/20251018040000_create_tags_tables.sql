@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A label the user puts on transactions across categories (e.g., "vacation"), names are stored lowercased
+CREATE TABLE IF NOT EXISTS tags (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name VARCHAR(30) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT uq_tags_user_id_name UNIQUE (user_id, name)
+);
+
+-- The links are rewritten with the transactions of the account on every save, deleting a transaction drops its links
+CREATE TABLE IF NOT EXISTS transaction_tags (
+  transaction_id UUID NOT NULL,
+  tag_id UUID NOT NULL,
+
+  PRIMARY KEY (transaction_id, tag_id),
+  CONSTRAINT fk_transactions FOREIGN KEY(transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+  CONSTRAINT fk_tags FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Reports aggregate by tag, reaching the transactions from the tag side
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag_id ON transaction_tags (tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transaction_tags_tag_id;
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The amount a user allows spending each month with a payee, matched against the transaction descriptions
+CREATE TABLE IF NOT EXISTS payee_limits (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  payee VARCHAR(60) NOT NULL,
+  amount_in_cents BIGINT NOT NULL CHECK (amount_in_cents > 0),
+  currency VARCHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- A payee has a single limit per currency
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payee_limits_user_id_payee_currency ON payee_limits (user_id, LOWER(payee), currency);
+
+-- Each limit alerts at most once per month
+CREATE TABLE IF NOT EXISTS payee_limit_alerts_sent (
+  payee_limit_id UUID NOT NULL,
+  month DATE NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (payee_limit_id, month),
+  CONSTRAINT fk_payee_limits FOREIGN KEY(payee_limit_id) REFERENCES payee_limits(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payee_limit_alerts_sent;
+DROP INDEX IF EXISTS uq_payee_limits_user_id_payee_currency;
+DROP TABLE IF EXISTS payee_limits;
+-- +goose StatementEnd
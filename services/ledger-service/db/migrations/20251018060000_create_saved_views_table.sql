@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Named transaction filters the clients apply, criteria holds the filters as validated by the API
+CREATE TABLE IF NOT EXISTS saved_views (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name VARCHAR(60) NOT NULL,
+  criteria JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- View names are unique per user, ignoring case
+CREATE UNIQUE INDEX IF NOT EXISTS uq_saved_views_user_id_name ON saved_views (user_id, LOWER(name));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_saved_views_user_id_name;
+DROP TABLE IF EXISTS saved_views;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Preferences followed by several modules, a user without a row uses the defaults
+-- The financial month starts on month_start_day, or on the nth weekday when month_start_business_day is set, 0 meaning unset
+CREATE TABLE IF NOT EXISTS user_settings (
+  user_id UUID PRIMARY KEY,
+  month_start_day SMALLINT NOT NULL DEFAULT 0 CHECK (month_start_day BETWEEN 0 AND 28),
+  month_start_business_day SMALLINT NOT NULL DEFAULT 0 CHECK (month_start_business_day BETWEEN 0 AND 10),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT chk_user_settings_month_start CHECK (month_start_day = 0 OR month_start_business_day = 0)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_settings;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Users receive the weekly summary email unless they opt out
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS weekly_summary_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Each week is summarized at most once per user, week being its first day
+CREATE TABLE IF NOT EXISTS weekly_summaries_sent (
+  user_id UUID NOT NULL,
+  week DATE NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (user_id, week),
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS weekly_summaries_sent;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS weekly_summary_enabled;
+-- +goose StatementEnd
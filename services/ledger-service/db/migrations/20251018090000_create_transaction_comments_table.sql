@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Append-only threads on transactions, written by the members of the account
+-- transaction_id has no foreign key: the ledger rewrites the transactions of an account on every save, which would drop
+-- the threads, so the comments of a deleted transaction stay until its account is deleted and are never listed
+CREATE TABLE IF NOT EXISTS transaction_comments (
+  id UUID PRIMARY KEY,
+  account_id UUID NOT NULL,
+  transaction_id UUID NOT NULL,
+  author_id UUID NOT NULL,
+  body VARCHAR(1000) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Threads are read whole, oldest first
+CREATE INDEX IF NOT EXISTS idx_transaction_comments_transaction_id ON transaction_comments (transaction_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transaction_comments_transaction_id;
+DROP TABLE IF EXISTS transaction_comments;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An offer to hand an account over to another user, the account only changes owner once the recipient accepts it
+CREATE TABLE IF NOT EXISTS account_transfers (
+  id UUID PRIMARY KEY,
+  account_id UUID NOT NULL,
+  from_user_id UUID NOT NULL,
+  to_user_id UUID NOT NULL,
+  status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'ACCEPTED', 'DECLINED', 'CANCELLED')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at TIMESTAMPTZ, -- resolved_at is set once the transfer leaves PENDING
+
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_from_users FOREIGN KEY(from_user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_to_users FOREIGN KEY(to_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- An account is offered to a single user at a time
+CREATE UNIQUE INDEX IF NOT EXISTS uq_account_transfers_account_id_pending ON account_transfers (account_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_account_transfers_from_user_id ON account_transfers (from_user_id);
+CREATE INDEX IF NOT EXISTS idx_account_transfers_to_user_id ON account_transfers (to_user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_account_transfers_to_user_id;
+DROP INDEX IF EXISTS idx_account_transfers_from_user_id;
+DROP INDEX IF EXISTS uq_account_transfers_account_id_pending;
+DROP TABLE IF EXISTS account_transfers;
+-- +goose StatementEnd
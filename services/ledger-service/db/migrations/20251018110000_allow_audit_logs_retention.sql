@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- audit_logs stays append-only, except for the retention policy deleting the expired records in a transaction
+-- that sets fintrack.audit_logs_retention
+CREATE OR REPLACE FUNCTION reject_audit_logs_change() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'DELETE' AND current_setting('fintrack.audit_logs_retention', true) = 'on' THEN
+    RETURN OLD;
+  END IF;
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION reject_audit_logs_change() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
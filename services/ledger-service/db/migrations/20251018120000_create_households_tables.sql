@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A group of users sharing some of their accounts and budgets, each member seeing what their permissions allow
+CREATE TABLE IF NOT EXISTS households (
+  id UUID PRIMARY KEY,
+  name VARCHAR(60) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+  household_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  role VARCHAR(20) NOT NULL CHECK (role IN ('ADMIN', 'MEMBER')),
+  permissions TEXT[] NOT NULL DEFAULT '{}', -- permissions lists ACCOUNTS, BUDGETS and REPORTS, admins see everything
+  invited_by UUID, -- invited_by is NULL for the creator of the household
+  invited_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  joined_at TIMESTAMPTZ, -- joined_at is NULL while the invitation is pending
+
+  PRIMARY KEY (household_id, user_id),
+  CONSTRAINT fk_households FOREIGN KEY(household_id) REFERENCES households(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_inviters FOREIGN KEY(invited_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS household_accounts (
+  household_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  shared_by UUID NOT NULL,
+  shared_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (household_id, account_id),
+  CONSTRAINT fk_households FOREIGN KEY(household_id) REFERENCES households(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(shared_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS household_budgets (
+  household_id UUID NOT NULL,
+  budget_id UUID NOT NULL,
+  shared_by UUID NOT NULL,
+  shared_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (household_id, budget_id),
+  CONSTRAINT fk_households FOREIGN KEY(household_id) REFERENCES households(id) ON DELETE CASCADE,
+  CONSTRAINT fk_budgets FOREIGN KEY(budget_id) REFERENCES budgets(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(shared_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_members_user_id ON household_members (user_id);
+CREATE INDEX IF NOT EXISTS idx_household_accounts_account_id ON household_accounts (account_id);
+CREATE INDEX IF NOT EXISTS idx_household_budgets_budget_id ON household_budgets (budget_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_household_budgets_budget_id;
+DROP INDEX IF EXISTS idx_household_accounts_account_id;
+DROP INDEX IF EXISTS idx_household_members_user_id;
+DROP TABLE IF EXISTS household_budgets;
+DROP TABLE IF EXISTS household_accounts;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Events processed by each consumer, recorded in the transaction of the handler so a redelivered event is skipped
+-- Rows are keyed by consumer since several handlers may process the same event
+CREATE TABLE IF NOT EXISTS inbox (
+  consumer VARCHAR(100) NOT NULL,
+  event_id UUID NOT NULL,
+  event_type VARCHAR(100) NOT NULL,
+  event_version INT NOT NULL,
+  processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (consumer, event_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS inbox;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- State of the multi-step flows spanning services (e.g., deleting an account), saved after every step
+-- locked_until leases a saga to the instance advancing it, so a crashed instance frees it once the lease expires
+CREATE TABLE IF NOT EXISTS sagas (
+  id UUID PRIMARY KEY,
+  name VARCHAR(60) NOT NULL,
+  correlation_id UUID NOT NULL,
+  status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'compensating', 'completed', 'compensated', 'failed')),
+  step INT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  data JSONB NOT NULL,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL,
+  locked_until TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  completed_at TIMESTAMPTZ
+);
+
+-- A single unfinished saga per flow and resource
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sagas_name_correlation_id_active ON sagas (name, correlation_id)
+  WHERE status IN ('running', 'compensating');
+
+-- The scheduler resumes the unfinished sagas whose next attempt is due
+CREATE INDEX IF NOT EXISTS idx_sagas_next_attempt_at ON sagas (next_attempt_at)
+  WHERE status IN ('running', 'compensating');
+
+-- The latest saga of a resource is shown to its user
+CREATE INDEX IF NOT EXISTS idx_sagas_name_correlation_id ON sagas (name, correlation_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sagas_name_correlation_id;
+DROP INDEX IF EXISTS idx_sagas_next_attempt_at;
+DROP INDEX IF EXISTS uq_sagas_name_correlation_id_active;
+DROP TABLE IF EXISTS sagas;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Events of the ledger aggregates, written in the transaction of the change they describe and published by the outbox relay
+-- The relay tails the table by position, reading a few seconds behind so rows committed late are not skipped
+CREATE TABLE IF NOT EXISTS outbox (
+  position BIGSERIAL PRIMARY KEY,
+  event_id UUID NOT NULL UNIQUE,
+  aggregate_type VARCHAR(50) NOT NULL,
+  aggregate_id UUID NOT NULL,
+  event_type VARCHAR(100) NOT NULL,
+  event_version INT NOT NULL,
+  envelope JSONB NOT NULL,
+  recorded_at TIMESTAMPTZ NOT NULL DEFAULT CLOCK_TIMESTAMP()
+);
+
+-- Cursor of the last message the relay published from each outbox, moved back to replay events
+CREATE TABLE IF NOT EXISTS outbox_relay_offsets (
+  source VARCHAR(50) PRIMARY KEY,
+  cursor TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox_relay_offsets;
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
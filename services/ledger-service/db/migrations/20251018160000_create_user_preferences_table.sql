@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Display preferences of the users, a user without a row uses the defaults
+-- An empty locale follows the language of the identity account
+CREATE TABLE IF NOT EXISTS user_preferences (
+  user_id UUID PRIMARY KEY,
+  base_currency VARCHAR(3) NOT NULL DEFAULT 'BRL',
+  locale VARCHAR(35) NOT NULL DEFAULT '',
+  date_format VARCHAR(10) NOT NULL DEFAULT 'DD/MM/YYYY',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_preferences;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Data keys encrypting the sensitive fields of the users, wrapped by the master key of the KMS
+-- Deleting a user deletes their key, which leaves their encrypted fields unreadable in the backups as well
+CREATE TABLE IF NOT EXISTS user_data_keys (
+  user_id UUID PRIMARY KEY,
+  wrapped_key BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- The metadata of a transaction holds its PIX identifiers and is encrypted once a master key is configured,
+-- so it is stored as text rather than JSON
+ALTER TABLE transactions ALTER COLUMN metadata TYPE TEXT USING metadata::text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Encrypted metadata does not convert back to JSON and is dropped
+ALTER TABLE transactions ALTER COLUMN metadata TYPE JSONB USING CASE WHEN metadata LIKE 'enc:%' THEN NULL ELSE metadata::jsonb END;
+DROP TABLE IF EXISTS user_data_keys;
+-- +goose StatementEnd
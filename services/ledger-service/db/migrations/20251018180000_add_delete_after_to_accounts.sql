@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- When the user asked to delete an account, it is deleted for good once delete_after passed
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS delete_after TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_delete_after ON accounts (delete_after) WHERE delete_after IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_accounts_delete_after;
+ALTER TABLE accounts DROP COLUMN IF EXISTS delete_after;
+-- +goose StatementEnd
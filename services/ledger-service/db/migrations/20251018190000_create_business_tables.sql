@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The business profile of the users running a small business, who tag their transactions with cost centers and suppliers
+CREATE TABLE IF NOT EXISTS business_profiles (
+  user_id UUID PRIMARY KEY,
+  legal_name VARCHAR(120) NOT NULL,
+  cnpj CHAR(14), -- cnpj holds the 14 digits of the company document, NULL for the businesses without one (e.g., freelancers)
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS cost_centers (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name VARCHAR(60) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Cost center names are unique per user, ignoring case
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cost_centers_user_id_name ON cost_centers (user_id, LOWER(name));
+
+-- The cost center and supplier of the business transactions
+-- transaction_id has no foreign key: the ledger rewrites the transactions of an account on every save, which would drop
+-- the details, so the details of a deleted transaction stay until its account is deleted and are never reported
+CREATE TABLE IF NOT EXISTS transaction_business_details (
+  transaction_id UUID PRIMARY KEY,
+  account_id UUID NOT NULL,
+  cost_center_id UUID,
+  supplier_cnpj CHAR(14),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_cost_centers FOREIGN KEY(cost_center_id) REFERENCES cost_centers(id) ON DELETE SET NULL
+);
+
+-- The cost center report groups the details of the accounts of a user by cost center
+CREATE INDEX IF NOT EXISTS idx_transaction_business_details_cost_center_id ON transaction_business_details (cost_center_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transaction_business_details_cost_center_id;
+DROP TABLE IF EXISTS transaction_business_details;
+DROP INDEX IF EXISTS uq_cost_centers_user_id_name;
+DROP TABLE IF EXISTS cost_centers;
+DROP TABLE IF EXISTS business_profiles;
+-- +goose StatementEnd
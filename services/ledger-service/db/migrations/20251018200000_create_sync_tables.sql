@@ -0,0 +1,108 @@
+-- +goose Up
+-- +goose StatementBegin
+-- version is bumped on every change of the account or of its transactions, the offline clients sending the version
+-- they last saw so the changes made meanwhile are detected as conflicts
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_account_version() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.version := OLD.version + 1;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_accounts_bump_version
+  BEFORE UPDATE ON accounts
+  FOR EACH ROW EXECUTE FUNCTION bump_account_version();
+
+-- The writers of transactions other than the ledger (transfers, recategorization, restores...) touch the account so
+-- its version is bumped too. The ledger already updates the account on every save and sets fintrack.ledger_save
+-- to skip them, its version returned by the save being the final one
+CREATE OR REPLACE FUNCTION touch_transactions_accounts() RETURNS TRIGGER AS $$
+BEGIN
+  IF current_setting('fintrack.ledger_save', true) = 'on' THEN
+    RETURN NULL;
+  END IF;
+  IF TG_OP = 'DELETE' THEN
+    UPDATE accounts SET updated_at = NOW() WHERE id IN (SELECT DISTINCT account_id FROM old_transactions);
+  ELSE
+    UPDATE accounts SET updated_at = NOW() WHERE id IN (SELECT DISTINCT account_id FROM new_transactions);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_transactions_touch_accounts_insert
+  AFTER INSERT ON transactions REFERENCING NEW TABLE AS new_transactions
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_transactions_accounts();
+
+CREATE TRIGGER trg_transactions_touch_accounts_update
+  AFTER UPDATE ON transactions REFERENCING NEW TABLE AS new_transactions
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_transactions_accounts();
+
+CREATE TRIGGER trg_transactions_touch_accounts_delete
+  AFTER DELETE ON transactions REFERENCING OLD TABLE AS old_transactions
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_transactions_accounts();
+
+-- The last change of each account of a user, at an ever growing position the sync cursors point to
+-- A deleted account keeps a tombstone so the clients forget it too, unless its user is being deleted
+CREATE SEQUENCE IF NOT EXISTS sync_position_seq;
+
+CREATE TABLE IF NOT EXISTS sync_changes (
+  user_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  position BIGINT NOT NULL,
+  version BIGINT NOT NULL,
+  deleted BOOLEAN NOT NULL DEFAULT FALSE,
+  recorded_at TIMESTAMPTZ NOT NULL DEFAULT CLOCK_TIMESTAMP(),
+
+  PRIMARY KEY (user_id, account_id),
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- The sync endpoint reads the changes of a user after a cursor
+CREATE INDEX IF NOT EXISTS idx_sync_changes_user_id_position ON sync_changes (user_id, position);
+
+CREATE OR REPLACE FUNCTION record_sync_change() RETURNS TRIGGER AS $$
+BEGIN
+  IF (TG_OP = 'DELETE' OR (TG_OP = 'UPDATE' AND OLD.user_id <> NEW.user_id))
+    AND EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id) THEN
+    INSERT INTO sync_changes (user_id, account_id, position, version, deleted)
+    VALUES (OLD.user_id, OLD.id, nextval('sync_position_seq'), OLD.version, TRUE)
+    ON CONFLICT (user_id, account_id) DO UPDATE SET
+      position = EXCLUDED.position, version = EXCLUDED.version, deleted = TRUE, recorded_at = CLOCK_TIMESTAMP();
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    INSERT INTO sync_changes (user_id, account_id, position, version, deleted)
+    VALUES (NEW.user_id, NEW.id, nextval('sync_position_seq'), NEW.version, FALSE)
+    ON CONFLICT (user_id, account_id) DO UPDATE SET
+      position = EXCLUDED.position, version = EXCLUDED.version, deleted = FALSE, recorded_at = CLOCK_TIMESTAMP();
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_accounts_record_sync_change
+  AFTER INSERT OR UPDATE OR DELETE ON accounts
+  FOR EACH ROW EXECUTE FUNCTION record_sync_change();
+
+INSERT INTO sync_changes (user_id, account_id, position, version)
+SELECT user_id, id, nextval('sync_position_seq'), version FROM accounts
+ON CONFLICT (user_id, account_id) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_accounts_record_sync_change ON accounts;
+DROP FUNCTION IF EXISTS record_sync_change();
+DROP INDEX IF EXISTS idx_sync_changes_user_id_position;
+DROP TABLE IF EXISTS sync_changes;
+DROP SEQUENCE IF EXISTS sync_position_seq;
+DROP TRIGGER IF EXISTS trg_transactions_touch_accounts_delete ON transactions;
+DROP TRIGGER IF EXISTS trg_transactions_touch_accounts_update ON transactions;
+DROP TRIGGER IF EXISTS trg_transactions_touch_accounts_insert ON transactions;
+DROP FUNCTION IF EXISTS touch_transactions_accounts();
+DROP TRIGGER IF EXISTS trg_accounts_bump_version ON accounts;
+DROP FUNCTION IF EXISTS bump_account_version();
+ALTER TABLE accounts DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
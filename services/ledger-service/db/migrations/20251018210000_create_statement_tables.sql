@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Users schedule at most one monthly statement email, covering the accounts they selected
+CREATE TABLE IF NOT EXISTS statement_schedules (
+  user_id UUID PRIMARY KEY,
+  format VARCHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT chk_statement_format CHECK (format IN ('pdf', 'csv'))
+);
+
+-- A deleted account leaves the schedules covering it
+CREATE TABLE IF NOT EXISTS statement_schedule_accounts (
+  user_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  position SMALLINT NOT NULL,
+
+  PRIMARY KEY (user_id, account_id),
+  CONSTRAINT fk_statement_schedules FOREIGN KEY(user_id) REFERENCES statement_schedules(user_id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Each financial month is sent at most once per user, period_start being its first day
+CREATE TABLE IF NOT EXISTS statements_sent (
+  user_id UUID NOT NULL,
+  period_start DATE NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (user_id, period_start),
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS statements_sent;
+DROP TABLE IF EXISTS statement_schedule_accounts;
+DROP TABLE IF EXISTS statement_schedules;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Receipts and other files attached to transactions, the files themselves being kept in the attachment storage
+-- No column has a foreign key: the ledger rewrites the transactions of an account on every save, and a cascade would
+-- drop the rows of files still in the storage. The garbage collection removes the files of deleted transactions instead
+CREATE TABLE IF NOT EXISTS attachments (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  transaction_id UUID NOT NULL,
+  filename VARCHAR(255) NOT NULL,
+  content_type VARCHAR(100) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  storage_key VARCHAR(255) NOT NULL,
+  status VARCHAR(10) NOT NULL,
+  orphaned_at TIMESTAMPTZ, -- orphaned_at is when the transaction was found deleted, cleared if it is restored
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT chk_attachment_status CHECK (status IN ('PENDING', 'STORED')),
+  CONSTRAINT chk_attachment_size CHECK (size_bytes > 0)
+);
+
+-- The files of a transaction are listed oldest first, and the quota sums the files of each user
+CREATE INDEX IF NOT EXISTS idx_attachments_transaction_id ON attachments (transaction_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_attachments_user_id;
+DROP INDEX IF EXISTS idx_attachments_transaction_id;
+DROP TABLE IF EXISTS attachments;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_kind_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_kind_check CHECK (kind IN ('CHECKING', 'CREDIT_CARD', 'INVESTMENT', 'SAVINGS'));
+
+-- The rate each savings account earns, used to simulate its interest
+CREATE TABLE IF NOT EXISTS account_yields (
+  account_id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  yield_type VARCHAR(20) NOT NULL,
+  rate BIGINT NOT NULL CHECK (rate > 0), -- rate is a percentage with 4 implied decimal places
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT chk_account_yields_type CHECK (yield_type IN ('CDI_PERCENT', 'FIXED_RATE'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_yields;
+UPDATE accounts SET kind = 'CHECKING' WHERE kind = 'SAVINGS';
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_kind_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_kind_check CHECK (kind IN ('CHECKING', 'CREDIT_CARD', 'INVESTMENT'));
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A transaction a household member proposes on an account shared by another member, added to the account only once its owner approves it
+CREATE TABLE IF NOT EXISTS household_transaction_proposals (
+  id UUID PRIMARY KEY,
+  household_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  owner_id UUID NOT NULL, -- owner_id is the member who shared the account, the only one deciding on the proposal
+  proposed_by UUID NOT NULL,
+  type VARCHAR(20) NOT NULL CHECK (type IN ('INCOME', 'EXPENSE')),
+  description VARCHAR(100) NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount <> 0), -- amount in minor units of currency, negative for expenses
+  currency VARCHAR(3) NOT NULL,
+  due_date TIMESTAMPTZ NOT NULL,
+  status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'CANCELLED')),
+  rejection_reason VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at TIMESTAMPTZ, -- resolved_at is set once the proposal leaves PENDING
+
+  CONSTRAINT fk_households FOREIGN KEY(household_id) REFERENCES households(id) ON DELETE CASCADE,
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_owners FOREIGN KEY(owner_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_proposers FOREIGN KEY(proposed_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_transaction_proposals_household_id ON household_transaction_proposals (household_id, status);
+CREATE INDEX IF NOT EXISTS idx_household_transaction_proposals_account_id ON household_transaction_proposals (account_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_household_transaction_proposals_account_id;
+DROP INDEX IF EXISTS idx_household_transaction_proposals_household_id;
+DROP TABLE IF EXISTS household_transaction_proposals;
+UPDATE household_members SET permissions = array_remove(permissions, 'PROPOSE_TRANSACTIONS');
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Users opt in before their ledger changes are exported to the data warehouse
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS analytics_sharing_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Each batch of outbox rows read by the warehouse exporter, the last position of the latest batch being where the next export resumes
+-- A batch without a consenting user writes no file, object_key staying NULL
+CREATE TABLE IF NOT EXISTS warehouse_exports (
+  id UUID PRIMARY KEY,
+  object_key VARCHAR(255),
+  first_position BIGINT NOT NULL,
+  last_position BIGINT NOT NULL,
+  records INT NOT NULL CHECK (records >= 0), -- records counts the rows written to the file, the changes of the users who did not opt in being left out
+  exported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT chk_warehouse_exports_positions CHECK (first_position <= last_position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_exports_last_position ON warehouse_exports (last_position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_warehouse_exports_last_position;
+DROP TABLE IF EXISTS warehouse_exports;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS analytics_sharing_enabled;
+-- +goose StatementEnd
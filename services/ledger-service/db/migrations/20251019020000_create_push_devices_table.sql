@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS push_devices (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL,
+  platform VARCHAR(10) NOT NULL,
+  token TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  -- A token addresses a single app install, moving to whoever registers it last
+  CONSTRAINT uq_push_devices_token UNIQUE (token)
+);
+
+-- Push deliveries fetch every device of a user
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_push_devices_user_id;
+DROP TABLE IF EXISTS push_devices;
+-- +goose StatementEnd
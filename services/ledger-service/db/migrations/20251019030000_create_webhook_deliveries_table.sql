@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL,
+  category VARCHAR(30) NOT NULL,
+  url TEXT NOT NULL, -- url is where the last attempt was posted, replays following the URL the user set since
+  payload JSONB NOT NULL,
+  status VARCHAR(10) NOT NULL, -- status is delivered or failed, as of the last attempt
+  attempts INT NOT NULL DEFAULT 1,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  delivered_at TIMESTAMPTZ,
+
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- The failed deliveries are listed and replayed by creation time
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_failed ON webhook_deliveries (user_id, created_at) WHERE status = 'failed';
+-- The last success of a webhook tells how long it has been failing
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_delivered ON webhook_deliveries (user_id, delivered_at) WHERE status = 'delivered';
+
+-- webhook_disabled_at is set when the webhook failed for too long and was removed from every category
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS webhook_disabled_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS webhook_disabled_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_delivered;
+DROP INDEX IF EXISTS idx_webhook_deliveries_failed;
+DROP TABLE IF EXISTS webhook_deliveries;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- archived_transactions keeps the settled history an account rolled into an opening balance, out of the transactions
+-- loaded with the account on every change
+CREATE TABLE IF NOT EXISTS archived_transactions (
+  id UUID PRIMARY KEY,
+  account_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  category_id UUID,
+  type transaction_type NOT NULL,
+  description VARCHAR(100) NOT NULL,
+  observation TEXT,
+  amount_in_cents BIGINT NOT NULL,
+  due_date TIMESTAMPTZ NOT NULL,
+  paid_at TIMESTAMPTZ NOT NULL,
+  original_amount_in_cents BIGINT,
+  original_currency CHAR(3),
+  exchange_rate NUMERIC(20, 8),
+  metadata TEXT, -- metadata is sealed like the one of the transactions, so it is stored as text rather than JSON
+  tags TEXT[] NOT NULL DEFAULT '{}', -- tags are kept by name, the links to the tags being dropped with the transaction
+  archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_accounts FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_categories FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_archived_transactions_account_id_due_date ON archived_transactions (account_id, due_date);
+-- The reports and backups read the archived history of a user across their accounts
+CREATE INDEX IF NOT EXISTS idx_archived_transactions_user_id_due_date ON archived_transactions (user_id, due_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_archived_transactions_user_id_due_date;
+DROP INDEX IF EXISTS idx_archived_transactions_account_id_due_date;
+DROP TABLE IF EXISTS archived_transactions;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The day the weeks of the reports start on ('sunday' or 'monday'), 'locale' following the conventions of the locale
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS week_start VARCHAR(10) NOT NULL DEFAULT 'locale';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS week_start;
+-- +goose StatementEnd
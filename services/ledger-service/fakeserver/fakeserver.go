@@ -0,0 +1,203 @@
+// Package fakeserver serves the ledger API of fintrack from memory, for the contract tests of the SDK and the mobile
+// clients: the accounts and transactions live in an in-memory repository, the clock stands still until moved, the IDs
+// are handed out in sequence and the users are authenticated by canned tokens, so no database or identity service runs
+//
+// The routes, bodies and errors are the ones of the ledger module, served by its own handlers behind the error handler
+// and route policies of the API, while the modules working straight on Postgres (reports, budgets, imports...) are not
+// served, like in demo mode
+//
+// Usage from a Go test:
+//
+//	fake := fakeserver.New(fakeserver.Options{})
+//	srv := httptest.NewServer(fake)
+//	defer srv.Close()
+//	client := sdk.New(srv.URL, fakeserver.DefaultToken)
+//
+// Clients in other languages run the cmd/fakeserver binary, resetting the data and moving the clock between their
+// cases through the control routes under /_fake
+package fakeserver
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errreport"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/pkg/requestid"
+	"github.com/Guizzs26/fintrack/pkg/validatorx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/authz"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/translations"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// apiLatestVersion is the latest version of the request bodies, kept in step with the one of cmd/api
+const apiLatestVersion = 2
+
+// DefaultToken authenticates DefaultUserID, the user of the servers created without users
+const DefaultToken = "fake-token"
+
+var (
+	// DefaultUserID is the user authenticated by DefaultToken
+	DefaultUserID = uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	// DefaultNow is the instant the clock starts at when Options.Now is zero
+	DefaultNow = time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+)
+
+// User is a user of the fake server and the canned token authenticating them
+type User struct {
+	Token string
+	ID    uuid.UUID
+	// Unverified denies the user the write routes, the fake server requiring verified emails like the API configured to
+	Unverified bool
+}
+
+// Options configures a fake server, the zero value serving DefaultUserID at DefaultNow
+type Options struct {
+	Now    time.Time    // Now is the instant the clock starts at and goes back to on Reset
+	Users  []User       // Users are the users authenticated by the server, DefaultToken alone when empty
+	Logger *slog.Logger // Logger receives the logs of the server, discarded when nil
+}
+
+// Server is a fake of the ledger API, an http.Handler safe for concurrent use
+type Server struct {
+	opts   Options
+	clock  *clock.ManualClock
+	users  map[string]User
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	handler *echo.Echo
+}
+
+// New creates a fake server with no data
+func New(opts Options) *Server {
+	if opts.Now.IsZero() {
+		opts.Now = DefaultNow
+	}
+	if len(opts.Users) == 0 {
+		opts.Users = []User{{Token: DefaultToken, ID: DefaultUserID}}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	s := &Server{
+		opts:   opts,
+		clock:  clock.NewManualClock(opts.Now),
+		users:  make(map[string]User, len(opts.Users)),
+		logger: opts.Logger,
+	}
+	for _, u := range opts.Users {
+		s.users[u.Token] = u
+	}
+	s.handler = s.build()
+	return s
+}
+
+// ServeHTTP serves the request from the current data
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	handler := s.handler
+	s.mu.RUnlock()
+	handler.ServeHTTP(w, r)
+}
+
+// Clock returns the clock of the server, for the tests moving the time (e.g., past the due date of a transaction)
+func (s *Server) Clock() *clock.ManualClock {
+	return s.clock
+}
+
+// Reset drops every account and transaction, moves the clock back to Options.Now and restarts the sequence of IDs,
+// so each case of a suite starts from the same state
+func (s *Server) Reset() {
+	s.clock.Set(s.opts.Now)
+	handler := s.build()
+
+	s.mu.Lock()
+	s.handler = handler
+	s.mu.Unlock()
+}
+
+// build wires the ledger module on an empty in-memory repository
+func (s *Server) build() *echo.Echo {
+	cfg := &config.Config{}
+	cfg.Ledger.AccountDeletionGrace = 30 * 24 * time.Hour
+	cfg.Ledger.UndoWindow = 5 * time.Minute
+	cfg.Ledger.MaxActiveTransactions = 5000
+
+	deps := module.Deps{
+		Config:  cfg,
+		Logger:  s.logger,
+		Audit:   audit.NewLogger(discardSink{}, s.clock),
+		Metrics: metrics.NewRegistry(),
+		Periods: module.CalendarPeriods{},
+		Display: module.DefaultPreferences{},
+		IDs:     &sequentialIDs{},
+		Clock:   s.clock,
+	}
+	ledgerModule := ledger.NewModuleWithRepository(deps, ledger.NewInMemoryAccountRepository(), nil)
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Binder = httpx.NewVersionedBinder(apiLatestVersion)
+	e.Validator = validatorx.NewValidator(s.clock)
+	e.HTTPErrorHandler = httpx.ErrorHandler(errreport.Nop{})
+
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: requestid.New}))
+	e.Use(s.loggerMiddleware())
+	e.Use(httpx.LanguageMiddleware(httpx.LanguageConfig{Catalog: translations.Errors}))
+	e.Use(httpx.VersionMiddleware(httpx.VersionConfig{Latest: apiLatestVersion}))
+	e.Use(middleware.Recover())
+	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogStatus: true,
+		LogMethod: true,
+		LogURI:    true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			s.logger.Info("HTTP_REQUEST", slog.String("method", v.Method), slog.String("uri", v.URI), slog.Int("status", v.Status))
+			return nil
+		},
+	}))
+
+	s.registerControlRoutes(e.Group(ControlPrefix))
+
+	apiRouteGroup := e.Group("/api/v1", s.authMiddleware(), authz.RoutePolicies(true).Middleware())
+	ledgerModule.RegisterRoutes(apiRouteGroup)
+	return e
+}
+
+// discardSink drops the audit records, the fake server having no one to read them
+type discardSink struct{}
+
+func (discardSink) Write(context.Context, audit.Record) error {
+	return nil
+}
+
+// sequentialIDs hands out UUIDv7-shaped IDs counting up from 1, so the IDs of a run are the same every time and keep
+// the order they were created in
+type sequentialIDs struct {
+	next atomic.Uint64
+}
+
+// NewID returns the next ID of the sequence
+func (g *sequentialIDs) NewID() uuid.UUID {
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], g.next.Add(1))
+	id[6] = 0x70  // version 7
+	id[8] |= 0x80 // RFC 4122 variant
+	return id
+}
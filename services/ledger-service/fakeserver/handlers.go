@@ -0,0 +1,98 @@
+package fakeserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/labstack/echo/v4"
+)
+
+// ControlPrefix is the path of the routes driving the fake server, which the real API does not serve
+const ControlPrefix = "/_fake"
+
+// ClockRequest moves the clock of the fake server, to an instant or by a duration (e.g., "24h")
+type ClockRequest struct {
+	Now     *time.Time `json:"now,omitempty"`
+	Advance string     `json:"advance,omitempty"`
+}
+
+// ClockResponse is the instant the clock of the fake server stands at
+type ClockResponse struct {
+	Now time.Time `json:"now"`
+}
+
+// registerControlRoutes mounts the routes resetting the data and moving the clock, unauthenticated so the clients
+// drive the server without a token
+func (s *Server) registerControlRoutes(controlRouteGroup *echo.Group) {
+	controlRouteGroup.POST("/reset", s.resetHandler)
+	controlRouteGroup.GET("/clock", s.clockHandler)
+	controlRouteGroup.PUT("/clock", s.setClockHandler)
+}
+
+func (s *Server) resetHandler(c echo.Context) error {
+	s.Reset()
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) clockHandler(c echo.Context) error {
+	return httpx.SendSuccess(c, http.StatusOK, ClockResponse{Now: s.clock.Now()})
+}
+
+func (s *Server) setClockHandler(c echo.Context) error {
+	var req ClockRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	switch {
+	case req.Now != nil && req.Advance != "":
+		return echo.NewHTTPError(http.StatusBadRequest, "set either now or advance")
+	case req.Now != nil:
+		s.clock.Set(req.Now.UTC())
+	case req.Advance != "":
+		d, err := time.ParseDuration(req.Advance)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid advance format, expected a duration such as 24h")
+		}
+		s.clock.Advance(d)
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "now or advance is required")
+	}
+	return httpx.SendSuccess(c, http.StatusOK, ClockResponse{Now: s.clock.Now()})
+}
+
+// loggerMiddleware hands the logger of the server to the request, where the error handler of the API finds it
+func (s *Server) loggerMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.SetRequest(c.Request().WithContext(ctxlogger.SetLogger(c.Request().Context(), s.logger)))
+			return next(c)
+		}
+	}
+}
+
+// authMiddleware authenticates the canned tokens of the users, answering the other tokens like the API answers the
+// tokens rejected by the identity service. The route policies then deny the unverified users the write routes
+func (s *Server) authMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			accessToken, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !found || strings.TrimSpace(accessToken) == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed bearer token")
+			}
+			u, ok := s.users[accessToken]
+			if !ok {
+				return identityclient.ErrInvalidToken
+			}
+
+			ctx := authctx.WithUser(c.Request().Context(), authctx.UserContext{UserID: u.ID, EmailVerified: !u.Unverified})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
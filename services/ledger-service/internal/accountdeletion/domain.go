@@ -0,0 +1,38 @@
+package accountdeletion
+
+import (
+	"context"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDeletionNotFound = errx.New(errx.CategoryNotFound, "ACCOUNT_DELETION_NOT_FOUND", "the account deletion was never requested")
+	ErrEmailMismatch    = errx.New(errx.CategoryValidation, "EMAIL_MISMATCH", "the email does not match the one of the account")
+)
+
+// SagaName identifies the account deletion flow in the saga coordinator
+const SagaName = "account_deletion"
+
+// Step names of the account deletion flow, in order
+const (
+	StepDeactivateUser  = "deactivate_user"   // Blocks the logins while the data is purged, undone by reactivating the user
+	StepPurgeLedgerData = "purge_ledger_data" // Deletes the ledger data of the user, the point of no return
+	StepNotifyUser      = "notify_user"       // Emails the user their data was deleted, best effort
+	StepConfirmDeletion = "confirm_deletion"  // Deletes the user from the identity service
+)
+
+// Repository purges the ledger data of a user
+type Repository interface {
+	// PurgeUser deletes the user and everything they own in the ledger, purging an unknown user succeeds
+	PurgeUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// deletionData is what the account deletion saga is started with
+// The language is kept from the request, as the preferences of the user are purged before the email is sent
+type deletionData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Lang   i18n.Lang `json:"lang,omitempty"`
+}
@@ -0,0 +1,101 @@
+package accountdeletion
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/saga"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DeletionHandler holds dependencies for account deletion HTTP handlers
+type DeletionHandler struct {
+	deletionService *Service
+}
+
+// NewDeletionHandler creates a new instance of DeletionHandler
+func NewDeletionHandler(deletionService *Service) *DeletionHandler {
+	return &DeletionHandler{deletionService: deletionService}
+}
+
+// RegisterRoutes sets up the API routes for the account deletion module
+func (h *DeletionHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	deletionGroup := apiRouteGroup.Group("/user-deletion")
+
+	deletionGroup.POST("", h.requestDeletionHandler)
+	deletionGroup.GET("", h.findDeletionHandler)
+}
+
+// RequestDeletionRequest defines the expected JSON body for deleting the account, confirmed by typing its email
+type RequestDeletionRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// DeletionResponse defines the progress of an account deletion returned by the API
+type DeletionResponse struct {
+	ID          uuid.UUID   `json:"id"`
+	Status      saga.Status `json:"status"`
+	Step        string      `json:"step,omitempty"` // Step is omitted once the deletion finished
+	CreatedAt   time.Time   `json:"created_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+}
+
+// requestDeletionHandler handles the HTTP request for deleting the account of the user, answered before the deletion completes
+func (h *DeletionHandler) requestDeletionHandler(c echo.Context) error {
+	var req RequestDeletionRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	deletion, err := h.deletionService.RequestDeletion(c.Request().Context(), userID, req.Email)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, toDeletionResponse(deletion))
+}
+
+// findDeletionHandler handles the HTTP request for following the deletion of the account of the user
+func (h *DeletionHandler) findDeletionHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	deletion, err := h.deletionService.FindDeletion(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, toDeletionResponse(deletion))
+}
+
+// currentUserID extracts the authenticated user's ID from the request context
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toDeletionResponse maps the internal Deletion model to the public DeletionResponse DTO
+func toDeletionResponse(d *Deletion) DeletionResponse {
+	return DeletionResponse{
+		ID:          d.ID,
+		Status:      d.Status,
+		Step:        d.Step,
+		CreatedAt:   d.CreatedAt,
+		CompletedAt: d.CompletedAt,
+	}
+}
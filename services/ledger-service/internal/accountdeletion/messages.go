@@ -0,0 +1,15 @@
+package accountdeletion
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// messages translates the email confirming the deletion to the user
+var messages = i18n.Catalog{
+	i18n.English: {
+		"deletion_email_subject": "Your FinTrack account was deleted",
+		"deletion_email_body":    "As you requested, your accounts, transactions and every other record of your FinTrack account were deleted.",
+	},
+	i18n.Portuguese: {
+		"deletion_email_subject": "Sua conta FinTrack foi excluída",
+		"deletion_email_body":    "Conforme solicitado, suas contas, transações e todos os demais registros da sua conta FinTrack foram excluídos.",
+	},
+}
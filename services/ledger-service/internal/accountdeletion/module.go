@@ -0,0 +1,40 @@
+package accountdeletion
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/saga"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the account deletion repository, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *DeletionHandler
+}
+
+// NewModule creates the account deletion module, purging the ledger data straight in Postgres
+func NewModule(deps module.Deps) *Module {
+	deletionSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.Identity, deps.Sagas, deps.Mailer, deps.Audit, deps.Clock)
+
+	return &Module{
+		service: deletionSvc,
+		handler: NewDeletionHandler(deletionSvc),
+	}
+}
+
+// Saga returns the account deletion flow, to register on the saga coordinator
+func (m *Module) Saga() saga.Definition {
+	return m.service.Saga()
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "accountdeletion"
+}
+
+// RegisterRoutes mounts the account deletion routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,41 @@
+package accountdeletion
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// PurgeUser deletes the user in a single transaction, their transactions first since they restrict the deletion
+// of the accounts, everything else the user owns (accounts, categories, budgets, memberships...) being deleted by cascade
+func (r *PostgresRepository) PurgeUser(ctx context.Context, userID uuid.UUID) error {
+	return pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			DELETE FROM transactions
+			WHERE account_id IN (SELECT id FROM accounts WHERE user_id = $1)
+		`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete transactions of user: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+}
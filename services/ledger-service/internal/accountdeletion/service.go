@@ -0,0 +1,193 @@
+package accountdeletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/saga"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the account deletion
+const (
+	auditDeletionRequested = "user.deletion_requested"
+	auditResourceUser      = "user"
+)
+
+const (
+	// sagaTimeout compensates a deletion whose data could not be purged in time, giving the user their account back
+	sagaTimeout = 30 * time.Minute
+	// identityStepTimeout bounds each call to the identity service
+	identityStepTimeout = 15 * time.Second
+	// purgeStepTimeout bounds the purge of the ledger data, which may hold years of transactions
+	purgeStepTimeout = 5 * time.Minute
+)
+
+// Deletion is the progress of the deletion of an account
+type Deletion struct {
+	ID          uuid.UUID
+	Status      saga.Status
+	Step        string // Step is the step being executed or compensated, empty once the deletion finished
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// Identity is the part of the identity service the deletion flow drives
+type Identity interface {
+	GetUser(ctx context.Context, userID uuid.UUID) (*identityclient.User, error)
+	DeactivateUser(ctx context.Context, userID uuid.UUID) error
+	ReactivateUser(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Service deletes accounts through a saga spanning the identity service and the ledger
+type Service struct {
+	repo     Repository
+	identity Identity
+	sagas    *saga.Coordinator
+	mailer   notify.Mailer
+	auditor  *audit.Logger
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the account deletion Service
+func NewService(repo Repository, identity Identity, sagas *saga.Coordinator, mailer notify.Mailer, auditor *audit.Logger, clock clock.Clock) *Service {
+	return &Service{
+		repo:     repo,
+		identity: identity,
+		sagas:    sagas,
+		mailer:   mailer,
+		auditor:  auditor,
+		clock:    clock,
+	}
+}
+
+// Saga returns the account deletion flow, to register on the saga coordinator
+// The user is deactivated first so nothing is written while the data is purged, and deleted from the identity
+// service last so a failed purge can give the account back. Once the data is purged the flow only moves forward
+func (s *Service) Saga() saga.Definition {
+	return saga.Definition{
+		Name:    SagaName,
+		Timeout: sagaTimeout,
+		Steps: []saga.Step{
+			{
+				Name:       StepDeactivateUser,
+				Action:     s.withUser(s.identity.DeactivateUser),
+				Compensate: s.withUser(s.identity.ReactivateUser),
+				Timeout:    identityStepTimeout,
+			},
+			{
+				Name:    StepPurgeLedgerData,
+				Action:  s.withUser(s.repo.PurgeUser),
+				Timeout: purgeStepTimeout,
+			},
+			{
+				Name:    StepNotifyUser,
+				Action:  s.notifyUser,
+				Timeout: identityStepTimeout,
+			},
+			{
+				Name:    StepConfirmDeletion,
+				Action:  s.withUser(s.identity.DeleteUser),
+				Timeout: identityStepTimeout,
+			},
+		},
+	}
+}
+
+// RequestDeletion is the use case for deleting the account of the user, who confirms it by typing their email
+func (s *Service) RequestDeletion(ctx context.Context, userID uuid.UUID, email string) (*Deletion, error) {
+	user, err := s.identity.GetUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !strings.EqualFold(strings.TrimSpace(email), user.Email) {
+		return nil, ErrEmailMismatch
+	}
+
+	lang, _ := i18n.FromContext(ctx)
+	started, err := s.sagas.Start(ctx, SagaName, userID, deletionData{UserID: userID, Lang: lang})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditDeletionRequested,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Metadata:     map[string]string{"saga_id": started.ID.String()},
+	})
+
+	return s.toDeletion(started), nil
+}
+
+// FindDeletion is the use case for following the latest deletion of the account of the user
+func (s *Service) FindDeletion(ctx context.Context, userID uuid.UUID) (*Deletion, error) {
+	latest, err := s.sagas.FindLatest(ctx, SagaName, userID)
+	if err != nil {
+		if errors.Is(err, saga.ErrSagaNotFound) {
+			return nil, ErrDeletionNotFound
+		}
+		return nil, fmt.Errorf("failed to find account deletion: %w", err)
+	}
+	return s.toDeletion(latest), nil
+}
+
+// notifyUser emails the user their data was deleted, while the identity service still knows their address
+// The email is written in the language of the deletion request, the default one for the deletions requested before it was kept
+// Failures are only logged: the data is already gone and the email must not hold the deletion back
+func (s *Service) notifyUser(ctx context.Context, instance *saga.Saga) error {
+	var data deletionData
+	if err := instance.Decode(&data); err != nil {
+		return err
+	}
+	lang, _ := i18n.Resolve(string(data.Lang))
+
+	err := s.mailer.Mail(ctx, notify.Email{
+		UserID:  data.UserID,
+		Subject: messages.Format(lang, "deletion_email_subject"),
+		Body:    messages.Format(lang, "deletion_email_body"),
+	})
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to email account deletion",
+			slog.String("user_id", data.UserID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil
+}
+
+// withUser adapts a function of the user being deleted to a saga step
+func (s *Service) withUser(fn func(ctx context.Context, userID uuid.UUID) error) saga.StepFunc {
+	return func(ctx context.Context, instance *saga.Saga) error {
+		var data deletionData
+		if err := instance.Decode(&data); err != nil {
+			return err
+		}
+		return fn(ctx, data.UserID)
+	}
+}
+
+// toDeletion maps a saga of the flow to a Deletion
+func (s *Service) toDeletion(instance *saga.Saga) *Deletion {
+	deletion := &Deletion{
+		ID:          instance.ID,
+		Status:      instance.Status,
+		CreatedAt:   instance.CreatedAt,
+		CompletedAt: instance.CompletedAt,
+	}
+	if steps := s.Saga().Steps; !instance.Finished() && instance.Step >= 0 && instance.Step < len(steps) {
+		deletion.Step = steps[instance.Step].Name
+	}
+	return deletion
+}
@@ -0,0 +1,132 @@
+package attachments
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound      = errx.New(errx.CategoryNotFound, "ATTACHMENT_NOT_FOUND", "attachment not found")
+	ErrTransactionNotFound     = errx.New(errx.CategoryNotFound, "TRANSACTION_NOT_FOUND", "transaction not found")
+	ErrAttachmentEmpty         = errx.New(errx.CategoryValidation, "ATTACHMENT_EMPTY", "the attached file is empty")
+	ErrAttachmentTooLarge      = errx.New(errx.CategoryValidation, "ATTACHMENT_TOO_LARGE", "the attached file is too large")
+	ErrUnsupportedAttachment   = errx.New(errx.CategoryValidation, "UNSUPPORTED_ATTACHMENT_TYPE", "only JPEG, PNG, WebP and PDF files can be attached")
+	ErrTooManyAttachments      = errx.New(errx.CategoryConflict, "TOO_MANY_ATTACHMENTS", "the maximum number of attachments on the transaction was reached")
+	ErrAttachmentQuotaExceeded = errx.New(errx.CategoryConflict, "ATTACHMENT_QUOTA_EXCEEDED", "the attachments storage quota was reached")
+	ErrInvalidDownloadLink     = errx.New(errx.CategoryForbidden, "INVALID_DOWNLOAD_LINK", "the download link is invalid or expired")
+)
+
+const (
+	// MaxAttachmentsPerTransaction bounds the files of a transaction, which are listed whole
+	MaxAttachmentsPerTransaction = 10
+
+	maxFilenameLength = 255
+	// defaultFilename names the files uploaded without a usable name
+	defaultFilename = "receipt"
+)
+
+// allowedContentTypes are the types of the files accepted, detected from their content rather than trusted from the client
+var allowedContentTypes = []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}
+
+// Status tells whether the file of an attachment made it to the storage
+type Status string
+
+const (
+	StatusPending Status = "PENDING" // The quota is reserved while the file is uploaded to the storage
+	StatusStored  Status = "STORED"
+)
+
+// Storage keeps the attachment files out of the database and the API, handing out expiring URLs to download them
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, content []byte) error
+	// Delete removes a file, succeeding when it does not exist
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL downloading the file without credentials until expiresAt, saved under filename
+	SignedURL(key, filename, contentType string, expiresAt time.Time) (string, error)
+}
+
+// Repository persists the attachments and tracks the storage used by each user
+type Repository interface {
+	// TransactionOwned tells whether the transaction belongs to the account and the account to the user
+	TransactionOwned(ctx context.Context, userID, accountID, transactionID uuid.UUID) (bool, error)
+	// Reserve saves a pending attachment, ErrAttachmentQuotaExceeded or ErrTooManyAttachments when it does not fit
+	Reserve(ctx context.Context, attachment *Attachment, quotaBytes int64) error
+	MarkStored(ctx context.Context, attachmentID uuid.UUID) error
+	// FindByID returns a stored attachment on an account of the user, ErrAttachmentNotFound when the account is someone else's
+	FindByID(ctx context.Context, userID, attachmentID uuid.UUID) (*Attachment, error)
+	// FindByTransactionID returns the stored attachments of a transaction, oldest first
+	FindByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]*Attachment, error)
+	Delete(ctx context.Context, attachmentID uuid.UUID) error
+	// Usage sums the attachments of the user, the pending ones included
+	Usage(ctx context.Context, userID uuid.UUID) (Usage, error)
+	// RefreshOrphans flags the attachments whose transaction was deleted as orphaned at now, and unflags the ones
+	// whose transaction was restored
+	RefreshOrphans(ctx context.Context, now time.Time) error
+	// FindCollectable returns up to limit attachments orphaned, or left pending, before the given time
+	FindCollectable(ctx context.Context, before time.Time, limit int) ([]*Attachment, error)
+}
+
+// Attachment is a file attached to a transaction by its owner (e.g., the receipt of a purchase)
+type Attachment struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	Filename      string
+	ContentType   string
+	SizeBytes     int64
+	StorageKey    string // StorageKey locates the file in the Storage
+	Status        Status
+	CreatedAt     time.Time
+}
+
+// NewAttachment creates a pending attachment of the user's transaction, named after the uploaded file
+func NewAttachment(userID, accountID, transactionID uuid.UUID, filename, contentType string, size int64, ids idgen.Generator, clock clock.Clock) *Attachment {
+	id := ids.NewID()
+	return &Attachment{
+		ID:            id,
+		UserID:        userID,
+		AccountID:     accountID,
+		TransactionID: transactionID,
+		Filename:      sanitizeFilename(filename),
+		ContentType:   contentType,
+		SizeBytes:     size,
+		StorageKey:    "users/" + userID.String() + "/" + id.String(),
+		Status:        StatusPending,
+		CreatedAt:     clock.Now(),
+	}
+}
+
+// Usage is how much of the attachments storage quota a user holds
+type Usage struct {
+	Attachments int
+	UsedBytes   int64
+	QuotaBytes  int64
+}
+
+// sanitizeFilename keeps the last element of the uploaded name without control characters, cut at maxFilenameLength
+func sanitizeFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	filename = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+	if filename == "" || filename == "." || filename == ".." || filename == "/" {
+		return defaultFilename
+	}
+	if utf8.RuneCountInString(filename) > maxFilenameLength {
+		filename = string([]rune(filename)[:maxFilenameLength])
+	}
+	return filename
+}
@@ -0,0 +1,224 @@
+package attachments
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// UploadPath is the route uploading attachments, exempt from the default body limit since it sets its own
+const UploadPath = "/api/v1/accounts/:id/transactions/:transactionId/attachments"
+
+// multipartOverhead is the room left in the body limit for the multipart boundaries and headers around the file
+const multipartOverhead = 64 * 1024
+
+// AttachmentsHandler holds dependencies for the attachments HTTP handlers
+type AttachmentsHandler struct {
+	attachmentsService *Service
+	maxUploadSize      string
+}
+
+// NewAttachmentsHandler creates a new instance of AttachmentsHandler, accepting files up to maxFileSize bytes
+func NewAttachmentsHandler(attachmentsService *Service, maxFileSize int64) *AttachmentsHandler {
+	return &AttachmentsHandler{
+		attachmentsService: attachmentsService,
+		maxUploadSize:      strconv.FormatInt(maxFileSize+multipartOverhead, 10),
+	}
+}
+
+// RegisterRoutes sets up the API routes for the attachments module
+func (h *AttachmentsHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.POST("/accounts/:id/transactions/:transactionId/attachments", h.uploadHandler, middleware.BodyLimit(h.maxUploadSize))
+	apiRouteGroup.GET("/accounts/:id/transactions/:transactionId/attachments", h.listHandler)
+
+	attachmentsGroup := apiRouteGroup.Group("/attachments")
+	attachmentsGroup.GET("/usage", h.usageHandler)
+	attachmentsGroup.GET("/:id/url", h.downloadURLHandler)
+	attachmentsGroup.DELETE("/:id", h.deleteHandler)
+}
+
+// AttachmentResponse defines the structure of an attachment returned by the API, downloaded through its URL route
+type AttachmentResponse struct {
+	ID            uuid.UUID `json:"id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	SizeBytes     int64     `json:"size_bytes"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// DownloadURLResponse defines the structure of an expiring download URL returned by the API
+type DownloadURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UsageResponse defines the structure of the attachments quota usage returned by the API
+type UsageResponse struct {
+	Attachments    int   `json:"attachments"`
+	UsedBytes      int64 `json:"used_bytes"`
+	QuotaBytes     int64 `json:"quota_bytes"`
+	AvailableBytes int64 `json:"available_bytes"`
+}
+
+// uploadHandler handles the HTTP request for attaching the multipart "file" field to a transaction
+func (h *AttachmentsHandler) uploadHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the file field is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the file could not be read")
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the file could not be read")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := UploadParams{
+		UserID:        userID,
+		AccountID:     accountID,
+		TransactionID: transactionID,
+		Filename:      fileHeader.Filename,
+		Content:       content,
+	}
+
+	attachment, err := h.attachmentsService.Upload(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toAttachmentResponse(attachment))
+}
+
+// listHandler handles the HTTP request for listing the attachments of a transaction
+func (h *AttachmentsHandler) listHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := h.attachmentsService.ListByTransaction(c.Request().Context(), userID, accountID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	response := make([]AttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		response[i] = toAttachmentResponse(attachment)
+	}
+	return httpx.SendSuccess(c, http.StatusOK, response)
+}
+
+// usageHandler handles the HTTP request for finding the user's attachments quota usage
+func (h *AttachmentsHandler) usageHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	usage, err := h.attachmentsService.Usage(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, UsageResponse{
+		Attachments:    usage.Attachments,
+		UsedBytes:      usage.UsedBytes,
+		QuotaBytes:     usage.QuotaBytes,
+		AvailableBytes: max(usage.QuotaBytes-usage.UsedBytes, 0),
+	})
+}
+
+// downloadURLHandler handles the HTTP request for an expiring URL downloading an attachment
+func (h *AttachmentsHandler) downloadURLHandler(c echo.Context) error {
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid attachment id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	link, err := h.attachmentsService.DownloadURL(c.Request().Context(), userID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, DownloadURLResponse{URL: link.URL, ExpiresAt: link.ExpiresAt})
+}
+
+// deleteHandler handles the HTTP request for removing an attachment and its file
+func (h *AttachmentsHandler) deleteHandler(c echo.Context) error {
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid attachment id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.attachmentsService.Delete(c.Request().Context(), userID, attachmentID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// currentUserID extracts the authenticated user's ID from the request context
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toAttachmentResponse maps an Attachment to the public AttachmentResponse DTO
+func toAttachmentResponse(a *Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:            a.ID,
+		AccountID:     a.AccountID,
+		TransactionID: a.TransactionID,
+		Filename:      a.Filename,
+		ContentType:   a.ContentType,
+		SizeBytes:     a.SizeBytes,
+		CreatedAt:     a.CreatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package attachments
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the attachments repository, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *AttachmentsHandler
+	storage Storage
+}
+
+// NewModule creates the attachments module, whose files are kept in storage and tracked in Postgres
+func NewModule(deps module.Deps, storage Storage) *Module {
+	cfg := deps.Config.Attachments
+	limits := Limits{
+		MaxFileSize: cfg.MaxFileSize,
+		QuotaBytes:  cfg.QuotaBytes,
+		URLTTL:      cfg.URLTTL,
+		OrphanGrace: cfg.OrphanGrace,
+	}
+	attachmentsSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), storage, limits, deps.IDs, deps.Clock)
+
+	return &Module{
+		service: attachmentsSvc,
+		handler: NewAttachmentsHandler(attachmentsSvc, cfg.MaxFileSize),
+		storage: storage,
+	}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "attachments"
+}
+
+// RegisterRoutes mounts the attachments routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
+
+// RegisterPublicRoutes mounts the route serving the signed URLs of the local disk storage, the files of a bucket
+// being downloaded straight from it
+func (m *Module) RegisterPublicRoutes(publicRouteGroup *echo.Group) {
+	if disk, ok := m.storage.(*DiskStorage); ok {
+		publicRouteGroup.GET("/attachments/*", disk.Download)
+	}
+}
+
+// Service returns the attachments service, for the composition root scheduling the garbage collection
+func (m *Module) Service() *Service {
+	return m.service
+}
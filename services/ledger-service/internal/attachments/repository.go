@@ -0,0 +1,220 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// attachmentColumns are the columns scanned by scanAttachment
+const attachmentColumns = `id, user_id, account_id, transaction_id, filename, content_type, size_bytes, storage_key, status, created_at`
+
+// TransactionOwned tells whether the transaction belongs to the account and the account to the user, the transactions
+// rolled into an opening balance keeping their receipts
+func (r *PostgresRepository) TransactionOwned(ctx context.Context, userID, accountID, transactionID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM (
+				SELECT id, account_id FROM transactions
+				UNION ALL
+				SELECT id, account_id FROM archived_transactions
+			) t
+			JOIN accounts a ON a.id = t.account_id
+			WHERE t.id = $1 AND a.id = $2 AND a.user_id = $3
+		)
+	`
+
+	var owned bool
+	if err := r.pool.QueryRow(ctx, query, transactionID, accountID, userID).Scan(&owned); err != nil {
+		return false, fmt.Errorf("failed to check transaction: %w", err)
+	}
+	return owned, nil
+}
+
+// Reserve saves a pending attachment once the transaction and the quota of the user have room for it
+// The uploads of a user are serialized by an advisory lock, so concurrent ones cannot overflow the quota together
+func (r *PostgresRepository) Reserve(ctx context.Context, attachment *Attachment, quotaBytes int64) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::TEXT, 0))`, attachment.UserID); err != nil {
+			return fmt.Errorf("failed to lock attachments quota: %w", err)
+		}
+
+		usageQuery := `
+			SELECT COALESCE(SUM(size_bytes), 0), COUNT(*) FILTER (WHERE transaction_id = $2)
+			FROM attachments
+			WHERE user_id = $1
+		`
+		var used int64
+		var onTransaction int
+		if err := tx.QueryRow(ctx, usageQuery, attachment.UserID, attachment.TransactionID).Scan(&used, &onTransaction); err != nil {
+			return fmt.Errorf("failed to sum attachments: %w", err)
+		}
+		if onTransaction >= MaxAttachmentsPerTransaction {
+			return ErrTooManyAttachments.With("max_attachments", MaxAttachmentsPerTransaction)
+		}
+		if used+attachment.SizeBytes > quotaBytes {
+			return ErrAttachmentQuotaExceeded.With("quota_bytes", quotaBytes).With("used_bytes", used)
+		}
+
+		insertQuery := `
+			INSERT INTO attachments (` + attachmentColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`
+		_, err := tx.Exec(ctx, insertQuery,
+			attachment.ID, attachment.UserID, attachment.AccountID, attachment.TransactionID, attachment.Filename,
+			attachment.ContentType, attachment.SizeBytes, attachment.StorageKey, attachment.Status, attachment.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert attachment: %w", err)
+		}
+		return nil
+	})
+}
+
+// MarkStored records that the file of the attachment reached the storage
+func (r *PostgresRepository) MarkStored(ctx context.Context, attachmentID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE attachments SET status = $2 WHERE id = $1`, attachmentID, StatusStored)
+	if err != nil {
+		return fmt.Errorf("failed to mark attachment as stored: %w", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a stored attachment on an account of the user, the account deciding who owns it
+func (r *PostgresRepository) FindByID(ctx context.Context, userID, attachmentID uuid.UUID) (*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE id = $1 AND status = $3
+			AND EXISTS (SELECT 1 FROM accounts a WHERE a.id = attachments.account_id AND a.user_id = $2)
+	`
+
+	attachment, err := scanAttachment(r.pool.QueryRow(ctx, query, attachmentID, userID, StatusStored))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttachmentNotFound.With("attachment_id", attachmentID)
+		}
+		return nil, fmt.Errorf("failed to fetch attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// FindByTransactionID retrieves the stored attachments of a transaction, oldest first
+func (r *PostgresRepository) FindByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE transaction_id = $1 AND status = $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, transactionID, StatusStored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	attachments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Attachment, error) {
+		return scanAttachment(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// Delete removes an attachment
+func (r *PostgresRepository) Delete(ctx context.Context, attachmentID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM attachments WHERE id = $1`, attachmentID); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
+
+// Usage sums the attachments of the user, the quota being left for the caller to fill in
+func (r *PostgresRepository) Usage(ctx context.Context, userID uuid.UUID) (Usage, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(size_bytes), 0) FROM attachments WHERE user_id = $1`
+
+	var usage Usage
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&usage.Attachments, &usage.UsedBytes); err != nil {
+		return Usage{}, fmt.Errorf("failed to sum attachments: %w", err)
+	}
+	return usage, nil
+}
+
+// RefreshOrphans flags the stored attachments whose transaction no longer exists and unflags the restored ones
+// A transaction rolled into an opening balance still exists, moved to the archived history
+func (r *PostgresRepository) RefreshOrphans(ctx context.Context, now time.Time) error {
+	flagQuery := `
+		UPDATE attachments a
+		SET orphaned_at = $1
+		WHERE a.status = $2 AND a.orphaned_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = a.transaction_id)
+			AND NOT EXISTS (SELECT 1 FROM archived_transactions t WHERE t.id = a.transaction_id)
+	`
+	if _, err := r.pool.Exec(ctx, flagQuery, now, StatusStored); err != nil {
+		return fmt.Errorf("failed to flag orphaned attachments: %w", err)
+	}
+
+	unflagQuery := `
+		UPDATE attachments a
+		SET orphaned_at = NULL
+		WHERE a.orphaned_at IS NOT NULL AND (
+			EXISTS (SELECT 1 FROM transactions t WHERE t.id = a.transaction_id)
+			OR EXISTS (SELECT 1 FROM archived_transactions t WHERE t.id = a.transaction_id)
+		)
+	`
+	if _, err := r.pool.Exec(ctx, unflagQuery); err != nil {
+		return fmt.Errorf("failed to unflag restored attachments: %w", err)
+	}
+	return nil
+}
+
+// FindCollectable retrieves up to limit attachments orphaned, or left pending by an interrupted upload, before the given time
+func (r *PostgresRepository) FindCollectable(ctx context.Context, before time.Time, limit int) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE orphaned_at < $1 OR (status = $2 AND created_at < $1)
+		ORDER BY created_at
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, before, StatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collectable attachments: %w", err)
+	}
+	attachments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Attachment, error) {
+		return scanAttachment(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan collectable attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// scanAttachment scans a row holding the attachmentColumns
+func scanAttachment(row pgx.Row) (*Attachment, error) {
+	var a Attachment
+	err := row.Scan(&a.ID, &a.UserID, &a.AccountID, &a.TransactionID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.StorageKey, &a.Status, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
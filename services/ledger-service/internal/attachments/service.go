@@ -0,0 +1,213 @@
+package attachments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/google/uuid"
+)
+
+// collectBatchSize bounds the attachments removed per query by the garbage collection
+const collectBatchSize = 100
+
+// Limits bounds the attachments of each user and the life of their download URLs
+type Limits struct {
+	MaxFileSize int64         // MaxFileSize is the largest file accepted, in bytes
+	QuotaBytes  int64         // QuotaBytes is the storage each user may fill with attachments
+	URLTTL      time.Duration // URLTTL is how long a download URL works
+	OrphanGrace time.Duration // OrphanGrace is how long the files of a deleted transaction are kept, in case it is restored
+}
+
+// Service manages the attachments of the transactions and their files in the storage
+type Service struct {
+	repo    Repository
+	storage Storage
+	limits  Limits
+	ids     idgen.Generator
+	clock   clock.Clock
+}
+
+// NewService creates a new instance of the attachments Service
+func NewService(repo Repository, storage Storage, limits Limits, ids idgen.Generator, clock clock.Clock) *Service {
+	return &Service{repo: repo, storage: storage, limits: limits, ids: ids, clock: clock}
+}
+
+// UploadParams holds all the required data for the Upload use case
+type UploadParams struct {
+	UserID        uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	Filename      string
+	Content       []byte
+}
+
+// DownloadLink is an expiring URL downloading the file of an attachment
+type DownloadLink struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Upload is the use case for attaching a file to a transaction of the user
+// The quota is reserved before the file is stored, so a failed upload leaves at most a pending row for the garbage collection
+func (s *Service) Upload(ctx context.Context, params UploadParams) (*Attachment, error) {
+	size := int64(len(params.Content))
+	if size == 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if size > s.limits.MaxFileSize {
+		return nil, ErrAttachmentTooLarge.With("max_bytes", s.limits.MaxFileSize)
+	}
+	contentType := http.DetectContentType(params.Content)
+	if !slices.Contains(allowedContentTypes, contentType) {
+		return nil, ErrUnsupportedAttachment.With("content_type", contentType)
+	}
+
+	if err := s.checkTransaction(ctx, params.UserID, params.AccountID, params.TransactionID); err != nil {
+		return nil, err
+	}
+
+	attachment := NewAttachment(params.UserID, params.AccountID, params.TransactionID, params.Filename, contentType, size, s.ids, s.clock)
+	if err := s.repo.Reserve(ctx, attachment, s.limits.QuotaBytes); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Put(ctx, attachment.StorageKey, contentType, params.Content); err != nil {
+		s.release(ctx, attachment)
+		return nil, fmt.Errorf("failed to store attachment file: %w", err)
+	}
+	if err := s.repo.MarkStored(ctx, attachment.ID); err != nil {
+		return nil, err
+	}
+	attachment.Status = StatusStored
+	return attachment, nil
+}
+
+// ListByTransaction is the use case for listing the attachments of a transaction of the user
+func (s *Service) ListByTransaction(ctx context.Context, userID, accountID, transactionID uuid.UUID) ([]*Attachment, error) {
+	if err := s.checkTransaction(ctx, userID, accountID, transactionID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByTransactionID(ctx, transactionID)
+}
+
+// DownloadURL is the use case for handing out an expiring URL to download an attachment of the user
+func (s *Service) DownloadURL(ctx context.Context, userID, attachmentID uuid.UUID) (*DownloadLink, error) {
+	attachment, err := s.repo.FindByID(ctx, userID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.clock.Now().Add(s.limits.URLTTL)
+	url, err := s.storage.SignedURL(attachment.StorageKey, attachment.Filename, attachment.ContentType, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attachment URL: %w", err)
+	}
+	return &DownloadLink{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// Delete is the use case for removing an attachment of the user and its file
+// The file goes first, so a failure leaves the attachment listed for the user to retry rather than a file no one tracks
+func (s *Service) Delete(ctx context.Context, userID, attachmentID uuid.UUID) error {
+	attachment, err := s.repo.FindByID(ctx, userID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Delete(ctx, attachment.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete attachment file: %w", err)
+	}
+	return s.repo.Delete(ctx, attachment.ID)
+}
+
+// Usage is the use case for finding how much of the attachments quota the user holds
+func (s *Service) Usage(ctx context.Context, userID uuid.UUID) (Usage, error) {
+	usage, err := s.repo.Usage(ctx, userID)
+	if err != nil {
+		return Usage{}, err
+	}
+	usage.QuotaBytes = s.limits.QuotaBytes
+	return usage, nil
+}
+
+// CollectGarbage removes the attachments of the transactions deleted longer than the orphan grace ago,
+// and the ones left pending as long by an interrupted upload, releasing their quota
+func (s *Service) CollectGarbage(ctx context.Context) error {
+	now := s.clock.Now()
+	if err := s.repo.RefreshOrphans(ctx, now); err != nil {
+		return err
+	}
+
+	before := now.Add(-s.limits.OrphanGrace)
+	removed, failed := 0, 0
+	for {
+		attachments, err := s.repo.FindCollectable(ctx, before, collectBatchSize)
+		if err != nil {
+			return err
+		}
+
+		batchFailed := 0
+		for _, attachment := range attachments {
+			if err := s.collect(ctx, attachment); err != nil {
+				ctxlogger.GetLogger(ctx).Error("failed to collect attachment",
+					slog.String("attachment_id", attachment.ID.String()),
+					slog.String("error", err.Error()),
+				)
+				batchFailed++
+				continue
+			}
+			removed++
+		}
+		failed += batchFailed
+
+		// A batch that is short, or made only of failures that would be fetched again, ends the run
+		if len(attachments) < collectBatchSize || batchFailed == len(attachments) {
+			break
+		}
+	}
+
+	ctxlogger.GetLogger(ctx).Info("collected orphaned attachments",
+		slog.Int("removed", removed),
+		slog.Int("failed", failed),
+	)
+	if failed > 0 {
+		return fmt.Errorf("failed to collect %d attachments", failed)
+	}
+	return nil
+}
+
+// collect removes the file of an attachment, then the attachment
+func (s *Service) collect(ctx context.Context, attachment *Attachment) error {
+	if err := s.storage.Delete(ctx, attachment.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete attachment file: %w", err)
+	}
+	return s.repo.Delete(ctx, attachment.ID)
+}
+
+// release removes the pending attachment of a failed upload, the garbage collection removing it otherwise
+func (s *Service) release(ctx context.Context, attachment *Attachment) {
+	if err := s.repo.Delete(ctx, attachment.ID); err != nil {
+		ctxlogger.GetLogger(ctx).Warn("failed to release attachment quota",
+			slog.String("attachment_id", attachment.ID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// checkTransaction fails with ErrTransactionNotFound unless the transaction belongs to the account of the user
+func (s *Service) checkTransaction(ctx context.Context, userID, accountID, transactionID uuid.UUID) error {
+	owned, err := s.repo.TransactionOwned(ctx, userID, accountID, transactionID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrTransactionNotFound.With("transaction_id", transactionID)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	_ Storage = (*S3Storage)(nil)
+	_ Storage = (*DiskStorage)(nil)
+)
+
+// ----- S3 ----- //
+
+// S3Storage keeps the files in a bucket of S3 or of a compatible service (MinIO, R2...), addressed path-style
+// The download URLs are presigned with Signature Version 4, so the files never go through the API
+type S3Storage struct {
+	client          *http.Client
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+	clock           clock.Clock
+}
+
+// NewS3Storage creates a new S3Storage for the bucket, endpoint being the base URL of the service (e.g., "https://s3.amazonaws.com")
+func NewS3Storage(endpoint, region, bucket, accessKeyID, secretAccessKey string, timeout time.Duration, clock clock.Clock) (*S3Storage, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	return &S3Storage{
+		client:          &http.Client{Timeout: timeout},
+		endpoint:        parsed,
+		region:          region,
+		bucket:          bucket,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		clock:           clock,
+	}, nil
+}
+
+// Put uploads the file to the bucket
+func (s *S3Storage) Put(ctx context.Context, key, contentType string, content []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, sha256Hex(content))
+	return s.do(req)
+}
+
+// Delete removes the file from the bucket, S3 replying with success when it does not exist
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	s.sign(req, sha256Hex(nil))
+	return s.do(req)
+}
+
+// SignedURL presigns a GET of the file, S3 serving it under filename until expiresAt (at most 7 days ahead)
+func (s *S3Storage) SignedURL(key, filename, contentType string, expiresAt time.Time) (string, error) {
+	now := s.clock.Now().UTC()
+	expires := int64(expiresAt.Sub(now).Seconds())
+	if expires < 1 || expires > 7*24*60*60 {
+		return "", fmt.Errorf("presigned URLs expire between 1 second and 7 days, got %d seconds", expires)
+	}
+
+	u := s.objectURL(key)
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(amzDateLayout))
+	query.Set("X-Amz-Expires", strconv.FormatInt(expires, 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	query.Set("response-content-disposition", contentDisposition(filename))
+	query.Set("response-content-type", contentType)
+	u.RawQuery = canonicalQuery(query)
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	u.RawQuery += "&X-Amz-Signature=" + s.signature(now, canonical)
+	return u.String(), nil
+}
+
+// amzDateLayout is the ISO 8601 basic format of the Signature Version 4 timestamps
+const amzDateLayout = "20060102T150405Z"
+
+// objectURL returns the path-style URL of the file in the bucket
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = u.Path + "/" + s.bucket + "/" + key
+	u.RawPath = u.Path[:len(u.Path)-len(key)] + uriEncode(key, false)
+	return &u
+}
+
+// sign sets the Signature Version 4 headers of the request, whose signed headers are host, content-type and the x-amz ones
+func (s *S3Storage) sign(req *http.Request, payloadHash string) {
+	now := s.clock.Now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateLayout))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           now.Format(amzDateLayout),
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, s.scope(now), signedHeaders, s.signature(now, canonical)))
+}
+
+// scope is the credential scope of the signatures made at now
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature signs the canonical request with the key derived from the secret for the scope of now
+func (s *S3Storage) signature(now time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", now.Format(amzDateLayout), s.scope(now), sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// do sends a signed request, failing on any non-2xx response
+func (s *S3Storage) do(req *http.Request) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call S3: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("S3 responded with status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// canonicalQuery encodes the query sorted by name, with the Signature Version 4 escaping
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode escapes every byte but the unreserved characters of RFC 3986, and the slashes unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// contentDisposition makes the browsers save the file under filename instead of opening it
+func contentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// ----- Local disk ----- //
+
+// DiskStorage keeps the files in a local directory, used until a bucket is configured
+// Its download URLs point to a public route of the API verifying their signature, so it only suits development
+type DiskStorage struct {
+	dir     string
+	baseURL string // baseURL is where the public routes of the API are reached (e.g., "http://localhost:8080/api/v1/public")
+	secret  []byte
+	clock   clock.Clock
+}
+
+// NewDiskStorage creates a new DiskStorage in dir, signing its download URLs with secret
+func NewDiskStorage(dir, baseURL string, secret []byte, clock clock.Clock) (*DiskStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	return &DiskStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), secret: secret, clock: clock}, nil
+}
+
+// Put writes the file, replacing the one with the same key
+func (s *DiskStorage) Put(ctx context.Context, key, contentType string, content []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o640); err != nil {
+		return fmt.Errorf("failed to write attachment file: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the file, succeeding when it does not exist
+func (s *DiskStorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete attachment file: %w", err)
+	}
+	return nil
+}
+
+// SignedURL returns the URL of the download route, signed over the key, the file details and the expiration
+func (s *DiskStorage) SignedURL(key, filename, contentType string, expiresAt time.Time) (string, error) {
+	query := url.Values{}
+	query.Set("filename", filename)
+	query.Set("type", contentType)
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", s.sign(key, query))
+	return s.baseURL + "/attachments/" + uriEncode(key, false) + "?" + query.Encode(), nil
+}
+
+// Download serves the file of a signed URL, rejecting the tampered and expired ones
+func (s *DiskStorage) Download(c echo.Context) error {
+	key := c.Param("*")
+	query := c.QueryParams()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || !hmac.Equal([]byte(query.Get("signature")), []byte(s.sign(key, query))) {
+		return ErrInvalidDownloadLink
+	}
+	if s.clock.Now().Unix() > expires {
+		return ErrInvalidDownloadLink
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, query.Get("type"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, contentDisposition(query.Get("filename")))
+	return c.File(s.path(key))
+}
+
+// sign computes the signature of a download URL
+func (s *DiskStorage) sign(key string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.secret)
+	for _, part := range []string{key, query.Get("filename"), query.Get("type"), query.Get("expires")} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to its file, the keys being generated by NewAttachment and checked by the signatures
+func (s *DiskStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(filepath.Clean("/"+key)))
+}
@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnsupportedArchiveVersion = errx.New(errx.CategoryValidation, "UNSUPPORTED_ARCHIVE_VERSION", "the backup archive version is not supported")
+	ErrInvalidArchive            = errx.New(errx.CategoryValidation, "INVALID_ARCHIVE", "the backup archive is invalid")
+	ErrRestoreConflict           = errx.New(errx.CategoryConflict, "RESTORE_CONFLICT", "the backup archive holds records owned by another user")
+	ErrExportTooLarge            = errx.New(errx.CategoryValidation, "EXPORT_TOO_LARGE", "the user has more transactions than an export may hold")
+)
+
+// ArchiveVersion is the version of the archives produced by Export, bumped whenever their format changes
+const ArchiveVersion = 1
+
+// Repository reads and writes every record of a user at once
+type Repository interface {
+	// Export reads the records of the user, including the default categories they reference
+	// It fails with ErrExportTooLarge when the user has more than maxRows transactions, archived ones included, by far
+	// the largest part
+	Export(ctx context.Context, userID uuid.UUID, maxRows int) (*Archive, error)
+	// Restore creates or replaces the records of the archive in a single transaction, owned by the user
+	Restore(ctx context.Context, userID uuid.UUID, archive *Archive) (*RestoreResult, error)
+}
+
+// Archive is the versioned JSON document holding all the data of a user
+// Records keep their IDs, so restoring the same archive twice leaves the data unchanged
+type Archive struct {
+	Version              int                 `json:"version"`
+	ExportedAt           time.Time           `json:"exported_at"`
+	Categories           []CategoryRecord    `json:"categories"`
+	Accounts             []AccountRecord     `json:"accounts"`
+	Transactions         []TransactionRecord `json:"transactions"`
+	ArchivedTransactions []TransactionRecord `json:"archived_transactions"` // ArchivedTransactions are the settled transactions the accounts rolled into their opening balance
+	Budgets              []BudgetRecord      `json:"budgets"`
+	AlertThresholds      []int               `json:"alert_thresholds"` // AlertThresholds are null when the user never changed the defaults, empty when alerts are disabled
+	Positions            []PositionRecord    `json:"positions"`
+}
+
+// CategoryRecord is a category of the archive
+// Default categories are shared by every user, they are exported so the archive is complete on another instance
+type CategoryRecord struct {
+	ID       uuid.UUID  `json:"id"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	Name     string     `json:"name"`
+	Default  bool       `json:"default"`
+}
+
+// AccountRecord is an account of the archive
+type AccountRecord struct {
+	ID                      uuid.UUID          `json:"id"`
+	Name                    string             `json:"name"`
+	Currency                string             `json:"currency"`
+	Kind                    ledger.AccountKind `json:"kind"`
+	StatementClosingDay     *int               `json:"statement_closing_day,omitempty"`
+	IncludeInOverallBalance bool               `json:"include_in_overall_balance"`
+	ArchivedAt              *time.Time         `json:"archived_at,omitempty"`
+	CreatedAt               time.Time          `json:"created_at"`
+}
+
+// TransactionRecord is a transaction of the archive, amounts are expressed in minor units of the account currency
+type TransactionRecord struct {
+	ID               uuid.UUID              `json:"id"`
+	AccountID        uuid.UUID              `json:"account_id"`
+	CategoryID       *uuid.UUID             `json:"category_id,omitempty"`
+	Type             ledger.TransactionType `json:"type"`
+	Description      string                 `json:"description"`
+	Observation      string                 `json:"observation,omitempty"`
+	Amount           int64                  `json:"amount"`
+	DueDate          time.Time              `json:"due_date"`
+	PaidAt           *time.Time             `json:"paid_at,omitempty"`
+	OriginalAmount   *int64                 `json:"original_amount,omitempty"`
+	OriginalCurrency *string                `json:"original_currency,omitempty"`
+	ExchangeRate     *string                `json:"exchange_rate,omitempty"`
+	Metadata         json.RawMessage        `json:"metadata,omitempty"` // Metadata holds the optional details of the transaction (e.g., PIX identifiers)
+	Tags             []string               `json:"tags,omitempty"`
+}
+
+// BudgetRecord is a monthly category budget of the archive
+type BudgetRecord struct {
+	ID         uuid.UUID `json:"id"`
+	CategoryID uuid.UUID `json:"category_id"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+}
+
+// PositionRecord is an investment position of the archive, its quantity has 8 implied decimal places
+type PositionRecord struct {
+	ID        uuid.UUID `json:"id"`
+	AccountID uuid.UUID `json:"account_id"`
+	Ticker    string    `json:"ticker"`
+	Quantity  int64     `json:"quantity"`
+	CostBasis int64     `json:"cost_basis"`
+	Currency  string    `json:"currency"`
+}
+
+// RestoreResult counts the records written by a restore
+type RestoreResult struct {
+	Categories           int `json:"categories"`
+	Accounts             int `json:"accounts"`
+	Transactions         int `json:"transactions"`
+	ArchivedTransactions int `json:"archived_transactions"`
+	Budgets              int `json:"budgets"`
+	Positions            int `json:"positions"`
+}
+
+// Validate checks the archive can be restored: its version is supported and every reference points to a record of the archive
+// The database enforces the remaining rules (lengths, signs...) when the archive is restored
+func (a *Archive) Validate() error {
+	if a.Version != ArchiveVersion {
+		return ErrUnsupportedArchiveVersion.With("version", a.Version).With("supported_version", ArchiveVersion)
+	}
+
+	categories := make(map[uuid.UUID]bool, len(a.Categories))
+	for _, c := range a.Categories {
+		categories[c.ID] = true
+	}
+	for _, c := range a.Categories {
+		if c.ParentID != nil && !categories[*c.ParentID] {
+			return invalid("category %s has an unknown parent", c.ID)
+		}
+	}
+
+	accounts := make(map[uuid.UUID]ledger.AccountKind, len(a.Accounts))
+	for _, acc := range a.Accounts {
+		if !money.IsKnownCurrency(acc.Currency) {
+			return invalid("account %s has an unsupported currency", acc.ID)
+		}
+		if !slices.Contains(ledger.AccountKind("").Values(), string(acc.Kind)) {
+			return invalid("account %s has an invalid kind", acc.ID)
+		}
+		accounts[acc.ID] = acc.Kind
+	}
+
+	transactions := make(map[uuid.UUID]bool, len(a.Transactions))
+	for _, tx := range a.Transactions {
+		if err := validateTransaction(tx, accounts, categories); err != nil {
+			return err
+		}
+		transactions[tx.ID] = true
+	}
+
+	// Only settled transactions are rolled into an opening balance, and a transaction is either live or archived
+	for _, tx := range a.ArchivedTransactions {
+		if err := validateTransaction(tx, accounts, categories); err != nil {
+			return err
+		}
+		if tx.PaidAt == nil {
+			return invalid("archived transaction %s is not paid", tx.ID)
+		}
+		if transactions[tx.ID] {
+			return invalid("transaction %s is both live and archived", tx.ID)
+		}
+	}
+
+	for _, b := range a.Budgets {
+		if !categories[b.CategoryID] {
+			return invalid("budget %s has an unknown category", b.ID)
+		}
+		if !money.IsKnownCurrency(b.Currency) {
+			return invalid("budget %s has an unsupported currency", b.ID)
+		}
+	}
+
+	for _, p := range a.Positions {
+		if kind, ok := accounts[p.AccountID]; !ok || kind != ledger.Investment {
+			return invalid("position %s is not held by an investment account of the archive", p.ID)
+		}
+		if !money.IsKnownCurrency(p.Currency) {
+			return invalid("position %s has an unsupported currency", p.ID)
+		}
+	}
+
+	return nil
+}
+
+// validateTransaction checks the references, the type and the tags of a live or archived transaction
+func validateTransaction(tx TransactionRecord, accounts map[uuid.UUID]ledger.AccountKind, categories map[uuid.UUID]bool) error {
+	if _, ok := accounts[tx.AccountID]; !ok {
+		return invalid("transaction %s has an unknown account", tx.ID)
+	}
+	if tx.CategoryID != nil && !categories[*tx.CategoryID] {
+		return invalid("transaction %s has an unknown category", tx.ID)
+	}
+	if !slices.Contains(ledger.TransactionType("").Values(), string(tx.Type)) {
+		return invalid("transaction %s has an invalid type", tx.ID)
+	}
+	// Tags are exported normalized, anything else would split a tag in the reports
+	if tags, err := ledger.NormalizeTags(tx.Tags); err != nil || !slices.Equal(tags, tx.Tags) {
+		return invalid("transaction %s has invalid tags", tx.ID)
+	}
+	return nil
+}
+
+// invalid builds an ErrInvalidArchive describing the first problem found
+func invalid(format string, args ...any) error {
+	return ErrInvalidArchive.With("reason", fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RestorePath is the route restoring archives, exempt from the default body limit since archives hold the whole history
+const RestorePath = "/api/v1/restore"
+
+// BackupHandler holds dependencies for backup-related HTTP handlers
+type BackupHandler struct {
+	backupService  *Service
+	maxRestoreSize string
+}
+
+// NewBackupHandler creates a new instance of BackupHandler, accepting archives up to maxRestoreSize (e.g., "50MB")
+func NewBackupHandler(backupService *Service, maxRestoreSize string) *BackupHandler {
+	return &BackupHandler{backupService: backupService, maxRestoreSize: maxRestoreSize}
+}
+
+// RegisterRoutes sets up the API routes for the backup module
+func (h *BackupHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.GET("/backup", h.exportHandler)
+	apiRouteGroup.POST("/restore", h.restoreHandler, middleware.BodyLimit(h.maxRestoreSize))
+}
+
+// exportHandler handles the HTTP request for downloading the archive of the user's data
+func (h *BackupHandler) exportHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	archive, err := h.backupService.Export(c.Request().Context(), userID, httpx.MaxExportRows(c))
+	if err != nil {
+		return err
+	}
+
+	// The archive is sent as is, outside the success envelope, so the downloaded file can be restored unchanged
+	filename := fmt.Sprintf("fintrack-backup-%s.json", archive.ExportedAt.Format("2006-01-02"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSON(http.StatusOK, archive)
+}
+
+// restoreHandler handles the HTTP request for restoring an archive, replying 202 Accepted with the restore job
+func (h *BackupHandler) restoreHandler(c echo.Context) error {
+	var archive Archive
+	if err := c.Bind(&archive); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid backup archive format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.backupService.StartRestore(c.Request().Context(), userID, &archive)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendAccepted(c, jobs.ToJobResponse(job))
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
@@ -0,0 +1,32 @@
+package backup
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the backup repository, service and handler from the shared dependencies
+type Module struct {
+	handler *BackupHandler
+}
+
+// NewModule creates the backup module
+func NewModule(deps module.Deps) *Module {
+	backupSvc := NewService(NewPostgresRepository(deps.Postgres.Pool, deps.Postgres, deps.Fields), deps.Jobs, deps.Audit, deps.Clock)
+
+	return &Module{
+		handler: NewBackupHandler(backupSvc, deps.Config.Backup.MaxRestoreSize),
+	}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "backup"
+}
+
+// RegisterRoutes mounts the backup routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
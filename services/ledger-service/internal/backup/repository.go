@@ -0,0 +1,679 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/fieldcrypt"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+// It reads and writes the tables of the other modules directly, so an archive is exported and restored atomically
+// Exports only read, so they are taken from the read pool, which may be a replica
+// The encrypted fields of the transactions are exported decrypted and encrypted again when restored
+type PostgresRepository struct {
+	pool   *pgxpool.Pool
+	reads  postgres.ReadRouter
+	fields *fieldcrypt.Cipher
+}
+
+// NewPostgresRepository creates a new PostgresRepository restoring into pool and exporting from reads
+// fields encrypts the sensitive fields of the transactions and may be nil when they are stored in plain text
+func NewPostgresRepository(pool *pgxpool.Pool, reads postgres.ReadRouter, fields *fieldcrypt.Cipher) *PostgresRepository {
+	return &PostgresRepository{pool: pool, reads: reads, fields: fields}
+}
+
+// ----- Export ----- //
+
+// Export reads every record of the user within a single read-only snapshot of the database
+func (r *PostgresRepository) Export(ctx context.Context, userID uuid.UUID, maxRows int) (*Archive, error) {
+	archive := &Archive{Version: ArchiveVersion}
+
+	err := pgx.BeginTxFunc(ctx, r.reads.ReadPool(), pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		var err error
+		if archive.Categories, err = exportCategories(ctx, tx, userID); err != nil {
+			return err
+		}
+		if archive.Accounts, err = exportAccounts(ctx, tx, userID); err != nil {
+			return err
+		}
+		if archive.Transactions, err = exportTransactions(ctx, tx, userID, maxRows); err != nil {
+			return err
+		}
+		if archive.ArchivedTransactions, err = exportArchivedTransactions(ctx, tx, userID, maxRows, len(archive.Transactions)); err != nil {
+			return err
+		}
+		if archive.Budgets, err = exportBudgets(ctx, tx, userID); err != nil {
+			return err
+		}
+		if archive.AlertThresholds, err = exportAlertThresholds(ctx, tx, userID); err != nil {
+			return err
+		}
+		if archive.Positions, err = exportPositions(ctx, tx, userID); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range archive.Transactions {
+		if err := r.openTransaction(ctx, userID, &archive.Transactions[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range archive.ArchivedTransactions {
+		if err := r.openTransaction(ctx, userID, &archive.ArchivedTransactions[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return archive, nil
+}
+
+// openTransaction decrypts the observation and the metadata of an exported transaction
+func (r *PostgresRepository) openTransaction(ctx context.Context, userID uuid.UUID, t *TransactionRecord) error {
+	observation, err := r.fields.OpenString(ctx, userID, t.Observation)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt transaction observation: %w", err)
+	}
+	metadata, err := r.fields.Open(ctx, userID, t.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt transaction metadata: %w", err)
+	}
+	t.Observation, t.Metadata = observation, metadata
+	return nil
+}
+
+// exportCategories reads the categories of the user plus the default ones (and their parents) referenced by its records
+func exportCategories(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]CategoryRecord, error) {
+	query := `
+		WITH RECURSIVE exported AS (
+			SELECT c.id, c.parent_id, c.name, c.user_id
+			FROM categories c
+			WHERE c.user_id = $1
+				OR (c.user_id IS NULL AND (
+					c.id IN (SELECT category_id FROM transactions WHERE user_id = $1)
+					OR c.id IN (SELECT category_id FROM archived_transactions WHERE user_id = $1)
+					OR c.id IN (SELECT category_id FROM budgets WHERE user_id = $1)
+				))
+			UNION
+			SELECT p.id, p.parent_id, p.name, p.user_id
+			FROM categories p
+			JOIN exported e ON p.id = e.parent_id
+			WHERE p.user_id = $1 OR p.user_id IS NULL
+		)
+		SELECT id, parent_id, name, user_id IS NULL FROM exported ORDER BY name, id
+	`
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories to export: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]CategoryRecord, 0)
+	for rows.Next() {
+		var c CategoryRecord
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.Name, &c.Default); err != nil {
+			return nil, fmt.Errorf("failed to scan category row: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category rows: %w", err)
+	}
+
+	return categories, nil
+}
+
+// exportAccounts reads the accounts of the user, archived ones included
+func exportAccounts(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]AccountRecord, error) {
+	query := `
+		SELECT id, name, currency, kind, statement_closing_day, include_in_overall_balance, archived_at, created_at
+		FROM accounts
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accounts to export: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]AccountRecord, 0)
+	for rows.Next() {
+		var (
+			a          AccountRecord
+			closingDay *int16
+		)
+		if err := rows.Scan(&a.ID, &a.Name, &a.Currency, &a.Kind, &closingDay, &a.IncludeInOverallBalance, &a.ArchivedAt, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account row: %w", err)
+		}
+		if closingDay != nil {
+			day := int(*closingDay)
+			a.StatementClosingDay = &day
+		}
+		a.Currency = strings.TrimSpace(a.Currency)
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account rows: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// exportTransactions reads the transactions of the user, dropping categories of other users it should never reference
+// One row more than maxRows is read, to tell a user at the limit from one over it
+func exportTransactions(ctx context.Context, tx pgx.Tx, userID uuid.UUID, maxRows int) ([]TransactionRecord, error) {
+	query := `
+		SELECT t.id, t.account_id, c.id, t.type, t.description, COALESCE(t.observation, ''), t.amount_in_cents, t.due_date, t.paid_at,
+			t.original_amount_in_cents, t.original_currency, t.exchange_rate::text, t.metadata,
+			ARRAY(
+				SELECT tg.name FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+				WHERE tt.transaction_id = t.id ORDER BY tg.name
+			)
+		FROM transactions t
+		LEFT JOIN categories c ON c.id = t.category_id AND (c.user_id = t.user_id OR c.user_id IS NULL)
+		WHERE t.user_id = $1
+		ORDER BY t.due_date, t.id
+		LIMIT $2
+	`
+
+	rows, err := tx.Query(ctx, query, userID, maxRows+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions to export: %w", err)
+	}
+
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) > maxRows {
+		return nil, ErrExportTooLarge.With("max_rows", maxRows)
+	}
+
+	return transactions, nil
+}
+
+// exportArchivedTransactions reads the archived history of the user, which counts towards the maxRows transactions
+// an export may hold along the exported live ones
+func exportArchivedTransactions(ctx context.Context, tx pgx.Tx, userID uuid.UUID, maxRows, exported int) ([]TransactionRecord, error) {
+	query := `
+		SELECT t.id, t.account_id, c.id, t.type, t.description, COALESCE(t.observation, ''), t.amount_in_cents, t.due_date, t.paid_at,
+			t.original_amount_in_cents, t.original_currency, t.exchange_rate::text, t.metadata, t.tags
+		FROM archived_transactions t
+		LEFT JOIN categories c ON c.id = t.category_id AND (c.user_id = t.user_id OR c.user_id IS NULL)
+		WHERE t.user_id = $1
+		ORDER BY t.due_date, t.id
+		LIMIT $2
+	`
+
+	rows, err := tx.Query(ctx, query, userID, maxRows-exported+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived transactions to export: %w", err)
+	}
+
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if exported+len(transactions) > maxRows {
+		return nil, ErrExportTooLarge.With("max_rows", maxRows)
+	}
+
+	return transactions, nil
+}
+
+// scanTransactions reads and closes the rows of a live or archived transactions export
+func scanTransactions(rows pgx.Rows) ([]TransactionRecord, error) {
+	defer rows.Close()
+
+	transactions := make([]TransactionRecord, 0)
+	for rows.Next() {
+		var t TransactionRecord
+		err := rows.Scan(&t.ID, &t.AccountID, &t.CategoryID, &t.Type, &t.Description, &t.Observation, &t.Amount, &t.DueDate, &t.PaidAt,
+			&t.OriginalAmount, &t.OriginalCurrency, &t.ExchangeRate, &t.Metadata, &t.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction row: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction rows: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// exportBudgets reads the category budgets of the user
+func exportBudgets(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]BudgetRecord, error) {
+	query := `SELECT id, category_id, amount_in_cents, currency FROM budgets WHERE user_id = $1 ORDER BY created_at, id`
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets to export: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := make([]BudgetRecord, 0)
+	for rows.Next() {
+		var b BudgetRecord
+		if err := rows.Scan(&b.ID, &b.CategoryID, &b.Amount, &b.Currency); err != nil {
+			return nil, fmt.Errorf("failed to scan budget row: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating budget rows: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// exportAlertThresholds reads the budget alert thresholds of the user, nil when they never changed the defaults
+func exportAlertThresholds(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]int, error) {
+	var thresholds []int32
+	err := tx.QueryRow(ctx, `SELECT thresholds FROM budget_alert_preferences WHERE user_id = $1`, userID).Scan(&thresholds)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch alert thresholds to export: %w", err)
+	}
+
+	result := make([]int, len(thresholds))
+	for i, t := range thresholds {
+		result[i] = int(t)
+	}
+	return result, nil
+}
+
+// exportPositions reads the investment positions of the user
+func exportPositions(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]PositionRecord, error) {
+	query := `
+		SELECT id, account_id, ticker, quantity, cost_basis_in_cents, currency
+		FROM investment_positions
+		WHERE user_id = $1
+		ORDER BY account_id, ticker
+	`
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query positions to export: %w", err)
+	}
+	defer rows.Close()
+
+	positions := make([]PositionRecord, 0)
+	for rows.Next() {
+		var p PositionRecord
+		if err := rows.Scan(&p.ID, &p.AccountID, &p.Ticker, &p.Quantity, &p.CostBasis, &p.Currency); err != nil {
+			return nil, fmt.Errorf("failed to scan position row: %w", err)
+		}
+		p.Currency = strings.TrimSpace(p.Currency)
+		positions = append(positions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating position rows: %w", err)
+	}
+
+	return positions, nil
+}
+
+// ----- Restore ----- //
+
+// Restore upserts the records of the archive by ID in a single transaction, keeping the records missing from it
+// Default categories missing on this instance are recreated as categories of the user, with IDs derived from the
+// archived ones so restoring the archive again finds them
+func (r *PostgresRepository) Restore(ctx context.Context, userID uuid.UUID, archive *Archive) (*RestoreResult, error) {
+	result := &RestoreResult{}
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		categoryIDs, err := restoreCategories(ctx, tx, userID, archive.Categories)
+		if err != nil {
+			return err
+		}
+		result.Categories = len(archive.Categories)
+
+		accounts := &pgx.Batch{}
+		for _, a := range archive.Accounts {
+			accounts.Queue(`
+				INSERT INTO accounts (id, user_id, name, currency, kind, statement_closing_day, include_in_overall_balance, archived_at, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (id)
+				DO UPDATE SET
+					name = EXCLUDED.name,
+					currency = EXCLUDED.currency,
+					kind = EXCLUDED.kind,
+					statement_closing_day = EXCLUDED.statement_closing_day,
+					include_in_overall_balance = EXCLUDED.include_in_overall_balance,
+					archived_at = EXCLUDED.archived_at,
+					updated_at = now()
+				WHERE accounts.user_id = EXCLUDED.user_id
+			`, a.ID, userID, a.Name, a.Currency, a.Kind, a.StatementClosingDay, a.IncludeInOverallBalance, a.ArchivedAt, a.CreatedAt)
+		}
+		if result.Accounts, err = execOwned(ctx, tx, accounts, "account"); err != nil {
+			return err
+		}
+
+		// A transaction the archive holds live was rolled into an opening balance here since, or the other way around:
+		// the copy of the archive replaces the other one, so the balances of the archive are restored too
+		if err := dropCopies(ctx, tx, userID, "archived_transactions", archive.Transactions); err != nil {
+			return err
+		}
+		if err := dropCopies(ctx, tx, userID, "transactions", archive.ArchivedTransactions); err != nil {
+			return err
+		}
+
+		transactions := &pgx.Batch{}
+		for _, t := range archive.Transactions {
+			observation, err := r.fields.SealString(ctx, userID, t.Observation)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt transaction observation: %w", err)
+			}
+			metadata, err := r.fields.Seal(ctx, userID, t.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt transaction metadata: %w", err)
+			}
+			transactions.Queue(`
+				INSERT INTO transactions (
+					id, account_id, user_id, category_id, type, description, observation, amount_in_cents, due_date, paid_at,
+					original_amount_in_cents, original_currency, exchange_rate, metadata
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::numeric, $14)
+				ON CONFLICT (id)
+				DO UPDATE SET
+					account_id = EXCLUDED.account_id,
+					category_id = EXCLUDED.category_id,
+					type = EXCLUDED.type,
+					description = EXCLUDED.description,
+					observation = EXCLUDED.observation,
+					amount_in_cents = EXCLUDED.amount_in_cents,
+					due_date = EXCLUDED.due_date,
+					paid_at = EXCLUDED.paid_at,
+					original_amount_in_cents = EXCLUDED.original_amount_in_cents,
+					original_currency = EXCLUDED.original_currency,
+					exchange_rate = EXCLUDED.exchange_rate,
+					metadata = EXCLUDED.metadata,
+					updated_at = now()
+				WHERE transactions.user_id = EXCLUDED.user_id
+			`, t.ID, t.AccountID, userID, mapCategoryID(categoryIDs, t.CategoryID), t.Type, t.Description, observation, t.Amount, t.DueDate, t.PaidAt,
+				t.OriginalAmount, t.OriginalCurrency, t.ExchangeRate, metadata)
+		}
+		if result.Transactions, err = execOwned(ctx, tx, transactions, "transaction"); err != nil {
+			return err
+		}
+		if err := restoreTransactionTags(ctx, tx, userID, archive.Transactions); err != nil {
+			return err
+		}
+
+		// The archived history keeps its tags by name, so it needs no links to the tags of the user
+		archived := &pgx.Batch{}
+		for _, t := range archive.ArchivedTransactions {
+			observation, err := r.fields.SealString(ctx, userID, t.Observation)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt transaction observation: %w", err)
+			}
+			metadata, err := r.fields.Seal(ctx, userID, t.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt transaction metadata: %w", err)
+			}
+			tags := t.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			archived.Queue(`
+				INSERT INTO archived_transactions (
+					id, account_id, user_id, category_id, type, description, observation, amount_in_cents, due_date, paid_at,
+					original_amount_in_cents, original_currency, exchange_rate, metadata, tags
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::numeric, $14, $15)
+				ON CONFLICT (id)
+				DO UPDATE SET
+					account_id = EXCLUDED.account_id,
+					category_id = EXCLUDED.category_id,
+					type = EXCLUDED.type,
+					description = EXCLUDED.description,
+					observation = EXCLUDED.observation,
+					amount_in_cents = EXCLUDED.amount_in_cents,
+					due_date = EXCLUDED.due_date,
+					paid_at = EXCLUDED.paid_at,
+					original_amount_in_cents = EXCLUDED.original_amount_in_cents,
+					original_currency = EXCLUDED.original_currency,
+					exchange_rate = EXCLUDED.exchange_rate,
+					metadata = EXCLUDED.metadata,
+					tags = EXCLUDED.tags
+				WHERE archived_transactions.user_id = EXCLUDED.user_id
+			`, t.ID, t.AccountID, userID, mapCategoryID(categoryIDs, t.CategoryID), t.Type, t.Description, observation, t.Amount, t.DueDate, t.PaidAt,
+				t.OriginalAmount, t.OriginalCurrency, t.ExchangeRate, metadata, tags)
+		}
+		if result.ArchivedTransactions, err = execOwned(ctx, tx, archived, "archived transaction"); err != nil {
+			return err
+		}
+
+		// A category has a single budget per user, so budgets are matched by category rather than by ID
+		budgets := &pgx.Batch{}
+		for _, b := range archive.Budgets {
+			budgets.Queue(`
+				INSERT INTO budgets (id, user_id, category_id, amount_in_cents, currency)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (user_id, category_id)
+				DO UPDATE SET amount_in_cents = EXCLUDED.amount_in_cents, currency = EXCLUDED.currency, updated_at = now()
+			`, b.ID, userID, categoryIDs[b.CategoryID], b.Amount, b.Currency)
+		}
+		if result.Budgets, err = execOwned(ctx, tx, budgets, "budget"); err != nil {
+			return err
+		}
+
+		if archive.AlertThresholds != nil {
+			thresholds := make([]int32, len(archive.AlertThresholds))
+			for i, t := range archive.AlertThresholds {
+				thresholds[i] = int32(t)
+			}
+			_, err := tx.Exec(ctx, `
+				INSERT INTO budget_alert_preferences (user_id, thresholds)
+				VALUES ($1, $2)
+				ON CONFLICT (user_id) DO UPDATE SET thresholds = EXCLUDED.thresholds, updated_at = now()
+			`, userID, thresholds)
+			if err != nil {
+				return fmt.Errorf("failed to restore alert thresholds: %w", err)
+			}
+		}
+
+		// The accounts were restored as the user's above, so the positions are matched by account and ticker
+		positions := &pgx.Batch{}
+		for _, p := range archive.Positions {
+			positions.Queue(`
+				INSERT INTO investment_positions (id, user_id, account_id, ticker, quantity, cost_basis_in_cents, currency)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (account_id, ticker)
+				DO UPDATE SET
+					quantity = EXCLUDED.quantity,
+					cost_basis_in_cents = EXCLUDED.cost_basis_in_cents,
+					currency = EXCLUDED.currency,
+					updated_at = now()
+			`, p.ID, userID, p.AccountID, p.Ticker, p.Quantity, p.CostBasis, p.Currency)
+		}
+		if result.Positions, err = execOwned(ctx, tx, positions, "position"); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, toRestoreError(err)
+	}
+
+	return result, nil
+}
+
+// restoreCategories upserts the categories of the archive, returning the ID each archived category got on this instance
+// Parents are linked once every category exists, so the archive order does not matter
+func restoreCategories(ctx context.Context, tx pgx.Tx, userID uuid.UUID, categories []CategoryRecord) (map[uuid.UUID]uuid.UUID, error) {
+	var defaultIDs []uuid.UUID
+	for _, c := range categories {
+		if c.Default {
+			defaultIDs = append(defaultIDs, c.ID)
+		}
+	}
+
+	existingDefaults := make(map[uuid.UUID]bool, len(defaultIDs))
+	if len(defaultIDs) > 0 {
+		rows, err := tx.Query(ctx, `SELECT id FROM categories WHERE id = ANY($1) AND user_id IS NULL`, defaultIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query default categories: %w", err)
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan default category row: %w", err)
+			}
+			existingDefaults[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating default category rows: %w", err)
+		}
+	}
+
+	ids := make(map[uuid.UUID]uuid.UUID, len(categories))
+	for _, c := range categories {
+		ids[c.ID] = c.ID
+		if c.Default && !existingDefaults[c.ID] {
+			ids[c.ID] = uuid.NewSHA1(userID, c.ID[:])
+		}
+	}
+
+	inserts := &pgx.Batch{}
+	parents := &pgx.Batch{}
+	for _, c := range categories {
+		if existingDefaults[c.ID] {
+			continue
+		}
+		inserts.Queue(`
+			INSERT INTO categories (id, user_id, name)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name
+			WHERE categories.user_id = EXCLUDED.user_id
+		`, ids[c.ID], userID, c.Name)
+		parents.Queue(`UPDATE categories SET parent_id = $2 WHERE id = $1 AND user_id = $3`, ids[c.ID], mapCategoryID(ids, c.ParentID), userID)
+	}
+
+	if _, err := execOwned(ctx, tx, inserts, "category"); err != nil {
+		return nil, err
+	}
+	if _, err := execOwned(ctx, tx, parents, "category"); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// restoreTransactionTags replaces the tags of the restored transactions, creating the tags the user did not have yet
+func restoreTransactionTags(ctx context.Context, tx pgx.Tx, userID uuid.UUID, transactions []TransactionRecord) error {
+	batch := &pgx.Batch{}
+	names := make(map[string]bool)
+	for _, t := range transactions {
+		for _, name := range t.Tags {
+			if !names[name] {
+				names[name] = true
+				batch.Queue(`
+					INSERT INTO tags (id, user_id, name) VALUES ($1, $2, $3)
+					ON CONFLICT (user_id, name) DO NOTHING
+				`, uuid.New(), userID, name)
+			}
+		}
+	}
+	for _, t := range transactions {
+		batch.Queue(`DELETE FROM transaction_tags WHERE transaction_id = $1`, t.ID)
+		if len(t.Tags) > 0 {
+			batch.Queue(`
+				INSERT INTO transaction_tags (transaction_id, tag_id)
+				SELECT $1, id FROM tags WHERE user_id = $2 AND name = ANY($3)
+			`, t.ID, userID, t.Tags)
+		}
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to restore transaction tags: %w", err)
+	}
+	return nil
+}
+
+// dropCopies deletes from table the copies the user holds of the given transactions
+func dropCopies(ctx context.Context, tx pgx.Tx, userID uuid.UUID, table string, transactions []TransactionRecord) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1 AND id = ANY($2)`, userID, ids); err != nil {
+		return fmt.Errorf("failed to drop the replaced copies from %s: %w", table, err)
+	}
+	return nil
+}
+
+// execOwned runs a batch of upserts, each expected to write one row
+// An upsert writing nothing hit a record of another user, whose ID the archive reuses
+func execOwned(ctx context.Context, tx pgx.Tx, batch *pgx.Batch, resource string) (int, error) {
+	if batch.Len() == 0 {
+		return 0, nil
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for range batch.Len() {
+		tag, err := br.Exec()
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore %s: %w", resource, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrRestoreConflict.With("resource", resource)
+		}
+	}
+
+	return batch.Len(), br.Close()
+}
+
+// mapCategoryID returns the restored ID of an optional category reference
+func mapCategoryID(ids map[uuid.UUID]uuid.UUID, categoryID *uuid.UUID) *uuid.UUID {
+	if categoryID == nil {
+		return nil
+	}
+	id := ids[*categoryID]
+	return &id
+}
+
+// toRestoreError turns the constraint violations of archived records into an ErrInvalidArchive
+// Integrity (class 23) and data (class 22) errors mean the archive holds records the ledger would never write
+func toRestoreError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")) {
+		reason := pgErr.ConstraintName
+		if reason == "" {
+			reason = pgErr.Message
+		}
+		return ErrInvalidArchive.With("reason", reason)
+	}
+	return err
+}
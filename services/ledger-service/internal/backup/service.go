@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/budgets"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the backups
+const (
+	auditBackupExported = "backup.exported"
+	auditBackupRestored = "backup.restored"
+	auditResourceUser   = "user"
+
+	// restoreJobKind identifies the restore jobs on the job status endpoint
+	restoreJobKind = "backup_restore"
+)
+
+// Service exports all the data of a user as an Archive and restores it, on this instance or another one
+type Service struct {
+	repo    Repository
+	jobs    *jobs.Service
+	auditor *audit.Logger
+	clock   clock.Clock
+}
+
+// NewService creates a new instance of the backup Service
+func NewService(repo Repository, jobs *jobs.Service, auditor *audit.Logger, clock clock.Clock) *Service {
+	return &Service{
+		repo:    repo,
+		jobs:    jobs,
+		auditor: auditor,
+		clock:   clock,
+	}
+}
+
+// Export is the use case for producing the archive of every record of the user, up to maxRows transactions
+func (s *Service) Export(ctx context.Context, userID uuid.UUID, maxRows int) (*Archive, error) {
+	archive, err := s.repo.Export(ctx, userID, maxRows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export backup: %w", err)
+	}
+	archive.ExportedAt = s.clock.Now()
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditBackupExported,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Metadata:     archiveCounts(archive),
+	})
+
+	return archive, nil
+}
+
+// StartRestore is the use case for restoring an archive in the background, once it is known to be valid
+// The restore merges the archive into the current data: its records are created or replaced, the others are kept
+func (s *Service) StartRestore(ctx context.Context, userID uuid.UUID, archive *Archive) (*jobs.Job, error) {
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+	if archive.AlertThresholds != nil {
+		prefs := budgets.DefaultAlertPreferences(userID)
+		if err := prefs.SetThresholds(archive.AlertThresholds, s.clock); err != nil {
+			return nil, err
+		}
+		archive.AlertThresholds = prefs.Thresholds
+	}
+
+	job, err := s.jobs.Enqueue(ctx, userID, restoreJobKind, func(ctx context.Context, progress jobs.ProgressFunc) (any, error) {
+		result, err := s.repo.Restore(ctx, userID, archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore backup: %w", err)
+		}
+
+		s.auditor.Record(ctx, audit.Entry{
+			Action:       auditBackupRestored,
+			ResourceType: auditResourceUser,
+			ResourceID:   userID.String(),
+			Metadata:     archiveCounts(archive),
+		})
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start backup restore: %w", err)
+	}
+
+	return job, nil
+}
+
+// archiveCounts summarizes the archive for the audit records
+func archiveCounts(archive *Archive) map[string]string {
+	return map[string]string{
+		"version":               strconv.Itoa(archive.Version),
+		"categories":            strconv.Itoa(len(archive.Categories)),
+		"accounts":              strconv.Itoa(len(archive.Accounts)),
+		"transactions":          strconv.Itoa(len(archive.Transactions)),
+		"archived_transactions": strconv.Itoa(len(archive.ArchivedTransactions)),
+		"budgets":               strconv.Itoa(len(archive.Budgets)),
+		"positions":             strconv.Itoa(len(archive.Positions)),
+	}
+}
@@ -0,0 +1,278 @@
+package bankconnect
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrConsentNotFound       = errx.New(errx.CategoryNotFound, "BANK_CONSENT_NOT_FOUND", "bank consent not found")
+	ErrConsentNotActive      = errx.New(errx.CategoryConflict, "BANK_CONSENT_NOT_ACTIVE", "the bank consent was revoked or expired")
+	ErrConsentAlreadyExists  = errx.New(errx.CategoryConflict, "BANK_CONSENT_ALREADY_EXISTS", "the bank connection was already added")
+	ErrUnsupportedProvider   = errx.New(errx.CategoryValidation, "UNSUPPORTED_BANK_PROVIDER", "the bank connection provider is not configured")
+	ErrRemoteAccountNotFound = errx.New(errx.CategoryNotFound, "BANK_ACCOUNT_NOT_FOUND", "the bank account was not found in the connection")
+	ErrLinkNotFound          = errx.New(errx.CategoryNotFound, "BANK_ACCOUNT_LINK_NOT_FOUND", "the bank account is not linked to an account")
+	ErrAccountAlreadyLinked  = errx.New(errx.CategoryConflict, "ACCOUNT_ALREADY_LINKED", "the account is already linked to a bank account")
+	ErrLinkCurrencyMismatch  = errx.New(errx.CategoryValidation, "BANK_ACCOUNT_CURRENCY_MISMATCH", "the bank account and the account have different currencies")
+	ErrLinkKindMismatch      = errx.New(errx.CategoryValidation, "BANK_ACCOUNT_KIND_MISMATCH", "bank credit cards link to credit card accounts, other bank accounts to checking accounts")
+	ErrProviderUnavailable   = errx.New(errx.CategoryUnavailable, "BANK_PROVIDER_UNAVAILABLE", "the bank connection provider is unavailable")
+	ErrInvalidConsentToken   = errx.New(errx.CategoryValidation, "INVALID_BANK_CONSENT_TOKEN", "the token returned by the bank connection provider is invalid or expired")
+	ErrSyncInProgress        = errx.New(errx.CategoryConflict, "BANK_SYNC_IN_PROGRESS", "the bank connection is already being synced")
+	ErrInvalidWebhook        = errx.New(errx.CategoryUnauthenticated, "INVALID_BANK_WEBHOOK", "the bank webhook signature is invalid")
+	ErrMalformedWebhook      = errx.New(errx.CategoryValidation, "MALFORMED_BANK_WEBHOOK", "the bank webhook payload is malformed")
+)
+
+const (
+	// InitialSyncWindow is how far back the first sync of a linked account reads its transactions
+	InitialSyncWindow = 90 * 24 * time.Hour
+	// syncOverlap reads again the last days of every sync, since banks post some transactions days after their date
+	syncOverlap = 7 * 24 * time.Hour
+
+	// MatchWindow is how many days a bank transaction may be dated away from the ledger transaction it matches
+	MatchWindow = 3
+	// staleSyncRunAfter is when a running sync is considered interrupted (e.g., by a restart), so the consent can sync again
+	staleSyncRunAfter = time.Hour
+	// syncRunRetention is how long the sync runs are kept
+	syncRunRetention = 90 * 24 * time.Hour
+)
+
+// Provider identifies the aggregator holding the bank consent (e.g., Pluggy)
+type Provider string
+
+const (
+	Pluggy Provider = "PLUGGY" // Pluggy aggregates the Open Finance Brasil banks
+	Plaid  Provider = "PLAID"  // Plaid aggregates US and European banks
+)
+
+// Values returns the known providers, used by the enum validation
+func (Provider) Values() []string {
+	return []string{string(Pluggy), string(Plaid)}
+}
+
+// ConsentStatus is the stage of a consent
+type ConsentStatus string
+
+const (
+	ConsentActive  ConsentStatus = "ACTIVE"
+	ConsentRevoked ConsentStatus = "REVOKED" // ConsentRevoked was revoked by the user, its data is kept but never synced again
+	ConsentExpired ConsentStatus = "EXPIRED" // ConsentExpired reached its expiration, the user must consent again on the provider
+)
+
+// RemoteAccountType tells how a bank account maps to the ledger account kinds
+type RemoteAccountType string
+
+const (
+	RemoteChecking   RemoteAccountType = "CHECKING" // RemoteChecking covers checking and savings accounts
+	RemoteCreditCard RemoteAccountType = "CREDIT_CARD"
+)
+
+// SyncTrigger tells what started a sync run
+type SyncTrigger string
+
+const (
+	SyncManual    SyncTrigger = "MANUAL"    // SyncManual was requested by the user
+	SyncScheduled SyncTrigger = "SCHEDULED" // SyncScheduled was started by the periodic sync
+	SyncWebhook   SyncTrigger = "WEBHOOK"   // SyncWebhook was started by the provider signaling new data
+)
+
+// SyncRunStatus is the stage of a sync run
+type SyncRunStatus string
+
+const (
+	SyncRunning   SyncRunStatus = "RUNNING"
+	SyncSucceeded SyncRunStatus = "SUCCEEDED"
+	SyncFailed    SyncRunStatus = "FAILED" // SyncFailed failed on some accounts, the others were synced
+)
+
+// Connector reads the accounts of a consent through an aggregator
+// Consents are given by the user in the provider's widget, opened with a link token and answering with a token to connect
+// Amounts are signed from the account's point of view: money leaving a checking account and credit card charges are negative
+type Connector interface {
+	Provider() Provider
+	// CreateLinkToken creates the token opening the provider's widget for the user
+	CreateLinkToken(ctx context.Context, userID uuid.UUID) (*LinkToken, error)
+	// Connect turns the token returned by the widget into the credentials of the consent, failing with ErrInvalidConsentToken
+	Connect(ctx context.Context, token string) (*ConsentCredentials, error)
+	// Accounts lists the bank accounts shared by the consent
+	Accounts(ctx context.Context, consent *Consent) ([]RemoteAccount, error)
+	// Balance returns the current balance of a bank account, negative for the debt of credit cards
+	Balance(ctx context.Context, consent *Consent, remoteAccountID string) (money.Money, error)
+	// Transactions lists the transactions of a bank account dated from the given day on
+	Transactions(ctx context.Context, consent *Consent, remoteAccountID string, from time.Time) ([]RemoteTransaction, error)
+	// Revoke deletes the consent on the provider, succeeding when it is already gone
+	Revoke(ctx context.Context, consent *Consent) error
+	// ParseWebhook verifies a webhook sent by the provider, failing with ErrInvalidWebhook, and returns the consent
+	// it signals new data for, nil for the events calling for no sync
+	ParseWebhook(ctx context.Context, header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// LinkToken opens the provider's widget, where the user picks a bank and gives the consent
+type LinkToken struct {
+	Provider  Provider
+	Token     string
+	ExpiresAt time.Time
+}
+
+// WebhookEvent is a webhook of a provider signaling a consent has new data to sync
+type WebhookEvent struct {
+	ExternalID string // ExternalID identifies the consent on the provider
+	Name       string // Name is the event name of the provider (e.g., "transactions/created"), for the logs
+}
+
+// ConsentCredentials identify a consent on the provider
+type ConsentCredentials struct {
+	ExternalID string
+	Secret     string     // Secret authenticates the calls about the consent (e.g., the Plaid access token), empty when the provider needs none
+	ExpiresAt  *time.Time // ExpiresAt is nil when the provider does not report an expiration
+}
+
+// Repository persists the consents, the links between bank accounts and ledger accounts, and the transactions already synced
+type Repository interface {
+	// SaveConsent inserts the consent, failing with ErrConsentAlreadyExists when the user already added it
+	SaveConsent(ctx context.Context, consent *Consent) error
+	FindConsentByID(ctx context.Context, userID, consentID uuid.UUID) (*Consent, error)
+	FindConsentsByUserID(ctx context.Context, userID uuid.UUID) ([]*Consent, error)
+	// FindConsentsByExternalID returns the consents identified by externalID on the provider, for its webhooks
+	FindConsentsByExternalID(ctx context.Context, provider Provider, externalID string) ([]*Consent, error)
+	// FindActiveConsents returns the active consents of every user, for the scheduled sync
+	FindActiveConsents(ctx context.Context) ([]*Consent, error)
+	UpdateConsentStatus(ctx context.Context, consentID uuid.UUID, status ConsentStatus) error
+	// UpdateConsentSync records the outcome of a sync, an empty syncErr clearing the last error
+	UpdateConsentSync(ctx context.Context, consentID uuid.UUID, syncedAt time.Time, syncErr string) error
+
+	// SaveLink inserts or replaces the link of a bank account, failing with ErrAccountAlreadyLinked when the ledger account has another link
+	SaveLink(ctx context.Context, link *Link) error
+	FindLinksByConsentID(ctx context.Context, consentID uuid.UUID) ([]*Link, error)
+	DeleteLink(ctx context.Context, consentID uuid.UUID, remoteAccountID string) error
+	UpdateLinkSyncedAt(ctx context.Context, linkID uuid.UUID, syncedAt time.Time) error
+
+	// ClaimTransactions records the transactions as synced for the link, returning the ones no earlier sync recorded
+	// Claiming before importing keeps two concurrent syncs from importing the same transaction twice
+	ClaimTransactions(ctx context.Context, linkID uuid.UUID, remoteTransactionIDs []string) ([]string, error)
+	// ReleaseTransactions forgets claimed transactions whose import failed, so the next sync imports them
+	ReleaseTransactions(ctx context.Context, linkID uuid.UUID, remoteTransactionIDs []string) error
+	// RecordLedgerTransactions records the ledger transaction each synced transaction was imported as or matched to
+	RecordLedgerTransactions(ctx context.Context, linkID uuid.UUID, ledgerTransactionIDs map[string]uuid.UUID) error
+	// FindLedgerTransactionIDs returns the ledger transactions already tied to a synced transaction of the link
+	FindLedgerTransactionIDs(ctx context.Context, linkID uuid.UUID) ([]uuid.UUID, error)
+
+	// StartSyncRun inserts a running sync run, failing with ErrSyncInProgress when the consent has one running
+	// Runs left running since before staleBefore are failed first, and runs older than the retention are pruned
+	StartSyncRun(ctx context.Context, run *SyncRun, staleBefore, pruneBefore time.Time) error
+	// FinishSyncRun records the outcome of a sync run
+	FinishSyncRun(ctx context.Context, run *SyncRun) error
+	// FindSyncRuns returns a page of the sync runs of the consent, newest first
+	FindSyncRuns(ctx context.Context, consentID uuid.UUID, limit, offset int) ([]*SyncRun, error)
+}
+
+// Consent is the authorization given by the user on an aggregator to read the accounts of a bank
+type Consent struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Provider     Provider
+	ExternalID   string // ExternalID identifies the consent on the provider (e.g., the Pluggy item ID)
+	Secret       string // Secret authenticates the calls about the consent, stored encrypted and never returned by the API
+	Status       ConsentStatus
+	ExpiresAt    *time.Time // ExpiresAt is nil when the provider does not report an expiration
+	LastSyncedAt *time.Time
+	LastError    string // LastError is the reason the last sync failed, empty when it succeeded
+	CreatedAt    time.Time
+}
+
+// IsActive reports whether the consent can still be synced at now
+func (c *Consent) IsActive(now time.Time) bool {
+	return c.Status == ConsentActive && (c.ExpiresAt == nil || now.Before(*c.ExpiresAt))
+}
+
+// Link syncs a bank account of a consent into a ledger account
+type Link struct {
+	ID              uuid.UUID
+	ConsentID       uuid.UUID
+	UserID          uuid.UUID
+	RemoteAccountID string
+	AccountID       uuid.UUID
+	LastSyncedAt    *time.Time
+	CreatedAt       time.Time
+}
+
+// syncFrom returns the first day read by the next sync of the link
+func (l *Link) syncFrom(now time.Time) time.Time {
+	if l.LastSyncedAt == nil {
+		return now.Add(-InitialSyncWindow).Truncate(24 * time.Hour)
+	}
+	return l.LastSyncedAt.Add(-syncOverlap).Truncate(24 * time.Hour)
+}
+
+// RemoteAccount is a bank account shared by a consent
+type RemoteAccount struct {
+	ID       string
+	Name     string
+	Type     RemoteAccountType
+	Currency string
+	Balance  money.Money
+}
+
+// RemoteTransaction is a transaction of a bank account, pending ones may still change or disappear
+type RemoteTransaction struct {
+	ID          string
+	Date        time.Time
+	Description string
+	Amount      money.Money
+	Pending     bool
+	Pix         *ledger.PixDetails // Pix is set for PIX transfers whose identifiers the connector could read
+}
+
+// SyncResult counts what a sync did with the bank transactions
+type SyncResult struct {
+	Fetched     int `json:"fetched"`     // Fetched is the number of posted bank transactions read
+	Imported    int `json:"imported"`    // Imported is the number of bank transactions added to the ledger
+	Matched     int `json:"matched"`     // Matched is the number of bank transactions matched to a transaction already in the ledger
+	Duplicates  int `json:"duplicates"`  // Duplicates is the number of bank transactions an earlier sync already handled
+	Categorized int `json:"categorized"` // Categorized is the number of imported transactions categorized by a rule
+	Adjusted    int `json:"adjusted"`    // Adjusted is the number of accounts whose balance was reconciled with the bank
+}
+
+// SyncRun is the log of a sync of a consent
+type SyncRun struct {
+	ID         uuid.UUID
+	ConsentID  uuid.UUID
+	UserID     uuid.UUID
+	Trigger    SyncTrigger
+	Status     SyncRunStatus
+	Result     SyncResult
+	Error      string // Error is the reason the run failed, empty when it succeeded
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// finish records the outcome of the run at now
+func (r *SyncRun) finish(now time.Time, err error) {
+	r.Status = SyncSucceeded
+	if err != nil {
+		r.Status = SyncFailed
+		r.Error = err.Error()
+	}
+	r.FinishedAt = &now
+}
+
+// currencyOr returns the currency code reported by a provider, or fallback when it is omitted
+func currencyOr(code, fallback string) string {
+	if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+		return code
+	}
+	return fallback
+}
+
+// decimalMoney converts a decimal amount of a provider API to Money, rounded to the minor unit of its currency
+func decimalMoney(amount float64, currency string) money.Money {
+	scale := math.Pow10(money.MinorUnits(currency))
+	return money.New(int64(math.Round(amount*scale)), currency)
+}
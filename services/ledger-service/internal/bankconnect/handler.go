@@ -0,0 +1,427 @@
+package bankconnect
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxWebhookBodySize bounds the webhook bodies read before their signature is verified
+const maxWebhookBodySize = 1 << 20
+
+// BankConnectHandler holds dependencies for bank connection HTTP handlers
+type BankConnectHandler struct {
+	bankConnectService *Service
+}
+
+// NewBankConnectHandler creates a new instance of BankConnectHandler
+func NewBankConnectHandler(bankConnectService *Service) *BankConnectHandler {
+	return &BankConnectHandler{bankConnectService: bankConnectService}
+}
+
+// RegisterRoutes sets up the API routes for the bank connect module
+func (h *BankConnectHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	consentsGroup := apiRouteGroup.Group("/bank-consents")
+
+	consentsGroup.POST("/link-token", h.createLinkTokenHandler)
+	consentsGroup.POST("", h.connectHandler)
+	consentsGroup.GET("", h.listConsentsHandler)
+	consentsGroup.DELETE("/:id", h.revokeHandler)
+	consentsGroup.GET("/:id/accounts", h.listAccountsHandler)
+	consentsGroup.PUT("/:id/accounts/:remoteAccountId/link", h.linkHandler)
+	consentsGroup.DELETE("/:id/accounts/:remoteAccountId/link", h.unlinkHandler)
+	consentsGroup.POST("/:id/sync", h.syncHandler)
+	consentsGroup.GET("/:id/sync-runs", h.listSyncRunsHandler)
+}
+
+// RegisterWebhookRoutes sets up the unauthenticated routes receiving the webhooks of the providers, verified by their signature
+func (h *BankConnectHandler) RegisterWebhookRoutes(webhooksGroup *echo.Group) {
+	webhooksGroup.POST("/banks/:provider", h.webhookHandler)
+}
+
+// LinkTokenRequest defines the expected JSON body for opening the widget of a provider
+type LinkTokenRequest struct {
+	Provider Provider `json:"provider" validate:"required,enum"`
+}
+
+// ConnectRequest defines the expected JSON body for adding a consent given on the provider
+type ConnectRequest struct {
+	Provider Provider `json:"provider" validate:"required,enum"`
+	Token    string   `json:"token" validate:"required,max=255"` // Token is returned by the widget (e.g., the Pluggy item ID or the Plaid public token)
+}
+
+// LinkTokenResponse defines the structure of a link token returned by the API
+type LinkTokenResponse struct {
+	Provider  Provider  `json:"provider"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LinkRequest defines the expected JSON body for linking a bank account to a ledger account
+type LinkRequest struct {
+	AccountID uuid.UUID `json:"account_id" validate:"required"`
+}
+
+// ConsentResponse defines the structure of a consent returned by the API
+type ConsentResponse struct {
+	ID           uuid.UUID     `json:"id"`
+	Provider     Provider      `json:"provider"`
+	ExternalID   string        `json:"external_id"`
+	Status       ConsentStatus `json:"status"`
+	ExpiresAt    *time.Time    `json:"expires_at"`
+	LastSyncedAt *time.Time    `json:"last_synced_at"`
+	LastError    string        `json:"last_error,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// RemoteAccountResponse defines the structure of a bank account returned by the API
+type RemoteAccountResponse struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Type     RemoteAccountType `json:"type"`
+	Currency string            `json:"currency"`
+	Balance  int64             `json:"balance"`
+	Link     *LinkResponse     `json:"link"`
+}
+
+// LinkResponse defines the structure of a bank account link returned by the API
+type LinkResponse struct {
+	AccountID    uuid.UUID  `json:"account_id"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ConnectResponse defines the structure of a newly added consent with its bank accounts
+type ConnectResponse struct {
+	Consent  ConsentResponse         `json:"consent"`
+	Accounts []RemoteAccountResponse `json:"accounts"`
+}
+
+// SyncRunResponse defines the structure of a sync run returned by the API
+type SyncRunResponse struct {
+	ID         uuid.UUID     `json:"id"`
+	Trigger    SyncTrigger   `json:"trigger"`
+	Status     SyncRunStatus `json:"status"`
+	Result     SyncResult    `json:"result"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at"`
+}
+
+// createLinkTokenHandler handles the HTTP request for creating the token opening the widget of a provider
+func (h *BankConnectHandler) createLinkTokenHandler(c echo.Context) error {
+	var req LinkTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	token, err := h.bankConnectService.CreateLinkToken(c.Request().Context(), userID, req.Provider)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, LinkTokenResponse{
+		Provider:  token.Provider,
+		Token:     token.Token,
+		ExpiresAt: token.ExpiresAt,
+	})
+}
+
+// connectHandler handles the HTTP request for adding a consent given on the provider
+func (h *BankConnectHandler) connectHandler(c echo.Context) error {
+	var req ConnectRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := ConnectParams{
+		UserID:   userID,
+		Provider: req.Provider,
+		Token:    req.Token,
+	}
+
+	consent, accounts, err := h.bankConnectService.Connect(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	resp := ConnectResponse{
+		Consent:  toConsentResponse(consent),
+		Accounts: make([]RemoteAccountResponse, len(accounts)),
+	}
+	for i, account := range accounts {
+		resp.Accounts[i] = toRemoteAccountResponse(ConsentAccount{RemoteAccount: account})
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, resp)
+}
+
+// listConsentsHandler handles the HTTP request for listing the consents of the user
+func (h *BankConnectHandler) listConsentsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	consents, err := h.bankConnectService.FindConsents(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]ConsentResponse, len(consents))
+	for i, consent := range consents {
+		resp[i] = toConsentResponse(consent)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// revokeHandler handles the HTTP request for revoking a consent
+func (h *BankConnectHandler) revokeHandler(c echo.Context) error {
+	consentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid consent id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.bankConnectService.Revoke(c.Request().Context(), userID, consentID); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// listAccountsHandler handles the HTTP request for listing the bank accounts of a consent with their links
+func (h *BankConnectHandler) listAccountsHandler(c echo.Context) error {
+	consentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid consent id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := h.bankConnectService.Accounts(c.Request().Context(), userID, consentID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]RemoteAccountResponse, len(accounts))
+	for i, account := range accounts {
+		resp[i] = toRemoteAccountResponse(account)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// linkHandler handles the HTTP request for linking a bank account to a ledger account
+func (h *BankConnectHandler) linkHandler(c echo.Context) error {
+	consentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid consent id format")
+	}
+
+	var req LinkRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := LinkParams{
+		UserID:          userID,
+		ConsentID:       consentID,
+		RemoteAccountID: c.Param("remoteAccountId"),
+		AccountID:       req.AccountID,
+	}
+
+	link, err := h.bankConnectService.LinkAccount(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toLinkResponse(link))
+}
+
+// unlinkHandler handles the HTTP request for stopping the sync of a bank account
+func (h *BankConnectHandler) unlinkHandler(c echo.Context) error {
+	consentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid consent id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.bankConnectService.UnlinkAccount(c.Request().Context(), userID, consentID, c.Param("remoteAccountId"))
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// syncHandler handles the HTTP request for syncing the linked accounts of a consent, replying 202 Accepted with the sync job
+func (h *BankConnectHandler) syncHandler(c echo.Context) error {
+	consentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid consent id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.bankConnectService.Sync(c.Request().Context(), userID, consentID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendAccepted(c, jobs.ToJobResponse(job))
+}
+
+// listSyncRunsHandler handles the HTTP request for listing the latest sync runs of a consent
+func (h *BankConnectHandler) listSyncRunsHandler(c echo.Context) error {
+	consentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid consent id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := httpx.ParsePage(c)
+	if err != nil {
+		return err
+	}
+
+	runs, err := h.bankConnectService.FindSyncRuns(c.Request().Context(), userID, consentID, page.Limit, page.Offset)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]SyncRunResponse, len(runs))
+	for i, run := range runs {
+		resp[i] = toSyncRunResponse(run)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// webhookHandler handles the webhook of a provider (e.g., /webhooks/banks/pluggy), whose raw body is needed to verify its signature
+func (h *BankConnectHandler) webhookHandler(c echo.Context) error {
+	provider := Provider(strings.ToUpper(c.Param("provider")))
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBodySize+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read webhook body")
+	}
+	if len(body) > maxWebhookBodySize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "webhook body is too large")
+	}
+
+	if err := h.bankConnectService.HandleWebhook(c.Request().Context(), provider, c.Request().Header, body); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, nil)
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toConsentResponse maps a Consent to the public ConsentResponse DTO
+func toConsentResponse(consent *Consent) ConsentResponse {
+	return ConsentResponse{
+		ID:           consent.ID,
+		Provider:     consent.Provider,
+		ExternalID:   consent.ExternalID,
+		Status:       consent.Status,
+		ExpiresAt:    consent.ExpiresAt,
+		LastSyncedAt: consent.LastSyncedAt,
+		LastError:    consent.LastError,
+		CreatedAt:    consent.CreatedAt,
+	}
+}
+
+// toRemoteAccountResponse maps a ConsentAccount to the public RemoteAccountResponse DTO
+func toRemoteAccountResponse(account ConsentAccount) RemoteAccountResponse {
+	resp := RemoteAccountResponse{
+		ID:       account.ID,
+		Name:     account.Name,
+		Type:     account.Type,
+		Currency: account.Currency,
+		Balance:  account.Balance.Amount,
+	}
+	if account.Link != nil {
+		link := toLinkResponse(account.Link)
+		resp.Link = &link
+	}
+	return resp
+}
+
+// toLinkResponse maps a Link to the public LinkResponse DTO
+func toLinkResponse(link *Link) LinkResponse {
+	return LinkResponse{
+		AccountID:    link.AccountID,
+		LastSyncedAt: link.LastSyncedAt,
+		CreatedAt:    link.CreatedAt,
+	}
+}
+
+// toSyncRunResponse maps a SyncRun to the public SyncRunResponse DTO
+func toSyncRunResponse(run *SyncRun) SyncRunResponse {
+	return SyncRunResponse{
+		ID:         run.ID,
+		Trigger:    run.Trigger,
+		Status:     run.Status,
+		Result:     run.Result,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+}
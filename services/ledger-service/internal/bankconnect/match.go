@@ -0,0 +1,84 @@
+package bankconnect
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// matchTransactions pairs the bank transactions with the ledger transactions the user already entered, so syncing
+// does not add them twice. A bank transaction matches a ledger transaction of the same amount dated at most MatchWindow
+// days away; the closest date wins, then the most similar description. Each ledger transaction matches at most once,
+// and the ones in taken (tied to an earlier synced transaction) and the balance adjustments never match
+// The matches are keyed by the bank transaction ID
+func matchTransactions(bank []RemoteTransaction, ledgerTxs []ledger.Transaction, taken map[uuid.UUID]bool) map[string]ledger.Transaction {
+	candidates := make([]ledger.Transaction, 0, len(ledgerTxs))
+	for _, tx := range ledgerTxs {
+		if tx.Type != ledger.Adjustment && !taken[tx.ID] {
+			candidates = append(candidates, tx)
+		}
+	}
+
+	// Oldest bank transactions pick first, so a recurring amount matches its ledger occurrences in order
+	ordered := make([]RemoteTransaction, len(bank))
+	copy(ordered, bank)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Date.Before(ordered[j].Date) })
+
+	used := make(map[uuid.UUID]bool)
+	matches := make(map[string]ledger.Transaction)
+	for _, bankTx := range ordered {
+		best, bestDistance, bestSimilarity := -1, 0, 0
+		for i, candidate := range candidates {
+			if used[candidate.ID] || candidate.Amount != bankTx.Amount {
+				continue
+			}
+			distance := daysBetween(bankTx.Date, candidate.DueDate)
+			if distance > MatchWindow {
+				continue
+			}
+			similarity := descriptionSimilarity(bankTx.Description, candidate.Description)
+			if best < 0 || distance < bestDistance || (distance == bestDistance && similarity > bestSimilarity) {
+				best, bestDistance, bestSimilarity = i, distance, similarity
+			}
+		}
+		if best >= 0 {
+			used[candidates[best].ID] = true
+			matches[bankTx.ID] = candidates[best]
+		}
+	}
+	return matches
+}
+
+// daysBetween returns how many calendar days apart the dates are
+func daysBetween(a, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(dayA.Sub(dayB).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// descriptionSimilarity counts the words of at least 3 letters both descriptions share, ignoring case
+// Bank descriptions are noisy (e.g., "COMPRA NETFLIX SAO PAULO" against "Netflix"), so it only breaks ties
+func descriptionSimilarity(a, b string) int {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(a)) {
+		if len([]rune(word)) >= 3 {
+			words[word] = true
+		}
+	}
+
+	shared := 0
+	for _, word := range strings.Fields(strings.ToLower(b)) {
+		if words[word] {
+			shared++
+			delete(words, word)
+		}
+	}
+	return shared
+}
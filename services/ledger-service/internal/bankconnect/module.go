@@ -0,0 +1,46 @@
+package bankconnect
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the bank connect repository, service and handler from the shared dependencies
+type Module struct {
+	handler *BankConnectHandler
+	service *Service
+}
+
+// NewModule creates the bank connect module, syncing into the ledger the consents of the given connectors
+// categorizer categorizes the imported transactions, secrets encrypts the consent secrets and may be nil when no connector stores any
+func NewModule(deps module.Deps, accounts AccountSyncer, categorizer Categorizer, secrets *SecretBox, connectors ...Connector) *Module {
+	repo := NewPostgresRepository(deps.Postgres.Pool, secrets)
+	bankConnectSvc := NewService(repo, accounts, categorizer, deps.Jobs, deps.Audit, deps.Clock, connectors...)
+
+	return &Module{
+		handler: NewBankConnectHandler(bankConnectSvc),
+		service: bankConnectSvc,
+	}
+}
+
+// Service returns the bank connect service, for the composition root scheduling its sync
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "bankconnect"
+}
+
+// RegisterRoutes mounts the bank connect routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
+
+// RegisterWebhookRoutes mounts the provider webhook routes, outside the authenticated API
+func (m *Module) RegisterWebhookRoutes(webhooksGroup *echo.Group) {
+	m.handler.RegisterWebhookRoutes(webhooksGroup)
+}
@@ -0,0 +1,545 @@
+package bankconnect
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/retry"
+	"github.com/google/uuid"
+)
+
+var _ Connector = (*PlaidConnector)(nil)
+
+const (
+	// plaidPageSize is the largest page of transactions Plaid returns
+	plaidPageSize = 500
+	// plaidClientName is shown to the user in Plaid Link
+	plaidClientName = "Fintrack"
+	// plaidDefaultCurrency is assumed when Plaid omits the currency of a US account
+	plaidDefaultCurrency = "USD"
+	// plaidVerificationHeader carries the JWT signing every Plaid webhook
+	plaidVerificationHeader = "Plaid-Verification"
+	// plaidWebhookMaxAge rejects replayed webhooks, signed longer ago than Plaid recommends accepting
+	plaidWebhookMaxAge = 5 * time.Minute
+)
+
+// plaidSyncWebhookCodes are the codes of the TRANSACTIONS webhooks signaling new transactions to sync
+var plaidSyncWebhookCodes = map[string]bool{
+	"SYNC_UPDATES_AVAILABLE": true,
+	"INITIAL_UPDATE":         true,
+	"HISTORICAL_UPDATE":      true,
+	"DEFAULT_UPDATE":         true,
+}
+
+// Plaid error codes handled apart from the generic failures
+const (
+	plaidErrInvalidPublicToken = "INVALID_PUBLIC_TOKEN"
+	plaidErrInvalidAccessToken = "INVALID_ACCESS_TOKEN"
+	plaidErrItemNotFound       = "ITEM_NOT_FOUND"
+	plaidErrProductNotReady    = "PRODUCT_NOT_READY"
+)
+
+// PlaidConnector reads US and European bank accounts through the Plaid API (https://plaid.com/docs/api)
+// Consents are Plaid items, their access token is the consent secret
+type PlaidConnector struct {
+	baseURL      string
+	clientID     string
+	secret       string
+	countryCodes []string
+	language     string
+	webhookURL   string
+	client       *http.Client
+	retry        retry.Policy
+	clock        clock.Clock
+
+	mu               sync.Mutex
+	verificationKeys map[string]*ecdsa.PublicKey // verificationKeys caches the webhook verification keys by key ID
+}
+
+// PlaidConfig holds the settings of the Plaid integration
+type PlaidConfig struct {
+	BaseURL      string // BaseURL selects the Plaid environment (e.g., https://sandbox.plaid.com)
+	ClientID     string
+	Secret       string
+	CountryCodes []string // CountryCodes lists the countries whose banks are offered in Plaid Link (e.g., US, GB, FR)
+	Language     string
+	WebhookURL   string // WebhookURL receives the Plaid webhooks of the items, none are sent when empty
+	Timeout      time.Duration
+	Retry        retry.Policy // Retry spaces the attempts of a request Plaid failed to serve (5xx, 429 or unreachable)
+}
+
+// NewPlaidConnector creates a new PlaidConnector
+func NewPlaidConnector(cfg PlaidConfig, clock clock.Clock) *PlaidConnector {
+	return &PlaidConnector{
+		baseURL:      strings.TrimRight(cfg.BaseURL, "/"),
+		clientID:     cfg.ClientID,
+		secret:       cfg.Secret,
+		countryCodes: cfg.CountryCodes,
+		language:     cfg.Language,
+		webhookURL:   cfg.WebhookURL,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		retry:        cfg.Retry,
+		clock:        clock,
+
+		verificationKeys: make(map[string]*ecdsa.PublicKey),
+	}
+}
+
+// plaidAccount is an account as returned by the Plaid API
+type plaidAccount struct {
+	AccountID string `json:"account_id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"` // Type is depository, credit, loan, investment or other
+	Balances  struct {
+		Current         *float64 `json:"current"`
+		ISOCurrencyCode string   `json:"iso_currency_code"`
+	} `json:"balances"`
+}
+
+// plaidTransaction is a transaction as returned by the Plaid API
+type plaidTransaction struct {
+	TransactionID   string  `json:"transaction_id"`
+	Name            string  `json:"name"`
+	MerchantName    string  `json:"merchant_name"`
+	Amount          float64 `json:"amount"` // Amount is positive when money leaves the account, for every account type
+	ISOCurrencyCode string  `json:"iso_currency_code"`
+	Date            string  `json:"date"`
+	Pending         bool    `json:"pending"`
+}
+
+// Provider returns PLAID
+func (p *PlaidConnector) Provider() Provider {
+	return Plaid
+}
+
+// CreateLinkToken creates a link token opening Plaid Link for the user, with the transactions product
+func (p *PlaidConnector) CreateLinkToken(ctx context.Context, userID uuid.UUID) (*LinkToken, error) {
+	in := map[string]any{
+		"client_name":   plaidClientName,
+		"language":      p.language,
+		"country_codes": p.countryCodes,
+		"user":          map[string]string{"client_user_id": userID.String()},
+		"products":      []string{"transactions"},
+	}
+	if p.webhookURL != "" {
+		in["webhook"] = p.webhookURL
+	}
+
+	var resp struct {
+		LinkToken  string    `json:"link_token"`
+		Expiration time.Time `json:"expiration"`
+	}
+	if err := p.do(ctx, "/link/token/create", in, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create plaid link token: %w", err)
+	}
+	return &LinkToken{Provider: Plaid, Token: resp.LinkToken, ExpiresAt: resp.Expiration}, nil
+}
+
+// Connect exchanges the public token returned by Plaid Link for the access token of the item
+// European items report when their consent expires, which is read from the item
+func (p *PlaidConnector) Connect(ctx context.Context, token string) (*ConsentCredentials, error) {
+	var exchanged struct {
+		AccessToken string `json:"access_token"`
+		ItemID      string `json:"item_id"`
+	}
+	if err := p.do(ctx, "/item/public_token/exchange", map[string]string{"public_token": token}, &exchanged); err != nil {
+		if isPlaidError(err, plaidErrInvalidPublicToken) {
+			return nil, ErrInvalidConsentToken.With("provider", Plaid)
+		}
+		return nil, fmt.Errorf("failed to exchange plaid public token: %w", err)
+	}
+
+	var item struct {
+		Item struct {
+			ConsentExpirationTime *time.Time `json:"consent_expiration_time"`
+		} `json:"item"`
+	}
+	if err := p.do(ctx, "/item/get", map[string]string{"access_token": exchanged.AccessToken}, &item); err != nil {
+		return nil, fmt.Errorf("failed to fetch plaid item: %w", err)
+	}
+
+	return &ConsentCredentials{
+		ExternalID: exchanged.ItemID,
+		Secret:     exchanged.AccessToken,
+		ExpiresAt:  item.Item.ConsentExpirationTime,
+	}, nil
+}
+
+// Accounts lists the depository and credit accounts of the Plaid item, loans and investments cannot be linked to the ledger
+func (p *PlaidConnector) Accounts(ctx context.Context, consent *Consent) ([]RemoteAccount, error) {
+	var resp struct {
+		Accounts []plaidAccount `json:"accounts"`
+	}
+	if err := p.do(ctx, "/accounts/get", map[string]string{"access_token": consent.Secret}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list plaid accounts: %w", err)
+	}
+
+	accounts := make([]RemoteAccount, 0, len(resp.Accounts))
+	for _, a := range resp.Accounts {
+		if account, ok := a.toRemoteAccount(); ok {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// Balance returns the real-time balance of a Plaid account
+func (p *PlaidConnector) Balance(ctx context.Context, consent *Consent, remoteAccountID string) (money.Money, error) {
+	in := map[string]any{
+		"access_token": consent.Secret,
+		"options":      map[string]any{"account_ids": []string{remoteAccountID}},
+	}
+	var resp struct {
+		Accounts []plaidAccount `json:"accounts"`
+	}
+	if err := p.do(ctx, "/accounts/balance/get", in, &resp); err != nil {
+		return money.Money{}, fmt.Errorf("failed to fetch plaid account balance: %w", err)
+	}
+
+	for _, a := range resp.Accounts {
+		if account, ok := a.toRemoteAccount(); ok && account.ID == remoteAccountID {
+			return account.Balance, nil
+		}
+	}
+	return money.Money{}, ErrRemoteAccountNotFound.With("remote_account_id", remoteAccountID)
+}
+
+// Transactions lists the transactions of a Plaid account from the given day to today, reading every page
+// Plaid reports money leaving the account as positive amounts, they are negated to follow the Connector sign convention
+// An item whose transactions Plaid is still fetching has none yet, they are read by a later sync
+func (p *PlaidConnector) Transactions(ctx context.Context, consent *Consent, remoteAccountID string, from time.Time) ([]RemoteTransaction, error) {
+	transactions := make([]RemoteTransaction, 0)
+	for offset := 0; ; {
+		in := map[string]any{
+			"access_token": consent.Secret,
+			"start_date":   from.Format(time.DateOnly),
+			"end_date":     p.clock.Now().Format(time.DateOnly),
+			"options": map[string]any{
+				"account_ids": []string{remoteAccountID},
+				"count":       plaidPageSize,
+				"offset":      offset,
+			},
+		}
+		var page struct {
+			Accounts          []plaidAccount     `json:"accounts"`
+			Transactions      []plaidTransaction `json:"transactions"`
+			TotalTransactions int                `json:"total_transactions"`
+		}
+		if err := p.do(ctx, "/transactions/get", in, &page); err != nil {
+			if isPlaidError(err, plaidErrProductNotReady) {
+				return transactions, nil
+			}
+			return nil, fmt.Errorf("failed to list plaid transactions: %w", err)
+		}
+
+		accountCurrency := plaidDefaultCurrency
+		for _, a := range page.Accounts {
+			if a.AccountID == remoteAccountID {
+				accountCurrency = plaidCurrency(a.Balances.ISOCurrencyCode)
+			}
+		}
+
+		for _, t := range page.Transactions {
+			date, err := time.Parse(time.DateOnly, t.Date)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse plaid transaction date %q: %w", t.Date, err)
+			}
+			currency := accountCurrency
+			if t.ISOCurrencyCode != "" {
+				currency = plaidCurrency(t.ISOCurrencyCode)
+			}
+			description := t.MerchantName
+			if description == "" {
+				description = t.Name
+			}
+
+			transactions = append(transactions, RemoteTransaction{
+				ID:          t.TransactionID,
+				Date:        date,
+				Description: strings.TrimSpace(description),
+				Amount:      decimalMoney(-t.Amount, currency),
+				Pending:     t.Pending,
+			})
+		}
+
+		offset += len(page.Transactions)
+		if len(page.Transactions) == 0 || offset >= page.TotalTransactions {
+			return transactions, nil
+		}
+	}
+}
+
+// Revoke removes the Plaid item, which invalidates its access token
+func (p *PlaidConnector) Revoke(ctx context.Context, consent *Consent) error {
+	err := p.do(ctx, "/item/remove", map[string]string{"access_token": consent.Secret}, nil)
+	if err != nil && !isPlaidError(err, plaidErrItemNotFound, plaidErrInvalidAccessToken) {
+		return fmt.Errorf("failed to remove plaid item: %w", err)
+	}
+	return nil
+}
+
+// toRemoteAccount maps a depository or credit Plaid account, whose credit balance is the debt as a positive amount
+func (a plaidAccount) toRemoteAccount() (RemoteAccount, bool) {
+	account := RemoteAccount{
+		ID:       a.AccountID,
+		Name:     a.Name,
+		Currency: plaidCurrency(a.Balances.ISOCurrencyCode),
+	}
+	current := 0.0
+	if a.Balances.Current != nil {
+		current = *a.Balances.Current
+	}
+
+	switch a.Type {
+	case "depository":
+		account.Type = RemoteChecking
+		account.Balance = decimalMoney(current, account.Currency)
+	case "credit":
+		account.Type = RemoteCreditCard
+		account.Balance = decimalMoney(-current, account.Currency)
+	default:
+		return RemoteAccount{}, false
+	}
+	return account, true
+}
+
+// plaidCurrency returns the currency code reported by Plaid, USD when it is omitted
+func plaidCurrency(code string) string {
+	return currencyOr(code, plaidDefaultCurrency)
+}
+
+// ----- Webhooks ----- //
+
+// ParseWebhook verifies the JWT signing a Plaid webhook and returns the item of the TRANSACTIONS webhooks signaling new transactions
+// (https://plaid.com/docs/api/webhooks/webhook-verification)
+func (p *PlaidConnector) ParseWebhook(ctx context.Context, header http.Header, body []byte) (*WebhookEvent, error) {
+	if err := p.verifyWebhook(ctx, header.Get(plaidVerificationHeader), body); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		WebhookType string `json:"webhook_type"`
+		WebhookCode string `json:"webhook_code"`
+		ItemID      string `json:"item_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ItemID == "" {
+		return nil, ErrMalformedWebhook.With("provider", Plaid)
+	}
+	if payload.WebhookType != "TRANSACTIONS" || !plaidSyncWebhookCodes[payload.WebhookCode] {
+		return nil, nil
+	}
+	return &WebhookEvent{ExternalID: payload.ItemID, Name: payload.WebhookType + "/" + payload.WebhookCode}, nil
+}
+
+// verifyWebhook checks the ES256 signature of the JWT with the key Plaid publishes for its key ID,
+// that it was issued less than plaidWebhookMaxAge ago, and that it signs the SHA-256 of the body
+func (p *PlaidConnector) verifyWebhook(ctx context.Context, token string, body []byte) error {
+	invalid := func(reason string) error {
+		return ErrInvalidWebhook.With("provider", Plaid).With("reason", reason)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return invalid("malformed verification token")
+	}
+	var jwtHeader struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &jwtHeader); err != nil || jwtHeader.Kid == "" {
+		return invalid("malformed verification token header")
+	}
+	if jwtHeader.Alg != "ES256" {
+		return invalid("unexpected signing algorithm")
+	}
+
+	key, err := p.verificationKey(ctx, jwtHeader.Kid)
+	if err != nil {
+		// Plaid rejects the key IDs it never issued
+		var plaidErr *plaidError
+		if errors.As(err, &plaidErr) {
+			return invalid("unknown verification key")
+		}
+		return err
+	}
+	if key == nil {
+		return invalid("expired verification key")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(signature) != 64 {
+		return invalid("malformed signature")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return invalid("signature mismatch")
+	}
+
+	var claims struct {
+		IssuedAt   int64  `json:"iat"`
+		BodySHA256 string `json:"request_body_sha256"`
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return invalid("malformed verification token claims")
+	}
+	if p.clock.Now().Sub(time.Unix(claims.IssuedAt, 0)) > plaidWebhookMaxAge {
+		return invalid("verification token too old")
+	}
+	bodySum := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(bodySum[:])), []byte(claims.BodySHA256)) != 1 {
+		return invalid("body hash mismatch")
+	}
+	return nil
+}
+
+// verificationKey returns the cached webhook verification key, asking Plaid for it when unknown
+// A key Plaid reports as expired is returned as nil and not cached, so it is asked again until the webhooks stop using it
+func (p *PlaidConnector) verificationKey(ctx context.Context, keyID string) (*ecdsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.verificationKeys[keyID]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var resp struct {
+		Key struct {
+			Crv       string `json:"crv"`
+			X         string `json:"x"`
+			Y         string `json:"y"`
+			ExpiredAt *int64 `json:"expired_at"`
+		} `json:"key"`
+	}
+	if err := p.do(ctx, "/webhook_verification_key/get", map[string]string{"key_id": keyID}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch plaid webhook verification key: %w", err)
+	}
+	if resp.Key.ExpiredAt != nil {
+		return nil, nil
+	}
+	if resp.Key.Crv != "P-256" {
+		return nil, fmt.Errorf("unexpected plaid webhook verification key curve %q", resp.Key.Crv)
+	}
+
+	x, errX := base64.RawURLEncoding.DecodeString(resp.Key.X)
+	y, errY := base64.RawURLEncoding.DecodeString(resp.Key.Y)
+	if errX != nil || errY != nil || len(x) > 32 || len(y) > 32 {
+		return nil, errors.New("malformed plaid webhook verification key")
+	}
+	// The uncompressed point is 0x04 followed by the coordinates, each left-padded to 32 bytes
+	point := make([]byte, 65)
+	point[0] = 4
+	copy(point[33-len(x):33], x)
+	copy(point[65-len(y):], y)
+	key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plaid webhook verification key: %w", err)
+	}
+
+	p.mu.Lock()
+	p.verificationKeys[keyID] = key
+	p.mu.Unlock()
+	return key, nil
+}
+
+// decodeJWTPart decodes a base64url encoded JSON part of a JWT into out
+func decodeJWTPart(part string, out any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, out)
+}
+
+// ----- HTTP ----- //
+
+// plaidError is an error response of the Plaid API
+type plaidError struct {
+	Status  int    `json:"-"`
+	Type    string `json:"error_type"`
+	Code    string `json:"error_code"`
+	Message string `json:"error_message"`
+}
+
+func (e *plaidError) Error() string {
+	return fmt.Sprintf("plaid responded with status %d: %s (%s)", e.Status, e.Code, e.Message)
+}
+
+// isPlaidError reports whether err is a Plaid error response with one of the codes
+func isPlaidError(err error, codes ...string) bool {
+	var plaidErr *plaidError
+	if !errors.As(err, &plaidErr) {
+		return false
+	}
+	for _, code := range codes {
+		if plaidErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// do posts in as JSON to a Plaid endpoint, authenticated by the client headers, and decodes the JSON response into out, when not nil
+// A request Plaid failed to serve is attempted again, every endpoint called being a read or failing harmlessly when repeated
+func (p *PlaidConnector) do(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode plaid request: %w", err)
+	}
+
+	return retry.Do(ctx, p.retry, func(ctx context.Context) error {
+		return p.send(ctx, path, body, out)
+	})
+}
+
+// send posts one request to a Plaid endpoint
+func (p *PlaidConnector) send(ctx context.Context, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create plaid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PLAID-CLIENT-ID", p.clientID)
+	req.Header.Set("PLAID-SECRET", p.secret)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ErrProviderUnavailable.With("provider", Plaid).With("reason", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return ErrProviderUnavailable.With("provider", Plaid).With("status", resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		plaidErr := &plaidError{Status: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(plaidErr); err != nil {
+			plaidErr.Message = "unreadable error response"
+		}
+		return plaidErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode plaid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package bankconnect
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/pix"
+	"github.com/Guizzs26/fintrack/pkg/retry"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var _ Connector = (*PluggyConnector)(nil)
+
+const (
+	// pluggyAPIKeyTTL renews the API key before the 2 hours Pluggy keeps it valid
+	pluggyAPIKeyTTL = 100 * time.Minute
+	// pluggyConnectTokenTTL is how long Pluggy accepts a connect token
+	pluggyConnectTokenTTL = 30 * time.Minute
+	// pluggyPageSize is the largest page of transactions Pluggy returns
+	pluggyPageSize = 500
+	// pluggyDefaultCurrency is assumed when Pluggy omits the currency, every Open Finance Brasil account being in reais
+	pluggyDefaultCurrency = "BRL"
+	// pluggyWebhookSecretHeader carries the shared secret of the webhooks, set as a custom header when registering them on Pluggy
+	pluggyWebhookSecretHeader = "X-Webhook-Secret"
+)
+
+// pluggySyncEvents are the Pluggy webhook events signaling new transactions to sync
+var pluggySyncEvents = map[string]bool{
+	"item/updated":         true,
+	"transactions/created": true,
+	"transactions/updated": true,
+}
+
+// PluggyConnector reads Open Finance accounts through the Pluggy API (https://docs.pluggy.ai)
+// Consents are Pluggy items, created by the user in the Pluggy Connect widget before being added here
+type PluggyConnector struct {
+	baseURL       string
+	clientID      string
+	clientSecret  string
+	webhookSecret string
+	client        *http.Client
+	retry         retry.Policy
+	clock         clock.Clock
+
+	mu              sync.Mutex
+	apiKey          string
+	apiKeyExpiresAt time.Time
+}
+
+// PluggyConfig holds the settings of the Pluggy integration
+type PluggyConfig struct {
+	BaseURL       string
+	ClientID      string // ClientID and ClientSecret are the client credentials of the Pluggy dashboard
+	ClientSecret  string
+	WebhookSecret string // WebhookSecret authenticates the Pluggy webhooks, which are all rejected when empty
+	Timeout       time.Duration
+	Retry         retry.Policy // Retry spaces the attempts of a request Pluggy failed to serve (5xx, 429 or unreachable)
+}
+
+// NewPluggyConnector creates a new PluggyConnector
+func NewPluggyConnector(cfg PluggyConfig, clock clock.Clock) *PluggyConnector {
+	return &PluggyConnector{
+		baseURL:       strings.TrimRight(cfg.BaseURL, "/"),
+		clientID:      cfg.ClientID,
+		clientSecret:  cfg.ClientSecret,
+		webhookSecret: cfg.WebhookSecret,
+		client:        &http.Client{Timeout: cfg.Timeout},
+		retry:         cfg.Retry,
+		clock:         clock,
+	}
+}
+
+// pluggyAccount is an account as returned by the Pluggy API
+type pluggyAccount struct {
+	ID           string  `json:"id"`
+	Type         string  `json:"type"` // Type is BANK or CREDIT
+	Name         string  `json:"name"`
+	Balance      float64 `json:"balance"`
+	CurrencyCode string  `json:"currencyCode"`
+}
+
+// pluggyTransaction is a transaction as returned by the Pluggy API
+type pluggyTransaction struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	// AmountInAccountCurrency is set when the transaction was made in another currency (e.g., a purchase abroad)
+	AmountInAccountCurrency *float64  `json:"amountInAccountCurrency"`
+	Date                    time.Time `json:"date"`
+	Status                  string    `json:"status"` // Status is PENDING or POSTED
+	// PaymentData is set for transfers, its reference number being the end-to-end ID of PIX transfers
+	PaymentData *struct {
+		PaymentMethod   string `json:"paymentMethod"` // PaymentMethod is PIX, TED, TEF or BOLETO
+		ReferenceNumber string `json:"referenceNumber"`
+	} `json:"paymentData"`
+}
+
+// pixDetails returns the PIX identifiers of the transaction, nil when it is not a PIX transfer or its
+// reference number is not a valid end-to-end ID, which some institutions replace by their own
+func (t pluggyTransaction) pixDetails() *ledger.PixDetails {
+	if t.PaymentData == nil || t.PaymentData.PaymentMethod != "PIX" {
+		return nil
+	}
+	endToEndID := strings.TrimSpace(t.PaymentData.ReferenceNumber)
+	if pix.ValidateEndToEndID(endToEndID) != nil {
+		return nil
+	}
+	return &ledger.PixDetails{EndToEndID: endToEndID}
+}
+
+// pluggyPage is a page of results of the Pluggy API
+type pluggyPage[T any] struct {
+	TotalPages int `json:"totalPages"`
+	Results    []T `json:"results"`
+}
+
+// Provider returns PLUGGY
+func (p *PluggyConnector) Provider() Provider {
+	return Pluggy
+}
+
+// CreateLinkToken creates a connect token opening the Pluggy Connect widget for the user
+func (p *PluggyConnector) CreateLinkToken(ctx context.Context, userID uuid.UUID) (*LinkToken, error) {
+	var resp struct {
+		AccessToken string `json:"accessToken"`
+	}
+	in := map[string]string{"clientUserId": userID.String()}
+	if err := p.do(ctx, http.MethodPost, "/connect_token", nil, in, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create pluggy connect token: %w", err)
+	}
+	return &LinkToken{Provider: Pluggy, Token: resp.AccessToken, ExpiresAt: p.clock.Now().Add(pluggyConnectTokenTTL)}, nil
+}
+
+// Connect checks the item ID returned by the Pluggy Connect widget, which needs no secret to be read afterwards
+func (p *PluggyConnector) Connect(ctx context.Context, token string) (*ConsentCredentials, error) {
+	var item struct {
+		ID               string     `json:"id"`
+		ConsentExpiresAt *time.Time `json:"consentExpiresAt"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/items/"+url.PathEscape(token), nil, nil, &item); err != nil {
+		if isPluggyNotFound(err) {
+			return nil, ErrInvalidConsentToken.With("provider", Pluggy)
+		}
+		return nil, fmt.Errorf("failed to fetch pluggy item: %w", err)
+	}
+	return &ConsentCredentials{ExternalID: item.ID, ExpiresAt: item.ConsentExpiresAt}, nil
+}
+
+// Accounts lists the accounts of the Pluggy item
+func (p *PluggyConnector) Accounts(ctx context.Context, consent *Consent) ([]RemoteAccount, error) {
+	var page pluggyPage[pluggyAccount]
+	if err := p.do(ctx, http.MethodGet, "/accounts", url.Values{"itemId": {consent.ExternalID}}, nil, &page); err != nil {
+		return nil, fmt.Errorf("failed to list pluggy accounts: %w", err)
+	}
+
+	accounts := make([]RemoteAccount, len(page.Results))
+	for i, a := range page.Results {
+		accounts[i] = a.toRemoteAccount()
+	}
+	return accounts, nil
+}
+
+// Balance returns the current balance of a Pluggy account
+func (p *PluggyConnector) Balance(ctx context.Context, consent *Consent, remoteAccountID string) (money.Money, error) {
+	var account pluggyAccount
+	if err := p.do(ctx, http.MethodGet, "/accounts/"+url.PathEscape(remoteAccountID), nil, nil, &account); err != nil {
+		return money.Money{}, fmt.Errorf("failed to fetch pluggy account balance: %w", err)
+	}
+	return account.toRemoteAccount().Balance, nil
+}
+
+// Transactions lists the transactions of a Pluggy account from the given day on, reading every page, in the currency of the account
+// Pluggy reports credit card charges as positive amounts, they are negated to follow the Connector sign convention
+func (p *PluggyConnector) Transactions(ctx context.Context, consent *Consent, remoteAccountID string, from time.Time) ([]RemoteTransaction, error) {
+	var account pluggyAccount
+	if err := p.do(ctx, http.MethodGet, "/accounts/"+url.PathEscape(remoteAccountID), nil, nil, &account); err != nil {
+		return nil, fmt.Errorf("failed to fetch pluggy account: %w", err)
+	}
+	credit := account.Type == "CREDIT"
+	accountCurrency := currencyOr(account.CurrencyCode, pluggyDefaultCurrency)
+
+	transactions := make([]RemoteTransaction, 0)
+	for pageNumber := 1; ; pageNumber++ {
+		query := url.Values{
+			"accountId": {remoteAccountID},
+			"from":      {from.Format(time.DateOnly)},
+			"pageSize":  {strconv.Itoa(pluggyPageSize)},
+			"page":      {strconv.Itoa(pageNumber)},
+		}
+		var page pluggyPage[pluggyTransaction]
+		if err := p.do(ctx, http.MethodGet, "/transactions", query, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list pluggy transactions: %w", err)
+		}
+
+		for _, t := range page.Results {
+			amount := decimalMoney(t.Amount, accountCurrency)
+			if t.AmountInAccountCurrency != nil {
+				amount = decimalMoney(*t.AmountInAccountCurrency, accountCurrency)
+			}
+			if credit {
+				amount.Amount = -amount.Amount
+			}
+			transactions = append(transactions, RemoteTransaction{
+				ID:          t.ID,
+				Date:        t.Date.UTC().Truncate(24 * time.Hour),
+				Description: strings.TrimSpace(t.Description),
+				Amount:      amount,
+				Pending:     t.Status == "PENDING",
+				Pix:         t.pixDetails(),
+			})
+		}
+
+		if pageNumber >= page.TotalPages {
+			return transactions, nil
+		}
+	}
+}
+
+// Revoke deletes the Pluggy item, which revokes its consent on the bank
+func (p *PluggyConnector) Revoke(ctx context.Context, consent *Consent) error {
+	err := p.do(ctx, http.MethodDelete, "/items/"+url.PathEscape(consent.ExternalID), nil, nil, nil)
+	if err != nil && !isPluggyNotFound(err) {
+		return fmt.Errorf("failed to delete pluggy item: %w", err)
+	}
+	return nil
+}
+
+// ParseWebhook checks the shared secret of a Pluggy webhook, since Pluggy does not sign them,
+// and returns the item of the events signaling new transactions
+func (p *PluggyConnector) ParseWebhook(ctx context.Context, header http.Header, body []byte) (*WebhookEvent, error) {
+	secret := header.Get(pluggyWebhookSecretHeader)
+	if p.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(p.webhookSecret)) != 1 {
+		return nil, ErrInvalidWebhook.With("provider", Pluggy)
+	}
+
+	var payload struct {
+		Event  string `json:"event"`
+		ItemID string `json:"itemId"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ItemID == "" {
+		return nil, ErrMalformedWebhook.With("provider", Pluggy)
+	}
+	if !pluggySyncEvents[payload.Event] {
+		return nil, nil
+	}
+	return &WebhookEvent{ExternalID: payload.ItemID, Name: payload.Event}, nil
+}
+
+// toRemoteAccount maps a Pluggy account, whose credit card balance is the debt as a positive amount
+func (a pluggyAccount) toRemoteAccount() RemoteAccount {
+	currency := currencyOr(a.CurrencyCode, pluggyDefaultCurrency)
+	account := RemoteAccount{
+		ID:       a.ID,
+		Name:     a.Name,
+		Type:     RemoteChecking,
+		Currency: currency,
+		Balance:  decimalMoney(a.Balance, currency),
+	}
+	if a.Type == "CREDIT" {
+		account.Type = RemoteCreditCard
+		account.Balance.Amount = -account.Balance.Amount
+	}
+	return account
+}
+
+// ----- HTTP ----- //
+
+// pluggyStatusError is a non-2xx response of the Pluggy API
+type pluggyStatusError struct {
+	status int
+}
+
+func (e *pluggyStatusError) Error() string {
+	return fmt.Sprintf("pluggy responded with status %d", e.status)
+}
+
+// isPluggyNotFound reports whether err is a 404 response of the Pluggy API
+func isPluggyNotFound(err error) bool {
+	var statusErr *pluggyStatusError
+	return errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound
+}
+
+// do calls the Pluggy API with a valid API key, sending in as JSON and decoding the JSON response into out, when not nil
+// A request Pluggy failed to serve is attempted again, following the retry policy of the connector
+func (p *PluggyConnector) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode pluggy request: %w", err)
+		}
+	}
+
+	return retry.Do(ctx, p.retry, func(ctx context.Context) error {
+		return p.attempt(ctx, method, path, query, body, out)
+	})
+}
+
+// attempt makes one call to the Pluggy API
+// A rejected API key is renewed once, since Pluggy may invalidate it before its expiration
+func (p *PluggyConnector) attempt(ctx context.Context, method, path string, query url.Values, body []byte, out any) error {
+	resp, err := p.send(ctx, method, path, query, body, false)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		if resp, err = p.send(ctx, method, path, query, body, true); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && strings.HasPrefix(path, "/accounts/"):
+		return ErrRemoteAccountNotFound.With("remote_account_id", strings.TrimPrefix(path, "/accounts/"))
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return ErrProviderUnavailable.With("provider", Pluggy).With("status", resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return &pluggyStatusError{status: resp.StatusCode}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode pluggy response: %w", err)
+	}
+	return nil
+}
+
+// send sends one request to the Pluggy API, renewing the API key first when it expired or renew is set
+func (p *PluggyConnector) send(ctx context.Context, method, path string, query url.Values, body []byte, renew bool) (*http.Response, error) {
+	apiKey, err := p.key(ctx, renew)
+	if err != nil {
+		return nil, err
+	}
+
+	target := p.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pluggy request: %w", err)
+	}
+	req.Header.Set("X-API-KEY", apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, ErrProviderUnavailable.With("provider", Pluggy).With("reason", err.Error())
+	}
+	return resp, nil
+}
+
+// key returns the cached API key, asking Pluggy for a new one when it expired or renew is set
+func (p *PluggyConnector) key(ctx context.Context, renew bool) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	if !renew && p.apiKey != "" && now.Before(p.apiKeyExpiresAt) {
+		return p.apiKey, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"clientId": p.clientID, "clientSecret": p.clientSecret})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode pluggy credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/auth", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create pluggy auth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", ErrProviderUnavailable.With("provider", Pluggy).With("reason", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("failed to authenticate on pluggy: %w", &pluggyStatusError{status: resp.StatusCode})
+	}
+
+	var auth struct {
+		APIKey string `json:"apiKey"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("failed to decode pluggy auth response: %w", err)
+	}
+
+	p.apiKey = auth.APIKey
+	p.apiKeyExpiresAt = now.Add(pluggyAPIKeyTTL)
+	return p.apiKey, nil
+}
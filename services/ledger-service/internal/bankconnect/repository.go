@@ -0,0 +1,440 @@
+package bankconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// Unique constraints mapped to domain errors
+const (
+	consentUniqueConstraint     = "uq_bank_consents_user_id_provider_external_id"
+	linkAccountUniqueConstraint = "uq_bank_account_links_account_id"
+	runningSyncUniqueIndex      = "uq_bank_sync_runs_consent_id_running"
+)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface, encrypting the consent secrets with secrets
+type PostgresRepository struct {
+	pool    *pgxpool.Pool
+	secrets *SecretBox
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool, secrets *SecretBox) *PostgresRepository {
+	return &PostgresRepository{pool: pool, secrets: secrets}
+}
+
+// ----- Consents ----- //
+
+const consentColumns = `id, user_id, provider, external_id, secret, status, expires_at, last_synced_at, last_error, created_at`
+
+// SaveConsent inserts a new consent with its secret encrypted
+func (r *PostgresRepository) SaveConsent(ctx context.Context, consent *Consent) error {
+	secret, err := r.secrets.Seal(consent.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt bank consent secret: %w", err)
+	}
+
+	query := `
+		INSERT INTO bank_consents (id, user_id, provider, external_id, secret, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = r.pool.Exec(ctx, query,
+		consent.ID, consent.UserID, consent.Provider, consent.ExternalID, secret, consent.Status, consent.ExpiresAt, consent.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, consentUniqueConstraint) {
+			return ErrConsentAlreadyExists.With("external_id", consent.ExternalID)
+		}
+		return fmt.Errorf("failed to insert bank consent: %w", err)
+	}
+	return nil
+}
+
+// FindConsentByID retrieves a consent of the user
+func (r *PostgresRepository) FindConsentByID(ctx context.Context, userID, consentID uuid.UUID) (*Consent, error) {
+	query := `SELECT ` + consentColumns + ` FROM bank_consents WHERE id = $1 AND user_id = $2`
+
+	consent, err := r.scanConsent(r.pool.QueryRow(ctx, query, consentID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConsentNotFound.With("consent_id", consentID)
+		}
+		return nil, fmt.Errorf("failed to fetch bank consent: %w", err)
+	}
+	return consent, nil
+}
+
+// FindConsentsByUserID retrieves every consent of the user, newest first
+func (r *PostgresRepository) FindConsentsByUserID(ctx context.Context, userID uuid.UUID) ([]*Consent, error) {
+	query := `SELECT ` + consentColumns + ` FROM bank_consents WHERE user_id = $1 ORDER BY created_at DESC`
+	return r.queryConsents(ctx, query, userID)
+}
+
+// FindConsentsByExternalID retrieves the consents of every user identified by externalID on the provider
+func (r *PostgresRepository) FindConsentsByExternalID(ctx context.Context, provider Provider, externalID string) ([]*Consent, error) {
+	query := `SELECT ` + consentColumns + ` FROM bank_consents WHERE provider = $1 AND external_id = $2`
+	return r.queryConsents(ctx, query, provider, externalID)
+}
+
+// FindActiveConsents retrieves the active consents of every user, least recently synced first
+func (r *PostgresRepository) FindActiveConsents(ctx context.Context) ([]*Consent, error) {
+	query := `SELECT ` + consentColumns + ` FROM bank_consents WHERE status = $1 ORDER BY last_synced_at NULLS FIRST`
+	return r.queryConsents(ctx, query, ConsentActive)
+}
+
+// UpdateConsentStatus sets the status of a consent
+func (r *PostgresRepository) UpdateConsentStatus(ctx context.Context, consentID uuid.UUID, status ConsentStatus) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE bank_consents SET status = $2 WHERE id = $1`, consentID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update bank consent status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConsentNotFound.With("consent_id", consentID)
+	}
+	return nil
+}
+
+// UpdateConsentSync records when the consent was synced and why it failed, NULL when it succeeded
+func (r *PostgresRepository) UpdateConsentSync(ctx context.Context, consentID uuid.UUID, syncedAt time.Time, syncErr string) error {
+	query := `UPDATE bank_consents SET last_synced_at = $2, last_error = NULLIF($3, '') WHERE id = $1`
+	if _, err := r.pool.Exec(ctx, query, consentID, syncedAt, syncErr); err != nil {
+		return fmt.Errorf("failed to update bank consent sync: %w", err)
+	}
+	return nil
+}
+
+// queryConsents runs a query selecting consentColumns
+func (r *PostgresRepository) queryConsents(ctx context.Context, query string, args ...any) ([]*Consent, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bank consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := make([]*Consent, 0)
+	for rows.Next() {
+		consent, err := r.scanConsent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank consent row: %w", err)
+		}
+		consents = append(consents, consent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank consent rows: %w", err)
+	}
+
+	return consents, nil
+}
+
+// scanConsent scans a row selecting consentColumns, decrypting its secret
+func (r *PostgresRepository) scanConsent(row pgx.Row) (*Consent, error) {
+	var (
+		consent   Consent
+		secret    []byte
+		lastError *string
+	)
+	err := row.Scan(
+		&consent.ID, &consent.UserID, &consent.Provider, &consent.ExternalID, &secret, &consent.Status,
+		&consent.ExpiresAt, &consent.LastSyncedAt, &lastError, &consent.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Secret, err = r.secrets.Open(secret); err != nil {
+		return nil, err
+	}
+	if lastError != nil {
+		consent.LastError = *lastError
+	}
+	return &consent, nil
+}
+
+// ----- Links ----- //
+
+// SaveLink links the bank account to its ledger account, replacing the previous link of the bank account
+// The transactions synced through the previous link are forgotten with it, so the new account receives them too
+func (r *PostgresRepository) SaveLink(ctx context.Context, link *Link) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		deleteQuery := `DELETE FROM bank_account_links WHERE consent_id = $1 AND remote_account_id = $2`
+		if _, err := tx.Exec(ctx, deleteQuery, link.ConsentID, link.RemoteAccountID); err != nil {
+			return fmt.Errorf("failed to delete previous bank account link: %w", err)
+		}
+
+		insertQuery := `
+			INSERT INTO bank_account_links (id, consent_id, user_id, remote_account_id, account_id, last_synced_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		_, err := tx.Exec(ctx, insertQuery,
+			link.ID, link.ConsentID, link.UserID, link.RemoteAccountID, link.AccountID, link.LastSyncedAt, link.CreatedAt,
+		)
+		if err != nil {
+			if isUniqueViolation(err, linkAccountUniqueConstraint) {
+				return ErrAccountAlreadyLinked.With("account_id", link.AccountID)
+			}
+			return fmt.Errorf("failed to insert bank account link: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindLinksByConsentID retrieves the links of the bank accounts of a consent
+func (r *PostgresRepository) FindLinksByConsentID(ctx context.Context, consentID uuid.UUID) ([]*Link, error) {
+	query := `
+		SELECT id, consent_id, user_id, remote_account_id, account_id, last_synced_at, created_at
+		FROM bank_account_links
+		WHERE consent_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, consentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bank account links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]*Link, 0)
+	for rows.Next() {
+		var link Link
+		err := rows.Scan(&link.ID, &link.ConsentID, &link.UserID, &link.RemoteAccountID, &link.AccountID, &link.LastSyncedAt, &link.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank account link row: %w", err)
+		}
+		links = append(links, &link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank account link rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// DeleteLink deletes the link of a bank account, with the record of its synced transactions
+func (r *PostgresRepository) DeleteLink(ctx context.Context, consentID uuid.UUID, remoteAccountID string) error {
+	query := `DELETE FROM bank_account_links WHERE consent_id = $1 AND remote_account_id = $2`
+	tag, err := r.pool.Exec(ctx, query, consentID, remoteAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to delete bank account link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLinkNotFound.With("remote_account_id", remoteAccountID)
+	}
+	return nil
+}
+
+// UpdateLinkSyncedAt records when the link was last synced
+func (r *PostgresRepository) UpdateLinkSyncedAt(ctx context.Context, linkID uuid.UUID, syncedAt time.Time) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE bank_account_links SET last_synced_at = $2 WHERE id = $1`, linkID, syncedAt); err != nil {
+		return fmt.Errorf("failed to update bank account link sync: %w", err)
+	}
+	return nil
+}
+
+// ----- Synced transactions ----- //
+
+// ClaimTransactions inserts the transactions ignoring the ones already recorded, returning the inserted ones
+func (r *PostgresRepository) ClaimTransactions(ctx context.Context, linkID uuid.UUID, remoteTransactionIDs []string) ([]string, error) {
+	if len(remoteTransactionIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		INSERT INTO bank_synced_transactions (link_id, remote_transaction_id)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT (link_id, remote_transaction_id) DO NOTHING
+		RETURNING remote_transaction_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, linkID, remoteTransactionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim synced transactions: %w", err)
+	}
+	defer rows.Close()
+
+	claimed := make([]string, 0, len(remoteTransactionIDs))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan claimed transaction row: %w", err)
+		}
+		claimed = append(claimed, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating claimed transaction rows: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// ReleaseTransactions deletes the record of the given synced transactions
+func (r *PostgresRepository) ReleaseTransactions(ctx context.Context, linkID uuid.UUID, remoteTransactionIDs []string) error {
+	query := `DELETE FROM bank_synced_transactions WHERE link_id = $1 AND remote_transaction_id = ANY($2)`
+	if _, err := r.pool.Exec(ctx, query, linkID, remoteTransactionIDs); err != nil {
+		return fmt.Errorf("failed to release synced transactions: %w", err)
+	}
+	return nil
+}
+
+// RecordLedgerTransactions sets the ledger transaction of the synced transactions with a single UPDATE
+func (r *PostgresRepository) RecordLedgerTransactions(ctx context.Context, linkID uuid.UUID, ledgerTransactionIDs map[string]uuid.UUID) error {
+	if len(ledgerTransactionIDs) == 0 {
+		return nil
+	}
+
+	remoteIDs := make([]string, 0, len(ledgerTransactionIDs))
+	ledgerIDs := make([]uuid.UUID, 0, len(ledgerTransactionIDs))
+	for remoteID, ledgerID := range ledgerTransactionIDs {
+		remoteIDs = append(remoteIDs, remoteID)
+		ledgerIDs = append(ledgerIDs, ledgerID)
+	}
+
+	query := `
+		UPDATE bank_synced_transactions st
+		SET ledger_transaction_id = m.ledger_transaction_id
+		FROM unnest($2::text[], $3::uuid[]) AS m(remote_transaction_id, ledger_transaction_id)
+		WHERE st.link_id = $1 AND st.remote_transaction_id = m.remote_transaction_id
+	`
+	if _, err := r.pool.Exec(ctx, query, linkID, remoteIDs, ledgerIDs); err != nil {
+		return fmt.Errorf("failed to record synced ledger transactions: %w", err)
+	}
+	return nil
+}
+
+// FindLedgerTransactionIDs retrieves the ledger transactions tied to the synced transactions of the link
+func (r *PostgresRepository) FindLedgerTransactionIDs(ctx context.Context, linkID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT ledger_transaction_id
+		FROM bank_synced_transactions
+		WHERE link_id = $1 AND ledger_transaction_id IS NOT NULL
+	`
+
+	rows, err := r.pool.Query(ctx, query, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query synced ledger transactions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan synced ledger transaction row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating synced ledger transaction rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ----- Sync runs ----- //
+
+// StartSyncRun fails the stale running runs of the consent, prunes its old runs and inserts the new run in one transaction
+// The partial unique index on the running runs keeps two syncs of the consent from running at once
+func (r *PostgresRepository) StartSyncRun(ctx context.Context, run *SyncRun, staleBefore, pruneBefore time.Time) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		staleQuery := `
+			UPDATE bank_sync_runs
+			SET status = $2, error = 'interrupted before finishing', finished_at = $3
+			WHERE consent_id = $1 AND status = $4 AND started_at < $5
+		`
+		if _, err := tx.Exec(ctx, staleQuery, run.ConsentID, SyncFailed, run.StartedAt, SyncRunning, staleBefore); err != nil {
+			return fmt.Errorf("failed to fail stale bank sync runs: %w", err)
+		}
+
+		pruneQuery := `DELETE FROM bank_sync_runs WHERE consent_id = $1 AND started_at < $2`
+		if _, err := tx.Exec(ctx, pruneQuery, run.ConsentID, pruneBefore); err != nil {
+			return fmt.Errorf("failed to prune bank sync runs: %w", err)
+		}
+
+		insertQuery := `
+			INSERT INTO bank_sync_runs (id, consent_id, user_id, trigger, status, started_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		_, err := tx.Exec(ctx, insertQuery, run.ID, run.ConsentID, run.UserID, run.Trigger, run.Status, run.StartedAt)
+		if err != nil {
+			if isUniqueViolation(err, runningSyncUniqueIndex) {
+				return ErrSyncInProgress.With("consent_id", run.ConsentID)
+			}
+			return fmt.Errorf("failed to insert bank sync run: %w", err)
+		}
+		return nil
+	})
+}
+
+// FinishSyncRun updates the status, counters and error of the run
+func (r *PostgresRepository) FinishSyncRun(ctx context.Context, run *SyncRun) error {
+	query := `
+		UPDATE bank_sync_runs
+		SET status = $2, fetched = $3, imported = $4, matched = $5, duplicates = $6, categorized = $7, adjusted = $8,
+			error = NULLIF($9, ''), finished_at = $10
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query,
+		run.ID, run.Status, run.Result.Fetched, run.Result.Imported, run.Result.Matched, run.Result.Duplicates,
+		run.Result.Categorized, run.Result.Adjusted, run.Error, run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bank sync run: %w", err)
+	}
+	return nil
+}
+
+// FindSyncRuns retrieves a page of the runs of the consent, newest first
+func (r *PostgresRepository) FindSyncRuns(ctx context.Context, consentID uuid.UUID, limit, offset int) ([]*SyncRun, error) {
+	query := `
+		SELECT id, consent_id, user_id, trigger, status, fetched, imported, matched, duplicates, categorized, adjusted,
+			error, started_at, finished_at
+		FROM bank_sync_runs
+		WHERE consent_id = $1
+		ORDER BY started_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, consentID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bank sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*SyncRun, 0)
+	for rows.Next() {
+		var (
+			run      SyncRun
+			runError *string
+		)
+		err := rows.Scan(
+			&run.ID, &run.ConsentID, &run.UserID, &run.Trigger, &run.Status, &run.Result.Fetched, &run.Result.Imported,
+			&run.Result.Matched, &run.Result.Duplicates, &run.Result.Categorized, &run.Result.Adjusted,
+			&runError, &run.StartedAt, &run.FinishedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank sync run row: %w", err)
+		}
+		if runError != nil {
+			run.Error = *runError
+		}
+		runs = append(runs, &run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank sync run rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+// isUniqueViolation reports whether err violates the named unique constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,81 @@
+package bankconnect
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// errSecretKeyRequired is returned when a consent holding a secret is stored without an encryption key configured
+var errSecretKeyRequired = errors.New("BANK_CONNECT_SECRET_KEY is required to store the secrets of bank consents")
+
+// SecretBox encrypts the consent secrets with AES-256-GCM, so a database dump does not hand out access to bank accounts
+// A nil SecretBox stores no secrets, for setups whose providers need none
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox creates a SecretBox from a base64 encoded 32 bytes key, returning nil when the key is empty
+func NewSecretBox(encodedKey string) (*SecretBox, error) {
+	if encodedKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bank connect secret key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid bank connect secret key: expected 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bank connect cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bank connect cipher: %w", err)
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts the secret, prefixing it with its random nonce, an empty secret is stored as nil
+func (b *SecretBox) Seal(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	if b == nil {
+		return nil, errSecretKeyRequired
+	}
+
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate secret nonce: %w", err)
+	}
+	return b.aead.Seal(nonce, nonce, []byte(secret), nil), nil
+}
+
+// Open decrypts a secret sealed by Seal
+func (b *SecretBox) Open(sealed []byte) (string, error) {
+	if len(sealed) == 0 {
+		return "", nil
+	}
+	if b == nil {
+		return "", errSecretKeyRequired
+	}
+
+	nonceSize := b.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("sealed secret is too short")
+	}
+	secret, err := b.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(secret), nil
+}
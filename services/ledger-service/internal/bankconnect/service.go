@@ -0,0 +1,824 @@
+package bankconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the bank connections
+const (
+	auditConsentCreated        = "bank_consent.created"
+	auditConsentRevoked        = "bank_consent.revoked"
+	auditAccountLinked         = "bank_account.linked"
+	auditAccountUnlinked       = "bank_account.unlinked"
+	auditResourceBankConsent   = "bank_consent"
+	auditResourceLedgerAccount = "account"
+
+	// syncJobKind identifies the sync jobs started by the user on the job status endpoint
+	syncJobKind = "bank_sync"
+)
+
+const (
+	// maxDescriptionLength is the longest description the ledger accepts, longer bank descriptions are cut
+	maxDescriptionLength = 100
+	// defaultDescription describes the bank transactions without description, which the ledger requires
+	defaultDescription = "Transação bancária"
+)
+
+// AccountSyncer reads the linked ledger accounts and writes the synced transactions, satisfied by the ledger Service
+type AccountSyncer interface {
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+	ImportTransactions(ctx context.Context, params ledger.ImportTransactionsParams) ([]ledger.Transaction, error)
+	MarkTransactionsAsPaid(ctx context.Context, params ledger.MarkTransactionsPaidParams) (int, error)
+	AdjustAccountBalance(ctx context.Context, params ledger.BalanceAdjustmentParams) (*ledger.Account, error)
+}
+
+// Categorizer picks the category of the imported bank transactions, satisfied by the categorization Service
+type Categorizer interface {
+	// Categorize returns the category of each description of a transaction of the account, nil where none applies
+	Categorize(ctx context.Context, userID, accountID uuid.UUID, descriptions []string) ([]*uuid.UUID, error)
+}
+
+// ConnectParams holds all the required data for the Connect use case
+type ConnectParams struct {
+	UserID   uuid.UUID
+	Provider Provider
+	Token    string // Token is returned by the provider's widget once the user consents (e.g., the Pluggy item ID or the Plaid public token)
+}
+
+// LinkParams holds all the required data for the LinkAccount use case
+type LinkParams struct {
+	UserID          uuid.UUID
+	ConsentID       uuid.UUID
+	RemoteAccountID string
+	AccountID       uuid.UUID
+}
+
+// ConsentAccount is a bank account of a consent with the ledger account it syncs into, if any
+type ConsentAccount struct {
+	RemoteAccount
+	Link *Link
+}
+
+// Service connects bank consents to the ledger: the user adds a consent given on an aggregator,
+// links its bank accounts to ledger accounts, and their posted transactions are synced on demand and on a schedule
+type Service struct {
+	repo        Repository
+	connectors  map[Provider]Connector
+	accounts    AccountSyncer
+	categorizer Categorizer
+	jobs        *jobs.Service
+	auditor     *audit.Logger
+	clock       clock.Clock
+}
+
+// NewService creates a new instance of the bank connect Service, reading the consents of the given connectors
+func NewService(repo Repository, accounts AccountSyncer, categorizer Categorizer, jobs *jobs.Service, auditor *audit.Logger, clock clock.Clock, connectors ...Connector) *Service {
+	byProvider := make(map[Provider]Connector, len(connectors))
+	for _, connector := range connectors {
+		byProvider[connector.Provider()] = connector
+	}
+
+	return &Service{
+		repo:        repo,
+		connectors:  byProvider,
+		accounts:    accounts,
+		categorizer: categorizer,
+		jobs:        jobs,
+		auditor:     auditor,
+		clock:       clock,
+	}
+}
+
+// CreateLinkToken is the use case for opening the widget of the provider, where the user gives a consent
+func (s *Service) CreateLinkToken(ctx context.Context, userID uuid.UUID, provider Provider) (*LinkToken, error) {
+	connector, err := s.connector(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := connector.CreateLinkToken(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bank link token: %w", err)
+	}
+	return token, nil
+}
+
+// Connect is the use case for adding the consent the user gave in the provider's widget, returning the bank accounts it shares
+// The accounts are read before the consent is saved, a consent that cannot be saved is revoked so it does not linger on the provider
+func (s *Service) Connect(ctx context.Context, params ConnectParams) (*Consent, []RemoteAccount, error) {
+	connector, err := s.connector(params.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	credentials, err := connector.Connect(ctx, params.Token)
+	if err != nil {
+		return nil, nil, err
+	}
+	consent := &Consent{
+		ID:         uuid.New(),
+		UserID:     params.UserID,
+		Provider:   params.Provider,
+		ExternalID: credentials.ExternalID,
+		Secret:     credentials.Secret,
+		Status:     ConsentActive,
+		ExpiresAt:  credentials.ExpiresAt,
+		CreatedAt:  s.clock.Now(),
+	}
+
+	accounts, err := connector.Accounts(ctx, consent)
+	if err == nil {
+		err = s.repo.SaveConsent(ctx, consent)
+	}
+	if err != nil {
+		// A consent already added is the same consent on the provider, which must keep working
+		if !errors.Is(err, ErrConsentAlreadyExists) && consent.Secret != "" {
+			if revokeErr := connector.Revoke(ctx, consent); revokeErr != nil {
+				err = errors.Join(err, revokeErr)
+			}
+		}
+		return nil, nil, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditConsentCreated,
+		ResourceType: auditResourceBankConsent,
+		ResourceID:   consent.ID.String(),
+		Metadata: map[string]string{
+			"provider": string(consent.Provider),
+			"accounts": strconv.Itoa(len(accounts)),
+		},
+	})
+
+	return consent, accounts, nil
+}
+
+// FindConsents is the use case for listing the consents of the user
+func (s *Service) FindConsents(ctx context.Context, userID uuid.UUID) ([]*Consent, error) {
+	consents, err := s.repo.FindConsentsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank consents: %w", err)
+	}
+	return consents, nil
+}
+
+// Accounts is the use case for listing the bank accounts of an active consent with their links
+func (s *Service) Accounts(ctx context.Context, userID, consentID uuid.UUID) ([]ConsentAccount, error) {
+	consent, connector, err := s.activeConsent(ctx, userID, consentID)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := connector.Accounts(ctx, consent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the accounts of the bank consent: %w", err)
+	}
+	links, err := s.repo.FindLinksByConsentID(ctx, consent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank account links: %w", err)
+	}
+	byRemoteID := make(map[string]*Link, len(links))
+	for _, link := range links {
+		byRemoteID[link.RemoteAccountID] = link
+	}
+
+	accounts := make([]ConsentAccount, len(remote))
+	for i, account := range remote {
+		accounts[i] = ConsentAccount{RemoteAccount: account, Link: byRemoteID[account.ID]}
+	}
+	return accounts, nil
+}
+
+// LinkAccount is the use case for syncing a bank account into a ledger account of the same currency and kind
+// Linking the bank account to another ledger account replaces its link, the next sync reading its history again
+func (s *Service) LinkAccount(ctx context.Context, params LinkParams) (*Link, error) {
+	consent, connector, err := s.activeConsent(ctx, params.UserID, params.ConsentID)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := connector.Accounts(ctx, consent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the accounts of the bank consent: %w", err)
+	}
+	var remoteAccount *RemoteAccount
+	for i := range remote {
+		if remote[i].ID == params.RemoteAccountID {
+			remoteAccount = &remote[i]
+			break
+		}
+	}
+	if remoteAccount == nil {
+		return nil, ErrRemoteAccountNotFound.With("remote_account_id", params.RemoteAccountID)
+	}
+
+	account, err := s.accounts.FindAccountByID(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account to link: %w", err)
+	}
+	if err := checkLinkable(remoteAccount, account); err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.FindLinksByConsentID(ctx, consent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank account links: %w", err)
+	}
+	for _, link := range links {
+		if link.RemoteAccountID == params.RemoteAccountID && link.AccountID == account.ID {
+			return link, nil
+		}
+	}
+
+	link := &Link{
+		ID:              uuid.New(),
+		ConsentID:       consent.ID,
+		UserID:          params.UserID,
+		RemoteAccountID: params.RemoteAccountID,
+		AccountID:       account.ID,
+		CreatedAt:       s.clock.Now(),
+	}
+	if err := s.repo.SaveLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditAccountLinked,
+		ResourceType: auditResourceLedgerAccount,
+		ResourceID:   account.ID.String(),
+		Metadata: map[string]string{
+			"consent_id":        consent.ID.String(),
+			"remote_account_id": params.RemoteAccountID,
+		},
+	})
+
+	return link, nil
+}
+
+// UnlinkAccount is the use case for stopping the sync of a bank account, the transactions already synced are kept
+func (s *Service) UnlinkAccount(ctx context.Context, userID, consentID uuid.UUID, remoteAccountID string) error {
+	consent, err := s.repo.FindConsentByID(ctx, userID, consentID)
+	if err != nil {
+		return fmt.Errorf("failed to find bank consent: %w", err)
+	}
+	if err := s.repo.DeleteLink(ctx, consent.ID, remoteAccountID); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditAccountUnlinked,
+		ResourceType: auditResourceBankConsent,
+		ResourceID:   consent.ID.String(),
+		Metadata:     map[string]string{"remote_account_id": remoteAccountID},
+	})
+
+	return nil
+}
+
+// Revoke is the use case for revoking a consent on the provider, its links stop syncing but the synced transactions are kept
+func (s *Service) Revoke(ctx context.Context, userID, consentID uuid.UUID) error {
+	consent, err := s.repo.FindConsentByID(ctx, userID, consentID)
+	if err != nil {
+		return fmt.Errorf("failed to find bank consent: %w", err)
+	}
+	if consent.Status == ConsentRevoked {
+		return nil
+	}
+
+	connector, err := s.connector(consent.Provider)
+	if err != nil {
+		return err
+	}
+	if err := connector.Revoke(ctx, consent); err != nil {
+		return fmt.Errorf("failed to revoke bank consent: %w", err)
+	}
+	if err := s.repo.UpdateConsentStatus(ctx, consent.ID, ConsentRevoked); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditConsentRevoked,
+		ResourceType: auditResourceBankConsent,
+		ResourceID:   consent.ID.String(),
+		Metadata:     map[string]string{"provider": string(consent.Provider)},
+	})
+
+	return nil
+}
+
+// Sync is the use case for syncing the linked accounts of a consent in the background
+// The sync run is started before the job, so a sync already running is refused with ErrSyncInProgress
+func (s *Service) Sync(ctx context.Context, userID, consentID uuid.UUID) (*jobs.Job, error) {
+	consent, _, err := s.activeConsent(ctx, userID, consentID)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.startSyncRun(ctx, consent, SyncManual)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobs.Enqueue(ctx, userID, syncJobKind, func(ctx context.Context, progress jobs.ProgressFunc) (any, error) {
+		return s.syncConsent(ctx, consent, run)
+	})
+	if err != nil {
+		s.finishSyncRun(ctx, run, &SyncResult{}, err)
+		return nil, fmt.Errorf("failed to start bank sync: %w", err)
+	}
+
+	return job, nil
+}
+
+// FindSyncRuns is the use case for listing a page of the sync runs of a consent of the user, newest first
+func (s *Service) FindSyncRuns(ctx context.Context, userID, consentID uuid.UUID, limit, offset int) ([]*SyncRun, error) {
+	consent, err := s.repo.FindConsentByID(ctx, userID, consentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank consent: %w", err)
+	}
+
+	runs, err := s.repo.FindSyncRuns(ctx, consent.ID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank sync runs: %w", err)
+	}
+	return runs, nil
+}
+
+// SyncAll is the scheduled task syncing every active consent, each in its own sync run
+// A consent that fails to sync is skipped, so one unavailable bank does not hold back every other sync,
+// and a consent the user is syncing at the same time is left to that sync
+func (s *Service) SyncAll(ctx context.Context) error {
+	consents, err := s.repo.FindActiveConsents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find bank consents to sync: %w", err)
+	}
+
+	failed, imported := 0, 0
+	for _, consent := range consents {
+		result, err := s.syncScheduled(ctx, consent)
+		if err != nil && !errors.Is(err, ErrSyncInProgress) {
+			failed++
+			ctxlogger.GetLogger(ctx).Error("failed to sync bank consent",
+				slog.String("consent_id", consent.ID.String()),
+				slog.String("user_id", consent.UserID.String()),
+				slog.String("error", err.Error()),
+			)
+		}
+		if result != nil {
+			imported += result.Imported
+		}
+	}
+
+	ctxlogger.GetLogger(ctx).Info("synced bank consents",
+		slog.Int("consents", len(consents)),
+		slog.Int("imported", imported),
+		slog.Int("failed", failed),
+	)
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d of %d bank consents", failed, len(consents))
+	}
+	return nil
+}
+
+// HandleWebhook is the use case for syncing in the background the consents a verified webhook of the provider signals new data for
+// Webhooks about unknown or inactive consents, and about consents already syncing, are acknowledged without a sync,
+// since the providers retry the webhooks they see failing
+func (s *Service) HandleWebhook(ctx context.Context, provider Provider, header http.Header, body []byte) error {
+	connector, err := s.connector(provider)
+	if err != nil {
+		return err
+	}
+	event, err := connector.ParseWebhook(ctx, header, body)
+	if err != nil || event == nil {
+		return err
+	}
+
+	consents, err := s.repo.FindConsentsByExternalID(ctx, provider, event.ExternalID)
+	if err != nil {
+		return fmt.Errorf("failed to find bank consents of webhook: %w", err)
+	}
+
+	logger := ctxlogger.GetLogger(ctx)
+	for _, consent := range consents {
+		if err := s.expireConsent(ctx, consent); err != nil {
+			continue
+		}
+		run, err := s.startSyncRun(ctx, consent, SyncWebhook)
+		if errors.Is(err, ErrSyncInProgress) {
+			logger.Info("bank consent already syncing, webhook skipped",
+				slog.String("consent_id", consent.ID.String()),
+				slog.String("event", event.Name),
+			)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = s.jobs.Enqueue(ctx, consent.UserID, syncJobKind, func(ctx context.Context, progress jobs.ProgressFunc) (any, error) {
+			return s.syncConsent(ctx, consent, run)
+		})
+		if err != nil {
+			s.finishSyncRun(ctx, run, &SyncResult{}, err)
+			return fmt.Errorf("failed to start bank sync: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// syncScheduled syncs a consent in a scheduled run
+func (s *Service) syncScheduled(ctx context.Context, consent *Consent) (*SyncResult, error) {
+	if err := s.expireConsent(ctx, consent); err != nil {
+		return nil, err
+	}
+	run, err := s.startSyncRun(ctx, consent, SyncScheduled)
+	if err != nil {
+		return nil, err
+	}
+	return s.syncConsent(ctx, consent, run)
+}
+
+// startSyncRun records the start of a sync of the consent
+func (s *Service) startSyncRun(ctx context.Context, consent *Consent, trigger SyncTrigger) (*SyncRun, error) {
+	now := s.clock.Now()
+	run := &SyncRun{
+		ID:        uuid.New(),
+		ConsentID: consent.ID,
+		UserID:    consent.UserID,
+		Trigger:   trigger,
+		Status:    SyncRunning,
+		StartedAt: now,
+	}
+	if err := s.repo.StartSyncRun(ctx, run, now.Add(-staleSyncRunAfter), now.Add(-syncRunRetention)); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// finishSyncRun records the outcome of a sync run, a failure to record it is only logged since the sync itself is done
+func (s *Service) finishSyncRun(ctx context.Context, run *SyncRun, result *SyncResult, syncErr error) {
+	run.Result = *result
+	run.finish(s.clock.Now(), syncErr)
+	if err := s.repo.FinishSyncRun(ctx, run); err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to record bank sync run",
+			slog.String("run_id", run.ID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// expireConsent fails with ErrConsentNotActive when the consent can no longer be synced,
+// marking it expired when it expired on the provider's side, so the user knows to consent again
+func (s *Service) expireConsent(ctx context.Context, consent *Consent) error {
+	if consent.IsActive(s.clock.Now()) {
+		return nil
+	}
+	if consent.Status == ConsentActive {
+		if err := s.repo.UpdateConsentStatus(ctx, consent.ID, ConsentExpired); err != nil {
+			return err
+		}
+	}
+	return ErrConsentNotActive.With("consent_id", consent.ID)
+}
+
+// syncConsent syncs every link of the consent, recording the outcome on the run and on the consent
+// A failing link does not stop the others, the partial result is returned with the joined errors
+func (s *Service) syncConsent(ctx context.Context, consent *Consent, run *SyncRun) (*SyncResult, error) {
+	result := &SyncResult{}
+	syncErr := s.syncLinks(ctx, consent, result)
+	s.finishSyncRun(ctx, run, result, syncErr)
+
+	message := ""
+	if syncErr != nil {
+		message = syncErr.Error()
+	}
+	if err := s.repo.UpdateConsentSync(ctx, consent.ID, run.StartedAt, message); err != nil {
+		return result, errors.Join(syncErr, err)
+	}
+
+	return result, syncErr
+}
+
+// syncLinks syncs every link of the consent into result, joining the errors of the failing links
+func (s *Service) syncLinks(ctx context.Context, consent *Consent, result *SyncResult) error {
+	if err := s.expireConsent(ctx, consent); err != nil {
+		return err
+	}
+	connector, err := s.connector(consent.Provider)
+	if err != nil {
+		return err
+	}
+	links, err := s.repo.FindLinksByConsentID(ctx, consent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find bank account links: %w", err)
+	}
+
+	now := s.clock.Now()
+	var errs []error
+	for _, link := range links {
+		if err := s.syncLink(ctx, connector, consent, link, now, result); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync bank account %s: %w", link.RemoteAccountID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncLink adds to the ledger the posted bank transactions no earlier sync handled, then reconciles the balance of checking accounts
+// Pending transactions are left for a later sync, once the bank posts them
+func (s *Service) syncLink(ctx context.Context, connector Connector, consent *Consent, link *Link, now time.Time, result *SyncResult) error {
+	account, err := s.accounts.FindAccountByID(ctx, link.UserID, link.AccountID)
+	if err != nil {
+		return err
+	}
+	if account.ArchivedAt != nil {
+		return nil
+	}
+
+	remote, err := connector.Transactions(ctx, consent, link.RemoteAccountID, link.syncFrom(now))
+	if err != nil {
+		return err
+	}
+
+	posted := make(map[string]RemoteTransaction, len(remote))
+	ids := make([]string, 0, len(remote))
+	for _, tx := range remote {
+		if tx.Pending || tx.Amount.IsZero() || tx.Amount.Currency != account.Currency {
+			continue
+		}
+		if _, ok := posted[tx.ID]; !ok {
+			ids = append(ids, tx.ID)
+		}
+		posted[tx.ID] = tx
+	}
+	result.Fetched += len(ids)
+
+	claimed, err := s.repo.ClaimTransactions(ctx, link.ID, ids)
+	if err != nil {
+		return err
+	}
+	result.Duplicates += len(ids) - len(claimed)
+	if len(claimed) > 0 {
+		transactions := make([]RemoteTransaction, len(claimed))
+		for i, id := range claimed {
+			transactions[i] = posted[id]
+		}
+		if err := s.addTransactions(ctx, connector.Provider(), link, account, transactions, now, result); err != nil {
+			return err
+		}
+	}
+
+	if account.Kind == ledger.Checking || account.Kind == ledger.Savings {
+		adjusted, err := s.reconcile(ctx, connector, consent, link)
+		if err != nil {
+			return err
+		}
+		if adjusted {
+			result.Adjusted++
+		}
+	}
+
+	return s.repo.UpdateLinkSyncedAt(ctx, link.ID, now)
+}
+
+// addTransactions adds the claimed bank transactions to the ledger account: the ones matching a transaction the user
+// already entered settle it, the others are imported with the category of the user's rules
+// The claims are released when nothing reached the ledger, so the next sync handles them again
+func (s *Service) addTransactions(ctx context.Context, provider Provider, link *Link, account *ledger.Account, transactions []RemoteTransaction, now time.Time, result *SyncResult) error {
+	source := strings.ToLower(string(provider))
+	claimed := make([]string, len(transactions))
+	for i, tx := range transactions {
+		claimed[i] = tx.ID
+	}
+	release := func(err error) error {
+		if releaseErr := s.repo.ReleaseTransactions(ctx, link.ID, claimed); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+
+	takenIDs, err := s.repo.FindLedgerTransactionIDs(ctx, link.ID)
+	if err != nil {
+		return release(err)
+	}
+	taken := make(map[uuid.UUID]bool, len(takenIDs))
+	for _, id := range takenIDs {
+		taken[id] = true
+	}
+
+	matches := matchTransactions(transactions, account.Transactions(), taken)
+	ledgerIDs := make(map[string]uuid.UUID, len(transactions))
+	payments := make([]ledger.TransactionPayment, 0, len(matches))
+	toImport := make([]RemoteTransaction, 0, len(transactions)-len(matches))
+	for _, tx := range transactions {
+		match, ok := matches[tx.ID]
+		if !ok {
+			toImport = append(toImport, tx)
+			continue
+		}
+		ledgerIDs[tx.ID] = match.ID
+		if match.PaidAt == nil {
+			payments = append(payments, ledger.TransactionPayment{TransactionID: match.ID, PaidAt: earliest(tx.Date, now)})
+		}
+	}
+
+	if len(payments) > 0 {
+		_, err := s.accounts.MarkTransactionsAsPaid(ctx, ledger.MarkTransactionsPaidParams{
+			AccountID: account.ID,
+			UserID:    link.UserID,
+			Source:    source,
+			Payments:  payments,
+		})
+		if err != nil {
+			return release(err)
+		}
+	}
+	result.Matched += len(matches)
+
+	if len(toImport) > 0 {
+		imports, categorized, err := s.toImportedTransactions(ctx, link, toImport)
+		if err != nil {
+			return release(err)
+		}
+
+		imported, err := s.accounts.ImportTransactions(ctx, ledger.ImportTransactionsParams{
+			AccountID:    account.ID,
+			UserID:       link.UserID,
+			Source:       source,
+			Transactions: imports,
+		})
+		if err != nil {
+			return release(err)
+		}
+		for i, tx := range imported {
+			ledgerIDs[toImport[i].ID] = tx.ID
+		}
+		result.Imported += len(imported)
+		result.Categorized += categorized
+	}
+
+	// The claims stay even if recording fails, releasing them would import the transactions again
+	return s.repo.RecordLedgerTransactions(ctx, link.ID, ledgerIDs)
+}
+
+// toImportedTransactions maps the bank transactions to the ledger import, categorized by the user's rules,
+// returning how many a rule categorized
+func (s *Service) toImportedTransactions(ctx context.Context, link *Link, transactions []RemoteTransaction) ([]ledger.ImportedTransaction, int, error) {
+	imports := make([]ledger.ImportedTransaction, len(transactions))
+	descriptions := make([]string, len(transactions))
+	for i, tx := range transactions {
+		imports[i] = toImportedTransaction(tx)
+		descriptions[i] = tx.Description
+	}
+
+	categories, err := s.categorizer.Categorize(ctx, link.UserID, link.AccountID, descriptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to categorize bank transactions: %w", err)
+	}
+	categorized := 0
+	for i, categoryID := range categories {
+		if categoryID != nil {
+			imports[i].CategoryID = categoryID
+			categorized++
+		}
+	}
+	return imports, categorized, nil
+}
+
+// reconcile adjusts the real balance of a checking or savings account to the bank balance, for the transactions older than the first sync
+// The adjustment is computed on the real balance, so the transactions the user scheduled ahead are kept out of it
+func (s *Service) reconcile(ctx context.Context, connector Connector, consent *Consent, link *Link) (bool, error) {
+	bankBalance, err := connector.Balance(ctx, consent, link.RemoteAccountID)
+	if err != nil {
+		return false, err
+	}
+
+	account, err := s.accounts.FindAccountByID(ctx, link.UserID, link.AccountID)
+	if err != nil {
+		return false, err
+	}
+	realBalance, err := account.RealBalance(s.clock)
+	if err != nil {
+		return false, err
+	}
+	diff, err := bankBalance.Sub(realBalance)
+	if err != nil || diff.IsZero() {
+		return false, err
+	}
+	projected, err := account.ProjectedBalance()
+	if err != nil {
+		return false, err
+	}
+	newBalance, err := projected.Add(diff)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.accounts.AdjustAccountBalance(ctx, ledger.BalanceAdjustmentParams{
+		AccountID:  account.ID,
+		UserID:     link.UserID,
+		NewBalance: newBalance.Amount,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// activeConsent returns an active consent of the user with the connector of its provider
+func (s *Service) activeConsent(ctx context.Context, userID, consentID uuid.UUID) (*Consent, Connector, error) {
+	consent, err := s.repo.FindConsentByID(ctx, userID, consentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find bank consent: %w", err)
+	}
+	if !consent.IsActive(s.clock.Now()) {
+		return nil, nil, ErrConsentNotActive.With("consent_id", consent.ID)
+	}
+	connector, err := s.connector(consent.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	return consent, connector, nil
+}
+
+// connector returns the connector of the provider, failing when it is not configured
+func (s *Service) connector(provider Provider) (Connector, error) {
+	connector, ok := s.connectors[provider]
+	if !ok {
+		return nil, ErrUnsupportedProvider.With("provider", provider)
+	}
+	return connector, nil
+}
+
+// checkLinkable checks the bank account can be synced into the ledger account
+func checkLinkable(remote *RemoteAccount, account *ledger.Account) error {
+	if err := account.EnsureWritable(); err != nil {
+		return err
+	}
+	if remote.Currency != account.Currency {
+		return ErrLinkCurrencyMismatch.With("bank_currency", remote.Currency).With("account_currency", account.Currency)
+	}
+	if (remote.Type == RemoteCreditCard) != (account.Kind == ledger.CreditCard) || account.Kind == ledger.Investment {
+		return ErrLinkKindMismatch.With("bank_account_type", remote.Type).With("account_kind", account.Kind)
+	}
+	return nil
+}
+
+// toImportedTransaction maps a posted bank transaction to the ledger import, whose type follows the sign of the amount
+func toImportedTransaction(tx RemoteTransaction) ledger.ImportedTransaction {
+	description := strings.TrimSpace(tx.Description)
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		description = strings.TrimSpace(string([]rune(description)[:maxDescriptionLength]))
+	}
+	if description == "" {
+		description = defaultDescription
+	}
+
+	return ledger.ImportedTransaction{
+		Description: description,
+		Amount:      formatAmount(tx.Amount),
+		Date:        tx.Date,
+		Pix:         tx.Pix,
+	}
+}
+
+// earliest returns the earliest of the two times
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// formatAmount writes the amount as a plain decimal string (e.g., "-1234.56"), which money.Parse reads back exactly
+func formatAmount(m money.Money) string {
+	minorUnits := money.MinorUnits(m.Currency)
+	sign, amount := "", uint64(m.Amount)
+	if m.Amount < 0 {
+		sign, amount = "-", uint64(-m.Amount)
+	}
+	if minorUnits == 0 {
+		return sign + strconv.FormatUint(amount, 10)
+	}
+
+	scale := uint64(1)
+	for range minorUnits {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, minorUnits, amount%scale)
+}
@@ -0,0 +1,166 @@
+package budgets
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBudgetNotFound         = errx.New(errx.CategoryNotFound, "BUDGET_NOT_FOUND", "budget not found")
+	ErrCategoryNotFound       = errx.New(errx.CategoryNotFound, "CATEGORY_NOT_FOUND", "category not found")
+	ErrInvalidBudgetAmount    = errx.New(errx.CategoryValidation, "INVALID_BUDGET_AMOUNT", "the budget amount must be positive")
+	ErrInvalidAlertThresholds = errx.New(errx.CategoryValidation, "INVALID_ALERT_THRESHOLDS", "alert thresholds must be distinct percentages within the allowed range")
+	ErrUnsupportedCurrency    = errx.New(errx.CategoryValidation, "UNSUPPORTED_CURRENCY", "currency is not supported")
+	ErrTooManyAlertThresholds = errx.New(errx.CategoryValidation, "TOO_MANY_ALERT_THRESHOLDS", "too many alert thresholds")
+	ErrInvalidHistoryMonths   = errx.New(errx.CategoryValidation, "INVALID_HISTORY_MONTHS", "the budget history must cover between 1 and the allowed number of months")
+)
+
+const (
+	minAlertThreshold  = 1   // minAlertThreshold is the lowest percentage of a budget that can trigger an alert
+	maxAlertThreshold  = 200 // maxAlertThreshold lets users be alerted well past their budget
+	maxAlertThresholds = 5
+
+	DefaultHistoryMonths = 12 // DefaultHistoryMonths is how many financial months the budget history covers when not asked
+	maxHistoryMonths     = 36
+)
+
+// DefaultAlertThresholds are the percentages of a budget alerting users who never changed them
+var DefaultAlertThresholds = []int{80, 100}
+
+// BudgetRepository persists the monthly budgets and the alerts already sent for them
+type BudgetRepository interface {
+	Save(ctx context.Context, budget *Budget) error
+	FindByID(ctx context.Context, budgetID uuid.UUID) (*Budget, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Budget, error)
+	FindByCategory(ctx context.Context, userID, categoryID uuid.UUID) (*Budget, error)
+	Delete(ctx context.Context, budgetID uuid.UUID) error
+	// MarkAlertSent records the alert of a threshold for a month, reporting false when it was already sent
+	MarkAlertSent(ctx context.Context, budgetID uuid.UUID, month time.Time, threshold int, sentAt time.Time) (bool, error)
+}
+
+// AlertPreferencesRepository persists the alert thresholds chosen by each user
+type AlertPreferencesRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*AlertPreferences, error)
+	Save(ctx context.Context, prefs *AlertPreferences) error
+}
+
+// SpendingReader reads the categories and expenses the budgets are checked against
+type SpendingReader interface {
+	// CategoryName returns the name of a category visible to the user (its own or a default one)
+	CategoryName(ctx context.Context, userID, categoryID uuid.UUID) (string, error)
+	// CategorySpending returns the total spent (as a positive amount) in a category and currency within [from, to)
+	CategorySpending(ctx context.Context, userID, categoryID uuid.UUID, currency string, from, to time.Time) (int64, error)
+	// MonthlyCategorySpending returns the total spent in a category and currency within each of the contiguous periods
+	// delimited by bounds ([bounds[0], bounds[1]), [bounds[1], bounds[2]), ...), in the same order
+	MonthlyCategorySpending(ctx context.Context, userID, categoryID uuid.UUID, currency string, bounds []time.Time) ([]int64, error)
+}
+
+// Budget is the amount a user plans to spend each month in a category
+type Budget struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	CategoryID uuid.UUID
+	Amount     money.Money
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NewBudget creates a new monthly Budget for a category
+func NewBudget(userID, categoryID uuid.UUID, amount money.Money, ids idgen.Generator, clock clock.Clock) (*Budget, error) {
+	if !money.IsKnownCurrency(amount.Currency) {
+		return nil, ErrUnsupportedCurrency.With("currency", amount.Currency)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidBudgetAmount
+	}
+
+	now := clock.Now()
+	return &Budget{
+		ID:         ids.NewID(),
+		UserID:     userID,
+		CategoryID: categoryID,
+		Amount:     amount,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// ChangeAmount replaces the monthly amount of the budget
+func (b *Budget) ChangeAmount(amount money.Money, clock clock.Clock) error {
+	if !money.IsKnownCurrency(amount.Currency) {
+		return ErrUnsupportedCurrency.With("currency", amount.Currency)
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidBudgetAmount
+	}
+
+	b.Amount = amount
+	b.UpdatedAt = clock.Now()
+	return nil
+}
+
+// CrossedThresholds returns the thresholds (percentages of the budget) reached by spentAfter but not by spentBefore
+func (b *Budget) CrossedThresholds(thresholds []int, spentBefore, spentAfter int64) []int {
+	var crossed []int
+	for _, t := range thresholds {
+		limit := b.Amount.Amount * int64(t)
+		if spentBefore*100 < limit && spentAfter*100 >= limit {
+			crossed = append(crossed, t)
+		}
+	}
+	return crossed
+}
+
+// BudgetMonth is what was budgeted and spent in a category over one financial month
+type BudgetMonth struct {
+	Start    time.Time // Start and End delimit the half-open range [Start, End) of the month
+	End      time.Time
+	Budgeted money.Money
+	Spent    money.Money
+}
+
+// BudgetHistory is a budget compared with the spending of its category over the last financial months
+// Budget amounts are not versioned, so every month is compared with the current amount
+type BudgetHistory struct {
+	Budget       *Budget
+	CategoryName string
+	Months       []BudgetMonth // Months are ordered from the oldest, the current month last
+}
+
+// AlertPreferences holds the percentages of a budget at which a user is alerted
+type AlertPreferences struct {
+	UserID     uuid.UUID
+	Thresholds []int // Thresholds are sorted ascending
+	UpdatedAt  time.Time
+}
+
+// DefaultAlertPreferences returns the alert preferences of a user who never changed them
+func DefaultAlertPreferences(userID uuid.UUID) *AlertPreferences {
+	return &AlertPreferences{UserID: userID, Thresholds: slices.Clone(DefaultAlertThresholds)}
+}
+
+// SetThresholds replaces the alert thresholds, an empty list disables the alerts
+func (p *AlertPreferences) SetThresholds(thresholds []int, clock clock.Clock) error {
+	if len(thresholds) > maxAlertThresholds {
+		return ErrTooManyAlertThresholds.With("max_thresholds", maxAlertThresholds)
+	}
+
+	sorted := slices.Clone(thresholds)
+	slices.Sort(sorted)
+	for i, t := range sorted {
+		if t < minAlertThreshold || t > maxAlertThreshold || (i > 0 && sorted[i-1] == t) {
+			return ErrInvalidAlertThresholds.With("min", minAlertThreshold).With("max", maxAlertThreshold)
+		}
+	}
+
+	p.Thresholds = sorted
+	p.UpdatedAt = clock.Now()
+	return nil
+}
@@ -0,0 +1,291 @@
+package budgets
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BudgetHandler holds dependencies for budget-related HTTP handlers
+type BudgetHandler struct {
+	budgetService *Service
+}
+
+// NewBudgetHandler creates a new instance of BudgetHandler
+func NewBudgetHandler(budgetService *Service) *BudgetHandler {
+	return &BudgetHandler{budgetService: budgetService}
+}
+
+// RegisterRoutes sets up the API routes for the budgets module
+func (h *BudgetHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	budgetsGroup := apiRouteGroup.Group("/budgets")
+
+	budgetsGroup.GET("", h.listBudgetsHandler)
+	budgetsGroup.PUT("/categories/:categoryId", h.setBudgetHandler)
+	budgetsGroup.DELETE("/:id", h.deleteBudgetHandler)
+	budgetsGroup.GET("/:categoryId/history", h.budgetHistoryHandler)
+	budgetsGroup.GET("/alert-preferences", h.getAlertPreferencesHandler)
+	budgetsGroup.PUT("/alert-preferences", h.updateAlertPreferencesHandler)
+}
+
+// SetBudgetRequest defines the expected JSON body for setting the monthly budget of a category
+// Version 1 bodies send the amount as a number of minor units (e.g., 150000), see BindVersion
+type SetBudgetRequest struct {
+	Amount   string `json:"amount" validate:"required,max=32"` // Decimal string in the budget currency (e.g., "1.500,00" or "1500.00")
+	Currency string `json:"currency" validate:"required,len=3"`
+}
+
+// BindVersion decodes the version 1 bodies, whose amount is in minor units of the budget currency
+func (r *SetBudgetRequest) BindVersion(_ int, decode func(any) error) error {
+	var v1 struct {
+		Amount   *int64 `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+	r.Currency = v1.Currency
+	if v1.Amount != nil {
+		r.Amount = money.New(*v1.Amount, v1.Currency).Decimal()
+	}
+	return nil
+}
+
+// UpdateAlertPreferencesRequest defines the expected JSON body for updating the budget alert thresholds
+type UpdateAlertPreferencesRequest struct {
+	Thresholds []int `json:"thresholds" validate:"max=5"` // Percentages of the budget (e.g., [80, 100]), an empty list disables the alerts
+}
+
+// BudgetResponse defines the structure of a budget returned by the API
+type BudgetResponse struct {
+	ID           uuid.UUID `json:"id"`
+	CategoryID   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name,omitempty"`
+	Currency     string    `json:"currency"`
+	Amount       int64     `json:"amount"`          // Amount in minor units of the currency
+	Spent        *int64    `json:"spent,omitempty"` // Spent in the current month, in minor units of the currency
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BudgetMonthResponse defines what was budgeted and spent in a financial month, in minor units of the budget currency
+type BudgetMonthResponse struct {
+	Month    string `json:"month"` // Month is the month the financial month starts in, formatted as YYYY-MM
+	From     string `json:"from"`  // From and To are formatted as YYYY-MM-DD, both included
+	To       string `json:"to"`
+	Budgeted int64  `json:"budgeted"`
+	Spent    int64  `json:"spent"`
+}
+
+// BudgetHistoryResponse defines the structure of the budget history of a category returned by the API
+// Budget amounts are not versioned, so every month is compared with the current amount
+type BudgetHistoryResponse struct {
+	BudgetID     uuid.UUID             `json:"budget_id"`
+	CategoryID   uuid.UUID             `json:"category_id"`
+	CategoryName string                `json:"category_name"`
+	Currency     string                `json:"currency"`
+	Months       []BudgetMonthResponse `json:"months"` // Months are ordered from the oldest, the current month last
+}
+
+// AlertPreferencesResponse defines the structure of the budget alert preferences returned by the API
+type AlertPreferencesResponse struct {
+	Thresholds []int      `json:"thresholds"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// listBudgetsHandler handles the HTTP request for listing the user's budgets with their monthly spending
+func (h *BudgetHandler) listBudgetsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := h.budgetService.ListBudgets(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]BudgetResponse, 0, len(statuses))
+	for _, st := range statuses {
+		br := toBudgetResponse(st.Budget)
+		br.CategoryName = st.CategoryName
+		br.Spent = &st.Spent.Amount
+		resp = append(resp, br)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// setBudgetHandler handles the HTTP request for creating or changing the monthly budget of a category
+func (h *BudgetHandler) setBudgetHandler(c echo.Context) error {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid category id format")
+	}
+
+	var req SetBudgetRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	budget, err := h.budgetService.SetBudget(c.Request().Context(), SetBudgetParams{
+		UserID:     userID,
+		CategoryID: categoryID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toBudgetResponse(budget))
+}
+
+// budgetHistoryHandler handles the HTTP request for the budgeted and spent amounts of a category
+// over the last financial months (e.g., ?months=12)
+func (h *BudgetHandler) budgetHistoryHandler(c echo.Context) error {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid category id format")
+	}
+
+	months := DefaultHistoryMonths
+	if raw := c.QueryParam("months"); raw != "" {
+		if months, err = strconv.Atoi(raw); err != nil {
+			return ErrInvalidHistoryMonths.With("months", raw)
+		}
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	history, err := h.budgetService.BudgetHistory(c.Request().Context(), userID, categoryID, months)
+	if err != nil {
+		return err
+	}
+
+	resp := BudgetHistoryResponse{
+		BudgetID:     history.Budget.ID,
+		CategoryID:   history.Budget.CategoryID,
+		CategoryName: history.CategoryName,
+		Currency:     history.Budget.Amount.Currency,
+		Months:       make([]BudgetMonthResponse, len(history.Months)),
+	}
+	for i, m := range history.Months {
+		resp.Months[i] = BudgetMonthResponse{
+			Month:    m.Start.Format("2006-01"),
+			From:     m.Start.Format(time.DateOnly),
+			To:       m.End.AddDate(0, 0, -1).Format(time.DateOnly),
+			Budgeted: m.Budgeted.Amount,
+			Spent:    m.Spent.Amount,
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// deleteBudgetHandler handles the HTTP request for deleting a budget
+func (h *BudgetHandler) deleteBudgetHandler(c echo.Context) error {
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid budget id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.budgetService.DeleteBudget(c.Request().Context(), userID, budgetID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getAlertPreferencesHandler handles the HTTP request for finding the user's budget alert thresholds
+func (h *BudgetHandler) getAlertPreferencesHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := h.budgetService.GetAlertPreferences(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toAlertPreferencesResponse(prefs))
+}
+
+// updateAlertPreferencesHandler handles the HTTP request for replacing the user's budget alert thresholds
+func (h *BudgetHandler) updateAlertPreferencesHandler(c echo.Context) error {
+	var req UpdateAlertPreferencesRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := h.budgetService.UpdateAlertPreferences(c.Request().Context(), userID, req.Thresholds)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toAlertPreferencesResponse(prefs))
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toBudgetResponse maps the internal Budget domain model to the public BudgetResponse DTO
+func toBudgetResponse(b *Budget) BudgetResponse {
+	return BudgetResponse{
+		ID:         b.ID,
+		CategoryID: b.CategoryID,
+		Currency:   b.Amount.Currency,
+		Amount:     b.Amount.Amount,
+		CreatedAt:  b.CreatedAt,
+		UpdatedAt:  b.UpdatedAt,
+	}
+}
+
+// toAlertPreferencesResponse maps the internal AlertPreferences domain model to the public AlertPreferencesResponse DTO
+func toAlertPreferencesResponse(prefs *AlertPreferences) AlertPreferencesResponse {
+	resp := AlertPreferencesResponse{Thresholds: prefs.Thresholds}
+	if resp.Thresholds == nil {
+		resp.Thresholds = []int{}
+	}
+	if !prefs.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &prefs.UpdatedAt
+	}
+	return resp
+}
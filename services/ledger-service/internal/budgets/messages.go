@@ -0,0 +1,19 @@
+package budgets
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// messages translates the budget alerts sent to the users
+var messages = i18n.Catalog{
+	i18n.English: {
+		"budget_alert_title_threshold": "%d%% of the %s budget used",
+		"budget_alert_title_reached":   "%s budget reached",
+		"budget_alert_title_exceeded":  "%s budget exceeded",
+		"budget_alert_body":            "You spent %s of the %s budgeted for %s this month",
+	},
+	i18n.Portuguese: {
+		"budget_alert_title_threshold": "%d%% do orçamento de %s utilizado",
+		"budget_alert_title_reached":   "Orçamento de %s atingido",
+		"budget_alert_title_exceeded":  "Orçamento de %s ultrapassado",
+		"budget_alert_body":            "Você gastou %s dos %s orçados para %s neste mês",
+	},
+}
@@ -0,0 +1,54 @@
+package budgets
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the budget repositories, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *BudgetHandler
+}
+
+// NewModule creates the budgets module, alerts are delivered through deps.Notifier
+func NewModule(deps module.Deps) *Module {
+	budgetSvc := NewService(
+		NewPostgresBudgetRepository(deps.Postgres.Pool),
+		NewPostgresAlertPreferencesRepository(deps.Postgres.Pool),
+		NewPostgresSpendingReader(deps.Postgres.Pool),
+		deps.Notifier,
+		deps.Periods,
+		deps.Display,
+		deps.IDs,
+		deps.Clock,
+	)
+
+	return &Module{
+		service: budgetSvc,
+		handler: NewBudgetHandler(budgetSvc),
+	}
+}
+
+// Service returns the budgets service, for modules reading the budgets (e.g., the weekly summary)
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// TransactionListener returns the listener the ledger notifies of saved transactions, to check the budgets
+func (m *Module) TransactionListener() ledger.TransactionListener {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "budgets"
+}
+
+// RegisterRoutes mounts the budgets routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
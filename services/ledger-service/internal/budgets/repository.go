@@ -0,0 +1,330 @@
+package budgets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ BudgetRepository           = (*PostgresBudgetRepository)(nil)
+	_ AlertPreferencesRepository = (*PostgresAlertPreferencesRepository)(nil)
+	_ SpendingReader             = (*PostgresSpendingReader)(nil)
+)
+
+// ----- Budgets ----- //
+
+// PostgresBudgetRepository is a PostgreSQL implementation of the BudgetRepository interface
+type PostgresBudgetRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresBudgetRepository creates a new PostgresBudgetRepository
+func NewPostgresBudgetRepository(pool *pgxpool.Pool) *PostgresBudgetRepository {
+	return &PostgresBudgetRepository{pool: pool}
+}
+
+// budgetModel represents the budget structure in the database
+type budgetModel struct {
+	ID         uuid.UUID `db:"id"`
+	UserID     uuid.UUID `db:"user_id"`
+	CategoryID uuid.UUID `db:"category_id"`
+	Amount     int64     `db:"amount_in_cents"`
+	Currency   string    `db:"currency"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+const budgetColumns = `id, user_id, category_id, amount_in_cents, currency, created_at, updated_at`
+
+// Save inserts a new budget or updates the amount of an existing one
+func (r *PostgresBudgetRepository) Save(ctx context.Context, budget *Budget) error {
+	m := toBudgetPersistence(budget)
+
+	query := `
+		INSERT INTO budgets (id, user_id, category_id, amount_in_cents, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			amount_in_cents = EXCLUDED.amount_in_cents,
+			currency = EXCLUDED.currency,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, m.ID, m.UserID, m.CategoryID, m.Amount, m.Currency, m.CreatedAt, m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert budget: %v", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a budget by its ID
+func (r *PostgresBudgetRepository) FindByID(ctx context.Context, budgetID uuid.UUID) (*Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE id = $1`
+
+	budget, err := r.findOne(ctx, query, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	if budget == nil {
+		return nil, ErrBudgetNotFound.With("budget_id", budgetID)
+	}
+	return budget, nil
+}
+
+// FindByCategory retrieves the budget of a user's category, returning nil when it has none
+func (r *PostgresBudgetRepository) FindByCategory(ctx context.Context, userID, categoryID uuid.UUID) (*Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 AND category_id = $2`
+	return r.findOne(ctx, query, userID, categoryID)
+}
+
+// FindByUserID retrieves every budget of a user
+func (r *PostgresBudgetRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 ORDER BY created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := make([]*Budget, 0)
+	for rows.Next() {
+		var m budgetModel
+		if err := rows.Scan(&m.ID, &m.UserID, &m.CategoryID, &m.Amount, &m.Currency, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan budget row: %w", err)
+		}
+		budgets = append(budgets, toBudgetDomain(&m))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating budget rows: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// Delete deletes a budget and the record of its sent alerts
+func (r *PostgresBudgetRepository) Delete(ctx context.Context, budgetID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM budgets WHERE id = $1`, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBudgetNotFound.With("budget_id", budgetID)
+	}
+	return nil
+}
+
+// MarkAlertSent records the alert of a threshold for a month, reporting false when it was already recorded
+func (r *PostgresBudgetRepository) MarkAlertSent(ctx context.Context, budgetID uuid.UUID, month time.Time, threshold int, sentAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO budget_alerts_sent (budget_id, month, threshold, sent_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (budget_id, month, threshold) DO NOTHING
+	`
+
+	tag, err := r.pool.Exec(ctx, query, budgetID, month, threshold, sentAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record budget alert: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// findOne runs a query selecting budgetColumns, returning nil when no budget matches
+func (r *PostgresBudgetRepository) findOne(ctx context.Context, query string, args ...any) (*Budget, error) {
+	var m budgetModel
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&m.ID, &m.UserID, &m.CategoryID, &m.Amount, &m.Currency, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch budget: %w", err)
+	}
+	return toBudgetDomain(&m), nil
+}
+
+// toBudgetDomain maps a persistence budgetModel to a domain Budget
+func toBudgetDomain(m *budgetModel) *Budget {
+	return &Budget{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		CategoryID: m.CategoryID,
+		Amount:     money.New(m.Amount, m.Currency),
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+// toBudgetPersistence maps a domain Budget to a persistence budgetModel
+func toBudgetPersistence(b *Budget) *budgetModel {
+	return &budgetModel{
+		ID:         b.ID,
+		UserID:     b.UserID,
+		CategoryID: b.CategoryID,
+		Amount:     b.Amount.Amount,
+		Currency:   b.Amount.Currency,
+		CreatedAt:  b.CreatedAt,
+		UpdatedAt:  b.UpdatedAt,
+	}
+}
+
+// ----- Alert preferences ----- //
+
+// PostgresAlertPreferencesRepository is a PostgreSQL implementation of the AlertPreferencesRepository interface
+type PostgresAlertPreferencesRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAlertPreferencesRepository creates a new PostgresAlertPreferencesRepository
+func NewPostgresAlertPreferencesRepository(pool *pgxpool.Pool) *PostgresAlertPreferencesRepository {
+	return &PostgresAlertPreferencesRepository{pool: pool}
+}
+
+// FindByUserID retrieves the alert preferences of a user, returning nil when they were never saved
+func (r *PostgresAlertPreferencesRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*AlertPreferences, error) {
+	query := `SELECT user_id, thresholds, updated_at FROM budget_alert_preferences WHERE user_id = $1`
+
+	var (
+		prefs      AlertPreferences
+		thresholds []int32
+	)
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&prefs.UserID, &thresholds, &prefs.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch budget alert preferences: %w", err)
+	}
+
+	prefs.Thresholds = make([]int, len(thresholds))
+	for i, t := range thresholds {
+		prefs.Thresholds[i] = int(t)
+	}
+	return &prefs, nil
+}
+
+// Save inserts or replaces the alert preferences of a user
+func (r *PostgresAlertPreferencesRepository) Save(ctx context.Context, prefs *AlertPreferences) error {
+	thresholds := make([]int32, len(prefs.Thresholds))
+	for i, t := range prefs.Thresholds {
+		thresholds[i] = int32(t)
+	}
+
+	query := `
+		INSERT INTO budget_alert_preferences (user_id, thresholds, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			thresholds = EXCLUDED.thresholds,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.pool.Exec(ctx, query, prefs.UserID, thresholds, prefs.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to upsert budget alert preferences: %v", err)
+	}
+	return nil
+}
+
+// ----- Spending ----- //
+
+// PostgresSpendingReader reads the categories and expenses from the ledger tables
+type PostgresSpendingReader struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSpendingReader creates a new PostgresSpendingReader
+func NewPostgresSpendingReader(pool *pgxpool.Pool) *PostgresSpendingReader {
+	return &PostgresSpendingReader{pool: pool}
+}
+
+// CategoryName returns the name of a category owned by the user or a default one
+func (r *PostgresSpendingReader) CategoryName(ctx context.Context, userID, categoryID uuid.UUID) (string, error) {
+	query := `SELECT name FROM categories WHERE id = $1 AND (user_id = $2 OR user_id IS NULL)`
+
+	var name string
+	if err := r.pool.QueryRow(ctx, query, categoryID, userID).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCategoryNotFound.With("category_id", categoryID)
+		}
+		return "", fmt.Errorf("failed to fetch category: %w", err)
+	}
+	return name, nil
+}
+
+// MonthlyCategorySpending sums the expenses of a category due within each period in a single grouped query, the
+// archived ones included
+func (r *PostgresSpendingReader) MonthlyCategorySpending(ctx context.Context, userID, categoryID uuid.UUID, currency string, bounds []time.Time) ([]int64, error) {
+	if len(bounds) < 2 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT p.start_at, COALESCE(SUM(-t.amount_in_cents), 0)
+		FROM unnest($4::timestamptz[], $5::timestamptz[]) AS p(start_at, end_at)
+		LEFT JOIN (
+			(
+				SELECT account_id, user_id, category_id, type, amount_in_cents, due_date FROM transactions
+				UNION ALL
+				SELECT account_id, user_id, category_id, type, amount_in_cents, due_date FROM archived_transactions
+			) t JOIN accounts a ON a.id = t.account_id AND a.currency = $3
+		) ON t.user_id = $1
+			AND t.category_id = $2
+			AND t.type = 'EXPENSE'
+			AND t.due_date >= p.start_at AND t.due_date < p.end_at
+		GROUP BY p.start_at
+		ORDER BY p.start_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, categoryID, currency, bounds[:len(bounds)-1], bounds[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monthly category spending: %w", err)
+	}
+	defer rows.Close()
+
+	spending := make([]int64, 0, len(bounds)-1)
+	for rows.Next() {
+		var (
+			start time.Time
+			spent int64
+		)
+		if err := rows.Scan(&start, &spent); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly category spending row: %w", err)
+		}
+		spending = append(spending, spent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating monthly category spending rows: %w", err)
+	}
+
+	return spending, nil
+}
+
+// CategorySpending sums the expenses (stored as negative amounts) of a category due within [from, to), archived ones included
+func (r *PostgresSpendingReader) CategorySpending(ctx context.Context, userID, categoryID uuid.UUID, currency string, from, to time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(-t.amount_in_cents), 0)
+		FROM (
+			SELECT account_id, user_id, category_id, type, amount_in_cents, due_date FROM transactions
+			UNION ALL
+			SELECT account_id, user_id, category_id, type, amount_in_cents, due_date FROM archived_transactions
+		) t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.user_id = $1
+			AND t.category_id = $2
+			AND t.type = 'EXPENSE'
+			AND a.currency = $3
+			AND t.due_date >= $4 AND t.due_date < $5
+	`
+
+	var spent int64
+	if err := r.pool.QueryRow(ctx, query, userID, categoryID, currency, from, to).Scan(&spent); err != nil {
+		return 0, fmt.Errorf("failed to sum category spending: %w", err)
+	}
+	return spent, nil
+}
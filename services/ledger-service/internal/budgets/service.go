@@ -0,0 +1,338 @@
+package budgets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+var _ ledger.TransactionListener = (*Service)(nil)
+
+// SetBudgetParams holds all the required data for the SetBudget use case
+type SetBudgetParams struct {
+	UserID     uuid.UUID
+	CategoryID uuid.UUID
+	Amount     string // Amount as a decimal string, parsed using the currency (e.g., "1.500,00")
+	Currency   string
+}
+
+// BudgetStatus is a budget with what was spent on it in the current financial month
+type BudgetStatus struct {
+	Budget       *Budget
+	CategoryName string
+	Spent        money.Money
+}
+
+// Service manages the monthly budgets and alerts users when their spending crosses the alert thresholds
+type Service struct {
+	budgetRepo BudgetRepository
+	prefsRepo  AlertPreferencesRepository
+	spending   SpendingReader
+	notifier   notify.Notifier
+	periods    module.AccountingPeriods
+	display    module.UserPreferences
+	ids        idgen.Generator
+	clock      clock.Clock
+}
+
+// NewService creates a new instance of the budgets Service, budgets apply to the financial month of each user
+func NewService(
+	budgetRepo BudgetRepository,
+	prefsRepo AlertPreferencesRepository,
+	spending SpendingReader,
+	notifier notify.Notifier,
+	periods module.AccountingPeriods,
+	display module.UserPreferences,
+	ids idgen.Generator,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		budgetRepo: budgetRepo,
+		prefsRepo:  prefsRepo,
+		spending:   spending,
+		notifier:   notifier,
+		periods:    periods,
+		display:    display,
+		ids:        ids,
+		clock:      clock,
+	}
+}
+
+// SetBudget is the use case for creating or changing the monthly budget of a category
+func (s *Service) SetBudget(ctx context.Context, params SetBudgetParams) (*Budget, error) {
+	if _, err := s.spending.CategoryName(ctx, params.UserID, params.CategoryID); err != nil {
+		return nil, fmt.Errorf("failed to find budget category: %w", err)
+	}
+
+	amount, err := money.Parse(params.Amount, params.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse budget amount: %w", err)
+	}
+
+	budget, err := s.budgetRepo.FindByCategory(ctx, params.UserID, params.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find category budget: %w", err)
+	}
+
+	if budget == nil {
+		budget, err = NewBudget(params.UserID, params.CategoryID, amount, s.ids, s.clock)
+	} else {
+		err = budget.ChangeAmount(amount, s.clock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set budget: %w", err)
+	}
+
+	if err := s.budgetRepo.Save(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to save budget: %w", err)
+	}
+	return budget, nil
+}
+
+// ListBudgets is the use case for listing the user's budgets with their spending in the current financial month
+func (s *Service) ListBudgets(ctx context.Context, userID uuid.UUID) ([]BudgetStatus, error) {
+	budgets, err := s.budgetRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budgets: %w", err)
+	}
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	from, to := period.RangeIn(s.clock.Now(), time.UTC)
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for _, b := range budgets {
+		name, err := s.spending.CategoryName(ctx, userID, b.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find budget category: %w", err)
+		}
+		spent, err := s.spending.CategorySpending(ctx, userID, b.CategoryID, b.Amount.Currency, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute budget spending: %w", err)
+		}
+		statuses = append(statuses, BudgetStatus{
+			Budget:       b,
+			CategoryName: name,
+			Spent:        money.New(spent, b.Amount.Currency),
+		})
+	}
+	return statuses, nil
+}
+
+// BudgetHistory is the use case for comparing the budget of a category with what was spent on it
+// in each of the last financial months of the user, the current one included
+func (s *Service) BudgetHistory(ctx context.Context, userID, categoryID uuid.UUID, months int) (*BudgetHistory, error) {
+	if months < 1 || months > maxHistoryMonths {
+		return nil, ErrInvalidHistoryMonths.With("months", months).With("max_months", maxHistoryMonths)
+	}
+
+	budget, err := s.budgetRepo.FindByCategory(ctx, userID, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find category budget: %w", err)
+	}
+	if budget == nil {
+		return nil, ErrBudgetNotFound.With("category_id", categoryID)
+	}
+	name, err := s.spending.CategoryName(ctx, userID, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budget category: %w", err)
+	}
+
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	// The bounds go back from the end of the current month, each month starting where the previous one ends
+	start, end := period.RangeIn(s.clock.Now(), time.UTC)
+	bounds := make([]time.Time, months+1)
+	bounds[months] = end
+	for i := months - 1; i >= 0; i-- {
+		bounds[i] = start
+		previous := start.AddDate(0, 0, -1)
+		start, _ = period.RangeIn(previous, time.UTC)
+	}
+
+	spent, err := s.spending.MonthlyCategorySpending(ctx, userID, categoryID, budget.Amount.Currency, bounds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute budget history: %w", err)
+	}
+	if len(spent) != months {
+		return nil, fmt.Errorf("expected the spending of %d months, got %d", months, len(spent))
+	}
+
+	history := &BudgetHistory{Budget: budget, CategoryName: name, Months: make([]BudgetMonth, months)}
+	for i := range history.Months {
+		history.Months[i] = BudgetMonth{
+			Start:    bounds[i],
+			End:      bounds[i+1],
+			Budgeted: budget.Amount,
+			Spent:    money.New(spent[i], budget.Amount.Currency),
+		}
+	}
+	return history, nil
+}
+
+// DeleteBudget is the use case for deleting one of the user's budgets
+func (s *Service) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error {
+	budget, err := s.budgetRepo.FindByID(ctx, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to find budget to delete: %w", err)
+	}
+	if budget.UserID != userID {
+		return ErrBudgetNotFound.With("budget_id", budgetID)
+	}
+
+	if err := s.budgetRepo.Delete(ctx, budgetID); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	return nil
+}
+
+// GetAlertPreferences is the use case for finding the user's alert thresholds, falling back to the defaults
+func (s *Service) GetAlertPreferences(ctx context.Context, userID uuid.UUID) (*AlertPreferences, error) {
+	prefs, err := s.prefsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budget alert preferences: %w", err)
+	}
+	if prefs == nil {
+		return DefaultAlertPreferences(userID), nil
+	}
+	return prefs, nil
+}
+
+// UpdateAlertPreferences is the use case for replacing the user's alert thresholds
+func (s *Service) UpdateAlertPreferences(ctx context.Context, userID uuid.UUID, thresholds []int) (*AlertPreferences, error) {
+	prefs, err := s.GetAlertPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := prefs.SetThresholds(thresholds, s.clock); err != nil {
+		return nil, fmt.Errorf("failed to update budget alert thresholds: %w", err)
+	}
+
+	if err := s.prefsRepo.Save(ctx, prefs); err != nil {
+		return nil, fmt.Errorf("failed to save budget alert preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// TransactionAdded checks the budget of the transaction category, alerting the user once per financial month
+// for each threshold the new expense pushes the spending past
+func (s *Service) TransactionAdded(ctx context.Context, account *ledger.Account, tx ledger.Transaction) {
+	if tx.Type != ledger.Expense || tx.CategoryID == nil {
+		return
+	}
+
+	if err := s.checkBudget(ctx, account.UserID, *tx.CategoryID, tx); err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to check budget alerts",
+			slog.String("category_id", tx.CategoryID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *Service) checkBudget(ctx context.Context, userID, categoryID uuid.UUID, tx ledger.Transaction) error {
+	budget, err := s.budgetRepo.FindByCategory(ctx, userID, categoryID)
+	if err != nil {
+		return err
+	}
+	if budget == nil || !budget.Amount.SameCurrency(tx.Amount) {
+		return nil
+	}
+
+	prefs, err := s.GetAlertPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return err
+	}
+	month, nextMonth := period.RangeIn(tx.DueDate, time.UTC)
+	spent, err := s.spending.CategorySpending(ctx, userID, categoryID, budget.Amount.Currency, month, nextMonth)
+	if err != nil {
+		return err
+	}
+	// Expenses are negative, so the spending before the transaction is the total minus its absolute amount
+	spentBefore := spent + tx.Amount.Amount
+
+	crossed := budget.CrossedThresholds(prefs.Thresholds, spentBefore, spent)
+	if len(crossed) == 0 {
+		return nil
+	}
+
+	// A single expense may cross several thresholds, every one is recorded but only the highest is notified
+	notifyThreshold := 0
+	for _, threshold := range crossed {
+		firstTime, err := s.budgetRepo.MarkAlertSent(ctx, budget.ID, month, threshold, s.clock.Now())
+		if err != nil {
+			return err
+		}
+		if firstTime {
+			notifyThreshold = max(notifyThreshold, threshold)
+		}
+	}
+	if notifyThreshold == 0 {
+		return nil
+	}
+
+	categoryName, err := s.spending.CategoryName(ctx, userID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	display, err := s.display.DisplayPreferences(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find display preferences: %w", err)
+	}
+
+	return s.notifier.Notify(ctx, budgetAlertMessage(budget, categoryName, money.New(spent, budget.Amount.Currency), notifyThreshold, month, display.Locale))
+}
+
+// budgetAlertMessage builds the notification of a crossed threshold, written in the language of the locale and amounts formatted in it
+func budgetAlertMessage(budget *Budget, categoryName string, spent money.Money, threshold int, month time.Time, locale string) notify.Message {
+	lang, _ := i18n.Resolve(locale)
+
+	var title string
+	switch {
+	case threshold < 100:
+		title = messages.Format(lang, "budget_alert_title_threshold", threshold, categoryName)
+	case threshold == 100:
+		title = messages.Format(lang, "budget_alert_title_reached", categoryName)
+	default:
+		title = messages.Format(lang, "budget_alert_title_exceeded", categoryName)
+	}
+
+	return notify.Message{
+		UserID:   budget.UserID,
+		Category: notify.CategoryBudgetAlert,
+		Title:    title,
+		Body:     messages.Format(lang, "budget_alert_body", spent.Format(locale), budget.Amount.Format(locale), categoryName),
+		Data: map[string]string{
+			"budget_id":     budget.ID.String(),
+			"category_id":   budget.CategoryID.String(),
+			"category_name": categoryName,
+			"currency":      budget.Amount.Currency,
+			"budgeted":      strconv.FormatInt(budget.Amount.Amount, 10),
+			"spent":         strconv.FormatInt(spent.Amount, 10),
+			"threshold":     strconv.Itoa(threshold),
+			"month":         month.Format("2006-01"),
+		},
+	}
+}
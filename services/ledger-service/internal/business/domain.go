@@ -0,0 +1,192 @@
+package business
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/taxid"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProfileNotFound         = errx.New(errx.CategoryNotFound, "BUSINESS_PROFILE_NOT_FOUND", "the business profile is not enabled")
+	ErrInvalidLegalName        = errx.New(errx.CategoryValidation, "INVALID_LEGAL_NAME", "the legal name must have between 2 and 120 characters")
+	ErrCostCenterNotFound      = errx.New(errx.CategoryNotFound, "COST_CENTER_NOT_FOUND", "cost center not found")
+	ErrCostCenterAlreadyExists = errx.New(errx.CategoryConflict, "COST_CENTER_ALREADY_EXISTS", "a cost center with the same name already exists")
+	ErrTooManyCostCenters      = errx.New(errx.CategoryConflict, "TOO_MANY_COST_CENTERS", "the maximum number of cost centers was reached")
+	ErrInvalidCostCenterName   = errx.New(errx.CategoryValidation, "INVALID_COST_CENTER_NAME", "the cost center name must have between 1 and 60 characters")
+	ErrTransactionNotFound     = errx.New(errx.CategoryNotFound, "TRANSACTION_NOT_FOUND", "transaction not found")
+	ErrBusinessDetailsEmpty    = errx.New(errx.CategoryValidation, "BUSINESS_DETAILS_EMPTY", "a cost center or a supplier CNPJ is required")
+	ErrBusinessDetailsNotFound = errx.New(errx.CategoryNotFound, "BUSINESS_DETAILS_NOT_FOUND", "the transaction has no business details")
+	ErrInvalidReportRange      = errx.New(errx.CategoryValidation, "INVALID_REPORT_RANGE", "the report range must start before it ends and span at most the allowed period")
+)
+
+const (
+	// MaxCostCentersPerUser bounds the cost centers, which are listed whole
+	MaxCostCentersPerUser = 100
+
+	minLegalNameLength  = 2
+	maxLegalNameLength  = 120
+	maxCostCenterLength = 60
+	// maxReportRange caps the period a single report can cover
+	maxReportRange = 5 * 366 * 24 * time.Hour
+)
+
+// ProfileRepository persists the business profiles
+type ProfileRepository interface {
+	// FindByUserID returns the business profile of the user, nil when they never enabled it
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*Profile, error)
+	Save(ctx context.Context, profile *Profile) error
+	// Delete removes the profile, the cost centers and the details of the transactions being kept for when it is enabled again
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+// CostCenterRepository persists the cost centers of the users
+type CostCenterRepository interface {
+	Save(ctx context.Context, center *CostCenter) error
+	// FindByID retrieves a cost center of the user, ErrCostCenterNotFound when it belongs to someone else
+	FindByID(ctx context.Context, userID, centerID uuid.UUID) (*CostCenter, error)
+	// FindByUserID retrieves the cost centers of the user ordered by name
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*CostCenter, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	// Delete removes a cost center, the transactions it was set on keeping their supplier
+	Delete(ctx context.Context, userID, centerID uuid.UUID) error
+}
+
+// DetailsRepository persists the business details of the transactions and reports on them
+type DetailsRepository interface {
+	// TransactionOwned tells whether the transaction belongs to the account and the account to the user
+	TransactionOwned(ctx context.Context, userID, accountID, transactionID uuid.UUID) (bool, error)
+	// FindByTransactionID returns the details of the transaction, ErrBusinessDetailsNotFound when it has none
+	FindByTransactionID(ctx context.Context, transactionID uuid.UUID) (*TransactionDetails, error)
+	Save(ctx context.Context, details *TransactionDetails) error
+	Delete(ctx context.Context, transactionID uuid.UUID) error
+	// CostCenterTotals sums the transactions of the user with business details due within [from, to)
+	// by cost center and currency, ordered by currency, cost center name and with the transactions without cost center last
+	CostCenterTotals(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]CostCenterTotals, error)
+}
+
+// Profile is the business a user runs, enabling them to set cost centers and suppliers on their transactions
+type Profile struct {
+	UserID    uuid.UUID
+	LegalName string
+	CNPJ      string // CNPJ holds the 14 digits of the company document, empty for the businesses without one
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewProfile creates the business profile of a user
+func NewProfile(userID uuid.UUID, legalName, cnpj string, clock clock.Clock) (*Profile, error) {
+	now := clock.Now()
+	profile := &Profile{UserID: userID, CreatedAt: now}
+	if err := profile.Update(legalName, cnpj, clock); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Update replaces the legal name and the CNPJ of the business, an empty CNPJ removing it
+func (p *Profile) Update(legalName, cnpj string, clock clock.Clock) error {
+	legalName = strings.TrimSpace(legalName)
+	if length := utf8.RuneCountInString(legalName); length < minLegalNameLength || length > maxLegalNameLength {
+		return ErrInvalidLegalName.With("min_length", minLegalNameLength).With("max_length", maxLegalNameLength)
+	}
+
+	var err error
+	if cnpj != "" {
+		if cnpj, err = taxid.NormalizeCNPJ(cnpj); err != nil {
+			return err
+		}
+	}
+
+	p.LegalName = legalName
+	p.CNPJ = cnpj
+	p.UpdatedAt = clock.Now()
+	return nil
+}
+
+// CostCenter is a part of the business its expenses and income are tracked by (e.g., a store, a project or a department)
+type CostCenter struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+// NewCostCenter creates a new CostCenter of the user
+func NewCostCenter(userID uuid.UUID, name string, clock clock.Clock) (*CostCenter, error) {
+	center := &CostCenter{ID: uuid.New(), UserID: userID, CreatedAt: clock.Now()}
+	if err := center.Rename(name); err != nil {
+		return nil, err
+	}
+	return center, nil
+}
+
+// Rename changes the name of the cost center
+func (c *CostCenter) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if length := utf8.RuneCountInString(name); length == 0 || length > maxCostCenterLength {
+		return ErrInvalidCostCenterName.With("max_length", maxCostCenterLength)
+	}
+	c.Name = name
+	return nil
+}
+
+// TransactionDetails are the cost center and the supplier of a business transaction, at least one of them being set
+type TransactionDetails struct {
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	CostCenterID  *uuid.UUID
+	SupplierCNPJ  string // SupplierCNPJ holds the 14 digits of the CNPJ of the supplier paid or the customer paying
+	UpdatedAt     time.Time
+}
+
+// NewTransactionDetails creates the business details of a transaction, validating the CNPJ of the supplier
+func NewTransactionDetails(accountID, transactionID uuid.UUID, costCenterID *uuid.UUID, supplierCNPJ string, clock clock.Clock) (*TransactionDetails, error) {
+	if costCenterID == nil && supplierCNPJ == "" {
+		return nil, ErrBusinessDetailsEmpty
+	}
+
+	var err error
+	if supplierCNPJ != "" {
+		if supplierCNPJ, err = taxid.NormalizeCNPJ(supplierCNPJ); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TransactionDetails{
+		TransactionID: transactionID,
+		AccountID:     accountID,
+		CostCenterID:  costCenterID,
+		SupplierCNPJ:  supplierCNPJ,
+		UpdatedAt:     clock.Now(),
+	}, nil
+}
+
+// CostCenterTotals is what a cost center spent and received in a currency
+// The transactions with a supplier but no cost center are summed together, with neither CostCenterID nor CostCenter
+type CostCenterTotals struct {
+	CostCenterID *uuid.UUID
+	CostCenter   string
+	Income       money.Money // Income is positive, the sum of the income transactions
+	Expenses     money.Money // Expenses is positive, the sum of the expense transactions
+	Transactions int
+}
+
+// Net returns the income minus the expenses of the cost center
+func (t CostCenterTotals) Net() (money.Money, error) {
+	return t.Income.Sub(t.Expenses)
+}
+
+// CostCenterReport is the income and expenses by cost center over a period
+type CostCenterReport struct {
+	From        time.Time
+	To          time.Time // To is the last day of the period, included
+	CostCenters []CostCenterTotals
+	Locale      string // Locale formats the amounts, the pt-BR conventions when empty
+}
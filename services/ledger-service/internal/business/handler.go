@@ -0,0 +1,403 @@
+package business
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/taxid"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BusinessHandler holds dependencies for the business profile HTTP handlers
+type BusinessHandler struct {
+	businessService *Service
+}
+
+// NewBusinessHandler creates a new instance of BusinessHandler
+func NewBusinessHandler(businessService *Service) *BusinessHandler {
+	return &BusinessHandler{businessService: businessService}
+}
+
+// RegisterRoutes sets up the API routes for the business module
+func (h *BusinessHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	businessGroup := apiRouteGroup.Group("/business")
+
+	businessGroup.GET("/profile", h.getProfileHandler)
+	businessGroup.PUT("/profile", h.saveProfileHandler)
+	businessGroup.DELETE("/profile", h.disableProfileHandler)
+	businessGroup.GET("/cost-centers", h.listCostCentersHandler)
+	businessGroup.POST("/cost-centers", h.createCostCenterHandler)
+	businessGroup.DELETE("/cost-centers/:id", h.deleteCostCenterHandler)
+	businessGroup.GET("/reports/cost-centers", h.costCenterReportHandler)
+
+	detailsGroup := apiRouteGroup.Group("/accounts/:id/transactions/:transactionId/business")
+	detailsGroup.GET("", h.getDetailsHandler)
+	detailsGroup.PUT("", h.setDetailsHandler)
+	detailsGroup.DELETE("", h.clearDetailsHandler)
+}
+
+// SaveProfileRequest defines the expected JSON body for enabling the business profile or changing it
+type SaveProfileRequest struct {
+	LegalName string `json:"legal_name" validate:"required,max=120"`
+	CNPJ      string `json:"cnpj" validate:"omitempty,max=18"` // CNPJ may be formatted (e.g., "12.345.678/0001-95"), omitted for businesses without one
+}
+
+// CreateCostCenterRequest defines the expected JSON body for creating a cost center
+type CreateCostCenterRequest struct {
+	Name string `json:"name" validate:"required,max=60"`
+}
+
+// SetDetailsRequest defines the expected JSON body for setting the business details of a transaction, at least one field being required
+type SetDetailsRequest struct {
+	CostCenterID *uuid.UUID `json:"cost_center_id"`
+	SupplierCNPJ string     `json:"supplier_cnpj" validate:"omitempty,max=18"`
+}
+
+// ProfileResponse defines the structure of the business profile returned by the API
+type ProfileResponse struct {
+	LegalName string    `json:"legal_name"`
+	CNPJ      string    `json:"cnpj,omitempty"` // CNPJ is formatted as written on documents (e.g., "12.345.678/0001-95")
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CostCenterResponse defines the structure of a cost center returned by the API
+type CostCenterResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DetailsResponse defines the structure of the business details of a transaction returned by the API
+type DetailsResponse struct {
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	CostCenterID  *uuid.UUID `json:"cost_center_id"`
+	SupplierCNPJ  string     `json:"supplier_cnpj,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// CostCenterTotalsResponse defines the income and expenses of a cost center in a currency returned by the API
+// Amounts are expressed in minor units of the currency, the formatted ones in the locale of the user
+// The transactions without cost center are returned together with neither cost_center_id nor cost_center
+type CostCenterTotalsResponse struct {
+	CostCenterID      *uuid.UUID `json:"cost_center_id"`
+	CostCenter        string     `json:"cost_center"`
+	Currency          string     `json:"currency"`
+	Income            int64      `json:"income"`
+	Expenses          int64      `json:"expenses"`
+	Net               int64      `json:"net"`
+	IncomeFormatted   string     `json:"income_formatted"`
+	ExpensesFormatted string     `json:"expenses_formatted"`
+	NetFormatted      string     `json:"net_formatted"`
+	Transactions      int        `json:"transactions"`
+}
+
+// CostCenterReportResponse defines the structure of the cost center report returned by the API
+type CostCenterReportResponse struct {
+	From        string                     `json:"from"` // From and To are formatted as YYYY-MM-DD, both included
+	To          string                     `json:"to"`
+	CostCenters []CostCenterTotalsResponse `json:"cost_centers"`
+}
+
+// getProfileHandler handles the HTTP request for finding the user's business profile
+func (h *BusinessHandler) getProfileHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	profile, err := h.businessService.GetProfile(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toProfileResponse(profile))
+}
+
+// saveProfileHandler handles the HTTP request for enabling the user's business profile or changing it
+func (h *BusinessHandler) saveProfileHandler(c echo.Context) error {
+	var req SaveProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	profile, err := h.businessService.SaveProfile(c.Request().Context(), userID, req.LegalName, req.CNPJ)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toProfileResponse(profile))
+}
+
+// disableProfileHandler handles the HTTP request for leaving the business profile
+func (h *BusinessHandler) disableProfileHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.businessService.DisableProfile(c.Request().Context(), userID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listCostCentersHandler handles the HTTP request for listing the user's cost centers
+func (h *BusinessHandler) listCostCentersHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	centers, err := h.businessService.ListCostCenters(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]CostCenterResponse, 0, len(centers))
+	for _, center := range centers {
+		resp = append(resp, toCostCenterResponse(center))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// createCostCenterHandler handles the HTTP request for creating a cost center
+func (h *BusinessHandler) createCostCenterHandler(c echo.Context) error {
+	var req CreateCostCenterRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	center, err := h.businessService.CreateCostCenter(c.Request().Context(), userID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toCostCenterResponse(center))
+}
+
+// deleteCostCenterHandler handles the HTTP request for deleting a cost center
+func (h *BusinessHandler) deleteCostCenterHandler(c echo.Context) error {
+	centerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cost center id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.businessService.DeleteCostCenter(c.Request().Context(), userID, centerID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getDetailsHandler handles the HTTP request for finding the business details of a transaction
+func (h *BusinessHandler) getDetailsHandler(c echo.Context) error {
+	accountID, transactionID, err := transactionParams(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	details, err := h.businessService.GetTransactionDetails(c.Request().Context(), userID, accountID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toDetailsResponse(details))
+}
+
+// setDetailsHandler handles the HTTP request for setting the cost center and the supplier of a transaction
+func (h *BusinessHandler) setDetailsHandler(c echo.Context) error {
+	accountID, transactionID, err := transactionParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req SetDetailsRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	details, err := h.businessService.SetTransactionDetails(c.Request().Context(), SetDetailsParams{
+		UserID:        userID,
+		AccountID:     accountID,
+		TransactionID: transactionID,
+		CostCenterID:  req.CostCenterID,
+		SupplierCNPJ:  req.SupplierCNPJ,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toDetailsResponse(details))
+}
+
+// clearDetailsHandler handles the HTTP request for removing the business details of a transaction
+func (h *BusinessHandler) clearDetailsHandler(c echo.Context) error {
+	accountID, transactionID, err := transactionParams(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.businessService.ClearTransactionDetails(c.Request().Context(), userID, accountID, transactionID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// costCenterReportHandler handles the HTTP request for the income and expenses by cost center of the user
+// (e.g., ?from=2025-01-01&to=2025-01-31)
+func (h *BusinessHandler) costCenterReportHandler(c echo.Context) error {
+	from, err := dateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := dateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.businessService.CostCenterReport(c.Request().Context(), userID, from, to)
+	if err != nil {
+		return err
+	}
+
+	resp := CostCenterReportResponse{
+		From:        report.From.Format(time.DateOnly),
+		To:          report.To.Format(time.DateOnly),
+		CostCenters: make([]CostCenterTotalsResponse, len(report.CostCenters)),
+	}
+	for i, totals := range report.CostCenters {
+		net, err := totals.Net()
+		if err != nil {
+			return err
+		}
+		resp.CostCenters[i] = CostCenterTotalsResponse{
+			CostCenterID:      totals.CostCenterID,
+			CostCenter:        totals.CostCenter,
+			Currency:          totals.Income.Currency,
+			Income:            totals.Income.Amount,
+			Expenses:          totals.Expenses.Amount,
+			Net:               net.Amount,
+			IncomeFormatted:   totals.Income.Format(report.Locale),
+			ExpensesFormatted: totals.Expenses.Format(report.Locale),
+			NetFormatted:      net.Format(report.Locale),
+			Transactions:      totals.Transactions,
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// transactionParams parses the account and transaction ids of the route
+func transactionParams(c echo.Context) (accountID, transactionID uuid.UUID, err error) {
+	accountID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err = uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
+	return accountID, transactionID, nil
+}
+
+// dateQueryParam parses an optional YYYY-MM-DD query parameter, returning the zero time when it is absent
+func dateQueryParam(c echo.Context, name string) (time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+" date format, expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toProfileResponse maps the internal Profile domain model to the public ProfileResponse DTO
+func toProfileResponse(profile *Profile) ProfileResponse {
+	return ProfileResponse{
+		LegalName: profile.LegalName,
+		CNPJ:      taxid.FormatCNPJ(profile.CNPJ),
+		CreatedAt: profile.CreatedAt,
+		UpdatedAt: profile.UpdatedAt,
+	}
+}
+
+// toCostCenterResponse maps the internal CostCenter domain model to the public CostCenterResponse DTO
+func toCostCenterResponse(center *CostCenter) CostCenterResponse {
+	return CostCenterResponse{
+		ID:        center.ID,
+		Name:      center.Name,
+		CreatedAt: center.CreatedAt,
+	}
+}
+
+// toDetailsResponse maps the internal TransactionDetails domain model to the public DetailsResponse DTO
+func toDetailsResponse(details *TransactionDetails) DetailsResponse {
+	return DetailsResponse{
+		TransactionID: details.TransactionID,
+		CostCenterID:  details.CostCenterID,
+		SupplierCNPJ:  taxid.FormatCNPJ(details.SupplierCNPJ),
+		UpdatedAt:     details.UpdatedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package business
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the business repositories, service and handler from the shared dependencies
+type Module struct {
+	handler *BusinessHandler
+}
+
+// NewModule creates the business module, whose profiles, cost centers and transaction details are stored in Postgres
+func NewModule(deps module.Deps) *Module {
+	businessSvc := NewService(
+		NewPostgresProfileRepository(deps.Postgres.Pool),
+		NewPostgresCostCenterRepository(deps.Postgres.Pool),
+		NewPostgresDetailsRepository(deps.Postgres.Pool),
+		deps.Periods,
+		deps.Display,
+		deps.Clock,
+	)
+
+	return &Module{handler: NewBusinessHandler(businessSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "business"
+}
+
+// RegisterRoutes mounts the business routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,315 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ ProfileRepository    = (*PostgresProfileRepository)(nil)
+	_ CostCenterRepository = (*PostgresCostCenterRepository)(nil)
+	_ DetailsRepository    = (*PostgresDetailsRepository)(nil)
+)
+
+// costCenterUniqueIndex keeps a single cost center per name, case-insensitively, for each user
+const costCenterUniqueIndex = "uq_cost_centers_user_id_name"
+
+// ----- Profiles ----- //
+
+// PostgresProfileRepository is a PostgreSQL implementation of the ProfileRepository interface
+type PostgresProfileRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresProfileRepository creates a new PostgresProfileRepository
+func NewPostgresProfileRepository(pool *pgxpool.Pool) *PostgresProfileRepository {
+	return &PostgresProfileRepository{pool: pool}
+}
+
+// FindByUserID retrieves the business profile of the user, nil when there is none
+func (r *PostgresProfileRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	query := `
+		SELECT user_id, legal_name, COALESCE(cnpj, ''), created_at, updated_at
+		FROM business_profiles
+		WHERE user_id = $1
+	`
+
+	var p Profile
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&p.UserID, &p.LegalName, &p.CNPJ, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch business profile: %w", err)
+	}
+	return &p, nil
+}
+
+// Save inserts or replaces the business profile of the user
+func (r *PostgresProfileRepository) Save(ctx context.Context, profile *Profile) error {
+	query := `
+		INSERT INTO business_profiles (user_id, legal_name, cnpj, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			legal_name = EXCLUDED.legal_name,
+			cnpj = EXCLUDED.cnpj,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.pool.Exec(ctx, query, profile.UserID, profile.LegalName, profile.CNPJ, profile.CreatedAt, profile.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert business profile: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the business profile of the user
+func (r *PostgresProfileRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM business_profiles WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete business profile: %w", err)
+	}
+	return nil
+}
+
+// ----- Cost centers ----- //
+
+// PostgresCostCenterRepository is a PostgreSQL implementation of the CostCenterRepository interface
+type PostgresCostCenterRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresCostCenterRepository creates a new PostgresCostCenterRepository
+func NewPostgresCostCenterRepository(pool *pgxpool.Pool) *PostgresCostCenterRepository {
+	return &PostgresCostCenterRepository{pool: pool}
+}
+
+// Save inserts a new cost center or renames an existing one
+func (r *PostgresCostCenterRepository) Save(ctx context.Context, center *CostCenter) error {
+	query := `
+		INSERT INTO cost_centers (id, user_id, name, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id)
+		DO UPDATE SET name = EXCLUDED.name
+	`
+	_, err := r.pool.Exec(ctx, query, center.ID, center.UserID, center.Name, center.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == costCenterUniqueIndex {
+			return ErrCostCenterAlreadyExists.With("name", center.Name)
+		}
+		return fmt.Errorf("failed to upsert cost center: %w", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a cost center of the user
+func (r *PostgresCostCenterRepository) FindByID(ctx context.Context, userID, centerID uuid.UUID) (*CostCenter, error) {
+	query := `SELECT id, user_id, name, created_at FROM cost_centers WHERE id = $1 AND user_id = $2`
+
+	var c CostCenter
+	if err := r.pool.QueryRow(ctx, query, centerID, userID).Scan(&c.ID, &c.UserID, &c.Name, &c.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCostCenterNotFound.With("cost_center_id", centerID)
+		}
+		return nil, fmt.Errorf("failed to fetch cost center: %w", err)
+	}
+	return &c, nil
+}
+
+// FindByUserID retrieves the cost centers of the user ordered by name
+func (r *PostgresCostCenterRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*CostCenter, error) {
+	query := `
+		SELECT id, user_id, name, created_at
+		FROM cost_centers
+		WHERE user_id = $1
+		ORDER BY LOWER(name), id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost centers: %w", err)
+	}
+	defer rows.Close()
+
+	centers := make([]*CostCenter, 0)
+	for rows.Next() {
+		var c CostCenter
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cost center row: %w", err)
+		}
+		centers = append(centers, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost center rows: %w", err)
+	}
+
+	return centers, nil
+}
+
+// CountByUserID counts the cost centers of the user
+func (r *PostgresCostCenterRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM cost_centers WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cost centers: %w", err)
+	}
+	return count, nil
+}
+
+// Delete removes a cost center of the user, the foreign key clearing it from the transactions it was set on
+func (r *PostgresCostCenterRepository) Delete(ctx context.Context, userID, centerID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM cost_centers WHERE id = $1 AND user_id = $2`, centerID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cost center: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCostCenterNotFound.With("cost_center_id", centerID)
+	}
+	return nil
+}
+
+// ----- Transaction details ----- //
+
+// PostgresDetailsRepository is a PostgreSQL implementation of the DetailsRepository interface
+type PostgresDetailsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresDetailsRepository creates a new PostgresDetailsRepository
+func NewPostgresDetailsRepository(pool *pgxpool.Pool) *PostgresDetailsRepository {
+	return &PostgresDetailsRepository{pool: pool}
+}
+
+// TransactionOwned tells whether the transaction belongs to the account and the account to the user
+func (r *PostgresDetailsRepository) TransactionOwned(ctx context.Context, userID, accountID, transactionID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM transactions t
+			JOIN accounts a ON a.id = t.account_id
+			WHERE t.id = $1 AND a.id = $2 AND a.user_id = $3
+		)
+	`
+
+	var owned bool
+	if err := r.pool.QueryRow(ctx, query, transactionID, accountID, userID).Scan(&owned); err != nil {
+		return false, fmt.Errorf("failed to check transaction: %w", err)
+	}
+	return owned, nil
+}
+
+// FindByTransactionID retrieves the business details of a transaction
+func (r *PostgresDetailsRepository) FindByTransactionID(ctx context.Context, transactionID uuid.UUID) (*TransactionDetails, error) {
+	query := `
+		SELECT transaction_id, account_id, cost_center_id, COALESCE(supplier_cnpj, ''), updated_at
+		FROM transaction_business_details
+		WHERE transaction_id = $1
+	`
+
+	var d TransactionDetails
+	err := r.pool.QueryRow(ctx, query, transactionID).Scan(&d.TransactionID, &d.AccountID, &d.CostCenterID, &d.SupplierCNPJ, &d.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBusinessDetailsNotFound.With("transaction_id", transactionID)
+		}
+		return nil, fmt.Errorf("failed to fetch business details: %w", err)
+	}
+	return &d, nil
+}
+
+// Save inserts or replaces the business details of a transaction
+func (r *PostgresDetailsRepository) Save(ctx context.Context, details *TransactionDetails) error {
+	query := `
+		INSERT INTO transaction_business_details (transaction_id, account_id, cost_center_id, supplier_cnpj, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (transaction_id)
+		DO UPDATE SET
+			cost_center_id = EXCLUDED.cost_center_id,
+			supplier_cnpj = EXCLUDED.supplier_cnpj,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.pool.Exec(ctx, query, details.TransactionID, details.AccountID, details.CostCenterID, details.SupplierCNPJ, details.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert business details: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the business details of a transaction
+func (r *PostgresDetailsRepository) Delete(ctx context.Context, transactionID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM transaction_business_details WHERE transaction_id = $1`, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete business details: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBusinessDetailsNotFound.With("transaction_id", transactionID)
+	}
+	return nil
+}
+
+// CostCenterTotals sums the income and the expenses (stored as negative amounts) with business details due within [from, to)
+// by cost center and account currency, archived transactions included and the details of deleted ones being left out
+// by the join
+func (r *PostgresDetailsRepository) CostCenterTotals(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]CostCenterTotals, error) {
+	query := `
+		-- name: costCenterTotals
+		SELECT
+			cc.id,
+			COALESCE(cc.name, ''),
+			a.currency,
+			COALESCE(SUM(t.amount_in_cents) FILTER (WHERE t.type = 'INCOME'), 0),
+			COALESCE(SUM(-t.amount_in_cents) FILTER (WHERE t.type = 'EXPENSE'), 0),
+			COUNT(*)
+		FROM transaction_business_details d
+		JOIN (
+			SELECT id, type, amount_in_cents, due_date FROM transactions
+			UNION ALL
+			SELECT id, type, amount_in_cents, due_date FROM archived_transactions
+		) t ON t.id = d.transaction_id
+		JOIN accounts a ON a.id = d.account_id
+		LEFT JOIN cost_centers cc ON cc.id = d.cost_center_id
+		WHERE a.user_id = $1
+			AND t.type IN ('INCOME', 'EXPENSE')
+			AND t.due_date >= $2 AND t.due_date < $3
+		GROUP BY cc.id, cc.name, a.currency
+		ORDER BY a.currency, LOWER(cc.name) NULLS LAST
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost center totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make([]CostCenterTotals, 0)
+	for rows.Next() {
+		var (
+			centerID         *uuid.UUID
+			center, currency string
+			income, expenses int64
+			count            int
+		)
+		if err := rows.Scan(&centerID, &center, &currency, &income, &expenses, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan cost center totals row: %w", err)
+		}
+		totals = append(totals, CostCenterTotals{
+			CostCenterID: centerID,
+			CostCenter:   center,
+			Income:       money.New(income, currency),
+			Expenses:     money.New(expenses, currency),
+			Transactions: count,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost center totals rows: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,237 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/google/uuid"
+)
+
+// SetDetailsParams holds all the required data for the SetTransactionDetails use case
+type SetDetailsParams struct {
+	UserID        uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	CostCenterID  *uuid.UUID
+	SupplierCNPJ  string // SupplierCNPJ may be formatted (e.g., "12.345.678/0001-95")
+}
+
+// Service manages the business profiles, their cost centers and the business details of the transactions
+type Service struct {
+	profileRepo ProfileRepository
+	centerRepo  CostCenterRepository
+	detailsRepo DetailsRepository
+	periods     module.AccountingPeriods
+	display     module.UserPreferences
+	clock       clock.Clock
+}
+
+// NewService creates a new instance of the business Service, reports apply to the financial month of each user
+func NewService(
+	profileRepo ProfileRepository,
+	centerRepo CostCenterRepository,
+	detailsRepo DetailsRepository,
+	periods module.AccountingPeriods,
+	display module.UserPreferences,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		profileRepo: profileRepo,
+		centerRepo:  centerRepo,
+		detailsRepo: detailsRepo,
+		periods:     periods,
+		display:     display,
+		clock:       clock,
+	}
+}
+
+// GetProfile is the use case for finding the business profile of the user
+func (s *Service) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business profile: %w", err)
+	}
+	if profile == nil {
+		return nil, ErrProfileNotFound.With("user_id", userID)
+	}
+	return profile, nil
+}
+
+// SaveProfile is the use case for enabling the business profile of the user or changing its legal name and CNPJ
+func (s *Service) SaveProfile(ctx context.Context, userID uuid.UUID, legalName, cnpj string) (*Profile, error) {
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business profile: %w", err)
+	}
+
+	if profile == nil {
+		profile, err = NewProfile(userID, legalName, cnpj, s.clock)
+	} else {
+		err = profile.Update(legalName, cnpj, s.clock)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.profileRepo.Save(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save business profile: %w", err)
+	}
+	return profile, nil
+}
+
+// DisableProfile is the use case for leaving the business profile, the cost centers and the business details
+// of the transactions being kept for when the user enables it again
+func (s *Service) DisableProfile(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.GetProfile(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.profileRepo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to disable business profile: %w", err)
+	}
+	return nil
+}
+
+// ListCostCenters is the use case for listing the cost centers of the user ordered by name
+func (s *Service) ListCostCenters(ctx context.Context, userID uuid.UUID) ([]*CostCenter, error) {
+	if _, err := s.GetProfile(ctx, userID); err != nil {
+		return nil, err
+	}
+	centers, err := s.centerRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cost centers: %w", err)
+	}
+	return centers, nil
+}
+
+// CreateCostCenter is the use case for adding a cost center to the business of the user
+func (s *Service) CreateCostCenter(ctx context.Context, userID uuid.UUID, name string) (*CostCenter, error) {
+	if _, err := s.GetProfile(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	center, err := NewCostCenter(userID, name, s.clock)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.centerRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxCostCentersPerUser {
+		return nil, ErrTooManyCostCenters.With("max_cost_centers", MaxCostCentersPerUser)
+	}
+
+	if err := s.centerRepo.Save(ctx, center); err != nil {
+		return nil, err
+	}
+	return center, nil
+}
+
+// DeleteCostCenter is the use case for deleting a cost center, the transactions it was set on keeping their supplier
+func (s *Service) DeleteCostCenter(ctx context.Context, userID, centerID uuid.UUID) error {
+	if err := s.centerRepo.Delete(ctx, userID, centerID); err != nil {
+		return fmt.Errorf("failed to delete cost center: %w", err)
+	}
+	return nil
+}
+
+// GetTransactionDetails is the use case for finding the cost center and the supplier of a transaction of the user
+func (s *Service) GetTransactionDetails(ctx context.Context, userID, accountID, transactionID uuid.UUID) (*TransactionDetails, error) {
+	if err := s.checkOwnership(ctx, userID, accountID, transactionID); err != nil {
+		return nil, err
+	}
+	return s.detailsRepo.FindByTransactionID(ctx, transactionID)
+}
+
+// SetTransactionDetails is the use case for setting the cost center and the supplier of a transaction of the user,
+// which requires the business profile
+func (s *Service) SetTransactionDetails(ctx context.Context, params SetDetailsParams) (*TransactionDetails, error) {
+	if _, err := s.GetProfile(ctx, params.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnership(ctx, params.UserID, params.AccountID, params.TransactionID); err != nil {
+		return nil, err
+	}
+
+	details, err := NewTransactionDetails(params.AccountID, params.TransactionID, params.CostCenterID, params.SupplierCNPJ, s.clock)
+	if err != nil {
+		return nil, err
+	}
+	if details.CostCenterID != nil {
+		if _, err := s.centerRepo.FindByID(ctx, params.UserID, *details.CostCenterID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.detailsRepo.Save(ctx, details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// ClearTransactionDetails is the use case for removing the cost center and the supplier of a transaction of the user
+func (s *Service) ClearTransactionDetails(ctx context.Context, userID, accountID, transactionID uuid.UUID) error {
+	if err := s.checkOwnership(ctx, userID, accountID, transactionID); err != nil {
+		return err
+	}
+	return s.detailsRepo.Delete(ctx, transactionID)
+}
+
+// CostCenterReport is the use case for reporting the income and expenses by cost center between two days, both included
+// The period defaults to the current financial month of the user up to today
+func (s *Service) CostCenterReport(ctx context.Context, userID uuid.UUID, from, to time.Time) (*CostCenterReport, error) {
+	if _, err := s.GetProfile(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	to = day(to)
+	if from.IsZero() {
+		period, err := s.periods.AccountingPeriod(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find accounting period: %w", err)
+		}
+		from, _ = period.RangeIn(to, time.UTC)
+	}
+	from = day(from)
+
+	if from.After(to) || to.Sub(from) > maxReportRange {
+		return nil, ErrInvalidReportRange.With("from", from.Format(time.DateOnly)).With("to", to.Format(time.DateOnly))
+	}
+
+	prefs, err := s.display.DisplayPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find display preferences: %w", err)
+	}
+
+	totals, err := s.detailsRepo.CostCenterTotals(ctx, userID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute cost center totals: %w", err)
+	}
+
+	return &CostCenterReport{From: from, To: to, CostCenters: totals, Locale: prefs.Locale}, nil
+}
+
+// checkOwnership reports the transactions of accounts the user does not own as not found
+func (s *Service) checkOwnership(ctx context.Context, userID, accountID, transactionID uuid.UUID) error {
+	owned, err := s.detailsRepo.TransactionOwned(ctx, userID, accountID, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to find business transaction: %w", err)
+	}
+	if !owned {
+		return ErrTransactionNotFound.With("transaction_id", transactionID)
+	}
+	return nil
+}
+
+// day truncates a time to its UTC day
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
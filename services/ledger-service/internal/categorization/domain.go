@@ -0,0 +1,111 @@
+package categorization
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRuleNotFound        = errx.New(errx.CategoryNotFound, "CATEGORIZATION_RULE_NOT_FOUND", "categorization rule not found")
+	ErrCategoryNotFound    = errx.New(errx.CategoryNotFound, "CATEGORY_NOT_FOUND", "category not found")
+	ErrAccountNotFound     = errx.New(errx.CategoryNotFound, "ACCOUNT_NOT_FOUND", "account not found")
+	ErrRuleAlreadyExists   = errx.New(errx.CategoryConflict, "CATEGORIZATION_RULE_ALREADY_EXISTS", "a rule with the same text already exists for the account")
+	ErrTooManyRules        = errx.New(errx.CategoryConflict, "TOO_MANY_CATEGORIZATION_RULES", "the maximum number of categorization rules was reached")
+	ErrRulePatternTooShort = errx.New(errx.CategoryValidation, "RULE_PATTERN_TOO_SHORT", "the rule text is too short")
+	ErrRulePatternTooLong  = errx.New(errx.CategoryValidation, "RULE_PATTERN_TOO_LONG", "the rule text is too long")
+)
+
+const (
+	// MaxRulesPerUser bounds the rules checked against every synced transaction
+	MaxRulesPerUser = 200
+
+	minPatternLength = 2
+	maxPatternLength = 100
+)
+
+// Rule categorizes the transactions whose description contains its pattern, ignoring case and accents
+// A rule scoped to an account only applies to the transactions of that account
+type Rule struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Pattern    string
+	CategoryID uuid.UUID
+	AccountID  *uuid.UUID // AccountID is nil for the rules applying to every account
+	CreatedAt  time.Time
+}
+
+// Matches reports whether the rule applies to a transaction of the account with the normalized description
+func (r *Rule) Matches(accountID uuid.UUID, normalizedDescription string) bool {
+	if r.AccountID != nil && *r.AccountID != accountID {
+		return false
+	}
+	return strings.Contains(normalizedDescription, normalize(r.Pattern))
+}
+
+// moreSpecific reports whether the rule wins over other when both match:
+// account rules win over global ones, then the longest pattern wins, then the oldest rule
+func (r *Rule) moreSpecific(other *Rule) bool {
+	if (r.AccountID != nil) != (other.AccountID != nil) {
+		return r.AccountID != nil
+	}
+	if len(r.Pattern) != len(other.Pattern) {
+		return len(r.Pattern) > len(other.Pattern)
+	}
+	return r.CreatedAt.Before(other.CreatedAt)
+}
+
+// Match returns the most specific rule matching a transaction of the account, nil when none matches
+func Match(rules []*Rule, accountID uuid.UUID, description string) *Rule {
+	normalized := normalize(description)
+
+	var best *Rule
+	for _, rule := range rules {
+		if rule.Matches(accountID, normalized) && (best == nil || rule.moreSpecific(best)) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// validatePattern checks the pattern is long enough not to match every transaction
+func validatePattern(pattern string) error {
+	length := len([]rune(strings.TrimSpace(pattern)))
+	if length < minPatternLength {
+		return ErrRulePatternTooShort.With("min_length", minPatternLength)
+	}
+	if length > maxPatternLength {
+		return ErrRulePatternTooLong.With("max_length", maxPatternLength)
+	}
+	return nil
+}
+
+// accentReplacer strips the accents found in Portuguese and Spanish bank descriptions
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// normalize lowercases the text, strips its accents and collapses its spaces
+func normalize(text string) string {
+	return strings.Join(strings.Fields(accentReplacer.Replace(strings.ToLower(text))), " ")
+}
+
+// Repository persists the categorization rules
+type Repository interface {
+	// Save inserts the rule, failing with ErrRuleAlreadyExists when the user has a rule with the same pattern and account
+	Save(ctx context.Context, rule *Rule) error
+	// FindByUserID returns the rules of the user, oldest first
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Rule, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	Delete(ctx context.Context, userID, ruleID uuid.UUID) error
+	CategoryExists(ctx context.Context, userID, categoryID uuid.UUID) (bool, error)
+	AccountExists(ctx context.Context, userID, accountID uuid.UUID) (bool, error)
+}
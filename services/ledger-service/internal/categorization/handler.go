@@ -0,0 +1,134 @@
+package categorization
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CategorizationHandler holds dependencies for categorization rule HTTP handlers
+type CategorizationHandler struct {
+	categorizationService *Service
+}
+
+// NewCategorizationHandler creates a new instance of CategorizationHandler
+func NewCategorizationHandler(categorizationService *Service) *CategorizationHandler {
+	return &CategorizationHandler{categorizationService: categorizationService}
+}
+
+// RegisterRoutes sets up the API routes for the categorization module
+func (h *CategorizationHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	rulesGroup := apiRouteGroup.Group("/categorization-rules")
+
+	rulesGroup.POST("", h.createRuleHandler)
+	rulesGroup.GET("", h.listRulesHandler)
+	rulesGroup.DELETE("/:id", h.deleteRuleHandler)
+}
+
+// CreateRuleRequest defines the expected JSON body for creating a categorization rule
+type CreateRuleRequest struct {
+	Pattern    string     `json:"pattern" validate:"required,max=100"` // Pattern is the text the descriptions must contain, ignoring case and accents
+	CategoryID uuid.UUID  `json:"category_id" validate:"required"`
+	AccountID  *uuid.UUID `json:"account_id,omitempty"` // AccountID restricts the rule to the transactions of an account
+}
+
+// RuleResponse defines the structure of a categorization rule returned by the API
+type RuleResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Pattern    string     `json:"pattern"`
+	CategoryID uuid.UUID  `json:"category_id"`
+	AccountID  *uuid.UUID `json:"account_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// createRuleHandler handles the HTTP request for creating a categorization rule
+func (h *CategorizationHandler) createRuleHandler(c echo.Context) error {
+	var req CreateRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := CreateRuleParams{
+		UserID:     userID,
+		Pattern:    req.Pattern,
+		CategoryID: req.CategoryID,
+		AccountID:  req.AccountID,
+	}
+
+	rule, err := h.categorizationService.CreateRule(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toRuleResponse(rule))
+}
+
+// listRulesHandler handles the HTTP request for listing the categorization rules of the user
+func (h *CategorizationHandler) listRulesHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	rules, err := h.categorizationService.FindRules(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]RuleResponse, len(rules))
+	for i, rule := range rules {
+		resp[i] = toRuleResponse(rule)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// deleteRuleHandler handles the HTTP request for deleting a categorization rule
+func (h *CategorizationHandler) deleteRuleHandler(c echo.Context) error {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid rule id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.categorizationService.DeleteRule(c.Request().Context(), userID, ruleID); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toRuleResponse maps a Rule to the public RuleResponse DTO
+func toRuleResponse(rule *Rule) RuleResponse {
+	return RuleResponse{
+		ID:         rule.ID,
+		Pattern:    rule.Pattern,
+		CategoryID: rule.CategoryID,
+		AccountID:  rule.AccountID,
+		CreatedAt:  rule.CreatedAt,
+	}
+}
@@ -0,0 +1,39 @@
+package categorization
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the categorization repository, service and handler from the shared dependencies
+type Module struct {
+	handler *CategorizationHandler
+	service *Service
+}
+
+// NewModule creates the categorization module, whose rules are stored in Postgres
+func NewModule(deps module.Deps) *Module {
+	categorizationSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.Audit, deps.Clock)
+
+	return &Module{
+		handler: NewCategorizationHandler(categorizationSvc),
+		service: categorizationSvc,
+	}
+}
+
+// Service returns the categorization service, for the composition root handing its rules to the bank sync
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "categorization"
+}
+
+// RegisterRoutes mounts the categorization routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,116 @@
+package categorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// ruleUniqueIndex keeps a single rule per pattern, case-insensitively, for each account and for every account
+const ruleUniqueIndex = "uq_categorization_rules_user_id_pattern_account_id"
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// Save inserts a new categorization rule
+func (r *PostgresRepository) Save(ctx context.Context, rule *Rule) error {
+	query := `
+		INSERT INTO categorization_rules (id, user_id, pattern, category_id, account_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.pool.Exec(ctx, query, rule.ID, rule.UserID, rule.Pattern, rule.CategoryID, rule.AccountID, rule.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == ruleUniqueIndex {
+			return ErrRuleAlreadyExists.With("pattern", rule.Pattern)
+		}
+		return fmt.Errorf("failed to insert categorization rule: %w", err)
+	}
+	return nil
+}
+
+// FindByUserID retrieves the rules of the user, oldest first
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Rule, error) {
+	query := `
+		SELECT id, user_id, pattern, category_id, account_id, created_at
+		FROM categorization_rules
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categorization rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*Rule, 0)
+	for rows.Next() {
+		var rule Rule
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.Pattern, &rule.CategoryID, &rule.AccountID, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan categorization rule row: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categorization rule rows: %w", err)
+	}
+
+	return rules, nil
+}
+
+// CountByUserID counts the rules of the user
+func (r *PostgresRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM categorization_rules WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count categorization rules: %w", err)
+	}
+	return count, nil
+}
+
+// Delete deletes a rule of the user
+func (r *PostgresRepository) Delete(ctx context.Context, userID, ruleID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM categorization_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete categorization rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRuleNotFound.With("rule_id", ruleID)
+	}
+	return nil
+}
+
+// CategoryExists tells whether the category is one of the user's or a system-default one
+func (r *PostgresRepository) CategoryExists(ctx context.Context, userID, categoryID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND (user_id = $2 OR user_id IS NULL))`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, categoryID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check category: %w", err)
+	}
+	return exists, nil
+}
+
+// AccountExists tells whether the account belongs to the user
+func (r *PostgresRepository) AccountExists(ctx context.Context, userID, accountID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM accounts WHERE id = $1 AND user_id = $2)`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, accountID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check account: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,142 @@
+package categorization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the categorization rules
+const (
+	auditRuleCreated  = "categorization_rule.created"
+	auditRuleDeleted  = "categorization_rule.deleted"
+	auditResourceRule = "categorization_rule"
+)
+
+// CreateRuleParams holds all the required data for the CreateRule use case
+type CreateRuleParams struct {
+	UserID     uuid.UUID
+	Pattern    string
+	CategoryID uuid.UUID
+	AccountID  *uuid.UUID
+}
+
+// Service manages the rules categorizing the transactions synced from banks
+type Service struct {
+	repo    Repository
+	auditor *audit.Logger
+	clock   clock.Clock
+}
+
+// NewService creates a new instance of the categorization Service
+func NewService(repo Repository, auditor *audit.Logger, clock clock.Clock) *Service {
+	return &Service{
+		repo:    repo,
+		auditor: auditor,
+		clock:   clock,
+	}
+}
+
+// CreateRule is the use case for adding a rule categorizing the transactions whose description contains a text
+func (s *Service) CreateRule(ctx context.Context, params CreateRuleParams) (*Rule, error) {
+	if err := validatePattern(params.Pattern); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.CategoryExists(ctx, params.UserID, params.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find rule category: %w", err)
+	}
+	if !exists {
+		return nil, ErrCategoryNotFound.With("category_id", params.CategoryID)
+	}
+	if params.AccountID != nil {
+		exists, err := s.repo.AccountExists(ctx, params.UserID, *params.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find rule account: %w", err)
+		}
+		if !exists {
+			return nil, ErrAccountNotFound.With("account_id", *params.AccountID)
+		}
+	}
+
+	count, err := s.repo.CountByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxRulesPerUser {
+		return nil, ErrTooManyRules.With("max_rules", MaxRulesPerUser)
+	}
+
+	rule := &Rule{
+		ID:         uuid.New(),
+		UserID:     params.UserID,
+		Pattern:    strings.TrimSpace(params.Pattern),
+		CategoryID: params.CategoryID,
+		AccountID:  params.AccountID,
+		CreatedAt:  s.clock.Now(),
+	}
+	if err := s.repo.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditRuleCreated,
+		ResourceType: auditResourceRule,
+		ResourceID:   rule.ID.String(),
+		Metadata: map[string]string{
+			"pattern":     rule.Pattern,
+			"category_id": rule.CategoryID.String(),
+		},
+	})
+
+	return rule, nil
+}
+
+// FindRules is the use case for listing the rules of the user, oldest first
+func (s *Service) FindRules(ctx context.Context, userID uuid.UUID) ([]*Rule, error) {
+	rules, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorization rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule is the use case for deleting a rule, the transactions it categorized keep their category
+func (s *Service) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	if err := s.repo.Delete(ctx, userID, ruleID); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditRuleDeleted,
+		ResourceType: auditResourceRule,
+		ResourceID:   ruleID.String(),
+	})
+
+	return nil
+}
+
+// Categorize returns the category of each description of a transaction of the account, nil where no rule matches
+func (s *Service) Categorize(ctx context.Context, userID, accountID uuid.UUID, descriptions []string) ([]*uuid.UUID, error) {
+	rules, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorization rules: %w", err)
+	}
+
+	categories := make([]*uuid.UUID, len(descriptions))
+	if len(rules) == 0 {
+		return categories, nil
+	}
+	for i, description := range descriptions {
+		if rule := Match(rules, accountID, description); rule != nil {
+			categoryID := rule.CategoryID
+			categories[i] = &categoryID
+		}
+	}
+	return categories, nil
+}
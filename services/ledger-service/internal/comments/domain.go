@@ -0,0 +1,63 @@
+package comments
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTransactionNotFound = errx.New(errx.CategoryNotFound, "TRANSACTION_NOT_FOUND", "transaction not found")
+	ErrInvalidComment      = errx.New(errx.CategoryValidation, "INVALID_COMMENT", "the comment must have between 1 and 1000 characters")
+	ErrTooManyComments     = errx.New(errx.CategoryConflict, "TOO_MANY_COMMENTS", "the maximum number of comments on the transaction was reached")
+)
+
+const (
+	// MaxCommentsPerTransaction bounds a thread, which is always returned whole
+	MaxCommentsPerTransaction = 200
+
+	maxCommentLength = 1000
+)
+
+// Comment is a message left on a transaction by a member of its account, threads are append-only
+type Comment struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	AuthorID      uuid.UUID
+	Body          string
+	CreatedAt     time.Time
+}
+
+// NewComment creates a new Comment of the author on a transaction
+func NewComment(accountID, transactionID, authorID uuid.UUID, body string, ids idgen.Generator, clock clock.Clock) (*Comment, error) {
+	body = strings.TrimSpace(body)
+	if length := utf8.RuneCountInString(body); length == 0 || length > maxCommentLength {
+		return nil, ErrInvalidComment.With("max_length", maxCommentLength)
+	}
+
+	return &Comment{
+		ID:            ids.NewID(),
+		AccountID:     accountID,
+		TransactionID: transactionID,
+		AuthorID:      authorID,
+		Body:          body,
+		CreatedAt:     clock.Now(),
+	}, nil
+}
+
+// Repository persists the comment threads of the transactions
+type Repository interface {
+	// TransactionVisible tells whether the transaction belongs to the account and the user owns the account or sees it through a household
+	TransactionVisible(ctx context.Context, userID, accountID, transactionID uuid.UUID) (bool, error)
+	Save(ctx context.Context, comment *Comment) error
+	// FindByTransactionID returns the thread of a transaction, oldest first
+	FindByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]*Comment, error)
+	CountByTransactionID(ctx context.Context, transactionID uuid.UUID) (int, error)
+}
@@ -0,0 +1,136 @@
+package comments
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CommentHandler holds dependencies for transaction comment HTTP handlers
+type CommentHandler struct {
+	commentService *Service
+}
+
+// NewCommentHandler creates a new instance of CommentHandler
+func NewCommentHandler(commentService *Service) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+// RegisterRoutes sets up the API routes for the comments module
+func (h *CommentHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	threadGroup := apiRouteGroup.Group("/accounts/:id/transactions/:transactionId/comments")
+
+	threadGroup.GET("", h.listCommentsHandler)
+	threadGroup.POST("", h.addCommentHandler)
+}
+
+// AddCommentRequest defines the expected JSON body for commenting on a transaction
+type AddCommentRequest struct {
+	Body string `json:"body" validate:"required,max=1000"`
+}
+
+// CommentResponse defines the structure of a comment returned by the API
+type CommentResponse struct {
+	ID         uuid.UUID `json:"id"`
+	AuthorID   uuid.UUID `json:"author_id"`
+	AuthorName string    `json:"author_name,omitempty"` // AuthorName is left out when the identity service could not name the author
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// listCommentsHandler handles the HTTP request for reading the comment thread of a transaction
+func (h *CommentHandler) listCommentsHandler(c echo.Context) error {
+	accountID, transactionID, err := transactionParams(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	comments, err := h.commentService.FindComments(c.Request().Context(), userID, accountID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	names := h.commentService.AuthorNames(c.Request().Context(), comments)
+	resp := make([]CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		commentResp := toCommentResponse(comment)
+		commentResp.AuthorName = names[comment.AuthorID]
+		resp = append(resp, commentResp)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// addCommentHandler handles the HTTP request for commenting on a transaction
+func (h *CommentHandler) addCommentHandler(c echo.Context) error {
+	accountID, transactionID, err := transactionParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req AddCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	comment, err := h.commentService.AddComment(c.Request().Context(), AddCommentParams{
+		UserID:        userID,
+		AccountID:     accountID,
+		TransactionID: transactionID,
+		Body:          req.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toCommentResponse(comment))
+}
+
+// transactionParams parses the account and transaction ids of the route
+func transactionParams(c echo.Context) (accountID, transactionID uuid.UUID, err error) {
+	accountID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err = uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
+	return accountID, transactionID, nil
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toCommentResponse maps the internal Comment domain model to the public CommentResponse DTO
+func toCommentResponse(comment *Comment) CommentResponse {
+	return CommentResponse{
+		ID:        comment.ID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+	}
+}
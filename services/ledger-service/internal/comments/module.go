@@ -0,0 +1,31 @@
+package comments
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the comments repository, service and handler from the shared dependencies
+type Module struct {
+	handler *CommentHandler
+}
+
+// NewModule creates the comments module, whose threads are stored in Postgres and whose authors are named by the
+// identity service
+func NewModule(deps module.Deps) *Module {
+	commentSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.Identity, deps.IDs, deps.Clock)
+
+	return &Module{handler: NewCommentHandler(commentSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "comments"
+}
+
+// RegisterRoutes mounts the comments routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
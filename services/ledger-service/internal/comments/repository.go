@@ -0,0 +1,105 @@
+package comments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// TransactionVisible tells whether the transaction belongs to the account and the account to the user,
+// or is shared with a household where the user may see the accounts. The threads of the transactions rolled into an
+// opening balance stay readable from the archived history
+func (r *PostgresRepository) TransactionVisible(ctx context.Context, userID, accountID, transactionID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM (
+				SELECT id, account_id FROM transactions
+				UNION ALL
+				SELECT id, account_id FROM archived_transactions
+			) t
+			JOIN accounts a ON a.id = t.account_id
+			WHERE t.id = $1 AND a.id = $2 AND (
+				a.user_id = $3 OR EXISTS (
+					SELECT 1
+					FROM household_accounts ha
+					JOIN household_members m ON m.household_id = ha.household_id
+					WHERE ha.account_id = a.id AND m.user_id = $3 AND m.joined_at IS NOT NULL
+						AND (m.role = 'ADMIN' OR 'ACCOUNTS' = ANY(m.permissions))
+				)
+			)
+		)
+	`
+
+	var visible bool
+	if err := r.pool.QueryRow(ctx, query, transactionID, accountID, userID).Scan(&visible); err != nil {
+		return false, fmt.Errorf("failed to check transaction: %w", err)
+	}
+	return visible, nil
+}
+
+// Save inserts a new comment, comments are never updated
+func (r *PostgresRepository) Save(ctx context.Context, comment *Comment) error {
+	query := `
+		INSERT INTO transaction_comments (id, account_id, transaction_id, author_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.pool.Exec(ctx, query, comment.ID, comment.AccountID, comment.TransactionID, comment.AuthorID, comment.Body, comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert comment: %w", err)
+	}
+	return nil
+}
+
+// FindByTransactionID retrieves the thread of a transaction, oldest first
+func (r *PostgresRepository) FindByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]*Comment, error) {
+	query := `
+		SELECT id, account_id, transaction_id, author_id, body, created_at
+		FROM transaction_comments
+		WHERE transaction_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*Comment, 0)
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.AccountID, &c.TransactionID, &c.AuthorID, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment row: %w", err)
+		}
+		comments = append(comments, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment rows: %w", err)
+	}
+
+	return comments, nil
+}
+
+// CountByTransactionID counts the comments of a transaction
+func (r *PostgresRepository) CountByTransactionID(ctx context.Context, transactionID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM transaction_comments WHERE transaction_id = $1`, transactionID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,125 @@
+package comments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/google/uuid"
+)
+
+// AddCommentParams holds all the required data for the AddComment use case
+type AddCommentParams struct {
+	UserID        uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	Body          string
+}
+
+// Directory resolves the users owned by the identity service, many at once
+type Directory interface {
+	GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]identityclient.User, error)
+}
+
+// Service manages the comment threads of the transactions
+type Service struct {
+	repo      Repository
+	directory Directory
+	ids       idgen.Generator
+	clock     clock.Clock
+}
+
+// NewService creates a new instance of the comments Service
+func NewService(repo Repository, directory Directory, ids idgen.Generator, clock clock.Clock) *Service {
+	return &Service{
+		repo:      repo,
+		directory: directory,
+		ids:       ids,
+		clock:     clock,
+	}
+}
+
+// AddComment is the use case for commenting on a transaction of an account the user is a member of
+func (s *Service) AddComment(ctx context.Context, params AddCommentParams) (*Comment, error) {
+	if err := s.checkAccess(ctx, params.UserID, params.AccountID, params.TransactionID); err != nil {
+		return nil, err
+	}
+
+	comment, err := NewComment(params.AccountID, params.TransactionID, params.UserID, params.Body, s.ids, s.clock)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByTransactionID(ctx, params.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxCommentsPerTransaction {
+		return nil, ErrTooManyComments.With("max_comments", MaxCommentsPerTransaction)
+	}
+
+	if err := s.repo.Save(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// FindComments is the use case for reading the thread of a transaction, oldest first
+func (s *Service) FindComments(ctx context.Context, userID, accountID, transactionID uuid.UUID) ([]*Comment, error) {
+	if err := s.checkAccess(ctx, userID, accountID, transactionID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.repo.FindByTransactionID(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find comments: %w", err)
+	}
+	return comments, nil
+}
+
+// AuthorNames resolves the names of the authors of a thread in a single call to the identity service
+// The thread is still worth reading without them, so a failure only leaves the names out
+func (s *Service) AuthorNames(ctx context.Context, comments []*Comment) map[uuid.UUID]string {
+	authorIDs := make([]uuid.UUID, 0, len(comments))
+	seen := make(map[uuid.UUID]bool, len(comments))
+	for _, comment := range comments {
+		if !seen[comment.AuthorID] {
+			seen[comment.AuthorID] = true
+			authorIDs = append(authorIDs, comment.AuthorID)
+		}
+	}
+	if len(authorIDs) == 0 {
+		return nil
+	}
+
+	users, err := s.directory.GetUsersByIDs(ctx, authorIDs)
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Warn("failed to resolve the comment authors, answering without their names",
+			slog.Int("author_count", len(authorIDs)),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	names := make(map[uuid.UUID]string, len(users))
+	for userID, user := range users {
+		names[userID] = user.Name
+	}
+	return names
+}
+
+// checkAccess reports the transactions of accounts the user is not a member of as not found
+func (s *Service) checkAccess(ctx context.Context, userID, accountID, transactionID uuid.UUID) error {
+	visible, err := s.repo.TransactionVisible(ctx, userID, accountID, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to find commented transaction: %w", err)
+	}
+	if !visible {
+		return ErrTransactionNotFound.With("transaction_id", transactionID)
+	}
+	return nil
+}
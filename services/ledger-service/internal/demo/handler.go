@@ -0,0 +1,50 @@
+package demo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DemoHandler exposes the public endpoint that starts a demo session
+type DemoHandler struct {
+	demoService *Service
+}
+
+// NewDemoHandler creates a new instance of DemoHandler
+func NewDemoHandler(demoService *Service) *DemoHandler {
+	return &DemoHandler{demoService: demoService}
+}
+
+// RegisterRoutes sets up the public demo routes, outside the authenticated API group
+func (h *DemoHandler) RegisterRoutes(publicRouteGroup *echo.Group) {
+	demoGroup := publicRouteGroup.Group("/demo")
+
+	demoGroup.POST("/sessions", h.createSessionHandler)
+}
+
+// SessionResponse defines the structure of a demo session returned by the API
+type SessionResponse struct {
+	UserID      uuid.UUID `json:"user_id"`
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// createSessionHandler handles the HTTP request for provisioning a demo user with seeded data
+func (h *DemoHandler) createSessionHandler(c echo.Context) error {
+	session, err := h.demoService.CreateSession(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, SessionResponse{
+		UserID:      session.UserID,
+		AccessToken: session.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   session.ExpiresAt,
+	})
+}
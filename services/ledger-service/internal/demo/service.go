@@ -0,0 +1,169 @@
+package demo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/devseed"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDemoCapacityReached = errx.New(errx.CategoryUnavailable, "DEMO_CAPACITY_REACHED", "too many demo sessions are running, try again later")
+)
+
+const (
+	// TokenPrefix distinguishes demo access tokens from the identity service ones
+	TokenPrefix = "demo_"
+
+	// Role is granted to demo users, so features can be restricted for them
+	Role = "demo"
+
+	// purgeInterval is how often expired sessions and their data are removed
+	purgeInterval = time.Minute
+)
+
+// Session is a throwaway demo user with its access token
+type Session struct {
+	UserID      uuid.UUID
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// Service provisions demo sessions, each one a new user with seeded data kept in memory
+// Sessions live in the instance memory, so demo mode is meant for a single instance
+type Service struct {
+	accounts    *ledger.InMemoryAccountRepository
+	seeder      *devseed.Seeder
+	clock       clock.Clock
+	ttl         time.Duration
+	months      int
+	maxSessions int
+
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewService creates a new demo Service seeding the in-memory accounts repository
+func NewService(accounts *ledger.InMemoryAccountRepository, ids idgen.Generator, clock clock.Clock, ttl time.Duration, months, maxSessions int) *Service {
+	return &Service{
+		accounts:    accounts,
+		seeder:      devseed.NewSeeder(devseed.EphemeralCategoryStore{}, accounts, ids, clock),
+		clock:       clock,
+		ttl:         ttl,
+		months:      months,
+		maxSessions: maxSessions,
+		sessions:    make(map[string]Session),
+	}
+}
+
+// CreateSession provisions a new demo user with seeded data and returns its access token
+func (s *Service) CreateSession(ctx context.Context) (*Session, error) {
+	s.mu.Lock()
+	if len(s.sessions) >= s.maxSessions {
+		s.mu.Unlock()
+		return nil, ErrDemoCapacityReached.With("max_sessions", s.maxSessions)
+	}
+	s.mu.Unlock()
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := Session{
+		UserID:      uuid.New(),
+		AccessToken: token,
+		ExpiresAt:   s.clock.Now().Add(s.ttl),
+	}
+
+	_, err = s.seeder.Seed(ctx, devseed.Options{
+		UserID:   session.UserID,
+		Months:   s.months,
+		RandSeed: s.clock.Now().UnixNano(),
+	})
+	if err != nil {
+		s.accounts.DeleteByUserID(ctx, session.UserID)
+		return nil, fmt.Errorf("failed to seed demo user: %w", err)
+	}
+
+	s.mu.Lock()
+	s.sessions[token] = session
+	s.mu.Unlock()
+
+	return &session, nil
+}
+
+// Authenticate returns the session of a demo access token, failing when unknown or expired
+func (s *Service) Authenticate(accessToken string) (*Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[accessToken]
+	if !ok || !s.clock.Now().Before(session.ExpiresAt) {
+		return nil, false
+	}
+	return &session, true
+}
+
+// IsDemoToken reports whether an access token was issued by the demo mode
+func IsDemoToken(accessToken string) bool {
+	return strings.HasPrefix(accessToken, TokenPrefix)
+}
+
+// Run removes the expired sessions and their data until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purgeExpired(ctx)
+		}
+	}
+}
+
+// purgeExpired deletes the expired sessions and every account of their users
+func (s *Service) purgeExpired(ctx context.Context) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	var expired []Session
+	for token, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			expired = append(expired, session)
+			delete(s.sessions, token)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, session := range expired {
+		s.accounts.DeleteByUserID(ctx, session.UserID)
+	}
+	if len(expired) > 0 {
+		ctxlogger.GetLogger(ctx).Info("purged expired demo sessions", slog.Int("sessions", len(expired)))
+	}
+}
+
+// newToken generates a random demo access token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate demo token: %w", err)
+	}
+	return TokenPrefix + hex.EncodeToString(b), nil
+}
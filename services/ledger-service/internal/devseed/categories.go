@@ -0,0 +1,78 @@
+package devseed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ CategoryStore = (*PostgresCategoryStore)(nil)
+	_ CategoryStore = EphemeralCategoryStore{}
+)
+
+// CategoryStore creates the generated category tree of a user
+// The ledger has no category module yet, so the seeder owns this small abstraction
+type CategoryStore interface {
+	CreateTree(ctx context.Context, userID uuid.UUID, tree []CategoryNode) (map[string]uuid.UUID, error)
+}
+
+// PostgresCategoryStore inserts the categories in the categories table
+type PostgresCategoryStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresCategoryStore creates a new PostgresCategoryStore
+func NewPostgresCategoryStore(pool *pgxpool.Pool) *PostgresCategoryStore {
+	return &PostgresCategoryStore{pool: pool}
+}
+
+// CreateTree inserts the parent categories and their subcategories in a single transaction
+func (s *PostgresCategoryStore) CreateTree(ctx context.Context, userID uuid.UUID, tree []CategoryNode) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID)
+
+	err := pgx.BeginTxFunc(ctx, s.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		query := `INSERT INTO categories (user_id, parent_id, name) VALUES ($1, $2, $3) RETURNING id`
+
+		for _, parent := range tree {
+			var parentID uuid.UUID
+			if err := tx.QueryRow(ctx, query, userID, nil, parent.Name).Scan(&parentID); err != nil {
+				return fmt.Errorf("failed to insert category %q: %w", parent.Name, err)
+			}
+			ids[parent.Name] = parentID
+
+			for _, child := range parent.Children {
+				var childID uuid.UUID
+				if err := tx.QueryRow(ctx, query, userID, parentID, child).Scan(&childID); err != nil {
+					return fmt.Errorf("failed to insert category %q: %w", child, err)
+				}
+				ids[child] = childID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// EphemeralCategoryStore only assigns IDs to the categories without storing them,
+// used by the demo mode whose data lives in memory
+type EphemeralCategoryStore struct{}
+
+// CreateTree assigns a new ID to every category of the tree
+func (EphemeralCategoryStore) CreateTree(ctx context.Context, userID uuid.UUID, tree []CategoryNode) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID)
+	for _, parent := range tree {
+		ids[parent.Name] = uuid.New()
+		for _, child := range parent.Children {
+			ids[child] = uuid.New()
+		}
+	}
+	return ids, nil
+}
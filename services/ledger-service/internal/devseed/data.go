@@ -0,0 +1,83 @@
+package devseed
+
+import "github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+
+// CategoryNode is a parent category and its subcategories
+type CategoryNode struct {
+	Name     string
+	Children []string
+}
+
+// categoryTree is the category hierarchy created for the user
+var categoryTree = []CategoryNode{
+	{Name: "Receitas", Children: []string{"Salário", "Freelance", "Rendimentos"}},
+	{Name: "Moradia", Children: []string{"Aluguel", "Energia", "Água", "Internet"}},
+	{Name: "Alimentação", Children: []string{"Supermercado", "Restaurantes", "Delivery"}},
+	{Name: "Transporte", Children: []string{"Combustível", "Aplicativos", "Transporte público"}},
+	{Name: "Saúde", Children: []string{"Plano de saúde", "Farmácia"}},
+	{Name: "Lazer", Children: []string{"Streaming", "Viagens"}},
+}
+
+// accountTemplate describes a generated account
+type accountTemplate struct {
+	key              string
+	name             string
+	currency         string
+	includeInBalance bool
+}
+
+// accountTemplates are the accounts created for the user
+var accountTemplates = []accountTemplate{
+	{key: "checking", name: "Conta corrente", currency: ledger.DefaultCurrency, includeInBalance: true},
+	{key: "wallet", name: "Carteira", currency: ledger.DefaultCurrency, includeInBalance: true},
+	{key: "savings", name: "Poupança", currency: ledger.DefaultCurrency, includeInBalance: false},
+	{key: "travel", name: "Travel savings", currency: "USD", includeInBalance: true},
+}
+
+// rule generates between minPerMonth and maxPerMonth transactions a month in an account
+// Amounts are in minor units and always positive, expenses are negated when generated
+type rule struct {
+	account      string
+	category     string
+	txType       ledger.TransactionType
+	descriptions []string
+	day          int // day is the fixed day of the month for recurring bills, 0 spreads them randomly
+	minPerMonth  int
+	maxPerMonth  int
+	minAmount    int64
+	maxAmount    int64
+}
+
+// rules describe a typical monthly budget, with recurring bills and variable spending
+var rules = []rule{
+	// Checking account: income and recurring bills
+	{account: "checking", category: "Salário", txType: ledger.Income, descriptions: []string{"Salário"}, day: 5, minPerMonth: 1, maxPerMonth: 1, minAmount: 650000, maxAmount: 650000},
+	{account: "checking", category: "Freelance", txType: ledger.Income, descriptions: []string{"Projeto freelance", "Consultoria"}, minPerMonth: 0, maxPerMonth: 1, minAmount: 80000, maxAmount: 350000},
+	{account: "checking", category: "Aluguel", txType: ledger.Expense, descriptions: []string{"Aluguel"}, day: 10, minPerMonth: 1, maxPerMonth: 1, minAmount: 185000, maxAmount: 185000},
+	{account: "checking", category: "Energia", txType: ledger.Expense, descriptions: []string{"Conta de luz"}, day: 15, minPerMonth: 1, maxPerMonth: 1, minAmount: 12000, maxAmount: 26000},
+	{account: "checking", category: "Água", txType: ledger.Expense, descriptions: []string{"Conta de água"}, day: 18, minPerMonth: 1, maxPerMonth: 1, minAmount: 6000, maxAmount: 11000},
+	{account: "checking", category: "Internet", txType: ledger.Expense, descriptions: []string{"Internet fibra"}, day: 20, minPerMonth: 1, maxPerMonth: 1, minAmount: 9990, maxAmount: 9990},
+	{account: "checking", category: "Plano de saúde", txType: ledger.Expense, descriptions: []string{"Plano de saúde"}, day: 8, minPerMonth: 1, maxPerMonth: 1, minAmount: 48000, maxAmount: 48000},
+	{account: "checking", category: "Streaming", txType: ledger.Expense, descriptions: []string{"Netflix", "Spotify"}, day: 12, minPerMonth: 2, maxPerMonth: 2, minAmount: 2190, maxAmount: 5590},
+
+	// Checking account: variable spending
+	{account: "checking", category: "Supermercado", txType: ledger.Expense, descriptions: []string{"Supermercado", "Atacadão", "Hortifruti"}, minPerMonth: 3, maxPerMonth: 6, minAmount: 8000, maxAmount: 45000},
+	{account: "checking", category: "Restaurantes", txType: ledger.Expense, descriptions: []string{"Almoço", "Jantar", "Pizzaria", "Hamburgueria"}, minPerMonth: 2, maxPerMonth: 7, minAmount: 3500, maxAmount: 22000},
+	{account: "checking", category: "Delivery", txType: ledger.Expense, descriptions: []string{"iFood", "Delivery"}, minPerMonth: 2, maxPerMonth: 8, minAmount: 3000, maxAmount: 12000},
+	{account: "checking", category: "Combustível", txType: ledger.Expense, descriptions: []string{"Posto de gasolina"}, minPerMonth: 2, maxPerMonth: 4, minAmount: 15000, maxAmount: 30000},
+	{account: "checking", category: "Aplicativos", txType: ledger.Expense, descriptions: []string{"Uber", "99"}, minPerMonth: 1, maxPerMonth: 8, minAmount: 1200, maxAmount: 6500},
+	{account: "checking", category: "Farmácia", txType: ledger.Expense, descriptions: []string{"Farmácia"}, minPerMonth: 0, maxPerMonth: 2, minAmount: 1500, maxAmount: 18000},
+
+	// Wallet: small cash expenses
+	{account: "wallet", category: "Receitas", txType: ledger.Income, descriptions: []string{"Saque"}, day: 6, minPerMonth: 1, maxPerMonth: 1, minAmount: 30000, maxAmount: 50000},
+	{account: "wallet", category: "Transporte público", txType: ledger.Expense, descriptions: []string{"Ônibus", "Metrô"}, minPerMonth: 4, maxPerMonth: 12, minAmount: 440, maxAmount: 880},
+	{account: "wallet", category: "Restaurantes", txType: ledger.Expense, descriptions: []string{"Padaria", "Lanchonete", "Feira"}, minPerMonth: 3, maxPerMonth: 8, minAmount: 800, maxAmount: 4500},
+
+	// Savings: monthly deposits and interest
+	{account: "savings", category: "Rendimentos", txType: ledger.Income, descriptions: []string{"Depósito"}, day: 7, minPerMonth: 1, maxPerMonth: 1, minAmount: 50000, maxAmount: 100000},
+	{account: "savings", category: "Rendimentos", txType: ledger.Income, descriptions: []string{"Rendimento poupança"}, day: 28, minPerMonth: 1, maxPerMonth: 1, minAmount: 800, maxAmount: 2500},
+
+	// Travel savings in USD
+	{account: "travel", category: "Rendimentos", txType: ledger.Income, descriptions: []string{"Monthly deposit"}, day: 15, minPerMonth: 1, maxPerMonth: 1, minAmount: 20000, maxAmount: 40000},
+	{account: "travel", category: "Viagens", txType: ledger.Expense, descriptions: []string{"Hotel booking", "Flight", "Travel insurance"}, minPerMonth: 0, maxPerMonth: 1, minAmount: 5000, maxAmount: 45000},
+}
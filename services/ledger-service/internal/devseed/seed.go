@@ -0,0 +1,151 @@
+package devseed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// maxMonths bounds the generated history, enough for performance work without exhausting the database
+const maxMonths = 60
+
+// Options configures the data generated for a user
+type Options struct {
+	UserID   uuid.UUID
+	Months   int   // Months of history to generate, ending with the current month
+	RandSeed int64 // RandSeed makes the generated data reproducible across runs
+}
+
+// Result summarizes what was generated
+type Result struct {
+	Categories   int
+	Accounts     int
+	Transactions int
+}
+
+// Seeder generates realistic development data (categories, accounts and transactions) for a user
+type Seeder struct {
+	categories  CategoryStore
+	accountRepo ledger.AccountRepository
+	ids         idgen.Generator
+	clock       clock.Clock
+}
+
+// NewSeeder creates a new Seeder
+func NewSeeder(categories CategoryStore, accountRepo ledger.AccountRepository, ids idgen.Generator, clock clock.Clock) *Seeder {
+	return &Seeder{
+		categories:  categories,
+		accountRepo: accountRepo,
+		ids:         ids,
+		clock:       clock,
+	}
+}
+
+// Seed generates the categories, accounts and transactions of opts.UserID
+// Transactions due after today are left unpaid, so projected balances differ from real ones
+func (s *Seeder) Seed(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Months < 1 || opts.Months > maxMonths {
+		return nil, fmt.Errorf("months must be between 1 and %d, got %d", maxMonths, opts.Months)
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(opts.RandSeed), uint64(opts.RandSeed)))
+	result := &Result{}
+
+	categoryIDs, err := s.createCategories(ctx, opts.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result.Categories = len(categoryIDs)
+
+	now := s.clock.Now()
+	firstMonth := clock.StartOfMonthIn(now, time.UTC).AddDate(0, -(opts.Months - 1), 0)
+
+	for _, tmpl := range accountTemplates {
+		account, err := ledger.NewAccount(opts.UserID, tmpl.name, tmpl.currency, tmpl.includeInBalance, s.ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create account %q: %w", tmpl.name, err)
+		}
+
+		for month := range opts.Months {
+			monthStart := firstMonth.AddDate(0, month, 0)
+			for _, r := range rules {
+				if r.account != tmpl.key {
+					continue
+				}
+
+				added, err := s.applyRule(account, r, monthStart, categoryIDs, rng)
+				if err != nil {
+					return nil, fmt.Errorf("failed to generate %q for account %q: %w", r.category, tmpl.name, err)
+				}
+				result.Transactions += added
+			}
+		}
+
+		// Saving once per account keeps seeding fast even for years of history
+		if err := s.accountRepo.Save(ctx, account); err != nil {
+			return nil, fmt.Errorf("failed to save account %q: %w", tmpl.name, err)
+		}
+		result.Accounts++
+	}
+
+	return result, nil
+}
+
+// applyRule adds the transactions generated by r for the month starting at monthStart
+func (s *Seeder) applyRule(account *ledger.Account, r rule, monthStart time.Time, categoryIDs map[string]uuid.UUID, rng *rand.Rand) (int, error) {
+	today := s.clock.Now()
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	occurrences := r.minPerMonth + rng.IntN(r.maxPerMonth-r.minPerMonth+1)
+
+	categoryID := categoryIDs[r.category]
+	for range occurrences {
+		day := r.day
+		if day == 0 {
+			day = 1 + rng.IntN(daysInMonth)
+		}
+		dueDate := monthStart.AddDate(0, 0, min(day, daysInMonth)-1).Add(time.Duration(8+rng.IntN(12)) * time.Hour)
+
+		var paidAt *time.Time
+		if !dueDate.After(today) {
+			paidAt = &dueDate
+		}
+
+		amount := r.minAmount + rng.Int64N(r.maxAmount-r.minAmount+1)
+		if r.txType == ledger.Expense {
+			amount = -amount
+		}
+
+		err := account.AddTransaction(
+			r.txType,
+			r.descriptions[rng.IntN(len(r.descriptions))],
+			"",
+			money.New(amount, account.Currency),
+			&categoryID,
+			dueDate,
+			paidAt,
+			s.ids,
+			s.clock,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return occurrences, nil
+}
+
+// createCategories creates the category tree owned by the user, returning the IDs by name
+func (s *Seeder) createCategories(ctx context.Context, userID uuid.UUID) (map[string]uuid.UUID, error) {
+	ids, err := s.categories.CreateTree(ctx, userID, categoryTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create categories: %w", err)
+	}
+	return ids, nil
+}
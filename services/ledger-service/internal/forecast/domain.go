@@ -0,0 +1,128 @@
+package forecast
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidHorizon  = errx.New(errx.CategoryValidation, "INVALID_FORECAST_HORIZON", "horizon must be a number followed by d, w, m or y (e.g., 6m), up to 2 years")
+	ErrInvalidInterval = errx.New(errx.CategoryValidation, "INVALID_FORECAST_INTERVAL", "interval must be day or week")
+)
+
+const (
+	Daily  Interval = "day"
+	Weekly Interval = "week"
+
+	// DefaultHorizon is used when the client does not ask for a specific horizon
+	DefaultHorizon = "6m"
+
+	maxHorizonDays = 2 * 366
+)
+
+var horizonPattern = regexp.MustCompile(`^([1-9][0-9]{0,2})([dwmy])$`)
+
+// Interval is the spacing between the points of a forecast
+type Interval string
+
+// ParseInterval validates an interval name, an empty name means Daily
+func ParseInterval(name string) (Interval, error) {
+	switch Interval(name) {
+	case "", Daily:
+		return Daily, nil
+	case Weekly:
+		return Weekly, nil
+	default:
+		return "", ErrInvalidInterval.With("interval", name)
+	}
+}
+
+// Horizon is how far ahead a forecast looks, in calendar units (e.g., 6 months)
+type Horizon struct {
+	years, months, days int
+}
+
+// ParseHorizon parses a horizon such as "45d", "12w", "6m" or "1y"
+func ParseHorizon(value string) (Horizon, error) {
+	match := horizonPattern.FindStringSubmatch(value)
+	if match == nil {
+		return Horizon{}, ErrInvalidHorizon.With("horizon", value)
+	}
+	n, _ := strconv.Atoi(match[1])
+
+	var h Horizon
+	switch match[2] {
+	case "d":
+		h.days = n
+	case "w":
+		h.days = n * 7
+	case "m":
+		h.months = n
+	case "y":
+		h.years = n
+	}
+
+	// Months and years vary in length, so the limit is checked from a fixed reference date
+	reference := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if h.End(reference).Sub(reference) > maxHorizonDays*24*time.Hour {
+		return Horizon{}, ErrInvalidHorizon.With("horizon", value)
+	}
+
+	return h, nil
+}
+
+// End returns the exclusive end of a forecast starting at from
+func (h Horizon) End(from time.Time) time.Time {
+	return from.AddDate(h.years, h.months, h.days)
+}
+
+// Item is an expected cash movement not recorded as a ledger transaction yet (e.g., the next occurrence of a recurring bill)
+type Item struct {
+	AccountID   uuid.UUID
+	Date        time.Time
+	Amount      money.Money // Amount is negative for money leaving the account
+	Description string
+}
+
+// Source provides expected items of a user within [from, to), recurring definitions and installment plans plug in here
+type Source interface {
+	Items(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]Item, error)
+}
+
+// AccountReader loads the accounts a forecast starts from, satisfied by the ledger Service
+type AccountReader interface {
+	FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*ledger.Account, error)
+}
+
+// Point is the projected balance of an account at the end of a day or week
+type Point struct {
+	Date          time.Time   // Date is the first day covered by the point
+	Balance       money.Money // Balance at the end of the last day covered by the point
+	LowestBalance money.Money // LowestBalance among the end-of-day balances covered by the point
+	Negative      bool        // Negative flags points where the balance goes below zero on some day
+}
+
+// AccountForecast is the projected balance series of a single account
+type AccountForecast struct {
+	Account           *ledger.Account
+	StartingBalance   money.Money // StartingBalance is the real balance when the forecast was computed
+	EndingBalance     money.Money
+	LowestBalance     money.Money
+	FirstNegativeDate *time.Time
+	Points            []Point
+}
+
+// Forecast is the projection of every account of a user over a horizon
+type Forecast struct {
+	From     time.Time
+	To       time.Time // To is exclusive
+	Interval Interval
+	Accounts []AccountForecast
+}
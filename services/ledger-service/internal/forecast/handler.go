@@ -0,0 +1,129 @@
+package forecast
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ForecastHandler holds dependencies for forecast-related HTTP handlers
+type ForecastHandler struct {
+	forecastService *Service
+}
+
+// NewForecastHandler creates a new instance of ForecastHandler
+func NewForecastHandler(forecastService *Service) *ForecastHandler {
+	return &ForecastHandler{forecastService: forecastService}
+}
+
+// RegisterRoutes sets up the API routes for the forecast module
+func (h *ForecastHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.GET("/forecast", h.forecastHandler)
+}
+
+// PointResponse defines the structure of a forecast point returned by the API
+type PointResponse struct {
+	Date          time.Time `json:"date"`
+	Balance       int64     `json:"balance"`
+	LowestBalance int64     `json:"lowest_balance"`
+	Negative      bool      `json:"negative"`
+}
+
+// AccountForecastResponse defines the projected balances of an account returned by the API
+// Amounts are expressed in minor units of the account currency
+type AccountForecastResponse struct {
+	AccountID         uuid.UUID          `json:"account_id"`
+	Name              string             `json:"name"`
+	Currency          string             `json:"currency"`
+	Kind              ledger.AccountKind `json:"kind"`
+	StartingBalance   int64              `json:"starting_balance"`
+	EndingBalance     int64              `json:"ending_balance"`
+	LowestBalance     int64              `json:"lowest_balance"`
+	FirstNegativeDate *time.Time         `json:"first_negative_date,omitempty"`
+	Points            []PointResponse    `json:"points"`
+}
+
+// ForecastResponse defines the structure of a forecast returned by the API
+type ForecastResponse struct {
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Interval Interval                  `json:"interval"`
+	Accounts []AccountForecastResponse `json:"accounts"`
+}
+
+// forecastHandler handles the HTTP request for projecting the user's balances (e.g., ?horizon=6m&interval=week)
+func (h *ForecastHandler) forecastHandler(c echo.Context) error {
+	rawHorizon := c.QueryParam("horizon")
+	if rawHorizon == "" {
+		rawHorizon = DefaultHorizon
+	}
+	horizon, err := ParseHorizon(rawHorizon)
+	if err != nil {
+		return err
+	}
+	interval, err := ParseInterval(c.QueryParam("interval"))
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	forecast, err := h.forecastService.Forecast(c.Request().Context(), userID, horizon, interval)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toForecastResponse(forecast))
+}
+
+// currentUserID returns the ID of the authenticated user, set in the request context by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toForecastResponse maps the internal Forecast domain model to the public ForecastResponse DTO
+func toForecastResponse(f *Forecast) ForecastResponse {
+	accounts := make([]AccountForecastResponse, len(f.Accounts))
+	for i, af := range f.Accounts {
+		points := make([]PointResponse, len(af.Points))
+		for j, p := range af.Points {
+			points[j] = PointResponse{
+				Date:          p.Date,
+				Balance:       p.Balance.Amount,
+				LowestBalance: p.LowestBalance.Amount,
+				Negative:      p.Negative,
+			}
+		}
+
+		accounts[i] = AccountForecastResponse{
+			AccountID:         af.Account.ID,
+			Name:              af.Account.Name,
+			Currency:          af.Account.Currency,
+			Kind:              af.Account.Kind,
+			StartingBalance:   af.StartingBalance.Amount,
+			EndingBalance:     af.EndingBalance.Amount,
+			LowestBalance:     af.LowestBalance.Amount,
+			FirstNegativeDate: af.FirstNegativeDate,
+			Points:            points,
+		}
+	}
+
+	return ForecastResponse{
+		From:     f.From,
+		To:       f.To,
+		Interval: f.Interval,
+		Accounts: accounts,
+	}
+}
@@ -0,0 +1,30 @@
+package forecast
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the forecast service and handler from the shared dependencies
+type Module struct {
+	handler *ForecastHandler
+}
+
+// NewModule creates the forecast module, projecting the accounts read from the ledger plus the items of the sources
+func NewModule(deps module.Deps, accounts AccountReader, sources ...Source) *Module {
+	return &Module{
+		handler: NewForecastHandler(NewService(accounts, deps.Clock, sources...)),
+	}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "forecast"
+}
+
+// RegisterRoutes mounts the forecast routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,166 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// Service projects the balance of the user's accounts from what is already known to come
+type Service struct {
+	accounts AccountReader
+	sources  []Source
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the forecast Service, the sources add items beyond the unpaid transactions
+func NewService(accounts AccountReader, clock clock.Clock, sources ...Source) *Service {
+	return &Service{
+		accounts: accounts,
+		sources:  sources,
+		clock:    clock,
+	}
+}
+
+// Forecast is the use case for projecting the balance of every active account of a user
+// Each account starts from its real balance, then unpaid transactions are applied on their due date
+// (overdue ones today), paid transactions dated in the future on their payment date, and the source items on their date
+func (s *Service) Forecast(ctx context.Context, userID uuid.UUID, horizon Horizon, interval Interval) (*Forecast, error) {
+	now := s.clock.Now()
+	from := clock.StartOfDayIn(now, time.UTC)
+	to := horizon.End(from)
+
+	accounts, err := s.accounts.FindAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounts to forecast: %w", err)
+	}
+
+	itemsByAccount := make(map[uuid.UUID][]Item)
+	for _, source := range s.sources {
+		items, err := source.Items(ctx, userID, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load forecast items: %w", err)
+		}
+		for _, item := range items {
+			itemsByAccount[item.AccountID] = append(itemsByAccount[item.AccountID], item)
+		}
+	}
+
+	forecast := &Forecast{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Accounts: make([]AccountForecast, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		accountForecast, err := s.project(account, itemsByAccount[account.ID], from, to, interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to forecast account %s: %w", account.ID, err)
+		}
+		forecast.Accounts = append(forecast.Accounts, accountForecast)
+	}
+
+	return forecast, nil
+}
+
+// project walks the account balance day by day, grouping the days into points of the interval
+func (s *Service) project(account *ledger.Account, items []Item, from, to time.Time, interval Interval) (AccountForecast, error) {
+	now := s.clock.Now()
+
+	balance, err := account.RealBalance(s.clock)
+	if err != nil {
+		return AccountForecast{}, err
+	}
+
+	changes := make(map[time.Time]money.Money)
+	apply := func(date time.Time, amount money.Money) error {
+		day := clock.StartOfDayIn(date, time.UTC)
+		if day.Before(from) {
+			day = from
+		}
+		if !day.Before(to) {
+			return nil
+		}
+		current, ok := changes[day]
+		if !ok {
+			current = money.Zero(account.Currency)
+		}
+		next, err := current.Add(amount)
+		if err != nil {
+			return err
+		}
+		changes[day] = next
+		return nil
+	}
+
+	for _, tx := range account.Transactions() {
+		switch {
+		case tx.PaidAt == nil:
+			err = apply(tx.DueDate, tx.Amount)
+		case tx.PaidAt.After(now):
+			err = apply(*tx.PaidAt, tx.Amount)
+		}
+		if err != nil {
+			return AccountForecast{}, err
+		}
+	}
+	for _, item := range items {
+		if err := apply(item.Date, item.Amount); err != nil {
+			return AccountForecast{}, err
+		}
+	}
+
+	// A credit card balance is the debt on the card, so it is expected to stay below zero
+	flagNegative := account.Kind != ledger.CreditCard
+
+	result := AccountForecast{
+		Account:         account,
+		StartingBalance: balance,
+		LowestBalance:   balance,
+		Points:          make([]Point, 0),
+	}
+	var point *Point
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if change, ok := changes[day]; ok {
+			if balance, err = balance.Add(change); err != nil {
+				return AccountForecast{}, err
+			}
+		}
+
+		if point == nil || startsPoint(day, interval) {
+			result.Points = append(result.Points, Point{Date: day, LowestBalance: balance})
+			point = &result.Points[len(result.Points)-1]
+		}
+		point.Balance = balance
+		if balance.Amount < point.LowestBalance.Amount {
+			point.LowestBalance = balance
+		}
+		if balance.Amount < result.LowestBalance.Amount {
+			result.LowestBalance = balance
+		}
+
+		if flagNegative && balance.IsNegative() {
+			point.Negative = true
+			if result.FirstNegativeDate == nil {
+				negativeDay := day
+				result.FirstNegativeDate = &negativeDay
+			}
+		}
+	}
+	result.EndingBalance = balance
+
+	return result, nil
+}
+
+// startsPoint tells whether a new point begins on day, weeks start on Monday
+func startsPoint(day time.Time, interval Interval) bool {
+	if interval == Weekly {
+		return day.Weekday() == time.Monday
+	}
+	return true
+}
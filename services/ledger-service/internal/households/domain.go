@@ -0,0 +1,538 @@
+package households
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrHouseholdNotFound    = errx.New(errx.CategoryNotFound, "HOUSEHOLD_NOT_FOUND", "household not found")
+	ErrMemberNotFound       = errx.New(errx.CategoryNotFound, "HOUSEHOLD_MEMBER_NOT_FOUND", "household member not found")
+	ErrUserNotFound         = errx.New(errx.CategoryNotFound, "USER_NOT_FOUND", "no user is registered with this email")
+	ErrAccountNotFound      = errx.New(errx.CategoryNotFound, "ACCOUNT_NOT_FOUND", "account not found")
+	ErrBudgetNotFound       = errx.New(errx.CategoryNotFound, "BUDGET_NOT_FOUND", "budget not found")
+	ErrInvalidHouseholdName = errx.New(errx.CategoryValidation, "INVALID_HOUSEHOLD_NAME", "the household name must have between 1 and 60 characters")
+	ErrInvalidPermission    = errx.New(errx.CategoryValidation, "INVALID_HOUSEHOLD_PERMISSION", "unknown household permission")
+	ErrAlreadyMember        = errx.New(errx.CategoryConflict, "ALREADY_HOUSEHOLD_MEMBER", "the user is already a member of the household or invited to it")
+	ErrNotInvited           = errx.New(errx.CategoryConflict, "NOT_INVITED_TO_HOUSEHOLD", "the user has no pending invitation to the household")
+	ErrLastAdmin            = errx.New(errx.CategoryConflict, "LAST_HOUSEHOLD_ADMIN", "the household must keep an admin, promote another member or delete the household")
+	ErrAlreadyShared        = errx.New(errx.CategoryConflict, "ALREADY_SHARED_WITH_HOUSEHOLD", "already shared with the household")
+	ErrTooManyMembers       = errx.New(errx.CategoryConflict, "TOO_MANY_HOUSEHOLD_MEMBERS", "the maximum number of household members was reached")
+	ErrTooManyHouseholds    = errx.New(errx.CategoryConflict, "TOO_MANY_HOUSEHOLDS", "the maximum number of households was reached")
+	ErrHouseholdAdminOnly   = errx.New(errx.CategoryForbidden, "HOUSEHOLD_ADMIN_ONLY", "only the admins of the household can do this")
+	ErrHouseholdPermission  = errx.New(errx.CategoryForbidden, "HOUSEHOLD_PERMISSION_REQUIRED", "the member is not allowed to see this in the household")
+	ErrNotSharedByMember    = errx.New(errx.CategoryForbidden, "NOT_SHARED_BY_MEMBER", "only the member who shared it or an admin can stop sharing it")
+	ErrProposalNotFound     = errx.New(errx.CategoryNotFound, "TRANSACTION_PROPOSAL_NOT_FOUND", "transaction proposal not found")
+	ErrInvalidProposalType  = errx.New(errx.CategoryValidation, "INVALID_PROPOSAL_TYPE", "only incomes and expenses can be proposed")
+	ErrProposalOnOwnAccount = errx.New(errx.CategoryValidation, "PROPOSAL_ON_OWN_ACCOUNT", "add the transaction to your own account directly, proposals are for the accounts of other members")
+	ErrProposalNotPending   = errx.New(errx.CategoryConflict, "TRANSACTION_PROPOSAL_NOT_PENDING", "the transaction proposal was already resolved")
+	ErrNotAccountOwner      = errx.New(errx.CategoryForbidden, "NOT_SHARED_ACCOUNT_OWNER", "only the owner of the account can approve or reject the transactions proposed on it")
+	ErrNotProposer          = errx.New(errx.CategoryForbidden, "NOT_TRANSACTION_PROPOSER", "only the member who proposed the transaction can cancel it")
+)
+
+const (
+	// MaxMembersPerHousehold bounds the members and pending invitations of a household
+	MaxMembersPerHousehold = 10
+	// MaxHouseholdsPerUser bounds the households a user belongs to or is invited to
+	MaxHouseholdsPerUser = 5
+
+	maxNameLength = 60
+)
+
+// Role is the role of a member in a household
+type Role string
+
+const (
+	RoleAdmin  Role = "ADMIN"  // RoleAdmin manages the household and its members, and sees everything shared in it
+	RoleMember Role = "MEMBER" // RoleMember sees what their permissions allow
+)
+
+// Values returns every role, used to validate enum fields
+func (Role) Values() []string {
+	return []string{string(RoleAdmin), string(RoleMember)}
+}
+
+// Permission lets a member see a kind of data shared in the household, or propose transactions on the shared accounts
+type Permission string
+
+const (
+	PermissionAccounts Permission = "ACCOUNTS"             // PermissionAccounts shows the shared accounts with their transactions
+	PermissionBudgets  Permission = "BUDGETS"              // PermissionBudgets shows the shared budgets with their spending
+	PermissionReports  Permission = "REPORTS"              // PermissionReports shows the monthly report of the shared accounts
+	PermissionPropose  Permission = "PROPOSE_TRANSACTIONS" // PermissionPropose lets the member propose transactions on the shared accounts
+)
+
+// Values returns every permission, used to validate enum fields
+func (Permission) Values() []string {
+	return []string{string(PermissionAccounts), string(PermissionBudgets), string(PermissionReports), string(PermissionPropose)}
+}
+
+// ProposalStatus is the state of a transaction proposal
+type ProposalStatus string
+
+const (
+	ProposalPending   ProposalStatus = "PENDING"   // ProposalPending waits for the owner of the account to approve or reject it
+	ProposalApproved  ProposalStatus = "APPROVED"  // ProposalApproved means the transaction was added to the account
+	ProposalRejected  ProposalStatus = "REJECTED"  // ProposalRejected means the owner refused the transaction
+	ProposalCancelled ProposalStatus = "CANCELLED" // ProposalCancelled means the member withdrew the proposal
+)
+
+// Values returns every proposal status, used to validate enum fields
+func (ProposalStatus) Values() []string {
+	return []string{string(ProposalPending), string(ProposalApproved), string(ProposalRejected), string(ProposalCancelled)}
+}
+
+// Household groups users sharing some of their accounts and budgets, each member seeing what their permissions allow
+// Sharing only grants visibility: the shared accounts and budgets are still changed by their owner alone,
+// the members allowed to propose transactions waiting for the owner to approve them
+type Household struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	Members   []Member
+	Accounts  []SharedAccount
+	Budgets   []SharedBudget
+}
+
+// Member is a user of the household, or a user invited to it until they join
+type Member struct {
+	UserID      uuid.UUID
+	Name        string // Name and Email are read along the member, so the other members know who they are
+	Email       string
+	Role        Role
+	Permissions []Permission
+	InvitedBy   *uuid.UUID // InvitedBy is nil for the creator of the household
+	InvitedAt   time.Time
+	JoinedAt    *time.Time // JoinedAt is nil while the invitation is pending
+}
+
+// SharedAccount is an account a member shared with the household
+type SharedAccount struct {
+	AccountID uuid.UUID
+	SharedBy  uuid.UUID
+	SharedAt  time.Time
+}
+
+// SharedBudget is a budget a member shared with the household
+type SharedBudget struct {
+	BudgetID uuid.UUID
+	SharedBy uuid.UUID
+	SharedAt time.Time
+}
+
+// NewHousehold creates a household whose creator is its first admin
+func NewHousehold(name string, creatorID uuid.UUID, ids idgen.Generator, clock clock.Clock) (*Household, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+	return &Household{
+		ID:        ids.NewID(),
+		Name:      name,
+		CreatedAt: now,
+		Members: []Member{{
+			UserID:    creatorID,
+			Role:      RoleAdmin,
+			InvitedAt: now,
+			JoinedAt:  &now,
+		}},
+	}, nil
+}
+
+// Rename changes the name of the household, admins only
+func (h *Household) Rename(actorID uuid.UUID, name string) error {
+	if err := h.requireAdmin(actorID); err != nil {
+		return err
+	}
+	name, err := validateName(name)
+	if err != nil {
+		return err
+	}
+
+	h.Name = name
+	return nil
+}
+
+// Invite adds a pending member, who becomes a member by joining, admins only
+func (h *Household) Invite(actorID, userID uuid.UUID, role Role, permissions []Permission, clock clock.Clock) error {
+	if err := h.requireAdmin(actorID); err != nil {
+		return err
+	}
+	if h.member(userID) != nil {
+		return ErrAlreadyMember.With("user_id", userID)
+	}
+	if len(h.Members) >= MaxMembersPerHousehold {
+		return ErrTooManyMembers.With("max_members", MaxMembersPerHousehold)
+	}
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return err
+	}
+
+	h.Members = append(h.Members, Member{
+		UserID:      userID,
+		Role:        role,
+		Permissions: permissions,
+		InvitedBy:   &actorID,
+		InvitedAt:   clock.Now(),
+	})
+	return nil
+}
+
+// Join accepts the pending invitation of the user
+func (h *Household) Join(userID uuid.UUID, clock clock.Clock) error {
+	m := h.member(userID)
+	if m == nil || m.JoinedAt != nil {
+		return ErrNotInvited.With("household_id", h.ID)
+	}
+
+	now := clock.Now()
+	m.JoinedAt = &now
+	return nil
+}
+
+// ChangeMember changes the role and the permissions of a member, admins only
+func (h *Household) ChangeMember(actorID, userID uuid.UUID, role Role, permissions []Permission) error {
+	if err := h.requireAdmin(actorID); err != nil {
+		return err
+	}
+	m := h.member(userID)
+	if m == nil {
+		return ErrMemberNotFound.With("user_id", userID)
+	}
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return err
+	}
+	if m.Role == RoleAdmin && role != RoleAdmin && h.activeAdmins() == 1 && m.JoinedAt != nil {
+		return ErrLastAdmin
+	}
+
+	m.Role = role
+	m.Permissions = permissions
+	return nil
+}
+
+// RemoveMember removes a member, or declines an invitation, along with what the member shared
+// Admins remove any member, other members only remove themselves
+func (h *Household) RemoveMember(actorID, userID uuid.UUID) error {
+	if actorID != userID {
+		if err := h.requireAdmin(actorID); err != nil {
+			return err
+		}
+	}
+	m := h.member(userID)
+	if m == nil {
+		return ErrMemberNotFound.With("user_id", userID)
+	}
+	if m.Role == RoleAdmin && m.JoinedAt != nil && h.activeAdmins() == 1 {
+		return ErrLastAdmin
+	}
+
+	h.Members = slices.DeleteFunc(h.Members, func(m Member) bool { return m.UserID == userID })
+	h.Accounts = slices.DeleteFunc(h.Accounts, func(a SharedAccount) bool { return a.SharedBy == userID })
+	h.Budgets = slices.DeleteFunc(h.Budgets, func(b SharedBudget) bool { return b.SharedBy == userID })
+	return nil
+}
+
+// ShareAccount shares an account of the member with the household, the caller checks the member owns it
+func (h *Household) ShareAccount(actorID, accountID uuid.UUID, clock clock.Clock) error {
+	if err := h.requireActive(actorID); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(h.Accounts, func(a SharedAccount) bool { return a.AccountID == accountID }) {
+		return ErrAlreadyShared.With("account_id", accountID)
+	}
+
+	h.Accounts = append(h.Accounts, SharedAccount{AccountID: accountID, SharedBy: actorID, SharedAt: clock.Now()})
+	return nil
+}
+
+// UnshareAccount stops sharing an account, by the member who shared it or an admin
+func (h *Household) UnshareAccount(actorID, accountID uuid.UUID) error {
+	i := slices.IndexFunc(h.Accounts, func(a SharedAccount) bool { return a.AccountID == accountID })
+	if i < 0 {
+		return ErrAccountNotFound.With("account_id", accountID)
+	}
+	if err := h.requireSharerOrAdmin(actorID, h.Accounts[i].SharedBy); err != nil {
+		return err
+	}
+
+	h.Accounts = slices.Delete(h.Accounts, i, i+1)
+	return nil
+}
+
+// ShareBudget shares a budget of the member with the household, the caller checks the member owns it
+func (h *Household) ShareBudget(actorID, budgetID uuid.UUID, clock clock.Clock) error {
+	if err := h.requireActive(actorID); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(h.Budgets, func(b SharedBudget) bool { return b.BudgetID == budgetID }) {
+		return ErrAlreadyShared.With("budget_id", budgetID)
+	}
+
+	h.Budgets = append(h.Budgets, SharedBudget{BudgetID: budgetID, SharedBy: actorID, SharedAt: clock.Now()})
+	return nil
+}
+
+// UnshareBudget stops sharing a budget, by the member who shared it or an admin
+func (h *Household) UnshareBudget(actorID, budgetID uuid.UUID) error {
+	i := slices.IndexFunc(h.Budgets, func(b SharedBudget) bool { return b.BudgetID == budgetID })
+	if i < 0 {
+		return ErrBudgetNotFound.With("budget_id", budgetID)
+	}
+	if err := h.requireSharerOrAdmin(actorID, h.Budgets[i].SharedBy); err != nil {
+		return err
+	}
+
+	h.Budgets = slices.Delete(h.Budgets, i, i+1)
+	return nil
+}
+
+// Propose creates the pending proposal of a transaction on a shared account, by a member allowed to propose them
+// The amount is in the currency of the account, negative for expenses as in the ledger
+func (h *Household) Propose(actorID, accountID uuid.UUID, txType ledger.TransactionType, description string, amount money.Money, dueDate time.Time, ids idgen.Generator, clock clock.Clock) (*Proposal, error) {
+	if err := h.Allowed(actorID, PermissionPropose); err != nil {
+		return nil, err
+	}
+	shared := h.sharedAccount(accountID)
+	if shared == nil {
+		return nil, ErrAccountNotFound.With("account_id", accountID)
+	}
+	if shared.SharedBy == actorID {
+		return nil, ErrProposalOnOwnAccount
+	}
+
+	switch {
+	case txType != ledger.Income && txType != ledger.Expense:
+		return nil, ErrInvalidProposalType.With("type", txType)
+	case amount.IsZero():
+		return nil, ledger.ErrAmountCannotBeZero
+	case (txType == ledger.Income) == amount.IsNegative():
+		return nil, ledger.ErrInconsistentAmountSign
+	}
+
+	return &Proposal{
+		ID:          ids.NewID(),
+		HouseholdID: h.ID,
+		AccountID:   accountID,
+		OwnerID:     shared.SharedBy,
+		ProposedBy:  actorID,
+		Type:        txType,
+		Description: strings.TrimSpace(description),
+		Amount:      amount,
+		DueDate:     dueDate,
+		Status:      ProposalPending,
+		CreatedAt:   clock.Now(),
+	}, nil
+}
+
+// Visible tells whether the user is an active member of the household, pending invitations seeing nothing but the name
+func (h *Household) Visible(userID uuid.UUID) bool {
+	m := h.member(userID)
+	return m != nil && m.JoinedAt != nil
+}
+
+// Allowed checks the user is an active member allowed to see the kind of data, admins seeing everything
+func (h *Household) Allowed(userID uuid.UUID, permission Permission) error {
+	if err := h.requireActive(userID); err != nil {
+		return err
+	}
+	m := h.member(userID)
+	if m.Role != RoleAdmin && !slices.Contains(m.Permissions, permission) {
+		return ErrHouseholdPermission.With("permission", permission)
+	}
+	return nil
+}
+
+// Member returns a copy of the member or invited user, nil when the user is neither
+func (h *Household) Member(userID uuid.UUID) *Member {
+	m := h.member(userID)
+	if m == nil {
+		return nil
+	}
+	memberCopy := *m
+	return &memberCopy
+}
+
+// requireAdmin checks the user is an active admin of the household
+func (h *Household) requireAdmin(userID uuid.UUID) error {
+	if err := h.requireActive(userID); err != nil {
+		return err
+	}
+	if h.member(userID).Role != RoleAdmin {
+		return ErrHouseholdAdminOnly
+	}
+	return nil
+}
+
+// requireActive reports the households the user did not join as not found
+func (h *Household) requireActive(userID uuid.UUID) error {
+	if !h.Visible(userID) {
+		return ErrHouseholdNotFound.With("household_id", h.ID)
+	}
+	return nil
+}
+
+// requireSharerOrAdmin checks the user shared the item or is an admin of the household
+func (h *Household) requireSharerOrAdmin(userID, sharedBy uuid.UUID) error {
+	if userID == sharedBy {
+		return h.requireActive(userID)
+	}
+	if err := h.requireAdmin(userID); err != nil {
+		if errors.Is(err, ErrHouseholdAdminOnly) {
+			return ErrNotSharedByMember
+		}
+		return err
+	}
+	return nil
+}
+
+// sharedAccount returns the account shared with the household, nil when it is not shared
+func (h *Household) sharedAccount(accountID uuid.UUID) *SharedAccount {
+	for i := range h.Accounts {
+		if h.Accounts[i].AccountID == accountID {
+			return &h.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// member returns the member or invited user, nil when the user is neither
+func (h *Household) member(userID uuid.UUID) *Member {
+	for i := range h.Members {
+		if h.Members[i].UserID == userID {
+			return &h.Members[i]
+		}
+	}
+	return nil
+}
+
+// activeAdmins counts the admins who joined the household
+func (h *Household) activeAdmins() int {
+	count := 0
+	for _, m := range h.Members {
+		if m.Role == RoleAdmin && m.JoinedAt != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// validateName trims a household name and checks its length
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if length := utf8.RuneCountInString(name); length == 0 || length > maxNameLength {
+		return "", ErrInvalidHouseholdName.With("max_length", maxNameLength)
+	}
+	return name, nil
+}
+
+// normalizePermissions checks the permissions and removes the duplicates, keeping them sorted
+func normalizePermissions(permissions []Permission) ([]Permission, error) {
+	normalized := make([]Permission, 0, len(permissions))
+	for _, p := range permissions {
+		if !slices.Contains(Permission("").Values(), string(p)) {
+			return nil, ErrInvalidPermission.With("permission", p)
+		}
+		if !slices.Contains(normalized, p) {
+			normalized = append(normalized, p)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// Proposal is a transaction a member proposes on an account shared with the household by another member,
+// added to the account as a pending transaction once its owner approves it
+type Proposal struct {
+	ID              uuid.UUID
+	HouseholdID     uuid.UUID
+	AccountID       uuid.UUID
+	AccountName     string    // AccountName is read along the proposal, so both members know where it goes
+	OwnerID         uuid.UUID // OwnerID is the member who shared the account, the only one deciding on the proposal
+	ProposedBy      uuid.UUID
+	Type            ledger.TransactionType
+	Description     string
+	Amount          money.Money
+	DueDate         time.Time
+	Status          ProposalStatus
+	RejectionReason string
+	CreatedAt       time.Time
+	ResolvedAt      *time.Time
+}
+
+// Approve marks the proposal as approved by the owner of the account, the caller adding the transaction to it
+func (p *Proposal) Approve(userID uuid.UUID, clock clock.Clock) error {
+	if userID != p.OwnerID {
+		return ErrNotAccountOwner
+	}
+	return p.resolve(ProposalApproved, clock)
+}
+
+// Reject marks the proposal as refused by the owner of the account, the reason being shown to the member who proposed it
+func (p *Proposal) Reject(userID uuid.UUID, reason string, clock clock.Clock) error {
+	if userID != p.OwnerID {
+		return ErrNotAccountOwner
+	}
+	if err := p.resolve(ProposalRejected, clock); err != nil {
+		return err
+	}
+
+	p.RejectionReason = strings.TrimSpace(reason)
+	return nil
+}
+
+// Cancel marks the proposal as withdrawn by the member who proposed it
+func (p *Proposal) Cancel(userID uuid.UUID, clock clock.Clock) error {
+	if userID != p.ProposedBy {
+		return ErrNotProposer
+	}
+	return p.resolve(ProposalCancelled, clock)
+}
+
+// resolve moves a pending proposal to its final status
+func (p *Proposal) resolve(status ProposalStatus, clock clock.Clock) error {
+	if p.Status != ProposalPending {
+		return ErrProposalNotPending.With("status", p.Status)
+	}
+
+	now := clock.Now()
+	p.Status = status
+	p.ResolvedAt = &now
+	return nil
+}
+
+// Repository persists the households with their members and what they share
+type Repository interface {
+	// Save writes the household and replaces its members, shared accounts and shared budgets
+	Save(ctx context.Context, household *Household) error
+	FindByID(ctx context.Context, householdID uuid.UUID) (*Household, error)
+	// FindByUserID returns the households the user joined or is invited to, ordered by name
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Household, error)
+	Delete(ctx context.Context, householdID uuid.UUID) error
+	// FindUserIDByEmail finds the user invited to a household by the email they registered with
+	FindUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error)
+	SaveProposal(ctx context.Context, proposal *Proposal) error
+	// ResolveProposal saves the final status of the proposal unless another request resolved it first, failing with
+	// ErrProposalNotPending then, so a proposal is resolved once however many requests race on it
+	ResolveProposal(ctx context.Context, proposal *Proposal) error
+	// ReopenProposal moves an approved proposal back to pending, when its transaction could not be added
+	ReopenProposal(ctx context.Context, proposalID uuid.UUID) error
+	FindProposal(ctx context.Context, proposalID uuid.UUID) (*Proposal, error)
+	// FindProposals returns the proposals of the household the user made or decides on with the status, the most recent first
+	FindProposals(ctx context.Context, householdID, userID uuid.UUID, status ProposalStatus) ([]*Proposal, error)
+}
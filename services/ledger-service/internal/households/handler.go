@@ -0,0 +1,832 @@
+package households
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/validatorx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// HouseholdHandler holds dependencies for household HTTP handlers
+type HouseholdHandler struct {
+	householdService *Service
+	clock            clock.Clock
+}
+
+// NewHouseholdHandler creates a new instance of HouseholdHandler, clock computes the real balances of the shared accounts
+func NewHouseholdHandler(householdService *Service, clock clock.Clock) *HouseholdHandler {
+	return &HouseholdHandler{householdService: householdService, clock: clock}
+}
+
+// RegisterRoutes sets up the API routes for the households module
+func (h *HouseholdHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	householdsGroup := apiRouteGroup.Group("/households")
+
+	householdsGroup.POST("", h.createHouseholdHandler)
+	householdsGroup.GET("", h.listHouseholdsHandler)
+	householdsGroup.GET("/:id", h.getHouseholdHandler)
+	householdsGroup.PUT("/:id", h.renameHouseholdHandler)
+	householdsGroup.DELETE("/:id", h.deleteHouseholdHandler)
+
+	householdsGroup.POST("/:id/members", h.inviteMemberHandler)
+	householdsGroup.POST("/:id/join", h.joinHouseholdHandler)
+	householdsGroup.PUT("/:id/members/:userId", h.changeMemberHandler)
+	householdsGroup.DELETE("/:id/members/:userId", h.removeMemberHandler)
+
+	householdsGroup.GET("/:id/accounts", h.listAccountsHandler)
+	householdsGroup.GET("/:id/accounts/:accountId", h.getAccountHandler)
+	householdsGroup.POST("/:id/accounts", h.shareAccountHandler)
+	householdsGroup.DELETE("/:id/accounts/:accountId", h.unshareHandler("accountId", "invalid account id format", h.householdService.UnshareAccount))
+
+	householdsGroup.GET("/:id/budgets", h.listBudgetsHandler)
+	householdsGroup.POST("/:id/budgets", h.shareBudgetHandler)
+	householdsGroup.DELETE("/:id/budgets/:budgetId", h.unshareHandler("budgetId", "invalid budget id format", h.householdService.UnshareBudget))
+
+	householdsGroup.GET("/:id/report", h.reportHandler)
+
+	householdsGroup.POST("/:id/accounts/:accountId/proposals", h.proposeTransactionHandler)
+	householdsGroup.GET("/:id/proposals", h.listProposalsHandler)
+	householdsGroup.POST("/:id/proposals/:proposalId/approve", h.resolveProposalHandler(h.householdService.ApproveProposal))
+	householdsGroup.POST("/:id/proposals/:proposalId/reject", h.rejectProposalHandler)
+	householdsGroup.POST("/:id/proposals/:proposalId/cancel", h.resolveProposalHandler(h.householdService.CancelProposal))
+}
+
+// HouseholdRequest defines the expected JSON body for creating or renaming a household
+type HouseholdRequest struct {
+	Name string `json:"name" validate:"required,max=60"`
+}
+
+// InviteMemberRequest defines the expected JSON body for inviting a user to a household
+type InviteMemberRequest struct {
+	Email       string       `json:"email" validate:"required,email,max=255"`
+	Role        Role         `json:"role" validate:"required,enum"`
+	Permissions []Permission `json:"permissions" validate:"dive,enum"` // Permissions are ignored for admins, who see everything
+}
+
+// ChangeMemberRequest defines the expected JSON body for changing the role and the permissions of a member
+type ChangeMemberRequest struct {
+	Role        Role         `json:"role" validate:"required,enum"`
+	Permissions []Permission `json:"permissions" validate:"dive,enum"`
+}
+
+// ShareAccountRequest defines the expected JSON body for sharing an account with a household
+type ShareAccountRequest struct {
+	AccountID uuid.UUID `json:"account_id" validate:"required"`
+}
+
+// ShareBudgetRequest defines the expected JSON body for sharing a budget with a household
+type ShareBudgetRequest struct {
+	BudgetID uuid.UUID `json:"budget_id" validate:"required"`
+}
+
+// ProposeTransactionRequest defines the expected JSON body for proposing a transaction on a shared account
+// Version 1 bodies send the amount as a number of minor units (e.g., -123456), see BindVersion
+type ProposeTransactionRequest struct {
+	Type        ledger.TransactionType `json:"type" validate:"required,oneof=INCOME EXPENSE"`
+	Description string                 `json:"description" validate:"required,min=1,max=100"`
+	Amount      string                 `json:"amount" validate:"max=32"` // Decimal string in the account currency, negative for expenses
+	DueDate     time.Time              `json:"due_date" validate:"required"`
+
+	amountMinorUnits *int64 // amountMinorUnits is the amount of a version 1 body
+}
+
+// BindVersion decodes the version 1 bodies, whose amount is in minor units of the account currency
+func (r *ProposeTransactionRequest) BindVersion(_ int, decode func(any) error) error {
+	var v1 struct {
+		Type        ledger.TransactionType `json:"type"`
+		Description string                 `json:"description"`
+		Amount      *int64                 `json:"amount"`
+		DueDate     time.Time              `json:"due_date"`
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+	*r = ProposeTransactionRequest{Type: v1.Type, Description: v1.Description, DueDate: v1.DueDate, amountMinorUnits: v1.Amount}
+	return nil
+}
+
+// ValidateStruct applies the ProposeTransactionRequest rules spanning both versions of the body
+func (r ProposeTransactionRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	if r.amountMinorUnits == nil && r.Amount == "" {
+		sl.ReportError("required", "", "amount")
+	}
+}
+
+// RejectProposalRequest defines the expected JSON body for rejecting a transaction proposal
+type RejectProposalRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// HouseholdResponse defines the structure of a household returned by the API
+// Members and shared items are left out of the households the user is only invited to
+type HouseholdResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	Name      string               `json:"name"`
+	Role      Role                 `json:"role"`   // Role is the role of the user in the household
+	Joined    bool                 `json:"joined"` // Joined is false while the invitation of the user is pending
+	CreatedAt time.Time            `json:"created_at"`
+	Members   []MemberResponse     `json:"members,omitempty"`
+	Accounts  []SharedItemResponse `json:"accounts,omitempty"`
+	Budgets   []SharedItemResponse `json:"budgets,omitempty"`
+}
+
+// MemberResponse defines the structure of a household member returned by the API
+type MemberResponse struct {
+	UserID      uuid.UUID    `json:"user_id"`
+	Name        string       `json:"name"`
+	Email       string       `json:"email"`
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+	InvitedAt   time.Time    `json:"invited_at"`
+	JoinedAt    *time.Time   `json:"joined_at,omitempty"`
+}
+
+// SharedItemResponse defines the structure of an account or a budget shared with a household
+type SharedItemResponse struct {
+	ID       uuid.UUID `json:"id"`
+	SharedBy uuid.UUID `json:"shared_by"`
+	SharedAt time.Time `json:"shared_at"`
+}
+
+// HouseholdAccountResponse defines the structure of a shared account returned by the API
+// The observations and the categories of the transactions stay private to the owner of the account
+type HouseholdAccountResponse struct {
+	ID               uuid.UUID                      `json:"id"`
+	Name             string                         `json:"name"`
+	Currency         string                         `json:"currency"`
+	Kind             ledger.AccountKind             `json:"kind"`
+	SharedBy         uuid.UUID                      `json:"shared_by"`
+	RealBalance      int64                          `json:"real_balance"`
+	ProjectedBalance int64                          `json:"projected_balance"`
+	Transactions     []HouseholdTransactionResponse `json:"transactions,omitempty"`
+}
+
+// HouseholdTransactionResponse defines the structure of a transaction of a shared account returned by the API
+type HouseholdTransactionResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	Type        ledger.TransactionType `json:"type"`
+	Description string                 `json:"description"`
+	Amount      int64                  `json:"amount"`
+	DueDate     time.Time              `json:"due_date"`
+	PaidAt      *time.Time             `json:"paid_at,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+}
+
+// HouseholdBudgetResponse defines the structure of a shared budget returned by the API
+type HouseholdBudgetResponse struct {
+	ID           uuid.UUID `json:"id"`
+	CategoryName string    `json:"category_name"`
+	Currency     string    `json:"currency"`
+	Amount       int64     `json:"amount"` // Amount in minor units of the currency
+	Spent        int64     `json:"spent"`  // Spent in the current financial month of the owner, in minor units of the currency
+	SharedBy     uuid.UUID `json:"shared_by"`
+}
+
+// ProposalResponse defines the structure of a transaction proposal returned by the API
+type ProposalResponse struct {
+	ID              uuid.UUID              `json:"id"`
+	AccountID       uuid.UUID              `json:"account_id"`
+	AccountName     string                 `json:"account_name"`
+	OwnerID         uuid.UUID              `json:"owner_id"`
+	ProposedBy      uuid.UUID              `json:"proposed_by"`
+	Type            ledger.TransactionType `json:"type"`
+	Description     string                 `json:"description"`
+	Currency        string                 `json:"currency"`
+	Amount          int64                  `json:"amount"` // Amount in minor units of the currency
+	DueDate         time.Time              `json:"due_date"`
+	Status          ProposalStatus         `json:"status"`
+	RejectionReason string                 `json:"rejection_reason,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	ResolvedAt      *time.Time             `json:"resolved_at,omitempty"`
+}
+
+// ReportResponse defines the structure of the monthly report of a household returned by the API
+type ReportResponse struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Accounts []FlowResponse `json:"accounts"`
+	Totals   []FlowResponse `json:"totals"` // Totals has one entry per currency
+}
+
+// FlowResponse defines the structure of what entered and left an account, or all the accounts of a currency
+type FlowResponse struct {
+	AccountID *uuid.UUID `json:"account_id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	SharedBy  *uuid.UUID `json:"shared_by,omitempty"`
+	Currency  string     `json:"currency"`
+	Income    int64      `json:"income"`
+	Expense   int64      `json:"expense"`
+}
+
+// createHouseholdHandler handles the HTTP request for creating a household
+func (h *HouseholdHandler) createHouseholdHandler(c echo.Context) error {
+	var req HouseholdRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	household, err := h.householdService.CreateHousehold(c.Request().Context(), userID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toHouseholdResponse(household, userID))
+}
+
+// listHouseholdsHandler handles the HTTP request for listing the households the user joined or is invited to
+func (h *HouseholdHandler) listHouseholdsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	households, err := h.householdService.FindHouseholds(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]HouseholdResponse, 0, len(households))
+	for _, household := range households {
+		resp = append(resp, toHouseholdResponse(household, userID))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// getHouseholdHandler handles the HTTP request for reading a household with its members and shared items
+func (h *HouseholdHandler) getHouseholdHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	household, err := h.householdService.FindHousehold(c.Request().Context(), userID, householdID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toHouseholdResponse(household, userID))
+}
+
+// renameHouseholdHandler handles the HTTP request for renaming a household
+func (h *HouseholdHandler) renameHouseholdHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req HouseholdRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	household, err := h.householdService.RenameHousehold(c.Request().Context(), userID, householdID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toHouseholdResponse(household, userID))
+}
+
+// deleteHouseholdHandler handles the HTTP request for deleting a household
+func (h *HouseholdHandler) deleteHouseholdHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.householdService.DeleteHousehold(c.Request().Context(), userID, householdID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// inviteMemberHandler handles the HTTP request for inviting a user to a household
+func (h *HouseholdHandler) inviteMemberHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req InviteMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	household, err := h.householdService.InviteMember(c.Request().Context(), InviteMemberParams{
+		UserID:      userID,
+		HouseholdID: householdID,
+		Email:       req.Email,
+		Role:        req.Role,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toHouseholdResponse(household, userID))
+}
+
+// joinHouseholdHandler handles the HTTP request for accepting an invitation to a household
+func (h *HouseholdHandler) joinHouseholdHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	household, err := h.householdService.JoinHousehold(c.Request().Context(), userID, householdID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toHouseholdResponse(household, userID))
+}
+
+// changeMemberHandler handles the HTTP request for changing the role and the permissions of a member
+func (h *HouseholdHandler) changeMemberHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+	memberUserID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id format")
+	}
+
+	var req ChangeMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	household, err := h.householdService.ChangeMember(c.Request().Context(), ChangeMemberParams{
+		UserID:       userID,
+		HouseholdID:  householdID,
+		MemberUserID: memberUserID,
+		Role:         req.Role,
+		Permissions:  req.Permissions,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toHouseholdResponse(household, userID))
+}
+
+// removeMemberHandler handles the HTTP request for removing a member, leaving a household or declining an invitation
+func (h *HouseholdHandler) removeMemberHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+	memberUserID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id format")
+	}
+
+	if err := h.householdService.RemoveMember(c.Request().Context(), userID, householdID, memberUserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listAccountsHandler handles the HTTP request for listing the accounts shared with a household
+func (h *HouseholdHandler) listAccountsHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := h.householdService.FindAccounts(c.Request().Context(), userID, householdID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]HouseholdAccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		ar, err := toHouseholdAccountResponse(account, h.clock)
+		if err != nil {
+			return err
+		}
+		resp = append(resp, ar)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// getAccountHandler handles the HTTP request for reading an account shared with a household, with its transactions
+func (h *HouseholdHandler) getAccountHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	account, err := h.householdService.FindAccount(c.Request().Context(), userID, householdID, accountID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := toHouseholdAccountResponse(*account, h.clock)
+	if err != nil {
+		return err
+	}
+	resp.Transactions = make([]HouseholdTransactionResponse, 0)
+	for _, tx := range account.Account.Transactions() {
+		resp.Transactions = append(resp.Transactions, HouseholdTransactionResponse{
+			ID:          tx.ID,
+			Type:        tx.Type,
+			Description: tx.Description,
+			Amount:      tx.Amount.Amount,
+			DueDate:     tx.DueDate,
+			PaidAt:      tx.PaidAt,
+			Tags:        tx.Tags,
+		})
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// shareAccountHandler handles the HTTP request for sharing an account with a household
+func (h *HouseholdHandler) shareAccountHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req ShareAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	household, err := h.householdService.ShareAccount(c.Request().Context(), userID, householdID, req.AccountID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toHouseholdResponse(household, userID))
+}
+
+// listBudgetsHandler handles the HTTP request for listing the budgets shared with a household
+func (h *HouseholdHandler) listBudgetsHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	budgets, err := h.householdService.FindBudgets(c.Request().Context(), userID, householdID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]HouseholdBudgetResponse, 0, len(budgets))
+	for _, b := range budgets {
+		resp = append(resp, HouseholdBudgetResponse{
+			ID:           b.Status.Budget.ID,
+			CategoryName: b.Status.CategoryName,
+			Currency:     b.Status.Budget.Amount.Currency,
+			Amount:       b.Status.Budget.Amount.Amount,
+			Spent:        b.Status.Spent.Amount,
+			SharedBy:     b.SharedBy,
+		})
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// shareBudgetHandler handles the HTTP request for sharing a budget with a household
+func (h *HouseholdHandler) shareBudgetHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	var req ShareBudgetRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	household, err := h.householdService.ShareBudget(c.Request().Context(), userID, householdID, req.BudgetID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toHouseholdResponse(household, userID))
+}
+
+// unshareHandler builds the handler of an HTTP request to stop sharing an account or a budget with a household
+func (h *HouseholdHandler) unshareHandler(param, invalidMessage string, unshare func(ctx context.Context, userID, householdID, itemID uuid.UUID) (*Household, error)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		householdID, userID, err := householdParams(c)
+		if err != nil {
+			return err
+		}
+		itemID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, invalidMessage)
+		}
+
+		household, err := unshare(c.Request().Context(), userID, householdID, itemID)
+		if err != nil {
+			return err
+		}
+
+		return httpx.SendSuccess(c, http.StatusOK, toHouseholdResponse(household, userID))
+	}
+}
+
+// reportHandler handles the HTTP request for the monthly report of the accounts shared with a household
+func (h *HouseholdHandler) reportHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.householdService.Report(c.Request().Context(), userID, householdID)
+	if err != nil {
+		return err
+	}
+
+	resp := ReportResponse{
+		From:     report.From,
+		To:       report.To,
+		Accounts: make([]FlowResponse, 0, len(report.Accounts)),
+		Totals:   make([]FlowResponse, 0, len(report.Totals)),
+	}
+	for _, flow := range report.Accounts {
+		resp.Accounts = append(resp.Accounts, FlowResponse{
+			AccountID: &flow.AccountID,
+			Name:      flow.Name,
+			SharedBy:  &flow.SharedBy,
+			Currency:  flow.Income.Currency,
+			Income:    flow.Income.Amount,
+			Expense:   flow.Expense.Amount,
+		})
+	}
+	for _, total := range report.Totals {
+		resp.Totals = append(resp.Totals, FlowResponse{
+			Currency: total.Income.Currency,
+			Income:   total.Income.Amount,
+			Expense:  total.Expense.Amount,
+		})
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// proposeTransactionHandler handles the HTTP request for proposing a transaction on an account shared with a household
+func (h *HouseholdHandler) proposeTransactionHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req ProposeTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	params := ProposeTransactionParams{
+		UserID:      userID,
+		HouseholdID: householdID,
+		AccountID:   accountID,
+		Type:        req.Type,
+		Description: req.Description,
+		Amount:      req.Amount,
+		DueDate:     req.DueDate,
+	}
+	if req.amountMinorUnits != nil {
+		params.Amount, params.AmountMinorUnits = "", *req.amountMinorUnits
+	}
+	proposal, err := h.householdService.ProposeTransaction(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toProposalResponse(proposal))
+}
+
+// listProposalsHandler handles the HTTP request for listing the transaction proposals the user made or decides on
+// The status query parameter defaults to PENDING, listing the queue of proposals awaiting a decision
+func (h *HouseholdHandler) listProposalsHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+	status := ProposalPending
+	if raw := c.QueryParam("status"); raw != "" {
+		if !slices.Contains(ProposalStatus("").Values(), raw) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid proposal status")
+		}
+		status = ProposalStatus(raw)
+	}
+
+	proposals, err := h.householdService.FindProposals(c.Request().Context(), userID, householdID, status)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]ProposalResponse, 0, len(proposals))
+	for _, proposal := range proposals {
+		resp = append(resp, toProposalResponse(proposal))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// rejectProposalHandler handles the HTTP request for the owner of the account rejecting a transaction proposal
+func (h *HouseholdHandler) rejectProposalHandler(c echo.Context) error {
+	householdID, userID, err := householdParams(c)
+	if err != nil {
+		return err
+	}
+	proposalID, err := uuid.Parse(c.Param("proposalId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid proposal id format")
+	}
+
+	var req RejectProposalRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	proposal, err := h.householdService.RejectProposal(c.Request().Context(), userID, householdID, proposalID, req.Reason)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toProposalResponse(proposal))
+}
+
+// resolveProposalHandler builds the handler of an HTTP request approving or cancelling a transaction proposal
+func (h *HouseholdHandler) resolveProposalHandler(resolve func(ctx context.Context, userID, householdID, proposalID uuid.UUID) (*Proposal, error)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		householdID, userID, err := householdParams(c)
+		if err != nil {
+			return err
+		}
+		proposalID, err := uuid.Parse(c.Param("proposalId"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid proposal id format")
+		}
+
+		proposal, err := resolve(c.Request().Context(), userID, householdID, proposalID)
+		if err != nil {
+			return err
+		}
+
+		return httpx.SendSuccess(c, http.StatusOK, toProposalResponse(proposal))
+	}
+}
+
+// householdParams reads the household id of the path and the authenticated user
+func householdParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	householdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "invalid household id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return householdID, userID, nil
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toHouseholdResponse maps the internal Household domain model to the public HouseholdResponse DTO seen by the user
+func toHouseholdResponse(h *Household, userID uuid.UUID) HouseholdResponse {
+	resp := HouseholdResponse{
+		ID:        h.ID,
+		Name:      h.Name,
+		CreatedAt: h.CreatedAt,
+	}
+	if m := h.Member(userID); m != nil {
+		resp.Role = m.Role
+		resp.Joined = m.JoinedAt != nil
+	}
+	if !resp.Joined {
+		return resp
+	}
+
+	resp.Members = make([]MemberResponse, 0, len(h.Members))
+	for _, m := range h.Members {
+		permissions := m.Permissions
+		if permissions == nil {
+			permissions = make([]Permission, 0)
+		}
+		resp.Members = append(resp.Members, MemberResponse{
+			UserID:      m.UserID,
+			Name:        m.Name,
+			Email:       m.Email,
+			Role:        m.Role,
+			Permissions: permissions,
+			InvitedAt:   m.InvitedAt,
+			JoinedAt:    m.JoinedAt,
+		})
+	}
+	resp.Accounts = make([]SharedItemResponse, 0, len(h.Accounts))
+	for _, a := range h.Accounts {
+		resp.Accounts = append(resp.Accounts, SharedItemResponse{ID: a.AccountID, SharedBy: a.SharedBy, SharedAt: a.SharedAt})
+	}
+	resp.Budgets = make([]SharedItemResponse, 0, len(h.Budgets))
+	for _, b := range h.Budgets {
+		resp.Budgets = append(resp.Budgets, SharedItemResponse{ID: b.BudgetID, SharedBy: b.SharedBy, SharedAt: b.SharedAt})
+	}
+	return resp
+}
+
+// toProposalResponse maps the internal Proposal domain model to the public ProposalResponse DTO
+func toProposalResponse(p *Proposal) ProposalResponse {
+	return ProposalResponse{
+		ID:              p.ID,
+		AccountID:       p.AccountID,
+		AccountName:     p.AccountName,
+		OwnerID:         p.OwnerID,
+		ProposedBy:      p.ProposedBy,
+		Type:            p.Type,
+		Description:     p.Description,
+		Currency:        p.Amount.Currency,
+		Amount:          p.Amount.Amount,
+		DueDate:         p.DueDate,
+		Status:          p.Status,
+		RejectionReason: p.RejectionReason,
+		CreatedAt:       p.CreatedAt,
+		ResolvedAt:      p.ResolvedAt,
+	}
+}
+
+// toHouseholdAccountResponse maps a shared account to the public HouseholdAccountResponse DTO, without its transactions
+func toHouseholdAccountResponse(a HouseholdAccount, clk clock.Clock) (HouseholdAccountResponse, error) {
+	realBalance, err := a.Account.RealBalance(clk)
+	if err != nil {
+		return HouseholdAccountResponse{}, err
+	}
+	projectedBalance, err := a.Account.ProjectedBalance()
+	if err != nil {
+		return HouseholdAccountResponse{}, err
+	}
+
+	return HouseholdAccountResponse{
+		ID:               a.Account.ID,
+		Name:             a.Account.Name,
+		Currency:         a.Account.Currency,
+		Kind:             a.Account.Kind,
+		SharedBy:         a.SharedBy,
+		RealBalance:      realBalance.Amount,
+		ProjectedBalance: projectedBalance.Amount,
+	}, nil
+}
@@ -0,0 +1,27 @@
+package households
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// messages translates the notifications sent to the members of the households, about invitations and transaction proposals
+var messages = i18n.Catalog{
+	i18n.English: {
+		"household_invitation_title": "Household invitation",
+		"household_invitation_body":  "You were invited to the household %s, join it to see what its members share",
+		"transaction_proposed_title": "Transaction awaiting your approval",
+		"transaction_proposed_body":  "%s proposed the transaction %s on your account %s, approve or reject it",
+		"proposal_approved_title":    "Proposed transaction approved",
+		"proposal_approved_body":     "The transaction %s you proposed was added to the account %s",
+		"proposal_rejected_title":    "Proposed transaction rejected",
+		"proposal_rejected_body":     "The transaction %s you proposed was not added to the account %s",
+	},
+	i18n.Portuguese: {
+		"household_invitation_title": "Convite para uma família",
+		"household_invitation_body":  "Você foi convidado para a família %s, participe para ver o que os membros compartilham",
+		"transaction_proposed_title": "Transação aguardando sua aprovação",
+		"transaction_proposed_body":  "%s propôs a transação %s na sua conta %s, aprove ou rejeite",
+		"proposal_approved_title":    "Transação proposta aprovada",
+		"proposal_approved_body":     "A transação %s que você propôs foi adicionada à conta %s",
+		"proposal_rejected_title":    "Transação proposta rejeitada",
+		"proposal_rejected_body":     "A transação %s que você propôs não foi adicionada à conta %s",
+	},
+}
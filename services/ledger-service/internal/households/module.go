@@ -0,0 +1,40 @@
+package households
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the households repository, service and handler from the shared dependencies
+type Module struct {
+	handler *HouseholdHandler
+}
+
+// NewModule creates the households module, the shared accounts and budgets are read through their owners
+func NewModule(deps module.Deps, accounts AccountReader, budgets BudgetReader) *Module {
+	householdSvc := NewService(
+		NewPostgresRepository(deps.Postgres.Pool),
+		accounts,
+		budgets,
+		deps.Periods,
+		deps.Notifier,
+		deps.Display,
+		deps.Audit,
+		deps.IDs,
+		deps.Clock,
+	)
+
+	return &Module{handler: NewHouseholdHandler(householdSvc, deps.Clock)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "households"
+}
+
+// RegisterRoutes mounts the households routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,373 @@
+package households
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ Repository           = (*PostgresRepository)(nil)
+	_ ledger.AccountAccess = (*PostgresRepository)(nil)
+)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// Save upserts the household and rewrites its members and shared items in a single transaction
+func (r *PostgresRepository) Save(ctx context.Context, household *Household) error {
+	return pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO households (id, name, created_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name
+		`, household.ID, household.Name, household.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to upsert household: %w", err)
+		}
+
+		for _, table := range []string{"household_members", "household_accounts", "household_budgets"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE household_id = $1`, household.ID); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", table, err)
+			}
+		}
+
+		batch := &pgx.Batch{}
+		for _, m := range household.Members {
+			batch.Queue(`
+				INSERT INTO household_members (household_id, user_id, role, permissions, invited_by, invited_at, joined_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, household.ID, m.UserID, m.Role, permissionStrings(m.Permissions), m.InvitedBy, m.InvitedAt, m.JoinedAt)
+		}
+		for _, a := range household.Accounts {
+			batch.Queue(`
+				INSERT INTO household_accounts (household_id, account_id, shared_by, shared_at)
+				VALUES ($1, $2, $3, $4)
+			`, household.ID, a.AccountID, a.SharedBy, a.SharedAt)
+		}
+		for _, b := range household.Budgets {
+			batch.Queue(`
+				INSERT INTO household_budgets (household_id, budget_id, shared_by, shared_at)
+				VALUES ($1, $2, $3, $4)
+			`, household.ID, b.BudgetID, b.SharedBy, b.SharedAt)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to insert household members and shared items: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindByID retrieves a household with its members and shared items
+func (r *PostgresRepository) FindByID(ctx context.Context, householdID uuid.UUID) (*Household, error) {
+	var h Household
+	err := r.pool.QueryRow(ctx, `SELECT id, name, created_at FROM households WHERE id = $1`, householdID).
+		Scan(&h.ID, &h.Name, &h.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrHouseholdNotFound.With("household_id", householdID)
+		}
+		return nil, fmt.Errorf("failed to query household: %w", err)
+	}
+
+	if err := r.loadHousehold(ctx, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// FindByUserID retrieves the households the user joined or is invited to, ordered by name
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Household, error) {
+	query := `
+		SELECT h.id, h.name, h.created_at
+		FROM households h
+		JOIN household_members m ON m.household_id = h.id
+		WHERE m.user_id = $1
+		ORDER BY h.name, h.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query households: %w", err)
+	}
+	defer rows.Close()
+
+	households := make([]*Household, 0)
+	for rows.Next() {
+		var h Household
+		if err := rows.Scan(&h.ID, &h.Name, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan household row: %w", err)
+		}
+		households = append(households, &h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating household rows: %w", err)
+	}
+
+	for _, h := range households {
+		if err := r.loadHousehold(ctx, h); err != nil {
+			return nil, err
+		}
+	}
+	return households, nil
+}
+
+// Delete deletes the household, its members and shared items are deleted by cascade
+func (r *PostgresRepository) Delete(ctx context.Context, householdID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM households WHERE id = $1`, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete household: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrHouseholdNotFound.With("household_id", householdID)
+	}
+	return nil
+}
+
+// FindUserIDByEmail retrieves the user registered with the email, ignoring case
+func (r *PostgresRepository) FindUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM users WHERE LOWER(email) = LOWER($1)`, email).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrUserNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to query invited user: %w", err)
+	}
+	return userID, nil
+}
+
+// SharedWith reports whether the account is shared in a household the user joined and sees the accounts of, so the
+// ledger tells them the account is not theirs instead of hiding it
+func (r *PostgresRepository) SharedWith(ctx context.Context, accountID, userID uuid.UUID) (bool, error) {
+	var shared bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM household_accounts a
+			JOIN household_members m ON m.household_id = a.household_id
+			WHERE a.account_id = $1 AND m.user_id = $2 AND m.joined_at IS NOT NULL
+				AND (m.role = $3 OR $4 = ANY(m.permissions))
+		)
+	`, accountID, userID, RoleAdmin, PermissionAccounts).Scan(&shared)
+	if err != nil {
+		return false, fmt.Errorf("failed to query shared account: %w", err)
+	}
+	return shared, nil
+}
+
+// SaveProposal inserts a new transaction proposal or updates the status of an existing one
+func (r *PostgresRepository) SaveProposal(ctx context.Context, proposal *Proposal) error {
+	query := `
+		INSERT INTO household_transaction_proposals (
+			id, household_id, account_id, owner_id, proposed_by, type, description, amount, currency,
+			due_date, status, rejection_reason, created_at, resolved_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			status = EXCLUDED.status,
+			rejection_reason = EXCLUDED.rejection_reason,
+			resolved_at = EXCLUDED.resolved_at
+	`
+	_, err := r.pool.Exec(ctx, query,
+		proposal.ID, proposal.HouseholdID, proposal.AccountID, proposal.OwnerID, proposal.ProposedBy,
+		proposal.Type, proposal.Description, proposal.Amount.Amount, proposal.Amount.Currency,
+		proposal.DueDate, proposal.Status, proposal.RejectionReason, proposal.CreatedAt, proposal.ResolvedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert transaction proposal: %w", err)
+	}
+	return nil
+}
+
+// ResolveProposal saves the final status of the proposal if it is still pending
+func (r *PostgresRepository) ResolveProposal(ctx context.Context, proposal *Proposal) error {
+	query := `
+		UPDATE household_transaction_proposals
+		SET status = $2, rejection_reason = $3, resolved_at = $4
+		WHERE id = $1 AND status = $5
+	`
+	tag, err := r.pool.Exec(ctx, query, proposal.ID, proposal.Status, proposal.RejectionReason, proposal.ResolvedAt, ProposalPending)
+	if err != nil {
+		return fmt.Errorf("failed to resolve transaction proposal: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProposalNotPending.With("proposal_id", proposal.ID)
+	}
+	return nil
+}
+
+// ReopenProposal moves an approved proposal back to pending
+func (r *PostgresRepository) ReopenProposal(ctx context.Context, proposalID uuid.UUID) error {
+	query := `
+		UPDATE household_transaction_proposals
+		SET status = $2, resolved_at = NULL
+		WHERE id = $1 AND status = $3
+	`
+	if _, err := r.pool.Exec(ctx, query, proposalID, ProposalPending, ProposalApproved); err != nil {
+		return fmt.Errorf("failed to reopen transaction proposal: %w", err)
+	}
+	return nil
+}
+
+// FindProposal retrieves a transaction proposal with the name of its account
+func (r *PostgresRepository) FindProposal(ctx context.Context, proposalID uuid.UUID) (*Proposal, error) {
+	query := proposalSelect + ` WHERE p.id = $1`
+
+	proposal, err := scanProposal(r.pool.QueryRow(ctx, query, proposalID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProposalNotFound.With("proposal_id", proposalID)
+		}
+		return nil, fmt.Errorf("failed to query transaction proposal: %w", err)
+	}
+	return proposal, nil
+}
+
+// FindProposals retrieves the proposals of the household the user made or decides on with the status, the most recent first
+func (r *PostgresRepository) FindProposals(ctx context.Context, householdID, userID uuid.UUID, status ProposalStatus) ([]*Proposal, error) {
+	query := proposalSelect + `
+		WHERE p.household_id = $1 AND (p.owner_id = $2 OR p.proposed_by = $2) AND p.status = $3
+		ORDER BY p.created_at DESC, p.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, householdID, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction proposals: %w", err)
+	}
+	defer rows.Close()
+
+	proposals := make([]*Proposal, 0)
+	for rows.Next() {
+		proposal, err := scanProposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction proposal row: %w", err)
+		}
+		proposals = append(proposals, proposal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction proposal rows: %w", err)
+	}
+
+	return proposals, nil
+}
+
+// proposalSelect reads the proposals along the name of their account, scanned by scanProposal
+const proposalSelect = `
+	SELECT p.id, p.household_id, p.account_id, a.name, p.owner_id, p.proposed_by, p.type, p.description,
+		p.amount, p.currency, p.due_date, p.status, p.rejection_reason, p.created_at, p.resolved_at
+	FROM household_transaction_proposals p
+	JOIN accounts a ON a.id = p.account_id
+`
+
+// scanProposal reads a proposal from a row of FindProposal or FindProposals
+func scanProposal(row pgx.Row) (*Proposal, error) {
+	var p Proposal
+	err := row.Scan(
+		&p.ID, &p.HouseholdID, &p.AccountID, &p.AccountName, &p.OwnerID, &p.ProposedBy, &p.Type, &p.Description,
+		&p.Amount.Amount, &p.Amount.Currency, &p.DueDate, &p.Status, &p.RejectionReason, &p.CreatedAt, &p.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// loadHousehold reads the members, with their name and email, and the shared items of the household
+func (r *PostgresRepository) loadHousehold(ctx context.Context, h *Household) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT m.user_id, u.name, u.email, m.role, m.permissions, m.invited_by, m.invited_at, m.joined_at
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = $1
+		ORDER BY m.invited_at, m.user_id
+	`, h.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query household members: %w", err)
+	}
+	defer rows.Close()
+
+	h.Members = make([]Member, 0)
+	for rows.Next() {
+		var m Member
+		var permissions []string
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.Role, &permissions, &m.InvitedBy, &m.InvitedAt, &m.JoinedAt); err != nil {
+			return fmt.Errorf("failed to scan household member row: %w", err)
+		}
+		for _, p := range permissions {
+			m.Permissions = append(m.Permissions, Permission(p))
+		}
+		h.Members = append(h.Members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating household member rows: %w", err)
+	}
+
+	accountRows, err := r.pool.Query(ctx, `
+		SELECT account_id, shared_by, shared_at FROM household_accounts
+		WHERE household_id = $1
+		ORDER BY shared_at, account_id
+	`, h.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query household accounts: %w", err)
+	}
+	defer accountRows.Close()
+
+	h.Accounts = make([]SharedAccount, 0)
+	for accountRows.Next() {
+		var a SharedAccount
+		if err := accountRows.Scan(&a.AccountID, &a.SharedBy, &a.SharedAt); err != nil {
+			return fmt.Errorf("failed to scan household account row: %w", err)
+		}
+		h.Accounts = append(h.Accounts, a)
+	}
+	if err := accountRows.Err(); err != nil {
+		return fmt.Errorf("error iterating household account rows: %w", err)
+	}
+
+	budgetRows, err := r.pool.Query(ctx, `
+		SELECT budget_id, shared_by, shared_at FROM household_budgets
+		WHERE household_id = $1
+		ORDER BY shared_at, budget_id
+	`, h.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query household budgets: %w", err)
+	}
+	defer budgetRows.Close()
+
+	h.Budgets = make([]SharedBudget, 0)
+	for budgetRows.Next() {
+		var b SharedBudget
+		if err := budgetRows.Scan(&b.BudgetID, &b.SharedBy, &b.SharedAt); err != nil {
+			return fmt.Errorf("failed to scan household budget row: %w", err)
+		}
+		h.Budgets = append(h.Budgets, b)
+	}
+	if err := budgetRows.Err(); err != nil {
+		return fmt.Errorf("error iterating household budget rows: %w", err)
+	}
+
+	return nil
+}
+
+// permissionStrings converts the permissions to the TEXT[] column
+func permissionStrings(permissions []Permission) []string {
+	values := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		values = append(values, string(p))
+	}
+	return values
+}
@@ -0,0 +1,750 @@
+package households
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/budgets"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the households, every change of who sees what leaving a record
+const (
+	auditHouseholdCreated    = "household.created"
+	auditHouseholdRenamed    = "household.renamed"
+	auditHouseholdDeleted    = "household.deleted"
+	auditMemberInvited       = "household.member_invited"
+	auditMemberJoined        = "household.member_joined"
+	auditMemberChanged       = "household.member_changed"
+	auditMemberRemoved       = "household.member_removed"
+	auditAccountShared       = "household.account_shared"
+	auditAccountUnshared     = "household.account_unshared"
+	auditBudgetShared        = "household.budget_shared"
+	auditBudgetUnshared      = "household.budget_unshared"
+	auditTransactionProposed = "household.transaction_proposed"
+	auditProposalApproved    = "household.proposal_approved"
+	auditProposalRejected    = "household.proposal_rejected"
+	auditProposalCancelled   = "household.proposal_cancelled"
+	auditResourceHousehold   = "household"
+)
+
+// AccountReader finds an account of a member and adds the approved proposals to it, satisfied by the ledger Service
+type AccountReader interface {
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+	AddTransactionToAccount(ctx context.Context, params ledger.AddTransactionParams) (*ledger.ArchivalSuggestion, error)
+}
+
+// BudgetReader lists the budgets of a member with their spending, satisfied by the budgets Service
+type BudgetReader interface {
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]budgets.BudgetStatus, error)
+}
+
+// InviteMemberParams holds all the required data for the InviteMember use case
+type InviteMemberParams struct {
+	UserID      uuid.UUID
+	HouseholdID uuid.UUID
+	Email       string
+	Role        Role
+	Permissions []Permission
+}
+
+// ChangeMemberParams holds all the required data for the ChangeMember use case
+type ChangeMemberParams struct {
+	UserID       uuid.UUID
+	HouseholdID  uuid.UUID
+	MemberUserID uuid.UUID
+	Role         Role
+	Permissions  []Permission
+}
+
+// ProposeTransactionParams holds all the required data for the ProposeTransaction use case
+type ProposeTransactionParams struct {
+	UserID      uuid.UUID
+	HouseholdID uuid.UUID
+	AccountID   uuid.UUID
+	Type        ledger.TransactionType
+	Description string
+	Amount      string // Amount as a decimal string, parsed using the account currency (e.g., "1.234,56")
+	// AmountMinorUnits is the amount in minor units of the account currency, used when Amount is empty (version 1 bodies)
+	AmountMinorUnits int64
+	DueDate          time.Time
+}
+
+// HouseholdAccount is an account shared with the household, as seen by its members
+type HouseholdAccount struct {
+	Account  *ledger.Account
+	SharedBy uuid.UUID
+}
+
+// HouseholdBudget is a budget shared with the household with its spending in the financial month of its owner
+type HouseholdBudget struct {
+	Status   budgets.BudgetStatus
+	SharedBy uuid.UUID
+}
+
+// AccountFlow is what entered and left a shared account in the report period
+type AccountFlow struct {
+	AccountID uuid.UUID
+	Name      string
+	SharedBy  uuid.UUID
+	Income    money.Money
+	Expense   money.Money
+}
+
+// Report is the monthly flow of the shared accounts, totalled by currency since accounts may differ in currency
+type Report struct {
+	From     time.Time
+	To       time.Time
+	Accounts []AccountFlow
+	Totals   []AccountFlow // Totals has one entry per currency, without account
+}
+
+// Service manages the households and what their members see of each other
+type Service struct {
+	repo     Repository
+	accounts AccountReader
+	budgets  BudgetReader
+	periods  module.AccountingPeriods
+	notifier notify.Notifier
+	display  module.UserPreferences
+	auditor  *audit.Logger
+	ids      idgen.Generator
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the households Service, the shared data being read through accounts and budgets
+func NewService(
+	repo Repository,
+	accounts AccountReader,
+	budgetReader BudgetReader,
+	periods module.AccountingPeriods,
+	notifier notify.Notifier,
+	display module.UserPreferences,
+	auditor *audit.Logger,
+	ids idgen.Generator,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		repo:     repo,
+		accounts: accounts,
+		budgets:  budgetReader,
+		periods:  periods,
+		notifier: notifier,
+		display:  display,
+		auditor:  auditor,
+		ids:      ids,
+		clock:    clock,
+	}
+}
+
+// CreateHousehold is the use case for creating a household, administered by the user
+func (s *Service) CreateHousehold(ctx context.Context, userID uuid.UUID, name string) (*Household, error) {
+	if err := s.checkHouseholdLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	household, err := NewHousehold(name, userID, s.ids, s.clock)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, household); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, auditHouseholdCreated, household, map[string]string{"name": household.Name})
+	return s.repo.FindByID(ctx, household.ID)
+}
+
+// FindHouseholds is the use case for listing the households the user joined or is invited to
+func (s *Service) FindHouseholds(ctx context.Context, userID uuid.UUID) ([]*Household, error) {
+	households, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find households: %w", err)
+	}
+	return households, nil
+}
+
+// FindHousehold is the use case for reading a household the user joined, with its members and shared items
+func (s *Service) FindHousehold(ctx context.Context, userID, householdID uuid.UUID) (*Household, error) {
+	household, err := s.repo.FindByID(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find household: %w", err)
+	}
+	if !household.Visible(userID) {
+		return nil, ErrHouseholdNotFound.With("household_id", householdID)
+	}
+	return household, nil
+}
+
+// RenameHousehold is the use case for an admin renaming the household
+func (s *Service) RenameHousehold(ctx context.Context, userID, householdID uuid.UUID, name string) (*Household, error) {
+	return s.change(ctx, householdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.Rename(userID, name); err != nil {
+			return "", nil, err
+		}
+		return auditHouseholdRenamed, map[string]string{"name": h.Name}, nil
+	})
+}
+
+// DeleteHousehold is the use case for an admin deleting the household, which stops every sharing
+func (s *Service) DeleteHousehold(ctx context.Context, userID, householdID uuid.UUID) error {
+	household, err := s.FindHousehold(ctx, userID, householdID)
+	if err != nil {
+		return err
+	}
+	if err := household.requireAdmin(userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, householdID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, auditHouseholdDeleted, household, nil)
+	return nil
+}
+
+// InviteMember is the use case for an admin inviting a registered user, who sees nothing until they join
+func (s *Service) InviteMember(ctx context.Context, params InviteMemberParams) (*Household, error) {
+	// Only the members learn whether an email is registered
+	if _, err := s.FindHousehold(ctx, params.UserID, params.HouseholdID); err != nil {
+		return nil, err
+	}
+
+	invitedID, err := s.repo.FindUserIDByEmail(ctx, params.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkHouseholdLimit(ctx, invitedID); err != nil {
+		return nil, err
+	}
+
+	household, err := s.change(ctx, params.HouseholdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.Invite(params.UserID, invitedID, params.Role, params.Permissions, s.clock); err != nil {
+			return "", nil, err
+		}
+		return auditMemberInvited, memberMetadata(h.Member(invitedID)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, invitedID, map[string]string{"household_id": household.ID.String()}, "household_invitation", household.Name)
+	return household, nil
+}
+
+// JoinHousehold is the use case for a user accepting their invitation to a household
+func (s *Service) JoinHousehold(ctx context.Context, userID, householdID uuid.UUID) (*Household, error) {
+	return s.change(ctx, householdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.Join(userID, s.clock); err != nil {
+			return "", nil, err
+		}
+		return auditMemberJoined, memberMetadata(h.Member(userID)), nil
+	})
+}
+
+// ChangeMember is the use case for an admin changing the role and the permissions of a member
+func (s *Service) ChangeMember(ctx context.Context, params ChangeMemberParams) (*Household, error) {
+	return s.change(ctx, params.HouseholdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.ChangeMember(params.UserID, params.MemberUserID, params.Role, params.Permissions); err != nil {
+			return "", nil, err
+		}
+		return auditMemberChanged, memberMetadata(h.Member(params.MemberUserID)), nil
+	})
+}
+
+// RemoveMember is the use case for an admin removing a member, or a user leaving or declining an invitation
+// What the member shared stops being shared with the household
+func (s *Service) RemoveMember(ctx context.Context, userID, householdID, memberUserID uuid.UUID) error {
+	household, err := s.repo.FindByID(ctx, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to find household: %w", err)
+	}
+	// Invited users decline by removing themselves before joining
+	if household.Member(userID) == nil {
+		return ErrHouseholdNotFound.With("household_id", householdID)
+	}
+
+	if err := household.RemoveMember(userID, memberUserID); err != nil {
+		return err
+	}
+	if err := s.repo.Save(ctx, household); err != nil {
+		return err
+	}
+
+	s.audit(ctx, auditMemberRemoved, household, map[string]string{"user_id": memberUserID.String()})
+	return nil
+}
+
+// ShareAccount is the use case for a member sharing one of their active accounts with the household
+func (s *Service) ShareAccount(ctx context.Context, userID, householdID, accountID uuid.UUID) (*Household, error) {
+	account, err := s.accounts.FindAccountByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.ArchivedAt != nil {
+		return nil, ErrAccountNotFound.With("account_id", accountID)
+	}
+
+	return s.change(ctx, householdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.ShareAccount(userID, accountID, s.clock); err != nil {
+			return "", nil, err
+		}
+		return auditAccountShared, map[string]string{"account_id": accountID.String()}, nil
+	})
+}
+
+// UnshareAccount is the use case for stopping sharing an account with the household
+func (s *Service) UnshareAccount(ctx context.Context, userID, householdID, accountID uuid.UUID) (*Household, error) {
+	return s.change(ctx, householdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.UnshareAccount(userID, accountID); err != nil {
+			return "", nil, err
+		}
+		return auditAccountUnshared, map[string]string{"account_id": accountID.String()}, nil
+	})
+}
+
+// ShareBudget is the use case for a member sharing one of their budgets with the household
+func (s *Service) ShareBudget(ctx context.Context, userID, householdID, budgetID uuid.UUID) (*Household, error) {
+	statuses, err := s.budgets.ListBudgets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budgets: %w", err)
+	}
+	if !slices.ContainsFunc(statuses, func(st budgets.BudgetStatus) bool { return st.Budget.ID == budgetID }) {
+		return nil, ErrBudgetNotFound.With("budget_id", budgetID)
+	}
+
+	return s.change(ctx, householdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.ShareBudget(userID, budgetID, s.clock); err != nil {
+			return "", nil, err
+		}
+		return auditBudgetShared, map[string]string{"budget_id": budgetID.String()}, nil
+	})
+}
+
+// UnshareBudget is the use case for stopping sharing a budget with the household
+func (s *Service) UnshareBudget(ctx context.Context, userID, householdID, budgetID uuid.UUID) (*Household, error) {
+	return s.change(ctx, householdID, func(h *Household) (string, map[string]string, error) {
+		if err := h.UnshareBudget(userID, budgetID); err != nil {
+			return "", nil, err
+		}
+		return auditBudgetUnshared, map[string]string{"budget_id": budgetID.String()}, nil
+	})
+}
+
+// FindAccounts is the use case for a member with the accounts permission reading the shared accounts
+// Accounts archived since they were shared are left out
+func (s *Service) FindAccounts(ctx context.Context, userID, householdID uuid.UUID) ([]HouseholdAccount, error) {
+	household, err := s.findAllowed(ctx, userID, householdID, PermissionAccounts)
+	if err != nil {
+		return nil, err
+	}
+	return s.sharedAccounts(ctx, household)
+}
+
+// FindAccount is the use case for a member with the accounts permission reading a shared account with its transactions
+func (s *Service) FindAccount(ctx context.Context, userID, householdID, accountID uuid.UUID) (*HouseholdAccount, error) {
+	household, err := s.findAllowed(ctx, userID, householdID, PermissionAccounts)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(household.Accounts, func(a SharedAccount) bool { return a.AccountID == accountID })
+	if i < 0 {
+		return nil, ErrAccountNotFound.With("account_id", accountID)
+	}
+	account, err := s.accounts.FindAccountByID(ctx, household.Accounts[i].SharedBy, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.ArchivedAt != nil {
+		return nil, ErrAccountNotFound.With("account_id", accountID)
+	}
+
+	return &HouseholdAccount{Account: account, SharedBy: household.Accounts[i].SharedBy}, nil
+}
+
+// FindBudgets is the use case for a member with the budgets permission reading the shared budgets
+func (s *Service) FindBudgets(ctx context.Context, userID, householdID uuid.UUID) ([]HouseholdBudget, error) {
+	household, err := s.findAllowed(ctx, userID, householdID, PermissionBudgets)
+	if err != nil {
+		return nil, err
+	}
+
+	// The budgets are listed once per member who shared some
+	bySharer := make(map[uuid.UUID][]budgets.BudgetStatus)
+	result := make([]HouseholdBudget, 0, len(household.Budgets))
+	for _, shared := range household.Budgets {
+		statuses, ok := bySharer[shared.SharedBy]
+		if !ok {
+			if statuses, err = s.budgets.ListBudgets(ctx, shared.SharedBy); err != nil {
+				return nil, fmt.Errorf("failed to find shared budgets: %w", err)
+			}
+			bySharer[shared.SharedBy] = statuses
+		}
+
+		i := slices.IndexFunc(statuses, func(st budgets.BudgetStatus) bool { return st.Budget.ID == shared.BudgetID })
+		if i < 0 {
+			continue
+		}
+		result = append(result, HouseholdBudget{Status: statuses[i], SharedBy: shared.SharedBy})
+	}
+	return result, nil
+}
+
+// Report is the use case for a member with the reports permission reading the flow of the shared accounts
+// in their current financial month, counting the paid transactions as the account list does
+func (s *Service) Report(ctx context.Context, userID, householdID uuid.UUID) (*Report, error) {
+	household, err := s.findAllowed(ctx, userID, householdID, PermissionReports)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := s.sharedAccounts(ctx, household)
+	if err != nil {
+		return nil, err
+	}
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	from, to := period.RangeIn(s.clock.Now(), time.UTC)
+	report := &Report{From: from, To: to, Accounts: make([]AccountFlow, 0, len(shared)), Totals: make([]AccountFlow, 0)}
+	for _, sa := range shared {
+		flow := AccountFlow{
+			AccountID: sa.Account.ID,
+			Name:      sa.Account.Name,
+			SharedBy:  sa.SharedBy,
+			Income:    money.Zero(sa.Account.Currency),
+			Expense:   money.Zero(sa.Account.Currency),
+		}
+		for _, tx := range sa.Account.Transactions() {
+			if tx.PaidAt == nil || tx.PaidAt.Before(from) || !tx.PaidAt.Before(to) {
+				continue
+			}
+			switch tx.Type {
+			case ledger.Income, ledger.Adjustment:
+				flow.Income, err = flow.Income.Add(tx.Amount)
+			case ledger.Expense:
+				flow.Expense, err = flow.Expense.Add(tx.Amount)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		report.Accounts = append(report.Accounts, flow)
+
+		if err := addToTotals(report, flow); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// ProposeTransaction is the use case for a member proposing a transaction on an account shared by another member,
+// who is notified and decides whether it is added to the account
+func (s *Service) ProposeTransaction(ctx context.Context, params ProposeTransactionParams) (*Proposal, error) {
+	household, err := s.findAllowed(ctx, params.UserID, params.HouseholdID, PermissionPropose)
+	if err != nil {
+		return nil, err
+	}
+	shared := household.sharedAccount(params.AccountID)
+	if shared == nil {
+		return nil, ErrAccountNotFound.With("account_id", params.AccountID)
+	}
+	account, err := s.accounts.FindAccountByID(ctx, shared.SharedBy, params.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.ArchivedAt != nil {
+		return nil, ErrAccountNotFound.With("account_id", params.AccountID)
+	}
+
+	amount := money.New(params.AmountMinorUnits, account.Currency)
+	if params.Amount != "" {
+		if amount, err = money.Parse(params.Amount, account.Currency); err != nil {
+			return nil, fmt.Errorf("failed to parse proposed amount: %w", err)
+		}
+	}
+	proposal, err := household.Propose(params.UserID, params.AccountID, params.Type, params.Description, amount, params.DueDate, s.ids, s.clock)
+	if err != nil {
+		return nil, err
+	}
+	proposal.AccountName = account.Name
+	if err := s.repo.SaveProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, auditTransactionProposed, household, proposalMetadata(proposal))
+	s.notify(ctx, proposal.OwnerID, proposalData(proposal), "transaction_proposed",
+		household.Member(params.UserID).Name, proposal.Description, proposal.AccountName)
+
+	return proposal, nil
+}
+
+// FindProposals is the use case for listing the proposals of the household with the status that the user made
+// or decides on, the most recent first
+func (s *Service) FindProposals(ctx context.Context, userID, householdID uuid.UUID, status ProposalStatus) ([]*Proposal, error) {
+	if _, err := s.FindHousehold(ctx, userID, householdID); err != nil {
+		return nil, err
+	}
+
+	proposals, err := s.repo.FindProposals(ctx, householdID, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transaction proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+// ApproveProposal is the use case for the owner of the account adding the proposed transaction to it, as a pending transaction
+// The proposal is claimed before the transaction is added, so concurrent approvals add it once, and reopened when
+// adding it fails so the owner can approve it again
+func (s *Service) ApproveProposal(ctx context.Context, userID, householdID, proposalID uuid.UUID) (*Proposal, error) {
+	household, proposal, err := s.findProposal(ctx, userID, householdID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	// The owner may have stopped sharing the account since it was proposed
+	if shared := household.sharedAccount(proposal.AccountID); shared == nil || shared.SharedBy != proposal.OwnerID {
+		return nil, ErrAccountNotFound.With("account_id", proposal.AccountID)
+	}
+
+	if err := proposal.Approve(userID, s.clock); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ResolveProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+	_, err = s.accounts.AddTransactionToAccount(ctx, ledger.AddTransactionParams{
+		AccountID:   proposal.AccountID,
+		UserID:      proposal.OwnerID,
+		Type:        proposal.Type,
+		Description: proposal.Description,
+		Amount:      proposal.Amount.Decimal(),
+		DueDate:     proposal.DueDate,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to add proposed transaction: %w", err)
+		if reopenErr := s.repo.ReopenProposal(context.WithoutCancel(ctx), proposal.ID); reopenErr != nil {
+			err = errors.Join(err, reopenErr)
+		}
+		return nil, err
+	}
+
+	s.audit(ctx, auditProposalApproved, household, proposalMetadata(proposal))
+	s.notify(ctx, proposal.ProposedBy, proposalData(proposal), "proposal_approved", proposal.Description, proposal.AccountName)
+
+	return proposal, nil
+}
+
+// RejectProposal is the use case for the owner of the account refusing the proposed transaction
+func (s *Service) RejectProposal(ctx context.Context, userID, householdID, proposalID uuid.UUID, reason string) (*Proposal, error) {
+	household, proposal, err := s.findProposal(ctx, userID, householdID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := proposal.Reject(userID, reason, s.clock); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ResolveProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	data := proposalData(proposal)
+	if proposal.RejectionReason != "" {
+		data["rejection_reason"] = proposal.RejectionReason
+	}
+	s.audit(ctx, auditProposalRejected, household, proposalMetadata(proposal))
+	s.notify(ctx, proposal.ProposedBy, data, "proposal_rejected", proposal.Description, proposal.AccountName)
+
+	return proposal, nil
+}
+
+// CancelProposal is the use case for the member withdrawing a pending proposal
+func (s *Service) CancelProposal(ctx context.Context, userID, householdID, proposalID uuid.UUID) (*Proposal, error) {
+	household, proposal, err := s.findProposal(ctx, userID, householdID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := proposal.Cancel(userID, s.clock); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ResolveProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, auditProposalCancelled, household, proposalMetadata(proposal))
+	return proposal, nil
+}
+
+// addToTotals adds the flow of an account to the total of its currency
+func addToTotals(report *Report, flow AccountFlow) error {
+	i := slices.IndexFunc(report.Totals, func(t AccountFlow) bool { return t.Income.Currency == flow.Income.Currency })
+	if i < 0 {
+		report.Totals = append(report.Totals, AccountFlow{Income: flow.Income, Expense: flow.Expense})
+		return nil
+	}
+
+	var err error
+	if report.Totals[i].Income, err = report.Totals[i].Income.Add(flow.Income); err != nil {
+		return err
+	}
+	if report.Totals[i].Expense, err = report.Totals[i].Expense.Add(flow.Expense); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sharedAccounts reads the active shared accounts through their owners
+func (s *Service) sharedAccounts(ctx context.Context, household *Household) ([]HouseholdAccount, error) {
+	accounts := make([]HouseholdAccount, 0, len(household.Accounts))
+	for _, shared := range household.Accounts {
+		account, err := s.accounts.FindAccountByID(ctx, shared.SharedBy, shared.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find shared account: %w", err)
+		}
+		if account.ArchivedAt != nil {
+			continue
+		}
+		accounts = append(accounts, HouseholdAccount{Account: account, SharedBy: shared.SharedBy})
+	}
+	return accounts, nil
+}
+
+// findAllowed finds a household the user may see the kind of data of
+func (s *Service) findAllowed(ctx context.Context, userID, householdID uuid.UUID, permission Permission) (*Household, error) {
+	household, err := s.repo.FindByID(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find household: %w", err)
+	}
+	if err := household.Allowed(userID, permission); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// findProposal finds a proposal of the household the user joined, reporting the proposals they neither made nor decide on as not found
+func (s *Service) findProposal(ctx context.Context, userID, householdID, proposalID uuid.UUID) (*Household, *Proposal, error) {
+	household, err := s.FindHousehold(ctx, userID, householdID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proposal, err := s.repo.FindProposal(ctx, proposalID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find transaction proposal: %w", err)
+	}
+	if proposal.HouseholdID != householdID || (proposal.OwnerID != userID && proposal.ProposedBy != userID) {
+		return nil, nil, ErrProposalNotFound.With("proposal_id", proposalID)
+	}
+	return household, proposal, nil
+}
+
+// change applies a change to a household, saves it and audits it, returning the household as saved
+func (s *Service) change(ctx context.Context, householdID uuid.UUID, apply func(h *Household) (string, map[string]string, error)) (*Household, error) {
+	household, err := s.repo.FindByID(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find household: %w", err)
+	}
+
+	action, metadata, err := apply(household)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, household); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, action, household, metadata)
+	return s.repo.FindByID(ctx, householdID)
+}
+
+// checkHouseholdLimit checks the user may still join or be invited to another household
+func (s *Service) checkHouseholdLimit(ctx context.Context, userID uuid.UUID) error {
+	households, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find households: %w", err)
+	}
+	if len(households) >= MaxHouseholdsPerUser {
+		return ErrTooManyHouseholds.With("max_households", MaxHouseholdsPerUser)
+	}
+	return nil
+}
+
+// audit records a change of the household
+func (s *Service) audit(ctx context.Context, action string, household *Household, metadata map[string]string) {
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       action,
+		ResourceType: auditResourceHousehold,
+		ResourceID:   household.ID.String(),
+		Metadata:     metadata,
+	})
+}
+
+// notify tells a user about a household in their language, the message being the key of its title and body in messages
+// A failed notification never undoes the change
+func (s *Service) notify(ctx context.Context, userID uuid.UUID, data map[string]string, message string, args ...any) {
+	lang := i18n.Default
+	if display, err := s.display.DisplayPreferences(ctx, userID); err == nil {
+		lang, _ = i18n.Resolve(display.Locale)
+	}
+
+	err := s.notifier.Notify(ctx, notify.Message{
+		UserID:   userID,
+		Category: notify.CategorySecurity,
+		Title:    messages.Format(lang, message+"_title"),
+		Body:     messages.Format(lang, message+"_body", args...),
+		Data:     data,
+	})
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to notify household member",
+			slog.String("household_id", data["household_id"]),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// proposalMetadata describes a transaction proposal for the audit records
+func proposalMetadata(p *Proposal) map[string]string {
+	return map[string]string{
+		"proposal_id": p.ID.String(),
+		"account_id":  p.AccountID.String(),
+		"proposed_by": p.ProposedBy.String(),
+		"amount":      p.Amount.String(),
+		"status":      string(p.Status),
+	}
+}
+
+// proposalData identifies a transaction proposal in the notifications about it
+func proposalData(p *Proposal) map[string]string {
+	return map[string]string{
+		"household_id": p.HouseholdID.String(),
+		"proposal_id":  p.ID.String(),
+		"account_id":   p.AccountID.String(),
+		"status":       string(p.Status),
+	}
+}
+
+// memberMetadata describes the role and the permissions of a member for the audit records
+func memberMetadata(m *Member) map[string]string {
+	return map[string]string{
+		"user_id":     m.UserID.String(),
+		"role":        string(m.Role),
+		"permissions": strings.Join(permissionStrings(m.Permissions), ","),
+	}
+}
@@ -0,0 +1,323 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	_ Adapter = OrganizzeAdapter{}
+	_ Adapter = MobillsAdapter{}
+	_ Adapter = YNABAdapter{}
+)
+
+// DefaultAdapters returns the adapters of every supported format
+func DefaultAdapters() []Adapter {
+	return []Adapter{OrganizzeAdapter{}, MobillsAdapter{}, YNABAdapter{}}
+}
+
+const (
+	// maxDescriptionLength is the longest description the ledger accepts, longer ones are cut so the import does not fail
+	maxDescriptionLength = 100
+	// defaultDescription describes the rows exported without description, which the ledger requires
+	defaultDescription = "Importado"
+)
+
+// Brazilian apps write dates day first, YNAB follows the US locale by default
+var (
+	brazilianDateLayouts = []string{"02/01/2006", "02/01/06", "2006-01-02"}
+	usDateLayouts        = []string{"01/02/2006", "2006-01-02"}
+)
+
+// ----- Organizze ----- //
+
+// OrganizzeAdapter reads the CSV exported by Organizze (Data;Descrição;Categoria;Valor;Situação)
+type OrganizzeAdapter struct{}
+
+// Format returns ORGANIZZE
+func (OrganizzeAdapter) Format() Format {
+	return Organizze
+}
+
+// Parse reads the rows of an Organizze export, whose values are already signed
+func (OrganizzeAdapter) Parse(r io.Reader) ([]Row, error) {
+	table, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := table.require("data", "descricao", "valor")
+	if err != nil {
+		return nil, err
+	}
+	category := table.column("categoria")
+
+	return table.rows(func(line int, record []string) (*Row, error) {
+		amount := cleanAmount(record[cols[2]])
+		if isZeroAmount(amount) {
+			return nil, nil
+		}
+		date, err := parseDate(line, record[cols[0]], brazilianDateLayouts)
+		if err != nil {
+			return nil, err
+		}
+		return &Row{
+			Date:        date,
+			Description: strings.TrimSpace(record[cols[1]]),
+			Amount:      amount,
+			Category:    field(record, category),
+		}, nil
+	})
+}
+
+// ----- Mobills ----- //
+
+// MobillsAdapter reads the CSV exported by Mobills (Data;Descrição;Valor;Conta;Categoria;Subcategoria)
+type MobillsAdapter struct{}
+
+// Format returns MOBILLS
+func (MobillsAdapter) Format() Format {
+	return Mobills
+}
+
+// Parse reads the rows of a Mobills export, joining the subcategory to its category as "Category > Subcategory"
+func (MobillsAdapter) Parse(r io.Reader) ([]Row, error) {
+	table, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := table.require("data", "descricao", "valor")
+	if err != nil {
+		return nil, err
+	}
+	category := table.column("categoria")
+	subcategory := table.column("subcategoria")
+
+	return table.rows(func(line int, record []string) (*Row, error) {
+		amount := cleanAmount(record[cols[2]])
+		if isZeroAmount(amount) {
+			return nil, nil
+		}
+		date, err := parseDate(line, record[cols[0]], brazilianDateLayouts)
+		if err != nil {
+			return nil, err
+		}
+
+		name := field(record, category)
+		if sub := field(record, subcategory); sub != "" && name != "" {
+			name += " > " + sub
+		} else if sub != "" {
+			name = sub
+		}
+
+		return &Row{
+			Date:        date,
+			Description: strings.TrimSpace(record[cols[1]]),
+			Amount:      amount,
+			Category:    name,
+		}, nil
+	})
+}
+
+// ----- YNAB ----- //
+
+// YNABAdapter reads the register CSV exported by YNAB (Account,Flag,Date,Payee,Category Group/Category,...,Memo,Outflow,Inflow)
+type YNABAdapter struct{}
+
+// Format returns YNAB
+func (YNABAdapter) Format() Format {
+	return YNAB
+}
+
+// Parse reads the rows of a YNAB register, whose amounts are split in unsigned outflow and inflow columns
+// The payee is the description and the memo the observation
+func (YNABAdapter) Parse(r io.Reader) ([]Row, error) {
+	table, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := table.require("date", "payee", "outflow", "inflow")
+	if err != nil {
+		return nil, err
+	}
+	category := table.column("category group/category", "category")
+	memo := table.column("memo")
+
+	return table.rows(func(line int, record []string) (*Row, error) {
+		amount := cleanAmount(record[cols[3]])
+		if outflow := cleanAmount(record[cols[2]]); !isZeroAmount(outflow) {
+			amount = "-" + strings.TrimLeft(outflow, "-+")
+		}
+		if isZeroAmount(amount) {
+			return nil, nil
+		}
+		date, err := parseDate(line, record[cols[0]], usDateLayouts)
+		if err != nil {
+			return nil, err
+		}
+
+		description := strings.TrimSpace(record[cols[1]])
+		observation := field(record, memo)
+		if description == "" {
+			description, observation = observation, ""
+		}
+
+		return &Row{
+			Date:        date,
+			Description: description,
+			Observation: observation,
+			Amount:      amount,
+			Category:    field(record, category),
+		}, nil
+	})
+}
+
+// ----- CSV helpers ----- //
+
+// csvTable is a CSV export read with its header, so adapters find their columns by name whatever their order
+type csvTable struct {
+	columns map[string]int
+	width   int // width is the number of header fields, shorter records are padded to it
+	records [][]string
+}
+
+// readCSV reads a whole export, detecting whether its fields are separated by ';' (Brazilian apps) or ','
+func readCSV(r io.Reader) (*csvTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrInvalidFile.With("reason", "the file could not be read")
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM written by spreadsheet apps
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, ErrInvalidFile.With("line", parseErr.Line).With("reason", parseErr.Err.Error())
+		}
+		return nil, ErrInvalidFile.With("reason", err.Error())
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	table := &csvTable{columns: make(map[string]int, len(records[0])), width: len(records[0]), records: records[1:]}
+	for i, name := range records[0] {
+		table.columns[normalizeName(name)] = i
+	}
+	return table, nil
+}
+
+// column returns the index of the first column found among the normalized names, -1 when the export has none of them
+func (t *csvTable) column(names ...string) int {
+	for _, name := range names {
+		if i, ok := t.columns[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// require returns the indexes of the columns the format cannot do without, in the given order
+func (t *csvTable) require(names ...string) ([]int, error) {
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		index := t.column(name)
+		if index < 0 {
+			return nil, ErrInvalidFile.With("missing_column", name)
+		}
+		indexes[i] = index
+	}
+	return indexes, nil
+}
+
+// rows maps every non-blank record with parse, which returns a nil row for the records to skip (e.g., zero amounts)
+func (t *csvTable) rows(parse func(line int, record []string) (*Row, error)) ([]Row, error) {
+	rows := make([]Row, 0, len(t.records))
+	for i, record := range t.records {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		line := i + 2 // The header is the first line
+		if len(record) < t.width {
+			record = append(record, make([]string, t.width-len(record))...)
+		}
+
+		row, err := parse(line, record)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			continue
+		}
+		row.Description = truncate(row.Description, maxDescriptionLength)
+		if row.Description == "" {
+			row.Description = defaultDescription
+		}
+		rows = append(rows, *row)
+		if len(rows) > MaxRows {
+			return nil, ErrTooManyRows.With("max_rows", MaxRows)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+// truncate cuts s to at most n runes
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:n]))
+}
+
+// field returns the trimmed value of an optional column, empty when the format has no such column
+func field(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// parseDate parses a date with the first matching layout, as a UTC day
+func parseDate(line int, value string, layouts []string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, ErrInvalidFile.With("line", line).With("reason", "invalid date "+value)
+}
+
+// cleanAmount drops currency symbols and spaces (e.g., "R$ -1.234,56" is "-1.234,56"), leaving the sign and separators
+func cleanAmount(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' || r == '+' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isZeroAmount reports whether a cleaned amount is empty or zero
+func isZeroAmount(amount string) bool {
+	return strings.Trim(amount, "0.,-+") == ""
+}
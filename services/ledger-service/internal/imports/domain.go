@@ -0,0 +1,163 @@
+package imports
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImportNotFound    = errx.New(errx.CategoryNotFound, "IMPORT_NOT_FOUND", "import not found")
+	ErrUnsupportedFormat = errx.New(errx.CategoryValidation, "UNSUPPORTED_IMPORT_FORMAT", "the import format is not supported")
+	ErrInvalidFile       = errx.New(errx.CategoryValidation, "INVALID_IMPORT_FILE", "the file does not match the import format")
+	ErrEmptyImport       = errx.New(errx.CategoryValidation, "EMPTY_IMPORT", "the file has no transactions to import")
+	ErrTooManyRows       = errx.New(errx.CategoryValidation, "TOO_MANY_IMPORT_ROWS", "the file has too many transactions")
+	ErrUnmappedCategory  = errx.New(errx.CategoryValidation, "UNMAPPED_IMPORT_CATEGORY", "every category of the file must be mapped")
+	ErrCategoryNotFound  = errx.New(errx.CategoryNotFound, "CATEGORY_NOT_FOUND", "category not found")
+	ErrImportNotPending  = errx.New(errx.CategoryConflict, "IMPORT_NOT_PENDING", "the import was already confirmed")
+)
+
+const (
+	// MaxRows caps the transactions of a single import, larger histories are imported in several files
+	MaxRows = 5000
+	// PendingTTL is how long an import waits for its categories to be confirmed
+	PendingTTL = 24 * time.Hour
+)
+
+// Format identifies the application a CSV export comes from
+type Format string
+
+const (
+	Organizze Format = "ORGANIZZE"
+	Mobills   Format = "MOBILLS"
+	YNAB      Format = "YNAB"
+)
+
+// Values returns the supported formats, used by the enum validation
+func (Format) Values() []string {
+	return []string{string(Organizze), string(Mobills), string(YNAB)}
+}
+
+// Status is the stage of an import
+type Status string
+
+const (
+	StatusPending   Status = "PENDING"   // StatusPending waits for the user to confirm the category mapping
+	StatusImporting Status = "IMPORTING" // StatusImporting is adding the transactions to the account
+	StatusImported  Status = "IMPORTED"
+)
+
+// Adapter reads the CSV export of an application into rows
+type Adapter interface {
+	Format() Format
+	Parse(r io.Reader) ([]Row, error)
+}
+
+// Repository persists the imports between their upload and their confirmation
+type Repository interface {
+	// Save inserts the import, pruning the user's pending imports that expired
+	Save(ctx context.Context, imp *Import) error
+	FindByID(ctx context.Context, userID, importID uuid.UUID) (*Import, error)
+	// UpdateStatus moves the import from one status to another, reporting false when it was not in the expected status
+	UpdateStatus(ctx context.Context, importID uuid.UUID, from, to Status) (bool, error)
+	Delete(ctx context.Context, userID, importID uuid.UUID) error
+}
+
+// CategoryReader lists the categories the imported transactions can be mapped to
+type CategoryReader interface {
+	// FindCategories returns the categories visible to the user, its own and the default ones
+	FindCategories(ctx context.Context, userID uuid.UUID) ([]Category, error)
+}
+
+// Category is a fintrack category a source category can be mapped to
+type Category struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// Row is a transaction read from an export, in the terms of the source application
+type Row struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Observation string    `json:"observation,omitempty"`
+	Amount      string    `json:"amount"`   // Amount is a signed decimal string, negative for expenses (e.g., "-1.234,56")
+	Category    string    `json:"category"` // Category is the name of the category in the source application, empty when uncategorized
+}
+
+// Import is an uploaded export waiting for its categories to be mapped before its rows become transactions
+type Import struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Format    Format
+	Status    Status
+	Rows      []Row
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// SourceCategory is a category of the export, with the fintrack category suggested for it
+type SourceCategory struct {
+	Name                string
+	Transactions        int
+	SuggestedCategoryID *uuid.UUID // SuggestedCategoryID is a category with the same name, nil when none matched
+}
+
+// SourceCategories lists the distinct categories of the rows in order of appearance, suggesting a match for each
+// Names are compared without case nor accents, and "Group: Category" or "Group > Category" names match on their last part
+func (imp *Import) SourceCategories(categories []Category) []SourceCategory {
+	byName := make(map[string]uuid.UUID, len(categories))
+	for _, c := range categories {
+		byName[normalizeName(c.Name)] = c.ID
+	}
+
+	var result []SourceCategory
+	index := make(map[string]int)
+	for _, row := range imp.Rows {
+		if row.Category == "" {
+			continue
+		}
+		if i, ok := index[row.Category]; ok {
+			result[i].Transactions++
+			continue
+		}
+
+		source := SourceCategory{Name: row.Category, Transactions: 1}
+		if id, ok := byName[normalizeName(row.Category)]; ok {
+			source.SuggestedCategoryID = &id
+		} else if id, ok := byName[normalizeName(leafName(row.Category))]; ok {
+			source.SuggestedCategoryID = &id
+		}
+		index[row.Category] = len(result)
+		result = append(result, source)
+	}
+
+	return result
+}
+
+// leafName returns the last part of a hierarchical category name (e.g., "Casa: Aluguel" is "Aluguel")
+func leafName(name string) string {
+	if i := strings.LastIndexAny(name, ":>"); i >= 0 {
+		return strings.TrimSpace(name[i+1:])
+	}
+	return name
+}
+
+// accentReplacer strips the accents found in Portuguese and Spanish category names
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// normalizeName lowercases the name and strips its accents and surrounding spaces
+func normalizeName(name string) string {
+	return accentReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,229 @@
+package imports
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// sampleRows is the number of rows returned with an import, enough for the user to check the file was read correctly
+const sampleRows = 10
+
+// ImportHandler holds dependencies for import-related HTTP handlers
+type ImportHandler struct {
+	importService *Service
+}
+
+// NewImportHandler creates a new instance of ImportHandler
+func NewImportHandler(importService *Service) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// RegisterRoutes sets up the API routes for the imports module
+func (h *ImportHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	importsGroup := apiRouteGroup.Group("/imports")
+
+	importsGroup.POST("", h.uploadHandler)
+	importsGroup.GET("/:id", h.findImportHandler)
+	importsGroup.POST("/:id/confirm", h.confirmHandler)
+	importsGroup.DELETE("/:id", h.discardHandler)
+}
+
+// UploadRequest defines the expected multipart form fields for uploading an export, sent along its "file" field
+type UploadRequest struct {
+	Format    Format    `form:"format" validate:"required,enum"`
+	AccountID uuid.UUID `form:"account_id" validate:"required"`
+}
+
+// ConfirmRequest defines the expected JSON body for confirming the category mapping of an import
+type ConfirmRequest struct {
+	// Mappings maps each category of the file to a fintrack category ID, null leaves its transactions uncategorized
+	Mappings map[string]*uuid.UUID `json:"mappings" validate:"required"`
+}
+
+// SourceCategoryResponse defines a category of the imported file returned by the API
+type SourceCategoryResponse struct {
+	Name                string     `json:"name"`
+	Transactions        int        `json:"transactions"`
+	SuggestedCategoryID *uuid.UUID `json:"suggested_category_id"`
+}
+
+// RowResponse defines a row of the imported file returned by the API
+type RowResponse struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Observation string    `json:"observation,omitempty"`
+	Amount      string    `json:"amount"`
+	Category    string    `json:"category,omitempty"`
+}
+
+// ImportResponse defines the structure of an import returned by the API, with the first rows of its file
+type ImportResponse struct {
+	ID           uuid.UUID                `json:"id"`
+	AccountID    uuid.UUID                `json:"account_id"`
+	Format       Format                   `json:"format"`
+	Status       Status                   `json:"status"`
+	Transactions int                      `json:"transactions"`
+	Categories   []SourceCategoryResponse `json:"categories"`
+	Sample       []RowResponse            `json:"sample"`
+	CreatedAt    time.Time                `json:"created_at"`
+	ExpiresAt    time.Time                `json:"expires_at"`
+}
+
+// uploadHandler handles the HTTP request for uploading the CSV export of another application
+func (h *ImportHandler) uploadHandler(c echo.Context) error {
+	var req UploadRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the file field is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the file could not be read")
+	}
+	defer file.Close()
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := UploadParams{
+		UserID:    userID,
+		AccountID: req.AccountID,
+		Format:    req.Format,
+		File:      file,
+	}
+
+	preview, err := h.importService.Upload(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toImportResponse(preview))
+}
+
+// findImportHandler handles the HTTP request for finding an import with its category mapping suggestions
+func (h *ImportHandler) findImportHandler(c echo.Context) error {
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid import id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	preview, err := h.importService.Find(c.Request().Context(), userID, importID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toImportResponse(preview))
+}
+
+// confirmHandler handles the HTTP request for confirming the category mapping, replying 202 Accepted with the import job
+func (h *ImportHandler) confirmHandler(c echo.Context) error {
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid import id format")
+	}
+
+	var req ConfirmRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := ConfirmParams{
+		UserID:   userID,
+		ImportID: importID,
+		Mappings: req.Mappings,
+	}
+
+	job, err := h.importService.Confirm(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendAccepted(c, jobs.ToJobResponse(job))
+}
+
+// discardHandler handles the HTTP request for discarding an import
+func (h *ImportHandler) discardHandler(c echo.Context) error {
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid import id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.importService.Discard(c.Request().Context(), userID, importID); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toImportResponse maps a Preview to the public ImportResponse DTO
+func toImportResponse(p *Preview) ImportResponse {
+	imp := p.Import
+	resp := ImportResponse{
+		ID:           imp.ID,
+		AccountID:    imp.AccountID,
+		Format:       imp.Format,
+		Status:       imp.Status,
+		Transactions: len(imp.Rows),
+		Categories:   make([]SourceCategoryResponse, len(p.Categories)),
+		Sample:       make([]RowResponse, 0, min(len(imp.Rows), sampleRows)),
+		CreatedAt:    imp.CreatedAt,
+		ExpiresAt:    imp.ExpiresAt,
+	}
+	for i, c := range p.Categories {
+		resp.Categories[i] = SourceCategoryResponse{
+			Name:                c.Name,
+			Transactions:        c.Transactions,
+			SuggestedCategoryID: c.SuggestedCategoryID,
+		}
+	}
+	for _, row := range imp.Rows[:cap(resp.Sample)] {
+		resp.Sample = append(resp.Sample, RowResponse{
+			Date:        row.Date,
+			Description: row.Description,
+			Observation: row.Observation,
+			Amount:      row.Amount,
+			Category:    row.Category,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,34 @@
+package imports
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the imports repository, service and handler from the shared dependencies
+type Module struct {
+	handler *ImportHandler
+}
+
+// NewModule creates the imports module, adding the transactions through the ledger
+// Every supported format is available, further formats are added by passing their adapters
+func NewModule(deps module.Deps, accounts AccountImporter, adapters ...Adapter) *Module {
+	repo := NewPostgresRepository(deps.Postgres.Pool)
+	importSvc := NewService(repo, repo, accounts, deps.Jobs, deps.IDs, deps.Clock, append(DefaultAdapters(), adapters...)...)
+
+	return &Module{
+		handler: NewImportHandler(importSvc),
+	}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "imports"
+}
+
+// RegisterRoutes mounts the imports routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
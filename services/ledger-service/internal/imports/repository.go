@@ -0,0 +1,128 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ Repository     = (*PostgresRepository)(nil)
+	_ CategoryReader = (*PostgresRepository)(nil)
+)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository and CategoryReader interfaces
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// Save inserts the import with its rows, pruning the user's expired imports in the same transaction
+// Imports still adding their transactions are never pruned
+func (r *PostgresRepository) Save(ctx context.Context, imp *Import) error {
+	rows, err := json.Marshal(imp.Rows)
+	if err != nil {
+		return fmt.Errorf("failed to encode import rows: %w", err)
+	}
+
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		pruneQuery := `DELETE FROM imports WHERE user_id = $1 AND expires_at <= $2 AND status <> $3`
+		if _, err := tx.Exec(ctx, pruneQuery, imp.UserID, imp.CreatedAt, StatusImporting); err != nil {
+			return fmt.Errorf("failed to prune expired imports: %w", err)
+		}
+
+		insertQuery := `
+			INSERT INTO imports (id, user_id, account_id, format, status, data, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+		_, err := tx.Exec(ctx, insertQuery, imp.ID, imp.UserID, imp.AccountID, imp.Format, imp.Status, rows, imp.CreatedAt, imp.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert import: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindByID retrieves an import of the user with its rows
+func (r *PostgresRepository) FindByID(ctx context.Context, userID, importID uuid.UUID) (*Import, error) {
+	query := `
+		SELECT id, user_id, account_id, format, status, data, created_at, expires_at
+		FROM imports
+		WHERE id = $1 AND user_id = $2
+	`
+
+	var (
+		imp  Import
+		rows []byte
+	)
+	err := r.pool.QueryRow(ctx, query, importID, userID).Scan(
+		&imp.ID, &imp.UserID, &imp.AccountID, &imp.Format, &imp.Status, &rows, &imp.CreatedAt, &imp.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImportNotFound.With("import_id", importID)
+		}
+		return nil, fmt.Errorf("failed to fetch import: %w", err)
+	}
+	if err := json.Unmarshal(rows, &imp.Rows); err != nil {
+		return nil, fmt.Errorf("failed to decode import rows: %w", err)
+	}
+
+	return &imp, nil
+}
+
+// UpdateStatus moves the import from one status to another with a single conditional UPDATE
+func (r *PostgresRepository) UpdateStatus(ctx context.Context, importID uuid.UUID, from, to Status) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE imports SET status = $3 WHERE id = $1 AND status = $2`, importID, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to update import status: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Delete deletes an import of the user
+func (r *PostgresRepository) Delete(ctx context.Context, userID, importID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM imports WHERE id = $1 AND user_id = $2`, importID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete import: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrImportNotFound.With("import_id", importID)
+	}
+	return nil
+}
+
+// FindCategories retrieves the user's categories and the system-default ones
+func (r *PostgresRepository) FindCategories(ctx context.Context, userID uuid.UUID) ([]Category, error) {
+	// The user's categories come first, so they win over a default category of the same name
+	query := `SELECT id, name FROM categories WHERE user_id = $1 OR user_id IS NULL ORDER BY user_id NULLS LAST, name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]Category, 0)
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan category row: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category rows: %w", err)
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,252 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/google/uuid"
+)
+
+// importJobKind identifies the jobs adding the confirmed imports to the ledger
+const importJobKind = "transactions_import"
+
+// AccountImporter reads the account receiving an import and adds its transactions, satisfied by the ledger Service
+type AccountImporter interface {
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+	ImportTransactions(ctx context.Context, params ledger.ImportTransactionsParams) ([]ledger.Transaction, error)
+}
+
+// UploadParams holds all the required data for the Upload use case
+type UploadParams struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Format    Format
+	File      io.Reader
+}
+
+// ConfirmParams holds all the required data for the Confirm use case
+type ConfirmParams struct {
+	UserID   uuid.UUID
+	ImportID uuid.UUID
+	// Mappings maps every category of the file to a fintrack category, nil leaves its transactions uncategorized
+	Mappings map[string]*uuid.UUID
+}
+
+// Preview is an import with the categories of its file, for the user to confirm how they map
+type Preview struct {
+	Import     *Import
+	Categories []SourceCategory
+}
+
+// ImportResult is the result of an import job
+type ImportResult struct {
+	Imported int `json:"imported"`
+}
+
+// Service turns the CSV exports of other applications into ledger transactions, in two steps:
+// the file is uploaded and parsed, then its categories are mapped by the user and its rows imported
+type Service struct {
+	repo       Repository
+	categories CategoryReader
+	accounts   AccountImporter
+	jobs       *jobs.Service
+	adapters   map[Format]Adapter
+	ids        idgen.Generator
+	clock      clock.Clock
+}
+
+// NewService creates a new instance of the imports Service, reading the formats of the given adapters
+func NewService(repo Repository, categories CategoryReader, accounts AccountImporter, jobs *jobs.Service, ids idgen.Generator, clock clock.Clock, adapters ...Adapter) *Service {
+	byFormat := make(map[Format]Adapter, len(adapters))
+	for _, adapter := range adapters {
+		byFormat[adapter.Format()] = adapter
+	}
+
+	return &Service{
+		repo:       repo,
+		categories: categories,
+		accounts:   accounts,
+		jobs:       jobs,
+		adapters:   byFormat,
+		ids:        ids,
+		clock:      clock,
+	}
+}
+
+// Upload is the use case for parsing an export, kept pending until the user confirms its category mapping
+func (s *Service) Upload(ctx context.Context, params UploadParams) (*Preview, error) {
+	adapter, ok := s.adapters[params.Format]
+	if !ok {
+		return nil, ErrUnsupportedFormat.With("format", params.Format)
+	}
+
+	account, err := s.accounts.FindAccountByID(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account to import into: %w", err)
+	}
+	if err := account.EnsureWritable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := adapter.Parse(params.File)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	imp := &Import{
+		ID:        s.ids.NewID(),
+		UserID:    params.UserID,
+		AccountID: account.ID,
+		Format:    params.Format,
+		Status:    StatusPending,
+		Rows:      rows,
+		CreatedAt: now,
+		ExpiresAt: now.Add(PendingTTL),
+	}
+	if err := s.repo.Save(ctx, imp); err != nil {
+		return nil, fmt.Errorf("failed to save import: %w", err)
+	}
+
+	return s.preview(ctx, imp)
+}
+
+// Find is the use case for finding an import of the user with its category mapping suggestions
+func (s *Service) Find(ctx context.Context, userID, importID uuid.UUID) (*Preview, error) {
+	imp, err := s.find(ctx, userID, importID)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, imp)
+}
+
+// Confirm is the use case for importing the rows of a pending import with the category mapping chosen by the user
+// The rows are added to the account in the background, a failed job sets the import back to pending so it can be retried
+func (s *Service) Confirm(ctx context.Context, params ConfirmParams) (*jobs.Job, error) {
+	imp, err := s.find(ctx, params.UserID, params.ImportID)
+	if err != nil {
+		return nil, err
+	}
+	if imp.Status != StatusPending {
+		return nil, ErrImportNotPending.With("status", imp.Status)
+	}
+
+	transactions, err := s.mapRows(ctx, imp, params.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	started, err := s.repo.UpdateStatus(ctx, imp.ID, StatusPending, StatusImporting)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrImportNotPending
+	}
+
+	job, err := s.jobs.Enqueue(ctx, params.UserID, importJobKind, func(ctx context.Context, progress jobs.ProgressFunc) (any, error) {
+		imported, err := s.accounts.ImportTransactions(ctx, ledger.ImportTransactionsParams{
+			AccountID:    imp.AccountID,
+			UserID:       imp.UserID,
+			Source:       string(imp.Format),
+			Transactions: transactions,
+		})
+		if err != nil {
+			s.setStatus(ctx, imp.ID, StatusImporting, StatusPending)
+			return nil, err
+		}
+
+		s.setStatus(ctx, imp.ID, StatusImporting, StatusImported)
+		return ImportResult{Imported: len(imported)}, nil
+	})
+	if err != nil {
+		s.setStatus(ctx, imp.ID, StatusImporting, StatusPending)
+		return nil, fmt.Errorf("failed to start import: %w", err)
+	}
+
+	return job, nil
+}
+
+// Discard is the use case for deleting an import the user does not want to confirm
+func (s *Service) Discard(ctx context.Context, userID, importID uuid.UUID) error {
+	if err := s.repo.Delete(ctx, userID, importID); err != nil {
+		return fmt.Errorf("failed to discard import: %w", err)
+	}
+	return nil
+}
+
+// find returns an import of the user, pending imports past their expiration are not found anymore
+func (s *Service) find(ctx context.Context, userID, importID uuid.UUID) (*Import, error) {
+	imp, err := s.repo.FindByID(ctx, userID, importID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find import: %w", err)
+	}
+	if imp.Status == StatusPending && !s.clock.Now().Before(imp.ExpiresAt) {
+		return nil, ErrImportNotFound.With("import_id", importID)
+	}
+	return imp, nil
+}
+
+// preview suggests a fintrack category for each category of the import
+func (s *Service) preview(ctx context.Context, imp *Import) (*Preview, error) {
+	categories, err := s.categories.FindCategories(ctx, imp.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categories to suggest: %w", err)
+	}
+	return &Preview{Import: imp, Categories: imp.SourceCategories(categories)}, nil
+}
+
+// mapRows checks every category of the import is mapped to a category of the user, then maps the rows to transactions
+func (s *Service) mapRows(ctx context.Context, imp *Import, mappings map[string]*uuid.UUID) ([]ledger.ImportedTransaction, error) {
+	categories, err := s.categories.FindCategories(ctx, imp.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categories to map: %w", err)
+	}
+	visible := make(map[uuid.UUID]bool, len(categories))
+	for _, c := range categories {
+		visible[c.ID] = true
+	}
+
+	transactions := make([]ledger.ImportedTransaction, len(imp.Rows))
+	for i, row := range imp.Rows {
+		var categoryID *uuid.UUID
+		if row.Category != "" {
+			mapped, ok := mappings[row.Category]
+			if !ok {
+				return nil, ErrUnmappedCategory.With("category", row.Category)
+			}
+			if mapped != nil && !visible[*mapped] {
+				return nil, ErrCategoryNotFound.With("category_id", *mapped)
+			}
+			categoryID = mapped
+		}
+
+		transactions[i] = ledger.ImportedTransaction{
+			CategoryID:  categoryID,
+			Description: row.Description,
+			Observation: row.Observation,
+			Amount:      row.Amount,
+			Date:        row.Date,
+		}
+	}
+
+	return transactions, nil
+}
+
+// setStatus moves the import to its next status from a job, where a failure can only be logged
+func (s *Service) setStatus(ctx context.Context, importID uuid.UUID, from, to Status) {
+	if _, err := s.repo.UpdateStatus(ctx, importID, from, to); err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to update import status",
+			slog.String("import_id", importID.String()),
+			slog.String("status", string(to)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
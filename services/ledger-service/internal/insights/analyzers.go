@@ -0,0 +1,236 @@
+package insights
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+)
+
+var (
+	_ Analyzer = PriceIncreaseAnalyzer{}
+	_ Analyzer = DuplicateChargeAnalyzer{}
+	_ Analyzer = CategoryTrendAnalyzer{}
+)
+
+const day = 24 * time.Hour
+
+// DefaultAnalyzers returns the built-in analyzers with the thresholds of the insights endpoint
+func DefaultAnalyzers() []Analyzer {
+	return []Analyzer{
+		PriceIncreaseAnalyzer{MinCharges: 3, Recent: 45 * day},
+		DuplicateChargeAnalyzer{Window: 3 * day, Recent: 30 * day},
+		CategoryTrendAnalyzer{Months: 3, MinIncreasePercent: 25},
+	}
+}
+
+// PriceIncreaseAnalyzer finds the subscriptions whose latest charge costs more than the previous ones
+// A subscription is a merchant charged to an account in at least MinCharges different months, always the same price
+// before the latest charge, which must be dated within Recent of now
+type PriceIncreaseAnalyzer struct {
+	MinCharges int
+	Recent     time.Duration
+}
+
+// Analyze compares the latest charge of each subscription with its former price
+func (a PriceIncreaseAnalyzer) Analyze(_ context.Context, snapshot *Snapshot) ([]Insight, error) {
+	insights := make([]Insight, 0)
+	for _, charges := range groupCharges(snapshot.Expenses(), false) {
+		if len(charges) < a.MinCharges {
+			continue
+		}
+		latest, recurring := charges[len(charges)-1], charges[len(charges)-a.MinCharges:]
+		if snapshot.Now.Sub(latest.DueDate) > a.Recent || !distinctMonths(recurring) {
+			continue
+		}
+
+		previous := recurring[len(recurring)-2]
+		stable := !slices.ContainsFunc(recurring[:len(recurring)-1], func(e Expense) bool { return e.Amount != previous.Amount })
+		// Expenses are negative, a lower amount costs more
+		if !stable || latest.Amount.Amount >= previous.Amount.Amount {
+			continue
+		}
+
+		accountID := latest.AccountID
+		insights = append(insights, Insight{
+			Kind:           KindPriceIncrease,
+			Severity:       SeverityInfo,
+			AccountID:      &accountID,
+			Description:    latest.Description,
+			Amount:         cost(latest.Amount),
+			PreviousAmount: cost(previous.Amount),
+			Date:           latest.DueDate,
+			TransactionIDs: []uuid.UUID{previous.ID, latest.ID},
+		})
+	}
+	return insights, nil
+}
+
+// DuplicateChargeAnalyzer finds the expenses recorded more than once: same account, merchant and amount,
+// each charge at most Window after the previous one, the latest dated within Recent of now
+type DuplicateChargeAnalyzer struct {
+	Window time.Duration
+	Recent time.Duration
+}
+
+// Analyze groups the identical charges close in time
+func (a DuplicateChargeAnalyzer) Analyze(_ context.Context, snapshot *Snapshot) ([]Insight, error) {
+	insights := make([]Insight, 0)
+	for _, charges := range groupCharges(snapshot.Expenses(), true) {
+		start := 0
+		for i := 1; i <= len(charges); i++ {
+			if i < len(charges) && charges[i].DueDate.Sub(charges[i-1].DueDate) <= a.Window {
+				continue
+			}
+
+			duplicates := charges[start:i]
+			start = i
+			latest := duplicates[len(duplicates)-1]
+			if len(duplicates) < 2 || snapshot.Now.Sub(latest.DueDate) > a.Recent {
+				continue
+			}
+
+			accountID := latest.AccountID
+			insight := Insight{
+				Kind:           KindDuplicateCharge,
+				Severity:       SeverityWarning,
+				AccountID:      &accountID,
+				Description:    latest.Description,
+				Amount:         cost(latest.Amount),
+				PreviousAmount: money.Zero(latest.Amount.Currency),
+				Date:           latest.DueDate,
+				TransactionIDs: make([]uuid.UUID, 0, len(duplicates)),
+			}
+			for _, d := range duplicates {
+				insight.TransactionIDs = append(insight.TransactionIDs, d.ID)
+			}
+			insights = append(insights, insight)
+		}
+	}
+	return insights, nil
+}
+
+// CategoryTrendAnalyzer finds the categories whose spending in the last complete financial month exceeds
+// by at least MinIncreasePercent their average over the Months before it, all of them with some spending
+type CategoryTrendAnalyzer struct {
+	Months             int
+	MinIncreasePercent int64
+}
+
+// categoryCurrency keys the spending of a category, the accounts of a user possibly differing in currency
+type categoryCurrency struct {
+	categoryID uuid.UUID
+	currency   string
+}
+
+// Analyze compares the spending of each category in the last month with its average
+func (a CategoryTrendAnalyzer) Analyze(_ context.Context, snapshot *Snapshot) ([]Insight, error) {
+	// starts[0] is the start of the last complete month, starts[i] the start of the month i months before it
+	current, _ := snapshot.Period.RangeIn(snapshot.Now, time.UTC)
+	starts := make([]time.Time, a.Months+1)
+	for i := range starts {
+		named := time.Date(current.Year(), current.Month()-time.Month(i+1), 1, 0, 0, 0, 0, time.UTC)
+		starts[i], _ = snapshot.Period.MonthRangeIn(named.Year(), named.Month(), time.UTC)
+	}
+
+	spending := make(map[categoryCurrency][]int64)
+	lastMonth := make(map[categoryCurrency][]uuid.UUID)
+	for _, e := range snapshot.Expenses() {
+		if e.CategoryID == nil || !e.DueDate.Before(current) {
+			continue
+		}
+		month := slices.IndexFunc(starts, func(start time.Time) bool { return !e.DueDate.Before(start) })
+		if month < 0 {
+			continue
+		}
+
+		key := categoryCurrency{categoryID: *e.CategoryID, currency: e.Amount.Currency}
+		if spending[key] == nil {
+			spending[key] = make([]int64, a.Months+1)
+		}
+		spending[key][month] -= e.Amount.Amount
+		if month == 0 {
+			lastMonth[key] = append(lastMonth[key], e.ID)
+		}
+	}
+
+	insights := make([]Insight, 0)
+	for key, months := range spending {
+		previous := months[1:]
+		if slices.ContainsFunc(previous, func(spent int64) bool { return spent <= 0 }) {
+			continue
+		}
+		var total int64
+		for _, spent := range previous {
+			total += spent
+		}
+		average := total / int64(len(previous))
+		if months[0]*100 < average*(100+a.MinIncreasePercent) {
+			continue
+		}
+
+		categoryID := key.categoryID
+		insights = append(insights, Insight{
+			Kind:           KindCategoryTrend,
+			Severity:       SeverityInfo,
+			CategoryID:     &categoryID,
+			Amount:         money.New(months[0], key.currency),
+			PreviousAmount: money.New(average, key.currency),
+			Date:           starts[0],
+			TransactionIDs: lastMonth[key],
+		})
+	}
+	return insights, nil
+}
+
+// groupCharges groups the expenses charged to a same account by a same merchant, and of a same amount when byAmount,
+// each group ordered by due date
+func groupCharges(expenses []Expense, byAmount bool) [][]Expense {
+	type chargeGroup struct {
+		accountID uuid.UUID
+		merchant  string
+		amount    money.Money
+	}
+
+	groups := make(map[chargeGroup][]Expense)
+	order := make([]chargeGroup, 0)
+	for _, e := range expenses {
+		key := chargeGroup{accountID: e.AccountID, merchant: chargeKey(e.Description)}
+		if key.merchant == "" {
+			continue
+		}
+		if byAmount {
+			key.amount = e.Amount
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	charges := make([][]Expense, 0, len(order))
+	for _, key := range order {
+		group := groups[key]
+		slices.SortStableFunc(group, func(a, b Expense) int { return a.DueDate.Compare(b.DueDate) })
+		charges = append(charges, group)
+	}
+	return charges
+}
+
+// distinctMonths reports whether the charges, ordered by due date, fall on different calendar months
+func distinctMonths(charges []Expense) bool {
+	for i := 1; i < len(charges); i++ {
+		previous, current := charges[i-1].DueDate.UTC(), charges[i].DueDate.UTC()
+		if previous.Year() == current.Year() && previous.Month() == current.Month() {
+			return false
+		}
+	}
+	return true
+}
+
+// cost returns the positive amount of an expense
+func cost(amount money.Money) money.Money {
+	return money.New(-amount.Amount, amount.Currency)
+}
@@ -0,0 +1,96 @@
+package insights
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// Kind names the rule that found an insight, so clients can word and illustrate it
+type Kind string
+
+const (
+	KindPriceIncrease   Kind = "SUBSCRIPTION_PRICE_INCREASE" // KindPriceIncrease is a recurring charge costing more than it used to
+	KindDuplicateCharge Kind = "DUPLICATE_CHARGE"            // KindDuplicateCharge is the same charge recorded more than once within a few days
+	KindCategoryTrend   Kind = "CATEGORY_TRENDING_UP"        // KindCategoryTrend is a category costing clearly more than in the previous months
+)
+
+// Severity tells how urgently the user should look at an insight
+type Severity string
+
+const (
+	SeverityInfo    Severity = "INFO"    // SeverityInfo is worth knowing, nothing may be wrong
+	SeverityWarning Severity = "WARNING" // SeverityWarning likely calls for an action (e.g., asking for a refund)
+)
+
+// Insight is a finding about the ledger of a user, with the transactions it is based on
+type Insight struct {
+	Kind           Kind
+	Severity       Severity
+	AccountID      *uuid.UUID  // AccountID is nil for findings spanning every account (e.g., category trends)
+	CategoryID     *uuid.UUID  // CategoryID is set for category trends
+	Description    string      // Description is the charge the finding is about, as the user recorded it
+	Amount         money.Money // Amount is the new price, the duplicated charge or the spending of the last month, positive
+	PreviousAmount money.Money // PreviousAmount is the former price or the monthly average the amount is compared to, zero when unused
+	Date           time.Time   // Date is when the finding happened: the latest charge or the start of the last month
+	TransactionIDs []uuid.UUID
+}
+
+// Snapshot is the part of the ledger the analyzers look at, read once per request and shared by every analyzer
+type Snapshot struct {
+	UserID   uuid.UUID
+	Now      time.Time
+	Period   clock.AccountingPeriod // Period is the financial month of the user, monthly figures follow it
+	Accounts []*ledger.Account      // Accounts are the active accounts of the user, with their transactions
+}
+
+// Expenses returns the expenses of every account due up to now, the scheduled ones not being charged yet
+func (s *Snapshot) Expenses() []Expense {
+	expenses := make([]Expense, 0)
+	for _, account := range s.Accounts {
+		for _, tx := range account.Transactions() {
+			if tx.Type != ledger.Expense || tx.DueDate.After(s.Now) {
+				continue
+			}
+			expenses = append(expenses, Expense{AccountID: account.ID, Transaction: tx})
+		}
+	}
+	return expenses
+}
+
+// Expense is an expense of the snapshot along the account it was charged to
+type Expense struct {
+	AccountID uuid.UUID
+	ledger.Transaction
+}
+
+// Analyzer finds insights of one kind in the ledger of a user, the analyzers of the pipeline running in turn over the same snapshot
+type Analyzer interface {
+	Analyze(ctx context.Context, snapshot *Snapshot) ([]Insight, error)
+}
+
+// AccountReader loads the accounts the analyzers look at, satisfied by the ledger Service
+type AccountReader interface {
+	FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*ledger.Account, error)
+}
+
+// chargeKey reduces a description to the merchant it names, so the charges of a same merchant group together
+// even when the bank appends dates or installment numbers (e.g., "NETFLIX.COM 12/03" and "Netflix.com" match)
+func chargeKey(description string) string {
+	words := make([]string, 0)
+	for _, word := range strings.Fields(strings.ToLower(description)) {
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			continue
+		}
+		if word = strings.TrimFunc(word, unicode.IsPunct); word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
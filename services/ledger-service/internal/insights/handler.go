@@ -0,0 +1,91 @@
+package insights
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// InsightHandler holds dependencies for insight-related HTTP handlers
+type InsightHandler struct {
+	insightService *Service
+}
+
+// NewInsightHandler creates a new instance of InsightHandler
+func NewInsightHandler(insightService *Service) *InsightHandler {
+	return &InsightHandler{insightService: insightService}
+}
+
+// RegisterRoutes sets up the API routes for the insights module
+func (h *InsightHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.GET("/insights", h.insightsHandler)
+}
+
+// InsightResponse defines the structure of an insight returned by the API
+// Amounts are expressed in minor units of the currency, as positive costs
+type InsightResponse struct {
+	Kind           Kind        `json:"kind"`
+	Severity       Severity    `json:"severity"`
+	AccountID      *uuid.UUID  `json:"account_id,omitempty"`
+	CategoryID     *uuid.UUID  `json:"category_id,omitempty"`
+	Description    string      `json:"description,omitempty"`
+	Currency       string      `json:"currency"`
+	Amount         int64       `json:"amount"`
+	PreviousAmount int64       `json:"previous_amount,omitempty"`
+	Date           time.Time   `json:"date"`
+	TransactionIDs []uuid.UUID `json:"transaction_ids"`
+}
+
+// insightsHandler handles the HTTP request for the insights about the user's ledger
+func (h *InsightHandler) insightsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	insights, err := h.insightService.Insights(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]InsightResponse, 0, len(insights))
+	for _, insight := range insights {
+		resp = append(resp, toInsightResponse(insight))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// currentUserID returns the ID of the authenticated user, set in the request context by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toInsightResponse maps the internal Insight domain model to the public InsightResponse DTO
+func toInsightResponse(i Insight) InsightResponse {
+	transactionIDs := i.TransactionIDs
+	if transactionIDs == nil {
+		transactionIDs = make([]uuid.UUID, 0)
+	}
+
+	return InsightResponse{
+		Kind:           i.Kind,
+		Severity:       i.Severity,
+		AccountID:      i.AccountID,
+		CategoryID:     i.CategoryID,
+		Description:    i.Description,
+		Currency:       i.Amount.Currency,
+		Amount:         i.Amount.Amount,
+		PreviousAmount: i.PreviousAmount.Amount,
+		Date:           i.Date,
+		TransactionIDs: transactionIDs,
+	}
+}
@@ -0,0 +1,30 @@
+package insights
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the insights service and handler from the shared dependencies
+type Module struct {
+	handler *InsightHandler
+}
+
+// NewModule creates the insights module, running the analyzers over the accounts read from the ledger
+func NewModule(deps module.Deps, accounts AccountReader, analyzers ...Analyzer) *Module {
+	return &Module{
+		handler: NewInsightHandler(NewService(accounts, deps.Periods, deps.Clock, analyzers...)),
+	}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "insights"
+}
+
+// RegisterRoutes mounts the insights routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,69 @@
+package insights
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/google/uuid"
+)
+
+// Service runs the analyzers pipeline over the ledger of the users
+type Service struct {
+	accounts  AccountReader
+	periods   module.AccountingPeriods
+	analyzers []Analyzer
+	clock     clock.Clock
+}
+
+// NewService creates a new instance of the insights Service, the analyzers running in the given order
+func NewService(accounts AccountReader, periods module.AccountingPeriods, clock clock.Clock, analyzers ...Analyzer) *Service {
+	return &Service{
+		accounts:  accounts,
+		periods:   periods,
+		analyzers: analyzers,
+		clock:     clock,
+	}
+}
+
+// Insights is the use case for finding what deserves the attention of the user in their ledger
+// The warnings come first, then the most recent findings
+func (s *Service) Insights(ctx context.Context, userID uuid.UUID) ([]Insight, error) {
+	accounts, err := s.accounts.FindAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounts to analyze: %w", err)
+	}
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	snapshot := &Snapshot{UserID: userID, Now: s.clock.Now(), Period: period, Accounts: accounts}
+	insights := make([]Insight, 0)
+	for _, analyzer := range s.analyzers {
+		found, err := analyzer.Analyze(ctx, snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze ledger with %T: %w", analyzer, err)
+		}
+		insights = append(insights, found...)
+	}
+
+	slices.SortStableFunc(insights, func(a, b Insight) int {
+		if a.Severity != b.Severity {
+			return severityRank(a.Severity) - severityRank(b.Severity)
+		}
+		return cmp.Or(b.Date.Compare(a.Date), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Description, b.Description), cmp.Compare(b.Amount.Amount, a.Amount.Amount))
+	})
+	return insights, nil
+}
+
+// severityRank orders the warnings before the other insights
+func severityRank(severity Severity) int {
+	if severity == SeverityWarning {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,262 @@
+package interest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrYieldNotFound           = errx.New(errx.CategoryNotFound, "YIELD_NOT_FOUND", "no yield rate is set for the account")
+	ErrAccountNotSavings       = errx.New(errx.CategoryValidation, "ACCOUNT_NOT_SAVINGS", "yield rates can only be set on savings accounts")
+	ErrInvalidYieldRate        = errx.New(errx.CategoryValidation, "INVALID_YIELD_RATE", "yield rate must be a positive percentage up to 1000 with at most 4 decimal places")
+	ErrInvalidSimulationPeriod = errx.New(errx.CategoryValidation, "INVALID_SIMULATION_PERIOD", "the simulation must start on or before the month it ends")
+	ErrSimulationPeriodTooLong = errx.New(errx.CategoryValidation, "SIMULATION_PERIOD_TOO_LONG", "the simulation period is too long")
+)
+
+const (
+	CDIPercent YieldType = "CDI_PERCENT" // The Rate is a percentage of the CDI (e.g., 110 for 110% of the CDI)
+	FixedRate  YieldType = "FIXED_RATE"  // The Rate is an annual percentage (e.g., 12.5 for 12.5% a year)
+)
+
+const (
+	// RateDecimals is the number of decimal places kept by a Rate
+	RateDecimals = 4
+	// rateScale is 10^RateDecimals, the Rate value of one percent
+	rateScale = 10_000
+	// maxRate bounds the rates accepted, 1000% being far above any real yield
+	maxRate = 1000 * rateScale
+
+	// MaxSimulationMonths bounds the months of a simulation
+	MaxSimulationMonths = 120
+
+	// businessDaysPerYear is the convention the CDI and the Brazilian fixed income rates are quoted in
+	businessDaysPerYear = 252
+	// interestScale is the fraction of the minor unit the daily interest is accumulated in before being rounded
+	interestScale = 10_000
+)
+
+// YieldType tells how the rate of a savings account is quoted
+type YieldType string
+
+// Values lists every valid YieldType, satisfying validatorx.Enum
+func (YieldType) Values() []string {
+	return []string{string(CDIPercent), string(FixedRate)}
+}
+
+// Rate is a percentage as a fixed-point number with RateDecimals decimal places
+type Rate int64
+
+// ParseRate reads a positive percentage using a dot as decimal separator (e.g., "110" or "14.65")
+func ParseRate(value string) (Rate, error) {
+	invalid := func() (Rate, error) {
+		return 0, ErrInvalidYieldRate.With("input", value)
+	}
+
+	whole, frac, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if whole == "" || len(frac) > RateDecimals || strings.ContainsAny(whole+frac, "+-") {
+		return invalid()
+	}
+
+	parsed, err := strconv.ParseInt(whole+frac+strings.Repeat("0", RateDecimals-len(frac)), 10, 64)
+	if err != nil || parsed <= 0 || parsed > maxRate {
+		return invalid()
+	}
+	return Rate(parsed), nil
+}
+
+// String returns the rate as a plain decimal without trailing zeros (e.g., "14.65")
+func (r Rate) String() string {
+	s := strconv.FormatInt(int64(r)/rateScale, 10)
+	if frac := int64(r) % rateScale; frac != 0 {
+		s += "." + strings.TrimRight(strconv.FormatInt(frac+rateScale, 10)[1:], "0")
+	}
+	return s
+}
+
+// fraction returns the rate as a fraction of one (e.g., 0.1465 for 14.65%)
+func (r Rate) fraction() float64 {
+	return float64(r) / (100 * rateScale)
+}
+
+// Yield is the rate the balance of a savings account earns
+type Yield struct {
+	AccountID uuid.UUID
+	UserID    uuid.UUID
+	Type      YieldType
+	Rate      Rate
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewYield creates the Yield of a savings account
+func NewYield(userID, accountID uuid.UUID, yieldType YieldType, rate Rate, clock clock.Clock) (*Yield, error) {
+	now := clock.Now()
+	y := &Yield{
+		AccountID: accountID,
+		UserID:    userID,
+		CreatedAt: now,
+	}
+	if err := y.Update(yieldType, rate, clock); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// Update replaces how the rate is quoted and its value
+func (y *Yield) Update(yieldType YieldType, rate Rate, clock clock.Clock) error {
+	if rate <= 0 || rate > maxRate {
+		return ErrInvalidYieldRate.With("rate", rate.String())
+	}
+
+	y.Type = yieldType
+	y.Rate = rate
+	y.UpdatedAt = clock.Now()
+	return nil
+}
+
+// dailyRate is the rate earned on each business day, the CDI percentage applying to the daily CDI as B3 computes it
+func (y *Yield) dailyRate(cdi Rate) float64 {
+	if y.Type == CDIPercent {
+		return (math.Pow(1+cdi.fraction(), 1.0/businessDaysPerYear) - 1) * y.Rate.fraction()
+	}
+	return math.Pow(1+y.Rate.fraction(), 1.0/businessDaysPerYear) - 1
+}
+
+// Repository persists the yields of the savings accounts
+type Repository interface {
+	Save(ctx context.Context, yield *Yield) error
+	// FindByAccountID returns the yield of the account, nil when none is set
+	FindByAccountID(ctx context.Context, accountID uuid.UUID) (*Yield, error)
+	Delete(ctx context.Context, accountID uuid.UUID) error
+}
+
+// MonthlyInterest is the interest a savings account earns over a month, credited on its last day
+type MonthlyInterest struct {
+	Month          time.Time // Month is the first day of the month
+	OpeningBalance money.Money
+	NetFlow        money.Money // NetFlow sums the deposits and withdrawals of the month
+	Interest       money.Money
+	ClosingBalance money.Money
+	BusinessDays   int
+	Projected      bool       // Projected months have not ended, their flows include the pending transactions
+	TransactionID  *uuid.UUID // TransactionID is the interest transaction posted to the account, nil until posted
+}
+
+// InterestTransactionID is the ID of the interest transaction of the account for a month, derived from both
+// so the interest of a month is posted at most once
+func InterestTransactionID(accountID uuid.UUID, month time.Time) uuid.UUID {
+	return uuid.NewSHA1(accountID, []byte("interest:"+month.Format("2006-01")))
+}
+
+// InterestDescription is the description of the interest transaction of a month
+func InterestDescription(month time.Time) string {
+	return fmt.Sprintf("Rendimento %s", month.Format("2006-01"))
+}
+
+// simulate computes the interest of the account month by month from the first day of from to the last day of to,
+// compounded on every business day and credited at the end of each month. The months whose interest was already
+// posted keep the posted amount
+// Weekends are skipped but not the bank holidays, and a negative balance earns nothing
+func simulate(account *ledger.Account, yield *Yield, cdi Rate, from, to, today time.Time, rounding money.RoundingPolicy) ([]MonthlyInterest, error) {
+	from, to, today = startOfMonth(from), startOfMonth(to), startOfDay(today)
+	end := to.AddDate(0, 1, 0)
+
+	posted := make(map[uuid.UUID]money.Money)
+	for month := from; month.Before(end); month = month.AddDate(0, 1, 0) {
+		posted[InterestTransactionID(account.ID, month)] = money.Money{}
+	}
+
+	opening := money.Zero(account.Currency)
+	flows := make(map[time.Time]int64)
+	for _, tx := range account.Transactions() {
+		if _, ok := posted[tx.ID]; ok {
+			posted[tx.ID] = tx.Amount
+			continue
+		}
+		day := effectiveDate(tx, today)
+		if day.Before(from) {
+			var err error
+			if opening, err = opening.Add(tx.Amount); err != nil {
+				return nil, err
+			}
+		} else if day.Before(end) {
+			flows[day] += tx.Amount.Amount
+		}
+	}
+
+	daily := yield.dailyRate(cdi)
+	var months []MonthlyInterest
+	for month := from; month.Before(end); month = month.AddDate(0, 1, 0) {
+		next := month.AddDate(0, 1, 0)
+		result := MonthlyInterest{Month: month, OpeningBalance: opening, Projected: !next.AddDate(0, 0, -1).Before(today)}
+
+		balance, earned, netFlow := float64(opening.Amount), 0.0, int64(0)
+		for day := month; day.Before(next); day = day.AddDate(0, 0, 1) {
+			balance += float64(flows[day])
+			netFlow += flows[day]
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				continue
+			}
+			result.BusinessDays++
+			if balance > 0 {
+				accrued := balance * daily
+				balance += accrued
+				earned += accrued
+			}
+		}
+
+		txID := InterestTransactionID(account.ID, month)
+		if amount := posted[txID]; amount.Currency != "" {
+			result.Interest = amount
+			result.TransactionID = &txID
+		} else {
+			interest, err := rounding.Scale(money.New(int64(math.Round(earned*interestScale)), account.Currency), 1, interestScale)
+			if err != nil {
+				return nil, err
+			}
+			result.Interest = interest
+		}
+
+		result.NetFlow = money.New(netFlow, account.Currency)
+		closing, err := money.Sum(account.Currency, opening, result.NetFlow, result.Interest)
+		if err != nil {
+			return nil, err
+		}
+		result.ClosingBalance = closing
+		months = append(months, result)
+		opening = closing
+	}
+	return months, nil
+}
+
+// effectiveDate is the day the transaction moves the balance: the day it was paid, or for a pending one its due
+// date, the overdue ones being expected today
+func effectiveDate(tx ledger.Transaction, today time.Time) time.Time {
+	if tx.PaidAt != nil {
+		return startOfDay(*tx.PaidAt)
+	}
+	if due := startOfDay(tx.DueDate); due.After(today) {
+		return due
+	}
+	return today
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,237 @@
+package interest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// InterestHandler holds dependencies for the interest HTTP handlers
+type InterestHandler struct {
+	interestService *Service
+}
+
+// NewInterestHandler creates a new instance of InterestHandler
+func NewInterestHandler(interestService *Service) *InterestHandler {
+	return &InterestHandler{interestService: interestService}
+}
+
+// RegisterRoutes sets up the API routes for the interest module
+func (h *InterestHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	interestGroup := apiRouteGroup.Group("/interest")
+
+	interestGroup.GET("/accounts/:accountId/yield", h.getYieldHandler)
+	interestGroup.PUT("/accounts/:accountId/yield", h.setYieldHandler)
+	interestGroup.DELETE("/accounts/:accountId/yield", h.deleteYieldHandler)
+	interestGroup.POST("/accounts/:accountId/simulations", h.simulateHandler)
+}
+
+// SetYieldRequest defines the expected JSON body for setting the yield of a savings account
+type SetYieldRequest struct {
+	Type YieldType `json:"type" validate:"required,enum"`
+	Rate string    `json:"rate" validate:"required,max=16"` // Percentage with a dot separator (e.g., "110" of the CDI or "12.5" a year)
+}
+
+// SimulateRequest defines the expected JSON body for simulating the interest of a savings account
+type SimulateRequest struct {
+	From               string `json:"from" validate:"required"` // First month simulated (e.g., "2025-01")
+	To                 string `json:"to" validate:"required"`   // Last month simulated (e.g., "2025-12")
+	CreateTransactions bool   `json:"create_transactions"`      // Posts the interest of the months that ended as income transactions
+}
+
+// YieldResponse defines the structure of a yield returned by the API
+type YieldResponse struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Type      YieldType `json:"type"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SimulationResponse defines the structure of an interest simulation returned by the API
+// Amounts are expressed in minor units of the currency
+type SimulationResponse struct {
+	AccountID     uuid.UUID                 `json:"account_id"`
+	Currency      string                    `json:"currency"`
+	Yield         YieldResponse             `json:"yield"`
+	CDI           string                    `json:"cdi"` // CDI is the annual CDI percentage assumed
+	Months        []MonthlyInterestResponse `json:"months"`
+	TotalInterest int64                     `json:"total_interest"`
+	Created       int                       `json:"created"` // Created is how many interest transactions were posted
+}
+
+// MonthlyInterestResponse defines the structure of the interest of a month returned by the API
+type MonthlyInterestResponse struct {
+	Month          string     `json:"month"`
+	OpeningBalance int64      `json:"opening_balance"`
+	NetFlow        int64      `json:"net_flow"`
+	Interest       int64      `json:"interest"`
+	ClosingBalance int64      `json:"closing_balance"`
+	BusinessDays   int        `json:"business_days"`
+	Projected      bool       `json:"projected"`
+	TransactionID  *uuid.UUID `json:"transaction_id,omitempty"`
+}
+
+// getYieldHandler handles the HTTP request for finding the yield of a savings account
+func (h *InterestHandler) getYieldHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	yield, err := h.interestService.GetYield(c.Request().Context(), userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toYieldResponse(yield))
+}
+
+// setYieldHandler handles the HTTP request for setting or replacing the yield of a savings account
+func (h *InterestHandler) setYieldHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req SetYieldRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SetYieldParams{
+		UserID:    userID,
+		AccountID: accountID,
+		Type:      req.Type,
+		Rate:      req.Rate,
+	}
+
+	yield, err := h.interestService.SetYield(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toYieldResponse(yield))
+}
+
+// deleteYieldHandler handles the HTTP request for removing the yield of a savings account
+func (h *InterestHandler) deleteYieldHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.interestService.DeleteYield(c.Request().Context(), userID, accountID); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// simulateHandler handles the HTTP request for simulating the interest of a savings account over a period of months
+func (h *InterestHandler) simulateHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req SimulateRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+	from, err := time.Parse("2006-01", req.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid month format, expected YYYY-MM")
+	}
+	to, err := time.Parse("2006-01", req.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid month format, expected YYYY-MM")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SimulateParams{
+		UserID:             userID,
+		AccountID:          accountID,
+		From:               from,
+		To:                 to,
+		CreateTransactions: req.CreateTransactions,
+	}
+
+	simulation, err := h.interestService.Simulate(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toSimulationResponse(simulation))
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toYieldResponse maps a Yield to the public YieldResponse DTO
+func toYieldResponse(y *Yield) YieldResponse {
+	return YieldResponse{
+		AccountID: y.AccountID,
+		Type:      y.Type,
+		Rate:      y.Rate.String(),
+		UpdatedAt: y.UpdatedAt,
+	}
+}
+
+// toSimulationResponse maps a Simulation to the public SimulationResponse DTO
+func toSimulationResponse(s *Simulation) SimulationResponse {
+	months := make([]MonthlyInterestResponse, len(s.Months))
+	for i, m := range s.Months {
+		months[i] = MonthlyInterestResponse{
+			Month:          m.Month.Format("2006-01"),
+			OpeningBalance: m.OpeningBalance.Amount,
+			NetFlow:        m.NetFlow.Amount,
+			Interest:       m.Interest.Amount,
+			ClosingBalance: m.ClosingBalance.Amount,
+			BusinessDays:   m.BusinessDays,
+			Projected:      m.Projected,
+			TransactionID:  m.TransactionID,
+		}
+	}
+	return SimulationResponse{
+		AccountID:     s.AccountID,
+		Currency:      s.TotalInterest.Currency,
+		Yield:         toYieldResponse(s.Yield),
+		CDI:           s.CDI.String(),
+		Months:        months,
+		TotalInterest: s.TotalInterest.Amount,
+		Created:       s.Created,
+	}
+}
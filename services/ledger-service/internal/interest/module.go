@@ -0,0 +1,30 @@
+package interest
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the yield repository, service and handler from the shared dependencies
+type Module struct {
+	handler *InterestHandler
+}
+
+// NewModule creates the interest module, the CDI_PERCENT yields following the annual cdi
+func NewModule(deps module.Deps, accounts AccountImporter, cdi Rate) *Module {
+	interestSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), accounts, cdi, deps.Audit, deps.Rounding, deps.Clock)
+
+	return &Module{handler: NewInterestHandler(interestSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "interest"
+}
+
+// RegisterRoutes mounts the interest routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,67 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// Save inserts the yield of an account or replaces its rate
+func (r *PostgresRepository) Save(ctx context.Context, yield *Yield) error {
+	query := `
+		INSERT INTO account_yields (account_id, user_id, yield_type, rate, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_id)
+		DO UPDATE SET
+			yield_type = EXCLUDED.yield_type,
+			rate = EXCLUDED.rate,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, yield.AccountID, yield.UserID, yield.Type, int64(yield.Rate), yield.CreatedAt, yield.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert yield: %w", err)
+	}
+	return nil
+}
+
+// FindByAccountID retrieves the yield of an account, returning nil when none is set
+func (r *PostgresRepository) FindByAccountID(ctx context.Context, accountID uuid.UUID) (*Yield, error) {
+	query := `SELECT account_id, user_id, yield_type, rate, created_at, updated_at FROM account_yields WHERE account_id = $1`
+
+	var y Yield
+	var rate int64
+	err := r.pool.QueryRow(ctx, query, accountID).Scan(&y.AccountID, &y.UserID, &y.Type, &rate, &y.CreatedAt, &y.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch yield: %w", err)
+	}
+	y.Rate = Rate(rate)
+	return &y, nil
+}
+
+// Delete removes the yield of an account
+func (r *PostgresRepository) Delete(ctx context.Context, accountID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM account_yields WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete yield: %w", err)
+	}
+	return nil
+}
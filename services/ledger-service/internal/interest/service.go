@@ -0,0 +1,285 @@
+package interest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the yields
+const (
+	auditYieldSaved    = "account_yield.saved"
+	auditYieldDeleted  = "account_yield.deleted"
+	auditResourceYield = "account_yield"
+
+	// importSource names the interest transactions in the audit record of the ledger import
+	importSource = "interest"
+)
+
+// AccountImporter loads the savings accounts and posts their interest, satisfied by the ledger Service
+type AccountImporter interface {
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+	ImportTransactions(ctx context.Context, params ledger.ImportTransactionsParams) ([]ledger.Transaction, error)
+}
+
+// SetYieldParams holds all the required data for the SetYield use case
+type SetYieldParams struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Type      YieldType
+	Rate      string // Rate as a percentage with a dot separator (e.g., "110" of the CDI or "12.5" a year)
+}
+
+// SimulateParams holds all the required data for the Simulate use case
+type SimulateParams struct {
+	UserID             uuid.UUID
+	AccountID          uuid.UUID
+	From               time.Time // From is the first month simulated
+	To                 time.Time // To is the last month simulated
+	CreateTransactions bool      // CreateTransactions posts the interest of the months that ended as income transactions
+}
+
+// Simulation is the interest a savings account earns over a period at its yield
+type Simulation struct {
+	AccountID     uuid.UUID
+	Yield         *Yield
+	CDI           Rate // CDI is the annual CDI the simulation assumed
+	Months        []MonthlyInterest
+	TotalInterest money.Money
+	Created       int // Created is how many interest transactions the simulation posted
+}
+
+// yieldAuditState is the yield state kept in audit records
+type yieldAuditState struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Type      YieldType `json:"type"`
+	Rate      string    `json:"rate"`
+}
+
+// Service manages the yields of the savings accounts and simulates the interest they earn
+type Service struct {
+	repo     Repository
+	accounts AccountImporter
+	cdi      Rate // cdi is the annual CDI the CDI_PERCENT yields follow
+	auditor  *audit.Logger
+	rounding money.RoundingPolicy
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the interest Service, the CDI_PERCENT yields following the annual cdi
+func NewService(repo Repository, accounts AccountImporter, cdi Rate, auditor *audit.Logger, rounding money.RoundingPolicy, clock clock.Clock) *Service {
+	return &Service{
+		repo:     repo,
+		accounts: accounts,
+		cdi:      cdi,
+		auditor:  auditor,
+		rounding: rounding,
+		clock:    clock,
+	}
+}
+
+// GetYield is the use case for finding the yield of a savings account of the user
+func (s *Service) GetYield(ctx context.Context, userID, accountID uuid.UUID) (*Yield, error) {
+	account, err := s.savingsAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findYield(ctx, account.ID)
+}
+
+// SetYield is the use case for setting the yield of a savings account of the user or replacing it
+func (s *Service) SetYield(ctx context.Context, params SetYieldParams) (*Yield, error) {
+	account, err := s.savingsAccount(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.EnsureWritable(); err != nil {
+		return nil, err
+	}
+
+	rate, err := ParseRate(params.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	yield, err := s.repo.FindByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find yield: %w", err)
+	}
+
+	var before any
+	if yield == nil {
+		yield, err = NewYield(params.UserID, account.ID, params.Type, rate, s.clock)
+	} else {
+		before = toYieldAuditState(yield)
+		err = yield.Update(params.Type, rate, s.clock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set yield: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, yield); err != nil {
+		return nil, fmt.Errorf("failed to save yield: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditYieldSaved,
+		ResourceType: auditResourceYield,
+		ResourceID:   account.ID.String(),
+		Before:       before,
+		After:        toYieldAuditState(yield),
+	})
+
+	return yield, nil
+}
+
+// DeleteYield is the use case for removing the yield of a savings account of the user
+func (s *Service) DeleteYield(ctx context.Context, userID, accountID uuid.UUID) error {
+	account, err := s.savingsAccount(ctx, userID, accountID)
+	if err != nil {
+		return err
+	}
+	yield, err := s.findYield(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, account.ID); err != nil {
+		return fmt.Errorf("failed to delete yield: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditYieldDeleted,
+		ResourceType: auditResourceYield,
+		ResourceID:   account.ID.String(),
+		Before:       toYieldAuditState(yield),
+	})
+
+	return nil
+}
+
+// Simulate is the use case for simulating the interest a savings account of the user earns month by month,
+// optionally posting the interest of the months that ended as income transactions on their last day
+// Each month is posted at most once, so simulating the same months again only posts the ones still missing
+func (s *Service) Simulate(ctx context.Context, params SimulateParams) (*Simulation, error) {
+	from, to := startOfMonth(params.From), startOfMonth(params.To)
+	if to.Before(from) {
+		return nil, ErrInvalidSimulationPeriod
+	}
+	if months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1; months > MaxSimulationMonths {
+		return nil, ErrSimulationPeriodTooLong.With("max_months", MaxSimulationMonths)
+	}
+
+	account, err := s.savingsAccount(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	yield, err := s.findYield(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	months, err := simulate(account, yield, s.cdi, from, to, s.clock.Now(), s.rounding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate interest: %w", err)
+	}
+
+	created := 0
+	if params.CreateTransactions {
+		if created, err = s.post(ctx, account, months); err != nil {
+			return nil, err
+		}
+	}
+
+	amounts := make([]money.Money, len(months))
+	for i, month := range months {
+		amounts[i] = month.Interest
+	}
+	total, err := money.Sum(account.Currency, amounts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum interest: %w", err)
+	}
+
+	return &Simulation{
+		AccountID:     account.ID,
+		Yield:         yield,
+		CDI:           s.cdi,
+		Months:        months,
+		TotalInterest: total,
+		Created:       created,
+	}, nil
+}
+
+// post imports the interest of the ended months not posted yet in a single save, setting their transaction
+func (s *Service) post(ctx context.Context, account *ledger.Account, months []MonthlyInterest) (int, error) {
+	var pending []int
+	var imports []ledger.ImportedTransaction
+	for i, month := range months {
+		if month.Projected || month.TransactionID != nil || !month.Interest.IsPositive() {
+			continue
+		}
+		pending = append(pending, i)
+		imports = append(imports, ledger.ImportedTransaction{
+			ID:          InterestTransactionID(account.ID, month.Month),
+			Description: InterestDescription(month.Month),
+			Amount:      month.Interest.Decimal(),
+			Date:        month.Month.AddDate(0, 1, -1),
+		})
+	}
+	if len(imports) == 0 {
+		return 0, nil
+	}
+
+	imported, err := s.accounts.ImportTransactions(ctx, ledger.ImportTransactionsParams{
+		AccountID:    account.ID,
+		UserID:       account.UserID,
+		Source:       importSource,
+		Transactions: imports,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to post interest: %w", err)
+	}
+	for i, tx := range imported {
+		months[pending[i]].TransactionID = &tx.ID
+	}
+	return len(imported), nil
+}
+
+// savingsAccount finds an account of the user, which must be a savings account
+func (s *Service) savingsAccount(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error) {
+	account, err := s.accounts.FindAccountByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find savings account: %w", err)
+	}
+	if account.Kind != ledger.Savings {
+		return nil, ErrAccountNotSavings.With("account_id", accountID)
+	}
+	return account, nil
+}
+
+// findYield returns the yield of the account, ErrYieldNotFound when none is set
+func (s *Service) findYield(ctx context.Context, accountID uuid.UUID) (*Yield, error) {
+	yield, err := s.repo.FindByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find yield: %w", err)
+	}
+	if yield == nil {
+		return nil, ErrYieldNotFound.With("account_id", accountID)
+	}
+	return yield, nil
+}
+
+// toYieldAuditState snapshots the yield for an audit record
+func toYieldAuditState(y *Yield) *yieldAuditState {
+	return &yieldAuditState{
+		AccountID: y.AccountID,
+		Type:      y.Type,
+		Rate:      y.Rate.String(),
+	}
+}
@@ -0,0 +1,144 @@
+package investments
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPositionNotFound     = errx.New(errx.CategoryNotFound, "POSITION_NOT_FOUND", "position not found")
+	ErrAccountNotInvestment = errx.New(errx.CategoryValidation, "ACCOUNT_NOT_INVESTMENT", "positions can only be held in investment accounts")
+	ErrInvalidTicker        = errx.New(errx.CategoryValidation, "INVALID_TICKER", "ticker must have 1 to 12 letters, digits, dots or dashes")
+	ErrInvalidQuantity      = errx.New(errx.CategoryValidation, "INVALID_QUANTITY", "quantity must be a positive decimal number")
+	ErrInvalidCostBasis     = errx.New(errx.CategoryValidation, "INVALID_COST_BASIS", "cost basis cannot be negative")
+	ErrQuoteNotFound        = errx.New(errx.CategoryNotFound, "QUOTE_NOT_FOUND", "no quote available for the ticker")
+)
+
+// QuantityDecimals is the number of decimal places kept by a Quantity, enough for fractional shares and crypto assets
+const QuantityDecimals = 8
+
+// quantityScale is 10^QuantityDecimals, the Quantity value of one whole unit
+const quantityScale = 100_000_000
+
+var tickerPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9.\-]{0,11}$`)
+
+// Quantity is an amount of units of an asset, as a fixed-point number with QuantityDecimals decimal places
+type Quantity int64
+
+// ParseQuantity reads a positive decimal quantity using a dot as decimal separator (e.g., "12.5")
+func ParseQuantity(value string) (Quantity, error) {
+	invalid := func() (Quantity, error) {
+		return 0, ErrInvalidQuantity.With("input", value)
+	}
+
+	whole, frac, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if whole == "" || len(frac) > QuantityDecimals || strings.ContainsAny(whole+frac, "+-") {
+		return invalid()
+	}
+
+	parsed, err := strconv.ParseInt(whole+frac+strings.Repeat("0", QuantityDecimals-len(frac)), 10, 64)
+	if err != nil || parsed <= 0 {
+		return invalid()
+	}
+	return Quantity(parsed), nil
+}
+
+// String returns the quantity as a plain decimal without trailing zeros (e.g., "12.5")
+func (q Quantity) String() string {
+	s := strconv.FormatInt(int64(q)/quantityScale, 10)
+	if frac := int64(q) % quantityScale; frac != 0 {
+		s += "." + strings.TrimRight(strconv.FormatInt(frac+quantityScale, 10)[1:], "0")
+	}
+	return s
+}
+
+// NormalizeTicker upper-cases a ticker and checks its format
+func NormalizeTicker(ticker string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(ticker))
+	if !tickerPattern.MatchString(normalized) {
+		return "", ErrInvalidTicker.With("ticker", ticker)
+	}
+	return normalized, nil
+}
+
+// Position is the holding of an asset in an investment account, kept apart from the account cash transactions
+type Position struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Ticker    string
+	Quantity  Quantity
+	CostBasis money.Money // CostBasis is the total paid for the position, in the account currency
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewPosition creates a new Position of an investment account
+func NewPosition(userID, accountID uuid.UUID, ticker string, quantity Quantity, costBasis money.Money, clock clock.Clock) (*Position, error) {
+	normalized, err := NormalizeTicker(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+	p := &Position{
+		ID:        uuid.New(),
+		UserID:    userID,
+		AccountID: accountID,
+		Ticker:    normalized,
+		CreatedAt: now,
+	}
+	if err := p.Update(quantity, costBasis, clock); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update replaces the quantity and cost basis of the position (e.g., after buying more units)
+func (p *Position) Update(quantity Quantity, costBasis money.Money, clock clock.Clock) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity.With("quantity", quantity.String())
+	}
+	if costBasis.IsNegative() {
+		return ErrInvalidCostBasis
+	}
+
+	p.Quantity = quantity
+	p.CostBasis = costBasis
+	p.UpdatedAt = clock.Now()
+	return nil
+}
+
+// MarketValue values the position at a unit price, rounded to the minor unit of the price currency by the policy
+func (p *Position) MarketValue(price money.Money, rounding money.RoundingPolicy) (money.Money, error) {
+	return rounding.Scale(price, int64(p.Quantity), quantityScale)
+}
+
+// Quote is the latest known unit price of an asset
+type Quote struct {
+	Ticker string
+	Price  money.Money
+	AsOf   time.Time
+}
+
+// QuoteProvider prices assets by ticker, market data integrations plug in here
+// Implementations return ErrQuoteNotFound for tickers they do not know
+type QuoteProvider interface {
+	Quote(ctx context.Context, ticker string) (Quote, error)
+}
+
+// PositionRepository persists the positions of the investment accounts
+type PositionRepository interface {
+	Save(ctx context.Context, position *Position) error
+	FindByTicker(ctx context.Context, accountID uuid.UUID, ticker string) (*Position, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Position, error)
+	Delete(ctx context.Context, positionID uuid.UUID) error
+}
@@ -0,0 +1,157 @@
+package investments
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// InvestmentHandler holds dependencies for investment-related HTTP handlers
+type InvestmentHandler struct {
+	investmentService *Service
+}
+
+// NewInvestmentHandler creates a new instance of InvestmentHandler
+func NewInvestmentHandler(investmentService *Service) *InvestmentHandler {
+	return &InvestmentHandler{investmentService: investmentService}
+}
+
+// RegisterRoutes sets up the API routes for the investments module
+func (h *InvestmentHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	investmentsGroup := apiRouteGroup.Group("/investments")
+
+	investmentsGroup.GET("/positions", h.listPositionsHandler)
+	investmentsGroup.PUT("/accounts/:accountId/positions/:ticker", h.setPositionHandler)
+	investmentsGroup.DELETE("/accounts/:accountId/positions/:ticker", h.deletePositionHandler)
+}
+
+// SetPositionRequest defines the expected JSON body for setting the position of an investment account in a ticker
+type SetPositionRequest struct {
+	Quantity  string `json:"quantity" validate:"required,max=32"`   // Decimal string with a dot separator (e.g., "12.5")
+	CostBasis string `json:"cost_basis" validate:"required,max=32"` // Total paid, decimal string in the account currency (e.g., "1.234,56")
+}
+
+// PositionResponse defines the structure of a position returned by the API
+// Amounts are expressed in minor units of the currency, market figures are absent when no quote is available
+type PositionResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	AccountID      uuid.UUID  `json:"account_id"`
+	Ticker         string     `json:"ticker"`
+	Quantity       string     `json:"quantity"`
+	Currency       string     `json:"currency"`
+	CostBasis      int64      `json:"cost_basis"`
+	Price          *int64     `json:"price,omitempty"`
+	QuotedAt       *time.Time `json:"quoted_at,omitempty"`
+	MarketValue    *int64     `json:"market_value,omitempty"`
+	UnrealizedGain *int64     `json:"unrealized_gain,omitempty"` // UnrealizedGain is the market value minus the cost basis
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// listPositionsHandler handles the HTTP request for listing the user's positions with their market value
+func (h *InvestmentHandler) listPositionsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	valuations, err := h.investmentService.ListValuations(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]PositionResponse, len(valuations))
+	for i, v := range valuations {
+		resp[i] = toPositionResponse(v)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// setPositionHandler handles the HTTP request for creating or replacing a position
+func (h *InvestmentHandler) setPositionHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req SetPositionRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SetPositionParams{
+		UserID:    userID,
+		AccountID: accountID,
+		Ticker:    c.Param("ticker"),
+		Quantity:  req.Quantity,
+		CostBasis: req.CostBasis,
+	}
+
+	position, err := h.investmentService.SetPosition(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toPositionResponse(Valuation{Position: position}))
+}
+
+// deletePositionHandler handles the HTTP request for removing a position
+func (h *InvestmentHandler) deletePositionHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.investmentService.DeletePosition(c.Request().Context(), userID, accountID, c.Param("ticker")); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toPositionResponse maps a valued Position to the public PositionResponse DTO
+func toPositionResponse(v Valuation) PositionResponse {
+	p := v.Position
+	resp := PositionResponse{
+		ID:        p.ID,
+		AccountID: p.AccountID,
+		Ticker:    p.Ticker,
+		Quantity:  p.Quantity.String(),
+		Currency:  p.CostBasis.Currency,
+		CostBasis: p.CostBasis.Amount,
+		UpdatedAt: p.UpdatedAt,
+	}
+	if v.Quote != nil && v.MarketValue != nil {
+		gain := v.MarketValue.Amount - p.CostBasis.Amount
+		resp.Price = &v.Quote.Price.Amount
+		resp.QuotedAt = &v.Quote.AsOf
+		resp.MarketValue = &v.MarketValue.Amount
+		resp.UnrealizedGain = &gain
+	}
+	return resp
+}
@@ -0,0 +1,39 @@
+package investments
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the position repository, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *InvestmentHandler
+}
+
+// NewModule creates the investments module, holding positions of the ledger accounts priced by the quotes provider
+func NewModule(deps module.Deps, accounts AccountReader, quotes QuoteProvider) *Module {
+	investmentSvc := NewService(NewPostgresPositionRepository(deps.Postgres.Pool), accounts, quotes, deps.Audit, deps.Rounding, deps.Clock)
+
+	return &Module{
+		service: investmentSvc,
+		handler: NewInvestmentHandler(investmentSvc),
+	}
+}
+
+// Service returns the investments service, for modules valuing the positions (e.g., the net worth)
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "investments"
+}
+
+// RegisterRoutes mounts the investments routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,115 @@
+package investments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/money"
+)
+
+var (
+	_ QuoteProvider = (*StaticQuoteProvider)(nil)
+	_ QuoteProvider = (*CachedQuoteProvider)(nil)
+)
+
+// ----- Static quotes ----- //
+
+// StaticQuoteProvider serves fixed prices, for self-hosted setups without a market data integration
+type StaticQuoteProvider struct {
+	quotes map[string]Quote
+}
+
+// NewStaticQuoteProvider parses prices written as "<amount> <currency>" by ticker (e.g., "PETR4": "38.50 BRL")
+// Their AsOf is the time the provider was created
+func NewStaticQuoteProvider(prices map[string]string, clock clock.Clock) (*StaticQuoteProvider, error) {
+	now := clock.Now()
+	quotes := make(map[string]Quote, len(prices))
+	for rawTicker, rawPrice := range prices {
+		ticker, err := NormalizeTicker(rawTicker)
+		if err != nil {
+			return nil, err
+		}
+
+		amount, currency, ok := strings.Cut(strings.TrimSpace(rawPrice), " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid quote for %s: expected \"<amount> <currency>\", got %q", ticker, rawPrice)
+		}
+		if !money.IsKnownCurrency(strings.TrimSpace(currency)) {
+			return nil, fmt.Errorf("invalid quote for %s: unsupported currency %q", ticker, currency)
+		}
+		price, err := money.Parse(amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quote for %s: %w", ticker, err)
+		}
+
+		quotes[ticker] = Quote{Ticker: ticker, Price: price, AsOf: now}
+	}
+
+	return &StaticQuoteProvider{quotes: quotes}, nil
+}
+
+// Quote returns the configured price of the ticker
+func (p *StaticQuoteProvider) Quote(ctx context.Context, ticker string) (Quote, error) {
+	quote, ok := p.quotes[strings.ToUpper(ticker)]
+	if !ok {
+		return Quote{}, ErrQuoteNotFound.With("ticker", ticker)
+	}
+	return quote, nil
+}
+
+// ----- Cache ----- //
+
+// CachedQuoteProvider keeps the quotes of another provider for a while, so listing positions does not hit market data every time
+// Failures are not cached
+type CachedQuoteProvider struct {
+	next  QuoteProvider
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]cachedQuote
+}
+
+// cachedQuote is a quote and the time it stops being served from the cache
+type cachedQuote struct {
+	quote     Quote
+	expiresAt time.Time
+}
+
+// NewCachedQuoteProvider wraps next with a cache of the given TTL
+func NewCachedQuoteProvider(next QuoteProvider, ttl time.Duration, clock clock.Clock) *CachedQuoteProvider {
+	return &CachedQuoteProvider{
+		next:    next,
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]cachedQuote),
+	}
+}
+
+// Quote returns the cached quote of the ticker, asking the wrapped provider once it expired
+func (p *CachedQuoteProvider) Quote(ctx context.Context, ticker string) (Quote, error) {
+	key := strings.ToUpper(ticker)
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	entry, ok := p.entries[key]
+	p.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.quote, nil
+	}
+
+	quote, err := p.next.Quote(ctx, key)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	p.mu.Lock()
+	p.entries[key] = cachedQuote{quote: quote, expiresAt: now.Add(p.ttl)}
+	p.mu.Unlock()
+
+	return quote, nil
+}
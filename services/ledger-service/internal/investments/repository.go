@@ -0,0 +1,151 @@
+package investments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ PositionRepository = (*PostgresPositionRepository)(nil)
+
+// PostgresPositionRepository is a PostgreSQL implementation of the PositionRepository interface
+type PostgresPositionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPositionRepository creates a new PostgresPositionRepository
+func NewPostgresPositionRepository(pool *pgxpool.Pool) *PostgresPositionRepository {
+	return &PostgresPositionRepository{pool: pool}
+}
+
+// positionModel represents the position structure in the database
+type positionModel struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	AccountID uuid.UUID `db:"account_id"`
+	Ticker    string    `db:"ticker"`
+	Quantity  int64     `db:"quantity"` // Quantity is stored with QuantityDecimals implied decimal places
+	CostBasis int64     `db:"cost_basis_in_cents"`
+	Currency  string    `db:"currency"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+const positionColumns = `p.id, p.user_id, p.account_id, p.ticker, p.quantity, p.cost_basis_in_cents, p.currency, p.created_at, p.updated_at`
+
+// Save inserts a new position or updates the quantity and cost basis of an existing one
+func (r *PostgresPositionRepository) Save(ctx context.Context, position *Position) error {
+	m := toPositionPersistence(position)
+
+	query := `
+		INSERT INTO investment_positions (id, user_id, account_id, ticker, quantity, cost_basis_in_cents, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			cost_basis_in_cents = EXCLUDED.cost_basis_in_cents,
+			currency = EXCLUDED.currency,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, m.ID, m.UserID, m.AccountID, m.Ticker, m.Quantity, m.CostBasis, m.Currency, m.CreatedAt, m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert position: %v", err)
+	}
+	return nil
+}
+
+// FindByTicker retrieves the position of an account in a ticker, returning nil when it has none
+func (r *PostgresPositionRepository) FindByTicker(ctx context.Context, accountID uuid.UUID, ticker string) (*Position, error) {
+	query := `SELECT ` + positionColumns + ` FROM investment_positions p WHERE p.account_id = $1 AND p.ticker = $2`
+
+	var m positionModel
+	err := r.pool.QueryRow(ctx, query, accountID, ticker).Scan(
+		&m.ID, &m.UserID, &m.AccountID, &m.Ticker, &m.Quantity, &m.CostBasis, &m.Currency, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch position: %w", err)
+	}
+	return toPositionDomain(&m), nil
+}
+
+// FindByUserID retrieves the positions of the user's active investment accounts
+func (r *PostgresPositionRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Position, error) {
+	query := `
+		SELECT ` + positionColumns + `
+		FROM investment_positions p
+		JOIN accounts a ON a.id = p.account_id
+		WHERE p.user_id = $1 AND a.archived_at IS NULL
+		ORDER BY a.name, p.ticker
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := make([]*Position, 0)
+	for rows.Next() {
+		var m positionModel
+		if err := rows.Scan(&m.ID, &m.UserID, &m.AccountID, &m.Ticker, &m.Quantity, &m.CostBasis, &m.Currency, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan position row: %w", err)
+		}
+		positions = append(positions, toPositionDomain(&m))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating position rows: %w", err)
+	}
+
+	return positions, nil
+}
+
+// Delete deletes a position
+func (r *PostgresPositionRepository) Delete(ctx context.Context, positionID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM investment_positions WHERE id = $1`, positionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete position: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPositionNotFound.With("position_id", positionID)
+	}
+	return nil
+}
+
+// toPositionPersistence maps a domain Position to its persistence model
+func toPositionPersistence(p *Position) *positionModel {
+	return &positionModel{
+		ID:        p.ID,
+		UserID:    p.UserID,
+		AccountID: p.AccountID,
+		Ticker:    p.Ticker,
+		Quantity:  int64(p.Quantity),
+		CostBasis: p.CostBasis.Amount,
+		Currency:  p.CostBasis.Currency,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// toPositionDomain maps a persistence positionModel to a domain Position
+func toPositionDomain(m *positionModel) *Position {
+	return &Position{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		AccountID: m.AccountID,
+		Ticker:    m.Ticker,
+		Quantity:  Quantity(m.Quantity),
+		CostBasis: money.New(m.CostBasis, m.Currency),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
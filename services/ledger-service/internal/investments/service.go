@@ -0,0 +1,246 @@
+package investments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the investment positions
+const (
+	auditPositionSaved    = "investment_position.saved"
+	auditPositionDeleted  = "investment_position.deleted"
+	auditResourcePosition = "investment_position"
+)
+
+// AccountReader loads the ledger account holding the positions, satisfied by the ledger Service
+type AccountReader interface {
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+}
+
+// SetPositionParams holds all the required data for the SetPosition use case
+type SetPositionParams struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Ticker    string
+	Quantity  string // Quantity as a decimal string with a dot separator (e.g., "12.5")
+	CostBasis string // CostBasis as a decimal string, parsed using the account currency (e.g., "1.234,56")
+}
+
+// Valuation is a position priced at its latest quote, Quote and MarketValue are nil when no usable quote was found
+type Valuation struct {
+	Position    *Position
+	Quote       *Quote
+	MarketValue *money.Money
+}
+
+// positionAuditState is the position state kept in audit records
+type positionAuditState struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Ticker    string    `json:"ticker"`
+	Quantity  string    `json:"quantity"`
+	CostBasis int64     `json:"cost_basis"`
+	Currency  string    `json:"currency"`
+}
+
+// Service manages the positions of the investment accounts and values them through the QuoteProvider
+type Service struct {
+	positionRepo PositionRepository
+	accounts     AccountReader
+	quotes       QuoteProvider
+	auditor      *audit.Logger
+	rounding     money.RoundingPolicy
+	clock        clock.Clock
+}
+
+// NewService creates a new instance of the investments Service
+func NewService(positionRepo PositionRepository, accounts AccountReader, quotes QuoteProvider, auditor *audit.Logger, rounding money.RoundingPolicy, clock clock.Clock) *Service {
+	return &Service{
+		positionRepo: positionRepo,
+		accounts:     accounts,
+		quotes:       quotes,
+		auditor:      auditor,
+		rounding:     rounding,
+		clock:        clock,
+	}
+}
+
+// SetPosition is the use case for creating or replacing the position of an investment account in a ticker
+func (s *Service) SetPosition(ctx context.Context, params SetPositionParams) (*Position, error) {
+	account, err := s.investmentAccount(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := NormalizeTicker(params.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	quantity, err := ParseQuantity(params.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	costBasis, err := money.Parse(params.CostBasis, account.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse position cost basis: %w", err)
+	}
+
+	position, err := s.positionRepo.FindByTicker(ctx, account.ID, ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find position: %w", err)
+	}
+
+	var before any
+	if position == nil {
+		position, err = NewPosition(params.UserID, account.ID, ticker, quantity, costBasis, s.clock)
+	} else {
+		before = toPositionAuditState(position)
+		err = position.Update(quantity, costBasis, s.clock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set position: %w", err)
+	}
+
+	if err := s.positionRepo.Save(ctx, position); err != nil {
+		return nil, fmt.Errorf("failed to save position: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditPositionSaved,
+		ResourceType: auditResourcePosition,
+		ResourceID:   position.ID.String(),
+		Before:       before,
+		After:        toPositionAuditState(position),
+	})
+
+	return position, nil
+}
+
+// DeletePosition is the use case for removing the position of an investment account in a ticker (e.g., after selling it all)
+func (s *Service) DeletePosition(ctx context.Context, userID, accountID uuid.UUID, ticker string) error {
+	account, err := s.investmentAccount(ctx, userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := NormalizeTicker(ticker)
+	if err != nil {
+		return err
+	}
+	position, err := s.positionRepo.FindByTicker(ctx, account.ID, normalized)
+	if err != nil {
+		return fmt.Errorf("failed to find position: %w", err)
+	}
+	if position == nil {
+		return ErrPositionNotFound.With("ticker", normalized)
+	}
+
+	if err := s.positionRepo.Delete(ctx, position.ID); err != nil {
+		return fmt.Errorf("failed to delete position: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditPositionDeleted,
+		ResourceType: auditResourcePosition,
+		ResourceID:   position.ID.String(),
+		Before:       toPositionAuditState(position),
+	})
+
+	return nil
+}
+
+// ListValuations is the use case for listing the user's positions priced at their latest quote
+// A missing or unusable quote leaves the position unpriced instead of failing the whole list
+func (s *Service) ListValuations(ctx context.Context, userID uuid.UUID) ([]Valuation, error) {
+	positions, err := s.positionRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find positions: %w", err)
+	}
+
+	valuations := make([]Valuation, len(positions))
+	for i, position := range positions {
+		valuations[i] = Valuation{Position: position}
+
+		quote, err := s.quotes.Quote(ctx, position.Ticker)
+		if err == nil && quote.Price.Currency != position.CostBasis.Currency {
+			err = money.ErrCurrencyMismatch.With("quote_currency", quote.Price.Currency).With("position_currency", position.CostBasis.Currency)
+		}
+		var marketValue money.Money
+		if err == nil {
+			marketValue, err = position.MarketValue(quote.Price, s.rounding)
+		}
+		if err != nil {
+			ctxlogger.GetLogger(ctx).Warn("failed to value investment position",
+				slog.String("ticker", position.Ticker),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		valuations[i].Quote = &quote
+		valuations[i].MarketValue = &marketValue
+	}
+
+	return valuations, nil
+}
+
+// MarketValues sums the value of the positions of each investment account of the user, for the net worth
+// Positions without a usable quote are counted at their cost basis
+func (s *Service) MarketValues(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]money.Money, error) {
+	valuations, err := s.ListValuations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[uuid.UUID]money.Money)
+	for _, v := range valuations {
+		value := v.Position.CostBasis
+		if v.MarketValue != nil {
+			value = *v.MarketValue
+		}
+
+		total, ok := values[v.Position.AccountID]
+		if !ok {
+			total = money.Zero(value.Currency)
+		}
+		if total, err = total.Add(value); err != nil {
+			return nil, fmt.Errorf("failed to sum account market value: %w", err)
+		}
+		values[v.Position.AccountID] = total
+	}
+
+	return values, nil
+}
+
+// investmentAccount finds an account of the user, which must be an active investment account
+func (s *Service) investmentAccount(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error) {
+	account, err := s.accounts.FindAccountByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find investment account: %w", err)
+	}
+	if account.Kind != ledger.Investment {
+		return nil, ErrAccountNotInvestment.With("account_id", accountID)
+	}
+	if err := account.EnsureWritable(); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// toPositionAuditState snapshots the position for an audit record
+func toPositionAuditState(p *Position) *positionAuditState {
+	return &positionAuditState{
+		AccountID: p.AccountID,
+		Ticker:    p.Ticker,
+		Quantity:  p.Quantity.String(),
+		CostBasis: p.CostBasis.Amount,
+		Currency:  p.CostBasis.Currency,
+	}
+}
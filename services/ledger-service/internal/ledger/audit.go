@@ -0,0 +1,114 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions recorded for ledger mutations
+const (
+	auditAccountCreated           = "account.created"
+	auditAccountUpdated           = "account.updated"
+	auditAccountArchived          = "account.archived"
+	auditAccountUnarchived        = "account.unarchived"
+	auditAccountDeletionScheduled = "account.deletion_scheduled"
+	auditAccountDeletionCancelled = "account.deletion_cancelled"
+	auditAccountDeleted           = "account.deleted"
+	auditAccountBalanceAdjusted   = "account.balance_adjusted"
+	auditAccountStatementPaid     = "account.statement_paid"
+	auditAccountHistoryArchived   = "account.history_archived"
+	auditTransactionCreated       = "transaction.created"
+	auditTransactionsImported     = "transactions.imported"
+	auditTransactionsPaid         = "transactions.paid"
+	auditTransactionTagged        = "transaction.tagged"
+	auditTransactionClearingSet   = "transaction.clearing_set"
+	auditTransactionLocationSet   = "transaction.location_set"
+	auditOperationUndone          = "operation.undone"
+)
+
+// Audit resource types of the ledger
+const (
+	auditResourceAccount     = "account"
+	auditResourceTransaction = "transaction"
+)
+
+// accountAuditState is the account state kept in audit records (transactions are audited on their own)
+type accountAuditState struct {
+	Name                    string     `json:"name"`
+	Currency                string     `json:"currency"`
+	Kind                    string     `json:"kind"`
+	IncludeInOverallBalance bool       `json:"include_in_overall_balance"`
+	ArchivedAt              *time.Time `json:"archived_at,omitempty"`
+	DeleteAfter             *time.Time `json:"delete_after,omitempty"`
+	Balance                 *int64     `json:"balance,omitempty"` // Balance is the projected balance in minor units, when it could be computed
+}
+
+// transactionAuditState is the transaction state kept in audit records
+type transactionAuditState struct {
+	AccountID   uuid.UUID  `json:"account_id"`
+	CategoryID  *uuid.UUID `json:"category_id,omitempty"`
+	Type        string     `json:"type"`
+	Description string     `json:"description"`
+	Amount      int64      `json:"amount"`
+	Currency    string     `json:"currency"`
+	DueDate     time.Time  `json:"due_date"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+
+	OriginalAmount   *int64 `json:"original_amount,omitempty"`
+	OriginalCurrency string `json:"original_currency,omitempty"`
+	ExchangeRate     string `json:"exchange_rate,omitempty"`
+
+	PixEndToEndID string   `json:"pix_end_to_end_id,omitempty"` // PixEndToEndID is recorded without the keys, which identify people
+	Tags          []string `json:"tags,omitempty"`
+
+	ClearingMethod       string     `json:"clearing_method,omitempty"`
+	ExpectedClearingDate *time.Time `json:"expected_clearing_date,omitempty"`
+
+	Located bool `json:"located,omitempty"` // Located is recorded without the coordinates, which tell where the user goes
+}
+
+// toAccountAuditState snapshots the account for an audit record
+func toAccountAuditState(a *Account) *accountAuditState {
+	state := &accountAuditState{
+		Name:                    a.Name,
+		Currency:                a.Currency,
+		Kind:                    string(a.Kind),
+		IncludeInOverallBalance: a.IncludeInOverallBalance,
+		ArchivedAt:              a.ArchivedAt,
+		DeleteAfter:             a.DeleteAfter,
+	}
+	if balance, err := a.ProjectedBalance(); err == nil {
+		state.Balance = &balance.Amount
+	}
+	return state
+}
+
+// toTransactionAuditState snapshots a transaction of the account for an audit record
+func toTransactionAuditState(accountID uuid.UUID, tx Transaction) *transactionAuditState {
+	state := &transactionAuditState{
+		AccountID:   accountID,
+		CategoryID:  tx.CategoryID,
+		Type:        string(tx.Type),
+		Description: tx.Description,
+		Amount:      tx.Amount.Amount,
+		Currency:    tx.Amount.Currency,
+		DueDate:     tx.DueDate,
+		PaidAt:      tx.PaidAt,
+		Tags:        tx.Tags,
+	}
+	if tx.Original != nil {
+		state.OriginalAmount = &tx.Original.Amount.Amount
+		state.OriginalCurrency = tx.Original.Amount.Currency
+		state.ExchangeRate = tx.Original.Rate.String()
+	}
+	if tx.Pix != nil {
+		state.PixEndToEndID = tx.Pix.EndToEndID
+	}
+	if tx.Clearing != nil {
+		state.ClearingMethod = string(tx.Clearing.Method)
+		state.ExpectedClearingDate = &tx.Clearing.ExpectedClearingDate
+	}
+	state.Located = tx.Location != nil
+	return state
+}
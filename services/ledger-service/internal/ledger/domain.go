@@ -4,49 +4,170 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/pix"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrAccountArchived                   = errors.New("account is archived")
-	ErrAccountAlreadyArchived            = errors.New("account is already archived")
-	ErrAccountNotArchived                = errors.New("account is not archived")
-	ErrTransactionNotFound               = errors.New("transaction not found in this account")
-	ErrTransactionAlreadyPaid            = errors.New("transaction is already marked as paid")
-	ErrTransactionAlreadyUnpaid          = errors.New("transaction is already marked as unpaid")
-	ErrPaymentDateInFuture               = errors.New("payment date cannot be in the future")
-	ErrAmountCannotBeZero                = errors.New("transaction amount cannot be zero")
-	ErrAccountBalanceMustBeZeroToArchive = errors.New("account real balance must be zero")
-	ErrDescriptionRequired               = errors.New("transaction description is required")
-	ErrAccountNameRequired               = errors.New("account name is required")
-	ErrInconsistentAmountSign            = errors.New("transaction amount sign is inconsistent with its type")
-	ErrInvalidTransactionType            = errors.New("invalid transaction type")
-	ErrAccountAlreadyIncluded            = errors.New("account is already included in overall balance")
-	ErrAccountAlreadyExcluded            = errors.New("account is already excluded from overall balance")
+	ErrAccountArchived                   = errx.New(errx.CategoryForbidden, "ACCOUNT_ARCHIVED", "account is archived")
+	ErrAccountAlreadyArchived            = errx.New(errx.CategoryConflict, "ACCOUNT_ALREADY_ARCHIVED", "account is already archived")
+	ErrAccountNotArchived                = errx.New(errx.CategoryConflict, "ACCOUNT_NOT_ARCHIVED", "account is not archived")
+	ErrTransactionNotFound               = errx.New(errx.CategoryNotFound, "TRANSACTION_NOT_FOUND", "transaction not found in this account")
+	ErrTransactionAlreadyPaid            = errx.New(errx.CategoryConflict, "TRANSACTION_ALREADY_PAID", "transaction is already marked as paid")
+	ErrTransactionAlreadyUnpaid          = errx.New(errx.CategoryConflict, "TRANSACTION_ALREADY_UNPAID", "transaction is already marked as unpaid")
+	ErrPaymentDateInFuture               = errx.New(errx.CategoryValidation, "PAYMENT_DATE_IN_FUTURE", "payment date cannot be in the future")
+	ErrAmountCannotBeZero                = errx.New(errx.CategoryValidation, "AMOUNT_CANNOT_BE_ZERO", "transaction amount cannot be zero")
+	ErrAccountPendingDeletion            = errx.New(errx.CategoryForbidden, "ACCOUNT_PENDING_DELETION", "account is scheduled for deletion")
+	ErrAccountDeletionAlreadyScheduled   = errx.New(errx.CategoryConflict, "ACCOUNT_DELETION_ALREADY_SCHEDULED", "account deletion is already scheduled")
+	ErrAccountDeletionNotScheduled       = errx.New(errx.CategoryConflict, "ACCOUNT_DELETION_NOT_SCHEDULED", "account deletion is not scheduled")
+	ErrAccountDeletionNotDue             = errx.New(errx.CategoryConflict, "ACCOUNT_DELETION_NOT_DUE", "account deletion grace period has not elapsed")
+	ErrAccountBalanceMustBeZeroToArchive = errx.New(errx.CategoryConflict, "ACCOUNT_BALANCE_NOT_ZERO", "account real balance must be zero")
+	ErrDescriptionRequired               = errx.New(errx.CategoryValidation, "DESCRIPTION_REQUIRED", "transaction description is required")
+	ErrDescriptionTooLong                = errx.New(errx.CategoryValidation, "DESCRIPTION_TOO_LONG", "transaction description is too long")
+	ErrObservationTooLong                = errx.New(errx.CategoryValidation, "OBSERVATION_TOO_LONG", "transaction observation is too long")
+	ErrAccountNameRequired               = errx.New(errx.CategoryValidation, "ACCOUNT_NAME_REQUIRED", "account name is required")
+	ErrAccountNameTooLong                = errx.New(errx.CategoryValidation, "ACCOUNT_NAME_TOO_LONG", "account name is too long")
+	ErrInconsistentAmountSign            = errx.New(errx.CategoryValidation, "INCONSISTENT_AMOUNT_SIGN", "transaction amount sign is inconsistent with its type")
+	ErrInvalidTransactionType            = errx.New(errx.CategoryValidation, "INVALID_TRANSACTION_TYPE", "invalid transaction type")
+	ErrAccountAlreadyIncluded            = errx.New(errx.CategoryConflict, "ACCOUNT_ALREADY_INCLUDED", "account is already included in overall balance")
+	ErrAccountAlreadyExcluded            = errx.New(errx.CategoryConflict, "ACCOUNT_ALREADY_EXCLUDED", "account is already excluded from overall balance")
+	ErrUnsupportedCurrency               = errx.New(errx.CategoryValidation, "UNSUPPORTED_CURRENCY", "currency is not supported")
+	ErrInvalidAccountKind                = errx.New(errx.CategoryValidation, "INVALID_ACCOUNT_KIND", "invalid account kind")
+	ErrInvalidStatementClosingDay        = errx.New(errx.CategoryValidation, "INVALID_STATEMENT_CLOSING_DAY", "statement closing day must be between 1 and 28")
+	ErrAccountNotCreditCard              = errx.New(errx.CategoryValidation, "ACCOUNT_NOT_CREDIT_CARD", "account is not a credit card")
+	ErrPaymentAccountNotChecking         = errx.New(errx.CategoryValidation, "PAYMENT_ACCOUNT_NOT_CHECKING", "statements must be paid from a checking account")
+	ErrOriginalCurrencySameAsAccount     = errx.New(errx.CategoryValidation, "ORIGINAL_CURRENCY_SAME_AS_ACCOUNT", "the original currency must differ from the account currency")
+	ErrStatementNothingToPay             = errx.New(errx.CategoryConflict, "STATEMENT_NOTHING_TO_PAY", "statement has no unpaid expenses")
+	ErrPixDetailsEmpty                   = errx.New(errx.CategoryValidation, "PIX_DETAILS_EMPTY", "at least one PIX identifier is required")
+	ErrBoletoAmountRequired              = errx.New(errx.CategoryValidation, "BOLETO_AMOUNT_REQUIRED", "the boleto does not carry its amount, it must be informed")
+	ErrBoletoDueDateRequired             = errx.New(errx.CategoryValidation, "BOLETO_DUE_DATE_REQUIRED", "the boleto does not carry its due date, it must be informed")
+	ErrInvalidTag                        = errx.New(errx.CategoryValidation, "INVALID_TAG", "tags must have between 1 and 30 characters")
+	ErrTooManyTags                       = errx.New(errx.CategoryValidation, "TOO_MANY_TAGS", "a transaction can have at most 10 tags")
+	ErrInvalidClearingMethod             = errx.New(errx.CategoryValidation, "INVALID_CLEARING_METHOD", "clearing method must be CHEQUE or TED")
+	ErrClearingDateBeforePayment         = errx.New(errx.CategoryValidation, "CLEARING_DATE_BEFORE_PAYMENT", "expected clearing date cannot be before the payment date")
+	ErrTransactionAlreadyExists          = errx.New(errx.CategoryConflict, "TRANSACTION_ALREADY_EXISTS", "a transaction with this id already exists")
+	ErrAccountModified                   = errx.New(errx.CategoryConflict, "ACCOUNT_MODIFIED", "account was modified concurrently, reload it and try again")
+	ErrInvalidCoordinates                = errx.New(errx.CategoryValidation, "INVALID_COORDINATES", "latitude must be between -90 and 90 and longitude between -180 and 180")
+	ErrPlaceNameTooLong                  = errx.New(errx.CategoryValidation, "PLACE_NAME_TOO_LONG", "place name is too long")
+	ErrForbidden                         = errx.New(errx.CategoryForbidden, "ACCOUNT_ACCESS_FORBIDDEN", "the account is shared with you, only its owner can change it or open it outside of the household")
+	ErrHistoryNothingToArchive           = errx.New(errx.CategoryConflict, "HISTORY_NOTHING_TO_ARCHIVE", "no settled transaction is due before the given date")
+	ErrArchiveDateInFuture               = errx.New(errx.CategoryValidation, "ARCHIVE_DATE_IN_FUTURE", "only the history before today can be archived")
 )
 
+// errPartiallyLoaded is returned by the balances of an account loaded with a TransactionScope, as they need every transaction
+var errPartiallyLoaded = errors.New("the balance of a partially loaded account is unknown")
+
 const (
 	Income     TransactionType = "INCOME"
 	Expense    TransactionType = "EXPENSE"
 	Adjustment TransactionType = "ADJUSTMENT"
 
+	Checking   AccountKind = "CHECKING"
+	CreditCard AccountKind = "CREDIT_CARD"
+	Investment AccountKind = "INVESTMENT"
+	Savings    AccountKind = "SAVINGS"
+
+	Cheque ClearingMethod = "CHEQUE"
+	TED    ClearingMethod = "TED"
+
+	// DefaultCurrency is the currency of accounts created without an explicit one
+	DefaultCurrency = "BRL"
+
+	maxStatementClosingDay = 28
+
 	maxAccountNameLength            = 100
 	maxTransactionDescriptionLength = 100
 	maxTransactionObservationLength = 2500
+	maxTransactionTags              = 10
+	maxTagLength                    = 30
+	maxPlaceNameLength              = 100
+
+	// earthRadiusMeters is the mean radius of the Earth, used for the distances between locations
+	earthRadiusMeters = 6_371_000
 )
 
 // TransactionType represents the type of a financial transaction
 type TransactionType string
 
+// Values lists every valid TransactionType, satisfying validatorx.Enum
+func (TransactionType) Values() []string {
+	return []string{string(Income), string(Expense), string(Adjustment)}
+}
+
+// AccountKind tells how an account holds money: a checking account, a credit card paid through statements,
+// an investment (brokerage) account, whose positions are kept apart from its cash transactions, or a savings
+// account yielding interest on its balance
+type AccountKind string
+
+// Values lists every valid AccountKind, satisfying validatorx.Enum
+func (AccountKind) Values() []string {
+	return []string{string(Checking), string(CreditCard), string(Investment), string(Savings)}
+}
+
+// ClearingMethod tells how a transfer whose funds take days to become available was made
+type ClearingMethod string
+
+// Values lists every valid ClearingMethod, satisfying validatorx.Enum
+func (ClearingMethod) Values() []string {
+	return []string{string(Cheque), string(TED)}
+}
+
+// TransactionListener is notified after a transaction was saved to an account (e.g., to check budgets)
+// Listeners handle their own errors, the transaction is already saved when they run
+type TransactionListener interface {
+	TransactionAdded(ctx context.Context, account *Account, tx Transaction)
+}
+
+// AccountAccess tells whether a user who does not own an account already knows of it, the requests on such an
+// account failing with ErrForbidden instead of ErrAccountNotFound. Every other account stays hidden behind a 404
+type AccountAccess interface {
+	// SharedWith reports whether the account is shared with the user (e.g., in a household they see the accounts of)
+	SharedWith(ctx context.Context, accountID, userID uuid.UUID) (bool, error)
+}
+
 type AccountRepository interface {
 	Save(ctx context.Context, account *Account) error
+	SaveAll(ctx context.Context, accounts ...*Account) error // SaveAll persists several aggregates atomically
+	Delete(ctx context.Context, account *Account) error      // Delete removes the account and its transactions for good, writing its events
 	FindByID(ctx context.Context, accountID uuid.UUID) (*Account, error)
+	// FindByIDScoped loads the account with the transactions of the scope only, saving it leaves the others untouched
+	FindByIDScoped(ctx context.Context, accountID uuid.UUID, scope TransactionScope) (*Account, error)
+	// UpdateByID applies change to the account and saves it, the account being locked against concurrent updates meanwhile
+	UpdateByID(ctx context.Context, accountID uuid.UUID, change func(account *Account) error) (*Account, error)
 	FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error)
+	FindArchivedAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error)
+	// FindAccountIDsDueForDeletion returns the accounts whose deletion was scheduled before the given time
+	FindAccountIDsDueForDeletion(ctx context.Context, before time.Time) ([]uuid.UUID, error)
+}
+
+// TransactionScope restricts the transactions loaded with an account, for the use cases that do not need its whole
+// history (e.g., marking transactions as paid only needs the unpaid ones). The zero value loads every transaction
+type TransactionScope struct {
+	DueFrom    time.Time // DueFrom and DueBefore bound the due dates loaded as [DueFrom, DueBefore), a zero bound leaving its side open
+	DueBefore  time.Time
+	UnpaidOnly bool // UnpaidOnly loads the transactions not paid yet
+}
+
+// Includes reports whether the transaction belongs to the scope
+func (s TransactionScope) Includes(tx Transaction) bool {
+	if !s.DueFrom.IsZero() && tx.DueDate.Before(s.DueFrom) {
+		return false
+	}
+	if !s.DueBefore.IsZero() && !tx.DueDate.Before(s.DueBefore) {
+		return false
+	}
+	return !s.UnpaidOnly || tx.PaidAt == nil
 }
 
 // Transaction represents a single financial entry in an account
@@ -56,9 +177,109 @@ type Transaction struct {
 	Type        TransactionType
 	Description string
 	Observation string
-	Amount      int64
+	Amount      money.Money
 	DueDate     time.Time
 	PaidAt      *time.Time
+	Original    *ForeignAmount // Original is set when the transaction was made in another currency than the account's
+	Pix         *PixDetails    // Pix is set when the transaction is a PIX transfer with known identifiers
+	Tags        []string       // Tags label the transaction across categories (e.g., "vacation"), lowercased and sorted
+	Clearing    *Clearing      // Clearing is set for cheques and TEDs, whose funds become available after they are paid
+	Location    *Location      // Location is where the transaction was made, set by the mobile app
+}
+
+// Location is the place a transaction was made, in WGS 84 coordinates as reported by the devices
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	PlaceName string // PlaceName is optional (e.g., "Padaria Santa Marta")
+}
+
+// normalize validates the coordinates, returning the location with the place name trimmed
+func (l Location) normalize() (Location, error) {
+	if math.IsNaN(l.Latitude) || math.IsNaN(l.Longitude) || math.Abs(l.Latitude) > 90 || math.Abs(l.Longitude) > 180 {
+		return Location{}, ErrInvalidCoordinates
+	}
+	l.PlaceName = strings.TrimSpace(l.PlaceName)
+	if utf8.RuneCountInString(l.PlaceName) > maxPlaceNameLength {
+		return Location{}, ErrPlaceNameTooLong.With("max_length", maxPlaceNameLength)
+	}
+	return l, nil
+}
+
+// DistanceTo returns the great-circle distance in meters between the location and the given coordinates
+func (l Location) DistanceTo(latitude, longitude float64) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, latitude*math.Pi/180
+	dLat, dLng := lat2-lat1, (longitude-l.Longitude)*math.Pi/180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// LocatedTransaction is a transaction made at a known place, with the account it belongs to
+type LocatedTransaction struct {
+	AccountID      uuid.UUID
+	Transaction    Transaction
+	DistanceMeters *float64 // DistanceMeters is how far the transaction was made from the searched point, nil when not searching near one
+}
+
+// Clearing tells when the funds of a cheque or a TED become available
+// Until ExpectedClearingDate the paid transaction is pending clearance and stays out of the real balance
+type Clearing struct {
+	Method               ClearingMethod
+	ExpectedClearingDate time.Time // ExpectedClearingDate is distinct from the due date, it follows the payment
+}
+
+// settled tells whether the transaction was paid and its funds are available at the given time, so it can no longer
+// change either balance
+func (tx Transaction) settled(now time.Time) bool {
+	return tx.PaidAt != nil && !tx.PaidAt.After(now) && !tx.pendingClearance(now)
+}
+
+// pendingClearance tells whether the transaction was paid but its funds are still clearing at the given time
+func (tx Transaction) pendingClearance(now time.Time) bool {
+	return tx.PaidAt != nil && !tx.PaidAt.After(now) && tx.Clearing != nil && tx.Clearing.ExpectedClearingDate.After(now)
+}
+
+// PixDetails are the banking identifiers of a PIX transfer, kept to reconcile it with the bank statement
+type PixDetails struct {
+	EndToEndID string      // EndToEndID identifies the transfer across banks
+	Key        string      // Key is the PIX key of the other party: the payee of expenses, the payer of incomes
+	KeyType    pix.KeyType // KeyType is derived from Key
+	QRPayload  string      // QRPayload is the "copia e cola" BR Code the transfer paid
+}
+
+// normalize validates the identifiers, returning them with the key in the form the DICT stores it
+func (d PixDetails) normalize() (PixDetails, error) {
+	d.EndToEndID = strings.TrimSpace(d.EndToEndID)
+	d.QRPayload = strings.TrimSpace(d.QRPayload)
+	if d.EndToEndID == "" && strings.TrimSpace(d.Key) == "" && d.QRPayload == "" {
+		return PixDetails{}, ErrPixDetailsEmpty
+	}
+
+	if d.EndToEndID != "" {
+		if err := pix.ValidateEndToEndID(d.EndToEndID); err != nil {
+			return PixDetails{}, err
+		}
+	}
+	d.KeyType = ""
+	if strings.TrimSpace(d.Key) != "" {
+		key, keyType, err := pix.NormalizeKey(d.Key)
+		if err != nil {
+			return PixDetails{}, err
+		}
+		d.Key, d.KeyType = key, keyType
+	}
+	if d.QRPayload != "" {
+		if err := pix.ValidateQRPayload(d.QRPayload); err != nil {
+			return PixDetails{}, err
+		}
+	}
+	return d, nil
+}
+
+// ForeignAmount is the amount of a transaction in the currency it was made in, and the rate it was converted at
+type ForeignAmount struct {
+	Amount money.Money
+	Rate   money.ExchangeRate
 }
 
 // Account represents a user's account, which holds a collection of transactions (our aggregate root)
@@ -66,49 +287,117 @@ type Account struct {
 	ID                      uuid.UUID
 	UserID                  uuid.UUID
 	Name                    string
+	Currency                string
+	Kind                    AccountKind
+	StatementClosingDay     int // StatementClosingDay is the day of month a credit card statement closes, zero for checking accounts
 	IncludeInOverallBalance bool
 	transactions            []Transaction
 	ArchivedAt              *time.Time
+	DeleteAfter             *time.Time      // DeleteAfter is when the account is deleted for good, nil unless the user asked to delete it
+	Version                 int64           // Version grows on every saved change of the account or its transactions, zero until first saved
+	events                  []recordedEvent // events are written to the outbox along with the aggregate, then cleared
+	archived                []Transaction   // archived are moved to the archived history along with the aggregate, then cleared
+	// loaded holds the IDs of the transactions in storage of an account loaded with a TransactionScope, nil when every
+	// transaction was loaded. Saving the account only replaces these, the transactions out of the scope being kept
+	loaded map[uuid.UUID]bool
 }
 
-// NewAccount creates a new Account with the given user ID and name
-func NewAccount(userID uuid.UUID, name string, includeInBalance bool) (*Account, error) {
+// recordedEvent is an event of the aggregate waiting to be written to the outbox
+type recordedEvent struct {
+	event      contracts.Event
+	occurredAt time.Time
+}
+
+// NewAccount creates a new Account with the given user ID, name and currency
+func NewAccount(userID uuid.UUID, name, currency string, includeInBalance bool, ids idgen.Generator) (*Account, error) {
 	if strings.TrimSpace(name) == "" {
 		return nil, ErrAccountNameRequired
 	}
 	if len(name) > maxAccountNameLength {
-		return nil, fmt.Errorf("account name cannot exceed %d characters", maxAccountNameLength)
+		return nil, ErrAccountNameTooLong.With("max_length", maxAccountNameLength)
+	}
+	if !money.IsKnownCurrency(currency) {
+		return nil, ErrUnsupportedCurrency.With("currency", currency)
 	}
 
 	return &Account{
-		ID:                      uuid.New(),
+		ID:                      ids.NewID(),
 		UserID:                  userID,
 		Name:                    name,
+		Currency:                strings.ToUpper(currency),
+		Kind:                    Checking,
 		IncludeInOverallBalance: includeInBalance,
 		transactions:            make([]Transaction, 0),
 	}, nil
 }
 
-// AddTransaction adds a new transaction to the account
-func (a *Account) AddTransaction(txType TransactionType, description, observation string, amount int64, categoryID *uuid.UUID, dueDate time.Time, paidAt *time.Time, clock clock.Clock) error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+// NewCreditCardAccount creates a new credit card Account whose statements close on closingDay
+func NewCreditCardAccount(userID uuid.UUID, name, currency string, closingDay int, includeInBalance bool, ids idgen.Generator) (*Account, error) {
+	if closingDay < 1 || closingDay > maxStatementClosingDay {
+		return nil, ErrInvalidStatementClosingDay.With("closing_day", closingDay)
+	}
+
+	account, err := NewAccount(userID, name, currency, includeInBalance, ids)
+	if err != nil {
+		return nil, err
+	}
+	account.Kind = CreditCard
+	account.StatementClosingDay = closingDay
+
+	return account, nil
+}
+
+// NewInvestmentAccount creates a new investment Account, holding the cash of a brokerage account
+func NewInvestmentAccount(userID uuid.UUID, name, currency string, includeInBalance bool, ids idgen.Generator) (*Account, error) {
+	account, err := NewAccount(userID, name, currency, includeInBalance, ids)
+	if err != nil {
+		return nil, err
+	}
+	account.Kind = Investment
+
+	return account, nil
+}
+
+// NewSavingsAccount creates a new savings Account, whose balance yields interest at the rate set for it
+func NewSavingsAccount(userID uuid.UUID, name, currency string, includeInBalance bool, ids idgen.Generator) (*Account, error) {
+	account, err := NewAccount(userID, name, currency, includeInBalance, ids)
+	if err != nil {
+		return nil, err
+	}
+	account.Kind = Savings
+
+	return account, nil
+}
+
+// AddTransaction adds a new transaction to the account, its ID created by ids
+func (a *Account) AddTransaction(txType TransactionType, description, observation string, amount money.Money, categoryID *uuid.UUID, dueDate time.Time, paidAt *time.Time, ids idgen.Generator, clock clock.Clock) error {
+	return a.AddTransactionWithID(ids.NewID(), txType, description, observation, amount, categoryID, dueDate, paidAt, clock)
+}
+
+// AddTransactionWithID adds a new transaction whose ID was chosen by the client (e.g., created offline)
+func (a *Account) AddTransactionWithID(txID uuid.UUID, txType TransactionType, description, observation string, amount money.Money, categoryID *uuid.UUID, dueDate time.Time, paidAt *time.Time, clock clock.Clock) error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
 	if strings.TrimSpace(description) == "" {
 		return ErrDescriptionRequired
 	}
 	if utf8.RuneCountInString(description) > maxTransactionDescriptionLength {
-		return fmt.Errorf("transaction description cannot exceed %d characters", maxTransactionDescriptionLength)
+		return ErrDescriptionTooLong.With("max_length", maxTransactionDescriptionLength)
 	}
 
 	if strings.TrimSpace(observation) != "" {
 		if utf8.RuneCountInString(observation) > maxTransactionObservationLength {
-			return fmt.Errorf("transaction observation cannot exceed %d characters", maxTransactionObservationLength)
+			return ErrObservationTooLong.With("max_length", maxTransactionObservationLength)
 		}
 	}
 
-	if amount == 0 {
+	if amount.Currency != a.Currency {
+		return money.ErrCurrencyMismatch.With("account_currency", a.Currency).With("amount_currency", amount.Currency)
+	}
+
+	if amount.IsZero() {
 		return ErrAmountCannotBeZero
 	}
 
@@ -121,7 +410,7 @@ func (a *Account) AddTransaction(txType TransactionType, description, observatio
 
 	isIncome := txType == Income
 	isExpense := txType == Expense
-	if (isIncome && amount < 0) || (isExpense && amount > 0) {
+	if (isIncome && amount.IsNegative()) || (isExpense && amount.IsPositive()) {
 		return ErrInconsistentAmountSign
 	}
 
@@ -129,8 +418,12 @@ func (a *Account) AddTransaction(txType TransactionType, description, observatio
 		return ErrPaymentDateInFuture
 	}
 
+	if _, err := a.findTransaction(txID); err == nil {
+		return ErrTransactionAlreadyExists.With("transaction_id", txID)
+	}
+
 	tx := Transaction{
-		ID:          uuid.New(),
+		ID:          txID,
 		CategoryID:  categoryID,
 		Type:        txType,
 		Amount:      amount,
@@ -140,15 +433,128 @@ func (a *Account) AddTransaction(txType TransactionType, description, observatio
 		PaidAt:      paidAt,
 	}
 
-	a.transactions = append(a.transactions, tx)
+	a.appendTransaction(tx, clock.Now())
+
+	return nil
+}
+
+// AddForeignCurrencyTransaction adds a transaction made in another currency, keeping its original amount
+// The amount is what the account was charged, the conversion rate is derived from both amounts
+func (a *Account) AddForeignCurrencyTransaction(txType TransactionType, description, observation string, amount, original money.Money, categoryID *uuid.UUID, dueDate time.Time, paidAt *time.Time, ids idgen.Generator, clock clock.Clock) error {
+	if original.Currency == a.Currency {
+		return ErrOriginalCurrencySameAsAccount.With("currency", original.Currency)
+	}
+	if !money.IsKnownCurrency(original.Currency) {
+		return ErrUnsupportedCurrency.With("currency", original.Currency)
+	}
+	if amount.IsZero() || original.IsZero() {
+		return ErrAmountCannotBeZero
+	}
+	if original.IsNegative() != amount.IsNegative() {
+		return ErrInconsistentAmountSign
+	}
+
+	rate, err := money.RateOf(original, amount)
+	if err != nil {
+		return err
+	}
+
+	if err := a.AddTransaction(txType, description, observation, amount, categoryID, dueDate, paidAt, ids, clock); err != nil {
+		return err
+	}
+	a.transactions[len(a.transactions)-1].Original = &ForeignAmount{Amount: original, Rate: rate}
+
+	return nil
+}
+
+// AttachPix records the PIX identifiers of a transaction of the account, replacing the ones it had
+func (a *Account) AttachPix(txID uuid.UUID, details PixDetails) error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
+	}
+
+	normalized, err := details.normalize()
+	if err != nil {
+		return err
+	}
+	target, err := a.findTransaction(txID)
+	if err != nil {
+		return err
+	}
+	target.Pix = &normalized
+
+	return nil
+}
+
+// SetTransactionTags replaces the tags of a transaction of the account, an empty list removing them
+func (a *Account) SetTransactionTags(txID uuid.UUID, tags []string) error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
+	}
+
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	target, err := a.findTransaction(txID)
+	if err != nil {
+		return err
+	}
+	target.Tags = normalized
+
+	return nil
+}
+
+// SetTransactionClearing records when the funds of a cheque or a TED of the account become available, nil removing it
+func (a *Account) SetTransactionClearing(txID uuid.UUID, clearing *Clearing) error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
+	}
+
+	target, err := a.findTransaction(txID)
+	if err != nil {
+		return err
+	}
+	if clearing != nil {
+		if !slices.Contains(ClearingMethod("").Values(), string(clearing.Method)) {
+			return ErrInvalidClearingMethod.With("clearing_method", clearing.Method)
+		}
+		if target.PaidAt != nil && clearing.ExpectedClearingDate.Before(*target.PaidAt) {
+			return ErrClearingDateBeforePayment
+		}
+		clearing = &Clearing{Method: clearing.Method, ExpectedClearingDate: clearing.ExpectedClearingDate}
+	}
+	target.Clearing = clearing
+
+	return nil
+}
+
+// SetTransactionLocation records where a transaction of the account was made, nil removing it
+func (a *Account) SetTransactionLocation(txID uuid.UUID, location *Location) error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
+	}
+
+	target, err := a.findTransaction(txID)
+	if err != nil {
+		return err
+	}
+	if location != nil {
+		normalized, err := location.normalize()
+		if err != nil {
+			return err
+		}
+		location = &normalized
+	}
+	target.Location = location
 
 	return nil
 }
 
 // DeleteTransaction removes a transaction from the account by its ID
 func (a *Account) DeleteTransaction(txID uuid.UUID) error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
 	foundIndex := -1
@@ -160,7 +566,7 @@ func (a *Account) DeleteTransaction(txID uuid.UUID) error {
 	}
 
 	if foundIndex == -1 {
-		return ErrTransactionNotFound
+		return ErrTransactionNotFound.With("transaction_id", txID)
 	}
 
 	a.transactions = append(a.transactions[:foundIndex], a.transactions[foundIndex+1:]...)
@@ -171,37 +577,169 @@ func (a *Account) DeleteTransaction(txID uuid.UUID) error {
 // Real Balance calculates the "real" balance of the account
 // Adds only the transactions that have already been paid/completed to date
 // Represents the amount of money the user actually has
-func (a *Account) RealBalance(clock clock.Clock) int64 {
-	var total int64 = 0
+func (a *Account) RealBalance(clock clock.Clock) (money.Money, error) {
+	if a.Partial() {
+		return money.Money{}, errPartiallyLoaded
+	}
+	total := money.Zero(a.Currency)
 	now := clock.Now()
 	for _, tx := range a.transactions {
 		/*
 		 The transaction only appears in the real balance if:
 		 		1. The PaidAt field is not null
 		  	2. The payment date is not in the future
+		  	3. Its funds are not still clearing (cheques and TEDs)
 		*/
-		if tx.PaidAt != nil && !tx.PaidAt.After(now) {
-			total += tx.Amount
+		if tx.PaidAt != nil && !tx.PaidAt.After(now) && !tx.pendingClearance(now) {
+			var err error
+			if total, err = total.Add(tx.Amount); err != nil {
+				return money.Money{}, err
+			}
 		}
 	}
-	return total
+	return total, nil
+}
+
+// PendingClearanceBalance calculates the amount of the cheques and TEDs paid but not cleared yet
+// Together with the real balance and the unpaid transactions, it makes up the projected balance
+func (a *Account) PendingClearanceBalance(clock clock.Clock) (money.Money, error) {
+	if a.Partial() {
+		return money.Money{}, errPartiallyLoaded
+	}
+	total := money.Zero(a.Currency)
+	now := clock.Now()
+	for _, tx := range a.transactions {
+		if tx.pendingClearance(now) {
+			var err error
+			if total, err = total.Add(tx.Amount); err != nil {
+				return money.Money{}, err
+			}
+		}
+	}
+	return total, nil
 }
 
 // ProjectedBalance calculates the "projected" or "net" balance
 // It adds up ALL transactions, paid and pending
 // Represents the "net value" of the account, considering all future commitments
-func (a *Account) ProjectedBalance() int64 {
-	var total int64 = 0
+func (a *Account) ProjectedBalance() (money.Money, error) {
+	return a.projectedBalance(time.Time{})
+}
+
+// ProjectedBalanceAsOf calculates the projected balance on a date
+// It adds up the transactions due on or before that date, paid and pending, leaving the later ones out
+func (a *Account) ProjectedBalanceAsOf(asOf time.Time) (money.Money, error) {
+	y, m, d := asOf.Date()
+	return a.projectedBalance(time.Date(y, m, d+1, 0, 0, 0, 0, asOf.Location()))
+}
+
+// projectedBalance adds up the transactions due before the until instant, all of them when it is zero
+func (a *Account) projectedBalance(until time.Time) (money.Money, error) {
+	if a.Partial() {
+		return money.Money{}, errPartiallyLoaded
+	}
+	total := money.Zero(a.Currency)
 	for _, tx := range a.transactions {
-		total += tx.Amount
+		if !until.IsZero() && !tx.DueDate.Before(until) {
+			continue
+		}
+		var err error
+		if total, err = total.Add(tx.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// BalanceTotals sums the transactions of an account by type, expenses being negative and adjustments either way
+type BalanceTotals struct {
+	Income      money.Money
+	Expenses    money.Money
+	Adjustments money.Money
+}
+
+// Total returns the sum of the income, expenses and adjustments
+func (t BalanceTotals) Total() (money.Money, error) {
+	total, err := t.Income.Add(t.Expenses)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return total.Add(t.Adjustments)
+}
+
+// add sums the amount of the transaction into the totals of its type
+func (t *BalanceTotals) add(tx Transaction) error {
+	var err error
+	switch tx.Type {
+	case Income:
+		t.Income, err = t.Income.Add(tx.Amount)
+	case Expense:
+		t.Expenses, err = t.Expenses.Add(tx.Amount)
+	default:
+		t.Adjustments, err = t.Adjustments.Add(tx.Amount)
 	}
-	return total
+	return err
+}
+
+// BalanceBreakdown explains the balances of an account: the real balance is made of the transactions paid and cleared
+// to date, the projected balance adds the cheques and TEDs still clearing and the transactions not paid yet
+type BalanceBreakdown struct {
+	Paid             BalanceTotals
+	PendingClearance money.Money
+	Unpaid           BalanceTotals // Unpaid includes the transactions whose payment date is still ahead
+	Real             money.Money
+	Projected        money.Money
+}
+
+// BalanceBreakdown decomposes the real and projected balances of the account by the state and the type of its transactions
+func (a *Account) BalanceBreakdown(clock clock.Clock) (BalanceBreakdown, error) {
+	if a.Partial() {
+		return BalanceBreakdown{}, errPartiallyLoaded
+	}
+	zero := money.Zero(a.Currency)
+	breakdown := BalanceBreakdown{
+		Paid:             BalanceTotals{Income: zero, Expenses: zero, Adjustments: zero},
+		PendingClearance: zero,
+		Unpaid:           BalanceTotals{Income: zero, Expenses: zero, Adjustments: zero},
+	}
+
+	now := clock.Now()
+	for _, tx := range a.transactions {
+		var err error
+		switch {
+		case tx.pendingClearance(now):
+			breakdown.PendingClearance, err = breakdown.PendingClearance.Add(tx.Amount)
+		case tx.PaidAt != nil && !tx.PaidAt.After(now):
+			err = breakdown.Paid.add(tx)
+		default:
+			err = breakdown.Unpaid.add(tx)
+		}
+		if err != nil {
+			return BalanceBreakdown{}, err
+		}
+	}
+
+	var err error
+	if breakdown.Real, err = breakdown.Paid.Total(); err != nil {
+		return BalanceBreakdown{}, err
+	}
+	unpaid, err := breakdown.Unpaid.Total()
+	if err != nil {
+		return BalanceBreakdown{}, err
+	}
+	if breakdown.Projected, err = breakdown.Real.Add(breakdown.PendingClearance); err != nil {
+		return BalanceBreakdown{}, err
+	}
+	if breakdown.Projected, err = breakdown.Projected.Add(unpaid); err != nil {
+		return BalanceBreakdown{}, err
+	}
+	return breakdown, nil
 }
 
 // MarkTransactionAsPaid marks a specific transaction as paid at a given time
 func (a *Account) MarkTransactionAsPaid(txID uuid.UUID, paidAt time.Time, clock clock.Clock) error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
 	if paidAt.After(clock.Now()) {
@@ -224,8 +762,8 @@ func (a *Account) MarkTransactionAsPaid(txID uuid.UUID, paidAt time.Time, clock
 
 // MarkTransactionAsUnpaid marks a specific transaction as unpaid
 func (a *Account) MarkTransactionAsUnpaid(txID uuid.UUID) error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
 	target, err := a.findTransaction(txID)
@@ -242,25 +780,93 @@ func (a *Account) MarkTransactionAsUnpaid(txID uuid.UUID) error {
 	return nil
 }
 
+// StatementRange returns the half-open range [start, end) of due dates billed in the statement closing on the given month
+// A statement starts the day after the previous closing day and ends on the closing day itself
+func (a *Account) StatementRange(year int, month time.Month) (start, end time.Time, err error) {
+	if a.Kind != CreditCard {
+		return time.Time{}, time.Time{}, ErrAccountNotCreditCard
+	}
+
+	end = time.Date(year, month, a.StatementClosingDay+1, 0, 0, 0, 0, time.UTC)
+	return end.AddDate(0, -1, 0), end, nil
+}
+
+// PayStatement marks every unpaid expense of the statement closing on the given month as paid
+// The payment is credited to the card, so the statement no longer counts as debt once paid
+// It returns the total paid as a positive amount, to be charged to the account that paid the statement
+func (a *Account) PayStatement(year int, month time.Month, paidAt time.Time, ids idgen.Generator, clock clock.Clock) (money.Money, error) {
+	if err := a.EnsureWritable(); err != nil {
+		return money.Money{}, err
+	}
+
+	start, end, err := a.StatementRange(year, month)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if paidAt.After(clock.Now()) {
+		return money.Money{}, ErrPaymentDateInFuture
+	}
+
+	total := money.Zero(a.Currency)
+	for i := range a.transactions {
+		tx := &a.transactions[i]
+		if tx.Type != Expense || tx.PaidAt != nil || tx.DueDate.Before(start) || !tx.DueDate.Before(end) {
+			continue
+		}
+		if total, err = total.Sub(tx.Amount); err != nil {
+			return money.Money{}, err
+		}
+		tx.PaidAt = &paidAt
+	}
+
+	period := fmt.Sprintf("%04d-%02d", year, month)
+	if total.IsZero() {
+		return money.Money{}, ErrStatementNothingToPay.With("period", period)
+	}
+
+	a.appendTransaction(Transaction{
+		ID:          ids.NewID(),
+		Type:        Income,
+		Amount:      total,
+		Description: "Pagamento da fatura " + period,
+		DueDate:     paidAt,
+		PaidAt:      &paidAt,
+	}, clock.Now())
+
+	return total, nil
+}
+
 // Archive marks the account as archived, preventing new modifications
 func (a *Account) Archive(clock clock.Clock) error {
+	if a.DeleteAfter != nil {
+		return ErrAccountPendingDeletion
+	}
 	if a.ArchivedAt != nil {
 		return ErrAccountAlreadyArchived
 	}
 
-	if a.ProjectedBalance() != 0 {
-		return ErrAccountBalanceMustBeZeroToArchive
+	balance, err := a.ProjectedBalance()
+	if err != nil {
+		return err
+	}
+	if !balance.IsZero() {
+		return ErrAccountBalanceMustBeZeroToArchive.With("projected_balance", balance.Amount)
 	}
 
 	now := clock.Now()
 	a.ArchivedAt = &now
 	a.IncludeInOverallBalance = false
+	a.recordEvent(contracts.AccountArchivedV1{AccountID: a.ID, UserID: a.UserID, ArchivedAt: now.UTC()}, now)
 
 	return nil
 }
 
 // Unarchive removes the archived status from the account
 func (a *Account) Unarchive() error {
+	if a.DeleteAfter != nil {
+		return ErrAccountPendingDeletion
+	}
 	if a.ArchivedAt == nil {
 		return ErrAccountNotArchived
 	}
@@ -271,11 +877,66 @@ func (a *Account) Unarchive() error {
 	return nil
 }
 
-// EnableOverallBalance sets the account to be included in overall balance calculations
-func (a *Account) EnableOverallBalance() error {
+// ScheduleDeletion marks the account to be deleted for good, with its transactions, once the grace period elapsed
+// Until then the account can not be modified and the deletion can be cancelled. Unlike archiving, the balance
+// does not need to be zero, since nothing is left of the account afterwards
+func (a *Account) ScheduleDeletion(gracePeriod time.Duration, clock clock.Clock) error {
+	if a.DeleteAfter != nil {
+		return ErrAccountDeletionAlreadyScheduled
+	}
+
+	now := clock.Now()
+	deleteAfter := now.Add(gracePeriod)
+	a.DeleteAfter = &deleteAfter
+	a.recordEvent(contracts.AccountDeletionScheduledV1{AccountID: a.ID, UserID: a.UserID, DeleteAfter: deleteAfter.UTC()}, now)
+
+	return nil
+}
+
+// CancelDeletion keeps the account whose deletion was scheduled, as it was before
+func (a *Account) CancelDeletion(clock clock.Clock) error {
+	if a.DeleteAfter == nil {
+		return ErrAccountDeletionNotScheduled
+	}
+
+	now := clock.Now()
+	a.DeleteAfter = nil
+	a.recordEvent(contracts.AccountDeletionCancelledV1{AccountID: a.ID, UserID: a.UserID, CancelledAt: now.UTC()}, now)
+
+	return nil
+}
+
+// Delete records the deletion of the account whose grace period elapsed, the repository deleting it for good
+func (a *Account) Delete(clock clock.Clock) error {
+	if a.DeleteAfter == nil {
+		return ErrAccountDeletionNotScheduled
+	}
+	now := clock.Now()
+	if now.Before(*a.DeleteAfter) {
+		return ErrAccountDeletionNotDue.With("delete_after", a.DeleteAfter.UTC())
+	}
+
+	a.recordEvent(contracts.AccountDeletedV1{AccountID: a.ID, UserID: a.UserID, DeletedAt: now.UTC()}, now)
+
+	return nil
+}
+
+// EnsureWritable rejects the changes to an archived account or to one scheduled for deletion
+func (a *Account) EnsureWritable() error {
+	if a.DeleteAfter != nil {
+		return ErrAccountPendingDeletion
+	}
 	if a.ArchivedAt != nil {
 		return ErrAccountArchived
 	}
+	return nil
+}
+
+// EnableOverallBalance sets the account to be included in overall balance calculations
+func (a *Account) EnableOverallBalance() error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
+	}
 
 	if a.IncludeInOverallBalance {
 		return ErrAccountAlreadyIncluded
@@ -288,8 +949,8 @@ func (a *Account) EnableOverallBalance() error {
 
 // ExcludeFromOverallBalance removes the account from overall balance calculations
 func (a *Account) DisableOverallBalance() error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
 	if !a.IncludeInOverallBalance {
@@ -302,21 +963,27 @@ func (a *Account) DisableOverallBalance() error {
 }
 
 // AdjustBalance adjusts an account balance before archiving, keeping a history of chagens as a transaction
-func (a *Account) AdjustBalance(newBalance int64, clock clock.Clock) error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+func (a *Account) AdjustBalance(newBalance money.Money, ids idgen.Generator, clock clock.Clock) error {
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
-	currentBalance := a.ProjectedBalance()
-	diff := newBalance - currentBalance
+	currentBalance, err := a.ProjectedBalance()
+	if err != nil {
+		return err
+	}
+	diff, err := newBalance.Sub(currentBalance)
+	if err != nil {
+		return err
+	}
 
-	if diff == 0 {
+	if diff.IsZero() {
 		return nil
 	}
 
 	now := clock.Now()
 	adjustmentTx := Transaction{
-		ID:          uuid.New(),
+		ID:          ids.NewID(),
 		CategoryID:  nil,
 		Type:        Adjustment,
 		Amount:      diff,
@@ -325,21 +992,108 @@ func (a *Account) AdjustBalance(newBalance int64, clock clock.Clock) error {
 		PaidAt:      &now,
 	}
 
-	a.transactions = append(a.transactions, adjustmentTx)
+	a.appendTransaction(adjustmentTx, now)
 	return nil
 }
 
+// ArchivalSuggestion suggests rolling the oldest history of an account holding too many active transactions into an
+// opening balance, since every change of the account loads all of them
+type ArchivalSuggestion struct {
+	ActiveTransactions    int
+	MaxActiveTransactions int
+	Before                time.Time // Before is the first day of the month whose settled history is kept, the older one being archived
+}
+
+// SuggestArchival returns the archival to suggest once the account holds more than maxActive transactions, nil when
+// it does not or has no settled history to archive. The suggestion archives whole months of settled transactions,
+// the oldest first, until about half of maxActive are left, never reaching into the current month
+func (a *Account) SuggestArchival(maxActive int, now time.Time) *ArchivalSuggestion {
+	if maxActive <= 0 || a.Partial() || len(a.transactions) <= maxActive {
+		return nil
+	}
+
+	currentMonth := clock.StartOfMonthIn(now, time.UTC)
+	var settled []time.Time
+	for _, tx := range a.transactions {
+		if tx.settled(now) && tx.DueDate.Before(currentMonth) {
+			settled = append(settled, tx.DueDate)
+		}
+	}
+	if len(settled) == 0 {
+		return nil
+	}
+	slices.SortFunc(settled, time.Time.Compare)
+
+	excess := min(len(a.transactions)-maxActive/2, len(settled))
+	before := clock.StartOfMonthIn(settled[excess-1], time.UTC).AddDate(0, 1, 0)
+	return &ArchivalSuggestion{
+		ActiveTransactions:    len(a.transactions),
+		MaxActiveTransactions: maxActive,
+		Before:                before,
+	}
+}
+
+// ArchiveHistory rolls the settled transactions due before the given date into a single opening balance adjustment,
+// keeping both balances, and moves them to the archived history when the account is saved. The unpaid transactions
+// and the ones still clearing stay, whatever their due date. It returns the number of archived transactions
+func (a *Account) ArchiveHistory(before time.Time, ids idgen.Generator, clock clock.Clock) (int, error) {
+	if err := a.EnsureWritable(); err != nil {
+		return 0, err
+	}
+	if a.Partial() {
+		return 0, errPartiallyLoaded
+	}
+	now := clock.Now()
+	if before.After(now) {
+		return 0, ErrArchiveDateInFuture.With("before", before)
+	}
+
+	opening := money.New(0, a.Currency)
+	var kept, archived []Transaction
+	for _, tx := range a.transactions {
+		if !tx.settled(now) || !tx.DueDate.Before(before) {
+			kept = append(kept, tx)
+			continue
+		}
+		sum, err := opening.Add(tx.Amount)
+		if err != nil {
+			return 0, err
+		}
+		opening = sum
+		archived = append(archived, tx)
+	}
+	if len(archived) == 0 {
+		return 0, ErrHistoryNothingToArchive.With("before", before)
+	}
+
+	a.transactions = kept
+	a.archived = append(a.archived, archived...)
+	// A history summing to zero needs no opening balance
+	if !opening.IsZero() {
+		lastDay := before.AddDate(0, 0, -1)
+		a.appendTransaction(Transaction{
+			ID:          ids.NewID(),
+			Type:        Adjustment,
+			Amount:      opening,
+			Description: "Saldo de abertura até " + lastDay.Format(time.DateOnly),
+			DueDate:     lastDay,
+			PaidAt:      &lastDay,
+		}, now)
+	}
+	return len(archived), nil
+}
+
 // ChangeName is used to change the name of an already created account
 func (a *Account) ChangeName(name string) error {
-	if a.ArchivedAt != nil {
-		return ErrAccountArchived
+	if err := a.EnsureWritable(); err != nil {
+		return err
 	}
 
 	if strings.TrimSpace(name) == "" {
 		return ErrAccountNameRequired
 	}
 	if utf8.RuneCountInString(name) > maxAccountNameLength {
-		return fmt.Errorf("account name cannot exceed %d characters", maxAccountNameLength)
+		return ErrAccountNameTooLong.With("max_length", maxAccountNameLength)
 	}
 
 	a.Name = name
@@ -357,6 +1111,78 @@ func (a *Account) GetArchivedAt() *time.Time {
 	return &archivedCopy
 }
 
+// PurgeAt returns when the archived account is due to be purged, nil when it is not archived
+// or when archived accounts are kept forever (zero retention)
+func (a *Account) PurgeAt(retention time.Duration) *time.Time {
+	if a.ArchivedAt == nil || retention <= 0 {
+		return nil
+	}
+
+	purgeAt := a.ArchivedAt.Add(retention)
+	return &purgeAt
+}
+
+// appendTransaction adds a transaction to the account, recording its creation
+func (a *Account) appendTransaction(tx Transaction, occurredAt time.Time) {
+	a.transactions = append(a.transactions, tx)
+	a.recordEvent(contracts.TransactionCreatedV1{
+		TransactionID: tx.ID,
+		AccountID:     a.ID,
+		UserID:        a.UserID,
+		Type:          string(tx.Type),
+		Amount:        tx.Amount.Amount,
+		Currency:      tx.Amount.Currency,
+		DueDate:       tx.DueDate,
+		PaidAt:        tx.PaidAt,
+		CategoryID:    tx.CategoryID,
+	}, occurredAt)
+}
+
+// recordEvent records an event of the aggregate, to be written to the outbox when the account is saved
+func (a *Account) recordEvent(event contracts.Event, occurredAt time.Time) {
+	a.events = append(a.events, recordedEvent{event: event, occurredAt: occurredAt})
+}
+
+// clearEvents forgets the recorded events and archived transactions once the repository persisted them
+func (a *Account) clearEvents() {
+	a.events = nil
+	a.archived = nil
+}
+
+// Archived returns the transactions archived since the account was loaded, moved to the archived history when saved
+func (a *Account) Archived() []Transaction {
+	return slices.Clone(a.archived)
+}
+
+// Partial reports whether the account was loaded with a TransactionScope, holding some of its transactions only
+func (a *Account) Partial() bool {
+	return a.loaded != nil
+}
+
+// loadedTransactionIDs returns the IDs of the transactions in storage a partially loaded account replaces when saved
+func (a *Account) loadedTransactionIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(a.loaded))
+	for id := range a.loaded {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// setPartial records the account as loaded with a TransactionScope, its transactions being the ones it replaces when saved
+func (a *Account) setPartial() {
+	a.loaded = make(map[uuid.UUID]bool, len(a.transactions))
+	for _, tx := range a.transactions {
+		a.loaded[tx.ID] = true
+	}
+}
+
+// markLoaded records the transactions of a partially loaded account as the ones in storage, once it was saved
+func (a *Account) markLoaded() {
+	if a.Partial() {
+		a.setPartial()
+	}
+}
+
 // Transactions returns a copy of the account's transactions
 func (a *Account) Transactions() []Transaction {
 	txCopy := make([]Transaction, len(a.transactions))
@@ -364,6 +1190,26 @@ func (a *Account) Transactions() []Transaction {
 	return txCopy
 }
 
+// NormalizeTags lowercases the tags and collapses their spaces, dropping repeated ones and sorting them, nil when there are none
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTag.With("tag", tag)
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxTransactionTags {
+		return nil, ErrTooManyTags.With("max_tags", maxTransactionTags)
+	}
+
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
 // findTransaction finds a transaction by its ID within the account
 func (a *Account) findTransaction(txID uuid.UUID) (*Transaction, error) {
 	for i := range a.transactions {
@@ -371,5 +1217,5 @@ func (a *Account) findTransaction(txID uuid.UUID) (*Transaction, error) {
 			return &a.transactions[i], nil
 		}
 	}
-	return nil, ErrTransactionNotFound
+	return nil, ErrTransactionNotFound.With("transaction_id", txID)
 }
@@ -0,0 +1,36 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/inbox"
+	"github.com/jackc/pgx/v5"
+)
+
+// EventHandlers returns the handlers of the events the ledger consumes, to be run through the inbox
+func EventHandlers() map[inbox.Route]inbox.Handler {
+	return map[inbox.Route]inbox.Handler{
+		inbox.RouteOf(contracts.UserRegisteredV1{}): mirrorRegisteredUser,
+	}
+}
+
+// mirrorRegisteredUser copies a user registered on the identity service into the ledger
+// Credentials are owned by the identity service, the ledger copy only satisfies foreign keys
+func mirrorRegisteredUser(ctx context.Context, tx pgx.Tx, envelope contracts.Envelope) error {
+	var event contracts.UserRegisteredV1
+	if err := envelope.Decode(&event); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO users (id, name, email, password_hash, created_at)
+		VALUES ($1, $2, $3, '', $4)
+		ON CONFLICT (id) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, event.UserID, event.Name, event.Email, event.RegisteredAt); err != nil {
+		return fmt.Errorf("failed to mirror user %s: %w", event.UserID, err)
+	}
+	return nil
+}
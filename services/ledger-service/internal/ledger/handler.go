@@ -1,26 +1,50 @@
 package ledger
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/boleto"
 	"github.com/Guizzs26/fintrack/pkg/clock"
 	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/validatorx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// defaultNearRadiusMeters is how far from the near point the located transactions are searched without a radius
+	defaultNearRadiusMeters = 1000
+	maxNearRadiusMeters     = 100_000
+
+	// archiveHistoryPath is the path of the endpoint archiving the history of an account, relative to the API root
+	archiveHistoryPath = "/api/v1/accounts/%s/history/archive"
+	// headerArchiveBefore carries the date the history of an account holding too many transactions should be archived before
+	headerArchiveBefore = "X-Fintrack-Archive-Before"
+)
+
 // LedgerHandler holds dependencies for ledger-related HTTP handlers
 type LedgerHandler struct {
-	ledgerService *Service
-	clock         clock.Clock
+	ledgerService     *Service
+	periods           module.AccountingPeriods
+	archivedRetention time.Duration
+	clock             clock.Clock
 }
 
-// NewLedgerHandler creates a new instance of LedgerHandler
-func NewLedgerHandler(ledgerService *Service, clock clock.Clock) *LedgerHandler {
+// NewLedgerHandler creates a new instance of LedgerHandler, the month flow following the financial month of each user
+// archivedRetention is how long archived accounts are kept before being purged, zero keeping them forever
+func NewLedgerHandler(ledgerService *Service, periods module.AccountingPeriods, archivedRetention time.Duration, clock clock.Clock) *LedgerHandler {
 	return &LedgerHandler{
-		ledgerService: ledgerService,
-		clock:         clock,
+		ledgerService:     ledgerService,
+		periods:           periods,
+		archivedRetention: archivedRetention,
+		clock:             clock,
 	}
 }
 
@@ -32,27 +56,169 @@ func (h *LedgerHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
 	accountsGroup.POST("/:id/transactions", h.addTransactionHandler)
 	accountsGroup.PUT("/:id", h.updateAccountHandler)
 	accountsGroup.POST("/:id/balance-adjustment", h.accountBalanceAdjustmentHandler)
-	accountsGroup.DELETE("/:id", h.archiveAccountHandler)
+	accountsGroup.DELETE("/:id", h.deleteAccountHandler)
+	accountsGroup.POST("/:id/cancel-deletion", h.cancelAccountDeletionHandler)
+	accountsGroup.POST("/:id/archive", h.archiveAccountHandler)
+	accountsGroup.POST("/:id/history/archive", h.archiveHistoryHandler)
 	accountsGroup.POST("/:id/unarchive", h.unarchiveAccountHandler)
+	accountsGroup.POST("/:id/invoices/:period/pay", h.payStatementHandler)
+	accountsGroup.GET("/archived", h.findArchivedAccountsHandler)
 	accountsGroup.GET("/:id", h.findAccountByIDHandler)
+	accountsGroup.GET("/:id/balance-breakdown", h.balanceBreakdownHandler)
 	accountsGroup.GET("", h.findAccountsByUserIDHandler)
+	accountsGroup.POST("/:id/boleto-transactions", h.addBoletoTransactionHandler)
+	accountsGroup.PUT("/:id/transactions/:transactionId/tags", h.setTransactionTagsHandler)
+	accountsGroup.PUT("/:id/transactions/:transactionId/clearing", h.setTransactionClearingHandler)
+	accountsGroup.DELETE("/:id/transactions/:transactionId/clearing", h.removeTransactionClearingHandler)
+	accountsGroup.PUT("/:id/transactions/:transactionId/location", h.setTransactionLocationHandler)
+	accountsGroup.DELETE("/:id/transactions/:transactionId/location", h.removeTransactionLocationHandler)
+
+	apiRouteGroup.GET("/transactions/located", h.findLocatedTransactionsHandler)
+
+	apiRouteGroup.POST("/boletos/parse", h.parseBoletoHandler)
+	apiRouteGroup.POST("/undo", h.undoHandler)
 }
 
 // CreateAccountRequest defines the expected JSON body for creating a new account
 type CreateAccountRequest struct {
-	Name                    string `json:"name" validate:"required,min=1,max=100"`
-	IncludeInOverallBalance *bool  `json:"include_in_overall_balance,omitempty"`
+	Name                    string      `json:"name" validate:"required,min=1,max=100"`
+	Currency                string      `json:"currency,omitempty" validate:"omitempty,currency"`
+	Kind                    AccountKind `json:"kind,omitempty" validate:"omitempty,enum"` // Defaults to CHECKING when omitted
+	StatementClosingDay     *int        `json:"statement_closing_day,omitempty"`          // Required for credit cards, between 1 and 28
+	IncludeInOverallBalance *bool       `json:"include_in_overall_balance,omitempty"`
+}
+
+// ValidateStruct applies the CreateAccountRequest rules spanning several fields
+func (r CreateAccountRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	if r.Kind == CreditCard && r.StatementClosingDay == nil {
+		sl.ReportError("required_if", "kind=CREDIT_CARD", "statement_closing_day")
+	}
+	if r.Kind != CreditCard && r.StatementClosingDay != nil {
+		sl.ReportError("excluded_unless", "kind=CREDIT_CARD", "statement_closing_day")
+	}
 }
 
 // AddTransactionRequest defines the expected JSON body for creating a transaction for an account
+// Version 1 bodies send the amount as a number of minor units (e.g., 123456), see BindVersion
 type AddTransactionRequest struct {
-	Type        TransactionType `json:"type" validate:"required"`
+	Type        TransactionType `json:"type" validate:"required,enum"`
 	Description string          `json:"description" validate:"required,min=1,max=100"`
 	Observation string          `json:"observation,omitempty" validate:"max=2500"`
-	Amount      int64           `json:"amount" validate:"required,ne=0"`
-	DueDate     time.Time       `json:"due_date" validate:"required"`
+	Amount      string          `json:"amount" validate:"max=32"` // Decimal string in the account currency (e.g., "1.234,56" or "1234.56")
+	DueDate     *time.Time      `json:"due_date,omitempty"`       // Defaults to PaidAt when omitted
 	PaidAt      *time.Time      `json:"paid_at,omitempty"`
 	CategoryID  *uuid.UUID      `json:"category_id,omitempty"`
+
+	// OriginalAmount and OriginalCurrency describe a transaction made in another currency, Amount being what the account was charged
+	OriginalAmount   string `json:"original_amount,omitempty" validate:"max=32"`
+	OriginalCurrency string `json:"original_currency,omitempty" validate:"omitempty,currency"`
+
+	Pix      *PixRequest      `json:"pix,omitempty"`      // Pix holds the identifiers of a PIX transfer, for reconciling it with the bank
+	Clearing *ClearingRequest `json:"clearing,omitempty"` // Clearing is set for cheques and TEDs, whose funds become available after they are paid
+	Location *LocationRequest `json:"location,omitempty"` // Location is where the transaction was made, sent by the mobile app
+	Tags     []string         `json:"tags,omitempty" validate:"max=10,dive,max=30"`
+
+	amountMinorUnits *int64 // amountMinorUnits is the amount of a version 1 body
+}
+
+// addTransactionBody is the shape of AddTransactionRequest without its methods, decoded by BindVersion
+type addTransactionBody AddTransactionRequest
+
+// BindVersion decodes the version 1 bodies, whose amount is in minor units of the account currency
+func (r *AddTransactionRequest) BindVersion(_ int, decode func(any) error) error {
+	// Amount comes before the embedded body so it takes the "amount" key
+	var v1 struct {
+		Amount *int64 `json:"amount"`
+		addTransactionBody
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+	*r = AddTransactionRequest(v1.addTransactionBody)
+	r.amountMinorUnits = v1.Amount
+	return nil
+}
+
+// PixRequest defines the identifiers of a PIX transfer, at least one of them being required
+type PixRequest struct {
+	EndToEndID string `json:"end_to_end_id,omitempty" validate:"max=32"`
+	Key        string `json:"key,omitempty" validate:"max=77"` // Key is the PIX key of the payee of an expense or the payer of an income
+	QRPayload  string `json:"qr_payload,omitempty" validate:"max=512"`
+}
+
+// ClearingRequest defines when the funds of a cheque or a TED become available
+type ClearingRequest struct {
+	Method               ClearingMethod `json:"method" validate:"required,enum"`
+	ExpectedClearingDate *time.Time     `json:"expected_clearing_date" validate:"required"`
+}
+
+// LocationRequest defines where a transaction was made, in the coordinates reported by the device
+type LocationRequest struct {
+	Latitude  *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+	PlaceName string   `json:"place_name,omitempty" validate:"max=100"`
+}
+
+// ValidateStruct applies the AddTransactionRequest rules spanning several fields
+func (r AddTransactionRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	if r.amountMinorUnits == nil && r.Amount == "" {
+		sl.ReportError("required", "", "amount")
+	}
+	if r.OriginalAmount != "" && r.OriginalCurrency == "" {
+		sl.ReportError("required_with", "original_amount", "original_currency", "original_amount")
+	}
+	if r.OriginalCurrency != "" && r.OriginalAmount == "" {
+		sl.ReportError("required_with", "original_currency", "original_amount", "original_currency")
+	}
+	if r.DueDate == nil && r.PaidAt == nil {
+		sl.ReportError("required_without", "paid_at", "due_date", "paid_at")
+	}
+	if r.PaidAt != nil && r.PaidAt.After(sl.Now()) {
+		sl.ReportError("not_in_future", "", "paid_at")
+	}
+}
+
+// SetTransactionTagsRequest defines the expected JSON body for replacing the tags of a transaction
+type SetTransactionTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=10,dive,max=30"` // An empty list removes the tags of the transaction
+}
+
+// ParseBoletoRequest defines the expected JSON body for reading a boleto digitable line
+type ParseBoletoRequest struct {
+	DigitableLine string `json:"digitable_line" validate:"required,max=64"` // Digits of the line, dots and spaces being ignored
+}
+
+// AddBoletoTransactionRequest defines the expected JSON body for adding the unpaid expense of a boleto to an account
+// Version 1 bodies send the amount as a number of centavos (e.g., 123456), see BindVersion
+type AddBoletoTransactionRequest struct {
+	DigitableLine string     `json:"digitable_line" validate:"required,max=64"`
+	Description   string     `json:"description" validate:"required,min=1,max=100"`
+	Observation   string     `json:"observation,omitempty" validate:"max=2500"`
+	CategoryID    *uuid.UUID `json:"category_id,omitempty"`
+
+	// Amount and DueDate are required when the boleto leaves them out, and override the ones it carries otherwise
+	Amount  string     `json:"amount,omitempty" validate:"max=32"` // Positive decimal string in reais (e.g., "1.234,56")
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
+
+// addBoletoTransactionBody is the shape of AddBoletoTransactionRequest without its methods, decoded by BindVersion
+type addBoletoTransactionBody AddBoletoTransactionRequest
+
+// BindVersion decodes the version 1 bodies, whose amount is in centavos
+func (r *AddBoletoTransactionRequest) BindVersion(_ int, decode func(any) error) error {
+	// Amount comes before the embedded body so it takes the "amount" key
+	var v1 struct {
+		Amount *int64 `json:"amount,omitempty"`
+		addBoletoTransactionBody
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+	*r = AddBoletoTransactionRequest(v1.addBoletoTransactionBody)
+	if v1.Amount != nil {
+		r.Amount = money.New(*v1.Amount, boleto.Currency).Decimal()
+	}
+	return nil
 }
 
 // UpdateAccountRequest defines the expected JSON body for updating an account
@@ -61,9 +227,58 @@ type UpdateAccountRequest struct {
 	IncludeInOverallBalance *bool   `json:"include_in_overall_balance,omitempty"`
 }
 
+// ValidateStruct applies the UpdateAccountRequest rules spanning several fields
+func (r UpdateAccountRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	if r.Name == nil && r.IncludeInOverallBalance == nil {
+		sl.ReportError("required_any", "name, include_in_overall_balance", "name", "include_in_overall_balance")
+	}
+}
+
 // BalanceAdjustmentRequest defines the expected JSON body for adjust the account balance
+// Version 1 bodies send the balance as a number of minor units (e.g., 123456), see BindVersion
 type BalanceAdjustmentRequest struct {
-	NewBalance *int64 `json:"new_balance" validate:"required,gte=0"`
+	NewBalance string `json:"new_balance" validate:"max=32"` // Decimal string in the account currency (e.g., "1.234,56" or "1234.56")
+
+	newBalanceMinorUnits *int64 // newBalanceMinorUnits is the balance of a version 1 body
+}
+
+// BindVersion decodes the version 1 bodies, whose balance is in minor units of the account currency
+func (r *BalanceAdjustmentRequest) BindVersion(_ int, decode func(any) error) error {
+	var v1 struct {
+		NewBalance *int64 `json:"new_balance"`
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+	r.newBalanceMinorUnits = v1.NewBalance
+	return nil
+}
+
+// ValidateStruct applies the BalanceAdjustmentRequest rules spanning both versions of the body
+func (r BalanceAdjustmentRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	switch {
+	case r.newBalanceMinorUnits != nil:
+		if *r.newBalanceMinorUnits < 0 {
+			sl.ReportError("not_negative", "", "new_balance")
+		}
+	case r.NewBalance == "":
+		sl.ReportError("required", "", "new_balance")
+	case strings.HasPrefix(strings.TrimSpace(r.NewBalance), "-"):
+		sl.ReportError("not_negative", "", "new_balance")
+	}
+}
+
+// PayStatementRequest defines the expected JSON body for paying a credit card statement
+type PayStatementRequest struct {
+	PaymentAccountID uuid.UUID  `json:"payment_account_id" validate:"required"`
+	PaidAt           *time.Time `json:"paid_at,omitempty"` // Defaults to now when omitted
+}
+
+// ValidateStruct applies the PayStatementRequest rules spanning several fields
+func (r PayStatementRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	if r.PaidAt != nil && r.PaidAt.After(sl.Now()) {
+		sl.ReportError("not_in_future", "", "paid_at")
+	}
 }
 
 // TransactionResponse defines the structure of an transaction returned by the API
@@ -74,55 +289,189 @@ type TransactionResponse struct {
 	Amount      int64           `json:"amount"`
 	DueDate     time.Time       `json:"due_date"`
 	PaidAt      *time.Time      `json:"paid_at,omitempty"`
+
+	// Set for transactions made in another currency, OriginalAmount is in minor units of OriginalCurrency
+	OriginalAmount   *int64 `json:"original_amount,omitempty"`
+	OriginalCurrency string `json:"original_currency,omitempty"`
+	ExchangeRate     string `json:"exchange_rate,omitempty"`
+
+	Pix      *PixResponse      `json:"pix,omitempty"`
+	Clearing *ClearingResponse `json:"clearing,omitempty"`
+	Location *LocationResponse `json:"location,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+}
+
+// PixResponse defines the identifiers of a PIX transfer returned by the API
+type PixResponse struct {
+	EndToEndID string `json:"end_to_end_id,omitempty"`
+	Key        string `json:"key,omitempty"`
+	KeyType    string `json:"key_type,omitempty"`
+	QRPayload  string `json:"qr_payload,omitempty"`
+}
+
+// ClearingResponse defines when the funds of a cheque or a TED become available, returned by the API
+type ClearingResponse struct {
+	Method               ClearingMethod `json:"method"`
+	ExpectedClearingDate time.Time      `json:"expected_clearing_date"`
+}
+
+// LocationResponse defines where a transaction was made, returned by the API
+type LocationResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	PlaceName string  `json:"place_name,omitempty"`
+}
+
+// LocatedTransactionResponse defines a transaction made at a known place returned by the API, for the spending map
+type LocatedTransactionResponse struct {
+	AccountID      uuid.UUID           `json:"account_id"`
+	Transaction    TransactionResponse `json:"transaction"`
+	DistanceMeters *float64            `json:"distance_meters,omitempty"` // DistanceMeters is set when searching near a point
+}
+
+// UndoResponse defines the operation reversed by an undo, returned by the API
+type UndoResponse struct {
+	Operation  OperationKind `json:"operation"` // Operation is TRANSACTION_CREATED, ACCOUNT_DELETED or STATEMENT_PAID
+	AccountID  uuid.UUID     `json:"account_id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// BoletoResponse defines the structure of a parsed boleto returned by the API
+type BoletoResponse struct {
+	Kind     string     `json:"kind"` // Kind is BANK or COLLECTION (utility and tax bills)
+	Barcode  string     `json:"barcode"`
+	BankCode string     `json:"bank_code,omitempty"`
+	Amount   *int64     `json:"amount"` // Amount in minor units of Currency, null when the payer informs it
+	Currency string     `json:"currency"`
+	DueDate  *time.Time `json:"due_date"` // DueDate is null when the line does not carry it
 }
 
 // AccountResponse defines the structure of an account returned by the API
 type AccountResponse struct {
-	ID                      uuid.UUID `json:"id"`
-	UserID                  uuid.UUID `json:"user_id"`
-	Name                    string    `json:"name"`
-	IncludeInOverallBalance bool      `json:"include_in_overall_balance"`
+	ID                      uuid.UUID   `json:"id"`
+	UserID                  uuid.UUID   `json:"user_id"`
+	Name                    string      `json:"name"`
+	Currency                string      `json:"currency"`
+	Kind                    AccountKind `json:"kind"`
+	StatementClosingDay     int         `json:"statement_closing_day,omitempty"`
+	IncludeInOverallBalance bool        `json:"include_in_overall_balance"`
+	DeleteAfter             *time.Time  `json:"delete_after,omitempty"` // DeleteAfter is when an account the user deleted is deleted for good
 }
 
 // AccountDetailResponse defines the structure of an detailed account + transaction response returned by the API
 type AccountDetailResponse struct {
 	ID                      uuid.UUID             `json:"id"`
 	Name                    string                `json:"name"`
+	Currency                string                `json:"currency"`
+	Kind                    AccountKind           `json:"kind"`
+	StatementClosingDay     int                   `json:"statement_closing_day,omitempty"`
 	RealBalance             int64                 `json:"real_balance"`
+	PendingClearanceBalance int64                 `json:"pending_clearance_balance"` // Cheques and TEDs paid but not cleared yet, left out of the real balance
 	ProjectedBalance        int64                 `json:"projected_balance"`
 	IncludeInOverallBalance bool                  `json:"include_in_overall_balance"`
+	DeleteAfter             *time.Time            `json:"delete_after,omitempty"`
 	Transactions            []TransactionResponse `json:"transactions"`
+
+	// ProjectedBalanceAsOf is set when the as_of query parameter asks for the projected balance on a date
+	ProjectedBalanceAsOf *ProjectedBalanceAsOfResponse `json:"projected_balance_as_of,omitempty"`
+
+	// ArchivalSuggestion is set when the account holds too many transactions, suggesting to archive its oldest history
+	ArchivalSuggestion *ArchivalSuggestionResponse `json:"archival_suggestion,omitempty"`
+}
+
+// ProjectedBalanceAsOfResponse defines the projected balance of an account on a date, counting only the transactions due on or before it
+type ProjectedBalanceAsOfResponse struct {
+	AsOf    string `json:"as_of"`
+	Balance int64  `json:"balance"`
+}
+
+// ArchivalSuggestionResponse defines the archival suggested for an account holding too many transactions
+type ArchivalSuggestionResponse struct {
+	ActiveTransactions    int       `json:"active_transactions"`
+	MaxActiveTransactions int       `json:"max_active_transactions"`
+	ArchiveBefore         time.Time `json:"archive_before"`
+	ArchiveURL            string    `json:"archive_url"` // ArchiveURL is the endpoint archiving the history, given ArchiveBefore
+}
+
+// ArchiveHistoryRequest defines the expected JSON body for archiving the history of an account
+type ArchiveHistoryRequest struct {
+	Before time.Time `json:"before" validate:"required"` // The settled transactions due before it are archived
+}
+
+// ArchiveHistoryResponse defines the outcome of archiving the history of an account returned by the API
+type ArchiveHistoryResponse struct {
+	ArchivedTransactions int                   `json:"archived_transactions"`
+	Account              AccountDetailResponse `json:"account"`
+}
+
+// BalanceTotalsResponse defines the amounts of transactions by type, in minor units of the account currency
+type BalanceTotalsResponse struct {
+	Income      int64 `json:"income"`
+	Expenses    int64 `json:"expenses"` // Expenses are negative
+	Adjustments int64 `json:"adjustments"`
+	Total       int64 `json:"total"`
+}
+
+// BalanceBreakdownResponse defines how the balances of an account are made up, returned by the API
+// real_balance is the total of paid, projected_balance adds pending_clearance_balance and the total of unpaid
+type BalanceBreakdownResponse struct {
+	AccountID               uuid.UUID             `json:"account_id"`
+	Currency                string                `json:"currency"`
+	RealBalance             int64                 `json:"real_balance"`
+	Paid                    BalanceTotalsResponse `json:"paid"`
+	PendingClearanceBalance int64                 `json:"pending_clearance_balance"`
+	Unpaid                  BalanceTotalsResponse `json:"unpaid"` // Unpaid includes the transactions whose payment date is still ahead
+	ProjectedBalance        int64                 `json:"projected_balance"`
 }
 
 // AccountSummaryResponse defines a summary view of an account for list endpoints
 type AccountSummaryResponse struct {
-	ID               uuid.UUID `json:"id"`
-	Name             string    `json:"name"`
-	RealBalance      int64     `json:"real_balance"`
-	ProjectedBalance int64     `json:"projected_balance"`
+	ID                      uuid.UUID   `json:"id"`
+	Name                    string      `json:"name"`
+	Currency                string      `json:"currency"`
+	Kind                    AccountKind `json:"kind"`
+	RealBalance             int64       `json:"real_balance"`
+	PendingClearanceBalance int64       `json:"pending_clearance_balance"`
+	ProjectedBalance        int64       `json:"projected_balance"`
+	DeleteAfter             *time.Time  `json:"delete_after,omitempty"`
+}
+
+// ArchivedAccountResponse defines an archived account, with what the user needs to choose between restoring it or letting it be purged
+type ArchivedAccountResponse struct {
+	ID               uuid.UUID   `json:"id"`
+	Name             string      `json:"name"`
+	Currency         string      `json:"currency"`
+	Kind             AccountKind `json:"kind"`
+	TransactionCount int         `json:"transaction_count"`
+	ArchivedAt       time.Time   `json:"archived_at"`
+	PurgeAt          *time.Time  `json:"purge_at,omitempty"` // PurgeAt is omitted when archived accounts are kept forever
 }
 
-// CurrentMonthFlowSummary details the income, expenses and net (balance) result of the current month
+// CurrentMonthFlowSummary details the income, expenses and net (balance) result of the current financial month
 type CurrentMonthFlowSummary struct {
-	Income  int64 `json:"income"`
-	Expense int64 `json:"expense"`
-	NetFlow int64 `json:"net_flow"` // income - expense
+	From    string `json:"from"` // From and To are the days of the financial month, formatted as YYYY-MM-DD, both included
+	To      string `json:"to"`
+	Income  int64  `json:"income"`
+	Expense int64  `json:"expense"`
+	NetFlow int64  `json:"net_flow"` // income - expense
 }
 
 // AccountListResponse is the DTO for the response listing all the user's accounts
-// Includes the list of accounts and the calculated overall balances
+// Includes the list of accounts and the calculated overall balances (expressed in Currency)
 type AccountListResponse struct {
-	OverallRealBalance      int64                    `json:"overall_real_balance"`
-	OverallProjectedBalance int64                    `json:"overall_projected_balance"`
-	CurrentMonthFlow        CurrentMonthFlowSummary  `json:"current_month_flow"`
-	Accounts                []AccountSummaryResponse `json:"accounts"`
+	Currency                       string                   `json:"currency"`
+	OverallRealBalance             int64                    `json:"overall_real_balance"`
+	OverallPendingClearanceBalance int64                    `json:"overall_pending_clearance_balance"`
+	OverallProjectedBalance        int64                    `json:"overall_projected_balance"`
+	CurrentMonthFlow               CurrentMonthFlowSummary  `json:"current_month_flow"`
+	Accounts                       []AccountSummaryResponse `json:"accounts"`
 }
 
 // createAccountHandler handles the HTTP request for creating a new account
 func (h *LedgerHandler) createAccountHandler(c echo.Context) error {
 	var req CreateAccountRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body format")
+		return err
 	}
 
 	if err := c.Validate(&req); err != nil {
@@ -134,8 +483,26 @@ func (h *LedgerHandler) createAccountHandler(c echo.Context) error {
 		includeInBalance = *req.IncludeInOverallBalance
 	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	account, err := h.ledgerService.CreateAccount(c.Request().Context(), mockUserID, req.Name, includeInBalance)
+	currency := DefaultCurrency
+	if req.Currency != "" {
+		currency = req.Currency
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	var account *Account
+	switch req.Kind {
+	case CreditCard:
+		account, err = h.ledgerService.CreateCreditCardAccount(c.Request().Context(), userID, req.Name, currency, *req.StatementClosingDay, includeInBalance)
+	case Investment:
+		account, err = h.ledgerService.CreateInvestmentAccount(c.Request().Context(), userID, req.Name, currency, includeInBalance)
+	case Savings:
+		account, err = h.ledgerService.CreateSavingsAccount(c.Request().Context(), userID, req.Name, currency, includeInBalance)
+	default:
+		account, err = h.ledgerService.CreateAccount(c.Request().Context(), userID, req.Name, currency, includeInBalance)
+	}
 	if err != nil {
 		return err
 	}
@@ -152,246 +519,985 @@ func (h *LedgerHandler) addTransactionHandler(c echo.Context) error {
 
 	var req AddTransactionRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body format")
+		return err
 	}
 	if err := c.Validate(&req); err != nil {
 		return err
 	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
+	dueDate := req.PaidAt
+	if req.DueDate != nil {
+		dueDate = req.DueDate
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 	params := AddTransactionParams{
 		AccountID:   accountID,
-		UserID:      mockUserID, // In the future, this will come from JWT/middleware
+		UserID:      userID,
 		Type:        req.Type,
 		Description: req.Description,
 		Observation: req.Observation,
 		Amount:      req.Amount,
-		DueDate:     req.DueDate,
+		DueDate:     *dueDate,
 		PaidAt:      req.PaidAt,
 		CategoryID:  req.CategoryID,
+
+		OriginalAmount:   req.OriginalAmount,
+		OriginalCurrency: req.OriginalCurrency,
+
+		Tags: req.Tags,
+	}
+	if req.amountMinorUnits != nil {
+		params.Amount, params.AmountMinorUnits = "", *req.amountMinorUnits
+	}
+	if req.Pix != nil {
+		params.Pix = &PixDetails{
+			EndToEndID: req.Pix.EndToEndID,
+			Key:        req.Pix.Key,
+			QRPayload:  req.Pix.QRPayload,
+		}
+	}
+	if req.Clearing != nil {
+		params.Clearing = &Clearing{Method: req.Clearing.Method, ExpectedClearingDate: *req.Clearing.ExpectedClearingDate}
+	}
+	if req.Location != nil {
+		params.Location = toLocation(*req.Location)
 	}
 
-	if err := h.ledgerService.AddTransactionToAccount(c.Request().Context(), params); err != nil {
+	suggestion, err := h.ledgerService.AddTransactionToAccount(c.Request().Context(), params)
+	if err != nil {
 		return err
 	}
+	// The transaction is added anyway, the client being pointed to the archival of the oldest history
+	if suggestion != nil {
+		header := c.Response().Header()
+		header.Add(httpx.HeaderLink, fmt.Sprintf("<%s>; rel=\"archive-history\"", fmt.Sprintf(archiveHistoryPath, accountID)))
+		header.Set(headerArchiveBefore, suggestion.Before.Format(time.DateOnly))
+	}
 
 	// For a POST that creates a sub-resource, 204 No Content is a valid and efficient response
 	return httpx.SendSuccess(c, http.StatusNoContent, nil)
 }
 
-// updateAccountHandler handles HTTP request for update a existing account
-func (h *LedgerHandler) updateAccountHandler(c echo.Context) error {
+// setTransactionTagsHandler handles the HTTP request for replacing the tags of a transaction
+func (h *LedgerHandler) setTransactionTagsHandler(c echo.Context) error {
 	accountID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
 	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
 
-	var req UpdateAccountRequest
+	var req SetTransactionTagsRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body format")
+		return err
 	}
 	if err := c.Validate(&req); err != nil {
 		return err
 	}
-	if req.Name == nil && req.IncludeInOverallBalance == nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "at least one field must be provided for update")
-	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	params := UpdateAccountParams{
-		AccountID:               accountID,
-		UserID:                  mockUserID,
-		Name:                    req.Name,
-		IncludeInOverallBalance: req.IncludeInOverallBalance,
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SetTransactionTagsParams{
+		AccountID:     accountID,
+		UserID:        userID,
+		TransactionID: transactionID,
+		Tags:          req.Tags,
 	}
 
-	account, err := h.ledgerService.UpdateAccount(c.Request().Context(), params)
+	tx, err := h.ledgerService.SetTransactionTags(c.Request().Context(), params)
 	if err != nil {
 		return err
 	}
 
-	return httpx.SendSuccess(c, http.StatusOK, toAccountResponse(account))
+	return httpx.SendSuccess(c, http.StatusOK, toTransactionResponse(tx))
 }
 
-// archiveAccountHandler handles HTTP request for archive a existing account
-func (h *LedgerHandler) archiveAccountHandler(c echo.Context) error {
+// setTransactionClearingHandler handles the HTTP request for setting when the funds of a cheque or a TED become available
+func (h *LedgerHandler) setTransactionClearingHandler(c echo.Context) error {
 	accountID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid account ID format")
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
 	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	if err := h.ledgerService.ArchiveAccount(c.Request().Context(), mockUserID, accountID); err != nil {
+	var req ClearingRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
 		return err
 	}
 
-	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SetTransactionClearingParams{
+		AccountID:     accountID,
+		UserID:        userID,
+		TransactionID: transactionID,
+		Clearing:      &Clearing{Method: req.Method, ExpectedClearingDate: *req.ExpectedClearingDate},
+	}
+
+	tx, err := h.ledgerService.SetTransactionClearing(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toTransactionResponse(tx))
 }
 
-// unarchiveAccountHandler handles HTTP request for unarchived a archived account
-func (h *LedgerHandler) unarchiveAccountHandler(c echo.Context) error {
+// removeTransactionClearingHandler handles the HTTP request for removing the expected clearing of a transaction
+func (h *LedgerHandler) removeTransactionClearingHandler(c echo.Context) error {
 	accountID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid account ID format")
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SetTransactionClearingParams{
+		AccountID:     accountID,
+		UserID:        userID,
+		TransactionID: transactionID,
 	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	account, err := h.ledgerService.UnarchiveAccount(c.Request().Context(), mockUserID, accountID)
+	tx, err := h.ledgerService.SetTransactionClearing(c.Request().Context(), params)
 	if err != nil {
 		return err
 	}
 
-	return httpx.SendSuccess(c, http.StatusOK, toAccountResponse(account))
+	return httpx.SendSuccess(c, http.StatusOK, toTransactionResponse(tx))
 }
 
-// accountBalanceAdjustmentHandler handles HTTP request for adjust the balance of an existing account
-func (h *LedgerHandler) accountBalanceAdjustmentHandler(c echo.Context) error {
+// undoHandler handles the HTTP request for reversing the last ledger mutation of the user
+func (h *LedgerHandler) undoHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	operation, err := h.ledgerService.Undo(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, UndoResponse{
+		Operation:  operation.Kind,
+		AccountID:  operation.AccountID,
+		OccurredAt: operation.OccurredAt,
+	})
+}
+
+// setTransactionLocationHandler handles the HTTP request for setting where a transaction was made
+func (h *LedgerHandler) setTransactionLocationHandler(c echo.Context) error {
 	accountID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid account ID format")
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
 	}
 
-	var req BalanceAdjustmentRequest
+	var req LocationRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body format")
+		return err
 	}
-
 	if err := c.Validate(&req); err != nil {
 		return err
 	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	params := BalanceAdjustmentParams{
-		AccountID:  accountID,
-		UserID:     mockUserID,
-		NewBalance: *req.NewBalance,
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := SetTransactionLocationParams{
+		AccountID:     accountID,
+		UserID:        userID,
+		TransactionID: transactionID,
+		Location:      toLocation(req),
 	}
 
-	account, err := h.ledgerService.AdjustAccountBalance(c.Request().Context(), params)
+	tx, err := h.ledgerService.SetTransactionLocation(c.Request().Context(), params)
 	if err != nil {
 		return err
 	}
 
-	return httpx.SendSuccess(c, http.StatusOK, toAccountDetailResponse(account, h.clock))
+	return httpx.SendSuccess(c, http.StatusOK, toTransactionResponse(tx))
 }
 
-// findAccountByID handles the HTTP request for finding a account by id
-func (h *LedgerHandler) findAccountByIDHandler(c echo.Context) error {
+// removeTransactionLocationHandler handles the HTTP request for removing the location of a transaction
+func (h *LedgerHandler) removeTransactionLocationHandler(c echo.Context) error {
 	accountID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
 	}
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transaction id format")
+	}
 
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	account, err := h.ledgerService.FindAccountByID(c.Request().Context(), mockUserID, accountID)
+	userID, err := currentUserID(c)
 	if err != nil {
 		return err
 	}
+	params := SetTransactionLocationParams{
+		AccountID:     accountID,
+		UserID:        userID,
+		TransactionID: transactionID,
+	}
 
-	return httpx.SendSuccess(c, http.StatusOK, toAccountDetailResponse(account, h.clock))
-}
-
-// findAccountsByUserIDHandler handles the HTTP request for finding the account(s) by the user id
-func (h *LedgerHandler) findAccountsByUserIDHandler(c echo.Context) error {
-	mockUserID, _ := uuid.Parse("7e57d19c-5953-433c-9b57-d3d8e1f3b8b8")
-	accounts, err := h.ledgerService.FindAccountsByUserID(c.Request().Context(), mockUserID)
+	tx, err := h.ledgerService.SetTransactionLocation(c.Request().Context(), params)
 	if err != nil {
 		return err
 	}
 
-	return httpx.SendSuccess(c, http.StatusOK, toAccountListResponse(accounts, h.clock))
+	return httpx.SendSuccess(c, http.StatusOK, toTransactionResponse(tx))
 }
 
-// toAccountResponse maps the internal Account domain model to the public AccountResponse DTO
-func toAccountResponse(a *Account) AccountResponse {
-	return AccountResponse{
-		ID:                      a.ID,
-		UserID:                  a.UserID,
-		Name:                    a.Name,
-		IncludeInOverallBalance: a.IncludeInOverallBalance,
+// findLocatedTransactionsHandler handles the HTTP request for listing the user's transactions made at a known place,
+// optionally within a radius in meters of a point (e.g., ?near=-23.5505,-46.6333&radius=2000)
+func (h *LedgerHandler) findLocatedTransactionsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
 	}
-}
 
-// toAccountDetailResponse maps the internal Account domain model to the public AccountDetailResponse DTO
-func toAccountDetailResponse(a *Account, clock clock.Clock) AccountDetailResponse {
-	txs := a.Transactions()
-	txResponses := make([]TransactionResponse, len(txs))
-	for i, tx := range txs {
-		txResponses[i] = TransactionResponse{
-			ID:          tx.ID,
-			Type:        tx.Type,
-			Description: tx.Description,
-			Amount:      tx.Amount,
-			DueDate:     tx.DueDate,
-			PaidAt:      tx.PaidAt,
+	params := FindLocatedTransactionsParams{UserID: userID, RadiusMeters: defaultNearRadiusMeters}
+	if raw := c.QueryParam("near"); raw != "" {
+		latitude, longitude, ok := strings.Cut(raw, ",")
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+		if !ok || latErr != nil || lngErr != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid near format, expected latitude,longitude")
 		}
+		params.Near = &Location{Latitude: lat, Longitude: lng}
+	}
+	if raw := c.QueryParam("radius"); raw != "" {
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil || radius <= 0 || radius > maxNearRadiusMeters {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid radius, expected meters between 1 and 100000")
+		}
+		params.RadiusMeters = radius
 	}
 
-	return AccountDetailResponse{
-		ID:                      a.ID,
-		Name:                    a.Name,
-		RealBalance:             a.RealBalance(clock),
-		ProjectedBalance:        a.ProjectedBalance(),
-		IncludeInOverallBalance: a.IncludeInOverallBalance,
-		Transactions:            txResponses,
+	located, err := h.ledgerService.FindLocatedTransactions(c.Request().Context(), params)
+	if err != nil {
+		return err
 	}
-}
 
-// toAccountListResponse maps a slice of Accounts from the domain to the public DTO AccountListResponse
-// It is responsible for calculating the overall balances and cash flow for the *current month*
-func toAccountListResponse(accounts []*Account, clock clock.Clock) AccountListResponse {
-	now := clock.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
-
-	var overallRealBalance int64 = 0
-	var overallProjectedBalance int64 = 0
-	var currentMonthIncome int64 = 0
-	var currentMonthExpense int64 = 0
-	accountSummaries := make([]AccountSummaryResponse, len(accounts))
+	resp := make([]LocatedTransactionResponse, len(located))
+	for i, item := range located {
+		resp[i] = LocatedTransactionResponse{
+			AccountID:      item.AccountID,
+			Transaction:    toTransactionResponse(item.Transaction),
+			DistanceMeters: item.DistanceMeters,
+		}
+	}
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
 
-	for i, acc := range accounts {
-		realBalance := acc.RealBalance(clock)
-		projectedBalance := acc.ProjectedBalance()
+// parseBoletoHandler handles the HTTP request for reading the amount and due date of a boleto digitable line
+func (h *LedgerHandler) parseBoletoHandler(c echo.Context) error {
+	var req ParseBoletoRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
 
-		// A .presentation business logic: overall balance calculation (regardless of the period)
-		if acc.IncludeInOverallBalance {
-			overallRealBalance += realBalance
-			overallProjectedBalance += projectedBalance
-		}
+	parsed, err := h.ledgerService.ParseBoleto(req.DigitableLine)
+	if err != nil {
+		return err
+	}
 
-		// calculate the current month's flow
-		if acc.IncludeInOverallBalance {
-			for _, tx := range acc.Transactions() {
-				// The transaction only enters the monthly flow if:
-				// 1. It was paid/completed (PaidAt is not null)
-				// 2. The payment date is within the current month's range
-				if tx.PaidAt != nil && !tx.PaidAt.Before(startOfMonth) && tx.PaidAt.Before(startOfNextMonth) {
-					switch tx.Type {
-					case Income, Adjustment:
-						currentMonthIncome += tx.Amount
-					case Expense:
-						currentMonthExpense += tx.Amount
-					}
-				}
-			}
-		}
+	return httpx.SendSuccess(c, http.StatusOK, toBoletoResponse(parsed))
+}
 
-		accountSummaries[i] = AccountSummaryResponse{
-			ID:               acc.ID,
-			Name:             acc.Name,
-			RealBalance:      realBalance,
-			ProjectedBalance: projectedBalance,
-		}
+// addBoletoTransactionHandler handles the HTTP request for adding the unpaid expense of a boleto to an account
+func (h *LedgerHandler) addBoletoTransactionHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
 	}
 
-	return AccountListResponse{
-		OverallRealBalance:      overallRealBalance,
-		OverallProjectedBalance: overallProjectedBalance,
-		CurrentMonthFlow: CurrentMonthFlowSummary{
-			Income:  currentMonthIncome,
-			Expense: currentMonthExpense,
-			NetFlow: currentMonthIncome + currentMonthExpense},
-		Accounts: accountSummaries,
+	var req AddBoletoTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := AddBoletoTransactionParams{
+		AccountID:     accountID,
+		UserID:        userID,
+		CategoryID:    req.CategoryID,
+		Description:   req.Description,
+		Observation:   req.Observation,
+		DigitableLine: req.DigitableLine,
+		Amount:        req.Amount,
+		DueDate:       req.DueDate,
+	}
+
+	tx, err := h.ledgerService.AddBoletoTransaction(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toTransactionResponse(tx))
+}
+
+// payStatementHandler handles the HTTP request for paying a credit card statement, identified by its closing month (YYYY-MM)
+func (h *LedgerHandler) payStatementHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+	period, err := time.Parse("2006-01", c.Param("period"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid statement period format, expected YYYY-MM")
+	}
+
+	var req PayStatementRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	paidAt := h.clock.Now()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := PayStatementParams{
+		CardAccountID:    accountID,
+		PaymentAccountID: req.PaymentAccountID,
+		UserID:           userID,
+		Year:             period.Year(),
+		Month:            period.Month(),
+		PaidAt:           paidAt,
+	}
+
+	card, err := h.ledgerService.PayCreditCardStatement(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := toAccountDetailResponse(card, h.clock)
+	if err != nil {
+		return err
+	}
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// updateAccountHandler handles HTTP request for update a existing account
+func (h *LedgerHandler) updateAccountHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req UpdateAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := UpdateAccountParams{
+		AccountID:               accountID,
+		UserID:                  userID,
+		Name:                    req.Name,
+		IncludeInOverallBalance: req.IncludeInOverallBalance,
+	}
+
+	account, err := h.ledgerService.UpdateAccount(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toAccountResponse(account))
+}
+
+// deleteAccountHandler handles HTTP request for delete an account, which is deleted for good once the grace period elapsed
+func (h *LedgerHandler) deleteAccountHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account ID format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	account, err := h.ledgerService.ScheduleAccountDeletion(c.Request().Context(), userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusAccepted, toAccountResponse(account))
+}
+
+// cancelAccountDeletionHandler handles HTTP request for keep an account whose deletion grace period did not elapse yet
+func (h *LedgerHandler) cancelAccountDeletionHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account ID format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	account, err := h.ledgerService.CancelAccountDeletion(c.Request().Context(), userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toAccountResponse(account))
+}
+
+// archiveAccountHandler handles HTTP request for archive a existing account
+func (h *LedgerHandler) archiveAccountHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account ID format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.ledgerService.ArchiveAccount(c.Request().Context(), userID, accountID); err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusNoContent, nil)
+}
+
+// unarchiveAccountHandler handles HTTP request for unarchived a archived account
+func (h *LedgerHandler) unarchiveAccountHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid account ID format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	account, err := h.ledgerService.UnarchiveAccount(c.Request().Context(), userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toAccountResponse(account))
+}
+
+// accountBalanceAdjustmentHandler handles HTTP request for adjust the balance of an existing account
+func (h *LedgerHandler) accountBalanceAdjustmentHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account ID format")
+	}
+
+	var req BalanceAdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := BalanceAdjustmentParams{
+		AccountID:         accountID,
+		UserID:            userID,
+		NewBalanceDecimal: req.NewBalance,
+	}
+	if req.newBalanceMinorUnits != nil {
+		params.NewBalance, params.NewBalanceDecimal = *req.newBalanceMinorUnits, ""
+	}
+
+	account, err := h.ledgerService.AdjustAccountBalance(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := toAccountDetailResponse(account, h.clock)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// findAccountByID handles the HTTP request for finding a account by id
+func (h *LedgerHandler) findAccountByIDHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	asOf, err := dateQueryParam(c, "as_of")
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	account, err := h.ledgerService.FindAccountByID(c.Request().Context(), userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := toAccountDetailResponse(account, h.clock)
+	if err != nil {
+		return err
+	}
+	if !asOf.IsZero() {
+		balance, err := account.ProjectedBalanceAsOf(asOf)
+		if err != nil {
+			return err
+		}
+		resp.ProjectedBalanceAsOf = &ProjectedBalanceAsOfResponse{AsOf: asOf.Format(time.DateOnly), Balance: balance.Amount}
+	}
+	resp.ArchivalSuggestion = toArchivalSuggestionResponse(account.ID, h.ledgerService.SuggestArchival(account))
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// archiveHistoryHandler handles the HTTP request for rolling the settled history of an account into an opening balance
+func (h *LedgerHandler) archiveHistoryHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req ArchiveHistoryRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	account, archived, err := h.ledgerService.ArchiveHistory(c.Request().Context(), userID, accountID, req.Before)
+	if err != nil {
+		return err
+	}
+
+	resp, err := toAccountDetailResponse(account, h.clock)
+	if err != nil {
+		return err
+	}
+	resp.ArchivalSuggestion = toArchivalSuggestionResponse(account.ID, h.ledgerService.SuggestArchival(account))
+
+	return httpx.SendSuccess(c, http.StatusOK, ArchiveHistoryResponse{ArchivedTransactions: archived, Account: resp})
+}
+
+// balanceBreakdownHandler handles the HTTP request for explaining how the balances of an account are made up
+func (h *LedgerHandler) balanceBreakdownHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	account, err := h.ledgerService.FindAccountByID(c.Request().Context(), userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	breakdown, err := account.BalanceBreakdown(h.clock)
+	if err != nil {
+		return err
+	}
+	resp, err := toBalanceBreakdownResponse(account, breakdown)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// findAccountsByUserIDHandler handles the HTTP request for finding the account(s) by the user id
+func (h *LedgerHandler) findAccountsByUserIDHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	accounts, err := h.ledgerService.FindAccountsByUserID(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+	period, err := h.periods.AccountingPeriod(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := toAccountListResponse(accounts, period, h.clock)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// findArchivedAccountsHandler handles the HTTP request for listing the archived accounts of the user
+func (h *LedgerHandler) findArchivedAccountsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	accounts, err := h.ledgerService.FindArchivedAccountsByUserID(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]ArchivedAccountResponse, len(accounts))
+	for i, acc := range accounts {
+		resp[i] = toArchivedAccountResponse(acc, h.archivedRetention)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// dateQueryParam parses an optional YYYY-MM-DD query parameter, returning the zero time when it is absent
+func dateQueryParam(c echo.Context, name string) (time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+" date format, expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// currentUserID extracts the authenticated user ID placed in the request context by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toAccountResponse maps the internal Account domain model to the public AccountResponse DTO
+func toAccountResponse(a *Account) AccountResponse {
+	return AccountResponse{
+		ID:                      a.ID,
+		UserID:                  a.UserID,
+		Name:                    a.Name,
+		Currency:                a.Currency,
+		Kind:                    a.Kind,
+		StatementClosingDay:     a.StatementClosingDay,
+		IncludeInOverallBalance: a.IncludeInOverallBalance,
+		DeleteAfter:             a.DeleteAfter,
+	}
+}
+
+// toArchivedAccountResponse maps an archived Account to the public ArchivedAccountResponse DTO
+func toArchivedAccountResponse(a *Account, retention time.Duration) ArchivedAccountResponse {
+	return ArchivedAccountResponse{
+		ID:               a.ID,
+		Name:             a.Name,
+		Currency:         a.Currency,
+		Kind:             a.Kind,
+		TransactionCount: len(a.transactions),
+		ArchivedAt:       *a.ArchivedAt,
+		PurgeAt:          a.PurgeAt(retention),
+	}
+}
+
+// toLocation maps a validated LocationRequest DTO to the domain Location
+func toLocation(req LocationRequest) *Location {
+	return &Location{Latitude: *req.Latitude, Longitude: *req.Longitude, PlaceName: req.PlaceName}
+}
+
+// toTransactionResponse maps a domain Transaction to the public TransactionResponse DTO
+func toTransactionResponse(tx Transaction) TransactionResponse {
+	resp := TransactionResponse{
+		ID:          tx.ID,
+		Type:        tx.Type,
+		Description: tx.Description,
+		Amount:      tx.Amount.Amount,
+		DueDate:     tx.DueDate,
+		PaidAt:      tx.PaidAt,
+		Tags:        tx.Tags,
+	}
+	if tx.Original != nil {
+		resp.OriginalAmount = &tx.Original.Amount.Amount
+		resp.OriginalCurrency = tx.Original.Amount.Currency
+		resp.ExchangeRate = tx.Original.Rate.String()
+	}
+	if tx.Pix != nil {
+		resp.Pix = &PixResponse{
+			EndToEndID: tx.Pix.EndToEndID,
+			Key:        tx.Pix.Key,
+			KeyType:    string(tx.Pix.KeyType),
+			QRPayload:  tx.Pix.QRPayload,
+		}
+	}
+	if tx.Clearing != nil {
+		resp.Clearing = &ClearingResponse{
+			Method:               tx.Clearing.Method,
+			ExpectedClearingDate: tx.Clearing.ExpectedClearingDate,
+		}
+	}
+	if tx.Location != nil {
+		resp.Location = &LocationResponse{
+			Latitude:  tx.Location.Latitude,
+			Longitude: tx.Location.Longitude,
+			PlaceName: tx.Location.PlaceName,
+		}
+	}
+	return resp
+}
+
+// toBoletoResponse maps a parsed boleto to the public BoletoResponse DTO
+func toBoletoResponse(b *boleto.Boleto) BoletoResponse {
+	resp := BoletoResponse{
+		Kind:     string(b.Kind),
+		Barcode:  b.Barcode,
+		BankCode: b.BankCode,
+		Currency: boleto.Currency,
+		DueDate:  b.DueDate,
+	}
+	if b.Amount > 0 {
+		resp.Amount = &b.Amount
+	}
+	return resp
+}
+
+// toAccountDetailResponse maps the internal Account domain model to the public AccountDetailResponse DTO
+// Amounts are exposed in minor units of the account currency
+func toAccountDetailResponse(a *Account, clock clock.Clock) (AccountDetailResponse, error) {
+	txs := a.Transactions()
+	txResponses := make([]TransactionResponse, len(txs))
+	for i, tx := range txs {
+		txResponses[i] = toTransactionResponse(tx)
+	}
+
+	realBalance, err := a.RealBalance(clock)
+	if err != nil {
+		return AccountDetailResponse{}, err
+	}
+	pendingClearance, err := a.PendingClearanceBalance(clock)
+	if err != nil {
+		return AccountDetailResponse{}, err
+	}
+	projectedBalance, err := a.ProjectedBalance()
+	if err != nil {
+		return AccountDetailResponse{}, err
+	}
+
+	return AccountDetailResponse{
+		ID:                      a.ID,
+		Name:                    a.Name,
+		Currency:                a.Currency,
+		Kind:                    a.Kind,
+		StatementClosingDay:     a.StatementClosingDay,
+		RealBalance:             realBalance.Amount,
+		PendingClearanceBalance: pendingClearance.Amount,
+		ProjectedBalance:        projectedBalance.Amount,
+		IncludeInOverallBalance: a.IncludeInOverallBalance,
+		DeleteAfter:             a.DeleteAfter,
+		Transactions:            txResponses,
+	}, nil
+}
+
+// toArchivalSuggestionResponse maps an ArchivalSuggestion to the public ArchivalSuggestionResponse DTO, nil when there is none
+func toArchivalSuggestionResponse(accountID uuid.UUID, s *ArchivalSuggestion) *ArchivalSuggestionResponse {
+	if s == nil {
+		return nil
+	}
+	return &ArchivalSuggestionResponse{
+		ActiveTransactions:    s.ActiveTransactions,
+		MaxActiveTransactions: s.MaxActiveTransactions,
+		ArchiveBefore:         s.Before,
+		ArchiveURL:            fmt.Sprintf(archiveHistoryPath, accountID),
+	}
+}
+
+// toBalanceBreakdownResponse maps the BalanceBreakdown of an account to the public BalanceBreakdownResponse DTO
+func toBalanceBreakdownResponse(a *Account, b BalanceBreakdown) (BalanceBreakdownResponse, error) {
+	paid, err := toBalanceTotalsResponse(b.Paid)
+	if err != nil {
+		return BalanceBreakdownResponse{}, err
+	}
+	unpaid, err := toBalanceTotalsResponse(b.Unpaid)
+	if err != nil {
+		return BalanceBreakdownResponse{}, err
+	}
+
+	return BalanceBreakdownResponse{
+		AccountID:               a.ID,
+		Currency:                a.Currency,
+		RealBalance:             b.Real.Amount,
+		Paid:                    paid,
+		PendingClearanceBalance: b.PendingClearance.Amount,
+		Unpaid:                  unpaid,
+		ProjectedBalance:        b.Projected.Amount,
+	}, nil
+}
+
+// toBalanceTotalsResponse maps BalanceTotals to the public BalanceTotalsResponse DTO
+func toBalanceTotalsResponse(t BalanceTotals) (BalanceTotalsResponse, error) {
+	total, err := t.Total()
+	if err != nil {
+		return BalanceTotalsResponse{}, err
+	}
+	return BalanceTotalsResponse{
+		Income:      t.Income.Amount,
+		Expenses:    t.Expenses.Amount,
+		Adjustments: t.Adjustments.Amount,
+		Total:       total.Amount,
+	}, nil
+}
+
+// toAccountListResponse maps a slice of Accounts from the domain to the public DTO AccountListResponse
+// It is responsible for calculating the overall balances and cash flow for the *current financial month* of the user
+// Overall figures are expressed in DefaultCurrency, so accounts held in another currency are left out of them
+func toAccountListResponse(accounts []*Account, period clock.AccountingPeriod, clk clock.Clock) (AccountListResponse, error) {
+	now := clk.Now()
+	startOfMonth, startOfNextMonth := period.RangeIn(now, now.Location())
+
+	overallRealBalance := money.Zero(DefaultCurrency)
+	overallPendingClearance := money.Zero(DefaultCurrency)
+	overallProjectedBalance := money.Zero(DefaultCurrency)
+	currentMonthIncome := money.Zero(DefaultCurrency)
+	currentMonthExpense := money.Zero(DefaultCurrency)
+	accountSummaries := make([]AccountSummaryResponse, len(accounts))
+
+	for i, acc := range accounts {
+		realBalance, err := acc.RealBalance(clk)
+		if err != nil {
+			return AccountListResponse{}, err
+		}
+		pendingClearance, err := acc.PendingClearanceBalance(clk)
+		if err != nil {
+			return AccountListResponse{}, err
+		}
+		projectedBalance, err := acc.ProjectedBalance()
+		if err != nil {
+			return AccountListResponse{}, err
+		}
+
+		accountSummaries[i] = AccountSummaryResponse{
+			ID:                      acc.ID,
+			Name:                    acc.Name,
+			Currency:                acc.Currency,
+			Kind:                    acc.Kind,
+			RealBalance:             realBalance.Amount,
+			PendingClearanceBalance: pendingClearance.Amount,
+			ProjectedBalance:        projectedBalance.Amount,
+			DeleteAfter:             acc.DeleteAfter,
+		}
+
+		// Accounts the user deleted stay listed, so the deletion can be cancelled, but no longer count
+		if !acc.IncludeInOverallBalance || acc.DeleteAfter != nil || acc.Currency != DefaultCurrency {
+			continue
+		}
+
+		// A .presentation business logic: overall balance calculation (regardless of the period)
+		if overallRealBalance, err = overallRealBalance.Add(realBalance); err != nil {
+			return AccountListResponse{}, err
+		}
+		if overallPendingClearance, err = overallPendingClearance.Add(pendingClearance); err != nil {
+			return AccountListResponse{}, err
+		}
+		if overallProjectedBalance, err = overallProjectedBalance.Add(projectedBalance); err != nil {
+			return AccountListResponse{}, err
+		}
+
+		// calculate the current month's flow
+		for _, tx := range acc.Transactions() {
+			// The transaction only enters the monthly flow if:
+			// 1. It was paid/completed (PaidAt is not null)
+			// 2. The payment date is within the current month's range
+			if tx.PaidAt == nil || tx.PaidAt.Before(startOfMonth) || !tx.PaidAt.Before(startOfNextMonth) {
+				continue
+			}
+			switch tx.Type {
+			case Income, Adjustment:
+				currentMonthIncome, err = currentMonthIncome.Add(tx.Amount)
+			case Expense:
+				currentMonthExpense, err = currentMonthExpense.Add(tx.Amount)
+			}
+			if err != nil {
+				return AccountListResponse{}, err
+			}
+		}
+	}
+
+	netFlow, err := currentMonthIncome.Add(currentMonthExpense)
+	if err != nil {
+		return AccountListResponse{}, err
+	}
+
+	return AccountListResponse{
+		Currency:                       DefaultCurrency,
+		OverallRealBalance:             overallRealBalance.Amount,
+		OverallPendingClearanceBalance: overallPendingClearance.Amount,
+		OverallProjectedBalance:        overallProjectedBalance.Amount,
+		CurrentMonthFlow: CurrentMonthFlowSummary{
+			From:    startOfMonth.Format(time.DateOnly),
+			To:      startOfNextMonth.AddDate(0, 0, -1).Format(time.DateOnly),
+			Income:  currentMonthIncome.Amount,
+			Expense: currentMonthExpense.Amount,
+			NetFlow: netFlow.Amount},
+		Accounts: accountSummaries,
+	}, nil
 }
@@ -0,0 +1,60 @@
+package ledger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errreport"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func TestFindAccountByIDHandlerStatus(t *testing.T) {
+	ownerID, memberID, strangerID := uuid.New(), uuid.New(), uuid.New()
+	access := sharedAccounts{}
+	svc := newTestService(access)
+
+	account, err := svc.CreateAccount(context.Background(), ownerID, "Conta corrente", "BRL", true)
+	if err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+	access[account.ID] = []uuid.UUID{memberID}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		want   int
+	}{
+		{name: "owner", userID: ownerID, want: http.StatusOK},
+		{name: "member of the household", userID: memberID, want: http.StatusForbidden},
+		{name: "stranger", userID: strangerID, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = httpx.ErrorHandler(errreport.Nop{})
+			api := e.Group("/api/v1", func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					ctx := authctx.WithUser(c.Request().Context(), authctx.UserContext{UserID: tt.userID, EmailVerified: true})
+					c.SetRequest(c.Request().WithContext(ctx))
+					return next(c)
+				}
+			})
+			clk := clock.NewFixedClock(time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC))
+			NewLedgerHandler(svc, module.CalendarPeriods{}, 0, clk).RegisterRoutes(api)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+account.ID.String(), nil))
+			if rec.Code != tt.want {
+				t.Fatalf("GET /accounts/:id = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package ledger
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var _ AccountRepository = (*InMemoryAccountRepository)(nil)
+
+// InMemoryAccountRepository is an in-memory implementation of the AccountRepository interface
+// It backs the demo mode, where data is throwaway and must never reach the real database, so the archived history is dropped
+type InMemoryAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[uuid.UUID]*Account
+}
+
+// NewInMemoryAccountRepository creates a new, empty InMemoryAccountRepository
+func NewInMemoryAccountRepository() *InMemoryAccountRepository {
+	return &InMemoryAccountRepository{accounts: make(map[uuid.UUID]*Account)}
+}
+
+// Save stores a copy of the Account aggregate, replacing any previous version
+func (r *InMemoryAccountRepository) Save(ctx context.Context, account *Account) error {
+	return r.SaveAll(ctx, account)
+}
+
+// SaveAll stores copies of several Account aggregates under a single lock, mirroring the Postgres transaction
+// and its version check
+func (r *InMemoryAccountRepository) SaveAll(ctx context.Context, accounts ...*Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, account := range accounts {
+		if stored, ok := r.accounts[account.ID]; ok && stored.Version != account.Version {
+			return ErrAccountModified.With("account_id", account.ID)
+		}
+	}
+	for _, account := range accounts {
+		account.Version++
+		stored := cloneAccount(account)
+		if account.Partial() {
+			stored.transactions = r.mergeLoaded(account)
+		}
+		r.accounts[account.ID] = stored
+		account.clearEvents()
+		account.markLoaded()
+	}
+	return nil
+}
+
+// mergeLoaded returns the stored transactions of a partially loaded account, the ones it was loaded with being
+// replaced by its current ones
+func (r *InMemoryAccountRepository) mergeLoaded(account *Account) []Transaction {
+	var merged []Transaction
+	if stored, ok := r.accounts[account.ID]; ok {
+		for _, tx := range stored.transactions {
+			if !account.loaded[tx.ID] {
+				merged = append(merged, tx)
+			}
+		}
+	}
+	merged = append(merged, account.transactions...)
+	slices.SortStableFunc(merged, func(x, y Transaction) int { return x.DueDate.Compare(y.DueDate) })
+	return merged
+}
+
+// Delete removes the Account aggregate, its events having nowhere to be published in demo mode
+func (r *InMemoryAccountRepository) Delete(ctx context.Context, account *Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.accounts, account.ID)
+	account.clearEvents()
+	return nil
+}
+
+// FindByID retrieves a copy of an Account aggregate by its ID
+func (r *InMemoryAccountRepository) FindByID(ctx context.Context, accountID uuid.UUID) (*Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, ok := r.accounts[accountID]
+	if !ok {
+		return nil, ErrAccountNotFound.With("account_id", accountID)
+	}
+	return cloneAccount(account), nil
+}
+
+// FindByIDScoped retrieves a copy of an Account aggregate by its ID, with the transactions of the scope only
+func (r *InMemoryAccountRepository) FindByIDScoped(ctx context.Context, accountID uuid.UUID, scope TransactionScope) (*Account, error) {
+	account, err := r.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	account.transactions = slices.DeleteFunc(account.transactions, func(tx Transaction) bool { return !scope.Includes(tx) })
+	account.setPartial()
+	return account, nil
+}
+
+// UpdateByID applies change to a copy of the account and stores it, holding the lock meanwhile like the Postgres row lock
+func (r *InMemoryAccountRepository) UpdateByID(ctx context.Context, accountID uuid.UUID, change func(account *Account) error) (*Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.accounts[accountID]
+	if !ok {
+		return nil, ErrAccountNotFound.With("account_id", accountID)
+	}
+	account := cloneAccount(stored)
+	if err := change(account); err != nil {
+		return nil, err
+	}
+	account.Version++
+	r.accounts[account.ID] = cloneAccount(account)
+	account.clearEvents()
+	return account, nil
+}
+
+// FindAccountsByUserID retrieves copies of the user's accounts, ordered by name like the Postgres repository
+func (r *InMemoryAccountRepository) FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]*Account, 0)
+	for _, account := range r.accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, cloneAccount(account))
+		}
+	}
+	slices.SortFunc(accounts, func(a, b *Account) int { return strings.Compare(a.Name, b.Name) })
+
+	return accounts, nil
+}
+
+// FindArchivedAccountsByUserID retrieves copies of the user's archived accounts, the most recently archived first
+func (r *InMemoryAccountRepository) FindArchivedAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]*Account, 0)
+	for _, account := range r.accounts {
+		if account.UserID == userID && account.ArchivedAt != nil {
+			accounts = append(accounts, cloneAccount(account))
+		}
+	}
+	slices.SortFunc(accounts, func(a, b *Account) int {
+		if c := b.ArchivedAt.Compare(*a.ArchivedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return accounts, nil
+}
+
+// FindAccountIDsDueForDeletion returns the IDs of the accounts whose deletion was scheduled before the given time
+func (r *InMemoryAccountRepository) FindAccountIDsDueForDeletion(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]uuid.UUID, 0)
+	for id, account := range r.accounts {
+		if account.DeleteAfter != nil && account.DeleteAfter.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// DeleteByUserID removes every account of a user, used when a demo session expires
+func (r *InMemoryAccountRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, account := range r.accounts {
+		if account.UserID == userID {
+			delete(r.accounts, id)
+		}
+	}
+}
+
+// cloneAccount copies an aggregate so callers never share state with the stored version
+func cloneAccount(a *Account) *Account {
+	clone := *a
+	clone.transactions = slices.Clone(a.transactions)
+	clone.events = nil
+	clone.archived = nil
+	clone.loaded = nil
+	slices.SortStableFunc(clone.transactions, func(x, y Transaction) int { return x.DueDate.Compare(y.DueDate) })
+	return &clone
+}
@@ -0,0 +1,31 @@
+package ledger
+
+import "github.com/Guizzs26/fintrack/pkg/metrics"
+
+// Metrics holds the business metrics of the ledger, observed after each successful use case
+type Metrics struct {
+	accountsCreated     metrics.Counter
+	accountsArchived    metrics.Counter
+	accountsUnarchived  metrics.Counter
+	transactionsCreated metrics.Counter
+	balanceAdjustments  metrics.Counter
+
+	accountDeletionsScheduled metrics.Counter
+	accountDeletionsCancelled metrics.Counter
+	accountsDeleted           metrics.Counter
+}
+
+// NewMetrics registers the ledger metrics in the provider
+func NewMetrics(provider metrics.Provider) *Metrics {
+	return &Metrics{
+		accountsCreated:     provider.Counter("ledger_accounts_created_total", "Accounts created, by currency", "currency"),
+		accountsArchived:    provider.Counter("ledger_accounts_archived_total", "Accounts archived"),
+		accountsUnarchived:  provider.Counter("ledger_accounts_unarchived_total", "Accounts unarchived"),
+		transactionsCreated: provider.Counter("ledger_transactions_created_total", "Transactions created, by type", "type"),
+		balanceAdjustments:  provider.Counter("ledger_balance_adjustments_total", "Manual balance adjustments applied"),
+
+		accountDeletionsScheduled: provider.Counter("ledger_account_deletions_scheduled_total", "Account deletions requested by users"),
+		accountDeletionsCancelled: provider.Counter("ledger_account_deletions_cancelled_total", "Account deletions cancelled during the grace period"),
+		accountsDeleted:           provider.Counter("ledger_accounts_deleted_total", "Accounts deleted for good once their grace period elapsed"),
+	}
+}
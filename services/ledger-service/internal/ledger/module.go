@@ -0,0 +1,52 @@
+package ledger
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the ledger repositories, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *LedgerHandler
+}
+
+// NewModule creates the ledger module backed by Postgres, access telling the accounts shared with a user apart and the
+// listeners being notified of every saved transaction
+func NewModule(deps module.Deps, access AccountAccess, listeners ...TransactionListener) *Module {
+	slowQueries := NewSlowQueryLogger(deps.Config.Postgres.SlowQueryThreshold, deps.Metrics)
+	timeouts := QueryTimeouts{Write: deps.Config.Postgres.QueryTimeout, Read: deps.Config.Postgres.ReadQueryTimeout}
+	return NewModuleWithRepository(deps, NewPostgresAccountRepository(deps.Postgres.Pool, deps.Postgres, deps.Fields, slowQueries, timeouts), access, listeners...)
+}
+
+// NewModuleWithRepository creates the ledger module backed by the given repository (e.g., in-memory for demo mode),
+// access being nil when no account is shared
+func NewModuleWithRepository(deps module.Deps, accountRepo AccountRepository, access AccountAccess, listeners ...TransactionListener) *Module {
+	var operations *OperationLog
+	if deps.Config.Ledger.UndoWindow > 0 {
+		operations = NewOperationLog(deps.Config.Ledger.UndoWindow, deps.Clock)
+	}
+	ledgerSvc := NewLedgerService(accountRepo, deps.Audit, NewMetrics(deps.Metrics), deps.Config.Ledger.AccountDeletionGrace, deps.Config.Ledger.MaxActiveTransactions, operations, access, deps.IDs, deps.Clock, listeners...)
+
+	return &Module{
+		service: ledgerSvc,
+		handler: NewLedgerHandler(ledgerSvc, deps.Periods, deps.Config.Ledger.ArchivedAccountRetention, deps.Clock),
+	}
+}
+
+// Service returns the ledger service, for modules reading the accounts (e.g., the forecast)
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "ledger"
+}
+
+// RegisterRoutes mounts the ledger routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
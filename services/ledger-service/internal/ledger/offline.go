@@ -0,0 +1,324 @@
+package ledger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidOfflineChange = errx.New(errx.CategoryValidation, "INVALID_OFFLINE_CHANGE", "invalid offline change")
+
+const (
+	OfflineCreateTransaction OfflineChangeType = "CREATE_TRANSACTION"
+	OfflineMarkPaid          OfflineChangeType = "MARK_PAID"
+	OfflineMarkUnpaid        OfflineChangeType = "MARK_UNPAID"
+	OfflineDeleteTransaction OfflineChangeType = "DELETE_TRANSACTION"
+)
+
+const (
+	OfflineChangeApplied  OfflineChangeStatus = "APPLIED"
+	OfflineChangeConflict OfflineChangeStatus = "CONFLICT" // CONFLICT changes target a transaction changed on the server since the client saw it
+	OfflineChangeRejected OfflineChangeStatus = "REJECTED" // REJECTED changes break a ledger rule, their result carries its error code
+)
+
+const (
+	auditOfflineChangesApplied = "offline_changes.applied"
+
+	// offlineSaveAttempts bounds how many times the changes are applied again on a fresh account
+	// when the account is modified concurrently
+	offlineSaveAttempts = 3
+)
+
+// OfflineChangeType names an operation the client queued while offline
+type OfflineChangeType string
+
+// Values returns the valid offline change types
+func (OfflineChangeType) Values() []string {
+	return []string{string(OfflineCreateTransaction), string(OfflineMarkPaid), string(OfflineMarkUnpaid), string(OfflineDeleteTransaction)}
+}
+
+// OfflineChangeStatus is the outcome of an offline change
+type OfflineChangeStatus string
+
+// OfflineChange is an operation on a transaction the client queued while offline
+type OfflineChange struct {
+	ClientID      string // ClientID identifies the change in the queue of the client, echoed in its result
+	Type          OfflineChangeType
+	TransactionID uuid.UUID // TransactionID is chosen by the client for the created transactions, so retrying a change is idempotent
+	BaseRevision  string    // BaseRevision is the revision of the transaction the client changed, empty to skip the conflict check
+
+	// The details of the created transactions
+	TxType      TransactionType
+	Description string
+	Observation string
+	Amount      string // Amount as a decimal string, parsed using the account currency (e.g., "-1.234,56")
+	// AmountMinorUnits is the amount in minor units of the account currency, used when Amount is empty (version 1 bodies)
+	AmountMinorUnits int64
+	CategoryID       *uuid.UUID
+	DueDate          time.Time
+	Tags             []string
+	Location         *Location
+
+	PaidAt *time.Time // PaidAt is when a created transaction or a MARK_PAID one was paid
+}
+
+// OfflineChangeResult is the outcome of an offline change, Code naming the broken rule of the REJECTED ones
+type OfflineChangeResult struct {
+	ClientID string
+	Status   OfflineChangeStatus
+	Code     string
+}
+
+// ApplyOfflineChangesParams holds all the required data for the ApplyOfflineChanges use case
+type ApplyOfflineChangesParams struct {
+	AccountID uuid.UUID
+	UserID    uuid.UUID
+	Changes   []OfflineChange // Changes are applied in the order the client made them
+}
+
+// Revision fingerprints the content of the transaction, changing whenever any of its fields does
+// Times are compared at the precision the database keeps them
+func (tx Transaction) Revision() string {
+	state := struct {
+		CategoryID  *uuid.UUID
+		Type        TransactionType
+		Description string
+		Observation string
+		Amount      money.Money
+		DueDate     time.Time
+		PaidAt      *time.Time
+		Original    string
+		Pix         *PixDetails
+		Tags        []string
+		Clearing    *Clearing
+		Location    *Location
+	}{
+		CategoryID:  tx.CategoryID,
+		Type:        tx.Type,
+		Description: tx.Description,
+		Observation: tx.Observation,
+		Amount:      tx.Amount,
+		DueDate:     revisionTime(tx.DueDate),
+		Tags:        tx.Tags,
+		Pix:         tx.Pix,
+		Location:    tx.Location,
+	}
+	if tx.PaidAt != nil {
+		paidAt := revisionTime(*tx.PaidAt)
+		state.PaidAt = &paidAt
+	}
+	if tx.Original != nil {
+		state.Original = tx.Original.Amount.String() + " " + tx.Original.Rate.String()
+	}
+	if tx.Clearing != nil {
+		state.Clearing = &Clearing{Method: tx.Clearing.Method, ExpectedClearingDate: revisionTime(tx.Clearing.ExpectedClearingDate)}
+	}
+
+	// Marshaling plain strings and times cannot fail
+	data, _ := json.Marshal(state)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// revisionTime normalizes a time to the microseconds Postgres keeps, in UTC
+func revisionTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// ApplyOfflineChanges is the use case for reconciling the changes a client queued offline with the account,
+// applying them in order in a single save and returning the saved account with the outcome of each change
+// Creating a transaction that already exists or deleting one that no longer does count as applied, so a client
+// retrying a sync never duplicates its changes. The changes of a transaction the server changed since the client
+// saw it are reported as conflicts, for the user to review them on the current state
+func (s *Service) ApplyOfflineChanges(ctx context.Context, params ApplyOfflineChangesParams) (*Account, []OfflineChangeResult, error) {
+	for attempt := 1; ; attempt++ {
+		account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find account to apply offline changes: %w", err)
+		}
+
+		results, created, changed := s.applyOfflineChanges(account, params.Changes)
+		if !changed {
+			return account, results, nil
+		}
+
+		err = s.accountRepo.Save(ctx, account)
+		if errors.Is(err, ErrAccountModified) && attempt < offlineSaveAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to save account after applying offline changes: %w", err)
+		}
+
+		s.recordOfflineChanges(ctx, account, created, results)
+		return account, results, nil
+	}
+}
+
+// applyOfflineChanges applies the changes to the account, returning the IDs of the transactions created
+// and telling whether any of the changes changed it
+func (s *Service) applyOfflineChanges(account *Account, changes []OfflineChange) ([]OfflineChangeResult, []uuid.UUID, bool) {
+	results := make([]OfflineChangeResult, len(changes))
+	var created []uuid.UUID
+	changed := false
+	for i, change := range changes {
+		status, applied, err := s.applyOfflineChange(account, change)
+		results[i] = OfflineChangeResult{ClientID: change.ClientID, Status: status}
+		if err != nil {
+			results[i].Status = OfflineChangeRejected
+			if domainErr, ok := errx.AsDomainError(err); ok {
+				results[i].Code = domainErr.Code
+			}
+			continue
+		}
+		if applied {
+			changed = true
+			if change.Type == OfflineCreateTransaction {
+				created = append(created, change.TransactionID)
+			}
+		}
+	}
+	return results, created, changed
+}
+
+// applyOfflineChange applies a single change to the account, telling whether it changed the account
+// The errors are the ledger rules the change breaks, a change with nothing left to do being reported as applied
+func (s *Service) applyOfflineChange(account *Account, change OfflineChange) (OfflineChangeStatus, bool, error) {
+	current, err := account.findTransaction(change.TransactionID)
+	if err != nil && !errors.Is(err, ErrTransactionNotFound) {
+		return "", false, err
+	}
+
+	switch change.Type {
+	case OfflineCreateTransaction:
+		if current != nil {
+			return OfflineChangeApplied, false, nil
+		}
+		if err := s.createOfflineTransaction(account, change); err != nil {
+			return "", false, err
+		}
+		return OfflineChangeApplied, true, nil
+	case OfflineDeleteTransaction:
+		if current == nil {
+			return OfflineChangeApplied, false, nil
+		}
+	case OfflineMarkPaid, OfflineMarkUnpaid:
+		// A transaction deleted on the server cannot be settled, the user decides whether to create it again
+		if current == nil {
+			return OfflineChangeConflict, false, nil
+		}
+		if (current.PaidAt != nil) == (change.Type == OfflineMarkPaid) {
+			return OfflineChangeApplied, false, nil
+		}
+	default:
+		return "", false, ErrInvalidOfflineChange.With("type", change.Type)
+	}
+
+	if change.BaseRevision != "" && change.BaseRevision != current.Revision() {
+		return OfflineChangeConflict, false, nil
+	}
+
+	switch change.Type {
+	case OfflineDeleteTransaction:
+		err = account.DeleteTransaction(change.TransactionID)
+	case OfflineMarkPaid:
+		if change.PaidAt == nil {
+			return "", false, ErrInvalidOfflineChange.With("reason", "paid_at is required")
+		}
+		err = account.MarkTransactionAsPaid(change.TransactionID, *change.PaidAt, s.clock)
+	case OfflineMarkUnpaid:
+		err = account.MarkTransactionAsUnpaid(change.TransactionID)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return OfflineChangeApplied, true, nil
+}
+
+// createOfflineTransaction adds the transaction of a CREATE_TRANSACTION change with the ID the client chose
+func (s *Service) createOfflineTransaction(account *Account, change OfflineChange) error {
+	if change.TransactionID == uuid.Nil {
+		return ErrInvalidOfflineChange.With("reason", "transaction_id is required")
+	}
+	if change.DueDate.IsZero() {
+		return ErrInvalidOfflineChange.With("reason", "due_date is required")
+	}
+	var err error
+	amount := money.New(change.AmountMinorUnits, account.Currency)
+	if change.Amount != "" {
+		if amount, err = money.Parse(change.Amount, account.Currency); err != nil {
+			return err
+		}
+	}
+	// The tags and the location are checked before adding the transaction, so a rejected change leaves nothing behind
+	if _, err := NormalizeTags(change.Tags); err != nil {
+		return err
+	}
+	if change.Location != nil {
+		if _, err := change.Location.normalize(); err != nil {
+			return err
+		}
+	}
+
+	err = account.AddTransactionWithID(change.TransactionID, change.TxType, change.Description, change.Observation,
+		amount, change.CategoryID, change.DueDate, change.PaidAt, s.clock)
+	if err != nil {
+		return err
+	}
+	if len(change.Tags) > 0 {
+		if err := account.SetTransactionTags(change.TransactionID, change.Tags); err != nil {
+			return err
+		}
+	}
+	if change.Location != nil {
+		return account.SetTransactionLocation(change.TransactionID, change.Location)
+	}
+	return nil
+}
+
+// recordOfflineChanges records the saved offline changes, the created transactions being recorded and notified
+// like the ones added online
+func (s *Service) recordOfflineChanges(ctx context.Context, account *Account, created []uuid.UUID, results []OfflineChangeResult) {
+	counts := make(map[OfflineChangeStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditOfflineChangesApplied,
+		ResourceType: auditResourceAccount,
+		ResourceID:   account.ID.String(),
+		Metadata: map[string]string{
+			"applied":   strconv.Itoa(counts[OfflineChangeApplied]),
+			"conflicts": strconv.Itoa(counts[OfflineChangeConflict]),
+			"rejected":  strconv.Itoa(counts[OfflineChangeRejected]),
+		},
+	})
+
+	for _, txID := range created {
+		tx, err := account.findTransaction(txID)
+		if err != nil {
+			// A transaction created then deleted by the same changes was never saved
+			continue
+		}
+		s.auditor.Record(ctx, audit.Entry{
+			Action:       auditTransactionCreated,
+			ResourceType: auditResourceTransaction,
+			ResourceID:   tx.ID.String(),
+			After:        toTransactionAuditState(account.ID, *tx),
+		})
+		s.metrics.transactionsCreated.Inc(string(tx.Type))
+		for _, listener := range s.listeners {
+			listener.TransactionAdded(ctx, account, *tx)
+		}
+	}
+}
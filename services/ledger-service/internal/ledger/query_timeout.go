@@ -0,0 +1,100 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var _ DBQuerier = (*timeoutQuerier)(nil)
+
+// QueryTimeouts bound every statement of the repository, so a stuck query does not hold its connection and the
+// request waiting on it. The context of the caller still aborts a statement earlier (e.g., the client went away)
+type QueryTimeouts struct {
+	Write time.Duration // Write bounds the statements on the primary, loading and saving the aggregates, 0 disables it
+	Read  time.Duration // Read bounds the listings, which may scan the whole history of a user, 0 disables it
+}
+
+// withTimeout returns db with each statement bounded by timeout, or db itself when timeout is not positive
+func withTimeout(db DBQuerier, timeout time.Duration) DBQuerier {
+	if timeout <= 0 {
+		return db
+	}
+	return &timeoutQuerier{db: db, timeout: timeout}
+}
+
+// timeoutQuerier runs every statement of the wrapped DBQuerier under its own deadline, released once the statement
+// is done: when Exec returns, the row is scanned, the rows are closed or the batch is closed
+type timeoutQuerier struct {
+	db      DBQuerier
+	timeout time.Duration
+}
+
+func (q *timeoutQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+	return q.db.Exec(ctx, sql, args...)
+}
+
+func (q *timeoutQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	rows, err := q.db.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (q *timeoutQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	return &timeoutRow{row: q.db.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+func (q *timeoutQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	return &timeoutBatch{BatchResults: q.db.SendBatch(ctx, b), cancel: cancel}
+}
+
+// timeoutRows releases the deadline of the query once its rows are read or closed
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.cancel()
+	return false
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// timeoutRow releases the deadline of the query once its row is scanned
+type timeoutRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
+
+// timeoutBatch releases the deadline of the batch once it is closed
+type timeoutBatch struct {
+	pgx.BatchResults
+	cancel context.CancelFunc
+}
+
+func (b *timeoutBatch) Close() error {
+	defer b.cancel()
+	return b.BatchResults.Close()
+}
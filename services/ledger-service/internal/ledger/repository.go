@@ -2,10 +2,17 @@ package ledger
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/pkg/pix"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/fieldcrypt"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -15,19 +22,28 @@ import (
 var _ AccountRepository = (*PostgresAccountRepository)(nil)
 
 var (
-	ErrAccountNotFound = errors.New("account not found in database")
+	ErrAccountNotFound = errx.New(errx.CategoryNotFound, "ACCOUNT_NOT_FOUND", "account not found")
 )
 
+// outboxAggregateAccount is the aggregate type of the account events, naming the topic they are published to
+const outboxAggregateAccount = "account"
+
 // ----- Main struct repository and Querier ----- //
 
 // PostgresAccountRepository is a PostgreSQL implementation of the AccountRepository interface defined by the domain layer
+// The listings are read from the read pool, which may be a replica, while the aggregates loaded to be changed are read from the primary
+// The observations and the metadata of the transactions are encrypted with the data key of their user when fields is set
 type PostgresAccountRepository struct {
-	pool *pgxpool.Pool
+	pool        *pgxpool.Pool
+	reads       postgres.ReadRouter // reads hands out the pool of the listings, nil to read everything from pool
+	fields      *fieldcrypt.Cipher  // fields encrypts the sensitive fields of the transactions, nil to store them in plain text
+	slowQueries *SlowQueryLogger    // slowQueries decorates every Querier, nil when slow queries are not reported
+	timeouts    QueryTimeouts       // timeouts bound every statement of the Queriers, the zero value leaving them unbounded
 }
 
-// NewPostgresAccountRepository creates a new PostgresAccountRepository
-func NewPostgresAccountRepository(pool *pgxpool.Pool) *PostgresAccountRepository {
-	return &PostgresAccountRepository{pool: pool}
+// NewPostgresAccountRepository creates a new PostgresAccountRepository writing to pool and listing from reads, which may be nil
+func NewPostgresAccountRepository(pool *pgxpool.Pool, reads postgres.ReadRouter, fields *fieldcrypt.Cipher, slowQueries *SlowQueryLogger, timeouts QueryTimeouts) *PostgresAccountRepository {
+	return &PostgresAccountRepository{pool: pool, reads: reads, fields: fields, slowQueries: slowQueries, timeouts: timeouts}
 }
 
 // ExecTx executes a function within a database transaction
@@ -37,7 +53,7 @@ func (par *PostgresAccountRepository) ExecTx(ctx context.Context, fn func(q *Que
 		return fmt.Errorf("repository: failed to begin transaction: %w", err)
 	}
 
-	q := NewQuerier(tx)
+	q := NewQuerier(par.slowQueries.Wrap(withTimeout(tx, par.timeouts.Write)), par.fields)
 
 	if err := fn(q); err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
@@ -55,7 +71,16 @@ func (par *PostgresAccountRepository) ExecTx(ctx context.Context, fn func(q *Que
 
 // Querier returns a new Querier instance that uses the repository's connection pool
 func (par *PostgresAccountRepository) Querier() *Querier {
-	return NewQuerier(par.pool)
+	return NewQuerier(par.slowQueries.Wrap(withTimeout(par.pool, par.timeouts.Write)), par.fields)
+}
+
+// ReadQuerier returns a new Querier instance for the pure reads, which tolerate the lag of a replica
+func (par *PostgresAccountRepository) ReadQuerier() *Querier {
+	var db DBQuerier = par.pool
+	if par.reads != nil {
+		db = par.reads.ReadPool()
+	}
+	return NewQuerier(par.slowQueries.Wrap(withTimeout(db, par.timeouts.Read)), par.fields)
 }
 
 // DBQuerier is an interface that is satisfied by both *pgxpool.Pool and pgx.Tx
@@ -70,12 +95,13 @@ type DBQuerier interface {
 
 // Querier holds a DBQuerier interface, allowing it to execute SQL queries
 type Querier struct {
-	db DBQuerier
+	db     DBQuerier
+	fields *fieldcrypt.Cipher
 }
 
-// NewQuerier creates a new Querier
-func NewQuerier(db DBQuerier) *Querier {
-	return &Querier{db: db}
+// NewQuerier creates a new Querier, encrypting the sensitive fields of the transactions with fields when it is not nil
+func NewQuerier(db DBQuerier, fields *fieldcrypt.Cipher) *Querier {
+	return &Querier{db: db, fields: fields}
 }
 
 // ----- MODELS ----- //
@@ -85,8 +111,13 @@ type accountModel struct {
 	ID                      uuid.UUID  `db:"id"`
 	UserID                  uuid.UUID  `db:"user_id"`
 	Name                    string     `db:"name"`
+	Currency                string     `db:"currency"`
+	Kind                    string     `db:"kind"`
+	StatementClosingDay     *int16     `db:"statement_closing_day"`
 	IncludeInOverallBalance bool       `db:"include_in_overall_balance"`
 	ArchivedAt              *time.Time `db:"archived_at"`
+	DeleteAfter             *time.Time `db:"delete_after"`
+	Version                 int64      `db:"version"`
 	CreatedAt               time.Time  `db:"created_at"`
 	UpdatedAt               time.Time  `db:"updated_at"`
 }
@@ -104,26 +135,70 @@ type transactionModel struct {
 	DueDate     time.Time       `db:"due_date"`
 	PaidAt      *time.Time      `db:"paid_at"`
 	Metadata    []byte          `db:"metadata"`
-	CreatedAt   time.Time       `db:"created_at"`
-	UpdatedAt   time.Time       `db:"updated_at"`
+
+	OriginalAmount   *int64  `db:"original_amount_in_cents"`
+	OriginalCurrency *string `db:"original_currency"`
+	ExchangeRate     *string `db:"exchange_rate"` // ExchangeRate is read and written as text to keep the NUMERIC precision
+
+	Tags []string `db:"tags"` // Tags are read from the transaction_tags links, sorted by name
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// transactionMetadata is the JSON stored in the metadata column, holding the optional details of a transaction
+type transactionMetadata struct {
+	Pix      *pixMetadata      `json:"pix,omitempty"`
+	Clearing *clearingMetadata `json:"clearing,omitempty"`
+	Location *locationMetadata `json:"location,omitempty"`
+}
+
+// pixMetadata is the JSON form of PixDetails
+type pixMetadata struct {
+	EndToEndID string `json:"end_to_end_id,omitempty"`
+	Key        string `json:"key,omitempty"`
+	KeyType    string `json:"key_type,omitempty"`
+	QRPayload  string `json:"qr_payload,omitempty"`
+}
+
+// clearingMetadata is the JSON form of Clearing
+type clearingMetadata struct {
+	Method               string    `json:"method"`
+	ExpectedClearingDate time.Time `json:"expected_clearing_date"`
+}
+
+// locationMetadata is the JSON form of Location
+type locationMetadata struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	PlaceName string  `json:"place_name,omitempty"`
 }
 
 // ----- MAPPERS ----- //
 
 // toAccountPersistence maps a domain Account to its persistence model
 func toAccountPersistence(a *Account) *accountModel {
-	return &accountModel{
+	m := &accountModel{
 		ID:                      a.ID,
 		UserID:                  a.UserID,
 		Name:                    a.Name,
+		Currency:                a.Currency,
+		Kind:                    string(a.Kind),
 		IncludeInOverallBalance: a.IncludeInOverallBalance,
 		ArchivedAt:              a.GetArchivedAt(),
+		DeleteAfter:             a.DeleteAfter,
+		Version:                 a.Version,
 	}
+	if a.Kind == CreditCard {
+		closingDay := int16(a.StatementClosingDay)
+		m.StatementClosingDay = &closingDay
+	}
+	return m
 }
 
 // toTransactionPersistence maps a domain Transaction to its persistence model
 func toTransactionPersistence(tx *Transaction, accountID, userID uuid.UUID) *transactionModel {
-	return &transactionModel{
+	m := &transactionModel{
 		ID:          tx.ID,
 		AccountID:   accountID,
 		UserID:      userID,
@@ -131,44 +206,158 @@ func toTransactionPersistence(tx *Transaction, accountID, userID uuid.UUID) *tra
 		Type:        tx.Type,
 		Description: tx.Description,
 		Observation: tx.Observation,
-		Amount:      tx.Amount,
+		Amount:      tx.Amount.Amount,
 		DueDate:     tx.DueDate,
 		PaidAt:      tx.PaidAt,
-		Metadata:    nil,
+		Metadata:    toMetadataPersistence(tx),
+	}
+	if tx.Original != nil {
+		rate := tx.Original.Rate.String()
+		m.OriginalAmount = &tx.Original.Amount.Amount
+		m.OriginalCurrency = &tx.Original.Amount.Currency
+		m.ExchangeRate = &rate
 	}
+	return m
+}
+
+// toMetadataPersistence encodes the optional details of a transaction, nil when it has none
+func toMetadataPersistence(tx *Transaction) []byte {
+	if tx.Pix == nil && tx.Clearing == nil && tx.Location == nil {
+		return nil
+	}
+	var metadata transactionMetadata
+	if tx.Pix != nil {
+		metadata.Pix = &pixMetadata{
+			EndToEndID: tx.Pix.EndToEndID,
+			Key:        tx.Pix.Key,
+			KeyType:    string(tx.Pix.KeyType),
+			QRPayload:  tx.Pix.QRPayload,
+		}
+	}
+	if tx.Clearing != nil {
+		metadata.Clearing = &clearingMetadata{
+			Method:               string(tx.Clearing.Method),
+			ExpectedClearingDate: tx.Clearing.ExpectedClearingDate,
+		}
+	}
+	if tx.Location != nil {
+		metadata.Location = &locationMetadata{
+			Latitude:  tx.Location.Latitude,
+			Longitude: tx.Location.Longitude,
+			PlaceName: tx.Location.PlaceName,
+		}
+	}
+	// Marshaling plain strings, times and validated coordinates cannot fail
+	data, _ := json.Marshal(metadata)
+	return data
+}
+
+// sealTransaction encrypts the observation and the metadata of a persistence model with the data key of its user
+func (q *Querier) sealTransaction(ctx context.Context, m *transactionModel) error {
+	observation, err := q.fields.SealString(ctx, m.UserID, m.Observation)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt transaction observation: %w", err)
+	}
+	metadata, err := q.fields.Seal(ctx, m.UserID, m.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt transaction metadata: %w", err)
+	}
+	m.Observation, m.Metadata = observation, metadata
+	return nil
+}
+
+// openTransaction decrypts the observation and the metadata of a persistence model read from the database
+func (q *Querier) openTransaction(ctx context.Context, m *transactionModel) error {
+	observation, err := q.fields.OpenString(ctx, m.UserID, m.Observation)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt transaction observation: %w", err)
+	}
+	metadata, err := q.fields.Open(ctx, m.UserID, m.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt transaction metadata: %w", err)
+	}
+	m.Observation, m.Metadata = observation, metadata
+	return nil
 }
 
 // toAccountDomain maps a persistence accountModel and its transactions to a domain Account
 func toAccountDomain(m *accountModel, txsModels []transactionModel) *Account {
 	domainTx := make([]Transaction, len(txsModels))
 	for i, txm := range txsModels {
-		domainTx[i] = *toTransactionDomain(&txm)
+		domainTx[i] = *toTransactionDomain(&txm, m.Currency)
 	}
 
 	// 2. Montar o agregado Account, injetando suas transações filhas.
 	// Note que não usamos NewAccount() aqui, pois estamos recriando um agregado
 	// que já existe, e não criando um novo.
-	return &Account{
+	account := &Account{
 		ID:                      m.ID,
 		UserID:                  m.UserID,
 		Name:                    m.Name,
+		Currency:                m.Currency,
+		Kind:                    AccountKind(m.Kind),
 		IncludeInOverallBalance: m.IncludeInOverallBalance,
 		ArchivedAt:              m.ArchivedAt,
+		DeleteAfter:             m.DeleteAfter,
+		Version:                 m.Version,
 		transactions:            domainTx,
 	}
+	if m.StatementClosingDay != nil {
+		account.StatementClosingDay = int(*m.StatementClosingDay)
+	}
+	return account
 }
 
 // toTransactionDomain maps a persistence transactionModel to a domain Transaction
-func toTransactionDomain(m *transactionModel) *Transaction {
-	return &Transaction{
+// Amounts are stored in minor units of the owning account's currency, original amounts in minor units of their own
+func toTransactionDomain(m *transactionModel, currency string) *Transaction {
+	tx := &Transaction{
 		ID:          m.ID,
+		CategoryID:  m.CategoryID,
 		Type:        m.Type,
 		Description: m.Description,
 		Observation: m.Observation,
-		Amount:      m.Amount,
+		Amount:      money.New(m.Amount, currency),
 		DueDate:     m.DueDate,
 		PaidAt:      m.PaidAt,
 	}
+	if len(m.Tags) > 0 {
+		tx.Tags = m.Tags
+	}
+	if m.OriginalAmount != nil && m.OriginalCurrency != nil && m.ExchangeRate != nil {
+		// The rate was validated when written, a value that no longer parses only loses the original amount
+		if rate, err := money.ParseRate(*m.OriginalCurrency, currency, *m.ExchangeRate); err == nil {
+			tx.Original = &ForeignAmount{Amount: money.New(*m.OriginalAmount, *m.OriginalCurrency), Rate: rate}
+		}
+	}
+	if len(m.Metadata) > 0 {
+		// Metadata that no longer decodes only loses the details it held
+		var metadata transactionMetadata
+		if err := json.Unmarshal(m.Metadata, &metadata); err == nil {
+			if metadata.Pix != nil {
+				tx.Pix = &PixDetails{
+					EndToEndID: metadata.Pix.EndToEndID,
+					Key:        metadata.Pix.Key,
+					KeyType:    pix.KeyType(metadata.Pix.KeyType),
+					QRPayload:  metadata.Pix.QRPayload,
+				}
+			}
+			if metadata.Clearing != nil {
+				tx.Clearing = &Clearing{
+					Method:               ClearingMethod(metadata.Clearing.Method),
+					ExpectedClearingDate: metadata.Clearing.ExpectedClearingDate,
+				}
+			}
+			if metadata.Location != nil {
+				tx.Location = &Location{
+					Latitude:  metadata.Location.Latitude,
+					Longitude: metadata.Location.Longitude,
+					PlaceName: metadata.Location.PlaceName,
+				}
+			}
+		}
+	}
+	return tx
 }
 
 // ----- Repository Methods ----- //
@@ -177,23 +366,59 @@ func toTransactionDomain(m *transactionModel) *Transaction {
 // first upserting the account, then deleting all existing transactions for that account,
 // and finally bulk-inserting the current transactions from the aggregate
 func (par *PostgresAccountRepository) Save(ctx context.Context, account *Account) error {
-	return par.ExecTx(ctx, func(q *Querier) error {
-		accModel := toAccountPersistence(account)
+	return par.SaveAll(ctx, account)
+}
 
-		if err := q.upsertAccount(ctx, accModel); err != nil {
-			return err
+// SaveAll persists several Account aggregates within a single database transaction,
+// so an operation spanning accounts (e.g., paying a card statement) is never half applied
+// Each account must still be at the version it was loaded at, otherwise ErrAccountModified is returned
+func (par *PostgresAccountRepository) SaveAll(ctx context.Context, accounts ...*Account) error {
+	versions := make([]int64, len(accounts))
+	err := par.ExecTx(ctx, func(q *Querier) error {
+		for i, account := range accounts {
+			version, err := q.saveAccount(ctx, account)
+			if err != nil {
+				return err
+			}
+			versions[i] = version
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, account := range accounts {
+		account.Version = versions[i]
+		account.clearEvents()
+		account.markLoaded()
+	}
+	return nil
+}
 
-		if err := q.deleteTransactionsForAccount(ctx, accModel.ID); err != nil {
+// Delete removes the account and its transactions for good and writes its events to the outbox, in a single transaction
+// The other records of the account (comments, transfers, positions...) are deleted by cascade
+func (par *PostgresAccountRepository) Delete(ctx context.Context, account *Account) error {
+	err := par.ExecTx(ctx, func(q *Querier) error {
+		if err := q.deleteTransactionsForAccount(ctx, account.ID); err != nil {
 			return err
 		}
-
-		if err := q.bulkInsertTransactions(ctx, account.ID, account.UserID, account.Transactions()); err != nil {
+		if err := q.deleteAccount(ctx, account.ID); err != nil {
 			return err
 		}
-
-		return nil
+		return q.insertOutboxEvents(ctx, account)
 	})
+	if err != nil {
+		return err
+	}
+
+	account.clearEvents()
+	return nil
+}
+
+// FindAccountIDsDueForDeletion returns the IDs of the accounts whose deletion was scheduled before the given time
+func (par *PostgresAccountRepository) FindAccountIDsDueForDeletion(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	return par.Querier().getAccountIDsDueForDeletion(ctx, before)
 }
 
 // FindByID retrieves an Account aggregate by its ID. It first fetches the account
@@ -215,11 +440,85 @@ func (par *PostgresAccountRepository) FindByID(ctx context.Context, accountID uu
 	return account, nil
 }
 
+// FindByIDScoped retrieves an Account aggregate by its ID with the transactions of the scope only
+func (par *PostgresAccountRepository) FindByIDScoped(ctx context.Context, accountID uuid.UUID, scope TransactionScope) (*Account, error) {
+	q := par.Querier()
+
+	accModel, err := q.getAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	txModels, err := q.getTransactionsByAccountIDScoped(ctx, accountID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	account := toAccountDomain(accModel, txModels)
+	account.setPartial()
+	return account, nil
+}
+
+// FindByIDForUpdate retrieves an Account aggregate by its ID, locking the account row until the transaction of q ends
+// It must run inside ExecTx, a concurrent FindByIDForUpdate of the account waiting for the transaction to commit
+func (q *Querier) FindByIDForUpdate(ctx context.Context, accountID uuid.UUID) (*Account, error) {
+	accModel, err := q.getAccountByIDForUpdate(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	txModels, err := q.getTransactionsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAccountDomain(accModel, txModels), nil
+}
+
+// UpdateByID loads the account with its row locked, applies change to it and saves it within a single transaction,
+// so the concurrent updates of an account are applied one after the other instead of failing with ErrAccountModified
+// Nothing is saved when change fails, its error being returned
+func (par *PostgresAccountRepository) UpdateByID(ctx context.Context, accountID uuid.UUID, change func(account *Account) error) (*Account, error) {
+	var (
+		account *Account
+		version int64
+	)
+	err := par.ExecTx(ctx, func(q *Querier) error {
+		var err error
+		if account, err = q.FindByIDForUpdate(ctx, accountID); err != nil {
+			return err
+		}
+		if err := change(account); err != nil {
+			return err
+		}
+		version, err = q.saveAccount(ctx, account)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.Version = version
+	account.clearEvents()
+	return account, nil
+}
+
 // FindAccountsByUserID retrieves an collection (if exists) of Accounts aggregates by the user ID
 func (par *PostgresAccountRepository) FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error) {
-	q := par.Querier()
+	return par.findAccounts(ctx, userID, false)
+}
+
+// FindArchivedAccountsByUserID retrieves the archived Accounts aggregates of the user, the most recently archived first
+func (par *PostgresAccountRepository) FindArchivedAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error) {
+	return par.findAccounts(ctx, userID, true)
+}
+
+// findAccounts retrieves either the active or the archived Accounts aggregates of the user
+// They are only listed, never saved back, so they are read from the read pool
+func (par *PostgresAccountRepository) findAccounts(ctx context.Context, userID uuid.UUID, archived bool) ([]*Account, error) {
+	q := par.ReadQuerier()
 
-	accModels, err := q.getAccountsByUserID(ctx, userID)
+	accModels, err := q.getAccountsByUserID(ctx, userID, archived)
 	if err != nil {
 		return nil, err
 	}
@@ -249,47 +548,139 @@ func (par *PostgresAccountRepository) FindAccountsByUserID(ctx context.Context,
 
 // ----- Querier Methods ----- //
 
-// upsertAccount inserts a new account or updates an existing one based on its ID
-// It uses the 'ON CONFLICT' clause to perform an update if the account already exists
-func (q *Querier) upsertAccount(ctx context.Context, accountModel *accountModel) error {
+// saveAccount writes the account row, replaces all of its transactions and writes its events to the outbox,
+// returning the new version of the account
+func (q *Querier) saveAccount(ctx context.Context, account *Account) (int64, error) {
+	accModel := toAccountPersistence(account)
+
+	// The account row is updated below, so the triggers touching the account of the rewritten transactions are skipped
+	if _, err := q.db.Exec(ctx, `SELECT set_config('fintrack.ledger_save', 'on', true)`); err != nil {
+		return 0, fmt.Errorf("failed to flag ledger save: %w", err)
+	}
+
+	version, err := q.upsertAccount(ctx, accModel)
+	if err != nil {
+		return 0, err
+	}
+
+	// A partially loaded account only replaces the transactions it was loaded with
+	if account.Partial() {
+		if err := q.deleteLoadedTransactions(ctx, accModel.ID, account.loadedTransactionIDs()); err != nil {
+			return 0, err
+		}
+	} else if err := q.deleteTransactionsForAccount(ctx, accModel.ID); err != nil {
+		return 0, err
+	}
+
+	if err := q.bulkInsertTransactions(ctx, account.ID, account.UserID, account.Transactions()); err != nil {
+		return 0, err
+	}
+
+	if err := q.insertTransactionTags(ctx, account.UserID, account.Transactions()); err != nil {
+		return 0, err
+	}
+
+	if err := q.insertArchivedTransactions(ctx, account.ID, account.UserID, account.Archived()); err != nil {
+		return 0, err
+	}
+
+	if err := q.insertOutboxEvents(ctx, account); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// upsertAccount inserts a new account or updates an existing one based on its ID, returning its new version
+// It uses the 'ON CONFLICT' clause to perform an update if the account already exists and is still at the version
+// it was loaded at, the trigger on accounts bumping it
+func (q *Querier) upsertAccount(ctx context.Context, accountModel *accountModel) (int64, error) {
 	query := `
+		-- name: upsertAccount
 		INSERT INTO accounts (
 			id, 
 			user_id, 
 			name, 
+			currency,
+			kind,
+			statement_closing_day,
 			include_in_overall_balance, 
-			archived_at
+			archived_at,
+			delete_after
 		)
-		VALUES ($1, $2, $3, $4, $5)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (id)
 		DO UPDATE SET 
 			name = EXCLUDED.name,
    	  include_in_overall_balance = EXCLUDED.include_in_overall_balance,
     	archived_at = EXCLUDED.archived_at,
+			delete_after = EXCLUDED.delete_after,
     	updated_at = now()
+		WHERE accounts.version = $10
+		RETURNING version
 	`
 
-	_, err := q.db.Exec(ctx, query,
+	var version int64
+	err := q.db.QueryRow(ctx, query,
 		accountModel.ID,
 		accountModel.UserID,
 		accountModel.Name,
+		accountModel.Currency,
+		accountModel.Kind,
+		accountModel.StatementClosingDay,
 		accountModel.IncludeInOverallBalance,
 		accountModel.ArchivedAt,
-	)
+		accountModel.DeleteAfter,
+		accountModel.Version,
+	).Scan(&version)
 	if err != nil {
-		return fmt.Errorf("failed to upsert account: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrAccountModified.With("account_id", accountModel.ID)
+		}
+		return 0, fmt.Errorf("failed to upsert account: %w", err)
 	}
 
-	return nil
+	return version, nil
 }
 
 // deleteTransactionsForAccount deletes all transactions associated with a given account ID
 func (q *Querier) deleteTransactionsForAccount(ctx context.Context, accountID uuid.UUID) error {
-	query := `DELETE FROM transactions WHERE account_id = $1`
+	query := `
+		-- name: deleteTransactionsForAccount
+		DELETE FROM transactions WHERE account_id = $1
+	`
 
 	_, err := q.db.Exec(ctx, query, accountID)
 	if err != nil {
-		return fmt.Errorf("failed to delete transactions for account: %v", err)
+		return fmt.Errorf("failed to delete transactions for account: %w", err)
+	}
+
+	return nil
+}
+
+// deleteLoadedTransactions deletes the transactions of the account a partially loaded aggregate holds
+func (q *Querier) deleteLoadedTransactions(ctx context.Context, accountID uuid.UUID, transactionIDs []uuid.UUID) error {
+	query := `
+		-- name: deleteLoadedTransactions
+		DELETE FROM transactions WHERE account_id = $1 AND id = ANY($2)
+	`
+
+	if _, err := q.db.Exec(ctx, query, accountID, transactionIDs); err != nil {
+		return fmt.Errorf("failed to delete loaded transactions for account: %w", err)
+	}
+
+	return nil
+}
+
+// deleteAccount deletes the account row, its transactions having been deleted first since they restrict it
+func (q *Querier) deleteAccount(ctx context.Context, accountID uuid.UUID) error {
+	query := `
+		-- name: deleteAccount
+		DELETE FROM accounts WHERE id = $1
+	`
+
+	if _, err := q.db.Exec(ctx, query, accountID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
 	}
 
 	return nil
@@ -304,12 +695,19 @@ func (q *Querier) bulkInsertTransactions(ctx context.Context, accountID, userID
 	batch := &pgx.Batch{}
 
 	query := `
-		INSERT INTO transactions (id, account_id, user_id, category_id, type, description, observation, amount_in_cents, due_date, paid_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		-- name: bulkInsertTransactions
+		INSERT INTO transactions (
+			id, account_id, user_id, category_id, type, description, observation, amount_in_cents, due_date, paid_at,
+			original_amount_in_cents, original_currency, exchange_rate, metadata
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::numeric, $14)
 	`
 
 	for _, tx := range transactions {
 		txModel := toTransactionPersistence(&tx, accountID, userID)
+		if err := q.sealTransaction(ctx, txModel); err != nil {
+			return err
+		}
 		batch.Queue(query,
 			txModel.ID,
 			txModel.AccountID,
@@ -321,6 +719,10 @@ func (q *Querier) bulkInsertTransactions(ctx context.Context, accountID, userID
 			txModel.Amount,
 			txModel.DueDate,
 			txModel.PaidAt,
+			txModel.OriginalAmount,
+			txModel.OriginalCurrency,
+			txModel.ExchangeRate,
+			txModel.Metadata,
 		)
 	}
 
@@ -328,7 +730,131 @@ func (q *Querier) bulkInsertTransactions(ctx context.Context, accountID, userID
 	defer br.Close()
 
 	if _, err := br.Exec(); err != nil {
-		return fmt.Errorf("failed to bulk insert transactions: %v", err)
+		return fmt.Errorf("failed to bulk insert transactions: %w", err)
+	}
+
+	return nil
+}
+
+// insertArchivedTransactions moves the transactions rolled into an opening balance to the archived history, which
+// keeps their tags by name since the links to the tags were dropped with them
+func (q *Querier) insertArchivedTransactions(ctx context.Context, accountID, userID uuid.UUID, transactions []Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+
+	query := `
+		-- name: insertArchivedTransactions
+		INSERT INTO archived_transactions (
+			id, account_id, user_id, category_id, type, description, observation, amount_in_cents, due_date, paid_at,
+			original_amount_in_cents, original_currency, exchange_rate, metadata, tags
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::numeric, $14, $15)
+	`
+
+	for _, tx := range transactions {
+		txModel := toTransactionPersistence(&tx, accountID, userID)
+		if err := q.sealTransaction(ctx, txModel); err != nil {
+			return err
+		}
+		tags := tx.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		batch.Queue(query,
+			txModel.ID,
+			txModel.AccountID,
+			txModel.UserID,
+			txModel.CategoryID,
+			txModel.Type,
+			txModel.Description,
+			txModel.Observation,
+			txModel.Amount,
+			txModel.DueDate,
+			txModel.PaidAt,
+			txModel.OriginalAmount,
+			txModel.OriginalCurrency,
+			txModel.ExchangeRate,
+			txModel.Metadata,
+			tags,
+		)
+	}
+
+	if err := q.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert archived transactions: %w", err)
+	}
+
+	return nil
+}
+
+// insertTransactionTags links the transactions to their tags, creating the tags the user did not have yet
+// The links of the account were dropped with its transactions, so every tagged transaction is linked again
+func (q *Querier) insertTransactionTags(ctx context.Context, userID uuid.UUID, transactions []Transaction) error {
+	names := make(map[string]bool)
+	for _, tx := range transactions {
+		for _, tag := range tx.Tags {
+			names[tag] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for name := range names {
+		batch.Queue(`
+			-- name: insertTag
+			INSERT INTO tags (id, user_id, name) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, name) DO NOTHING
+		`, uuid.New(), userID, name)
+	}
+	for _, tx := range transactions {
+		if len(tx.Tags) == 0 {
+			continue
+		}
+		batch.Queue(`
+			-- name: insertTransactionTags
+			INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT $1, id FROM tags WHERE user_id = $2 AND name = ANY($3)
+		`, tx.ID, userID, tx.Tags)
+	}
+
+	if err := q.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert transaction tags: %w", err)
+	}
+
+	return nil
+}
+
+// insertOutboxEvents writes the events the account recorded to the outbox, keyed by the account
+// so the relay publishes the events of an account in order
+func (q *Querier) insertOutboxEvents(ctx context.Context, account *Account) error {
+	if len(account.events) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, recorded := range account.events {
+		envelope, err := contracts.NewEnvelope(recorded.event, recorded.occurredAt)
+		if err != nil {
+			return fmt.Errorf("failed to wrap %s event: %w", recorded.event.EventType(), err)
+		}
+		encoded, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s event: %w", envelope.Type, err)
+		}
+
+		batch.Queue(`
+			-- name: insertOutboxEvent
+			INSERT INTO outbox (event_id, aggregate_type, aggregate_id, event_type, event_version, envelope)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, envelope.ID, outboxAggregateAccount, account.ID, envelope.Type, envelope.Version, encoded)
+	}
+
+	if err := q.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert outbox events: %w", err)
 	}
 
 	return nil
@@ -337,24 +863,48 @@ func (q *Querier) bulkInsertTransactions(ctx context.Context, accountID, userID
 // getAccountByID retrieves a single account from the database by its ID
 func (q *Querier) getAccountByID(ctx context.Context, accountID uuid.UUID) (*accountModel, error) {
 	query := `
-		SELECT id, user_id, name, include_in_overall_balance, archived_at, created_at, updated_at
+		-- name: getAccountByID
+		SELECT id, user_id, name, currency, kind, statement_closing_day, include_in_overall_balance, archived_at, delete_after, version, created_at, updated_at
+		FROM accounts
+		WHERE id = $1
+	`
+
+	return q.scanAccountByID(ctx, query, accountID)
+}
+
+// getAccountByIDForUpdate retrieves a single account by its ID, locking its row until the transaction ends
+func (q *Querier) getAccountByIDForUpdate(ctx context.Context, accountID uuid.UUID) (*accountModel, error) {
+	query := `
+		-- name: getAccountByIDForUpdate
+		SELECT id, user_id, name, currency, kind, statement_closing_day, include_in_overall_balance, archived_at, delete_after, version, created_at, updated_at
 		FROM accounts
 		WHERE id = $1
+		FOR UPDATE
 	`
 
+	return q.scanAccountByID(ctx, query, accountID)
+}
+
+// scanAccountByID runs a query selecting the account row of accountID
+func (q *Querier) scanAccountByID(ctx context.Context, query string, accountID uuid.UUID) (*accountModel, error) {
 	var m accountModel
 	err := q.db.QueryRow(ctx, query, accountID).Scan(
 		&m.ID,
 		&m.UserID,
 		&m.Name,
+		&m.Currency,
+		&m.Kind,
+		&m.StatementClosingDay,
 		&m.IncludeInOverallBalance,
 		&m.ArchivedAt,
+		&m.DeleteAfter,
+		&m.Version,
 		&m.CreatedAt,
 		&m.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrAccountNotFound
+			return nil, ErrAccountNotFound.With("account_id", accountID)
 		}
 		return nil, fmt.Errorf("failed to fetch account by id: %w", err)
 	}
@@ -362,20 +912,80 @@ func (q *Querier) getAccountByID(ctx context.Context, accountID uuid.UUID) (*acc
 	return &m, nil
 }
 
+// getAccountIDsDueForDeletion retrieves the IDs of the accounts whose deletion was scheduled before the given time
+func (q *Querier) getAccountIDsDueForDeletion(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	query := `
+		-- name: getAccountIDsDueForDeletion
+		SELECT id FROM accounts WHERE delete_after < $1 ORDER BY delete_after
+	`
+
+	rows, err := q.db.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("query accounts due for deletion: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan accounts due for deletion: %w", err)
+	}
+
+	return ids, nil
+}
+
 // getTransactionsByAccountID retrieves all transactions for a given account ID
 func (q *Querier) getTransactionsByAccountID(ctx context.Context, accountID uuid.UUID) ([]transactionModel, error) {
 	query := `
+		-- name: getTransactionsByAccountID
 		SELECT id, account_id, user_id, category_id, type, description, 
 			observation, amount_in_cents, due_date, metadata, paid_at,
-			created_at, updated_at
+			original_amount_in_cents, original_currency, exchange_rate::text,
+			created_at, updated_at,
+			ARRAY(
+				SELECT tg.name FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+				WHERE tt.transaction_id = transactions.id ORDER BY tg.name
+			) AS tags
 		FROM transactions
 		WHERE account_id = $1
-		ORDER BY due_date ASC
+		ORDER BY due_date ASC, id ASC
 	`
 
-	rows, err := q.db.Query(ctx, query, accountID)
+	return q.queryAccountTransactions(ctx, query, accountID)
+}
+
+// getTransactionsByAccountIDScoped retrieves the transactions of an account within the scope, ordered by due date and then by ID, which follows the creation order of the time-ordered IDs
+func (q *Querier) getTransactionsByAccountIDScoped(ctx context.Context, accountID uuid.UUID, scope TransactionScope) ([]transactionModel, error) {
+	query := `
+		-- name: getTransactionsByAccountIDScoped
+		SELECT id, account_id, user_id, category_id, type, description, 
+			observation, amount_in_cents, due_date, metadata, paid_at,
+			original_amount_in_cents, original_currency, exchange_rate::text,
+			created_at, updated_at,
+			ARRAY(
+				SELECT tg.name FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+				WHERE tt.transaction_id = transactions.id ORDER BY tg.name
+			) AS tags
+		FROM transactions
+		WHERE account_id = $1
+			AND ($2::timestamptz IS NULL OR due_date >= $2)
+			AND ($3::timestamptz IS NULL OR due_date < $3)
+			AND (NOT $4 OR paid_at IS NULL)
+		ORDER BY due_date ASC, id ASC
+	`
+
+	var dueFrom, dueBefore *time.Time
+	if !scope.DueFrom.IsZero() {
+		dueFrom = &scope.DueFrom
+	}
+	if !scope.DueBefore.IsZero() {
+		dueBefore = &scope.DueBefore
+	}
+	return q.queryAccountTransactions(ctx, query, accountID, dueFrom, dueBefore, scope.UnpaidOnly)
+}
+
+// queryAccountTransactions runs a query selecting transactions of an account, decrypting their sensitive fields
+func (q *Querier) queryAccountTransactions(ctx context.Context, query string, args ...any) ([]transactionModel, error) {
+	rows, err := q.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("query transactions by account id: %v", err)
+		return nil, fmt.Errorf("query transactions by account id: %w", err)
 	}
 	defer rows.Close()
 
@@ -394,32 +1004,49 @@ func (q *Querier) getTransactionsByAccountID(ctx context.Context, accountID uuid
 			&m.DueDate,
 			&m.Metadata,
 			&m.PaidAt,
+			&m.OriginalAmount,
+			&m.OriginalCurrency,
+			&m.ExchangeRate,
 			&m.CreatedAt,
 			&m.UpdatedAt,
+			&m.Tags,
 		); err != nil {
-			return nil, fmt.Errorf("get transaction by account id: error scan transaction row: %v", err)
+			return nil, fmt.Errorf("get transaction by account id: error scan transaction row: %w", err)
+		}
+		if err := q.openTransaction(ctx, &m); err != nil {
+			return nil, err
 		}
 		transactions = append(transactions, m)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("get transaction by account id: error iterating transaction rows: %v", err)
+		return nil, fmt.Errorf("get transaction by account id: error iterating transaction rows: %w", err)
 	}
 
 	return transactions, nil
 }
 
-// getAccountsByUserID retrieves a single account from the database by the user id
-func (q *Querier) getAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]accountModel, error) {
+// getAccountsByUserID retrieves the active accounts of a user ordered by name, or the archived ones the most recently archived first
+func (q *Querier) getAccountsByUserID(ctx context.Context, userID uuid.UUID, archived bool) ([]accountModel, error) {
 	query := `
-		SELECT id, user_id, name, include_in_overall_balance, archived_at, created_at, updated_at
+		-- name: getAccountsByUserID
+		SELECT id, user_id, name, currency, kind, statement_closing_day, include_in_overall_balance, archived_at, delete_after, version, created_at, updated_at
 		FROM accounts
 		WHERE user_id = $1 AND archived_at IS NULL
 		ORDER BY name ASC
 	`
+	if archived {
+		query = `
+			-- name: getArchivedAccountsByUserID
+			SELECT id, user_id, name, currency, kind, statement_closing_day, include_in_overall_balance, archived_at, delete_after, version, created_at, updated_at
+			FROM accounts
+			WHERE user_id = $1 AND archived_at IS NOT NULL
+			ORDER BY archived_at DESC, name ASC
+		`
+	}
 
 	rows, err := q.db.Query(ctx, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("query accounts by user id: %v", err)
+		return nil, fmt.Errorf("query accounts by user id: %w", err)
 	}
 	defer rows.Close()
 
@@ -430,17 +1057,22 @@ func (q *Querier) getAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]
 			&m.ID,
 			&m.UserID,
 			&m.Name,
+			&m.Currency,
+			&m.Kind,
+			&m.StatementClosingDay,
 			&m.IncludeInOverallBalance,
 			&m.ArchivedAt,
+			&m.DeleteAfter,
+			&m.Version,
 			&m.CreatedAt,
 			&m.UpdatedAt,
 		); err != nil {
-			return nil, fmt.Errorf("get accounts by user id: error scan transaction row: %v", err)
+			return nil, fmt.Errorf("get accounts by user id: error scan transaction row: %w", err)
 		}
 		accounts = append(accounts, m)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("get account by user id: error iterating transaction rows: %v", err)
+		return nil, fmt.Errorf("get account by user id: error iterating transaction rows: %w", err)
 	}
 
 	return accounts, nil
@@ -449,17 +1081,23 @@ func (q *Querier) getAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]
 // getTransactionsByUserID retrieves all transactions for a given account ID
 func (q *Querier) getTransactionsByUserID(ctx context.Context, userID uuid.UUID) ([]transactionModel, error) {
 	query := `
+		-- name: getTransactionsByUserID
 		SELECT id, account_id, user_id, category_id, type, description, 
 			observation, amount_in_cents, due_date, metadata, paid_at,
-			created_at, updated_at
+			original_amount_in_cents, original_currency, exchange_rate::text,
+			created_at, updated_at,
+			ARRAY(
+				SELECT tg.name FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+				WHERE tt.transaction_id = transactions.id ORDER BY tg.name
+			) AS tags
 		FROM transactions
 		WHERE user_id = $1
-		ORDER BY due_date ASC
+		ORDER BY due_date ASC, id ASC
 	`
 
 	rows, err := q.db.Query(ctx, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("query transactions by user id: %v", err)
+		return nil, fmt.Errorf("query transactions by user id: %w", err)
 	}
 	defer rows.Close()
 
@@ -478,15 +1116,22 @@ func (q *Querier) getTransactionsByUserID(ctx context.Context, userID uuid.UUID)
 			&m.DueDate,
 			&m.Metadata,
 			&m.PaidAt,
+			&m.OriginalAmount,
+			&m.OriginalCurrency,
+			&m.ExchangeRate,
 			&m.CreatedAt,
 			&m.UpdatedAt,
+			&m.Tags,
 		); err != nil {
-			return nil, fmt.Errorf("get transaction by user id: error scan transaction row: %v", err)
+			return nil, fmt.Errorf("get transaction by user id: error scan transaction row: %w", err)
+		}
+		if err := q.openTransaction(ctx, &m); err != nil {
+			return nil, err
 		}
 		transactions = append(transactions, m)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("get transaction by user id: error iterating transaction rows: %v", err)
+		return nil, fmt.Errorf("get transaction by user id: error iterating transaction rows: %w", err)
 	}
 
 	return transactions, nil
@@ -1,12 +1,22 @@
 package ledger
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/boleto"
 	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -18,9 +28,66 @@ type AddTransactionParams struct {
 	Type        TransactionType
 	Description string
 	Observation string
-	Amount      int64
-	DueDate     time.Time
-	PaidAt      *time.Time
+	Amount      string // Amount as a decimal string, parsed using the account currency (e.g., "1.234,56")
+	// AmountMinorUnits is the amount in minor units of the account currency, used when Amount is empty (version 1 bodies)
+	AmountMinorUnits int64
+	DueDate          time.Time
+	PaidAt           *time.Time
+
+	// OriginalAmount and OriginalCurrency are set for transactions made in another currency (e.g., "-10.00" USD)
+	OriginalAmount   string
+	OriginalCurrency string
+
+	Pix      *PixDetails // Pix holds the identifiers of a PIX transfer, for reconciling it with the bank
+	Clearing *Clearing   // Clearing is set for cheques and TEDs, whose funds become available after they are paid
+	Location *Location   // Location is where the transaction was made, set by the mobile app
+	Tags     []string
+}
+
+// SetTransactionTagsParams holds all the required data for the SetTransactionTags use case
+type SetTransactionTagsParams struct {
+	AccountID     uuid.UUID
+	UserID        uuid.UUID
+	TransactionID uuid.UUID
+	Tags          []string // Tags replace the ones the transaction had, an empty list removing them
+}
+
+// SetTransactionClearingParams holds all the required data for the SetTransactionClearing use case
+type SetTransactionClearingParams struct {
+	AccountID     uuid.UUID
+	UserID        uuid.UUID
+	TransactionID uuid.UUID
+	Clearing      *Clearing // Clearing replaces the one the transaction had, nil removing it
+}
+
+// SetTransactionLocationParams holds all the required data for the SetTransactionLocation use case
+type SetTransactionLocationParams struct {
+	AccountID     uuid.UUID
+	UserID        uuid.UUID
+	TransactionID uuid.UUID
+	Location      *Location // Location replaces the one the transaction had, nil removing it
+}
+
+// FindLocatedTransactionsParams holds all the required data for the FindLocatedTransactions use case
+type FindLocatedTransactionsParams struct {
+	UserID uuid.UUID
+	Near   *Location // Near keeps the transactions made within RadiusMeters of it, nil keeping every located transaction
+	// RadiusMeters bounds the distance to Near
+	RadiusMeters float64
+}
+
+// AddBoletoTransactionParams holds all the required data for the AddBoletoTransaction use case
+type AddBoletoTransactionParams struct {
+	AccountID     uuid.UUID
+	UserID        uuid.UUID
+	CategoryID    *uuid.UUID
+	Description   string
+	Observation   string
+	DigitableLine string
+
+	// Amount and DueDate are required when the boleto leaves them out, and override the ones it carries otherwise
+	Amount  string // Amount as a positive decimal string in reais (e.g., "1.234,56")
+	DueDate *time.Time
 }
 
 // UpdateAccountParams hols all the required data for UpdateAccount use case
@@ -35,63 +102,603 @@ type UpdateAccountParams struct {
 type BalanceAdjustmentParams struct {
 	AccountID  uuid.UUID
 	UserID     uuid.UUID
-	NewBalance int64
+	NewBalance int64 // NewBalance in minor units of the account currency, unless NewBalanceDecimal is set
+	// NewBalanceDecimal is the new balance as a decimal string, parsed using the account currency (e.g., "1.234,56")
+	NewBalanceDecimal string
+}
+
+// PayStatementParams holds all the required data for the PayCreditCardStatement use case
+type PayStatementParams struct {
+	CardAccountID    uuid.UUID
+	PaymentAccountID uuid.UUID // PaymentAccountID is the checking account the statement is paid from
+	UserID           uuid.UUID
+	Year             int
+	Month            time.Month // Month is the month the statement closes on
+	PaidAt           time.Time
+}
+
+// ImportTransactionsParams holds all the required data for the ImportTransactions use case
+type ImportTransactionsParams struct {
+	AccountID    uuid.UUID
+	UserID       uuid.UUID
+	Source       string // Source names where the transactions come from in the audit record (e.g., "ynab")
+	Transactions []ImportedTransaction
+}
+
+// ImportedTransaction is a transaction read from another application, its type follows the sign of the amount
+type ImportedTransaction struct {
+	ID          uuid.UUID // ID is chosen by the caller when set, so importing the same transaction twice fails with ErrTransactionAlreadyExists
+	CategoryID  *uuid.UUID
+	Description string
+	Observation string
+	Amount      string // Amount as a decimal string, parsed using the account currency (e.g., "-1.234,56")
+	Date        time.Time
+	Pix         *PixDetails
+}
+
+// MarkTransactionsPaidParams holds all the required data for the MarkTransactionsAsPaid use case
+type MarkTransactionsPaidParams struct {
+	AccountID uuid.UUID
+	UserID    uuid.UUID
+	Source    string // Source names who settled the transactions in the audit record (e.g., "pluggy")
+	Payments  []TransactionPayment
+}
+
+// TransactionPayment is the date a pending transaction of the account was paid at
+type TransactionPayment struct {
+	TransactionID uuid.UUID
+	PaidAt        time.Time
 }
 
 // Service encapsulates the application's business logic (use cases) for the ledger module
 type Service struct {
-	accountRepo AccountRepository
-	clock       clock.Clock
+	accountRepo   AccountRepository
+	auditor       *audit.Logger
+	metrics       *Metrics
+	deletionGrace time.Duration // deletionGrace is how long a deleted account can be restored before being deleted for good
+	maxActive     int           // maxActive is the soft cap of active transactions per account, past which archiving is suggested
+	operations    *OperationLog // operations are the recent mutations the users can undo, nil disabling undo
+	access        AccountAccess // access tells the accounts shared with a user apart, nil hiding every account of others
+	ids           idgen.Generator
+	clock         clock.Clock
+	listeners     []TransactionListener
 }
 
 // NewService creates a new instance of the ledger Service
-func NewLedgerService(accRepo AccountRepository, clock clock.Clock) *Service {
+// Past maxActive transactions in an account, archiving its oldest history is suggested, 0 never suggesting it
+func NewLedgerService(accRepo AccountRepository, auditor *audit.Logger, metrics *Metrics, deletionGrace time.Duration, maxActive int, operations *OperationLog, access AccountAccess, ids idgen.Generator, clock clock.Clock, listeners ...TransactionListener) *Service {
 	return &Service{
-		accountRepo: accRepo,
-		clock:       clock,
+		accountRepo:   accRepo,
+		auditor:       auditor,
+		metrics:       metrics,
+		deletionGrace: deletionGrace,
+		maxActive:     maxActive,
+		operations:    operations,
+		access:        access,
+		ids:           ids,
+		clock:         clock,
+		listeners:     listeners,
 	}
 }
 
 // CreateAccount is the use case for creating a new account
-func (s *Service) CreateAccount(ctx context.Context, userID uuid.UUID, name string, includeInBalance bool) (*Account, error) {
-	account, err := NewAccount(userID, name, includeInBalance)
+func (s *Service) CreateAccount(ctx context.Context, userID uuid.UUID, name, currency string, includeInBalance bool) (*Account, error) {
+	account, err := NewAccount(userID, name, currency, includeInBalance, s.ids)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new account: %w", err)
 	}
 
+	return s.saveNewAccount(ctx, account)
+}
+
+// CreateCreditCardAccount is the use case for creating a new credit card account
+func (s *Service) CreateCreditCardAccount(ctx context.Context, userID uuid.UUID, name, currency string, closingDay int, includeInBalance bool) (*Account, error) {
+	account, err := NewCreditCardAccount(userID, name, currency, closingDay, includeInBalance, s.ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new credit card account: %w", err)
+	}
+
+	return s.saveNewAccount(ctx, account)
+}
+
+// AddTransactionToAccount is the use case for adding a new transaction to an existing account, returning the archival
+// to suggest when the account holds too many active transactions, nil otherwise
+// The account is locked from loading to saving, so concurrent additions are applied one after the other
+func (s *Service) AddTransactionToAccount(ctx context.Context, params AddTransactionParams) (*ArchivalSuggestion, error) {
+	account, err := s.updateAccount(ctx, params.UserID, params.AccountID, func(account *Account) error {
+		var err error
+		amount := money.New(params.AmountMinorUnits, account.Currency)
+		if params.Amount != "" {
+			if amount, err = money.Parse(params.Amount, account.Currency); err != nil {
+				return fmt.Errorf("failed to parse transaction amount: %w", err)
+			}
+		}
+
+		if params.OriginalCurrency != "" {
+			original, err := money.Parse(params.OriginalAmount, params.OriginalCurrency)
+			if err != nil {
+				return fmt.Errorf("failed to parse transaction original amount: %w", err)
+			}
+			err = account.AddForeignCurrencyTransaction(
+				params.Type,
+				params.Description,
+				params.Observation,
+				amount,
+				original,
+				params.CategoryID,
+				params.DueDate,
+				params.PaidAt,
+				s.ids,
+				s.clock,
+			)
+		} else {
+			err = account.AddTransaction(
+				params.Type,
+				params.Description,
+				params.Observation,
+				amount,
+				params.CategoryID,
+				params.DueDate,
+				params.PaidAt,
+				s.ids,
+				s.clock,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add transaction: %w", err)
+		}
+
+		txs := account.Transactions()
+		if params.Pix != nil {
+			if err := account.AttachPix(txs[len(txs)-1].ID, *params.Pix); err != nil {
+				return fmt.Errorf("failed to attach PIX details to transaction: %w", err)
+			}
+		}
+		if params.Clearing != nil {
+			if err := account.SetTransactionClearing(txs[len(txs)-1].ID, params.Clearing); err != nil {
+				return fmt.Errorf("failed to set transaction clearing: %w", err)
+			}
+		}
+		if params.Location != nil {
+			if err := account.SetTransactionLocation(txs[len(txs)-1].ID, params.Location); err != nil {
+				return fmt.Errorf("failed to set transaction location: %w", err)
+			}
+		}
+		if len(params.Tags) > 0 {
+			if err := account.SetTransactionTags(txs[len(txs)-1].ID, params.Tags); err != nil {
+				return fmt.Errorf("failed to tag transaction: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordAddedTransaction(ctx, account)
+	return s.SuggestArchival(account), nil
+}
+
+// SetTransactionTags is the use case for replacing the tags of a transaction, returning the tagged transaction
+func (s *Service) SetTransactionTags(ctx context.Context, params SetTransactionTagsParams) (Transaction, error) {
+	account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to find account to tag transaction: %w", err)
+	}
+
+	before, err := account.findTransaction(params.TransactionID)
+	if err != nil {
+		return Transaction{}, err
+	}
+	beforeState := toTransactionAuditState(account.ID, *before)
+
+	if err := account.SetTransactionTags(params.TransactionID, params.Tags); err != nil {
+		return Transaction{}, fmt.Errorf("failed to tag transaction: %w", err)
+	}
+
 	if err := s.accountRepo.Save(ctx, account); err != nil {
-		return nil, fmt.Errorf("failed to save new account: %w", err)
+		return Transaction{}, fmt.Errorf("failed to save account after tagging transaction: %w", err)
 	}
 
-	return account, nil
+	tagged, _ := account.findTransaction(params.TransactionID)
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditTransactionTagged,
+		ResourceType: auditResourceTransaction,
+		ResourceID:   tagged.ID.String(),
+		Before:       beforeState,
+		After:        toTransactionAuditState(account.ID, *tagged),
+	})
+
+	return *tagged, nil
 }
 
-// AddTransactionToAccount is the use case for adding a new transaction to an existing account
-func (s *Service) AddTransactionToAccount(ctx context.Context, params AddTransactionParams) error {
+// SetTransactionClearing is the use case for setting when the funds of a cheque or a TED become available,
+// returning the updated transaction
+func (s *Service) SetTransactionClearing(ctx context.Context, params SetTransactionClearingParams) (Transaction, error) {
 	account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
 	if err != nil {
-		return fmt.Errorf("failed to find account to add transaction: %w", err)
+		return Transaction{}, fmt.Errorf("failed to find account to set transaction clearing: %w", err)
+	}
+
+	before, err := account.findTransaction(params.TransactionID)
+	if err != nil {
+		return Transaction{}, err
 	}
+	beforeState := toTransactionAuditState(account.ID, *before)
 
-	err = account.AddTransaction(
-		params.Type,
-		params.Description,
-		params.Observation,
-		params.Amount,
-		params.CategoryID,
-		params.DueDate,
+	if err := account.SetTransactionClearing(params.TransactionID, params.Clearing); err != nil {
+		return Transaction{}, fmt.Errorf("failed to set transaction clearing: %w", err)
+	}
+
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return Transaction{}, fmt.Errorf("failed to save account after setting transaction clearing: %w", err)
+	}
+
+	updated, _ := account.findTransaction(params.TransactionID)
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditTransactionClearingSet,
+		ResourceType: auditResourceTransaction,
+		ResourceID:   updated.ID.String(),
+		Before:       beforeState,
+		After:        toTransactionAuditState(account.ID, *updated),
+	})
+
+	return *updated, nil
+}
+
+// SetTransactionLocation is the use case for setting where a transaction was made, returning the updated transaction
+func (s *Service) SetTransactionLocation(ctx context.Context, params SetTransactionLocationParams) (Transaction, error) {
+	account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to find account to set transaction location: %w", err)
+	}
+
+	before, err := account.findTransaction(params.TransactionID)
+	if err != nil {
+		return Transaction{}, err
+	}
+	beforeState := toTransactionAuditState(account.ID, *before)
+
+	if err := account.SetTransactionLocation(params.TransactionID, params.Location); err != nil {
+		return Transaction{}, fmt.Errorf("failed to set transaction location: %w", err)
+	}
+
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return Transaction{}, fmt.Errorf("failed to save account after setting transaction location: %w", err)
+	}
+
+	updated, _ := account.findTransaction(params.TransactionID)
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditTransactionLocationSet,
+		ResourceType: auditResourceTransaction,
+		ResourceID:   updated.ID.String(),
+		Before:       beforeState,
+		After:        toTransactionAuditState(account.ID, *updated),
+	})
+
+	return *updated, nil
+}
+
+// FindLocatedTransactions is the use case for listing the transactions of the user made at a known place, for the spending map
+// The locations are stored encrypted along with the other transaction details, so they are filtered once the accounts are read
+// Transactions near a point come closest first, the others most recent first
+func (s *Service) FindLocatedTransactions(ctx context.Context, params FindLocatedTransactionsParams) ([]LocatedTransaction, error) {
+	if params.Near != nil {
+		near, err := params.Near.normalize()
+		if err != nil {
+			return nil, err
+		}
+		params.Near = &near
+	}
+
+	accounts, err := s.FindAccountsByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	located := make([]LocatedTransaction, 0)
+	for _, account := range accounts {
+		for _, tx := range account.Transactions() {
+			if tx.Location == nil {
+				continue
+			}
+			item := LocatedTransaction{AccountID: account.ID, Transaction: tx}
+			if params.Near != nil {
+				distance := tx.Location.DistanceTo(params.Near.Latitude, params.Near.Longitude)
+				if distance > params.RadiusMeters {
+					continue
+				}
+				item.DistanceMeters = &distance
+			}
+			located = append(located, item)
+		}
+	}
+
+	slices.SortStableFunc(located, func(a, b LocatedTransaction) int {
+		if a.DistanceMeters != nil && b.DistanceMeters != nil {
+			return cmp.Compare(*a.DistanceMeters, *b.DistanceMeters)
+		}
+		return b.Transaction.DueDate.Compare(a.Transaction.DueDate)
+	})
+	return located, nil
+}
+
+// ParseBoleto is the use case for reading the amount and due date of a boleto from its digitable line,
+// so clients can pre-fill the expense before adding it
+func (s *Service) ParseBoleto(digitableLine string) (*boleto.Boleto, error) {
+	return boleto.Parse(digitableLine, s.clock.Now())
+}
+
+// AddBoletoTransaction is the use case for adding the unpaid expense of a boleto to an account in reais,
+// its amount and due date read from the digitable line unless informed
+func (s *Service) AddBoletoTransaction(ctx context.Context, params AddBoletoTransactionParams) (Transaction, error) {
+	parsed, err := s.ParseBoleto(params.DigitableLine)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to parse boleto digitable line: %w", err)
+	}
+
+	amount := money.New(parsed.Amount, boleto.Currency)
+	if params.Amount != "" {
+		if amount, err = money.Parse(params.Amount, boleto.Currency); err != nil {
+			return Transaction{}, fmt.Errorf("failed to parse boleto amount: %w", err)
+		}
+		if amount.IsNegative() {
+			return Transaction{}, ErrInconsistentAmountSign
+		}
+	}
+	if amount.IsZero() {
+		return Transaction{}, ErrBoletoAmountRequired
+	}
+	// Boleto amounts are what is owed, expenses being negative
+	if amount, err = amount.Negate(); err != nil {
+		return Transaction{}, err
+	}
+
+	dueDate := parsed.DueDate
+	if params.DueDate != nil {
+		dueDate = params.DueDate
+	}
+	if dueDate == nil {
+		return Transaction{}, ErrBoletoDueDateRequired
+	}
+
+	account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to find account to add boleto transaction: %w", err)
+	}
+	err = account.AddTransaction(Expense, params.Description, params.Observation, amount, params.CategoryID, *dueDate, nil, s.ids, s.clock)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to add boleto transaction: %w", err)
+	}
+
+	return s.saveAddedTransaction(ctx, account)
+}
+
+// ImportTransactions is the use case for adding the transactions imported from another application in a single save
+// Transactions dated up to now are imported as paid, later ones as pending
+// Listeners are not notified, so importing past months does not raise budget alerts about them
+// The imported transactions are returned in the order they were given
+func (s *Service) ImportTransactions(ctx context.Context, params ImportTransactionsParams) ([]Transaction, error) {
+	account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account to import transactions: %w", err)
+	}
+
+	now := s.clock.Now()
+	for i, imported := range params.Transactions {
+		amount, err := money.Parse(imported.Amount, account.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse imported transaction %d amount: %w", i+1, err)
+		}
+
+		txType := Income
+		if amount.IsNegative() {
+			txType = Expense
+		}
+		var paidAt *time.Time
+		if !imported.Date.After(now) {
+			paidAt = &imported.Date
+		}
+
+		txID := imported.ID
+		if txID == uuid.Nil {
+			txID = s.ids.NewID()
+		}
+		err = account.AddTransactionWithID(txID, txType, imported.Description, imported.Observation, amount, imported.CategoryID, imported.Date, paidAt, s.clock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add imported transaction %d: %w", i+1, err)
+		}
+		if imported.Pix != nil {
+			if err := account.AttachPix(txID, *imported.Pix); err != nil {
+				return nil, fmt.Errorf("failed to attach PIX details to imported transaction %d: %w", i+1, err)
+			}
+		}
+	}
+
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save account after importing transactions: %w", err)
+	}
+
+	txs := account.Transactions()
+	imported := txs[len(txs)-len(params.Transactions):]
+	for _, tx := range imported {
+		s.metrics.transactionsCreated.Inc(string(tx.Type))
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditTransactionsImported,
+		ResourceType: auditResourceAccount,
+		ResourceID:   account.ID.String(),
+		Metadata: map[string]string{
+			"source":   params.Source,
+			"imported": strconv.Itoa(len(params.Transactions)),
+		},
+	})
+
+	return imported, nil
+}
+
+// MarkTransactionsAsPaid is the use case for settling pending transactions of an account in a single save
+// (e.g., the scheduled transactions a bank sync found posted), the ones already paid are left as they are
+func (s *Service) MarkTransactionsAsPaid(ctx context.Context, params MarkTransactionsPaidParams) (int, error) {
+	account, err := s.accountRepo.FindByIDScoped(ctx, params.AccountID, TransactionScope{UnpaidOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find account to mark transactions as paid: %w", err)
+	}
+	if account.UserID != params.UserID {
+		return 0, s.denyAccess(ctx, params.UserID, params.AccountID)
+	}
+
+	paid := 0
+	for _, payment := range params.Payments {
+		// Only the unpaid transactions are loaded, a transaction paid meanwhile being skipped like an already paid one
+		err := account.MarkTransactionAsPaid(payment.TransactionID, payment.PaidAt, s.clock)
+		if errors.Is(err, ErrTransactionAlreadyPaid) || errors.Is(err, ErrTransactionNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark transaction %s as paid: %w", payment.TransactionID, err)
+		}
+		paid++
+	}
+	if paid == 0 {
+		return 0, nil
+	}
+
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return 0, fmt.Errorf("failed to save account after marking transactions as paid: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditTransactionsPaid,
+		ResourceType: auditResourceAccount,
+		ResourceID:   account.ID.String(),
+		Metadata: map[string]string{
+			"source": params.Source,
+			"paid":   strconv.Itoa(paid),
+		},
+	})
+
+	return paid, nil
+}
+
+// CreateInvestmentAccount is the use case for creating a new investment account
+func (s *Service) CreateInvestmentAccount(ctx context.Context, userID uuid.UUID, name, currency string, includeInBalance bool) (*Account, error) {
+	account, err := NewInvestmentAccount(userID, name, currency, includeInBalance, s.ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new investment account: %w", err)
+	}
+
+	return s.saveNewAccount(ctx, account)
+}
+
+// CreateSavingsAccount is the use case for creating a new savings account
+func (s *Service) CreateSavingsAccount(ctx context.Context, userID uuid.UUID, name, currency string, includeInBalance bool) (*Account, error) {
+	account, err := NewSavingsAccount(userID, name, currency, includeInBalance, s.ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new savings account: %w", err)
+	}
+
+	return s.saveNewAccount(ctx, account)
+}
+
+// PayCreditCardStatement is the use case for paying a credit card statement from a checking account
+// The card expenses are marked as paid and the matching expense is added to the checking account in a single save
+func (s *Service) PayCreditCardStatement(ctx context.Context, params PayStatementParams) (*Account, error) {
+	card, err := s.FindAccountByID(ctx, params.UserID, params.CardAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find credit card account: %w", err)
+	}
+	payment, err := s.FindAccountByID(ctx, params.UserID, params.PaymentAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find statement payment account: %w", err)
+	}
+	if payment.Kind != Checking {
+		return nil, ErrPaymentAccountNotChecking.With("account_id", payment.ID)
+	}
+	cardBefore := toAccountAuditState(card)
+	var unpaid []uuid.UUID
+	for _, tx := range card.Transactions() {
+		if tx.PaidAt == nil {
+			unpaid = append(unpaid, tx.ID)
+		}
+	}
+
+	total, err := card.PayStatement(params.Year, params.Month, params.PaidAt, s.ids, s.clock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pay statement: %w", err)
+	}
+	amount, err := total.Negate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute statement payment amount: %w", err)
+	}
+
+	period := fmt.Sprintf("%04d-%02d", params.Year, params.Month)
+	err = payment.AddTransaction(
+		Expense,
+		fmt.Sprintf("Fatura %s %s", card.Name, period),
+		"",
+		amount,
+		nil,
 		params.PaidAt,
+		&params.PaidAt,
+		s.ids,
 		s.clock,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to add transaction: %w", err)
+		return nil, fmt.Errorf("failed to add statement payment: %w", err)
 	}
 
-	if err := s.accountRepo.Save(ctx, account); err != nil {
-		return fmt.Errorf("failed to save account after adding transaction: %w", err)
+	if err := s.accountRepo.SaveAll(ctx, card, payment); err != nil {
+		return nil, fmt.Errorf("failed to save statement payment: %w", err)
 	}
 
-	return nil
+	cardTxs := card.Transactions()
+	credit := cardTxs[len(cardTxs)-1]
+	txs := payment.Transactions()
+	tx := txs[len(txs)-1]
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditAccountStatementPaid,
+		ResourceType: auditResourceAccount,
+		ResourceID:   card.ID.String(),
+		Before:       cardBefore,
+		After:        toAccountAuditState(card),
+		Metadata: map[string]string{
+			"period":                 period,
+			"payment_account_id":     payment.ID.String(),
+			"payment_transaction_id": tx.ID.String(),
+			"credit_transaction_id":  credit.ID.String(),
+		},
+	})
+	var paid []uuid.UUID
+	for _, txID := range unpaid {
+		if settled, _ := card.findTransaction(txID); settled.PaidAt != nil {
+			paid = append(paid, txID)
+		}
+	}
+	s.operations.record(params.UserID, OperationStatementPaid, card.ID,
+		compensation{accountID: card.ID, removeTransactions: []uuid.UUID{credit.ID}, unpayTransactions: paid},
+		compensation{accountID: payment.ID, removeTransactions: []uuid.UUID{tx.ID}},
+	)
+	for _, created := range []struct {
+		account *Account
+		tx      Transaction
+	}{{payment, tx}, {card, credit}} {
+		s.auditor.Record(ctx, audit.Entry{
+			Action:       auditTransactionCreated,
+			ResourceType: auditResourceTransaction,
+			ResourceID:   created.tx.ID.String(),
+			After:        toTransactionAuditState(created.account.ID, created.tx),
+		})
+		s.metrics.transactionsCreated.Inc(string(created.tx.Type))
+
+		for _, listener := range s.listeners {
+			listener.TransactionAdded(ctx, created.account, created.tx)
+		}
+	}
+
+	return card, nil
 }
 
 // UpdateAccount is the use case for update an existing account
@@ -100,6 +707,7 @@ func (s *Service) UpdateAccount(ctx context.Context, params UpdateAccountParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find account to update: %w", err)
 	}
+	before := toAccountAuditState(account)
 
 	if params.Name != nil {
 		if err := account.ChangeName(*params.Name); err != nil {
@@ -127,6 +735,8 @@ func (s *Service) UpdateAccount(ctx context.Context, params UpdateAccountParams)
 		return nil, fmt.Errorf("failed to update account: %w", err)
 	}
 
+	s.auditAccount(ctx, auditAccountUpdated, account.ID, before, toAccountAuditState(account))
+
 	return account, nil
 }
 
@@ -136,6 +746,7 @@ func (s *Service) ArchiveAccount(ctx context.Context, userID, accountID uuid.UUI
 	if err != nil {
 		return fmt.Errorf("failed to find account to archive: %w", err)
 	}
+	before := toAccountAuditState(account)
 
 	if err := account.Archive(s.clock); err != nil {
 		return fmt.Errorf("failed to archive account: %w", err)
@@ -145,6 +756,9 @@ func (s *Service) ArchiveAccount(ctx context.Context, userID, accountID uuid.UUI
 		return fmt.Errorf("failed to save archived account state: %w", err)
 	}
 
+	s.auditAccount(ctx, auditAccountArchived, account.ID, before, toAccountAuditState(account))
+	s.metrics.accountsArchived.Inc()
+
 	return nil
 }
 
@@ -154,6 +768,7 @@ func (s *Service) UnarchiveAccount(ctx context.Context, userID, accountID uuid.U
 	if err != nil {
 		return nil, err
 	}
+	before := toAccountAuditState(account)
 
 	if err := account.Unarchive(); err != nil {
 		return nil, fmt.Errorf("failed to unarchive the account: %w", err)
@@ -163,27 +778,223 @@ func (s *Service) UnarchiveAccount(ctx context.Context, userID, accountID uuid.U
 		return nil, fmt.Errorf("failed to save unarchived account: %w", err)
 	}
 
+	s.auditAccount(ctx, auditAccountUnarchived, account.ID, before, toAccountAuditState(account))
+	s.metrics.accountsUnarchived.Inc()
+
 	return account, nil
 }
 
-// AdjustAccountBalance is the use case for adjust the balance of an existing accoutn
-func (s *Service) AdjustAccountBalance(ctx context.Context, params BalanceAdjustmentParams) (*Account, error) {
-	account, err := s.FindAccountByID(ctx, params.UserID, params.AccountID)
+// ScheduleAccountDeletion is the use case for deleting an account, which is deleted for good once the grace period elapsed
+func (s *Service) ScheduleAccountDeletion(ctx context.Context, userID, accountID uuid.UUID) (*Account, error) {
+	account, err := s.FindAccountByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account to delete: %w", err)
+	}
+	before := toAccountAuditState(account)
+
+	if err := account.ScheduleDeletion(s.deletionGrace, s.clock); err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save account scheduled for deletion: %w", err)
+	}
+
+	s.auditAccount(ctx, auditAccountDeletionScheduled, account.ID, before, toAccountAuditState(account))
+	s.metrics.accountDeletionsScheduled.Inc()
+	s.operations.record(userID, OperationAccountDeleted, account.ID, compensation{accountID: account.ID, cancelDeletion: true})
+
+	return account, nil
+}
+
+// CancelAccountDeletion is the use case for restoring an account whose deletion grace period did not elapse yet
+func (s *Service) CancelAccountDeletion(ctx context.Context, userID, accountID uuid.UUID) (*Account, error) {
+	account, err := s.FindAccountByID(ctx, userID, accountID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find account for balance adjustment: %w", err)
+		return nil, err
 	}
+	before := toAccountAuditState(account)
 
-	if err := account.AdjustBalance(params.NewBalance, s.clock); err != nil {
-		return nil, fmt.Errorf("failed to adjust account balance: %w", err)
+	if err := account.CancelDeletion(s.clock); err != nil {
+		return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
 	}
 
 	if err := s.accountRepo.Save(ctx, account); err != nil {
-		return nil, fmt.Errorf("failed to save the adjusted account balance: %w", err)
+		return nil, fmt.Errorf("failed to save account whose deletion was cancelled: %w", err)
+	}
+
+	s.auditAccount(ctx, auditAccountDeletionCancelled, account.ID, before, toAccountAuditState(account))
+	s.metrics.accountDeletionsCancelled.Inc()
+
+	return account, nil
+}
+
+// PurgeDeletedAccounts deletes for good the accounts whose deletion grace period elapsed, run by the scheduler
+// An account failing to be deleted is logged and retried on the next run, without holding back the others
+func (s *Service) PurgeDeletedAccounts(ctx context.Context) error {
+	accountIDs, err := s.accountRepo.FindAccountIDsDueForDeletion(ctx, s.clock.Now())
+	if err != nil {
+		return fmt.Errorf("failed to find accounts due for deletion: %w", err)
+	}
+
+	failed := 0
+	for _, accountID := range accountIDs {
+		if err := s.purgeAccount(ctx, accountID); err != nil {
+			failed++
+			ctxlogger.GetLogger(ctx).Error("failed to purge deleted account",
+				slog.String("account_id", accountID.String()),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if len(accountIDs) > 0 {
+		ctxlogger.GetLogger(ctx).Info("purged deleted accounts",
+			slog.Int("accounts", len(accountIDs)),
+			slog.Int("failed", failed),
+		)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to purge %d of %d deleted accounts", failed, len(accountIDs))
+	}
+	return nil
+}
+
+// purgeAccount deletes an account due for deletion for good, the account being reloaded so a cancelled deletion is kept
+func (s *Service) purgeAccount(ctx context.Context, accountID uuid.UUID) error {
+	account, err := s.accountRepo.FindByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	before := toAccountAuditState(account)
+
+	if err := account.Delete(s.clock); err != nil {
+		return err
+	}
+	if err := s.accountRepo.Delete(ctx, account); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditAccountDeleted,
+		ResourceType: auditResourceAccount,
+		ResourceID:   account.ID.String(),
+		Actor:        audit.SystemActor,
+		Before:       before,
+		Metadata:     map[string]string{"user_id": account.UserID.String()},
+	})
+	s.metrics.accountsDeleted.Inc()
+
+	return nil
+}
+
+// AdjustAccountBalance is the use case for adjust the balance of an existing accoutn
+// The adjustment is computed from the balance of the locked account, so a transaction added meanwhile is not lost
+func (s *Service) AdjustAccountBalance(ctx context.Context, params BalanceAdjustmentParams) (*Account, error) {
+	var before *accountAuditState
+	account, err := s.updateAccount(ctx, params.UserID, params.AccountID, func(account *Account) error {
+		before = toAccountAuditState(account)
+
+		newBalance := money.New(params.NewBalance, account.Currency)
+		if params.NewBalanceDecimal != "" {
+			var err error
+			if newBalance, err = money.Parse(params.NewBalanceDecimal, account.Currency); err != nil {
+				return err
+			}
+		}
+		if err := account.AdjustBalance(newBalance, s.ids, s.clock); err != nil {
+			return fmt.Errorf("failed to adjust account balance: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.auditAccount(ctx, auditAccountBalanceAdjusted, account.ID, before, toAccountAuditState(account))
+	s.metrics.balanceAdjustments.Inc()
+
 	return account, nil
 }
 
+// SuggestArchival returns the archival to suggest for a fully loaded account holding too many active transactions,
+// nil otherwise
+func (s *Service) SuggestArchival(account *Account) *ArchivalSuggestion {
+	return account.SuggestArchival(s.maxActive, s.clock.Now())
+}
+
+// ArchiveHistory is the use case for rolling the settled transactions of an account due before the given date into an
+// opening balance, moving them to the archived history so the account stays quick to load, returning the account and
+// the number of archived transactions. Archiving can not be undone
+func (s *Service) ArchiveHistory(ctx context.Context, userID, accountID uuid.UUID, before time.Time) (*Account, int, error) {
+	var archived int
+	account, err := s.updateAccount(ctx, userID, accountID, func(account *Account) error {
+		var err error
+		archived, err = account.ArchiveHistory(before, s.ids, s.clock)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditAccountHistoryArchived,
+		ResourceType: auditResourceAccount,
+		ResourceID:   account.ID.String(),
+		Metadata: map[string]string{
+			"before":                before.Format(time.RFC3339),
+			"archived_transactions": strconv.Itoa(archived),
+		},
+	})
+	return account, archived, nil
+}
+
+// Undo is the use case for reversing the last ledger mutation the user made within the undo window,
+// through the compensating domain operations, returning the operation undone
+// An operation the ledger changed since (e.g., its transaction was paid) is dropped without being undone
+func (s *Service) Undo(ctx context.Context, userID uuid.UUID) (Operation, error) {
+	operation, ok := s.operations.pop(userID)
+	if !ok {
+		return Operation{}, ErrNothingToUndo
+	}
+
+	accounts := make([]*Account, 0, len(operation.compensations))
+	for _, c := range operation.compensations {
+		account, err := s.FindAccountByID(ctx, userID, c.accountID)
+		if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrForbidden) {
+			domainErr, _ := errx.AsDomainError(err)
+			return Operation{}, ErrUndoConflict.With("operation_id", operation.ID).With("reason", domainErr.Code)
+		}
+		if err != nil {
+			return Operation{}, fmt.Errorf("failed to find account to undo operation: %w", err)
+		}
+		if err := c.apply(account, s.clock); err != nil {
+			conflict := ErrUndoConflict.With("operation_id", operation.ID)
+			if domainErr, ok := errx.AsDomainError(err); ok {
+				conflict = conflict.With("reason", domainErr.Code)
+			}
+			return Operation{}, conflict
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := s.accountRepo.SaveAll(ctx, accounts...); err != nil {
+		return Operation{}, fmt.Errorf("failed to save accounts after undoing operation: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditOperationUndone,
+		ResourceType: auditResourceAccount,
+		ResourceID:   operation.AccountID.String(),
+		Metadata: map[string]string{
+			"operation":   string(operation.Kind),
+			"occurred_at": operation.OccurredAt.Format(time.RFC3339),
+		},
+	})
+
+	return operation, nil
+}
+
 // FindAccountByID is the use case for finding a account by it's id
 func (s *Service) FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*Account, error) {
 	account, err := s.accountRepo.FindByID(ctx, accountID)
@@ -192,7 +1003,7 @@ func (s *Service) FindAccountByID(ctx context.Context, userID, accountID uuid.UU
 	}
 
 	if account.UserID != userID {
-		return nil, ErrAccountNotFound
+		return nil, s.denyAccess(ctx, userID, accountID)
 	}
 
 	return account, nil
@@ -207,3 +1018,105 @@ func (s *Service) FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([
 
 	return accounts, nil
 }
+
+// FindArchivedAccountsByUserID is the use case for listing the archived accounts of the user, which can still be restored
+func (s *Service) FindArchivedAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*Account, error) {
+	accounts, err := s.accountRepo.FindArchivedAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archived accounts by user id: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// updateAccount applies change to the account of the user and saves it, the account being locked against
+// concurrent updates from loading to saving
+func (s *Service) updateAccount(ctx context.Context, userID, accountID uuid.UUID, change func(account *Account) error) (*Account, error) {
+	denied := false
+	account, err := s.accountRepo.UpdateByID(ctx, accountID, func(account *Account) error {
+		if account.UserID != userID {
+			denied = true
+			return ErrAccountNotFound.With("account_id", accountID)
+		}
+		return change(account)
+	})
+	// The access is told once the lock is released, denyAccess querying outside of the transaction holding it
+	if denied {
+		return nil, s.denyAccess(ctx, userID, accountID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+	return account, nil
+}
+
+// denyAccess returns the error of a user asking for an account of someone else: ErrForbidden when the account is shared
+// with them, so they know it exists, and ErrAccountNotFound otherwise, never telling a stranger that it exists
+func (s *Service) denyAccess(ctx context.Context, userID, accountID uuid.UUID) error {
+	if s.access != nil {
+		shared, err := s.access.SharedWith(ctx, accountID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check the access to the account: %w", err)
+		}
+		if shared {
+			return ErrForbidden.With("account_id", accountID)
+		}
+	}
+	return ErrAccountNotFound.With("account_id", accountID)
+}
+
+// saveAddedTransaction saves the account the last transaction was added to, recording it and notifying the listeners
+func (s *Service) saveAddedTransaction(ctx context.Context, account *Account) (Transaction, error) {
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return Transaction{}, fmt.Errorf("failed to save account after adding transaction: %w", err)
+	}
+
+	return s.recordAddedTransaction(ctx, account), nil
+}
+
+// recordAddedTransaction records the last transaction added to the saved account and notifies the listeners
+func (s *Service) recordAddedTransaction(ctx context.Context, account *Account) Transaction {
+	txs := account.Transactions()
+	tx := txs[len(txs)-1]
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditTransactionCreated,
+		ResourceType: auditResourceTransaction,
+		ResourceID:   tx.ID.String(),
+		After:        toTransactionAuditState(account.ID, tx),
+	})
+	s.metrics.transactionsCreated.Inc(string(tx.Type))
+	s.operations.record(account.UserID, OperationTransactionCreated, account.ID, compensation{accountID: account.ID, removeTransactions: []uuid.UUID{tx.ID}})
+
+	for _, listener := range s.listeners {
+		listener.TransactionAdded(ctx, account, tx)
+	}
+
+	return tx
+}
+
+// saveNewAccount persists a freshly created account and records its creation
+func (s *Service) saveNewAccount(ctx context.Context, account *Account) (*Account, error) {
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save new account: %w", err)
+	}
+
+	s.auditAccount(ctx, auditAccountCreated, account.ID, nil, toAccountAuditState(account))
+	s.metrics.accountsCreated.Inc(account.Currency)
+
+	return account, nil
+}
+
+// auditAccount records an account mutation, after it was saved
+func (s *Service) auditAccount(ctx context.Context, action string, accountID uuid.UUID, before, after *accountAuditState) {
+	entry := audit.Entry{
+		Action:       action,
+		ResourceType: auditResourceAccount,
+		ResourceID:   accountID.String(),
+		After:        after,
+	}
+	// A nil pointer stored in the any field would be encoded as "null" instead of an absent state
+	if before != nil {
+		entry.Before = before
+	}
+	s.auditor.Record(ctx, entry)
+}
@@ -0,0 +1,95 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/google/uuid"
+)
+
+// sharedAccounts is an AccountAccess sharing each account with the users listed for it
+type sharedAccounts map[uuid.UUID][]uuid.UUID
+
+func (s sharedAccounts) SharedWith(_ context.Context, accountID, userID uuid.UUID) (bool, error) {
+	for _, id := range s[accountID] {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// discardSink drops the audit records of the tests
+type discardSink struct{}
+
+func (discardSink) Write(context.Context, audit.Record) error {
+	return nil
+}
+
+// newTestService creates a service on an in-memory repository, the accounts being shared as access tells
+func newTestService(access AccountAccess) *Service {
+	clk := clock.NewFixedClock(time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC))
+	return NewLedgerService(NewInMemoryAccountRepository(), audit.NewLogger(discardSink{}, clk), NewMetrics(metrics.NewRegistry()),
+		0, 0, nil, access, idgen.V7Generator{}, clk)
+}
+
+func TestDenyAccess(t *testing.T) {
+	memberID, strangerID := uuid.New(), uuid.New()
+	accountID := uuid.New()
+	access := sharedAccounts{accountID: {memberID}}
+
+	tests := []struct {
+		name   string
+		access AccountAccess
+		userID uuid.UUID
+		want   error
+	}{
+		{name: "shared account", access: access, userID: memberID, want: ErrForbidden},
+		{name: "unrelated account", access: access, userID: strangerID, want: ErrAccountNotFound},
+		{name: "no sharing", access: nil, userID: memberID, want: ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestService(tt.access).denyAccess(context.Background(), tt.userID, accountID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("denyAccess() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAccountByIDOfAnotherUser(t *testing.T) {
+	ownerID, memberID, strangerID := uuid.New(), uuid.New(), uuid.New()
+	access := sharedAccounts{}
+	svc := newTestService(access)
+
+	account, err := svc.CreateAccount(context.Background(), ownerID, "Conta corrente", "BRL", true)
+	if err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+	access[account.ID] = []uuid.UUID{memberID}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		want   error
+	}{
+		{name: "owner", userID: ownerID, want: nil},
+		{name: "member of the household", userID: memberID, want: ErrForbidden},
+		{name: "stranger", userID: strangerID, want: ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.FindAccountByID(context.Background(), tt.userID, account.ID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("FindAccountByID() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var _ DBQuerier = (*slowQueryQuerier)(nil)
+
+// SlowQueryLogger decorates a DBQuerier, logging and counting the statements slower than a threshold
+type SlowQueryLogger struct {
+	threshold time.Duration
+	counter   metrics.Counter
+}
+
+// NewSlowQueryLogger creates a new SlowQueryLogger, a zero threshold disables it
+func NewSlowQueryLogger(threshold time.Duration, provider metrics.Provider) *SlowQueryLogger {
+	return &SlowQueryLogger{
+		threshold: threshold,
+		counter:   provider.Counter("ledger_slow_queries_total", "Ledger queries slower than the slow query threshold, by query tag", "query"),
+	}
+}
+
+// Wrap returns db decorated with the slow query logging, or db itself when the logger is disabled
+func (l *SlowQueryLogger) Wrap(db DBQuerier) DBQuerier {
+	if l == nil || l.threshold <= 0 {
+		return db
+	}
+	return &slowQueryQuerier{db: db, log: l}
+}
+
+// observe reports the statement when it took longer than the threshold
+// rows is the number of rows returned or affected, -1 when unknown
+func (l *SlowQueryLogger) observe(ctx context.Context, sql string, start time.Time, rows int64, err error) {
+	elapsed := time.Since(start)
+	if elapsed < l.threshold {
+		return
+	}
+
+	tag := postgres.QueryTag(sql)
+	l.counter.Inc(tag)
+
+	attrs := []any{
+		slog.String("query", tag),
+		slog.Int64("duration_ms", elapsed.Milliseconds()),
+		slog.Int64("threshold_ms", l.threshold.Milliseconds()),
+		slog.Int64("rows", rows),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	ctxlogger.GetLogger(ctx).Warn("slow query", attrs...)
+}
+
+// slowQueryQuerier times every statement sent through the wrapped DBQuerier
+type slowQueryQuerier struct {
+	db  DBQuerier
+	log *SlowQueryLogger
+}
+
+func (q *slowQueryQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := q.db.Exec(ctx, sql, args...)
+	q.log.observe(ctx, sql, start, tag.RowsAffected(), err)
+	return tag, err
+}
+
+func (q *slowQueryQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := q.db.Query(ctx, sql, args...)
+	if err != nil {
+		q.log.observe(ctx, sql, start, -1, err)
+		return nil, err
+	}
+	return &slowQueryRows{Rows: rows, ctx: ctx, sql: sql, start: start, log: q.log}, nil
+}
+
+func (q *slowQueryQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	start := time.Now()
+	return &slowQueryRow{row: q.db.QueryRow(ctx, sql, args...), ctx: ctx, sql: sql, start: start, log: q.log}
+}
+
+func (q *slowQueryQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	sql := ""
+	if len(b.QueuedQueries) > 0 {
+		sql = b.QueuedQueries[0].SQL
+	}
+	start := time.Now()
+	return &slowQueryBatch{BatchResults: q.db.SendBatch(ctx, b), ctx: ctx, sql: sql, start: start, log: q.log}
+}
+
+// slowQueryRows reports the query once its rows are closed, as they are streamed while iterating
+type slowQueryRows struct {
+	pgx.Rows
+	ctx      context.Context
+	sql      string
+	start    time.Time
+	log      *SlowQueryLogger
+	reported bool
+}
+
+func (r *slowQueryRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.report()
+	return false
+}
+
+func (r *slowQueryRows) Close() {
+	r.Rows.Close()
+	r.report()
+}
+
+func (r *slowQueryRows) report() {
+	if r.reported {
+		return
+	}
+	r.reported = true
+	r.log.observe(r.ctx, r.sql, r.start, r.Rows.CommandTag().RowsAffected(), r.Rows.Err())
+}
+
+// slowQueryRow reports the query once its row is scanned
+type slowQueryRow struct {
+	row   pgx.Row
+	ctx   context.Context
+	sql   string
+	start time.Time
+	log   *SlowQueryLogger
+}
+
+func (r *slowQueryRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+
+	rows := int64(1)
+	if err != nil {
+		rows = 0
+	}
+	reportErr := err
+	if errors.Is(err, pgx.ErrNoRows) {
+		reportErr = nil
+	}
+
+	r.log.observe(r.ctx, r.sql, r.start, rows, reportErr)
+	return err
+}
+
+// slowQueryBatch reports the whole batch once it is closed, tagged by its first statement
+type slowQueryBatch struct {
+	pgx.BatchResults
+	ctx   context.Context
+	sql   string
+	start time.Time
+	log   *SlowQueryLogger
+}
+
+func (b *slowQueryBatch) Close() error {
+	err := b.BatchResults.Close()
+	b.log.observe(b.ctx, b.sql, b.start, -1, err)
+	return err
+}
@@ -0,0 +1,156 @@
+package ledger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNothingToUndo = errx.New(errx.CategoryNotFound, "NOTHING_TO_UNDO", "there is no recent operation to undo")
+	ErrUndoConflict  = errx.New(errx.CategoryConflict, "UNDO_CONFLICT", "the operation can no longer be undone, the ledger changed since")
+)
+
+const (
+	OperationTransactionCreated OperationKind = "TRANSACTION_CREATED"
+	OperationAccountDeleted     OperationKind = "ACCOUNT_DELETED"
+	OperationStatementPaid      OperationKind = "STATEMENT_PAID"
+
+	// maxOperationsPerUser bounds the log of each user, the oldest operations being forgotten first
+	maxOperationsPerUser = 20
+)
+
+// OperationKind names a ledger mutation that can be undone
+type OperationKind string
+
+// Operation is a recent ledger mutation of a user, with the compensating operations reversing it
+type Operation struct {
+	ID            uuid.UUID
+	Kind          OperationKind
+	AccountID     uuid.UUID // AccountID is the account the mutation was made on (e.g., the card of a paid statement)
+	OccurredAt    time.Time
+	compensations []compensation
+}
+
+// compensation reverses what an operation did to one account
+type compensation struct {
+	accountID          uuid.UUID
+	removeTransactions []uuid.UUID // removeTransactions were added by the operation
+	unpayTransactions  []uuid.UUID // unpayTransactions were marked as paid by the operation
+	cancelDeletion     bool
+}
+
+// apply reverses the operation on the account with the domain operations, failing when the account changed since
+func (c compensation) apply(account *Account, clock clock.Clock) error {
+	for _, txID := range c.removeTransactions {
+		if err := account.DeleteTransaction(txID); err != nil {
+			return err
+		}
+	}
+	for _, txID := range c.unpayTransactions {
+		if err := account.MarkTransactionAsUnpaid(txID); err != nil {
+			return err
+		}
+	}
+	if c.cancelDeletion {
+		return account.CancelDeletion(clock)
+	}
+	return nil
+}
+
+// OperationLog keeps the recent ledger mutations of each user for the undo window
+// The log lives in the instance memory, like the demo sessions, so an undo only reaches the operations
+// made through the same instance and none survive a restart
+type OperationLog struct {
+	window time.Duration
+	clock  clock.Clock
+
+	mu         sync.Mutex
+	operations map[uuid.UUID][]Operation
+	sweptAt    time.Time // sweptAt is when the expired operations of every user were last forgotten
+}
+
+// NewOperationLog creates an OperationLog whose operations can be undone for the given window
+func NewOperationLog(window time.Duration, clock clock.Clock) *OperationLog {
+	return &OperationLog{
+		window:     window,
+		clock:      clock,
+		operations: make(map[uuid.UUID][]Operation),
+	}
+}
+
+// record appends an operation of the user, forgetting the expired ones
+func (l *OperationLog) record(userID uuid.UUID, kind OperationKind, accountID uuid.UUID, compensations ...compensation) {
+	if l == nil {
+		return
+	}
+
+	now := l.clock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The users who stopped making operations would otherwise keep their last ones in memory
+	if now.Sub(l.sweptAt) > l.window {
+		l.sweep(now)
+	}
+
+	operations := append(l.live(userID, now), Operation{
+		ID:            uuid.New(),
+		Kind:          kind,
+		AccountID:     accountID,
+		OccurredAt:    now,
+		compensations: compensations,
+	})
+	if len(operations) > maxOperationsPerUser {
+		operations = operations[len(operations)-maxOperationsPerUser:]
+	}
+	l.operations[userID] = operations
+}
+
+// pop removes and returns the last operation of the user still within the window
+func (l *OperationLog) pop(userID uuid.UUID) (Operation, bool) {
+	if l == nil {
+		return Operation{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	operations := l.live(userID, l.clock.Now())
+	if len(operations) == 0 {
+		delete(l.operations, userID)
+		return Operation{}, false
+	}
+	last := operations[len(operations)-1]
+	if len(operations) == 1 {
+		delete(l.operations, userID)
+	} else {
+		l.operations[userID] = operations[:len(operations)-1]
+	}
+	return last, true
+}
+
+// live returns the operations of the user made within the window, the caller holding the lock
+func (l *OperationLog) live(userID uuid.UUID, now time.Time) []Operation {
+	operations := l.operations[userID]
+	expired := 0
+	for expired < len(operations) && now.Sub(operations[expired].OccurredAt) > l.window {
+		expired++
+	}
+	return operations[expired:]
+}
+
+// sweep forgets the operations no longer within the window, the caller holding the lock
+func (l *OperationLog) sweep(now time.Time) {
+	for userID := range l.operations {
+		if operations := l.live(userID, now); len(operations) > 0 {
+			l.operations[userID] = operations
+		} else {
+			delete(l.operations, userID)
+		}
+	}
+	l.sweptAt = now
+}
@@ -0,0 +1,73 @@
+package networth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidHistoryRange = errx.New(errx.CategoryValidation, "INVALID_HISTORY_RANGE", "the history range must start before it ends and span at most the allowed period")
+
+// maxHistoryRange caps how far back a single history request can look
+const maxHistoryRange = 5 * 366 * 24 * time.Hour
+
+// DefaultHistoryRange is the period of the history returned when the request sets no start
+const DefaultHistoryRange = 365 * 24 * time.Hour
+
+// AccountReader loads the accounts counted in the net worth, satisfied by the ledger Service
+type AccountReader interface {
+	FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*ledger.Account, error)
+}
+
+// InvestmentValuer values the positions held by each investment account, satisfied by the investments Service
+type InvestmentValuer interface {
+	MarketValues(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]money.Money, error)
+}
+
+// SnapshotRepository persists the daily net worth snapshots
+type SnapshotRepository interface {
+	// SaveAll inserts the snapshots, replacing the ones already taken for the same user, date and currency
+	SaveAll(ctx context.Context, snapshots []Snapshot) error
+	// FindByUserID retrieves the snapshots of the user dated within [from, to], ordered by currency and date
+	FindByUserID(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]Snapshot, error)
+	// FindUserIDs retrieves the users holding at least one active account
+	FindUserIDs(ctx context.Context) ([]uuid.UUID, error)
+}
+
+// CurrencyNetWorth is the net worth held in a single currency, split by account group
+type CurrencyNetWorth struct {
+	Currency    string
+	Cash        money.Money // Cash is the real balance of the checking and investment accounts
+	CreditCards money.Money // CreditCards is the debt on the cards, negative or zero
+	Investments money.Money // Investments is the market value of the positions
+	Total       money.Money
+}
+
+// NetWorth is what a user owns minus what they owe, per currency since currencies are never converted
+type NetWorth struct {
+	Currencies []CurrencyNetWorth
+}
+
+// Snapshot is the net worth of a user in a currency as computed on a given day
+type Snapshot struct {
+	UserID   uuid.UUID
+	Date     time.Time // Date is the UTC day the snapshot refers to
+	NetWorth CurrencyNetWorth
+	TakenAt  time.Time
+}
+
+// Series is the history of the net worth of a user in a currency
+type Series struct {
+	Currency  string
+	Snapshots []Snapshot
+}
+
+// snapshotDate truncates t to its UTC day
+func snapshotDate(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
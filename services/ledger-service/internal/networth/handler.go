@@ -0,0 +1,144 @@
+package networth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// NetWorthHandler holds dependencies for net worth-related HTTP handlers
+type NetWorthHandler struct {
+	netWorthService *Service
+}
+
+// NewNetWorthHandler creates a new instance of NetWorthHandler
+func NewNetWorthHandler(netWorthService *Service) *NetWorthHandler {
+	return &NetWorthHandler{netWorthService: netWorthService}
+}
+
+// RegisterRoutes sets up the API routes for the net worth module
+func (h *NetWorthHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.GET("/net-worth", h.netWorthHandler)
+	apiRouteGroup.GET("/net-worth/history", h.historyHandler)
+}
+
+// CurrencyNetWorthResponse defines the net worth held in a currency returned by the API
+// Amounts are expressed in minor units of the currency
+type CurrencyNetWorthResponse struct {
+	Currency    string `json:"currency"`
+	Cash        int64  `json:"cash"`
+	CreditCards int64  `json:"credit_cards"`
+	Investments int64  `json:"investments"`
+	Total       int64  `json:"total"`
+}
+
+// NetWorthResponse defines the structure of the net worth returned by the API
+type NetWorthResponse struct {
+	Currencies []CurrencyNetWorthResponse `json:"currencies"`
+}
+
+// SnapshotResponse defines a daily net worth point of a history series returned by the API
+type SnapshotResponse struct {
+	Date        string `json:"date"` // Date is formatted as YYYY-MM-DD
+	Cash        int64  `json:"cash"`
+	CreditCards int64  `json:"credit_cards"`
+	Investments int64  `json:"investments"`
+	Total       int64  `json:"total"`
+}
+
+// SeriesResponse defines the net worth history in a currency returned by the API
+// Amounts are expressed in minor units of the currency, days without a snapshot are absent
+type SeriesResponse struct {
+	Currency  string             `json:"currency"`
+	Snapshots []SnapshotResponse `json:"snapshots"`
+}
+
+// netWorthHandler handles the HTTP request for computing the user's net worth
+func (h *NetWorthHandler) netWorthHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	netWorth, err := h.netWorthService.Compute(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := NetWorthResponse{Currencies: make([]CurrencyNetWorthResponse, len(netWorth.Currencies))}
+	for i, cur := range netWorth.Currencies {
+		resp.Currencies[i] = CurrencyNetWorthResponse{
+			Currency:    cur.Currency,
+			Cash:        cur.Cash.Amount,
+			CreditCards: cur.CreditCards.Amount,
+			Investments: cur.Investments.Amount,
+			Total:       cur.Total.Amount,
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// historyHandler handles the HTTP request for the daily net worth history of the user (e.g., ?from=2025-01-01&to=2025-06-30)
+func (h *NetWorthHandler) historyHandler(c echo.Context) error {
+	from, err := dateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := dateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	series, err := h.netWorthService.History(c.Request().Context(), userID, from, to)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]SeriesResponse, len(series))
+	for i, s := range series {
+		resp[i] = SeriesResponse{Currency: s.Currency, Snapshots: make([]SnapshotResponse, len(s.Snapshots))}
+		for j, snapshot := range s.Snapshots {
+			resp[i].Snapshots[j] = SnapshotResponse{
+				Date:        snapshot.Date.Format(time.DateOnly),
+				Cash:        snapshot.NetWorth.Cash.Amount,
+				CreditCards: snapshot.NetWorth.CreditCards.Amount,
+				Investments: snapshot.NetWorth.Investments.Amount,
+				Total:       snapshot.NetWorth.Total.Amount,
+			}
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// dateQueryParam parses an optional YYYY-MM-DD query parameter, returning the zero time when it is absent
+func dateQueryParam(c echo.Context, name string) (time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+" date format, expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
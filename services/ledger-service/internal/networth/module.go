@@ -0,0 +1,39 @@
+package networth
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the snapshot repository, net worth service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *NetWorthHandler
+}
+
+// NewModule creates the net worth module, investments is nil when positions are not available (e.g., demo mode)
+func NewModule(deps module.Deps, accounts AccountReader, investments InvestmentValuer) *Module {
+	netWorthSvc := NewService(accounts, investments, NewPostgresSnapshotRepository(deps.Postgres.Pool), deps.Clock)
+
+	return &Module{
+		service: netWorthSvc,
+		handler: NewNetWorthHandler(netWorthSvc),
+	}
+}
+
+// Service returns the net worth service, for the composition root scheduling its snapshots
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "networth"
+}
+
+// RegisterRoutes mounts the net worth routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,150 @@
+package networth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ SnapshotRepository = (*PostgresSnapshotRepository)(nil)
+
+// PostgresSnapshotRepository is a PostgreSQL implementation of the SnapshotRepository interface
+type PostgresSnapshotRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSnapshotRepository creates a new PostgresSnapshotRepository
+func NewPostgresSnapshotRepository(pool *pgxpool.Pool) *PostgresSnapshotRepository {
+	return &PostgresSnapshotRepository{pool: pool}
+}
+
+// snapshotModel represents the snapshot structure in the database
+type snapshotModel struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Date        time.Time `db:"snapshot_date"`
+	Currency    string    `db:"currency"`
+	Cash        int64     `db:"cash_in_cents"`
+	CreditCards int64     `db:"credit_cards_in_cents"`
+	Investments int64     `db:"investments_in_cents"`
+	Total       int64     `db:"total_in_cents"`
+	TakenAt     time.Time `db:"taken_at"`
+}
+
+// SaveAll upserts the snapshots in a single batch, so rerunning the task on the same day replaces its snapshots
+func (r *PostgresSnapshotRepository) SaveAll(ctx context.Context, snapshots []Snapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO net_worth_snapshots (user_id, snapshot_date, currency, cash_in_cents, credit_cards_in_cents, investments_in_cents, total_in_cents, taken_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, snapshot_date, currency)
+		DO UPDATE SET
+			cash_in_cents = EXCLUDED.cash_in_cents,
+			credit_cards_in_cents = EXCLUDED.credit_cards_in_cents,
+			investments_in_cents = EXCLUDED.investments_in_cents,
+			total_in_cents = EXCLUDED.total_in_cents,
+			taken_at = EXCLUDED.taken_at
+	`
+
+	batch := &pgx.Batch{}
+	for _, snapshot := range snapshots {
+		m := toSnapshotPersistence(snapshot)
+		batch.Queue(query, m.UserID, m.Date, m.Currency, m.Cash, m.CreditCards, m.Investments, m.Total, m.TakenAt)
+	}
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to upsert net worth snapshots: %w", err)
+	}
+	return nil
+}
+
+// FindByUserID retrieves the snapshots of the user dated within [from, to], ordered by currency and date
+func (r *PostgresSnapshotRepository) FindByUserID(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]Snapshot, error) {
+	query := `
+		SELECT user_id, snapshot_date, currency, cash_in_cents, credit_cards_in_cents, investments_in_cents, total_in_cents, taken_at
+		FROM net_worth_snapshots
+		WHERE user_id = $1 AND snapshot_date BETWEEN $2 AND $3
+		ORDER BY currency, snapshot_date
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query net worth snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := make([]Snapshot, 0)
+	for rows.Next() {
+		var m snapshotModel
+		if err := rows.Scan(&m.UserID, &m.Date, &m.Currency, &m.Cash, &m.CreditCards, &m.Investments, &m.Total, &m.TakenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan net worth snapshot row: %w", err)
+		}
+		snapshots = append(snapshots, toSnapshotDomain(&m))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating net worth snapshot rows: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// FindUserIDs retrieves the users holding at least one active account
+func (r *PostgresSnapshotRepository) FindUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT user_id FROM accounts WHERE archived_at IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users with accounts: %w", err)
+	}
+
+	defer rows.Close()
+
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user id row: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user id rows: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// toSnapshotPersistence maps a domain Snapshot to its persistence model
+func toSnapshotPersistence(s Snapshot) *snapshotModel {
+	return &snapshotModel{
+		UserID:      s.UserID,
+		Date:        s.Date,
+		Currency:    s.NetWorth.Currency,
+		Cash:        s.NetWorth.Cash.Amount,
+		CreditCards: s.NetWorth.CreditCards.Amount,
+		Investments: s.NetWorth.Investments.Amount,
+		Total:       s.NetWorth.Total.Amount,
+		TakenAt:     s.TakenAt,
+	}
+}
+
+// toSnapshotDomain maps a persistence snapshotModel to a domain Snapshot
+func toSnapshotDomain(m *snapshotModel) Snapshot {
+	return Snapshot{
+		UserID: m.UserID,
+		Date:   snapshotDate(m.Date),
+		NetWorth: CurrencyNetWorth{
+			Currency:    m.Currency,
+			Cash:        money.New(m.Cash, m.Currency),
+			CreditCards: money.New(m.CreditCards, m.Currency),
+			Investments: money.New(m.Investments, m.Currency),
+			Total:       money.New(m.Total, m.Currency),
+		},
+		TakenAt: m.TakenAt,
+	}
+}
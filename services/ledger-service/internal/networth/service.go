@@ -0,0 +1,201 @@
+package networth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// Service computes the net worth of a user from the ledger accounts and the investment positions, keeping its daily history
+type Service struct {
+	accounts     AccountReader
+	investments  InvestmentValuer
+	snapshotRepo SnapshotRepository
+	clock        clock.Clock
+}
+
+// NewService creates a new instance of the net worth Service, investments is nil when positions are not available (e.g., demo mode)
+func NewService(accounts AccountReader, investments InvestmentValuer, snapshotRepo SnapshotRepository, clock clock.Clock) *Service {
+	return &Service{
+		accounts:     accounts,
+		investments:  investments,
+		snapshotRepo: snapshotRepo,
+		clock:        clock,
+	}
+}
+
+// Compute is the use case for computing the net worth of the accounts the user includes in the overall balance
+// Credit cards count everything recorded on them as debt, since purchases are owed before their statement is paid
+func (s *Service) Compute(ctx context.Context, userID uuid.UUID) (*NetWorth, error) {
+	accounts, err := s.accounts.FindAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounts for net worth: %w", err)
+	}
+
+	marketValues := map[uuid.UUID]money.Money{}
+	if s.investments != nil {
+		if marketValues, err = s.investments.MarketValues(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to value investments for net worth: %w", err)
+		}
+	}
+
+	byCurrency := make(map[string]*CurrencyNetWorth)
+	entry := func(currency string) *CurrencyNetWorth {
+		if e, ok := byCurrency[currency]; ok {
+			return e
+		}
+		e := &CurrencyNetWorth{
+			Currency:    currency,
+			Cash:        money.Zero(currency),
+			CreditCards: money.Zero(currency),
+			Investments: money.Zero(currency),
+			Total:       money.Zero(currency),
+		}
+		byCurrency[currency] = e
+		return e
+	}
+
+	for _, account := range accounts {
+		if !account.IncludeInOverallBalance {
+			continue
+		}
+		e := entry(account.Currency)
+
+		if account.Kind == ledger.CreditCard {
+			debt, err := account.ProjectedBalance()
+			if err != nil {
+				return nil, err
+			}
+			if e.CreditCards, err = e.CreditCards.Add(debt); err != nil {
+				return nil, err
+			}
+		} else {
+			balance, err := account.RealBalance(s.clock)
+			if err != nil {
+				return nil, err
+			}
+			if e.Cash, err = e.Cash.Add(balance); err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := marketValues[account.ID]; ok {
+			// Positions are valued in the currency of their quote, which may differ from the account's
+			v := entry(value.Currency)
+			if v.Investments, err = v.Investments.Add(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	netWorth := &NetWorth{Currencies: make([]CurrencyNetWorth, 0, len(byCurrency))}
+	for _, e := range byCurrency {
+		total, err := money.Sum(e.Currency, e.Cash, e.CreditCards, e.Investments)
+		if err != nil {
+			return nil, err
+		}
+		e.Total = total
+		netWorth.Currencies = append(netWorth.Currencies, *e)
+	}
+	slices.SortFunc(netWorth.Currencies, func(a, b CurrencyNetWorth) int { return strings.Compare(a.Currency, b.Currency) })
+
+	return netWorth, nil
+}
+
+// TakeSnapshots is the scheduled task persisting today's net worth of every user holding an active account
+// A user whose net worth cannot be computed is skipped, so one failure does not hold back everyone else's history
+func (s *Service) TakeSnapshots(ctx context.Context) error {
+	userIDs, err := s.snapshotRepo.FindUserIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find users for net worth snapshots: %w", err)
+	}
+
+	now := s.clock.Now()
+	date := snapshotDate(now)
+	failed := 0
+	for _, userID := range userIDs {
+		if err := s.takeSnapshot(ctx, userID, date, now); err != nil {
+			failed++
+			ctxlogger.GetLogger(ctx).Error("failed to take net worth snapshot",
+				slog.String("user_id", userID.String()),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	ctxlogger.GetLogger(ctx).Info("took net worth snapshots",
+		slog.Time("date", date),
+		slog.Int("users", len(userIDs)),
+		slog.Int("failed", failed),
+	)
+	if failed > 0 {
+		return fmt.Errorf("failed to take the net worth snapshots of %d of %d users", failed, len(userIDs))
+	}
+	return nil
+}
+
+// takeSnapshot computes and persists the net worth of a user for the given date
+func (s *Service) takeSnapshot(ctx context.Context, userID uuid.UUID, date, takenAt time.Time) error {
+	netWorth, err := s.Compute(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]Snapshot, len(netWorth.Currencies))
+	for i, currencyNetWorth := range netWorth.Currencies {
+		snapshots[i] = Snapshot{
+			UserID:   userID,
+			Date:     date,
+			NetWorth: currencyNetWorth,
+			TakenAt:  takenAt,
+		}
+	}
+
+	if err := s.snapshotRepo.SaveAll(ctx, snapshots); err != nil {
+		return fmt.Errorf("failed to save net worth snapshots: %w", err)
+	}
+	return nil
+}
+
+// History is the use case for listing the daily net worth snapshots of the user within [from, to], one series per currency
+// A zero from defaults to DefaultHistoryRange before to, and a zero to defaults to today
+func (s *Service) History(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]Series, error) {
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	to = snapshotDate(to)
+	if from.IsZero() {
+		from = to.Add(-DefaultHistoryRange)
+	}
+	from = snapshotDate(from)
+
+	if from.After(to) || to.Sub(from) > maxHistoryRange {
+		return nil, ErrInvalidHistoryRange.With("from", from.Format(time.DateOnly)).With("to", to.Format(time.DateOnly))
+	}
+
+	snapshots, err := s.snapshotRepo.FindByUserID(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find net worth snapshots: %w", err)
+	}
+
+	series := make([]Series, 0)
+	for _, snapshot := range snapshots {
+		currency := snapshot.NetWorth.Currency
+		if len(series) == 0 || series[len(series)-1].Currency != currency {
+			series = append(series, Series{Currency: currency})
+		}
+		last := &series[len(series)-1]
+		last.Snapshots = append(last.Snapshots, snapshot)
+	}
+
+	return series, nil
+}
@@ -0,0 +1,362 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+var (
+	_ Channel = (*InAppChannel)(nil)
+	_ Channel = (*EmailChannel)(nil)
+	_ Channel = (*PushChannel)(nil)
+	_ Channel = (*WebhookChannel)(nil)
+
+	_ notify.Mailer = (*EmailChannel)(nil)
+)
+
+var (
+	// errWebhookFailed is the outcome recorded for the deliveries that did not get a 2xx response
+	errWebhookFailed = errors.New("the webhook could not be reached or did not accept the delivery")
+	// errWebhookAddressNotAllowed is returned by the dialer of the webhooks for the addresses that are not public
+	errWebhookAddressNotAllowed = errors.New("the webhook address is not public")
+
+	// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), not public though netip does not report it private
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+// ----- In-app ----- //
+
+// InAppChannel stores the message in the user's inbox, read through the notifications endpoints
+type InAppChannel struct {
+	repo  NotificationRepository
+	ids   idgen.Generator
+	clock clock.Clock
+}
+
+// NewInAppChannel creates a new InAppChannel
+func NewInAppChannel(repo NotificationRepository, ids idgen.Generator, clock clock.Clock) *InAppChannel {
+	return &InAppChannel{repo: repo, ids: ids, clock: clock}
+}
+
+// Kind returns the channel identifier
+func (ch *InAppChannel) Kind() ChannelKind {
+	return ChannelInApp
+}
+
+// Send stores the message as an unread notification
+func (ch *InAppChannel) Send(ctx context.Context, prefs *Preferences, msg notify.Message) error {
+	n := &Notification{
+		ID:        ch.ids.NewID(),
+		UserID:    msg.UserID,
+		Category:  msg.Category,
+		Title:     msg.Title,
+		Body:      msg.Body,
+		Data:      msg.Data,
+		CreatedAt: ch.clock.Now(),
+	}
+
+	if err := ch.repo.Save(ctx, n); err != nil {
+		return fmt.Errorf("failed to save in-app notification: %w", err)
+	}
+	return nil
+}
+
+// ----- Email ----- //
+
+// EmailSender delivers an email through a provider (SMTP, SES...), with the files attached to it
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string, attachments ...notify.Attachment) error
+}
+
+// EmailChannel emails the message to the address registered in the identity service
+// It is also the Mailer of the modules sending emails of their own
+type EmailChannel struct {
+	identity *identityclient.Client
+	sender   EmailSender
+}
+
+// NewEmailChannel creates a new EmailChannel
+func NewEmailChannel(identity *identityclient.Client, sender EmailSender) *EmailChannel {
+	return &EmailChannel{identity: identity, sender: sender}
+}
+
+// Kind returns the channel identifier
+func (ch *EmailChannel) Kind() ChannelKind {
+	return ChannelEmail
+}
+
+// Send looks up the user's email address and emails the message
+func (ch *EmailChannel) Send(ctx context.Context, prefs *Preferences, msg notify.Message) error {
+	return ch.Mail(ctx, notify.Email{UserID: msg.UserID, Subject: msg.Title, Body: msg.Body})
+}
+
+// Mail looks up the user's email address and sends the email with its attachments
+func (ch *EmailChannel) Mail(ctx context.Context, email notify.Email) error {
+	user, err := ch.identity.GetUser(ctx, email.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to find user email: %w", err)
+	}
+
+	if err := ch.sender.SendEmail(ctx, user.Email, email.Subject, email.Body, email.Attachments...); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// ----- Push ----- //
+
+// PushSender delivers a push notification to a device through its platform's provider (FCM, APNs...)
+// It returns ErrDeviceUnregistered when the provider no longer knows the device token
+type PushSender interface {
+	SendPush(ctx context.Context, device *Device, title, body string, data map[string]string) error
+}
+
+// PushChannel sends the message as a push notification to every device the user registered
+type PushChannel struct {
+	devices DeviceRepository
+	sender  PushSender
+}
+
+// NewPushChannel creates a new PushChannel
+func NewPushChannel(devices DeviceRepository, sender PushSender) *PushChannel {
+	return &PushChannel{devices: devices, sender: sender}
+}
+
+// Kind returns the channel identifier
+func (ch *PushChannel) Kind() ChannelKind {
+	return ChannelPush
+}
+
+// Send pushes the message to the user's devices, a user without devices being skipped. The devices the provider
+// no longer knows are forgotten, and a failing device does not prevent delivery to the others
+func (ch *PushChannel) Send(ctx context.Context, prefs *Preferences, msg notify.Message) error {
+	devices, err := ch.devices.FindByUserID(ctx, msg.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to find push devices: %w", err)
+	}
+
+	var errs []error
+	for _, device := range devices {
+		err := ch.sender.SendPush(ctx, device, msg.Title, msg.Body, msg.Data)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrDeviceUnregistered):
+			if err := ch.devices.Delete(ctx, device.UserID, device.ID); err != nil && !errors.Is(err, ErrDeviceNotFound) {
+				errs = append(errs, fmt.Errorf("failed to forget unregistered push device %s: %w", device.ID, err))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("failed to send push notification to device %s: %w", device.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ----- Webhook ----- //
+
+// webhookPayload is the JSON body posted to the user's webhook URL
+// ID is the same on every attempt of a delivery, so receivers can ignore the replays of a message they already got
+type webhookPayload struct {
+	ID       uuid.UUID         `json:"id"`
+	Category notify.Category   `json:"category"`
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Data     map[string]string `json:"data,omitempty"`
+	SentAt   time.Time         `json:"sent_at"`
+}
+
+// WebhookChannel posts the message as JSON to the webhook URL configured in the user's preferences, recording each
+// delivery so the failed ones can be replayed
+type WebhookChannel struct {
+	deliveries DeliveryRepository
+	client     *http.Client
+	secret     []byte
+	ids        idgen.Generator
+	clock      clock.Clock
+}
+
+// NewWebhookChannel creates a new WebhookChannel signing every delivery with secret
+func NewWebhookChannel(deliveries DeliveryRepository, timeout time.Duration, secret string, ids idgen.Generator, clock clock.Clock) *WebhookChannel {
+	return &WebhookChannel{
+		deliveries: deliveries,
+		client:     newWebhookClient(timeout),
+		secret:     []byte(secret),
+		ids:        ids,
+		clock:      clock,
+	}
+}
+
+// newWebhookClient creates the client posting the deliveries, which only connects to public addresses and does not
+// follow redirects: the URL is chosen by the user, who must not reach the network of the service through it
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// The address is checked once resolved, so a public name resolving to a private address is refused too
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return errWebhookAddressNotAllowed
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !publicAddress(ip.Unmap()) {
+				return errWebhookAddressNotAllowed
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would connect in place of the dialer, skipping the check
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddress reports whether ip may be reached by the webhooks: loopback, private, link-local (the cloud metadata
+// endpoints included), multicast and unspecified addresses are refused
+func publicAddress(ip netip.Addr) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// Kind returns the channel identifier
+func (ch *WebhookChannel) Kind() ChannelKind {
+	return ChannelWebhook
+}
+
+// Send posts the message to the user's webhook URL, failing on any non-2xx response
+func (ch *WebhookChannel) Send(ctx context.Context, prefs *Preferences, msg notify.Message) error {
+	if prefs.WebhookURL == "" {
+		return ErrWebhookURLRequired
+	}
+
+	now := ch.clock.Now()
+	delivery := &WebhookDelivery{
+		ID:        ch.ids.NewID(),
+		UserID:    prefs.UserID,
+		Category:  msg.Category,
+		URL:       prefs.WebhookURL,
+		CreatedAt: now,
+	}
+	body, err := json.Marshal(webhookPayload{
+		ID:       delivery.ID,
+		Category: msg.Category,
+		Title:    msg.Title,
+		Body:     msg.Body,
+		Data:     msg.Data,
+		SentAt:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	delivery.Payload = body
+
+	return ch.deliver(ctx, delivery)
+}
+
+// Redeliver posts a recorded delivery again, to url since the user may have fixed their webhook since
+func (ch *WebhookChannel) Redeliver(ctx context.Context, delivery *WebhookDelivery, url string) error {
+	delivery.URL = url
+	return ch.deliver(ctx, delivery)
+}
+
+// deliver makes an attempt of the delivery and records its outcome
+func (ch *WebhookChannel) deliver(ctx context.Context, delivery *WebhookDelivery) error {
+	now := ch.clock.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = now
+
+	err := ch.post(ctx, delivery.URL, delivery.Payload)
+	if err != nil {
+		delivery.Status = DeliveryFailed
+		delivery.LastError = err.Error()
+	} else {
+		delivery.Status = DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	}
+
+	if saveErr := ch.deliveries.Save(ctx, delivery); saveErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to record webhook delivery: %w", saveErr))
+	}
+	return err
+}
+
+// post sends the body to url, failing on any non-2xx response
+func (ch *WebhookChannel) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Receivers authenticate deliveries with the signature and reject replays outside of the timestamp window, so a
+	// replayed delivery is signed again
+	if len(ch.secret) > 0 {
+		signedAt := ch.clock.Now()
+		req.Header.Set(httpx.HeaderSignatureTimestamp, strconv.FormatInt(signedAt.Unix(), 10))
+		req.Header.Set(httpx.HeaderSignature, httpx.Sign(ch.secret, signedAt, body))
+	}
+
+	// The error is recorded on the delivery the user reads, so it tells neither why the connection failed nor the
+	// status of the response, which would map the hosts and ports the service reaches. The details are logged instead
+	resp, err := ch.client.Do(req)
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Info("webhook call failed", slog.String("error", err.Error()))
+		return errWebhookFailed
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		ctxlogger.GetLogger(ctx).Info("webhook rejected the delivery", slog.Int("status", resp.StatusCode))
+		return errWebhookFailed
+	}
+	return nil
+}
+
+// ----- Development senders ----- //
+
+// LogSender only logs emails and push notifications, used until a real provider is configured
+type LogSender struct{}
+
+// SendEmail logs the email instead of sending it
+func (LogSender) SendEmail(ctx context.Context, to, subject, body string, attachments ...notify.Attachment) error {
+	ctxlogger.GetLogger(ctx).Info("email notification (log sender)",
+		slog.String("to", to),
+		slog.String("subject", subject),
+		slog.Int("attachments", len(attachments)),
+	)
+	return nil
+}
+
+// SendPush logs the push notification instead of sending it
+func (LogSender) SendPush(ctx context.Context, device *Device, title, body string, data map[string]string) error {
+	ctxlogger.GetLogger(ctx).Info("push notification (log sender)",
+		slog.String("user_id", device.UserID.String()),
+		slog.String("device_id", device.ID.String()),
+		slog.String("platform", string(device.Platform)),
+		slog.String("title", title),
+	)
+	return nil
+}
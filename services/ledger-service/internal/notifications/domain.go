@@ -0,0 +1,217 @@
+package notifications
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotificationNotFound = errx.New(errx.CategoryNotFound, "NOTIFICATION_NOT_FOUND", "notification not found")
+	ErrWebhookURLRequired   = errx.New(errx.CategoryValidation, "WEBHOOK_URL_REQUIRED", "a webhook url is required to enable the webhook channel")
+	ErrChannelUnavailable   = errx.New(errx.CategoryUnavailable, "NOTIFICATION_CHANNEL_UNAVAILABLE", "the notification channel is not configured")
+	ErrDeliveryFailed       = errx.New(errx.CategoryUnavailable, "NOTIFICATION_DELIVERY_FAILED", "the notification could not be delivered")
+	ErrDeviceNotFound       = errx.New(errx.CategoryNotFound, "PUSH_DEVICE_NOT_FOUND", "push device not found")
+	ErrDeliveryNotFound     = errx.New(errx.CategoryNotFound, "WEBHOOK_DELIVERY_NOT_FOUND", "webhook delivery not found")
+	ErrDeliveryNotFailed    = errx.New(errx.CategoryConflict, "WEBHOOK_DELIVERY_NOT_FAILED", "only failed webhook deliveries can be replayed")
+	ErrInvalidReplayRange   = errx.New(errx.CategoryValidation, "INVALID_REPLAY_RANGE", "the start of the replayed range must be before its end")
+
+	// ErrDeviceUnregistered is returned by a PushSender when the provider no longer knows the token (the app was
+	// uninstalled), so the device is forgotten
+	ErrDeviceUnregistered = errx.New(errx.CategoryNotFound, "PUSH_DEVICE_UNREGISTERED", "the push device is no longer registered")
+)
+
+const (
+	ChannelEmail   ChannelKind = "email"
+	ChannelPush    ChannelKind = "push"
+	ChannelWebhook ChannelKind = "webhook"
+	ChannelInApp   ChannelKind = "in_app"
+)
+
+const (
+	PlatformFCM  DevicePlatform = "fcm"
+	PlatformAPNs DevicePlatform = "apns"
+)
+
+const (
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// ChannelKind identifies a delivery channel
+type ChannelKind string
+
+// Values returns every known channel, used to validate enum fields
+func (ChannelKind) Values() []string {
+	return []string{string(ChannelEmail), string(ChannelPush), string(ChannelWebhook), string(ChannelInApp)}
+}
+
+// DevicePlatform identifies the push provider a device token belongs to
+type DevicePlatform string
+
+// Values returns every known platform, used to validate enum fields
+func (DevicePlatform) Values() []string {
+	return []string{string(PlatformFCM), string(PlatformAPNs)}
+}
+
+// DeliveryStatus is the outcome of the last attempt of a webhook delivery
+type DeliveryStatus string
+
+// Channel delivers a message to a user through a single medium (email, push, webhook or in-app)
+type Channel interface {
+	Kind() ChannelKind
+	Send(ctx context.Context, prefs *Preferences, msg notify.Message) error
+}
+
+// PreferencesRepository persists the delivery preferences of each user
+type PreferencesRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+	Save(ctx context.Context, prefs *Preferences) error
+}
+
+// NotificationRepository persists the in-app notifications shown in the user's inbox
+type NotificationRepository interface {
+	Save(ctx context.Context, n *Notification) error
+	FindByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*Notification, error)
+	MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, readAt time.Time) error
+}
+
+// DeviceRepository persists the devices each user receives push notifications on
+type DeviceRepository interface {
+	Save(ctx context.Context, device *Device) error
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Device, error)
+	Delete(ctx context.Context, userID, deviceID uuid.UUID) error
+}
+
+// DeliveryRepository persists the deliveries of the webhook channel, so the failed ones can be replayed
+type DeliveryRepository interface {
+	Save(ctx context.Context, delivery *WebhookDelivery) error
+	FindByID(ctx context.Context, userID, deliveryID uuid.UUID) (*WebhookDelivery, error)
+	// FindFailed returns the failed deliveries of the user created within [from, to), the most recent first. A zero
+	// from or to leaves that end of the range open
+	FindFailed(ctx context.Context, userID uuid.UUID, from, to time.Time, limit, offset int) ([]*WebhookDelivery, error)
+	// FailingSince returns when the webhook of the user started failing, the first failure after its last success, and
+	// nil when it did not fail since
+	FailingSince(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+}
+
+// defaultChannels are the channels used for a category the user never configured
+var defaultChannels = map[notify.Category][]ChannelKind{
+	notify.CategoryReminder:    {ChannelInApp, ChannelPush},
+	notify.CategoryBudgetAlert: {ChannelInApp, ChannelPush},
+	notify.CategorySecurity:    {ChannelInApp, ChannelEmail},
+}
+
+// mandatoryChannels can not be disabled by the user, so critical events are never missed
+var mandatoryChannels = map[notify.Category][]ChannelKind{
+	notify.CategorySecurity: {ChannelInApp, ChannelEmail},
+}
+
+// Preferences holds the channels a user enabled for each notification category
+type Preferences struct {
+	UserID     uuid.UUID
+	Channels   map[notify.Category][]ChannelKind
+	WebhookURL string // WebhookURL receives the webhook channel deliveries, required to enable it
+	UpdatedAt  time.Time
+
+	// WebhookDisabledAt is when the webhook was disabled for failing too long, nil once the user enables it again
+	WebhookDisabledAt *time.Time
+}
+
+// DefaultPreferences returns the preferences of a user who never changed them
+func DefaultPreferences(userID uuid.UUID) *Preferences {
+	channels := make(map[notify.Category][]ChannelKind, len(defaultChannels))
+	for category, kinds := range defaultChannels {
+		channels[category] = slices.Clone(kinds)
+	}
+
+	return &Preferences{
+		UserID:   userID,
+		Channels: channels,
+	}
+}
+
+// SetChannels replaces the channels of a category, always keeping its mandatory channels
+func (p *Preferences) SetChannels(category notify.Category, kinds []ChannelKind, clock clock.Clock) error {
+	if slices.Contains(kinds, ChannelWebhook) && p.WebhookURL == "" {
+		return ErrWebhookURLRequired.With("category", category)
+	}
+
+	enabled := make([]ChannelKind, 0, len(kinds))
+	for _, kind := range append(slices.Clone(mandatoryChannels[category]), kinds...) {
+		if !slices.Contains(enabled, kind) {
+			enabled = append(enabled, kind)
+		}
+	}
+
+	p.Channels[category] = enabled
+	p.UpdatedAt = clock.Now()
+	return nil
+}
+
+// DisableWebhook removes the webhook channel from every category, keeping its URL so the user can enable it again
+func (p *Preferences) DisableWebhook(clock clock.Clock) {
+	now := clock.Now()
+	for category, kinds := range p.Channels {
+		p.Channels[category] = withoutChannel(kinds, ChannelWebhook)
+	}
+	p.WebhookDisabledAt = &now
+	p.UpdatedAt = now
+}
+
+// ChannelsFor returns the channels a message of the given category must be delivered to
+func (p *Preferences) ChannelsFor(category notify.Category) []ChannelKind {
+	kinds, ok := p.Channels[category]
+	if !ok {
+		kinds = defaultChannels[category]
+	}
+
+	for _, kind := range mandatoryChannels[category] {
+		if !slices.Contains(kinds, kind) {
+			kinds = append(slices.Clone(kinds), kind)
+		}
+	}
+	return kinds
+}
+
+// Notification is a message stored in the user's in-app inbox
+type Notification struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Category  notify.Category
+	Title     string
+	Body      string
+	Data      map[string]string
+	CreatedAt time.Time
+	ReadAt    *time.Time
+}
+
+// Device is an app install of a user, addressed by the token its push provider issued
+type Device struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Platform   DevicePlatform
+	Token      string
+	CreatedAt  time.Time
+	LastSeenAt time.Time // LastSeenAt is the last time the app registered the token, refreshed on every launch
+}
+
+// WebhookDelivery is a message posted to the webhook of a user, kept with the outcome of its last attempt
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Category      notify.Category
+	URL           string
+	Payload       []byte // Payload is the JSON body, posted again as is when the delivery is replayed
+	Status        DeliveryStatus
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	LastAttemptAt time.Time
+	DeliveredAt   *time.Time
+}
@@ -0,0 +1,434 @@
+package notifications
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/validatorx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// NotificationHandler holds dependencies for notification-related HTTP handlers
+type NotificationHandler struct {
+	notificationService *Service
+}
+
+// NewNotificationHandler creates a new instance of NotificationHandler
+func NewNotificationHandler(notificationService *Service) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// RegisterRoutes sets up the API routes for the notifications module
+func (h *NotificationHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	notificationsGroup := apiRouteGroup.Group("/notifications")
+
+	notificationsGroup.GET("", h.listNotificationsHandler)
+	notificationsGroup.POST("/:id/read", h.markAsReadHandler)
+	notificationsGroup.GET("/preferences", h.getPreferencesHandler)
+	notificationsGroup.PUT("/preferences", h.updatePreferencesHandler)
+	notificationsGroup.GET("/devices", h.listDevicesHandler)
+	notificationsGroup.POST("/devices", h.registerDeviceHandler)
+	notificationsGroup.DELETE("/devices/:id", h.unregisterDeviceHandler)
+	notificationsGroup.GET("/webhook/deliveries", h.listFailedDeliveriesHandler)
+	notificationsGroup.POST("/webhook/deliveries/replay", h.replayDeliveriesHandler)
+	notificationsGroup.POST("/webhook/deliveries/:id/replay", h.replayDeliveryHandler)
+}
+
+// CategoryChannelsRequest defines the channels enabled for a single notification category
+type CategoryChannelsRequest struct {
+	Category notify.Category `json:"category" validate:"required,enum"`
+	Channels []ChannelKind   `json:"channels" validate:"dive,enum"` // An empty list keeps only the mandatory channels
+}
+
+// UpdatePreferencesRequest defines the expected JSON body for updating the notification preferences
+type UpdatePreferencesRequest struct {
+	WebhookURL *string                   `json:"webhook_url,omitempty" validate:"omitempty,url,startswith=https://,max=2048"` // An empty string removes the webhook
+	Categories []CategoryChannelsRequest `json:"categories,omitempty" validate:"omitempty,dive"`
+}
+
+// ValidateStruct applies the UpdatePreferencesRequest rules spanning several fields
+func (r UpdatePreferencesRequest) ValidateStruct(sl *validatorx.StructLevel) {
+	if r.WebhookURL == nil && len(r.Categories) == 0 {
+		sl.ReportError("required_any", "webhook_url, categories", "webhook_url", "categories")
+	}
+}
+
+// CategoryChannelsResponse defines the channels enabled for a notification category returned by the API
+type CategoryChannelsResponse struct {
+	Category  notify.Category `json:"category"`
+	Channels  []ChannelKind   `json:"channels"`
+	Mandatory []ChannelKind   `json:"mandatory"` // Channels the user can not disable for this category
+}
+
+// PreferencesResponse defines the structure of the notification preferences returned by the API
+type PreferencesResponse struct {
+	WebhookURL        string                     `json:"webhook_url,omitempty"`
+	WebhookDisabledAt *time.Time                 `json:"webhook_disabled_at,omitempty"` // Set when the webhook was disabled for failing too long
+	Categories        []CategoryChannelsResponse `json:"categories"`
+	UpdatedAt         *time.Time                 `json:"updated_at,omitempty"`
+}
+
+// NotificationResponse defines the structure of an in-app notification returned by the API
+type NotificationResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	Category  notify.Category   `json:"category"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ReadAt    *time.Time        `json:"read_at,omitempty"`
+}
+
+// RegisterDeviceRequest defines the expected JSON body for registering a device to receive push notifications
+type RegisterDeviceRequest struct {
+	Platform DevicePlatform `json:"platform" validate:"required,enum"`
+	Token    string         `json:"token" validate:"required,max=4096"` // The token the app received from FCM or APNs
+}
+
+// DeviceResponse defines the structure of a push device returned by the API, its token left out
+type DeviceResponse struct {
+	ID         uuid.UUID      `json:"id"`
+	Platform   DevicePlatform `json:"platform"`
+	CreatedAt  time.Time      `json:"created_at"`
+	LastSeenAt time.Time      `json:"last_seen_at"`
+}
+
+// ReplayDeliveriesRequest defines the expected JSON body for replaying the failed webhook deliveries of a time range
+type ReplayDeliveriesRequest struct {
+	From time.Time `json:"from" validate:"required"`
+	To   time.Time `json:"to" validate:"required"`
+}
+
+// DeliveryResponse defines the structure of a webhook delivery returned by the API, its payload left out
+type DeliveryResponse struct {
+	ID            uuid.UUID       `json:"id"`
+	Category      notify.Category `json:"category"`
+	URL           string          `json:"url"`
+	Status        DeliveryStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	LastAttemptAt time.Time       `json:"last_attempt_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// listNotificationsHandler handles the HTTP request for listing the user's in-app notifications
+func (h *NotificationHandler) listNotificationsHandler(c echo.Context) error {
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	page, err := httpx.ParsePage(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	notifications, err := h.notificationService.ListInbox(c.Request().Context(), userID, unreadOnly, page.Limit, page.Offset)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		resp = append(resp, toNotificationResponse(n))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// markAsReadHandler handles the HTTP request for marking an in-app notification as read
+func (h *NotificationHandler) markAsReadHandler(c echo.Context) error {
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid notification id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.notificationService.MarkAsRead(c.Request().Context(), userID, notificationID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getPreferencesHandler handles the HTTP request for finding the user's notification preferences
+func (h *NotificationHandler) getPreferencesHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := h.notificationService.GetPreferences(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, ToPreferencesResponse(prefs))
+}
+
+// updatePreferencesHandler handles the HTTP request for updating the user's notification preferences
+func (h *NotificationHandler) updatePreferencesHandler(c echo.Context) error {
+	var req UpdatePreferencesRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	channels := make(map[notify.Category][]ChannelKind, len(req.Categories))
+	for _, cat := range req.Categories {
+		channels[cat.Category] = cat.Channels
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(c.Request().Context(), UpdatePreferencesParams{
+		UserID:     userID,
+		WebhookURL: req.WebhookURL,
+		Channels:   channels,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, ToPreferencesResponse(prefs))
+}
+
+// registerDeviceHandler handles the HTTP request for registering a device to receive push notifications
+func (h *NotificationHandler) registerDeviceHandler(c echo.Context) error {
+	var req RegisterDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	device, err := h.notificationService.RegisterDevice(c.Request().Context(), RegisterDeviceParams{
+		UserID:   userID,
+		Platform: req.Platform,
+		Token:    req.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toDeviceResponse(device))
+}
+
+// listDevicesHandler handles the HTTP request for listing the devices the user receives push notifications on
+func (h *NotificationHandler) listDevicesHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	devices, err := h.notificationService.ListDevices(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]DeviceResponse, 0, len(devices))
+	for _, d := range devices {
+		resp = append(resp, toDeviceResponse(d))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// unregisterDeviceHandler handles the HTTP request for stopping the push notifications of a device
+func (h *NotificationHandler) unregisterDeviceHandler(c echo.Context) error {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid device id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.notificationService.UnregisterDevice(c.Request().Context(), userID, deviceID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listFailedDeliveriesHandler handles the HTTP request for listing the user's failed webhook deliveries
+func (h *NotificationHandler) listFailedDeliveriesHandler(c echo.Context) error {
+	from, err := timeQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := timeQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	page, err := httpx.ParsePage(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := h.notificationService.ListFailedDeliveries(c.Request().Context(), userID, from, to, page.Limit, page.Offset)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]DeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, toDeliveryResponse(d))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// replayDeliveryHandler handles the HTTP request for posting a failed webhook delivery again
+func (h *NotificationHandler) replayDeliveryHandler(c echo.Context) error {
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid delivery id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := h.notificationService.ReplayDelivery(c.Request().Context(), userID, deliveryID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toDeliveryResponse(delivery))
+}
+
+// replayDeliveriesHandler handles the HTTP request for replaying the failed webhook deliveries of a time range
+// The replay runs in the background, answered with 202 Accepted and the job to poll
+func (h *NotificationHandler) replayDeliveriesHandler(c echo.Context) error {
+	var req ReplayDeliveriesRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.notificationService.ReplayDeliveries(c.Request().Context(), userID, req.From, req.To)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendAccepted(c, jobs.ToJobResponse(job))
+}
+
+// timeQueryParam parses an optional RFC 3339 query parameter, returning the zero time when it is absent
+func timeQueryParam(c echo.Context, name string) (time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+" time format, expected RFC 3339")
+	}
+	return t, nil
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// ToPreferencesResponse maps the internal Preferences domain model to the public PreferencesResponse DTO
+// Every known category is listed, including the ones still using the default channels
+func ToPreferencesResponse(prefs *Preferences) PreferencesResponse {
+	resp := PreferencesResponse{WebhookURL: prefs.WebhookURL, WebhookDisabledAt: prefs.WebhookDisabledAt}
+	if !prefs.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &prefs.UpdatedAt
+	}
+
+	for _, category := range notify.Category("").Values() {
+		c := notify.Category(category)
+		resp.Categories = append(resp.Categories, CategoryChannelsResponse{
+			Category:  c,
+			Channels:  prefs.ChannelsFor(c),
+			Mandatory: append([]ChannelKind{}, mandatoryChannels[c]...),
+		})
+	}
+	return resp
+}
+
+// toNotificationResponse maps the internal Notification domain model to the public NotificationResponse DTO
+func toNotificationResponse(n *Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:        n.ID,
+		Category:  n.Category,
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      n.Data,
+		CreatedAt: n.CreatedAt,
+		ReadAt:    n.ReadAt,
+	}
+}
+
+// toDeviceResponse maps the internal Device domain model to the public DeviceResponse DTO
+func toDeviceResponse(d *Device) DeviceResponse {
+	return DeviceResponse{
+		ID:         d.ID,
+		Platform:   d.Platform,
+		CreatedAt:  d.CreatedAt,
+		LastSeenAt: d.LastSeenAt,
+	}
+}
+
+// toDeliveryResponse maps the internal WebhookDelivery domain model to the public DeliveryResponse DTO
+func toDeliveryResponse(d *WebhookDelivery) DeliveryResponse {
+	return DeliveryResponse{
+		ID:            d.ID,
+		Category:      d.Category,
+		URL:           d.URL,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		CreatedAt:     d.CreatedAt,
+		LastAttemptAt: d.LastAttemptAt,
+		DeliveredAt:   d.DeliveredAt,
+	}
+}
@@ -0,0 +1,15 @@
+package notifications
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// messages translates the notifications sent by the notifications module itself, about the user's webhook
+var messages = i18n.Catalog{
+	i18n.English: {
+		"webhook_disabled_title": "Webhook disabled",
+		"webhook_disabled_body":  "Your webhook %s failed every delivery since %s and was disabled, fix it and enable it again to replay the failed deliveries",
+	},
+	i18n.Portuguese: {
+		"webhook_disabled_title": "Webhook desativado",
+		"webhook_disabled_body":  "Seu webhook %s falhou em todas as entregas desde %s e foi desativado, corrija-o e ative-o novamente para reenviar as entregas que falharam",
+	},
+}
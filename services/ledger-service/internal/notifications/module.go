@@ -0,0 +1,66 @@
+package notifications
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the notification repositories, channels, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	mailer  *EmailChannel
+	handler *NotificationHandler
+}
+
+// NewModule creates the notifications module
+// Email and push are only logged until a provider is configured for them
+func NewModule(deps module.Deps) *Module {
+	inboxRepo := NewPostgresNotificationRepository(deps.Postgres.Pool)
+	prefsRepo := NewPostgresPreferencesRepository(deps.Postgres.Pool)
+	deviceRepo := NewPostgresDeviceRepository(deps.Postgres.Pool)
+	deliveryRepo := NewPostgresDeliveryRepository(deps.Postgres.Pool)
+
+	emailChannel := NewEmailChannel(deps.Identity, LogSender{})
+	cfg := deps.Config.Notifications
+
+	notificationSvc := NewService(prefsRepo, inboxRepo, deviceRepo, deliveryRepo, deps.Jobs, cfg.WebhookDisableAfter, deps.IDs, deps.Clock,
+		NewInAppChannel(inboxRepo, deps.IDs, deps.Clock),
+		emailChannel,
+		NewPushChannel(deviceRepo, LogSender{}),
+		NewWebhookChannel(deliveryRepo, cfg.WebhookTimeout, cfg.WebhookSigningSecret, deps.IDs, deps.Clock),
+	)
+
+	return &Module{
+		service: notificationSvc,
+		mailer:  emailChannel,
+		handler: NewNotificationHandler(notificationSvc),
+	}
+}
+
+// Notifier returns the service other modules use to notify users (reminders, budget alerts, security events)
+func (m *Module) Notifier() notify.Notifier {
+	return m.service
+}
+
+// Mailer returns the email sender other modules use for emails of their own (e.g., the weekly summary)
+func (m *Module) Mailer() notify.Mailer {
+	return m.mailer
+}
+
+// Service returns the notification service, used by the preferences module to change the channels of each category
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "notifications"
+}
+
+// RegisterRoutes mounts the notifications routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,460 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ PreferencesRepository  = (*PostgresPreferencesRepository)(nil)
+	_ NotificationRepository = (*PostgresNotificationRepository)(nil)
+	_ DeviceRepository       = (*PostgresDeviceRepository)(nil)
+	_ DeliveryRepository     = (*PostgresDeliveryRepository)(nil)
+)
+
+// ----- Preferences ----- //
+
+// PostgresPreferencesRepository is a PostgreSQL implementation of the PreferencesRepository interface
+type PostgresPreferencesRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPreferencesRepository creates a new PostgresPreferencesRepository
+func NewPostgresPreferencesRepository(pool *pgxpool.Pool) *PostgresPreferencesRepository {
+	return &PostgresPreferencesRepository{pool: pool}
+}
+
+// preferencesModel represents the notification preferences structure in the database
+type preferencesModel struct {
+	UserID            uuid.UUID  `db:"user_id"`
+	Channels          []byte     `db:"channels"` // JSON object mapping each category to its channels
+	WebhookURL        *string    `db:"webhook_url"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+	WebhookDisabledAt *time.Time `db:"webhook_disabled_at"`
+}
+
+// FindByUserID retrieves the preferences of a user, returning nil when they were never saved
+func (r *PostgresPreferencesRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	query := `
+		SELECT user_id, channels, webhook_url, updated_at, webhook_disabled_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+
+	var m preferencesModel
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&m.UserID, &m.Channels, &m.WebhookURL, &m.UpdatedAt, &m.WebhookDisabledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+
+	return toPreferencesDomain(&m)
+}
+
+// Save inserts or replaces the preferences of a user
+func (r *PostgresPreferencesRepository) Save(ctx context.Context, prefs *Preferences) error {
+	m, err := toPreferencesPersistence(prefs)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO notification_preferences (user_id, channels, webhook_url, updated_at, webhook_disabled_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			channels = EXCLUDED.channels,
+			webhook_url = EXCLUDED.webhook_url,
+			updated_at = EXCLUDED.updated_at,
+			webhook_disabled_at = EXCLUDED.webhook_disabled_at
+	`
+
+	if _, err := r.pool.Exec(ctx, query, m.UserID, m.Channels, m.WebhookURL, m.UpdatedAt, m.WebhookDisabledAt); err != nil {
+		return fmt.Errorf("failed to upsert notification preferences: %v", err)
+	}
+	return nil
+}
+
+// toPreferencesDomain maps a persistence preferencesModel to domain Preferences
+func toPreferencesDomain(m *preferencesModel) (*Preferences, error) {
+	var channels map[notify.Category][]ChannelKind
+	if err := json.Unmarshal(m.Channels, &channels); err != nil {
+		return nil, fmt.Errorf("failed to decode notification channels: %w", err)
+	}
+
+	prefs := &Preferences{
+		UserID:            m.UserID,
+		Channels:          channels,
+		UpdatedAt:         m.UpdatedAt,
+		WebhookDisabledAt: m.WebhookDisabledAt,
+	}
+	if m.WebhookURL != nil {
+		prefs.WebhookURL = *m.WebhookURL
+	}
+	return prefs, nil
+}
+
+// toPreferencesPersistence maps domain Preferences to a persistence preferencesModel
+func toPreferencesPersistence(prefs *Preferences) (*preferencesModel, error) {
+	channels, err := json.Marshal(prefs.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification channels: %w", err)
+	}
+
+	m := &preferencesModel{
+		UserID:            prefs.UserID,
+		Channels:          channels,
+		UpdatedAt:         prefs.UpdatedAt,
+		WebhookDisabledAt: prefs.WebhookDisabledAt,
+	}
+	if prefs.WebhookURL != "" {
+		m.WebhookURL = &prefs.WebhookURL
+	}
+	return m, nil
+}
+
+// ----- In-app notifications ----- //
+
+// PostgresNotificationRepository is a PostgreSQL implementation of the NotificationRepository interface
+type PostgresNotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresNotificationRepository creates a new PostgresNotificationRepository
+func NewPostgresNotificationRepository(pool *pgxpool.Pool) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{pool: pool}
+}
+
+// notificationModel represents the in-app notification structure in the database
+type notificationModel struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	Category  string     `db:"category"`
+	Title     string     `db:"title"`
+	Body      string     `db:"body"`
+	Data      []byte     `db:"data"`
+	CreatedAt time.Time  `db:"created_at"`
+	ReadAt    *time.Time `db:"read_at"`
+}
+
+// Save inserts a new in-app notification
+func (r *PostgresNotificationRepository) Save(ctx context.Context, n *Notification) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, category, title, body, data, created_at, read_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.pool.Exec(ctx, query, n.ID, n.UserID, string(n.Category), n.Title, n.Body, data, n.CreatedAt, n.ReadAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert notification: %v", err)
+	}
+	return nil
+}
+
+// FindByUserID retrieves a page of the notifications of a user, newest first, optionally only the unread ones
+func (r *PostgresNotificationRepository) FindByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*Notification, error) {
+	query := `
+		SELECT id, user_id, category, title, body, data, created_at, read_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*Notification, 0)
+	for rows.Next() {
+		var m notificationModel
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Category, &m.Title, &m.Body, &m.Data, &m.CreatedAt, &m.ReadAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification row: %w", err)
+		}
+
+		n, err := toNotificationDomain(&m)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification rows: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkAsRead sets the read time of a user's notification, keeping the first read time if already read
+func (r *PostgresNotificationRepository) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, readAt time.Time) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`
+
+	tag, err := r.pool.Exec(ctx, query, notificationID, userID, readAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotificationNotFound.With("notification_id", notificationID)
+	}
+	return nil
+}
+
+// toNotificationDomain maps a persistence notificationModel to a domain Notification
+func toNotificationDomain(m *notificationModel) (*Notification, error) {
+	var data map[string]string
+	if len(m.Data) > 0 {
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode notification data: %w", err)
+		}
+	}
+
+	return &Notification{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Category:  notify.Category(m.Category),
+		Title:     m.Title,
+		Body:      m.Body,
+		Data:      data,
+		CreatedAt: m.CreatedAt,
+		ReadAt:    m.ReadAt,
+	}, nil
+}
+
+// ----- Push devices ----- //
+
+// PostgresDeviceRepository is a PostgreSQL implementation of the DeviceRepository interface
+type PostgresDeviceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresDeviceRepository creates a new PostgresDeviceRepository
+func NewPostgresDeviceRepository(pool *pgxpool.Pool) *PostgresDeviceRepository {
+	return &PostgresDeviceRepository{pool: pool}
+}
+
+// Save inserts a device or, when its token is already registered, moves it to the device's user and refreshes it.
+// The ID and creation time of the stored device are written back to it
+func (r *PostgresDeviceRepository) Save(ctx context.Context, device *Device) error {
+	query := `
+		INSERT INTO push_devices (id, user_id, platform, token, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (token)
+		DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			platform = EXCLUDED.platform,
+			last_seen_at = EXCLUDED.last_seen_at
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query, device.ID, device.UserID, string(device.Platform), device.Token, device.CreatedAt, device.LastSeenAt).
+		Scan(&device.ID, &device.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert push device: %v", err)
+	}
+	return nil
+}
+
+// FindByUserID retrieves every device of a user, most recently seen first
+func (r *PostgresDeviceRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Device, error) {
+	query := `
+		SELECT id, user_id, platform, token, created_at, last_seen_at
+		FROM push_devices
+		WHERE user_id = $1
+		ORDER BY last_seen_at DESC, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := make([]*Device, 0)
+	for rows.Next() {
+		var d Device
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Platform, &d.Token, &d.CreatedAt, &d.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push device row: %w", err)
+		}
+		devices = append(devices, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push device rows: %w", err)
+	}
+
+	return devices, nil
+}
+
+// Delete removes a device of a user
+func (r *PostgresDeviceRepository) Delete(ctx context.Context, userID, deviceID uuid.UUID) error {
+	query := `
+		DELETE FROM push_devices
+		WHERE id = $1 AND user_id = $2
+	`
+
+	tag, err := r.pool.Exec(ctx, query, deviceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete push device: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDeviceNotFound.With("device_id", deviceID)
+	}
+	return nil
+}
+
+// ----- Webhook deliveries ----- //
+
+// PostgresDeliveryRepository is a PostgreSQL implementation of the DeliveryRepository interface
+type PostgresDeliveryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresDeliveryRepository creates a new PostgresDeliveryRepository
+func NewPostgresDeliveryRepository(pool *pgxpool.Pool) *PostgresDeliveryRepository {
+	return &PostgresDeliveryRepository{pool: pool}
+}
+
+// deliveryColumns are the columns scanned by scanDelivery
+const deliveryColumns = `id, user_id, category, url, payload, status, attempts, last_error, created_at, last_attempt_at, delivered_at`
+
+// Save inserts a delivery or records a new attempt of it
+func (r *PostgresDeliveryRepository) Save(ctx context.Context, delivery *WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (` + deliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			url = EXCLUDED.url,
+			status = EXCLUDED.status,
+			attempts = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			delivered_at = EXCLUDED.delivered_at
+	`
+
+	var lastError *string
+	if delivery.LastError != "" {
+		lastError = &delivery.LastError
+	}
+	_, err := r.pool.Exec(ctx, query,
+		delivery.ID, delivery.UserID, string(delivery.Category), delivery.URL, delivery.Payload, string(delivery.Status),
+		delivery.Attempts, lastError, delivery.CreatedAt, delivery.LastAttemptAt, delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert webhook delivery: %v", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a delivery of a user
+func (r *PostgresDeliveryRepository) FindByID(ctx context.Context, userID, deliveryID uuid.UUID) (*WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = $1 AND user_id = $2
+	`
+
+	delivery, err := scanDelivery(r.pool.QueryRow(ctx, query, deliveryID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeliveryNotFound.With("delivery_id", deliveryID)
+		}
+		return nil, fmt.Errorf("failed to fetch webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// FindFailed retrieves a page of the failed deliveries of a user created within [from, to), the most recent first
+func (r *PostgresDeliveryRepository) FindFailed(ctx context.Context, userID uuid.UUID, from, to time.Time, limit, offset int) ([]*WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE user_id = $1 AND status = $2
+			AND ($3::timestamptz IS NULL OR created_at >= $3)
+			AND ($4::timestamptz IS NULL OR created_at < $4)
+		ORDER BY created_at DESC, id
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, string(DeliveryFailed), nullableTime(from), nullableTime(to), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook delivery rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// FailingSince retrieves the creation time of the first failed delivery after the last successful one
+func (r *PostgresDeliveryRepository) FailingSince(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	query := `
+		SELECT MIN(created_at)
+		FROM webhook_deliveries
+		WHERE user_id = $1 AND status = $2
+			AND created_at > COALESCE(
+				(SELECT MAX(delivered_at) FROM webhook_deliveries WHERE user_id = $1 AND status = $3),
+				'-infinity'
+			)
+	`
+
+	var since *time.Time
+	if err := r.pool.QueryRow(ctx, query, userID, string(DeliveryFailed), string(DeliveryDelivered)).Scan(&since); err != nil {
+		return nil, fmt.Errorf("failed to query webhook failures: %w", err)
+	}
+	return since, nil
+}
+
+// scanDelivery scans a row of the deliveryColumns
+func scanDelivery(row pgx.Row) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+	var lastError *string
+	err := row.Scan(&d.ID, &d.UserID, &d.Category, &d.URL, &d.Payload, &d.Status, &d.Attempts, &lastError,
+		&d.CreatedAt, &d.LastAttemptAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastError != nil {
+		d.LastError = *lastError
+	}
+	return &d, nil
+}
+
+// nullableTime returns nil for the zero time, leaving an end of a range open
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,382 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+var _ notify.Notifier = (*Service)(nil)
+
+const (
+	// replayJobKind identifies the replays of a range of webhook deliveries on the job status endpoint
+	replayJobKind = "webhook_replay"
+	// maxReplayedDeliveries bounds the deliveries a replay of a range posts again
+	maxReplayedDeliveries = 500
+)
+
+// Service delivers notifications through the enabled channels and manages user preferences
+type Service struct {
+	prefsRepo           PreferencesRepository
+	inboxRepo           NotificationRepository
+	devices             DeviceRepository
+	deliveries          DeliveryRepository
+	jobs                *jobs.Service
+	webhookDisableAfter time.Duration
+	channels            map[ChannelKind]Channel
+	ids                 idgen.Generator
+	clock               clock.Clock
+}
+
+// NewService creates a new instance of the notifications Service with the given delivery channels
+// A webhook failing every delivery for webhookDisableAfter is disabled, 0 never disables it
+func NewService(
+	prefsRepo PreferencesRepository,
+	inboxRepo NotificationRepository,
+	devices DeviceRepository,
+	deliveries DeliveryRepository,
+	jobs *jobs.Service,
+	webhookDisableAfter time.Duration,
+	ids idgen.Generator,
+	clock clock.Clock,
+	channels ...Channel,
+) *Service {
+	byKind := make(map[ChannelKind]Channel, len(channels))
+	for _, ch := range channels {
+		byKind[ch.Kind()] = ch
+	}
+
+	return &Service{
+		prefsRepo:           prefsRepo,
+		inboxRepo:           inboxRepo,
+		devices:             devices,
+		deliveries:          deliveries,
+		jobs:                jobs,
+		webhookDisableAfter: webhookDisableAfter,
+		channels:            byKind,
+		ids:                 ids,
+		clock:               clock,
+	}
+}
+
+// Notify delivers msg through every channel the user enabled for its category
+// A failing channel does not prevent delivery through the others; the error reports every failure
+func (s *Service) Notify(ctx context.Context, msg notify.Message) error {
+	prefs, err := s.GetPreferences(ctx, msg.UserID)
+	if err != nil {
+		return err
+	}
+
+	log := ctxlogger.GetLogger(ctx).With(
+		slog.String("user_id", msg.UserID.String()),
+		slog.String("category", string(msg.Category)),
+	)
+
+	var errs []error
+	for _, kind := range prefs.ChannelsFor(msg.Category) {
+		ch, ok := s.channels[kind]
+		if !ok {
+			errs = append(errs, ErrChannelUnavailable.With("channel", kind))
+			continue
+		}
+
+		if err := ch.Send(ctx, prefs, msg); err != nil {
+			log.Error("failed to deliver notification", slog.String("channel", string(kind)), slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("%s: %w", kind, err))
+			if kind == ChannelWebhook {
+				s.disableFailingWebhook(ctx, prefs)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrDeliveryFailed, errors.Join(errs...))
+	}
+	return nil
+}
+
+// GetPreferences is the use case for finding the user's preferences, falling back to the defaults
+func (s *Service) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	prefs, err := s.prefsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification preferences: %w", err)
+	}
+	if prefs == nil {
+		return DefaultPreferences(userID), nil
+	}
+	return prefs, nil
+}
+
+// UpdatePreferencesParams holds the changes to apply to the user's preferences
+// Categories left out of Channels keep their current channels
+type UpdatePreferencesParams struct {
+	UserID     uuid.UUID
+	WebhookURL *string
+	Channels   map[notify.Category][]ChannelKind
+}
+
+// UpdatePreferences is the use case for changing the channels enabled for each category
+func (s *Service) UpdatePreferences(ctx context.Context, params UpdatePreferencesParams) (*Preferences, error) {
+	prefs, err := s.GetPreferences(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WebhookURL != nil {
+		prefs.WebhookURL = *params.WebhookURL
+		prefs.WebhookDisabledAt = nil
+	}
+
+	for category, kinds := range params.Channels {
+		if err := prefs.SetChannels(category, kinds, s.clock); err != nil {
+			return nil, err
+		}
+		if slices.Contains(kinds, ChannelWebhook) {
+			prefs.WebhookDisabledAt = nil
+		}
+	}
+
+	// Removing the webhook URL disables the channel everywhere instead of leaving it broken
+	if prefs.WebhookURL == "" {
+		for category := range prefs.Channels {
+			if err := prefs.SetChannels(category, withoutChannel(prefs.ChannelsFor(category), ChannelWebhook), s.clock); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	prefs.UpdatedAt = s.clock.Now()
+	if err := s.prefsRepo.Save(ctx, prefs); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// ListInbox is the use case for listing a page of the user's in-app notifications, newest first
+func (s *Service) ListInbox(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*Notification, error) {
+	notifications, err := s.inboxRepo.FindByUserID(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkAsRead is the use case for marking one of the user's in-app notifications as read
+func (s *Service) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	if err := s.inboxRepo.MarkAsRead(ctx, userID, notificationID, s.clock.Now()); err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return nil
+}
+
+// RegisterDeviceParams holds the device token the user's app received from its push provider
+type RegisterDeviceParams struct {
+	UserID   uuid.UUID
+	Platform DevicePlatform
+	Token    string
+}
+
+// RegisterDevice is the use case for registering a device to receive push notifications. Apps register their token
+// on every launch, so registering a known token only refreshes it, moving it to the user when it was someone else's
+func (s *Service) RegisterDevice(ctx context.Context, params RegisterDeviceParams) (*Device, error) {
+	now := s.clock.Now()
+	device := &Device{
+		ID:         s.ids.NewID(),
+		UserID:     params.UserID,
+		Platform:   params.Platform,
+		Token:      params.Token,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := s.devices.Save(ctx, device); err != nil {
+		return nil, fmt.Errorf("failed to register push device: %w", err)
+	}
+	return device, nil
+}
+
+// ListDevices is the use case for listing the devices the user receives push notifications on
+func (s *Service) ListDevices(ctx context.Context, userID uuid.UUID) ([]*Device, error) {
+	devices, err := s.devices.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push devices: %w", err)
+	}
+	return devices, nil
+}
+
+// UnregisterDevice is the use case for stopping the push notifications of one of the user's devices (e.g., on logout)
+func (s *Service) UnregisterDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	if err := s.devices.Delete(ctx, userID, deviceID); err != nil {
+		return fmt.Errorf("failed to unregister push device: %w", err)
+	}
+	return nil
+}
+
+// ListFailedDeliveries is the use case for listing a page of the user's failed webhook deliveries created within
+// [from, to), newest first. A zero from or to leaves that end of the range open
+func (s *Service) ListFailedDeliveries(ctx context.Context, userID uuid.UUID, from, to time.Time, limit, offset int) ([]*WebhookDelivery, error) {
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, ErrInvalidReplayRange
+	}
+
+	deliveries, err := s.deliveries.FindFailed(ctx, userID, from, to, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ReplayDelivery is the use case for posting a failed webhook delivery again, to the current webhook URL of the user
+// The delivery keeps its ID, so the receiver can tell the replay apart from a new message
+func (s *Service) ReplayDelivery(ctx context.Context, userID, deliveryID uuid.UUID) (*WebhookDelivery, error) {
+	delivery, err := s.deliveries.FindByID(ctx, userID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status != DeliveryFailed {
+		return nil, ErrDeliveryNotFailed.With("delivery_id", deliveryID)
+	}
+
+	webhook, url, err := s.replayTarget(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The outcome is recorded on the delivery, which is returned failed again when the webhook still fails
+	if err := webhook.Redeliver(ctx, delivery, url); err != nil {
+		ctxlogger.GetLogger(ctx).Warn("failed to replay webhook delivery",
+			slog.String("delivery_id", deliveryID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+	return delivery, nil
+}
+
+// ReplayResult summarizes the replay of a range of webhook deliveries
+type ReplayResult struct {
+	Replayed  int  `json:"replayed"`
+	Delivered int  `json:"delivered"`
+	Failed    int  `json:"failed"`
+	Truncated bool `json:"truncated"` // Truncated reports that the range held more failed deliveries than a replay posts
+}
+
+// ReplayDeliveries is the use case for posting again, in the background, every failed webhook delivery the user got
+// within [from, to), oldest first. A replay posts at most maxReplayedDeliveries, the next ones being left to another
+func (s *Service) ReplayDeliveries(ctx context.Context, userID uuid.UUID, from, to time.Time) (*jobs.Job, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidReplayRange
+	}
+	webhook, url, err := s.replayTarget(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobs.Enqueue(ctx, userID, replayJobKind, func(ctx context.Context, progress jobs.ProgressFunc) (any, error) {
+		deliveries, err := s.deliveries.FindFailed(ctx, userID, from, to, maxReplayedDeliveries+1, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find webhook deliveries to replay: %w", err)
+		}
+
+		result := &ReplayResult{}
+		if len(deliveries) > maxReplayedDeliveries {
+			deliveries, result.Truncated = deliveries[:maxReplayedDeliveries], true
+		}
+		// FindFailed returns the most recent first, the receiver gets them back in the order they were sent
+		slices.Reverse(deliveries)
+
+		for i, delivery := range deliveries {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			result.Replayed++
+			if err := webhook.Redeliver(ctx, delivery, url); err != nil {
+				result.Failed++
+			} else {
+				result.Delivered++
+			}
+			progress((i + 1) * 100 / len(deliveries))
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start webhook replay: %w", err)
+	}
+	return job, nil
+}
+
+// replayTarget returns the webhook channel and the URL the deliveries of the user are replayed to
+func (s *Service) replayTarget(ctx context.Context, userID uuid.UUID) (*WebhookChannel, string, error) {
+	webhook, ok := s.channels[ChannelWebhook].(*WebhookChannel)
+	if !ok {
+		return nil, "", ErrChannelUnavailable.With("channel", ChannelWebhook)
+	}
+
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if prefs.WebhookURL == "" {
+		return nil, "", ErrWebhookURLRequired
+	}
+	return webhook, prefs.WebhookURL, nil
+}
+
+// disableFailingWebhook disables the webhook of the user once it failed every delivery for webhookDisableAfter, so a
+// dead endpoint stops receiving deliveries, and tells the user through their other channels
+// A failure to disable it is only logged, the next failed delivery trying again
+func (s *Service) disableFailingWebhook(ctx context.Context, prefs *Preferences) {
+	if s.webhookDisableAfter <= 0 {
+		return
+	}
+	log := ctxlogger.GetLogger(ctx).With(slog.String("user_id", prefs.UserID.String()))
+
+	since, err := s.deliveries.FailingSince(ctx, prefs.UserID)
+	if err != nil {
+		log.Error("failed to check webhook failures", slog.String("error", err.Error()))
+		return
+	}
+	if since == nil || s.clock.Now().Sub(*since) < s.webhookDisableAfter {
+		return
+	}
+
+	prefs.DisableWebhook(s.clock)
+	if err := s.prefsRepo.Save(ctx, prefs); err != nil {
+		log.Error("failed to disable failing webhook", slog.String("error", err.Error()))
+		return
+	}
+	log.Warn("disabled failing webhook", slog.Time("failing_since", *since))
+
+	lang, _ := i18n.FromContext(ctx)
+	err = s.Notify(ctx, notify.Message{
+		UserID:   prefs.UserID,
+		Category: notify.CategorySecurity,
+		Title:    messages.Format(lang, "webhook_disabled_title"),
+		Body:     messages.Format(lang, "webhook_disabled_body", prefs.WebhookURL, since.UTC().Format(time.RFC3339)),
+	})
+	if err != nil {
+		log.Error("failed to notify disabled webhook", slog.String("error", err.Error()))
+	}
+}
+
+// withoutChannel returns kinds without the given channel
+func withoutChannel(kinds []ChannelKind, removed ChannelKind) []ChannelKind {
+	kept := make([]ChannelKind, 0, len(kinds))
+	for _, kind := range kinds {
+		if kind != removed {
+			kept = append(kept, kind)
+		}
+	}
+	return kept
+}
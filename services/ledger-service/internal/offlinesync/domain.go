@@ -0,0 +1,91 @@
+package offlinesync
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSyncCursor  = errx.New(errx.CategoryValidation, "INVALID_SYNC_CURSOR", "sync cursor is invalid")
+	ErrTooManySyncChanges = errx.New(errx.CategoryValidation, "TOO_MANY_SYNC_CHANGES", "too many offline changes in a single sync")
+)
+
+const (
+	// MaxChangesPerSync bounds the offline changes sent in a single sync, a longer queue being sent over several syncs
+	MaxChangesPerSync = 200
+
+	// maxAccountsPerSync bounds the changed accounts returned by a sync, the client syncing again while there are more
+	maxAccountsPerSync = 50
+
+	// settleDelay keeps the changes recorded too recently out of a sync, so a change whose transaction commits after
+	// a later one is not skipped by the cursor
+	settleDelay = 5 * time.Second
+)
+
+// AccountSyncer reads the accounts of the user and applies the offline changes, satisfied by the ledger Service
+type AccountSyncer interface {
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+	ApplyOfflineChanges(ctx context.Context, params ledger.ApplyOfflineChangesParams) (*ledger.Account, []ledger.OfflineChangeResult, error)
+}
+
+// ChangeFeed reads the changes of the accounts of a user in the order they were recorded
+type ChangeFeed interface {
+	// ChangesSince returns the changes recorded after the position and before the given time, at most limit of them
+	ChangesSince(ctx context.Context, userID uuid.UUID, after int64, before time.Time, limit int) ([]AccountChange, error)
+}
+
+// AccountChange is the last change of an account, only its latest version being synced
+type AccountChange struct {
+	AccountID uuid.UUID
+	Position  int64 // Position grows with every change recorded for any account, cursors pointing to it
+	Version   int64
+	Deleted   bool // Deleted accounts are reported for the clients to forget them
+}
+
+// Cursor is where a client stopped reading the changes, opaque to the clients
+type Cursor int64
+
+// ParseCursor parses the cursor returned by a previous sync, an empty one starting from the beginning
+func ParseCursor(raw string) (Cursor, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	position, err := strconv.ParseInt(raw, 36, 64)
+	if err != nil || position < 0 {
+		return 0, ErrInvalidSyncCursor
+	}
+	return Cursor(position), nil
+}
+
+// String formats the cursor for the clients
+func (c Cursor) String() string {
+	return strconv.FormatInt(int64(c), 36)
+}
+
+// ChangeSet is the offline changes of the client to an account, applied in order
+type ChangeSet struct {
+	AccountID uuid.UUID
+	Changes   []ledger.OfflineChange
+}
+
+// ChangeSetResult is the outcome of the changes to an account, Version being the account version after them
+// It is zero when the account was not found
+type ChangeSetResult struct {
+	AccountID uuid.UUID
+	Version   int64
+	Results   []ledger.OfflineChangeResult
+}
+
+// SyncResult is the outcome of the offline changes and the accounts changed since the cursor of the client
+type SyncResult struct {
+	ChangeSets        []ChangeSetResult
+	Accounts          []*ledger.Account
+	DeletedAccountIDs []uuid.UUID
+	Cursor            Cursor
+	HasMore           bool // HasMore tells the client to sync again for the remaining changes
+}
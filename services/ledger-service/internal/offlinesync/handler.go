@@ -0,0 +1,296 @@
+package offlinesync
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// SyncHandler holds dependencies for the offline sync HTTP handlers
+type SyncHandler struct {
+	syncService *Service
+}
+
+// NewSyncHandler creates a new instance of SyncHandler
+func NewSyncHandler(syncService *Service) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// RegisterRoutes sets up the API routes for the offline sync module
+func (h *SyncHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.POST("/sync", h.syncHandler)
+}
+
+// SyncRequest defines the expected JSON body for a sync, the change sets being empty when the client has nothing queued
+type SyncRequest struct {
+	Cursor     string             `json:"cursor,omitempty" validate:"max=16"` // Cursor is the one returned by the previous sync, omitted on the first one
+	ChangeSets []ChangeSetRequest `json:"change_sets,omitempty" validate:"omitempty,dive"`
+}
+
+// ChangeSetRequest defines the offline changes of the client to an account, applied in order
+type ChangeSetRequest struct {
+	AccountID uuid.UUID       `json:"account_id" validate:"required"`
+	Changes   []ChangeRequest `json:"changes" validate:"required,min=1,dive"`
+}
+
+// ChangeRequest defines an operation on a transaction the client queued offline
+type ChangeRequest struct {
+	ClientID      string                   `json:"client_id" validate:"required,max=64"` // ClientID identifies the change in the queue of the client
+	Type          ledger.OfflineChangeType `json:"type" validate:"required,enum"`
+	TransactionID uuid.UUID                `json:"transaction_id" validate:"required"`        // Chosen by the client for CREATE_TRANSACTION
+	BaseRevision  string                   `json:"base_revision,omitempty" validate:"max=32"` // The revision of the transaction the client changed
+
+	// The details of a CREATE_TRANSACTION change
+	TransactionType ledger.TransactionType `json:"transaction_type,omitempty" validate:"omitempty,enum"`
+	Description     string                 `json:"description,omitempty" validate:"max=100"`
+	Observation     string                 `json:"observation,omitempty" validate:"max=2500"`
+	Amount          string                 `json:"amount,omitempty" validate:"max=32"` // Decimal string in the account currency (e.g., "1.234,56" or "1234.56")
+	CategoryID      *uuid.UUID             `json:"category_id,omitempty"`
+	DueDate         *time.Time             `json:"due_date,omitempty"` // Defaults to PaidAt when omitted
+	Tags            []string               `json:"tags,omitempty" validate:"max=10,dive,max=30"`
+	Location        *LocationRequest       `json:"location,omitempty"`
+
+	PaidAt *time.Time `json:"paid_at,omitempty"` // Required by MARK_PAID
+
+	amountMinorUnits *int64 // amountMinorUnits is the amount of a version 1 body
+}
+
+// changeBody is the shape of ChangeRequest without its methods, decoded by SyncRequest.BindVersion
+type changeBody ChangeRequest
+
+// BindVersion decodes the version 1 bodies, whose amounts are in minor units of the account currencies
+func (r *SyncRequest) BindVersion(_ int, decode func(any) error) error {
+	var v1 struct {
+		Cursor     string `json:"cursor,omitempty"`
+		ChangeSets []struct {
+			AccountID uuid.UUID `json:"account_id"`
+			// Amount comes before the embedded body so it takes the "amount" key
+			Changes []struct {
+				Amount *int64 `json:"amount,omitempty"`
+				changeBody
+			} `json:"changes"`
+		} `json:"change_sets,omitempty"`
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+
+	*r = SyncRequest{Cursor: v1.Cursor}
+	for _, set := range v1.ChangeSets {
+		changeSet := ChangeSetRequest{AccountID: set.AccountID}
+		for _, change := range set.Changes {
+			req := ChangeRequest(change.changeBody)
+			req.amountMinorUnits = change.Amount
+			changeSet.Changes = append(changeSet.Changes, req)
+		}
+		r.ChangeSets = append(r.ChangeSets, changeSet)
+	}
+	return nil
+}
+
+// LocationRequest defines where a created transaction was made, in the coordinates reported by the device
+type LocationRequest struct {
+	Latitude  *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+	PlaceName string   `json:"place_name,omitempty" validate:"max=100"`
+}
+
+// LocationResponse defines where a transaction was made, returned by the API
+type LocationResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	PlaceName string  `json:"place_name,omitempty"`
+}
+
+// ChangeResultResponse defines the outcome of an offline change returned by the API
+type ChangeResultResponse struct {
+	ClientID  string                     `json:"client_id"`
+	AccountID uuid.UUID                  `json:"account_id"`
+	Status    ledger.OfflineChangeStatus `json:"status"`
+	Code      string                     `json:"code,omitempty"` // Code is the error code of the REJECTED changes
+}
+
+// SyncedAccountResponse defines the latest version of a changed account returned by the API, with all its transactions
+type SyncedAccountResponse struct {
+	ID                      uuid.UUID                   `json:"id"`
+	Version                 int64                       `json:"version"`
+	Name                    string                      `json:"name"`
+	Currency                string                      `json:"currency"`
+	Kind                    ledger.AccountKind          `json:"kind"`
+	IncludeInOverallBalance bool                        `json:"include_in_overall_balance"`
+	ArchivedAt              *time.Time                  `json:"archived_at,omitempty"`
+	DeleteAfter             *time.Time                  `json:"delete_after,omitempty"`
+	Transactions            []SyncedTransactionResponse `json:"transactions"`
+}
+
+// SyncedTransactionResponse defines a transaction of a changed account returned by the API
+// Revision is sent back as the base_revision of the offline changes to the transaction
+type SyncedTransactionResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	Revision    string                 `json:"revision"`
+	Type        ledger.TransactionType `json:"type"`
+	Description string                 `json:"description"`
+	Observation string                 `json:"observation,omitempty"`
+	Amount      int64                  `json:"amount"`
+	CategoryID  *uuid.UUID             `json:"category_id,omitempty"`
+	DueDate     time.Time              `json:"due_date"`
+	PaidAt      *time.Time             `json:"paid_at,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Location    *LocationResponse      `json:"location,omitempty"`
+}
+
+// SyncResponse defines the outcome of a sync returned by the API
+type SyncResponse struct {
+	Results           []ChangeResultResponse  `json:"results"`
+	Accounts          []SyncedAccountResponse `json:"accounts"`
+	DeletedAccountIDs []uuid.UUID             `json:"deleted_account_ids"`
+	Cursor            string                  `json:"cursor"`
+	HasMore           bool                    `json:"has_more"` // HasMore tells the client to sync again right away with the new cursor
+}
+
+// syncHandler handles the HTTP request for applying the offline changes of the client and reading the changed accounts
+func (h *SyncHandler) syncHandler(c echo.Context) error {
+	var req SyncRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := ParseCursor(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	changeSets := make([]ChangeSet, len(req.ChangeSets))
+	for i, set := range req.ChangeSets {
+		changeSets[i] = ChangeSet{AccountID: set.AccountID, Changes: make([]ledger.OfflineChange, len(set.Changes))}
+		for j, change := range set.Changes {
+			changeSets[i].Changes[j] = toOfflineChange(change)
+		}
+	}
+
+	result, err := h.syncService.Sync(c.Request().Context(), SyncParams{
+		UserID:     userID,
+		Cursor:     cursor,
+		ChangeSets: changeSets,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toSyncResponse(result))
+}
+
+// currentUserID extracts the authenticated user's ID from the request context
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toOfflineChange maps a ChangeRequest DTO to the ledger OfflineChange
+func toOfflineChange(req ChangeRequest) ledger.OfflineChange {
+	change := ledger.OfflineChange{
+		ClientID:      req.ClientID,
+		Type:          req.Type,
+		TransactionID: req.TransactionID,
+		BaseRevision:  req.BaseRevision,
+		TxType:        req.TransactionType,
+		Description:   req.Description,
+		Observation:   req.Observation,
+		Amount:        req.Amount,
+		CategoryID:    req.CategoryID,
+		Tags:          req.Tags,
+		PaidAt:        req.PaidAt,
+	}
+	if req.amountMinorUnits != nil {
+		change.Amount, change.AmountMinorUnits = "", *req.amountMinorUnits
+	}
+	if req.Location != nil {
+		change.Location = &ledger.Location{Latitude: *req.Location.Latitude, Longitude: *req.Location.Longitude, PlaceName: req.Location.PlaceName}
+	}
+	switch {
+	case req.DueDate != nil:
+		change.DueDate = *req.DueDate
+	case req.PaidAt != nil:
+		change.DueDate = *req.PaidAt
+	}
+	return change
+}
+
+// toSyncResponse maps the SyncResult to the public SyncResponse DTO
+func toSyncResponse(result *SyncResult) SyncResponse {
+	resp := SyncResponse{
+		Results:           make([]ChangeResultResponse, 0),
+		Accounts:          make([]SyncedAccountResponse, len(result.Accounts)),
+		DeletedAccountIDs: result.DeletedAccountIDs,
+		Cursor:            result.Cursor.String(),
+		HasMore:           result.HasMore,
+	}
+	for _, set := range result.ChangeSets {
+		for _, r := range set.Results {
+			resp.Results = append(resp.Results, ChangeResultResponse{
+				ClientID:  r.ClientID,
+				AccountID: set.AccountID,
+				Status:    r.Status,
+				Code:      r.Code,
+			})
+		}
+	}
+	for i, account := range result.Accounts {
+		resp.Accounts[i] = toSyncedAccountResponse(account)
+	}
+	return resp
+}
+
+// toSyncedAccountResponse maps a ledger Account to the public SyncedAccountResponse DTO
+func toSyncedAccountResponse(a *ledger.Account) SyncedAccountResponse {
+	txs := a.Transactions()
+	resp := SyncedAccountResponse{
+		ID:                      a.ID,
+		Version:                 a.Version,
+		Name:                    a.Name,
+		Currency:                a.Currency,
+		Kind:                    a.Kind,
+		IncludeInOverallBalance: a.IncludeInOverallBalance,
+		ArchivedAt:              a.ArchivedAt,
+		DeleteAfter:             a.DeleteAfter,
+		Transactions:            make([]SyncedTransactionResponse, len(txs)),
+	}
+	for i, tx := range txs {
+		resp.Transactions[i] = SyncedTransactionResponse{
+			ID:          tx.ID,
+			Revision:    tx.Revision(),
+			Type:        tx.Type,
+			Description: tx.Description,
+			Observation: tx.Observation,
+			Amount:      tx.Amount.Amount,
+			CategoryID:  tx.CategoryID,
+			DueDate:     tx.DueDate,
+			PaidAt:      tx.PaidAt,
+			Tags:        tx.Tags,
+		}
+		if tx.Location != nil {
+			resp.Transactions[i].Location = &LocationResponse{
+				Latitude:  tx.Location.Latitude,
+				Longitude: tx.Location.Longitude,
+				PlaceName: tx.Location.PlaceName,
+			}
+		}
+	}
+	return resp
+}
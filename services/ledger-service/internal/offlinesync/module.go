@@ -0,0 +1,31 @@
+package offlinesync
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the change feed, service and handler of the offline sync from the shared dependencies
+type Module struct {
+	handler *SyncHandler
+}
+
+// NewModule creates the offline sync module, applying the offline changes through the ledger
+// The changes are read from the feed the triggers on accounts record in Postgres
+func NewModule(deps module.Deps, accounts AccountSyncer) *Module {
+	syncSvc := NewService(accounts, NewPostgresChangeFeed(deps.Postgres.Pool), deps.Clock)
+
+	return &Module{handler: NewSyncHandler(syncSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "offlinesync"
+}
+
+// RegisterRoutes mounts the offline sync routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,49 @@
+package offlinesync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ ChangeFeed = (*PostgresChangeFeed)(nil)
+
+// PostgresChangeFeed is a PostgreSQL implementation of the ChangeFeed interface
+// The changes are recorded by the triggers on accounts, every writer of the ledger being covered
+type PostgresChangeFeed struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresChangeFeed creates a new PostgresChangeFeed
+func NewPostgresChangeFeed(pool *pgxpool.Pool) *PostgresChangeFeed {
+	return &PostgresChangeFeed{pool: pool}
+}
+
+// ChangesSince returns the changes of the accounts of the user recorded after the position and before the given time
+func (r *PostgresChangeFeed) ChangesSince(ctx context.Context, userID uuid.UUID, after int64, before time.Time, limit int) ([]AccountChange, error) {
+	query := `
+		SELECT account_id, position, version, deleted
+		FROM sync_changes
+		WHERE user_id = $1 AND position > $2 AND recorded_at < $3
+		ORDER BY position
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, after, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync changes: %w", err)
+	}
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (AccountChange, error) {
+		var c AccountChange
+		err := row.Scan(&c.AccountID, &c.Position, &c.Version, &c.Deleted)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sync changes: %w", err)
+	}
+	return changes, nil
+}
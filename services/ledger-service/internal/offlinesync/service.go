@@ -0,0 +1,116 @@
+package offlinesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// SyncParams holds all the required data for the Sync use case
+type SyncParams struct {
+	UserID     uuid.UUID
+	Cursor     Cursor      // Cursor is where the previous sync of the client stopped, zero on its first sync
+	ChangeSets []ChangeSet // ChangeSets are the offline changes queued by the client, applied before reading the changes
+}
+
+// Service reconciles the changes the clients queued offline with the ledger and returns what changed on the server
+type Service struct {
+	accounts AccountSyncer
+	feed     ChangeFeed
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the offline sync Service
+func NewService(accounts AccountSyncer, feed ChangeFeed, clock clock.Clock) *Service {
+	return &Service{accounts: accounts, feed: feed, clock: clock}
+}
+
+// Sync is the use case for applying the offline changes of the client, then returning the accounts changed since
+// its cursor with their latest version and transactions
+// The client keeps the returned cursor for its next sync. The changes it just made are returned by a later sync
+// along with the ones made meanwhile, and so are the accounts the changes could not be applied to
+func (s *Service) Sync(ctx context.Context, params SyncParams) (*SyncResult, error) {
+	total := 0
+	for _, set := range params.ChangeSets {
+		total += len(set.Changes)
+	}
+	if total > MaxChangesPerSync {
+		return nil, ErrTooManySyncChanges.With("max_changes", MaxChangesPerSync)
+	}
+
+	result := &SyncResult{ChangeSets: make([]ChangeSetResult, 0, len(params.ChangeSets))}
+	for _, set := range params.ChangeSets {
+		setResult, err := s.applyChangeSet(ctx, params.UserID, set)
+		if err != nil {
+			return nil, err
+		}
+		result.ChangeSets = append(result.ChangeSets, setResult)
+	}
+
+	before := s.clock.Now().Add(-settleDelay)
+	changes, err := s.feed.ChangesSince(ctx, params.UserID, int64(params.Cursor), before, maxAccountsPerSync+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync changes: %w", err)
+	}
+	if len(changes) > maxAccountsPerSync {
+		changes, result.HasMore = changes[:maxAccountsPerSync], true
+	}
+
+	result.Cursor = params.Cursor
+	result.Accounts = make([]*ledger.Account, 0, len(changes))
+	result.DeletedAccountIDs = make([]uuid.UUID, 0)
+	for _, change := range changes {
+		result.Cursor = Cursor(change.Position)
+		if change.Deleted {
+			result.DeletedAccountIDs = append(result.DeletedAccountIDs, change.AccountID)
+			continue
+		}
+
+		// The account may have been deleted or handed to someone else since the change was read, its tombstone being
+		// returned by a later sync
+		account, err := s.accounts.FindAccountByID(ctx, params.UserID, change.AccountID)
+		if errors.Is(err, ledger.ErrAccountNotFound) || errors.Is(err, ledger.ErrForbidden) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find synced account: %w", err)
+		}
+		result.Accounts = append(result.Accounts, account)
+	}
+
+	return result, nil
+}
+
+// applyChangeSet applies the offline changes to an account, all of them being rejected when the user has no such account
+// or it is only shared with them
+func (s *Service) applyChangeSet(ctx context.Context, userID uuid.UUID, set ChangeSet) (ChangeSetResult, error) {
+	account, results, err := s.accounts.ApplyOfflineChanges(ctx, ledger.ApplyOfflineChangesParams{
+		AccountID: set.AccountID,
+		UserID:    userID,
+		Changes:   set.Changes,
+	})
+	if errors.Is(err, ledger.ErrAccountNotFound) || errors.Is(err, ledger.ErrForbidden) {
+		code := ledger.ErrAccountNotFound.Code
+		if errors.Is(err, ledger.ErrForbidden) {
+			code = ledger.ErrForbidden.Code
+		}
+		results = make([]ledger.OfflineChangeResult, len(set.Changes))
+		for i, change := range set.Changes {
+			results[i] = ledger.OfflineChangeResult{
+				ClientID: change.ClientID,
+				Status:   ledger.OfflineChangeRejected,
+				Code:     code,
+			}
+		}
+		return ChangeSetResult{AccountID: set.AccountID, Results: results}, nil
+	}
+	if err != nil {
+		return ChangeSetResult{}, fmt.Errorf("failed to apply offline changes: %w", err)
+	}
+
+	return ChangeSetResult{AccountID: set.AccountID, Version: account.Version, Results: results}, nil
+}
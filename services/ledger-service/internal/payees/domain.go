@@ -0,0 +1,132 @@
+package payees
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLimitNotFound       = errx.New(errx.CategoryNotFound, "PAYEE_LIMIT_NOT_FOUND", "payee limit not found")
+	ErrLimitAlreadyExists  = errx.New(errx.CategoryConflict, "PAYEE_LIMIT_ALREADY_EXISTS", "the payee already has a limit in this currency")
+	ErrInvalidLimitAmount  = errx.New(errx.CategoryValidation, "INVALID_PAYEE_LIMIT_AMOUNT", "the payee limit amount must be positive")
+	ErrInvalidPayee        = errx.New(errx.CategoryValidation, "INVALID_PAYEE", "the payee must have between 2 and 60 characters")
+	ErrTooManyLimits       = errx.New(errx.CategoryValidation, "TOO_MANY_PAYEE_LIMITS", "the maximum number of payee limits was reached")
+	ErrUnsupportedCurrency = errx.New(errx.CategoryValidation, "UNSUPPORTED_CURRENCY", "currency is not supported")
+)
+
+const (
+	// MaxLimitsPerUser bounds the limits checked against every new expense
+	MaxLimitsPerUser = 50
+
+	minPayeeLength = 2
+	maxPayeeLength = 60
+)
+
+// LimitRepository persists the monthly payee limits and the alerts already sent for them
+type LimitRepository interface {
+	// Save inserts or updates the limit, failing with ErrLimitAlreadyExists when the user has another limit
+	// for the same payee and currency
+	Save(ctx context.Context, limit *Limit) error
+	FindByID(ctx context.Context, limitID uuid.UUID) (*Limit, error)
+	// FindByUserID returns the limits of the user, oldest first
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Limit, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	Delete(ctx context.Context, limitID uuid.UUID) error
+	// MarkAlertSent records the alert of a limit for a month, reporting false when it was already sent
+	MarkAlertSent(ctx context.Context, limitID uuid.UUID, month time.Time, sentAt time.Time) (bool, error)
+}
+
+// SpendingReader reads the expenses the limits are checked against
+type SpendingReader interface {
+	// Expenses returns the expenses of the user's accounts in a currency due within [from, to)
+	Expenses(ctx context.Context, userID uuid.UUID, currency string, from, to time.Time) ([]Expense, error)
+}
+
+// Expense is an expense of the ledger, as the limits see it
+type Expense struct {
+	Description string
+	Amount      int64 // Amount is positive, in minor units of the currency
+	DueDate     time.Time
+}
+
+// Limit is the amount a user allows spending each month with a payee, the merchant or person whose name shows in
+// the transaction descriptions (e.g., "ifood" matches "IFOOD *RESTAURANTE")
+type Limit struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Payee     string // Payee is kept as the user typed it, matching ignores case, accents and repeated spaces
+	Amount    money.Money
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewLimit creates a new monthly Limit for a payee
+func NewLimit(userID uuid.UUID, payee string, amount money.Money, clock clock.Clock) (*Limit, error) {
+	limit := &Limit{ID: uuid.New(), UserID: userID, CreatedAt: clock.Now()}
+	if err := limit.Change(payee, amount, clock); err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// Change replaces the payee and the monthly amount of the limit
+func (l *Limit) Change(payee string, amount money.Money, clock clock.Clock) error {
+	payee = strings.Join(strings.Fields(payee), " ")
+	if length := utf8.RuneCountInString(payee); length < minPayeeLength || length > maxPayeeLength {
+		return ErrInvalidPayee.With("min_length", minPayeeLength).With("max_length", maxPayeeLength)
+	}
+	if !money.IsKnownCurrency(amount.Currency) {
+		return ErrUnsupportedCurrency.With("currency", amount.Currency)
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidLimitAmount
+	}
+
+	l.Payee = payee
+	l.Amount = amount
+	l.UpdatedAt = clock.Now()
+	return nil
+}
+
+// Matches reports whether an expense description names the payee of the limit
+func (l *Limit) Matches(description string) bool {
+	return strings.Contains(normalize(description), normalize(l.Payee))
+}
+
+// Spent sums the expenses paid to the payee of the limit
+func (l *Limit) Spent(expenses []Expense) money.Money {
+	var spent int64
+	for _, expense := range expenses {
+		if l.Matches(expense.Description) {
+			spent += expense.Amount
+		}
+	}
+	return money.New(spent, l.Amount.Currency)
+}
+
+// Exceeded reports whether spentAfter goes over the limit that spentBefore was within
+func (l *Limit) Exceeded(spentBefore, spentAfter int64) bool {
+	return spentBefore <= l.Amount.Amount && spentAfter > l.Amount.Amount
+}
+
+// accentReplacer strips the accents found in Portuguese and Spanish bank descriptions
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// normalize lowercases the text, strips its accents and collapses its spaces
+func normalize(text string) string {
+	return strings.Join(strings.Fields(accentReplacer.Replace(strings.ToLower(text))), " ")
+}
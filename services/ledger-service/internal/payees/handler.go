@@ -0,0 +1,257 @@
+package payees
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PayeeLimitHandler holds dependencies for payee limit-related HTTP handlers
+type PayeeLimitHandler struct {
+	payeesService *Service
+}
+
+// NewPayeeLimitHandler creates a new instance of PayeeLimitHandler
+func NewPayeeLimitHandler(payeesService *Service) *PayeeLimitHandler {
+	return &PayeeLimitHandler{payeesService: payeesService}
+}
+
+// RegisterRoutes sets up the API routes for the payees module
+func (h *PayeeLimitHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	limitsGroup := apiRouteGroup.Group("/payee-limits")
+
+	limitsGroup.POST("", h.createLimitHandler)
+	limitsGroup.GET("", h.listLimitsHandler)
+	limitsGroup.GET("/report", h.reportHandler)
+	limitsGroup.PUT("/:id", h.updateLimitHandler)
+	limitsGroup.DELETE("/:id", h.deleteLimitHandler)
+}
+
+// SetLimitRequest defines the expected JSON body for creating or changing a payee limit
+// Version 1 bodies send the amount as a number of minor units (e.g., 30000), see BindVersion
+type SetLimitRequest struct {
+	Payee    string `json:"payee" validate:"required,max=60"`  // Payee as it shows in the transaction descriptions (e.g., "ifood")
+	Amount   string `json:"amount" validate:"required,max=32"` // Decimal string in the limit currency (e.g., "300,00" or "300.00")
+	Currency string `json:"currency" validate:"required,len=3"`
+}
+
+// BindVersion decodes the version 1 bodies, whose amount is in minor units of the limit currency
+func (r *SetLimitRequest) BindVersion(_ int, decode func(any) error) error {
+	var v1 struct {
+		Payee    string `json:"payee"`
+		Amount   *int64 `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := decode(&v1); err != nil {
+		return err
+	}
+	r.Payee, r.Currency = v1.Payee, v1.Currency
+	if v1.Amount != nil {
+		r.Amount = money.New(*v1.Amount, v1.Currency).Decimal()
+	}
+	return nil
+}
+
+// LimitResponse defines the structure of a payee limit returned by the API
+type LimitResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Payee     string    `json:"payee"`
+	Currency  string    `json:"currency"`
+	Amount    int64     `json:"amount"`          // Amount in minor units of the currency
+	Spent     *int64    `json:"spent,omitempty"` // Spent in the current financial month, in minor units of the currency
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PayeeComparisonResponse defines the spending with the payee of a limit in a month and in the month before
+// Change is the difference between both, positive when more was spent in the month
+type PayeeComparisonResponse struct {
+	LimitID       uuid.UUID `json:"limit_id"`
+	Payee         string    `json:"payee"`
+	Currency      string    `json:"currency"`
+	Limit         int64     `json:"limit"`
+	Spent         int64     `json:"spent"`
+	PreviousSpent int64     `json:"previous_spent"`
+	Change        int64     `json:"change"`
+	Exceeded      bool      `json:"exceeded"`
+}
+
+// PayeeReportResponse defines the structure of the month-over-month payee spending returned by the API
+type PayeeReportResponse struct {
+	Month  string                    `json:"month"` // Month is formatted as YYYY-MM
+	From   string                    `json:"from"`  // From and To are the days of the financial month, formatted as YYYY-MM-DD, both included
+	To     string                    `json:"to"`
+	Payees []PayeeComparisonResponse `json:"payees"`
+}
+
+// createLimitHandler handles the HTTP request for limiting the monthly spending with a payee
+func (h *PayeeLimitHandler) createLimitHandler(c echo.Context) error {
+	var req SetLimitRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	limit, err := h.payeesService.CreateLimit(c.Request().Context(), SetLimitParams{
+		UserID:   userID,
+		Payee:    req.Payee,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toLimitResponse(limit))
+}
+
+// listLimitsHandler handles the HTTP request for listing the user's payee limits with their monthly spending
+func (h *PayeeLimitHandler) listLimitsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := h.payeesService.ListLimits(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]LimitResponse, 0, len(statuses))
+	for _, st := range statuses {
+		lr := toLimitResponse(st.Limit)
+		lr.Spent = &st.Spent.Amount
+		resp = append(resp, lr)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// reportHandler handles the HTTP request for comparing the spending with each limited payee to the month before
+// (e.g., ?month=2025-03), the current financial month by default
+func (h *PayeeLimitHandler) reportHandler(c echo.Context) error {
+	var month time.Time
+	if raw := c.QueryParam("month"); raw != "" {
+		parsed, err := time.Parse("2006-01", raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid month format, expected YYYY-MM")
+		}
+		month = parsed
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.payeesService.Compare(c.Request().Context(), userID, month)
+	if err != nil {
+		return err
+	}
+
+	resp := PayeeReportResponse{
+		Month:  report.Month.Format("2006-01"),
+		From:   report.Month.Format(time.DateOnly),
+		To:     report.End.AddDate(0, 0, -1).Format(time.DateOnly),
+		Payees: make([]PayeeComparisonResponse, len(report.Payees)),
+	}
+	for i, cmp := range report.Payees {
+		resp.Payees[i] = PayeeComparisonResponse{
+			LimitID:       cmp.Limit.ID,
+			Payee:         cmp.Limit.Payee,
+			Currency:      cmp.Limit.Amount.Currency,
+			Limit:         cmp.Limit.Amount.Amount,
+			Spent:         cmp.Spent.Amount,
+			PreviousSpent: cmp.PreviousSpent.Amount,
+			Change:        cmp.Spent.Amount - cmp.PreviousSpent.Amount,
+			Exceeded:      cmp.Spent.Amount > cmp.Limit.Amount.Amount,
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// updateLimitHandler handles the HTTP request for changing the payee or the amount of a limit
+func (h *PayeeLimitHandler) updateLimitHandler(c echo.Context) error {
+	limitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid payee limit id format")
+	}
+
+	var req SetLimitRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	limit, err := h.payeesService.UpdateLimit(c.Request().Context(), limitID, SetLimitParams{
+		UserID:   userID,
+		Payee:    req.Payee,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toLimitResponse(limit))
+}
+
+// deleteLimitHandler handles the HTTP request for deleting a payee limit
+func (h *PayeeLimitHandler) deleteLimitHandler(c echo.Context) error {
+	limitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid payee limit id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.payeesService.DeleteLimit(c.Request().Context(), userID, limitID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toLimitResponse maps the internal Limit domain model to the public LimitResponse DTO
+func toLimitResponse(l *Limit) LimitResponse {
+	return LimitResponse{
+		ID:        l.ID,
+		Payee:     l.Payee,
+		Currency:  l.Amount.Currency,
+		Amount:    l.Amount.Amount,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package payees
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// messages translates the payee limit alerts sent to the users
+var messages = i18n.Catalog{
+	i18n.English: {
+		"limit_alert_title": "%s limit exceeded",
+		"limit_alert_body":  "You spent %s with %s this month, over the limit of %s",
+	},
+	i18n.Portuguese: {
+		"limit_alert_title": "Limite de %s ultrapassado",
+		"limit_alert_body":  "Você gastou %s com %s neste mês, acima do limite de %s",
+	},
+}
@@ -0,0 +1,47 @@
+package payees
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the payee limit repositories, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *PayeeLimitHandler
+}
+
+// NewModule creates the payees module, alerts are delivered through deps.Notifier
+func NewModule(deps module.Deps) *Module {
+	payeesSvc := NewService(
+		NewPostgresLimitRepository(deps.Postgres.Pool),
+		NewPostgresSpendingReader(deps.Postgres.Pool),
+		deps.Notifier,
+		deps.Periods,
+		deps.Display,
+		deps.Clock,
+	)
+
+	return &Module{
+		service: payeesSvc,
+		handler: NewPayeeLimitHandler(payeesSvc),
+	}
+}
+
+// TransactionListener returns the listener the ledger notifies of saved transactions, to check the payee limits
+func (m *Module) TransactionListener() ledger.TransactionListener {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "payees"
+}
+
+// RegisterRoutes mounts the payees routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,224 @@
+package payees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ LimitRepository = (*PostgresLimitRepository)(nil)
+	_ SpendingReader  = (*PostgresSpendingReader)(nil)
+)
+
+// limitUniqueIndex keeps a single limit per payee, case-insensitively, and currency
+const limitUniqueIndex = "uq_payee_limits_user_id_payee_currency"
+
+// ----- Limits ----- //
+
+// PostgresLimitRepository is a PostgreSQL implementation of the LimitRepository interface
+type PostgresLimitRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLimitRepository creates a new PostgresLimitRepository
+func NewPostgresLimitRepository(pool *pgxpool.Pool) *PostgresLimitRepository {
+	return &PostgresLimitRepository{pool: pool}
+}
+
+// limitModel represents the payee limit structure in the database
+type limitModel struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Payee     string    `db:"payee"`
+	Amount    int64     `db:"amount_in_cents"`
+	Currency  string    `db:"currency"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+const limitColumns = `id, user_id, payee, amount_in_cents, currency, created_at, updated_at`
+
+// Save inserts a new limit or updates the payee and amount of an existing one
+func (r *PostgresLimitRepository) Save(ctx context.Context, limit *Limit) error {
+	m := toLimitPersistence(limit)
+
+	query := `
+		INSERT INTO payee_limits (id, user_id, payee, amount_in_cents, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			payee = EXCLUDED.payee,
+			amount_in_cents = EXCLUDED.amount_in_cents,
+			currency = EXCLUDED.currency,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, m.ID, m.UserID, m.Payee, m.Amount, m.Currency, m.CreatedAt, m.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == limitUniqueIndex {
+			return ErrLimitAlreadyExists.With("payee", limit.Payee).With("currency", limit.Amount.Currency)
+		}
+		return fmt.Errorf("failed to upsert payee limit: %w", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a limit by its ID
+func (r *PostgresLimitRepository) FindByID(ctx context.Context, limitID uuid.UUID) (*Limit, error) {
+	query := `SELECT ` + limitColumns + ` FROM payee_limits WHERE id = $1`
+
+	var m limitModel
+	err := r.pool.QueryRow(ctx, query, limitID).Scan(&m.ID, &m.UserID, &m.Payee, &m.Amount, &m.Currency, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLimitNotFound.With("limit_id", limitID)
+		}
+		return nil, fmt.Errorf("failed to fetch payee limit: %w", err)
+	}
+	return toLimitDomain(&m), nil
+}
+
+// FindByUserID retrieves the limits of a user, oldest first
+func (r *PostgresLimitRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*Limit, error) {
+	query := `SELECT ` + limitColumns + ` FROM payee_limits WHERE user_id = $1 ORDER BY created_at, id`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payee limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := make([]*Limit, 0)
+	for rows.Next() {
+		var m limitModel
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Payee, &m.Amount, &m.Currency, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan payee limit row: %w", err)
+		}
+		limits = append(limits, toLimitDomain(&m))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payee limit rows: %w", err)
+	}
+
+	return limits, nil
+}
+
+// CountByUserID counts the limits of the user
+func (r *PostgresLimitRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM payee_limits WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count payee limits: %w", err)
+	}
+	return count, nil
+}
+
+// Delete deletes a limit and the record of its sent alerts
+func (r *PostgresLimitRepository) Delete(ctx context.Context, limitID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM payee_limits WHERE id = $1`, limitID)
+	if err != nil {
+		return fmt.Errorf("failed to delete payee limit: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLimitNotFound.With("limit_id", limitID)
+	}
+	return nil
+}
+
+// MarkAlertSent records the alert of a limit for a month, reporting false when it was already recorded
+func (r *PostgresLimitRepository) MarkAlertSent(ctx context.Context, limitID uuid.UUID, month time.Time, sentAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO payee_limit_alerts_sent (payee_limit_id, month, sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (payee_limit_id, month) DO NOTHING
+	`
+
+	tag, err := r.pool.Exec(ctx, query, limitID, month, sentAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record payee limit alert: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// toLimitDomain maps a persistence limitModel to a domain Limit
+func toLimitDomain(m *limitModel) *Limit {
+	return &Limit{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Payee:     m.Payee,
+		Amount:    money.New(m.Amount, m.Currency),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// toLimitPersistence maps a domain Limit to a persistence limitModel
+func toLimitPersistence(l *Limit) *limitModel {
+	return &limitModel{
+		ID:        l.ID,
+		UserID:    l.UserID,
+		Payee:     l.Payee,
+		Amount:    l.Amount.Amount,
+		Currency:  l.Amount.Currency,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
+
+// ----- Spending ----- //
+
+// PostgresSpendingReader reads the expenses from the ledger tables
+type PostgresSpendingReader struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSpendingReader creates a new PostgresSpendingReader
+func NewPostgresSpendingReader(pool *pgxpool.Pool) *PostgresSpendingReader {
+	return &PostgresSpendingReader{pool: pool}
+}
+
+// Expenses returns the expenses (stored as negative amounts) of the user's accounts in a currency due within [from, to),
+// archived ones included
+func (r *PostgresSpendingReader) Expenses(ctx context.Context, userID uuid.UUID, currency string, from, to time.Time) ([]Expense, error) {
+	query := `
+		SELECT t.description, -t.amount_in_cents, t.due_date
+		FROM (
+			SELECT account_id, user_id, type, description, amount_in_cents, due_date FROM transactions
+			UNION ALL
+			SELECT account_id, user_id, type, description, amount_in_cents, due_date FROM archived_transactions
+		) t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.user_id = $1
+			AND t.type = 'EXPENSE'
+			AND a.currency = $2
+			AND t.due_date >= $3 AND t.due_date < $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, currency, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := make([]Expense, 0)
+	for rows.Next() {
+		var e Expense
+		if err := rows.Scan(&e.Description, &e.Amount, &e.DueDate); err != nil {
+			return nil, fmt.Errorf("failed to scan expense row: %w", err)
+		}
+		expenses = append(expenses, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expense rows: %w", err)
+	}
+
+	return expenses, nil
+}
@@ -0,0 +1,296 @@
+package payees
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+var _ ledger.TransactionListener = (*Service)(nil)
+
+// SetLimitParams holds all the required data for the CreateLimit and UpdateLimit use cases
+type SetLimitParams struct {
+	UserID   uuid.UUID
+	Payee    string
+	Amount   string // Amount as a decimal string, parsed using the currency (e.g., "300,00")
+	Currency string
+}
+
+// LimitStatus is a limit with what was spent with its payee in the current financial month
+type LimitStatus struct {
+	Limit *Limit
+	Spent money.Money
+}
+
+// PayeeComparison is what was spent with the payee of a limit in a financial month and in the month before
+type PayeeComparison struct {
+	Limit         *Limit
+	Spent         money.Money
+	PreviousSpent money.Money
+}
+
+// PayeeReport compares the spending with the payees the user limits in a financial month to the month before
+type PayeeReport struct {
+	Month  time.Time // Month is the first day of the compared financial month
+	End    time.Time // End is the first day after it
+	Payees []PayeeComparison
+}
+
+// Service manages the monthly payee limits and alerts users when their spending with a payee exceeds its limit
+type Service struct {
+	limitRepo LimitRepository
+	spending  SpendingReader
+	notifier  notify.Notifier
+	periods   module.AccountingPeriods
+	display   module.UserPreferences
+	clock     clock.Clock
+}
+
+// NewService creates a new instance of the payees Service, limits apply to the financial month of each user
+func NewService(
+	limitRepo LimitRepository,
+	spending SpendingReader,
+	notifier notify.Notifier,
+	periods module.AccountingPeriods,
+	display module.UserPreferences,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		limitRepo: limitRepo,
+		spending:  spending,
+		notifier:  notifier,
+		periods:   periods,
+		display:   display,
+		clock:     clock,
+	}
+}
+
+// CreateLimit is the use case for limiting the monthly spending with a payee
+func (s *Service) CreateLimit(ctx context.Context, params SetLimitParams) (*Limit, error) {
+	count, err := s.limitRepo.CountByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count payee limits: %w", err)
+	}
+	if count >= MaxLimitsPerUser {
+		return nil, ErrTooManyLimits.With("max_limits", MaxLimitsPerUser)
+	}
+
+	amount, err := money.Parse(params.Amount, params.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payee limit amount: %w", err)
+	}
+	limit, err := NewLimit(params.UserID, params.Payee, amount, s.clock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payee limit: %w", err)
+	}
+
+	if err := s.limitRepo.Save(ctx, limit); err != nil {
+		return nil, fmt.Errorf("failed to save payee limit: %w", err)
+	}
+	return limit, nil
+}
+
+// UpdateLimit is the use case for changing the payee or the amount of one of the user's limits
+func (s *Service) UpdateLimit(ctx context.Context, limitID uuid.UUID, params SetLimitParams) (*Limit, error) {
+	limit, err := s.findUserLimit(ctx, params.UserID, limitID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := money.Parse(params.Amount, params.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payee limit amount: %w", err)
+	}
+	if err := limit.Change(params.Payee, amount, s.clock); err != nil {
+		return nil, fmt.Errorf("failed to change payee limit: %w", err)
+	}
+
+	if err := s.limitRepo.Save(ctx, limit); err != nil {
+		return nil, fmt.Errorf("failed to save payee limit: %w", err)
+	}
+	return limit, nil
+}
+
+// ListLimits is the use case for listing the user's limits with their spending in the current financial month
+func (s *Service) ListLimits(ctx context.Context, userID uuid.UUID) ([]LimitStatus, error) {
+	report, err := s.Compare(ctx, userID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]LimitStatus, len(report.Payees))
+	for i, c := range report.Payees {
+		statuses[i] = LimitStatus{Limit: c.Limit, Spent: c.Spent}
+	}
+	return statuses, nil
+}
+
+// Compare is the use case for comparing the spending with each payee the user limits in a financial month to the month before
+// The financial month is the one named after the year and month of month, a zero month comparing the current one
+func (s *Service) Compare(ctx context.Context, userID uuid.UUID, month time.Time) (*PayeeReport, error) {
+	limits, err := s.limitRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payee limits: %w", err)
+	}
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	var from, to time.Time
+	if month.IsZero() {
+		from, to = period.RangeIn(s.clock.Now(), time.UTC)
+	} else {
+		from, to = period.MonthRangeIn(month.Year(), month.Month(), time.UTC)
+	}
+	previousFrom, _ := period.RangeIn(from.AddDate(0, 0, -1), time.UTC)
+
+	// The expenses of both months are read once per currency, then split between the months
+	current := make(map[string][]Expense)
+	previous := make(map[string][]Expense)
+	comparisons := make([]PayeeComparison, 0, len(limits))
+	for _, limit := range limits {
+		currency := limit.Amount.Currency
+		if _, ok := current[currency]; !ok {
+			expenses, err := s.spending.Expenses(ctx, userID, currency, previousFrom, to)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find expenses for payee limits: %w", err)
+			}
+			current[currency], previous[currency] = []Expense{}, []Expense{}
+			for _, expense := range expenses {
+				if expense.DueDate.Before(from) {
+					previous[currency] = append(previous[currency], expense)
+				} else {
+					current[currency] = append(current[currency], expense)
+				}
+			}
+		}
+
+		comparisons = append(comparisons, PayeeComparison{
+			Limit:         limit,
+			Spent:         limit.Spent(current[currency]),
+			PreviousSpent: limit.Spent(previous[currency]),
+		})
+	}
+	return &PayeeReport{Month: from, End: to, Payees: comparisons}, nil
+}
+
+// DeleteLimit is the use case for deleting one of the user's limits
+func (s *Service) DeleteLimit(ctx context.Context, userID, limitID uuid.UUID) error {
+	if _, err := s.findUserLimit(ctx, userID, limitID); err != nil {
+		return err
+	}
+
+	if err := s.limitRepo.Delete(ctx, limitID); err != nil {
+		return fmt.Errorf("failed to delete payee limit: %w", err)
+	}
+	return nil
+}
+
+// TransactionAdded checks the limits of the payees the expense names, alerting the user once per financial month
+// when the expense pushes the spending with a payee over its limit
+func (s *Service) TransactionAdded(ctx context.Context, account *ledger.Account, tx ledger.Transaction) {
+	if tx.Type != ledger.Expense {
+		return
+	}
+
+	if err := s.checkLimits(ctx, account.UserID, tx); err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to check payee limits",
+			slog.String("transaction_id", tx.ID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *Service) checkLimits(ctx context.Context, userID uuid.UUID, tx ledger.Transaction) error {
+	limits, err := s.limitRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var matched []*Limit
+	for _, limit := range limits {
+		if limit.Amount.SameCurrency(tx.Amount) && limit.Matches(tx.Description) {
+			matched = append(matched, limit)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	period, err := s.periods.AccountingPeriod(ctx, userID)
+	if err != nil {
+		return err
+	}
+	month, nextMonth := period.RangeIn(tx.DueDate, time.UTC)
+	expenses, err := s.spending.Expenses(ctx, userID, tx.Amount.Currency, month, nextMonth)
+	if err != nil {
+		return err
+	}
+
+	for _, limit := range matched {
+		spent := limit.Spent(expenses)
+		// Expenses are negative, so the spending before the transaction is the total minus its absolute amount
+		if !limit.Exceeded(spent.Amount+tx.Amount.Amount, spent.Amount) {
+			continue
+		}
+
+		firstTime, err := s.limitRepo.MarkAlertSent(ctx, limit.ID, month, s.clock.Now())
+		if err != nil {
+			return err
+		}
+		if !firstTime {
+			continue
+		}
+		display, err := s.display.DisplayPreferences(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to find display preferences: %w", err)
+		}
+		if err := s.notifier.Notify(ctx, limitAlertMessage(limit, spent, month, display.Locale)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findUserLimit finds a limit of the user, reporting the limits of other users as not found
+func (s *Service) findUserLimit(ctx context.Context, userID, limitID uuid.UUID) (*Limit, error) {
+	limit, err := s.limitRepo.FindByID(ctx, limitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payee limit: %w", err)
+	}
+	if limit.UserID != userID {
+		return nil, ErrLimitNotFound.With("limit_id", limitID)
+	}
+	return limit, nil
+}
+
+// limitAlertMessage builds the notification of an exceeded limit, written in the language of the locale and amounts formatted in it
+func limitAlertMessage(limit *Limit, spent money.Money, month time.Time, locale string) notify.Message {
+	lang, _ := i18n.Resolve(locale)
+
+	return notify.Message{
+		UserID:   limit.UserID,
+		Category: notify.CategoryBudgetAlert,
+		Title:    messages.Format(lang, "limit_alert_title", limit.Payee),
+		Body:     messages.Format(lang, "limit_alert_body", spent.Format(locale), limit.Payee, limit.Amount.Format(locale)),
+		Data: map[string]string{
+			"payee_limit_id": limit.ID.String(),
+			"payee":          limit.Payee,
+			"currency":       limit.Amount.Currency,
+			"limit":          strconv.FormatInt(limit.Amount.Amount, 10),
+			"spent":          strconv.FormatInt(spent.Amount, 10),
+			"month":          month.Format("2006-01"),
+		},
+	}
+}
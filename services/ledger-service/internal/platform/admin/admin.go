@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/logger"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/labstack/echo/v4"
+)
+
+// TokenMiddleware protects the operator endpoints with a static bearer token (ADMIN_TOKEN)
+// They are not user-facing, so they do not go through the identity service
+func TokenMiddleware(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			provided, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid admin token")
+			}
+			return next(c)
+		}
+	}
+}
+
+// AdminHandler exposes the operator endpoints
+type AdminHandler struct {
+	levels *logger.Levels
+}
+
+// NewAdminHandler creates a new instance of AdminHandler
+func NewAdminHandler(levels *logger.Levels) *AdminHandler {
+	return &AdminHandler{levels: levels}
+}
+
+// RegisterRoutes sets up the operator routes under the admin group
+func (h *AdminHandler) RegisterRoutes(adminRouteGroup *echo.Group) {
+	adminRouteGroup.GET("/log-levels", h.getLogLevelsHandler)
+	adminRouteGroup.PUT("/log-levels", h.updateLogLevelHandler)
+}
+
+// UpdateLogLevelRequest defines the expected JSON body for changing a log level
+type UpdateLogLevelRequest struct {
+	Scope string  `json:"scope,omitempty"`                                                  // The scope to change (e.g., "http"), the root level when empty
+	Level *string `json:"level,omitempty" validate:"omitempty,oneof=debug info warn error"` // The new level, omitted to reset the scope (or root) to its default
+}
+
+// ScopeLevelResponse defines the level of a logger scope returned by the API
+type ScopeLevelResponse struct {
+	Scope      string `json:"scope"`
+	Level      string `json:"level"`
+	Overridden bool   `json:"overridden"` // False when the scope follows the root level
+}
+
+// LogLevelsResponse defines the structure of the runtime log levels returned by the API
+type LogLevelsResponse struct {
+	Root   string               `json:"root"`
+	Scopes []ScopeLevelResponse `json:"scopes"`
+}
+
+// getLogLevelsHandler handles the HTTP request for listing the current log levels
+func (h *AdminHandler) getLogLevelsHandler(c echo.Context) error {
+	return httpx.SendSuccess(c, http.StatusOK, h.toLogLevelsResponse())
+}
+
+// updateLogLevelHandler handles the HTTP request for changing the level of the root logger or of a scope
+func (h *AdminHandler) updateLogLevelHandler(c echo.Context) error {
+	var req UpdateLogLevelRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case req.Scope == "" && req.Level == nil:
+		h.levels.ResetRoot()
+	case req.Scope == "":
+		level, _ := logger.ParseLevel(*req.Level)
+		h.levels.SetRoot(level)
+	case req.Level == nil:
+		err = h.levels.Reset(req.Scope)
+	default:
+		level, _ := logger.ParseLevel(*req.Level)
+		err = h.levels.Set(req.Scope, level)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	ctxlogger.GetLogger(c.Request().Context()).Warn("log level changed",
+		slog.String("scope", req.Scope),
+		slog.Any("level", req.Level),
+	)
+	return httpx.SendSuccess(c, http.StatusOK, h.toLogLevelsResponse())
+}
+
+// toLogLevelsResponse maps the runtime levels to the public LogLevelsResponse DTO
+func (h *AdminHandler) toLogLevelsResponse() LogLevelsResponse {
+	resp := LogLevelsResponse{
+		Root:   strings.ToLower(h.levels.Root().String()),
+		Scopes: make([]ScopeLevelResponse, 0),
+	}
+	for _, s := range h.levels.ScopeLevels() {
+		resp.Scopes = append(resp.Scopes, ScopeLevelResponse{
+			Scope:      s.Scope,
+			Level:      strings.ToLower(s.Level.String()),
+			Overridden: s.Overridden,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Audit vocabulary of the impersonations
+const (
+	auditUserImpersonated = "user.impersonated"
+	auditResourceUser     = "user"
+	auditSupportActor     = "support:"
+)
+
+// Impersonator mints the tokens support acts as a user with, the identity service recording them too
+type Impersonator interface {
+	IssueImpersonationToken(ctx context.Context, userID uuid.UUID, operator, reason string, ttl time.Duration) (string, time.Time, error)
+}
+
+// messages translates the notification telling a user that support acted as them
+var messages = i18n.Catalog{
+	i18n.English: {
+		"user_impersonated_title": "Support accessed your account",
+		"user_impersonated_body":  "%s from support accessed your account until %s: %s",
+	},
+	i18n.Portuguese: {
+		"user_impersonated_title": "O suporte acessou sua conta",
+		"user_impersonated_body":  "%s do suporte acessou sua conta até %s: %s",
+	},
+}
+
+// ImpersonationHandler lets support reproduce the issues of a user with a short-lived token acting as them, instead of
+// asking for their password. Every impersonation is audited and notified to the user
+type ImpersonationHandler struct {
+	impersonator Impersonator
+	auditor      *audit.Logger
+	notifier     notify.Notifier
+	display      module.UserPreferences
+}
+
+// NewImpersonationHandler creates a new instance of ImpersonationHandler
+func NewImpersonationHandler(impersonator Impersonator, auditor *audit.Logger, notifier notify.Notifier, display module.UserPreferences) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonator: impersonator,
+		auditor:      auditor,
+		notifier:     notifier,
+		display:      display,
+	}
+}
+
+// RegisterRoutes sets up the impersonation routes under the admin group
+func (h *ImpersonationHandler) RegisterRoutes(adminRouteGroup *echo.Group) {
+	adminRouteGroup.POST("/users/:id/impersonations", h.impersonateHandler)
+}
+
+// ImpersonateRequest defines the expected JSON body for impersonating a user
+type ImpersonateRequest struct {
+	Operator   string `json:"operator" validate:"required,max=255"`            // The support member acting as the user, shown to them
+	Reason     string `json:"reason" validate:"required,max=500"`              // Why the user is impersonated (e.g., the support ticket)
+	TTLSeconds int64  `json:"ttl_seconds,omitempty" validate:"gte=0,lte=3600"` // How long the token is valid, 15 minutes when omitted
+}
+
+// ImpersonationResponse defines the token support acts as the user with
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// impersonateHandler handles the HTTP request for minting a token acting as a user
+func (h *ImpersonationHandler) impersonateHandler(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id format")
+	}
+
+	var req ImpersonateRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	token, expiresAt, err := h.impersonator.IssueImpersonationToken(ctx, userID, req.Operator, req.Reason, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	h.auditor.Record(ctx, audit.Entry{
+		Action:       auditUserImpersonated,
+		ResourceType: auditResourceUser,
+		ResourceID:   userID.String(),
+		Actor:        auditSupportActor + req.Operator,
+		Metadata: map[string]string{
+			"reason":     req.Reason,
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+	h.notify(ctx, userID, req.Operator, req.Reason, expiresAt)
+
+	return httpx.SendSuccess(c, http.StatusCreated, ImpersonationResponse{
+		AccessToken: token,
+		ExpiresAt:   expiresAt.UTC(),
+	})
+}
+
+// notify tells the user that support acts as them, in their language. A failed notification never revokes the token,
+// the audit record being kept either way
+func (h *ImpersonationHandler) notify(ctx context.Context, userID uuid.UUID, operator, reason string, expiresAt time.Time) {
+	lang := i18n.Default
+	if display, err := h.display.DisplayPreferences(ctx, userID); err == nil {
+		lang, _ = i18n.Resolve(display.Locale)
+	}
+
+	err := h.notifier.Notify(ctx, notify.Message{
+		UserID:   userID,
+		Category: notify.CategorySecurity,
+		Title:    messages.Format(lang, "user_impersonated_title"),
+		Body:     messages.Format(lang, "user_impersonated_body", operator, expiresAt.UTC().Format(time.RFC3339), reason),
+		Data: map[string]string{
+			"operator":   operator,
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to notify impersonated user",
+			slog.String("user_id", userID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
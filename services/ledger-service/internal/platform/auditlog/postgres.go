@@ -0,0 +1,64 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ audit.Sink = (*PostgresSink)(nil)
+
+// PostgresSink stores audit records in the append-only audit_logs table
+// The table rejects UPDATE and DELETE with a trigger, so records can only be inserted, then deleted by the retention policy
+type PostgresSink struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSink creates a new PostgresSink
+func NewPostgresSink(pool *pgxpool.Pool) *PostgresSink {
+	return &PostgresSink{pool: pool}
+}
+
+// Write inserts the audit record
+func (s *PostgresSink) Write(ctx context.Context, record audit.Record) error {
+	var metadata []byte
+	if len(record.Metadata) > 0 {
+		var err error
+		if metadata, err = json.Marshal(record.Metadata); err != nil {
+			return fmt.Errorf("failed to encode audit metadata: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO audit_logs (id, occurred_at, actor, action, resource_type, resource_id, before_state, after_state, request_id, metadata)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, NULLIF($9, ''), $10)
+	`
+
+	_, err := s.pool.Exec(ctx, query,
+		record.ID,
+		record.OccurredAt,
+		record.Actor,
+		record.Action,
+		record.ResourceType,
+		record.ResourceID,
+		nullableJSON(record.Before),
+		nullableJSON(record.After),
+		record.RequestID,
+		nullableJSON(metadata),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
+
+// nullableJSON maps an absent state to NULL instead of an empty JSONB value
+func nullableJSON(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,103 @@
+// Package authz declares what each route of the API requires from the authenticated user (the access of the token and
+// the account it acts on) and evaluates it in a single middleware, so the rules do not end up scattered across the
+// handlers as the API grows
+package authz
+
+import (
+	"net/http"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Access is what a route does with the data of the user
+type Access int
+
+const (
+	Read  Access = iota // Read routes are granted to the read-only tokens
+	Write               // Write routes change data, denied to the read-only tokens and, when required, to unverified emails
+)
+
+// Policy declares what a route requires from the authenticated user
+type Policy struct {
+	Access Access
+	// AccountParam names the path param holding the account the route acts on. The tokens restricted to accounts are
+	// only granted the routes of one of their accounts, so they are denied every route without it
+	AccountParam string
+}
+
+// route identifies a route by its method and echo path (e.g., "/api/v1/accounts/:id")
+type route struct {
+	method string
+	path   string
+}
+
+// Policies maps the routes to their policy, the routes without one declared following the fallback
+type Policies struct {
+	routes               map[route]Policy
+	fallback             func(method, path string) Policy
+	requireVerifiedEmail bool
+}
+
+// NewPolicies creates the policies of the API, fallback deciding the policy of the routes declared without one
+// When requireVerifiedEmail is set, the write routes are denied to the users who did not verify their email
+func NewPolicies(fallback func(method, path string) Policy, requireVerifiedEmail bool) *Policies {
+	return &Policies{
+		routes:               make(map[route]Policy),
+		fallback:             fallback,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// Route declares the policy of a route, returning the policies so the declarations can be chained
+func (p *Policies) Route(method, path string, policy Policy) *Policies {
+	p.routes[route{method: method, path: path}] = policy
+	return p
+}
+
+// Lookup returns the policy of a route
+func (p *Policies) Lookup(method, path string) Policy {
+	if policy, ok := p.routes[route{method: method, path: path}]; ok {
+		return policy
+	}
+	return p.fallback(method, path)
+}
+
+// Middleware evaluates the policy of the matched route before its handler runs. It must run after the auth middleware
+func (p *Policies) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := authctx.UserFromContext(c.Request().Context())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+			}
+			if err := p.authorize(c, user, p.Lookup(c.Request().Method, c.Path())); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// authorize checks the scope of the user's token, then their email
+func (p *Policies) authorize(c echo.Context, user authctx.UserContext, policy Policy) error {
+	if policy.Access == Write && user.Scope.ReadOnly {
+		return identityclient.ErrOutOfScope
+	}
+	if len(user.Scope.AccountIDs) > 0 {
+		if policy.AccountParam == "" {
+			return identityclient.ErrOutOfScope
+		}
+		accountID, err := uuid.Parse(c.Param(policy.AccountParam))
+		if err != nil || !user.Scope.AllowsAccount(accountID) {
+			return identityclient.ErrOutOfScope
+		}
+	}
+
+	if policy.Access == Write && p.requireVerifiedEmail && !user.EmailVerified {
+		return identityclient.ErrEmailNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package authz
+
+import (
+	"net/http"
+	"strings"
+)
+
+// accountParams are the path params naming the account of the routes under /api/v1/accounts
+var accountParams = []string{"id", "accountId"}
+
+// defaultPolicy reads on the safe methods and writes on the others, the routes under /api/v1/accounts acting on the
+// account of their :id or :accountId param. Every other route spans the accounts of the user, so it is denied to the
+// tokens restricted to accounts
+func defaultPolicy(method, path string) Policy {
+	policy := Policy{Access: Write}
+	if isSafeMethod(method) {
+		policy.Access = Read
+	}
+	for _, param := range accountParams {
+		if strings.HasPrefix(path, "/api/v1/accounts/:"+param) {
+			policy.AccountParam = param
+			break
+		}
+	}
+	return policy
+}
+
+// RoutePolicies declares the policies of the routes the default policy gets wrong: the reads sent with POST and the
+// routes acting on an account outside of /api/v1/accounts. The interest simulations may post the simulated income, so
+// they write
+func RoutePolicies(requireVerifiedEmail bool) *Policies {
+	return NewPolicies(defaultPolicy, requireVerifiedEmail).
+		Route(http.MethodPost, "/api/v1/boletos/parse", Policy{Access: Read}).
+		Route(http.MethodGet, "/api/v1/interest/accounts/:accountId/yield", Policy{Access: Read, AccountParam: "accountId"}).
+		Route(http.MethodPut, "/api/v1/interest/accounts/:accountId/yield", Policy{Access: Write, AccountParam: "accountId"}).
+		Route(http.MethodDelete, "/api/v1/interest/accounts/:accountId/yield", Policy{Access: Write, AccountParam: "accountId"}).
+		Route(http.MethodPost, "/api/v1/interest/accounts/:accountId/simulations", Policy{Access: Write, AccountParam: "accountId"}).
+		Route(http.MethodPut, "/api/v1/investments/accounts/:accountId/positions/:ticker", Policy{Access: Write, AccountParam: "accountId"}).
+		Route(http.MethodDelete, "/api/v1/investments/accounts/:accountId/positions/:ticker", Policy{Access: Write, AccountParam: "accountId"})
+}
+
+// isSafeMethod reports whether requests of the method only read
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,98 @@
+// Package boot checks the dependencies of the service before it starts serving, so a misconfigured or isolated
+// instance fails at once with every problem listed, instead of receiving traffic it can not handle
+package boot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FailureUnreachable       FailureKind = "unreachable"        // FailureUnreachable is a dependency that did not answer
+	FailurePendingMigrations FailureKind = "pending_migrations" // FailurePendingMigrations is a database missing migrations
+	FailureMissingSecret     FailureKind = "missing_secret"     // FailureMissingSecret is a secret an enabled feature needs
+)
+
+// FailureKind classifies why a check failed, telling the operator what to fix
+type FailureKind string
+
+// Failure is a problem found by a check
+type Failure struct {
+	Check string
+	Kind  FailureKind
+	Err   error
+	Hint  string // Hint tells the operator how to fix it, empty when the error says it all
+}
+
+// Error describes the failure with its hint
+func (f *Failure) Error() string {
+	msg := fmt.Sprintf("%s: %s: %v", f.Check, f.Kind, f.Err)
+	if f.Hint != "" {
+		msg += " (" + f.Hint + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the error of the failure
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// Error lists every failure of the checks, in the order of the checks
+type Error struct {
+	Failures []*Failure
+}
+
+// Error describes every failure, one per line
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "startup checks found %d problems:", len(e.Failures))
+	for _, f := range e.Failures {
+		b.WriteString("\n  - " + f.Error())
+	}
+	return b.String()
+}
+
+// Check verifies a dependency, returning the failures it found or none when the dependency is ready
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) []*Failure
+}
+
+// Checker runs the checks of the dependencies at startup
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+// NewChecker creates a Checker bounding each check by timeout
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Run runs every check concurrently, returning an *Error listing all their failures or nil when they all passed
+func (c *Checker) Run(ctx context.Context) error {
+	results := make([][]*Failure, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Go(func() {
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			results[i] = check.Run(checkCtx)
+		})
+	}
+	wg.Wait()
+
+	var failures []*Failure
+	for _, result := range results {
+		failures = append(failures, result...)
+	}
+	if len(failures) > 0 {
+		return &Error{Failures: failures}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/migrate"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pinger is a dependency answering a ping, like the identity service or Redis
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingCheck checks that a dependency answers its ping
+func PingCheck(name string, pinger Pinger) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) []*Failure {
+			if err := pinger.Ping(ctx); err != nil {
+				return []*Failure{{Check: name, Kind: FailureUnreachable, Err: err}}
+			}
+			return nil
+		},
+	}
+}
+
+// PostgresCheck checks that a database answers, connecting with probe, and, when migrations is not nil, that it holds
+// every migration of the dir directory of migrations
+func PostgresCheck(name string, probe func(ctx context.Context) (*pgxpool.Pool, error), migrations fs.FS, dir string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) []*Failure {
+			pool, err := probe(ctx)
+			if err != nil {
+				return []*Failure{{Check: name, Kind: FailureUnreachable, Err: err}}
+			}
+			defer pool.Close()
+
+			if migrations == nil {
+				return nil
+			}
+			pending, err := pendingMigrations(ctx, pool, migrations, dir)
+			if err != nil {
+				return []*Failure{{Check: name, Kind: FailureUnreachable, Err: err}}
+			}
+			if len(pending) > 0 {
+				return []*Failure{{
+					Check: name,
+					Kind:  FailurePendingMigrations,
+					Err:   fmt.Errorf("%d migrations are not applied: %s", len(pending), strings.Join(pending, ", ")),
+					Hint:  "run fintrackctl migrate up",
+				}}
+			}
+			return nil
+		},
+	}
+}
+
+// pendingMigrations returns the names of the migrations the database misses
+func pendingMigrations(ctx context.Context, pool *pgxpool.Pool, migrations fs.FS, dir string) ([]string, error) {
+	migrator, err := migrate.New(pool, migrations, dir)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Name)
+		}
+	}
+	return pending, nil
+}
+
+// SecretsCheck checks that the secrets the enabled features need are set, reporting each missing one
+func SecretsCheck(secrets []config.RequiredSecret) Check {
+	return Check{
+		Name: "secrets",
+		Run: func(ctx context.Context) []*Failure {
+			var failures []*Failure
+			for _, secret := range secrets {
+				if !secret.Set {
+					failures = append(failures, &Failure{
+						Check: "secrets",
+						Kind:  FailureMissingSecret,
+						Err:   fmt.Errorf("%s is not set", secret.Var),
+						Hint:  "required by " + secret.RequiredBy,
+					})
+				}
+			}
+			return failures
+		},
+	}
+}
@@ -1,28 +1,48 @@
 package config
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"reflect"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// Config is the configuration of the ledger service, read from the environment. The platform settings come first,
+// then a section per module
 type Config struct {
+	Profile Profile `envconfig:"APP_PROFILE" default:"dev"` // Profile selects the overrides of the defaults for the environment (dev, staging or prod)
+
 	Server struct {
-		Port         string        `envconfig:"SERVER_PORT" default:"3333"`
+		Port         string        `envconfig:"SERVER_PORT" default:"9999"`
 		ReadTimeout  time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"5s"`
 		WriteTimeout time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"10s"`
 		IdleTimeout  time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" default:"120s"`
+		// TrustedProxies are the networks of the load balancers in front of the API, whose X-Forwarded-For the IP filters follow
+		TrustedProxies []string `envconfig:"SERVER_TRUSTED_PROXIES"`
+		// StartupCheckTimeout bounds each check of the dependencies run before the port is bound
+		StartupCheckTimeout time.Duration `envconfig:"SERVER_STARTUP_CHECK_TIMEOUT" default:"10s"`
 	}
 	Postgres struct {
-		MaxConns          int32         `envconfig:"PGX_MAX_CONNS" default:"20"`
-		MinConns          int32         `envconfig:"PGX_MIN_CONNS" default:"5"`
-		MaxConnLifetime   time.Duration `envconfig:"PGX_MAX_CONN_LIFETIME" default:"30m"`
-		MaxConnIdleTime   time.Duration `envconfig:"PGX_MAX_CONN_IDLE_TIME" default:"5m"`
-		HealthCheckPeriod time.Duration `envconfig:"PGX_HEALTH_CHECK_PERIOD" default:"1m"`
-		ConnectTimeout    time.Duration `envconfig:"PGX_CONNECT_TIMEOUT" default:"5s"`
+		MaxConns           int32         `envconfig:"PGX_MAX_CONNS" default:"20"`
+		MinConns           int32         `envconfig:"PGX_MIN_CONNS" default:"5"`
+		MaxConnLifetime    time.Duration `envconfig:"PGX_MAX_CONN_LIFETIME" default:"30m"`
+		MaxConnIdleTime    time.Duration `envconfig:"PGX_MAX_CONN_IDLE_TIME" default:"5m"`
+		HealthCheckPeriod  time.Duration `envconfig:"PGX_HEALTH_CHECK_PERIOD" default:"1m"`
+		ConnectTimeout     time.Duration `envconfig:"PGX_CONNECT_TIMEOUT" default:"5s"`
+		SlowQueryThreshold time.Duration `envconfig:"PGX_SLOW_QUERY_THRESHOLD" default:"200ms"` // SlowQueryThreshold logs slower ledger queries, 0 disables it
+		TraceQueries       string        `envconfig:"PGX_TRACE_QUERIES" default:"errors"`       // TraceQueries reports off, errors or all queries to the logs
+		QueryTimeout       time.Duration `envconfig:"PGX_QUERY_TIMEOUT" default:"5s"`           // QueryTimeout aborts a ledger statement loading or saving accounts, 0 disables it
+		ReadQueryTimeout   time.Duration `envconfig:"PGX_READ_QUERY_TIMEOUT" default:"15s"`     // ReadQueryTimeout aborts a ledger listing statement, 0 disables it
+		MaxAcquireWait     time.Duration `envconfig:"PGX_MAX_ACQUIRE_WAIT" default:"3s"`        // MaxAcquireWait fails a query with DATABASE_BUSY when no connection frees up in time, 0 waits as long as the request
+		WarmUp             bool          `envconfig:"PGX_WARM_UP" default:"true"`               // WarmUp opens the MinConns connections on startup, before serving
+		StatsInterval      time.Duration `envconfig:"PGX_STATS_INTERVAL" default:"15s"`         // StatsInterval is how often the pool stats are sampled into the metrics
 	}
 	Database struct {
 		Host     string `envconfig:"DB_HOST" required:"true"`
@@ -32,18 +52,281 @@ type Config struct {
 		Name     string `envconfig:"DB_NAME" required:"true"`
 		SSLMode  string `envconfig:"DB_SSL_MODE" default:"disable"`
 	}
+	// DatabaseReplica serves the listings, reports and exports from a read replica, disabled when the host is empty
+	// The replica is reached with the user, password and database of the primary
+	DatabaseReplica struct {
+		Host             string        `envconfig:"DB_REPLICA_HOST"`
+		Port             int           `envconfig:"DB_REPLICA_PORT" default:"5432"`
+		MaxLag           time.Duration `envconfig:"DB_REPLICA_MAX_LAG" default:"5s"`            // MaxLag sends the reads back to the primary while the replica lags further behind
+		LagCheckInterval time.Duration `envconfig:"DB_REPLICA_LAG_CHECK_INTERVAL" default:"5s"` // LagCheckInterval is how often the lag of the replica is measured
+	}
+	// API negotiates the shape of the request bodies through the application/vnd.fintrack.v<N>+json media types, plain
+	// application/json bodies following DefaultVersion so breaking changes ship alongside the former shape
+	API struct {
+		DefaultVersion int    `envconfig:"API_DEFAULT_VERSION" default:"1"`
+		V1Sunset       string `envconfig:"API_V1_SUNSET"` // V1Sunset is the day version 1 stops being served (e.g., 2026-06-30), announced by its responses, no date being announced when empty
+	}
+	Admin struct {
+		Token        string   `envconfig:"ADMIN_TOKEN"`         // Token protects the /admin endpoints, which are disabled when empty
+		AllowedCIDRs []string `envconfig:"ADMIN_ALLOWED_CIDRS"` // AllowedCIDRs restricts the /admin endpoints to these networks (e.g., 10.0.0.0/8), any network being allowed when empty
+	}
+	// Signing authenticates machine-to-machine callers by an HMAC of the timestamp and body of their requests
+	Signing struct {
+		Secrets      []string      `envconfig:"REQUEST_SIGNING_SECRETS"`                    // Secrets are the accepted keys, comma separated to rotate them
+		ReplayWindow time.Duration `envconfig:"REQUEST_SIGNING_REPLAY_WINDOW" default:"5m"` // ReplayWindow is how far a signature timestamp may be from now
+	}
+	Pagination PaginationConfig
+	// RateLimit caps the requests of each client IP over a sliding window, disabled when Requests is 0
+	RateLimit struct {
+		Requests int           `envconfig:"RATE_LIMIT_REQUESTS" default:"300"`
+		Window   time.Duration `envconfig:"RATE_LIMIT_WINDOW" default:"1m"`
+	}
+	// CostLimit protects the reports and exports apart from RateLimit: each user runs a few at once, the next ones
+	// waiting in a queue, and spends a budget of months of history over a window (e.g., a 10-year export costs 120)
+	CostLimit struct {
+		Concurrent   int           `envconfig:"COST_LIMIT_CONCURRENT" default:"2"`     // Concurrent is how many costly requests a user runs at once, 0 for no cap
+		MaxQueued    int           `envconfig:"COST_LIMIT_MAX_QUEUED" default:"4"`     // MaxQueued is how many more wait for a slot, the next ones being rejected
+		QueueTimeout time.Duration `envconfig:"COST_LIMIT_QUEUE_TIMEOUT" default:"5s"` // QueueTimeout is how long a request waits for a slot
+		Budget       int           `envconfig:"COST_LIMIT_BUDGET" default:"360"`       // Budget is the months of history a user may ask for per window, 0 for no budget
+		Window       time.Duration `envconfig:"COST_LIMIT_WINDOW" default:"1h"`
+	}
+	// Redis keeps the rate limit windows shared by every instance, each instance counting on its own when Addr is empty
+	Redis struct {
+		Addr     string `envconfig:"REDIS_ADDR"`
+		Password string `envconfig:"REDIS_PASSWORD"`
+		DB       int    `envconfig:"REDIS_DB" default:"0"`
+	}
+	Backup struct {
+		MaxRestoreSize string `envconfig:"BACKUP_MAX_RESTORE_SIZE" default:"50MB"` // MaxRestoreSize caps the archives accepted by the restore endpoint
+	}
+	// Attachments keeps the files attached to transactions in an S3 compatible bucket, or in a local directory while
+	// the bucket is empty, which suits development only since the API then serves the files itself
+	Attachments struct {
+		MaxFileSize        int64         `envconfig:"ATTACHMENTS_MAX_FILE_SIZE" default:"10485760"` // MaxFileSize is the largest file accepted, in bytes
+		QuotaBytes         int64         `envconfig:"ATTACHMENTS_QUOTA_BYTES" default:"524288000"`  // QuotaBytes is the storage each user may fill with attachments
+		URLTTL             time.Duration `envconfig:"ATTACHMENTS_URL_TTL" default:"5m"`             // URLTTL is how long a download URL works
+		OrphanGrace        time.Duration `envconfig:"ATTACHMENTS_ORPHAN_GRACE" default:"24h"`       // OrphanGrace is how long the files of a deleted transaction are kept, in case it is restored
+		LocalDir           string        `envconfig:"ATTACHMENTS_LOCAL_DIR" default:"./data/attachments"`
+		LocalSigningSecret string        `envconfig:"ATTACHMENTS_LOCAL_SIGNING_SECRET"`                                          // LocalSigningSecret signs the local download URLs, a random one lasting until the restart when empty
+		PublicBaseURL      string        `envconfig:"ATTACHMENTS_PUBLIC_BASE_URL" default:"http://localhost:9999/api/v1/public"` // PublicBaseURL is where the clients reach the public routes of the API
+		S3Endpoint         string        `envconfig:"ATTACHMENTS_S3_ENDPOINT" default:"https://s3.amazonaws.com"`
+		S3Region           string        `envconfig:"ATTACHMENTS_S3_REGION" default:"us-east-1"`
+		S3Bucket           string        `envconfig:"ATTACHMENTS_S3_BUCKET"`
+		S3AccessKeyID      string        `envconfig:"ATTACHMENTS_S3_ACCESS_KEY_ID"`
+		S3SecretAccessKey  string        `envconfig:"ATTACHMENTS_S3_SECRET_ACCESS_KEY"`
+		S3Timeout          time.Duration `envconfig:"ATTACHMENTS_S3_TIMEOUT" default:"30s"`
+	}
+	// Warehouse exports the ledger changes of the users who opted in as Parquet files, which BigQuery, Athena or Spark load
+	// as they are. Files go to an S3 compatible bucket, or to a local directory while the bucket is empty, the export being
+	// disabled when both are empty
+	Warehouse struct {
+		Prefix            string        `envconfig:"WAREHOUSE_PREFIX" default:"ledger_changes"` // Prefix is the folder of the files, partitioned by day below it (e.g., ledger_changes/dt=2025-10-19/)
+		BatchSize         int           `envconfig:"WAREHOUSE_BATCH_SIZE" default:"10000"`      // BatchSize is how many ledger changes go in a file at most
+		SettleDelay       time.Duration `envconfig:"WAREHOUSE_SETTLE_DELAY" default:"1m"`       // SettleDelay is how old a change must be to be exported, so a late commit is not skipped
+		LocalDir          string        `envconfig:"WAREHOUSE_LOCAL_DIR"`
+		S3Endpoint        string        `envconfig:"WAREHOUSE_S3_ENDPOINT" default:"https://s3.amazonaws.com"`
+		S3Region          string        `envconfig:"WAREHOUSE_S3_REGION" default:"us-east-1"`
+		S3Bucket          string        `envconfig:"WAREHOUSE_S3_BUCKET"`
+		S3AccessKeyID     string        `envconfig:"WAREHOUSE_S3_ACCESS_KEY_ID"`
+		S3SecretAccessKey string        `envconfig:"WAREHOUSE_S3_SECRET_ACCESS_KEY"`
+		S3Timeout         time.Duration `envconfig:"WAREHOUSE_S3_TIMEOUT" default:"60s"`
+	}
+	// BankConnect syncs bank accounts through aggregators, each provider being disabled while its client ID is empty
+	BankConnect struct {
+		SecretKey           string        `envconfig:"BANK_CONNECT_SECRET_KEY"`            // SecretKey is the base64 AES-256 key encrypting the consent secrets, required by Plaid
+		Timeout             time.Duration `envconfig:"BANK_CONNECT_TIMEOUT" default:"15s"` // Timeout bounds every request to the providers
+		PluggyBaseURL       string        `envconfig:"BANK_CONNECT_PLUGGY_BASE_URL" default:"https://api.pluggy.ai"`
+		PluggyClientID      string        `envconfig:"BANK_CONNECT_PLUGGY_CLIENT_ID"`
+		PluggyClientSecret  string        `envconfig:"BANK_CONNECT_PLUGGY_CLIENT_SECRET"`
+		PluggyWebhookSecret string        `envconfig:"BANK_CONNECT_PLUGGY_WEBHOOK_SECRET"`                              // PluggyWebhookSecret is the X-Webhook-Secret header set on the Pluggy webhooks, which are rejected when empty
+		PlaidBaseURL        string        `envconfig:"BANK_CONNECT_PLAID_BASE_URL" default:"https://sandbox.plaid.com"` // PlaidBaseURL selects the sandbox, development or production environment
+		PlaidClientID       string        `envconfig:"BANK_CONNECT_PLAID_CLIENT_ID"`
+		PlaidSecret         string        `envconfig:"BANK_CONNECT_PLAID_SECRET"`
+		PlaidCountryCodes   []string      `envconfig:"BANK_CONNECT_PLAID_COUNTRY_CODES" default:"US"` // PlaidCountryCodes lists the countries offered in Plaid Link (e.g., US,GB,FR)
+		PlaidLanguage       string        `envconfig:"BANK_CONNECT_PLAID_LANGUAGE" default:"en"`
+		PlaidWebhookURL     string        `envconfig:"BANK_CONNECT_PLAID_WEBHOOK_URL"` // PlaidWebhookURL is the public URL of /api/v1/webhooks/banks/plaid, no webhooks are sent when empty
+	}
+	// Demo mode provisions throwaway users whose ledger data lives in memory, for product demos
+	// It must never be enabled on the production deployment
+	Demo struct {
+		Enabled     bool          `envconfig:"DEMO_ENABLED" default:"false"`
+		SessionTTL  time.Duration `envconfig:"DEMO_SESSION_TTL" default:"2h"`
+		SeedMonths  int           `envconfig:"DEMO_SEED_MONTHS" default:"6"`
+		MaxSessions int           `envconfig:"DEMO_MAX_SESSIONS" default:"200"`
+	}
+
+	Ledger        LedgerConfig
+	Identity      IdentityConfig
+	Notifications NotificationsConfig
+	Jobs          JobsConfig
+	Observability ObservabilityConfig
+}
+
+// LedgerConfig configures the accounts and transactions, and how their amounts are rounded, valued and encrypted
+type LedgerConfig struct {
+	ArchivedAccountRetention time.Duration `envconfig:"LEDGER_ARCHIVED_ACCOUNT_RETENTION" default:"0"` // ArchivedAccountRetention is how long archived accounts are kept before being purged, 0 keeps them forever
+	AccountDeletionGrace     time.Duration `envconfig:"LEDGER_ACCOUNT_DELETION_GRACE" default:"720h"`  // AccountDeletionGrace is how long a deleted account can be restored before being deleted for good
+	UndoWindow               time.Duration `envconfig:"LEDGER_UNDO_WINDOW" default:"5m"`               // UndoWindow is how long the last mutations of a user can be undone, 0 disables undo
+	MaxActiveTransactions    int           `envconfig:"LEDGER_MAX_ACTIVE_TRANSACTIONS" default:"5000"` // MaxActiveTransactions is the soft cap of transactions per account past which archiving its history is suggested, 0 never suggests it
+	// Money rounds the currency conversions and allocations to the minor unit, the shares always adding up to the amount split
+	Money struct {
+		RoundingMode string `envconfig:"MONEY_ROUNDING_MODE" default:"HALF_EVEN"` // RoundingMode is HALF_EVEN, HALF_UP, DOWN or UP
+	}
+	// Investments values the positions of investment accounts, quotes are written as TICKER:"<amount> <currency>" pairs
+	Investments struct {
+		Quotes        map[string]string `envconfig:"INVESTMENTS_QUOTES"`                        // Quotes are fixed prices by ticker (e.g., PETR4:38.50 BRL,IVVB11:310.20 BRL)
+		QuoteCacheTTL time.Duration     `envconfig:"INVESTMENTS_QUOTE_CACHE_TTL" default:"15m"` // QuoteCacheTTL is how long a quote is served before asking the provider again
+	}
+	// Interest simulates the interest of the savings accounts, whose yields are a percentage of the CDI or a fixed rate
+	Interest struct {
+		CDIAnnualRate string `envconfig:"INTEREST_CDI_ANNUAL_RATE" default:"14.90"` // CDIAnnualRate is the annual CDI percentage the simulations assume
+	}
+	// FieldEncryption encrypts the observations and the metadata of the transactions with a data key per user,
+	// the fields being stored in plain text while the master key is empty
+	FieldEncryption struct {
+		MasterKey string `envconfig:"FIELD_ENCRYPTION_MASTER_KEY"` // MasterKey is the base64 AES-256 key wrapping the data keys of the users
+	}
+}
+
+// IdentityConfig configures the gRPC client of the identity service, which authenticates the users
+type IdentityConfig struct {
+	// Addr is the internal listener of the identity service, the public one not serving the service-only methods
+	Addr       string        `envconfig:"IDENTITY_GRPC_ADDR" default:"localhost:50052"`
+	Timeout    time.Duration `envconfig:"IDENTITY_GRPC_TIMEOUT" default:"3s"`
+	MaxRetries int           `envconfig:"IDENTITY_GRPC_MAX_RETRIES" default:"2"`
+	// RequireVerifiedEmail rejects the mutating requests of the users who did not verify their email
+	RequireVerifiedEmail bool `envconfig:"IDENTITY_REQUIRE_VERIFIED_EMAIL" default:"false"`
+	// ServiceToken is the INTERNAL_SERVICE_TOKEN of the identity service, sent on the service-only calls (e.g., the
+	// impersonations)
+	ServiceToken string `envconfig:"IDENTITY_SERVICE_TOKEN"`
+}
+
+// NotificationsConfig configures the delivery of the notifications sent to the users
+type NotificationsConfig struct {
+	WebhookTimeout       time.Duration `envconfig:"NOTIFICATIONS_WEBHOOK_TIMEOUT" default:"5s"`
+	WebhookSigningSecret string        `envconfig:"NOTIFICATIONS_WEBHOOK_SIGNING_SECRET"`
+	WebhookDisableAfter  time.Duration `envconfig:"NOTIFICATIONS_WEBHOOK_DISABLE_AFTER" default:"24h"` // WebhookDisableAfter is how long a webhook fails every delivery before being disabled, 0 never disables it
+}
+
+// JobsConfig configures the background jobs: the schedule of the recurring ones and the data retention they apply
+type JobsConfig struct {
+	Scheduler struct {
+		Enabled              bool          `envconfig:"SCHEDULER_ENABLED" default:"true"`
+		LockKey              int64         `envconfig:"SCHEDULER_LOCK_KEY" default:"727001"`
+		LeaderRetryInterval  time.Duration `envconfig:"SCHEDULER_LEADER_RETRY_INTERVAL" default:"15s"`
+		JobsPurgeCron        string        `envconfig:"SCHEDULER_JOBS_PURGE_CRON" default:"0 3 * * *"`
+		JobsRetention        time.Duration `envconfig:"SCHEDULER_JOBS_RETENTION" default:"720h"`
+		NetWorthSnapshotCron string        `envconfig:"SCHEDULER_NET_WORTH_SNAPSHOT_CRON" default:"30 0 * * *"` // NetWorthSnapshotCron takes the daily net worth snapshots, after midnight UTC
+		BankSyncCron         string        `envconfig:"SCHEDULER_BANK_SYNC_CRON" default:"0 */6 * * *"`         // BankSyncCron syncs the accounts linked to Open Finance consents
+		WeeklySummaryCron    string        `envconfig:"SCHEDULER_WEEKLY_SUMMARY_CRON" default:"0 9 * * 1"`      // WeeklySummaryCron emails the summary of the previous week, Mondays by default
+		MonthlyStatementCron string        `envconfig:"SCHEDULER_MONTHLY_STATEMENT_CRON" default:"0 8 * * *"`   // MonthlyStatementCron emails the statements of the financial months that ended, checked daily
+		RetentionCron        string        `envconfig:"SCHEDULER_RETENTION_CRON" default:"0 4 * * *"`           // RetentionCron applies the retention policy
+		SagasResumeCron      string        `envconfig:"SCHEDULER_SAGAS_RESUME_CRON" default:"* * * * *"`        // SagasResumeCron retries the failed saga steps and resumes the sagas interrupted by a restart
+		AccountPurgeCron     string        `envconfig:"SCHEDULER_ACCOUNT_PURGE_CRON" default:"15 * * * *"`      // AccountPurgeCron deletes for good the accounts whose deletion grace period elapsed
+		AttachmentsGCCron    string        `envconfig:"SCHEDULER_ATTACHMENTS_GC_CRON" default:"45 * * * *"`     // AttachmentsGCCron removes the attachments of deleted transactions once their grace period elapsed
+		WarehouseExportCron  string        `envconfig:"SCHEDULER_WAREHOUSE_EXPORT_CRON" default:"30 * * * *"`   // WarehouseExportCron exports the ledger changes to the data warehouse
+	}
+	// Retention removes old data once it is kept for longer than its rule allows, every rule being disabled at 0
+	// Archived accounts follow LEDGER_ARCHIVED_ACCOUNT_RETENTION
+	Retention struct {
+		DryRun            bool `envconfig:"RETENTION_DRY_RUN" default:"false"`          // DryRun only logs what the scheduled runs would remove
+		ObservationsYears int  `envconfig:"RETENTION_OBSERVATIONS_YEARS" default:"0"`   // ObservationsYears trims the observations of the transactions due longer ago
+		ObservationLength int  `envconfig:"RETENTION_OBSERVATION_LENGTH" default:"100"` // ObservationLength is how many characters of a trimmed observation are kept
+		AuditLogsYears    int  `envconfig:"RETENTION_AUDIT_LOGS_YEARS" default:"0"`     // AuditLogsYears deletes the audit records older than this
+	}
+}
+
+// ObservabilityConfig configures the logs and the error reporting
+type ObservabilityConfig struct {
+	Log struct {
+		Level           string        `envconfig:"LOG_LEVEL" default:"debug"`
+		SamplePerSecond int           `envconfig:"LOG_SAMPLE_PER_SECOND" default:"100"`
+		Bodies          string        `envconfig:"LOG_BODIES" default:"off"`            // Bodies logs request/response bodies: off, header (X-Debug-Bodies) or always
+		BodiesMaxBytes  int           `envconfig:"LOG_BODIES_MAX_BYTES" default:"4096"` // BodiesMaxBytes caps each logged body
+		Stdout          bool          `envconfig:"LOG_STDOUT" default:"true"`           // Stdout writes the logs to the standard output
+		File            string        `envconfig:"LOG_FILE"`                            // File also writes the logs to a rotated file, disabled when empty
+		FileMaxSizeMB   int64         `envconfig:"LOG_FILE_MAX_SIZE_MB" default:"100"`  // FileMaxSizeMB rotates the file once it reaches this size
+		FileMaxAge      time.Duration `envconfig:"LOG_FILE_MAX_AGE" default:"24h"`      // FileMaxAge rotates the file once it is this old
+		FileMaxBackups  int           `envconfig:"LOG_FILE_MAX_BACKUPS" default:"7"`    // FileMaxBackups is the number of rotated files kept
+	}
+	// ErrorReporting sends 5xx and unhandled errors to Sentry, disabled when the DSN is empty
+	ErrorReporting struct {
+		SentryDSN   string        `envconfig:"SENTRY_DSN"`
+		Environment string        `envconfig:"SENTRY_ENVIRONMENT" default:"development"`
+		Release     string        `envconfig:"SENTRY_RELEASE"`
+		Timeout     time.Duration `envconfig:"SENTRY_TIMEOUT" default:"5s"`
+	}
 }
 
-func Load() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
+// PaginationConfig bounds the result sets clients may request from the list and export endpoints
+type PaginationConfig struct {
+	DefaultPageSize int `envconfig:"PAGINATION_DEFAULT_PAGE_SIZE" default:"50"`   // DefaultPageSize is the page size when the client sets no limit
+	MaxPageSize     int `envconfig:"PAGINATION_MAX_PAGE_SIZE" default:"100"`      // MaxPageSize is the largest limit a client may request
+	MaxExportRows   int `envconfig:"PAGINATION_MAX_EXPORT_ROWS" default:"100000"` // MaxExportRows bounds the transactions of a backup export
+}
+
+// Load reads the configuration from these sources, each one overriding the ones below it:
+//
+//  1. the command-line flags in args, one per variable (e.g., -log-level for LOG_LEVEL)
+//  2. the environment
+//  3. the .env file of the profile (e.g., .env.staging), optional
+//  4. the .env file, optional
+//  5. the YAML or TOML file named by the -config flag or CONFIG_FILE, optional
+//  6. the defaults of the profile, then the defaults of the fields
+//
+// It fails with every problem of the configuration at once
+func Load(args []string) (*Config, error) {
+	keys := envKeys(reflect.TypeFor[Config]())
+	file, flagValues, err := parseFlags(args, keys)
+	if err != nil {
+		return nil, err
+	}
+	// The flags win over the environment, so they are the only values written over it
+	for key, value := range flagValues {
+		if err := os.Setenv(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	dotenv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Printf("error loading .env file: %s", err)
 		return nil, err
 	}
 
+	if file == "" {
+		file = cmp.Or(os.Getenv(configFileVar), dotenv[configFileVar])
+	}
+	var fileValues map[string]string
+	if file != "" {
+		if fileValues, err = readFile(file, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	profile, err := activeProfile(dotenv, fileValues)
+	if err != nil {
+		return nil, err
+	}
+	for _, envFile := range []string{profile.envFile(), ".env"} {
+		if err := godotenv.Load(envFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to load %s: %w", envFile, err)
+		}
+	}
+	if err := setUnset(fileValues); err != nil {
+		return nil, err
+	}
+	if err := setUnset(profileDefaults[profile]); err != nil {
+		return nil, err
+	}
+
 	var cfg Config
 	if err := envconfig.Process("", &cfg); err != nil {
 		return nil, fmt.Errorf("failed to process config from environment: %w", err)
 	}
-	log.Println("✔️ Configuration loaded successfully")
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	log.Printf("✔️ Configuration loaded successfully (profile %s)", cfg.Profile)
 	return &cfg, nil
 }
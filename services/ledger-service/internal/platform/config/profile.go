@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	ProfileDev     Profile = "dev"
+	ProfileStaging Profile = "staging"
+	ProfileProd    Profile = "prod"
+)
+
+// Profile names the environment the service is deployed to, each one overriding the defaults that do not suit it
+// (e.g., the debug logs of development)
+type Profile string
+
+// profileDefaults replace the defaults of the variables a profile sets nowhere, the dev profile keeping the defaults
+var profileDefaults = map[Profile]map[string]string{
+	ProfileDev: {},
+	ProfileStaging: {
+		"LOG_LEVEL":          "info",
+		"DB_SSL_MODE":        "require",
+		"SENTRY_ENVIRONMENT": "staging",
+	},
+	ProfileProd: {
+		"LOG_LEVEL":          "info",
+		"LOG_BODIES":         "off",
+		"DB_SSL_MODE":        "require",
+		"SENTRY_ENVIRONMENT": "production",
+	},
+}
+
+// activeProfile returns the profile set by APP_PROFILE in the environment or else in the first source setting it, dev
+// by default
+func activeProfile(sources ...map[string]string) (Profile, error) {
+	name, ok := os.LookupEnv("APP_PROFILE")
+	for _, source := range sources {
+		if ok {
+			break
+		}
+		name, ok = source["APP_PROFILE"]
+	}
+	if name == "" {
+		return ProfileDev, nil
+	}
+
+	profile := Profile(name)
+	if _, ok := profileDefaults[profile]; !ok {
+		return "", fmt.Errorf("unknown APP_PROFILE %q, expected dev, staging or prod", name)
+	}
+	return profile, nil
+}
+
+// envFile is the optional .env file of the profile (e.g., .env.staging), overriding the .env file
+func (p Profile) envFile() string {
+	return ".env." + string(p)
+}
@@ -0,0 +1,37 @@
+package config
+
+// RequiredSecret is a secret an enabled feature of the configuration needs to work
+type RequiredSecret struct {
+	Var        string // Var is the variable holding the secret
+	Set        bool   // Set reports whether the variable holds a value
+	RequiredBy string // RequiredBy names the feature needing it
+}
+
+// RequiredSecrets lists the secrets the enabled features need, whether they are set or not. The prod profile also
+// requires the master key of the field encryption, the observations being stored in plain text without it
+func (c *Config) RequiredSecrets() []RequiredSecret {
+	var secrets []RequiredSecret
+	require := func(v, value, requiredBy string) {
+		secrets = append(secrets, RequiredSecret{Var: v, Set: value != "", RequiredBy: requiredBy})
+	}
+
+	if c.BankConnect.PluggyClientID != "" {
+		require("BANK_CONNECT_PLUGGY_CLIENT_SECRET", c.BankConnect.PluggyClientSecret, "the Pluggy bank connections")
+	}
+	if c.BankConnect.PlaidClientID != "" {
+		require("BANK_CONNECT_PLAID_SECRET", c.BankConnect.PlaidSecret, "the Plaid bank connections")
+		require("BANK_CONNECT_SECRET_KEY", c.BankConnect.SecretKey, "the Plaid bank connections")
+	}
+	if c.Attachments.S3Bucket != "" {
+		require("ATTACHMENTS_S3_ACCESS_KEY_ID", c.Attachments.S3AccessKeyID, "the attachments bucket")
+		require("ATTACHMENTS_S3_SECRET_ACCESS_KEY", c.Attachments.S3SecretAccessKey, "the attachments bucket")
+	}
+	if c.Warehouse.S3Bucket != "" {
+		require("WAREHOUSE_S3_ACCESS_KEY_ID", c.Warehouse.S3AccessKeyID, "the warehouse bucket")
+		require("WAREHOUSE_S3_SECRET_ACCESS_KEY", c.Warehouse.S3SecretAccessKey, "the warehouse bucket")
+	}
+	if c.Profile == ProfileProd {
+		require("FIELD_ENCRYPTION_MASTER_KEY", c.Ledger.FieldEncryption.MasterKey, "the field encryption of the prod profile")
+	}
+	return secrets
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileVar names the configuration file when the -config flag is not given
+const configFileVar = "CONFIG_FILE"
+
+// envKeys returns the variables read by the fields of t and of its sections, in declaration order
+func envKeys(t reflect.Type) []string {
+	var keys []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if key := field.Tag.Get("envconfig"); key != "" {
+			keys = append(keys, key)
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, envKeys(field.Type)...)
+		}
+	}
+	return keys
+}
+
+// flagName is the command-line flag of a variable (e.g., -log-level for LOG_LEVEL)
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// parseFlags reads the command line, where -config names the configuration file and every variable has a flag named
+// after it, returning the file and the variables the flags set
+func parseFlags(args []string, keys []string) (string, map[string]string, error) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	file := fs.String("config", "", "configuration file (.yaml, .yml or .toml), overriding "+configFileVar)
+
+	values := make(map[string]string)
+	for _, key := range keys {
+		fs.Func(flagName(key), "sets "+key, func(value string) error {
+			values[key] = value
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() > 0 {
+		return "", nil, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	return *file, values, nil
+}
+
+// readFile reads the variables of a YAML or TOML configuration file, whose nested keys are joined with underscores
+// (e.g., log: {level: info} sets LOG_LEVEL). A list is joined with commas and a map set as a whole is written as
+// key:value pairs, as envconfig reads them. A key that no variable matches is rejected, so a typo is not ignored
+func readFile(path string, keys []string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	tree := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		tree, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, keys, values)
+
+	var unknown []string
+	for key := range values {
+		if !slices.Contains(keys, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, fmt.Errorf("unknown keys in config file %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+// flatten writes the leaves of tree to values, keyed by their path joined with underscores and uppercased
+func flatten(prefix string, tree map[string]any, keys []string, values map[string]string) {
+	for name, value := range tree {
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			if slices.Contains(keys, key) {
+				values[key] = joinPairs(v)
+				continue
+			}
+			flatten(key, v, keys, values)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// joinPairs writes a map as the key:value pairs of envconfig, sorted by key
+func joinPairs(m map[string]any) string {
+	pairs := make([]string, 0, len(m))
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		pairs = append(pairs, fmt.Sprintf("%s:%v", key, m[key]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// setUnset writes the values to the environment, skipping the variables already set there
+func setUnset(values map[string]string) error {
+	var errs []error
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
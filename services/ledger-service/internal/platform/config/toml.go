@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML the configuration files need: tables, dotted keys, strings, numbers, booleans,
+// and single-line arrays and inline tables. Values that are not strings are kept as written (e.g., 15m or 0.5)
+func parseTOML(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	table := root
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", n+1, line)
+			}
+			var err error
+			if table, err = subTable(root, strings.TrimSpace(line[1:len(line)-1])); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			continue
+		}
+
+		if err := setTOMLPair(table, line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	return root, nil
+}
+
+// setTOMLPair sets the key = value pair of line in table
+func setTOMLPair(table map[string]any, line string) error {
+	name, raw, found := strings.Cut(line, "=")
+	if !found {
+		return fmt.Errorf("expected key = value, got %q", line)
+	}
+
+	path := strings.Split(strings.TrimSpace(name), ".")
+	parent, err := subTable(table, strings.Join(path[:len(path)-1], "."))
+	if err != nil {
+		return err
+	}
+	key, err := tomlKey(path[len(path)-1])
+	if err != nil {
+		return err
+	}
+
+	value, err := tomlValue(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("key %q: %w", key, err)
+	}
+	parent[key] = value
+	return nil
+}
+
+// subTable returns the table at the dotted path below root, creating the missing ones
+func subTable(root map[string]any, path string) (map[string]any, error) {
+	table := root
+	if path == "" {
+		return table, nil
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		key, err := tomlKey(part)
+		if err != nil {
+			return nil, err
+		}
+		next, ok := table[key].(map[string]any)
+		if !ok {
+			if _, taken := table[key]; taken {
+				return nil, fmt.Errorf("key %q is both a value and a table", key)
+			}
+			next = make(map[string]any)
+			table[key] = next
+		}
+		table = next
+	}
+	return table, nil
+}
+
+// tomlKey returns a bare or quoted key
+func tomlKey(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("empty key")
+	}
+	if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'") {
+		return tomlString(raw)
+	}
+	return raw, nil
+}
+
+// tomlValue parses a string, an array, an inline table or a scalar kept as written
+func tomlValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+		return tomlString(raw)
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return nil, fmt.Errorf("arrays must be written on a single line")
+		}
+		items := make([]any, 0)
+		for _, item := range splitTopLevel(raw[1 : len(raw)-1]) {
+			value, err := tomlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case strings.HasPrefix(raw, "{"):
+		if !strings.HasSuffix(raw, "}") {
+			return nil, fmt.Errorf("inline tables must be written on a single line")
+		}
+		table := make(map[string]any)
+		for _, pair := range splitTopLevel(raw[1 : len(raw)-1]) {
+			if err := setTOMLPair(table, pair); err != nil {
+				return nil, err
+			}
+		}
+		return table, nil
+	default:
+		return raw, nil
+	}
+}
+
+// tomlString unquotes a basic ("...") or literal ('...') string
+func tomlString(raw string) (string, error) {
+	if len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	s, err := strconv.Unquote(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", raw)
+	}
+	return s, nil
+}
+
+// splitTopLevel splits the items of an array or inline table on the commas outside of strings and nested brackets,
+// dropping the empty ones (e.g., after a trailing comma)
+func splitTopLevel(s string) []string {
+	var items []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	items = append(items, s[start:])
+
+	kept := items[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// stripComment drops the # comment ending a line, ignoring the # inside strings
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/pkg/logger"
+	"github.com/Guizzs26/fintrack/pkg/money"
+)
+
+// traceModes are the values of PGX_TRACE_QUERIES, kept here since the postgres package depends on the config
+var traceModes = []string{"off", "errors", "all"}
+
+// problems collects the problems of a configuration, so they are all reported at once
+type problems []error
+
+// check records the problem described by format when ok is false
+func (p *problems) check(ok bool, format string, args ...any) {
+	if !ok {
+		*p = append(*p, fmt.Errorf(format, args...))
+	}
+}
+
+// Validate checks the values of the configuration and how they fit together, returning every problem found joined
+// in a single error, or nil when the configuration is valid. The prod profile also rejects the settings meant for
+// development only
+func (c *Config) Validate() error {
+	var p problems
+
+	_, known := profileDefaults[c.Profile]
+	p.check(known, "APP_PROFILE %q is not dev, staging or prod", c.Profile)
+
+	p.check(c.Server.Port != "", "SERVER_PORT is required")
+	p.check(c.Postgres.MaxConns > 0, "PGX_MAX_CONNS must be positive, got %d", c.Postgres.MaxConns)
+	p.check(c.Postgres.MinConns >= 0 && c.Postgres.MinConns <= c.Postgres.MaxConns,
+		"PGX_MIN_CONNS must be between 0 and PGX_MAX_CONNS (%d), got %d", c.Postgres.MaxConns, c.Postgres.MinConns)
+	p.check(slices.Contains(traceModes, strings.ToLower(strings.TrimSpace(c.Postgres.TraceQueries))), "PGX_TRACE_QUERIES %q is not off, errors or all", c.Postgres.TraceQueries)
+	p.check(c.API.DefaultVersion >= 1, "API_DEFAULT_VERSION must be at least 1, got %d", c.API.DefaultVersion)
+	if c.API.V1Sunset != "" {
+		_, err := time.Parse(time.DateOnly, c.API.V1Sunset)
+		p.check(err == nil, "API_V1_SUNSET %q is not a YYYY-MM-DD date", c.API.V1Sunset)
+	}
+	p.check(c.Pagination.DefaultPageSize > 0 && c.Pagination.DefaultPageSize <= c.Pagination.MaxPageSize,
+		"PAGINATION_DEFAULT_PAGE_SIZE must be between 1 and PAGINATION_MAX_PAGE_SIZE (%d), got %d", c.Pagination.MaxPageSize, c.Pagination.DefaultPageSize)
+	p.check(c.RateLimit.Requests >= 0, "RATE_LIMIT_REQUESTS must not be negative, got %d", c.RateLimit.Requests)
+	p.check(c.RateLimit.Requests == 0 || c.RateLimit.Window > 0, "RATE_LIMIT_WINDOW must be positive while the rate limit is enabled")
+	p.check(c.CostLimit.Concurrent >= 0, "COST_LIMIT_CONCURRENT must not be negative, got %d", c.CostLimit.Concurrent)
+	p.check(c.CostLimit.MaxQueued >= 0, "COST_LIMIT_MAX_QUEUED must not be negative, got %d", c.CostLimit.MaxQueued)
+	p.check(c.CostLimit.Concurrent == 0 || c.CostLimit.QueueTimeout >= 0, "COST_LIMIT_QUEUE_TIMEOUT must not be negative")
+	p.check(c.CostLimit.Budget >= 0, "COST_LIMIT_BUDGET must not be negative, got %d", c.CostLimit.Budget)
+	p.check(c.CostLimit.Budget == 0 || c.CostLimit.Window > 0, "COST_LIMIT_WINDOW must be positive while the cost budget is enabled")
+
+	_, err := money.ParseRoundingPolicy(c.Ledger.Money.RoundingMode)
+	p.check(err == nil, "MONEY_ROUNDING_MODE %q is not HALF_EVEN, HALF_UP, DOWN or UP", c.Ledger.Money.RoundingMode)
+	p.check(c.Ledger.AccountDeletionGrace >= 0, "LEDGER_ACCOUNT_DELETION_GRACE must not be negative")
+	p.check(c.Ledger.UndoWindow >= 0, "LEDGER_UNDO_WINDOW must not be negative")
+	p.check(c.Ledger.MaxActiveTransactions >= 0, "LEDGER_MAX_ACTIVE_TRANSACTIONS must not be negative")
+
+	p.check(c.Identity.Addr != "", "IDENTITY_GRPC_ADDR is required")
+	p.check(c.Identity.Timeout > 0, "IDENTITY_GRPC_TIMEOUT must be positive")
+	p.check(c.Identity.MaxRetries >= 0, "IDENTITY_GRPC_MAX_RETRIES must not be negative, got %d", c.Identity.MaxRetries)
+
+	p.check(c.Notifications.WebhookTimeout > 0, "NOTIFICATIONS_WEBHOOK_TIMEOUT must be positive")
+	p.check(c.Notifications.WebhookDisableAfter >= 0, "NOTIFICATIONS_WEBHOOK_DISABLE_AFTER must not be negative")
+
+	p.check(c.Jobs.Retention.ObservationsYears >= 0, "RETENTION_OBSERVATIONS_YEARS must not be negative")
+	p.check(c.Jobs.Retention.ObservationLength > 0, "RETENTION_OBSERVATION_LENGTH must be positive, got %d", c.Jobs.Retention.ObservationLength)
+	p.check(c.Jobs.Retention.AuditLogsYears >= 0, "RETENTION_AUDIT_LOGS_YEARS must not be negative")
+
+	_, err = logger.ParseLevel(c.Observability.Log.Level)
+	p.check(err == nil, "LOG_LEVEL %q is not debug, info, warn or error", c.Observability.Log.Level)
+	p.check(slices.Contains([]httpx.BodyLogMode{httpx.BodyLogOff, httpx.BodyLogHeader, httpx.BodyLogAlways}, httpx.BodyLogMode(c.Observability.Log.Bodies)),
+		"LOG_BODIES %q is not off, header or always", c.Observability.Log.Bodies)
+	p.check(c.Observability.Log.Stdout || c.Observability.Log.File != "", "LOG_STDOUT is disabled without a LOG_FILE, the logs would be lost")
+
+	if c.Profile == ProfileProd {
+		p.check(!c.Demo.Enabled, "DEMO_ENABLED must not be true on the prod profile")
+		p.check(c.Database.SSLMode != "disable", "DB_SSL_MODE must not be disable on the prod profile")
+		p.check(c.Observability.Log.Bodies != string(httpx.BodyLogAlways), "LOG_BODIES must not be always on the prod profile, the bodies hold personal data")
+		p.check(c.Attachments.S3Bucket != "", "ATTACHMENTS_S3_BUCKET is required on the prod profile, the local directory suiting development only")
+	}
+
+	return errors.Join(p...)
+}
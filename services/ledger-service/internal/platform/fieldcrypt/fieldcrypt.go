@@ -0,0 +1,164 @@
+// Package fieldcrypt encrypts the sensitive free texts of the users (e.g., transaction observations) before they are
+// written, so a leaked database or backup does not expose them
+//
+// Every user has their own data key, stored wrapped by the master key of a KMS. The fields are sealed with the key of
+// their owner and bound to them, so a value copied to the row of another user does not open. Sealed values carry
+// Prefix, which leaves the values written before the encryption was enabled readable as they are
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Prefix marks the sealed values, followed by the base64 of their nonce and ciphertext
+const Prefix = "enc:v1:"
+
+// errMasterKeyRequired is returned when a sealed value is read without the encryption configured
+var errMasterKeyRequired = errors.New("FIELD_ENCRYPTION_MASTER_KEY is required to read the encrypted fields")
+
+// Cipher seals and opens the fields of each user with their data key, created on their first sealed field
+// A nil Cipher stores the fields in plain text, for setups without encryption
+type Cipher struct {
+	kms  KMS
+	keys KeyStore
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cipher.AEAD // cache holds the unwrapped keys, so the KMS is asked once per user
+}
+
+// NewCipher creates a Cipher wrapping the data keys with kms, returning nil when kms is nil
+func NewCipher(kms KMS, keys KeyStore) *Cipher {
+	if kms == nil {
+		return nil
+	}
+	return &Cipher{kms: kms, keys: keys, cache: make(map[uuid.UUID]cipher.AEAD)}
+}
+
+// NewLocalCipher creates a Cipher wrapping the data keys with a LocalKMS, returning nil when the master key is empty
+func NewLocalCipher(encodedMasterKey string, keys KeyStore) (*Cipher, error) {
+	kms, err := NewLocalKMS(encodedMasterKey)
+	if err != nil || kms == nil {
+		return nil, err
+	}
+	return NewCipher(kms, keys), nil
+}
+
+// Seal encrypts the field of the user, an empty field being stored as is
+func (c *Cipher) Seal(ctx context.Context, userID uuid.UUID, field []byte) ([]byte, error) {
+	if c == nil || len(field) == 0 {
+		return field, nil
+	}
+
+	aead, err := c.dataKey(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate field nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, field, userID[:])
+
+	encoded := make([]byte, len(Prefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(encoded, Prefix)
+	base64.StdEncoding.Encode(encoded[len(Prefix):], sealed)
+	return encoded, nil
+}
+
+// Open decrypts a field of the user sealed by Seal, returning the fields stored in plain text as they are
+func (c *Cipher) Open(ctx context.Context, userID uuid.UUID, stored []byte) ([]byte, error) {
+	encoded, ok := bytes.CutPrefix(stored, []byte(Prefix))
+	if !ok {
+		return stored, nil
+	}
+	if c == nil {
+		return nil, errMasterKeyRequired
+	}
+
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(sealed, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted field: %w", err)
+	}
+	sealed = sealed[:n]
+
+	aead, err := c.dataKey(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("encrypted field is too short")
+	}
+	field, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], userID[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt field: %w", err)
+	}
+	return field, nil
+}
+
+// SealString encrypts a text field of the user
+func (c *Cipher) SealString(ctx context.Context, userID uuid.UUID, field string) (string, error) {
+	sealed, err := c.Seal(ctx, userID, []byte(field))
+	return string(sealed), err
+}
+
+// OpenString decrypts a text field of the user
+func (c *Cipher) OpenString(ctx context.Context, userID uuid.UUID, stored string) (string, error) {
+	field, err := c.Open(ctx, userID, []byte(stored))
+	return string(field), err
+}
+
+// dataKey returns the cipher of the data key of the user, creating the key when create is set and they have none
+func (c *Cipher) dataKey(ctx context.Context, userID uuid.UUID, create bool) (cipher.AEAD, error) {
+	c.mu.RLock()
+	aead, ok := c.cache[userID]
+	c.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	wrapped, err := c.keys.FindKey(ctx, userID)
+	if errors.Is(err, ErrKeyNotFound) && create {
+		wrapped, err = c.createKey(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := c.kms.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if aead, err = newAEAD(key); err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	c.mu.Lock()
+	c.cache[userID] = aead
+	c.mu.Unlock()
+	return aead, nil
+}
+
+// createKey draws a new data key for the user and stores it wrapped, returning the wrapped key kept by the store
+func (c *Cipher) createKey(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := c.kms.WrapKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.keys.CreateKey(ctx, userID, wrapped)
+}
@@ -0,0 +1,82 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var _ KMS = (*LocalKMS)(nil)
+
+// KMS wraps and unwraps the data keys of the users with a master key it never hands out
+// A cloud key management service implements it to keep the master key out of the service
+type KMS interface {
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// LocalKMS wraps the data keys with AES-256-GCM under a master key held in the config,
+// for setups without a key management service
+type LocalKMS struct {
+	aead cipher.AEAD
+}
+
+// NewLocalKMS creates a LocalKMS from a base64 encoded 32 bytes master key, returning nil when the key is empty
+func NewLocalKMS(encodedKey string) (*LocalKMS, error) {
+	if encodedKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field encryption master key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field encryption master key: %w", err)
+	}
+
+	return &LocalKMS{aead: aead}, nil
+}
+
+// WrapKey encrypts the data key, prefixing it with its random nonce
+func (k *LocalKMS) WrapKey(_ context.Context, key []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate key nonce: %w", err)
+	}
+	return k.aead.Seal(nonce, nonce, key, nil), nil
+}
+
+// UnwrapKey decrypts a data key wrapped by WrapKey
+func (k *LocalKMS) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	nonceSize := k.aead.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, errors.New("wrapped key is too short")
+	}
+	key, err := k.aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key: %w", err)
+	}
+	return key, nil
+}
+
+// newAEAD creates the AES-256-GCM cipher of a 32 bytes key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("expected 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
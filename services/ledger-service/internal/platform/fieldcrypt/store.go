@@ -0,0 +1,61 @@
+package fieldcrypt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ KeyStore = (*PostgresKeyStore)(nil)
+
+// ErrKeyNotFound is returned when the user has no data key yet
+var ErrKeyNotFound = errors.New("data key not found")
+
+// KeyStore keeps the wrapped data key of each user
+type KeyStore interface {
+	// FindKey returns the wrapped data key of the user, ErrKeyNotFound when they have none
+	FindKey(ctx context.Context, userID uuid.UUID) ([]byte, error)
+	// CreateKey stores the wrapped data key of the user unless they already have one,
+	// returning the key kept so concurrent writers of the same user agree on it
+	CreateKey(ctx context.Context, userID uuid.UUID, wrapped []byte) ([]byte, error)
+}
+
+// PostgresKeyStore is a PostgreSQL implementation of the KeyStore interface
+// The keys are deleted with their user, which leaves whatever was encrypted with them unreadable
+type PostgresKeyStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresKeyStore creates a new PostgresKeyStore
+func NewPostgresKeyStore(pool *pgxpool.Pool) *PostgresKeyStore {
+	return &PostgresKeyStore{pool: pool}
+}
+
+// FindKey returns the wrapped data key of the user
+func (s *PostgresKeyStore) FindKey(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	var wrapped []byte
+	err := s.pool.QueryRow(ctx, `SELECT wrapped_key FROM user_data_keys WHERE user_id = $1`, userID).Scan(&wrapped)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch data key: %w", err)
+	}
+	return wrapped, nil
+}
+
+// CreateKey stores the wrapped data key of the user, keeping the one already stored on conflict
+func (s *PostgresKeyStore) CreateKey(ctx context.Context, userID uuid.UUID, wrapped []byte) ([]byte, error) {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO user_data_keys (user_id, wrapped_key) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data key: %w", err)
+	}
+	return s.FindKey(ctx, userID)
+}
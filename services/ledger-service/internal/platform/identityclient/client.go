@@ -0,0 +1,319 @@
+package identityclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	identityv1 "github.com/Guizzs26/fintrack/gen/go/identity/v1"
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/grpcx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrInvalidToken        = errx.New(errx.CategoryUnauthenticated, "UNAUTHENTICATED", "invalid or expired access token")
+	ErrUserNotFound        = errx.New(errx.CategoryNotFound, "USER_NOT_FOUND", "user not found in identity service")
+	ErrIdentityUnavailable = errx.New(errx.CategoryUnavailable, "SERVICE_UNAVAILABLE", "authentication is temporarily unavailable")
+	ErrEmailAlreadyInUse   = errx.New(errx.CategoryConflict, "EMAIL_ALREADY_IN_USE", "email is already in use")
+	ErrOutOfScope          = errx.New(errx.CategoryForbidden, "TOKEN_OUT_OF_SCOPE", "the access token does not grant this request")
+	ErrEmailNotVerified    = errx.New(errx.CategoryForbidden, "EMAIL_NOT_VERIFIED", "verify your email before changing your data")
+	ErrUserDeactivated     = errx.New(errx.CategoryForbidden, "USER_DEACTIVATED", "the user is deactivated while their account is being deleted")
+)
+
+// retryServiceConfig enables gRPC's built-in transparent retries for transient failures
+// The %d placeholder is replaced with the configured max attempts
+const retryServiceConfig = `{
+	"methodConfig": [{
+		"name": [{"service": "identity.v1.IdentityService"}],
+		"retryPolicy": {
+			"maxAttempts": %d,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2.0,
+			"retryableStatusCodes": ["UNAVAILABLE", "RESOURCE_EXHAUSTED"]
+		}
+	}]
+}`
+
+// User is the ledger's view of a user owned by the identity service
+type User struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+}
+
+// TokenInfo holds the result of a successful access token validation
+type TokenInfo struct {
+	UserID        uuid.UUID
+	ExpiresAt     time.Time
+	Scope         authctx.Scope // Scope is the zero Scope for the tokens of a login
+	EmailVerified bool
+	// ImpersonatedBy names the support member acting as the user, empty for the tokens of the user
+	ImpersonatedBy string
+}
+
+// Client wraps the identity-service gRPC client, managing the underlying connection
+// and applying per-call timeouts and retries
+type Client struct {
+	conn    *grpc.ClientConn
+	rpc     identityv1.IdentityServiceClient
+	timeout time.Duration
+	// serviceToken authenticates the ledger on the service-only methods
+	serviceToken string
+}
+
+// NewClient creates a new identity Client using the identity section of the config
+// The connection is established lazily by gRPC on the first call
+func NewClient(cfg config.Config) (*Client, error) {
+	maxAttempts := max(cfg.Identity.MaxRetries+1, 1)
+
+	conn, err := grpc.NewClient(cfg.Identity.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(retryServiceConfig, maxAttempts)),
+		grpc.WithUnaryInterceptor(grpcx.CorrelationUnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity grpc client: %w", err)
+	}
+
+	log.Printf("✅ Identity gRPC client configured for %s", cfg.Identity.Addr)
+	return &Client{
+		conn:         conn,
+		rpc:          identityv1.NewIdentityServiceClient(conn),
+		timeout:      cfg.Identity.Timeout,
+		serviceToken: cfg.Identity.ServiceToken,
+	}, nil
+}
+
+// Ping connects to the identity service, waiting until the connection is ready or ctx is done
+func (c *Client) Ping(ctx context.Context) error {
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("identity service at %s is not reachable (connection %s): %w", c.conn.Target(), strings.ToLower(state.String()), ctx.Err())
+		}
+	}
+}
+
+// Close tears down the underlying gRPC connection
+func (c *Client) Close() error {
+	if c.conn != nil {
+		log.Printf("Identity gRPC client connection closed\n")
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// ValidateToken asks the identity service to verify an access token
+func (c *Client) ValidateToken(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.rpc.ValidateToken(ctx, &identityv1.ValidateTokenRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	userID, err := uuid.Parse(resp.GetUserId())
+	if err != nil {
+		return nil, fmt.Errorf("identity service returned an invalid user id: %w", err)
+	}
+
+	scope := authctx.Scope{ReadOnly: resp.GetReadOnly()}
+	for _, raw := range resp.GetAccountIds() {
+		accountID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("identity service returned an invalid account id: %w", err)
+		}
+		scope.AccountIDs = append(scope.AccountIDs, accountID)
+	}
+
+	return &TokenInfo{
+		UserID:         userID,
+		ExpiresAt:      time.Unix(resp.GetExpiresAt(), 0),
+		Scope:          scope,
+		EmailVerified:  resp.GetEmailVerified(),
+		ImpersonatedBy: resp.GetImpersonatedBy(),
+	}, nil
+}
+
+// IssueImpersonationToken mints a token acting as the user for operator, a support member, recorded by the identity
+// service with the reason. A ttl of 0 picks the default of the identity service
+func (c *Client) IssueImpersonationToken(ctx context.Context, userID uuid.UUID, operator, reason string, ttl time.Duration) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(grpcx.WithServiceToken(ctx, c.serviceToken), c.timeout)
+	defer cancel()
+
+	resp, err := c.rpc.IssueImpersonationToken(ctx, &identityv1.IssueImpersonationTokenRequest{
+		UserId:     userID.String(),
+		Operator:   operator,
+		Reason:     reason,
+		TtlSeconds: int64(ttl / time.Second),
+	})
+	if err != nil {
+		return "", time.Time{}, mapError(err)
+	}
+	return resp.GetAccessToken(), time.Unix(resp.GetExpiresAt(), 0), nil
+}
+
+// GetUser looks up a user by its ID in the identity service
+func (c *Client) GetUser(ctx context.Context, userID uuid.UUID) (*User, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.rpc.GetUser(ctx, &identityv1.GetUserRequest{UserId: userID.String()})
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &User{
+		ID:    userID,
+		Name:  resp.GetName(),
+		Email: resp.GetEmail(),
+	}, nil
+}
+
+// maxUsersByIDs is the most users the identity service resolves in a single call
+const maxUsersByIDs = 500
+
+// GetUsersByIDs looks up many users at once in the identity service, the unknown ones being left out of the map
+// Only the public profile is returned, the emails of the users stay with the identity service
+func (c *Client) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]User, error) {
+	users := make(map[uuid.UUID]User, len(userIDs))
+	for i := 0; i < len(userIDs); i += maxUsersByIDs {
+		chunk := userIDs[i:min(i+maxUsersByIDs, len(userIDs))]
+		req := &identityv1.GetUsersByIDsRequest{UserIds: make([]string, 0, len(chunk))}
+		for _, userID := range chunk {
+			req.UserIds = append(req.UserIds, userID.String())
+		}
+
+		resp, err := c.getUsersByIDs(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range resp.GetUsers() {
+			userID, err := uuid.Parse(summary.GetUserId())
+			if err != nil {
+				return nil, fmt.Errorf("identity service returned an invalid user id: %w", err)
+			}
+			users[userID] = User{ID: userID, Name: summary.GetName()}
+		}
+	}
+	return users, nil
+}
+
+// getUsersByIDs makes a single GetUsersByIDs call under the timeout of the client
+func (c *Client) getUsersByIDs(ctx context.Context, req *identityv1.GetUsersByIDsRequest) (*identityv1.GetUsersByIDsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.rpc.GetUsersByIDs(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+// Register creates a new user in the identity service, returning its ID
+func (c *Client) Register(ctx context.Context, name, email, password string) (uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.rpc.Register(ctx, &identityv1.RegisterRequest{Name: name, Email: email, Password: password})
+	if err != nil {
+		return uuid.Nil, mapError(err)
+	}
+
+	userID, err := uuid.Parse(resp.GetUserId())
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("identity service returned an invalid user id: %w", err)
+	}
+	return userID, nil
+}
+
+// DeactivateUser blocks the logins of a user while their account is being deleted
+func (c *Client) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.rpc.DeactivateUser(ctx, &identityv1.DeactivateUserRequest{UserId: userID.String()}); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// ReactivateUser unblocks the logins of a deactivated user
+func (c *Client) ReactivateUser(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.rpc.ReactivateUser(ctx, &identityv1.ReactivateUserRequest{UserId: userID.String()}); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user from the identity service for good, deleting an unknown user succeeds
+func (c *Client) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.rpc.DeleteUser(ctx, &identityv1.DeleteUserRequest{UserId: userID.String()}); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// mapError translates gRPC status errors into errors the ledger API understands
+func mapError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("identity call failed: %w", err)
+	}
+
+	switch st.Code() {
+	case codes.Unauthenticated:
+		return ErrInvalidToken
+	case codes.NotFound:
+		return ErrUserNotFound
+	case codes.AlreadyExists:
+		// The conflicts of concurrent updates share the code, the reason of the ErrorInfo telling them apart
+		if reason := errorReason(st); reason == "" || reason == "EMAIL_ALREADY_IN_USE" {
+			return ErrEmailAlreadyInUse
+		}
+		return fmt.Errorf("identity call failed with code %s: %s", st.Code(), st.Message())
+	case codes.PermissionDenied:
+		if errorReason(st) == "USER_DEACTIVATED" {
+			return ErrUserDeactivated
+		}
+		return fmt.Errorf("identity call failed with code %s: %s", st.Code(), st.Message())
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", ErrIdentityUnavailable, st.Message())
+	default:
+		return fmt.Errorf("identity call failed with code %s: %s", st.Code(), st.Message())
+	}
+}
+
+// errorReason returns the reason of the ErrorInfo detail of a status, empty when it carries none
+func errorReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/consumer"
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Route identifies the events a handler processes
+type Route struct {
+	Type    string
+	Version int
+}
+
+// RouteOf returns the route of an event type (e.g., RouteOf(contracts.UserRegisteredV1{}))
+func RouteOf(event contracts.Event) Route {
+	return Route{Type: event.EventType(), Version: event.EventVersion()}
+}
+
+// Handler processes an event, its writes going through tx so they are committed along with the inbox record
+type Handler func(ctx context.Context, tx pgx.Tx, envelope contracts.Envelope) error
+
+// Inbox records the events each consumer processed, in the transaction of the handler,
+// so an event delivered again after a crash or a rebalance is processed exactly once
+type Inbox struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+	clock  clock.Clock
+}
+
+// New creates a new Inbox
+func New(pool *pgxpool.Pool, logger *slog.Logger, clock clock.Clock) *Inbox {
+	return &Inbox{
+		pool:   pool,
+		logger: logger.With(slog.String("component", "inbox")),
+		clock:  clock,
+	}
+}
+
+// Process runs the handler in a transaction that records the event for the consumer,
+// skipping the event when the consumer already processed it
+// The boolean result reports whether the handler ran
+func (i *Inbox) Process(ctx context.Context, consumerName string, envelope contracts.Envelope, handler Handler) (bool, error) {
+	processed := false
+	err := pgx.BeginTxFunc(ctx, i.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO inbox (consumer, event_id, event_type, event_version, processed_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (consumer, event_id) DO NOTHING
+		`, consumerName, envelope.ID, envelope.Type, envelope.Version, i.clock.Now())
+		if err != nil {
+			return fmt.Errorf("failed to record event in inbox: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+
+		if err := handler(ctx, tx, envelope); err != nil {
+			return err
+		}
+		processed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return processed, nil
+}
+
+// ConsumerHandler returns a consumer.Handler decoding the records as contracts envelopes and processing each
+// through the handler of its route
+// Malformed envelopes and payloads are permanent errors, dead-lettered without retrying, while events without
+// a handler are skipped, since the topics carry events other consumers read
+func (i *Inbox) ConsumerHandler(consumerName string, handlers map[Route]Handler) consumer.Handler {
+	return func(ctx context.Context, record consumer.Record) error {
+		envelope, err := contracts.Unmarshal(record.Value)
+		if err != nil {
+			return consumer.Permanent(err)
+		}
+
+		handler, ok := handlers[Route{Type: envelope.Type, Version: envelope.Version}]
+		if !ok {
+			return nil
+		}
+
+		processed, err := i.Process(ctx, consumerName, envelope, handler)
+		if err != nil {
+			if errors.Is(err, contracts.ErrInvalidPayload) {
+				return consumer.Permanent(err)
+			}
+			return err
+		}
+		if !processed {
+			i.logger.Debug("skipping event already processed",
+				slog.String("consumer", consumerName),
+				slog.String("event_id", envelope.ID.String()),
+				slog.String("event_type", envelope.Type),
+			)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// statusPathPrefix is the path of the job status endpoint, relative to the API root
+const statusPathPrefix = "/api/v1/jobs/"
+
+// Handler exposes the job status endpoint and plugs into the API as a module
+type Handler struct {
+	jobService *Service
+}
+
+// NewHandler creates a new instance of the jobs Handler
+func NewHandler(jobService *Service) *Handler {
+	return &Handler{jobService: jobService}
+}
+
+// Name returns the module identifier
+func (h *Handler) Name() string {
+	return "jobs"
+}
+
+// RegisterRoutes sets up the API routes for the jobs module
+func (h *Handler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	jobsGroup := apiRouteGroup.Group("/jobs")
+
+	jobsGroup.GET("/:id", h.findJobByIDHandler)
+}
+
+// findJobByIDHandler handles the HTTP request for polling the status of a job
+func (h *Handler) findJobByIDHandler(c echo.Context) error {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid job id format")
+	}
+
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+
+	job, err := h.jobService.FindByID(c.Request().Context(), user.UserID, jobID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, ToJobResponse(job))
+}
+
+// ToJobResponse maps the internal Job model to the public httpx.JobResponse DTO
+// Modules starting jobs use it to reply with httpx.SendAccepted
+func ToJobResponse(job *Job) httpx.JobResponse {
+	resp := httpx.JobResponse{
+		ID:          job.ID.String(),
+		Kind:        job.Kind,
+		Status:      string(job.Status),
+		Progress:    job.Progress,
+		StatusURL:   statusPathPrefix + job.ID.String(),
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		CompletedAt: job.CompletedAt,
+	}
+
+	if len(job.Result) > 0 {
+		resp.Result = json.RawMessage(job.Result)
+	}
+	if job.Status == StatusFailed {
+		apiErr := httpx.NewAPIError(job.ErrorCode, job.ErrorMessage, nil)
+		resp.Error = &apiErr
+	}
+
+	return resp
+}
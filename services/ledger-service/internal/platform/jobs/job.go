@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrJobNotFound = errx.New(errx.CategoryNotFound, "JOB_NOT_FOUND", "job not found")
+)
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+
+	// fallbackErrorCode is stored for failures that are not DomainErrors, whose details must not leak to clients
+	fallbackErrorCode    = "INTERNAL_SERVER_ERROR"
+	fallbackErrorMessage = "the job failed unexpectedly"
+
+	// interruptedErrorCode is stored for the jobs left unfinished by a stopped process, which the user must start again
+	interruptedErrorCode    = "JOB_INTERRUPTED"
+	interruptedErrorMessage = "the job was interrupted before finishing, start it again"
+
+	// interruptedAfter is how long an unfinished job goes without updates before FailInterrupted fails it
+	interruptedAfter = time.Hour
+)
+
+// Status represents the lifecycle stage of a job
+type Status string
+
+// Repository persists jobs so their status survives across requests and instances
+type Repository interface {
+	Save(ctx context.Context, job *Job) error
+	FindByID(ctx context.Context, jobID uuid.UUID) (*Job, error)
+	DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// FailUnfinishedBefore fails the pending and running jobs not updated since before, returning how many were failed
+	FailUnfinishedBefore(ctx context.Context, before time.Time, code, message string, now time.Time) (int64, error)
+}
+
+// ProgressFunc reports the completion percentage (0-100) of a running job
+type ProgressFunc func(percent int)
+
+// Func is the work performed by a job. It reports progress through the ProgressFunc and returns a
+// JSON-serializable result. Returning a DomainError exposes its code and message to the client
+type Func func(ctx context.Context, progress ProgressFunc) (any, error)
+
+// Job is a long-running operation executed in the background on behalf of a user
+type Job struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Kind         string
+	Status       Status
+	Progress     int
+	Result       json.RawMessage
+	ErrorCode    string
+	ErrorMessage string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// Service starts jobs in the background and tracks their status
+type Service struct {
+	repo      Repository
+	completed metrics.Counter
+	ids       idgen.Generator
+	clock     clock.Clock
+	wg        sync.WaitGroup
+}
+
+// NewService creates a new instance of the jobs Service
+func NewService(repo Repository, provider metrics.Provider, ids idgen.Generator, clock clock.Clock) *Service {
+	return &Service{
+		repo:      repo,
+		completed: provider.Counter("jobs_completed_total", "Background jobs (imports, exports...) completed, by kind and final status", "kind", "status"),
+		ids:       ids,
+		clock:     clock,
+	}
+}
+
+// Enqueue persists a pending job and runs fn in the background
+// The job keeps the values of ctx (logger, authenticated user...) but not its cancellation,
+// so it outlives the HTTP request that started it
+func (s *Service) Enqueue(ctx context.Context, userID uuid.UUID, kind string, fn Func) (*Job, error) {
+	now := s.clock.Now()
+	job := &Job{
+		ID:        s.ids.NewID(),
+		UserID:    userID,
+		Kind:      kind,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Save(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save pending job: %w", err)
+	}
+
+	snapshot := *job
+	jobCtx := context.WithoutCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(jobCtx, job, fn)
+	}()
+
+	return &snapshot, nil
+}
+
+// FindByID is the use case for finding a job by its id, only visible to the user who started it
+func (s *Service) FindByID(ctx context.Context, userID, jobID uuid.UUID) (*Job, error) {
+	job, err := s.repo.FindByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job by id: %w", err)
+	}
+
+	if job.UserID != userID {
+		return nil, ErrJobNotFound.With("job_id", jobID)
+	}
+
+	return job, nil
+}
+
+// PurgeCompleted deletes the jobs that reached a final status longer than retention ago
+// It is meant to be triggered periodically by the scheduler
+func (s *Service) PurgeCompleted(ctx context.Context, retention time.Duration) error {
+	deleted, err := s.repo.DeleteCompletedBefore(ctx, s.clock.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("failed to purge completed jobs: %w", err)
+	}
+
+	ctxlogger.GetLogger(ctx).Info("purged completed jobs", slog.Int64("deleted", deleted))
+	return nil
+}
+
+// FailInterrupted fails the jobs left pending or running by a process that stopped, whose work is lost since jobs
+// only run in the memory of the process that enqueued them. It is meant to be called on startup; only the jobs not
+// updated for interruptedAfter are failed, so the jobs still running on other instances are left alone
+func (s *Service) FailInterrupted(ctx context.Context) error {
+	now := s.clock.Now()
+	failed, err := s.repo.FailUnfinishedBefore(ctx, now.Add(-interruptedAfter), interruptedErrorCode, interruptedErrorMessage, now)
+	if err != nil {
+		return fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+
+	if failed > 0 {
+		ctxlogger.GetLogger(ctx).Warn("failed interrupted jobs", slog.Int64("failed", failed))
+	}
+	return nil
+}
+
+// Wait blocks until every running job finishes or ctx is done, used during graceful shutdown
+func (s *Service) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for running jobs: %w", ctx.Err())
+	}
+}
+
+// run executes fn, persisting every status and progress change of the job
+func (s *Service) run(ctx context.Context, job *Job, fn Func) {
+	log := ctxlogger.GetLogger(ctx).With(
+		slog.String("job_id", job.ID.String()),
+		slog.String("job_kind", job.Kind),
+	)
+
+	var mu sync.Mutex
+	save := func(mutate func()) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		mutate()
+		job.UpdatedAt = s.clock.Now()
+		if err := s.repo.Save(ctx, job); err != nil {
+			log.Error("failed to save job state", slog.String("error", err.Error()))
+		}
+	}
+
+	save(func() { job.Status = StatusRunning })
+	log.Info("job started")
+
+	progress := func(percent int) {
+		percent = min(max(percent, 0), 100)
+		save(func() { job.Progress = percent })
+	}
+
+	result, err := s.execute(ctx, fn, progress)
+
+	save(func() {
+		now := s.clock.Now()
+		job.CompletedAt = &now
+
+		if err != nil {
+			job.Status = StatusFailed
+			job.ErrorCode, job.ErrorMessage = fallbackErrorCode, fallbackErrorMessage
+			if domainErr, ok := errx.AsDomainError(err); ok {
+				job.ErrorCode, job.ErrorMessage = domainErr.Code, domainErr.Message
+			}
+			return
+		}
+
+		encoded, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			err = fmt.Errorf("failed to encode job result: %w", marshalErr)
+			job.Status = StatusFailed
+			job.ErrorCode, job.ErrorMessage = fallbackErrorCode, fallbackErrorMessage
+			return
+		}
+
+		job.Status = StatusSucceeded
+		job.Progress = 100
+		job.Result = encoded
+	})
+
+	s.completed.Inc(job.Kind, string(job.Status))
+
+	if err != nil {
+		log.Error("job failed", slog.String("error", err.Error()))
+		return
+	}
+	log.Info("job succeeded")
+}
+
+// execute runs fn, turning a panic into an error so a faulty job never crashes the API
+func (s *Service) execute(ctx context.Context, fn Func, progress ProgressFunc) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx, progress)
+}
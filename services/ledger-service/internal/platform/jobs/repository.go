@@ -0,0 +1,186 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresJobRepository)(nil)
+
+// PostgresJobRepository is a PostgreSQL implementation of the jobs Repository interface
+type PostgresJobRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresJobRepository creates a new PostgresJobRepository
+func NewPostgresJobRepository(pool *pgxpool.Pool) *PostgresJobRepository {
+	return &PostgresJobRepository{pool: pool}
+}
+
+// jobModel represents the job structure in the database
+type jobModel struct {
+	ID           uuid.UUID  `db:"id"`
+	UserID       uuid.UUID  `db:"user_id"`
+	Kind         string     `db:"kind"`
+	Status       string     `db:"status"`
+	Progress     int        `db:"progress"`
+	Result       []byte     `db:"result"`
+	ErrorCode    *string    `db:"error_code"`
+	ErrorMessage *string    `db:"error_message"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+	CompletedAt  *time.Time `db:"completed_at"`
+}
+
+// Save inserts a new job or updates the status, progress and outcome of an existing one
+func (r *PostgresJobRepository) Save(ctx context.Context, job *Job) error {
+	query := `
+		INSERT INTO jobs (
+			id,
+			user_id,
+			kind,
+			status,
+			progress,
+			result,
+			error_code,
+			error_message,
+			created_at,
+			updated_at,
+			completed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			status = EXCLUDED.status,
+			progress = EXCLUDED.progress,
+			result = EXCLUDED.result,
+			error_code = EXCLUDED.error_code,
+			error_message = EXCLUDED.error_message,
+			updated_at = EXCLUDED.updated_at,
+			completed_at = EXCLUDED.completed_at
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		job.ID,
+		job.UserID,
+		job.Kind,
+		string(job.Status),
+		job.Progress,
+		nullableBytes(job.Result),
+		nullableString(job.ErrorCode),
+		nullableString(job.ErrorMessage),
+		job.CreatedAt,
+		job.UpdatedAt,
+		job.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert job: %v", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a job by its ID
+func (r *PostgresJobRepository) FindByID(ctx context.Context, jobID uuid.UUID) (*Job, error) {
+	query := `
+		SELECT id, user_id, kind, status, progress, result, error_code, error_message,
+			created_at, updated_at, completed_at
+		FROM jobs
+		WHERE id = $1
+	`
+
+	var m jobModel
+	err := r.pool.QueryRow(ctx, query, jobID).Scan(
+		&m.ID,
+		&m.UserID,
+		&m.Kind,
+		&m.Status,
+		&m.Progress,
+		&m.Result,
+		&m.ErrorCode,
+		&m.ErrorMessage,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+		&m.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrJobNotFound.With("job_id", jobID)
+		}
+		return nil, fmt.Errorf("failed to fetch job by id: %w", err)
+	}
+
+	return toJobDomain(&m), nil
+}
+
+// DeleteCompletedBefore deletes the jobs completed before the given time, returning how many were deleted
+func (r *PostgresJobRepository) DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM jobs WHERE completed_at IS NOT NULL AND completed_at < $1`
+
+	tag, err := r.pool.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed jobs: %v", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// FailUnfinishedBefore fails the pending and running jobs not updated since before, returning how many were failed
+func (r *PostgresJobRepository) FailUnfinishedBefore(ctx context.Context, before time.Time, code, message string, now time.Time) (int64, error) {
+	query := `
+		UPDATE jobs
+		SET status = $1, error_code = $2, error_message = $3, updated_at = $4, completed_at = $4
+		WHERE status IN ($5, $6) AND updated_at < $7
+	`
+
+	tag, err := r.pool.Exec(ctx, query, string(StatusFailed), code, message, now, string(StatusPending), string(StatusRunning), before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail unfinished jobs: %v", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// toJobDomain maps a persistence jobModel to a domain Job
+func toJobDomain(m *jobModel) *Job {
+	job := &Job{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Kind:        m.Kind,
+		Status:      Status(m.Status),
+		Progress:    m.Progress,
+		Result:      m.Result,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		CompletedAt: m.CompletedAt,
+	}
+	if m.ErrorCode != nil {
+		job.ErrorCode = *m.ErrorCode
+	}
+	if m.ErrorMessage != nil {
+		job.ErrorMessage = *m.ErrorMessage
+	}
+	return job
+}
+
+// nullableString maps an empty string to a SQL NULL
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullableBytes maps an empty JSON document to a SQL NULL
+func nullableBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
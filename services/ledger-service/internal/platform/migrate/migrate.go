@@ -0,0 +1,198 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// versionTable is the table goose uses to track applied migrations, shared so both tools stay interchangeable
+const versionTable = "goose_db_version"
+
+// Migration is a single goose SQL migration file
+type Migration struct {
+	Version int64
+	Name    string
+	upSQL   string
+}
+
+// MigrationStatus reports whether a migration was applied and when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies goose-formatted SQL migrations (-- +goose Up / -- +goose Down)
+// Only the Up direction is supported: rollbacks stay a deliberate, manual operation
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New creates a Migrator reading the *.sql files found in dir of fsys
+func New(pool *pgxpool.Pool, fsys fs.FS, dir string) (*Migrator, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		m, err := parseMigration(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Status lists every migration with the time it was applied, if it was
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration in version order, each one in its own transaction
+// It returns the migrations that were applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		err := pgx.BeginTxFunc(ctx, m.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, mig.upSQL); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, "INSERT INTO "+versionTable+" (version_id, is_applied) VALUES ($1, true)", mig.Version)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to apply migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+
+	return done, nil
+}
+
+// applied returns the applied migration versions with the time they were applied
+// Like goose, the latest row of each version decides whether it is applied
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	createTable := `
+		CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+			id SERIAL PRIMARY KEY,
+			version_id BIGINT NOT NULL,
+			is_applied BOOLEAN NOT NULL,
+			tstamp TIMESTAMP DEFAULT NOW()
+		)
+	`
+	if _, err := m.pool.Exec(ctx, createTable); err != nil {
+		return nil, fmt.Errorf("failed to create migrations version table: %w", err)
+	}
+
+	query := `
+		SELECT DISTINCT ON (version_id) version_id, is_applied, tstamp
+		FROM ` + versionTable + `
+		ORDER BY version_id, id DESC
+	`
+	rows, err := m.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			at        time.Time
+		)
+		if err := rows.Scan(&version, &isApplied, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		if isApplied {
+			applied[version] = at
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// parseMigration reads a goose SQL file named <version>_<name>.sql, keeping only its Up section
+func parseMigration(fsys fs.FS, file string) (Migration, error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+	rawVersion, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return Migration{}, fmt.Errorf("invalid migration file name %q: expected <version>_<name>.sql", file)
+	}
+
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil {
+		return Migration{}, fmt.Errorf("invalid migration version in %q: %w", file, err)
+	}
+
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to read migration %q: %w", file, err)
+	}
+
+	var (
+		up    strings.Builder
+		inUp  bool
+		hasUp bool
+	)
+	for line := range strings.Lines(string(content)) {
+		switch directive := strings.TrimSpace(line); directive {
+		case "-- +goose Up":
+			inUp, hasUp = true, true
+			continue
+		case "-- +goose Down":
+			inUp = false
+			continue
+		case "-- +goose StatementBegin", "-- +goose StatementEnd":
+			continue
+		}
+		if inUp {
+			up.WriteString(line)
+		}
+	}
+	if !hasUp {
+		return Migration{}, fmt.Errorf("migration %q has no '-- +goose Up' section", file)
+	}
+
+	return Migration{Version: version, Name: name, upSQL: up.String()}, nil
+}
@@ -0,0 +1,92 @@
+package module
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/fieldcrypt"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/identityclient"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/jobs"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/saga"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/scheduler"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Deps holds the shared infrastructure built once by the composition root (cmd/api)
+// and handed to every module, so modules never create their own config, logging or db layers
+type Deps struct {
+	Config   *config.Config
+	Logger   *slog.Logger
+	Postgres *postgres.Postgres
+	Identity *identityclient.Client
+	Jobs     *jobs.Service        // Jobs runs long-running operations answered with 202 Accepted
+	Tasks    *scheduler.Scheduler // Tasks registers recurring work triggered on cron schedules
+	Sagas    *saga.Coordinator    // Sagas orchestrates the flows spanning services (e.g., deleting an account)
+	Notifier notify.Notifier      // Notifier delivers reminders, budget alerts and security events to users
+	Mailer   notify.Mailer        // Mailer emails users directly (e.g., the weekly summary)
+	Audit    *audit.Logger        // Audit records who changed what, apart from the operational logs
+	Metrics  metrics.Provider     // Metrics creates the business metrics exposed on /metrics
+	Periods  AccountingPeriods    // Periods resolves the financial month of each user for the monthly figures
+	Display  UserPreferences      // Display resolves how each user wants amounts and dates shown in reports and notifications
+	Fields   *fieldcrypt.Cipher   // Fields encrypts the sensitive fields of the users, nil when they are stored in plain text
+	Rounding money.RoundingPolicy // Rounding rounds the currency conversions and allocations to the minor unit
+	IDs      idgen.Generator      // IDs creates the IDs of the new accounts and transactions, time-ordered
+	Clock    clock.Clock
+}
+
+// AccountingPeriods resolves the accounting period (financial month) chosen by each user
+type AccountingPeriods interface {
+	AccountingPeriod(ctx context.Context, userID uuid.UUID) (clock.AccountingPeriod, error)
+}
+
+// CalendarPeriods resolves every user to the calendar month, used when the user settings are not available (e.g., demo mode)
+type CalendarPeriods struct{}
+
+// AccountingPeriod returns the calendar month
+func (CalendarPeriods) AccountingPeriod(context.Context, uuid.UUID) (clock.AccountingPeriod, error) {
+	return clock.AccountingPeriod{}, nil
+}
+
+// DisplayPreferences are how a user wants amounts and dates shown
+type DisplayPreferences struct {
+	BaseCurrency string       // BaseCurrency is the currency the reports lead with (e.g., "BRL")
+	Locale       string       // Locale formats the amounts (e.g., "pt-BR"), the pt-BR conventions applying when empty
+	DateLayout   string       // DateLayout is the Go layout of the dates (e.g., "02/01/2006")
+	WeekStart    time.Weekday // WeekStart is the day the weeks of the reports start on
+}
+
+// DefaultDisplayPreferences returns the preferences of a user who never changed them
+func DefaultDisplayPreferences() DisplayPreferences {
+	return DisplayPreferences{BaseCurrency: "BRL", DateLayout: "02/01/2006", WeekStart: time.Sunday}
+}
+
+// UserPreferences resolves the display preferences chosen by each user
+type UserPreferences interface {
+	DisplayPreferences(ctx context.Context, userID uuid.UUID) (DisplayPreferences, error)
+}
+
+// DefaultPreferences resolves every user to the default display preferences, used when the user preferences are not available (e.g., demo mode)
+type DefaultPreferences struct{}
+
+// DisplayPreferences returns the defaults
+func (DefaultPreferences) DisplayPreferences(context.Context, uuid.UUID) (DisplayPreferences, error) {
+	return DefaultDisplayPreferences(), nil
+}
+
+// Module is implemented by every feature module that plugs into the API
+type Module interface {
+	// Name returns a short, unique identifier for the module (e.g., "ledger")
+	Name() string
+	// RegisterRoutes mounts the module's HTTP routes under the authenticated API group
+	RegisterRoutes(apiRouteGroup *echo.Group)
+}
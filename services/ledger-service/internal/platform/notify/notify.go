@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CategoryReminder    Category = "reminder"     // Upcoming or overdue bills and transactions
+	CategoryBudgetAlert Category = "budget_alert" // Spending approaching or exceeding a budget
+	CategorySecurity    Category = "security"     // Sign-ins, password changes and other account events
+)
+
+// Category groups notifications by purpose, users choose the delivery channels of each category
+type Category string
+
+// Values returns every known category, used to validate enum fields
+func (Category) Values() []string {
+	return []string{string(CategoryReminder), string(CategoryBudgetAlert), string(CategorySecurity)}
+}
+
+// Message is a notification addressed to a single user, independent of how it is delivered
+type Message struct {
+	UserID   uuid.UUID
+	Category Category
+	Title    string
+	Body     string
+	Data     map[string]string // Data carries structured context for clients (e.g., "account_id")
+}
+
+// Notifier delivers messages to users through the channels they enabled for the message category
+// Modules depend on this interface instead of the notifications module itself
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Email is a message delivered by email only, for content longer than a notification (e.g., the weekly summary)
+type Email struct {
+	UserID      uuid.UUID
+	Subject     string
+	Body        string // Body is plain text
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with an email (e.g., a statement PDF)
+type Attachment struct {
+	Filename    string
+	ContentType string // ContentType is the MIME type of the file (e.g., "application/pdf")
+	Content     []byte
+}
+
+// Mailer emails users at the address they registered in the identity service
+// Unlike the Notifier, it ignores the channels chosen for the notification categories, the caller handles opting out
+type Mailer interface {
+	Mail(ctx context.Context, email Email) error
+}
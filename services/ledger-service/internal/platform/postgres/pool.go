@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ pgxpool.AcquireTracer = (*poolTracer)(nil)
+
+var (
+	ErrPoolExhausted = errx.New(errx.CategoryUnavailable, "DATABASE_BUSY", "no database connection became available in time, try again")
+)
+
+// defaultPoolStatsInterval is how often the pool stats are sampled when no interval is configured
+const defaultPoolStatsInterval = 15 * time.Second
+
+// poolTracer is the tracer of a pool: it reports the queries through the QueryTracer and bounds the wait for a connection
+type poolTracer struct {
+	*QueryTracer
+	maxAcquireWait time.Duration // maxAcquireWait bounds the wait for a connection, 0 waiting as long as the caller does
+}
+
+// TraceAcquireStart bounds the acquisition by the max acquire wait, the context returned being only used to acquire
+func (t *poolTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	if t.maxAcquireWait <= 0 {
+		return ctx
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, t.maxAcquireWait)
+	return &acquireContext{Context: waitCtx, parent: ctx, cancel: cancel}
+}
+
+// TraceAcquireEnd releases the timer of the max acquire wait
+func (t *poolTracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireEndData) {
+	if c, ok := ctx.(*acquireContext); ok {
+		c.cancel()
+	}
+}
+
+// acquireContext is the context a connection is acquired with, failing with ErrPoolExhausted once the max acquire wait
+// ran out while the caller still waits. pgxpool returns the error of that context, which carries the typed error up
+type acquireContext struct {
+	context.Context
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+func (c *acquireContext) Err() error {
+	err := c.Context.Err()
+	if err != nil && c.parent.Err() == nil {
+		return ErrPoolExhausted
+	}
+	return err
+}
+
+// warmUp opens the minimum connections of the pool before it serves, so the first requests do not pay for them
+// The connections are held together, otherwise the pool would hand the same idle connection out again
+func warmUp(ctx context.Context, pool *pgxpool.Pool, conns int32) error {
+	acquired := make([]*pgxpool.Conn, 0, conns)
+	defer func() {
+		for _, conn := range acquired {
+			conn.Release()
+		}
+	}()
+
+	for range conns {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to warm up postgres connection pool: %w", err)
+		}
+		acquired = append(acquired, conn)
+	}
+	return nil
+}
+
+// poolMetrics exposes the stats of the pools, the cumulative ones as counters fed with the growth since the last sample
+type poolMetrics struct {
+	acquired    metrics.Gauge
+	idle        metrics.Gauge
+	total       metrics.Gauge
+	max         metrics.Gauge
+	acquires    metrics.Counter
+	waits       metrics.Counter
+	waitSeconds metrics.Counter
+	canceled    metrics.Counter
+
+	last map[string]*pgxpool.Stat // last holds the previous sample of each pool
+}
+
+// newPoolMetrics registers the pool metrics in the provider
+func newPoolMetrics(provider metrics.Provider) *poolMetrics {
+	return &poolMetrics{
+		acquired:    provider.Gauge("ledger_db_pool_acquired_conns", "Connections currently in use, by pool", "pool"),
+		idle:        provider.Gauge("ledger_db_pool_idle_conns", "Connections currently idle, by pool", "pool"),
+		total:       provider.Gauge("ledger_db_pool_total_conns", "Connections open, by pool", "pool"),
+		max:         provider.Gauge("ledger_db_pool_max_conns", "Connections the pool may open at most, by pool", "pool"),
+		acquires:    provider.Counter("ledger_db_pool_acquires_total", "Connections acquired, by pool", "pool"),
+		waits:       provider.Counter("ledger_db_pool_empty_acquires_total", "Acquisitions that waited for a connection to be released or opened, by pool", "pool"),
+		waitSeconds: provider.Counter("ledger_db_pool_acquire_wait_seconds_total", "Time spent waiting for a connection, by pool", "pool"),
+		canceled:    provider.Counter("ledger_db_pool_canceled_acquires_total", "Acquisitions given up, the max acquire wait included, by pool", "pool"),
+		last:        make(map[string]*pgxpool.Stat),
+	}
+}
+
+// observe samples the stats of the pool
+func (m *poolMetrics) observe(name string, pool *pgxpool.Pool) {
+	stat := pool.Stat()
+	m.acquired.Set(float64(stat.AcquiredConns()), name)
+	m.idle.Set(float64(stat.IdleConns()), name)
+	m.total.Set(float64(stat.TotalConns()), name)
+	m.max.Set(float64(stat.MaxConns()), name)
+
+	var (
+		acquires, waits, canceled int64
+		waitTime                  time.Duration
+	)
+	if last, ok := m.last[name]; ok {
+		acquires, waits, canceled, waitTime = last.AcquireCount(), last.EmptyAcquireCount(), last.CanceledAcquireCount(), last.EmptyAcquireWaitTime()
+	}
+	m.acquires.Add(float64(stat.AcquireCount()-acquires), name)
+	m.waits.Add(float64(stat.EmptyAcquireCount()-waits), name)
+	m.canceled.Add(float64(stat.CanceledAcquireCount()-canceled), name)
+	m.waitSeconds.Add((stat.EmptyAcquireWaitTime() - waitTime).Seconds(), name)
+	m.last[name] = stat
+}
+
+// MonitorPools samples the stats of the pools into the metrics of the provider every interval until ctx is done
+func (p *Postgres) MonitorPools(ctx context.Context, provider metrics.Provider, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPoolStatsInterval
+	}
+	m := newPoolMetrics(provider)
+	sample := func() {
+		m.observe("primary", p.Pool)
+		if p.Replica != nil {
+			m.observe("replica", p.Replica)
+		}
+	}
+
+	sample()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sample()
+		}
+	}
+}
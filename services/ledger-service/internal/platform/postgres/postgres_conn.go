@@ -4,26 +4,67 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync/atomic"
+	"time"
 
 	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var _ ReadRouter = (*Postgres)(nil)
+
+// ReadRouter hands out the pool of the pure reads (listings, reports, exports), which tolerate replication lag
+type ReadRouter interface {
+	ReadPool() *pgxpool.Pool
+}
+
 type Postgres struct {
-	Pool *pgxpool.Pool
+	Pool    *pgxpool.Pool // Pool connects to the primary, serving the writes and the reads that must see them
+	Replica *pgxpool.Pool // Replica connects to the read replica, nil when none is configured
+
+	maxLag        time.Duration
+	checkInterval time.Duration
+	replicaReady  atomic.Bool // replicaReady reports whether the replica lagged less than maxLag at the last check
 }
 
-func NewPostgresConnection(ctx context.Context, cfg config.Config) (*Postgres, error) {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
+// NewPostgresConnection opens the connection pool, reporting queries to spans when it is not nil
+// A pool to the read replica is opened as well when its host is configured
+func NewPostgresConnection(ctx context.Context, cfg config.Config, spans SpanEventRecorder) (*Postgres, error) {
+	traceMode, err := ParseTraceMode(cfg.Postgres.TraceQueries)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := newPool(ctx, cfg, cfg.Database.Host, cfg.Database.Port, NewQueryTracer(traceMode, spans))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Postgres connection pool established successfully")
+
+	pg := &Postgres{
+		Pool:          pool,
+		maxLag:        cfg.DatabaseReplica.MaxLag,
+		checkInterval: cfg.DatabaseReplica.LagCheckInterval,
+	}
+	if cfg.DatabaseReplica.Host == "" {
+		return pg, nil
+	}
+
+	replica, err := newPool(ctx, cfg, cfg.DatabaseReplica.Host, cfg.DatabaseReplica.Port, NewQueryTracer(traceMode, spans))
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to the read replica: %w", err)
+	}
+	pg.Replica = replica
+	pg.checkReplica(ctx)
+	log.Printf("✅ Postgres read replica connection pool established successfully")
+
+	return pg, nil
+}
 
-	parsedCfg, err := pgxpool.ParseConfig(dsn)
+// newPool opens and pings a connection pool to the server at host and port
+func newPool(ctx context.Context, cfg config.Config, host string, port int, tracer *QueryTracer) (*pgxpool.Pool, error) {
+	parsedCfg, err := pgxpool.ParseConfig(dsn(cfg, host, port))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pgx config: %w", err)
 	}
@@ -34,6 +75,7 @@ func NewPostgresConnection(ctx context.Context, cfg config.Config) (*Postgres, e
 	parsedCfg.MaxConnIdleTime = cfg.Postgres.MaxConnIdleTime
 	parsedCfg.HealthCheckPeriod = cfg.Postgres.HealthCheckPeriod
 	parsedCfg.ConnConfig.ConnectTimeout = cfg.Postgres.ConnectTimeout
+	parsedCfg.ConnConfig.Tracer = &poolTracer{QueryTracer: tracer, maxAcquireWait: cfg.Postgres.MaxAcquireWait}
 
 	pool, err := pgxpool.NewWithConfig(ctx, parsedCfg)
 	if err != nil {
@@ -44,12 +86,54 @@ func NewPostgresConnection(ctx context.Context, cfg config.Config) (*Postgres, e
 		pool.Close()
 		return nil, fmt.Errorf("failed to ping postgres: %w", err)
 	}
+	if cfg.Postgres.WarmUp {
+		if err := warmUp(ctx, pool, min(cfg.Postgres.MinConns, cfg.Postgres.MaxConns)); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	return pool, nil
+}
 
-	log.Printf("✅ Postgres connection pool established successfully")
-	return &Postgres{Pool: pool}, nil
+// Probe opens a pool of a single connection to the server at host and port and pings it, for the startup checks run
+// before the pools are opened and warmed up
+func Probe(ctx context.Context, cfg config.Config, host string, port int) (*pgxpool.Pool, error) {
+	parsedCfg, err := pgxpool.ParseConfig(dsn(cfg, host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pgx config: %w", err)
+	}
+	parsedCfg.MaxConns = 1
+	parsedCfg.MinConns = 0
+	parsedCfg.ConnConfig.ConnectTimeout = cfg.Postgres.ConnectTimeout
+
+	pool, err := pgxpool.NewWithConfig(ctx, parsedCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres connection pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping postgres at %s:%d: %w", host, port, err)
+	}
+	return pool, nil
+}
+
+// dsn returns the connection string of the server at host and port, with the credentials and database of cfg
+func dsn(cfg config.Config, host string, port int) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		cfg.Database.User,
+		cfg.Database.Password,
+		host,
+		port,
+		cfg.Database.Name,
+		cfg.Database.SSLMode,
+	)
 }
 
 func (p *Postgres) Close() {
+	if p.Replica != nil {
+		p.Replica.Close()
+		log.Printf("Postgres read replica connection pool closed\n")
+	}
 	if p.Pool != nil {
 		p.Pool.Close()
 		log.Printf("Postgres connection pool closed\n")
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultLagCheckInterval is how often the lag is measured when no interval is configured
+const defaultLagCheckInterval = 5 * time.Second
+
+// replicaLagQuery measures how far behind the primary the replica replays, in seconds
+// A replica that replayed everything it received is not lagging, however long ago the primary last wrote
+const replicaLagQuery = `
+	-- name: replicaLag
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8
+`
+
+// ReadPool returns the pool of the pure reads: the replica while it lags less than the tolerance, the primary otherwise
+// Reads preceding a write (e.g., loading an aggregate to change it) must use Pool, so they never see stale data
+func (p *Postgres) ReadPool() *pgxpool.Pool {
+	if p.Replica != nil && p.replicaReady.Load() {
+		return p.Replica
+	}
+	return p.Pool
+}
+
+// MonitorReplica measures the lag of the replica every check interval until ctx is done,
+// routing the reads back to the primary while the replica lags too far behind or can not be reached
+func (p *Postgres) MonitorReplica(ctx context.Context) {
+	if p.Replica == nil {
+		return
+	}
+
+	interval := p.checkInterval
+	if interval <= 0 {
+		interval = defaultLagCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkReplica(ctx)
+		}
+	}
+}
+
+// checkReplica measures the lag of the replica once, logging when the reads move between the replica and the primary
+func (p *Postgres) checkReplica(ctx context.Context) {
+	lag, err := p.replicaLag(ctx)
+	ready := err == nil && lag <= p.maxLag
+
+	if was := p.replicaReady.Swap(ready); was == ready {
+		return
+	}
+	logger := ctxlogger.GetLogger(ctx)
+	switch {
+	case err != nil:
+		logger.Warn("read replica unavailable, reads sent to the primary", slog.String("error", err.Error()))
+	case !ready:
+		logger.Warn("read replica lagging, reads sent to the primary", slog.Duration("lag", lag), slog.Duration("max_lag", p.maxLag))
+	default:
+		logger.Info("read replica caught up, reads sent to the replica", slog.Duration("lag", lag))
+	}
+}
+
+// replicaLag measures how far behind the primary the replica is
+func (p *Postgres) replicaLag(ctx context.Context) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, max(p.checkInterval, time.Second))
+	defer cancel()
+
+	var seconds float64
+	if err := p.Replica.QueryRow(ctx, replicaLagQuery).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to measure replica lag: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ pgx.QueryTracer = (*QueryTracer)(nil)
+
+// TraceMode selects which queries the QueryTracer reports
+type TraceMode string
+
+const (
+	TraceOff    TraceMode = "off"    // TraceOff reports nothing
+	TraceErrors TraceMode = "errors" // TraceErrors reports the failed queries only
+	TraceAll    TraceMode = "all"    // TraceAll reports every query, the successful ones at debug level
+)
+
+// queryTagPrefix marks the comment naming a query (e.g., "-- name: getAccountByID"),
+// statements without it are tagged by their first characters
+const (
+	queryTagPrefix    = "-- name:"
+	untaggedQuerySize = 60
+)
+
+// SpanEventRecorder adds an event to the span active in ctx
+// It keeps the tracer free of a tracing SDK, an OpenTelemetry adapter implements it with trace.SpanFromContext(ctx).AddEvent
+type SpanEventRecorder interface {
+	RecordSpanEvent(ctx context.Context, name string, attrs ...slog.Attr)
+}
+
+// QueryTracer is a pgx.QueryTracer reporting each query's duration, args count and error
+// to the contextual slog logger and, when a recorder is set, as span events
+type QueryTracer struct {
+	mode  TraceMode
+	spans SpanEventRecorder
+}
+
+// NewQueryTracer creates a new QueryTracer, spans may be nil
+func NewQueryTracer(mode TraceMode, spans SpanEventRecorder) *QueryTracer {
+	return &QueryTracer{mode: mode, spans: spans}
+}
+
+// ParseTraceMode parses a trace mode from its name (off, errors or all)
+func ParseTraceMode(name string) (TraceMode, error) {
+	switch mode := TraceMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case TraceOff, TraceErrors, TraceAll:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown query trace mode %q", name)
+	}
+}
+
+type traceStartKey struct{}
+
+// traceStart is what TraceQueryStart keeps in the context for TraceQueryEnd
+type traceStart struct {
+	sql       string
+	argsCount int
+	at        time.Time
+}
+
+// TraceQueryStart is called by pgx before a query is sent
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if t.mode == TraceOff {
+		return ctx
+	}
+	return context.WithValue(ctx, traceStartKey{}, traceStart{
+		sql:       data.SQL,
+		argsCount: len(data.Args),
+		at:        time.Now(),
+	})
+}
+
+// TraceQueryEnd is called by pgx once the query finished
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(traceStartKey{}).(traceStart)
+	if !ok || (data.Err == nil && t.mode != TraceAll) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", QueryTag(start.sql)),
+		slog.Int64("duration_ms", time.Since(start.at).Milliseconds()),
+		slog.Int("args_count", start.argsCount),
+		slog.Int64("rows", data.CommandTag.RowsAffected()),
+	}
+
+	level, message := slog.LevelDebug, "query executed"
+	if data.Err != nil {
+		level, message = slog.LevelWarn, "query failed"
+		attrs = append(attrs, slog.String("error", data.Err.Error()))
+	}
+
+	ctxlogger.GetLogger(ctx).LogAttrs(ctx, level, message, attrs...)
+	if t.spans != nil {
+		t.spans.RecordSpanEvent(ctx, message, attrs...)
+	}
+}
+
+// QueryTag returns the name given to the statement by its leading "-- name:" comment,
+// or its first characters with the whitespace collapsed
+func QueryTag(sql string) string {
+	trimmed := strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(trimmed, queryTagPrefix); ok {
+		name, _, _ := strings.Cut(rest, "\n")
+		return strings.TrimSpace(name)
+	}
+
+	collapsed := strings.Join(strings.Fields(trimmed), " ")
+	if len(collapsed) > untaggedQuerySize {
+		collapsed = collapsed[:untaggedQuerySize]
+	}
+	return collapsed
+}
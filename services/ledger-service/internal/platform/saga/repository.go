@@ -0,0 +1,181 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// activeSagaUniqueIndex keeps a single unfinished saga per flow and correlation ID
+const activeSagaUniqueIndex = "uq_sagas_name_correlation_id_active"
+
+// PostgresRepository is a PostgreSQL implementation of the saga Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// sagaColumns lists the columns read into a Saga, in the order scanSaga expects
+const sagaColumns = `
+	id, name, correlation_id, status, step, attempts, data, COALESCE(last_error, ''),
+	next_attempt_at, created_at, updated_at, completed_at
+`
+
+// Create inserts a new saga, failing when the flow is already running for the correlation ID
+func (r *PostgresRepository) Create(ctx context.Context, saga *Saga) error {
+	query := `
+		INSERT INTO sagas (
+			id, name, correlation_id, status, step, attempts, data, last_error,
+			next_attempt_at, created_at, updated_at, completed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		saga.ID, saga.Name, saga.CorrelationID, string(saga.Status), saga.Step, saga.Attempts, []byte(saga.Data), saga.LastError,
+		saga.NextAttemptAt, saga.CreatedAt, saga.UpdatedAt, saga.CompletedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == activeSagaUniqueIndex {
+			return ErrSagaAlreadyRunning.With("correlation_id", saga.CorrelationID)
+		}
+		return fmt.Errorf("failed to insert saga: %w", err)
+	}
+	return nil
+}
+
+// Save updates the state of a saga and releases its lease
+func (r *PostgresRepository) Save(ctx context.Context, saga *Saga) error {
+	query := `
+		UPDATE sagas
+		SET status = $2,
+			step = $3,
+			attempts = $4,
+			last_error = NULLIF($5, ''),
+			next_attempt_at = $6,
+			updated_at = $7,
+			completed_at = $8,
+			locked_until = NULL
+		WHERE id = $1
+	`
+	tag, err := r.pool.Exec(ctx, query,
+		saga.ID, string(saga.Status), saga.Step, saga.Attempts, saga.LastError, saga.NextAttemptAt, saga.UpdatedAt, saga.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update saga: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSagaNotFound.With("saga_id", saga.ID)
+	}
+	return nil
+}
+
+// FindByID retrieves a saga by its ID
+func (r *PostgresRepository) FindByID(ctx context.Context, sagaID uuid.UUID) (*Saga, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas WHERE id = $1`
+
+	saga, err := scanSaga(r.pool.QueryRow(ctx, query, sagaID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSagaNotFound.With("saga_id", sagaID)
+		}
+		return nil, fmt.Errorf("failed to find saga by id: %w", err)
+	}
+	return saga, nil
+}
+
+// FindLatest retrieves the most recent saga of the flow for the correlation ID
+func (r *PostgresRepository) FindLatest(ctx context.Context, name string, correlationID uuid.UUID) (*Saga, error) {
+	query := `
+		SELECT ` + sagaColumns + `
+		FROM sagas
+		WHERE name = $1 AND correlation_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	saga, err := scanSaga(r.pool.QueryRow(ctx, query, name, correlationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSagaNotFound.With("correlation_id", correlationID)
+		}
+		return nil, fmt.Errorf("failed to find latest saga: %w", err)
+	}
+	return saga, nil
+}
+
+// Lease locks an unfinished saga until the given time, unless another instance holds an unexpired lease
+func (r *PostgresRepository) Lease(ctx context.Context, sagaID uuid.UUID, now, until time.Time) (bool, error) {
+	query := `
+		UPDATE sagas
+		SET locked_until = $3
+		WHERE id = $1
+			AND status IN ('running', 'compensating')
+			AND (locked_until IS NULL OR locked_until < $2)
+	`
+	tag, err := r.pool.Exec(ctx, query, sagaID, now, until)
+	if err != nil {
+		return false, fmt.Errorf("failed to lease saga: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Release clears the lease of a saga
+func (r *PostgresRepository) Release(ctx context.Context, sagaID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE sagas SET locked_until = NULL WHERE id = $1`, sagaID); err != nil {
+		return fmt.Errorf("failed to release saga: %w", err)
+	}
+	return nil
+}
+
+// FindDue lists the unfinished sagas whose next attempt is due and which no instance holds, oldest first
+func (r *PostgresRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM sagas
+		WHERE status IN ('running', 'compensating')
+			AND next_attempt_at <= $1
+			AND (locked_until IS NULL OR locked_until < $1)
+		ORDER BY next_attempt_at
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due sagas: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan due sagas: %w", err)
+	}
+	return ids, nil
+}
+
+// scanSaga reads a row selected with sagaColumns
+func scanSaga(row pgx.Row) (*Saga, error) {
+	var saga Saga
+	var status string
+	var data []byte
+	err := row.Scan(
+		&saga.ID, &saga.Name, &saga.CorrelationID, &status, &saga.Step, &saga.Attempts, &data, &saga.LastError,
+		&saga.NextAttemptAt, &saga.CreatedAt, &saga.UpdatedAt, &saga.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	saga.Status = Status(status)
+	saga.Data = data
+	return &saga, nil
+}
@@ -0,0 +1,450 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSagaNotFound       = errx.New(errx.CategoryNotFound, "SAGA_NOT_FOUND", "saga not found")
+	ErrSagaAlreadyRunning = errx.New(errx.CategoryConflict, "SAGA_ALREADY_RUNNING", "the same flow is already in progress")
+)
+
+const (
+	StatusRunning      Status = "running"      // The steps are being executed
+	StatusCompensating Status = "compensating" // A step failed or the saga timed out, the completed steps are being undone
+	StatusCompleted    Status = "completed"    // Every step succeeded
+	StatusCompensated  Status = "compensated"  // Every completed step was undone
+	StatusFailed       Status = "failed"       // A compensation kept failing, the saga needs a manual intervention
+
+	// defaultMaxAttempts is how many times a step is tried before the saga compensates
+	defaultMaxAttempts = 3
+	// maxCompensationAttempts is how many times a compensation is tried before the saga is marked failed
+	maxCompensationAttempts = 10
+	// defaultStepTimeout bounds an attempt of a step without its own timeout
+	defaultStepTimeout = time.Minute
+	// leaseMargin is added to the step timeout to lease a saga, so an instance crashing mid-step frees it
+	leaseMargin = 30 * time.Second
+	// resumeBatchSize is how many due sagas a Resume advances
+	resumeBatchSize = 100
+)
+
+// Status represents the lifecycle stage of a saga
+type Status string
+
+// StepFunc performs (or undoes) a step, it must be idempotent since a step interrupted by a crash is run again
+type StepFunc func(ctx context.Context, saga *Saga) error
+
+// Step is a unit of work of a flow, with what undoes it
+type Step struct {
+	Name       string        // Name identifies the step in logs and in the saga state (e.g., "purge_ledger_data")
+	Action     StepFunc      // Action performs the step
+	Compensate StepFunc      // Compensate undoes the step, nil when it can not be undone
+	Timeout    time.Duration // Timeout bounds each attempt, one minute by default
+	// MaxAttempts is how many times the step is tried before the saga compensates, 3 by default
+	// Once a step without compensation completed, the following steps are retried until they succeed
+	MaxAttempts int
+}
+
+// Definition describes a multi-step flow, steps running in order and compensated in reverse order
+// A step without compensation is the point of no return: once it completed, the saga can only move forward
+type Definition struct {
+	Name       string // Name identifies the flow (e.g., "account_deletion")
+	Steps      []Step
+	Timeout    time.Duration // Timeout compensates the saga when the point of no return is not reached in time, 0 disables it
+	RetryDelay time.Duration // RetryDelay is the wait before retrying a failed step, doubling on every attempt, 30s by default
+}
+
+// Saga is a running or finished instance of a flow
+type Saga struct {
+	ID            uuid.UUID
+	Name          string
+	CorrelationID uuid.UUID // CorrelationID is the resource the flow is about (e.g., the user being deleted)
+	Status        Status
+	Step          int // Step is the index of the step being executed or compensated
+	Attempts      int // Attempts counts the failed attempts of the current step
+	Data          json.RawMessage
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+}
+
+// Decode decodes the data the saga was started with
+func (s *Saga) Decode(v any) error {
+	if err := json.Unmarshal(s.Data, v); err != nil {
+		return fmt.Errorf("failed to decode data of saga %s: %w", s.ID, err)
+	}
+	return nil
+}
+
+// Finished reports whether the saga reached a final status
+func (s *Saga) Finished() bool {
+	return s.Status == StatusCompleted || s.Status == StatusCompensated || s.Status == StatusFailed
+}
+
+// Repository persists sagas so a flow survives restarts and is advanced by a single instance at a time
+type Repository interface {
+	Create(ctx context.Context, saga *Saga) error
+	Save(ctx context.Context, saga *Saga) error // Save persists the state of a saga and releases its lease
+	FindByID(ctx context.Context, sagaID uuid.UUID) (*Saga, error)
+	FindLatest(ctx context.Context, name string, correlationID uuid.UUID) (*Saga, error)
+	// Lease locks an unfinished saga until the given time, returning false when another instance holds it
+	Lease(ctx context.Context, sagaID uuid.UUID, now, until time.Time) (bool, error)
+	Release(ctx context.Context, sagaID uuid.UUID) error
+	FindDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+}
+
+// Coordinator runs the steps of the registered flows, persisting the state of every saga after each step
+// Sagas left behind by a failure or a restart are advanced again by Resume, triggered by the scheduler
+type Coordinator struct {
+	repo        Repository
+	definitions map[string]*Definition
+	logger      *slog.Logger
+	clock       clock.Clock
+	wg          sync.WaitGroup
+}
+
+// NewCoordinator creates a new saga Coordinator
+func NewCoordinator(repo Repository, logger *slog.Logger, clock clock.Clock) *Coordinator {
+	return &Coordinator{
+		repo:        repo,
+		definitions: make(map[string]*Definition),
+		logger:      logger.With(slog.String("component", "saga")),
+		clock:       clock,
+	}
+}
+
+// Register adds a flow to the coordinator, flows must be registered before sagas are started or resumed
+func (c *Coordinator) Register(def Definition) error {
+	if def.Name == "" || len(def.Steps) == 0 {
+		return errors.New("a saga definition needs a name and at least one step")
+	}
+	if _, ok := c.definitions[def.Name]; ok {
+		return fmt.Errorf("saga %s is already registered", def.Name)
+	}
+	if def.RetryDelay <= 0 {
+		def.RetryDelay = 30 * time.Second
+	}
+	for i := range def.Steps {
+		if def.Steps[i].Action == nil {
+			return fmt.Errorf("step %s of saga %s has no action", def.Steps[i].Name, def.Name)
+		}
+		if def.Steps[i].Timeout <= 0 {
+			def.Steps[i].Timeout = defaultStepTimeout
+		}
+		if def.Steps[i].MaxAttempts <= 0 {
+			def.Steps[i].MaxAttempts = defaultMaxAttempts
+		}
+	}
+
+	c.definitions[def.Name] = &def
+	return nil
+}
+
+// Start persists a new saga of the flow and runs it in the background, one saga per flow and correlation ID at a time
+// The saga keeps the values of ctx (logger, request ID...) but not its cancellation, so it outlives the request
+func (c *Coordinator) Start(ctx context.Context, name string, correlationID uuid.UUID, data any) (*Saga, error) {
+	if _, ok := c.definitions[name]; !ok {
+		return nil, fmt.Errorf("saga %s is not registered", name)
+	}
+
+	latest, err := c.repo.FindLatest(ctx, name, correlationID)
+	if err != nil && !errors.Is(err, ErrSagaNotFound) {
+		return nil, fmt.Errorf("failed to find previous saga: %w", err)
+	}
+	if latest != nil && !latest.Finished() {
+		return nil, ErrSagaAlreadyRunning.With("saga_id", latest.ID)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode saga data: %w", err)
+	}
+
+	now := c.clock.Now()
+	saga := &Saga{
+		ID:            uuid.New(),
+		Name:          name,
+		CorrelationID: correlationID,
+		Status:        StatusRunning,
+		Data:          encoded,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := c.repo.Create(ctx, saga); err != nil {
+		return nil, fmt.Errorf("failed to create saga: %w", err)
+	}
+
+	snapshot := *saga
+	sagaCtx := context.WithoutCancel(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.advance(sagaCtx, saga.ID)
+	}()
+
+	return &snapshot, nil
+}
+
+// FindByID returns a saga by its ID
+func (c *Coordinator) FindByID(ctx context.Context, sagaID uuid.UUID) (*Saga, error) {
+	return c.repo.FindByID(ctx, sagaID)
+}
+
+// FindLatest returns the latest saga of the flow for the correlation ID
+func (c *Coordinator) FindLatest(ctx context.Context, name string, correlationID uuid.UUID) (*Saga, error) {
+	return c.repo.FindLatest(ctx, name, correlationID)
+}
+
+// Resume advances the unfinished sagas whose next attempt is due, those waiting to retry a step
+// and those interrupted by a restart once their lease expired
+// It is meant to be triggered periodically by the scheduler
+func (c *Coordinator) Resume(ctx context.Context) error {
+	ids, err := c.repo.FindDue(ctx, c.clock.Now(), resumeBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find due sagas: %w", err)
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.advance(ctx, id)
+	}
+
+	if len(ids) > 0 {
+		ctxlogger.GetLogger(ctx).Info("resumed sagas", slog.Int("sagas", len(ids)))
+	}
+	return nil
+}
+
+// Wait blocks until every saga started by this instance stops advancing or ctx is done, used during graceful shutdown
+func (c *Coordinator) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for running sagas: %w", ctx.Err())
+	}
+}
+
+// advance leases the saga and runs its steps (or compensations) until it finishes or a step has to be retried later
+func (c *Coordinator) advance(ctx context.Context, sagaID uuid.UUID) {
+	log := c.logger.With(slog.String("saga_id", sagaID.String()))
+
+	saga, err := c.repo.FindByID(ctx, sagaID)
+	if err != nil {
+		log.Error("failed to load saga", slog.String("error", err.Error()))
+		return
+	}
+	def, ok := c.definitions[saga.Name]
+	if !ok {
+		log.Error("saga is not registered", slog.String("saga", saga.Name))
+		return
+	}
+	log = log.With(slog.String("saga", saga.Name))
+
+	for !saga.Finished() {
+		now := c.clock.Now()
+		if saga.NextAttemptAt.After(now) {
+			return
+		}
+
+		step := def.Steps[max(0, min(saga.Step, len(def.Steps)-1))]
+		leased, err := c.repo.Lease(ctx, saga.ID, now, now.Add(step.Timeout+leaseMargin))
+		if err != nil {
+			log.Error("failed to lease saga", slog.String("error", err.Error()))
+			return
+		}
+		if !leased {
+			log.Debug("saga is leased by another instance")
+			return
+		}
+
+		// Reload after leasing, another instance may have advanced the saga meanwhile
+		if saga, err = c.repo.FindByID(ctx, sagaID); err != nil {
+			log.Error("failed to reload saga", slog.String("error", err.Error()))
+			c.release(ctx, log, sagaID)
+			return
+		}
+		if saga.Finished() {
+			c.release(ctx, log, sagaID)
+			return
+		}
+
+		switch saga.Status {
+		case StatusRunning:
+			c.runStep(ctx, log, def, saga)
+		case StatusCompensating:
+			c.compensateStep(ctx, log, def, saga)
+		}
+
+		saga.UpdatedAt = c.clock.Now()
+		if err := c.repo.Save(ctx, saga); err != nil {
+			log.Error("failed to save saga state", slog.String("error", err.Error()))
+			return
+		}
+	}
+
+	log.Info("saga finished", slog.String("status", string(saga.Status)))
+}
+
+// runStep runs the current step, moving to the next one on success or scheduling a retry (or the compensation) on failure
+func (c *Coordinator) runStep(ctx context.Context, log *slog.Logger, def *Definition, saga *Saga) {
+	step := def.Steps[saga.Step]
+	pastPivot := def.pastPivot(saga.Step)
+
+	if !pastPivot && def.Timeout > 0 && c.clock.Now().After(saga.CreatedAt.Add(def.Timeout)) {
+		log.Warn("saga timed out, compensating", slog.String("step", step.Name))
+		saga.LastError = fmt.Sprintf("timed out after %s", def.Timeout)
+		c.startCompensation(saga)
+		return
+	}
+
+	err := c.execute(ctx, step.Timeout, step.Action, saga)
+	if err == nil {
+		log.Info("saga step completed", slog.String("step", step.Name))
+		saga.Step++
+		saga.Attempts = 0
+		saga.LastError = ""
+		if saga.Step == len(def.Steps) {
+			c.finish(saga, StatusCompleted)
+		}
+		return
+	}
+
+	saga.Attempts++
+	saga.LastError = err.Error()
+	if !pastPivot && saga.Attempts >= step.MaxAttempts {
+		log.Warn("saga step failed, compensating",
+			slog.String("step", step.Name),
+			slog.Int("attempts", saga.Attempts),
+			slog.String("error", err.Error()),
+		)
+		c.startCompensation(saga)
+		return
+	}
+
+	log.Warn("saga step failed, retrying later",
+		slog.String("step", step.Name),
+		slog.Int("attempts", saga.Attempts),
+		slog.String("error", err.Error()),
+	)
+	saga.NextAttemptAt = c.clock.Now().Add(def.retryDelay(saga.Attempts))
+}
+
+// compensateStep undoes the current step, moving to the previous one on success or scheduling a retry on failure
+func (c *Coordinator) compensateStep(ctx context.Context, log *slog.Logger, def *Definition, saga *Saga) {
+	if saga.Step < 0 {
+		c.finish(saga, StatusCompensated)
+		return
+	}
+
+	step := def.Steps[saga.Step]
+	if step.Compensate != nil {
+		if err := c.execute(ctx, step.Timeout, step.Compensate, saga); err != nil {
+			saga.Attempts++
+			saga.LastError = err.Error()
+			if saga.Attempts >= maxCompensationAttempts {
+				log.Error("saga compensation keeps failing, giving up",
+					slog.String("step", step.Name),
+					slog.Int("attempts", saga.Attempts),
+					slog.String("error", err.Error()),
+				)
+				c.finish(saga, StatusFailed)
+				return
+			}
+
+			log.Warn("saga compensation failed, retrying later",
+				slog.String("step", step.Name),
+				slog.Int("attempts", saga.Attempts),
+				slog.String("error", err.Error()),
+			)
+			saga.NextAttemptAt = c.clock.Now().Add(def.retryDelay(saga.Attempts))
+			return
+		}
+		log.Info("saga step compensated", slog.String("step", step.Name))
+	}
+
+	saga.Step--
+	saga.Attempts = 0
+	if saga.Step < 0 {
+		c.finish(saga, StatusCompensated)
+	}
+}
+
+// startCompensation switches the saga to undo the steps completed before the current one
+func (c *Coordinator) startCompensation(saga *Saga) {
+	saga.Status = StatusCompensating
+	saga.Step--
+	saga.Attempts = 0
+	saga.NextAttemptAt = c.clock.Now()
+	if saga.Step < 0 {
+		c.finish(saga, StatusCompensated)
+	}
+}
+
+// finish moves the saga to a final status
+func (c *Coordinator) finish(saga *Saga, status Status) {
+	now := c.clock.Now()
+	saga.Status = status
+	saga.CompletedAt = &now
+}
+
+// execute runs fn within the step timeout, turning a panic into an error so a faulty step never crashes the API
+func (c *Coordinator) execute(ctx context.Context, timeout time.Duration, fn StepFunc, saga *Saga) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("saga step panicked: %v", r)
+		}
+	}()
+	return fn(ctx, saga)
+}
+
+// release frees the lease of a saga that was not saved
+func (c *Coordinator) release(ctx context.Context, log *slog.Logger, sagaID uuid.UUID) {
+	if err := c.repo.Release(ctx, sagaID); err != nil {
+		log.Error("failed to release saga", slog.String("error", err.Error()))
+	}
+}
+
+// pastPivot reports whether a step without compensation completed before the given step
+func (d *Definition) pastPivot(step int) bool {
+	for _, s := range d.Steps[:step] {
+		if s.Compensate == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay returns the wait before the next attempt of a step that failed attempts times, capped at one hour
+func (d *Definition) retryDelay(attempts int) time.Duration {
+	delay := d.RetryDelay
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, time.Hour)
+}
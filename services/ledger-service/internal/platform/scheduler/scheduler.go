@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/robfig/cron/v3"
+)
+
+// Task is a recurring unit of work triggered by the scheduler (e.g., purging old jobs)
+type Task struct {
+	Name     string                          // Name identifies the task in logs (e.g., "jobs_purge")
+	Schedule string                          // Schedule is a standard 5-field cron expression (e.g., "0 3 * * *")
+	Run      func(ctx context.Context) error // Run performs the work, ctx is cancelled on shutdown
+}
+
+// scheduledTask is a registered Task with its parsed schedule
+type scheduledTask struct {
+	Task
+	schedule cron.Schedule
+	running  atomic.Bool
+}
+
+// Scheduler triggers registered tasks following their cron schedules
+// In multi-instance deployments only the instance holding a Postgres advisory lock (the leader)
+// runs tasks; the others keep retrying to take over if the leader goes away
+type Scheduler struct {
+	pool          *pgxpool.Pool
+	logger        *slog.Logger
+	lockKey       int64
+	retryInterval time.Duration
+	tasks         []*scheduledTask
+	clock         clock.Clock
+}
+
+// New creates a new Scheduler using the scheduler section of the config, the schedules being followed on clock
+func New(pool *pgxpool.Pool, logger *slog.Logger, cfg config.Config, clock clock.Clock) *Scheduler {
+	return &Scheduler{
+		pool:          pool,
+		logger:        logger.With(slog.String("component", "scheduler")),
+		lockKey:       cfg.Jobs.Scheduler.LockKey,
+		retryInterval: cfg.Jobs.Scheduler.LeaderRetryInterval,
+		clock:         clock,
+	}
+}
+
+// Register adds a task to the scheduler, failing when its cron expression is invalid
+// Tasks must be registered before Run is called
+func (s *Scheduler) Register(task Task) error {
+	schedule, err := cron.ParseStandard(task.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q for task %s: %w", task.Schedule, task.Name, err)
+	}
+
+	s.tasks = append(s.tasks, &scheduledTask{Task: task, schedule: schedule})
+	return nil
+}
+
+// Run blocks until ctx is done, competing for leadership and triggering tasks while leader
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.tasks) == 0 {
+		s.logger.Info("no tasks registered, scheduler idle")
+		return
+	}
+
+	for {
+		leader, err := s.lead(ctx)
+		if err != nil {
+			s.logger.Error("scheduler leadership lost", slog.String("error", err.Error()))
+		} else if !leader {
+			s.logger.Debug("another instance is the scheduler leader")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+// lead tries to take the advisory lock and, when it succeeds, triggers tasks until ctx is done
+// or the lock connection breaks. The boolean result reports whether leadership was acquired
+func (s *Scheduler) lead(ctx context.Context) (bool, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection for leader election: %w", err)
+	}
+	defer conn.Release()
+
+	// The lock is bound to this session, so it is released if the connection (or the instance) dies
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", s.lockKey).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	defer func() {
+		// Use a fresh context: ctx may already be cancelled when shutting down
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock($1)", s.lockKey); err != nil {
+			s.logger.Error("failed to release scheduler advisory lock", slog.String("error", err.Error()))
+		}
+	}()
+
+	s.logger.Info("acquired scheduler leadership", slog.Int("tasks", len(s.tasks)))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Watch the lock session: if it breaks, another instance may take over, so stop triggering tasks
+	healthErr := make(chan error, 1)
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		ticker := time.NewTicker(s.retryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-leaderCtx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.Ping(leaderCtx); err != nil && leaderCtx.Err() == nil {
+					healthErr <- fmt.Errorf("advisory lock connection failed: %w", err)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	s.loop(leaderCtx)
+
+	// The connection is not safe for concurrent use: stop the watcher before unlocking
+	cancel()
+	<-watcherDone
+
+	select {
+	case err := <-healthErr:
+		return true, err
+	default:
+		return true, nil
+	}
+}
+
+// loop sleeps until the next due task and triggers it, until ctx is done
+func (s *Scheduler) loop(ctx context.Context) {
+	next := make(map[*scheduledTask]time.Time, len(s.tasks))
+	now := s.clock.Now()
+	for _, t := range s.tasks {
+		next[t] = t.schedule.Next(now)
+	}
+
+	for {
+		earliest := time.Time{}
+		for _, at := range next {
+			if earliest.IsZero() || at.Before(earliest) {
+				earliest = at
+			}
+		}
+
+		timer := time.NewTimer(earliest.Sub(s.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		now = s.clock.Now()
+
+		for t, at := range next {
+			if !at.After(now) {
+				s.trigger(ctx, t)
+				next[t] = t.schedule.Next(now)
+			}
+		}
+	}
+}
+
+// trigger runs a task in the background, skipping it if its previous run is still in progress
+func (s *Scheduler) trigger(ctx context.Context, t *scheduledTask) {
+	log := s.logger.With(slog.String("task", t.Name))
+
+	if !t.running.CompareAndSwap(false, true) {
+		log.Warn("skipping task run, previous run still in progress")
+		return
+	}
+
+	go func() {
+		defer t.running.Store(false)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("task panicked", slog.Any("panic", r))
+			}
+		}()
+
+		start := s.clock.Now()
+		log.Info("task started")
+		if err := t.Run(ctx); err != nil {
+			log.Error("task failed", slog.String("error", err.Error()), slog.String("duration", s.clock.Now().Sub(start).String()))
+			return
+		}
+		log.Info("task finished", slog.String("duration", s.clock.Now().Sub(start).String()))
+	}()
+}
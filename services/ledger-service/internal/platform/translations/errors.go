@@ -0,0 +1,276 @@
+package translations
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// Errors translates the error messages of the ledger service, the domain errors by code and the handler errors by message
+// The English messages are the ones the errors are declared with, so only the other languages are listed
+var Errors = i18n.Catalog{
+	i18n.Portuguese: {
+		// Accounts and transactions
+		"ACCOUNT_ACCESS_FORBIDDEN":           "a conta foi compartilhada com você, apenas o dono pode alterá-la ou abri-la fora da família",
+		"ACCOUNT_ALREADY_ARCHIVED":           "a conta já está arquivada",
+		"ACCOUNT_ALREADY_EXCLUDED":           "a conta já está fora do saldo geral",
+		"ACCOUNT_ALREADY_INCLUDED":           "a conta já está incluída no saldo geral",
+		"ACCOUNT_ARCHIVED":                   "a conta está arquivada",
+		"ACCOUNT_BALANCE_NOT_ZERO":           "o saldo real da conta deve ser zero",
+		"ACCOUNT_DELETION_ALREADY_SCHEDULED": "a exclusão da conta já está agendada",
+		"ACCOUNT_DELETION_NOT_DUE":           "o prazo para cancelar a exclusão da conta ainda não terminou",
+		"ACCOUNT_DELETION_NOT_SCHEDULED":     "a exclusão da conta não está agendada",
+		"ACCOUNT_MODIFIED":                   "a conta foi alterada ao mesmo tempo, recarregue-a e tente novamente",
+		"ACCOUNT_NAME_REQUIRED":              "o nome da conta é obrigatório",
+		"ACCOUNT_NAME_TOO_LONG":              "o nome da conta é longo demais",
+		"ACCOUNT_NOT_ARCHIVED":               "a conta não está arquivada",
+		"ACCOUNT_NOT_CREDIT_CARD":            "a conta não é um cartão de crédito",
+		"ACCOUNT_NOT_FOUND":                  "conta não encontrada",
+		"ACCOUNT_PENDING_DELETION":           "a conta está agendada para exclusão",
+		"AMOUNT_CANNOT_BE_ZERO":              "o valor da transação não pode ser zero",
+		"ARCHIVE_DATE_IN_FUTURE":             "apenas o histórico anterior a hoje pode ser arquivado",
+		"CLEARING_DATE_BEFORE_PAYMENT":       "a data prevista de compensação não pode ser anterior à data de pagamento",
+		"DESCRIPTION_REQUIRED":               "a descrição da transação é obrigatória",
+		"DESCRIPTION_TOO_LONG":               "a descrição da transação é longa demais",
+		"HISTORY_NOTHING_TO_ARCHIVE":         "nenhuma transação liquidada vence antes da data informada",
+		"INCONSISTENT_AMOUNT_SIGN":           "o sinal do valor da transação não condiz com o seu tipo",
+		"INVALID_ACCOUNT_KIND":               "tipo de conta inválido",
+		"INVALID_CLEARING_METHOD":            "a forma de compensação deve ser CHEQUE ou TED",
+		"INVALID_COORDINATES":                "a latitude deve estar entre -90 e 90 e a longitude entre -180 e 180",
+		"INVALID_DATE_RANGE":                 "o início do período deve ser anterior ao seu fim",
+		"INVALID_EXCHANGE_RATE":              "a taxa de câmbio deve ser um número decimal positivo",
+		"INVALID_OFFLINE_CHANGE":             "alteração offline inválida",
+		"INVALID_STATEMENT_CLOSING_DAY":      "o dia de fechamento da fatura deve estar entre 1 e 28",
+		"INVALID_TAG":                        "as tags devem ter entre 1 e 30 caracteres",
+		"INVALID_TRANSACTION_TYPE":           "tipo de transação inválido",
+		"NOTHING_TO_UNDO":                    "não há operação recente para desfazer",
+		"OBSERVATION_TOO_LONG":               "a observação da transação é longa demais",
+		"ORIGINAL_CURRENCY_SAME_AS_ACCOUNT":  "a moeda original deve ser diferente da moeda da conta",
+		"PAYMENT_ACCOUNT_NOT_CHECKING":       "as faturas devem ser pagas com uma conta corrente",
+		"PAYMENT_DATE_IN_FUTURE":             "a data de pagamento não pode estar no futuro",
+		"PIX_DETAILS_EMPTY":                  "ao menos um identificador PIX é obrigatório",
+		"PLACE_NAME_TOO_LONG":                "o nome do local é longo demais",
+		"STATEMENT_NOTHING_TO_PAY":           "a fatura não tem despesas em aberto",
+		"TOO_MANY_TAGS":                      "uma transação pode ter no máximo 10 tags",
+		"TRANSACTION_ALREADY_EXISTS":         "já existe uma transação com este id",
+		"TRANSACTION_ALREADY_PAID":           "a transação já está marcada como paga",
+		"TRANSACTION_ALREADY_UNPAID":         "a transação já está marcada como não paga",
+		"TRANSACTION_NOT_FOUND":              "transação não encontrada",
+		"UNDO_CONFLICT":                      "a operação não pode mais ser desfeita, o lançamento mudou desde então",
+		"UNDO_TOKEN_NOT_FOUND":               "token de desfazer não encontrado ou expirado",
+
+		// Money, PIX and boletos
+		"AMOUNT_OVERFLOW":             "o valor está fora do intervalo suportado",
+		"AMOUNT_TOO_PRECISE":          "o valor tem mais casas decimais do que a sua moeda permite",
+		"BOLETO_AMOUNT_REQUIRED":      "o boleto não traz o seu valor, ele deve ser informado",
+		"BOLETO_DUE_DATE_REQUIRED":    "o boleto não traz o seu vencimento, ele deve ser informado",
+		"CURRENCY_MISMATCH":           "os valores estão em moedas diferentes",
+		"INVALID_AMOUNT":              "o valor não é um número decimal válido",
+		"INVALID_BOLETO_CHECK_DIGIT":  "a linha digitável do boleto tem um dígito verificador errado, provavelmente foi digitada incorretamente",
+		"INVALID_BOLETO_LINE":         "a linha digitável do boleto deve ter 47 dígitos, ou 48 para contas de consumo e tributos",
+		"INVALID_CNPJ":                "o CNPJ é inválido",
+		"INVALID_PIX_END_TO_END_ID":   "o ID end-to-end do PIX é inválido",
+		"INVALID_PIX_KEY":             "a chave PIX não é um CPF, CNPJ, e-mail, telefone ou chave aleatória válida",
+		"INVALID_PIX_QR_PAYLOAD":      "o conteúdo do QR code PIX é inválido",
+		"UNSUPPORTED_BOLETO_CURRENCY": "apenas boletos em reais são suportados",
+		"UNSUPPORTED_CURRENCY":        "moeda não suportada",
+
+		// Budgets, payee limits and categorization
+		"BUDGET_NOT_FOUND":                   "orçamento não encontrado",
+		"CATEGORIZATION_RULE_ALREADY_EXISTS": "já existe uma regra com o mesmo texto para a conta",
+		"CATEGORIZATION_RULE_NOT_FOUND":      "regra de categorização não encontrada",
+		"CATEGORY_NOT_FOUND":                 "categoria não encontrada",
+		"DEFAULT_CATEGORY_NOT_DELETABLE":     "as categorias padrão não podem ser excluídas",
+		"EMPTY_RECATEGORIZATION_FILTER":      "ao menos um filtro é obrigatório para recategorizar transações",
+		"FILTER_TEXT_TOO_LONG":               "o texto do filtro é longo demais",
+		"FILTER_TEXT_TOO_SHORT":              "o texto do filtro é curto demais",
+		"INVALID_ALERT_THRESHOLDS":           "os limites de alerta devem ser percentuais distintos dentro do intervalo permitido",
+		"INVALID_BUDGET_AMOUNT":              "o valor do orçamento deve ser positivo",
+		"INVALID_HISTORY_MONTHS":             "o histórico do orçamento deve cobrir entre 1 e o número de meses permitido",
+		"INVALID_PAYEE":                      "o favorecido deve ter entre 2 e 60 caracteres",
+		"INVALID_PAYEE_LIMIT_AMOUNT":         "o valor do limite do favorecido deve ser positivo",
+		"PAYEE_LIMIT_ALREADY_EXISTS":         "o favorecido já tem um limite nesta moeda",
+		"PAYEE_LIMIT_NOT_FOUND":              "limite do favorecido não encontrado",
+		"REPLACEMENT_CATEGORY_DELETED":       "a categoria substituta é a categoria excluída ou uma de suas subcategorias",
+		"REPLACEMENT_CATEGORY_REQUIRED":      "uma categoria substituta, ou uncategorized, é obrigatória para excluir uma categoria",
+		"RULE_PATTERN_TOO_LONG":              "o texto da regra é longo demais",
+		"RULE_PATTERN_TOO_SHORT":             "o texto da regra é curto demais",
+		"SAME_CATEGORY":                      "a categoria de destino é a categoria filtrada",
+		"TOO_MANY_ALERT_THRESHOLDS":          "limites de alerta demais",
+		"TOO_MANY_CATEGORIZATION_RULES":      "o número máximo de regras de categorização foi atingido",
+		"TOO_MANY_PAYEE_LIMITS":              "o número máximo de limites de favorecidos foi atingido",
+
+		// Investments, net worth, forecasts and reports
+		"ACCOUNT_NOT_INVESTMENT":    "posições só podem ser mantidas em contas de investimento",
+		"INVALID_COST_BASIS":        "o custo de aquisição não pode ser negativo",
+		"INVALID_EXPORT_FORMAT":     "o formato da exportação deve ser csv ou json",
+		"INVALID_FORECAST_HORIZON":  "o horizonte deve ser um número seguido de d, w, m ou y (ex.: 6m), até 2 anos",
+		"INVALID_FORECAST_INTERVAL": "o intervalo deve ser day ou week",
+		"INVALID_HISTORY_RANGE":     "o período do histórico deve começar antes de terminar e cobrir no máximo o período permitido",
+		"INVALID_QUANTITY":          "a quantidade deve ser um número decimal positivo",
+		"INVALID_REPORT_RANGE":      "o período do relatório deve começar antes de terminar e cobrir no máximo o período permitido",
+		"INVALID_TICKER":            "o ticker deve ter de 1 a 12 letras, dígitos, pontos ou hífens",
+		"INVALID_WEEK_START":        "a semana deve começar em sunday ou monday",
+		"POSITION_NOT_FOUND":        "posição não encontrada",
+		"QUOTE_NOT_FOUND":           "nenhuma cotação disponível para o ticker",
+
+		// Imports, backups and saved views
+		"EMPTY_IMPORT":                "o arquivo não tem transações para importar",
+		"EXPORT_TOO_LARGE":            "o usuário tem mais transações do que uma exportação comporta",
+		"IMPORT_NOT_FOUND":            "importação não encontrada",
+		"IMPORT_NOT_PENDING":          "a importação já foi confirmada",
+		"INVALID_ARCHIVE":             "o arquivo de backup é inválido",
+		"INVALID_IMPORT_FILE":         "o arquivo não corresponde ao formato de importação",
+		"INVALID_VIEW_CRITERIA":       "os critérios da visão são inválidos",
+		"INVALID_VIEW_NAME":           "o nome da visão deve ter entre 1 e 60 caracteres",
+		"RESTORE_CONFLICT":            "o arquivo de backup contém registros de outro usuário",
+		"TOO_MANY_IMPORT_ROWS":        "o arquivo tem transações demais",
+		"TOO_MANY_VIEWS":              "o número máximo de visões foi atingido",
+		"UNMAPPED_IMPORT_CATEGORY":    "todas as categorias do arquivo devem ser mapeadas",
+		"UNSUPPORTED_ARCHIVE_VERSION": "a versão do arquivo de backup não é suportada",
+		"UNSUPPORTED_IMPORT_FORMAT":   "o formato de importação não é suportado",
+		"VIEW_ALREADY_EXISTS":         "já existe uma visão com o mesmo nome",
+		"VIEW_NOT_FOUND":              "visão não encontrada",
+
+		// Comments, households and account transfers
+		"ACCOUNT_LINKED_TO_BANK":           "contas sincronizadas com um banco não podem ser transferidas, desvincule a conta primeiro",
+		"ACCOUNT_TRANSFER_ALREADY_PENDING": "a conta já foi oferecida a outro usuário",
+		"ACCOUNT_TRANSFER_NOT_FOUND":       "transferência de conta não encontrada",
+		"ACCOUNT_TRANSFER_NOT_PENDING":     "a transferência de conta já foi resolvida",
+		"ACCOUNT_TRANSFER_TO_SELF":         "a conta já pertence a este usuário",
+		"ALREADY_HOUSEHOLD_MEMBER":         "o usuário já é membro da família ou já foi convidado para ela",
+		"ALREADY_SHARED_WITH_HOUSEHOLD":    "já compartilhado com a família",
+		"HOUSEHOLD_ADMIN_ONLY":             "apenas os administradores da família podem fazer isso",
+		"HOUSEHOLD_MEMBER_NOT_FOUND":       "membro da família não encontrado",
+		"HOUSEHOLD_NOT_FOUND":              "família não encontrada",
+		"HOUSEHOLD_PERMISSION_REQUIRED":    "o membro não tem permissão para ver isto na família",
+		"INVALID_COMMENT":                  "o comentário deve ter entre 1 e 1000 caracteres",
+		"INVALID_PROPOSAL_TYPE":            "apenas receitas e despesas podem ser propostas",
+		"INVALID_HOUSEHOLD_NAME":           "o nome da família deve ter entre 1 e 60 caracteres",
+		"INVALID_HOUSEHOLD_PERMISSION":     "permissão de família desconhecida",
+		"INVALID_ALLOCATION_RATIOS":        "as proporções de divisão não podem ser negativas e devem somar mais que zero",
+		"LAST_HOUSEHOLD_ADMIN":             "a família deve manter um administrador, promova outro membro ou exclua a família",
+		"NOT_ACCOUNT_TRANSFER_RECIPIENT":   "apenas o destinatário pode aceitar ou recusar uma transferência de conta",
+		"NOT_ACCOUNT_TRANSFER_SENDER":      "apenas o remetente pode cancelar uma transferência de conta",
+		"NOT_INVITED_TO_HOUSEHOLD":         "o usuário não tem convite pendente para a família",
+		"NOT_SHARED_ACCOUNT_OWNER":         "apenas o titular da conta pode aprovar ou rejeitar as transações propostas nela",
+		"NOT_SHARED_BY_MEMBER":             "apenas o membro que compartilhou ou um administrador pode deixar de compartilhar",
+		"NOT_TRANSACTION_PROPOSER":         "apenas o membro que propôs a transação pode cancelá-la",
+		"PROPOSAL_ON_OWN_ACCOUNT":          "adicione a transação diretamente à sua conta, propostas são para as contas de outros membros",
+		"RECIPIENT_NOT_FOUND":              "nenhum usuário está cadastrado com este e-mail",
+		"RECIPIENT_OUTSIDE_HOUSEHOLD":      "contas só podem ser transferidas para um membro de uma família do remetente",
+		"TRANSACTION_PROPOSAL_NOT_FOUND":   "proposta de transação não encontrada",
+		"TRANSACTION_PROPOSAL_NOT_PENDING": "a proposta de transação já foi resolvida",
+		"TOO_MANY_COMMENTS":                "o número máximo de comentários na transação foi atingido",
+		"TOO_MANY_HOUSEHOLDS":              "o número máximo de famílias foi atingido",
+		"TOO_MANY_HOUSEHOLD_MEMBERS":       "o número máximo de membros da família foi atingido",
+
+		// Business profiles and cost centers
+		"BUSINESS_DETAILS_EMPTY":     "um centro de custo ou o CNPJ do fornecedor é obrigatório",
+		"BUSINESS_DETAILS_NOT_FOUND": "a transação não tem dados empresariais",
+		"BUSINESS_PROFILE_NOT_FOUND": "o perfil empresarial não está ativado",
+		"COST_CENTER_ALREADY_EXISTS": "já existe um centro de custo com o mesmo nome",
+		"COST_CENTER_NOT_FOUND":      "centro de custo não encontrado",
+		"INVALID_COST_CENTER_NAME":   "o nome do centro de custo deve ter entre 1 e 60 caracteres",
+		"INVALID_LEGAL_NAME":         "a razão social deve ter entre 2 e 120 caracteres",
+		"TOO_MANY_COST_CENTERS":      "o número máximo de centros de custo foi atingido",
+
+		// Offline sync
+		"INVALID_SYNC_CURSOR":   "o cursor de sincronização é inválido",
+		"TOO_MANY_SYNC_CHANGES": "alterações offline demais em uma única sincronização",
+
+		// Account statement emails
+		"INVALID_STATEMENT_FORMAT":     "o formato do extrato deve ser pdf ou csv",
+		"STATEMENT_ACCOUNTS_EMPTY":     "ao menos uma conta é obrigatória",
+		"STATEMENT_SCHEDULE_NOT_FOUND": "nenhum envio de extratos está agendado",
+		"TOO_MANY_STATEMENT_ACCOUNTS":  "o email de extratos cobre contas demais",
+
+		// Savings interest
+		"ACCOUNT_NOT_SAVINGS":        "taxas de rendimento só podem ser definidas em contas poupança",
+		"INVALID_SIMULATION_PERIOD":  "a simulação deve começar no mês em que termina ou antes dele",
+		"INVALID_YIELD_RATE":         "a taxa de rendimento deve ser um percentual positivo de até 1000 com no máximo 4 casas decimais",
+		"SIMULATION_PERIOD_TOO_LONG": "o período da simulação é longo demais",
+		"YIELD_NOT_FOUND":            "nenhuma taxa de rendimento foi definida para a conta",
+
+		// Transaction attachments
+		"ATTACHMENT_EMPTY":            "o arquivo anexado está vazio",
+		"ATTACHMENT_NOT_FOUND":        "anexo não encontrado",
+		"ATTACHMENT_QUOTA_EXCEEDED":   "o limite de armazenamento de anexos foi atingido",
+		"ATTACHMENT_TOO_LARGE":        "o arquivo anexado é grande demais",
+		"INVALID_DOWNLOAD_LINK":       "o link de download é inválido ou expirou",
+		"TOO_MANY_ATTACHMENTS":        "a transação atingiu o número máximo de anexos",
+		"UNSUPPORTED_ATTACHMENT_TYPE": "apenas arquivos JPEG, PNG, WebP e PDF podem ser anexados",
+
+		// Bank connections
+		"ACCOUNT_ALREADY_LINKED":         "a conta já está vinculada a uma conta bancária",
+		"BANK_ACCOUNT_CURRENCY_MISMATCH": "a conta bancária e a conta têm moedas diferentes",
+		"BANK_ACCOUNT_KIND_MISMATCH":     "cartões de crédito do banco se vinculam a contas de cartão de crédito, as demais contas bancárias a contas correntes",
+		"BANK_ACCOUNT_LINK_NOT_FOUND":    "a conta bancária não está vinculada a uma conta",
+		"BANK_ACCOUNT_NOT_FOUND":         "a conta bancária não foi encontrada na conexão",
+		"BANK_CONSENT_ALREADY_EXISTS":    "a conexão bancária já foi adicionada",
+		"BANK_CONSENT_NOT_ACTIVE":        "o consentimento bancário foi revogado ou expirou",
+		"BANK_CONSENT_NOT_FOUND":         "consentimento bancário não encontrado",
+		"BANK_PROVIDER_UNAVAILABLE":      "o provedor de conexão bancária está indisponível",
+		"BANK_SYNC_IN_PROGRESS":          "a conexão bancária já está sendo sincronizada",
+		"INVALID_BANK_CONSENT_TOKEN":     "o token retornado pelo provedor de conexão bancária é inválido ou expirou",
+		"INVALID_BANK_WEBHOOK":           "a assinatura do webhook bancário é inválida",
+		"MALFORMED_BANK_WEBHOOK":         "o conteúdo do webhook bancário é inválido",
+		"UNSUPPORTED_BANK_PROVIDER":      "o provedor de conexão bancária não está configurado",
+
+		// Notifications, settings and preferences
+		"INVALID_ACCOUNTING_PERIOD":        "o mês financeiro começa em um dia de 1 a 28 ou em um dos 10 primeiros dias úteis",
+		"INVALID_REPLAY_RANGE":             "o início do período reenviado deve ser anterior ao seu fim",
+		"NOTIFICATION_CHANNEL_UNAVAILABLE": "o canal de notificação não está configurado",
+		"NOTIFICATION_DELIVERY_FAILED":     "não foi possível entregar a notificação",
+		"NOTIFICATION_NOT_FOUND":           "notificação não encontrada",
+		"PUSH_DEVICE_NOT_FOUND":            "dispositivo de notificações push não encontrado",
+		"PUSH_DEVICE_UNREGISTERED":         "o dispositivo de notificações push não está mais registrado",
+		"UNSUPPORTED_BASE_CURRENCY":        "a moeda base não é suportada",
+		"UNSUPPORTED_LOCALE":               "os valores não podem ser formatados nesta localidade",
+		"WEBHOOK_DELIVERY_NOT_FAILED":      "apenas as entregas de webhook que falharam podem ser reenviadas",
+		"WEBHOOK_DELIVERY_NOT_FOUND":       "entrega de webhook não encontrada",
+		"WEBHOOK_URL_REQUIRED":             "uma url de webhook é obrigatória para ativar o canal de webhook",
+
+		// Identity, database, jobs, sagas and demo mode
+		"ACCOUNT_DELETION_NOT_FOUND": "a exclusão da conta nunca foi solicitada",
+		"DATABASE_BUSY":              "nenhuma conexão com o banco de dados ficou disponível a tempo, tente novamente",
+		"DEMO_CAPACITY_REACHED":      "há sessões de demonstração demais em andamento, tente novamente mais tarde",
+		"EMAIL_ALREADY_IN_USE":       "o e-mail já está em uso",
+		"EMAIL_MISMATCH":             "o e-mail não corresponde ao da conta",
+		"EMAIL_NOT_VERIFIED":         "verifique seu e-mail antes de alterar seus dados",
+		"JOB_NOT_FOUND":              "tarefa não encontrada",
+		"SAGA_ALREADY_RUNNING":       "o mesmo fluxo já está em andamento",
+		"SAGA_NOT_FOUND":             "saga não encontrada",
+		"SERVICE_UNAVAILABLE":        "a autenticação está temporariamente indisponível",
+		"TOKEN_OUT_OF_SCOPE":         "o token de acesso não permite esta requisição",
+		"UNAUTHENTICATED":            "token de acesso inválido ou expirado",
+		"USER_DEACTIVATED":           "o usuário está desativado enquanto sua conta é excluída",
+		"USER_NOT_FOUND":             "usuário não encontrado",
+
+		// Messages of the handler errors
+		"invalid account id format":                            "formato do id da conta inválido",
+		"invalid account ID format":                            "formato do id da conta inválido",
+		"Invalid account ID format":                            "Formato do id da conta inválido",
+		"invalid attachment id format":                         "formato do id do anexo inválido",
+		"invalid backup archive format":                        "formato do arquivo de backup inválido",
+		"invalid budget id format":                             "formato do id do orçamento inválido",
+		"invalid category id format":                           "formato do id da categoria inválido",
+		"invalid consent id format":                            "formato do id do consentimento inválido",
+		"invalid cost center id format":                        "formato do id do centro de custo inválido",
+		"invalid household id format":                          "formato do id da família inválido",
+		"invalid import id format":                             "formato do id da importação inválido",
+		"invalid job id format":                                "formato do id da tarefa inválido",
+		"invalid notification id format":                       "formato do id da notificação inválido",
+		"invalid payee limit id format":                        "formato do id do limite do favorecido inválido",
+		"invalid proposal id format":                           "formato do id da proposta inválido",
+		"invalid proposal status":                              "status da proposta inválido",
+		"invalid replacement category id format":               "formato do id da categoria substituta inválido",
+		"invalid rule id format":                               "formato do id da regra inválido",
+		"invalid transaction id format":                        "formato do id da transação inválido",
+		"invalid transfer id format":                           "formato do id da transferência inválido",
+		"invalid user id format":                               "formato do id do usuário inválido",
+		"invalid view id format":                               "formato do id da visão inválido",
+		"invalid month format, expected YYYY-MM":               "formato do mês inválido, esperado AAAA-MM",
+		"invalid statement period format, expected YYYY-MM":    "formato do período da fatura inválido, esperado AAAA-MM",
+		"invalid near format, expected latitude,longitude":     "formato do near inválido, esperado latitude,longitude",
+		"invalid radius, expected meters between 1 and 100000": "raio inválido, esperado em metros entre 1 e 100000",
+		"the file field is required":                           "o campo file é obrigatório",
+		"the file could not be read":                           "não foi possível ler o arquivo",
+	},
+}
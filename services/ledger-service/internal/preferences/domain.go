@@ -0,0 +1,168 @@
+package preferences
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnsupportedCurrency = errx.New(errx.CategoryValidation, "UNSUPPORTED_BASE_CURRENCY", "the base currency is not supported")
+	ErrUnsupportedLocale   = errx.New(errx.CategoryValidation, "UNSUPPORTED_LOCALE", "amounts can not be formatted in this locale")
+)
+
+const (
+	DateFormatISO        DateFormat = "YYYY-MM-DD"
+	DateFormatDayFirst   DateFormat = "DD/MM/YYYY"
+	DateFormatMonthFirst DateFormat = "MM/DD/YYYY"
+)
+
+// DateFormat is how the user wants dates written
+type DateFormat string
+
+// Values returns every known date format, used to validate enum fields
+func (DateFormat) Values() []string {
+	return []string{string(DateFormatISO), string(DateFormatDayFirst), string(DateFormatMonthFirst)}
+}
+
+// Layout returns the Go layout of the date format
+func (f DateFormat) Layout() string {
+	switch f {
+	case DateFormatISO:
+		return time.DateOnly
+	case DateFormatMonthFirst:
+		return "01/02/2006"
+	default:
+		return "02/01/2006"
+	}
+}
+
+const (
+	WeekStartLocale WeekStart = "locale"
+	WeekStartSunday WeekStart = "sunday"
+	WeekStartMonday WeekStart = "monday"
+)
+
+// WeekStart is the day the user's weeks start on, WeekStartLocale following the conventions of the locale
+type WeekStart string
+
+// Values returns every known week start, used to validate enum fields
+func (WeekStart) Values() []string {
+	return []string{string(WeekStartLocale), string(WeekStartSunday), string(WeekStartMonday)}
+}
+
+// Weekday returns the day the weeks start on, the conventions of locale deciding for WeekStartLocale
+func (w WeekStart) Weekday(locale string) time.Weekday {
+	switch w {
+	case WeekStartSunday:
+		return time.Sunday
+	case WeekStartMonday:
+		return time.Monday
+	default:
+		return localeWeekStart(locale)
+	}
+}
+
+// sundayFirstRegions lists the regions whose calendars start the week on Sunday, most others starting it on Monday
+var sundayFirstRegions = map[string]bool{
+	"br": true, "us": true, "ca": true, "mx": true, "ar": true, "co": true, "pe": true, "ve": true,
+	"jp": true, "kr": true, "tw": true, "hk": true, "in": true, "il": true, "za": true, "ph": true,
+}
+
+// localeWeekStart returns the day the weeks start on in the region of the locale (e.g., Monday for "pt-PT")
+// A locale without region follows the language's main country, and an empty one the pt-BR conventions
+func localeWeekStart(locale string) time.Weekday {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	lang, region, _ := strings.Cut(tag, "-")
+	if region == "" {
+		switch lang {
+		case "", "pt":
+			region = "br"
+		case "en":
+			region = "us"
+		case "ja":
+			region = "jp"
+		case "he":
+			region = "il"
+		}
+	}
+	if sundayFirstRegions[region] {
+		return time.Sunday
+	}
+	return time.Monday
+}
+
+// Repository persists the display preferences of the users
+type Repository interface {
+	// FindByUserID returns the preferences of the user, nil when the user never changed them
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+	// Save inserts or replaces the preferences of a user
+	Save(ctx context.Context, prefs *Preferences) error
+}
+
+// Preferences are how a user wants amounts and dates shown across the reports and notifications
+type Preferences struct {
+	UserID       uuid.UUID
+	BaseCurrency string     // BaseCurrency is the currency the reports lead with
+	Locale       string     // Locale formats the amounts, empty to follow the language of the identity account
+	DateFormat   DateFormat // DateFormat writes the dates of the emails and notifications
+	WeekStart    WeekStart  // WeekStart is the day the weeks of the reports start on
+	UpdatedAt    time.Time
+}
+
+// DefaultPreferences returns the preferences of a user who never changed them: reais, day-first dates and weeks following the locale
+func DefaultPreferences(userID uuid.UUID) *Preferences {
+	return &Preferences{UserID: userID, BaseCurrency: "BRL", DateFormat: DateFormatDayFirst, WeekStart: WeekStartLocale}
+}
+
+// SetBaseCurrency changes the currency the reports lead with
+func (p *Preferences) SetBaseCurrency(currency string, clk clock.Clock) error {
+	if !money.IsKnownCurrency(currency) {
+		return ErrUnsupportedCurrency.With("currency", currency)
+	}
+	p.BaseCurrency = strings.ToUpper(currency)
+	p.UpdatedAt = clk.Now()
+	return nil
+}
+
+// SetLocale changes the locale the amounts are formatted in, an empty locale following the identity account again
+func (p *Preferences) SetLocale(locale string, clk clock.Clock) error {
+	if locale != "" && !money.IsKnownLocale(locale) {
+		return ErrUnsupportedLocale.With("locale", locale)
+	}
+	p.Locale = locale
+	p.UpdatedAt = clk.Now()
+	return nil
+}
+
+// SetDateFormat changes how dates are written, the format being validated as an enum by the handler
+func (p *Preferences) SetDateFormat(format DateFormat, clk clock.Clock) {
+	p.DateFormat = format
+	p.UpdatedAt = clk.Now()
+}
+
+// SetWeekStart changes the day the weeks start on, the week start being validated as an enum by the handler
+func (p *Preferences) SetWeekStart(weekStart WeekStart, clk clock.Clock) {
+	p.WeekStart = weekStart
+	p.UpdatedAt = clk.Now()
+}
+
+// Display returns the preferences as followed by the other modules, accountLocale standing in for an empty locale
+func (p *Preferences) Display(accountLocale string) module.DisplayPreferences {
+	locale := p.Locale
+	if locale == "" {
+		locale = accountLocale
+	}
+	return module.DisplayPreferences{
+		BaseCurrency: p.BaseCurrency,
+		Locale:       locale,
+		DateLayout:   p.DateFormat.Layout(),
+		WeekStart:    p.WeekStart.Weekday(locale),
+	}
+}
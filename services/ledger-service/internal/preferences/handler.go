@@ -0,0 +1,164 @@
+package preferences
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/notifications"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/settings"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PreferencesHandler holds dependencies for user preferences HTTP handlers
+type PreferencesHandler struct {
+	preferencesService *Service
+	clock              clock.Clock
+}
+
+// NewPreferencesHandler creates a new instance of PreferencesHandler
+func NewPreferencesHandler(preferencesService *Service, clock clock.Clock) *PreferencesHandler {
+	return &PreferencesHandler{preferencesService: preferencesService, clock: clock}
+}
+
+// RegisterRoutes sets up the API routes for the preferences module
+func (h *PreferencesHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.GET("/me/preferences", h.getPreferencesHandler)
+	apiRouteGroup.PATCH("/me/preferences", h.updatePreferencesHandler)
+}
+
+// FinancialMonthRequest defines when the financial month starts, omitting both fields going back to calendar months
+type FinancialMonthRequest struct {
+	StartDay         int `json:"start_day" validate:"omitempty,min=1,max=28"`
+	StartBusinessDay int `json:"start_business_day" validate:"omitempty,min=1,max=10"`
+}
+
+// NotificationOptInsRequest defines the notifications the user subscribes to
+type NotificationOptInsRequest struct {
+	WeeklySummary *bool                                   `json:"weekly_summary,omitempty"`
+	Categories    []notifications.CategoryChannelsRequest `json:"categories,omitempty" validate:"omitempty,dive"`
+}
+
+// UpdatePreferencesRequest defines the expected JSON body for changing the user's preferences, omitted fields being left unchanged
+type UpdatePreferencesRequest struct {
+	BaseCurrency     *string                    `json:"base_currency,omitempty" validate:"omitempty,len=3"`
+	Locale           *string                    `json:"locale,omitempty" validate:"omitempty,max=35"` // An empty locale follows the language of the account
+	DateFormat       *DateFormat                `json:"date_format,omitempty" validate:"omitempty,enum"`
+	WeekStart        *WeekStart                 `json:"week_start,omitempty" validate:"omitempty,enum"` // "locale" follows the conventions of the locale
+	FinancialMonth   *FinancialMonthRequest     `json:"financial_month,omitempty"`
+	Notifications    *NotificationOptInsRequest `json:"notifications,omitempty"`
+	AnalyticsSharing *bool                      `json:"analytics_sharing,omitempty"` // AnalyticsSharing opts in or out of the export of the ledger changes to the data warehouse
+}
+
+// NotificationOptInsResponse defines the notifications the user subscribes to returned by the API
+type NotificationOptInsResponse struct {
+	WeeklySummary bool `json:"weekly_summary"`
+	notifications.PreferencesResponse
+}
+
+// PreferencesResponse defines the structure of the user's preferences returned by the API
+type PreferencesResponse struct {
+	BaseCurrency     string                            `json:"base_currency"`
+	Locale           string                            `json:"locale,omitempty"`
+	DateFormat       DateFormat                        `json:"date_format"`
+	WeekStart        WeekStart                         `json:"week_start"`
+	FinancialMonth   settings.AccountingPeriodResponse `json:"financial_month"`
+	Notifications    NotificationOptInsResponse        `json:"notifications"`
+	AnalyticsSharing bool                              `json:"analytics_sharing"`
+	UpdatedAt        *time.Time                        `json:"updated_at,omitempty"`
+}
+
+// getPreferencesHandler handles the HTTP request for finding every preference of the user
+func (h *PreferencesHandler) getPreferencesHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	profile, err := h.preferencesService.GetProfile(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toPreferencesResponse(profile, h.clock))
+}
+
+// updatePreferencesHandler handles the HTTP request for changing any of the user's preferences
+func (h *PreferencesHandler) updatePreferencesHandler(c echo.Context) error {
+	var req UpdatePreferencesRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	params := UpdateProfileParams{
+		UserID:           userID,
+		BaseCurrency:     req.BaseCurrency,
+		Locale:           req.Locale,
+		DateFormat:       req.DateFormat,
+		WeekStart:        req.WeekStart,
+		AnalyticsSharing: req.AnalyticsSharing,
+	}
+	if req.FinancialMonth != nil {
+		params.AccountingPeriod = &clock.AccountingPeriod{
+			StartDay:         req.FinancialMonth.StartDay,
+			StartBusinessDay: req.FinancialMonth.StartBusinessDay,
+		}
+	}
+	if req.Notifications != nil {
+		params.WeeklySummary = req.Notifications.WeeklySummary
+		if len(req.Notifications.Categories) > 0 {
+			params.Channels = make(map[notify.Category][]notifications.ChannelKind, len(req.Notifications.Categories))
+			for _, category := range req.Notifications.Categories {
+				params.Channels[category.Category] = category.Channels
+			}
+		}
+	}
+
+	profile, err := h.preferencesService.UpdateProfile(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toPreferencesResponse(profile, h.clock))
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toPreferencesResponse maps the Profile of the user to the public PreferencesResponse DTO
+func toPreferencesResponse(p *Profile, clk clock.Clock) PreferencesResponse {
+	resp := PreferencesResponse{
+		BaseCurrency:   p.Display.BaseCurrency,
+		Locale:         p.Display.Locale,
+		DateFormat:     p.Display.DateFormat,
+		WeekStart:      p.Display.WeekStart,
+		FinancialMonth: settings.ToAccountingPeriodResponse(p.Settings, clk),
+		Notifications: NotificationOptInsResponse{
+			WeeklySummary:       p.Settings.WeeklySummary,
+			PreferencesResponse: notifications.ToPreferencesResponse(p.Notifications),
+		},
+		AnalyticsSharing: p.Settings.AnalyticsSharing,
+	}
+	if !p.Display.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &p.Display.UpdatedAt
+	}
+	return resp
+}
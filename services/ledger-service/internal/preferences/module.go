@@ -0,0 +1,40 @@
+package preferences
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the preferences repository, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *PreferencesHandler
+}
+
+// NewModule creates the preferences module, whose display preferences are stored in Postgres
+// The financial month and the notification opt-ins are kept by the settings and notifications modules
+func NewModule(deps module.Deps, settings SettingsService, notifications NotificationService) *Module {
+	preferencesSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), settings, notifications, deps.Clock)
+
+	return &Module{
+		service: preferencesSvc,
+		handler: NewPreferencesHandler(preferencesSvc, deps.Clock),
+	}
+}
+
+// Display returns the resolver of the users' display preferences, handed to the modules through module.Deps
+func (m *Module) Display() module.UserPreferences {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "preferences"
+}
+
+// RegisterRoutes mounts the preferences routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
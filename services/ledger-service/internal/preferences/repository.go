@@ -0,0 +1,70 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// FindByUserID retrieves the preferences of a user, nil when the user never changed them
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	query := `
+		SELECT user_id, base_currency, locale, date_format, week_start, updated_at
+		FROM user_preferences
+		WHERE user_id = $1
+	`
+
+	var prefs Preferences
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&prefs.UserID,
+		&prefs.BaseCurrency,
+		&prefs.Locale,
+		&prefs.DateFormat,
+		&prefs.WeekStart,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch user preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// Save inserts or replaces the preferences of a user
+func (r *PostgresRepository) Save(ctx context.Context, prefs *Preferences) error {
+	query := `
+		INSERT INTO user_preferences (user_id, base_currency, locale, date_format, week_start, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			base_currency = EXCLUDED.base_currency,
+			locale = EXCLUDED.locale,
+			date_format = EXCLUDED.date_format,
+			week_start = EXCLUDED.week_start,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, prefs.UserID, prefs.BaseCurrency, prefs.Locale, prefs.DateFormat, prefs.WeekStart, prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert user preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package preferences
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/notifications"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/settings"
+	"github.com/google/uuid"
+)
+
+var _ module.UserPreferences = (*Service)(nil)
+
+// SettingsService is what the preferences need from the settings module, which keeps the financial month, the weekly summary
+// and the data warehouse export consent
+type SettingsService interface {
+	GetSettings(ctx context.Context, userID uuid.UUID) (*settings.Settings, error)
+	UpdateAccountingPeriod(ctx context.Context, userID uuid.UUID, period clock.AccountingPeriod) (*settings.Settings, error)
+	UpdateWeeklySummary(ctx context.Context, userID uuid.UUID, enabled bool) (*settings.Settings, error)
+	UpdateAnalyticsSharing(ctx context.Context, userID uuid.UUID, enabled bool) (*settings.Settings, error)
+}
+
+// NotificationService is what the preferences need from the notifications module, which keeps the channels of each category
+type NotificationService interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*notifications.Preferences, error)
+	UpdatePreferences(ctx context.Context, params notifications.UpdatePreferencesParams) (*notifications.Preferences, error)
+}
+
+// Profile gathers every preference of a user, each part being kept by the module following it
+type Profile struct {
+	Display       *Preferences
+	Settings      *settings.Settings
+	Notifications *notifications.Preferences
+}
+
+// Service manages the display preferences of the users and gathers them with the settings and notification preferences
+type Service struct {
+	repo          Repository
+	settings      SettingsService
+	notifications NotificationService
+	clock         clock.Clock
+}
+
+// NewService creates a new instance of the preferences Service
+func NewService(repo Repository, settings SettingsService, notifications NotificationService, clock clock.Clock) *Service {
+	return &Service{
+		repo:          repo,
+		settings:      settings,
+		notifications: notifications,
+		clock:         clock,
+	}
+}
+
+// GetPreferences is the use case for finding the user's display preferences, the defaults when never changed
+func (s *Service) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	prefs, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find preferences: %w", err)
+	}
+	if prefs == nil {
+		return DefaultPreferences(userID), nil
+	}
+	return prefs, nil
+}
+
+// GetProfile is the use case for finding every preference of the user
+func (s *Service) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	display, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	userSettings, err := s.settings.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	notificationPrefs, err := s.notifications.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{Display: display, Settings: userSettings, Notifications: notificationPrefs}, nil
+}
+
+// UpdateProfileParams holds the changes to apply to the user's preferences, nil fields being left unchanged
+type UpdateProfileParams struct {
+	UserID           uuid.UUID
+	BaseCurrency     *string
+	Locale           *string
+	DateFormat       *DateFormat
+	WeekStart        *WeekStart
+	AccountingPeriod *clock.AccountingPeriod
+	WeeklySummary    *bool
+	AnalyticsSharing *bool
+	Channels         map[notify.Category][]notifications.ChannelKind // Channels replaces the channels of the listed categories
+}
+
+// UpdateProfile is the use case for changing any of the user's preferences at once
+// The display preferences are validated before anything is saved, the other parts are saved by their own module
+func (s *Service) UpdateProfile(ctx context.Context, params UpdateProfileParams) (*Profile, error) {
+	display, err := s.GetPreferences(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	displayChanged := params.BaseCurrency != nil || params.Locale != nil || params.DateFormat != nil || params.WeekStart != nil
+	if params.BaseCurrency != nil {
+		if err := display.SetBaseCurrency(*params.BaseCurrency, s.clock); err != nil {
+			return nil, err
+		}
+	}
+	if params.Locale != nil {
+		if err := display.SetLocale(*params.Locale, s.clock); err != nil {
+			return nil, err
+		}
+	}
+	if params.DateFormat != nil {
+		display.SetDateFormat(*params.DateFormat, s.clock)
+	}
+	if params.WeekStart != nil {
+		display.SetWeekStart(*params.WeekStart, s.clock)
+	}
+	if displayChanged {
+		if err := s.repo.Save(ctx, display); err != nil {
+			return nil, fmt.Errorf("failed to save preferences: %w", err)
+		}
+	}
+
+	if params.AccountingPeriod != nil {
+		if _, err := s.settings.UpdateAccountingPeriod(ctx, params.UserID, *params.AccountingPeriod); err != nil {
+			return nil, err
+		}
+	}
+	if params.WeeklySummary != nil {
+		if _, err := s.settings.UpdateWeeklySummary(ctx, params.UserID, *params.WeeklySummary); err != nil {
+			return nil, err
+		}
+	}
+	if params.AnalyticsSharing != nil {
+		if _, err := s.settings.UpdateAnalyticsSharing(ctx, params.UserID, *params.AnalyticsSharing); err != nil {
+			return nil, err
+		}
+	}
+	if len(params.Channels) > 0 {
+		_, err := s.notifications.UpdatePreferences(ctx, notifications.UpdatePreferencesParams{
+			UserID:   params.UserID,
+			Channels: params.Channels,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetProfile(ctx, params.UserID)
+}
+
+// DisplayPreferences returns how the user wants amounts and dates shown, for the reports and notifications
+// An unset locale follows the language of the identity account when the user is the caller of the request
+func (s *Service) DisplayPreferences(ctx context.Context, userID uuid.UUID) (module.DisplayPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return module.DisplayPreferences{}, err
+	}
+
+	accountLocale := ""
+	if user, ok := authctx.UserFromContext(ctx); ok && user.UserID == userID {
+		accountLocale = user.Locale
+	}
+	return prefs.Display(accountLocale), nil
+}
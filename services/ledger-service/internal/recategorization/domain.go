@@ -0,0 +1,100 @@
+package recategorization
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyFilter        = errx.New(errx.CategoryValidation, "EMPTY_RECATEGORIZATION_FILTER", "at least one filter is required to recategorize transactions")
+	ErrInvalidDateRange   = errx.New(errx.CategoryValidation, "INVALID_DATE_RANGE", "the start of the date range must be before its end")
+	ErrSameCategory       = errx.New(errx.CategoryValidation, "SAME_CATEGORY", "the target category is the category being filtered")
+	ErrCategoryNotFound   = errx.New(errx.CategoryNotFound, "CATEGORY_NOT_FOUND", "category not found")
+	ErrUndoTokenNotFound  = errx.New(errx.CategoryNotFound, "UNDO_TOKEN_NOT_FOUND", "undo token not found or expired")
+	ErrFilterTextTooLong  = errx.New(errx.CategoryValidation, "FILTER_TEXT_TOO_LONG", "the text filter is too long")
+	ErrFilterTextTooShort = errx.New(errx.CategoryValidation, "FILTER_TEXT_TOO_SHORT", "the text filter is too short")
+
+	ErrReplacementCategoryRequired = errx.New(errx.CategoryValidation, "REPLACEMENT_CATEGORY_REQUIRED", "a replacement category, or uncategorized, is required to delete a category")
+	ErrReplacementCategoryDeleted  = errx.New(errx.CategoryValidation, "REPLACEMENT_CATEGORY_DELETED", "the replacement category is the deleted category or one of its subcategories")
+	ErrDefaultCategoryNotDeletable = errx.New(errx.CategoryForbidden, "DEFAULT_CATEGORY_NOT_DELETABLE", "the default categories cannot be deleted")
+)
+
+const (
+	// UndoTTL is how long a recategorization can be undone
+	UndoTTL = 24 * time.Hour
+
+	// Uncategorized is the replacement leaving the transactions of a deleted category without a category
+	Uncategorized = "uncategorized"
+
+	minFilterTextLength = 2
+	maxFilterTextLength = 100
+)
+
+// Filter selects the transactions of a user to recategorize, all the given criteria must match
+type Filter struct {
+	CategoryID *uuid.UUID // CategoryID matches the transactions currently in this category
+	Text       string     // Text matches descriptions containing it, case-insensitively
+	From       *time.Time // From matches due dates on or after it
+	To         *time.Time // To matches due dates before it
+}
+
+// Validate checks the filter selects a bounded set of transactions
+func (f Filter) Validate() error {
+	text := strings.TrimSpace(f.Text)
+	if f.CategoryID == nil && text == "" && f.From == nil && f.To == nil {
+		return ErrEmptyFilter
+	}
+	if text != "" && len([]rune(text)) < minFilterTextLength {
+		return ErrFilterTextTooShort.With("min_length", minFilterTextLength)
+	}
+	if len([]rune(text)) > maxFilterTextLength {
+		return ErrFilterTextTooLong.With("max_length", maxFilterTextLength)
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// likePattern returns the ILIKE pattern of the text filter, with its wildcards escaped
+func (f Filter) likePattern() *string {
+	text := strings.TrimSpace(f.Text)
+	if text == "" {
+		return nil
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+	pattern := "%" + escaped + "%"
+	return &pattern
+}
+
+// Batch is a recategorization that can still be undone, identified by its undo token
+type Batch struct {
+	Token            uuid.UUID
+	UserID           uuid.UUID
+	TargetCategoryID uuid.UUID
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+}
+
+// Result reports a recategorization, Batch is nil when no transaction matched
+type Result struct {
+	Affected int64
+	Batch    *Batch
+}
+
+// Repository runs the recategorizations and keeps the previous categories needed to undo them
+type Repository interface {
+	CategoryExists(ctx context.Context, userID, categoryID uuid.UUID) (bool, error)
+	// Recategorize moves the matching transactions to the batch target category, returning how many were moved
+	Recategorize(ctx context.Context, batch *Batch, filter Filter) (int64, error)
+	// Undo restores the previous categories of a batch that did not expire and discards it, returning how many were restored
+	// Transactions moved to another category since then are left as they are
+	Undo(ctx context.Context, userID, token uuid.UUID, now time.Time) (int64, error)
+	// DeleteCategory deletes a category of the user and its subcategories, moving their transactions to the replacement
+	// (nil leaving them uncategorized) in the same transaction, returning how many were moved
+	DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, replacementID *uuid.UUID) (int64, error)
+}
@@ -0,0 +1,179 @@
+package recategorization
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RecategorizationHandler holds dependencies for recategorization-related HTTP handlers
+type RecategorizationHandler struct {
+	recategorizationService *Service
+}
+
+// NewRecategorizationHandler creates a new instance of RecategorizationHandler
+func NewRecategorizationHandler(recategorizationService *Service) *RecategorizationHandler {
+	return &RecategorizationHandler{recategorizationService: recategorizationService}
+}
+
+// RegisterRoutes sets up the API routes for the recategorization module
+func (h *RecategorizationHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	transactionsGroup := apiRouteGroup.Group("/transactions")
+
+	transactionsGroup.POST("/recategorize", h.recategorizeHandler)
+	transactionsGroup.POST("/recategorize/undo", h.undoHandler)
+
+	categoriesGroup := apiRouteGroup.Group("/categories")
+	categoriesGroup.DELETE("/:id", h.deleteCategoryHandler)
+}
+
+// FilterRequest defines the expected JSON filter selecting the transactions to recategorize
+type FilterRequest struct {
+	CategoryID *uuid.UUID `json:"category_id,omitempty"` // Matches the transactions currently in this category
+	Text       string     `json:"text,omitempty" validate:"max=100"`
+	From       *time.Time `json:"from,omitempty"` // Matches due dates on or after it
+	To         *time.Time `json:"to,omitempty"`   // Matches due dates before it
+}
+
+// RecategorizeRequest defines the expected JSON body for recategorizing transactions in bulk
+type RecategorizeRequest struct {
+	Filter           FilterRequest `json:"filter"`
+	TargetCategoryID uuid.UUID     `json:"target_category_id" validate:"required"`
+}
+
+// UndoRequest defines the expected JSON body for undoing a recategorization
+type UndoRequest struct {
+	UndoToken uuid.UUID `json:"undo_token" validate:"required"`
+}
+
+// RecategorizeResponse defines the result of a recategorization returned by the API
+// The undo token is absent when no transaction matched the filter
+type RecategorizeResponse struct {
+	Affected      int64      `json:"affected"`
+	UndoToken     *uuid.UUID `json:"undo_token,omitempty"`
+	UndoExpiresAt *time.Time `json:"undo_expires_at,omitempty"`
+}
+
+// UndoResponse defines the result of undoing a recategorization returned by the API
+type UndoResponse struct {
+	Restored int64 `json:"restored"`
+}
+
+// DeleteCategoryResponse defines the result of deleting a category returned by the API
+type DeleteCategoryResponse struct {
+	Moved int64 `json:"moved"` // Moved counts the transactions moved to the replacement category
+}
+
+// recategorizeHandler handles the HTTP request for moving the transactions matching a filter to another category
+func (h *RecategorizationHandler) recategorizeHandler(c echo.Context) error {
+	var req RecategorizeRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := RecategorizeParams{
+		UserID: userID,
+		Filter: Filter{
+			CategoryID: req.Filter.CategoryID,
+			Text:       req.Filter.Text,
+			From:       req.Filter.From,
+			To:         req.Filter.To,
+		},
+		TargetCategoryID: req.TargetCategoryID,
+	}
+
+	result, err := h.recategorizationService.Recategorize(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	resp := RecategorizeResponse{Affected: result.Affected}
+	if result.Batch != nil {
+		resp.UndoToken = &result.Batch.Token
+		resp.UndoExpiresAt = &result.Batch.ExpiresAt
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// undoHandler handles the HTTP request for undoing a recategorization through its undo token
+func (h *RecategorizationHandler) undoHandler(c echo.Context) error {
+	var req UndoRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	restored, err := h.recategorizationService.Undo(c.Request().Context(), userID, req.UndoToken)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, UndoResponse{Restored: restored})
+}
+
+// deleteCategoryHandler handles the HTTP request for deleting a category, the replacement_category_id query parameter
+// naming the category its transactions move to, or uncategorized
+func (h *RecategorizationHandler) deleteCategoryHandler(c echo.Context) error {
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid category id format")
+	}
+
+	var replacementID *uuid.UUID
+	switch raw := c.QueryParam("replacement_category_id"); raw {
+	case "":
+		return ErrReplacementCategoryRequired
+	case Uncategorized:
+	default:
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid replacement category id format")
+		}
+		replacementID = &id
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	params := DeleteCategoryParams{
+		UserID:        userID,
+		CategoryID:    categoryID,
+		ReplacementID: replacementID,
+	}
+
+	moved, err := h.recategorizationService.DeleteCategory(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, DeleteCategoryResponse{Moved: moved})
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
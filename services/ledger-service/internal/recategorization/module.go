@@ -0,0 +1,32 @@
+package recategorization
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the recategorization repository, service and handler from the shared dependencies
+type Module struct {
+	handler *RecategorizationHandler
+}
+
+// NewModule creates the recategorization module, which updates the transactions and the categories straight in Postgres
+func NewModule(deps module.Deps) *Module {
+	recategorizationSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.Audit, deps.Clock)
+
+	return &Module{
+		handler: NewRecategorizationHandler(recategorizationSvc),
+	}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "recategorization"
+}
+
+// RegisterRoutes mounts the recategorization routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
@@ -0,0 +1,231 @@
+package recategorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// errNothingMatched rolls back a recategorization that matched no transaction, so no empty batch is kept
+var errNothingMatched = errors.New("no transaction matched the filter")
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// CategoryExists tells whether the category is one of the user's or a system-default one
+func (r *PostgresRepository) CategoryExists(ctx context.Context, userID, categoryID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND (user_id = $2 OR user_id IS NULL))`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, categoryID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check category: %w", err)
+	}
+	return exists, nil
+}
+
+// Recategorize moves the matching transactions with a single UPDATE, whose previous categories are inserted
+// as the batch items in the same statement. The user's expired batches are pruned in the same transaction
+func (r *PostgresRepository) Recategorize(ctx context.Context, batch *Batch, filter Filter) (int64, error) {
+	var affected int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		pruneQuery := `DELETE FROM recategorization_batches WHERE user_id = $1 AND expires_at <= $2`
+		if _, err := tx.Exec(ctx, pruneQuery, batch.UserID, batch.CreatedAt); err != nil {
+			return fmt.Errorf("failed to prune expired recategorizations: %w", err)
+		}
+
+		batchQuery := `
+			INSERT INTO recategorization_batches (id, user_id, target_category_id, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.Exec(ctx, batchQuery, batch.Token, batch.UserID, batch.TargetCategoryID, batch.CreatedAt, batch.ExpiresAt); err != nil {
+			return fmt.Errorf("failed to insert recategorization batch: %w", err)
+		}
+
+		updateQuery := `
+			WITH matched AS (
+				SELECT id, category_id
+				FROM transactions
+				WHERE user_id = $1
+					AND category_id IS DISTINCT FROM $2
+					AND ($3::uuid IS NULL OR category_id = $3)
+					AND ($4::text IS NULL OR description ILIKE $4)
+					AND ($5::timestamptz IS NULL OR due_date >= $5)
+					AND ($6::timestamptz IS NULL OR due_date < $6)
+				FOR UPDATE
+			), updated AS (
+				UPDATE transactions t
+				SET category_id = $2, updated_at = now()
+				FROM matched m
+				WHERE t.id = m.id
+				RETURNING t.id, m.category_id AS previous_category_id
+			)
+			INSERT INTO recategorization_items (batch_id, transaction_id, previous_category_id)
+			SELECT $7, id, previous_category_id FROM updated
+		`
+		tag, err := tx.Exec(ctx, updateQuery,
+			batch.UserID,
+			batch.TargetCategoryID,
+			filter.CategoryID,
+			filter.likePattern(),
+			filter.From,
+			filter.To,
+			batch.Token,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to recategorize transactions: %w", err)
+		}
+
+		affected = tag.RowsAffected()
+		if affected == 0 {
+			return errNothingMatched
+		}
+		return nil
+	})
+	if errors.Is(err, errNothingMatched) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// Undo restores the previous categories of the batch, only where the transaction is still in the batch target category
+func (r *PostgresRepository) Undo(ctx context.Context, userID, token uuid.UUID, now time.Time) (int64, error) {
+	var restored int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		batchQuery := `
+			SELECT target_category_id, expires_at
+			FROM recategorization_batches
+			WHERE id = $1 AND user_id = $2
+			FOR UPDATE
+		`
+		var (
+			targetCategoryID uuid.UUID
+			expiresAt        time.Time
+		)
+		if err := tx.QueryRow(ctx, batchQuery, token, userID).Scan(&targetCategoryID, &expiresAt); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUndoTokenNotFound
+			}
+			return fmt.Errorf("failed to fetch recategorization batch: %w", err)
+		}
+		if !expiresAt.After(now) {
+			return ErrUndoTokenNotFound
+		}
+
+		restoreQuery := `
+			UPDATE transactions t
+			SET category_id = i.previous_category_id, updated_at = now()
+			FROM recategorization_items i
+			WHERE i.batch_id = $1
+				AND t.id = i.transaction_id
+				AND t.category_id = $2
+		`
+		tag, err := tx.Exec(ctx, restoreQuery, token, targetCategoryID)
+		if err != nil {
+			return fmt.Errorf("failed to restore transaction categories: %w", err)
+		}
+
+		// A batch is undone once, its items are deleted in cascade
+		if _, err := tx.Exec(ctx, `DELETE FROM recategorization_batches WHERE id = $1`, token); err != nil {
+			return fmt.Errorf("failed to delete recategorization batch: %w", err)
+		}
+
+		restored = tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return restored, nil
+}
+
+// DeleteCategory moves the transactions of the category and of its subcategories with a single UPDATE, which also
+// moves their archived history, before deleting the category, its subcategories going with it in cascade
+func (r *PostgresRepository) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, replacementID *uuid.UUID) (int64, error) {
+	var moved int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		lockQuery := `SELECT user_id FROM categories WHERE id = $1 AND (user_id = $2 OR user_id IS NULL) FOR UPDATE`
+		var ownerID *uuid.UUID
+		if err := tx.QueryRow(ctx, lockQuery, categoryID, userID).Scan(&ownerID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrCategoryNotFound.With("category_id", categoryID)
+			}
+			return fmt.Errorf("failed to lock category: %w", err)
+		}
+		if ownerID == nil {
+			return ErrDefaultCategoryNotDeletable
+		}
+
+		// deleted holds the category and its subcategories, all deleted with it
+		deletedCTE := `
+			WITH RECURSIVE deleted AS (
+				SELECT id FROM categories WHERE id = $1
+				UNION
+				SELECT c.id FROM categories c JOIN deleted d ON c.parent_id = d.id
+			)
+		`
+
+		if replacementID != nil {
+			replacementQuery := deletedCTE + `
+				SELECT
+					EXISTS (SELECT 1 FROM categories WHERE id = $2 AND (user_id = $3 OR user_id IS NULL)),
+					EXISTS (SELECT 1 FROM deleted WHERE id = $2)
+			`
+			var exists, deleted bool
+			if err := tx.QueryRow(ctx, replacementQuery, categoryID, *replacementID, userID).Scan(&exists, &deleted); err != nil {
+				return fmt.Errorf("failed to check replacement category: %w", err)
+			}
+			if !exists {
+				return ErrCategoryNotFound.With("category_id", *replacementID)
+			}
+			if deleted {
+				return ErrReplacementCategoryDeleted
+			}
+		}
+
+		moveQuery := deletedCTE + `
+			, moved AS (
+				UPDATE transactions
+				SET category_id = $2, updated_at = now()
+				WHERE category_id IN (SELECT id FROM deleted)
+				RETURNING id
+			), archived AS (
+				UPDATE archived_transactions
+				SET category_id = $2
+				WHERE category_id IN (SELECT id FROM deleted)
+			)
+			SELECT count(*) FROM moved
+		`
+		if err := tx.QueryRow(ctx, moveQuery, categoryID, replacementID).Scan(&moved); err != nil {
+			return fmt.Errorf("failed to move category transactions: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM categories WHERE id = $1`, categoryID); err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
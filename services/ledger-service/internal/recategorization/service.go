@@ -0,0 +1,143 @@
+package recategorization
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the recategorizations
+const (
+	auditRecategorized            = "transactions.recategorized"
+	auditRecategorizationUndone   = "transactions.recategorization_undone"
+	auditResourceRecategorization = "recategorization"
+	auditCategoryDeleted          = "category.deleted"
+	auditResourceCategory         = "category"
+)
+
+// RecategorizeParams holds all the required data for the Recategorize use case
+type RecategorizeParams struct {
+	UserID           uuid.UUID
+	Filter           Filter
+	TargetCategoryID uuid.UUID
+}
+
+// DeleteCategoryParams holds all the required data for the DeleteCategory use case
+type DeleteCategoryParams struct {
+	UserID        uuid.UUID
+	CategoryID    uuid.UUID
+	ReplacementID *uuid.UUID // ReplacementID is nil to leave the transactions uncategorized
+}
+
+// Service moves transactions between categories in bulk, keeping each move undoable for UndoTTL
+type Service struct {
+	repo    Repository
+	auditor *audit.Logger
+	clock   clock.Clock
+}
+
+// NewService creates a new instance of the recategorization Service
+func NewService(repo Repository, auditor *audit.Logger, clock clock.Clock) *Service {
+	return &Service{
+		repo:    repo,
+		auditor: auditor,
+		clock:   clock,
+	}
+}
+
+// Recategorize is the use case for moving every transaction matching the filter to the target category
+func (s *Service) Recategorize(ctx context.Context, params RecategorizeParams) (*Result, error) {
+	if err := params.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	if params.Filter.CategoryID != nil && *params.Filter.CategoryID == params.TargetCategoryID {
+		return nil, ErrSameCategory
+	}
+
+	exists, err := s.repo.CategoryExists(ctx, params.UserID, params.TargetCategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find target category: %w", err)
+	}
+	if !exists {
+		return nil, ErrCategoryNotFound.With("category_id", params.TargetCategoryID)
+	}
+
+	now := s.clock.Now()
+	batch := &Batch{
+		Token:            uuid.New(),
+		UserID:           params.UserID,
+		TargetCategoryID: params.TargetCategoryID,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(UndoTTL),
+	}
+
+	affected, err := s.repo.Recategorize(ctx, batch, params.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recategorize transactions: %w", err)
+	}
+	if affected == 0 {
+		return &Result{}, nil
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditRecategorized,
+		ResourceType: auditResourceRecategorization,
+		ResourceID:   batch.Token.String(),
+		Metadata: map[string]string{
+			"target_category_id": params.TargetCategoryID.String(),
+			"affected":           strconv.FormatInt(affected, 10),
+		},
+	})
+
+	return &Result{Affected: affected, Batch: batch}, nil
+}
+
+// Undo is the use case for restoring the categories changed by a recategorization, returning how many were restored
+func (s *Service) Undo(ctx context.Context, userID, token uuid.UUID) (int64, error) {
+	restored, err := s.repo.Undo(ctx, userID, token, s.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to undo recategorization: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditRecategorizationUndone,
+		ResourceType: auditResourceRecategorization,
+		ResourceID:   token.String(),
+		Metadata:     map[string]string{"restored": strconv.FormatInt(restored, 10)},
+	})
+
+	return restored, nil
+}
+
+// DeleteCategory is the use case for deleting a category of the user, moving its transactions to the replacement,
+// returning how many were moved
+func (s *Service) DeleteCategory(ctx context.Context, params DeleteCategoryParams) (int64, error) {
+	if params.ReplacementID != nil && *params.ReplacementID == params.CategoryID {
+		return 0, ErrReplacementCategoryDeleted
+	}
+
+	moved, err := s.repo.DeleteCategory(ctx, params.UserID, params.CategoryID, params.ReplacementID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	replacement := Uncategorized
+	if params.ReplacementID != nil {
+		replacement = params.ReplacementID.String()
+	}
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditCategoryDeleted,
+		ResourceType: auditResourceCategory,
+		ResourceID:   params.CategoryID.String(),
+		Metadata: map[string]string{
+			"replacement_category_id": replacement,
+			"moved":                   strconv.FormatInt(moved, 10),
+		},
+	})
+
+	return moved, nil
+}
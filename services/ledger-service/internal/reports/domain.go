@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidReportRange  = errx.New(errx.CategoryValidation, "INVALID_REPORT_RANGE", "the report range must start before it ends and span at most the allowed period")
+	ErrInvalidExportFormat = errx.New(errx.CategoryValidation, "INVALID_EXPORT_FORMAT", "the export format must be csv or json")
+	ErrInvalidWeekStart    = errx.New(errx.CategoryValidation, "INVALID_WEEK_START", "the week start must be sunday or monday")
+)
+
+// maxReportRange caps the period a single report can cover
+const maxReportRange = 5 * 366 * 24 * time.Hour
+
+// Repository computes the reports straight from the ledger tables
+type Repository interface {
+	// TagSpending sums the expenses due within [from, to) by tag and currency, ordered by currency, spending and tag
+	TagSpending(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]TagSpending, error)
+	// EachCategorySpending calls fn with the expenses due within [from, to) summed by category and currency as they are read,
+	// the spending in leadCurrency first, then ordered by currency, spending and category
+	EachCategorySpending(ctx context.Context, userID uuid.UUID, from, to time.Time, leadCurrency string, fn func(CategorySpending) error) error
+	// WeekSpending sums the expenses due within [from, to) by week, the weeks starting on weekStart, and currency,
+	// ordered by week with the spending in leadCurrency first
+	WeekSpending(ctx context.Context, userID uuid.UUID, from, to time.Time, weekStart time.Weekday, leadCurrency string) ([]WeekSpending, error)
+}
+
+// TagSpending is what was spent in a currency on the transactions of a tag
+// A transaction counts toward each of its tags, so the spending of the tags may add up to more than what was spent
+type TagSpending struct {
+	Tag          string
+	Spent        money.Money // Spent is positive, the sum of the expenses of the tag
+	Transactions int
+}
+
+// TagReport is the spending by tag over a period
+type TagReport struct {
+	From         time.Time
+	To           time.Time     // To is the last day of the period, included
+	Tags         []TagSpending // Tags in the base currency come first
+	BaseCurrency string        // BaseCurrency is the currency the user's reports lead with
+	Locale       string        // Locale formats the amounts, the pt-BR conventions when empty
+}
+
+// CategorySpending is what was spent in a currency on the transactions of a category
+// The expenses without category are summed together, with neither CategoryID nor Category
+type CategorySpending struct {
+	CategoryID   *uuid.UUID
+	Category     string
+	Spent        money.Money // Spent is positive, the sum of the expenses of the category
+	Transactions int
+}
+
+// SpendingReport is the spending by category over a period
+type SpendingReport struct {
+	From         time.Time
+	To           time.Time          // To is the last day of the period, included
+	Categories   []CategorySpending // Categories in the base currency come first
+	BaseCurrency string             // BaseCurrency is the currency the user's reports lead with
+	Locale       string             // Locale formats the amounts, the pt-BR conventions when empty
+}
+
+// WeekSpending is what was spent in a currency on the transactions due within a week
+type WeekSpending struct {
+	Start        time.Time   // Start is the first day of the week, which may come before the report when the week is partial
+	Spent        money.Money // Spent is positive, the sum of the expenses of the week
+	Transactions int
+}
+
+// WeeklyReport is the spending by week over a period, the first and last weeks counting only their days within it
+type WeeklyReport struct {
+	From         time.Time
+	To           time.Time      // To is the last day of the period, included
+	WeekStart    time.Weekday   // WeekStart is the day the weeks start on, the user's own unless the request chose another
+	Weeks        []WeekSpending // Weeks in the base currency come first within each week
+	BaseCurrency string         // BaseCurrency is the currency the user's reports lead with
+	Locale       string         // Locale formats the amounts, the pt-BR conventions when empty
+}
+
+// ExportFormat is the file format the spending report is exported in
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// ParseExportFormat validates the export format, defaulting to CSV when empty
+func ParseExportFormat(raw string) (ExportFormat, error) {
+	switch format := ExportFormat(raw); format {
+	case "":
+		return ExportFormatCSV, nil
+	case ExportFormatCSV, ExportFormatJSON:
+		return format, nil
+	default:
+		return "", ErrInvalidExportFormat.With("format", raw)
+	}
+}
+
+// ParseWeekStart validates the day the weeks of a report start on, nil when empty to follow the user's preference
+func ParseWeekStart(raw string) (*time.Weekday, error) {
+	var weekStart time.Weekday
+	switch strings.ToLower(raw) {
+	case "":
+		return nil, nil
+	case "sunday":
+		weekStart = time.Sunday
+	case "monday":
+		weekStart = time.Monday
+	default:
+		return nil, ErrInvalidWeekStart.With("week_start", raw)
+	}
+	return &weekStart, nil
+}
+
+// weekStartName returns how the day the weeks start on is written in the responses (e.g., "monday")
+func weekStartName(weekStart time.Weekday) string {
+	return strings.ToLower(weekStart.String())
+}
@@ -0,0 +1,355 @@
+package reports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ReportsHandler holds dependencies for report-related HTTP handlers
+type ReportsHandler struct {
+	reportsService *Service
+}
+
+// NewReportsHandler creates a new instance of ReportsHandler
+func NewReportsHandler(reportsService *Service) *ReportsHandler {
+	return &ReportsHandler{reportsService: reportsService}
+}
+
+// RegisterRoutes sets up the API routes for the reports module
+func (h *ReportsHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	reportsGroup := apiRouteGroup.Group("/reports")
+
+	reportsGroup.GET("/tags", h.tagReportHandler)
+	reportsGroup.GET("/spending", h.spendingReportHandler)
+	reportsGroup.GET("/spending/export", h.exportSpendingHandler)
+	reportsGroup.GET("/spending/weekly", h.weeklyReportHandler)
+}
+
+// TagSpendingResponse defines the spending of a tag in a currency returned by the API
+// Spent is expressed in minor units of the currency, SpentFormatted in the locale of the user
+type TagSpendingResponse struct {
+	Tag            string `json:"tag"`
+	Currency       string `json:"currency"`
+	Spent          int64  `json:"spent"`
+	SpentFormatted string `json:"spent_formatted"`
+	Transactions   int    `json:"transactions"`
+}
+
+// TagReportResponse defines the structure of the spending by tag returned by the API
+// A transaction counts toward each of its tags, so the tags may add up to more than what was spent
+type TagReportResponse struct {
+	From         string                `json:"from"` // From and To are formatted as YYYY-MM-DD, both included
+	To           string                `json:"to"`
+	BaseCurrency string                `json:"base_currency"` // Tags in the base currency come first
+	Tags         []TagSpendingResponse `json:"tags"`
+}
+
+// tagReportHandler handles the HTTP request for the spending by tag of the user (e.g., ?from=2025-01-01&to=2025-01-31)
+func (h *ReportsHandler) tagReportHandler(c echo.Context) error {
+	from, err := dateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := dateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.reportsService.TagReport(c.Request().Context(), userID, from, to)
+	if err != nil {
+		return err
+	}
+
+	resp := TagReportResponse{
+		From:         report.From.Format(time.DateOnly),
+		To:           report.To.Format(time.DateOnly),
+		BaseCurrency: report.BaseCurrency,
+		Tags:         make([]TagSpendingResponse, len(report.Tags)),
+	}
+	for i, tag := range report.Tags {
+		resp.Tags[i] = TagSpendingResponse{
+			Tag:            tag.Tag,
+			Currency:       tag.Spent.Currency,
+			Spent:          tag.Spent.Amount,
+			SpentFormatted: tag.Spent.Format(report.Locale),
+			Transactions:   tag.Transactions,
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// CategorySpendingResponse defines the spending of a category in a currency returned by the API
+// The expenses without category are returned together with neither category_id nor category
+type CategorySpendingResponse struct {
+	CategoryID     *uuid.UUID `json:"category_id"`
+	Category       string     `json:"category"`
+	Currency       string     `json:"currency"`
+	Spent          int64      `json:"spent"`
+	SpentFormatted string     `json:"spent_formatted"`
+	Transactions   int        `json:"transactions"`
+}
+
+// SpendingReportResponse defines the structure of the spending by category returned by the API
+// Categories is written last, the JSON export streaming the categories after the rest of the report
+type SpendingReportResponse struct {
+	From         string                     `json:"from"` // From and To are formatted as YYYY-MM-DD, both included
+	To           string                     `json:"to"`
+	BaseCurrency string                     `json:"base_currency"` // Categories in the base currency come first
+	Categories   []CategorySpendingResponse `json:"categories"`
+}
+
+// toCategorySpendingResponse converts the spending of a category, formatting its amount in the locale of the report
+func toCategorySpendingResponse(category CategorySpending, locale string) CategorySpendingResponse {
+	return CategorySpendingResponse{
+		CategoryID:     category.CategoryID,
+		Category:       category.Category,
+		Currency:       category.Spent.Currency,
+		Spent:          category.Spent.Amount,
+		SpentFormatted: category.Spent.Format(locale),
+		Transactions:   category.Transactions,
+	}
+}
+
+// spendingReportHandler handles the HTTP request for the spending by category of the user (e.g., ?from=2025-01-01&to=2025-01-31)
+func (h *ReportsHandler) spendingReportHandler(c echo.Context) error {
+	from, err := dateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := dateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.reportsService.SpendingReport(c.Request().Context(), userID, from, to)
+	if err != nil {
+		return err
+	}
+
+	resp := SpendingReportResponse{
+		From:         report.From.Format(time.DateOnly),
+		To:           report.To.Format(time.DateOnly),
+		BaseCurrency: report.BaseCurrency,
+		Categories:   make([]CategorySpendingResponse, len(report.Categories)),
+	}
+	for i, category := range report.Categories {
+		resp.Categories[i] = toCategorySpendingResponse(category, report.Locale)
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// WeekSpendingResponse defines the spending of a week in a currency returned by the API
+type WeekSpendingResponse struct {
+	WeekStart      string `json:"week_start"` // WeekStart and WeekEnd are formatted as YYYY-MM-DD, both included
+	WeekEnd        string `json:"week_end"`
+	Currency       string `json:"currency"`
+	Spent          int64  `json:"spent"`
+	SpentFormatted string `json:"spent_formatted"`
+	Transactions   int    `json:"transactions"`
+}
+
+// WeeklyReportResponse defines the structure of the spending by week returned by the API
+// The first and last weeks may start before from or end after to, only their days within the report being counted
+type WeeklyReportResponse struct {
+	From         string                 `json:"from"` // From and To are formatted as YYYY-MM-DD, both included
+	To           string                 `json:"to"`
+	BaseCurrency string                 `json:"base_currency"` // Weeks in the base currency come first within each week
+	WeekStartsOn string                 `json:"week_starts_on"`
+	Weeks        []WeekSpendingResponse `json:"weeks"`
+}
+
+// weeklyReportHandler handles the HTTP request for the spending by week of the user
+// (e.g., ?from=2025-01-01&to=2025-03-31&week_start=monday), the weeks starting on the user's preferred day when week_start is omitted
+func (h *ReportsHandler) weeklyReportHandler(c echo.Context) error {
+	from, err := dateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := dateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+	weekStart, err := ParseWeekStart(c.QueryParam("week_start"))
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.reportsService.WeeklyReport(c.Request().Context(), userID, from, to, weekStart)
+	if err != nil {
+		return err
+	}
+
+	resp := WeeklyReportResponse{
+		From:         report.From.Format(time.DateOnly),
+		To:           report.To.Format(time.DateOnly),
+		BaseCurrency: report.BaseCurrency,
+		WeekStartsOn: weekStartName(report.WeekStart),
+		Weeks:        make([]WeekSpendingResponse, len(report.Weeks)),
+	}
+	for i, week := range report.Weeks {
+		resp.Weeks[i] = WeekSpendingResponse{
+			WeekStart:      week.Start.Format(time.DateOnly),
+			WeekEnd:        week.Start.AddDate(0, 0, 6).Format(time.DateOnly),
+			Currency:       week.Spent.Currency,
+			Spent:          week.Spent.Amount,
+			SpentFormatted: week.Spent.Format(report.Locale),
+			Transactions:   week.Transactions,
+		}
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// exportSpendingHandler handles the HTTP request for downloading the spending by category of the user
+// as a CSV or JSON file (e.g., ?format=csv&from=2025-01-01&to=2025-12-31), streamed as the categories are read
+func (h *ReportsHandler) exportSpendingHandler(c echo.Context) error {
+	format, err := ParseExportFormat(c.QueryParam("format"))
+	if err != nil {
+		return err
+	}
+	from, err := dateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := dateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	export, err := h.reportsService.ExportSpending(c.Request().Context(), userID, from, to)
+	if err != nil {
+		return err
+	}
+
+	// The file is sent outside the success envelope, so it opens as is in a spreadsheet or script
+	filename := fmt.Sprintf("fintrack-spending-%s-%s.%s", export.From.Format(time.DateOnly), export.To.Format(time.DateOnly), format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == ExportFormatJSON {
+		return writeSpendingJSON(c, export)
+	}
+	return writeSpendingCSV(c, export)
+}
+
+// writeSpendingCSV streams the export as CSV, with the amounts as decimal numbers in the currency of each row
+func writeSpendingCSV(c echo.Context, export *SpendingExport) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	if err := w.Write([]string{"category_id", "category", "currency", "spent", "transactions"}); err != nil {
+		return err
+	}
+	err := export.Each(c.Request().Context(), func(category CategorySpending) error {
+		var categoryID string
+		if category.CategoryID != nil {
+			categoryID = category.CategoryID.String()
+		}
+		return w.Write([]string{
+			categoryID,
+			category.Category,
+			category.Spent.Currency,
+			category.Spent.Decimal(),
+			strconv.Itoa(category.Transactions),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// writeSpendingJSON streams the export as a SpendingReportResponse, writing each category as it is read
+func writeSpendingJSON(c echo.Context, export *SpendingExport) error {
+	head, err := json.Marshal(SpendingReportResponse{
+		From:         export.From.Format(time.DateOnly),
+		To:           export.To.Format(time.DateOnly),
+		BaseCurrency: export.BaseCurrency,
+	})
+	if err != nil {
+		return err
+	}
+	// The categories are appended to the report in place of its closing `"categories":null}`
+	head = append(head[:len(head)-len(`null}`)], '[')
+
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c.Response().WriteHeader(http.StatusOK)
+	if _, err := c.Response().Write(head); err != nil {
+		return err
+	}
+
+	first := true
+	err = export.Each(c.Request().Context(), func(category CategorySpending) error {
+		row, err := json.Marshal(toCategorySpendingResponse(category, export.Locale))
+		if err != nil {
+			return err
+		}
+		if !first {
+			row = append([]byte{','}, row...)
+		}
+		first = false
+		_, err = c.Response().Write(row)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Response().Write([]byte("]}"))
+	return err
+}
+
+// dateQueryParam parses an optional YYYY-MM-DD query parameter, returning the zero time when it is absent
+func dateQueryParam(c echo.Context, name string) (time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+" date format, expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
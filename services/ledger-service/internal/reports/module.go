@@ -0,0 +1,30 @@
+package reports
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the reports repository, service and handler from the shared dependencies
+type Module struct {
+	handler *ReportsHandler
+}
+
+// NewModule creates the reports module, whose reports are computed in Postgres (on the read replica when configured)
+func NewModule(deps module.Deps) *Module {
+	reportsSvc := NewService(NewPostgresRepository(deps.Postgres), deps.Periods, deps.Display, deps.Clock)
+
+	return &Module{handler: NewReportsHandler(reportsSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "reports"
+}
+
+// RegisterRoutes mounts the reports routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
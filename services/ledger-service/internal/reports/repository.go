@@ -0,0 +1,174 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/postgres"
+	"github.com/google/uuid"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+// Reports only read, so they are computed on the read pool, which may be a replica
+type PostgresRepository struct {
+	reads postgres.ReadRouter
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(reads postgres.ReadRouter) *PostgresRepository {
+	return &PostgresRepository{reads: reads}
+}
+
+// TagSpending sums the expenses (stored as negative amounts) due within [from, to) by tag and account currency
+// The archived transactions count too, their tags being kept by name rather than linked
+func (r *PostgresRepository) TagSpending(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]TagSpending, error) {
+	query := `
+		-- name: tagSpending
+		SELECT t.tag, a.currency, SUM(-t.amount_in_cents), COUNT(*)
+		FROM (
+			SELECT tg.name AS tag, t.account_id, t.amount_in_cents
+			FROM tags tg
+			JOIN transaction_tags tt ON tt.tag_id = tg.id
+			JOIN transactions t ON t.id = tt.transaction_id
+			WHERE tg.user_id = $1
+				AND t.user_id = $1
+				AND t.type = 'EXPENSE'
+				AND t.due_date >= $2 AND t.due_date < $3
+			UNION ALL
+			SELECT tags.tag, t.account_id, t.amount_in_cents
+			FROM archived_transactions t, unnest(t.tags) AS tags(tag)
+			WHERE t.user_id = $1
+				AND t.type = 'EXPENSE'
+				AND t.due_date >= $2 AND t.due_date < $3
+		) t
+		JOIN accounts a ON a.id = t.account_id
+		GROUP BY t.tag, a.currency
+		ORDER BY a.currency, SUM(-t.amount_in_cents) DESC, t.tag
+	`
+
+	rows, err := r.reads.ReadPool().Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag spending: %w", err)
+	}
+	defer rows.Close()
+
+	spending := make([]TagSpending, 0)
+	for rows.Next() {
+		var (
+			tag, currency string
+			spent         int64
+			count         int
+		)
+		if err := rows.Scan(&tag, &currency, &spent, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag spending row: %w", err)
+		}
+		spending = append(spending, TagSpending{Tag: tag, Spent: money.New(spent, currency), Transactions: count})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag spending rows: %w", err)
+	}
+
+	return spending, nil
+}
+
+// EachCategorySpending sums the expenses (stored as negative amounts) due within [from, to) by category and account currency,
+// archived ones included, streaming the rows to fn so an export does not hold the whole report in memory
+func (r *PostgresRepository) EachCategorySpending(ctx context.Context, userID uuid.UUID, from, to time.Time, leadCurrency string, fn func(CategorySpending) error) error {
+	query := `
+		-- name: categorySpending
+		SELECT c.id, COALESCE(c.name, ''), a.currency, SUM(-t.amount_in_cents), COUNT(*)
+		FROM (
+			SELECT account_id, user_id, category_id, type, amount_in_cents, due_date FROM transactions
+			UNION ALL
+			SELECT account_id, user_id, category_id, type, amount_in_cents, due_date FROM archived_transactions
+		) t
+		JOIN accounts a ON a.id = t.account_id
+		LEFT JOIN categories c ON c.id = t.category_id
+		WHERE t.user_id = $1
+			AND t.type = 'EXPENSE'
+			AND t.due_date >= $2 AND t.due_date < $3
+		GROUP BY c.id, c.name, a.currency
+		ORDER BY a.currency <> $4, a.currency, SUM(-t.amount_in_cents) DESC, c.name NULLS LAST
+	`
+
+	rows, err := r.reads.ReadPool().Query(ctx, query, userID, from, to, leadCurrency)
+	if err != nil {
+		return fmt.Errorf("failed to query category spending: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			categoryID         *uuid.UUID
+			category, currency string
+			spent              int64
+			count              int
+		)
+		if err := rows.Scan(&categoryID, &category, &currency, &spent, &count); err != nil {
+			return fmt.Errorf("failed to scan category spending row: %w", err)
+		}
+		spending := CategorySpending{CategoryID: categoryID, Category: category, Spent: money.New(spent, currency), Transactions: count}
+		if err := fn(spending); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating category spending rows: %w", err)
+	}
+
+	return nil
+}
+
+// WeekSpending sums the expenses (stored as negative amounts) due within [from, to) by week and account currency,
+// archived ones included
+// Postgres truncates to weeks starting on Monday, so the due dates are shifted by the days between weekStart and Monday
+// before being truncated and shifted back
+func (r *PostgresRepository) WeekSpending(ctx context.Context, userID uuid.UUID, from, to time.Time, weekStart time.Weekday, leadCurrency string) ([]WeekSpending, error) {
+	query := `
+		-- name: weekSpending
+		SELECT
+			(date_trunc('week', (t.due_date AT TIME ZONE 'UTC') + make_interval(days => $4)) - make_interval(days => $4))::date AS week,
+			a.currency, SUM(-t.amount_in_cents), COUNT(*)
+		FROM (
+			SELECT account_id, user_id, type, amount_in_cents, due_date FROM transactions
+			UNION ALL
+			SELECT account_id, user_id, type, amount_in_cents, due_date FROM archived_transactions
+		) t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.user_id = $1
+			AND t.type = 'EXPENSE'
+			AND t.due_date >= $2 AND t.due_date < $3
+		GROUP BY week, a.currency
+		ORDER BY week, a.currency <> $5, a.currency
+	`
+
+	shift := (int(time.Monday) - int(weekStart) + 7) % 7
+	rows, err := r.reads.ReadPool().Query(ctx, query, userID, from, to, shift, leadCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query week spending: %w", err)
+	}
+	defer rows.Close()
+
+	spending := make([]WeekSpending, 0)
+	for rows.Next() {
+		var (
+			start    time.Time
+			currency string
+			spent    int64
+			count    int
+		)
+		if err := rows.Scan(&start, &currency, &spent, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan week spending row: %w", err)
+		}
+		spending = append(spending, WeekSpending{Start: start, Spent: money.New(spent, currency), Transactions: count})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating week spending rows: %w", err)
+	}
+
+	return spending, nil
+}
@@ -0,0 +1,176 @@
+package reports
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/google/uuid"
+)
+
+// Service builds the spending reports of a user
+type Service struct {
+	repo    Repository
+	periods module.AccountingPeriods
+	display module.UserPreferences
+	clock   clock.Clock
+}
+
+// NewService creates a new instance of the reports Service, reports follow the display preferences of each user
+func NewService(repo Repository, periods module.AccountingPeriods, display module.UserPreferences, clock clock.Clock) *Service {
+	return &Service{repo: repo, periods: periods, display: display, clock: clock}
+}
+
+// TagReport is the use case for reporting the spending by tag between two days, both included
+// The period defaults to the current financial month of the user up to today
+func (s *Service) TagReport(ctx context.Context, userID uuid.UUID, from, to time.Time) (*TagReport, error) {
+	from, to, err := s.reportRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.display.DisplayPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find display preferences: %w", err)
+	}
+
+	tags, err := s.repo.TagSpending(ctx, userID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute tag spending: %w", err)
+	}
+	leadWithCurrency(tags, prefs.BaseCurrency)
+
+	return &TagReport{From: from, To: to, Tags: tags, BaseCurrency: prefs.BaseCurrency, Locale: prefs.Locale}, nil
+}
+
+// SpendingReport is the use case for reporting the spending by category between two days, both included
+// The period defaults to the current financial month of the user up to today
+func (s *Service) SpendingReport(ctx context.Context, userID uuid.UUID, from, to time.Time) (*SpendingReport, error) {
+	export, err := s.ExportSpending(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := export.SpendingReport
+	report.Categories = make([]CategorySpending, 0)
+	err = export.Each(ctx, func(category CategorySpending) error {
+		report.Categories = append(report.Categories, category)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// SpendingExport is a spending report whose categories are read one at a time, so they can be written as they arrive
+type SpendingExport struct {
+	SpendingReport // SpendingReport holds the period and preferences of the report, without its categories
+
+	userID uuid.UUID
+	repo   Repository
+}
+
+// Each calls fn with the spending of each category in the order of the report, stopping at the first error
+func (e *SpendingExport) Each(ctx context.Context, fn func(CategorySpending) error) error {
+	err := e.repo.EachCategorySpending(ctx, e.userID, e.From, e.To.AddDate(0, 0, 1), e.BaseCurrency, fn)
+	if err != nil {
+		return fmt.Errorf("failed to compute category spending: %w", err)
+	}
+	return nil
+}
+
+// ExportSpending is the use case for exporting the spending by category between two days, both included
+// The range and preferences are resolved upfront, so they are rejected before anything is written
+func (s *Service) ExportSpending(ctx context.Context, userID uuid.UUID, from, to time.Time) (*SpendingExport, error) {
+	from, to, err := s.reportRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.display.DisplayPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find display preferences: %w", err)
+	}
+
+	return &SpendingExport{
+		SpendingReport: SpendingReport{From: from, To: to, BaseCurrency: prefs.BaseCurrency, Locale: prefs.Locale},
+		userID:         userID,
+		repo:           s.repo,
+	}, nil
+}
+
+// WeeklyReport is the use case for reporting the spending by week between two days, both included
+// The weeks start on the day chosen by the user (or following their locale) unless weekStart is given for this request
+func (s *Service) WeeklyReport(ctx context.Context, userID uuid.UUID, from, to time.Time, weekStart *time.Weekday) (*WeeklyReport, error) {
+	from, to, err := s.reportRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.display.DisplayPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find display preferences: %w", err)
+	}
+	if weekStart == nil {
+		weekStart = &prefs.WeekStart
+	}
+
+	weeks, err := s.repo.WeekSpending(ctx, userID, from, to.AddDate(0, 0, 1), *weekStart, prefs.BaseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute week spending: %w", err)
+	}
+
+	return &WeeklyReport{
+		From:         from,
+		To:           to,
+		WeekStart:    *weekStart,
+		Weeks:        weeks,
+		BaseCurrency: prefs.BaseCurrency,
+		Locale:       prefs.Locale,
+	}, nil
+}
+
+// reportRange resolves the days a report covers, defaulting to the current financial month of the user up to today
+func (s *Service) reportRange(ctx context.Context, userID uuid.UUID, from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	to = day(to)
+	if from.IsZero() {
+		period, err := s.periods.AccountingPeriod(ctx, userID)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to find accounting period: %w", err)
+		}
+		from, _ = period.RangeIn(to, time.UTC)
+	}
+	from = day(from)
+
+	if from.After(to) || to.Sub(from) > maxReportRange {
+		return time.Time{}, time.Time{}, ErrInvalidReportRange.With("from", from.Format(time.DateOnly)).With("to", to.Format(time.DateOnly))
+	}
+	return from, to, nil
+}
+
+// leadWithCurrency moves the spending in the currency ahead of the other currencies, keeping the order within each currency
+func leadWithCurrency(tags []TagSpending, currency string) {
+	rank := func(t TagSpending) int {
+		if t.Spent.Currency == currency {
+			return 0
+		}
+		return 1
+	}
+	slices.SortStableFunc(tags, func(a, b TagSpending) int {
+		return cmp.Compare(rank(a), rank(b))
+	})
+}
+
+// day truncates a time to its UTC day
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,61 @@
+package retention
+
+import (
+	"context"
+	"time"
+)
+
+// Rule names a kind of data the retention policy removes once it is old enough
+type Rule string
+
+const (
+	RuleArchivedAccounts Rule = "archived_accounts" // RuleArchivedAccounts deletes the accounts archived for longer than the retention, with their transactions
+	RuleObservations     Rule = "observations"      // RuleObservations trims the long observations of old transactions
+	RuleAuditLogs        Rule = "audit_logs"        // RuleAuditLogs deletes the old audit records
+)
+
+// Policy tells how long each kind of data is kept, a zero value disabling its rule
+type Policy struct {
+	ArchivedAccounts  time.Duration // ArchivedAccounts is how long archived accounts are kept, counted from their archiving
+	ObservationsYears int           // ObservationsYears is how many years the observations of a transaction are kept whole, counted from its due date
+	ObservationLength int           // ObservationLength is how many characters of an observation are kept once trimmed
+	AuditLogsYears    int           // AuditLogsYears is how many years audit records are kept
+}
+
+// Cutoff is an enabled rule and the time before which its data is removed
+type Cutoff struct {
+	Rule   Rule
+	Before time.Time
+	Length int // Length is how many characters RuleObservations keeps
+}
+
+// Cutoffs returns the enabled rules with their cutoff relative to now
+func (p Policy) Cutoffs(now time.Time) []Cutoff {
+	var cutoffs []Cutoff
+	if p.ArchivedAccounts > 0 {
+		cutoffs = append(cutoffs, Cutoff{Rule: RuleArchivedAccounts, Before: now.Add(-p.ArchivedAccounts)})
+	}
+	if p.ObservationsYears > 0 && p.ObservationLength > 0 {
+		cutoffs = append(cutoffs, Cutoff{Rule: RuleObservations, Before: now.AddDate(-p.ObservationsYears, 0, 0), Length: p.ObservationLength})
+	}
+	if p.AuditLogsYears > 0 {
+		cutoffs = append(cutoffs, Cutoff{Rule: RuleAuditLogs, Before: now.AddDate(-p.AuditLogsYears, 0, 0)})
+	}
+	return cutoffs
+}
+
+// Result is what a rule removed in a run, or would remove in a dry run
+type Result struct {
+	Rule     Rule
+	Before   time.Time
+	Affected int64 // Affected counts the deleted accounts or audit records, or the trimmed observations
+	DryRun   bool
+}
+
+// Repository counts and removes the data of each rule
+type Repository interface {
+	// Count returns how many rows the rule would affect for the cutoff, without changing anything
+	Count(ctx context.Context, cutoff Cutoff) (int64, error)
+	// Apply removes the data of the rule older than the cutoff, returning how many rows it affected
+	Apply(ctx context.Context, cutoff Cutoff) (int64, error)
+}
@@ -0,0 +1,114 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// Count returns how many rows the rule would affect for the cutoff
+func (r *PostgresRepository) Count(ctx context.Context, cutoff Cutoff) (int64, error) {
+	var query string
+	args := []any{cutoff.Before}
+	switch cutoff.Rule {
+	case RuleArchivedAccounts:
+		query = `SELECT COUNT(*) FROM accounts WHERE archived_at < $1`
+	case RuleObservations:
+		query = `SELECT COUNT(*) FROM transactions WHERE due_date < $1 AND length(observation) > $2 + 1 AND observation NOT LIKE 'enc:%'`
+		args = append(args, cutoff.Length)
+	case RuleAuditLogs:
+		query = `SELECT COUNT(*) FROM audit_logs WHERE occurred_at < $1`
+	default:
+		return 0, fmt.Errorf("unknown retention rule %q", cutoff.Rule)
+	}
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", cutoff.Rule, err)
+	}
+	return count, nil
+}
+
+// Apply removes the data of the rule older than the cutoff in a single transaction
+func (r *PostgresRepository) Apply(ctx context.Context, cutoff Cutoff) (int64, error) {
+	var affected int64
+	err := pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+		switch cutoff.Rule {
+		case RuleArchivedAccounts:
+			affected, err = deleteArchivedAccounts(ctx, tx, cutoff)
+		case RuleObservations:
+			affected, err = trimObservations(ctx, tx, cutoff)
+		case RuleAuditLogs:
+			affected, err = deleteAuditLogs(ctx, tx, cutoff)
+		default:
+			err = fmt.Errorf("unknown retention rule %q", cutoff.Rule)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// deleteArchivedAccounts deletes the expired archived accounts, their transactions first since they restrict the deletion
+// The other records of the accounts (positions, comments, transfers...) are deleted by cascade
+func deleteArchivedAccounts(ctx context.Context, tx pgx.Tx, cutoff Cutoff) (int64, error) {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM transactions
+		WHERE account_id IN (SELECT id FROM accounts WHERE archived_at < $1)
+	`, cutoff.Before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete transactions of archived accounts: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM accounts WHERE archived_at < $1`, cutoff.Before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived accounts: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// trimObservations keeps the beginning of the long observations of old transactions, followed by an ellipsis
+// which leaves them longer than the kept length, so they are not trimmed again by the next runs
+// The ledger rewrites the transactions of an account on every save, so a compressed copy would be written back
+// expanded, while a trimmed observation is simply kept. Encrypted observations can not be trimmed in the database
+// and are left whole, protected by the key of their user
+func trimObservations(ctx context.Context, tx pgx.Tx, cutoff Cutoff) (int64, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE transactions SET observation = left(observation, $2) || '…', updated_at = NOW()
+		WHERE due_date < $1 AND length(observation) > $2 + 1 AND observation NOT LIKE 'enc:%'
+	`, cutoff.Before, cutoff.Length)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trim observations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// deleteAuditLogs deletes the expired audit records, the only deletion the audit_logs trigger lets through
+func deleteAuditLogs(ctx context.Context, tx pgx.Tx, cutoff Cutoff) (int64, error) {
+	if _, err := tx.Exec(ctx, `SET LOCAL fintrack.audit_logs_retention = 'on'`); err != nil {
+		return 0, fmt.Errorf("failed to enable audit logs retention: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM audit_logs WHERE occurred_at < $1`, cutoff.Before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
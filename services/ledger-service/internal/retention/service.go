@@ -0,0 +1,90 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/config"
+)
+
+// Audit vocabulary of the retention policy, each applied rule leaving a record
+const (
+	auditRetentionApplied = "retention.applied"
+	auditResourceRule     = "retention_rule"
+)
+
+// PolicyFromConfig reads the retention policy, the archived accounts retention being the one announced by the ledger
+func PolicyFromConfig(cfg *config.Config) Policy {
+	return Policy{
+		ArchivedAccounts:  cfg.Ledger.ArchivedAccountRetention,
+		ObservationsYears: cfg.Jobs.Retention.ObservationsYears,
+		ObservationLength: cfg.Jobs.Retention.ObservationLength,
+		AuditLogsYears:    cfg.Jobs.Retention.AuditLogsYears,
+	}
+}
+
+// Service applies the retention policy, removing the data kept for longer than its rules allow
+type Service struct {
+	repo    Repository
+	policy  Policy
+	auditor *audit.Logger
+	clock   clock.Clock
+}
+
+// NewService creates a new instance of the retention Service
+func NewService(repo Repository, policy Policy, auditor *audit.Logger, clock clock.Clock) *Service {
+	return &Service{
+		repo:    repo,
+		policy:  policy,
+		auditor: auditor,
+		clock:   clock,
+	}
+}
+
+// Run applies every enabled rule of the policy, or only reports what each one would remove when dryRun is set
+// A failing rule stops the run, the rules applied before it stay applied
+func (s *Service) Run(ctx context.Context, dryRun bool) ([]Result, error) {
+	cutoffs := s.policy.Cutoffs(s.clock.Now())
+	results := make([]Result, 0, len(cutoffs))
+
+	for _, cutoff := range cutoffs {
+		result := Result{Rule: cutoff.Rule, Before: cutoff.Before, DryRun: dryRun}
+
+		var err error
+		if dryRun {
+			result.Affected, err = s.repo.Count(ctx, cutoff)
+		} else {
+			result.Affected, err = s.repo.Apply(ctx, cutoff)
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to apply retention rule %s: %w", cutoff.Rule, err)
+		}
+		results = append(results, result)
+
+		ctxlogger.GetLogger(ctx).Info("applied retention rule",
+			slog.String("rule", string(result.Rule)),
+			slog.Time("before", result.Before),
+			slog.Int64("affected", result.Affected),
+			slog.Bool("dry_run", dryRun),
+		)
+		if !dryRun && result.Affected > 0 {
+			s.auditor.Record(ctx, audit.Entry{
+				Action:       auditRetentionApplied,
+				ResourceType: auditResourceRule,
+				ResourceID:   string(result.Rule),
+				Metadata: map[string]string{
+					"before":   result.Before.UTC().Format(time.RFC3339),
+					"affected": strconv.FormatInt(result.Affected, 10),
+				},
+			})
+		}
+	}
+
+	return results, nil
+}
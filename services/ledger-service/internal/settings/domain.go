@@ -0,0 +1,68 @@
+package settings
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAccountingPeriod = errx.New(errx.CategoryValidation, "INVALID_ACCOUNTING_PERIOD", "the financial month starts either on a day from 1 to 28 or on one of the first 10 business days")
+
+const (
+	maxStartDay         = 28
+	maxStartBusinessDay = 10
+)
+
+// Repository persists the settings of the users
+type Repository interface {
+	// FindByUserID returns the settings of the user, nil when the user never changed them
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*Settings, error)
+	// Save inserts or replaces the settings of a user
+	Save(ctx context.Context, settings *Settings) error
+}
+
+// Settings are the preferences of a user that several modules follow
+type Settings struct {
+	UserID           uuid.UUID
+	AccountingPeriod clock.AccountingPeriod // AccountingPeriod drives the monthly figures: month flow, budgets, payee limits and reports
+	WeeklySummary    bool                   // WeeklySummary tells whether the user receives the weekly summary email
+	AnalyticsSharing bool                   // AnalyticsSharing tells whether the ledger changes of the user are exported to the data warehouse, off unless they opt in
+	UpdatedAt        time.Time
+}
+
+// DefaultSettings returns the settings of a user who never changed them: calendar months and the weekly summary
+func DefaultSettings(userID uuid.UUID) *Settings {
+	return &Settings{UserID: userID, WeeklySummary: true}
+}
+
+// SetAccountingPeriod changes when the financial month of the user starts, the zero period going back to calendar months
+// Credit card statements are not affected, they keep the closing day of the card
+func (s *Settings) SetAccountingPeriod(period clock.AccountingPeriod, clk clock.Clock) error {
+	if period.StartDay < 0 || period.StartDay > maxStartDay ||
+		period.StartBusinessDay < 0 || period.StartBusinessDay > maxStartBusinessDay ||
+		(period.StartDay > 0 && period.StartBusinessDay > 0) {
+		return ErrInvalidAccountingPeriod.With("max_start_day", maxStartDay).With("max_start_business_day", maxStartBusinessDay)
+	}
+	if period.IsCalendarMonth() {
+		period = clock.AccountingPeriod{}
+	}
+
+	s.AccountingPeriod = period
+	s.UpdatedAt = clk.Now()
+	return nil
+}
+
+// SetWeeklySummary subscribes the user to the weekly summary email or opts them out of it
+func (s *Settings) SetWeeklySummary(enabled bool, clk clock.Clock) {
+	s.WeeklySummary = enabled
+	s.UpdatedAt = clk.Now()
+}
+
+// SetAnalyticsSharing lets the ledger changes of the user be exported to the data warehouse or stops their export
+func (s *Settings) SetAnalyticsSharing(enabled bool, clk clock.Clock) {
+	s.AnalyticsSharing = enabled
+	s.UpdatedAt = clk.Now()
+}
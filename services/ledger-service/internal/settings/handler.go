@@ -0,0 +1,223 @@
+package settings
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// SettingsHandler holds dependencies for user settings HTTP handlers
+type SettingsHandler struct {
+	settingsService *Service
+	clock           clock.Clock
+}
+
+// NewSettingsHandler creates a new instance of SettingsHandler
+func NewSettingsHandler(settingsService *Service, clock clock.Clock) *SettingsHandler {
+	return &SettingsHandler{settingsService: settingsService, clock: clock}
+}
+
+// weeklySummaryDeprecation announces the weekly summary routes are replaced by the notification opt-ins of the preferences
+var weeklySummaryDeprecation = httpx.Deprecation{
+	Since:     time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+	Sunset:    time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+	Successor: "/api/v1/me/preferences",
+}
+
+// RegisterRoutes sets up the API routes for the settings module
+func (h *SettingsHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	settingsGroup := apiRouteGroup.Group("/settings")
+
+	settingsGroup.GET("/accounting-period", h.getAccountingPeriodHandler)
+	settingsGroup.PUT("/accounting-period", h.updateAccountingPeriodHandler)
+	settingsGroup.GET("/weekly-summary", h.getWeeklySummaryHandler, httpx.Deprecated(weeklySummaryDeprecation))
+	settingsGroup.PUT("/weekly-summary", h.updateWeeklySummaryHandler, httpx.Deprecated(weeklySummaryDeprecation))
+	settingsGroup.GET("/analytics-sharing", h.getAnalyticsSharingHandler)
+	settingsGroup.PUT("/analytics-sharing", h.updateAnalyticsSharingHandler)
+}
+
+// UpdateAccountingPeriodRequest defines the expected JSON body for changing when the financial month starts
+// Omitting both fields goes back to calendar months
+type UpdateAccountingPeriodRequest struct {
+	StartDay         int `json:"start_day" validate:"omitempty,min=1,max=28"`          // StartDay is the day of the month the financial month starts on (e.g., 5)
+	StartBusinessDay int `json:"start_business_day" validate:"omitempty,min=1,max=10"` // StartBusinessDay is the nth weekday it starts on (e.g., 5 for the 5th business day)
+}
+
+// UpdateWeeklySummaryRequest defines the expected JSON body for subscribing to the weekly summary email or opting out of it
+type UpdateWeeklySummaryRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// WeeklySummaryResponse defines the structure of the weekly summary subscription returned by the API
+type WeeklySummaryResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// UpdateAnalyticsSharingRequest defines the expected JSON body for opting in or out of the data warehouse export
+type UpdateAnalyticsSharingRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// AnalyticsSharingResponse defines the structure of the data warehouse export consent returned by the API
+type AnalyticsSharingResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AccountingPeriodResponse defines the structure of the financial month setting returned by the API
+type AccountingPeriodResponse struct {
+	StartDay         int        `json:"start_day,omitempty"`
+	StartBusinessDay int        `json:"start_business_day,omitempty"`
+	CalendarMonth    bool       `json:"calendar_month"`
+	CurrentFrom      string     `json:"current_from"` // CurrentFrom and CurrentTo are the days of the current period, formatted as YYYY-MM-DD, both included
+	CurrentTo        string     `json:"current_to"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// getAccountingPeriodHandler handles the HTTP request for finding when the user's financial month starts
+func (h *SettingsHandler) getAccountingPeriodHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.settingsService.GetSettings(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, ToAccountingPeriodResponse(settings, h.clock))
+}
+
+// updateAccountingPeriodHandler handles the HTTP request for changing when the user's financial month starts
+func (h *SettingsHandler) updateAccountingPeriodHandler(c echo.Context) error {
+	var req UpdateAccountingPeriodRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.settingsService.UpdateAccountingPeriod(c.Request().Context(), userID, clock.AccountingPeriod{
+		StartDay:         req.StartDay,
+		StartBusinessDay: req.StartBusinessDay,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, ToAccountingPeriodResponse(settings, h.clock))
+}
+
+// getWeeklySummaryHandler handles the HTTP request for finding whether the user receives the weekly summary email
+func (h *SettingsHandler) getWeeklySummaryHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.settingsService.GetSettings(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, WeeklySummaryResponse{Enabled: settings.WeeklySummary})
+}
+
+// updateWeeklySummaryHandler handles the HTTP request for subscribing to the weekly summary email or opting out of it
+func (h *SettingsHandler) updateWeeklySummaryHandler(c echo.Context) error {
+	var req UpdateWeeklySummaryRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.settingsService.UpdateWeeklySummary(c.Request().Context(), userID, *req.Enabled)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, WeeklySummaryResponse{Enabled: settings.WeeklySummary})
+}
+
+// getAnalyticsSharingHandler handles the HTTP request for finding whether the user's ledger changes are exported to the data warehouse
+func (h *SettingsHandler) getAnalyticsSharingHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.settingsService.GetSettings(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, AnalyticsSharingResponse{Enabled: settings.AnalyticsSharing})
+}
+
+// updateAnalyticsSharingHandler handles the HTTP request for opting in or out of the data warehouse export
+func (h *SettingsHandler) updateAnalyticsSharingHandler(c echo.Context) error {
+	var req UpdateAnalyticsSharingRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.settingsService.UpdateAnalyticsSharing(c.Request().Context(), userID, *req.Enabled)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, AnalyticsSharingResponse{Enabled: settings.AnalyticsSharing})
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// ToAccountingPeriodResponse maps the accounting period of the Settings to the public AccountingPeriodResponse DTO
+func ToAccountingPeriodResponse(s *Settings, clk clock.Clock) AccountingPeriodResponse {
+	period := s.AccountingPeriod
+	from, to := period.RangeIn(clk.Now(), time.UTC)
+
+	resp := AccountingPeriodResponse{
+		StartDay:         period.StartDay,
+		StartBusinessDay: period.StartBusinessDay,
+		CalendarMonth:    period.IsCalendarMonth(),
+		CurrentFrom:      from.Format(time.DateOnly),
+		CurrentTo:        to.AddDate(0, 0, -1).Format(time.DateOnly),
+	}
+	if !s.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &s.UpdatedAt
+	}
+	return resp
+}
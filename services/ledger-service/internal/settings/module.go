@@ -0,0 +1,44 @@
+package settings
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the settings repository, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *SettingsHandler
+}
+
+// NewModule creates the settings module, whose settings are stored in Postgres
+func NewModule(deps module.Deps) *Module {
+	settingsSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.Clock)
+
+	return &Module{
+		service: settingsSvc,
+		handler: NewSettingsHandler(settingsSvc, deps.Clock),
+	}
+}
+
+// AccountingPeriods returns the resolver of the users' financial month, handed to the modules through module.Deps
+func (m *Module) AccountingPeriods() module.AccountingPeriods {
+	return m.service
+}
+
+// Service returns the settings service, used by the preferences module to change the financial month and the weekly summary
+func (m *Module) Service() *Service {
+	return m.service
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "settings"
+}
+
+// RegisterRoutes mounts the settings routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
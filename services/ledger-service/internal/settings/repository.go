@@ -0,0 +1,71 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// FindByUserID retrieves the settings of a user, nil when the user never changed them
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	query := `
+		SELECT user_id, month_start_day, month_start_business_day, weekly_summary_enabled, analytics_sharing_enabled, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`
+
+	var settings Settings
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.AccountingPeriod.StartDay,
+		&settings.AccountingPeriod.StartBusinessDay,
+		&settings.WeeklySummary,
+		&settings.AnalyticsSharing,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch user settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// Save inserts or replaces the settings of a user
+func (r *PostgresRepository) Save(ctx context.Context, settings *Settings) error {
+	query := `
+		INSERT INTO user_settings (user_id, month_start_day, month_start_business_day, weekly_summary_enabled, analytics_sharing_enabled, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			month_start_day = EXCLUDED.month_start_day,
+			month_start_business_day = EXCLUDED.month_start_business_day,
+			weekly_summary_enabled = EXCLUDED.weekly_summary_enabled,
+			analytics_sharing_enabled = EXCLUDED.analytics_sharing_enabled,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	period := settings.AccountingPeriod
+	_, err := r.pool.Exec(ctx, query, settings.UserID, period.StartDay, period.StartBusinessDay, settings.WeeklySummary, settings.AnalyticsSharing, settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert user settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/google/uuid"
+)
+
+var _ module.AccountingPeriods = (*Service)(nil)
+
+// Service manages the settings of the users
+type Service struct {
+	repo  Repository
+	clock clock.Clock
+}
+
+// NewService creates a new instance of the settings Service
+func NewService(repo Repository, clock clock.Clock) *Service {
+	return &Service{
+		repo:  repo,
+		clock: clock,
+	}
+}
+
+// GetSettings is the use case for finding the user's settings, the defaults when never changed
+func (s *Service) GetSettings(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	settings, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find settings: %w", err)
+	}
+	if settings == nil {
+		return DefaultSettings(userID), nil
+	}
+	return settings, nil
+}
+
+// UpdateAccountingPeriod is the use case for changing the day the user's financial month starts on (e.g., payday)
+func (s *Service) UpdateAccountingPeriod(ctx context.Context, userID uuid.UUID, period clock.AccountingPeriod) (*Settings, error) {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.SetAccountingPeriod(period, s.clock); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateWeeklySummary is the use case for subscribing to the weekly summary email or opting out of it
+func (s *Service) UpdateWeeklySummary(ctx context.Context, userID uuid.UUID, enabled bool) (*Settings, error) {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings.SetWeeklySummary(enabled, s.clock)
+
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateAnalyticsSharing is the use case for opting in or out of the export of the user's ledger changes to the data warehouse
+func (s *Service) UpdateAnalyticsSharing(ctx context.Context, userID uuid.UUID, enabled bool) (*Settings, error) {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings.SetAnalyticsSharing(enabled, s.clock)
+
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save settings: %w", err)
+	}
+	return settings, nil
+}
+
+// AccountingPeriod returns the financial month of the user, for the modules computing monthly figures
+func (s *Service) AccountingPeriod(ctx context.Context, userID uuid.UUID) (clock.AccountingPeriod, error) {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return clock.AccountingPeriod{}, err
+	}
+	return settings.AccountingPeriod, nil
+}
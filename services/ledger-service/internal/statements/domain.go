@@ -0,0 +1,169 @@
+package statements
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrScheduleNotFound         = errx.New(errx.CategoryNotFound, "STATEMENT_SCHEDULE_NOT_FOUND", "no statement email is scheduled")
+	ErrStatementAccountsEmpty   = errx.New(errx.CategoryValidation, "STATEMENT_ACCOUNTS_EMPTY", "at least one account is required")
+	ErrTooManyStatementAccounts = errx.New(errx.CategoryValidation, "TOO_MANY_STATEMENT_ACCOUNTS", "the statement email covers too many accounts")
+	ErrInvalidStatementFormat   = errx.New(errx.CategoryValidation, "INVALID_STATEMENT_FORMAT", "the statement format must be pdf or csv")
+)
+
+// MaxAccountsPerSchedule bounds the accounts of a statement email, every one of them being attached
+const MaxAccountsPerSchedule = 20
+
+// AccountReader reads the accounts the statements cover, implemented by the ledger service
+type AccountReader interface {
+	// FindAccountByID returns an account of the user. An account of someone else fails with ledger.ErrForbidden when it
+	// is shared with the user and ledger.ErrAccountNotFound otherwise
+	FindAccountByID(ctx context.Context, userID, accountID uuid.UUID) (*ledger.Account, error)
+}
+
+// Repository persists the statement schedules and records the periods already sent
+type Repository interface {
+	// FindByUserID returns the schedule of the user, nil when they have none
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*Schedule, error)
+	// FindAll returns the schedules of every user
+	FindAll(ctx context.Context) ([]*Schedule, error)
+	Save(ctx context.Context, schedule *Schedule) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// MarkSent records the statements of a period for a user, reporting false when they were already sent
+	MarkSent(ctx context.Context, userID uuid.UUID, periodStart time.Time, sentAt time.Time) (bool, error)
+}
+
+// Format is the file format the statements are attached in
+type Format string
+
+const (
+	FormatPDF Format = "pdf" // A single PDF document, one section per account
+	FormatCSV Format = "csv" // A CSV file per account
+)
+
+// Values returns every known format, used to validate enum fields
+func (Format) Values() []string {
+	return []string{string(FormatPDF), string(FormatCSV)}
+}
+
+// Schedule is the monthly email of a user with the statements of the accounts they selected
+// Statements are sent once the financial month of the user ends, covering it whole
+type Schedule struct {
+	UserID     uuid.UUID
+	AccountIDs []uuid.UUID // AccountIDs are in the order the statements are attached
+	Format     Format
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NewSchedule creates the statement schedule of a user
+func NewSchedule(userID uuid.UUID, accountIDs []uuid.UUID, format Format, clock clock.Clock) (*Schedule, error) {
+	schedule := &Schedule{UserID: userID, CreatedAt: clock.Now()}
+	if err := schedule.Update(accountIDs, format, clock); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Update replaces the accounts and the format of the schedule, the repeated accounts being kept once
+func (s *Schedule) Update(accountIDs []uuid.UUID, format Format, clock clock.Clock) error {
+	if !slices.Contains(format.Values(), string(format)) {
+		return ErrInvalidStatementFormat.With("format", format)
+	}
+
+	unique := make([]uuid.UUID, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return ErrStatementAccountsEmpty
+	}
+	if len(unique) > MaxAccountsPerSchedule {
+		return ErrTooManyStatementAccounts.With("max_accounts", MaxAccountsPerSchedule)
+	}
+
+	s.AccountIDs = unique
+	s.Format = format
+	s.UpdatedAt = clock.Now()
+	return nil
+}
+
+// Line is a transaction paid during the statement period, with the balance of the account right after it
+type Line struct {
+	Date        time.Time
+	Description string
+	Amount      money.Money
+	Balance     money.Money
+}
+
+// Statement is what an account received and paid during a period
+type Statement struct {
+	AccountID   uuid.UUID
+	AccountName string
+	Currency    string
+	From        time.Time // From and To delimit the period [From, To)
+	To          time.Time
+	Opening     money.Money // Opening is the balance of the paid transactions before From
+	Closing     money.Money
+	Credits     money.Money
+	Debits      money.Money // Debits is positive
+	Lines       []Line      // Lines are ordered by payment date
+}
+
+// Build compiles the statement of the account for the period [from, to), from its paid transactions
+func Build(account *ledger.Account, from, to time.Time) Statement {
+	zero := money.Zero(account.Currency)
+	statement := Statement{
+		AccountID:   account.ID,
+		AccountName: account.Name,
+		Currency:    account.Currency,
+		From:        from,
+		To:          to,
+		Opening:     zero,
+		Credits:     zero,
+		Debits:      zero,
+	}
+
+	var paid []ledger.Transaction
+	for _, tx := range account.Transactions() {
+		switch {
+		case tx.PaidAt == nil || !tx.PaidAt.Before(to):
+		case tx.PaidAt.Before(from):
+			statement.Opening.Amount += tx.Amount.Amount
+		default:
+			paid = append(paid, tx)
+		}
+	}
+	slices.SortStableFunc(paid, func(a, b ledger.Transaction) int {
+		return a.PaidAt.Compare(*b.PaidAt)
+	})
+
+	balance := statement.Opening
+	for _, tx := range paid {
+		balance.Amount += tx.Amount.Amount
+		if tx.Amount.IsNegative() {
+			statement.Debits.Amount -= tx.Amount.Amount
+		} else {
+			statement.Credits.Amount += tx.Amount.Amount
+		}
+		statement.Lines = append(statement.Lines, Line{
+			Date:        *tx.PaidAt,
+			Description: tx.Description,
+			Amount:      tx.Amount,
+			Balance:     balance,
+		})
+	}
+	statement.Closing = balance
+
+	return statement
+}
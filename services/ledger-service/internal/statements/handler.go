@@ -0,0 +1,115 @@
+package statements
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// StatementsHandler holds dependencies for the statement schedule HTTP handlers
+type StatementsHandler struct {
+	statementsService *Service
+}
+
+// NewStatementsHandler creates a new instance of StatementsHandler
+func NewStatementsHandler(statementsService *Service) *StatementsHandler {
+	return &StatementsHandler{statementsService: statementsService}
+}
+
+// RegisterRoutes sets up the API routes for the statements module
+func (h *StatementsHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	statementsGroup := apiRouteGroup.Group("/statements")
+
+	statementsGroup.GET("/schedule", h.getScheduleHandler)
+	statementsGroup.PUT("/schedule", h.saveScheduleHandler)
+	statementsGroup.DELETE("/schedule", h.deleteScheduleHandler)
+}
+
+// SaveScheduleRequest defines the expected JSON body for scheduling the monthly statements or changing them
+type SaveScheduleRequest struct {
+	AccountIDs []uuid.UUID `json:"account_ids" validate:"required,min=1,max=20"` // AccountIDs are attached in the given order
+	Format     Format      `json:"format" validate:"required,enum"`
+}
+
+// ScheduleResponse defines the structure of the statement schedule returned by the API
+type ScheduleResponse struct {
+	AccountIDs []uuid.UUID `json:"account_ids"`
+	Format     Format      `json:"format"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// getScheduleHandler handles the HTTP request for finding the user's statement schedule
+func (h *StatementsHandler) getScheduleHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := h.statementsService.GetSchedule(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toScheduleResponse(schedule))
+}
+
+// saveScheduleHandler handles the HTTP request for scheduling the user's monthly statements or changing them
+func (h *StatementsHandler) saveScheduleHandler(c echo.Context) error {
+	var req SaveScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := h.statementsService.SaveSchedule(c.Request().Context(), userID, req.AccountIDs, req.Format)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toScheduleResponse(schedule))
+}
+
+// deleteScheduleHandler handles the HTTP request for stopping the user's statement emails
+func (h *StatementsHandler) deleteScheduleHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.statementsService.DeleteSchedule(c.Request().Context(), userID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// currentUserID extracts the authenticated user's ID from the request context
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toScheduleResponse maps a Schedule to the public ScheduleResponse DTO
+func toScheduleResponse(s *Schedule) ScheduleResponse {
+	return ScheduleResponse{
+		AccountIDs: s.AccountIDs,
+		Format:     s.Format,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package statements
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the statements repository, service and handler from the shared dependencies
+type Module struct {
+	service *Service
+	handler *StatementsHandler
+}
+
+// NewModule creates the statements module, the statements are emailed through deps.Mailer
+func NewModule(deps module.Deps, accounts AccountReader) *Module {
+	statementsSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), accounts, deps.Mailer, deps.Periods, deps.Display, deps.Clock)
+
+	return &Module{service: statementsSvc, handler: NewStatementsHandler(statementsSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "statements"
+}
+
+// RegisterRoutes mounts the statements routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
+
+// Service returns the statements service, for the composition root scheduling the monthly emails
+func (m *Module) Service() *Service {
+	return m.service
+}
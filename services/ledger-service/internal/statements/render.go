@@ -0,0 +1,188 @@
+package statements
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/pdf"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+// amountWidth is the width of the amount and balance columns of the PDF
+const amountWidth = 17
+
+// messages translates the texts of the email and of the PDF
+var messages = i18n.Catalog{
+	i18n.English: {
+		"statement_subject":         "Your statements from %s to %s",
+		"statement_body":            "Attached are the statements of your accounts from %s to %s.\n\nYou can change the accounts or stop receiving this email in fintrack.\n",
+		"statement_title":           "Statement - %s",
+		"statement_period":          "Period: %s to %s (%s)",
+		"statement_opening_balance": "Opening balance",
+		"statement_credits":         "Credits",
+		"statement_debits":          "Debits",
+		"statement_closing_balance": "Closing balance",
+		"statement_date":            "Date",
+		"statement_description":     "Description",
+		"statement_amount":          "Amount",
+		"statement_balance":         "Balance",
+		"statement_no_transactions": "No transactions were paid in this period.",
+	},
+	i18n.Portuguese: {
+		"statement_subject":         "Seus extratos de %s a %s",
+		"statement_body":            "Seguem em anexo os extratos das suas contas de %s a %s.\n\nVocê pode mudar as contas ou deixar de receber este email no fintrack.\n",
+		"statement_title":           "Extrato - %s",
+		"statement_period":          "Período: %s a %s (%s)",
+		"statement_opening_balance": "Saldo inicial",
+		"statement_credits":         "Créditos",
+		"statement_debits":          "Débitos",
+		"statement_closing_balance": "Saldo final",
+		"statement_date":            "Data",
+		"statement_description":     "Descrição",
+		"statement_amount":          "Valor",
+		"statement_balance":         "Saldo",
+		"statement_no_transactions": "Nenhuma transação foi paga neste período.",
+	},
+}
+
+// render builds the email with the statements attached in the format of the schedule, in the language of the user's locale
+// The period is written with both ends included, as the user reads it
+func render(userID uuid.UUID, statements []Statement, format Format, from, to time.Time, prefs module.DisplayPreferences) (notify.Email, error) {
+	lang, _ := i18n.Resolve(prefs.Locale)
+	first, last := from.Format(prefs.DateLayout), to.AddDate(0, 0, -1).Format(prefs.DateLayout)
+	subject := messages.Format(lang, "statement_subject", first, last)
+
+	var attachments []notify.Attachment
+	if format == FormatCSV {
+		for _, statement := range statements {
+			content, err := renderCSV(statement)
+			if err != nil {
+				return notify.Email{}, err
+			}
+			attachments = append(attachments, notify.Attachment{
+				Filename:    fmt.Sprintf("fintrack-statement-%s-%s-%s.csv", slug(statement), from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly)),
+				ContentType: "text/csv; charset=utf-8",
+				Content:     content,
+			})
+		}
+	} else {
+		attachments = append(attachments, notify.Attachment{
+			Filename:    fmt.Sprintf("fintrack-statements-%s-%s.pdf", from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly)),
+			ContentType: "application/pdf",
+			Content:     renderPDF(subject, statements, lang, prefs),
+		})
+	}
+
+	return notify.Email{
+		UserID:      userID,
+		Subject:     subject,
+		Body:        messages.Format(lang, "statement_body", first, last),
+		Attachments: attachments,
+	}, nil
+}
+
+// renderPDF writes the statements as a single document titled after the email, each account starting on a new page
+func renderPDF(title string, statements []Statement, lang i18n.Lang, prefs module.DisplayPreferences) []byte {
+	text := func(key string) string {
+		msg, _ := messages.Message(lang, key)
+		return msg
+	}
+
+	doc := pdf.New(title)
+	for _, statement := range statements {
+		doc.NewPage()
+		doc.Heading(messages.Format(lang, "statement_title", statement.AccountName))
+		doc.Text(messages.Format(lang, "statement_period",
+			statement.From.Format(prefs.DateLayout), statement.To.AddDate(0, 0, -1).Format(prefs.DateLayout), statement.Currency))
+		doc.Blank()
+
+		for _, total := range []struct{ key, amount string }{
+			{"statement_opening_balance", statement.Opening.Format(prefs.Locale)},
+			{"statement_credits", statement.Credits.Format(prefs.Locale)},
+			{"statement_debits", statement.Debits.Format(prefs.Locale)},
+			{"statement_closing_balance", statement.Closing.Format(prefs.Locale)},
+		} {
+			doc.Text(fmt.Sprintf("%-20s %*s", text(total.key), amountWidth, total.amount))
+		}
+		doc.Blank()
+
+		if len(statement.Lines) == 0 {
+			doc.Text(text("statement_no_transactions"))
+			continue
+		}
+
+		// The description takes what the date and amounts leave of the line
+		dateWidth := max(utf8.RuneCountInString(statement.From.Format(prefs.DateLayout)), utf8.RuneCountInString(text("statement_date")))
+		descriptionWidth := pdf.Columns() - dateWidth - 2*amountWidth - 6
+		row := func(date, description, amount, balance string) string {
+			return fmt.Sprintf("%-*s  %-*s  %*s  %*s",
+				dateWidth, date, descriptionWidth, truncate(description, descriptionWidth), amountWidth, amount, amountWidth, balance)
+		}
+
+		doc.Bold(row(text("statement_date"), text("statement_description"), text("statement_amount"), text("statement_balance")))
+		for _, line := range statement.Lines {
+			doc.Text(row(line.Date.Format(prefs.DateLayout), line.Description, line.Amount.Format(prefs.Locale), line.Balance.Format(prefs.Locale)))
+		}
+	}
+	return doc.Bytes()
+}
+
+// renderCSV writes the statement of an account as CSV, with the amounts as decimal numbers in the account currency
+// The opening balance comes first, so the balance column adds up from it
+func renderCSV(statement Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{
+		{"account", "currency", "date", "description", "amount", "balance"},
+		{statement.AccountName, statement.Currency, statement.From.Format(time.DateOnly), "opening balance", "", statement.Opening.Decimal()},
+	}
+	for _, line := range statement.Lines {
+		rows = append(rows, []string{
+			statement.AccountName,
+			statement.Currency,
+			line.Date.Format(time.DateOnly),
+			line.Description,
+			line.Amount.Decimal(),
+			line.Balance.Decimal(),
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write statement csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// truncate cuts the text at width characters, marking the cut with an ellipsis
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:width-1]) + "…"
+}
+
+// slug names the file of a statement after its account (e.g., "nubank-checking"), falling back to the account ID
+func slug(statement Statement) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(statement.AccountName) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	if s := strings.TrimSuffix(b.String(), "-"); s != "" {
+		return s
+	}
+	return statement.AccountID.String()
+}
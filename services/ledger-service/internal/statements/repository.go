@@ -0,0 +1,126 @@
+package statements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// FindByUserID retrieves the statement schedule of the user, nil when there is none
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*Schedule, error) {
+	query := `
+		SELECT s.user_id, s.format, s.created_at, s.updated_at,
+			COALESCE(ARRAY_AGG(a.account_id ORDER BY a.position) FILTER (WHERE a.account_id IS NOT NULL), '{}')
+		FROM statement_schedules s
+		LEFT JOIN statement_schedule_accounts a ON a.user_id = s.user_id
+		WHERE s.user_id = $1
+		GROUP BY s.user_id
+	`
+
+	var s Schedule
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&s.UserID, &s.Format, &s.CreatedAt, &s.UpdatedAt, &s.AccountIDs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch statement schedule: %w", err)
+	}
+	return &s, nil
+}
+
+// FindAll retrieves the statement schedules of every user
+func (r *PostgresRepository) FindAll(ctx context.Context) ([]*Schedule, error) {
+	query := `
+		SELECT s.user_id, s.format, s.created_at, s.updated_at,
+			COALESCE(ARRAY_AGG(a.account_id ORDER BY a.position) FILTER (WHERE a.account_id IS NOT NULL), '{}')
+		FROM statement_schedules s
+		LEFT JOIN statement_schedule_accounts a ON a.user_id = s.user_id
+		GROUP BY s.user_id
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement schedules: %w", err)
+	}
+	schedules, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Schedule, error) {
+		var s Schedule
+		err := row.Scan(&s.UserID, &s.Format, &s.CreatedAt, &s.UpdatedAt, &s.AccountIDs)
+		return &s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan statement schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// Save inserts or replaces the statement schedule of the user along with its accounts
+func (r *PostgresRepository) Save(ctx context.Context, schedule *Schedule) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		upsertQuery := `
+			INSERT INTO statement_schedules (user_id, format, created_at, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id)
+			DO UPDATE SET
+				format = EXCLUDED.format,
+				updated_at = EXCLUDED.updated_at
+		`
+		if _, err := tx.Exec(ctx, upsertQuery, schedule.UserID, schedule.Format, schedule.CreatedAt, schedule.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to upsert statement schedule: %w", err)
+		}
+
+		deleteQuery := `DELETE FROM statement_schedule_accounts WHERE user_id = $1`
+		if _, err := tx.Exec(ctx, deleteQuery, schedule.UserID); err != nil {
+			return fmt.Errorf("failed to delete statement schedule accounts: %w", err)
+		}
+
+		insertQuery := `
+			INSERT INTO statement_schedule_accounts (user_id, account_id, position)
+			SELECT $1, a.account_id, a.position
+			FROM UNNEST($2::UUID[]) WITH ORDINALITY AS a(account_id, position)
+		`
+		if _, err := tx.Exec(ctx, insertQuery, schedule.UserID, schedule.AccountIDs); err != nil {
+			return fmt.Errorf("failed to insert statement schedule accounts: %w", err)
+		}
+		return nil
+	})
+}
+
+// Delete removes the statement schedule of the user
+func (r *PostgresRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM statement_schedules WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete statement schedule: %w", err)
+	}
+	return nil
+}
+
+// MarkSent records the statements of a period for a user, reporting false when they were already recorded
+func (r *PostgresRepository) MarkSent(ctx context.Context, userID uuid.UUID, periodStart time.Time, sentAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO statements_sent (user_id, period_start, sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, period_start) DO NOTHING
+	`
+
+	tag, err := r.pool.Exec(ctx, query, userID, periodStart, sentAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record statements: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,183 @@
+package statements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+// Service manages the statement schedules and emails the monthly statements of the scheduled accounts
+type Service struct {
+	repo     Repository
+	accounts AccountReader
+	mailer   notify.Mailer
+	periods  module.AccountingPeriods
+	display  module.UserPreferences
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the statements Service, the statements cover the financial month of each user
+func NewService(
+	repo Repository,
+	accounts AccountReader,
+	mailer notify.Mailer,
+	periods module.AccountingPeriods,
+	display module.UserPreferences,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		repo:     repo,
+		accounts: accounts,
+		mailer:   mailer,
+		periods:  periods,
+		display:  display,
+		clock:    clock,
+	}
+}
+
+// GetSchedule is the use case for finding the statement schedule of the user
+func (s *Service) GetSchedule(ctx context.Context, userID uuid.UUID) (*Schedule, error) {
+	schedule, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find statement schedule: %w", err)
+	}
+	if schedule == nil {
+		return nil, ErrScheduleNotFound.With("user_id", userID)
+	}
+	return schedule, nil
+}
+
+// SaveSchedule is the use case for scheduling the monthly statements of the accounts of the user or changing them
+func (s *Service) SaveSchedule(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID, format Format) (*Schedule, error) {
+	schedule, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find statement schedule: %w", err)
+	}
+
+	if schedule == nil {
+		schedule, err = NewSchedule(userID, accountIDs, format, s.clock)
+	} else {
+		err = schedule.Update(accountIDs, format, s.clock)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accountID := range schedule.AccountIDs {
+		if _, err := s.accounts.FindAccountByID(ctx, userID, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Save(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save statement schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule is the use case for stopping the statement emails of the user
+func (s *Service) DeleteSchedule(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.GetSchedule(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete statement schedule: %w", err)
+	}
+	return nil
+}
+
+// SendMonthlyStatements emails the statements of the last financial month that ended to every user with a schedule
+// A month is sent at most once per user, so the task runs daily and sends the statements on the first run after the month ends
+func (s *Service) SendMonthlyStatements(ctx context.Context) error {
+	schedules, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find statement schedules: %w", err)
+	}
+
+	sent, failed := 0, 0
+	for _, schedule := range schedules {
+		ok, err := s.sendStatements(ctx, schedule)
+		if err != nil {
+			failed++
+			ctxlogger.GetLogger(ctx).Error("failed to send monthly statements",
+				slog.String("user_id", schedule.UserID.String()),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	ctxlogger.GetLogger(ctx).Info("sent monthly statements",
+		slog.Int("users", len(schedules)),
+		slog.Int("sent", sent),
+		slog.Int("failed", failed),
+	)
+	if failed > 0 {
+		return fmt.Errorf("failed to send the monthly statements of %d of %d users", failed, len(schedules))
+	}
+	return nil
+}
+
+// sendStatements emails the statements of the month before the current one, reporting false when there was nothing to send
+// The months that ended before the schedule was created are not sent. A month is recorded before the statements are built,
+// so a failed delivery is not retried rather than sent twice, and the accounts are only read once per month
+func (s *Service) sendStatements(ctx context.Context, schedule *Schedule) (bool, error) {
+	period, err := s.periods.AccountingPeriod(ctx, schedule.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+	to, _ := period.RangeIn(s.clock.Now(), time.UTC)
+	from, _ := period.RangeIn(to.AddDate(0, 0, -1), time.UTC)
+	if !schedule.CreatedAt.Before(to) {
+		return false, nil
+	}
+
+	firstTime, err := s.repo.MarkSent(ctx, schedule.UserID, from, s.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	if !firstTime {
+		return false, nil
+	}
+
+	// The accounts deleted or handed to someone else since the schedule was read are left out
+	statements := make([]Statement, 0, len(schedule.AccountIDs))
+	for _, accountID := range schedule.AccountIDs {
+		account, err := s.accounts.FindAccountByID(ctx, schedule.UserID, accountID)
+		if errors.Is(err, ledger.ErrAccountNotFound) || errors.Is(err, ledger.ErrForbidden) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to find account: %w", err)
+		}
+		statements = append(statements, Build(account, from, to))
+	}
+	if len(statements) == 0 {
+		return false, nil
+	}
+
+	prefs, err := s.display.DisplayPreferences(ctx, schedule.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find display preferences: %w", err)
+	}
+	email, err := render(schedule.UserID, statements, schedule.Format, from, to, prefs)
+	if err != nil {
+		return false, err
+	}
+	if err := s.mailer.Mail(ctx, email); err != nil {
+		return false, err
+	}
+	return true, nil
+}
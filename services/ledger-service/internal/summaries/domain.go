@@ -0,0 +1,150 @@
+package summaries
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/money"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/budgets"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+const (
+	// upcomingDays is how far ahead the summary looks for bills to pay
+	upcomingDays = 7
+	// maxBills bounds the bills listed in the email, the remaining ones are only counted
+	maxBills = 10
+)
+
+// AccountReader reads the accounts the summary covers, implemented by the ledger service
+type AccountReader interface {
+	FindAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]*ledger.Account, error)
+}
+
+// BudgetReader reads the budgets with their spending in the current financial month, implemented by the budgets service
+type BudgetReader interface {
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]budgets.BudgetStatus, error)
+}
+
+// Repository finds the users receiving the summary and records the weeks already sent
+type Repository interface {
+	// FindRecipients returns the users holding an active account who did not opt out of the weekly summary
+	FindRecipients(ctx context.Context) ([]uuid.UUID, error)
+	// MarkSent records the summary of a week for a user, reporting false when it was already sent
+	MarkSent(ctx context.Context, userID uuid.UUID, week time.Time, sentAt time.Time) (bool, error)
+}
+
+// Flow is the money that came in and went out of the accounts held in a currency
+type Flow struct {
+	Income  money.Money
+	Expense money.Money // Expense is positive
+	Net     money.Money
+}
+
+// Bill is an unpaid expense due soon or overdue
+type Bill struct {
+	Description string
+	AccountName string
+	Amount      money.Money // Amount is positive
+	DueDate     time.Time
+	Overdue     bool
+}
+
+// BudgetStatus is how much of a budget was spent in the current financial month
+type BudgetStatus struct {
+	Category string
+	Budgeted money.Money
+	Spent    money.Money
+	Percent  int64
+}
+
+// Summary is what happened to the finances of a user during a week and what comes next
+type Summary struct {
+	UserID    uuid.UUID
+	From      time.Time // From and To delimit the summarized week [From, To)
+	To        time.Time
+	Flows     []Flow // Flows hold the paid transactions of the week, one per currency
+	Bills     []Bill // Bills are overdue or due within the week after To, earliest first
+	MoreBills int    // MoreBills counts the bills left out of Bills
+	Budgets   []BudgetStatus
+}
+
+// Build compiles the summary of the week ending at to (excluded) from the accounts and budgets of the user
+func Build(userID uuid.UUID, accounts []*ledger.Account, statuses []budgets.BudgetStatus, to time.Time) *Summary {
+	summary := &Summary{UserID: userID, From: to.AddDate(0, 0, -7), To: to}
+	upcomingEnd := to.AddDate(0, 0, upcomingDays)
+
+	flows := make(map[string]*Flow)
+	var bills []Bill
+	for _, account := range accounts {
+		if account.ArchivedAt != nil {
+			continue
+		}
+		for _, tx := range account.Transactions() {
+			if tx.PaidAt != nil {
+				if tx.PaidAt.Before(summary.From) || !tx.PaidAt.Before(to) || (tx.Type != ledger.Income && tx.Type != ledger.Expense) {
+					continue
+				}
+				flow, ok := flows[account.Currency]
+				if !ok {
+					zero := money.Zero(account.Currency)
+					flow = &Flow{Income: zero, Expense: zero, Net: zero}
+					flows[account.Currency] = flow
+				}
+				flow.Net.Amount += tx.Amount.Amount
+				if tx.Type == ledger.Income {
+					flow.Income.Amount += tx.Amount.Amount
+				} else {
+					flow.Expense.Amount -= tx.Amount.Amount
+				}
+				continue
+			}
+
+			if tx.Type == ledger.Expense && tx.DueDate.Before(upcomingEnd) {
+				bills = append(bills, Bill{
+					Description: tx.Description,
+					AccountName: account.Name,
+					Amount:      money.New(-tx.Amount.Amount, tx.Amount.Currency),
+					DueDate:     tx.DueDate,
+					Overdue:     tx.DueDate.Before(to),
+				})
+			}
+		}
+	}
+
+	for _, currency := range slices.Sorted(maps.Keys(flows)) {
+		summary.Flows = append(summary.Flows, *flows[currency])
+	}
+
+	slices.SortStableFunc(bills, func(a, b Bill) int {
+		return a.DueDate.Compare(b.DueDate)
+	})
+	if len(bills) > maxBills {
+		summary.MoreBills = len(bills) - maxBills
+		bills = bills[:maxBills]
+	}
+	summary.Bills = bills
+
+	for _, st := range statuses {
+		summary.Budgets = append(summary.Budgets, BudgetStatus{
+			Category: st.CategoryName,
+			Budgeted: st.Budget.Amount,
+			Spent:    st.Spent,
+			Percent:  st.Spent.Amount * 100 / st.Budget.Amount.Amount,
+		})
+	}
+	slices.SortStableFunc(summary.Budgets, func(a, b BudgetStatus) int {
+		return strings.Compare(a.Category, b.Category)
+	})
+
+	return summary
+}
+
+// IsEmpty reports whether the summary has nothing worth an email
+func (s *Summary) IsEmpty() bool {
+	return len(s.Flows) == 0 && len(s.Bills) == 0 && len(s.Budgets) == 0
+}
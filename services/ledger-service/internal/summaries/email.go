@@ -0,0 +1,107 @@
+package summaries
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+)
+
+var (
+	//go:embed weekly_summary.en.tmpl
+	weeklySummaryEnglish string
+	//go:embed weekly_summary.pt-BR.tmpl
+	weeklySummaryPortuguese string
+)
+
+// weeklySummary holds the template of the email in each supported language
+var weeklySummary = map[i18n.Lang]*template.Template{
+	i18n.English:    template.Must(template.New("weekly_summary").Parse(weeklySummaryEnglish)),
+	i18n.Portuguese: template.Must(template.New("weekly_summary").Parse(weeklySummaryPortuguese)),
+}
+
+// messages translates the texts of the email outside its template
+var messages = i18n.Catalog{
+	i18n.English: {
+		"weekly_summary_subject": "Your week from %s to %s",
+	},
+	i18n.Portuguese: {
+		"weekly_summary_subject": "Sua semana de %s a %s",
+	},
+}
+
+// summaryEmail is the Summary with its amounts and dates formatted for the template
+type summaryEmail struct {
+	From, To  string
+	Flows     []flowLine
+	Bills     []billLine
+	MoreBills int
+	Budgets   []budgetLine
+}
+
+type flowLine struct {
+	Currency, Income, Expense, Net string
+}
+
+type billLine struct {
+	DueDate, Description, AccountName, Amount string
+	Overdue                                   bool
+}
+
+type budgetLine struct {
+	Category, Spent, Budgeted string
+	Percent                   int64
+}
+
+// render builds the email of the summary in the language of the user's locale, amounts and dates formatted as the user prefers
+// An empty locale writes the email in the default language with the pt-BR conventions
+func render(summary *Summary, prefs module.DisplayPreferences) (notify.Email, error) {
+	locale, layout := prefs.Locale, prefs.DateLayout
+	lang, _ := i18n.Resolve(locale)
+	lastDay := summary.To.AddDate(0, 0, -1)
+	data := summaryEmail{
+		From:      summary.From.Format(layout),
+		To:        lastDay.Format(layout),
+		MoreBills: summary.MoreBills,
+	}
+	for _, f := range summary.Flows {
+		data.Flows = append(data.Flows, flowLine{
+			Currency: f.Net.Currency,
+			Income:   f.Income.Format(locale),
+			Expense:  f.Expense.Format(locale),
+			Net:      f.Net.Format(locale),
+		})
+	}
+	for _, b := range summary.Bills {
+		data.Bills = append(data.Bills, billLine{
+			DueDate:     b.DueDate.Format(layout),
+			Description: b.Description,
+			AccountName: b.AccountName,
+			Amount:      b.Amount.Format(locale),
+			Overdue:     b.Overdue,
+		})
+	}
+	for _, b := range summary.Budgets {
+		data.Budgets = append(data.Budgets, budgetLine{
+			Category: b.Category,
+			Spent:    b.Spent.Format(locale),
+			Budgeted: b.Budgeted.Format(locale),
+			Percent:  b.Percent,
+		})
+	}
+
+	var body strings.Builder
+	if err := weeklySummary[lang].Execute(&body, data); err != nil {
+		return notify.Email{}, fmt.Errorf("failed to render weekly summary: %w", err)
+	}
+
+	return notify.Email{
+		UserID:  summary.UserID,
+		Subject: messages.Format(lang, "weekly_summary_subject", data.From, data.To),
+		Body:    body.String(),
+	}, nil
+}
@@ -0,0 +1,23 @@
+package summaries
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+)
+
+// Module wires the weekly summaries repository and service from the shared dependencies
+// It has no routes, users opt out of the summary through the settings module
+type Module struct {
+	service *Service
+}
+
+// NewModule creates the summaries module, the emails are delivered through deps.Mailer
+func NewModule(deps module.Deps, accounts AccountReader, budgets BudgetReader) *Module {
+	return &Module{
+		service: NewService(NewPostgresRepository(deps.Postgres.Pool), accounts, budgets, deps.Mailer, deps.Display, deps.Clock),
+	}
+}
+
+// Service returns the summaries service, for the composition root scheduling the weekly emails
+func (m *Module) Service() *Service {
+	return m.service
+}
@@ -0,0 +1,68 @@
+package summaries
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// FindRecipients retrieves the users holding at least one active account, except those who opted out of the summary
+func (r *PostgresRepository) FindRecipients(ctx context.Context) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT a.user_id
+		FROM accounts a
+		LEFT JOIN user_settings s ON s.user_id = a.user_id
+		WHERE a.archived_at IS NULL
+			AND COALESCE(s.weekly_summary_enabled, TRUE)
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weekly summary recipients: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user id row: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user id rows: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// MarkSent records the summary of a week for a user, reporting false when it was already recorded
+func (r *PostgresRepository) MarkSent(ctx context.Context, userID uuid.UUID, week time.Time, sentAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO weekly_summaries_sent (user_id, week, sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, week) DO NOTHING
+	`
+
+	tag, err := r.pool.Exec(ctx, query, userID, week, sentAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record weekly summary: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,111 @@
+package summaries
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+// Service compiles the weekly summaries and emails them to the users who did not opt out
+type Service struct {
+	repo     Repository
+	accounts AccountReader
+	budgets  BudgetReader
+	mailer   notify.Mailer
+	display  module.UserPreferences
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the summaries Service, the emails follow the display preferences of each user
+func NewService(repo Repository, accounts AccountReader, budgets BudgetReader, mailer notify.Mailer, display module.UserPreferences, clock clock.Clock) *Service {
+	return &Service{
+		repo:     repo,
+		accounts: accounts,
+		budgets:  budgets,
+		mailer:   mailer,
+		display:  display,
+		clock:    clock,
+	}
+}
+
+// SendWeeklySummaries emails the summary of the last 7 days (up to yesterday) to every recipient
+// A week is sent at most once per user, so running the task again the same day sends nothing new
+func (s *Service) SendWeeklySummaries(ctx context.Context) error {
+	userIDs, err := s.repo.FindRecipients(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find weekly summary recipients: %w", err)
+	}
+
+	to := clock.StartOfDayIn(s.clock.Now(), time.UTC)
+	sent, failed := 0, 0
+	for _, userID := range userIDs {
+		ok, err := s.sendSummary(ctx, userID, to)
+		if err != nil {
+			failed++
+			ctxlogger.GetLogger(ctx).Error("failed to send weekly summary",
+				slog.String("user_id", userID.String()),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	ctxlogger.GetLogger(ctx).Info("sent weekly summaries",
+		slog.Time("week_end", to),
+		slog.Int("users", len(userIDs)),
+		slog.Int("sent", sent),
+		slog.Int("failed", failed),
+	)
+	if failed > 0 {
+		return fmt.Errorf("failed to send the weekly summaries of %d of %d users", failed, len(userIDs))
+	}
+	return nil
+}
+
+// sendSummary emails the summary of the week ending at to, reporting false when there was nothing to send
+// The week is recorded before the email goes out, so a failed delivery is not retried rather than sent twice
+func (s *Service) sendSummary(ctx context.Context, userID uuid.UUID, to time.Time) (bool, error) {
+	accounts, err := s.accounts.FindAccountsByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find accounts: %w", err)
+	}
+	statuses, err := s.budgets.ListBudgets(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find budgets: %w", err)
+	}
+
+	summary := Build(userID, accounts, statuses, to)
+	if summary.IsEmpty() {
+		return false, nil
+	}
+	prefs, err := s.display.DisplayPreferences(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find display preferences: %w", err)
+	}
+	email, err := render(summary, prefs)
+	if err != nil {
+		return false, err
+	}
+
+	firstTime, err := s.repo.MarkSent(ctx, userID, summary.From, s.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	if !firstTime {
+		return false, nil
+	}
+	if err := s.mailer.Mail(ctx, email); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,145 @@
+package transfers
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTransferNotFound          = errx.New(errx.CategoryNotFound, "ACCOUNT_TRANSFER_NOT_FOUND", "account transfer not found")
+	ErrAccountNotFound           = errx.New(errx.CategoryNotFound, "ACCOUNT_NOT_FOUND", "account not found")
+	ErrRecipientNotFound         = errx.New(errx.CategoryNotFound, "RECIPIENT_NOT_FOUND", "no user is registered with this email")
+	ErrRecipientOutsideHousehold = errx.New(errx.CategoryForbidden, "RECIPIENT_OUTSIDE_HOUSEHOLD", "accounts can only be transferred to a member of a household of the sender")
+	ErrTransferToSelf            = errx.New(errx.CategoryValidation, "ACCOUNT_TRANSFER_TO_SELF", "the account already belongs to this user")
+	ErrAccountArchived           = errx.New(errx.CategoryConflict, "ACCOUNT_ARCHIVED", "archived accounts can not be transferred, restore the account first")
+	ErrAccountPendingDeletion    = errx.New(errx.CategoryConflict, "ACCOUNT_PENDING_DELETION", "accounts scheduled for deletion can not be transferred, cancel the deletion first")
+	ErrAccountLinkedToBank       = errx.New(errx.CategoryConflict, "ACCOUNT_LINKED_TO_BANK", "accounts synced from a bank can not be transferred, unlink the account first")
+	ErrTransferAlreadyPending    = errx.New(errx.CategoryConflict, "ACCOUNT_TRANSFER_ALREADY_PENDING", "the account is already offered to another user")
+	ErrTransferNotPending        = errx.New(errx.CategoryConflict, "ACCOUNT_TRANSFER_NOT_PENDING", "the account transfer was already resolved")
+	ErrNotTransferRecipient      = errx.New(errx.CategoryForbidden, "NOT_ACCOUNT_TRANSFER_RECIPIENT", "only the recipient can accept or decline an account transfer")
+	ErrNotTransferSender         = errx.New(errx.CategoryForbidden, "NOT_ACCOUNT_TRANSFER_SENDER", "only the sender can cancel an account transfer")
+)
+
+// Status is the state of an account transfer
+type Status string
+
+const (
+	StatusPending   Status = "PENDING"   // StatusPending waits for the recipient to accept or decline the account
+	StatusAccepted  Status = "ACCEPTED"  // StatusAccepted means the account now belongs to the recipient
+	StatusDeclined  Status = "DECLINED"  // StatusDeclined means the recipient refused the account
+	StatusCancelled Status = "CANCELLED" // StatusCancelled means the sender withdrew the offer
+)
+
+// Transfer is the offer of an account to another user, who becomes its owner by accepting it
+// The sender keeps the account until then, and can keep using it
+type Transfer struct {
+	ID          uuid.UUID
+	AccountID   uuid.UUID
+	AccountName string // AccountName is read along the transfer, so the recipient knows what they are offered
+	FromUserID  uuid.UUID
+	ToUserID    uuid.UUID
+	Status      Status
+	CreatedAt   time.Time
+	ResolvedAt  *time.Time
+}
+
+// Account is what the transfers need to know about the offered account
+type Account struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	Name            string
+	Archived        bool
+	PendingDeletion bool // PendingDeletion is set when the owner deleted the account, which is deleted for good after a grace period
+	BankLinked      bool // BankLinked is set when the account mirrors a bank account of a consent given by its owner
+}
+
+// checkTransferable rejects the accounts that can not change hands in their current state
+func (a *Account) checkTransferable() error {
+	if a.Archived {
+		return ErrAccountArchived.With("account_id", a.ID)
+	}
+	if a.PendingDeletion {
+		return ErrAccountPendingDeletion.With("account_id", a.ID)
+	}
+	if a.BankLinked {
+		return ErrAccountLinkedToBank.With("account_id", a.ID)
+	}
+	return nil
+}
+
+// NewTransfer creates the pending offer of the account to the recipient
+func NewTransfer(account *Account, toUserID uuid.UUID, ids idgen.Generator, clock clock.Clock) (*Transfer, error) {
+	if account.UserID == toUserID {
+		return nil, ErrTransferToSelf
+	}
+	if err := account.checkTransferable(); err != nil {
+		return nil, err
+	}
+
+	return &Transfer{
+		ID:          ids.NewID(),
+		AccountID:   account.ID,
+		AccountName: account.Name,
+		FromUserID:  account.UserID,
+		ToUserID:    toUserID,
+		Status:      StatusPending,
+		CreatedAt:   clock.Now(),
+	}, nil
+}
+
+// Accept marks the transfer as accepted by its recipient, the repository moving the account along
+func (t *Transfer) Accept(userID uuid.UUID, clock clock.Clock) error {
+	if userID != t.ToUserID {
+		return ErrNotTransferRecipient
+	}
+	return t.resolve(StatusAccepted, clock)
+}
+
+// Decline marks the transfer as refused by its recipient
+func (t *Transfer) Decline(userID uuid.UUID, clock clock.Clock) error {
+	if userID != t.ToUserID {
+		return ErrNotTransferRecipient
+	}
+	return t.resolve(StatusDeclined, clock)
+}
+
+// Cancel marks the transfer as withdrawn by its sender
+func (t *Transfer) Cancel(userID uuid.UUID, clock clock.Clock) error {
+	if userID != t.FromUserID {
+		return ErrNotTransferSender
+	}
+	return t.resolve(StatusCancelled, clock)
+}
+
+// resolve moves a pending transfer to its final status
+func (t *Transfer) resolve(status Status, clock clock.Clock) error {
+	if t.Status != StatusPending {
+		return ErrTransferNotPending.With("status", t.Status)
+	}
+
+	now := clock.Now()
+	t.Status = status
+	t.ResolvedAt = &now
+	return nil
+}
+
+// Repository persists the account transfers and moves the accounts between users
+type Repository interface {
+	FindAccount(ctx context.Context, accountID uuid.UUID) (*Account, error)
+	// FindUserIDByEmail finds the recipient of a transfer by the email they registered with
+	FindUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error)
+	// SharesHousehold tells whether both users joined a same household
+	SharesHousehold(ctx context.Context, userID, otherUserID uuid.UUID) (bool, error)
+	Save(ctx context.Context, transfer *Transfer) error
+	FindByID(ctx context.Context, transferID uuid.UUID) (*Transfer, error)
+	// FindByUserID returns a page of the transfers the user sent or received, the most recent first
+	FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Transfer, error)
+	// Complete saves the accepted transfer and hands the account, its transactions and its positions to the recipient
+	// in a single transaction, the categories of the sender being removed from the transactions
+	Complete(ctx context.Context, transfer *Transfer) error
+}
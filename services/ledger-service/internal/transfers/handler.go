@@ -0,0 +1,153 @@
+package transfers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// TransferHandler holds dependencies for account transfer HTTP handlers
+type TransferHandler struct {
+	transferService *Service
+}
+
+// NewTransferHandler creates a new instance of TransferHandler
+func NewTransferHandler(transferService *Service) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+// RegisterRoutes sets up the API routes for the transfers module
+func (h *TransferHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	apiRouteGroup.POST("/accounts/:id/transfers", h.requestTransferHandler)
+
+	transfersGroup := apiRouteGroup.Group("/account-transfers")
+
+	transfersGroup.GET("", h.listTransfersHandler)
+	transfersGroup.POST("/:id/accept", h.resolveTransferHandler(h.transferService.AcceptTransfer))
+	transfersGroup.POST("/:id/decline", h.resolveTransferHandler(h.transferService.DeclineTransfer))
+	transfersGroup.POST("/:id/cancel", h.resolveTransferHandler(h.transferService.CancelTransfer))
+}
+
+// RequestTransferRequest defines the expected JSON body for offering an account to another user
+type RequestTransferRequest struct {
+	RecipientEmail string `json:"recipient_email" validate:"required,email,max=255"`
+}
+
+// TransferResponse defines the structure of an account transfer returned by the API
+type TransferResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	AccountID   uuid.UUID  `json:"account_id"`
+	AccountName string     `json:"account_name"`
+	FromUserID  uuid.UUID  `json:"from_user_id"`
+	ToUserID    uuid.UUID  `json:"to_user_id"`
+	Status      Status     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// requestTransferHandler handles the HTTP request for offering an account to another user
+func (h *TransferHandler) requestTransferHandler(c echo.Context) error {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id format")
+	}
+
+	var req RequestTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	transfer, err := h.transferService.RequestTransfer(c.Request().Context(), RequestTransferParams{
+		UserID:         userID,
+		AccountID:      accountID,
+		RecipientEmail: req.RecipientEmail,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toTransferResponse(transfer))
+}
+
+// listTransfersHandler handles the HTTP request for listing the transfers the user sent or received
+func (h *TransferHandler) listTransfersHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := httpx.ParsePage(c)
+	if err != nil {
+		return err
+	}
+
+	transfers, err := h.transferService.FindTransfers(c.Request().Context(), userID, page.Limit, page.Offset)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]TransferResponse, 0, len(transfers))
+	for _, transfer := range transfers {
+		resp = append(resp, toTransferResponse(transfer))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// resolveTransferHandler builds the handler of an HTTP request accepting, declining or cancelling a transfer
+func (h *TransferHandler) resolveTransferHandler(resolve func(ctx context.Context, userID, transferID uuid.UUID) (*Transfer, error)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		transferID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer id format")
+		}
+
+		userID, err := currentUserID(c)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := resolve(c.Request().Context(), userID, transferID)
+		if err != nil {
+			return err
+		}
+
+		return httpx.SendSuccess(c, http.StatusOK, toTransferResponse(transfer))
+	}
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toTransferResponse maps the internal Transfer domain model to the public TransferResponse DTO
+func toTransferResponse(t *Transfer) TransferResponse {
+	return TransferResponse{
+		ID:          t.ID,
+		AccountID:   t.AccountID,
+		AccountName: t.AccountName,
+		FromUserID:  t.FromUserID,
+		ToUserID:    t.ToUserID,
+		Status:      t.Status,
+		CreatedAt:   t.CreatedAt,
+		ResolvedAt:  t.ResolvedAt,
+	}
+}
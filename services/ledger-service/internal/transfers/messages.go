@@ -0,0 +1,23 @@
+package transfers
+
+import "github.com/Guizzs26/fintrack/pkg/i18n"
+
+// messages translates the notifications sent to both ends of the account transfers
+var messages = i18n.Catalog{
+	i18n.English: {
+		"transfer_offered_title":  "Account offered to you",
+		"transfer_offered_body":   "You were offered the account %s, accept it to become its owner",
+		"transfer_accepted_title": "Account transfer accepted",
+		"transfer_accepted_body":  "The account %s now belongs to the user you offered it to",
+		"transfer_declined_title": "Account transfer declined",
+		"transfer_declined_body":  "The account %s was not accepted and remains yours",
+	},
+	i18n.Portuguese: {
+		"transfer_offered_title":  "Conta oferecida a você",
+		"transfer_offered_body":   "A conta %s foi oferecida a você, aceite para se tornar a titular",
+		"transfer_accepted_title": "Transferência de conta aceita",
+		"transfer_accepted_body":  "A conta %s agora pertence ao usuário a quem você a ofereceu",
+		"transfer_declined_title": "Transferência de conta recusada",
+		"transfer_declined_body":  "A conta %s não foi aceita e continua sendo sua",
+	},
+}
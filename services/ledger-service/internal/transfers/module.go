@@ -0,0 +1,30 @@
+package transfers
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the transfers repository, service and handler from the shared dependencies
+type Module struct {
+	handler *TransferHandler
+}
+
+// NewModule creates the transfers module, moving the accounts straight in Postgres
+func NewModule(deps module.Deps) *Module {
+	transferSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.Notifier, deps.Display, deps.Audit, deps.IDs, deps.Clock)
+
+	return &Module{handler: NewTransferHandler(transferSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "transfers"
+}
+
+// RegisterRoutes mounts the transfers routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
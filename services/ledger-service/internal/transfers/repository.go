@@ -0,0 +1,243 @@
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// pendingUniqueIndex keeps a single pending transfer per account
+const pendingUniqueIndex = "uq_account_transfers_account_id_pending"
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// FindAccount retrieves the offered account, telling whether it is archived, deleted or synced from a bank
+func (r *PostgresRepository) FindAccount(ctx context.Context, accountID uuid.UUID) (*Account, error) {
+	query := `
+		SELECT a.id, a.user_id, a.name, a.archived_at IS NOT NULL, a.delete_after IS NOT NULL,
+			EXISTS (SELECT 1 FROM bank_account_links l WHERE l.account_id = a.id)
+		FROM accounts a
+		WHERE a.id = $1
+	`
+
+	var a Account
+	err := r.pool.QueryRow(ctx, query, accountID).Scan(&a.ID, &a.UserID, &a.Name, &a.Archived, &a.PendingDeletion, &a.BankLinked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound.With("account_id", accountID)
+		}
+		return nil, fmt.Errorf("failed to query account: %w", err)
+	}
+	return &a, nil
+}
+
+// FindUserIDByEmail retrieves the user registered with the email, ignoring case
+func (r *PostgresRepository) FindUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT id FROM users WHERE LOWER(email) = LOWER($1)`, email).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrRecipientNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to query recipient: %w", err)
+	}
+	return userID, nil
+}
+
+// SharesHousehold tells whether both users joined a same household, pending invitations not counting
+func (r *PostgresRepository) SharesHousehold(ctx context.Context, userID, otherUserID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM household_members m
+			JOIN household_members other ON other.household_id = m.household_id
+			WHERE m.user_id = $1 AND other.user_id = $2
+				AND m.joined_at IS NOT NULL AND other.joined_at IS NOT NULL
+		)
+	`
+
+	var shares bool
+	if err := r.pool.QueryRow(ctx, query, userID, otherUserID).Scan(&shares); err != nil {
+		return false, fmt.Errorf("failed to check households: %w", err)
+	}
+	return shares, nil
+}
+
+// Save inserts a new transfer or updates the status of an existing one
+func (r *PostgresRepository) Save(ctx context.Context, transfer *Transfer) error {
+	query := `
+		INSERT INTO account_transfers (id, account_id, from_user_id, to_user_id, status, created_at, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			status = EXCLUDED.status,
+			resolved_at = EXCLUDED.resolved_at
+	`
+	_, err := r.pool.Exec(ctx, query,
+		transfer.ID, transfer.AccountID, transfer.FromUserID, transfer.ToUserID,
+		transfer.Status, transfer.CreatedAt, transfer.ResolvedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == pendingUniqueIndex {
+			return ErrTransferAlreadyPending.With("account_id", transfer.AccountID)
+		}
+		return fmt.Errorf("failed to upsert account transfer: %w", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a transfer with the name of its account
+func (r *PostgresRepository) FindByID(ctx context.Context, transferID uuid.UUID) (*Transfer, error) {
+	query := `
+		SELECT t.id, t.account_id, a.name, t.from_user_id, t.to_user_id, t.status, t.created_at, t.resolved_at
+		FROM account_transfers t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.id = $1
+	`
+
+	transfer, err := scanTransfer(r.pool.QueryRow(ctx, query, transferID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransferNotFound.With("transfer_id", transferID)
+		}
+		return nil, fmt.Errorf("failed to query account transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// FindByUserID retrieves a page of the transfers the user sent or received, the most recent first
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Transfer, error) {
+	query := `
+		SELECT t.id, t.account_id, a.name, t.from_user_id, t.to_user_id, t.status, t.created_at, t.resolved_at
+		FROM account_transfers t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.from_user_id = $1 OR t.to_user_id = $1
+		ORDER BY t.created_at DESC, t.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]*Transfer, 0)
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account transfer row: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account transfer rows: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// Complete saves the accepted transfer and moves the account to the recipient in a single transaction
+// The tags of the transactions are recreated for the recipient, the archived history and the receipts moving along,
+// and what only made sense for the sender is dropped:
+// their own categories on the transactions, their categorization rules for the account, its unfinished imports
+// and its sharing with the households of the sender
+func (r *PostgresRepository) Complete(ctx context.Context, transfer *Transfer) error {
+	return pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE account_transfers SET status = $2, resolved_at = $3
+			WHERE id = $1 AND status = 'PENDING'
+		`, transfer.ID, transfer.Status, transfer.ResolvedAt)
+		if err != nil {
+			return fmt.Errorf("failed to update account transfer: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrTransferNotPending
+		}
+
+		tag, err = tx.Exec(ctx, `
+			UPDATE accounts SET user_id = $3, updated_at = NOW()
+			WHERE id = $1 AND user_id = $2
+		`, transfer.AccountID, transfer.FromUserID, transfer.ToUserID)
+		if err != nil {
+			return fmt.Errorf("failed to move account: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrAccountNotFound.With("account_id", transfer.AccountID)
+		}
+
+		statements := []struct {
+			what  string
+			query string
+		}{
+			{"tags", `
+				INSERT INTO tags (id, user_id, name)
+				SELECT gen_random_uuid(), $2, names.name
+				FROM (
+					SELECT DISTINCT tg.name
+					FROM transaction_tags tt
+					JOIN tags tg ON tg.id = tt.tag_id
+					JOIN transactions t ON t.id = tt.transaction_id
+					WHERE t.account_id = $1
+				) names
+				ON CONFLICT (user_id, name) DO NOTHING
+			`},
+			{"transaction tags", `
+				UPDATE transaction_tags tt SET tag_id = recipient.id
+				FROM transactions t, tags sender, tags recipient
+				WHERE t.id = tt.transaction_id AND t.account_id = $1
+					AND sender.id = tt.tag_id AND recipient.user_id = $2 AND recipient.name = sender.name
+			`},
+			// Only the default categories are shared by every user
+			{"transactions", `
+				UPDATE transactions SET
+					user_id = $2,
+					category_id = CASE WHEN category_id IN (SELECT id FROM categories WHERE user_id IS NULL) THEN category_id END,
+					updated_at = NOW()
+				WHERE account_id = $1
+			`},
+			{"archived transactions", `
+				UPDATE archived_transactions SET
+					user_id = $2,
+					category_id = CASE WHEN category_id IN (SELECT id FROM categories WHERE user_id IS NULL) THEN category_id END
+				WHERE account_id = $1
+			`},
+			{"attachments", `UPDATE attachments SET user_id = $2 WHERE account_id = $1`},
+			{"investment positions", `UPDATE investment_positions SET user_id = $2, updated_at = NOW() WHERE account_id = $1`},
+			{"categorization rules", `DELETE FROM categorization_rules WHERE account_id = $1`},
+			{"imports", `DELETE FROM imports WHERE account_id = $1`},
+			{"household sharing", `DELETE FROM household_accounts WHERE account_id = $1`},
+		}
+		for _, stmt := range statements {
+			if _, err := tx.Exec(ctx, stmt.query, transfer.AccountID, transfer.ToUserID); err != nil {
+				return fmt.Errorf("failed to move %s: %w", stmt.what, err)
+			}
+		}
+		return nil
+	})
+}
+
+// scanTransfer reads a transfer from a row of FindByID or FindByUserID
+func scanTransfer(row pgx.Row) (*Transfer, error) {
+	var t Transfer
+	if err := row.Scan(&t.ID, &t.AccountID, &t.AccountName, &t.FromUserID, &t.ToUserID, &t.Status, &t.CreatedAt, &t.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,239 @@
+package transfers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Guizzs26/fintrack/pkg/audit"
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/i18n"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/notify"
+	"github.com/google/uuid"
+)
+
+// Audit vocabulary of the account transfers, the accepted transfers being recorded on the account itself
+const (
+	auditTransferRequested  = "account_transfer.requested"
+	auditTransferDeclined   = "account_transfer.declined"
+	auditTransferCancelled  = "account_transfer.cancelled"
+	auditAccountTransferred = "account.transferred"
+	auditResourceTransfer   = "account_transfer"
+	auditResourceAccount    = "account"
+)
+
+// RequestTransferParams holds all the required data for the RequestTransfer use case
+type RequestTransferParams struct {
+	UserID         uuid.UUID
+	AccountID      uuid.UUID
+	RecipientEmail string
+}
+
+// Service manages the transfers of accounts between users
+type Service struct {
+	repo     Repository
+	notifier notify.Notifier
+	display  module.UserPreferences
+	auditor  *audit.Logger
+	ids      idgen.Generator
+	clock    clock.Clock
+}
+
+// NewService creates a new instance of the transfers Service, both ends of a transfer are notified through notifier
+func NewService(repo Repository, notifier notify.Notifier, display module.UserPreferences, auditor *audit.Logger, ids idgen.Generator, clock clock.Clock) *Service {
+	return &Service{
+		repo:     repo,
+		notifier: notifier,
+		display:  display,
+		auditor:  auditor,
+		ids:      ids,
+		clock:    clock,
+	}
+}
+
+// RequestTransfer is the use case for offering an account of the user to another member of their households, who must accept it
+func (s *Service) RequestTransfer(ctx context.Context, params RequestTransferParams) (*Transfer, error) {
+	account, err := s.repo.FindAccount(ctx, params.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.UserID != params.UserID {
+		return nil, ErrAccountNotFound.With("account_id", params.AccountID)
+	}
+
+	recipientID, err := s.repo.FindUserIDByEmail(ctx, params.RecipientEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := NewTransfer(account, recipientID, s.ids, s.clock)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkHousehold(ctx, transfer); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, auditTransferRequested, transfer)
+	s.notify(ctx, transfer.ToUserID, transfer, "transfer_offered", transfer.AccountName)
+
+	return transfer, nil
+}
+
+// FindTransfers is the use case for listing a page of the transfers the user sent or received, the most recent first
+func (s *Service) FindTransfers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Transfer, error) {
+	transfers, err := s.repo.FindByUserID(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// AcceptTransfer is the use case for the recipient taking over the offered account with its transactions
+func (s *Service) AcceptTransfer(ctx context.Context, userID, transferID uuid.UUID) (*Transfer, error) {
+	transfer, err := s.findUserTransfer(ctx, userID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sender kept using the account while the offer was pending
+	account, err := s.repo.FindAccount(ctx, transfer.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.checkTransferable(); err != nil {
+		return nil, err
+	}
+	// Either user may have left the household since
+	if err := s.checkHousehold(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Accept(userID, s.clock); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Complete(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       auditAccountTransferred,
+		ResourceType: auditResourceAccount,
+		ResourceID:   transfer.AccountID.String(),
+		Before:       map[string]string{"user_id": transfer.FromUserID.String()},
+		After:        map[string]string{"user_id": transfer.ToUserID.String()},
+		Metadata:     map[string]string{"transfer_id": transfer.ID.String()},
+	})
+	s.notify(ctx, transfer.FromUserID, transfer, "transfer_accepted", transfer.AccountName)
+
+	return transfer, nil
+}
+
+// DeclineTransfer is the use case for the recipient refusing the offered account
+func (s *Service) DeclineTransfer(ctx context.Context, userID, transferID uuid.UUID) (*Transfer, error) {
+	transfer, err := s.findUserTransfer(ctx, userID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Decline(userID, s.clock); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, auditTransferDeclined, transfer)
+	s.notify(ctx, transfer.FromUserID, transfer, "transfer_declined", transfer.AccountName)
+
+	return transfer, nil
+}
+
+// CancelTransfer is the use case for the sender withdrawing a pending offer
+func (s *Service) CancelTransfer(ctx context.Context, userID, transferID uuid.UUID) (*Transfer, error) {
+	transfer, err := s.findUserTransfer(ctx, userID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Cancel(userID, s.clock); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, auditTransferCancelled, transfer)
+	return transfer, nil
+}
+
+// findUserTransfer finds a transfer the user sent or received, reporting the transfers of other users as not found
+func (s *Service) findUserTransfer(ctx context.Context, userID, transferID uuid.UUID) (*Transfer, error) {
+	transfer, err := s.repo.FindByID(ctx, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account transfer: %w", err)
+	}
+	if transfer.FromUserID != userID && transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound.With("transfer_id", transferID)
+	}
+	return transfer, nil
+}
+
+// checkHousehold checks the recipient of the transfer is a member of a household of the sender
+func (s *Service) checkHousehold(ctx context.Context, transfer *Transfer) error {
+	shares, err := s.repo.SharesHousehold(ctx, transfer.FromUserID, transfer.ToUserID)
+	if err != nil {
+		return fmt.Errorf("failed to check households: %w", err)
+	}
+	if !shares {
+		return ErrRecipientOutsideHousehold
+	}
+	return nil
+}
+
+// audit records a change of status of the transfer
+func (s *Service) audit(ctx context.Context, action string, transfer *Transfer) {
+	s.auditor.Record(ctx, audit.Entry{
+		Action:       action,
+		ResourceType: auditResourceTransfer,
+		ResourceID:   transfer.ID.String(),
+		Metadata: map[string]string{
+			"account_id":   transfer.AccountID.String(),
+			"from_user_id": transfer.FromUserID.String(),
+			"to_user_id":   transfer.ToUserID.String(),
+		},
+	})
+}
+
+// notify tells one end of the transfer about it in their language, the message being the key of its title and body in messages
+// A failed notification never undoes the transfer
+func (s *Service) notify(ctx context.Context, userID uuid.UUID, transfer *Transfer, message string, args ...any) {
+	lang := i18n.Default
+	if display, err := s.display.DisplayPreferences(ctx, userID); err == nil {
+		lang, _ = i18n.Resolve(display.Locale)
+	}
+
+	err := s.notifier.Notify(ctx, notify.Message{
+		UserID:   userID,
+		Category: notify.CategorySecurity,
+		Title:    messages.Format(lang, message+"_title"),
+		Body:     messages.Format(lang, message+"_body", args...),
+		Data: map[string]string{
+			"account_transfer_id": transfer.ID.String(),
+			"account_id":          transfer.AccountID.String(),
+			"status":              string(transfer.Status),
+		},
+	})
+	if err != nil {
+		ctxlogger.GetLogger(ctx).Error("failed to notify account transfer",
+			slog.String("transfer_id", transfer.ID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,180 @@
+package views
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Guizzs26/fintrack/pkg/errx"
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/ledger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrViewNotFound      = errx.New(errx.CategoryNotFound, "VIEW_NOT_FOUND", "view not found")
+	ErrViewAlreadyExists = errx.New(errx.CategoryConflict, "VIEW_ALREADY_EXISTS", "a view with the same name already exists")
+	ErrTooManyViews      = errx.New(errx.CategoryConflict, "TOO_MANY_VIEWS", "the maximum number of views was reached")
+	ErrInvalidViewName   = errx.New(errx.CategoryValidation, "INVALID_VIEW_NAME", "the view name must have between 1 and 60 characters")
+	ErrInvalidCriteria   = errx.New(errx.CategoryValidation, "INVALID_VIEW_CRITERIA", "the view criteria are invalid")
+)
+
+const (
+	// MaxViewsPerUser bounds the views offered by the UI
+	MaxViewsPerUser = 50
+
+	maxNameLength   = 60
+	maxSearchLength = 100
+	// maxCriteriaIDs bounds the accounts and the categories a view filters on
+	maxCriteriaIDs = 50
+)
+
+// Status filters the transactions on whether they were paid
+type Status string
+
+const (
+	StatusPaid   Status = "PAID"
+	StatusUnpaid Status = "UNPAID"
+)
+
+// Period is a date range relative to the day the view is opened, so a view keeps meaning the same thing over time
+type Period string
+
+const (
+	PeriodThisMonth  Period = "THIS_MONTH"
+	PeriodLastMonth  Period = "LAST_MONTH"
+	PeriodNextMonth  Period = "NEXT_MONTH"
+	PeriodLast30Days Period = "LAST_30_DAYS"
+	PeriodNext30Days Period = "NEXT_30_DAYS"
+	PeriodCustom     Period = "CUSTOM" // PeriodCustom uses the From and To dates of the criteria
+)
+
+var periods = []Period{PeriodThisMonth, PeriodLastMonth, PeriodNextMonth, PeriodLast30Days, PeriodNext30Days, PeriodCustom}
+
+// Criteria are the transaction filters of a view, applied by the clients
+// Empty filters match every transaction, the filters of a view all apply
+type Criteria struct {
+	AccountIDs  []uuid.UUID              `json:"account_ids,omitempty"`
+	CategoryIDs []uuid.UUID              `json:"category_ids,omitempty"`
+	Types       []ledger.TransactionType `json:"types,omitempty"`
+	Tags        []string                 `json:"tags,omitempty"` // Tags match transactions holding any of them
+	Status      Status                   `json:"status,omitempty"`
+	Period      Period                   `json:"period,omitempty"` // Period filters on the due date
+	From        string                   `json:"from,omitempty"`   // From and To are YYYY-MM-DD dates, both included, only for the CUSTOM period
+	To          string                   `json:"to,omitempty"`
+	Search      string                   `json:"search,omitempty"`     // Search is a text the description contains, ignoring case and accents
+	MinAmount   *int64                   `json:"min_amount,omitempty"` // MinAmount and MaxAmount bound the absolute amount, in minor units
+	MaxAmount   *int64                   `json:"max_amount,omitempty"`
+}
+
+// ParseCriteria decodes the criteria of a view, rejecting unknown filters so a typo does not silently match everything
+func ParseCriteria(raw json.RawMessage) (Criteria, error) {
+	var criteria Criteria
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return criteria, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&criteria); err != nil {
+		return Criteria{}, ErrInvalidCriteria.With("reason", err.Error())
+	}
+	if err := criteria.normalize(); err != nil {
+		return Criteria{}, err
+	}
+	return criteria, nil
+}
+
+// normalize validates the criteria, trimming the search text and normalizing the tags
+func (c *Criteria) normalize() error {
+	if len(c.AccountIDs) > maxCriteriaIDs || len(c.CategoryIDs) > maxCriteriaIDs {
+		return invalid("a view filters on at most 50 accounts and 50 categories")
+	}
+	for _, t := range c.Types {
+		if !slices.Contains(ledger.TransactionType("").Values(), string(t)) {
+			return invalid("unknown transaction type " + string(t))
+		}
+	}
+
+	tags, err := ledger.NormalizeTags(c.Tags)
+	if err != nil {
+		return invalid("invalid tags")
+	}
+	c.Tags = tags
+
+	if c.Status != "" && c.Status != StatusPaid && c.Status != StatusUnpaid {
+		return invalid("unknown status " + string(c.Status))
+	}
+
+	if c.Period != "" && !slices.Contains(periods, c.Period) {
+		return invalid("unknown period " + string(c.Period))
+	}
+	if c.Period == PeriodCustom {
+		from, err := time.Parse(time.DateOnly, c.From)
+		if err != nil {
+			return invalid("the custom period requires a from date formatted as YYYY-MM-DD")
+		}
+		to, err := time.Parse(time.DateOnly, c.To)
+		if err != nil {
+			return invalid("the custom period requires a to date formatted as YYYY-MM-DD")
+		}
+		if to.Before(from) {
+			return invalid("the custom period ends before it starts")
+		}
+	} else if c.From != "" || c.To != "" {
+		return invalid("from and to dates are only allowed with the CUSTOM period")
+	}
+
+	c.Search = strings.TrimSpace(c.Search)
+	if utf8.RuneCountInString(c.Search) > maxSearchLength {
+		return invalid("the search text is too long")
+	}
+
+	if (c.MinAmount != nil && *c.MinAmount < 0) || (c.MaxAmount != nil && *c.MaxAmount < 0) {
+		return invalid("amounts are absolute and cannot be negative")
+	}
+	if c.MinAmount != nil && c.MaxAmount != nil && *c.MinAmount > *c.MaxAmount {
+		return invalid("the minimum amount is greater than the maximum")
+	}
+	return nil
+}
+
+// invalid builds an ErrInvalidCriteria describing the problem found
+func invalid(reason string) error {
+	return ErrInvalidCriteria.With("reason", reason)
+}
+
+// View is a named set of transaction filters the user saved (e.g., "Unpaid bills this month"),
+// kept on the server so it is offered on every device
+// The accounts and categories of the criteria are not checked, a view filtering on a deleted one matches nothing
+type View struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Criteria  Criteria
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// validateName trims the name of a view and checks its length
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if length := utf8.RuneCountInString(name); length == 0 || length > maxNameLength {
+		return "", ErrInvalidViewName.With("max_length", maxNameLength)
+	}
+	return name, nil
+}
+
+// Repository persists the saved views
+type Repository interface {
+	// Save inserts or updates the view, failing with ErrViewAlreadyExists when the user has another view with the same name
+	Save(ctx context.Context, view *View) error
+	FindByID(ctx context.Context, userID, viewID uuid.UUID) (*View, error)
+	// FindByUserID returns the views of the user ordered by name
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*View, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	Delete(ctx context.Context, userID, viewID uuid.UUID) error
+}
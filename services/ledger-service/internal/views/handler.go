@@ -0,0 +1,186 @@
+package views
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/authctx"
+	"github.com/Guizzs26/fintrack/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ViewHandler holds dependencies for saved view HTTP handlers
+type ViewHandler struct {
+	viewService *Service
+}
+
+// NewViewHandler creates a new instance of ViewHandler
+func NewViewHandler(viewService *Service) *ViewHandler {
+	return &ViewHandler{viewService: viewService}
+}
+
+// RegisterRoutes sets up the API routes for the views module
+func (h *ViewHandler) RegisterRoutes(apiRouteGroup *echo.Group) {
+	viewsGroup := apiRouteGroup.Group("/views")
+
+	viewsGroup.POST("", h.createViewHandler)
+	viewsGroup.GET("", h.listViewsHandler)
+	viewsGroup.GET("/:id", h.findViewHandler)
+	viewsGroup.PUT("/:id", h.updateViewHandler)
+	viewsGroup.DELETE("/:id", h.deleteViewHandler)
+}
+
+// SaveViewRequest defines the expected JSON body for creating or replacing a view
+type SaveViewRequest struct {
+	Name     string          `json:"name" validate:"required,max=60"`
+	Criteria json.RawMessage `json:"criteria"` // Criteria are the filters of the view (e.g., {"types": ["EXPENSE"], "status": "UNPAID", "period": "THIS_MONTH"})
+}
+
+// ViewResponse defines the structure of a view returned by the API
+type ViewResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Criteria  Criteria  `json:"criteria"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// createViewHandler handles the HTTP request for saving a view
+func (h *ViewHandler) createViewHandler(c echo.Context) error {
+	var req SaveViewRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	view, err := h.viewService.CreateView(c.Request().Context(), SaveViewParams{
+		UserID:   userID,
+		Name:     req.Name,
+		Criteria: req.Criteria,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusCreated, toViewResponse(view))
+}
+
+// listViewsHandler handles the HTTP request for listing the user's views
+func (h *ViewHandler) listViewsHandler(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	views, err := h.viewService.FindViews(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]ViewResponse, 0, len(views))
+	for _, view := range views {
+		resp = append(resp, toViewResponse(view))
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, resp)
+}
+
+// findViewHandler handles the HTTP request for finding a view by its ID
+func (h *ViewHandler) findViewHandler(c echo.Context) error {
+	viewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid view id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	view, err := h.viewService.FindView(c.Request().Context(), userID, viewID)
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toViewResponse(view))
+}
+
+// updateViewHandler handles the HTTP request for renaming a view and replacing its criteria
+func (h *ViewHandler) updateViewHandler(c echo.Context) error {
+	viewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid view id format")
+	}
+
+	var req SaveViewRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	view, err := h.viewService.UpdateView(c.Request().Context(), viewID, SaveViewParams{
+		UserID:   userID,
+		Name:     req.Name,
+		Criteria: req.Criteria,
+	})
+	if err != nil {
+		return err
+	}
+
+	return httpx.SendSuccess(c, http.StatusOK, toViewResponse(view))
+}
+
+// deleteViewHandler handles the HTTP request for deleting a view
+func (h *ViewHandler) deleteViewHandler(c echo.Context) error {
+	viewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid view id format")
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.viewService.DeleteView(c.Request().Context(), userID, viewID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// currentUserID returns the ID of the authenticated user injected by the auth middleware
+func currentUserID(c echo.Context) (uuid.UUID, error) {
+	user, ok := authctx.UserFromContext(c.Request().Context())
+	if !ok {
+		return uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	}
+	return user.UserID, nil
+}
+
+// toViewResponse maps the internal View domain model to the public ViewResponse DTO
+func toViewResponse(v *View) ViewResponse {
+	return ViewResponse{
+		ID:        v.ID,
+		Name:      v.Name,
+		Criteria:  v.Criteria,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
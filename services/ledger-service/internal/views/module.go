@@ -0,0 +1,30 @@
+package views
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+	"github.com/labstack/echo/v4"
+)
+
+var _ module.Module = (*Module)(nil)
+
+// Module wires the views repository, service and handler from the shared dependencies
+type Module struct {
+	handler *ViewHandler
+}
+
+// NewModule creates the views module, whose views are stored in Postgres
+func NewModule(deps module.Deps) *Module {
+	viewSvc := NewService(NewPostgresRepository(deps.Postgres.Pool), deps.IDs, deps.Clock)
+
+	return &Module{handler: NewViewHandler(viewSvc)}
+}
+
+// Name returns the module identifier
+func (m *Module) Name() string {
+	return "views"
+}
+
+// RegisterRoutes mounts the views routes
+func (m *Module) RegisterRoutes(apiRouteGroup *echo.Group) {
+	m.handler.RegisterRoutes(apiRouteGroup)
+}
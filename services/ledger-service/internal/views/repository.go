@@ -0,0 +1,139 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// viewUniqueIndex keeps a single view per name, case-insensitively, for each user
+const viewUniqueIndex = "uq_saved_views_user_id_name"
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// Save inserts a new view or updates the name and the criteria of an existing one
+func (r *PostgresRepository) Save(ctx context.Context, view *View) error {
+	criteria, err := json.Marshal(view.Criteria)
+	if err != nil {
+		return fmt.Errorf("failed to encode view criteria: %w", err)
+	}
+
+	query := `
+		INSERT INTO saved_views (id, user_id, name, criteria, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id)
+		DO UPDATE SET
+			name = EXCLUDED.name,
+			criteria = EXCLUDED.criteria,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = r.pool.Exec(ctx, query, view.ID, view.UserID, view.Name, criteria, view.CreatedAt, view.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == viewUniqueIndex {
+			return ErrViewAlreadyExists.With("name", view.Name)
+		}
+		return fmt.Errorf("failed to upsert view: %w", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a view of the user
+func (r *PostgresRepository) FindByID(ctx context.Context, userID, viewID uuid.UUID) (*View, error) {
+	query := `
+		SELECT id, user_id, name, criteria, created_at, updated_at
+		FROM saved_views
+		WHERE id = $1 AND user_id = $2
+	`
+
+	view, err := scanView(r.pool.QueryRow(ctx, query, viewID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrViewNotFound.With("view_id", viewID)
+		}
+		return nil, fmt.Errorf("failed to fetch view: %w", err)
+	}
+	return view, nil
+}
+
+// FindByUserID retrieves the views of the user ordered by name
+func (r *PostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*View, error) {
+	query := `
+		SELECT id, user_id, name, criteria, created_at, updated_at
+		FROM saved_views
+		WHERE user_id = $1
+		ORDER BY LOWER(name), id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	views := make([]*View, 0)
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan view row: %w", err)
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating view rows: %w", err)
+	}
+
+	return views, nil
+}
+
+// CountByUserID counts the views of the user
+func (r *PostgresRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM saved_views WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count views: %w", err)
+	}
+	return count, nil
+}
+
+// Delete deletes a view of the user
+func (r *PostgresRepository) Delete(ctx context.Context, userID, viewID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM saved_views WHERE id = $1 AND user_id = $2`, viewID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrViewNotFound.With("view_id", viewID)
+	}
+	return nil
+}
+
+// scanView scans a saved_views row, decoding its criteria
+func scanView(row pgx.Row) (*View, error) {
+	var (
+		view     View
+		criteria []byte
+	)
+	if err := row.Scan(&view.ID, &view.UserID, &view.Name, &criteria, &view.CreatedAt, &view.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(criteria, &view.Criteria); err != nil {
+		return nil, fmt.Errorf("failed to decode view criteria: %w", err)
+	}
+	return &view, nil
+}
@@ -0,0 +1,112 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	"github.com/Guizzs26/fintrack/pkg/idgen"
+	"github.com/google/uuid"
+)
+
+// SaveViewParams holds all the required data for the CreateView and UpdateView use cases
+type SaveViewParams struct {
+	UserID   uuid.UUID
+	Name     string
+	Criteria json.RawMessage
+}
+
+// Service manages the transaction views saved by the users
+type Service struct {
+	repo  Repository
+	ids   idgen.Generator
+	clock clock.Clock
+}
+
+// NewService creates a new instance of the views Service
+func NewService(repo Repository, ids idgen.Generator, clock clock.Clock) *Service {
+	return &Service{
+		repo:  repo,
+		ids:   ids,
+		clock: clock,
+	}
+}
+
+// CreateView is the use case for saving a named set of transaction filters
+func (s *Service) CreateView(ctx context.Context, params SaveViewParams) (*View, error) {
+	name, err := validateName(params.Name)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := ParseCriteria(params.Criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxViewsPerUser {
+		return nil, ErrTooManyViews.With("max_views", MaxViewsPerUser)
+	}
+
+	now := s.clock.Now()
+	view := &View{
+		ID:        s.ids.NewID(),
+		UserID:    params.UserID,
+		Name:      name,
+		Criteria:  criteria,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Save(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// UpdateView is the use case for renaming a view and replacing its filters
+func (s *Service) UpdateView(ctx context.Context, viewID uuid.UUID, params SaveViewParams) (*View, error) {
+	name, err := validateName(params.Name)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := ParseCriteria(params.Criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := s.repo.FindByID(ctx, params.UserID, viewID)
+	if err != nil {
+		return nil, err
+	}
+	view.Name = name
+	view.Criteria = criteria
+	view.UpdatedAt = s.clock.Now()
+
+	if err := s.repo.Save(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// FindView is the use case for finding one of the user's views
+func (s *Service) FindView(ctx context.Context, userID, viewID uuid.UUID) (*View, error) {
+	return s.repo.FindByID(ctx, userID, viewID)
+}
+
+// FindViews is the use case for listing the views of the user, ordered by name
+func (s *Service) FindViews(ctx context.Context, userID uuid.UUID) ([]*View, error) {
+	views, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find views: %w", err)
+	}
+	return views, nil
+}
+
+// DeleteView is the use case for deleting one of the user's views
+func (s *Service) DeleteView(ctx context.Context, userID, viewID uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, viewID)
+}
@@ -0,0 +1,116 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/contracts"
+	"github.com/google/uuid"
+)
+
+// Operation is the kind of change a record describes, named as change data capture tools name them
+type Operation string
+
+const (
+	OperationInsert Operation = "INSERT"
+	OperationUpdate Operation = "UPDATE"
+	OperationDelete Operation = "DELETE"
+)
+
+// operations maps the ledger events to the change they make to their aggregate, the events missing from it
+// being exported as updates
+var operations = map[string]Operation{
+	contracts.TypeTransactionCreated:       OperationInsert,
+	contracts.TypeAccountArchived:          OperationUpdate,
+	contracts.TypeAccountDeletionScheduled: OperationUpdate,
+	contracts.TypeAccountDeletionCancelled: OperationUpdate,
+	contracts.TypeAccountDeleted:           OperationDelete,
+}
+
+// Change is a row of the ledger outbox, as the exporter reads it
+type Change struct {
+	Position      int64
+	EventID       uuid.UUID
+	AggregateType string
+	AggregateID   uuid.UUID
+	EventType     string
+	EventVersion  int
+	Envelope      []byte
+	RecordedAt    time.Time
+}
+
+// Record is a change normalized for the warehouse, a row of the exported files
+type Record struct {
+	Position     int64 // Position orders the records, the changes of an entity being applied in increasing position
+	EventID      uuid.UUID
+	EventType    string
+	EventVersion int
+	Entity       string    // Entity is the aggregate the change is about (e.g., "transaction", "account")
+	EntityID     uuid.UUID // EntityID is the ID of the aggregate
+	Operation    Operation
+	UserID       uuid.UUID // UserID owns the entity, only the changes of the users who opted in are exported
+	OccurredAt   time.Time
+	RecordedAt   time.Time
+	Data         json.RawMessage // Data is the payload of the event, as its versioned schema describes it
+}
+
+// Normalize turns an outbox change into a record, reading its owner from the payload
+// Every ledger event names the user it belongs to, a change without one is kept with a nil UserID and never exported
+func Normalize(change Change) (Record, error) {
+	envelope, err := contracts.Unmarshal(change.Envelope)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to unmarshal envelope of position %d: %w", change.Position, err)
+	}
+	var owner struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := json.Unmarshal(envelope.Data, &owner); err != nil {
+		return Record{}, fmt.Errorf("failed to read owner of position %d: %w", change.Position, err)
+	}
+
+	operation, ok := operations[change.EventType]
+	if !ok {
+		operation = OperationUpdate
+	}
+	return Record{
+		Position:     change.Position,
+		EventID:      change.EventID,
+		EventType:    change.EventType,
+		EventVersion: change.EventVersion,
+		Entity:       change.AggregateType,
+		EntityID:     change.AggregateID,
+		Operation:    operation,
+		UserID:       owner.UserID,
+		OccurredAt:   envelope.OccurredAt,
+		RecordedAt:   change.RecordedAt,
+		Data:         envelope.Data,
+	}, nil
+}
+
+// Export is a batch of outbox rows read by the exporter, written to a single file
+type Export struct {
+	ID            uuid.UUID
+	ObjectKey     string // ObjectKey is empty when no user of the batch opted in, no file being written
+	FirstPosition int64
+	LastPosition  int64
+	Records       int // Records counts the rows of the file
+	ExportedAt    time.Time
+}
+
+// Repository reads the ledger outbox and records the batches exported from it
+type Repository interface {
+	// LastPosition returns the position of the last row exported, 0 before the first export
+	LastPosition(ctx context.Context) (int64, error)
+	// FindChanges returns up to limit rows of the outbox after the position and recorded before the given time, oldest first
+	FindChanges(ctx context.Context, after int64, before time.Time, limit int) ([]Change, error)
+	// FindSharingUsers returns which of the users opted in to the export of their ledger changes
+	FindSharingUsers(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	SaveExport(ctx context.Context, export *Export) error
+}
+
+// Sink is where the exported files are written, an S3 compatible bucket or a local directory
+type Sink interface {
+	Put(ctx context.Context, key, contentType string, content []byte) error
+}
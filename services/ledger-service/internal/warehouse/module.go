@@ -0,0 +1,28 @@
+package warehouse
+
+import (
+	"github.com/Guizzs26/fintrack/services/ledger-service/internal/platform/module"
+)
+
+// Module wires the warehouse repository and service from the shared dependencies
+// It has no routes, users opt in to the export through the settings module
+type Module struct {
+	service *Service
+}
+
+// NewModule creates the warehouse module, the exported files being written to sink
+func NewModule(deps module.Deps, sink Sink) *Module {
+	cfg := deps.Config.Warehouse
+	return &Module{
+		service: NewService(NewPostgresRepository(deps.Postgres.Pool), sink, Config{
+			Prefix:      cfg.Prefix,
+			BatchSize:   cfg.BatchSize,
+			SettleDelay: cfg.SettleDelay,
+		}, deps.Clock),
+	}
+}
+
+// Service returns the warehouse service, for the composition root scheduling the exports
+func (m *Module) Service() *Service {
+	return m.service
+}
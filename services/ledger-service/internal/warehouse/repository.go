@@ -0,0 +1,104 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// PostgresRepository is a PostgreSQL implementation of the Repository interface
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// LastPosition retrieves the position the latest export ended at
+func (r *PostgresRepository) LastPosition(ctx context.Context) (int64, error) {
+	var position int64
+	if err := r.pool.QueryRow(ctx, `SELECT COALESCE(MAX(last_position), 0) FROM warehouse_exports`).Scan(&position); err != nil {
+		return 0, fmt.Errorf("failed to fetch last exported position: %w", err)
+	}
+	return position, nil
+}
+
+// FindChanges retrieves the outbox rows after the position recorded before the given time
+func (r *PostgresRepository) FindChanges(ctx context.Context, after int64, before time.Time, limit int) ([]Change, error) {
+	query := `
+		SELECT position, event_id, aggregate_type, aggregate_id, event_type, event_version, envelope, recorded_at
+		FROM outbox
+		WHERE position > $1 AND recorded_at < $2
+		ORDER BY position
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, after, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox changes: %w", err)
+	}
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Change, error) {
+		var c Change
+		err := row.Scan(&c.Position, &c.EventID, &c.AggregateType, &c.AggregateID, &c.EventType, &c.EventVersion, &c.Envelope, &c.RecordedAt)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan outbox changes: %w", err)
+	}
+	return changes, nil
+}
+
+// FindSharingUsers retrieves which of the users opted in to the export, the users without settings never did
+func (r *PostgresRepository) FindSharingUsers(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	query := `
+		SELECT user_id
+		FROM user_settings
+		WHERE user_id = ANY($1::UUID[]) AND analytics_sharing_enabled
+	`
+
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analytics sharing consents: %w", err)
+	}
+	defer rows.Close()
+
+	sharing := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user id row: %w", err)
+		}
+		sharing[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user id rows: %w", err)
+	}
+
+	return sharing, nil
+}
+
+// SaveExport records an exported batch, moving the position the next export resumes from
+func (r *PostgresRepository) SaveExport(ctx context.Context, export *Export) error {
+	query := `
+		INSERT INTO warehouse_exports (id, object_key, first_position, last_position, records, exported_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6)
+	`
+
+	_, err := r.pool.Exec(ctx, query, export.ID, export.ObjectKey, export.FirstPosition, export.LastPosition, export.Records, export.ExportedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert warehouse export: %w", err)
+	}
+	return nil
+}
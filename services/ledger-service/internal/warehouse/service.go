@@ -0,0 +1,169 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/Guizzs26/fintrack/pkg/clock"
+	ctxlogger "github.com/Guizzs26/fintrack/pkg/logger/context"
+	"github.com/Guizzs26/fintrack/pkg/parquet"
+	"github.com/google/uuid"
+)
+
+// parquetContentType is the media type of the exported files
+const parquetContentType = "application/vnd.apache.parquet"
+
+// columns is the schema of the exported files, in the order of the values appended by Service.encode
+var columns = []parquet.Column{
+	{Name: "position", Type: parquet.Int64},
+	{Name: "event_id", Type: parquet.String},
+	{Name: "event_type", Type: parquet.String},
+	{Name: "event_version", Type: parquet.Int32},
+	{Name: "entity", Type: parquet.String},
+	{Name: "entity_id", Type: parquet.String},
+	{Name: "operation", Type: parquet.String},
+	{Name: "user_id", Type: parquet.String},
+	{Name: "occurred_at", Type: parquet.Timestamp},
+	{Name: "recorded_at", Type: parquet.Timestamp},
+	{Name: "data", Type: parquet.JSON},
+}
+
+// Config tunes the exports
+type Config struct {
+	Prefix    string // Prefix is the folder of the files in the sink (e.g., "ledger_changes")
+	BatchSize int    // BatchSize is how many outbox rows go in a file at most
+	// SettleDelay is how old an outbox row must be to be exported, so a write committed after a newer one is not skipped
+	SettleDelay time.Duration
+}
+
+// Service exports the ledger changes of the users who opted in to the data warehouse
+type Service struct {
+	repo  Repository
+	sink  Sink
+	cfg   Config
+	clock clock.Clock
+}
+
+// NewService creates a new instance of the warehouse Service
+func NewService(repo Repository, sink Sink, cfg Config, clock clock.Clock) *Service {
+	return &Service{repo: repo, sink: sink, cfg: cfg, clock: clock}
+}
+
+// Export is the use case for writing the outbox rows recorded since the last export to the sink, a file per batch
+// Consent is checked at export time: the changes made while a user had not opted in are never exported, even
+// once they opt in, and opting out stops the export of the changes not exported yet
+func (s *Service) Export(ctx context.Context) error {
+	after, err := s.repo.LastPosition(ctx)
+	if err != nil {
+		return err
+	}
+
+	before := s.clock.Now().Add(-s.cfg.SettleDelay)
+	files, records := 0, 0
+	for {
+		changes, err := s.repo.FindChanges(ctx, after, before, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			break
+		}
+
+		export, err := s.exportBatch(ctx, changes)
+		if err != nil {
+			return err
+		}
+		if export.ObjectKey != "" {
+			files++
+		}
+		records += export.Records
+		after = export.LastPosition
+
+		if len(changes) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	ctxlogger.GetLogger(ctx).Info("exported ledger changes to the warehouse",
+		slog.Int("files", files),
+		slog.Int("records", records),
+		slog.Int64("last_position", after),
+	)
+	return nil
+}
+
+// exportBatch writes the records of the consenting users among the changes, then records the batch
+// The key of the file only depends on the batch, so a batch written again after a crash replaces its file
+func (s *Service) exportBatch(ctx context.Context, changes []Change) (*Export, error) {
+	first, last := changes[0], changes[len(changes)-1]
+	export := &Export{
+		ID:            uuid.New(),
+		FirstPosition: first.Position,
+		LastPosition:  last.Position,
+	}
+
+	records := make([]Record, 0, len(changes))
+	userIDs := make([]uuid.UUID, 0, len(changes))
+	for _, change := range changes {
+		record, err := Normalize(change)
+		if err != nil {
+			// A malformed row would otherwise block every later export
+			ctxlogger.GetLogger(ctx).Error("skipped malformed outbox row",
+				slog.Int64("position", change.Position),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		records = append(records, record)
+		userIDs = append(userIDs, record.UserID)
+	}
+
+	sharing, err := s.repo.FindSharingUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	table := parquet.New(columns...)
+	for _, record := range records {
+		if !sharing[record.UserID] {
+			continue
+		}
+		if err := encode(table, record); err != nil {
+			return nil, fmt.Errorf("failed to encode record of position %d: %w", record.Position, err)
+		}
+	}
+
+	if table.Len() > 0 {
+		date := first.RecordedAt.UTC().Format(time.DateOnly)
+		export.ObjectKey = path.Join(s.cfg.Prefix, "dt="+date, fmt.Sprintf("%020d-%020d.parquet", first.Position, last.Position))
+		if err := s.sink.Put(ctx, export.ObjectKey, parquetContentType, table.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to write warehouse export %s: %w", export.ObjectKey, err)
+		}
+	}
+	export.Records = table.Len()
+	export.ExportedAt = s.clock.Now()
+
+	if err := s.repo.SaveExport(ctx, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// encode appends the record to the table, following the order of columns
+func encode(table *parquet.Table, r Record) error {
+	return table.Append(
+		r.Position,
+		r.EventID.String(),
+		r.EventType,
+		int32(r.EventVersion),
+		r.Entity,
+		r.EntityID.String(),
+		string(r.Operation),
+		r.UserID.String(),
+		r.OccurredAt,
+		r.RecordedAt,
+		string(r.Data),
+	)
+}
@@ -0,0 +1,40 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var _ Sink = (*DirSink)(nil)
+
+// DirSink writes the exported files to a local directory, for development or a volume synced to the warehouse
+type DirSink struct {
+	dir string
+}
+
+// NewDirSink creates a new DirSink writing under dir, which is created when missing
+func NewDirSink(dir string) (*DirSink, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create warehouse directory: %w", err)
+	}
+	return &DirSink{dir: dir}, nil
+}
+
+// Put writes the file under the directory, through a temporary file so a partial file is never seen
+func (s *DirSink) Put(_ context.Context, key, _ string, content []byte) error {
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return fmt.Errorf("failed to create warehouse directory: %w", err)
+	}
+
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o640); err != nil {
+		return fmt.Errorf("failed to write warehouse file: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf("failed to move warehouse file: %w", err)
+	}
+	return nil
+}